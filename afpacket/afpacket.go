@@ -112,6 +112,8 @@ type TPacket struct {
 	shouldReleasePacket bool
 	// headerNextNeeded is set to true when header need to move to the next packet. No need to move it case of poll error.
 	headerNextNeeded bool
+	// ifaceNames caches the names of the interfaces packets were seen on by their index.
+	ifaceNames map[int]string
 	// tpVersion is the version of TPacket actually in use, set by setRequestedTPacketVersion.
 	tpVersion OptTPacketVersion
 	// Hackity hack hack hack.  We need to return a pointer to the header with
@@ -316,10 +318,22 @@ retry:
 	ci.CaptureLength = len(data)
 	ci.Length = h.current.getLength()
 	ci.InterfaceIndex = h.current.getIfaceIndex()
+	ci.InterfaceName = h.interfaceName(ci.InterfaceIndex)
+	if h.current.getPacketType() == unix.PACKET_OUTGOING {
+		ci.Direction = gopacket.PacketDirectionOutbound
+	} else {
+		ci.Direction = gopacket.PacketDirectionInbound
+	}
 	vlan := h.current.getVLAN()
 	if vlan >= 0 {
 		ci.AncillaryData = append(ci.AncillaryData, AncillaryVLAN{vlan})
 	}
+	if status := h.current.getPacketStatus(); status&(unix.TP_STATUS_CSUMNOTREADY|unix.TP_STATUS_CSUM_VALID) != 0 {
+		ci.AncillaryData = append(ci.AncillaryData, gopacket.ChecksumOffload{
+			NotReady: status&unix.TP_STATUS_CSUMNOTREADY != 0,
+			Verified: status&unix.TP_STATUS_CSUM_VALID != 0,
+		})
+	}
 	atomic.AddInt64(&h.stats.Packets, 1)
 	h.headerNextNeeded = true
 	h.mu.Unlock()
@@ -327,6 +341,26 @@ retry:
 	return
 }
 
+// interfaceName returns the name of the interface with the given index.  h.mu
+// must be held.
+func (h *TPacket) interfaceName(index int) string {
+	if h.opts.iface != "" {
+		return h.opts.iface
+	}
+	if name, ok := h.ifaceNames[index]; ok {
+		return name
+	}
+	iface, err := net.InterfaceByIndex(index)
+	if err != nil {
+		return ""
+	}
+	if h.ifaceNames == nil {
+		h.ifaceNames = make(map[int]string)
+	}
+	h.ifaceNames[index] = iface.Name
+	return iface.Name
+}
+
 // Stats returns statistics on the packets the TPacket has seen so far.
 func (h *TPacket) Stats() (Stats, error) {
 	return Stats{
@@ -47,8 +47,14 @@ type header interface {
 	// getIfaceIndex returns the index of the network interface
 	// where the packet was seen. The index can later be translated to a name.
 	getIfaceIndex() int
+	// getPacketType returns the sll_pkttype of the packet, e.g.
+	// unix.PACKET_OUTGOING for packets sent by this host.
+	getPacketType() uint8
 	// getVLAN returns the VLAN of a packet if it was provided out-of-band
 	getVLAN() int
+	// getPacketStatus returns the TPacket status of the current packet,
+	// which differs from getStatus for the tpacket3 block header.
+	getPacketStatus() int
 	// next moves this header to point to the next packet it contains,
 	// returning true on success (in which case getTime and getData will
 	// return values for the new packet) or false if there are no more
@@ -89,6 +95,9 @@ func (h *v1header) getVLAN() int {
 func (h *v1header) getStatus() int {
 	return int(h.Status)
 }
+func (h *v1header) getPacketStatus() int {
+	return int(h.Status)
+}
 func (h *v1header) clearStatus() {
 	h.Status = 0
 }
@@ -105,6 +114,10 @@ func (h *v1header) getIfaceIndex() int {
 	ll := (*unix.RawSockaddrLinklayer)(unsafe.Pointer(uintptr(unsafe.Pointer(h)) + uintptr(tpAlign(unix.SizeofTpacketHdr))))
 	return int(ll.Ifindex)
 }
+func (h *v1header) getPacketType() uint8 {
+	ll := (*unix.RawSockaddrLinklayer)(unsafe.Pointer(uintptr(unsafe.Pointer(h)) + uintptr(tpAlign(unix.SizeofTpacketHdr))))
+	return ll.Pkttype
+}
 func (h *v1header) next() bool {
 	return false
 }
@@ -115,6 +128,9 @@ func (h *v2header) getVLAN() int {
 func (h *v2header) getStatus() int {
 	return int(h.Status)
 }
+func (h *v2header) getPacketStatus() int {
+	return int(h.Status)
+}
 func (h *v2header) clearStatus() {
 	h.Status = 0
 }
@@ -132,6 +148,10 @@ func (h *v2header) getIfaceIndex() int {
 	ll := (*unix.RawSockaddrLinklayer)(unsafe.Pointer(uintptr(unsafe.Pointer(h)) + uintptr(tpAlign(unix.SizeofTpacket2Hdr))))
 	return int(ll.Ifindex)
 }
+func (h *v2header) getPacketType() uint8 {
+	ll := (*unix.RawSockaddrLinklayer)(unsafe.Pointer(uintptr(unsafe.Pointer(h)) + uintptr(tpAlign(unix.SizeofTpacket2Hdr))))
+	return ll.Pkttype
+}
 func (h *v2header) next() bool {
 	return false
 }
@@ -160,6 +180,9 @@ func (w *v3wrapper) getVLAN() int {
 func (w *v3wrapper) getStatus() int {
 	return int(w.blockhdr.Block_status)
 }
+func (w *v3wrapper) getPacketStatus() int {
+	return int(w.packet.Status)
+}
 func (w *v3wrapper) clearStatus() {
 	w.blockhdr.Block_status = 0
 }
@@ -178,6 +201,10 @@ func (w *v3wrapper) getIfaceIndex() int {
 	ll := (*unix.RawSockaddrLinklayer)(unsafe.Pointer(uintptr(unsafe.Pointer(w.packet)) + uintptr(tpAlign(unix.SizeofTpacket3Hdr))))
 	return int(ll.Ifindex)
 }
+func (w *v3wrapper) getPacketType() uint8 {
+	ll := (*unix.RawSockaddrLinklayer)(unsafe.Pointer(uintptr(unsafe.Pointer(w.packet)) + uintptr(tpAlign(unix.SizeofTpacket3Hdr))))
+	return ll.Pkttype
+}
 func (w *v3wrapper) next() bool {
 	w.used++
 	if w.used >= w.blockhdr.Num_pkts {
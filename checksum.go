@@ -0,0 +1,129 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import "errors"
+
+// ChecksumVerifier is implemented by layers with a checksum, like
+// layers.IPv4, layers.TCP and layers.UDP.
+type ChecksumVerifier interface {
+	// VerifyChecksum returns true if the checksum of the layer is valid.  It
+	// returns an error if the checksum can't be verified, e.g. because it
+	// covers a network layer pseudo header and no network layer was set.
+	VerifyChecksum() (bool, error)
+}
+
+// ChecksumStatus is the result of verifying the checksum of a layer.
+type ChecksumStatus uint8
+
+const (
+	// ChecksumUnverifiable is used if the checksum couldn't be verified.
+	ChecksumUnverifiable ChecksumStatus = iota
+	// ChecksumValid is used if the checksum is valid.
+	ChecksumValid
+	// ChecksumInvalid is used if the checksum is invalid.
+	ChecksumInvalid
+	// ChecksumOffloaded is used if the checksum is invalid, but the capture
+	// source reported that it wasn't computed yet, because computing it is
+	// offloaded to the network card.
+	ChecksumOffloaded
+)
+
+func (s ChecksumStatus) String() string {
+	switch s {
+	case ChecksumUnverifiable:
+		return "Unverifiable"
+	case ChecksumValid:
+		return "Valid"
+	case ChecksumInvalid:
+		return "Invalid"
+	case ChecksumOffloaded:
+		return "Offloaded"
+	}
+	return "Unknown"
+}
+
+// ChecksumResult is the result of verifying the checksum of a single layer.
+type ChecksumResult struct {
+	// Layer is the index of the layer in Packet.Layers().
+	Layer     int
+	LayerType LayerType
+	Status    ChecksumStatus
+	// Err is the reason for ChecksumUnverifiable.
+	Err error
+}
+
+// ChecksumOffload is ancillary data added to CaptureInfo by capture sources
+// that know about checksum offloading, like afpacket (TP_STATUS_CSUMNOTREADY
+// and TP_STATUS_CSUM_VALID).  Callers knowing that the checksums of a capture
+// file were offloaded can add it themselves.  It applies to the checksums of
+// the layers above the network layer; network layer checksums are never
+// offloaded.
+type ChecksumOffload struct {
+	// NotReady is true if the checksums haven't been computed yet, which is
+	// typical for outgoing packets captured on the sending host.
+	NotReady bool
+	// Verified is true if the checksums were already verified by the kernel
+	// or network card.
+	Verified bool
+}
+
+var errChecksumTruncated = errors.New("packet truncated")
+
+// VerifyChecksums verifies the checksums of all layers of p implementing
+// ChecksumVerifier and returns a result for each of them.  Layers with a
+// pseudo header checksum are verified against the preceding network layer.
+// A ChecksumOffload in the AncillaryData of p is taken into account, so
+// outgoing packets with offloaded checksums aren't flagged as corrupted.
+// Checksums covering the payload of a truncated packet are unverifiable,
+// unless the capture source verified them.
+func VerifyChecksums(p Packet) []ChecksumResult {
+	var offload ChecksumOffload
+	md := p.Metadata()
+	for _, a := range md.AncillaryData {
+		if o, ok := a.(ChecksumOffload); ok {
+			offload = o
+		}
+	}
+	var results []ChecksumResult
+	var network NetworkLayer
+	for i, l := range p.Layers() {
+		_, isNetwork := l.(NetworkLayer)
+		if v, ok := l.(ChecksumVerifier); ok {
+			result := ChecksumResult{Layer: i, LayerType: l.LayerType()}
+			var valid bool
+			var err error
+			if c, ok := l.(checksumNetworkLayerSetter); ok && network != nil {
+				err = c.SetNetworkLayerForChecksum(network)
+			}
+			if err == nil {
+				if md.Truncated && !isNetwork {
+					err = errChecksumTruncated
+				} else {
+					valid, err = v.VerifyChecksum()
+				}
+			}
+			switch {
+			case err != nil && !isNetwork && offload.Verified:
+				result.Status = ChecksumValid
+			case err != nil:
+				result.Err = err
+			case valid:
+				result.Status = ChecksumValid
+			case !isNetwork && offload.NotReady:
+				result.Status = ChecksumOffloaded
+			default:
+				result.Status = ChecksumInvalid
+			}
+			results = append(results, result)
+		}
+		if isNetwork {
+			network = l.(NetworkLayer)
+		}
+	}
+	return results
+}
@@ -8,6 +8,7 @@ package gopacket
 
 import (
 	"errors"
+	"fmt"
 )
 
 // DecodeFeedback is used by DecodingLayer layers to provide decoding metadata.
@@ -150,6 +151,26 @@ func (d *DecodeFailure) Dump() (s string) {
 // LayerType returns LayerTypeDecodeFailure
 func (d *DecodeFailure) LayerType() LayerType { return LayerTypeDecodeFailure }
 
+// ErrDecodeLimit is the error of the DecodeFailure layer added to a packet if
+// decoding stopped because a limit of DecodeOptions was exceeded:
+//
+//  if failure := p.ErrorLayer(); failure != nil {
+//    if limit, ok := failure.Error().(*gopacket.ErrDecodeLimit); ok {
+//      fmt.Println("stopped decoding at", limit.Limit)
+//    }
+//  }
+type ErrDecodeLimit struct {
+	// Limit is the name of the exceeded DecodeOptions field.
+	Limit string
+	// Value is the value of the limit.
+	Value int
+}
+
+// Error implements error.
+func (e *ErrDecodeLimit) Error() string {
+	return fmt.Sprintf("Decode limit %s of %d exceeded", e.Limit, e.Value)
+}
+
 // decodeUnknown "decodes" unsupported data types by returning an error.
 // This decoder will thus always return a DecodeFailure layer.
 func decodeUnknown(data []byte, p PacketBuilder) error {
@@ -0,0 +1,101 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"testing"
+)
+
+// limitTestLayer is an encapsulation with the given contents and payload
+type limitTestLayer struct {
+	contents, payload []byte
+}
+
+func (l *limitTestLayer) LayerType() LayerType     { return LayerTypeFragment }
+func (l *limitTestLayer) LayerContents() []byte    { return l.contents }
+func (l *limitTestLayer) LayerPayload() []byte     { return l.payload }
+func (l *limitTestLayer) CanDecode() LayerClass    { return LayerTypeFragment }
+func (l *limitTestLayer) NextLayerType() LayerType { return LayerTypeFragment }
+
+// decodeNested decodes a one byte header followed by another nested header
+func decodeNested(data []byte, p PacketBuilder) error {
+	p.AddLayer(&limitTestLayer{contents: data[:1], payload: data[1:]})
+	return p.NextDecoder(DecodeFunc(decodeNested))
+}
+
+// decodeLooping decodes the same data again and again
+func decodeLooping(data []byte, p PacketBuilder) error {
+	p.AddLayer(&limitTestLayer{contents: data, payload: data})
+	return p.NextDecoder(DecodeFunc(decodeLooping))
+}
+
+// limitTestNetworkLayer is a network layer with the given contents
+type limitTestNetworkLayer struct {
+	limitTestLayer
+}
+
+func (l *limitTestNetworkLayer) NetworkFlow() Flow { return InvalidFlow }
+
+// decodeNetwork decodes a 20 byte network header
+func decodeNetwork(data []byte, p PacketBuilder) error {
+	l := &limitTestNetworkLayer{limitTestLayer{contents: data[:20], payload: data[20:]}}
+	p.AddLayer(l)
+	p.SetNetworkLayer(l)
+	return nil
+}
+
+func TestDecodeLimits(t *testing.T) {
+	data := make([]byte, 100)
+	tests := []struct {
+		name    string
+		decoder Decoder
+		options DecodeOptions
+		layers  int
+		limit   string
+	}{
+		{"unlimited", DecodeFunc(decodeNested), DecodeOptions{}, 100, ""},
+		{"layers", DecodeFunc(decodeNested), DecodeOptions{MaxLayers: 10}, 10, "MaxLayers"},
+		{"depth", DecodeFunc(decodeNested), DecodeOptions{MaxDecodeDepth: 5}, 5, "MaxDecodeDepth"},
+		{"looping depth", DecodeFunc(decodeLooping), DecodeOptions{MaxDecodeDepth: 20}, 20, "MaxDecodeDepth"},
+		{"looping bytes", DecodeFunc(decodeLooping), DecodeOptions{MaxTotalLayerBytes: 350}, 3, "MaxTotalLayerBytes"},
+	}
+	for _, test := range tests {
+		for _, lazy := range []bool{false, true} {
+			options := test.options
+			options.Lazy = lazy
+			p := NewPacket(data, test.decoder, options)
+			layers := p.Layers()
+			failure := p.ErrorLayer()
+			if test.limit == "" {
+				if len(layers) != test.layers || failure != nil {
+					t.Errorf("%s (lazy %v): expected %d layers and no error, got %d and %v", test.name, lazy, test.layers, len(layers), failure)
+				}
+				continue
+			}
+			if len(layers) != test.layers+1 || failure == nil || layers[test.layers] != failure {
+				t.Errorf("%s (lazy %v): expected %d layers and an error layer, got %d and %v", test.name, lazy, test.layers, len(layers), failure)
+				continue
+			}
+			if err, ok := failure.Error().(*ErrDecodeLimit); !ok || err.Limit != test.limit {
+				t.Errorf("%s (lazy %v): expected %s limit error, got %v", test.name, lazy, test.limit, failure.Error())
+			}
+		}
+	}
+}
+
+func TestDecodeLimitsDroppedLayers(t *testing.T) {
+	data := make([]byte, 100)
+	for _, lazy := range []bool{false, true} {
+		p := NewPacket(data, DecodeFunc(decodeNetwork), DecodeOptions{MaxTotalLayerBytes: 10, Lazy: lazy})
+		if network := p.NetworkLayer(); network != nil {
+			t.Errorf("lazy %v: got network layer %v dropped by the limit", lazy, network)
+		}
+		if failure := p.ErrorLayer(); failure == nil || len(p.Layers()) != 1 || p.Layers()[0] != failure {
+			t.Errorf("lazy %v: expected only the error layer, got %v", lazy, p.Layers())
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// PacketDiff is a difference between two packets, as returned by Diff.
+type PacketDiff struct {
+	// Layer is the index of the differing layer in the packets.
+	Layer int
+	// LayerType is the type of the layer in the first packet, or in the
+	// second packet if the first packet has no layer at this index.
+	LayerType LayerType
+	// Field is the path of the differing field in the layer, e.g. "Seq" or
+	// "Options[1].OptionLength".  It's "Contents" if only the layer contents
+	// differ, and empty if the layers have different types or one of the
+	// packets has no layer at this index.
+	Field string
+	// A and B are the differing values of the first and second packet.
+	A, B string
+	// LayerOffset is the offset of the first differing byte of the layer
+	// contents in the data of the first packet, or -1 if the contents are
+	// equal or aren't part of the packet data.  It's the same for all
+	// differences in a layer and doesn't point at the differing field, since
+	// fields don't know where they were decoded from.
+	LayerOffset int
+}
+
+func (d PacketDiff) String() string {
+	offset := ""
+	if d.LayerOffset >= 0 {
+		offset = fmt.Sprintf(" @%d", d.LayerOffset)
+	}
+	if d.Field == "" {
+		return fmt.Sprintf("layer %d%s: %s != %s", d.Layer, offset, d.A, d.B)
+	}
+	return fmt.Sprintf("layer %d (%v) %s%s: %s != %s", d.Layer, d.LayerType, d.Field, offset, d.A, d.B)
+}
+
+// diffMaxDepth limits the nesting of values compared by Diff, guarding
+// against reference cycles.
+const diffMaxDepth = 16
+
+// Diff compares two packets layer by layer and returns their differences.
+// Layers are compared by their exported fields like LayerString outputs
+// them, recursing into slices and structs, so a difference in a checksum is
+// reported as a difference in field "Checksum".  Layer payloads aren't
+// compared, since they are part of the following layers.  Diff returns nil
+// if the packets are equal.
+//
+// This is useful for testing serializers by decoding, serializing and
+// decoding a packet again, or for finding the changes made to a packet by a
+// NAT or other middlebox.
+func Diff(a, b Packet) []PacketDiff {
+	var diffs []PacketDiff
+	la, lb := a.Layers(), b.Layers()
+	for i := 0; i < len(la) || i < len(lb); i++ {
+		switch {
+		case i >= len(lb):
+			diffs = append(diffs, PacketDiff{Layer: i, LayerType: la[i].LayerType(), A: la[i].LayerType().String(), B: "<none>", LayerOffset: -1})
+			continue
+		case i >= len(la):
+			diffs = append(diffs, PacketDiff{Layer: i, LayerType: lb[i].LayerType(), A: "<none>", B: lb[i].LayerType().String(), LayerOffset: -1})
+			continue
+		case la[i].LayerType() != lb[i].LayerType():
+			diffs = append(diffs, PacketDiff{Layer: i, LayerType: la[i].LayerType(), A: la[i].LayerType().String(), B: lb[i].LayerType().String(), LayerOffset: diffOffset(a, la[i], lb[i])})
+			continue
+		}
+
+		d := layerDiff{layer: i, layerType: la[i].LayerType(), offset: diffOffset(a, la[i], lb[i])}
+		d.value("", reflect.ValueOf(la[i]), reflect.ValueOf(lb[i]), 0)
+		if len(d.diffs) == 0 && !bytes.Equal(la[i].LayerContents(), lb[i].LayerContents()) {
+			d.add("Contents", hex.EncodeToString(la[i].LayerContents()), hex.EncodeToString(lb[i].LayerContents()))
+		}
+		diffs = append(diffs, d.diffs...)
+	}
+	return diffs
+}
+
+// diffOffset returns the offset of the first differing byte of the contents
+// of la and lb in the data of a, or -1
+func diffOffset(a Packet, la, lb Layer) int {
+	ca, cb := la.LayerContents(), lb.LayerContents()
+	i := 0
+	for i < len(ca) && i < len(cb) && ca[i] == cb[i] {
+		i++
+	}
+	if i == len(ca) && i == len(cb) {
+		return -1
+	}
+	start, ok := subsliceOffset(a.Data(), ca)
+	if !ok {
+		return -1
+	}
+	return start + i
+}
+
+// layerDiff collects the differences of a single layer
+type layerDiff struct {
+	layer     int
+	layerType LayerType
+	offset    int
+	diffs     []PacketDiff
+}
+
+func (d *layerDiff) add(field, a, b string) {
+	d.diffs = append(d.diffs, PacketDiff{Layer: d.layer, LayerType: d.layerType, Field: field, A: a, B: b, LayerOffset: d.offset})
+}
+
+// value compares a and b, which have the same type, and records the
+// differences under the field path
+func (d *layerDiff) value(path string, a, b reflect.Value, depth int) {
+	if depth > diffMaxDepth {
+		return
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.add(path, diffString(a), diffString(b))
+			}
+			return
+		}
+		a, b = a.Elem(), b.Elem()
+		if a.Type() != b.Type() {
+			d.add(path, a.Type().String(), b.Type().String())
+			return
+		}
+		d.value(path, a, b, depth)
+	case reflect.Struct:
+		typ := a.Type()
+		for i := 0; i < a.NumField(); i++ {
+			field := typ.Field(i)
+			if field.Anonymous && field.Name == "BaseLayer" {
+				continue
+			}
+			if field.PkgPath != "" && !field.Anonymous { // unexported
+				continue
+			}
+			name := path
+			if !field.Anonymous {
+				if name != "" {
+					name += "."
+				}
+				name += field.Name
+			}
+			d.value(name, a.Field(i), b.Field(i), depth+1)
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Type().Elem().Kind() == reflect.Uint8 {
+			if !bytes.Equal(diffBytes(a), diffBytes(b)) {
+				d.add(path, diffString(a), diffString(b))
+			}
+			return
+		}
+		if a.Len() != b.Len() {
+			d.add(path+".len", strconv.Itoa(a.Len()), strconv.Itoa(b.Len()))
+		}
+		for i := 0; i < a.Len() && i < b.Len(); i++ {
+			d.value(path+"["+strconv.Itoa(i)+"]", a.Index(i), b.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if a.CanInterface() && !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			d.add(path, diffString(a), diffString(b))
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+	default:
+		if diffString(a) != diffString(b) {
+			d.add(path, diffString(a), diffString(b))
+		}
+	}
+}
+
+// diffBytes returns the bytes of a byte slice or array
+func diffBytes(v reflect.Value) []byte {
+	data := make([]byte, v.Len())
+	for i := range data {
+		data[i] = byte(v.Index(i).Uint())
+	}
+	return data
+}
+
+// diffString formats v for a PacketDiff
+func diffString(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return "nil"
+		}
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if !v.CanInterface() {
+				return hex.EncodeToString(diffBytes(v))
+			}
+			if s, ok := v.Interface().(fmt.Stringer); ok {
+				return s.String()
+			}
+			return hex.EncodeToString(diffBytes(v))
+		}
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !v.CanInterface() {
+			return strconv.FormatInt(v.Int(), 10)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !v.CanInterface() {
+			return strconv.FormatUint(v.Uint(), 10)
+		}
+	}
+	if !v.CanInterface() {
+		return v.Type().String()
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,462 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DissectionFormat is the output format of a DissectionExporter.
+type DissectionFormat int
+
+// Output formats of a DissectionExporter
+const (
+	// DissectionJSON is the format of tshark -T json: an array with one object
+	// per packet, holding the protocols under "_source" -> "layers".
+	DissectionJSON DissectionFormat = iota
+	// DissectionPDML is the packet details markup language of tshark -T pdml.
+	DissectionPDML
+)
+
+// DissectionOptions holds options for a DissectionExporter.
+type DissectionOptions struct {
+	// Raw adds the raw bytes of the frame and every protocol to the JSON output
+	// together with their offsets and lengths, like tshark -x.  PDML output
+	// always contains offsets and lengths.
+	Raw bool
+}
+
+// DissectionExporter renders decoded packets in the formats of Wireshark's
+// tshark, so pipelines consuming tshark output can be fed by gopacket.
+//
+// Every layer of a packet becomes a protocol of the tree, positioned at the
+// offset and with the length of its contents in the packet data.  Protocol
+// names follow Wireshark for common layers (e.g. eth, ip, tcp) and are the
+// lower-case layer type names otherwise.  The exported fields of a layer are
+// output as "<protocol>.<lower-case field name>", again with Wireshark names
+// for the most common fields (e.g. ip.src, eth.type).  Nested structs and
+// slices are flattened into dotted names; repeated fields are merged into
+// arrays in JSON output.  Fields carry no offsets, as layers don't record the
+// positions of their fields.
+type DissectionExporter struct {
+	w       io.Writer
+	format  DissectionFormat
+	options DissectionOptions
+	n       int
+	err     error
+}
+
+// NewDissectionExporter returns a new DissectionExporter writing packets in the
+// given format to w.  Close must be called after the last packet to finish the
+// output.
+func NewDissectionExporter(w io.Writer, format DissectionFormat, options DissectionOptions) *DissectionExporter {
+	return &DissectionExporter{
+		w:       w,
+		format:  format,
+		options: options,
+	}
+}
+
+// dissectionNames maps layer type names to Wireshark protocol names
+var dissectionNames = map[string]string{
+	"Ethernet":        "eth",
+	"IPv4":            "ip",
+	"IPv6":            "ipv6",
+	"ICMPv4":          "icmp",
+	"ICMPv6":          "icmpv6",
+	"Dot1Q":           "vlan",
+	"Payload":         "data",
+	"Linux SLL":       "sll",
+	"Loopback":        "null",
+	"DHCPv4":          "dhcp",
+	"DecodeFailure":   "_ws.malformed",
+	"ERSPAN Type II":  "erspan",
+	"ERSPAN Type III": "erspan",
+}
+
+// dissectionFieldNames maps layer fields to Wireshark field names
+var dissectionFieldNames = map[string]string{
+	"eth.SrcMAC":          "eth.src",
+	"eth.DstMAC":          "eth.dst",
+	"eth.EthernetType":    "eth.type",
+	"ip.SrcIP":            "ip.src",
+	"ip.DstIP":            "ip.dst",
+	"ip.Protocol":         "ip.proto",
+	"ip.Length":           "ip.len",
+	"ip.IHL":              "ip.hdr_len",
+	"ip.TOS":              "ip.dsfield",
+	"ip.FragOffset":       "ip.frag_offset",
+	"ipv6.SrcIP":          "ipv6.src",
+	"ipv6.DstIP":          "ipv6.dst",
+	"ipv6.NextHeader":     "ipv6.nxt",
+	"ipv6.HopLimit":       "ipv6.hlim",
+	"ipv6.Length":         "ipv6.plen",
+	"ipv6.FlowLabel":      "ipv6.flow",
+	"tcp.DataOffset":      "tcp.hdr_len",
+	"tcp.Window":          "tcp.window_size_value",
+	"tcp.FIN":             "tcp.flags.fin",
+	"tcp.SYN":             "tcp.flags.syn",
+	"tcp.RST":             "tcp.flags.reset",
+	"tcp.PSH":             "tcp.flags.push",
+	"tcp.ACK":             "tcp.flags.ack",
+	"tcp.URG":             "tcp.flags.urg",
+	"tcp.ECE":             "tcp.flags.ece",
+	"tcp.CWR":             "tcp.flags.cwr",
+	"tcp.NS":              "tcp.flags.ns",
+	"tcp.Urgent":          "tcp.urgent_pointer",
+	"vlan.VLANIdentifier": "vlan.id",
+	"vlan.Type":           "vlan.etype",
+}
+
+// dissectionFieldScales maps Wireshark field names to the factor converting
+// the layer fields to their unit, e.g. header lengths in 32 bit words to
+// bytes
+var dissectionFieldScales = map[string]uint64{
+	"ip.hdr_len":  4,
+	"tcp.hdr_len": 4,
+}
+
+// dissectionField is a field of a dissected protocol
+type dissectionField struct {
+	name, show string
+}
+
+// dissectionProto is a dissected protocol
+type dissectionProto struct {
+	name      string
+	showName  string
+	pos, size int
+	raw       []byte
+	fields    []dissectionField
+}
+
+// dissect builds the protocol tree of p
+func dissect(p Packet, number int) []dissectionProto {
+	data := p.Data()
+	md := p.Metadata()
+	layers := p.Layers()
+
+	length := md.Length
+	if length == 0 {
+		length = len(data)
+	}
+	frame := dissectionProto{
+		name:     "frame",
+		showName: fmt.Sprintf("Frame %d: %d bytes on wire, %d bytes captured", number, length, len(data)),
+		size:     len(data),
+		raw:      data,
+	}
+	var protocols []string
+	protos := []dissectionProto{frame}
+	offset := 0
+	for _, l := range layers {
+		name := dissectionName(l.LayerType())
+		protocols = append(protocols, name)
+		contents := l.LayerContents()
+		pos, ok := subsliceOffset(data, contents)
+		if !ok {
+			pos = offset
+		}
+		if pos+len(contents) <= len(data) {
+			offset = pos + len(contents)
+		}
+		proto := dissectionProto{
+			name:     name,
+			showName: l.LayerType().String(),
+			pos:      pos,
+			size:     len(contents),
+			raw:      contents,
+		}
+		if failure, ok := l.(ErrorLayer); ok && failure.Error() != nil {
+			proto.fields = append(proto.fields, dissectionField{"_ws.expert.message", failure.Error().Error()})
+		} else {
+			v := reflect.ValueOf(l)
+			for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+				if v.IsNil() {
+					break
+				}
+				v = v.Elem()
+			}
+			if v.Kind() == reflect.Struct {
+				proto.fields = dissectFields(proto.fields, name, v, 0)
+			} else if v.IsValid() && v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+				proto.fields = dissectValue(proto.fields, name+".data", v, 0)
+			}
+		}
+		protos = append(protos, proto)
+	}
+	protos[0].fields = []dissectionField{
+		{"frame.interface_id", strconv.Itoa(md.InterfaceIndex)},
+		{"frame.time_epoch", fmt.Sprintf("%d.%09d", md.Timestamp.Unix(), md.Timestamp.Nanosecond())},
+		{"frame.number", strconv.Itoa(number)},
+		{"frame.len", strconv.Itoa(length)},
+		{"frame.cap_len", strconv.Itoa(len(data))},
+		{"frame.protocols", strings.Join(protocols, ":")},
+	}
+	return protos
+}
+
+// subsliceOffset returns the offset of s within data, if s points into data
+func subsliceOffset(data, s []byte) (int, bool) {
+	if len(s) == 0 || len(data) == 0 || cap(s) > cap(data) {
+		return 0, false
+	}
+	pos := cap(data) - cap(s)
+	if pos+len(s) > len(data) || &data[pos] != &s[0] {
+		return 0, false
+	}
+	return pos, true
+}
+
+// dissectionName returns the protocol name of a layer type
+func dissectionName(t LayerType) string {
+	name := t.String()
+	if n, ok := dissectionNames[name]; ok {
+		return n
+	}
+	// protocol names are the prefixes of field names, and can only have
+	// letters, digits, underscores and dashes
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '_'
+	}, name)
+}
+
+// dissectFields appends the exported fields of the struct v
+func dissectFields(fields []dissectionField, prefix string, v reflect.Value, depth int) []dissectionField {
+	typ := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := typ.Field(i)
+		f := v.Field(i)
+		if field.Anonymous {
+			if field.Name == "BaseLayer" {
+				continue
+			}
+			if f.Kind() == reflect.Ptr && !f.IsNil() {
+				f = f.Elem()
+			}
+			if f.Kind() == reflect.Struct {
+				fields = dissectFields(fields, prefix, f, depth)
+				continue
+			}
+		}
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		name, ok := dissectionFieldNames[prefix+"."+field.Name]
+		if !ok {
+			name = prefix + "." + strings.ToLower(field.Name)
+		}
+		if scale, ok := dissectionFieldScales[name]; ok {
+			switch f.Kind() {
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				fields = append(fields, dissectionField{name, strconv.FormatUint(f.Uint()*scale, 10)})
+				continue
+			}
+		}
+		fields = dissectValue(fields, name, f, depth+1)
+	}
+	return fields
+}
+
+// dissectValue appends v as field name; structs and slices are flattened
+func dissectValue(fields []dissectionField, name string, v reflect.Value, depth int) []dissectionField {
+	if depth > jsonMaxDepth {
+		return fields
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return fields
+		}
+		return dissectValue(fields, name, v.Elem(), depth)
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Map, reflect.Invalid:
+		return fields
+	}
+	if v.Type().Implements(jsonTextMarshalerType) && v.CanInterface() {
+		if data, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil && len(data) > 0 {
+			fields = append(fields, dissectionField{name, string(data)})
+		}
+		return fields
+	}
+	if v.Type() == jsonHardwareAddrType && v.CanInterface() {
+		return append(fields, dissectionField{name, v.Interface().(net.HardwareAddr).String()})
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		show := "0"
+		if v.Bool() {
+			show = "1"
+		}
+		fields = append(fields, dissectionField{name, show})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fields = append(fields, dissectionField{name, strconv.FormatInt(v.Int(), 10)})
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		fields = append(fields, dissectionField{name, strconv.FormatUint(v.Uint(), 10)})
+	case reflect.Float32, reflect.Float64:
+		fields = append(fields, dissectionField{name, strconv.FormatFloat(v.Float(), 'g', -1, 64)})
+	case reflect.String:
+		fields = append(fields, dissectionField{name, v.String()})
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Len() == 0 {
+				return fields
+			}
+			var b strings.Builder
+			for i := 0; i < v.Len(); i++ {
+				if i > 0 {
+					b.WriteByte(':')
+				}
+				fmt.Fprintf(&b, "%02x", v.Index(i).Uint())
+			}
+			return append(fields, dissectionField{name, b.String()})
+		}
+		for i := 0; i < v.Len(); i++ {
+			fields = dissectValue(fields, name, v.Index(i), depth+1)
+		}
+	case reflect.Struct:
+		fields = dissectFields(fields, name, v, depth)
+	}
+	return fields
+}
+
+// WritePacket writes the dissection of the given packet.
+func (e *DissectionExporter) WritePacket(p Packet) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.n++
+	protos := dissect(p, e.n)
+	var b bytes.Buffer
+	if e.format == DissectionPDML {
+		e.writePDML(&b, protos)
+	} else {
+		e.writeJSON(&b, protos, p)
+	}
+	_, e.err = e.w.Write(b.Bytes())
+	return e.err
+}
+
+func (e *DissectionExporter) writeJSON(b *bytes.Buffer, protos []dissectionProto, p Packet) {
+	if e.n == 1 {
+		b.WriteString("[\n")
+	} else {
+		b.WriteString(",\n")
+	}
+	fmt.Fprintf(b, "  {\n    \"_index\": \"packets-%s\",\n    \"_type\": \"doc\",\n    \"_score\": null,\n    \"_source\": {\n      \"layers\": {",
+		p.Metadata().Timestamp.UTC().Format("2006-01-02"))
+	names := map[string]bool{}
+	for i, proto := range protos {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString("\n        ")
+		if e.options.Raw {
+			jsonString(b, proto.name+"_raw")
+			fmt.Fprintf(b, ": [\"%s\", %d, %d, 0, 1],\n        ", hex.EncodeToString(proto.raw), proto.pos, proto.size)
+		}
+		name := proto.name
+		if names[name] {
+			// repeated protocols, e.g. IP in IP
+			name = fmt.Sprintf("%s_%d", name, i)
+		}
+		names[proto.name] = true
+		jsonString(b, name)
+		b.WriteString(": {")
+		writeDissectionFieldsJSON(b, proto.fields, "          ")
+		b.WriteString("\n        }")
+	}
+	b.WriteString("\n      }\n    }\n  }")
+}
+
+// writeDissectionFieldsJSON writes fields as object members, merging repeated fields into arrays
+func writeDissectionFieldsJSON(b *bytes.Buffer, fields []dissectionField, indent string) {
+	var order []string
+	values := map[string][]string{}
+	for _, f := range fields {
+		if _, ok := values[f.name]; !ok {
+			order = append(order, f.name)
+		}
+		values[f.name] = append(values[f.name], f.show)
+	}
+	for i, name := range order {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString("\n")
+		b.WriteString(indent)
+		jsonString(b, name)
+		b.WriteString(": ")
+		if v := values[name]; len(v) == 1 {
+			jsonString(b, v[0])
+		} else {
+			b.WriteByte('[')
+			for j, s := range v {
+				if j > 0 {
+					b.WriteString(", ")
+				}
+				jsonString(b, s)
+			}
+			b.WriteByte(']')
+		}
+	}
+}
+
+func (e *DissectionExporter) writePDML(b *bytes.Buffer, protos []dissectionProto) {
+	if e.n == 1 {
+		b.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<pdml version=\"0\" creator=\"gopacket\">\n")
+	}
+	b.WriteString("<packet>\n")
+	for _, proto := range protos {
+		fmt.Fprintf(b, "  <proto name=\"%s\" showname=\"%s\" size=\"%d\" pos=\"%d\">\n", xmlEscape(proto.name), xmlEscape(proto.showName), proto.size, proto.pos)
+		for _, f := range proto.fields {
+			fmt.Fprintf(b, "    <field name=\"%s\" show=\"%s\"/>\n", xmlEscape(f.name), xmlEscape(f.show))
+		}
+		b.WriteString("  </proto>\n")
+	}
+	b.WriteString("</packet>\n")
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// Close finishes the output.  The underlying writer is not closed.
+func (e *DissectionExporter) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	var end string
+	switch {
+	case e.format == DissectionPDML && e.n == 0:
+		end = "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<pdml version=\"0\" creator=\"gopacket\">\n</pdml>\n"
+	case e.format == DissectionPDML:
+		end = "</pdml>\n"
+	case e.n == 0:
+		end = "[\n]\n"
+	default:
+		end = "\n]\n"
+	}
+	_, e.err = io.WriteString(e.w, end)
+	return e.err
+}
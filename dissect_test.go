@@ -0,0 +1,29 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"testing"
+)
+
+var layerTypeDissectTest = RegisterLayerType(1992, LayerTypeMetadata{Name: "Dissect Test/v2.1", Decoder: DecodeFunc(decodePayload)})
+
+func TestDissectionName(t *testing.T) {
+	for _, test := range []struct {
+		layerType LayerType
+		want      string
+	}{
+		{LayerTypePayload, "data"},
+		{LayerTypeDecodeFailure, "_ws.malformed"},
+		{LayerTypeFragment, "fragment"},
+		{layerTypeDissectTest, "dissect_test_v2_1"},
+	} {
+		if got := dissectionName(test.layerType); got != test.want {
+			t.Errorf("%v: got %q, want %q", test.layerType, got, test.want)
+		}
+	}
+}
@@ -46,6 +46,13 @@ can be requested from the packet.
    fmt.Println("PACKET LAYER:", layer.LayerType())
  }
 
+With go 1.18 or later, LayerOf returns a layer with its concrete type, which
+avoids the type assertion.
+
+ if tcp := gopacket.LayerOf[*layers.TCP](packet); tcp != nil {
+   fmt.Printf("From src port %d to dst port %d\n", tcp.SrcPort, tcp.DstPort)
+ }
+
 Packets can be decoded from a number of starting points.  Many of our base
 types implement Decoder, which allow us to decode packets for which
 we don't have full data.
@@ -82,6 +89,13 @@ end-of-file.
     handlePacket(packet)  // do something with each packet
   }
 
+With Go 1.23 or later, the All function returns an iterator reading packets
+in the ranging goroutine, without an additional goroutine and channel:
+
+  for packet, err := range gopacket.All(packetSource) {
+    ...
+  }
+
 You can change the decoding options of the packetSource by setting fields in
 packetSource.DecodeOptions... see the following sections for more details.
 
@@ -129,6 +143,17 @@ The fastest method of decoding is to use both Lazy and NoCopy, but note from
 the many caveats above that for some implementations either or both may be
 dangerous.
 
+At very high packet rates, a PacketPool avoids allocating a new packet for
+every call by recycling packets once they're no longer used.
+
+ pool := gopacket.NewPacketPool()
+ for data := range myByteSliceChannel {
+   p := pool.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+   doSomethingWithPacket(p)
+   // p and its layers must not be used after this.
+   gopacket.RecyclePacket(p)
+ }
+
 
 Pointers To Known Layers
 
@@ -315,6 +340,14 @@ udp) stack will stop decoding after ip4, and only pass back [LayerTypeEthernet,
 LayerTypeIPv4] through the 'decoded' slice (along with an error saying it can't
 decode a UDP packet).
 
+Instead of iterating the decoded slice, callbacks may be registered with
+OnLayer, which are called as soon as a layer of their type has been decoded:
+
+ parser.OnLayer(layers.LayerTypeTCP, func(gopacket.DecodingLayer, []gopacket.LayerType) error {
+   fmt.Println("    TCP ", tcp.SrcPort, tcp.DstPort)
+   return nil
+ })
+
 Unfortunately, not all layers can be used by DecodingLayerParser... only those
 implementing the DecodingLayer interface are usable.  Also, it's possible to
 create DecodingLayers that are not themselves Layers... see
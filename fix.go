@@ -0,0 +1,72 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+// checksumNetworkLayerSetter is implemented by layers whose checksum covers a
+// pseudo header of the network layer, like layers.TCP and layers.UDP.
+type checksumNetworkLayerSetter interface {
+	SetNetworkLayerForChecksum(NetworkLayer) error
+}
+
+// rawLayer serializes a layer that isn't a SerializableLayer as its contents
+type rawLayer struct {
+	Layer
+}
+
+func (r rawLayer) SerializeTo(b SerializeBuffer, opts SerializeOptions) error {
+	contents := r.LayerContents()
+	bytes, err := b.PrependBytes(len(contents))
+	if err != nil {
+		return err
+	}
+	copy(bytes, contents)
+	return nil
+}
+
+// FixLayers recomputes the length fields and checksums of all layers of p
+// after their fields have been modified, e.g. by a packet rewriting tool, and
+// returns the resulting packet data.  The packet is serialized with
+// SerializeOptions FixLengths and ComputeChecksums, after telling every
+// layer with a pseudo header checksum (TCP, UDP, ICMPv6) its network layer,
+// so the quirks of the individual layers don't need to be known.  The layers
+// of p are updated in place by their SerializeTo methods, so their length
+// and checksum fields hold the fixed values afterwards.  Their contents and
+// payloads aren't updated; decode the returned data to get a consistent
+// packet.
+//
+// Layers that aren't SerializableLayers, like DecodeFailure, are written as
+// their unmodified contents.
+func FixLayers(p Packet) ([]byte, error) {
+	buf := NewSerializeBuffer()
+	if err := FixLayersTo(buf, p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FixLayersTo is like FixLayers, but writes the fixed packet to buf.
+func FixLayersTo(buf SerializeBuffer, p Packet) error {
+	layers := p.Layers()
+	serializable := make([]SerializableLayer, len(layers))
+	var network NetworkLayer
+	for i, l := range layers {
+		if c, ok := l.(checksumNetworkLayerSetter); ok && network != nil {
+			if err := c.SetNetworkLayerForChecksum(network); err != nil {
+				return err
+			}
+		}
+		if n, ok := l.(NetworkLayer); ok {
+			network = n
+		}
+		if s, ok := l.(SerializableLayer); ok {
+			serializable[i] = s
+		} else {
+			serializable[i] = rawLayer{l}
+		}
+	}
+	return SerializeLayers(buf, SerializeOptions{FixLengths: true, ComputeChecksums: true}, serializable...)
+}
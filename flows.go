@@ -8,6 +8,7 @@ package gopacket
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"strconv"
 )
@@ -24,15 +25,22 @@ import (
 //
 // The end result of this, though, is that an endpoint/flow can't be created
 // using more than MaxEndpointSize bytes per address.
+//
+// Endpoints and flows are small comparable values that don't reference any
+// memory, so creating, copying, comparing and hashing them doesn't allocate.
+// Only Raw and String return newly allocated memory; AppendRaw can be used to
+// get the raw bytes without allocation.
 const MaxEndpointSize = 16
 
 // Endpoint is the set of bytes used to address packets at various layers.
 // See LinkLayer, NetworkLayer, and TransportLayer specifications.
 // Endpoints are usable as map keys.
 type Endpoint struct {
+	// the small length goes last, so there's only padding at the end and an
+	// Endpoint takes 32 bytes on 64 bit platforms
 	typ EndpointType
-	len int
 	raw [MaxEndpointSize]byte
+	len uint8
 }
 
 // EndpointType returns the endpoint type associated with this endpoint.
@@ -42,6 +50,14 @@ func (a Endpoint) EndpointType() EndpointType { return a.typ }
 // most of the time, but they are faster than calling String.
 func (a Endpoint) Raw() []byte { return a.raw[:a.len] }
 
+// AppendRaw appends the raw bytes of this endpoint to b and returns the
+// resulting slice.  In contrast to Raw, it doesn't allocate if b has enough
+// capacity.
+func (a Endpoint) AppendRaw(b []byte) []byte { return append(b, a.raw[:a.len]...) }
+
+// Len returns the number of raw bytes of this endpoint.
+func (a Endpoint) Len() int { return int(a.len) }
+
 // LessThan provides a stable ordering for all endpoints.  It sorts first based
 // on the EndpointType of an endpoint, then based on the raw bytes of that
 // endpoint.
@@ -54,15 +70,22 @@ func (a Endpoint) LessThan(b Endpoint) bool {
 	return a.typ < b.typ || (a.typ == b.typ && bytes.Compare(a.raw[:a.len], b.raw[:b.len]) < 0)
 }
 
-// fnvHash is used by our FastHash functions, and implements the FNV hash
-// created by Glenn Fowler, Landon Curt Noll, and Phong Vo.
+// fnvHash is used by our FastHash functions, and implements a variant of the
+// FNV hash created by Glenn Fowler, Landon Curt Noll, and Phong Vo, which
+// processes the raw bytes of an endpoint as two 8 byte words.  This relies on
+// the bytes after the first n bytes of raw being zero, which is ensured by
+// NewEndpoint, NewFlow and their friends.  Since multiplying only spreads the
+// bits of the input to higher bits of the hash, the high half of the result is
+// folded into the low half, so that the low bits used by modulo based
+// load-balancing depend on all bytes.
 // See http://isthe.com/chongo/tech/comp/fnv/.
-func fnvHash(s []byte) (h uint64) {
-	h = fnvBasis
-	for i := 0; i < len(s); i++ {
-		h ^= uint64(s[i])
-		h *= fnvPrime
-	}
+func fnvHash(raw *[MaxEndpointSize]byte, n uint8) (h uint64) {
+	h = fnvBasis ^ uint64(n)
+	h ^= binary.LittleEndian.Uint64(raw[:8])
+	h *= fnvPrime
+	h ^= binary.LittleEndian.Uint64(raw[8:])
+	h *= fnvPrime
+	h ^= h >> 32
 	return
 }
 
@@ -76,7 +99,7 @@ const fnvPrime = 1099511628211
 // The output of FastHash is not guaranteed to remain the same through future
 // code revisions, so should not be used to key values in persistent storage.
 func (a Endpoint) FastHash() (h uint64) {
-	h = fnvHash(a.raw[:a.len])
+	h = fnvHash(&a.raw, a.len)
 	h ^= uint64(a.typ)
 	h *= fnvPrime
 	return
@@ -87,10 +110,10 @@ func (a Endpoint) FastHash() (h uint64) {
 // The size of raw must be less than MaxEndpointSize, otherwise this function
 // will panic.
 func NewEndpoint(typ EndpointType, raw []byte) (e Endpoint) {
-	e.len = len(raw)
-	if e.len > MaxEndpointSize {
+	if len(raw) > MaxEndpointSize {
 		panic("raw byte length greater than MaxEndpointSize")
 	}
+	e.len = uint8(len(raw))
 	e.typ = typ
 	copy(e.raw[:], raw)
 	return
@@ -140,9 +163,11 @@ func (a Endpoint) String() string {
 // Flow represents the direction of traffic for a packet layer, as a source and destination Endpoint.
 // Flows are usable as map keys.
 type Flow struct {
+	// like in Endpoint, the lengths go last, so a Flow takes 48 bytes on 64
+	// bit platforms
 	typ        EndpointType
-	slen, dlen int
 	src, dst   [MaxEndpointSize]byte
+	slen, dlen uint8
 }
 
 // FlowFromEndpoints creates a new flow by pasting together two endpoints.
@@ -153,7 +178,7 @@ func FlowFromEndpoints(src, dst Endpoint) (_ Flow, err error) {
 		err = fmt.Errorf("Mismatched endpoint types: %v->%v", src.typ, dst.typ)
 		return
 	}
-	return Flow{src.typ, src.len, dst.len, src.raw, dst.raw}, nil
+	return Flow{src.typ, src.raw, dst.raw, src.len, dst.len}, nil
 }
 
 // FastHash provides a quick hashing function for a flow, useful if you'd
@@ -167,7 +192,7 @@ func FlowFromEndpoints(src, dst Endpoint) (_ Flow, err error) {
 func (f Flow) FastHash() (h uint64) {
 	// This combination must be commutative.  We don't use ^, since that would
 	// give the same hash for all A->A flows.
-	h = fnvHash(f.src[:f.slen]) + fnvHash(f.dst[:f.dlen])
+	h = fnvHash(&f.src, f.slen) + fnvHash(&f.dst, f.dlen)
 	h ^= uint64(f.typ)
 	h *= fnvPrime
 	return
@@ -177,7 +202,7 @@ func (f Flow) FastHash() (h uint64) {
 // "Src->Dst"
 func (f Flow) String() string {
 	s, d := f.Endpoints()
-	return fmt.Sprintf("%v->%v", s, d)
+	return s.String() + "->" + d.String()
 }
 
 // EndpointType returns the EndpointType for this Flow.
@@ -187,7 +212,7 @@ func (f Flow) EndpointType() EndpointType {
 
 // Endpoints returns the two Endpoints for this flow.
 func (f Flow) Endpoints() (src, dst Endpoint) {
-	return Endpoint{f.typ, f.slen, f.src}, Endpoint{f.typ, f.dlen, f.dst}
+	return Endpoint{f.typ, f.src, f.slen}, Endpoint{f.typ, f.dst, f.dlen}
 }
 
 // Src returns the source Endpoint for this flow.
@@ -204,7 +229,7 @@ func (f Flow) Dst() (dst Endpoint) {
 
 // Reverse returns a new flow with endpoints reversed.
 func (f Flow) Reverse() Flow {
-	return Flow{f.typ, f.dlen, f.slen, f.dst, f.src}
+	return Flow{f.typ, f.dst, f.src, f.dlen, f.slen}
 }
 
 // NewFlow creates a new flow.
@@ -212,11 +237,11 @@ func (f Flow) Reverse() Flow {
 // src and dst must have length <= MaxEndpointSize, otherwise NewFlow will
 // panic.
 func NewFlow(t EndpointType, src, dst []byte) (f Flow) {
-	f.slen = len(src)
-	f.dlen = len(dst)
-	if f.slen > MaxEndpointSize || f.dlen > MaxEndpointSize {
+	if len(src) > MaxEndpointSize || len(dst) > MaxEndpointSize {
 		panic("flow raw byte length greater than MaxEndpointSize")
 	}
+	f.slen = uint8(len(src))
+	f.dlen = uint8(len(dst))
 	f.typ = t
 	copy(f.src[:], src)
 	copy(f.dst[:], dst)
@@ -0,0 +1,77 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+)
+
+func TestFlowSize(t *testing.T) {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		t.Skip("sizes are only checked on 64 bit platforms")
+	}
+	if s := unsafe.Sizeof(Endpoint{}); s != 32 {
+		t.Errorf("expected Endpoint to take 32 bytes, got %d", s)
+	}
+	if s := unsafe.Sizeof(Flow{}); s != 48 {
+		t.Errorf("expected Flow to take 48 bytes, got %d", s)
+	}
+}
+
+func TestFlowAllocs(t *testing.T) {
+	src, dst := []byte{10, 0, 0, 1}, []byte{10, 0, 0, 2}
+	flows := map[Flow]int{}
+	buf := make([]byte, 0, MaxEndpointSize)
+	var hash uint64
+	allocs := testing.AllocsPerRun(100, func() {
+		f := NewFlow(EndpointInvalid, src, dst)
+		flows[f]++
+		flows[f.Reverse()]++
+		s, d := f.Endpoints()
+		hash += f.FastHash() + s.FastHash() + d.FastHash()
+		buf = s.AppendRaw(buf[:0])
+		if !s.LessThan(d) || !bytes.Equal(buf, src) {
+			t.Fatal("unexpected endpoints")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+	if len(flows) != 2 {
+		t.Errorf("expected 2 flows, got %d", len(flows))
+	}
+}
+
+func TestFlowFastHash(t *testing.T) {
+	a, b := []byte{10, 0, 0, 1}, []byte{192, 168, 1, 1}
+	f := NewFlow(EndpointInvalid, a, b)
+	if f.FastHash() != f.Reverse().FastHash() {
+		t.Error("flow hash differs from hash of its reverse flow")
+	}
+	if NewEndpoint(EndpointInvalid, []byte{0}).FastHash() == NewEndpoint(EndpointInvalid, []byte{0, 0}).FastHash() {
+		t.Error("endpoints of different lengths collide")
+	}
+
+	// the low bits used for load-balancing depend on all bytes
+	for i := 0; i < 4; i++ {
+		const buckets = 16
+		var counts [buckets]int
+		for j := 0; j < 256*buckets; j++ {
+			raw := []byte{10, 0, 0, 0}
+			raw[i] = byte(j)
+			raw[(i+1)%4] = byte(j >> 8)
+			counts[NewEndpoint(EndpointInvalid, raw).FastHash()%buckets]++
+		}
+		for bucket, count := range counts {
+			if count < 128 || count > 384 {
+				t.Errorf("byte %d: bucket %d has %d of %d endpoints", i, bucket, count, 256*buckets)
+			}
+		}
+	}
+}
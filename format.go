@@ -0,0 +1,201 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Verbosity is the level of detail of FormatPacket and FormatLayer.
+type Verbosity int
+
+const (
+	// VerbositySummary formats a packet as a single line like tcpdump, e.g.
+	//   12:00:00.000000 IPv4 10.0.0.1.1234 > 10.0.0.2.80: Flags [S], seq 0, win 8192, length 0
+	// and a layer as a short description without addresses.
+	VerbositySummary Verbosity = iota
+	// VerbosityFields formats a packet with one line per layer listing its
+	// fields, like Packet.String, and a layer like LayerString.
+	VerbosityFields
+	// VerbosityDump formats a packet like Packet.Dump, and a layer like
+	// LayerDump, with hex dumps of the data.
+	VerbosityDump
+)
+
+// LayerFormatter formats a layer with the given verbosity.  It returns "" to
+// format the layer the default way.  Formatters for VerbosityDump only
+// replace the description of the layer preceding its hex dump.
+type LayerFormatter func(l Layer, v Verbosity) string
+
+// Formatter registrations replace the map, so that formatters can be
+// registered while packets are formatted.
+var layerFormattersMu sync.Mutex
+
+var layerFormatters atomic.Value // map[LayerType]LayerFormatter
+
+// RegisterLayerFormatter registers f as the formatter of layers of type t
+// used by FormatLayer and FormatPacket, replacing a previously registered
+// one; a nil f removes it.  It may be called while packets are formatted by
+// other goroutines.  The layers package registers summary formatters for
+// common layers like TCP.
+func RegisterLayerFormatter(t LayerType, f LayerFormatter) {
+	layerFormattersMu.Lock()
+	defer layerFormattersMu.Unlock()
+	old, _ := layerFormatters.Load().(map[LayerType]LayerFormatter)
+	m := make(map[LayerType]LayerFormatter, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	if f == nil {
+		delete(m, t)
+	} else {
+		m[t] = f
+	}
+	layerFormatters.Store(m)
+}
+
+// formatLayer formats l with the registered formatter, returning "" if there
+// is none or it returns ""
+func formatLayer(l Layer, v Verbosity) string {
+	m, _ := layerFormatters.Load().(map[LayerType]LayerFormatter)
+	if f := m[l.LayerType()]; f != nil {
+		return f(l, v)
+	}
+	return ""
+}
+
+// FormatLayer formats l with the given verbosity using its registered
+// formatter.  Without one, summaries are the name of the layer type, and the
+// other verbosities are like LayerString and LayerDump.
+func FormatLayer(l Layer, v Verbosity) string {
+	s := formatLayer(l, v)
+	switch v {
+	case VerbositySummary:
+		if s == "" {
+			s = l.LayerType().String()
+		}
+	case VerbosityFields:
+		if s == "" {
+			s = LayerString(l)
+		}
+	default:
+		if s == "" {
+			return LayerDump(l)
+		}
+		s = strings.TrimSuffix(s, "\n") + "\n" + hex.Dump(l.LayerContents())
+	}
+	return s
+}
+
+// FormatPacket formats p with the given verbosity, using the registered layer
+// formatters.  Summaries of packets with a network layer have the form
+// "<timestamp> <network layer type> <source> > <destination>: <summary>,
+// length <n>", where the addresses include the ports of the transport layer,
+// the summary is the one of the transport layer, or of the layer following
+// the network layer, and n is the length of its payload.  Summaries of other
+// packets are the ones of the layer following the link layer.  Decoding
+// errors and truncation are noted at the end.  Only summaries are a single
+// line without a trailing newline.
+func FormatPacket(p Packet, v Verbosity) string {
+	if v == VerbositySummary {
+		return packetSummary(p)
+	}
+	var b bytes.Buffer
+	layers := p.Layers()
+	md := p.Metadata()
+	if v == VerbosityDump {
+		fmt.Fprintf(&b, "-- FULL PACKET DATA (%d bytes) ------------------------------------\n%s", len(p.Data()), hex.Dump(p.Data()))
+		for i, l := range layers {
+			fmt.Fprintf(&b, "--- Layer %d ---\n%s", i+1, FormatLayer(l, v))
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, "PACKET: %d bytes", len(p.Data()))
+	if md.Truncated {
+		b.WriteString(", truncated")
+	}
+	if md.Length > 0 {
+		fmt.Fprintf(&b, ", wire length %d cap length %d", md.Length, md.CaptureLength)
+	}
+	if !md.Timestamp.IsZero() {
+		fmt.Fprintf(&b, " @ %v", md.Timestamp)
+	}
+	b.WriteByte('\n')
+	for i, l := range layers {
+		fmt.Fprintf(&b, "- Layer %d (%02d bytes) = %s\n", i+1, len(l.LayerContents()), FormatLayer(l, v))
+	}
+	return b.String()
+}
+
+func packetSummary(p Packet) string {
+	var b strings.Builder
+	if ts := p.Metadata().Timestamp; !ts.IsZero() {
+		b.WriteString(ts.Format("15:04:05.000000 "))
+	}
+	layers := p.Layers()
+	// index of the layer described by the summary
+	i := -1
+	if network := p.NetworkLayer(); network != nil {
+		i = layerIndex(layers, network)
+		src, dst := network.NetworkFlow().Endpoints()
+		srcStr, dstStr := endpointString(src), endpointString(dst)
+		if transport := p.TransportLayer(); i >= 0 && transport != nil {
+			// transport layers which failed to decode have no ports
+			sport, dport := transport.TransportFlow().Endpoints()
+			if j := layerIndex(layers, transport); j > i && sport.Len() > 0 && dport.Len() > 0 {
+				i = j
+				srcStr += "." + sport.String()
+				dstStr += "." + dport.String()
+			}
+		} else if i >= 0 && i+1 < len(layers) && layers[i+1] != p.ErrorLayer() {
+			i++
+		}
+		fmt.Fprintf(&b, "%v %s > %s", network.LayerType(), srcStr, dstStr)
+		if i >= 0 && layers[i] != network {
+			l := layers[i]
+			fmt.Fprintf(&b, ": %s, length %d", FormatLayer(l, VerbositySummary), len(l.LayerPayload()))
+		} else {
+			fmt.Fprintf(&b, ", length %d", len(network.LayerPayload()))
+		}
+	} else if len(layers) > 0 {
+		i = 0
+		if p.LinkLayer() != nil && len(layers) > 1 && layers[1] != p.ErrorLayer() {
+			i = 1
+		}
+		b.WriteString(FormatLayer(layers[i], VerbositySummary))
+	}
+	if e := p.ErrorLayer(); e != nil {
+		fmt.Fprintf(&b, " [decode error: %v]", e.Error())
+	} else if p.Metadata().Truncated {
+		b.WriteString(" [truncated]")
+	}
+	return b.String()
+}
+
+// endpointString returns e as a string, or "?" if e is empty because its
+// layer failed to decode
+func endpointString(e Endpoint) string {
+	if e.Len() == 0 {
+		return "?"
+	}
+	return e.String()
+}
+
+// layerIndex returns the index of l in layers, or -1
+func layerIndex(layers []Layer, l Layer) int {
+	for i, m := range layers {
+		if m == l {
+			return i
+		}
+	}
+	return -1
+}
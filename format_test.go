@@ -0,0 +1,66 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatPacket(t *testing.T) {
+	p := NewPacket([]byte{1, 2, 3}, DecodePayload, Default)
+	p.Metadata().Timestamp = time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC)
+	if got, want := FormatPacket(p, VerbosityFields), p.String(); got != want {
+		t.Errorf("fields: got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := FormatPacket(p, VerbosityDump), p.Dump(); got != want {
+		t.Errorf("dump: got\n%s\nwant\n%s", got, want)
+	}
+	if got, want := FormatPacket(p, VerbositySummary), "03:04:05.000006 Payload"; got != want {
+		t.Errorf("summary: got %q, want %q", got, want)
+	}
+}
+
+func TestRegisterLayerFormatter(t *testing.T) {
+	RegisterLayerFormatter(LayerTypePayload, func(l Layer, v Verbosity) string {
+		if v == VerbosityDump {
+			return ""
+		}
+		return "payload of " + string(rune('0'+len(l.LayerContents()))) + " bytes"
+	})
+	defer RegisterLayerFormatter(LayerTypePayload, nil)
+
+	p := NewPacket([]byte{1, 2, 3}, DecodePayload, Default)
+	if got, want := FormatPacket(p, VerbositySummary), "payload of 3 bytes"; got != want {
+		t.Errorf("summary: got %q, want %q", got, want)
+	}
+	if got := FormatPacket(p, VerbosityFields); !strings.Contains(got, "= payload of 3 bytes\n") {
+		t.Errorf("fields: got %q", got)
+	}
+	if got, want := FormatPacket(p, VerbosityDump), p.Dump(); got != want {
+		t.Errorf("dump: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegisterLayerFormatterConcurrent(t *testing.T) {
+	p := NewPacket([]byte{1, 2, 3}, DecodePayload, Default)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			RegisterLayerFormatter(LayerTypeFragment, func(l Layer, v Verbosity) string { return "" })
+		}
+		RegisterLayerFormatter(LayerTypeFragment, nil)
+	}()
+	for i := 0; i < 100; i++ {
+		if got := FormatPacket(p, VerbositySummary); !strings.HasSuffix(got, "Payload") {
+			t.Fatalf("summary: got %q", got)
+		}
+	}
+	<-done
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+//go:build go1.18
+// +build go1.18
+
+package gopackettest
+
+import (
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// FuzzDecodingLayer fuzzes the DecodeFromBytes and SerializeTo methods of
+// the layers returned by newLayer, starting with the seeds.  Every input is
+// checked with CheckDecode.  If it decodes and the layer is a
+// gopacket.SerializableLayer, serializing it with fixed lengths must not
+// panic, and if it succeeds, the layer must be able to decode what it
+// serialized.
+func FuzzDecodingLayer(f *testing.F, newLayer func() gopacket.DecodingLayer, seeds ...[]byte) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		l := newLayer()
+		if CheckDecode(t, l, data) != nil {
+			return
+		}
+		s, ok := l.(gopacket.SerializableLayer)
+		if !ok {
+			return
+		}
+		serialized, err := serialize(s, l.LayerPayload(), gopacket.SerializeOptions{FixLengths: true})
+		if panicked(err) {
+			t.Errorf("%T.SerializeTo after decoding %x: %v", l, data, err)
+		}
+		if err != nil {
+			// layers may refuse to serialize the odd values of fuzzed data
+			return
+		}
+		if err := CheckDecode(t, newLayer(), serialized); err != nil {
+			t.Errorf("%T can't decode %x serialized from %x: %v", l, serialized, data, err)
+		}
+	})
+}
+
+// FuzzDecoder fuzzes decoding packets starting with decoder, which is
+// usually a registered gopacket.LayerType, starting with the seeds.  Every
+// input is checked with CheckPacket.
+func FuzzDecoder(f *testing.F, decoder gopacket.Decoder, seeds ...[]byte) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		CheckPacket(t, data, decoder)
+	})
+}
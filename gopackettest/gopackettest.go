@@ -0,0 +1,196 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+// Package gopackettest provides helpers for testing gopacket layers, which
+// give layers implemented outside of gopacket the same coverage as the
+// layers in the layers package:
+//
+//	func TestMyProto(t *testing.T) {
+//	  gopackettest.CheckDecode(t, &MyProto{}, testPacketMyProto)
+//	  gopackettest.CheckTruncations(t, &MyProto{}, testPacketMyProto)
+//	  gopackettest.CheckRoundTrip(t, &MyProto{}, testPacketMyProto)
+//	  gopackettest.CheckDecodeAllocs(t, &MyProto{}, testPacketMyProto, 0)
+//	}
+//
+//	func FuzzMyProto(f *testing.F) {
+//	  gopackettest.FuzzDecodingLayer(f, func() gopacket.DecodingLayer { return &MyProto{} }, testPacketMyProto)
+//	}
+//
+// The checks report problems with t.Errorf, so all of them run even if one
+// fails.
+package gopackettest
+
+import (
+	"bytes"
+	"fmt"
+	"runtime/debug"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// panicError is the error returned for a recovered panic.
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.value, e.stack)
+}
+
+// recoverPanic sets *err to a *panicError if the calling function panicked.
+// It has to be deferred.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &panicError{value: r, stack: debug.Stack()}
+	}
+}
+
+// panicked returns true if err is a recovered panic
+func panicked(err error) bool {
+	_, ok := err.(*panicError)
+	return ok
+}
+
+// decode decodes data with l, turning panics into errors.  data is passed
+// with its capacity limited to its length, so reading beyond it panics
+// instead of silently reading whatever follows in memory.
+func decode(l gopacket.DecodingLayer, data []byte) (err error) {
+	defer recoverPanic(&err)
+	return l.DecodeFromBytes(data[:len(data):len(data)], gopacket.NilDecodeFeedback)
+}
+
+// CheckDecode decodes data with l and returns the decoding error.  It
+// reports an error if l panics, reads beyond data, modifies data, or
+// returns more contents and payload than data holds.
+func CheckDecode(t testing.TB, l gopacket.DecodingLayer, data []byte) error {
+	t.Helper()
+	in := make([]byte, len(data))
+	copy(in, data)
+	err := decode(l, in)
+	if panicked(err) {
+		t.Errorf("%T.DecodeFromBytes(%x): %v", l, data, err)
+		return err
+	}
+	if !bytes.Equal(in, data) {
+		t.Errorf("%T.DecodeFromBytes(%x) modified its input to %x", l, data, in)
+	}
+	if err != nil {
+		return err
+	}
+	if layer, ok := l.(gopacket.Layer); ok {
+		if n := len(layer.LayerContents()) + len(layer.LayerPayload()); n > len(data) {
+			t.Errorf("%T.DecodeFromBytes(%x): contents and payload have %d bytes, but data only %d", l, data, n, len(data))
+		}
+	}
+	return nil
+}
+
+// CheckTruncations decodes every prefix of data with l, reporting an error
+// if l panics, reads beyond the prefix or modifies it.
+func CheckTruncations(t testing.TB, l gopacket.DecodingLayer, data []byte) {
+	t.Helper()
+	for i := 0; i < len(data); i++ {
+		in := make([]byte, i)
+		copy(in, data)
+		if err := decode(l, in); panicked(err) {
+			t.Errorf("%T.DecodeFromBytes(%x) truncated to %d bytes: %v", l, data, i, err)
+		} else if !bytes.Equal(in, data[:i]) {
+			t.Errorf("%T.DecodeFromBytes(%x) truncated to %d bytes modified its input", l, data, i)
+		}
+	}
+}
+
+// CheckRoundTrip decodes data with l, serializes l followed by its payload,
+// and reports an error if the result doesn't equal the decoded data.  l has
+// to be a gopacket.SerializableLayer.  Layers whose encoding isn't unique,
+// e.g. because options may be reordered, can't be checked this way.
+func CheckRoundTrip(t testing.TB, l gopacket.DecodingLayer, data []byte) {
+	t.Helper()
+	s, ok := l.(gopacket.SerializableLayer)
+	layer, isLayer := l.(gopacket.Layer)
+	if !ok || !isLayer {
+		t.Errorf("%T isn't a gopacket.SerializableLayer", l)
+		return
+	}
+	if err := CheckDecode(t, l, data); err != nil {
+		t.Errorf("%T.DecodeFromBytes(%x): %v", l, data, err)
+		return
+	}
+	decoded := len(layer.LayerContents()) + len(l.LayerPayload())
+	if decoded > len(data) {
+		// already reported by CheckDecode
+		return
+	}
+	got, err := serialize(s, l.LayerPayload(), gopacket.SerializeOptions{})
+	if err != nil {
+		t.Errorf("%T.SerializeTo: %v", l, err)
+		return
+	}
+	if want := data[:decoded]; !bytes.Equal(got, want) {
+		t.Errorf("%T doesn't round trip:\ndecoded:    %x\nserialized: %x", l, want, got)
+	}
+}
+
+// serialize serializes l followed by payload with opts, turning panics into
+// errors
+func serialize(l gopacket.SerializableLayer, payload []byte, opts gopacket.SerializeOptions) (data []byte, err error) {
+	defer recoverPanic(&err)
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, opts, l, gopacket.Payload(payload)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// CheckDecodeAllocs reports an error if decoding data with l, reusing l,
+// allocates more than max times on average.
+func CheckDecodeAllocs(t testing.TB, l gopacket.DecodingLayer, data []byte, max float64) {
+	t.Helper()
+	if err := CheckDecode(t, l, data); err != nil {
+		t.Errorf("%T.DecodeFromBytes(%x): %v", l, data, err)
+		return
+	}
+	n := testing.AllocsPerRun(100, func() {
+		l.DecodeFromBytes(data, gopacket.NilDecodeFeedback)
+	})
+	if n > max {
+		t.Errorf("%T.DecodeFromBytes allocates %v times, want at most %v", l, n, max)
+	}
+}
+
+// CheckPacket decodes data as a packet starting with decoder, eagerly and
+// lazily, and formats all of its layers.  It reports an error if decoding or
+// formatting panics, and returns the eagerly decoded packet.
+func CheckPacket(t testing.TB, data []byte, decoder gopacket.Decoder) gopacket.Packet {
+	t.Helper()
+	var eager gopacket.Packet
+	for _, lazy := range []bool{false, true} {
+		p, err := decodePacket(data, decoder, lazy)
+		if err != nil {
+			t.Errorf("decoding %x with %v (lazy %v): %v", data, decoder, lazy, err)
+			continue
+		}
+		if !lazy {
+			eager = p
+		}
+	}
+	return eager
+}
+
+// decodePacket decodes and formats a packet, turning panics into errors
+func decodePacket(data []byte, decoder gopacket.Decoder, lazy bool) (p gopacket.Packet, err error) {
+	defer recoverPanic(&err)
+	in := data[:len(data):len(data)]
+	p = gopacket.NewPacket(in, decoder, gopacket.DecodeOptions{Lazy: lazy, NoCopy: true, SkipDecodeRecovery: true})
+	for _, l := range p.Layers() {
+		gopacket.LayerString(l)
+		gopacket.LayerDump(l)
+	}
+	_ = p.String()
+	return p, nil
+}
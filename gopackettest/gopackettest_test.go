@@ -0,0 +1,114 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopackettest
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+var testIPv4UDP = func() []byte {
+	data, err := layers.Build().
+		IPv4(&layers.IPv4{SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}).
+		UDP(&layers.UDP{SrcPort: 1234, DstPort: 5678}).
+		Payload([]byte("hello")).
+		Bytes()
+	if err != nil {
+		panic(err)
+	}
+	return data
+}()
+
+func TestLayers(t *testing.T) {
+	CheckDecode(t, &layers.IPv4{}, testIPv4UDP)
+	CheckTruncations(t, &layers.IPv4{}, testIPv4UDP)
+	CheckRoundTrip(t, &layers.IPv4{}, testIPv4UDP)
+	CheckRoundTrip(t, &layers.UDP{}, testIPv4UDP[20:])
+	CheckDecodeAllocs(t, &layers.UDP{}, testIPv4UDP[20:], 0)
+	p := CheckPacket(t, testIPv4UDP, layers.LayerTypeIPv4)
+	if p == nil || p.Layer(layers.LayerTypeUDP) == nil {
+		t.Errorf("unexpected packet %v", p)
+	}
+}
+
+// recorder records the errors reported by the checks
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// badLayer has the bugs the checks are looking for
+type badLayer struct {
+	layers.BaseLayer
+	overread, modify, alloc bool
+}
+
+func (b *badLayer) LayerType() gopacket.LayerType     { return gopacket.LayerTypePayload }
+func (b *badLayer) CanDecode() gopacket.LayerClass    { return gopacket.LayerTypePayload }
+func (b *badLayer) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+func (b *badLayer) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if b.overread {
+		// reads one byte beyond data
+		data = data[:len(data)+1]
+	}
+	if b.modify && len(data) > 0 {
+		data[0]++
+	}
+	if b.alloc {
+		data = append([]byte(nil), data...)
+	}
+	b.Contents = data
+	return nil
+}
+
+func TestChecksReportBugs(t *testing.T) {
+	data := []byte{1, 2, 3}
+	for _, test := range []struct {
+		name  string
+		check func(testing.TB)
+	}{
+		{"overread", func(t testing.TB) { CheckDecode(t, &badLayer{overread: true}, data) }},
+		{"overread truncated", func(t testing.TB) { CheckTruncations(t, &badLayer{overread: true}, data) }},
+		{"modify", func(t testing.TB) { CheckDecode(t, &badLayer{modify: true}, data) }},
+		{"alloc", func(t testing.TB) { CheckDecodeAllocs(t, &badLayer{alloc: true}, data, 0) }},
+		{"not serializable", func(t testing.TB) { CheckRoundTrip(t, &badLayer{}, data) }},
+		{"packet", func(t testing.TB) {
+			CheckPacket(t, data, gopacket.DecodeFunc(func([]byte, gopacket.PacketBuilder) error { panic("bug") }))
+		}},
+	} {
+		r := &recorder{TB: t}
+		test.check(r)
+		if len(r.errors) == 0 {
+			t.Errorf("%s: no error reported", test.name)
+		}
+	}
+
+	r := &recorder{TB: t}
+	CheckDecode(r, &badLayer{}, data)
+	CheckTruncations(r, &badLayer{}, data)
+	if len(r.errors) != 0 {
+		t.Errorf("unexpected errors: %v", r.errors)
+	}
+}
+
+func FuzzIPv4(f *testing.F) {
+	FuzzDecodingLayer(f, func() gopacket.DecodingLayer { return &layers.IPv4{} }, testIPv4UDP)
+}
+
+func FuzzIPv4Packet(f *testing.F) {
+	FuzzDecoder(f, layers.LayerTypeIPv4, testIPv4UDP)
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+//go:build go1.23
+// +build go1.23
+
+package gopacket
+
+import (
+	"io"
+	"iter"
+	"time"
+)
+
+// PacketData is the data of a packet read from a PacketDataSource together
+// with its CaptureInfo.
+type PacketData struct {
+	Data []byte
+	CaptureInfo
+}
+
+// All returns an iterator over the packets of source.  In contrast to
+// PacketSource.Packets, packets are read in the goroutine ranging over the
+// iterator, and no additional goroutine or channel is needed:
+//
+//	for packet, err := range gopacket.All(packetSource) {
+//	  if err != nil {
+//	    log.Println(err)
+//	    break
+//	  }
+//	  handlePacket(packet)  // Do something with each packet.
+//	}
+//
+// Errors are handled like by Packets: temporary errors are retried, and
+// other errors, like the read timeouts of live captures, are retried after a
+// short sleep.  io.EOF ends the iteration, and other known unrecoverable
+// errors, like io.ErrUnexpectedEOF, are yielded with a nil packet before the
+// iteration ends, so an error is always the last value.
+func All(source *PacketSource) iter.Seq2[Packet, error] {
+	return func(yield func(Packet, error) bool) {
+		for {
+			packet, err := source.NextPacket()
+			if err == nil {
+				if !yield(packet, nil) {
+					return
+				}
+				continue
+			}
+			retry, stop := classifyReadError(err)
+			if retry {
+				continue
+			}
+			if !stop {
+				time.Sleep(readRetryDelay)
+				continue
+			}
+			if err != io.EOF {
+				yield(nil, err)
+			}
+			return
+		}
+	}
+}
+
+// AllData is like All, but iterates over the undecoded packet data of
+// source, read with ReadPacketData.
+func AllData(source PacketDataSource) iter.Seq2[PacketData, error] {
+	return func(yield func(PacketData, error) bool) {
+		for {
+			data, ci, err := source.ReadPacketData()
+			if err == nil {
+				if !yield(PacketData{Data: data, CaptureInfo: ci}, nil) {
+					return
+				}
+				continue
+			}
+			retry, stop := classifyReadError(err)
+			if retry {
+				continue
+			}
+			if !stop {
+				time.Sleep(readRetryDelay)
+				continue
+			}
+			if err != io.EOF {
+				yield(PacketData{}, err)
+			}
+			return
+		}
+	}
+}
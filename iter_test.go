@@ -0,0 +1,81 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+//go:build go1.23
+// +build go1.23
+
+package gopacket
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// errorPacketSource returns its packet data and errors in order, then io.EOF
+type errorPacketSource struct {
+	data [][]byte
+	errs []error
+}
+
+func (s *errorPacketSource) ReadPacketData() ([]byte, CaptureInfo, error) {
+	if len(s.data) == 0 {
+		return nil, CaptureInfo{}, io.EOF
+	}
+	data, err := s.data[0], s.errs[0]
+	s.data, s.errs = s.data[1:], s.errs[1:]
+	return data, CaptureInfo{CaptureLength: len(data), Length: len(data)}, err
+}
+
+func TestAll(t *testing.T) {
+	// like the read timeouts of pcap and afpacket, which are retried
+	errTimeout := errors.New("packet poll timeout expired")
+	newSource := func() *errorPacketSource {
+		return &errorPacketSource{
+			data: [][]byte{{1}, nil, {2}, nil, {3}},
+			errs: []error{nil, errTimeout, nil, io.ErrUnexpectedEOF, nil},
+		}
+	}
+
+	var got []byte
+	var errs []error
+	for packet, err := range All(NewPacketSource(newSource(), DecodePayload)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		got = append(got, packet.Data()[0])
+	}
+	if string(got) != "\x01\x02" || len(errs) != 1 || errs[0] != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected packets %v and errors %v", got, errs)
+	}
+
+	got = nil
+	for data, err := range AllData(newSource()) {
+		if err != nil {
+			break
+		}
+		got = append(got, data.Data[0])
+		if data.CaptureLength != 1 {
+			t.Errorf("unexpected capture info %+v", data.CaptureInfo)
+		}
+	}
+	if string(got) != "\x01\x02" {
+		t.Errorf("unexpected data %v", got)
+	}
+
+	// io.EOF ends the iteration without an error
+	count := 0
+	for _, err := range AllData(&singlePacketSource{[]byte{1}}) {
+		if err != nil {
+			t.Error(err)
+		}
+		count++
+	}
+	if count != 1 {
+		t.Errorf("expected 1 packet, got %d", count)
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// JSONOptions selects the JSON output of MarshalPacketJSON.
+type JSONOptions struct {
+	// FiveTuple outputs only the capture metadata and the five-tuple (network
+	// addresses, transport protocol and ports) of the packet instead of all
+	// layers.
+	FiveTuple bool
+}
+
+// jsonMaxDepth limits the nesting of values in layers, guarding against
+// reference cycles.
+const jsonMaxDepth = 16
+
+// LayerJSON returns a canonical JSON representation of a layer.  It's an
+// object with the layer type under "LayerType", followed by all exported fields
+// of the layer under their Go names, recursing into exported slices and
+// structs.  The following rules apply:
+//   - The embedded BaseLayer (layer contents and payload) is left out.
+//   - Values implementing json.Marshaler are output accordingly.
+//   - Values implementing encoding.TextMarshaler (e.g. net.IP) and
+//     net.HardwareAddr are output as strings.
+//   - Other byte slices and arrays are output as hex strings.
+//   - Numeric values, including enumerations like EthernetType, are output as
+//     numbers.
+//   - Layers that aren't structs are output under "Value".
+//
+// The layers of the layers package embedding BaseLayer implement
+// json.Marshaler with LayerJSON.
+func LayerJSON(l Layer) ([]byte, error) {
+	var b bytes.Buffer
+	if err := layerJSON(&b, l); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func layerJSON(b *bytes.Buffer, l Layer) error {
+	b.WriteString(`{"LayerType":`)
+	jsonString(b, l.LayerType().String())
+	v := reflect.ValueOf(l)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			b.WriteByte('}')
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		if _, err := jsonFields(b, v, true, 0); err != nil {
+			return err
+		}
+	} else {
+		b.WriteString(`,"Value":`)
+		if err := jsonValue(b, v, 0); err != nil {
+			return err
+		}
+	}
+	b.WriteByte('}')
+	return nil
+}
+
+// jsonFields writes the exported fields of the struct v as object members.
+// comma is true if a member was already written.
+func jsonFields(b *bytes.Buffer, v reflect.Value, comma bool, depth int) (bool, error) {
+	typ := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous {
+			if field.Name == "BaseLayer" {
+				continue
+			}
+			f := v.Field(i)
+			if f.Kind() == reflect.Ptr {
+				if f.IsNil() {
+					continue
+				}
+				f = f.Elem()
+			}
+			if f.Kind() == reflect.Struct {
+				var err error
+				if comma, err = jsonFields(b, f, comma, depth); err != nil {
+					return comma, err
+				}
+				continue
+			}
+		}
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		switch field.Type.Kind() {
+		case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+			continue
+		}
+		if comma {
+			b.WriteByte(',')
+		}
+		comma = true
+		jsonString(b, field.Name)
+		b.WriteByte(':')
+		if err := jsonValue(b, v.Field(i), depth+1); err != nil {
+			return comma, err
+		}
+	}
+	return comma, nil
+}
+
+var (
+	jsonMarshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonTextMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonHardwareAddrType  = reflect.TypeOf(net.HardwareAddr{})
+)
+
+// jsonValue writes v according to the rules of LayerJSON
+func jsonValue(b *bytes.Buffer, v reflect.Value, depth int) error {
+	if depth > jsonMaxDepth {
+		b.WriteString("null")
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		if v.Kind() == reflect.Interface && v.CanInterface() {
+			if l, ok := v.Interface().(Layer); ok {
+				return layerJSON(b, l)
+			}
+		}
+		return jsonValue(b, v.Elem(), depth)
+	}
+	if v.Type().Implements(jsonMarshalerType) && v.CanInterface() {
+		data, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		return nil
+	}
+	if v.Type().Implements(jsonTextMarshalerType) && v.CanInterface() {
+		data, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		jsonString(b, string(data))
+		return nil
+	}
+	if v.Type() == jsonHardwareAddrType && v.CanInterface() {
+		jsonString(b, v.Interface().(net.HardwareAddr).String())
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		b.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			b.WriteString("null")
+		} else {
+			b.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case reflect.String:
+		jsonString(b, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, v.Len())
+			for i := range data {
+				data[i] = byte(v.Index(i).Uint())
+			}
+			jsonString(b, hex.EncodeToString(data))
+			return nil
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := jsonValue(b, v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for _, k := range v.MapKeys() {
+			key := fmt.Sprint(k)
+			keys = append(keys, key)
+			values[key] = v.MapIndex(k)
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			jsonString(b, key)
+			b.WriteByte(':')
+			if err := jsonValue(b, values[key], depth+1); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case reflect.Struct:
+		b.WriteByte('{')
+		if _, err := jsonFields(b, v, false, depth); err != nil {
+			return err
+		}
+		b.WriteByte('}')
+	default:
+		b.WriteString("null")
+	}
+	return nil
+}
+
+func jsonString(b *bytes.Buffer, s string) {
+	data, _ := json.Marshal(s)
+	b.Write(data)
+}
+
+// MarshalPacketJSON returns a canonical JSON representation of a packet.  It's
+// an object with the packet metadata under "Metadata" and, unless
+// options.FiveTuple is set, all layers as output by LayerJSON under "Layers".
+// With options.FiveTuple, the network addresses, the transport protocol and
+// the ports are output under "SrcIP", "DstIP", "Protocol", "SrcPort" and
+// "DstPort" instead, if the packet has such layers.
+func MarshalPacketJSON(p Packet, options JSONOptions) ([]byte, error) {
+	var b bytes.Buffer
+	md := p.Metadata()
+	fmt.Fprintf(&b, `{"Metadata":{"Timestamp":"%s","CaptureLength":%d,"Length":%d,"InterfaceIndex":%d,"Truncated":%t}`,
+		md.Timestamp.UTC().Format(time.RFC3339Nano), md.CaptureLength, md.Length, md.InterfaceIndex, md.Truncated)
+	if options.FiveTuple {
+		if net := p.NetworkLayer(); net != nil {
+			src, dst := net.NetworkFlow().Endpoints()
+			b.WriteString(`,"SrcIP":`)
+			jsonString(&b, src.String())
+			b.WriteString(`,"DstIP":`)
+			jsonString(&b, dst.String())
+		}
+		if transport := p.TransportLayer(); transport != nil {
+			src, dst := transport.TransportFlow().Endpoints()
+			b.WriteString(`,"Protocol":`)
+			jsonString(&b, transport.LayerType().String())
+			b.WriteString(`,"SrcPort":`)
+			jsonEndpoint(&b, src)
+			b.WriteString(`,"DstPort":`)
+			jsonEndpoint(&b, dst)
+		}
+		b.WriteByte('}')
+		return b.Bytes(), nil
+	}
+	b.WriteString(`,"Layers":[`)
+	for i, l := range p.Layers() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if err := layerJSON(&b, l); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteString("]}")
+	return b.Bytes(), nil
+}
+
+// jsonEndpoint writes 2 byte endpoints (ports) as number, others as string
+func jsonEndpoint(b *bytes.Buffer, e Endpoint) {
+	if raw := e.Raw(); len(raw) == 2 {
+		fmt.Fprintf(b, "%d", binary.BigEndian.Uint16(raw))
+		return
+	}
+	jsonString(b, e.String())
+}
+
+// MarshalJSON implements json.Marshaler, see MarshalPacketJSON.
+func (p *eagerPacket) MarshalJSON() ([]byte, error) {
+	p.checkReleased()
+	return MarshalPacketJSON(p, JSONOptions{})
+}
+
+// MarshalJSON implements json.Marshaler, see MarshalPacketJSON.
+func (p *lazyPacket) MarshalJSON() ([]byte, error) {
+	return MarshalPacketJSON(p, JSONOptions{})
+}
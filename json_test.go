@@ -0,0 +1,70 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+type jsonTestEnum uint8
+
+func (e jsonTestEnum) String() string { return "enum" }
+
+type jsonTestOption struct {
+	Type jsonTestEnum
+	Data []byte
+}
+
+type jsonTestLayer struct {
+	Fragment
+	IP       net.IP
+	MAC      net.HardwareAddr
+	Enum     jsonTestEnum
+	Name     string
+	Options  []jsonTestOption
+	Checksum [2]byte
+	Next     *jsonTestOption
+	hidden   int
+	Callback func()
+}
+
+func TestLayerJSON(t *testing.T) {
+	l := &jsonTestLayer{
+		Fragment: Fragment{1, 2},
+		IP:       net.IP{10, 0, 0, 1},
+		MAC:      net.HardwareAddr{0, 1, 2, 3, 4, 5},
+		Enum:     3,
+		Name:     "a\"b",
+		Options:  []jsonTestOption{{Type: 1, Data: []byte{0xab}}},
+		Checksum: [2]byte{0xff, 0},
+		hidden:   1,
+	}
+	data, err := LayerJSON(l)
+	if err != nil {
+		t.Fatal("can't marshal layer:", err)
+	}
+	want := `{"LayerType":"Fragment","Fragment":"0102","IP":"10.0.0.1","MAC":"00:01:02:03:04:05","Enum":3,"Name":"a\"b","Options":[{"Type":1,"Data":"ab"}],"Checksum":"ff00","Next":null}`
+	if string(data) != want {
+		t.Errorf("layer JSON mismatch:\n   got: %s\n  want: %s", data, want)
+	}
+
+	p := NewPacket([]byte{1, 2, 3}, DecodePayload, Default)
+	p.Metadata().Timestamp = time.Unix(1, 5).In(time.FixedZone("test", 3600))
+	p.Metadata().CaptureLength = 3
+	p.Metadata().Length = 4
+	data, err = json.Marshal(p)
+	if err != nil {
+		t.Fatal("can't marshal packet:", err)
+	}
+	want = `{"Metadata":{"Timestamp":"1970-01-01T00:00:01.000000005Z","CaptureLength":3,"Length":4,"InterfaceIndex":0,"Truncated":false},"Layers":[{"LayerType":"Payload","Value":"010203"}]}`
+	if string(data) != want {
+		t.Errorf("packet JSON mismatch:\n   got: %s\n  want: %s", data, want)
+	}
+}
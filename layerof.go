@@ -0,0 +1,50 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+//go:build go1.18
+// +build go1.18
+
+package gopacket
+
+import (
+	"fmt"
+)
+
+// LayerOf returns the first layer of the packet with type T, or the zero value
+// of T (nil) if there is no such layer.  T is usually a pointer to a concrete
+// layer, but can also be an interface like ApplicationLayer.
+//
+//	if tcp := gopacket.LayerOf[*layers.TCP](packet); tcp != nil {
+//	  fmt.Println(tcp.SrcPort, tcp.DstPort)
+//	}
+//
+// In contrast to Packet.Layer, a lazily decoded packet is decoded completely if
+// no layer with type T is found.
+func LayerOf[T Layer](p Packet) T {
+	t, _ := layerOf[T](p)
+	return t
+}
+
+// MustLayerOf is like LayerOf, but panics if the packet has no layer with type T.
+func MustLayerOf[T Layer](p Packet) T {
+	t, ok := layerOf[T](p)
+	if !ok {
+		panic(fmt.Sprintf("packet has no layer of type %T", t))
+	}
+	return t
+}
+
+// layerOf implements LayerOf and MustLayerOf.  It reports whether a layer was
+// found, since T needn't be a pointer or interface whose zero value is nil.
+func layerOf[T Layer](p Packet) (T, bool) {
+	for _, l := range p.Layers() {
+		if t, ok := l.(T); ok {
+			return t, true
+		}
+	}
+	var zero T
+	return zero, false
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+//go:build go1.18
+// +build go1.18
+
+package gopacket
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLayerOf(t *testing.T) {
+	p := NewPacket([]byte{1, 2, 3}, DecodePayload, Default)
+	payload := LayerOf[*Payload](p)
+	if payload == nil || !bytes.Equal(payload.Payload(), []byte{1, 2, 3}) {
+		t.Errorf("expected payload layer, got %v", payload)
+	}
+	if app := LayerOf[ApplicationLayer](p); app != ApplicationLayer(payload) {
+		t.Errorf("expected payload as application layer, got %v", app)
+	}
+	if f := LayerOf[*Fragment](p); f != nil {
+		t.Errorf("expected no fragment layer, got %v", f)
+	}
+	if got := MustLayerOf[*Payload](p); got != payload {
+		t.Errorf("expected payload layer, got %v", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for missing layer")
+		}
+	}()
+	MustLayerOf[*Fragment](p)
+}
@@ -0,0 +1,1371 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// BGPType is the type of a BGP message.
+type BGPType uint8
+
+// BGPType known values, see RFC 4271, section 4.1 and RFC 2918
+const (
+	BGPTypeOpen         BGPType = 1
+	BGPTypeUpdate       BGPType = 2
+	BGPTypeNotification BGPType = 3
+	BGPTypeKeepalive    BGPType = 4
+	BGPTypeRouteRefresh BGPType = 5
+)
+
+func (t BGPType) String() string {
+	switch t {
+	case BGPTypeOpen:
+		return "Open"
+	case BGPTypeUpdate:
+		return "Update"
+	case BGPTypeNotification:
+		return "Notification"
+	case BGPTypeKeepalive:
+		return "Keepalive"
+	case BGPTypeRouteRefresh:
+		return "Route-Refresh"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(t))
+	}
+}
+
+// BGPAFI is a BGP address family identifier.
+type BGPAFI uint16
+
+// BGPAFI known values
+const (
+	BGPAFIIPv4  BGPAFI = 1
+	BGPAFIIPv6  BGPAFI = 2
+	BGPAFIL2VPN BGPAFI = 25
+)
+
+// BGPSAFI is a BGP subsequent address family identifier.
+type BGPSAFI uint8
+
+// BGPSAFI known values
+const (
+	BGPSAFIUnicast   BGPSAFI = 1
+	BGPSAFIMulticast BGPSAFI = 2
+	BGPSAFILabeled   BGPSAFI = 4 // RFC 8277
+	BGPSAFIEVPN      BGPSAFI = 70
+	BGPSAFIMPLSVPN   BGPSAFI = 128 // RFC 4364 and RFC 4659
+	BGPSAFIFlowSpec  BGPSAFI = 133
+)
+
+// BGPAddressFamily is a pair of address family identifiers.
+type BGPAddressFamily struct {
+	AFI  BGPAFI
+	SAFI BGPSAFI
+}
+
+// bgpIPv4Unicast is the family of the NLRI of the UPDATE message body
+var bgpIPv4Unicast = BGPAddressFamily{BGPAFIIPv4, BGPSAFIUnicast}
+
+// prefixes returns whether the NLRI of the family are decoded as prefixes.
+func (f BGPAddressFamily) prefixes() bool {
+	if f.AFI != BGPAFIIPv4 && f.AFI != BGPAFIIPv6 {
+		return false
+	}
+	switch f.SAFI {
+	case BGPSAFIUnicast, BGPSAFIMulticast, BGPSAFILabeled, BGPSAFIMPLSVPN:
+		return true
+	}
+	return false
+}
+
+func (f BGPAddressFamily) addrLen() int {
+	if f.AFI == BGPAFIIPv6 {
+		return net.IPv6len
+	}
+	return net.IPv4len
+}
+
+// BGPSession holds the capabilities negotiated by a BGP session which
+// change the encoding of UPDATE messages.
+type BGPSession struct {
+	// AS4 is set if AS numbers are 4 octets long, RFC 6793.
+	AS4 bool
+	// AddPath lists the address families whose NLRI have path
+	// identifiers, RFC 7911.
+	AddPath []BGPAddressFamily
+}
+
+func (s *BGPSession) addPath(f BGPAddressFamily) bool {
+	if s == nil {
+		return false
+	}
+	for _, af := range s.AddPath {
+		if af == f {
+			return true
+		}
+	}
+	return false
+}
+
+// BGPLabel is an MPLS label stack entry of a labeled or VPN prefix: the
+// 20-bit label, 3 traffic class bits and the bottom of stack bit.
+type BGPLabel uint32
+
+// bgpLabelWithdraw is the compatibility label of withdrawn labeled prefixes
+const bgpLabelWithdraw BGPLabel = 0x800000
+
+// Label returns the label value.
+func (l BGPLabel) Label() uint32 { return uint32(l) >> 4 }
+
+// BottomOfStack returns whether l is the last entry of the label stack.
+func (l BGPLabel) BottomOfStack() bool { return l&1 != 0 }
+
+// BGPRouteDistinguisher is the route distinguisher of a VPN prefix, RFC
+// 4364, section 4.2.
+type BGPRouteDistinguisher [8]byte
+
+func (rd BGPRouteDistinguisher) String() string {
+	switch binary.BigEndian.Uint16(rd[0:2]) {
+	case 0:
+		return fmt.Sprintf("%d:%d", binary.BigEndian.Uint16(rd[2:4]), binary.BigEndian.Uint32(rd[4:8]))
+	case 1:
+		return fmt.Sprintf("%v:%d", net.IP(rd[2:6]), binary.BigEndian.Uint16(rd[6:8]))
+	case 2:
+		return fmt.Sprintf("%d:%d", binary.BigEndian.Uint32(rd[2:6]), binary.BigEndian.Uint16(rd[6:8]))
+	}
+	return fmt.Sprintf("%x", rd[:])
+}
+
+// BGPPrefix is a prefix of the NLRI or withdrawn routes.  Labels are set
+// for labeled and VPN prefixes, and RD for VPN prefixes.
+type BGPPrefix struct {
+	PathID uint32 // ADD-PATH only
+	Labels []BGPLabel
+	RD     BGPRouteDistinguisher
+	Prefix net.IPNet
+}
+
+func decodeBGPPrefixes(data []byte, f BGPAddressFamily, addPath bool) ([]BGPPrefix, error) {
+	var prefixes []BGPPrefix
+	addrLen := f.addrLen()
+	for len(data) > 0 {
+		var p BGPPrefix
+		if addPath {
+			if len(data) < 4 {
+				return nil, errors.New("BGP prefix path identifier too short")
+			}
+			p.PathID = binary.BigEndian.Uint32(data[0:4])
+			data = data[4:]
+		}
+		if len(data) < 1 {
+			return nil, errors.New("BGP prefix too short")
+		}
+		bits := int(data[0])
+		data = data[1:]
+		if f.SAFI == BGPSAFILabeled || f.SAFI == BGPSAFIMPLSVPN {
+			for {
+				if bits < 24 || len(data) < 3 {
+					return nil, errors.New("BGP prefix label too short")
+				}
+				l := BGPLabel(uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2]))
+				p.Labels = append(p.Labels, l)
+				bits -= 24
+				data = data[3:]
+				if l.BottomOfStack() || l == bgpLabelWithdraw {
+					break
+				}
+			}
+		}
+		if f.SAFI == BGPSAFIMPLSVPN {
+			if bits < 64 || len(data) < 8 {
+				return nil, errors.New("BGP prefix route distinguisher too short")
+			}
+			copy(p.RD[:], data)
+			bits -= 64
+			data = data[8:]
+		}
+		if bits > addrLen*8 {
+			return nil, fmt.Errorf("invalid BGP prefix length %d", bits)
+		}
+		n := (bits + 7) / 8
+		if len(data) < n {
+			return nil, errors.New("BGP prefix too short")
+		}
+		ip := make(net.IP, addrLen)
+		copy(ip, data[:n])
+		p.Prefix = net.IPNet{IP: ip, Mask: net.CIDRMask(bits, addrLen*8)}
+		data = data[n:]
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, nil
+}
+
+func appendBGPPrefixes(b []byte, prefixes []BGPPrefix, f BGPAddressFamily, addPath bool) ([]byte, error) {
+	addrLen := f.addrLen()
+	for _, p := range prefixes {
+		ip := p.Prefix.IP.To16()
+		if addrLen == net.IPv4len {
+			ip = p.Prefix.IP.To4()
+		}
+		ones, size := p.Prefix.Mask.Size()
+		if ip == nil || size != addrLen*8 {
+			return nil, fmt.Errorf("invalid BGP prefix %v for AFI %d", p.Prefix.String(), f.AFI)
+		}
+		if addPath {
+			b = append(b, 0, 0, 0, 0)
+			binary.BigEndian.PutUint32(b[len(b)-4:], p.PathID)
+		}
+		bits := ones
+		if f.SAFI == BGPSAFILabeled || f.SAFI == BGPSAFIMPLSVPN {
+			if len(p.Labels) == 0 {
+				return nil, fmt.Errorf("BGP labeled prefix %v without labels", p.Prefix.String())
+			}
+			bits += 24 * len(p.Labels)
+		}
+		if f.SAFI == BGPSAFIMPLSVPN {
+			bits += 64
+		}
+		if bits > 0xff {
+			return nil, fmt.Errorf("BGP prefix %v too long", p.Prefix.String())
+		}
+		b = append(b, uint8(bits))
+		if f.SAFI == BGPSAFILabeled || f.SAFI == BGPSAFIMPLSVPN {
+			for _, l := range p.Labels {
+				b = append(b, uint8(l>>16), uint8(l>>8), uint8(l))
+			}
+		}
+		if f.SAFI == BGPSAFIMPLSVPN {
+			b = append(b, p.RD[:]...)
+		}
+		b = append(b, ip[:(ones+7)/8]...)
+	}
+	return b, nil
+}
+
+// BGPCapabilityCode is the code of a capability of an OPEN message.
+type BGPCapabilityCode uint8
+
+// BGPCapabilityCode known values, see the IANA Capability Codes registry
+const (
+	BGPCapabilityMultiprotocol        BGPCapabilityCode = 1
+	BGPCapabilityRouteRefresh         BGPCapabilityCode = 2
+	BGPCapabilityExtendedNextHop      BGPCapabilityCode = 5
+	BGPCapabilityExtendedMessage      BGPCapabilityCode = 6
+	BGPCapabilityGracefulRestart      BGPCapabilityCode = 64
+	BGPCapabilityFourOctetAS          BGPCapabilityCode = 65
+	BGPCapabilityAddPath              BGPCapabilityCode = 69
+	BGPCapabilityEnhancedRouteRefresh BGPCapabilityCode = 70
+	BGPCapabilityLongLivedGR          BGPCapabilityCode = 71
+	BGPCapabilityFQDN                 BGPCapabilityCode = 73
+)
+
+// BGPCapability is a capability advertised by an OPEN message, RFC 5492.
+type BGPCapability struct {
+	Code  BGPCapabilityCode
+	Value []byte
+}
+
+// BGPOptionalParameter is an optional parameter of an OPEN message other
+// than capabilities.
+type BGPOptionalParameter struct {
+	Type  uint8
+	Value []byte
+}
+
+// bgpParameterCapabilities is the optional parameter type of capabilities,
+// and bgpParameterExtended flags extended optional parameters, RFC 9072.
+const (
+	bgpParameterCapabilities = 2
+	bgpParameterExtended     = 255
+)
+
+// BGPOpen is the body of an OPEN message.  Capabilities gathers the
+// capabilities of all the capabilities optional parameters.
+type BGPOpen struct {
+	Version      uint8
+	MyAS         uint16
+	HoldTime     uint16 // Seconds
+	Identifier   net.IP
+	Capabilities []BGPCapability
+	Parameters   []BGPOptionalParameter
+}
+
+// ASN returns the AS number of the speaker, which is advertised in the
+// 4-octet AS number capability if MyAS is AS_TRANS.
+func (o *BGPOpen) ASN() uint32 {
+	for _, c := range o.Capabilities {
+		if c.Code == BGPCapabilityFourOctetAS && len(c.Value) == 4 {
+			return binary.BigEndian.Uint32(c.Value)
+		}
+	}
+	return uint32(o.MyAS)
+}
+
+// AS4 returns whether the 4-octet AS number capability is advertised.
+func (o *BGPOpen) AS4() bool {
+	for _, c := range o.Capabilities {
+		if c.Code == BGPCapabilityFourOctetAS {
+			return true
+		}
+	}
+	return false
+}
+
+// Multiprotocol returns the address families of the multiprotocol
+// capabilities.
+func (o *BGPOpen) Multiprotocol() []BGPAddressFamily {
+	var families []BGPAddressFamily
+	for _, c := range o.Capabilities {
+		if c.Code == BGPCapabilityMultiprotocol && len(c.Value) == 4 {
+			families = append(families, BGPAddressFamily{
+				AFI:  BGPAFI(binary.BigEndian.Uint16(c.Value[0:2])),
+				SAFI: BGPSAFI(c.Value[3]),
+			})
+		}
+	}
+	return families
+}
+
+// NewBGPMultiprotocolCapability returns the multiprotocol capability of f.
+func NewBGPMultiprotocolCapability(f BGPAddressFamily) BGPCapability {
+	return BGPCapability{
+		Code:  BGPCapabilityMultiprotocol,
+		Value: []byte{uint8(f.AFI >> 8), uint8(f.AFI), 0, uint8(f.SAFI)},
+	}
+}
+
+// NewBGPFourOctetASCapability returns the 4-octet AS number capability of
+// asn.
+func NewBGPFourOctetASCapability(asn uint32) BGPCapability {
+	c := BGPCapability{Code: BGPCapabilityFourOctetAS, Value: make([]byte, 4)}
+	binary.BigEndian.PutUint32(c.Value, asn)
+	return c
+}
+
+func decodeBGPCapabilities(data []byte, caps []BGPCapability) ([]BGPCapability, error) {
+	for len(data) > 0 {
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			return nil, errors.New("BGP capability too short")
+		}
+		caps = append(caps, BGPCapability{Code: BGPCapabilityCode(data[0]), Value: data[2 : 2+int(data[1])]})
+		data = data[2+int(data[1]):]
+	}
+	return caps, nil
+}
+
+func decodeBGPOpen(data []byte) (*BGPOpen, error) {
+	if len(data) < 10 {
+		return nil, errors.New("BGP open too short")
+	}
+	o := &BGPOpen{
+		Version:    data[0],
+		MyAS:       binary.BigEndian.Uint16(data[1:3]),
+		HoldTime:   binary.BigEndian.Uint16(data[3:5]),
+		Identifier: net.IP(data[5:9]),
+	}
+	params := data[10:]
+	lengthSize := 1
+	if data[9] == bgpParameterExtended && len(params) > 0 && params[0] == bgpParameterExtended {
+		if len(params) < 3 {
+			return nil, errors.New("BGP open extended parameters too short")
+		}
+		lengthSize = 2
+		if int(binary.BigEndian.Uint16(params[1:3])) != len(params)-3 {
+			return nil, errors.New("BGP open optional parameters length mismatch")
+		}
+		params = params[3:]
+	} else if int(data[9]) != len(params) {
+		return nil, errors.New("BGP open optional parameters length mismatch")
+	}
+	for len(params) > 0 {
+		if len(params) < 1+lengthSize {
+			return nil, errors.New("BGP optional parameter too short")
+		}
+		length := int(params[1])
+		if lengthSize == 2 {
+			length = int(binary.BigEndian.Uint16(params[1:3]))
+		}
+		start := 1 + lengthSize
+		if len(params) < start+length {
+			return nil, errors.New("BGP optional parameter too short")
+		}
+		value := params[start : start+length]
+		if params[0] == bgpParameterCapabilities {
+			var err error
+			if o.Capabilities, err = decodeBGPCapabilities(value, o.Capabilities); err != nil {
+				return nil, err
+			}
+		} else {
+			o.Parameters = append(o.Parameters, BGPOptionalParameter{Type: params[0], Value: value})
+		}
+		params = params[start+length:]
+	}
+	return o, nil
+}
+
+func (o *BGPOpen) encode() ([]byte, error) {
+	ip := o.Identifier.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("invalid BGP identifier %v", o.Identifier)
+	}
+	var caps []byte
+	for _, c := range o.Capabilities {
+		if len(c.Value) > 0xff {
+			return nil, fmt.Errorf("BGP capability %d too long", c.Code)
+		}
+		caps = append(append(caps, uint8(c.Code), uint8(len(c.Value))), c.Value...)
+	}
+	params := o.Parameters
+	if len(caps) > 0 {
+		params = append([]BGPOptionalParameter{{Type: bgpParameterCapabilities, Value: caps}}, params...)
+	}
+	length, extended := 0, false
+	for _, p := range params {
+		length += 2 + len(p.Value)
+		extended = extended || len(p.Value) > 0xff
+	}
+	extended = extended || length > 0xff
+	b := []byte{o.Version, uint8(o.MyAS >> 8), uint8(o.MyAS), uint8(o.HoldTime >> 8), uint8(o.HoldTime)}
+	b = append(b, ip...)
+	if extended {
+		length += len(params)
+		if length > 0xffff {
+			return nil, errors.New("BGP open optional parameters too long")
+		}
+		b = append(b, bgpParameterExtended, bgpParameterExtended, uint8(length>>8), uint8(length))
+	} else {
+		b = append(b, uint8(length))
+	}
+	for _, p := range params {
+		if extended {
+			b = append(b, p.Type, uint8(len(p.Value)>>8), uint8(len(p.Value)))
+		} else {
+			b = append(b, p.Type, uint8(len(p.Value)))
+		}
+		b = append(b, p.Value...)
+	}
+	return b, nil
+}
+
+// BGPAttributeFlags are the flags of a path attribute.
+type BGPAttributeFlags uint8
+
+// BGPAttributeFlags bits
+const (
+	BGPAttributeFlagOptional       BGPAttributeFlags = 0x80
+	BGPAttributeFlagTransitive     BGPAttributeFlags = 0x40
+	BGPAttributeFlagPartial        BGPAttributeFlags = 0x20
+	BGPAttributeFlagExtendedLength BGPAttributeFlags = 0x10
+)
+
+// BGPAttributeType is the type of a path attribute.
+type BGPAttributeType uint8
+
+// BGPAttributeType known values, see the IANA BGP Path Attributes registry
+const (
+	BGPAttributeOrigin              BGPAttributeType = 1
+	BGPAttributeASPath              BGPAttributeType = 2
+	BGPAttributeNextHop             BGPAttributeType = 3
+	BGPAttributeMED                 BGPAttributeType = 4
+	BGPAttributeLocalPref           BGPAttributeType = 5
+	BGPAttributeAtomicAggregate     BGPAttributeType = 6
+	BGPAttributeAggregator          BGPAttributeType = 7
+	BGPAttributeCommunities         BGPAttributeType = 8
+	BGPAttributeOriginatorID        BGPAttributeType = 9
+	BGPAttributeClusterList         BGPAttributeType = 10
+	BGPAttributeMPReach             BGPAttributeType = 14
+	BGPAttributeMPUnreach           BGPAttributeType = 15
+	BGPAttributeExtendedCommunities BGPAttributeType = 16
+	BGPAttributeAS4Path             BGPAttributeType = 17
+	BGPAttributeAS4Aggregator       BGPAttributeType = 18
+	BGPAttributeLargeCommunities    BGPAttributeType = 32
+)
+
+// BGPOrigin is the value of the ORIGIN attribute.
+type BGPOrigin uint8
+
+// BGPOrigin values
+const (
+	BGPOriginIGP        BGPOrigin = 0
+	BGPOriginEGP        BGPOrigin = 1
+	BGPOriginIncomplete BGPOrigin = 2
+)
+
+// BGPASPathSegmentType is the type of an AS_PATH segment.
+type BGPASPathSegmentType uint8
+
+// BGPASPathSegmentType values, see RFC 4271 and RFC 5065
+const (
+	BGPASSet            BGPASPathSegmentType = 1
+	BGPASSequence       BGPASPathSegmentType = 2
+	BGPASConfedSequence BGPASPathSegmentType = 3
+	BGPASConfedSet      BGPASPathSegmentType = 4
+)
+
+// BGPASPathSegment is a segment of an AS_PATH or AS4_PATH attribute.
+type BGPASPathSegment struct {
+	Type BGPASPathSegmentType
+	ASNs []uint32
+}
+
+// BGPAggregator is the value of the AGGREGATOR and AS4_AGGREGATOR
+// attributes.
+type BGPAggregator struct {
+	AS      uint32
+	Address net.IP
+}
+
+// BGPCommunity is a community of the COMMUNITIES attribute, RFC 1997.
+type BGPCommunity uint32
+
+// BGPCommunity well-known values
+const (
+	BGPCommunityBlackhole         BGPCommunity = 0xffff029a
+	BGPCommunityNoExport          BGPCommunity = 0xffffff01
+	BGPCommunityNoAdvertise       BGPCommunity = 0xffffff02
+	BGPCommunityNoExportSubconfed BGPCommunity = 0xffffff03
+)
+
+func (c BGPCommunity) String() string {
+	return fmt.Sprintf("%d:%d", c>>16, c&0xffff)
+}
+
+// BGPExtendedCommunity is a community of the EXTENDED_COMMUNITIES
+// attribute, RFC 4360.  The type and sub-type are its high order bytes.
+type BGPExtendedCommunity uint64
+
+// BGPLargeCommunity is a community of the LARGE_COMMUNITY attribute, RFC
+// 8092.
+type BGPLargeCommunity struct {
+	GlobalAdmin, LocalData1, LocalData2 uint32
+}
+
+// BGPMPReach is the value of the MP_REACH_NLRI attribute, RFC 4760.  The
+// route distinguishers of VPN next hops are dropped.  RawNLRI holds the
+// NLRI of the address families not decoded as prefixes.
+type BGPMPReach struct {
+	BGPAddressFamily
+	AddPath bool
+	NextHop []net.IP
+	NLRI    []BGPPrefix
+	RawNLRI []byte
+}
+
+// BGPMPUnreach is the value of the MP_UNREACH_NLRI attribute, RFC 4760.
+type BGPMPUnreach struct {
+	BGPAddressFamily
+	AddPath   bool
+	Withdrawn []BGPPrefix
+	RawNLRI   []byte
+}
+
+// BGPPathAttribute is a path attribute of an UPDATE message.  Value is the
+// raw attribute value, and the other fields are its decoded value depending
+// on Type.  Known attribute types are serialized from their decoded value,
+// AS_PATH and AGGREGATOR ones depending on the AS4 field of the update, and
+// others from Value.  The extended length flag is set as needed.
+type BGPPathAttribute struct {
+	Flags BGPAttributeFlags
+	Type  BGPAttributeType
+	Value []byte
+
+	Origin              BGPOrigin
+	ASPath              []BGPASPathSegment // AS_PATH and AS4_PATH
+	NextHop             net.IP
+	MED                 uint32
+	LocalPref           uint32
+	Aggregator          BGPAggregator // AGGREGATOR and AS4_AGGREGATOR
+	Communities         []BGPCommunity
+	OriginatorID        net.IP
+	ClusterList         []net.IP
+	MPReach             *BGPMPReach
+	MPUnreach           *BGPMPUnreach
+	ExtendedCommunities []BGPExtendedCommunity
+	LargeCommunities    []BGPLargeCommunity
+}
+
+func decodeBGPASPath(data []byte, asLen int) ([]BGPASPathSegment, error) {
+	var segments []BGPASPathSegment
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, errors.New("BGP AS path segment too short")
+		}
+		segType := BGPASPathSegmentType(data[0])
+		count := int(data[1])
+		if segType < BGPASSet || segType > BGPASConfedSet || count == 0 {
+			return nil, fmt.Errorf("invalid BGP AS path segment type %d count %d", segType, count)
+		}
+		data = data[2:]
+		if len(data) < count*asLen {
+			return nil, errors.New("BGP AS path segment too short")
+		}
+		seg := BGPASPathSegment{Type: segType, ASNs: make([]uint32, count)}
+		for i := range seg.ASNs {
+			if asLen == 4 {
+				seg.ASNs[i] = binary.BigEndian.Uint32(data[4*i:])
+			} else {
+				seg.ASNs[i] = uint32(binary.BigEndian.Uint16(data[2*i:]))
+			}
+		}
+		data = data[count*asLen:]
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+func decodeBGPMPNextHop(data []byte, f BGPAddressFamily) ([]net.IP, error) {
+	rdLen := 0
+	if f.SAFI == BGPSAFIMPLSVPN {
+		rdLen = 8
+	}
+	var size int
+	switch len(data) {
+	case 0:
+		// Flow specification routes have no next hop
+		return nil, nil
+	case rdLen + net.IPv4len:
+		if f.AFI == BGPAFIIPv6 {
+			return nil, fmt.Errorf("invalid BGP next hop length %d", len(data))
+		}
+		size = rdLen + net.IPv4len
+	case rdLen + net.IPv6len, 2 * (rdLen + net.IPv6len):
+		size = rdLen + net.IPv6len
+	default:
+		return nil, fmt.Errorf("invalid BGP next hop length %d", len(data))
+	}
+	var hops []net.IP
+	for ; len(data) > 0; data = data[size:] {
+		hops = append(hops, net.IP(data[rdLen:size]))
+	}
+	return hops, nil
+}
+
+func decodeBGPMPReach(data []byte, session *BGPSession) (*BGPMPReach, error) {
+	if len(data) < 5 || len(data) < 5+int(data[3]) {
+		return nil, errors.New("BGP MP_REACH_NLRI too short")
+	}
+	r := &BGPMPReach{BGPAddressFamily: BGPAddressFamily{
+		AFI:  BGPAFI(binary.BigEndian.Uint16(data[0:2])),
+		SAFI: BGPSAFI(data[2]),
+	}}
+	var err error
+	if r.NextHop, err = decodeBGPMPNextHop(data[4:4+int(data[3])], r.BGPAddressFamily); err != nil {
+		return nil, err
+	}
+	data = data[4+int(data[3]):]
+	// Skip the obsolete SNPAs, RFC 2858
+	snpas := int(data[0])
+	data = data[1:]
+	for i := 0; i < snpas; i++ {
+		if len(data) < 1 || len(data) < 1+(int(data[0])+1)/2 {
+			return nil, errors.New("BGP MP_REACH_NLRI SNPA too short")
+		}
+		data = data[1+(int(data[0])+1)/2:]
+	}
+	if !r.prefixes() {
+		r.RawNLRI = data
+		return r, nil
+	}
+	r.AddPath = session.addPath(r.BGPAddressFamily)
+	if r.NLRI, err = decodeBGPPrefixes(data, r.BGPAddressFamily, r.AddPath); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func decodeBGPMPUnreach(data []byte, session *BGPSession) (*BGPMPUnreach, error) {
+	if len(data) < 3 {
+		return nil, errors.New("BGP MP_UNREACH_NLRI too short")
+	}
+	u := &BGPMPUnreach{BGPAddressFamily: BGPAddressFamily{
+		AFI:  BGPAFI(binary.BigEndian.Uint16(data[0:2])),
+		SAFI: BGPSAFI(data[2]),
+	}}
+	if !u.prefixes() {
+		u.RawNLRI = data[3:]
+		return u, nil
+	}
+	u.AddPath = session.addPath(u.BGPAddressFamily)
+	var err error
+	if u.Withdrawn, err = decodeBGPPrefixes(data[3:], u.BGPAddressFamily, u.AddPath); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func bgpAttributeLengthError(a *BGPPathAttribute) error {
+	return fmt.Errorf("invalid BGP attribute %d length %d", a.Type, len(a.Value))
+}
+
+// decode decodes the value of the attribute, with AS numbers of asLen
+// bytes in AS_PATH and AGGREGATOR attributes.
+func (a *BGPPathAttribute) decode(asLen int, session *BGPSession) (err error) {
+	v := a.Value
+	switch a.Type {
+	case BGPAttributeOrigin:
+		if len(v) != 1 {
+			return bgpAttributeLengthError(a)
+		}
+		a.Origin = BGPOrigin(v[0])
+	case BGPAttributeASPath, BGPAttributeAS4Path:
+		if a.Type == BGPAttributeAS4Path {
+			asLen = 4
+		}
+		a.ASPath, err = decodeBGPASPath(v, asLen)
+	case BGPAttributeNextHop:
+		if len(v) != net.IPv4len {
+			return bgpAttributeLengthError(a)
+		}
+		a.NextHop = net.IP(v)
+	case BGPAttributeMED, BGPAttributeLocalPref:
+		if len(v) != 4 {
+			return bgpAttributeLengthError(a)
+		}
+		if a.Type == BGPAttributeMED {
+			a.MED = binary.BigEndian.Uint32(v)
+		} else {
+			a.LocalPref = binary.BigEndian.Uint32(v)
+		}
+	case BGPAttributeAtomicAggregate:
+		if len(v) != 0 {
+			return bgpAttributeLengthError(a)
+		}
+	case BGPAttributeAggregator, BGPAttributeAS4Aggregator:
+		if a.Type == BGPAttributeAS4Aggregator {
+			asLen = 4
+		}
+		if len(v) != asLen+net.IPv4len {
+			return bgpAttributeLengthError(a)
+		}
+		if asLen == 4 {
+			a.Aggregator.AS = binary.BigEndian.Uint32(v)
+		} else {
+			a.Aggregator.AS = uint32(binary.BigEndian.Uint16(v))
+		}
+		a.Aggregator.Address = net.IP(v[asLen:])
+	case BGPAttributeCommunities:
+		if len(v)%4 != 0 {
+			return bgpAttributeLengthError(a)
+		}
+		a.Communities = make([]BGPCommunity, len(v)/4)
+		for i := range a.Communities {
+			a.Communities[i] = BGPCommunity(binary.BigEndian.Uint32(v[4*i:]))
+		}
+	case BGPAttributeOriginatorID:
+		if len(v) != net.IPv4len {
+			return bgpAttributeLengthError(a)
+		}
+		a.OriginatorID = net.IP(v)
+	case BGPAttributeClusterList:
+		if len(v)%4 != 0 {
+			return bgpAttributeLengthError(a)
+		}
+		a.ClusterList = make([]net.IP, len(v)/4)
+		for i := range a.ClusterList {
+			a.ClusterList[i] = net.IP(v[4*i : 4*i+4])
+		}
+	case BGPAttributeMPReach:
+		a.MPReach, err = decodeBGPMPReach(v, session)
+	case BGPAttributeMPUnreach:
+		a.MPUnreach, err = decodeBGPMPUnreach(v, session)
+	case BGPAttributeExtendedCommunities:
+		if len(v)%8 != 0 {
+			return bgpAttributeLengthError(a)
+		}
+		a.ExtendedCommunities = make([]BGPExtendedCommunity, len(v)/8)
+		for i := range a.ExtendedCommunities {
+			a.ExtendedCommunities[i] = BGPExtendedCommunity(binary.BigEndian.Uint64(v[8*i:]))
+		}
+	case BGPAttributeLargeCommunities:
+		if len(v)%12 != 0 {
+			return bgpAttributeLengthError(a)
+		}
+		a.LargeCommunities = make([]BGPLargeCommunity, len(v)/12)
+		for i := range a.LargeCommunities {
+			a.LargeCommunities[i] = BGPLargeCommunity{
+				GlobalAdmin: binary.BigEndian.Uint32(v[12*i:]),
+				LocalData1:  binary.BigEndian.Uint32(v[12*i+4:]),
+				LocalData2:  binary.BigEndian.Uint32(v[12*i+8:]),
+			}
+		}
+	}
+	return err
+}
+
+func appendBGPIPv4(b []byte, ip net.IP) ([]byte, error) {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return nil, fmt.Errorf("invalid BGP IPv4 address %v", ip)
+	}
+	return append(b, ip4...), nil
+}
+
+// encodeValue returns the value of the attribute, with AS numbers of asLen
+// bytes in AS_PATH and AGGREGATOR attributes.
+func (a *BGPPathAttribute) encodeValue(asLen int) (b []byte, err error) {
+	switch a.Type {
+	case BGPAttributeOrigin:
+		return []byte{uint8(a.Origin)}, nil
+	case BGPAttributeASPath, BGPAttributeAS4Path:
+		if a.Type == BGPAttributeAS4Path {
+			asLen = 4
+		}
+		b = []byte{}
+		for _, seg := range a.ASPath {
+			if len(seg.ASNs) == 0 || len(seg.ASNs) > 0xff {
+				return nil, fmt.Errorf("invalid BGP AS path segment of %d AS numbers", len(seg.ASNs))
+			}
+			b = append(b, uint8(seg.Type), uint8(len(seg.ASNs)))
+			for _, asn := range seg.ASNs {
+				if asLen == 4 {
+					b = append(b, uint8(asn>>24), uint8(asn>>16))
+				} else if asn > 0xffff {
+					return nil, fmt.Errorf("BGP AS number %d too large for 2-octet AS path", asn)
+				}
+				b = append(b, uint8(asn>>8), uint8(asn))
+			}
+		}
+		return b, nil
+	case BGPAttributeNextHop:
+		return appendBGPIPv4(nil, a.NextHop)
+	case BGPAttributeMED:
+		return []byte{uint8(a.MED >> 24), uint8(a.MED >> 16), uint8(a.MED >> 8), uint8(a.MED)}, nil
+	case BGPAttributeLocalPref:
+		return []byte{uint8(a.LocalPref >> 24), uint8(a.LocalPref >> 16), uint8(a.LocalPref >> 8), uint8(a.LocalPref)}, nil
+	case BGPAttributeAtomicAggregate:
+		return []byte{}, nil
+	case BGPAttributeAggregator, BGPAttributeAS4Aggregator:
+		as := a.Aggregator.AS
+		if a.Type == BGPAttributeAS4Aggregator || asLen == 4 {
+			b = []byte{uint8(as >> 24), uint8(as >> 16), uint8(as >> 8), uint8(as)}
+		} else if as > 0xffff {
+			return nil, fmt.Errorf("BGP AS number %d too large for 2-octet aggregator", as)
+		} else {
+			b = []byte{uint8(as >> 8), uint8(as)}
+		}
+		return appendBGPIPv4(b, a.Aggregator.Address)
+	case BGPAttributeCommunities:
+		b = make([]byte, 4*len(a.Communities))
+		for i, c := range a.Communities {
+			binary.BigEndian.PutUint32(b[4*i:], uint32(c))
+		}
+		return b, nil
+	case BGPAttributeOriginatorID:
+		return appendBGPIPv4(nil, a.OriginatorID)
+	case BGPAttributeClusterList:
+		b = []byte{}
+		for _, id := range a.ClusterList {
+			if b, err = appendBGPIPv4(b, id); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case BGPAttributeMPReach:
+		if a.MPReach == nil {
+			return nil, errors.New("BGP MP_REACH_NLRI attribute without value")
+		}
+		return a.MPReach.encode()
+	case BGPAttributeMPUnreach:
+		u := a.MPUnreach
+		if u == nil {
+			return nil, errors.New("BGP MP_UNREACH_NLRI attribute without value")
+		}
+		b = []byte{uint8(u.AFI >> 8), uint8(u.AFI), uint8(u.SAFI)}
+		if !u.prefixes() {
+			return append(b, u.RawNLRI...), nil
+		}
+		return appendBGPPrefixes(b, u.Withdrawn, u.BGPAddressFamily, u.AddPath)
+	case BGPAttributeExtendedCommunities:
+		b = make([]byte, 8*len(a.ExtendedCommunities))
+		for i, c := range a.ExtendedCommunities {
+			binary.BigEndian.PutUint64(b[8*i:], uint64(c))
+		}
+		return b, nil
+	case BGPAttributeLargeCommunities:
+		b = make([]byte, 12*len(a.LargeCommunities))
+		for i, c := range a.LargeCommunities {
+			binary.BigEndian.PutUint32(b[12*i:], c.GlobalAdmin)
+			binary.BigEndian.PutUint32(b[12*i+4:], c.LocalData1)
+			binary.BigEndian.PutUint32(b[12*i+8:], c.LocalData2)
+		}
+		return b, nil
+	}
+	return a.Value, nil
+}
+
+func (r *BGPMPReach) encode() ([]byte, error) {
+	b := []byte{uint8(r.AFI >> 8), uint8(r.AFI), uint8(r.SAFI), 0}
+	for _, ip := range r.NextHop {
+		if r.SAFI == BGPSAFIMPLSVPN {
+			b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+		}
+		if ip4 := ip.To4(); ip4 != nil && r.AFI != BGPAFIIPv6 {
+			b = append(b, ip4...)
+		} else if len(ip) == net.IPv6len {
+			b = append(b, ip...)
+		} else {
+			return nil, fmt.Errorf("invalid BGP next hop %v", ip)
+		}
+	}
+	if len(b)-4 > 0xff {
+		return nil, errors.New("too many BGP next hops")
+	}
+	b[3] = uint8(len(b) - 4)
+	// No SNPAs
+	b = append(b, 0)
+	if !r.prefixes() {
+		return append(b, r.RawNLRI...), nil
+	}
+	return appendBGPPrefixes(b, r.NLRI, r.BGPAddressFamily, r.AddPath)
+}
+
+// BGPUpdate is the body of an UPDATE message.  WithdrawnRoutes and NLRI
+// are IPv4 unicast prefixes, the other address families are in the
+// MP_REACH_NLRI and MP_UNREACH_NLRI attributes.  AS4 is set if AS_PATH and
+// AGGREGATOR attributes have 4-octet AS numbers, and AddPath if the
+// WithdrawnRoutes and NLRI prefixes have path identifiers.
+type BGPUpdate struct {
+	WithdrawnRoutes []BGPPrefix
+	PathAttributes  []BGPPathAttribute
+	NLRI            []BGPPrefix
+	AS4             bool
+	AddPath         bool
+}
+
+// Attribute returns the first path attribute of type t, or nil.
+func (u *BGPUpdate) Attribute(t BGPAttributeType) *BGPPathAttribute {
+	for i := range u.PathAttributes {
+		if u.PathAttributes[i].Type == t {
+			return &u.PathAttributes[i]
+		}
+	}
+	return nil
+}
+
+// bgpDetectAS4 guesses whether the AS_PATH and AGGREGATOR attributes of
+// attrs have 4-octet AS numbers.  AS4_PATH and AS4_AGGREGATOR attributes
+// are only sent to 2-octet speakers, the AGGREGATOR length tells the AS
+// number length, and else AS_PATH is tried with 4-octet AS numbers.
+func bgpDetectAS4(attrs []BGPPathAttribute) bool {
+	for _, a := range attrs {
+		if a.Type == BGPAttributeAS4Path || a.Type == BGPAttributeAS4Aggregator {
+			return false
+		}
+	}
+	for _, a := range attrs {
+		if a.Type == BGPAttributeAggregator {
+			return len(a.Value) == 4+net.IPv4len
+		}
+	}
+	for _, a := range attrs {
+		if a.Type == BGPAttributeASPath {
+			_, err := decodeBGPASPath(a.Value, 4)
+			return err == nil
+		}
+	}
+	return true
+}
+
+func decodeBGPUpdate(data []byte, session *BGPSession) (*BGPUpdate, error) {
+	if len(data) < 2 || len(data) < 4+int(binary.BigEndian.Uint16(data)) {
+		return nil, errors.New("BGP update too short")
+	}
+	withdrawn := data[2 : 2+int(binary.BigEndian.Uint16(data))]
+	data = data[2+len(withdrawn):]
+	if len(data) < 2+int(binary.BigEndian.Uint16(data)) {
+		return nil, errors.New("BGP update path attributes too short")
+	}
+	attrs := data[2 : 2+int(binary.BigEndian.Uint16(data))]
+	nlri := data[2+len(attrs):]
+
+	u := &BGPUpdate{AddPath: session.addPath(bgpIPv4Unicast)}
+	var err error
+	if u.WithdrawnRoutes, err = decodeBGPPrefixes(withdrawn, bgpIPv4Unicast, u.AddPath); err != nil {
+		return nil, err
+	}
+	for len(attrs) > 0 {
+		if len(attrs) < 3 {
+			return nil, errors.New("BGP path attribute too short")
+		}
+		a := BGPPathAttribute{Flags: BGPAttributeFlags(attrs[0]), Type: BGPAttributeType(attrs[1])}
+		length, start := int(attrs[2]), 3
+		if a.Flags&BGPAttributeFlagExtendedLength != 0 {
+			if len(attrs) < 4 {
+				return nil, errors.New("BGP path attribute too short")
+			}
+			length, start = int(binary.BigEndian.Uint16(attrs[2:4])), 4
+		}
+		if len(attrs) < start+length {
+			return nil, errors.New("BGP path attribute too short")
+		}
+		a.Value = attrs[start : start+length]
+		attrs = attrs[start+length:]
+		u.PathAttributes = append(u.PathAttributes, a)
+	}
+	if session != nil {
+		u.AS4 = session.AS4
+	} else {
+		u.AS4 = bgpDetectAS4(u.PathAttributes)
+	}
+	asLen := 2
+	if u.AS4 {
+		asLen = 4
+	}
+	for i := range u.PathAttributes {
+		if err := u.PathAttributes[i].decode(asLen, session); err != nil {
+			return nil, err
+		}
+	}
+	if u.NLRI, err = decodeBGPPrefixes(nlri, bgpIPv4Unicast, u.AddPath); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (u *BGPUpdate) encode() ([]byte, error) {
+	b, err := appendBGPPrefixes([]byte{0, 0}, u.WithdrawnRoutes, bgpIPv4Unicast, u.AddPath)
+	if err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint16(b, uint16(len(b)-2))
+	start := len(b)
+	b = append(b, 0, 0)
+	asLen := 2
+	if u.AS4 {
+		asLen = 4
+	}
+	for i := range u.PathAttributes {
+		a := &u.PathAttributes[i]
+		value, err := a.encodeValue(asLen)
+		if err != nil {
+			return nil, err
+		}
+		if len(value) > 0xffff {
+			return nil, fmt.Errorf("BGP path attribute %d too long", a.Type)
+		}
+		flags := a.Flags
+		if len(value) > 0xff {
+			flags |= BGPAttributeFlagExtendedLength
+		}
+		if flags&BGPAttributeFlagExtendedLength != 0 {
+			b = append(b, uint8(flags), uint8(a.Type), uint8(len(value)>>8), uint8(len(value)))
+		} else {
+			b = append(b, uint8(flags), uint8(a.Type), uint8(len(value)))
+		}
+		b = append(b, value...)
+	}
+	if len(b)-start-2 > 0xffff {
+		return nil, errors.New("BGP path attributes too long")
+	}
+	binary.BigEndian.PutUint16(b[start:], uint16(len(b)-start-2))
+	return appendBGPPrefixes(b, u.NLRI, bgpIPv4Unicast, u.AddPath)
+}
+
+// BGPNotificationCode is the error code of a NOTIFICATION message.
+type BGPNotificationCode uint8
+
+// BGPNotificationCode known values, see RFC 4271, section 4.5 and RFC 7313
+const (
+	BGPNotificationMessageHeaderError BGPNotificationCode = 1
+	BGPNotificationOpenMessageError   BGPNotificationCode = 2
+	BGPNotificationUpdateMessageError BGPNotificationCode = 3
+	BGPNotificationHoldTimerExpired   BGPNotificationCode = 4
+	BGPNotificationFSMError           BGPNotificationCode = 5
+	BGPNotificationCease              BGPNotificationCode = 6
+	BGPNotificationRouteRefreshError  BGPNotificationCode = 7
+)
+
+func (c BGPNotificationCode) String() string {
+	switch c {
+	case BGPNotificationMessageHeaderError:
+		return "Message Header Error"
+	case BGPNotificationOpenMessageError:
+		return "OPEN Message Error"
+	case BGPNotificationUpdateMessageError:
+		return "UPDATE Message Error"
+	case BGPNotificationHoldTimerExpired:
+		return "Hold Timer Expired"
+	case BGPNotificationFSMError:
+		return "Finite State Machine Error"
+	case BGPNotificationCease:
+		return "Cease"
+	case BGPNotificationRouteRefreshError:
+		return "ROUTE-REFRESH Message Error"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(c))
+	}
+}
+
+// BGPNotification is the body of a NOTIFICATION message.
+type BGPNotification struct {
+	Code    BGPNotificationCode
+	Subcode uint8
+	Data    []byte
+}
+
+// BGPRouteRefresh is the body of a ROUTE-REFRESH message, RFC 2918.
+// Subtype is the enhanced route refresh message subtype, RFC 7313.
+type BGPRouteRefresh struct {
+	BGPAddressFamily
+	Subtype uint8
+}
+
+// BGP header and message lengths, see RFC 4271, section 4.1 and RFC 8654
+const (
+	bgpHeaderLength = 19
+	bgpMaxLength    = 65535
+)
+
+// BGP is a BGP-4 message, RFC 4271, carried over TCP.  The messages
+// following it in the same segment are decoded as the next layers.
+// Depending on Type, one of the message fields is set, KEEPALIVE messages
+// having no body.
+//
+// Without the session capabilities, AS numbers are guessed to be 4 octets
+// long from the AS_PATH and AGGREGATOR attributes, and prefixes are
+// decoded without ADD-PATH path identifiers.  BGPStream decodes the
+// messages of reassembled TCP sessions with the session capabilities.
+type BGP struct {
+	BaseLayer
+	Length       uint16
+	Type         BGPType
+	Open         *BGPOpen
+	Update       *BGPUpdate
+	Notification *BGPNotification
+	RouteRefresh *BGPRouteRefresh
+
+	session *BGPSession
+}
+
+// LayerType returns LayerTypeBGP.
+func (b *BGP) LayerType() gopacket.LayerType { return LayerTypeBGP }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (b *BGP) CanDecode() gopacket.LayerClass { return LayerTypeBGP }
+
+// NextLayerType returns LayerTypeBGP if another message follows.
+func (b *BGP) NextLayerType() gopacket.LayerType {
+	if len(b.Payload) > 0 {
+		return LayerTypeBGP
+	}
+	return gopacket.LayerTypeZero
+}
+
+func decodeBGP(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&BGP{}, data, p)
+}
+
+// bgpHeaderLengthOf checks the BGP header of data, returning the message
+// length.
+func bgpHeaderLengthOf(data []byte) (int, error) {
+	for _, m := range data[:16] {
+		if m != 0xff {
+			return 0, errors.New("invalid BGP marker")
+		}
+	}
+	length := int(binary.BigEndian.Uint16(data[16:18]))
+	if length < bgpHeaderLength {
+		return 0, fmt.Errorf("invalid BGP message length %d", length)
+	}
+	return length, nil
+}
+
+// DecodeFromBytes decodes the slice into the BGP struct.
+func (b *BGP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < bgpHeaderLength {
+		df.SetTruncated()
+		return errors.New("BGP header too short")
+	}
+	length, err := bgpHeaderLengthOf(data)
+	if err != nil {
+		return err
+	}
+	if len(data) < length {
+		df.SetTruncated()
+		return errors.New("BGP message too short")
+	}
+	*b = BGP{session: b.session, Length: uint16(length), Type: BGPType(data[18])}
+	body := data[bgpHeaderLength:length]
+	switch b.Type {
+	case BGPTypeOpen:
+		b.Open, err = decodeBGPOpen(body)
+	case BGPTypeUpdate:
+		b.Update, err = decodeBGPUpdate(body, b.session)
+	case BGPTypeNotification:
+		if len(body) < 2 {
+			return errors.New("BGP notification too short")
+		}
+		b.Notification = &BGPNotification{Code: BGPNotificationCode(body[0]), Subcode: body[1], Data: body[2:]}
+	case BGPTypeKeepalive:
+		if len(body) != 0 {
+			return errors.New("invalid BGP keepalive length")
+		}
+	case BGPTypeRouteRefresh:
+		if len(body) != 4 {
+			return errors.New("invalid BGP route refresh length")
+		}
+		b.RouteRefresh = &BGPRouteRefresh{
+			BGPAddressFamily: BGPAddressFamily{AFI: BGPAFI(binary.BigEndian.Uint16(body[0:2])), SAFI: BGPSAFI(body[3])},
+			Subtype:          body[2],
+		}
+	}
+	if err != nil {
+		return err
+	}
+	b.BaseLayer = BaseLayer{Contents: data[:length], Payload: data[length:]}
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+// See the docs for gopacket.SerializableLayer for more info.
+func (b *BGP) SerializeTo(buf gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	var body []byte
+	var err error
+	switch b.Type {
+	case BGPTypeOpen:
+		if b.Open == nil {
+			return errors.New("BGP open message without open body")
+		}
+		body, err = b.Open.encode()
+	case BGPTypeUpdate:
+		if b.Update == nil {
+			return errors.New("BGP update message without update body")
+		}
+		body, err = b.Update.encode()
+	case BGPTypeNotification:
+		if b.Notification == nil {
+			return errors.New("BGP notification message without notification body")
+		}
+		body = append([]byte{uint8(b.Notification.Code), b.Notification.Subcode}, b.Notification.Data...)
+	case BGPTypeRouteRefresh:
+		if b.RouteRefresh == nil {
+			return errors.New("BGP route refresh message without route refresh body")
+		}
+		rr := b.RouteRefresh
+		body = []byte{uint8(rr.AFI >> 8), uint8(rr.AFI), rr.Subtype, uint8(rr.SAFI)}
+	}
+	if err != nil {
+		return err
+	}
+	length := bgpHeaderLength + len(body)
+	if length > bgpMaxLength {
+		return errors.New("BGP message too long")
+	}
+	if opts.FixLengths {
+		b.Length = uint16(length)
+	}
+	bytes, err := buf.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 16; i++ {
+		bytes[i] = 0xff
+	}
+	binary.BigEndian.PutUint16(bytes[16:18], b.Length)
+	bytes[18] = uint8(b.Type)
+	copy(bytes[bgpHeaderLength:], body)
+	return nil
+}
+
+// BGPStream decodes the BGP messages sent in one direction of a TCP
+// session, whose data is passed to Decode as it's reassembled, e.g. by a
+// reassembly.Stream.
+type BGPStream struct {
+	// Session holds the capabilities used to decode UPDATE messages, which
+	// are guessed while it is nil.  It is set by OPEN messages, AS4 being
+	// set if 4-octet AS numbers are advertised, assuming the peer supports
+	// them too.  AddPath must be set by the caller, since it depends on the
+	// capabilities of both peers.
+	Session *BGPSession
+
+	buf    []byte
+	failed bool
+}
+
+// Decode decodes data following the data of previous calls, and returns the
+// messages completed by data.  The returned messages reference the data.  A
+// message failing to decode is skipped, so that decoding goes on with the
+// following messages, and the first error is returned with the messages
+// decoded.  After an invalid message header, the message boundaries are lost
+// and the stream can't be decoded any further.
+func (s *BGPStream) Decode(data []byte) ([]*BGP, error) {
+	if s.failed {
+		return nil, nil
+	}
+	s.buf = append(s.buf, data...)
+	var messages []*BGP
+	var firstErr error
+	for len(s.buf) >= bgpHeaderLength {
+		length, err := bgpHeaderLengthOf(s.buf)
+		if err != nil {
+			s.buf, s.failed = nil, true
+			return messages, err
+		}
+		if len(s.buf) < length {
+			break
+		}
+		b, err := decodeBGPMessage(s.buf[:length], s.Session)
+		s.buf = s.buf[length:]
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if b.Open != nil {
+			if s.Session == nil {
+				s.Session = &BGPSession{}
+			}
+			s.Session.AS4 = b.Open.AS4()
+		}
+		messages = append(messages, b)
+	}
+	if len(s.buf) == 0 {
+		s.buf = nil
+	}
+	return messages, firstErr
+}
+
+// ReadBGPMessage reads the next BGP message of a TCP stream from r, such
+// as a tcpreader.ReaderStream, decoding UPDATE messages with the
+// capabilities of session, which are guessed if session is nil.  It returns io.EOF if r ends between messages, and
+// io.ErrUnexpectedEOF if it ends within one.
+func ReadBGPMessage(r io.Reader, session *BGPSession) (*BGP, error) {
+	header := make([]byte, bgpHeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length, err := bgpHeaderLengthOf(header)
+	if err != nil {
+		return nil, err
+	}
+	msg := make([]byte, length)
+	copy(msg, header)
+	if _, err := io.ReadFull(r, msg[bgpHeaderLength:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return decodeBGPMessage(msg, session)
+}
+
+func decodeBGPMessage(msg []byte, session *BGPSession) (*BGP, error) {
+	b := &BGP{session: session}
+	if err := b.DecodeFromBytes(msg, gopacket.NilDecodeFeedback); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
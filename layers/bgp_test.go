@@ -0,0 +1,345 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketBGP is a TCP segment holding an OPEN advertising IPv4 unicast,
+// route refresh and 4-octet AS capabilities, an UPDATE with a 4-octet
+// AS_PATH and a KEEPALIVE.
+var testPacketBGP = []byte{
+	0xc3, 0x50, 0x00, 0xb3, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x50, 0x18, 0x40, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0x00, 0x2d, 0x01, 0x04, 0xfd, 0xe8, 0x00, 0xb4, 0xc0, 0x00, 0x02, 0x01,
+	0x10, 0x02, 0x0e, 0x01, 0x04, 0x00, 0x01, 0x00, 0x01, 0x02, 0x00, 0x41, 0x04, 0x00, 0x00, 0xfd,
+	0xe8, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0x00, 0x3a, 0x02, 0x00, 0x00, 0x00, 0x1f, 0x40, 0x01, 0x01, 0x00, 0x40, 0x02, 0x0a, 0x02,
+	0x02, 0x00, 0x00, 0xfd, 0xe8, 0x00, 0x00, 0xfd, 0xe9, 0x40, 0x03, 0x04, 0xc0, 0x00, 0x02, 0x01,
+	0xc0, 0x08, 0x04, 0xfd, 0xe8, 0x00, 0x64, 0x18, 0xc6, 0x33, 0x64, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x13, 0x04,
+}
+
+func TestBGPMessages(t *testing.T) {
+	p := gopacket.NewPacket(testPacketBGP, LayerTypeTCP, gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true})
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeTCP, LayerTypeBGP, LayerTypeBGP, LayerTypeBGP}, t)
+	layers := p.Layers()
+
+	open := layers[1].(*BGP)
+	wantOpen := &BGPOpen{
+		Version:    4,
+		MyAS:       65000,
+		HoldTime:   180,
+		Identifier: net.IP{192, 0, 2, 1},
+		Capabilities: []BGPCapability{
+			NewBGPMultiprotocolCapability(BGPAddressFamily{BGPAFIIPv4, BGPSAFIUnicast}),
+			{Code: BGPCapabilityRouteRefresh, Value: []byte{}},
+			NewBGPFourOctetASCapability(65000),
+		},
+	}
+	if open.Type != BGPTypeOpen || open.Length != 45 || !reflect.DeepEqual(open.Open, wantOpen) {
+		t.Errorf("BGP open mismatch:\ngot  %#v\nwant %#v", open.Open, wantOpen)
+	}
+	if asn := open.Open.ASN(); asn != 65000 {
+		t.Errorf("ASN %d", asn)
+	}
+	if mp := open.Open.Multiprotocol(); !reflect.DeepEqual(mp, []BGPAddressFamily{{BGPAFIIPv4, BGPSAFIUnicast}}) {
+		t.Errorf("multiprotocol capabilities %v", mp)
+	}
+
+	update := layers[2].(*BGP).Update
+	if update == nil || !update.AS4 || len(update.PathAttributes) != 4 {
+		t.Fatalf("BGP update mismatch: %#v", update)
+	}
+	if seg := update.Attribute(BGPAttributeASPath).ASPath; !reflect.DeepEqual(seg, []BGPASPathSegment{{Type: BGPASSequence, ASNs: []uint32{65000, 65001}}}) {
+		t.Errorf("AS path %v", seg)
+	}
+	if nh := update.Attribute(BGPAttributeNextHop).NextHop; !nh.Equal(net.IP{192, 0, 2, 1}) {
+		t.Errorf("next hop %v", nh)
+	}
+	if c := update.Attribute(BGPAttributeCommunities).Communities; len(c) != 1 || c[0].String() != "65000:100" {
+		t.Errorf("communities %v", c)
+	}
+	wantNLRI := []BGPPrefix{{Prefix: net.IPNet{IP: net.IP{198, 51, 100, 0}, Mask: net.CIDRMask(24, 32)}}}
+	if !reflect.DeepEqual(update.NLRI, wantNLRI) {
+		t.Errorf("NLRI %v", update.NLRI)
+	}
+	if keepalive := layers[3].(*BGP); keepalive.Type != BGPTypeKeepalive || len(keepalive.Payload) != 0 {
+		t.Errorf("BGP keepalive mismatch: %#v", keepalive)
+	}
+
+	// Each message serializes back to its bytes
+	for _, l := range layers[1:] {
+		buf := gopacket.NewSerializeBuffer()
+		if err := l.(*BGP).SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), l.LayerContents()) {
+			t.Errorf("%v serialization mismatch:\ngot  %x\nwant %x", l.(*BGP).Type, buf.Bytes(), l.LayerContents())
+		}
+	}
+}
+
+func TestBGPTwoOctetASPath(t *testing.T) {
+	update := &BGP{Type: BGPTypeUpdate, Update: &BGPUpdate{
+		PathAttributes: []BGPPathAttribute{
+			{Flags: BGPAttributeFlagTransitive, Type: BGPAttributeASPath, ASPath: []BGPASPathSegment{
+				{Type: BGPASSequence, ASNs: []uint32{64512, 23456}},
+				{Type: BGPASSet, ASNs: []uint32{64513}},
+			}},
+			{Flags: BGPAttributeFlagOptional | BGPAttributeFlagTransitive, Type: BGPAttributeAS4Path, ASPath: []BGPASPathSegment{
+				{Type: BGPASSequence, ASNs: []uint32{64512, 4200000000}},
+			}},
+			{Flags: BGPAttributeFlagOptional | BGPAttributeFlagTransitive, Type: BGPAttributeAggregator, Aggregator: BGPAggregator{AS: 64512, Address: net.IP{192, 0, 2, 9}}},
+		},
+	}}
+	buf := gopacket.NewSerializeBuffer()
+	if err := update.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeBGP, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	got := p.Layer(LayerTypeBGP).(*BGP).Update
+	if got.AS4 {
+		t.Error("2-octet AS path decoded as 4-octet")
+	}
+	for i := range update.Update.PathAttributes {
+		want := update.Update.PathAttributes[i]
+		want.Value = got.PathAttributes[i].Value
+		if !reflect.DeepEqual(got.PathAttributes[i], want) {
+			t.Errorf("attribute mismatch:\ngot  %#v\nwant %#v", got.PathAttributes[i], want)
+		}
+	}
+
+	update.Update.PathAttributes[0].ASPath[0].ASNs[0] = 4200000000
+	if err := update.SerializeTo(gopacket.NewSerializeBuffer(), gopacket.SerializeOptions{}); err == nil {
+		t.Error("serialized a 4-octet AS number in a 2-octet AS path")
+	}
+}
+
+func TestBGPRoundTrip(t *testing.T) {
+	rd := BGPRouteDistinguisher{0, 0, 0xfd, 0xe8, 0, 0, 0, 100}
+	if rd.String() != "65000:100" {
+		t.Errorf("route distinguisher %v", rd)
+	}
+	mustCIDR := func(s string) net.IPNet {
+		ip, n, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			n.IP = ip4
+		}
+		return *n
+	}
+	for _, test := range []struct {
+		name string
+		bgp  *BGP
+	}{
+		{
+			name: "open with extended parameters",
+			bgp: &BGP{Type: BGPTypeOpen, Open: &BGPOpen{
+				Version:      4,
+				MyAS:         23456,
+				HoldTime:     90,
+				Identifier:   net.IP{192, 0, 2, 2},
+				Capabilities: []BGPCapability{NewBGPFourOctetASCapability(4200000000), {Code: BGPCapabilityFQDN, Value: bytes.Repeat([]byte{'a'}, 255)}},
+				Parameters:   []BGPOptionalParameter{{Type: 1, Value: []byte{0, 1}}},
+			}},
+		},
+		{
+			name: "IPv6 unicast",
+			bgp: &BGP{Type: BGPTypeUpdate, Update: &BGPUpdate{
+				AS4: true,
+				PathAttributes: []BGPPathAttribute{
+					{Flags: BGPAttributeFlagTransitive, Type: BGPAttributeOrigin, Origin: BGPOriginIncomplete},
+					{Flags: BGPAttributeFlagTransitive, Type: BGPAttributeASPath, ASPath: []BGPASPathSegment{{Type: BGPASSequence, ASNs: []uint32{4200000000}}}},
+					{Flags: BGPAttributeFlagOptional, Type: BGPAttributeMPReach, MPReach: &BGPMPReach{
+						BGPAddressFamily: BGPAddressFamily{BGPAFIIPv6, BGPSAFIUnicast},
+						NextHop:          []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("fe80::1")},
+						NLRI: []BGPPrefix{
+							{Prefix: mustCIDR("2001:db8:1::/48")},
+							{Prefix: mustCIDR("::/0")},
+						},
+					}},
+					{Flags: BGPAttributeFlagOptional, Type: BGPAttributeMPUnreach, MPUnreach: &BGPMPUnreach{
+						BGPAddressFamily: BGPAddressFamily{BGPAFIIPv6, BGPSAFIUnicast},
+						Withdrawn:        []BGPPrefix{{Prefix: mustCIDR("2001:db8:2::/64")}},
+					}},
+					{Flags: BGPAttributeFlagOptional | BGPAttributeFlagTransitive, Type: BGPAttributeLargeCommunities, LargeCommunities: []BGPLargeCommunity{{4200000000, 1, 2}}},
+				},
+			}},
+		},
+		{
+			name: "VPNv4 and labeled IPv6",
+			bgp: &BGP{Type: BGPTypeUpdate, Update: &BGPUpdate{
+				AS4: true,
+				WithdrawnRoutes: []BGPPrefix{
+					{Prefix: mustCIDR("10.0.0.0/8")},
+				},
+				PathAttributes: []BGPPathAttribute{
+					{Flags: BGPAttributeFlagOptional, Type: BGPAttributeMPReach, MPReach: &BGPMPReach{
+						BGPAddressFamily: BGPAddressFamily{BGPAFIIPv4, BGPSAFIMPLSVPN},
+						NextHop:          []net.IP{{192, 0, 2, 3}},
+						NLRI: []BGPPrefix{
+							{Labels: []BGPLabel{16000<<4 | 1}, RD: rd, Prefix: mustCIDR("172.16.0.0/12")},
+						},
+					}},
+					{Flags: BGPAttributeFlagOptional, Type: BGPAttributeMPUnreach, MPUnreach: &BGPMPUnreach{
+						BGPAddressFamily: BGPAddressFamily{BGPAFIIPv6, BGPSAFILabeled},
+						Withdrawn:        []BGPPrefix{{Labels: []BGPLabel{bgpLabelWithdraw}, Prefix: mustCIDR("2001:db8:3::/48")}},
+					}},
+					{Flags: BGPAttributeFlagOptional | BGPAttributeFlagTransitive, Type: BGPAttributeExtendedCommunities, ExtendedCommunities: []BGPExtendedCommunity{0x0002fde800000064}},
+					{Flags: BGPAttributeFlagOptional, Type: BGPAttributeOriginatorID, OriginatorID: net.IP{192, 0, 2, 4}},
+					{Flags: BGPAttributeFlagOptional, Type: BGPAttributeClusterList, ClusterList: []net.IP{{192, 0, 2, 5}, {192, 0, 2, 6}}},
+				},
+				NLRI: []BGPPrefix{{Prefix: mustCIDR("0.0.0.0/0")}},
+			}},
+		},
+		{
+			name: "EVPN",
+			bgp: &BGP{Type: BGPTypeUpdate, Update: &BGPUpdate{
+				AS4: true,
+				PathAttributes: []BGPPathAttribute{
+					{Flags: BGPAttributeFlagOptional, Type: BGPAttributeMPReach, MPReach: &BGPMPReach{
+						BGPAddressFamily: BGPAddressFamily{BGPAFIL2VPN, BGPSAFIEVPN},
+						NextHop:          []net.IP{{192, 0, 2, 7}},
+						RawNLRI:          []byte{3, 17, 0, 1, 192, 0, 2, 7, 0, 100, 0, 0, 0, 0, 32, 192, 0, 2, 7},
+					}},
+					{Flags: BGPAttributeFlagOptional | BGPAttributeFlagTransitive, Type: 99, Value: []byte{1, 2, 3}},
+				},
+			}},
+		},
+		{
+			name: "notification",
+			bgp:  &BGP{Type: BGPTypeNotification, Notification: &BGPNotification{Code: BGPNotificationCease, Subcode: 2, Data: []byte{0, 1, 1, 0, 0, 0, 10}}},
+		},
+		{
+			name: "route refresh",
+			bgp:  &BGP{Type: BGPTypeRouteRefresh, RouteRefresh: &BGPRouteRefresh{BGPAddressFamily: BGPAddressFamily{BGPAFIIPv6, BGPSAFIUnicast}, Subtype: 1}},
+		},
+	} {
+		buf := gopacket.NewSerializeBuffer()
+		if err := test.bgp.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		p := gopacket.NewPacket(buf.Bytes(), LayerTypeBGP, testDecodeOptions)
+		if p.ErrorLayer() != nil {
+			t.Errorf("%s: failed to decode packet: %v", test.name, p.ErrorLayer().Error())
+			continue
+		}
+		got := p.Layer(LayerTypeBGP).(*BGP)
+		test.bgp.BaseLayer = got.BaseLayer
+		if got.Update != nil {
+			for i := range got.Update.PathAttributes {
+				test.bgp.Update.PathAttributes[i].Value = got.Update.PathAttributes[i].Value
+			}
+		}
+		if !reflect.DeepEqual(got, test.bgp) {
+			t.Errorf("%s: BGP mismatch:\ngot  %#v\nwant %#v", test.name, got, test.bgp)
+		}
+	}
+}
+
+func TestBGPStream(t *testing.T) {
+	addPath := []BGPAddressFamily{bgpIPv4Unicast}
+	messages := []*BGP{
+		{Type: BGPTypeKeepalive},
+		{Type: BGPTypeUpdate, Update: &BGPUpdate{AddPath: true, NLRI: []BGPPrefix{
+			{PathID: 1, Prefix: net.IPNet{IP: net.IP{198, 51, 100, 0}, Mask: net.CIDRMask(24, 32)}},
+			{PathID: 2, Prefix: net.IPNet{IP: net.IP{198, 51, 100, 0}, Mask: net.CIDRMask(24, 32)}},
+		}}},
+	}
+	var stream []byte
+	for _, m := range append([]*BGP{{Type: BGPTypeOpen, Open: &BGPOpen{
+		Version:      4,
+		MyAS:         65000,
+		HoldTime:     180,
+		Identifier:   net.IP{192, 0, 2, 1},
+		Capabilities: []BGPCapability{NewBGPFourOctetASCapability(65000)},
+	}}}, messages...) {
+		buf := gopacket.NewSerializeBuffer()
+		if err := m.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+			t.Fatal(err)
+		}
+		stream = append(stream, buf.Bytes()...)
+	}
+
+	s := &BGPStream{Session: &BGPSession{AddPath: addPath}}
+	var got []*BGP
+	// Feed the stream in small segments, splitting the headers
+	for data := stream; len(data) > 0; {
+		n := 7
+		if n > len(data) {
+			n = len(data)
+		}
+		m, err := s.Decode(data[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, m...)
+		data = data[n:]
+	}
+	if len(s.buf) != 0 {
+		t.Errorf("%d bytes left buffered", len(s.buf))
+	}
+	if len(got) != 3 || got[0].Open == nil || !s.Session.AS4 {
+		t.Fatalf("unexpected messages %v, session %+v", got, s.Session)
+	}
+	if nlri := got[2].Update.NLRI; len(nlri) != 2 || nlri[1].PathID != 2 {
+		t.Errorf("ADD-PATH NLRI %v", nlri)
+	}
+
+	// The same messages read from a reader
+	r := bytes.NewReader(stream)
+	for i := 0; ; i++ {
+		m, err := ReadBGPMessage(r, s.Session)
+		if err == io.EOF {
+			if i != 3 {
+				t.Errorf("read %d messages", i)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 2 && !reflect.DeepEqual(m.Update, got[2].Update) {
+			t.Errorf("read update mismatch:\ngot  %#v\nwant %#v", m.Update, got[2].Update)
+		}
+	}
+	if _, err := ReadBGPMessage(bytes.NewReader(stream[:30]), nil); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated stream error %v", err)
+	}
+
+	// A message failing to decode is skipped
+	bad := append(bytes.Repeat([]byte{0xff}, 16), 0, bgpHeaderLength, byte(BGPTypeUpdate))
+	m, err := (&BGPStream{Session: &BGPSession{AddPath: addPath}}).Decode(append(bad, stream...))
+	if err == nil || len(m) != 3 {
+		t.Errorf("decoded %d messages, error %v", len(m), err)
+	}
+
+	// A bad marker loses the message boundaries
+	if _, err := s.Decode(bytes.Repeat([]byte{0}, bgpHeaderLength)); err == nil || len(s.buf) != 0 {
+		t.Errorf("bad marker error %v, %d bytes buffered", err, len(s.buf))
+	}
+	if m, err := s.Decode(stream); m != nil || err != nil {
+		t.Errorf("decoded %v, error %v after a bad marker", m, err)
+	}
+}
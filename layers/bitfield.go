@@ -5,14 +5,22 @@
 
 package layers
 
+import "sync/atomic"
+
+// bitfield is safe for concurrent use.
 type bitfield [1024]uint64
 
 // set sets bit i in bitfield b to 1.
 func (b *bitfield) set(i uint16) {
-	b[i>>6] |= (1 << (i & 0x3f))
+	for {
+		old := atomic.LoadUint64(&b[i>>6])
+		if atomic.CompareAndSwapUint64(&b[i>>6], old, old|(1<<(i&0x3f))) {
+			return
+		}
+	}
 }
 
 // has reports whether bit i is set to 1 in bitfield b.
 func (b *bitfield) has(i uint16) bool {
-	return b[i>>6]&(1<<(i&0x3f)) != 0
+	return atomic.LoadUint64(&b[i>>6])&(1<<(i&0x3f)) != 0
 }
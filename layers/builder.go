@@ -0,0 +1,278 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// Builder crafts packets layer by layer, filling in what SerializeLayers
+// otherwise needs to be told, which makes generating test traffic easy:
+//
+//	data, err := layers.Build().
+//	  Ethernet(nil).
+//	  IPv4(&layers.IPv4{SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}).
+//	  TCP(&layers.TCP{SrcPort: 1234, DstPort: 80, SYN: true}).
+//	  Payload([]byte("hello")).
+//	  Bytes()
+//
+// Passing nil to a layer method adds the layer with default fields.  When
+// the packet is serialized, the fields chaining the layers, like
+// Ethernet.EthernetType and IPv4.Protocol, are set from the following layer
+// if they are zero and the following layer is known, unless Keep was called
+// for the layer, zero IP versions and TTLs get their usual values, nil
+// addresses are set to the loopback addresses (MAC addresses to all zeros),
+// and the network layer of TCP, UDP and ICMPv6 checksums is set.  Lengths and
+// checksums are fixed by Bytes, or by Serialize if opts ask for it.  The
+// layers passed to a Builder are modified by it.
+type Builder struct {
+	layers []gopacket.SerializableLayer
+	// keep holds the indexes of the layers whose chaining fields are kept
+	keep map[int]bool
+}
+
+// Build returns an empty Builder.
+func Build() *Builder {
+	return &Builder{}
+}
+
+// Layer adds l to the packet.  It's used for layers without a method of
+// their own; the following layer types are still set if l is one of the
+// layers with a method.
+func (b *Builder) Layer(l gopacket.SerializableLayer) *Builder {
+	b.layers = append(b.layers, l)
+	return b
+}
+
+// Keep keeps the fields chaining the last added layer to the following one
+// even if they are zero, since zero values like EthernetTypeLLC or
+// IPProtocolIPv6HopByHop are valid.
+func (b *Builder) Keep() *Builder {
+	if len(b.layers) > 0 {
+		if b.keep == nil {
+			b.keep = map[int]bool{}
+		}
+		b.keep[len(b.layers)-1] = true
+	}
+	return b
+}
+
+// Ethernet adds an Ethernet layer.
+func (b *Builder) Ethernet(eth *Ethernet) *Builder {
+	if eth == nil {
+		eth = &Ethernet{}
+	}
+	return b.Layer(eth)
+}
+
+// Dot1Q adds an 802.1Q VLAN tag.
+func (b *Builder) Dot1Q(d *Dot1Q) *Builder {
+	if d == nil {
+		d = &Dot1Q{}
+	}
+	return b.Layer(d)
+}
+
+// IPv4 adds an IPv4 layer.
+func (b *Builder) IPv4(ip *IPv4) *Builder {
+	if ip == nil {
+		ip = &IPv4{}
+	}
+	return b.Layer(ip)
+}
+
+// IPv6 adds an IPv6 layer.
+func (b *Builder) IPv6(ip *IPv6) *Builder {
+	if ip == nil {
+		ip = &IPv6{}
+	}
+	return b.Layer(ip)
+}
+
+// TCP adds a TCP layer.  A nil tcp defaults to a SYN from port 20 to port 80.
+func (b *Builder) TCP(tcp *TCP) *Builder {
+	if tcp == nil {
+		tcp = &TCP{SrcPort: 20, DstPort: 80, SYN: true, Window: 8192}
+	}
+	return b.Layer(tcp)
+}
+
+// UDP adds a UDP layer.  A nil udp defaults to port 53 to port 53.
+func (b *Builder) UDP(udp *UDP) *Builder {
+	if udp == nil {
+		udp = &UDP{SrcPort: 53, DstPort: 53}
+	}
+	return b.Layer(udp)
+}
+
+// ICMPv4 adds an ICMPv4 layer.  A nil icmp defaults to an echo request.
+func (b *Builder) ICMPv4(icmp *ICMPv4) *Builder {
+	if icmp == nil {
+		icmp = &ICMPv4{TypeCode: CreateICMPv4TypeCode(ICMPv4TypeEchoRequest, 0)}
+	}
+	return b.Layer(icmp)
+}
+
+// ICMPv6 adds an ICMPv6 layer.  A nil icmp defaults to an echo request,
+// whose identifier and sequence number follow in an ICMPv6Echo layer.
+func (b *Builder) ICMPv6(icmp *ICMPv6) *Builder {
+	if icmp == nil {
+		icmp = &ICMPv6{TypeCode: CreateICMPv6TypeCode(ICMPv6TypeEchoRequest, 0)}
+	}
+	return b.Layer(icmp)
+}
+
+// Payload adds data as the payload of the packet.
+func (b *Builder) Payload(data []byte) *Builder {
+	return b.Layer(gopacket.Payload(data))
+}
+
+// Layers returns the layers of the packet after setting their defaults.
+func (b *Builder) Layers() []gopacket.SerializableLayer {
+	var network gopacket.NetworkLayer
+	for i, l := range b.layers {
+		var next gopacket.LayerType
+		if i+1 < len(b.layers) && !b.keep[i] {
+			next = b.layers[i+1].LayerType()
+		}
+		switch l := l.(type) {
+		case *Ethernet:
+			if l.SrcMAC == nil {
+				l.SrcMAC = make(net.HardwareAddr, 6)
+			}
+			if l.DstMAC == nil {
+				l.DstMAC = make(net.HardwareAddr, 6)
+			}
+			if t, ok := builderEthernetType(next); ok && l.EthernetType == 0 {
+				l.EthernetType = t
+			}
+		case *Dot1Q:
+			if t, ok := builderEthernetType(next); ok && l.Type == 0 {
+				l.Type = t
+			}
+		case *IPv4:
+			if l.Version == 0 {
+				l.Version = 4
+			}
+			if l.TTL == 0 {
+				l.TTL = 64
+			}
+			if l.SrcIP == nil {
+				l.SrcIP = net.IPv4(127, 0, 0, 1)
+			}
+			if l.DstIP == nil {
+				l.DstIP = net.IPv4(127, 0, 0, 1)
+			}
+			if p, ok := builderIPProtocol(next); ok && l.Protocol == 0 {
+				l.Protocol = p
+			}
+			network = l
+		case *IPv6:
+			if l.Version == 0 {
+				l.Version = 6
+			}
+			if l.HopLimit == 0 {
+				l.HopLimit = 64
+			}
+			if l.SrcIP == nil {
+				l.SrcIP = net.IPv6loopback
+			}
+			if l.DstIP == nil {
+				l.DstIP = net.IPv6loopback
+			}
+			if p, ok := builderIPProtocol(next); ok && l.NextHeader == 0 {
+				l.NextHeader = p
+			}
+			network = l
+		case *TCP:
+			if network != nil {
+				l.SetNetworkLayerForChecksum(network)
+			}
+		case *UDP:
+			if network != nil {
+				l.SetNetworkLayerForChecksum(network)
+			}
+		case *ICMPv6:
+			if network != nil {
+				l.SetNetworkLayerForChecksum(network)
+			}
+		}
+	}
+	return b.layers
+}
+
+// Serialize serializes the packet with opts and returns its data.
+func (b *Builder) Serialize(opts gopacket.SerializeOptions) ([]byte, error) {
+	buf := gopacket.NewSerializeBuffer()
+	if err := b.SerializeTo(buf, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SerializeTo serializes the packet with opts into buf.
+func (b *Builder) SerializeTo(buf gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	return gopacket.SerializeLayers(buf, opts, b.Layers()...)
+}
+
+// Bytes serializes the packet with fixed lengths and computed checksums, and
+// returns its data.
+func (b *Builder) Bytes() ([]byte, error) {
+	return b.Serialize(gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true})
+}
+
+// builderEthernetType returns the EthernetType of the layer type following an
+// Ethernet or Dot1Q layer, and false if there is none
+func builderEthernetType(next gopacket.LayerType) (EthernetType, bool) {
+	switch next {
+	case LayerTypeIPv4:
+		return EthernetTypeIPv4, true
+	case LayerTypeIPv6:
+		return EthernetTypeIPv6, true
+	case LayerTypeDot1Q:
+		return EthernetTypeDot1Q, true
+	case LayerTypeARP:
+		return EthernetTypeARP, true
+	case LayerTypeMPLS:
+		return EthernetTypeMPLSUnicast, true
+	}
+	return 0, false
+}
+
+// builderIPProtocol returns the IPProtocol of the layer type following an IP
+// layer, and false if there is none
+func builderIPProtocol(next gopacket.LayerType) (IPProtocol, bool) {
+	switch next {
+	case LayerTypeTCP:
+		return IPProtocolTCP, true
+	case LayerTypeUDP:
+		return IPProtocolUDP, true
+	case LayerTypeICMPv4:
+		return IPProtocolICMPv4, true
+	case LayerTypeICMPv6:
+		return IPProtocolICMPv6, true
+	case LayerTypeSCTP:
+		return IPProtocolSCTP, true
+	case LayerTypeIPv4:
+		return IPProtocolIPv4, true
+	case LayerTypeIPv6:
+		return IPProtocolIPv6, true
+	case LayerTypeGRE:
+		return IPProtocolGRE, true
+	case LayerTypeIPv6HopByHop:
+		return IPProtocolIPv6HopByHop, true
+	case LayerTypeIPv6Routing:
+		return IPProtocolIPv6Routing, true
+	case LayerTypeIPv6Fragment:
+		return IPProtocolIPv6Fragment, true
+	case LayerTypeIPv6Destination:
+		return IPProtocolIPv6Destination, true
+	}
+	return 0, false
+}
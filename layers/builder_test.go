@@ -0,0 +1,95 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *Builder
+		want    []gopacket.LayerType
+	}{
+		{"tcp", Build().Ethernet(nil).IPv4(&IPv4{SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}).TCP(nil).Payload([]byte("hello")),
+			[]gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeTCP, gopacket.LayerTypePayload}},
+		{"udp over ipv6 over vlan", Build().Ethernet(nil).Dot1Q(&Dot1Q{VLANIdentifier: 42}).IPv6(nil).UDP(&UDP{SrcPort: 1234, DstPort: 9999}).Payload([]byte{1, 2, 3}),
+			[]gopacket.LayerType{LayerTypeEthernet, LayerTypeDot1Q, LayerTypeIPv6, LayerTypeUDP, gopacket.LayerTypePayload}},
+		{"icmpv4", Build().Ethernet(nil).IPv4(nil).ICMPv4(nil),
+			[]gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeICMPv4}},
+		{"icmpv6", Build().Ethernet(nil).IPv6(nil).ICMPv6(nil).Layer(&ICMPv6Echo{Identifier: 1, SeqNumber: 2}),
+			[]gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv6, LayerTypeICMPv6, LayerTypeICMPv6Echo}},
+	}
+	for _, test := range tests {
+		data, err := test.builder.Bytes()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default)
+		if p.ErrorLayer() != nil {
+			t.Errorf("%s: %v", test.name, p.ErrorLayer().Error())
+			continue
+		}
+		var types []gopacket.LayerType
+		for _, l := range p.Layers() {
+			types = append(types, l.LayerType())
+		}
+		if !reflect.DeepEqual(types, test.want) {
+			t.Errorf("%s: expected layers %v, got %v", test.name, test.want, types)
+		}
+		for _, r := range gopacket.VerifyChecksums(p) {
+			if r.Status != gopacket.ChecksumValid {
+				t.Errorf("%s: %v checksum %v (%v)", test.name, r.LayerType, r.Status, r.Err)
+			}
+		}
+	}
+}
+
+func TestBuilderKeepsFields(t *testing.T) {
+	ip := &IPv4{Version: 4, TTL: 1, Protocol: IPProtocolUDP, SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}
+	data, err := Build().IPv4(ip).TCP(&TCP{SrcPort: 1, DstPort: 2}).Serialize(gopacket.SerializeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip.TTL != 1 || ip.Protocol != IPProtocolUDP {
+		t.Errorf("fields overwritten: %+v", ip)
+	}
+	// lengths aren't fixed without FixLengths
+	if !bytes.Equal(data[2:4], []byte{0, 0}) {
+		t.Errorf("expected zero length, got %x", data[2:4])
+	}
+}
+
+func TestBuilderKeep(t *testing.T) {
+	ip := &IPv4{Protocol: IPProtocolIPv6HopByHop}
+	data, err := Build().Ethernet(nil).IPv4(ip).Keep().TCP(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip.Protocol != IPProtocolIPv6HopByHop || data[14+9] != 0 {
+		t.Errorf("explicit zero protocol overwritten: %d", data[14+9])
+	}
+	// other defaults still apply
+	if ip.TTL != 64 || ip.SrcIP == nil {
+		t.Errorf("defaults not set: %+v", ip)
+	}
+
+	eth := &Ethernet{}
+	if _, err := Build().Ethernet(eth).IPv4(nil).Bytes(); err != nil {
+		t.Fatal(err)
+	}
+	if eth.EthernetType != EthernetTypeIPv4 {
+		t.Errorf("expected EthernetType IPv4 without Keep, got %v", eth.EthernetType)
+	}
+}
@@ -219,6 +219,10 @@ func (c *CiscoDiscovery) LayerType() gopacket.LayerType {
 }
 
 func decodeCiscoDiscovery(data []byte, p gopacket.PacketBuilder) error {
+	if len(data) < 4 {
+		p.SetTruncated()
+		return errors.New("CiscoDiscovery packet too short")
+	}
 	c := &CiscoDiscovery{
 		Version:  data[0],
 		TTL:      data[1],
@@ -238,6 +242,63 @@ func decodeCiscoDiscovery(data []byte, p gopacket.PacketBuilder) error {
 	return p.NextDecoder(gopacket.DecodeFunc(decodeCiscoDiscoveryInfo))
 }
 
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.  The TLVs
+// are written from Values, which can be encoded by CiscoDiscoveryInfo.Values().
+func (c *CiscoDiscovery) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	length := 4
+	for _, v := range c.Values {
+		if len(v.Value) > 0xffff-4 {
+			return fmt.Errorf("CiscoDiscovery TLV %v too long", v.Type)
+		}
+		length += 4 + len(v.Value)
+	}
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	bytes[0] = c.Version
+	bytes[1] = c.TTL
+	tlvs := bytes[4:]
+	for i := range c.Values {
+		v := &c.Values[i]
+		if opts.FixLengths {
+			v.Length = uint16(len(v.Value) + 4)
+		}
+		binary.BigEndian.PutUint16(tlvs[0:2], uint16(v.Type))
+		binary.BigEndian.PutUint16(tlvs[2:4], v.Length)
+		copy(tlvs[4:], v.Value)
+		tlvs = tlvs[4+len(v.Value):]
+	}
+	if opts.ComputeChecksums {
+		bytes[2], bytes[3] = 0, 0
+		c.Checksum = cdpChecksum(bytes)
+	}
+	binary.BigEndian.PutUint16(bytes[2:4], c.Checksum)
+	return nil
+}
+
+// VerifyChecksum verifies the checksum of the CDP packet, implementing
+// gopacket.ChecksumVerifier.
+func (c *CiscoDiscovery) VerifyChecksum() (bool, error) {
+	data := append(append([]byte(nil), c.Contents...), c.Payload...)
+	return cdpChecksum(data) == 0, nil
+}
+
+// cdpChecksum computes the rfc1071 checksum of CDP packets, with a quirk of
+// Cisco's implementation: the last byte of odd length packets is added as a
+// sign extended word, decremented when negative.
+func cdpChecksum(data []byte) uint16 {
+	if len(data)%2 == 0 {
+		return tcpipChecksum(data, 0)
+	}
+	last := uint32(data[len(data)-1])
+	if last&0x80 != 0 {
+		last = 0xff00 | (last - 1)
+	}
+	return tcpipChecksum(data[:len(data)-1], last)
+}
+
 // LayerType returns gopacket.LayerTypeCiscoDiscoveryInfo.
 func (c *CiscoDiscoveryInfo) LayerType() gopacket.LayerType {
 	return LayerTypeCiscoDiscoveryInfo
@@ -312,7 +373,10 @@ func decodeCiscoDiscoveryInfo(data []byte, p gopacket.PacketBuilder) error {
 			l := len(v)
 			if l%5 == 0 && l >= 5 {
 				for len(v) > 0 {
-					_, ipnet, _ := net.ParseCIDR(fmt.Sprintf("%d.%d.%d.%d/%d", v[0], v[1], v[2], v[3], v[4]))
+					_, ipnet, err := net.ParseCIDR(fmt.Sprintf("%d.%d.%d.%d/%d", v[0], v[1], v[2], v[3], v[4]))
+					if err != nil {
+						return err
+					}
 					info.IPPrefixes = append(info.IPPrefixes, *ipnet)
 					v = v[5:]
 				}
@@ -407,7 +471,7 @@ func decodeCiscoDiscoveryInfo(data []byte, p gopacket.PacketBuilder) error {
 			}
 			info.PowerRequest.ID = binary.BigEndian.Uint16(val.Value[0:2])
 			info.PowerRequest.MgmtID = binary.BigEndian.Uint16(val.Value[2:4])
-			for n := 4; n < len(val.Value); n += 4 {
+			for n := 4; n+4 <= len(val.Value); n += 4 {
 				info.PowerRequest.Values = append(info.PowerRequest.Values, binary.BigEndian.Uint32(val.Value[n:n+4]))
 			}
 		case CDPTLVPowerAvailable:
@@ -416,7 +480,7 @@ func decodeCiscoDiscoveryInfo(data []byte, p gopacket.PacketBuilder) error {
 			}
 			info.PowerAvailable.ID = binary.BigEndian.Uint16(val.Value[0:2])
 			info.PowerAvailable.MgmtID = binary.BigEndian.Uint16(val.Value[2:4])
-			for n := 4; n < len(val.Value); n += 4 {
+			for n := 4; n+4 <= len(val.Value); n += 4 {
 				info.PowerAvailable.Values = append(info.PowerAvailable.Values, binary.BigEndian.Uint32(val.Value[n:n+4]))
 			}
 			//		case CDPTLVPortUnidirectional
@@ -453,13 +517,13 @@ func decodeCiscoDiscoveryInfo(data []byte, p gopacket.PacketBuilder) error {
 				data = data[8:]
 				switch tType {
 				case CDPEnergyWiseRole:
-					info.EnergyWise.Role = string(data[:])
+					info.EnergyWise.Role = string(data[:tLen])
 				case CDPEnergyWiseDomain:
-					info.EnergyWise.Domain = string(data[:])
+					info.EnergyWise.Domain = string(data[:tLen])
 				case CDPEnergyWiseName:
-					info.EnergyWise.Name = string(data[:])
+					info.EnergyWise.Name = string(data[:tLen])
 				case CDPEnergyWiseReplyTo:
-					if len(data) >= 18 {
+					if tLen >= 18 {
 						info.EnergyWise.ReplyUnknown1 = data[0:2]
 						info.EnergyWise.ReplyPort = data[2:4]
 						info.EnergyWise.ReplyAddress = data[4:8]
@@ -485,6 +549,158 @@ func decodeCiscoDiscoveryInfo(data []byte, p gopacket.PacketBuilder) error {
 	return nil
 }
 
+// SerializeTo writes nothing, implementing gopacket.SerializableLayer: the
+// TLVs are serialized by the CiscoDiscovery layer, from its Values.
+func (c *CiscoDiscoveryInfo) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	return nil
+}
+
+// Values encodes the set fields into TLVs, as set in the Values of a
+// CiscoDiscovery layer to serialize.  Zero values, like a false FullDuplex,
+// aren't encoded.  EnergyWise isn't encoded; its TLV can be added to
+// Unknown.
+func (c *CiscoDiscoveryInfo) Values() []CiscoDiscoveryValue {
+	var vals []CiscoDiscoveryValue
+	add := func(t CDPTLVType, v []byte) {
+		vals = append(vals, CiscoDiscoveryValue{Type: t, Length: uint16(len(v) + 4), Value: v})
+	}
+	addString := func(t CDPTLVType, v string) {
+		if v != "" {
+			add(t, []byte(v))
+		}
+	}
+	addUint := func(t CDPTLVType, v uint32, size int) {
+		if v != 0 {
+			b := make([]byte, 4)
+			binary.BigEndian.PutUint32(b, v)
+			add(t, b[4-size:])
+		}
+	}
+	addString(CDPTLVDevID, c.DeviceID)
+	if len(c.Addresses) > 0 {
+		add(CDPTLVAddress, encodeAddresses(c.Addresses))
+	}
+	addString(CDPTLVPortID, c.PortID)
+	if c.Capabilities != (CDPCapabilities{}) {
+		var caps CDPCapability
+		for _, bit := range []struct {
+			set  bool
+			mask CDPCapability
+		}{
+			{c.Capabilities.L3Router, CDPCapMaskRouter},
+			{c.Capabilities.TBBridge, CDPCapMaskTBBridge},
+			{c.Capabilities.SPBridge, CDPCapMaskSPBridge},
+			{c.Capabilities.L2Switch, CDPCapMaskSwitch},
+			{c.Capabilities.IsHost, CDPCapMaskHost},
+			{c.Capabilities.IGMPFilter, CDPCapMaskIGMPFilter},
+			{c.Capabilities.L1Repeater, CDPCapMaskRepeater},
+			{c.Capabilities.IsPhone, CDPCapMaskPhone},
+			{c.Capabilities.RemotelyManaged, CDPCapMaskRemote},
+		} {
+			if bit.set {
+				caps |= bit.mask
+			}
+		}
+		addUint(CDPTLVCapabilities, uint32(caps), 4)
+	}
+	addString(CDPTLVVersion, c.Version)
+	addString(CDPTLVPlatform, c.Platform)
+	if len(c.IPPrefixes) > 0 {
+		var v []byte
+		for _, prefix := range c.IPPrefixes {
+			ones, _ := prefix.Mask.Size()
+			v = append(append(v, prefix.IP.To4()...), byte(ones))
+		}
+		add(CDPTLVIPPrefix, v)
+	}
+	if h := c.CDPHello; h.OUI != nil {
+		v := make([]byte, 32)
+		copy(v[0:3], h.OUI)
+		binary.BigEndian.PutUint16(v[3:5], h.ProtocolID)
+		copy(v[5:9], h.ClusterMaster.To4())
+		copy(v[9:13], h.Unknown1.To4())
+		v[13], v[14], v[15], v[16] = h.Version, h.SubVersion, h.Status, h.Unknown2
+		copy(v[17:23], h.ClusterCommander)
+		copy(v[23:29], h.SwitchMAC)
+		v[29] = h.Unknown3
+		binary.BigEndian.PutUint16(v[30:32], h.ManagementVLAN)
+		add(CDPTLVHello, v)
+	}
+	addString(CDPTLVVTPDomain, c.VTPDomain)
+	addUint(CDPTLVNativeVLAN, uint32(c.NativeVLAN), 2)
+	if c.FullDuplex {
+		add(CDPTLVFullDuplex, []byte{1})
+	}
+	if c.VLANReply != (CDPVLANDialogue{}) {
+		add(CDPTLVVLANReply, []byte{c.VLANReply.ID, byte(c.VLANReply.VLAN >> 8), byte(c.VLANReply.VLAN)})
+	}
+	if c.VLANQuery != (CDPVLANDialogue{}) {
+		add(CDPTLVVLANQuery, []byte{c.VLANQuery.ID, byte(c.VLANQuery.VLAN >> 8), byte(c.VLANQuery.VLAN)})
+	}
+	addUint(CDPTLVPower, uint32(c.PowerConsumption), 2)
+	addUint(CDPTLVMTU, c.MTU, 4)
+	addUint(CDPTLVExtendedTrust, uint32(c.ExtendedTrust), 1)
+	addUint(CDPTLVUntrustedCOS, uint32(c.UntrustedCOS), 1)
+	addString(CDPTLVSysName, c.SysName)
+	addString(CDPTLVSysOID, c.SysOID)
+	if len(c.MgmtAddresses) > 0 {
+		add(CDPTLVMgmtAddresses, encodeAddresses(c.MgmtAddresses))
+	}
+	if c.Location != (CDPLocation{}) {
+		add(CDPTLVLocation, append([]byte{c.Location.Type}, c.Location.Location...))
+	}
+	for _, d := range []struct {
+		t CDPTLVType
+		p CDPPowerDialogue
+	}{{CDPTLVPowerRequested, c.PowerRequest}, {CDPTLVPowerAvailable, c.PowerAvailable}} {
+		if d.p.ID == 0 && d.p.MgmtID == 0 && len(d.p.Values) == 0 {
+			continue
+		}
+		v := make([]byte, 4+4*len(d.p.Values))
+		binary.BigEndian.PutUint16(v[0:2], d.p.ID)
+		binary.BigEndian.PutUint16(v[2:4], d.p.MgmtID)
+		for i, value := range d.p.Values {
+			binary.BigEndian.PutUint32(v[4+4*i:], value)
+		}
+		add(d.t, v)
+	}
+	if c.SparePairPoe != (CDPSparePairPoE{}) {
+		var v byte
+		if c.SparePairPoe.PSEFourWire {
+			v |= CDPPoEFourWire
+		}
+		if c.SparePairPoe.PDArchShared {
+			v |= CDPPoEPDArch
+		}
+		if c.SparePairPoe.PDRequestOn {
+			v |= CDPPoEPDRequest
+		}
+		if c.SparePairPoe.PSEOn {
+			v |= CDPPoEPSE
+		}
+		add(CDPTLVSparePairPOE, []byte{v})
+	}
+	return append(vals, c.Unknown...)
+}
+
+// encodeAddresses encodes IPv4 and IPv6 addresses as in Address and
+// Management Addresses TLVs.
+func encodeAddresses(addresses []net.IP) []byte {
+	v := make([]byte, 4)
+	binary.BigEndian.PutUint32(v, uint32(len(addresses)))
+	for _, ip := range addresses {
+		if ip4 := ip.To4(); ip4 != nil {
+			v = append(v, CDPProtocolTypeNLPID, 1, byte(CDPAddressTypeIPV4), 0, 4)
+			v = append(v, ip4...)
+		} else {
+			v = append(v, CDPProtocolType802_2, 8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 16)
+			binary.BigEndian.PutUint64(v[len(v)-10:], uint64(CDPAddressTypeIPV6))
+			v = append(v, ip.To16()...)
+		}
+	}
+	return v
+}
+
 // CDP Protocol Types
 const (
 	CDPProtocolTypeNLPID byte = 1
@@ -517,6 +733,9 @@ func decodeAddresses(v []byte) (addresses []net.IP, err error) {
 		return nil, fmt.Errorf("Invalid Address TLV length %d", len(v))
 	}
 	for i := 0; i < numaddr; i++ {
+		if len(v) < 2 {
+			return nil, errors.New("Invalid Address TLV: truncated address")
+		}
 		prottype := v[0]
 		if prottype != CDPProtocolTypeNLPID && prottype != CDPProtocolType802_2 { // invalid protocol type
 			return nil, fmt.Errorf("Invalid Address Protocol %d", prottype)
@@ -526,11 +745,17 @@ func decodeAddresses(v []byte) (addresses []net.IP, err error) {
 			(prottype == CDPProtocolType802_2 && protlen != 3 && protlen != 8) { // invalid length
 			return nil, fmt.Errorf("Invalid Address Protocol length %d", protlen)
 		}
+		if len(v) < 4+protlen {
+			return nil, errors.New("Invalid Address TLV: truncated address")
+		}
 		plen := make([]byte, 8)
 		copy(plen[8-protlen:], v[2:2+protlen])
 		protocol := CDPAddressType(binary.BigEndian.Uint64(plen))
 		v = v[2+protlen:]
-		addrlen := binary.BigEndian.Uint16(v[0:2])
+		addrlen := int(binary.BigEndian.Uint16(v[0:2]))
+		if len(v) < 2+addrlen {
+			return nil, errors.New("Invalid Address TLV: truncated address")
+		}
 		ab := v[2 : 2+addrlen]
 		if protocol == CDPAddressTypeIPV4 && addrlen == 4 {
 			addresses = append(addresses, net.IPv4(ab[0], ab[1], ab[2], ab[3]))
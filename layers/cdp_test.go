@@ -0,0 +1,91 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestSerializeCiscoDiscovery(t *testing.T) {
+	info := &CiscoDiscoveryInfo{
+		DeviceID:         "switch1.example.com",
+		Addresses:        []net.IP{net.IPv4(192, 0, 2, 1), net.ParseIP("2001:db8::1")},
+		PortID:           "GigabitEthernet1/0/1",
+		Capabilities:     CDPCapabilities{L3Router: true, L2Switch: true, IGMPFilter: true},
+		Version:          "Cisco IOS Software, Version 15.2(7)E",
+		Platform:         "cisco WS-C3850-24P",
+		IPPrefixes:       []net.IPNet{{IP: net.IP{198, 51, 100, 0}, Mask: net.CIDRMask(24, 32)}},
+		VTPDomain:        "LAB",
+		NativeVLAN:       10,
+		FullDuplex:       true,
+		VLANReply:        CDPVLANDialogue{ID: 1, VLAN: 20},
+		PowerConsumption: 6300,
+		ExtendedTrust:    1,
+		UntrustedCOS:     3,
+		MgmtAddresses:    []net.IP{net.IPv4(192, 0, 2, 1)},
+		Location:         CDPLocation{Type: 0, Location: "Rack 4"},
+		PowerAvailable:   CDPPowerDialogue{ID: 1, MgmtID: 2, Values: []uint32{0, 15400}},
+		SparePairPoe:     CDPSparePairPoE{PSEFourWire: true, PSEOn: true},
+		Unknown:          []CiscoDiscoveryValue{{Type: 0x1c, Length: 5, Value: []byte{0x7f}}},
+	}
+	snap := &SNAP{OrganizationalCode: []byte{0x00, 0x00, 0x0c}, Type: EthernetTypeCiscoDiscovery}
+	llc := &LLC{DSAP: 0xaa, SSAP: 0xaa, Control: 3}
+	eth := &Ethernet{
+		SrcMAC: net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+		DstMAC: net.HardwareAddr{0x01, 0x00, 0x0c, 0xcc, 0xcc, 0xcc},
+	}
+	cdp := &CiscoDiscovery{Version: 2, TTL: 180, Values: info.Values()}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, eth, llc, snap, cdp); err != nil {
+		t.Fatal(err)
+	}
+
+	p := gopacket.NewPacket(buf.Bytes(), LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeLLC, LayerTypeSNAP, LayerTypeCiscoDiscovery, LayerTypeCiscoDiscoveryInfo}, t)
+	gotCDP := p.Layer(LayerTypeCiscoDiscovery).(*CiscoDiscovery)
+	if gotCDP.Version != 2 || gotCDP.TTL != 180 || gotCDP.Checksum != cdp.Checksum {
+		t.Errorf("CiscoDiscovery header mismatch: %#v", gotCDP)
+	}
+	if valid, err := gotCDP.VerifyChecksum(); !valid || err != nil {
+		t.Errorf("CiscoDiscovery checksum invalid: %v", err)
+	}
+	got := p.Layer(LayerTypeCiscoDiscoveryInfo).(*CiscoDiscoveryInfo)
+	got.BaseLayer = info.BaseLayer
+	if !reflect.DeepEqual(got, info) {
+		t.Errorf("CiscoDiscoveryInfo mismatch:\ngot  %#v\nwant %#v", got, info)
+	}
+
+	fixed, err := gopacket.FixLayers(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fixed, buf.Bytes()) {
+		t.Errorf("FixLayers mismatch:\ngot  %x\nwant %x", fixed, buf.Bytes())
+	}
+}
+
+func TestCiscoDiscoveryChecksumOddLength(t *testing.T) {
+	for _, last := range []byte{0x7f, 0x80, 0xfd} {
+		cdp := &CiscoDiscovery{Version: 2, TTL: 180, Values: []CiscoDiscoveryValue{{Type: CDPTLVDevID, Value: []byte{'a', 'b', last}}}}
+		buf := gopacket.NewSerializeBuffer()
+		if err := cdp.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}); err != nil {
+			t.Fatal(err)
+		}
+		p := gopacket.NewPacket(buf.Bytes(), LayerTypeCiscoDiscovery, testDecodeOptions)
+		if valid, err := p.Layer(LayerTypeCiscoDiscovery).(*CiscoDiscovery).VerifyChecksum(); !valid || err != nil {
+			t.Errorf("last byte %#x: checksum invalid: %v", last, err)
+		}
+	}
+}
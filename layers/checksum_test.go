@@ -0,0 +1,112 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func checksumTestPacket(t *testing.T, ls ...gopacket.SerializableLayer) []byte {
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, ls...); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func checksumTestStatuses(results []gopacket.ChecksumResult) map[gopacket.LayerType]gopacket.ChecksumStatus {
+	statuses := make(map[gopacket.LayerType]gopacket.ChecksumStatus)
+	for _, r := range results {
+		statuses[r.LayerType] = r.Status
+	}
+	return statuses
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	eth := &Ethernet{SrcMAC: net.HardwareAddr{1, 2, 3, 4, 5, 6}, DstMAC: net.HardwareAddr{6, 5, 4, 3, 2, 1}, EthernetType: EthernetTypeIPv4}
+	ip4 := func(proto IPProtocol) *IPv4 {
+		return &IPv4{Version: 4, TTL: 64, Protocol: proto, SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}
+	}
+	ipTCP := ip4(IPProtocolTCP)
+	tcp := &TCP{SrcPort: 1234, DstPort: 80, Seq: 1, ACK: true, Window: 1024}
+	tcp.SetNetworkLayerForChecksum(ipTCP)
+	tcpPacket := checksumTestPacket(t, eth, ipTCP, tcp, gopacket.Payload("odd payload"))
+
+	ipUDP := ip4(IPProtocolUDP)
+	udp := &UDP{SrcPort: 1234, DstPort: 9999}
+	udp.SetNetworkLayerForChecksum(ipUDP)
+	udpPacket := checksumTestPacket(t, eth, ipUDP, udp, gopacket.Payload{1, 2, 3})
+
+	icmpPacket := checksumTestPacket(t, eth, ip4(IPProtocolICMPv4), &ICMPv4{TypeCode: CreateICMPv4TypeCode(ICMPv4TypeEchoRequest, 0), Id: 1, Seq: 2}, gopacket.Payload{1, 2, 3})
+	sctpPacket := checksumTestPacket(t, eth, ip4(IPProtocolSCTP), &SCTP{SrcPort: 1, DstPort: 2, VerificationTag: 3}, &SCTPData{BeginFragment: true, EndFragment: true, TSN: 1}, gopacket.Payload{1, 2, 3, 4})
+
+	ip6 := &IPv6{Version: 6, NextHeader: IPProtocolICMPv6, HopLimit: 64, SrcIP: net.ParseIP("fe80::1"), DstIP: net.ParseIP("fe80::2")}
+	icmp6 := &ICMPv6{TypeCode: CreateICMPv6TypeCode(ICMPv6TypeEchoRequest, 0)}
+	icmp6.SetNetworkLayerForChecksum(ip6)
+	icmp6Packet := checksumTestPacket(t, &Ethernet{SrcMAC: eth.SrcMAC, DstMAC: eth.DstMAC, EthernetType: EthernetTypeIPv6}, ip6, icmp6, &ICMPv6Echo{Identifier: 1, SeqNumber: 2})
+
+	corrupt := func(data []byte, offset int) []byte {
+		data = append([]byte(nil), data...)
+		data[offset] ^= 0xff
+		return data
+	}
+	noUDPChecksum := append([]byte(nil), udpPacket...)
+	noUDPChecksum[14+20+6], noUDPChecksum[14+20+7] = 0, 0
+
+	tests := []struct {
+		name      string
+		data      []byte
+		ancillary []interface{}
+		truncated bool
+		want      map[gopacket.LayerType]gopacket.ChecksumStatus
+	}{
+		{"tcp", tcpPacket, nil, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeTCP: gopacket.ChecksumValid}},
+		{"udp", udpPacket, nil, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeUDP: gopacket.ChecksumValid}},
+		{"icmpv4", icmpPacket, nil, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeICMPv4: gopacket.ChecksumValid}},
+		{"icmpv6", icmp6Packet, nil, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeICMPv6: gopacket.ChecksumValid}},
+		{"sctp", sctpPacket, nil, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeSCTP: gopacket.ChecksumValid}},
+		{"corrupt ip", corrupt(tcpPacket, 14+8), nil, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumInvalid, LayerTypeTCP: gopacket.ChecksumValid}},
+		{"corrupt payload", corrupt(tcpPacket, len(tcpPacket)-1), nil, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeTCP: gopacket.ChecksumInvalid}},
+		{"corrupt sctp", corrupt(sctpPacket, len(sctpPacket)-1), nil, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeSCTP: gopacket.ChecksumInvalid}},
+		{"no udp checksum", noUDPChecksum, nil, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeUDP: gopacket.ChecksumUnverifiable}},
+		{"offloaded", corrupt(tcpPacket, len(tcpPacket)-1), []interface{}{gopacket.ChecksumOffload{NotReady: true}}, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeTCP: gopacket.ChecksumOffloaded}},
+		{"offloaded ip", corrupt(tcpPacket, 14+8), []interface{}{gopacket.ChecksumOffload{NotReady: true}}, false, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumInvalid, LayerTypeTCP: gopacket.ChecksumValid}},
+		{"truncated", tcpPacket, nil, true, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeTCP: gopacket.ChecksumUnverifiable}},
+		{"truncated verified", tcpPacket, []interface{}{gopacket.ChecksumOffload{Verified: true}}, true, map[gopacket.LayerType]gopacket.ChecksumStatus{LayerTypeIPv4: gopacket.ChecksumValid, LayerTypeTCP: gopacket.ChecksumValid}},
+	}
+	for _, test := range tests {
+		p := gopacket.NewPacket(test.data, LinkTypeEthernet, gopacket.Default)
+		if p.ErrorLayer() != nil {
+			t.Fatalf("%s: %v", test.name, p.ErrorLayer().Error())
+		}
+		p.Metadata().AncillaryData = test.ancillary
+		p.Metadata().Truncated = test.truncated
+		results := gopacket.VerifyChecksums(p)
+		got := checksumTestStatuses(results)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.want, got)
+			continue
+		}
+		for lt, status := range test.want {
+			if got[lt] != status {
+				t.Errorf("%s: %v checksum expected %v, got %v", test.name, lt, status, got[lt])
+			}
+		}
+		for _, r := range results {
+			if p.Layers()[r.Layer].LayerType() != r.LayerType {
+				t.Errorf("%s: result for layer %d has layer type %v", test.name, r.Layer, r.LayerType)
+			}
+			if (r.Status == gopacket.ChecksumUnverifiable) != (r.Err != nil) {
+				t.Errorf("%s: %v status %v with error %v", test.name, r.LayerType, r.Status, r.Err)
+			}
+		}
+	}
+}
@@ -10,6 +10,7 @@ package layers
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
 	"reflect"
@@ -753,6 +754,9 @@ func TestDecodeCiscoDiscovery(t *testing.T) {
 	if !reflect.DeepEqual(info, want) {
 		t.Errorf("Values mismatch, \ngot  %#v\nwant %#v\n", info, want)
 	}
+	if valid, err := p.Layer(LayerTypeCiscoDiscovery).(*CiscoDiscovery).VerifyChecksum(); !valid || err != nil {
+		t.Errorf("CiscoDiscovery checksum invalid: %v", err)
+	}
 }
 
 func TestDecodeLinkLayerDiscovery(t *testing.T) {
@@ -1042,6 +1046,59 @@ func TestDecodingLayerParserFullTCPPacket(t *testing.T) {
 	}
 }
 
+func TestDecodingLayerParserCallbacks(t *testing.T) {
+	var eth Ethernet
+	var ip4 IPv4
+	var tcp TCP
+	var payload gopacket.Payload
+	dlp := gopacket.NewDecodingLayerParser(LayerTypeEthernet, &eth, &ip4, &tcp, &payload)
+
+	var calls []string
+	dlp.OnLayer(LayerTypeIPv4, func(layer gopacket.DecodingLayer, decoded []gopacket.LayerType) error {
+		if layer != &ip4 || len(decoded) != 2 || decoded[1] != LayerTypeIPv4 {
+			t.Errorf("unexpected IPv4 callback arguments %v %v", layer, decoded)
+		}
+		calls = append(calls, "IPv4")
+		return nil
+	})
+	dlp.OnLayer(LayerTypeTCP, func(layer gopacket.DecodingLayer, decoded []gopacket.LayerType) error {
+		// lower layers are already decoded, the payload isn't yet
+		if ip4.Protocol != IPProtocolTCP || len(payload) != 0 {
+			t.Errorf("unexpected layer state in TCP callback")
+		}
+		calls = append(calls, "TCP")
+		return nil
+	})
+	decoded := []gopacket.LayerType{}
+	if err := dlp.DecodeLayers(testSimpleTCPPacket, &decoded); err != nil {
+		t.Fatal("Error from dlp parser: ", err)
+	}
+	if len(decoded) != 4 || len(calls) != 2 || calls[0] != "IPv4" || calls[1] != "TCP" {
+		t.Errorf("unexpected decoded layers %v or calls %v", decoded, calls)
+	}
+
+	// errors stop decoding
+	stop := errors.New("stop")
+	dlp.OnLayer(LayerTypeIPv4, func(gopacket.DecodingLayer, []gopacket.LayerType) error {
+		return stop
+	})
+	calls = nil
+	if err := dlp.DecodeLayers(testSimpleTCPPacket, &decoded); err != stop {
+		t.Errorf("expected callback error, got %v", err)
+	}
+	if len(decoded) != 2 || len(calls) != 0 {
+		t.Errorf("unexpected decoded layers %v or calls %v", decoded, calls)
+	}
+
+	// removing all callbacks
+	dlp.OnLayer(LayerTypeIPv4, nil)
+	dlp.OnLayer(LayerTypeTCP, nil)
+	calls = nil
+	if err := dlp.DecodeLayers(testSimpleTCPPacket, &decoded); err != nil || len(decoded) != 4 || len(calls) != 0 {
+		t.Errorf("unexpected result %v %v %v", err, decoded, calls)
+	}
+}
+
 func testDecodingLayerContainer(t *testing.T, dlc gopacket.DecodingLayerContainer) {
 	dlc = dlc.Put(&Ethernet{})
 	dlc = dlc.Put(&IPv4{})
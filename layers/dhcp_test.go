@@ -179,3 +179,77 @@ func testDHCPOptionEqual(t *testing.T, idx int, d1, d2 DHCPOption) {
 		t.Errorf("expection Options[%d].Data to be = %v, got %v", idx, d1.Data, d2.Data)
 	}
 }
+
+func TestDHCPv4RelayAgentInfoAndTypedOptions(t *testing.T) {
+	dhcp := &DHCPv4{Operation: DHCPOpRequest, HardwareType: LinkTypeEthernet, Xid: 0x12345678,
+		RelayAgentIP: net.IP{192, 0, 2, 1}, ClientHWAddr: net.HardwareAddr{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc}}
+	dhcp.SetMessageType(DHCPMsgTypeRequest)
+	dhcp.SetIPOption(DHCPOptRequestIP, net.IP{192, 0, 2, 100})
+	dhcp.SetUint32Option(DHCPOptLeaseTime, 3600)
+	dhcp.SetUint16Option(DHCPOptMaxMessageSize, 1500)
+	dhcp.SetStringOption(DHCPOptHostname, "client")
+	dhcp.SetIPsOption(DHCPOptRouter, []net.IP{{192, 0, 2, 1}, {192, 0, 2, 2}})
+	vendor, err := NewDHCPSubOptionsOption(DHCPOptVendorOption, []DHCPSubOption{{Type: 1, Data: []byte("acs")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dhcp.Options = append(dhcp.Options, vendor)
+	relay, err := NewDHCPSubOptionsOption(DHCPOptRelayAgentInfo, []DHCPSubOption{
+		{Type: uint8(DHCPRelayAgentCircuitID), Data: []byte("eth0:100")},
+		{Type: uint8(DHCPRelayAgentRemoteID), Data: []byte{0, 6, 1, 2, 3, 4, 5, 6}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dhcp.Options = append(dhcp.Options, relay)
+	dhcp.SetMessageType(DHCPMsgTypeDecline)
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, dhcp); err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeDHCPv4, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	d := p.Layer(LayerTypeDHCPv4).(*DHCPv4)
+	if len(d.Options) != 8 || d.MessageType() != DHCPMsgTypeDecline {
+		t.Errorf("wrong options %s", d.Options)
+	}
+	if ip := d.IPOption(DHCPOptRequestIP); !ip.Equal(net.IP{192, 0, 2, 100}) {
+		t.Errorf("wrong requested IP %v", ip)
+	}
+	if lease, ok := d.Uint32Option(DHCPOptLeaseTime); !ok || lease != 3600 {
+		t.Errorf("wrong lease time %d", lease)
+	}
+	if size, ok := d.Uint16Option(DHCPOptMaxMessageSize); !ok || size != 1500 {
+		t.Errorf("wrong max message size %d", size)
+	}
+	if _, ok := d.Uint32Option(DHCPOptT1); ok {
+		t.Error("missing option found")
+	}
+	if routers := d.IPsOption(DHCPOptRouter); len(routers) != 2 || !routers[1].Equal(net.IP{192, 0, 2, 2}) {
+		t.Errorf("wrong routers %v", routers)
+	}
+	if d.StringOption(DHCPOptHostname) != "client" {
+		t.Errorf("wrong hostname %q", d.StringOption(DHCPOptHostname))
+	}
+	if string(d.CircuitID()) != "eth0:100" || !bytes.Equal(d.RemoteID(), []byte{0, 6, 1, 2, 3, 4, 5, 6}) {
+		t.Errorf("wrong relay agent information %q %v", d.CircuitID(), d.RemoteID())
+	}
+	if subs, err := d.VendorInfo(); err != nil || len(subs) != 1 || string(subs[0].Data) != "acs" {
+		t.Errorf("wrong vendor information %v %v", subs, err)
+	}
+	if s := d.Option(DHCPOptRelayAgentInfo).String(); s != "Option(RelayAgentInfo:CircuitID:[101 116 104 48 58 49 48 48],RemoteID:[0 6 1 2 3 4 5 6])" {
+		t.Errorf("wrong relay agent information string %s", s)
+	}
+
+	bad := NewDHCPOption(DHCPOptRelayAgentInfo, []byte{1, 5, 'a'})
+	if _, err := bad.SubOptions(); err == nil {
+		t.Error("truncated sub-option decoded")
+	}
+	dhcp.Options[0].Length = 7
+	if err := dhcp.SerializeTo(gopacket.NewSerializeBuffer(), gopacket.SerializeOptions{}); err == nil {
+		t.Error("option with wrong length serialized")
+	}
+}
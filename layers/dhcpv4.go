@@ -196,6 +196,21 @@ func (d *DHCPv4) Len() uint16 {
 // SerializationBuffer, implementing gopacket.SerializableLayer.
 // See the docs for gopacket.SerializableLayer for more info.
 func (d *DHCPv4) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	for i := range d.Options {
+		o := &d.Options[i]
+		if o.Type == DHCPOptPad {
+			continue
+		}
+		if opts.FixLengths {
+			if len(o.Data) > 255 {
+				return fmt.Errorf("DHCPv4 option %s length %d too long", o.Type, len(o.Data))
+			}
+			o.Length = uint8(len(o.Data))
+		}
+		if int(o.Length) != len(o.Data) {
+			return fmt.Errorf("DHCPv4 option %s length %d for %d bytes", o.Type, o.Length, len(o.Data))
+		}
+	}
 	plen := int(d.Len())
 
 	data, err := b.PrependBytes(plen)
@@ -222,23 +237,21 @@ func (d *DHCPv4) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Serialize
 	copy(data[108:236], d.File)
 	binary.BigEndian.PutUint32(data[236:240], DHCPMagic)
 
-	if len(d.Options) > 0 {
-		offset := 240
-		for _, o := range d.Options {
-			if err := o.encode(data[offset:]); err != nil {
-				return err
-			}
-			// A pad option is only a single byte
-			if o.Type == DHCPOptPad {
-				offset++
-			} else {
-				offset += 2 + len(o.Data)
-			}
-		}
-		optend := NewDHCPOption(DHCPOptEnd, nil)
-		if err := optend.encode(data[offset:]); err != nil {
+	offset := 240
+	for _, o := range d.Options {
+		if err := o.encode(data[offset:]); err != nil {
 			return err
 		}
+		// A pad option is only a single byte
+		if o.Type == DHCPOptPad {
+			offset++
+		} else {
+			offset += 2 + len(o.Data)
+		}
+	}
+	optend := NewDHCPOption(DHCPOptEnd, nil)
+	if err := optend.encode(data[offset:]); err != nil {
+		return err
 	}
 	return nil
 }
@@ -330,6 +343,7 @@ const (
 	DHCPOptT2                    DHCPOpt = 59  // 4, uint32
 	DHCPOptClassID               DHCPOpt = 60  // n, []byte
 	DHCPOptClientID              DHCPOpt = 61  // n >=  2, []byte
+	DHCPOptRelayAgentInfo        DHCPOpt = 82  // n, sub-options
 	DHCPOptDomainSearch          DHCPOpt = 119 // n, string
 	DHCPOptSIPServers            DHCPOpt = 120 // n, url
 	DHCPOptClasslessStaticRoute  DHCPOpt = 121 //
@@ -468,6 +482,8 @@ func (o DHCPOpt) String() string {
 		return "ClassID"
 	case DHCPOptClientID:
 		return "ClientID"
+	case DHCPOptRelayAgentInfo:
+		return "RelayAgentInfo"
 	case DHCPOptDomainSearch:
 		return "DomainSearch"
 	case DHCPOptClasslessStaticRoute:
@@ -528,6 +544,22 @@ func (o DHCPOption) String() string {
 		buf.WriteString(")")
 		return buf.String()
 
+	case DHCPOptRelayAgentInfo:
+		subs, err := o.SubOptions()
+		if err != nil {
+			return fmt.Sprintf("Option(%s:INVALID)", o.Type)
+		}
+		buf := &bytes.Buffer{}
+		buf.WriteString(fmt.Sprintf("Option(%s:", o.Type))
+		for i, sub := range subs {
+			buf.WriteString(fmt.Sprintf("%s:%v", DHCPRelayAgentSubOpt(sub.Type), sub.Data))
+			if i+1 != len(subs) {
+				buf.WriteByte(',')
+			}
+		}
+		buf.WriteString(")")
+		return buf.String()
+
 	default:
 		return fmt.Sprintf("Option(%s:%v)", o.Type, o.Data)
 	}
@@ -593,3 +625,261 @@ const (
 	// InvalidMagicCookie is returned when Magic cookie is missing into BOOTP header
 	InvalidMagicCookie = DHCPv4Error("Bad DHCP header")
 )
+
+// DHCPSubOption represents a sub-option encapsulated in a DHCP option, e.g.
+// the relay agent information option or the vendor specific information
+// option.
+type DHCPSubOption struct {
+	Type   uint8
+	Length uint8
+	Data   []byte
+}
+
+// SubOptions decodes the data of the option as encapsulated sub-options, as
+// in the relay agent information option and in the vendor specific
+// information option.  Pad and end sub-options are skipped.
+func (o *DHCPOption) SubOptions() ([]DHCPSubOption, error) {
+	var subs []DHCPSubOption
+	for data := o.Data; len(data) > 0; {
+		switch data[0] {
+		case byte(DHCPOptPad):
+			data = data[1:]
+			continue
+		case byte(DHCPOptEnd):
+			if o.Type == DHCPOptVendorOption {
+				return subs, nil
+			}
+		}
+		if len(data) < 2 {
+			return nil, DecOptionNotEnoughData
+		}
+		sub := DHCPSubOption{Type: data[0], Length: data[1]}
+		if int(sub.Length) > len(data[2:]) {
+			return nil, DecOptionMalformed
+		}
+		sub.Data = data[2 : 2+int(sub.Length)]
+		subs = append(subs, sub)
+		data = data[2+int(sub.Length):]
+	}
+	return subs, nil
+}
+
+// NewDHCPSubOptionsOption constructs a new DHCPOption with a given type
+// encapsulating sub-options.
+func NewDHCPSubOptionsOption(t DHCPOpt, subs []DHCPSubOption) (DHCPOption, error) {
+	var data []byte
+	for _, sub := range subs {
+		if len(sub.Data) > 255 {
+			return DHCPOption{}, fmt.Errorf("DHCPv4 sub-option length %d too long", len(sub.Data))
+		}
+		data = append(data, sub.Type, byte(len(sub.Data)))
+		data = append(data, sub.Data...)
+	}
+	if len(data) > 255 {
+		return DHCPOption{}, fmt.Errorf("DHCPv4 option %s length %d too long", t, len(data))
+	}
+	return NewDHCPOption(t, data), nil
+}
+
+// DHCPRelayAgentSubOpt represents a sub-option of the relay agent information
+// option, RFC 3046.
+type DHCPRelayAgentSubOpt uint8
+
+// Constants for the DHCPRelayAgentSubOpt sub-options.
+const (
+	DHCPRelayAgentCircuitID              DHCPRelayAgentSubOpt = 1   // RFC 3046
+	DHCPRelayAgentRemoteID               DHCPRelayAgentSubOpt = 2   // RFC 3046
+	DHCPRelayAgentLinkSelection          DHCPRelayAgentSubOpt = 5   // RFC 3527
+	DHCPRelayAgentSubscriberID           DHCPRelayAgentSubOpt = 6   // RFC 3993
+	DHCPRelayAgentRADIUSAttributes       DHCPRelayAgentSubOpt = 7   // RFC 4014
+	DHCPRelayAgentAuthentication         DHCPRelayAgentSubOpt = 8   // RFC 4030
+	DHCPRelayAgentVendorSpecific         DHCPRelayAgentSubOpt = 9   // RFC 4243
+	DHCPRelayAgentFlags                  DHCPRelayAgentSubOpt = 10  // RFC 5010
+	DHCPRelayAgentServerIDOverride       DHCPRelayAgentSubOpt = 11  // RFC 5107
+	DHCPRelayAgentVirtualSubnetSelection DHCPRelayAgentSubOpt = 151 // RFC 6607
+)
+
+// String returns a string version of a DHCPRelayAgentSubOpt.
+func (o DHCPRelayAgentSubOpt) String() string {
+	switch o {
+	case DHCPRelayAgentCircuitID:
+		return "CircuitID"
+	case DHCPRelayAgentRemoteID:
+		return "RemoteID"
+	case DHCPRelayAgentLinkSelection:
+		return "LinkSelection"
+	case DHCPRelayAgentSubscriberID:
+		return "SubscriberID"
+	case DHCPRelayAgentRADIUSAttributes:
+		return "RADIUSAttributes"
+	case DHCPRelayAgentAuthentication:
+		return "Authentication"
+	case DHCPRelayAgentVendorSpecific:
+		return "VendorSpecific"
+	case DHCPRelayAgentFlags:
+		return "Flags"
+	case DHCPRelayAgentServerIDOverride:
+		return "ServerIDOverride"
+	case DHCPRelayAgentVirtualSubnetSelection:
+		return "VirtualSubnetSelection"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(o))
+	}
+}
+
+// Option returns the first option of type t, or nil.
+func (d *DHCPv4) Option(t DHCPOpt) *DHCPOption {
+	for i := range d.Options {
+		if d.Options[i].Type == t {
+			return &d.Options[i]
+		}
+	}
+	return nil
+}
+
+// SetOption replaces the data of the first option of type t, or adds the
+// option.
+func (d *DHCPv4) SetOption(t DHCPOpt, data []byte) {
+	if o := d.Option(t); o != nil {
+		*o = NewDHCPOption(t, data)
+		return
+	}
+	d.Options = append(d.Options, NewDHCPOption(t, data))
+}
+
+// MessageType returns the MessageType option, or 0.
+func (d *DHCPv4) MessageType() DHCPMsgType {
+	if o := d.Option(DHCPOptMessageType); o != nil && len(o.Data) == 1 {
+		return DHCPMsgType(o.Data[0])
+	}
+	return DHCPMsgTypeUnspecified
+}
+
+// SetMessageType sets the MessageType option.
+func (d *DHCPv4) SetMessageType(t DHCPMsgType) {
+	d.SetOption(DHCPOptMessageType, []byte{byte(t)})
+}
+
+// IPOption returns the IPv4 address of an option of type t, e.g. ServerID,
+// or nil.
+func (d *DHCPv4) IPOption(t DHCPOpt) net.IP {
+	if o := d.Option(t); o != nil && len(o.Data) == 4 {
+		return net.IP(o.Data)
+	}
+	return nil
+}
+
+// SetIPOption sets the IPv4 address of an option of type t.
+func (d *DHCPv4) SetIPOption(t DHCPOpt, ip net.IP) {
+	d.SetOption(t, append([]byte(nil), ip.To4()...))
+}
+
+// IPsOption returns the IPv4 addresses of an option of type t, e.g. Router,
+// or nil.
+func (d *DHCPv4) IPsOption(t DHCPOpt) []net.IP {
+	o := d.Option(t)
+	if o == nil || len(o.Data)%4 != 0 {
+		return nil
+	}
+	var ips []net.IP
+	for i := 0; i < len(o.Data); i += 4 {
+		ips = append(ips, net.IP(o.Data[i:i+4]))
+	}
+	return ips
+}
+
+// SetIPsOption sets the IPv4 addresses of an option of type t.
+func (d *DHCPv4) SetIPsOption(t DHCPOpt, ips []net.IP) {
+	data := make([]byte, 0, 4*len(ips))
+	for _, ip := range ips {
+		data = append(data, ip.To4()...)
+	}
+	d.SetOption(t, data)
+}
+
+// Uint32Option returns the value of an option of type t, e.g. LeaseTime.
+func (d *DHCPv4) Uint32Option(t DHCPOpt) (uint32, bool) {
+	if o := d.Option(t); o != nil && len(o.Data) == 4 {
+		return binary.BigEndian.Uint32(o.Data), true
+	}
+	return 0, false
+}
+
+// SetUint32Option sets the value of an option of type t.
+func (d *DHCPv4) SetUint32Option(t DHCPOpt, v uint32) {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, v)
+	d.SetOption(t, data)
+}
+
+// Uint16Option returns the value of an option of type t, e.g.
+// MaxMessageSize.
+func (d *DHCPv4) Uint16Option(t DHCPOpt) (uint16, bool) {
+	if o := d.Option(t); o != nil && len(o.Data) == 2 {
+		return binary.BigEndian.Uint16(o.Data), true
+	}
+	return 0, false
+}
+
+// SetUint16Option sets the value of an option of type t.
+func (d *DHCPv4) SetUint16Option(t DHCPOpt, v uint16) {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, v)
+	d.SetOption(t, data)
+}
+
+// StringOption returns the value of an option of type t, e.g. Hostname, or
+// "".
+func (d *DHCPv4) StringOption(t DHCPOpt) string {
+	if o := d.Option(t); o != nil {
+		return string(o.Data)
+	}
+	return ""
+}
+
+// SetStringOption sets the value of an option of type t.
+func (d *DHCPv4) SetStringOption(t DHCPOpt, s string) {
+	d.SetOption(t, []byte(s))
+}
+
+// RelayAgentInfo returns the sub-options of the relay agent information
+// option, or nil.
+func (d *DHCPv4) RelayAgentInfo() ([]DHCPSubOption, error) {
+	o := d.Option(DHCPOptRelayAgentInfo)
+	if o == nil {
+		return nil, nil
+	}
+	return o.SubOptions()
+}
+
+func (d *DHCPv4) relayAgentSubOption(t DHCPRelayAgentSubOpt) []byte {
+	subs, _ := d.RelayAgentInfo()
+	for _, sub := range subs {
+		if sub.Type == uint8(t) {
+			return sub.Data
+		}
+	}
+	return nil
+}
+
+// CircuitID returns the circuit ID of the relay agent information option, or
+// nil.
+func (d *DHCPv4) CircuitID() []byte {
+	return d.relayAgentSubOption(DHCPRelayAgentCircuitID)
+}
+
+// RemoteID returns the remote ID of the relay agent information option, or
+// nil.
+func (d *DHCPv4) RemoteID() []byte {
+	return d.relayAgentSubOption(DHCPRelayAgentRemoteID)
+}
+
+// VendorInfo returns the sub-options of the vendor specific information
+// option, for vendors encapsulating them, or nil.
+func (d *DHCPv4) VendorInfo() ([]DHCPSubOption, error) {
+	o := d.Option(DHCPOptVendorOption)
+	if o == nil {
+		return nil, nil
+	}
+	return o.SubOptions()
+}
@@ -92,8 +92,8 @@ func (d *DHCPv6) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error
 		return fmt.Errorf("DHCPv6 length %d too short", len(data))
 	}
 	d.BaseLayer = BaseLayer{Contents: data}
-	d.Options = d.Options[:0]
 	d.MsgType = DHCPv6MsgType(data[0])
+	d.HopCount, d.LinkAddr, d.PeerAddr, d.TransactionID = 0, nil, nil, nil
 
 	offset := 0
 	if d.MsgType == DHCPv6MsgTypeRelayForward || d.MsgType == DHCPv6MsgTypeRelayReply {
@@ -110,19 +110,30 @@ func (d *DHCPv6) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error
 		offset = 4
 	}
 
-	stop := len(data)
-	for offset < stop {
-		o := DHCPv6Option{}
-		if err := o.decode(data[offset:]); err != nil {
-			return err
+	var err error
+	if d.Options, err = decodeDHCPv6Options(data[offset:], d.Options[:0]); err != nil {
+		return err
+	}
+	// The message relayed by relay agents is the payload
+	if offset == 34 {
+		if o := d.Option(DHCPv6OptRelayMessage); o != nil {
+			d.Payload = o.Data
 		}
-		d.Options = append(d.Options, o)
-		offset += int(o.Length) + 4 // 2 from option code, 2 from option length
 	}
 
 	return nil
 }
 
+// Option returns the first option of code, or nil.
+func (d *DHCPv6) Option(code DHCPv6Opt) *DHCPv6Option {
+	for i := range d.Options {
+		if d.Options[i].Code == code {
+			return &d.Options[i]
+		}
+	}
+	return nil
+}
+
 // Len returns the length of a DHCPv6 packet.
 func (d *DHCPv6) Len() int {
 	n := 1
@@ -142,8 +153,41 @@ func (d *DHCPv6) Len() int {
 // SerializeTo writes the serialized form of this layer into the
 // SerializationBuffer, implementing gopacket.SerializableLayer.
 // See the docs for gopacket.SerializableLayer for more info.
+//
+// The payload of relay messages, e.g. the message relayed serialized as the
+// next layer, is encapsulated in a RelayMessage option, which takes the place
+// of the RelayMessage option of a decoded relay message.
 func (d *DHCPv6) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if opts.FixLengths {
+		for i := range d.Options {
+			if len(d.Options[i].Data) > 0xffff {
+				return fmt.Errorf("DHCPv6 option %s length %d too long", d.Options[i].Code, len(d.Options[i].Data))
+			}
+			d.Options[i].Length = uint16(len(d.Options[i].Data))
+		}
+	}
 	plen := int(d.Len())
+	// The options following the relayed message are appended after it
+	before, after := d.Options, []DHCPv6Option(nil)
+	relayed := len(b.Bytes())
+	if (d.MsgType == DHCPv6MsgTypeRelayForward || d.MsgType == DHCPv6MsgTypeRelayReply) && relayed > 0 {
+		if relayed > 0xffff {
+			return fmt.Errorf("DHCPv6 relayed message length %d too long", relayed)
+		}
+		for i, o := range d.Options {
+			if o.Code == DHCPv6OptRelayMessage {
+				before, after = d.Options[:i], d.Options[i+1:]
+				plen -= int(o.Length) + 4
+				break
+			}
+		}
+		for _, o := range after {
+			plen -= int(o.Length) + 4
+		}
+		plen += 4 // 2 from option code, 2 from option length
+	} else {
+		relayed = 0
+	}
 
 	data, err := b.PrependBytes(plen)
 	if err != nil {
@@ -162,13 +206,33 @@ func (d *DHCPv6) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Serialize
 		offset = 4
 	}
 
-	if len(d.Options) > 0 {
-		for _, o := range d.Options {
-			if err := o.encode(data[offset:], opts); err != nil {
-				return err
-			}
-			offset += int(o.Length) + 4 // 2 from option code, 2 from option length
+	for _, o := range before {
+		if err := o.encode(data[offset:], opts); err != nil {
+			return err
 		}
+		offset += int(o.Length) + 4 // 2 from option code, 2 from option length
+	}
+	if relayed == 0 {
+		return nil
+	}
+	binary.BigEndian.PutUint16(data[offset:], uint16(DHCPv6OptRelayMessage))
+	binary.BigEndian.PutUint16(data[offset+2:], uint16(relayed))
+	if len(after) == 0 {
+		return nil
+	}
+	alen := 0
+	for _, o := range after {
+		alen += int(o.Length) + 4
+	}
+	if data, err = b.AppendBytes(alen); err != nil {
+		return err
+	}
+	offset = 0
+	for _, o := range after {
+		if err := o.encode(data[offset:], opts); err != nil {
+			return err
+		}
+		offset += int(o.Length) + 4
 	}
 	return nil
 }
@@ -178,19 +242,17 @@ func (d *DHCPv6) CanDecode() gopacket.LayerClass {
 	return LayerTypeDHCPv6
 }
 
-// NextLayerType returns the layer type contained by this DecodingLayer.
+// NextLayerType returns the layer type contained by this DecodingLayer,
+// LayerTypeDHCPv6 for the message relayed by relay messages.
 func (d *DHCPv6) NextLayerType() gopacket.LayerType {
+	if len(d.Payload) > 0 {
+		return LayerTypeDHCPv6
+	}
 	return gopacket.LayerTypePayload
 }
 
 func decodeDHCPv6(data []byte, p gopacket.PacketBuilder) error {
-	dhcp := &DHCPv6{}
-	err := dhcp.DecodeFromBytes(data, p)
-	if err != nil {
-		return err
-	}
-	p.AddLayer(dhcp)
-	return p.NextDecoder(gopacket.LayerTypePayload)
+	return decodingLayerDecoder(&DHCPv6{}, data, p)
 }
 
 // DHCPv6StatusCode represents a DHCP status code - RFC-3315
@@ -11,6 +11,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"net"
+	"strings"
+
 	"github.com/google/gopacket"
 )
 
@@ -571,7 +574,7 @@ func (o DHCPv6Option) String() string {
 		return fmt.Sprintf("Option(%s:[%s])", o.Code, duid.String())
 	case DHCPv6OptOro:
 		options := ""
-		for i := 0; i < int(o.Length); i += 2 {
+		for i := 0; i+1 < len(o.Data); i += 2 {
 			if options != "" {
 				options += ","
 			}
@@ -579,6 +582,36 @@ func (o DHCPv6Option) String() string {
 			options += option.String()
 		}
 		return fmt.Sprintf("Option(%s:[%s])", o.Code, options)
+	case DHCPv6OptIANA, DHCPv6OptIAPD:
+		var ia DHCPv6IA
+		if err := ia.DecodeFromBytes(o.Data); err != nil {
+			return fmt.Sprintf("Option(%s:INVALID)", o.Code)
+		}
+		return fmt.Sprintf("Option(%s:[IAID: %d, T1: %d, T2: %d, Options: %s])", o.Code, ia.IAID, ia.T1, ia.T2, ia.Options)
+	case DHCPv6OptIAAddr:
+		var a DHCPv6IAAddr
+		if err := a.DecodeFromBytes(o.Data); err != nil {
+			return fmt.Sprintf("Option(%s:INVALID)", o.Code)
+		}
+		return fmt.Sprintf("Option(%s:[Address: %s, PreferredLifetime: %d, ValidLifetime: %d, Options: %s])", o.Code, a.Address, a.PreferredLifetime, a.ValidLifetime, a.Options)
+	case DHCPv6OptIAPrefix:
+		var p DHCPv6IAPrefix
+		if err := p.DecodeFromBytes(o.Data); err != nil {
+			return fmt.Sprintf("Option(%s:INVALID)", o.Code)
+		}
+		return fmt.Sprintf("Option(%s:[Prefix: %s/%d, PreferredLifetime: %d, ValidLifetime: %d, Options: %s])", o.Code, p.Prefix, p.PrefixLength, p.PreferredLifetime, p.ValidLifetime, p.Options)
+	case DHCPv6OptStatusCode:
+		var st DHCPv6Status
+		if err := st.DecodeFromBytes(o.Data); err != nil {
+			return fmt.Sprintf("Option(%s:INVALID)", o.Code)
+		}
+		return fmt.Sprintf("Option(%s:[%s: %q])", o.Code, st.StatusCode, st.Message)
+	case DHCPv6OptClientFQDN:
+		var f DHCPv6FQDN
+		if err := f.DecodeFromBytes(o.Data); err != nil {
+			return fmt.Sprintf("Option(%s:INVALID)", o.Code)
+		}
+		return fmt.Sprintf("Option(%s:%s)", o.Code, f.Name)
 	default:
 		return fmt.Sprintf("Option(%s:%v)", o.Code, o.Data)
 	}
@@ -619,3 +652,302 @@ func (o *DHCPv6Option) decode(data []byte) error {
 	o.Data = data[4 : 4+o.Length]
 	return nil
 }
+
+func decodeDHCPv6Options(data []byte, options DHCPv6Options) (DHCPv6Options, error) {
+	for offset := 0; offset < len(data); {
+		o := DHCPv6Option{}
+		if err := o.decode(data[offset:]); err != nil {
+			return options, err
+		}
+		options = append(options, o)
+		offset += int(o.Length) + 4 // 2 from option code, 2 from option length
+	}
+	return options, nil
+}
+
+func encodeDHCPv6Options(data []byte, options DHCPv6Options) []byte {
+	for _, o := range options {
+		data = append(data, byte(o.Code>>8), byte(o.Code), byte(len(o.Data)>>8), byte(len(o.Data)))
+		data = append(data, o.Data...)
+	}
+	return data
+}
+
+// DHCPv6IA is an identity association for non-temporary addresses, the data
+// of IANA options, or for prefix delegation, the data of IAPD options, as
+// stated in RFC 8415, sections 21.4 and 21.21.
+type DHCPv6IA struct {
+	IAID uint32
+	// T1 and T2 are the times to renew and to rebind, in seconds
+	T1, T2 uint32
+	// Options are the IAAddr options of IANA, or the IAPrefix options of
+	// IAPD, and their StatusCode option
+	Options DHCPv6Options
+}
+
+// DecodeFromBytes decodes the given bytes into a DHCPv6IA
+func (ia *DHCPv6IA) DecodeFromBytes(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("Not enough bytes to decode: %d", len(data))
+	}
+	ia.IAID = binary.BigEndian.Uint32(data[0:4])
+	ia.T1 = binary.BigEndian.Uint32(data[4:8])
+	ia.T2 = binary.BigEndian.Uint32(data[8:12])
+	var err error
+	ia.Options, err = decodeDHCPv6Options(data[12:], ia.Options[:0])
+	return err
+}
+
+// Encode encodes the DHCPv6IA in a slice of bytes
+func (ia *DHCPv6IA) Encode() []byte {
+	data := make([]byte, 12)
+	binary.BigEndian.PutUint32(data[0:4], ia.IAID)
+	binary.BigEndian.PutUint32(data[4:8], ia.T1)
+	binary.BigEndian.PutUint32(data[8:12], ia.T2)
+	return encodeDHCPv6Options(data, ia.Options)
+}
+
+// DHCPv6IATA is an identity association for temporary addresses, the data of
+// IATA options, as stated in RFC 8415, section 21.5.
+type DHCPv6IATA struct {
+	IAID uint32
+	// Options are the IAAddr options and the StatusCode option
+	Options DHCPv6Options
+}
+
+// DecodeFromBytes decodes the given bytes into a DHCPv6IATA
+func (ia *DHCPv6IATA) DecodeFromBytes(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("Not enough bytes to decode: %d", len(data))
+	}
+	ia.IAID = binary.BigEndian.Uint32(data[0:4])
+	var err error
+	ia.Options, err = decodeDHCPv6Options(data[4:], ia.Options[:0])
+	return err
+}
+
+// Encode encodes the DHCPv6IATA in a slice of bytes
+func (ia *DHCPv6IATA) Encode() []byte {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data[0:4], ia.IAID)
+	return encodeDHCPv6Options(data, ia.Options)
+}
+
+// DHCPv6IAAddr is an address of an identity association, the data of IAAddr
+// options, as stated in RFC 8415, section 21.6.
+type DHCPv6IAAddr struct {
+	Address net.IP
+	// PreferredLifetime and ValidLifetime are in seconds
+	PreferredLifetime, ValidLifetime uint32
+	Options                          DHCPv6Options
+}
+
+// DecodeFromBytes decodes the given bytes into a DHCPv6IAAddr
+func (a *DHCPv6IAAddr) DecodeFromBytes(data []byte) error {
+	if len(data) < 24 {
+		return fmt.Errorf("Not enough bytes to decode: %d", len(data))
+	}
+	a.Address = net.IP(data[0:16])
+	a.PreferredLifetime = binary.BigEndian.Uint32(data[16:20])
+	a.ValidLifetime = binary.BigEndian.Uint32(data[20:24])
+	var err error
+	a.Options, err = decodeDHCPv6Options(data[24:], a.Options[:0])
+	return err
+}
+
+// Encode encodes the DHCPv6IAAddr in a slice of bytes
+func (a *DHCPv6IAAddr) Encode() []byte {
+	data := make([]byte, 24)
+	copy(data[0:16], a.Address.To16())
+	binary.BigEndian.PutUint32(data[16:20], a.PreferredLifetime)
+	binary.BigEndian.PutUint32(data[20:24], a.ValidLifetime)
+	return encodeDHCPv6Options(data, a.Options)
+}
+
+// DHCPv6IAPrefix is a prefix delegated to an identity association, the data
+// of IAPrefix options, as stated in RFC 8415, section 21.22.
+type DHCPv6IAPrefix struct {
+	// PreferredLifetime and ValidLifetime are in seconds
+	PreferredLifetime, ValidLifetime uint32
+	PrefixLength                     uint8
+	Prefix                           net.IP
+	Options                          DHCPv6Options
+}
+
+// DecodeFromBytes decodes the given bytes into a DHCPv6IAPrefix
+func (p *DHCPv6IAPrefix) DecodeFromBytes(data []byte) error {
+	if len(data) < 25 {
+		return fmt.Errorf("Not enough bytes to decode: %d", len(data))
+	}
+	p.PreferredLifetime = binary.BigEndian.Uint32(data[0:4])
+	p.ValidLifetime = binary.BigEndian.Uint32(data[4:8])
+	p.PrefixLength = data[8]
+	p.Prefix = net.IP(data[9:25])
+	var err error
+	p.Options, err = decodeDHCPv6Options(data[25:], p.Options[:0])
+	return err
+}
+
+// Encode encodes the DHCPv6IAPrefix in a slice of bytes
+func (p *DHCPv6IAPrefix) Encode() []byte {
+	data := make([]byte, 25)
+	binary.BigEndian.PutUint32(data[0:4], p.PreferredLifetime)
+	binary.BigEndian.PutUint32(data[4:8], p.ValidLifetime)
+	data[8] = p.PrefixLength
+	copy(data[9:25], p.Prefix.To16())
+	return encodeDHCPv6Options(data, p.Options)
+}
+
+// DHCPv6Status is the data of StatusCode options, as stated in RFC 8415,
+// section 21.13.
+type DHCPv6Status struct {
+	StatusCode DHCPv6StatusCode
+	Message    string
+}
+
+// DecodeFromBytes decodes the given bytes into a DHCPv6Status
+func (s *DHCPv6Status) DecodeFromBytes(data []byte) error {
+	if len(data) < 2 {
+		return fmt.Errorf("Not enough bytes to decode: %d", len(data))
+	}
+	s.StatusCode = DHCPv6StatusCode(binary.BigEndian.Uint16(data[0:2]))
+	s.Message = string(data[2:])
+	return nil
+}
+
+// Encode encodes the DHCPv6Status in a slice of bytes
+func (s *DHCPv6Status) Encode() []byte {
+	data := make([]byte, 2, 2+len(s.Message))
+	binary.BigEndian.PutUint16(data[0:2], uint16(s.StatusCode))
+	return append(data, s.Message...)
+}
+
+// DHCPv6FQDN is the data of ClientFQDN options, as stated in RFC 4704.
+type DHCPv6FQDN struct {
+	// ServerUpdate, Override and NoUpdate are the S, O and N flags
+	ServerUpdate, Override, NoUpdate bool
+	// Name is the domain name, without trailing dot
+	Name string
+	// Partial is set for names which aren't fully qualified
+	Partial bool
+}
+
+// DecodeFromBytes decodes the given bytes into a DHCPv6FQDN
+func (f *DHCPv6FQDN) DecodeFromBytes(data []byte) error {
+	if len(data) < 1 {
+		return fmt.Errorf("Not enough bytes to decode: %d", len(data))
+	}
+	f.ServerUpdate = data[0]&0x1 != 0
+	f.Override = data[0]&0x2 != 0
+	f.NoUpdate = data[0]&0x4 != 0
+	f.Name, f.Partial = "", true
+	var name []byte
+	for data = data[1:]; len(data) > 0; {
+		n := int(data[0])
+		if n == 0 {
+			if len(data) != 1 {
+				return errors.New("DHCPv6 FQDN data after root label")
+			}
+			f.Partial = false
+			break
+		}
+		if n > 63 || len(data) < 1+n {
+			return fmt.Errorf("invalid DHCPv6 FQDN label length %d", n)
+		}
+		if len(name) > 0 {
+			name = append(name, '.')
+		}
+		name = append(name, data[1:1+n]...)
+		data = data[1+n:]
+	}
+	f.Name = string(name)
+	return nil
+}
+
+// Encode encodes the DHCPv6FQDN in a slice of bytes
+func (f *DHCPv6FQDN) Encode() []byte {
+	data := []byte{0}
+	if f.ServerUpdate {
+		data[0] |= 0x1
+	}
+	if f.Override {
+		data[0] |= 0x2
+	}
+	if f.NoUpdate {
+		data[0] |= 0x4
+	}
+	if f.Name != "" {
+		for _, label := range strings.Split(strings.TrimSuffix(f.Name, "."), ".") {
+			data = append(data, byte(len(label)))
+			data = append(data, label...)
+		}
+	}
+	if !f.Partial {
+		data = append(data, 0)
+	}
+	return data
+}
+
+// DHCPv6VendorClass is the data of VendorClass options, as stated in RFC
+// 8415, section 21.16.
+type DHCPv6VendorClass struct {
+	EnterpriseNumber uint32
+	Data             [][]byte
+}
+
+// DecodeFromBytes decodes the given bytes into a DHCPv6VendorClass
+func (v *DHCPv6VendorClass) DecodeFromBytes(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("Not enough bytes to decode: %d", len(data))
+	}
+	v.EnterpriseNumber = binary.BigEndian.Uint32(data[0:4])
+	v.Data = v.Data[:0]
+	for data = data[4:]; len(data) > 0; {
+		if len(data) < 2 {
+			return fmt.Errorf("Not enough bytes to decode: %d", len(data))
+		}
+		n := int(binary.BigEndian.Uint16(data[0:2]))
+		if len(data) < 2+n {
+			return fmt.Errorf("dhcpv6 vendor class data size < length %d", n)
+		}
+		v.Data = append(v.Data, data[2:2+n])
+		data = data[2+n:]
+	}
+	return nil
+}
+
+// Encode encodes the DHCPv6VendorClass in a slice of bytes
+func (v *DHCPv6VendorClass) Encode() []byte {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data[0:4], v.EnterpriseNumber)
+	for _, d := range v.Data {
+		data = append(data, byte(len(d)>>8), byte(len(d)))
+		data = append(data, d...)
+	}
+	return data
+}
+
+// DHCPv6VendorOpts is the data of VendorOpts options, as stated in RFC 8415,
+// section 21.17.  The codes of its options are defined by the vendor.
+type DHCPv6VendorOpts struct {
+	EnterpriseNumber uint32
+	Options          DHCPv6Options
+}
+
+// DecodeFromBytes decodes the given bytes into a DHCPv6VendorOpts
+func (v *DHCPv6VendorOpts) DecodeFromBytes(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("Not enough bytes to decode: %d", len(data))
+	}
+	v.EnterpriseNumber = binary.BigEndian.Uint32(data[0:4])
+	var err error
+	v.Options, err = decodeDHCPv6Options(data[4:], v.Options[:0])
+	return err
+}
+
+// Encode encodes the DHCPv6VendorOpts in a slice of bytes
+func (v *DHCPv6VendorOpts) Encode() []byte {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data[0:4], v.EnterpriseNumber)
+	return encodeDHCPv6Options(data, v.Options)
+}
@@ -8,6 +8,7 @@ package layers
 
 import (
 	"bytes"
+	"net"
 	"testing"
 
 	"github.com/google/gopacket"
@@ -91,3 +92,116 @@ func testDHCPv6OptionEqual(t *testing.T, idx int, d1, d2 DHCPv6Option) {
 		t.Errorf("expection Options[%d].Data to be = %v, got %v", idx, d1.Data, d2.Data)
 	}
 }
+
+func TestDHCPv6RelayAndOptions(t *testing.T) {
+	addr := &DHCPv6IAAddr{Address: net.ParseIP("2001:db8::10"), PreferredLifetime: 3600, ValidLifetime: 7200}
+	status := &DHCPv6Status{StatusCode: DHCPv6StatusCodeSuccess, Message: "ok"}
+	iana := &DHCPv6IA{IAID: 1, T1: 1800, T2: 2880, Options: DHCPv6Options{
+		NewDHCPv6Option(DHCPv6OptIAAddr, addr.Encode()),
+		NewDHCPv6Option(DHCPv6OptStatusCode, status.Encode()),
+	}}
+	prefix := &DHCPv6IAPrefix{PreferredLifetime: 3600, ValidLifetime: 7200, PrefixLength: 56, Prefix: net.ParseIP("2001:db8:100::")}
+	iapd := &DHCPv6IA{IAID: 2, Options: DHCPv6Options{NewDHCPv6Option(DHCPv6OptIAPrefix, prefix.Encode())}}
+	iata := &DHCPv6IATA{IAID: 3}
+	fqdn := &DHCPv6FQDN{ServerUpdate: true, Name: "host.example.com"}
+	class := &DHCPv6VendorClass{EnterpriseNumber: 4491, Data: [][]byte{[]byte("docsis3.0")}}
+	vendor := &DHCPv6VendorOpts{EnterpriseNumber: 4491, Options: DHCPv6Options{NewDHCPv6Option(1, []byte{0, 32})}}
+	reply := &DHCPv6{MsgType: DHCPv6MsgTypeReply, TransactionID: []byte{1, 2, 3}, Options: DHCPv6Options{
+		NewDHCPv6Option(DHCPv6OptIANA, iana.Encode()),
+		NewDHCPv6Option(DHCPv6OptIAPD, iapd.Encode()),
+		NewDHCPv6Option(DHCPv6OptIATA, iata.Encode()),
+		NewDHCPv6Option(DHCPv6OptClientFQDN, fqdn.Encode()),
+		NewDHCPv6Option(DHCPv6OptVendorClass, class.Encode()),
+		NewDHCPv6Option(DHCPv6OptVendorOpts, vendor.Encode()),
+	}}
+	inner := &DHCPv6{MsgType: DHCPv6MsgTypeRelayReply, HopCount: 0, LinkAddr: net.ParseIP("2001:db8:1::1"), PeerAddr: net.ParseIP("fe80::1")}
+	outer := &DHCPv6{MsgType: DHCPv6MsgTypeRelayReply, HopCount: 1, LinkAddr: net.ParseIP("2001:db8:2::1"), PeerAddr: net.ParseIP("fe80::2"), Options: DHCPv6Options{
+		NewDHCPv6Option(DHCPv6OptInterfaceID, []byte("eth0")),
+	}}
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true}
+	if err := gopacket.SerializeLayers(buf, opts, outer, inner, reply); err != nil {
+		t.Fatal(err)
+	}
+
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeDHCPv6, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeDHCPv6, LayerTypeDHCPv6, LayerTypeDHCPv6}, t)
+	layers := p.Layers()
+	outer2, inner2, reply2 := layers[0].(*DHCPv6), layers[1].(*DHCPv6), layers[2].(*DHCPv6)
+	if outer2.HopCount != 1 || !outer2.PeerAddr.Equal(outer.PeerAddr) || len(outer2.Options) != 2 || string(outer2.Option(DHCPv6OptInterfaceID).Data) != "eth0" {
+		t.Errorf("wrong outer relay %v", outer2)
+	}
+	if !inner2.LinkAddr.Equal(inner.LinkAddr) || inner2.Option(DHCPv6OptRelayMessage) == nil {
+		t.Errorf("wrong inner relay %v", inner2)
+	}
+	testDHCPv6Equal(t, reply, reply2)
+
+	// Decoded relay messages serialize their relayed message once, in place
+	// of their RelayMessage option
+	ip := &IPv6{Version: 6, HopLimit: 64, NextHeader: IPProtocolUDP, SrcIP: net.ParseIP("fe80::2"), DstIP: net.ParseIP("fe80::3")}
+	udp := &UDP{SrcPort: 547, DstPort: 547}
+	outer.Options = DHCPv6Options{
+		NewDHCPv6Option(DHCPv6OptRelayMessage, nil),
+		NewDHCPv6Option(DHCPv6OptInterfaceID, []byte("eth0")),
+	}
+	buf = gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, opts, &Ethernet{SrcMAC: net.HardwareAddr{1, 2, 3, 4, 5, 6}, DstMAC: net.HardwareAddr{6, 5, 4, 3, 2, 1}, EthernetType: EthernetTypeIPv6}, ip, udp, outer, inner, reply); err != nil {
+		t.Fatal(err)
+	}
+	p = gopacket.NewPacket(buf.Bytes(), LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv6, LayerTypeUDP, LayerTypeDHCPv6, LayerTypeDHCPv6, LayerTypeDHCPv6}, t)
+	if o := p.Layers()[3].(*DHCPv6).Options; len(o) != 2 || o[0].Code != DHCPv6OptRelayMessage || string(o[1].Data) != "eth0" {
+		t.Errorf("wrong outer relay options %v", o)
+	}
+	checkSerialization(p, t)
+
+	var iana2 DHCPv6IA
+	if err := iana2.DecodeFromBytes(reply2.Option(DHCPv6OptIANA).Data); err != nil {
+		t.Fatal(err)
+	}
+	var addr2 DHCPv6IAAddr
+	var status2 DHCPv6Status
+	if iana2.IAID != 1 || iana2.T1 != 1800 || iana2.T2 != 2880 || len(iana2.Options) != 2 {
+		t.Fatalf("wrong IANA %+v", iana2)
+	}
+	if err := addr2.DecodeFromBytes(iana2.Options[0].Data); err != nil || !addr2.Address.Equal(addr.Address) || addr2.ValidLifetime != 7200 {
+		t.Errorf("wrong IAAddr %+v %v", addr2, err)
+	}
+	if err := status2.DecodeFromBytes(iana2.Options[1].Data); err != nil || status2 != *status {
+		t.Errorf("wrong StatusCode %+v %v", status2, err)
+	}
+	var iapd2 DHCPv6IA
+	var prefix2 DHCPv6IAPrefix
+	if err := iapd2.DecodeFromBytes(reply2.Option(DHCPv6OptIAPD).Data); err != nil || len(iapd2.Options) != 1 {
+		t.Fatalf("wrong IAPD %+v %v", iapd2, err)
+	}
+	if err := prefix2.DecodeFromBytes(iapd2.Options[0].Data); err != nil || prefix2.PrefixLength != 56 || !prefix2.Prefix.Equal(prefix.Prefix) {
+		t.Errorf("wrong IAPrefix %+v %v", prefix2, err)
+	}
+	var iata2 DHCPv6IATA
+	if err := iata2.DecodeFromBytes(reply2.Option(DHCPv6OptIATA).Data); err != nil || iata2.IAID != 3 {
+		t.Errorf("wrong IATA %+v %v", iata2, err)
+	}
+	var fqdn2 DHCPv6FQDN
+	if err := fqdn2.DecodeFromBytes(reply2.Option(DHCPv6OptClientFQDN).Data); err != nil || fqdn2 != *fqdn {
+		t.Errorf("wrong ClientFQDN %+v %v", fqdn2, err)
+	}
+	var class2 DHCPv6VendorClass
+	if err := class2.DecodeFromBytes(reply2.Option(DHCPv6OptVendorClass).Data); err != nil || class2.EnterpriseNumber != 4491 || len(class2.Data) != 1 || string(class2.Data[0]) != "docsis3.0" {
+		t.Errorf("wrong VendorClass %+v %v", class2, err)
+	}
+	var vendor2 DHCPv6VendorOpts
+	if err := vendor2.DecodeFromBytes(reply2.Option(DHCPv6OptVendorOpts).Data); err != nil || vendor2.EnterpriseNumber != 4491 || len(vendor2.Options) != 1 {
+		t.Errorf("wrong VendorOpts %+v %v", vendor2, err)
+	}
+	if s := reply2.Options[0].String(); s != "Option(IA_NA:[IAID: 1, T1: 1800, T2: 2880, Options: [Option(IAAddr:[Address: 2001:db8::10, PreferredLifetime: 3600, ValidLifetime: 7200, Options: []]), Option(StatusCode:[Success: \"ok\"])]])" {
+		t.Errorf("wrong IANA string %s", s)
+	}
+}
@@ -0,0 +1,455 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/gopacket"
+)
+
+// DiameterCommandCode is the command code of a Diameter message.
+type DiameterCommandCode uint32
+
+// Some Diameter command codes, of RFC 6733, RFC 4006 and 3GPP TS 29.272
+// and 29.229
+const (
+	DiameterCapabilitiesExchange    DiameterCommandCode = 257
+	DiameterReAuth                  DiameterCommandCode = 258
+	DiameterAA                      DiameterCommandCode = 265
+	DiameterEAP                     DiameterCommandCode = 268
+	DiameterAccounting              DiameterCommandCode = 271
+	DiameterCreditControl           DiameterCommandCode = 272
+	DiameterAbortSession            DiameterCommandCode = 274
+	DiameterSessionTermination      DiameterCommandCode = 275
+	DiameterDeviceWatchdog          DiameterCommandCode = 280
+	DiameterDisconnectPeer          DiameterCommandCode = 282
+	DiameterUserAuthorization       DiameterCommandCode = 300
+	DiameterServerAssignment        DiameterCommandCode = 301
+	DiameterLocationInfo            DiameterCommandCode = 302
+	DiameterMultimediaAuth          DiameterCommandCode = 303
+	DiameterRegistrationTermination DiameterCommandCode = 304
+	DiameterPushProfile             DiameterCommandCode = 305
+	DiameterUpdateLocation          DiameterCommandCode = 316
+	DiameterCancelLocation          DiameterCommandCode = 317
+	DiameterAuthenticationInfo      DiameterCommandCode = 318
+	DiameterInsertSubscriberData    DiameterCommandCode = 319
+	DiameterDeleteSubscriberData    DiameterCommandCode = 320
+	DiameterPurgeUE                 DiameterCommandCode = 321
+	DiameterReset                   DiameterCommandCode = 322
+	DiameterNotify                  DiameterCommandCode = 323
+)
+
+var diameterCommandCodeNames = map[DiameterCommandCode]string{
+	DiameterCapabilitiesExchange:    "Capabilities-Exchange",
+	DiameterReAuth:                  "Re-Auth",
+	DiameterAA:                      "AA",
+	DiameterEAP:                     "Diameter-EAP",
+	DiameterAccounting:              "Accounting",
+	DiameterCreditControl:           "Credit-Control",
+	DiameterAbortSession:            "Abort-Session",
+	DiameterSessionTermination:      "Session-Termination",
+	DiameterDeviceWatchdog:          "Device-Watchdog",
+	DiameterDisconnectPeer:          "Disconnect-Peer",
+	DiameterUserAuthorization:       "User-Authorization",
+	DiameterServerAssignment:        "Server-Assignment",
+	DiameterLocationInfo:            "Location-Info",
+	DiameterMultimediaAuth:          "Multimedia-Auth",
+	DiameterRegistrationTermination: "Registration-Termination",
+	DiameterPushProfile:             "Push-Profile",
+	DiameterUpdateLocation:          "Update-Location",
+	DiameterCancelLocation:          "Cancel-Location",
+	DiameterAuthenticationInfo:      "Authentication-Information",
+	DiameterInsertSubscriberData:    "Insert-Subscriber-Data",
+	DiameterDeleteSubscriberData:    "Delete-Subscriber-Data",
+	DiameterPurgeUE:                 "Purge-UE",
+	DiameterReset:                   "Reset",
+	DiameterNotify:                  "Notify",
+}
+
+func (c DiameterCommandCode) String() string {
+	if name, ok := diameterCommandCodeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("DiameterCommandCode(%d)", uint32(c))
+}
+
+// Some Diameter application IDs
+const (
+	DiameterApplicationCommon         uint32 = 0
+	DiameterApplicationNASREQ         uint32 = 1
+	DiameterApplicationBaseAccounting uint32 = 3
+	DiameterApplicationCreditControl  uint32 = 4
+	DiameterApplicationEAP            uint32 = 5
+	DiameterApplicationCx             uint32 = 16777216
+	DiameterApplicationGx             uint32 = 16777238
+	DiameterApplicationS6a            uint32 = 16777251
+	DiameterApplicationRelay          uint32 = 0xffffffff
+)
+
+// DiameterVendor3GPP is the vendor ID of the AVPs of 3GPP.
+const DiameterVendor3GPP uint32 = 10415
+
+const (
+	diameterHeaderLength          = 20
+	diameterAVPHeaderLength       = 8
+	diameterVendorAVPHeaderLength = 12
+	diameterMaxLength             = 1<<24 - 1
+)
+
+// Some Diameter AVP codes, of the base protocol and of credit control
+const (
+	DiameterAVPUserName                    uint32 = 1
+	DiameterAVPHostIPAddress               uint32 = 257
+	DiameterAVPAuthApplicationID           uint32 = 258
+	DiameterAVPAcctApplicationID           uint32 = 259
+	DiameterAVPVendorSpecificApplicationID uint32 = 260
+	DiameterAVPSessionID                   uint32 = 263
+	DiameterAVPOriginHost                  uint32 = 264
+	DiameterAVPSupportedVendorID           uint32 = 265
+	DiameterAVPVendorID                    uint32 = 266
+	DiameterAVPResultCode                  uint32 = 268
+	DiameterAVPProductName                 uint32 = 269
+	DiameterAVPAuthSessionState            uint32 = 277
+	DiameterAVPOriginStateID               uint32 = 278
+	DiameterAVPFailedAVP                   uint32 = 279
+	DiameterAVPRouteRecord                 uint32 = 282
+	DiameterAVPDestinationRealm            uint32 = 283
+	DiameterAVPProxyInfo                   uint32 = 284
+	DiameterAVPDestinationHost             uint32 = 293
+	DiameterAVPOriginRealm                 uint32 = 296
+	DiameterAVPExperimentalResult          uint32 = 297
+	DiameterAVPExperimentalResultCode      uint32 = 298
+	DiameterAVPCCRequestNumber             uint32 = 415
+	DiameterAVPCCRequestType               uint32 = 416
+	DiameterAVPGrantedServiceUnit          uint32 = 431
+	DiameterAVPRequestedServiceUnit        uint32 = 437
+	DiameterAVPSubscriptionID              uint32 = 443
+	DiameterAVPUsedServiceUnit             uint32 = 446
+	DiameterAVPMultipleServicesCC          uint32 = 456
+	DiameterAVPUserEquipmentInfo           uint32 = 458
+	DiameterAVPServiceContextID            uint32 = 461
+)
+
+type diameterAVPKey struct {
+	vendor, code uint32
+}
+
+var diameterGroupedAVPsMu sync.Mutex
+
+var diameterGroupedAVPs atomic.Value // map[diameterAVPKey]bool
+
+// RegisterDiameterGroupedAVP registers the AVP of vendor and code as a
+// grouped AVP, whose data is decoded into AVPs.  The grouped AVPs of the
+// base protocol and of credit control are registered, and the ones of
+// 3GPP used by S6a and Gx.  It may be called while packets are decoded by
+// other goroutines.
+func RegisterDiameterGroupedAVP(vendor, code uint32) {
+	diameterGroupedAVPsMu.Lock()
+	defer diameterGroupedAVPsMu.Unlock()
+	old, _ := diameterGroupedAVPs.Load().(map[diameterAVPKey]bool)
+	m := make(map[diameterAVPKey]bool, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[diameterAVPKey{vendor, code}] = true
+	diameterGroupedAVPs.Store(m)
+}
+
+func init() {
+	for _, code := range []uint32{
+		DiameterAVPVendorSpecificApplicationID, DiameterAVPFailedAVP, DiameterAVPProxyInfo,
+		DiameterAVPExperimentalResult, DiameterAVPGrantedServiceUnit, DiameterAVPRequestedServiceUnit,
+		DiameterAVPSubscriptionID, DiameterAVPUsedServiceUnit, DiameterAVPMultipleServicesCC,
+		DiameterAVPUserEquipmentInfo,
+		413, // CC-Money
+		445, // Unit-Value
+	} {
+		RegisterDiameterGroupedAVP(0, code)
+	}
+	for _, code := range []uint32{
+		628,  // Supported-Features
+		1016, // QoS-Information
+		1400, // Subscription-Data
+		1408, // Requested-EUTRAN-Authentication-Info
+		1413, // Authentication-Info
+		1414, // E-UTRAN-Vector
+		1429, // APN-Configuration-Profile
+		1430, // APN-Configuration
+		1431, // EPS-Subscribed-QoS-Profile
+		1435, // AMBR
+	} {
+		RegisterDiameterGroupedAVP(DiameterVendor3GPP, code)
+	}
+}
+
+// DiameterAVP is an attribute-value pair of a Diameter message.  Its data is
+// decoded by the methods of its type, e.g. Uint32 or Address.
+type DiameterAVP struct {
+	Code uint32
+	// VendorSpecific, Mandatory and Protected are the V, M and P flags
+	VendorSpecific, Mandatory, Protected bool
+	// Length is the length of the AVP, including its header but not its
+	// padding
+	Length   uint32
+	VendorID uint32
+	Data     []byte
+	// AVPs are the AVPs of grouped AVPs, registered with
+	// RegisterDiameterGroupedAVP
+	AVPs []DiameterAVP
+}
+
+// AVP returns the first AVP of the grouped AVP of code and vendor, or nil.
+func (a *DiameterAVP) AVP(code, vendor uint32) *DiameterAVP {
+	return diameterAVP(a.AVPs, code, vendor)
+}
+
+func diameterAVP(avps []DiameterAVP, code, vendor uint32) *DiameterAVP {
+	for i := range avps {
+		if avps[i].Code == code && avps[i].VendorID == vendor {
+			return &avps[i]
+		}
+	}
+	return nil
+}
+
+// Uint32 returns the data of Unsigned32, Integer32 and Enumerated AVPs.
+func (a *DiameterAVP) Uint32() (uint32, error) {
+	if len(a.Data) != 4 {
+		return 0, fmt.Errorf("Diameter AVP of %d bytes isn't a 32-bit integer", len(a.Data))
+	}
+	return binary.BigEndian.Uint32(a.Data), nil
+}
+
+// Uint64 returns the data of Unsigned64 and Integer64 AVPs.
+func (a *DiameterAVP) Uint64() (uint64, error) {
+	if len(a.Data) != 8 {
+		return 0, fmt.Errorf("Diameter AVP of %d bytes isn't a 64-bit integer", len(a.Data))
+	}
+	return binary.BigEndian.Uint64(a.Data), nil
+}
+
+// Address returns the IPv4 or IPv6 address of Address AVPs.
+func (a *DiameterAVP) Address() (net.IP, error) {
+	if len(a.Data) == 6 && binary.BigEndian.Uint16(a.Data) == 1 {
+		return net.IP(a.Data[2:]), nil
+	}
+	if len(a.Data) == 18 && binary.BigEndian.Uint16(a.Data) == 2 {
+		return net.IP(a.Data[2:]), nil
+	}
+	return nil, errors.New("Diameter AVP isn't an IP address")
+}
+
+// Diameter is a Diameter message, RFC 6733, carried over TCP or SCTP.  The
+// messages following it in the same segment or chunk are decoded as the
+// next layers.
+type Diameter struct {
+	BaseLayer
+	Version uint8
+	// MessageLength is the length of the message, including its header
+	MessageLength uint32
+	// Request, Proxiable, Error and Retransmitted are the R, P, E and T flags
+	Request, Proxiable, Error, Retransmitted bool
+	CommandCode                              DiameterCommandCode
+	ApplicationID                            uint32
+	HopByHopID, EndToEndID                   uint32
+	AVPs                                     []DiameterAVP
+}
+
+// LayerType returns LayerTypeDiameter.
+func (d *Diameter) LayerType() gopacket.LayerType { return LayerTypeDiameter }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (d *Diameter) CanDecode() gopacket.LayerClass { return LayerTypeDiameter }
+
+// NextLayerType returns LayerTypeDiameter if another message follows.
+func (d *Diameter) NextLayerType() gopacket.LayerType {
+	if len(d.Payload) > 0 {
+		return LayerTypeDiameter
+	}
+	return gopacket.LayerTypeZero
+}
+
+// AVP returns the first AVP of code and vendor, or nil.
+func (d *Diameter) AVP(code, vendor uint32) *DiameterAVP {
+	return diameterAVP(d.AVPs, code, vendor)
+}
+
+func decodeDiameterAVPs(data []byte, avps []DiameterAVP) ([]DiameterAVP, error) {
+	grouped, _ := diameterGroupedAVPs.Load().(map[diameterAVPKey]bool)
+	for len(data) > 0 {
+		if len(data) < diameterAVPHeaderLength {
+			return avps, errors.New("Diameter AVP too short")
+		}
+		a := DiameterAVP{
+			Code:           binary.BigEndian.Uint32(data[0:4]),
+			VendorSpecific: data[4]&0x80 != 0,
+			Mandatory:      data[4]&0x40 != 0,
+			Protected:      data[4]&0x20 != 0,
+			Length:         binary.BigEndian.Uint32(data[4:8]) & 0xffffff,
+		}
+		hlen := diameterAVPHeaderLength
+		if a.VendorSpecific {
+			hlen = diameterVendorAVPHeaderLength
+		}
+		if int(a.Length) < hlen || int(a.Length) > len(data) {
+			return avps, fmt.Errorf("invalid Diameter AVP length %d", a.Length)
+		}
+		if a.VendorSpecific {
+			a.VendorID = binary.BigEndian.Uint32(data[8:12])
+		}
+		a.Data = data[hlen:a.Length]
+		if grouped[diameterAVPKey{a.VendorID, a.Code}] {
+			var err error
+			if a.AVPs, err = decodeDiameterAVPs(a.Data, nil); err != nil {
+				return avps, err
+			}
+		}
+		avps = append(avps, a)
+		padded := (int(a.Length) + 3) &^ 3
+		if padded > len(data) {
+			// the padding of the last AVP may be missing
+			padded = len(data)
+		}
+		data = data[padded:]
+	}
+	return avps, nil
+}
+
+// DecodeFromBytes decodes the slice into the Diameter struct.
+func (d *Diameter) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < diameterHeaderLength {
+		df.SetTruncated()
+		return errors.New("Diameter header too short")
+	}
+	d.Version = data[0]
+	if d.Version != 1 {
+		return fmt.Errorf("unsupported Diameter version %d", d.Version)
+	}
+	d.MessageLength = binary.BigEndian.Uint32(data[0:4]) & 0xffffff
+	d.Request = data[4]&0x80 != 0
+	d.Proxiable = data[4]&0x40 != 0
+	d.Error = data[4]&0x20 != 0
+	d.Retransmitted = data[4]&0x10 != 0
+	d.CommandCode = DiameterCommandCode(binary.BigEndian.Uint32(data[4:8]) & 0xffffff)
+	d.ApplicationID = binary.BigEndian.Uint32(data[8:12])
+	d.HopByHopID = binary.BigEndian.Uint32(data[12:16])
+	d.EndToEndID = binary.BigEndian.Uint32(data[16:20])
+	if d.MessageLength < diameterHeaderLength || d.MessageLength%4 != 0 {
+		return fmt.Errorf("invalid Diameter message length %d", d.MessageLength)
+	}
+	if len(data) < int(d.MessageLength) {
+		df.SetTruncated()
+		return errors.New("Diameter message too short")
+	}
+	var err error
+	if d.AVPs, err = decodeDiameterAVPs(data[diameterHeaderLength:d.MessageLength], d.AVPs[:0]); err != nil {
+		return err
+	}
+	d.Contents = data[:d.MessageLength]
+	d.Payload = data[d.MessageLength:]
+	return nil
+}
+
+func decodeDiameter(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&Diameter{}, data, p)
+}
+
+func serializeDiameterAVPs(avps []DiameterAVP, fix bool) ([]byte, error) {
+	var data []byte
+	for i := range avps {
+		a := &avps[i]
+		value := a.Data
+		if len(a.AVPs) > 0 {
+			var err error
+			if value, err = serializeDiameterAVPs(a.AVPs, fix); err != nil {
+				return nil, err
+			}
+		}
+		hlen := diameterAVPHeaderLength
+		if a.VendorSpecific {
+			hlen = diameterVendorAVPHeaderLength
+		}
+		if fix {
+			if hlen+len(value) > diameterMaxLength {
+				return nil, fmt.Errorf("Diameter AVP of %d bytes too long", len(value))
+			}
+			a.Length = uint32(hlen + len(value))
+		}
+		if int(a.Length) != hlen+len(value) {
+			return nil, fmt.Errorf("Diameter AVP length %d for %d bytes", a.Length, len(value))
+		}
+		flags := byte(0)
+		if a.VendorSpecific {
+			flags |= 0x80
+		}
+		if a.Mandatory {
+			flags |= 0x40
+		}
+		if a.Protected {
+			flags |= 0x20
+		}
+		data = append(data, byte(a.Code>>24), byte(a.Code>>16), byte(a.Code>>8), byte(a.Code))
+		data = append(data, flags, byte(a.Length>>16), byte(a.Length>>8), byte(a.Length))
+		if a.VendorSpecific {
+			data = append(data, byte(a.VendorID>>24), byte(a.VendorID>>16), byte(a.VendorID>>8), byte(a.VendorID))
+		}
+		data = append(data, value...)
+		data = append(data, make([]byte, (4-len(value)%4)%4)...)
+	}
+	return data, nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.  The data of
+// grouped AVPs with AVPs is serialized from them.  With FixLengths, the
+// lengths of the AVPs and of the message are set.
+func (d *Diameter) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	avps, err := serializeDiameterAVPs(d.AVPs, opts.FixLengths)
+	if err != nil {
+		return err
+	}
+	length := diameterHeaderLength + len(avps)
+	if length > diameterMaxLength {
+		return errors.New("Diameter message too long")
+	}
+	if opts.FixLengths {
+		d.MessageLength = uint32(length)
+	}
+	if d.CommandCode > diameterMaxLength {
+		return fmt.Errorf("Diameter command code %d exceeds max for 24-bit uint", d.CommandCode)
+	}
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(bytes[0:4], uint32(d.Version)<<24|d.MessageLength&0xffffff)
+	flags := uint32(0)
+	if d.Request {
+		flags |= 0x80
+	}
+	if d.Proxiable {
+		flags |= 0x40
+	}
+	if d.Error {
+		flags |= 0x20
+	}
+	if d.Retransmitted {
+		flags |= 0x10
+	}
+	binary.BigEndian.PutUint32(bytes[4:8], flags<<24|uint32(d.CommandCode))
+	binary.BigEndian.PutUint32(bytes[8:12], d.ApplicationID)
+	binary.BigEndian.PutUint32(bytes[12:16], d.HopByHopID)
+	binary.BigEndian.PutUint32(bytes[16:20], d.EndToEndID)
+	copy(bytes[diameterHeaderLength:], avps)
+	return nil
+}
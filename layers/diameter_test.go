@@ -0,0 +1,102 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestDiameter(t *testing.T) {
+	cer := &Diameter{
+		Version:       1,
+		Request:       true,
+		CommandCode:   DiameterCapabilitiesExchange,
+		ApplicationID: DiameterApplicationCommon,
+		HopByHopID:    0x11223344,
+		EndToEndID:    0x55667788,
+		AVPs: []DiameterAVP{
+			{Code: DiameterAVPOriginHost, Mandatory: true, Data: []byte("mme.example.org")},
+			{Code: DiameterAVPHostIPAddress, Mandatory: true, Data: []byte{0, 1, 192, 0, 2, 1}},
+			{Code: DiameterAVPVendorSpecificApplicationID, Mandatory: true, AVPs: []DiameterAVP{
+				{Code: DiameterAVPVendorID, Mandatory: true, Data: []byte{0, 0, 0x28, 0xaf}},
+				{Code: DiameterAVPAuthApplicationID, Mandatory: true, Data: []byte{0x01, 0x00, 0x00, 0x23}},
+			}},
+			{Code: 628, VendorSpecific: true, VendorID: DiameterVendor3GPP, AVPs: []DiameterAVP{
+				{Code: 629, VendorSpecific: true, VendorID: DiameterVendor3GPP, Data: []byte{0, 0, 0, 1}},
+			}},
+		},
+	}
+	dwa := &Diameter{Version: 1, CommandCode: DiameterDeviceWatchdog, AVPs: []DiameterAVP{
+		{Code: DiameterAVPResultCode, Mandatory: true, Data: []byte{0, 0, 0x07, 0xd1}},
+	}}
+	ip := &IPv4{Version: 4, IHL: 5, TTL: 64, Protocol: IPProtocolTCP, SrcIP: net.IP{192, 0, 2, 1}, DstIP: net.IP{192, 0, 2, 2}}
+	tcp := &TCP{SrcPort: 40000, DstPort: 3868, DataOffset: 5, Window: 1024}
+	tcp.SetNetworkLayerForChecksum(ip)
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts,
+		ip, tcp, cer, dwa); err != nil {
+		t.Fatal(err)
+	}
+	if cer.MessageLength%4 != 0 || cer.AVPs[0].Length != 8+15 {
+		t.Errorf("lengths not fixed: %d %d", cer.MessageLength, cer.AVPs[0].Length)
+	}
+
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv4, gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true})
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeTCP, LayerTypeDiameter, LayerTypeDiameter}, t)
+	layers := p.Layers()
+	d := layers[2].(*Diameter)
+	if !d.Request || d.CommandCode != DiameterCapabilitiesExchange || d.HopByHopID != 0x11223344 || d.EndToEndID != 0x55667788 {
+		t.Errorf("wrong header %+v", d)
+	}
+	if host := d.AVP(DiameterAVPOriginHost, 0); host == nil || string(host.Data) != "mme.example.org" || !host.Mandatory {
+		t.Errorf("wrong Origin-Host %+v", host)
+	}
+	if ip, err := d.AVP(DiameterAVPHostIPAddress, 0).Address(); err != nil || !ip.Equal(net.IP{192, 0, 2, 1}) {
+		t.Errorf("wrong Host-IP-Address %v %v", ip, err)
+	}
+	vsa := d.AVP(DiameterAVPVendorSpecificApplicationID, 0)
+	if vsa == nil || len(vsa.AVPs) != 2 {
+		t.Fatalf("wrong Vendor-Specific-Application-Id %+v", vsa)
+	}
+	if id, err := vsa.AVP(DiameterAVPAuthApplicationID, 0).Uint32(); err != nil || id != DiameterApplicationS6a {
+		t.Errorf("wrong Auth-Application-Id %d %v", id, err)
+	}
+	features := d.AVP(628, DiameterVendor3GPP)
+	if features == nil || !features.VendorSpecific || len(features.AVPs) != 1 || features.AVPs[0].VendorID != DiameterVendor3GPP {
+		t.Errorf("wrong Supported-Features %+v", features)
+	}
+	d = layers[3].(*Diameter)
+	if d.Request || d.CommandCode != DiameterDeviceWatchdog {
+		t.Errorf("wrong header %+v", d)
+	}
+	if rc, err := d.AVP(DiameterAVPResultCode, 0).Uint32(); err != nil || rc != 2001 {
+		t.Errorf("wrong Result-Code %d %v", rc, err)
+	}
+
+	// Diameter over SCTP is decoded by payload protocol identifier.
+	payload := append([]byte(nil), layers[2].LayerContents()...)
+	data := append([]byte{0, 3, 0, 0}, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, SCTPPayloadDiameter)
+	data = append(data, payload...)
+	data[3] = byte(len(data))
+	p = gopacket.NewPacket(data, gopacket.DecodeFunc(decodeSCTPData), gopacket.Default)
+	checkLayers(p, []gopacket.LayerType{LayerTypeSCTPData, LayerTypeDiameter}, t)
+	if d, ok := p.Layer(LayerTypeDiameter).(*Diameter); !ok || !bytes.Equal(d.Contents, payload) {
+		t.Error("Diameter over SCTP not decoded")
+	}
+
+	if _, err := decodeDiameterAVPs([]byte{0, 0, 1, 8, 0x80, 0, 0, 8}, nil); err == nil {
+		t.Error("vendor specific AVP without vendor ID decoded")
+	}
+}
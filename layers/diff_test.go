@@ -0,0 +1,55 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestDiff(t *testing.T) {
+	a := gopacket.NewPacket(testSimpleTCPPacket, LinkTypeEthernet, gopacket.Default)
+	if diffs := gopacket.Diff(a, gopacket.NewPacket(testSimpleTCPPacket, LinkTypeEthernet, gopacket.Default)); diffs != nil {
+		t.Errorf("expected no differences, got %v", diffs)
+	}
+
+	data := append([]byte(nil), testSimpleTCPPacket...)
+	data[19] ^= 0xff // last byte of IPv4 Id
+	data[22] = 1     // IPv4 TTL
+	data[41] ^= 0xff // last byte of TCP Seq
+	b := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default)
+	diffs := gopacket.Diff(a, b)
+	want := []gopacket.PacketDiff{
+		// all differences of a layer get the offset of its first differing byte
+		{Layer: 1, LayerType: LayerTypeIPv4, Field: "Id", A: "14815", B: "14624", LayerOffset: 19},
+		{Layer: 1, LayerType: LayerTypeIPv4, Field: "TTL", A: "64", B: "1", LayerOffset: 19},
+		{Layer: 2, LayerType: LayerTypeTCP, Field: "Seq", A: "3313372744", B: "3313372855", LayerOffset: 41},
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("expected %d differences, got %v", len(want), diffs)
+	}
+	for i := range want {
+		if diffs[i] != want[i] {
+			t.Errorf("difference %d: expected %v, got %v", i, want[i], diffs[i])
+		}
+	}
+	if s := diffs[2].String(); s != "layer 2 (TCP) Seq @41: 3313372744 != 3313372855" {
+		t.Errorf("unexpected string %q", s)
+	}
+
+	// missing layers
+	b = gopacket.NewPacket(testSimpleTCPPacket[:34], LinkTypeEthernet, gopacket.Default)
+	diffs = gopacket.Diff(a, b)
+	if len(diffs) == 0 {
+		t.Fatal("expected differences")
+	}
+	last := diffs[len(diffs)-1]
+	if last.Field != "" || last.B != "<none>" || last.Layer != len(a.Layers())-1 {
+		t.Errorf("unexpected difference %v", last)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestDissectionJSON(t *testing.T) {
+	var buf bytes.Buffer
+	e := gopacket.NewDissectionExporter(&buf, gopacket.DissectionJSON, gopacket.DissectionOptions{Raw: true})
+	for i := 0; i < 2; i++ {
+		if err := e.WritePacket(gopacket.NewPacket(testSimpleTCPPacket, LinkTypeEthernet, gopacket.Default)); err != nil {
+			t.Fatal("can't write packet:", err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal("can't close exporter:", err)
+	}
+
+	var packets []struct {
+		Source struct {
+			Layers map[string]interface{}
+		} `json:"_source"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &packets); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.Bytes())
+	}
+	if len(packets) != 2 {
+		t.Fatalf("expected 2 packets, got %d", len(packets))
+	}
+	layers := packets[1].Source.Layers
+	for proto, want := range map[string][]float64{
+		"frame_raw": {0, 434},
+		"eth_raw":   {0, 14},
+		"ip_raw":    {14, 20},
+		"tcp_raw":   {34, 32},
+		"data_raw":  {66, 368},
+	} {
+		raw, _ := layers[proto].([]interface{})
+		if len(raw) != 5 || raw[1] != want[0] || raw[2] != want[1] {
+			t.Errorf("%s: expected offset and length %v, got %v", proto, want, raw)
+		}
+	}
+	frame, _ := layers["frame"].(map[string]interface{})
+	if frame["frame.number"] != "2" || frame["frame.protocols"] != "eth:ip:tcp:data" {
+		t.Errorf("wrong frame fields %v", frame)
+	}
+	ip, _ := layers["ip"].(map[string]interface{})
+	if ip["ip.src"] != "172.17.81.73" || ip["ip.proto"] != "6" || ip["ip.hdr_len"] != "20" {
+		t.Errorf("wrong ip fields %v", ip)
+	}
+	tcp, _ := layers["tcp"].(map[string]interface{})
+	if tcp["tcp.dstport"] != "80" || tcp["tcp.flags.push"] != "1" || tcp["tcp.hdr_len"] != "32" || !reflect.DeepEqual(tcp["tcp.options.optiontype"], []interface{}{"1", "1", "8"}) {
+		t.Errorf("wrong tcp fields %v", tcp)
+	}
+}
+
+func TestDissectionProtocols(t *testing.T) {
+	var buf bytes.Buffer
+	e := gopacket.NewDissectionExporter(&buf, gopacket.DissectionJSON, gopacket.DissectionOptions{})
+	if err := e.WritePacket(gopacket.NewPacket(testPacketGeneve1, LinkTypeLinuxSLL, gopacket.Default)); err != nil {
+		t.Fatal("can't write packet:", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal("can't close exporter:", err)
+	}
+	var packets []struct {
+		Source struct {
+			Layers map[string]interface{}
+		} `json:"_source"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &packets); err != nil || len(packets) != 1 {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.Bytes())
+	}
+	frame, _ := packets[0].Source.Layers["frame"].(map[string]interface{})
+	if got, want := frame["frame.protocols"], "sll:ip:udp:geneve:eth:ip:icmp:data"; got != want {
+		t.Errorf("got protocols %v, want %s", got, want)
+	}
+}
+
+func TestDissectionPDML(t *testing.T) {
+	var buf bytes.Buffer
+	e := gopacket.NewDissectionExporter(&buf, gopacket.DissectionPDML, gopacket.DissectionOptions{})
+	// truncated TCP header
+	if err := e.WritePacket(gopacket.NewPacket(testSimpleTCPPacket[:60], LinkTypeEthernet, gopacket.Default)); err != nil {
+		t.Fatal("can't write packet:", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal("can't close exporter:", err)
+	}
+
+	var pdml struct {
+		Packets []struct {
+			Protos []struct {
+				Name   string `xml:"name,attr"`
+				Pos    int    `xml:"pos,attr"`
+				Size   int    `xml:"size,attr"`
+				Fields []struct {
+					Name string `xml:"name,attr"`
+					Show string `xml:"show,attr"`
+				} `xml:"field"`
+			} `xml:"proto"`
+		} `xml:"packet"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &pdml); err != nil {
+		t.Fatalf("invalid PDML: %v\n%s", err, buf.Bytes())
+	}
+	if len(pdml.Packets) != 1 {
+		t.Fatalf("expected 1 packet, got %d", len(pdml.Packets))
+	}
+	protos := pdml.Packets[0].Protos
+	want := []struct {
+		name      string
+		pos, size int
+	}{{"frame", 0, 60}, {"eth", 0, 14}, {"ip", 14, 20}, {"tcp", 34, 26}, {"_ws.malformed", 60, 0}}
+	if len(protos) != len(want) {
+		t.Fatalf("expected %d protocols, got %d", len(want), len(protos))
+	}
+	for i, w := range want {
+		if protos[i].Name != w.name || protos[i].Pos != w.pos || protos[i].Size != w.size {
+			t.Errorf("protocol %d: expected %v, got %s at %d with size %d", i, w, protos[i].Name, protos[i].Pos, protos[i].Size)
+		}
+	}
+	if f := protos[2].Fields; len(f) == 0 || f[len(f)-1].Name != "ip.dst" || f[len(f)-1].Show != "173.222.254.225" {
+		t.Errorf("wrong ip fields %v", f)
+	}
+}
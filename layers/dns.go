@@ -52,26 +52,37 @@ type DNSType uint16
 
 // DNSType known values.
 const (
-	DNSTypeA     DNSType = 1   // a host address
-	DNSTypeNS    DNSType = 2   // an authoritative name server
-	DNSTypeMD    DNSType = 3   // a mail destination (Obsolete - use MX)
-	DNSTypeMF    DNSType = 4   // a mail forwarder (Obsolete - use MX)
-	DNSTypeCNAME DNSType = 5   // the canonical name for an alias
-	DNSTypeSOA   DNSType = 6   // marks the start of a zone of authority
-	DNSTypeMB    DNSType = 7   // a mailbox domain name (EXPERIMENTAL)
-	DNSTypeMG    DNSType = 8   // a mail group member (EXPERIMENTAL)
-	DNSTypeMR    DNSType = 9   // a mail rename domain name (EXPERIMENTAL)
-	DNSTypeNULL  DNSType = 10  // a null RR (EXPERIMENTAL)
-	DNSTypeWKS   DNSType = 11  // a well known service description
-	DNSTypePTR   DNSType = 12  // a domain name pointer
-	DNSTypeHINFO DNSType = 13  // host information
-	DNSTypeMINFO DNSType = 14  // mailbox or mail list information
-	DNSTypeMX    DNSType = 15  // mail exchange
-	DNSTypeTXT   DNSType = 16  // text strings
-	DNSTypeAAAA  DNSType = 28  // a IPv6 host address [RFC3596]
-	DNSTypeSRV   DNSType = 33  // server discovery [RFC2782] [RFC6195]
-	DNSTypeOPT   DNSType = 41  // OPT Pseudo-RR [RFC6891]
-	DNSTypeURI   DNSType = 256 // URI RR [RFC7553]
+	DNSTypeA      DNSType = 1   // a host address
+	DNSTypeNS     DNSType = 2   // an authoritative name server
+	DNSTypeMD     DNSType = 3   // a mail destination (Obsolete - use MX)
+	DNSTypeMF     DNSType = 4   // a mail forwarder (Obsolete - use MX)
+	DNSTypeCNAME  DNSType = 5   // the canonical name for an alias
+	DNSTypeSOA    DNSType = 6   // marks the start of a zone of authority
+	DNSTypeMB     DNSType = 7   // a mailbox domain name (EXPERIMENTAL)
+	DNSTypeMG     DNSType = 8   // a mail group member (EXPERIMENTAL)
+	DNSTypeMR     DNSType = 9   // a mail rename domain name (EXPERIMENTAL)
+	DNSTypeNULL   DNSType = 10  // a null RR (EXPERIMENTAL)
+	DNSTypeWKS    DNSType = 11  // a well known service description
+	DNSTypePTR    DNSType = 12  // a domain name pointer
+	DNSTypeHINFO  DNSType = 13  // host information
+	DNSTypeMINFO  DNSType = 14  // mailbox or mail list information
+	DNSTypeMX     DNSType = 15  // mail exchange
+	DNSTypeTXT    DNSType = 16  // text strings
+	DNSTypeAAAA   DNSType = 28  // a IPv6 host address [RFC3596]
+	DNSTypeSRV    DNSType = 33  // server discovery [RFC2782] [RFC6195]
+	DNSTypeOPT    DNSType = 41  // OPT Pseudo-RR [RFC6891]
+	DNSTypeDS     DNSType = 43  // Delegation Signer [RFC4034]
+	DNSTypeRRSIG  DNSType = 46  // RRSIG [RFC4034]
+	DNSTypeNSEC   DNSType = 47  // NSEC [RFC4034]
+	DNSTypeDNSKEY DNSType = 48  // DNSKEY [RFC4034]
+	DNSTypeNSEC3  DNSType = 50  // NSEC3 [RFC5155]
+	DNSTypeTLSA   DNSType = 52  // TLSA [RFC6698]
+	DNSTypeSVCB   DNSType = 64  // General Purpose Service Binding [RFC9460]
+	DNSTypeHTTPS  DNSType = 65  // SVCB-compatible type for use with HTTP [RFC9460]
+	DNSTypeIXFR   DNSType = 251 // incremental transfer [RFC1995]
+	DNSTypeAXFR   DNSType = 252 // transfer of an entire zone
+	DNSTypeURI    DNSType = 256 // URI RR [RFC7553]
+	DNSTypeCAA    DNSType = 257 // Certification Authority Restriction [RFC8659]
 )
 
 func (dt DNSType) String() string {
@@ -116,8 +127,30 @@ func (dt DNSType) String() string {
 		return "SRV"
 	case DNSTypeOPT:
 		return "OPT"
+	case DNSTypeDS:
+		return "DS"
+	case DNSTypeRRSIG:
+		return "RRSIG"
+	case DNSTypeNSEC:
+		return "NSEC"
+	case DNSTypeDNSKEY:
+		return "DNSKEY"
+	case DNSTypeNSEC3:
+		return "NSEC3"
+	case DNSTypeTLSA:
+		return "TLSA"
+	case DNSTypeSVCB:
+		return "SVCB"
+	case DNSTypeHTTPS:
+		return "HTTPS"
+	case DNSTypeIXFR:
+		return "IXFR"
+	case DNSTypeAXFR:
+		return "AXFR"
 	case DNSTypeURI:
 		return "URI"
+	case DNSTypeCAA:
+		return "CAA"
 	}
 }
 
@@ -420,15 +453,15 @@ func recSize(rr *DNSResourceRecord) int {
 	case DNSTypeAAAA:
 		return 16
 	case DNSTypeNS:
-		return len(rr.NS) + 2
+		return nameSize(rr.NS)
 	case DNSTypeCNAME:
-		return len(rr.CNAME) + 2
+		return nameSize(rr.CNAME)
 	case DNSTypePTR:
-		return len(rr.PTR) + 2
+		return nameSize(rr.PTR)
 	case DNSTypeSOA:
-		return len(rr.SOA.MName) + 2 + len(rr.SOA.RName) + 2 + 20
+		return nameSize(rr.SOA.MName) + nameSize(rr.SOA.RName) + 20
 	case DNSTypeMX:
-		return 2 + len(rr.MX.Name) + 2
+		return 2 + nameSize(rr.MX.Name)
 	case DNSTypeTXT:
 		l := len(rr.TXTs)
 		for _, txt := range rr.TXTs {
@@ -436,9 +469,17 @@ func recSize(rr *DNSResourceRecord) int {
 		}
 		return l
 	case DNSTypeSRV:
-		return 6 + len(rr.SRV.Name) + 2
+		return 6 + nameSize(rr.SRV.Name)
 	case DNSTypeURI:
 		return 4 + len(rr.URI.Target)
+	case DNSTypeSVCB, DNSTypeHTTPS:
+		return rr.SVCB.size()
+	case DNSTypeTLSA:
+		return 3 + len(rr.TLSA.Certificate)
+	case DNSTypeCAA:
+		return 2 + len(rr.CAA.Tag) + len(rr.CAA.Value)
+	case DNSTypeDNSKEY, DNSTypeDS, DNSTypeRRSIG, DNSTypeNSEC, DNSTypeNSEC3:
+		return rr.dnssecSize()
 	case DNSTypeOPT:
 		l := len(rr.OPT) * 4
 		for _, opt := range rr.OPT {
@@ -701,6 +742,14 @@ type DNSResourceRecord struct {
 	MX             DNSMX
 	OPT            []DNSOPT // See RFC 6891, section 6.1.2
 	URI            DNSURI
+	SVCB           DNSSVCB // SVCB and HTTPS records
+	TLSA           DNSTLSA
+	CAA            DNSCAA
+	DNSKEY         DNSDNSKEY
+	DS             DNSDS
+	RRSIG          DNSRRSIG
+	NSEC           DNSNSEC
+	NSEC3          DNSNSEC3
 
 	// Undecoded TXT for backward compatibility
 	TXT []byte
@@ -816,6 +865,25 @@ func (rr *DNSResourceRecord) encode(data []byte, offset int, opts gopacket.Seria
 			copy(data[noff2+4:], opt.Data)
 			noff2 += 4 + len(opt.Data)
 		}
+	case DNSTypeSVCB, DNSTypeHTTPS:
+		rr.SVCB.encode(data, noff+10)
+	case DNSTypeTLSA:
+		data[noff+10] = rr.TLSA.Usage
+		data[noff+11] = rr.TLSA.Selector
+		data[noff+12] = rr.TLSA.MatchingType
+		copy(data[noff+13:], rr.TLSA.Certificate)
+	case DNSTypeCAA:
+		if len(rr.CAA.Tag) == 0 || len(rr.CAA.Tag) > 255 {
+			return 0, fmt.Errorf("invalid CAA tag length %d", len(rr.CAA.Tag))
+		}
+		data[noff+10] = rr.CAA.Flags
+		data[noff+11] = byte(len(rr.CAA.Tag))
+		copy(data[noff+12:], rr.CAA.Tag)
+		copy(data[noff+12+len(rr.CAA.Tag):], rr.CAA.Value)
+	case DNSTypeDNSKEY, DNSTypeDS, DNSTypeRRSIG, DNSTypeNSEC, DNSTypeNSEC3:
+		if err := rr.encodeDNSSEC(data, noff+10); err != nil {
+			return 0, err
+		}
 	default:
 		return 0, fmt.Errorf("serializing resource record of type %v not supported", rr.Type)
 	}
@@ -855,6 +923,10 @@ func (rr *DNSResourceRecord) String() string {
 			return "PTR " + string(rr.PTR)
 		case DNSTypeTXT:
 			return "TXT " + string(rr.TXT)
+		case DNSTypeSVCB, DNSTypeHTTPS:
+			return fmt.Sprintf("%v %d %s", rr.Type, rr.SVCB.Priority, string(rr.SVCB.Target))
+		case DNSTypeCAA:
+			return fmt.Sprintf("CAA %d %s %q", rr.CAA.Flags, string(rr.CAA.Tag), string(rr.CAA.Value))
 		}
 	}
 
@@ -988,6 +1060,25 @@ func (rr *DNSResourceRecord) decodeRData(data []byte, offset int, buffer *[]byte
 			return err
 		}
 		rr.OPT = allOPT
+	case DNSTypeSVCB, DNSTypeHTTPS:
+		return rr.SVCB.decode(data, offset, buffer)
+	case DNSTypeTLSA:
+		if len(rr.Data) < 3 {
+			return errors.New("TLSA too small")
+		}
+		rr.TLSA.Usage = rr.Data[0]
+		rr.TLSA.Selector = rr.Data[1]
+		rr.TLSA.MatchingType = rr.Data[2]
+		rr.TLSA.Certificate = rr.Data[3:]
+	case DNSTypeCAA:
+		if len(rr.Data) < 2 || len(rr.Data) < 2+int(rr.Data[1]) {
+			return errors.New("CAA too small")
+		}
+		rr.CAA.Flags = rr.Data[0]
+		rr.CAA.Tag = rr.Data[2 : 2+int(rr.Data[1])]
+		rr.CAA.Value = rr.Data[2+int(rr.Data[1]):]
+	case DNSTypeDNSKEY, DNSTypeDS, DNSTypeRRSIG, DNSTypeNSEC, DNSTypeNSEC3:
+		return rr.decodeDNSSECRData(data, offset, buffer)
 	}
 	return nil
 }
@@ -0,0 +1,502 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+)
+
+// nameSize returns the length of an encoded name, the root name being
+// encoded as a single byte.
+func nameSize(name []byte) int {
+	if len(name) == 0 {
+		return 1
+	}
+	return len(name) + 2
+}
+
+// DNSSVCBParamKey is the key of a service parameter of SVCB and HTTPS records,
+// see RFC 9460, section 14.3.2
+type DNSSVCBParamKey uint16
+
+// DNSSVCBParamKey known values.
+const (
+	DNSSVCBParamMandatory     DNSSVCBParamKey = 0
+	DNSSVCBParamALPN          DNSSVCBParamKey = 1
+	DNSSVCBParamNoDefaultALPN DNSSVCBParamKey = 2
+	DNSSVCBParamPort          DNSSVCBParamKey = 3
+	DNSSVCBParamIPv4Hint      DNSSVCBParamKey = 4
+	DNSSVCBParamECH           DNSSVCBParamKey = 5
+	DNSSVCBParamIPv6Hint      DNSSVCBParamKey = 6
+)
+
+func (k DNSSVCBParamKey) String() string {
+	switch k {
+	case DNSSVCBParamMandatory:
+		return "mandatory"
+	case DNSSVCBParamALPN:
+		return "alpn"
+	case DNSSVCBParamNoDefaultALPN:
+		return "no-default-alpn"
+	case DNSSVCBParamPort:
+		return "port"
+	case DNSSVCBParamIPv4Hint:
+		return "ipv4hint"
+	case DNSSVCBParamECH:
+		return "ech"
+	case DNSSVCBParamIPv6Hint:
+		return "ipv6hint"
+	}
+	return fmt.Sprintf("key%d", uint16(k))
+}
+
+// DNSSVCBParam is a service parameter of SVCB and HTTPS records.  Its value is
+// decoded by the methods of its key.
+type DNSSVCBParam struct {
+	Key   DNSSVCBParamKey
+	Value []byte
+}
+
+// ALPN returns the protocol IDs of alpn parameters.
+func (p DNSSVCBParam) ALPN() ([][]byte, error) {
+	if p.Key != DNSSVCBParamALPN {
+		return nil, fmt.Errorf("SVCB parameter %v isn't alpn", p.Key)
+	}
+	return decodeCharacterStrings(p.Value)
+}
+
+// Port returns the port of port parameters.
+func (p DNSSVCBParam) Port() (uint16, error) {
+	if p.Key != DNSSVCBParamPort || len(p.Value) != 2 {
+		return 0, errors.New("invalid SVCB port parameter")
+	}
+	return binary.BigEndian.Uint16(p.Value), nil
+}
+
+// IPHints returns the addresses of ipv4hint and ipv6hint parameters.
+func (p DNSSVCBParam) IPHints() ([]net.IP, error) {
+	size := net.IPv4len
+	if p.Key == DNSSVCBParamIPv6Hint {
+		size = net.IPv6len
+	} else if p.Key != DNSSVCBParamIPv4Hint {
+		return nil, fmt.Errorf("SVCB parameter %v isn't an IP hint", p.Key)
+	}
+	if len(p.Value) == 0 || len(p.Value)%size != 0 {
+		return nil, errors.New("invalid SVCB IP hint parameter")
+	}
+	var ips []net.IP
+	for i := 0; i < len(p.Value); i += size {
+		ips = append(ips, net.IP(p.Value[i:i+size]))
+	}
+	return ips, nil
+}
+
+// DNSSVCB is a service binding record, of SVCB and HTTPS records, see RFC
+// 9460.  A Priority of 0 is the alias form.
+type DNSSVCB struct {
+	Priority uint16
+	Target   []byte
+	Params   []DNSSVCBParam
+}
+
+// Param returns the parameter of key, or nil.
+func (s *DNSSVCB) Param(key DNSSVCBParamKey) *DNSSVCBParam {
+	for i := range s.Params {
+		if s.Params[i].Key == key {
+			return &s.Params[i]
+		}
+	}
+	return nil
+}
+
+func (s *DNSSVCB) decode(data []byte, offset int, buffer *[]byte) error {
+	if len(data) < offset+2 {
+		return errors.New("SVCB too small")
+	}
+	s.Priority = binary.BigEndian.Uint16(data[offset : offset+2])
+	name, i, err := decodeName(data, offset+2, buffer, 1)
+	if err != nil {
+		return err
+	}
+	s.Target = name
+	for i < len(data) {
+		if len(data) < i+4 {
+			return errors.New("SVCB parameter too small")
+		}
+		p := DNSSVCBParam{Key: DNSSVCBParamKey(binary.BigEndian.Uint16(data[i : i+2]))}
+		l := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if len(data) < i+4+l {
+			return errors.New("SVCB parameter too small")
+		}
+		p.Value = data[i+4 : i+4+l]
+		s.Params = append(s.Params, p)
+		i += 4 + l
+	}
+	return nil
+}
+
+func (s *DNSSVCB) size() int {
+	l := 2 + nameSize(s.Target)
+	for _, p := range s.Params {
+		l += 4 + len(p.Value)
+	}
+	return l
+}
+
+func (s *DNSSVCB) encode(data []byte, offset int) {
+	binary.BigEndian.PutUint16(data[offset:], s.Priority)
+	offset = encodeName(s.Target, data, offset+2)
+	for _, p := range s.Params {
+		binary.BigEndian.PutUint16(data[offset:], uint16(p.Key))
+		binary.BigEndian.PutUint16(data[offset+2:], uint16(len(p.Value)))
+		copy(data[offset+4:], p.Value)
+		offset += 4 + len(p.Value)
+	}
+}
+
+// DNSTLSA is a TLSA record, associating a certificate with a service, see RFC
+// 6698.
+type DNSTLSA struct {
+	Usage, Selector, MatchingType uint8
+	Certificate                   []byte
+}
+
+// DNSCAA is a Certification Authority Authorization record, see RFC 8659.
+type DNSCAA struct {
+	Flags uint8
+	Tag   []byte
+	Value []byte
+}
+
+// DNSDNSKEY is a DNSKEY record, holding a public key used by DNSSEC, see RFC
+// 4034, section 2.
+type DNSDNSKEY struct {
+	Flags     uint16
+	Protocol  uint8
+	Algorithm uint8
+	PublicKey []byte
+}
+
+// DNSDS is a Delegation Signer record, referring to a DNSKEY record of a
+// delegated zone, see RFC 4034, section 5.
+type DNSDS struct {
+	KeyTag     uint16
+	Algorithm  uint8
+	DigestType uint8
+	Digest     []byte
+}
+
+// DNSRRSIG is a RRSIG record, holding the DNSSEC signature of a record set,
+// see RFC 4034, section 3.
+type DNSRRSIG struct {
+	TypeCovered DNSType
+	Algorithm   uint8
+	Labels      uint8
+	OriginalTTL uint32
+	// Expiration and Inception are in seconds since 1 January 1970
+	Expiration, Inception uint32
+	KeyTag                uint16
+	SignerName            []byte
+	Signature             []byte
+}
+
+// DNSNSEC is a NSEC record, proving the non-existence of names and types, see
+// RFC 4034, section 4.
+type DNSNSEC struct {
+	NextDomain []byte
+	Types      []DNSType
+}
+
+// DNSNSEC3 is a NSEC3 record, proving the non-existence of hashed names and
+// types, see RFC 5155, section 3.
+type DNSNSEC3 struct {
+	HashAlgorithm   uint8
+	Flags           uint8
+	Iterations      uint16
+	Salt            []byte
+	NextHashedOwner []byte
+	Types           []DNSType
+}
+
+func decodeDNSTypeBitmap(data []byte) ([]DNSType, error) {
+	var types []DNSType
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, errors.New("DNS type bitmap too small")
+		}
+		window, l := int(data[0]), int(data[1])
+		if l == 0 || l > 32 || len(data) < 2+l {
+			return nil, errors.New("invalid DNS type bitmap length")
+		}
+		for i, b := range data[2 : 2+l] {
+			for bit := 0; bit < 8; bit++ {
+				if b&(0x80>>uint(bit)) != 0 {
+					types = append(types, DNSType(window<<8|i<<3|bit))
+				}
+			}
+		}
+		data = data[2+l:]
+	}
+	return types, nil
+}
+
+func encodeDNSTypeBitmap(types []DNSType) []byte {
+	sorted := append([]DNSType(nil), types...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var data []byte
+	window := -1
+	start := 0
+	for _, t := range sorted {
+		if int(t>>8) != window {
+			window = int(t >> 8)
+			start = len(data)
+			data = append(data, byte(window), 0)
+		}
+		i := int(t&0xff) >> 3
+		for int(data[start+1]) <= i {
+			data = append(data, 0)
+			data[start+1]++
+		}
+		data[start+2+i] |= 0x80 >> uint(t&7)
+	}
+	return data
+}
+
+func (rr *DNSResourceRecord) decodeDNSSECRData(data []byte, offset int, buffer *[]byte) error {
+	rdata := data[offset:]
+	switch rr.Type {
+	case DNSTypeDNSKEY:
+		if len(rdata) < 4 {
+			return errors.New("DNSKEY too small")
+		}
+		rr.DNSKEY.Flags = binary.BigEndian.Uint16(rdata[0:2])
+		rr.DNSKEY.Protocol = rdata[2]
+		rr.DNSKEY.Algorithm = rdata[3]
+		rr.DNSKEY.PublicKey = rdata[4:]
+	case DNSTypeDS:
+		if len(rdata) < 4 {
+			return errors.New("DS too small")
+		}
+		rr.DS.KeyTag = binary.BigEndian.Uint16(rdata[0:2])
+		rr.DS.Algorithm = rdata[2]
+		rr.DS.DigestType = rdata[3]
+		rr.DS.Digest = rdata[4:]
+	case DNSTypeRRSIG:
+		if len(rdata) < 18 {
+			return errors.New("RRSIG too small")
+		}
+		rr.RRSIG.TypeCovered = DNSType(binary.BigEndian.Uint16(rdata[0:2]))
+		rr.RRSIG.Algorithm = rdata[2]
+		rr.RRSIG.Labels = rdata[3]
+		rr.RRSIG.OriginalTTL = binary.BigEndian.Uint32(rdata[4:8])
+		rr.RRSIG.Expiration = binary.BigEndian.Uint32(rdata[8:12])
+		rr.RRSIG.Inception = binary.BigEndian.Uint32(rdata[12:16])
+		rr.RRSIG.KeyTag = binary.BigEndian.Uint16(rdata[16:18])
+		name, endq, err := decodeName(data, offset+18, buffer, 1)
+		if err != nil {
+			return err
+		}
+		rr.RRSIG.SignerName = name
+		rr.RRSIG.Signature = data[endq:]
+	case DNSTypeNSEC:
+		name, endq, err := decodeName(data, offset, buffer, 1)
+		if err != nil {
+			return err
+		}
+		rr.NSEC.NextDomain = name
+		if rr.NSEC.Types, err = decodeDNSTypeBitmap(data[endq:]); err != nil {
+			return err
+		}
+	case DNSTypeNSEC3:
+		if len(rdata) < 5 {
+			return errors.New("NSEC3 too small")
+		}
+		rr.NSEC3.HashAlgorithm = rdata[0]
+		rr.NSEC3.Flags = rdata[1]
+		rr.NSEC3.Iterations = binary.BigEndian.Uint16(rdata[2:4])
+		i := 5 + int(rdata[4])
+		if len(rdata) < i+1 {
+			return errors.New("NSEC3 too small")
+		}
+		rr.NSEC3.Salt = rdata[5:i]
+		j := i + 1 + int(rdata[i])
+		if len(rdata) < j {
+			return errors.New("NSEC3 too small")
+		}
+		rr.NSEC3.NextHashedOwner = rdata[i+1 : j]
+		var err error
+		if rr.NSEC3.Types, err = decodeDNSTypeBitmap(rdata[j:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rr *DNSResourceRecord) dnssecSize() int {
+	switch rr.Type {
+	case DNSTypeDNSKEY:
+		return 4 + len(rr.DNSKEY.PublicKey)
+	case DNSTypeDS:
+		return 4 + len(rr.DS.Digest)
+	case DNSTypeRRSIG:
+		return 18 + nameSize(rr.RRSIG.SignerName) + len(rr.RRSIG.Signature)
+	case DNSTypeNSEC:
+		return nameSize(rr.NSEC.NextDomain) + len(encodeDNSTypeBitmap(rr.NSEC.Types))
+	case DNSTypeNSEC3:
+		return 6 + len(rr.NSEC3.Salt) + len(rr.NSEC3.NextHashedOwner) + len(encodeDNSTypeBitmap(rr.NSEC3.Types))
+	}
+	return 0
+}
+
+func (rr *DNSResourceRecord) encodeDNSSEC(data []byte, offset int) error {
+	switch rr.Type {
+	case DNSTypeDNSKEY:
+		binary.BigEndian.PutUint16(data[offset:], rr.DNSKEY.Flags)
+		data[offset+2] = rr.DNSKEY.Protocol
+		data[offset+3] = rr.DNSKEY.Algorithm
+		copy(data[offset+4:], rr.DNSKEY.PublicKey)
+	case DNSTypeDS:
+		binary.BigEndian.PutUint16(data[offset:], rr.DS.KeyTag)
+		data[offset+2] = rr.DS.Algorithm
+		data[offset+3] = rr.DS.DigestType
+		copy(data[offset+4:], rr.DS.Digest)
+	case DNSTypeRRSIG:
+		binary.BigEndian.PutUint16(data[offset:], uint16(rr.RRSIG.TypeCovered))
+		data[offset+2] = rr.RRSIG.Algorithm
+		data[offset+3] = rr.RRSIG.Labels
+		binary.BigEndian.PutUint32(data[offset+4:], rr.RRSIG.OriginalTTL)
+		binary.BigEndian.PutUint32(data[offset+8:], rr.RRSIG.Expiration)
+		binary.BigEndian.PutUint32(data[offset+12:], rr.RRSIG.Inception)
+		binary.BigEndian.PutUint16(data[offset+16:], rr.RRSIG.KeyTag)
+		offset = encodeName(rr.RRSIG.SignerName, data, offset+18)
+		copy(data[offset:], rr.RRSIG.Signature)
+	case DNSTypeNSEC:
+		offset = encodeName(rr.NSEC.NextDomain, data, offset)
+		copy(data[offset:], encodeDNSTypeBitmap(rr.NSEC.Types))
+	case DNSTypeNSEC3:
+		if len(rr.NSEC3.Salt) > 255 || len(rr.NSEC3.NextHashedOwner) > 255 {
+			return errors.New("NSEC3 salt or hash too long")
+		}
+		data[offset] = rr.NSEC3.HashAlgorithm
+		data[offset+1] = rr.NSEC3.Flags
+		binary.BigEndian.PutUint16(data[offset+2:], rr.NSEC3.Iterations)
+		data[offset+4] = byte(len(rr.NSEC3.Salt))
+		offset += 5 + copy(data[offset+5:], rr.NSEC3.Salt)
+		data[offset] = byte(len(rr.NSEC3.NextHashedOwner))
+		offset += 1 + copy(data[offset+1:], rr.NSEC3.NextHashedOwner)
+		copy(data[offset:], encodeDNSTypeBitmap(rr.NSEC3.Types))
+	}
+	return nil
+}
+
+// DNSEDNS0 contains the EDNS(0) fields of an OPT pseudo-record, see RFC 6891,
+// section 6.1.3
+type DNSEDNS0 struct {
+	UDPPayloadSize uint16
+	// ExtendedRCode is the upper 8 bits of the response code
+	ExtendedRCode uint8
+	Version       uint8
+	DO            bool // DNSSEC OK
+	Z             uint16
+	Options       []DNSOPT
+}
+
+// EDNS0 returns the EDNS(0) fields of the OPT pseudo-record of the
+// additional records, or nil.
+func (d *DNS) EDNS0() *DNSEDNS0 {
+	for i := range d.Additionals {
+		rr := &d.Additionals[i]
+		if rr.Type == DNSTypeOPT {
+			return &DNSEDNS0{
+				UDPPayloadSize: uint16(rr.Class),
+				ExtendedRCode:  uint8(rr.TTL >> 24),
+				Version:        uint8(rr.TTL >> 16),
+				DO:             rr.TTL&0x8000 != 0,
+				Z:              uint16(rr.TTL & 0x7fff),
+				Options:        rr.OPT,
+			}
+		}
+	}
+	return nil
+}
+
+// ResourceRecord returns the OPT pseudo-record carrying the EDNS(0) fields.
+func (e *DNSEDNS0) ResourceRecord() DNSResourceRecord {
+	ttl := uint32(e.ExtendedRCode)<<24 | uint32(e.Version)<<16 | uint32(e.Z&0x7fff)
+	if e.DO {
+		ttl |= 0x8000
+	}
+	return DNSResourceRecord{Type: DNSTypeOPT, Class: DNSClass(e.UDPPayloadSize), TTL: ttl, OPT: e.Options}
+}
+
+// DNSClientSubnet is the data of EDNSClientSubnet options, see RFC 7871.
+type DNSClientSubnet struct {
+	// Family is 1 for IPv4 and 2 for IPv6
+	Family             uint16
+	SourcePrefixLength uint8
+	ScopePrefixLength  uint8
+	Address            net.IP
+}
+
+// ClientSubnet decodes the data of EDNSClientSubnet options.
+func (opt DNSOPT) ClientSubnet() (DNSClientSubnet, error) {
+	if opt.Code != DNSOptionCodeEDNSClientSubnet || len(opt.Data) < 4 {
+		return DNSClientSubnet{}, errors.New("invalid EDNS client subnet option")
+	}
+	cs := DNSClientSubnet{
+		Family:             binary.BigEndian.Uint16(opt.Data[0:2]),
+		SourcePrefixLength: opt.Data[2],
+		ScopePrefixLength:  opt.Data[3],
+	}
+	size := net.IPv4len
+	if cs.Family == 2 {
+		size = net.IPv6len
+	} else if cs.Family != 1 {
+		return DNSClientSubnet{}, fmt.Errorf("unsupported EDNS client subnet family %d", cs.Family)
+	}
+	if len(opt.Data)-4 > size || int(cs.SourcePrefixLength) > 8*size {
+		return DNSClientSubnet{}, errors.New("invalid EDNS client subnet option")
+	}
+	cs.Address = make(net.IP, size)
+	copy(cs.Address, opt.Data[4:])
+	return cs, nil
+}
+
+// OPT encodes the client subnet as an EDNSClientSubnet option, truncating
+// the address to the source prefix.
+func (cs DNSClientSubnet) OPT() DNSOPT {
+	addr := cs.Address.To4()
+	if cs.Family == 2 {
+		addr = cs.Address.To16()
+	}
+	n := (int(cs.SourcePrefixLength) + 7) / 8
+	if n > len(addr) {
+		n = len(addr)
+	}
+	data := make([]byte, 4+n)
+	binary.BigEndian.PutUint16(data[0:2], cs.Family)
+	data[2] = cs.SourcePrefixLength
+	data[3] = cs.ScopePrefixLength
+	copy(data[4:], addr[:n])
+	if bits := cs.SourcePrefixLength % 8; bits != 0 && n > 0 {
+		data[3+n] &= 0xff << (8 - bits)
+	}
+	return DNSOPT{Code: DNSOptionCodeEDNSClientSubnet, Data: data}
+}
+
+// Cookie decodes the data of Cookie options, see RFC 7873.  The server cookie
+// is missing from queries without a known server cookie.
+func (opt DNSOPT) Cookie() (client, server []byte, err error) {
+	if opt.Code != DNSOptionCodeCookie || (len(opt.Data) != 8 && (len(opt.Data) < 16 || len(opt.Data) > 40)) {
+		return nil, nil, errors.New("invalid DNS cookie option")
+	}
+	return opt.Data[:8], opt.Data[8:], nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/gopacket"
+)
+
+// DNSStream decodes the DNS messages sent in one direction of a TCP
+// connection, each message being prefixed by its 2-byte length, see RFC 1035,
+// section 4.2.2.  The connection data is passed to Decode as it's
+// reassembled, e.g. by a reassembly.Stream.
+type DNSStream struct {
+	buf []byte
+}
+
+// Decode decodes data following the data of previous calls, and returns the
+// messages completed by data.  The returned messages reference the data.  A
+// message failing to decode is skipped, so that decoding goes on with the
+// following messages, and the first error is returned with the messages
+// decoded.
+func (s *DNSStream) Decode(data []byte) ([]*DNS, error) {
+	s.buf = append(s.buf, data...)
+	var messages []*DNS
+	var firstErr error
+	for len(s.buf) >= 2 {
+		end := 2 + int(binary.BigEndian.Uint16(s.buf))
+		if len(s.buf) < end {
+			break
+		}
+		d, err := decodeDNSMessage(s.buf[2:end])
+		s.buf = s.buf[end:]
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		messages = append(messages, d)
+	}
+	if len(s.buf) == 0 {
+		s.buf = nil
+	}
+	return messages, firstErr
+}
+
+// ReadDNSMessage reads the next length-prefixed DNS message of a TCP stream
+// from r, such as a tcpreader.ReaderStream.  It returns io.EOF if r ends
+// between messages, and io.ErrUnexpectedEOF if it ends within one.
+func ReadDNSMessage(r io.Reader) (*DNS, error) {
+	var length [2]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return decodeDNSMessage(msg)
+}
+
+func decodeDNSMessage(msg []byte) (*DNS, error) {
+	d := &DNS{}
+	if err := d.DecodeFromBytes(msg, gopacket.NilDecodeFeedback); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// DNSZoneTransfer gathers the records of AXFR and IXFR responses, which can
+// span many messages.  Both start and end with the SOA record of the zone
+// served, IXFR responses being either incremental or full zones, see RFC
+// 5936 and RFC 1995.
+type DNSZoneTransfer struct {
+	// Records holds the answers in order, including the SOA records
+	// delimiting the transfer and the IXFR difference sequences.
+	Records []DNSResourceRecord
+	// Serial is the serial of the zone served.
+	Serial uint32
+	// Incremental is set for IXFR responses holding differences.
+	Incremental bool
+	// Done is set once the final SOA record is seen.
+	Done bool
+
+	messages  int
+	additions bool
+}
+
+// Add adds the answers of a response message of the transfer.
+func (z *DNSZoneTransfer) Add(d *DNS) error {
+	if !d.QR {
+		return errors.New("zone transfer message is not a response")
+	}
+	if d.ResponseCode != DNSResponseCodeNoErr {
+		return fmt.Errorf("zone transfer failed: %v", d.ResponseCode)
+	}
+	z.messages++
+	for i := range d.Answers {
+		rr := &d.Answers[i]
+		if z.Done {
+			return errors.New("records after the end of the zone transfer")
+		}
+		z.Records = append(z.Records, *rr)
+		if rr.Type != DNSTypeSOA {
+			if len(z.Records) == 1 {
+				return errors.New("zone transfer doesn't start with a SOA record")
+			}
+			continue
+		}
+		switch {
+		case len(z.Records) == 1:
+			z.Serial = rr.SOA.Serial
+		case len(z.Records) == 2 && rr.SOA.Serial != z.Serial:
+			// The first difference sequence starts with its old SOA
+			z.Incremental = true
+		case !z.Incremental:
+			z.Done = true
+		case z.additions && rr.SOA.Serial == z.Serial:
+			z.Done = true
+		default:
+			z.additions = !z.additions
+		}
+	}
+	// A single SOA record tells that an IXFR client is up to date
+	if z.messages == 1 && len(z.Records) == 1 && len(d.Questions) == 1 && d.Questions[0].Type == DNSTypeIXFR {
+		z.Done = true
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func testDNSSOA(serial uint32) DNSResourceRecord {
+	return DNSResourceRecord{Name: []byte("example.com"), Type: DNSTypeSOA, Class: DNSClassIN, TTL: 3600,
+		SOA: DNSSOA{MName: []byte("ns.example.com"), RName: []byte("admin.example.com"), Serial: serial}}
+}
+
+func testDNSA(name string, ip byte) DNSResourceRecord {
+	return DNSResourceRecord{Name: []byte(name), Type: DNSTypeA, Class: DNSClassIN, TTL: 3600, IP: net.IP{192, 0, 2, ip}}
+}
+
+// testDNSStream returns the length-prefixed messages of a zone transfer.
+func testDNSStream(t *testing.T, qtype DNSType, answers ...[]DNSResourceRecord) []byte {
+	var stream []byte
+	for _, a := range answers {
+		d := &DNS{ID: 7, QR: true, AA: true, Questions: []DNSQuestion{{Name: []byte("example.com"), Type: qtype, Class: DNSClassIN}}, Answers: a}
+		buf := gopacket.NewSerializeBuffer()
+		if err := d.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+			t.Fatal(err)
+		}
+		stream = append(stream, byte(len(buf.Bytes())>>8), byte(len(buf.Bytes())))
+		stream = append(stream, buf.Bytes()...)
+	}
+	return stream
+}
+
+func TestDNSStreamAXFR(t *testing.T) {
+	stream := testDNSStream(t, DNSTypeAXFR,
+		[]DNSResourceRecord{testDNSSOA(10), testDNSA("a.example.com", 1)},
+		[]DNSResourceRecord{testDNSA("b.example.com", 2), testDNSSOA(10)},
+	)
+	var s DNSStream
+	var z DNSZoneTransfer
+	// Feed the stream in small segments, splitting the length prefixes
+	for len(stream) > 0 {
+		n := 7
+		if n > len(stream) {
+			n = len(stream)
+		}
+		messages, err := s.Decode(stream[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream = stream[n:]
+		for _, d := range messages {
+			if err := z.Add(d); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if len(s.buf) != 0 {
+		t.Errorf("%d bytes left buffered", len(s.buf))
+	}
+	if !z.Done || z.Incremental || z.Serial != 10 || len(z.Records) != 4 || string(z.Records[2].Name) != "b.example.com" {
+		t.Errorf("unexpected zone transfer %+v", z)
+	}
+	if err := z.Add(&DNS{QR: true, Answers: []DNSResourceRecord{testDNSA("c.example.com", 3)}}); err == nil {
+		t.Error("added records after the end of the transfer")
+	}
+
+	// A message failing to decode is skipped
+	message := testDNSStream(t, DNSTypeAXFR, []DNSResourceRecord{testDNSSOA(11)})
+	stream = append(append(append([]byte(nil), message...), 0, 3, 1, 2, 3), message...)
+	messages, err := s.Decode(stream)
+	if err == nil || len(messages) != 2 || messages[1].Answers[0].SOA.Serial != 11 {
+		t.Errorf("unexpected messages %v, error %v", messages, err)
+	}
+}
+
+func TestReadDNSMessageIXFR(t *testing.T) {
+	stream := testDNSStream(t, DNSTypeIXFR,
+		[]DNSResourceRecord{testDNSSOA(3), testDNSSOA(1), testDNSA("old.example.com", 1), testDNSSOA(2)},
+		[]DNSResourceRecord{testDNSA("mid.example.com", 2), testDNSSOA(2), testDNSSOA(3)},
+		[]DNSResourceRecord{testDNSA("new.example.com", 3), testDNSSOA(3)},
+	)
+	r := bytes.NewReader(stream)
+	var z DNSZoneTransfer
+	for {
+		d, err := ReadDNSMessage(r)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if z.Done {
+			t.Fatal("transfer done before its last message")
+		}
+		if err := z.Add(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !z.Done || !z.Incremental || z.Serial != 3 || len(z.Records) != 9 {
+		t.Errorf("unexpected zone transfer %+v", z)
+	}
+
+	// Up to date
+	z = DNSZoneTransfer{}
+	d, err := ReadDNSMessage(bytes.NewReader(testDNSStream(t, DNSTypeIXFR, []DNSResourceRecord{testDNSSOA(3)})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := z.Add(d); err != nil || !z.Done || z.Incremental {
+		t.Errorf("unexpected up to date transfer %+v, %v", z, err)
+	}
+
+	if _, err := ReadDNSMessage(bytes.NewReader(stream[:20])); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v reading a truncated message, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
@@ -1161,3 +1161,117 @@ func TestDNSPacketWriteAnswer(t *testing.T) {
 		t.Fatalf("Encoded size, want %d got %d", want, got)
 	}
 }
+
+func TestDNSModernRecords(t *testing.T) {
+	cs := DNSClientSubnet{Family: 1, SourcePrefixLength: 20, Address: net.IP{192, 0, 255, 1}}
+	edns := &DNSEDNS0{UDPPayloadSize: 1232, DO: true, Options: []DNSOPT{
+		cs.OPT(),
+		{Code: DNSOptionCodeCookie, Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+	}}
+	dns := &DNS{ID: 0x4242, QR: true, OpCode: DNSOpCodeQuery, Answers: []DNSResourceRecord{
+		{Name: []byte("example.com"), Type: DNSTypeHTTPS, Class: DNSClassIN, SVCB: DNSSVCB{
+			Priority: 1,
+			Params: []DNSSVCBParam{
+				{Key: DNSSVCBParamALPN, Value: []byte("\x02h3\x02h2")},
+				{Key: DNSSVCBParamPort, Value: []byte{0x01, 0xbb}},
+				{Key: DNSSVCBParamIPv4Hint, Value: []byte{192, 0, 2, 1, 192, 0, 2, 2}},
+			},
+		}},
+		{Name: []byte("_443._tcp.example.com"), Type: DNSTypeTLSA, Class: DNSClassIN,
+			TLSA: DNSTLSA{Usage: 3, Selector: 1, MatchingType: 1, Certificate: []byte{0xde, 0xad}}},
+		{Name: []byte("example.com"), Type: DNSTypeCAA, Class: DNSClassIN,
+			CAA: DNSCAA{Tag: []byte("issue"), Value: []byte("ca.example.net")}},
+		{Name: []byte("example.com"), Type: DNSTypeDNSKEY, Class: DNSClassIN,
+			DNSKEY: DNSDNSKEY{Flags: 257, Protocol: 3, Algorithm: 13, PublicKey: []byte{1, 2, 3}}},
+		{Name: []byte("example.com"), Type: DNSTypeDS, Class: DNSClassIN,
+			DS: DNSDS{KeyTag: 2371, Algorithm: 13, DigestType: 2, Digest: []byte{4, 5}}},
+		{Name: []byte("example.com"), Type: DNSTypeRRSIG, Class: DNSClassIN, RRSIG: DNSRRSIG{
+			TypeCovered: DNSTypeA, Algorithm: 13, Labels: 2, OriginalTTL: 3600,
+			Expiration: 1700000000, Inception: 1690000000, KeyTag: 2371,
+			SignerName: []byte("example.com"), Signature: []byte{6, 7, 8},
+		}},
+		{Name: []byte("example.com"), Type: DNSTypeNSEC, Class: DNSClassIN, NSEC: DNSNSEC{
+			NextDomain: []byte("www.example.com"),
+			Types:      []DNSType{DNSTypeCAA, DNSTypeA, DNSTypeRRSIG, DNSTypeNSEC},
+		}},
+		{Name: []byte("abc.example.com"), Type: DNSTypeNSEC3, Class: DNSClassIN, NSEC3: DNSNSEC3{
+			HashAlgorithm: 1, Iterations: 10, Salt: []byte{0xaa, 0xbb},
+			NextHashedOwner: []byte{1, 2, 3, 4}, Types: []DNSType{DNSTypeAAAA},
+		}},
+	}, Additionals: []DNSResourceRecord{edns.ResourceRecord()}}
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, dns); err != nil {
+		t.Fatal(err)
+	}
+	got := &DNS{}
+	if err := got.DecodeFromBytes(buf.Bytes(), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatalf("could not decode: %v", err)
+	}
+	if len(got.Answers) != len(dns.Answers) {
+		t.Fatalf("got %d answers, want %d", len(got.Answers), len(dns.Answers))
+	}
+
+	svcb := got.Answers[0].SVCB
+	if svcb.Priority != 1 || len(svcb.Target) != 0 || len(svcb.Params) != 3 {
+		t.Errorf("unexpected HTTPS record %+v", svcb)
+	}
+	if alpn, err := svcb.Param(DNSSVCBParamALPN).ALPN(); err != nil || len(alpn) != 2 || string(alpn[0]) != "h3" {
+		t.Errorf("unexpected alpn %q, %v", alpn, err)
+	}
+	if port, err := svcb.Param(DNSSVCBParamPort).Port(); err != nil || port != 443 {
+		t.Errorf("unexpected port %d, %v", port, err)
+	}
+	if ips, err := svcb.Param(DNSSVCBParamIPv4Hint).IPHints(); err != nil || len(ips) != 2 || !ips[1].Equal(net.IP{192, 0, 2, 2}) {
+		t.Errorf("unexpected IP hints %v, %v", ips, err)
+	}
+	if svcb.Param(DNSSVCBParamECH) != nil {
+		t.Error("unexpected ech parameter")
+	}
+	if tlsa := got.Answers[1].TLSA; tlsa.Usage != 3 || tlsa.Selector != 1 || tlsa.MatchingType != 1 || !bytes.Equal(tlsa.Certificate, []byte{0xde, 0xad}) {
+		t.Errorf("unexpected TLSA record %+v", tlsa)
+	}
+	if caa := got.Answers[2].CAA; string(caa.Tag) != "issue" || string(caa.Value) != "ca.example.net" {
+		t.Errorf("unexpected CAA record %+v", caa)
+	}
+	if key := got.Answers[3].DNSKEY; key.Flags != 257 || key.Protocol != 3 || key.Algorithm != 13 || !bytes.Equal(key.PublicKey, []byte{1, 2, 3}) {
+		t.Errorf("unexpected DNSKEY record %+v", key)
+	}
+	if ds := got.Answers[4].DS; ds.KeyTag != 2371 || ds.DigestType != 2 || !bytes.Equal(ds.Digest, []byte{4, 5}) {
+		t.Errorf("unexpected DS record %+v", ds)
+	}
+	if sig := got.Answers[5].RRSIG; sig.TypeCovered != DNSTypeA || sig.Expiration != 1700000000 || string(sig.SignerName) != "example.com" || !bytes.Equal(sig.Signature, []byte{6, 7, 8}) {
+		t.Errorf("unexpected RRSIG record %+v", sig)
+	}
+	nsec := got.Answers[6].NSEC
+	if want := []DNSType{DNSTypeA, DNSTypeRRSIG, DNSTypeNSEC, DNSTypeCAA}; string(nsec.NextDomain) != "www.example.com" || len(nsec.Types) != len(want) {
+		t.Errorf("unexpected NSEC record %+v", nsec)
+	} else {
+		for i := range want {
+			if nsec.Types[i] != want[i] {
+				t.Errorf("NSEC type %d is %v, want %v", i, nsec.Types[i], want[i])
+			}
+		}
+	}
+	if nsec3 := got.Answers[7].NSEC3; nsec3.Iterations != 10 || !bytes.Equal(nsec3.Salt, []byte{0xaa, 0xbb}) || !bytes.Equal(nsec3.NextHashedOwner, []byte{1, 2, 3, 4}) || len(nsec3.Types) != 1 || nsec3.Types[0] != DNSTypeAAAA {
+		t.Errorf("unexpected NSEC3 record %+v", nsec3)
+	}
+
+	e := got.EDNS0()
+	if e == nil {
+		t.Fatal("missing EDNS0")
+	}
+	if e.UDPPayloadSize != 1232 || !e.DO || e.Version != 0 || len(e.Options) != 2 {
+		t.Fatalf("unexpected EDNS0 %+v", e)
+	}
+	subnet, err := e.Options[0].ClientSubnet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subnet.SourcePrefixLength != 20 || !subnet.Address.Equal(net.IP{192, 0, 240, 0}) {
+		t.Errorf("unexpected client subnet %+v", subnet)
+	}
+	if client, server, err := e.Options[1].Cookie(); err != nil || len(client) != 8 || len(server) != 0 {
+		t.Errorf("unexpected cookie %x %x, %v", client, server, err)
+	}
+}
@@ -56,6 +56,9 @@ this:
  layers.EthernetTypeMetadata[EthernetTypeIPv4].DecodeWith = mySpiffyIPv4Decoder
 
 This will make all future ethernet packets use your new decoder to decode IPv4
-packets, instead of the built-in decoder used by gopacket.
+packets, instead of the built-in decoder used by gopacket.  The enumeration
+metadata isn't synchronized, so it may only be modified during initialization,
+before any packets are decoded.  To change decoding at run time, use
+gopacket.DecoderOverrides, which is safe for concurrent use.
 */
 package layers
@@ -0,0 +1,357 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/gopacket"
+)
+
+// DTLSRecord is a record of a DTLS datagram, RFC 6347 section 4.1, or a
+// ciphertext record of DTLS 1.3, RFC 9147 section 4.
+type DTLSRecord struct {
+	ContentType TLSType
+	Version     TLSVersion
+	Epoch       uint16
+	// SequenceNumber is the 48 bit sequence number of the record in its
+	// epoch
+	SequenceNumber uint64
+	Length         uint16
+	// Unified is set for the ciphertext records of DTLS 1.3, whose unified
+	// header only has the low 2 bits of Epoch and the low 8 or 16 bits of
+	// SequenceNumber, which are encrypted.  Their ContentType is
+	// TLSApplicationData and their Version is 0.
+	Unified bool
+	// Data is the fragment of the record
+	Data []byte
+
+	// Encrypted is set for the records of epochs other than 0, whose Alert
+	// and handshake messages aren't decoded.
+	Encrypted bool
+	// AlertLevel and AlertDescription are the alert of plaintext Alert
+	// records
+	AlertLevel       TLSAlertLevel
+	AlertDescription TLSAlertDescr
+	// Fragments are the handshake message fragments of plaintext Handshake
+	// records.
+	Fragments []DTLSHandshakeFragment
+	// Messages are the handshake messages of the Fragments holding whole
+	// messages.  Messages fragmented over several records are reassembled
+	// by a DTLSStream.
+	Messages []TLSHandshakeMessage
+}
+
+// DTLSHandshakeFragment is a fragment of a handshake message, RFC 6347
+// section 4.2.2.  Handshake messages larger than a datagram are sent in
+// several fragments, with the same MessageSeq.
+type DTLSHandshakeFragment struct {
+	Type TLSHandshakeType
+	// Length is the length of the body of the message
+	Length         uint32
+	MessageSeq     uint16
+	FragmentOffset uint32
+	// Data is the part of the body starting at FragmentOffset
+	Data []byte
+}
+
+// Whole returns true if the fragment holds the whole message.
+func (f *DTLSHandshakeFragment) Whole() bool {
+	return f.FragmentOffset == 0 && uint32(len(f.Data)) == f.Length
+}
+
+// DTLS is a DTLS datagram, RFC 6347 and RFC 9147, made of one or more
+// records.  UDP ports commonly used by DTLS are decoded as DTLS; WebRTC
+// uses DTLS on random ports, DetectDTLS recognizes it there.
+type DTLS struct {
+	BaseLayer
+	Records []DTLSRecord
+}
+
+// LayerType returns LayerTypeDTLS.
+func (d *DTLS) LayerType() gopacket.LayerType { return LayerTypeDTLS }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (d *DTLS) CanDecode() gopacket.LayerClass { return LayerTypeDTLS }
+
+// NextLayerType returns gopacket.LayerTypeZero, since the records take the
+// whole datagram.
+func (d *DTLS) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+
+// Payload returns nil, since the data of DTLS is in its records.
+func (d *DTLS) Payload() []byte { return nil }
+
+var errDTLSTooShort = errors.New("DTLS record too short")
+
+// DecodeFromBytes decodes the records of a datagram.
+func (d *DTLS) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	d.BaseLayer = BaseLayer{Contents: data}
+	d.Records = d.Records[:0]
+	for len(data) > 0 {
+		var r DTLSRecord
+		n, err := r.decodeFromBytes(data, df)
+		if err != nil {
+			return err
+		}
+		d.Records = append(d.Records, r)
+		data = data[n:]
+	}
+	return nil
+}
+
+// decodeFromBytes decodes the record at the start of data, and returns its
+// length.
+func (r *DTLSRecord) decodeFromBytes(data []byte, df gopacket.DecodeFeedback) (int, error) {
+	if len(data) > 0 && data[0]&0xe0 == 0x20 {
+		return r.decodeUnified(data, df)
+	}
+	if len(data) < 13 {
+		df.SetTruncated()
+		return 0, errDTLSTooShort
+	}
+	r.ContentType = TLSType(data[0])
+	r.Version = TLSVersion(binary.BigEndian.Uint16(data[1:3]))
+	r.Epoch = binary.BigEndian.Uint16(data[3:5])
+	r.SequenceNumber = uint64(binary.BigEndian.Uint16(data[5:7]))<<32 | uint64(binary.BigEndian.Uint32(data[7:11]))
+	r.Length = binary.BigEndian.Uint16(data[11:13])
+	n := 13 + int(r.Length)
+	if len(data) < n {
+		df.SetTruncated()
+		return 0, errDTLSTooShort
+	}
+	r.Data = data[13:n]
+	r.Encrypted = r.Epoch != 0
+
+	switch r.ContentType {
+	default:
+		return 0, fmt.Errorf("unknown DTLS record type %d", r.ContentType)
+	case TLSChangeCipherSpec, TLSApplicationData:
+	case TLSAlert:
+		if !r.Encrypted {
+			if len(r.Data) != 2 {
+				return 0, errors.New("DTLS Alert malformed")
+			}
+			r.AlertLevel = TLSAlertLevel(r.Data[0])
+			r.AlertDescription = TLSAlertDescr(r.Data[1])
+		}
+	case TLSHandshake:
+		if !r.Encrypted {
+			if err := r.decodeFragments(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// decodeUnified decodes a record with a unified header, RFC 9147 section
+// 4.
+func (r *DTLSRecord) decodeUnified(data []byte, df gopacket.DecodeFeedback) (int, error) {
+	flags := data[0]
+	if flags&0x10 != 0 {
+		// the length of connection IDs is negotiated
+		return 0, errors.New("DTLS connection IDs not supported")
+	}
+	r.Unified = true
+	r.Encrypted = true
+	r.ContentType = TLSApplicationData
+	r.Epoch = uint16(flags & 0x03)
+	hl := 2
+	if flags&0x08 != 0 {
+		hl = 3
+	}
+	if flags&0x04 != 0 {
+		hl += 2
+	}
+	if len(data) < hl {
+		df.SetTruncated()
+		return 0, errDTLSTooShort
+	}
+	if flags&0x08 != 0 {
+		r.SequenceNumber = uint64(binary.BigEndian.Uint16(data[1:3]))
+	} else {
+		r.SequenceNumber = uint64(data[1])
+	}
+	n := len(data)
+	if flags&0x04 != 0 {
+		r.Length = binary.BigEndian.Uint16(data[hl-2 : hl])
+		n = hl + int(r.Length)
+		if len(data) < n {
+			df.SetTruncated()
+			return 0, errDTLSTooShort
+		}
+	} else {
+		// the record takes the rest of the datagram
+		r.Length = uint16(n - hl)
+	}
+	r.Data = data[hl:n]
+	return n, nil
+}
+
+// decodeFragments decodes the handshake message fragments of a plaintext
+// Handshake record.
+func (r *DTLSRecord) decodeFragments() error {
+	data := r.Data
+	for len(data) > 0 {
+		if len(data) < 12 {
+			return errors.New("DTLS handshake fragment too short")
+		}
+		f := DTLSHandshakeFragment{
+			Type:           TLSHandshakeType(data[0]),
+			Length:         uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3]),
+			MessageSeq:     binary.BigEndian.Uint16(data[4:6]),
+			FragmentOffset: uint32(data[6])<<16 | uint32(data[7])<<8 | uint32(data[8]),
+		}
+		n := 12 + (int(data[9])<<16 | int(data[10])<<8 | int(data[11]))
+		if len(data) < n || f.FragmentOffset+uint32(n-12) > f.Length {
+			return errors.New("DTLS handshake fragment malformed")
+		}
+		f.Data = data[12:n]
+		r.Fragments = append(r.Fragments, f)
+		if f.Whole() {
+			m := TLSHandshakeMessage{Type: f.Type}
+			if err := m.decodeBody(f.Data, true); err != nil {
+				return err
+			}
+			r.Messages = append(r.Messages, m)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// decodeDTLS decodes the records of a datagram.
+func decodeDTLS(data []byte, p gopacket.PacketBuilder) error {
+	d := &DTLS{}
+	if err := d.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(d)
+	p.SetApplicationLayer(d)
+	return nil
+}
+
+// DetectDTLS recognizes DTLS datagrams starting with a DTLS 1.0 or 1.2
+// record on any UDP port, like the ones of WebRTC.  Datagrams of DTLS 1.3
+// ciphertext records only can't be recognized.  To detect DTLS on UDP ports
+// not decoded as DTLS, register DetectDTLS as a hook for unknown UDP ports:
+//
+//	gopacket.RegisterUnknownProtocolHook(gopacket.UnknownUDPPort, layers.DetectDTLS)
+func DetectDTLS(u *gopacket.UnknownProtocol) gopacket.Decoder {
+	data := u.Data
+	if len(data) < 13 || data[0] < byte(TLSChangeCipherSpec) || data[0] > byte(TLSApplicationData) {
+		return nil
+	}
+	if v := TLSVersion(binary.BigEndian.Uint16(data[1:3])); v != 0xfeff && v != 0xfefd {
+		return nil
+	}
+	var d DTLS
+	if d.DecodeFromBytes(data, gopacket.NilDecodeFeedback) != nil {
+		return nil
+	}
+	return LayerTypeDTLS
+}
+
+// dtlsMaxMessageLength and dtlsMaxPendingMessages limit the memory used by
+// DTLSStream for the reassembly of handshake messages.
+const (
+	dtlsMaxMessageLength   = 1 << 20
+	dtlsMaxPendingMessages = 32
+)
+
+// DTLSStream reassembles the handshake messages sent in one direction of a
+// DTLS association, whose datagrams are passed to Decode in order of
+// arrival.  Retransmitted messages are dropped.
+type DTLSStream struct {
+	started bool
+	// next is the message_seq of the next message to return
+	next    uint16
+	pending map[uint16]*dtlsMessage
+	failed  bool
+}
+
+// dtlsMessage is a handshake message being reassembled
+type dtlsMessage struct {
+	typ  TLSHandshakeType
+	body []byte
+	// received are the sorted and disjoint ranges of body received
+	received [][2]uint32
+}
+
+// add records the reception of body[start:end].
+func (m *dtlsMessage) add(start, end uint32) {
+	m.received = append(m.received, [2]uint32{start, end})
+	sort.Slice(m.received, func(i, j int) bool { return m.received[i][0] < m.received[j][0] })
+	merged := m.received[:1]
+	for _, r := range m.received[1:] {
+		if last := &merged[len(merged)-1]; r[0] <= last[1] {
+			if r[1] > last[1] {
+				last[1] = r[1]
+			}
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	m.received = merged
+}
+
+func (m *dtlsMessage) complete() bool {
+	return len(m.received) == 1 && m.received[0] == [2]uint32{0, uint32(len(m.body))}
+}
+
+// Decode reassembles the handshake fragments of the plaintext records of d,
+// and returns the messages completed by them, in order.  The returned
+// messages don't reference d.  After an error, the stream can't be decoded
+// any further.
+func (s *DTLSStream) Decode(d *DTLS) ([]TLSHandshakeMessage, error) {
+	if s.failed {
+		return nil, nil
+	}
+	var messages []TLSHandshakeMessage
+	for i := range d.Records {
+		for _, f := range d.Records[i].Fragments {
+			if !s.started {
+				s.started = true
+				s.next = f.MessageSeq
+				s.pending = make(map[uint16]*dtlsMessage)
+			}
+			// drop retransmissions, and messages too far ahead
+			if ahead := f.MessageSeq - s.next; ahead >= dtlsMaxPendingMessages {
+				continue
+			}
+			m := s.pending[f.MessageSeq]
+			if m == nil {
+				if f.Length > dtlsMaxMessageLength {
+					s.failed = true
+					return messages, fmt.Errorf("DTLS %v of %d bytes too long", f.Type, f.Length)
+				}
+				m = &dtlsMessage{typ: f.Type, body: make([]byte, f.Length)}
+				s.pending[f.MessageSeq] = m
+			} else if m.typ != f.Type || uint32(len(m.body)) != f.Length {
+				s.failed = true
+				return messages, fmt.Errorf("DTLS %v fragment of message %d inconsistent with previous fragments", f.Type, f.MessageSeq)
+			}
+			copy(m.body[f.FragmentOffset:], f.Data)
+			m.add(f.FragmentOffset, f.FragmentOffset+uint32(len(f.Data)))
+
+			for m := s.pending[s.next]; m != nil && m.complete(); m = s.pending[s.next] {
+				message := TLSHandshakeMessage{Type: m.typ}
+				if err := message.decodeBody(m.body, true); err != nil {
+					s.failed = true
+					return messages, err
+				}
+				messages = append(messages, message)
+				delete(s.pending, s.next)
+				s.next++
+			}
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// dtlsTestRecord returns a DTLS 1.2 record.
+func dtlsTestRecord(typ TLSType, epoch uint16, seq uint64, fragment ...[]byte) []byte {
+	r := make([]byte, 11)
+	r[0] = byte(typ)
+	binary.BigEndian.PutUint16(r[1:], 0xfefd)
+	binary.BigEndian.PutUint16(r[3:], epoch)
+	binary.BigEndian.PutUint16(r[5:], uint16(seq>>32))
+	binary.BigEndian.PutUint32(r[7:], uint32(seq))
+	return append(r, tlsTestVector(2, fragment...)...)
+}
+
+// dtlsTestFragment returns the fragment of a message body starting at
+// offset.
+func dtlsTestFragment(typ TLSHandshakeType, seq uint16, offset int, body []byte, fragment []byte) []byte {
+	f := append([]byte{byte(typ)}, tlsTestVector(3)...)
+	f[1], f[2], f[3] = byte(len(body)>>16), byte(len(body)>>8), byte(len(body))
+	f = append(f, byte(seq>>8), byte(seq), byte(offset>>16), byte(offset>>8), byte(offset))
+	return append(f, tlsTestVector(3, fragment)...)
+}
+
+var (
+	testDTLSCookie      = []byte("cookie")
+	testDTLSClientHello = bytes.Join([][]byte{
+		{0xfe, 0xfd}, testTLS13Random,
+		tlsTestVector(1),
+		tlsTestVector(1, testDTLSCookie),
+		tlsTestVector(2, []byte{0xc0, 0x2b}),
+		tlsTestVector(1, []byte{0}),
+		tlsTestVector(2, tlsTestExtension(TLSExtensionServerName, tlsTestVector(2, []byte{0}, tlsTestVector(2, []byte("example.com"))))),
+	}, nil)
+)
+
+func TestDTLSHandshake(t *testing.T) {
+	hvr := append([]byte{0xfe, 0xff}, tlsTestVector(1, testDTLSCookie)...)
+	datagram := append(dtlsTestRecord(TLSHandshake, 0, 0, dtlsTestFragment(TLSHandshakeHelloVerifyRequest, 0, 0, hvr, hvr)),
+		dtlsTestRecord(TLSAlert, 0, 1, []byte{1, 0})...)
+	data, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 5684, DstPort: 50000}).Payload(datagram).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeDTLS}, t)
+	d := p.ApplicationLayer().(*DTLS)
+	if len(d.Records) != 2 {
+		t.Fatalf("got %d records", len(d.Records))
+	}
+	r := d.Records[0]
+	if r.Version != 0xfefd || r.Version.String() != "DTLS 1.2" || r.Epoch != 0 || r.Encrypted || len(r.Messages) != 1 {
+		t.Fatalf("unexpected record %#v", r)
+	}
+	if m := r.Messages[0]; m.Type != TLSHandshakeHelloVerifyRequest || m.Version != 0xfeff || !bytes.Equal(m.Cookie, testDTLSCookie) {
+		t.Errorf("unexpected HelloVerifyRequest %#v", m)
+	}
+	if r := d.Records[1]; r.SequenceNumber != 1 || r.AlertLevel != TLSAlertWarning || r.AlertDescription != TLSAlertCloseNotify {
+		t.Errorf("unexpected Alert record %#v", r)
+	}
+
+	// the second ClientHello has the cookie
+	d = &DTLS{}
+	err = d.DecodeFromBytes(dtlsTestRecord(TLSHandshake, 0, 1, dtlsTestFragment(TLSHandshakeClientHello, 1, 0, testDTLSClientHello, testDTLSClientHello)), gopacket.NilDecodeFeedback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := d.Records[0].Messages[0]
+	if m.Version != 0xfefd || !bytes.Equal(m.Cookie, testDTLSCookie) || m.ServerName != "example.com" || len(m.CipherSuites) != 1 {
+		t.Errorf("unexpected ClientHello %#v", m)
+	}
+
+	// records of later epochs are encrypted
+	d = &DTLS{}
+	err = d.DecodeFromBytes(append(dtlsTestRecord(TLSChangeCipherSpec, 0, 2, []byte{1}),
+		dtlsTestRecord(TLSHandshake, 1, 0x10000000000, make([]byte, 40))...), gopacket.NilDecodeFeedback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := d.Records[1]; !r.Encrypted || r.Epoch != 1 || r.SequenceNumber != 0x10000000000 || len(r.Fragments) != 0 || len(r.Data) != 40 {
+		t.Errorf("unexpected encrypted record %#v", r)
+	}
+
+	for _, data := range [][]byte{
+		dtlsTestRecord(TLSHandshake, 0, 0, make([]byte, 11)),
+		dtlsTestRecord(TLSHandshake, 0, 0, dtlsTestFragment(TLSHandshakeClientHello, 0, 2, hvr, hvr)),
+		dtlsTestRecord(TLSAlert, 0, 0, []byte{1}),
+		dtlsTestRecord(0x19, 0, 0, []byte{1}),
+		dtlsTestRecord(TLSHandshake, 0, 0)[:12],
+	} {
+		if err := (&DTLS{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
+
+func TestDTLSUnified(t *testing.T) {
+	// a record with a 16 bit sequence number and a length, followed by one
+	// taking the rest of the datagram
+	data := append([]byte{0x2e, 0x12, 0x34, 0x00, 0x03, 1, 2, 3}, 0x22, 0x56, 4, 5, 6, 7)
+	d := &DTLS{}
+	if err := d.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Records) != 2 {
+		t.Fatalf("got %d records", len(d.Records))
+	}
+	if r := d.Records[0]; !r.Unified || !r.Encrypted || r.Epoch != 2 || r.SequenceNumber != 0x1234 || !bytes.Equal(r.Data, []byte{1, 2, 3}) {
+		t.Errorf("unexpected record %#v", r)
+	}
+	if r := d.Records[1]; r.Epoch != 2 || r.SequenceNumber != 0x56 || r.Length != 4 || !bytes.Equal(r.Data, []byte{4, 5, 6, 7}) {
+		t.Errorf("unexpected record %#v", r)
+	}
+	if err := (&DTLS{}).DecodeFromBytes([]byte{0x3e, 0, 0, 0, 0}, gopacket.NilDecodeFeedback); err == nil {
+		t.Error("no error for connection ID")
+	}
+}
+
+func TestDTLSStream(t *testing.T) {
+	certificate := bytes.Repeat([]byte{0xaa}, 300)
+	datagrams := [][]byte{
+		dtlsTestRecord(TLSHandshake, 0, 0, dtlsTestFragment(TLSHandshakeClientHello, 0, 0, testDTLSClientHello, testDTLSClientHello)),
+		// the Certificate in three fragments, the last one received first
+		dtlsTestRecord(TLSHandshake, 0, 3, dtlsTestFragment(TLSHandshakeCertificate, 1, 200, certificate, certificate[200:])),
+		dtlsTestRecord(TLSHandshake, 0, 1, dtlsTestFragment(TLSHandshakeCertificate, 1, 0, certificate, certificate[:120])),
+		// a retransmission of the ClientHello, and the end of the Certificate
+		append(dtlsTestRecord(TLSHandshake, 0, 4, dtlsTestFragment(TLSHandshakeClientHello, 0, 0, testDTLSClientHello, testDTLSClientHello)),
+			dtlsTestRecord(TLSHandshake, 0, 2, dtlsTestFragment(TLSHandshakeCertificate, 1, 100, certificate, certificate[100:200]),
+				dtlsTestFragment(TLSHandshakeServerHelloDone, 2, 0, nil, nil))...),
+	}
+	want := [][]TLSHandshakeType{
+		{TLSHandshakeClientHello},
+		nil,
+		nil,
+		{TLSHandshakeCertificate, TLSHandshakeServerHelloDone},
+	}
+	s := &DTLSStream{}
+	for i, datagram := range datagrams {
+		d := &DTLS{}
+		if err := d.DecodeFromBytes(datagram, gopacket.NilDecodeFeedback); err != nil {
+			t.Fatal(err)
+		}
+		messages, err := s.Decode(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) != len(want[i]) {
+			t.Fatalf("datagram %d: got %d messages, want %d", i, len(messages), len(want[i]))
+		}
+		for j, m := range messages {
+			if m.Type != want[i][j] {
+				t.Errorf("datagram %d: got %v, want %v", i, m.Type, want[i][j])
+			}
+			if m.Type == TLSHandshakeCertificate && !bytes.Equal(m.Body, certificate) {
+				t.Error("Certificate reassembled wrong")
+			}
+		}
+	}
+
+	s = &DTLSStream{}
+	d := &DTLS{}
+	d.DecodeFromBytes(append(dtlsTestRecord(TLSHandshake, 0, 0, dtlsTestFragment(TLSHandshakeCertificate, 0, 0, certificate, certificate[:10])),
+		dtlsTestRecord(TLSHandshake, 0, 1, dtlsTestFragment(TLSHandshakeFinished, 0, 10, certificate, certificate[10:20]))...), gopacket.NilDecodeFeedback)
+	if _, err := s.Decode(d); err == nil {
+		t.Error("no error for inconsistent fragments")
+	}
+}
+
+func TestDetectDTLS(t *testing.T) {
+	datagram := dtlsTestRecord(TLSHandshake, 0, 0, dtlsTestFragment(TLSHandshakeClientHello, 0, 0, testDTLSClientHello, testDTLSClientHello))
+	if DetectDTLS(&gopacket.UnknownProtocol{Data: datagram}) != LayerTypeDTLS {
+		t.Error("DTLS not detected")
+	}
+	for _, data := range [][]byte{
+		tlsTestRecord(testTLS13ClientHello),
+		datagram[:len(datagram)-1],
+		{0x2e, 0x12, 0x34, 0x00, 0x03, 1, 2, 3},
+	} {
+		if DetectDTLS(&gopacket.UnknownProtocol{Data: data}) != nil {
+			t.Errorf("%x detected as DTLS", data)
+		}
+	}
+}
@@ -16,6 +16,12 @@ import (
 
 // EnumMetadata keeps track of a set of metadata for each enumeration value
 // for protocol enumerations.
+//
+// The XXXMetadata arrays holding it, like EthernetTypeMetadata, aren't
+// synchronized: they may only be modified during initialization, before
+// packets are decoded.  To change decoding while packets are decoded, use
+// gopacket.DecoderOverrides or RegisterTCPPortLayerType and
+// RegisterUDPPortLayerType instead.
 type EnumMetadata struct {
 	// DecodeWith is the decoder to use to decode this protocol's data.
 	DecodeWith gopacket.Decoder
@@ -46,10 +52,15 @@ const (
 	EthernetTypePPPoESession                EthernetType = 0x8864
 	EthernetTypeMPLSUnicast                 EthernetType = 0x8847
 	EthernetTypeMPLSMulticast               EthernetType = 0x8848
+	EthernetTypeSlowProtocols               EthernetType = 0x8809
 	EthernetTypeEAPOL                       EthernetType = 0x888e
+	EthernetTypeMACsec                      EthernetType = 0x88e5
+	EthernetTypeNSH                         EthernetType = 0x894f
 	EthernetTypeERSPAN                      EthernetType = 0x88be
+	EthernetTypeERSPANIII                   EthernetType = 0x22eb
 	EthernetTypeQinQ                        EthernetType = 0x88a8
 	EthernetTypeLinkLayerDiscovery          EthernetType = 0x88cc
+	EthernetTypePTP                         EthernetType = 0x88f7
 	EthernetTypeEthernetCTP                 EthernetType = 0x9000
 )
 
@@ -68,6 +79,7 @@ const (
 	IPProtocolIPv6            IPProtocol = 41
 	IPProtocolIPv6Routing     IPProtocol = 43
 	IPProtocolIPv6Fragment    IPProtocol = 44
+	IPProtocolRSVP            IPProtocol = 46
 	IPProtocolGRE             IPProtocol = 47
 	IPProtocolESP             IPProtocol = 50
 	IPProtocolAH              IPProtocol = 51
@@ -77,10 +89,13 @@ const (
 	IPProtocolOSPF            IPProtocol = 89
 	IPProtocolIPIP            IPProtocol = 94
 	IPProtocolEtherIP         IPProtocol = 97
+	IPProtocolPIM             IPProtocol = 103
 	IPProtocolVRRP            IPProtocol = 112
+	IPProtocolL2TP            IPProtocol = 115
 	IPProtocolSCTP            IPProtocol = 132
 	IPProtocolUDPLite         IPProtocol = 136
 	IPProtocolMPLSInIP        IPProtocol = 137
+	IPProtocolEthernet        IPProtocol = 143
 )
 
 // LinkType is an enumeration of link types, and acts as a decoder for any
@@ -149,6 +164,13 @@ const (
 	PPPTypeIPv6          PPPType = 0x0057
 	PPPTypeMPLSUnicast   PPPType = 0x0281
 	PPPTypeMPLSMulticast PPPType = 0x0283
+	PPPTypeCompressed    PPPType = 0x00fd
+	PPPTypeIPCP          PPPType = 0x8021
+	PPPTypeIPv6CP        PPPType = 0x8057
+	PPPTypeCCP           PPPType = 0x80fd
+	PPPTypeLCP           PPPType = 0xc021
+	PPPTypePAP           PPPType = 0xc023
+	PPPTypeCHAP          PPPType = 0xc223
 )
 
 // SCTPChunkType is an enumeration of chunk types inside SCTP packets.
@@ -167,7 +189,14 @@ const (
 	SCTPChunkTypeError            SCTPChunkType = 9
 	SCTPChunkTypeCookieEcho       SCTPChunkType = 10
 	SCTPChunkTypeCookieAck        SCTPChunkType = 11
+	SCTPChunkTypeECNE             SCTPChunkType = 12
+	SCTPChunkTypeCWR              SCTPChunkType = 13
 	SCTPChunkTypeShutdownComplete SCTPChunkType = 14
+	SCTPChunkTypeAuth             SCTPChunkType = 15
+	SCTPChunkTypeASCONFAck        SCTPChunkType = 0x80
+	SCTPChunkTypeReConfig         SCTPChunkType = 0x82
+	SCTPChunkTypeForwardTSN       SCTPChunkType = 0xc0
+	SCTPChunkTypeASCONF           SCTPChunkType = 0xc1
 )
 
 // FDDIFrameControl is an enumeration of FDDI frame control bytes.
@@ -315,10 +344,15 @@ func initActualTypeData() {
 	EthernetTypeMetadata[EthernetTypeLinkLayerDiscovery] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeLinkLayerDiscovery), Name: "LinkLayerDiscovery", LayerType: LayerTypeLinkLayerDiscovery}
 	EthernetTypeMetadata[EthernetTypeMPLSUnicast] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeMPLS), Name: "MPLSUnicast", LayerType: LayerTypeMPLS}
 	EthernetTypeMetadata[EthernetTypeMPLSMulticast] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeMPLS), Name: "MPLSMulticast", LayerType: LayerTypeMPLS}
+	EthernetTypeMetadata[EthernetTypeSlowProtocols] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSlowProtocols), Name: "SlowProtocols", LayerType: LayerTypeLACP}
+	EthernetTypeMetadata[EthernetTypePTP] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodePTP), Name: "PTP", LayerType: LayerTypePTP}
 	EthernetTypeMetadata[EthernetTypeEAPOL] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeEAPOL), Name: "EAPOL", LayerType: LayerTypeEAPOL}
+	EthernetTypeMetadata[EthernetTypeNSH] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeNSH), Name: "NSH", LayerType: LayerTypeNSH}
+	EthernetTypeMetadata[EthernetTypeMACsec] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeMACsec), Name: "MACsec", LayerType: LayerTypeMACsec}
 	EthernetTypeMetadata[EthernetTypeQinQ] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeDot1Q), Name: "Dot1Q", LayerType: LayerTypeDot1Q}
 	EthernetTypeMetadata[EthernetTypeTransparentEthernetBridging] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeEthernet), Name: "TransparentEthernetBridging", LayerType: LayerTypeEthernet}
 	EthernetTypeMetadata[EthernetTypeERSPAN] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeERSPANII), Name: "ERSPAN Type II", LayerType: LayerTypeERSPANII}
+	EthernetTypeMetadata[EthernetTypeERSPANIII] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeERSPANIII), Name: "ERSPAN Type III", LayerType: LayerTypeERSPANIII}
 
 	IPProtocolMetadata[IPProtocolIPv4] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeIPv4), Name: "IPv4", LayerType: LayerTypeIPv4}
 	IPProtocolMetadata[IPProtocolTCP] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeTCP), Name: "TCP", LayerType: LayerTypeTCP}
@@ -340,9 +374,13 @@ func initActualTypeData() {
 	IPProtocolMetadata[IPProtocolESP] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeIPSecESP), Name: "IPSecESP", LayerType: LayerTypeIPSecESP}
 	IPProtocolMetadata[IPProtocolUDPLite] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeUDPLite), Name: "UDPLite", LayerType: LayerTypeUDPLite}
 	IPProtocolMetadata[IPProtocolMPLSInIP] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeMPLS), Name: "MPLS", LayerType: LayerTypeMPLS}
+	IPProtocolMetadata[IPProtocolEthernet] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeEthernet), Name: "Ethernet", LayerType: LayerTypeEthernet}
 	IPProtocolMetadata[IPProtocolNoNextHeader] = EnumMetadata{DecodeWith: gopacket.DecodePayload, Name: "NoNextHeader", LayerType: gopacket.LayerTypePayload}
 	IPProtocolMetadata[IPProtocolIGMP] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeIGMP), Name: "IGMP", LayerType: LayerTypeIGMP}
 	IPProtocolMetadata[IPProtocolVRRP] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeVRRP), Name: "VRRP", LayerType: LayerTypeVRRP}
+	IPProtocolMetadata[IPProtocolRSVP] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeRSVP), Name: "RSVP", LayerType: LayerTypeRSVP}
+	IPProtocolMetadata[IPProtocolPIM] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodePIM), Name: "PIM", LayerType: LayerTypePIM}
+	IPProtocolMetadata[IPProtocolL2TP] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeL2TPIP), Name: "L2TP", LayerType: LayerTypeL2TPIP}
 
 	SCTPChunkTypeMetadata[SCTPChunkTypeData] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPData), Name: "Data"}
 	SCTPChunkTypeMetadata[SCTPChunkTypeInit] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPInit), Name: "Init"}
@@ -357,11 +395,27 @@ func initActualTypeData() {
 	SCTPChunkTypeMetadata[SCTPChunkTypeCookieEcho] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPCookieEcho), Name: "CookieEcho"}
 	SCTPChunkTypeMetadata[SCTPChunkTypeCookieAck] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPEmptyLayer), Name: "CookieAck"}
 	SCTPChunkTypeMetadata[SCTPChunkTypeShutdownComplete] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPEmptyLayer), Name: "ShutdownComplete"}
+	SCTPChunkTypeMetadata[SCTPChunkTypeECNE] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPECN), Name: "ECNE"}
+	SCTPChunkTypeMetadata[SCTPChunkTypeCWR] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPECN), Name: "CWR"}
+	SCTPChunkTypeMetadata[SCTPChunkTypeAuth] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPAuth), Name: "Auth"}
+	SCTPChunkTypeMetadata[SCTPChunkTypeASCONFAck] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPASCONF), Name: "ASCONFAck"}
+	SCTPChunkTypeMetadata[SCTPChunkTypeReConfig] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPReConfig), Name: "ReConfig"}
+	SCTPChunkTypeMetadata[SCTPChunkTypeForwardTSN] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPForwardTSN), Name: "ForwardTSN"}
+	SCTPChunkTypeMetadata[SCTPChunkTypeASCONF] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeSCTPASCONF), Name: "ASCONF"}
 
 	PPPTypeMetadata[PPPTypeIPv4] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeIPv4), Name: "IPv4"}
 	PPPTypeMetadata[PPPTypeIPv6] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeIPv6), Name: "IPv6"}
 	PPPTypeMetadata[PPPTypeMPLSUnicast] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeMPLS), Name: "MPLSUnicast"}
 	PPPTypeMetadata[PPPTypeMPLSMulticast] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodeMPLS), Name: "MPLSMulticast"}
+	// the PPP control protocols, and the data compressed or encrypted with
+	// MPPE, of PPTP and L2TP tunnels
+	PPPTypeMetadata[PPPTypeCompressed] = EnumMetadata{DecodeWith: gopacket.DecodePayload, Name: "Compressed"}
+	PPPTypeMetadata[PPPTypeIPCP] = EnumMetadata{DecodeWith: gopacket.DecodePayload, Name: "IPCP"}
+	PPPTypeMetadata[PPPTypeIPv6CP] = EnumMetadata{DecodeWith: gopacket.DecodePayload, Name: "IPv6CP"}
+	PPPTypeMetadata[PPPTypeCCP] = EnumMetadata{DecodeWith: gopacket.DecodePayload, Name: "CCP"}
+	PPPTypeMetadata[PPPTypeLCP] = EnumMetadata{DecodeWith: gopacket.DecodePayload, Name: "LCP"}
+	PPPTypeMetadata[PPPTypePAP] = EnumMetadata{DecodeWith: gopacket.DecodePayload, Name: "PAP"}
+	PPPTypeMetadata[PPPTypeCHAP] = EnumMetadata{DecodeWith: gopacket.DecodePayload, Name: "CHAP"}
 
 	PPPoECodeMetadata[PPPoECodeSession] = EnumMetadata{DecodeWith: gopacket.DecodeFunc(decodePPP), Name: "PPP"}
 
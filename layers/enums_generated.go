@@ -81,6 +81,9 @@ func (a EthernetType) LayerType() gopacket.LayerType {
 type errorDecoderForEthernetType int
 
 func (a *errorDecoderForEthernetType) Decode(data []byte, p gopacket.PacketBuilder) error {
+	if ok, err := gopacket.DecodeUnknownProtocol(gopacket.UnknownEthernetType, int(*a), data, p); ok {
+		return err
+	}
 	return a
 }
 func (a *errorDecoderForEthernetType) Error() string {
@@ -155,6 +158,9 @@ func (a IPProtocol) LayerType() gopacket.LayerType {
 type errorDecoderForIPProtocol int
 
 func (a *errorDecoderForIPProtocol) Decode(data []byte, p gopacket.PacketBuilder) error {
+	if ok, err := gopacket.DecodeUnknownProtocol(gopacket.UnknownIPProtocol, int(*a), data, p); ok {
+		return err
+	}
 	return a
 }
 func (a *errorDecoderForIPProtocol) Error() string {
@@ -0,0 +1,171 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/gopacket"
+)
+
+// ERSPANIIIVersion - The value for the version field of Type III headers
+const ERSPANIIIVersion = 0x2
+
+// ERSPAN Type III frame types
+const (
+	ERSPANIIIFrameTypeEthernet = 0x0
+	ERSPANIIIFrameTypeIP       = 0x2
+)
+
+// ERSPAN Type III timestamp granularities
+const (
+	ERSPANIIIGranularity100Microseconds = 0x0
+	ERSPANIIIGranularity100Nanoseconds  = 0x1
+	ERSPANIIIGranularityIEEE1588        = 0x2
+	ERSPANIIIGranularityUser            = 0x3
+)
+
+// ERSPANIII contains all of the fields found in an ERSPAN Type III header
+// https://tools.ietf.org/html/draft-foschiano-erspan-03
+//
+//	0                   1                   2                   3
+//	0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |  Ver  |          VLAN         | COS |BSO|T|     Session ID    |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                          Timestamp                            |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |             SGT               |P|    FT   |   Hw ID   |D|Gra|O|
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |        Platform Specific SubHeader (8 octets, optional)       |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+type ERSPANIII struct {
+	BaseLayer
+	IsTruncated               bool
+	Version, CoS              uint8
+	BSO                       uint8 // bad, short or oversized frame
+	VLANIdentifier, SessionID uint16
+	Timestamp                 uint32
+	SGT                       uint16 // security group tag
+	IsPDU                     bool   // 'P' bit, set for Ethernet PDU frames
+	FrameType, HardwareID     uint8
+	IsEgress                  bool // 'D' bit
+	Granularity               uint8
+	HasPlatformSpecific       bool // 'O' bit
+	PlatformID                uint8
+	PlatformSpecificInfo      uint64 // 58 bits
+}
+
+// LayerType returns LayerTypeERSPANIII.
+func (erspan3 *ERSPANIII) LayerType() gopacket.LayerType { return LayerTypeERSPANIII }
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (erspan3 *ERSPANIII) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 12 {
+		df.SetTruncated()
+		return errors.New("ERSPAN Type III header too short")
+	}
+	erspan3Length := 12
+	erspan3.Version = data[0] >> 4
+	if erspan3.Version != ERSPANIIIVersion {
+		return fmt.Errorf("invalid ERSPAN Type III version %d", erspan3.Version)
+	}
+	erspan3.VLANIdentifier = binary.BigEndian.Uint16(data[:2]) & 0x0FFF
+	erspan3.CoS = data[2] >> 5
+	erspan3.BSO = data[2] & 0x18 >> 3
+	erspan3.IsTruncated = data[2]&0x4 != 0
+	erspan3.SessionID = binary.BigEndian.Uint16(data[2:4]) & 0x03FF
+	erspan3.Timestamp = binary.BigEndian.Uint32(data[4:8])
+	erspan3.SGT = binary.BigEndian.Uint16(data[8:10])
+	erspan3.IsPDU = data[10]&0x80 != 0
+	erspan3.FrameType = data[10] & 0x7c >> 2
+	erspan3.HardwareID = uint8(binary.BigEndian.Uint16(data[10:12]) & 0x03f0 >> 4)
+	erspan3.IsEgress = data[11]&0x8 != 0
+	erspan3.Granularity = data[11] & 0x6 >> 1
+	erspan3.HasPlatformSpecific = data[11]&0x1 != 0
+	erspan3.PlatformID, erspan3.PlatformSpecificInfo = 0, 0
+	if erspan3.HasPlatformSpecific {
+		if len(data) < 20 {
+			df.SetTruncated()
+			return errors.New("ERSPAN Type III platform specific subheader too short")
+		}
+		sub := binary.BigEndian.Uint64(data[12:20])
+		erspan3.PlatformID = uint8(sub >> 58)
+		erspan3.PlatformSpecificInfo = sub & (1<<58 - 1)
+		erspan3Length = 20
+	}
+	erspan3.Contents = data[:erspan3Length]
+	erspan3.Payload = data[erspan3Length:]
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+// See the docs for gopacket.SerializableLayer for more info.
+func (erspan3 *ERSPANIII) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	length := 12
+	if erspan3.HasPlatformSpecific {
+		length = 20
+	}
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+
+	twoByteInt := uint16(erspan3.Version&0xF)<<12 | erspan3.VLANIdentifier&0x0FFF
+	binary.BigEndian.PutUint16(bytes, twoByteInt)
+
+	twoByteInt = uint16(erspan3.CoS&0x7)<<13 | uint16(erspan3.BSO&0x3)<<11 | erspan3.SessionID&0x03FF
+	if erspan3.IsTruncated {
+		twoByteInt |= 0x400
+	}
+	binary.BigEndian.PutUint16(bytes[2:], twoByteInt)
+	binary.BigEndian.PutUint32(bytes[4:], erspan3.Timestamp)
+	binary.BigEndian.PutUint16(bytes[8:], erspan3.SGT)
+
+	twoByteInt = uint16(erspan3.FrameType&0x1F)<<10 | uint16(erspan3.HardwareID&0x3F)<<4 | uint16(erspan3.Granularity&0x3)<<1
+	if erspan3.IsPDU {
+		twoByteInt |= 0x8000
+	}
+	if erspan3.IsEgress {
+		twoByteInt |= 0x8
+	}
+	if erspan3.HasPlatformSpecific {
+		twoByteInt |= 0x1
+		binary.BigEndian.PutUint64(bytes[12:], uint64(erspan3.PlatformID&0x3F)<<58|erspan3.PlatformSpecificInfo&(1<<58-1))
+	}
+	binary.BigEndian.PutUint16(bytes[10:], twoByteInt)
+	return nil
+}
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (erspan3 *ERSPANIII) CanDecode() gopacket.LayerClass {
+	return LayerTypeERSPANIII
+}
+
+// NextLayerType returns the layer type contained by this DecodingLayer: an
+// Ethernet frame, or an IP packet.
+func (erspan3 *ERSPANIII) NextLayerType() gopacket.LayerType {
+	switch erspan3.FrameType {
+	case ERSPANIIIFrameTypeEthernet:
+		return LayerTypeEthernet
+	case ERSPANIIIFrameTypeIP:
+		if len(erspan3.Payload) > 0 && erspan3.Payload[0]>>4 == 6 {
+			return LayerTypeIPv6
+		}
+		return LayerTypeIPv4
+	}
+	return gopacket.LayerTypePayload
+}
+
+func decodeERSPANIII(data []byte, p gopacket.PacketBuilder) error {
+	erspan3 := &ERSPANIII{}
+	return decodingLayerDecoder(erspan3, data, p)
+}
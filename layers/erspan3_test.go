@@ -0,0 +1,68 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestERSPANIII(t *testing.T) {
+	erspan := &ERSPANIII{
+		Version:              ERSPANIIIVersion,
+		VLANIdentifier:       0x2aa,
+		CoS:                  0x5,
+		BSO:                  0x1,
+		IsTruncated:          true,
+		SessionID:            0x155,
+		Timestamp:            0x01020304,
+		SGT:                  0xbeef,
+		IsPDU:                true,
+		HardwareID:           0x2b,
+		IsEgress:             true,
+		Granularity:          ERSPANIIIGranularity100Nanoseconds,
+		HasPlatformSpecific:  true,
+		PlatformID:           0x3,
+		PlatformSpecificInfo: 0x0123456789abcde,
+	}
+	expectedBytes := []byte{0x22, 0xaa, 0xad, 0x55, 1, 2, 3, 4, 0xbe, 0xef, 0x82, 0xbb, 0x0c, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde}
+	frame, err := Build().Ethernet(nil).IPv4(nil).UDP(&UDP{SrcPort: 53, DstPort: 53}).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{}, erspan, gopacket.Payload(frame)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(buf.Bytes()[:20], expectedBytes) {
+		t.Fatalf("Got %x, expected %x\n", buf.Bytes()[:20], expectedBytes)
+	}
+
+	gre := []byte{0x10, 0x00, 0x22, 0xeb, 0, 0, 0, 1}
+	p := gopacket.NewPacket(append(gre, buf.Bytes()...), LayerTypeGRE, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeGRE, LayerTypeERSPANIII, LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP}, t)
+	got := p.Layer(LayerTypeERSPANIII).(*ERSPANIII)
+	got.BaseLayer = BaseLayer{}
+	if !reflect.DeepEqual(got, erspan) {
+		t.Errorf("Got %+v, expected %+v\n", got, erspan)
+	}
+
+	for _, data := range [][]byte{
+		expectedBytes[:11],
+		expectedBytes[:16],
+		append([]byte{0x12}, expectedBytes[1:]...),
+	} {
+		if err := (&ERSPANIII{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
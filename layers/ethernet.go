@@ -119,5 +119,8 @@ func decodeEthernet(data []byte, p gopacket.PacketBuilder) error {
 	}
 	p.AddLayer(eth)
 	p.SetLinkLayer(eth)
+	if eth.EthernetType == EthernetTypeMACsec {
+		return p.NextDecoder(macsecDecoder{eth})
+	}
 	return p.NextDecoder(eth.EthernetType)
 }
@@ -0,0 +1,81 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestFixLayers(t *testing.T) {
+	p := gopacket.NewPacket(testSimpleTCPPacket, LinkTypeEthernet, gopacket.Default)
+	ip := p.Layer(LayerTypeIPv4).(*IPv4)
+	tcp := p.Layer(LayerTypeTCP).(*TCP)
+	payload := p.Layer(gopacket.LayerTypePayload).(*gopacket.Payload)
+	ip.DstIP = net.IP{10, 1, 2, 3}
+	tcp.DstPort = 8080
+	*payload = (*payload)[:10]
+
+	data, err := gopacket.FixLayers(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default)
+	if fixed.ErrorLayer() != nil {
+		t.Fatal(fixed.ErrorLayer().Error())
+	}
+	fixedIP := fixed.Layer(LayerTypeIPv4).(*IPv4)
+	fixedTCP := fixed.Layer(LayerTypeTCP).(*TCP)
+	if !fixedIP.DstIP.Equal(ip.DstIP) || fixedTCP.DstPort != 8080 {
+		t.Errorf("modified fields not serialized: %v %v", fixedIP.DstIP, fixedTCP.DstPort)
+	}
+	if want := uint16(len(fixedIP.Contents) + len(fixedTCP.Contents) + 10); fixedIP.Length != want {
+		t.Errorf("IPv4 length %d, want %d", fixedIP.Length, want)
+	}
+	if csum := checksum(append([]byte(nil), fixedIP.Contents...)); csum != fixedIP.Checksum {
+		t.Errorf("IPv4 checksum %#x, want %#x", fixedIP.Checksum, csum)
+	}
+	fixedTCP.SetNetworkLayerForChecksum(fixedIP)
+	// the checksum computed over a header with a valid checksum is zero
+	if csum, err := fixedTCP.ComputeChecksum(); err != nil || csum != 0 {
+		t.Errorf("TCP checksum %#x invalid, sums to %#x (%v)", fixedTCP.Checksum, csum, err)
+	}
+
+	// fixing a consistent packet doesn't change it
+	again, err := gopacket.FixLayers(fixed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("fixing a fixed packet changed it:\n%x\n%x", data, again)
+	}
+}
+
+func TestSCTPSerializeChecksum(t *testing.T) {
+	buf := gopacket.NewSerializeBuffer()
+	// leave garbage in the buffer, where the checksum field will be written
+	garbage, _ := buf.PrependBytes(64)
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+	buf.Clear()
+	sctp := &SCTP{SrcPort: 1234, DstPort: 5678, VerificationTag: 42}
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{ComputeChecksums: true}, sctp, gopacket.Payload{1, 2, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	data := append([]byte(nil), buf.Bytes()...)
+	got := binary.LittleEndian.Uint32(data[8:12])
+	binary.LittleEndian.PutUint32(data[8:12], 0)
+	if want := crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)); got != want {
+		t.Errorf("SCTP checksum %#x, want %#x", got, want)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+
+	"github.com/google/gopacket"
+)
+
+// maxFlowKeyTunnelDepth limits the number of tunnels ExtractFlowKey descends
+// into.
+const maxFlowKeyTunnelDepth = 4
+
+// FlowKey is the five-tuple of a packet, extracted by ExtractFlowKey.  It's
+// comparable and doesn't reference the packet data, so it can be used as a
+// map key.
+type FlowKey struct {
+	// SrcIP and DstIP hold the addresses, IPv4 addresses in their first 4
+	// bytes.
+	SrcIP, DstIP [16]byte
+	// IPVersion is 4 or 6.
+	IPVersion uint8
+	Protocol  IPProtocol
+	// SrcPort and DstPort are only set for TCP, UDP and SCTP, and are zero
+	// for non-first fragments.
+	SrcPort, DstPort uint16
+	// VLAN is the VLAN identifier of the last 802.1Q tag before the IP
+	// header, or 0.
+	VLAN uint16
+	// Tunneled is true if the key was taken from a packet inside an IP-in-IP,
+	// GRE or VXLAN tunnel.
+	Tunneled bool
+}
+
+// NetworkFlow returns the flow of the IP addresses of k.
+func (k FlowKey) NetworkFlow() gopacket.Flow {
+	if k.IPVersion == 6 {
+		return gopacket.NewFlow(EndpointIPv6, k.SrcIP[:16], k.DstIP[:16])
+	}
+	return gopacket.NewFlow(EndpointIPv4, k.SrcIP[:4], k.DstIP[:4])
+}
+
+// TransportFlow returns the flow of the ports of k, or gopacket.InvalidFlow
+// if k has no ports.
+func (k FlowKey) TransportFlow() gopacket.Flow {
+	var typ gopacket.EndpointType
+	switch k.Protocol {
+	case IPProtocolTCP:
+		typ = EndpointTCPPort
+	case IPProtocolUDP:
+		typ = EndpointUDPPort
+	case IPProtocolSCTP:
+		typ = EndpointSCTPPort
+	default:
+		return gopacket.InvalidFlow
+	}
+	var src, dst [2]byte
+	binary.BigEndian.PutUint16(src[:], k.SrcPort)
+	binary.BigEndian.PutUint16(dst[:], k.DstPort)
+	return gopacket.NewFlow(typ, src[:], dst[:])
+}
+
+// FastHash returns a hash of k for load-balancing, which is the same for
+// both directions of a flow.  Like gopacket.Flow.FastHash, its output may
+// change in future code revisions.
+func (k FlowKey) FastHash() uint64 {
+	h := k.NetworkFlow().FastHash()
+	// ports are added to keep the hash symmetric
+	h += uint64(k.SrcPort) + uint64(k.DstPort)
+	h ^= uint64(k.Protocol)
+	return h * 1099511628211
+}
+
+// ExtractFlowKey extracts the five-tuple of the packet data with the given
+// link type, parsing only the headers needed for it, which is much faster
+// than decoding the packet.  It's meant for load-balancing and flow table
+// lookups deciding whether a packet needs to be decoded.  VLAN tags and MPLS
+// labels are skipped, and IP-in-IP, GRE and VXLAN (on UDP port 4789) tunnels
+// are descended into, so the key is the one of the innermost IP packet.
+// Supported link types are Ethernet, Linux SLL, Null, Loop and raw IP.  ok
+// is false if no IP header could be found.
+//
+// ExtractFlowKey doesn't allocate.
+func ExtractFlowKey(data []byte, linkType LinkType) (key FlowKey, ok bool) {
+	switch linkType {
+	case LinkTypeEthernet:
+		ok = flowKeyEthernet(data, &key, 0)
+	case LinkTypeLinuxSLL:
+		if len(data) >= 16 {
+			ok = flowKeyEthernetType(EthernetType(binary.BigEndian.Uint16(data[14:16])), data[16:], &key, 0)
+		}
+	case LinkTypeNull, LinkTypeLoop:
+		// the address family is in host or network byte order; use the IP
+		// version instead
+		if len(data) >= 4 {
+			ok = flowKeyIP(data[4:], &key, 0)
+		}
+	case LinkTypeRaw, LinkTypeIPv4, LinkTypeIPv6:
+		ok = flowKeyIP(data, &key, 0)
+	}
+	return
+}
+
+func flowKeyEthernet(data []byte, key *FlowKey, depth int) bool {
+	if len(data) < 14 {
+		return false
+	}
+	return flowKeyEthernetType(EthernetType(binary.BigEndian.Uint16(data[12:14])), data[14:], key, depth)
+}
+
+func flowKeyEthernetType(typ EthernetType, data []byte, key *FlowKey, depth int) bool {
+	for {
+		switch typ {
+		case EthernetTypeDot1Q, EthernetTypeQinQ:
+			if len(data) < 4 {
+				return false
+			}
+			key.VLAN = binary.BigEndian.Uint16(data[:2]) & 0xfff
+			typ = EthernetType(binary.BigEndian.Uint16(data[2:4]))
+			data = data[4:]
+		case EthernetTypeMPLSUnicast, EthernetTypeMPLSMulticast:
+			for {
+				if len(data) < 4 {
+					return false
+				}
+				bottom := data[2]&1 != 0
+				data = data[4:]
+				if bottom {
+					break
+				}
+			}
+			// MPLS doesn't tell the payload type; guess IP
+			return flowKeyIP(data, key, depth)
+		case EthernetTypeIPv4, EthernetTypeIPv6:
+			return flowKeyIP(data, key, depth)
+		default:
+			return false
+		}
+	}
+}
+
+func flowKeyIP(data []byte, key *FlowKey, depth int) bool {
+	if len(data) < 1 {
+		return false
+	}
+	var proto IPProtocol
+	var fragment bool
+	switch data[0] >> 4 {
+	case 4:
+		if len(data) < 20 {
+			return false
+		}
+		ihl := int(data[0]&0xf) * 4
+		if ihl < 20 || len(data) < ihl {
+			return false
+		}
+		key.IPVersion = 4
+		key.SrcIP, key.DstIP = [16]byte{}, [16]byte{}
+		copy(key.SrcIP[:], data[12:16])
+		copy(key.DstIP[:], data[16:20])
+		proto = IPProtocol(data[9])
+		fragment = binary.BigEndian.Uint16(data[6:8])&0x1fff != 0
+		data = data[ihl:]
+	case 6:
+		if len(data) < 40 {
+			return false
+		}
+		key.IPVersion = 6
+		copy(key.SrcIP[:], data[8:24])
+		copy(key.DstIP[:], data[24:40])
+		proto = IPProtocol(data[6])
+		data = data[40:]
+	extensions:
+		for {
+			switch proto {
+			case IPProtocolIPv6HopByHop, IPProtocolIPv6Routing, IPProtocolIPv6Destination:
+				if len(data) < 2 || len(data) < (int(data[1])+1)*8 {
+					break extensions
+				}
+				proto, data = IPProtocol(data[0]), data[(int(data[1])+1)*8:]
+			case IPProtocolAH:
+				if len(data) < 2 || len(data) < (int(data[1])+2)*4 {
+					break extensions
+				}
+				proto, data = IPProtocol(data[0]), data[(int(data[1])+2)*4:]
+			case IPProtocolIPv6Fragment:
+				if len(data) < 8 {
+					break extensions
+				}
+				fragment = binary.BigEndian.Uint16(data[2:4])&0xfff8 != 0
+				proto, data = IPProtocol(data[0]), data[8:]
+			default:
+				break extensions
+			}
+		}
+	default:
+		return false
+	}
+	key.Protocol = proto
+	key.SrcPort, key.DstPort = 0, 0
+	if fragment {
+		return true
+	}
+
+	switch proto {
+	case IPProtocolTCP, IPProtocolUDP, IPProtocolSCTP:
+		if len(data) < 4 {
+			return true
+		}
+		key.SrcPort = binary.BigEndian.Uint16(data[0:2])
+		key.DstPort = binary.BigEndian.Uint16(data[2:4])
+		if proto == IPProtocolUDP && key.DstPort == 4789 && len(data) >= 16 && data[8]&0x08 != 0 && depth < maxFlowKeyTunnelDepth {
+			flowKeyTunnel(key, func(inner *FlowKey) bool {
+				return flowKeyEthernet(data[16:], inner, depth+1)
+			})
+		}
+	case IPProtocolIPv4, IPProtocolIPv6:
+		if depth < maxFlowKeyTunnelDepth {
+			flowKeyTunnel(key, func(inner *FlowKey) bool {
+				return flowKeyIP(data, inner, depth+1)
+			})
+		}
+	case IPProtocolGRE:
+		if len(data) < 4 || depth >= maxFlowKeyTunnelDepth {
+			return true
+		}
+		flags := binary.BigEndian.Uint16(data[0:2])
+		typ := EthernetType(binary.BigEndian.Uint16(data[2:4]))
+		if flags&0x4007 != 0 {
+			// routing present, or not GRE version 0
+			return true
+		}
+		offset := 4
+		if flags&0x8000 != 0 {
+			offset += 4
+		}
+		if flags&0x2000 != 0 {
+			offset += 4
+		}
+		if flags&0x1000 != 0 {
+			offset += 4
+		}
+		if len(data) < offset {
+			return true
+		}
+		flowKeyTunnel(key, func(inner *FlowKey) bool {
+			if typ == EthernetTypeTransparentEthernetBridging {
+				return flowKeyEthernet(data[offset:], inner, depth+1)
+			}
+			return flowKeyEthernetType(typ, data[offset:], inner, depth+1)
+		})
+	}
+	return true
+}
+
+// flowKeyTunnel replaces key with the key of the tunneled packet extracted by
+// extract, if there is one
+func flowKeyTunnel(key *FlowKey, extract func(inner *FlowKey) bool) {
+	inner := *key
+	if extract(&inner) {
+		inner.Tunneled = true
+		*key = inner
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestExtractFlowKey(t *testing.T) {
+	ip4 := func(a, b byte) *IPv4 {
+		return &IPv4{SrcIP: net.IP{10, 0, 0, a}, DstIP: net.IP{10, 0, 0, b}}
+	}
+	tests := []struct {
+		name     string
+		builder  *Builder
+		vlan     uint16
+		tunneled bool
+	}{
+		{"tcp", Build().Ethernet(nil).IPv4(ip4(1, 2)).TCP(&TCP{SrcPort: 1234, DstPort: 80}).Payload([]byte("hello")), 0, false},
+		{"udp over ipv6 over vlan", Build().Ethernet(nil).Dot1Q(&Dot1Q{VLANIdentifier: 42}).Dot1Q(&Dot1Q{VLANIdentifier: 43}).
+			IPv6(&IPv6{SrcIP: net.ParseIP("2001:db8::1"), DstIP: net.ParseIP("2001:db8::2")}).UDP(&UDP{SrcPort: 1234, DstPort: 9999}), 43, false},
+		{"icmp", Build().Ethernet(nil).IPv4(ip4(1, 2)).ICMPv4(nil), 0, false},
+		{"mpls", Build().Ethernet(nil).Layer(&MPLS{Label: 1, StackBottom: true, TTL: 64}).IPv4(ip4(1, 2)).UDP(&UDP{SrcPort: 1, DstPort: 2}), 0, false},
+		{"ipip", Build().Ethernet(nil).IPv4(ip4(1, 2)).IPv4(ip4(3, 4)).TCP(nil), 0, true},
+		{"vxlan", Build().Ethernet(nil).IPv4(ip4(1, 2)).UDP(&UDP{SrcPort: 5000, DstPort: 4789}).Layer(&VXLAN{ValidIDFlag: true, VNI: 7}).
+			Ethernet(nil).IPv4(ip4(5, 6)).TCP(nil), 0, true},
+		{"gre", Build().Ethernet(nil).IPv4(ip4(1, 2)).Layer(&GRE{KeyPresent: true, Key: 9, Protocol: EthernetTypeIPv6}).
+			IPv6(&IPv6{SrcIP: net.ParseIP("2001:db8::3"), DstIP: net.ParseIP("2001:db8::4")}).UDP(&UDP{SrcPort: 1, DstPort: 2}), 0, true},
+	}
+	for _, test := range tests {
+		data, err := test.builder.Bytes()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		key, ok := ExtractFlowKey(data, LinkTypeEthernet)
+		if !ok {
+			t.Errorf("%s: no flow key", test.name)
+			continue
+		}
+		// the innermost network and transport layers of the decoded packet
+		// have to match the key
+		var net, transport gopacket.Flow
+		p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default)
+		for _, l := range p.Layers() {
+			switch l := l.(type) {
+			case gopacket.NetworkLayer:
+				net, transport = l.NetworkFlow(), gopacket.InvalidFlow
+			case gopacket.TransportLayer:
+				transport = l.TransportFlow()
+			}
+		}
+		if got := key.NetworkFlow(); got != net {
+			t.Errorf("%s: expected network flow %v, got %v", test.name, net, got)
+		}
+		if got := key.TransportFlow(); got != transport {
+			t.Errorf("%s: expected transport flow %v, got %v", test.name, transport, got)
+		}
+		if key.VLAN != test.vlan {
+			t.Errorf("%s: expected VLAN %d, got %d", test.name, test.vlan, key.VLAN)
+		}
+		if key.Tunneled != test.tunneled {
+			t.Errorf("%s: expected Tunneled %v, got %v", test.name, test.tunneled, key.Tunneled)
+		}
+		for i := 0; i < len(data); i++ {
+			if k, ok := ExtractFlowKey(data[:i], LinkTypeEthernet); ok && k.Tunneled && !test.tunneled {
+				t.Errorf("%s: truncated to %d bytes: unexpected tunnel", test.name, i)
+			}
+		}
+	}
+}
+
+func TestExtractFlowKeyLinkTypes(t *testing.T) {
+	data, err := Build().IPv4(&IPv4{SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}).TCP(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FlowKey{IPVersion: 4, Protocol: IPProtocolTCP, SrcPort: 20, DstPort: 80}
+	copy(want.SrcIP[:], []byte{10, 0, 0, 1})
+	copy(want.DstIP[:], []byte{10, 0, 0, 2})
+
+	sll := append(make([]byte, 14), 0x08, 0x00)
+	for _, test := range []struct {
+		linkType LinkType
+		data     []byte
+	}{
+		{LinkTypeRaw, data},
+		{LinkTypeIPv4, data},
+		{LinkTypeNull, append([]byte{2, 0, 0, 0}, data...)},
+		{LinkTypeLoop, append([]byte{0, 0, 0, 2}, data...)},
+		{LinkTypeLinuxSLL, append(sll, data...)},
+	} {
+		if key, ok := ExtractFlowKey(test.data, test.linkType); !ok || key != want {
+			t.Errorf("%v: expected %+v, got %+v (%v)", test.linkType, want, key, ok)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if key, ok := ExtractFlowKey(data[:i], LinkTypeRaw); ok {
+			t.Errorf("truncated to %d bytes: unexpected key %+v", i, key)
+		}
+	}
+	if _, ok := ExtractFlowKey(data, LinkTypeEthernet); ok {
+		t.Error("unexpected key for IPv4 data as Ethernet")
+	}
+}
+
+func TestExtractFlowKeyFragment(t *testing.T) {
+	data, err := Build().IPv4(&IPv4{FragOffset: 100}).UDP(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, ok := ExtractFlowKey(data, LinkTypeRaw)
+	if !ok || key.Protocol != IPProtocolUDP || key.SrcPort != 0 || key.DstPort != 0 {
+		t.Errorf("unexpected key %+v (%v)", key, ok)
+	}
+}
+
+func TestFlowKeyFastHashSymmetric(t *testing.T) {
+	a, _ := Build().IPv4(&IPv4{SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}).TCP(&TCP{SrcPort: 1, DstPort: 2}).Bytes()
+	b, _ := Build().IPv4(&IPv4{SrcIP: net.IP{10, 0, 0, 2}, DstIP: net.IP{10, 0, 0, 1}}).TCP(&TCP{SrcPort: 2, DstPort: 1}).Bytes()
+	ka, _ := ExtractFlowKey(a, LinkTypeRaw)
+	kb, _ := ExtractFlowKey(b, LinkTypeRaw)
+	if ka.FastHash() != kb.FastHash() {
+		t.Errorf("hashes differ: %x, %x", ka.FastHash(), kb.FastHash())
+	}
+}
+
+func TestExtractFlowKeyAllocs(t *testing.T) {
+	data, _ := Build().Ethernet(nil).IPv4(nil).UDP(&UDP{SrcPort: 5000, DstPort: 4789}).Layer(&VXLAN{ValidIDFlag: true}).
+		Ethernet(nil).IPv4(nil).TCP(nil).Bytes()
+	if n := testing.AllocsPerRun(100, func() { ExtractFlowKey(data, LinkTypeEthernet) }); n != 0 {
+		t.Errorf("ExtractFlowKey allocates %v times", n)
+	}
+}
+
+func BenchmarkExtractFlowKey(b *testing.B) {
+	data, _ := Build().Ethernet(nil).IPv4(nil).TCP(nil).Payload(make([]byte, 100)).Bytes()
+	for i := 0; i < b.N; i++ {
+		ExtractFlowKey(data, LinkTypeEthernet)
+	}
+}
+
+func BenchmarkExtractFlowKeyDecode(b *testing.B) {
+	data, _ := Build().Ethernet(nil).IPv4(nil).TCP(nil).Payload(make([]byte, 100)).Bytes()
+	for i := 0; i < b.N; i++ {
+		p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.NoCopy)
+		p.NetworkLayer().NetworkFlow()
+		p.TransportLayer().TransportFlow()
+	}
+}
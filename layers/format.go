@@ -0,0 +1,82 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/gopacket"
+)
+
+// The summary formatters produce tcpdump style descriptions for
+// gopacket.FormatPacket.
+func init() {
+	gopacket.RegisterLayerFormatter(LayerTypeTCP, formatTCP)
+	gopacket.RegisterLayerFormatter(LayerTypeICMPv4, formatICMPv4)
+	gopacket.RegisterLayerFormatter(LayerTypeICMPv6, formatICMPv6)
+	gopacket.RegisterLayerFormatter(LayerTypeARP, formatARP)
+}
+
+func formatTCP(l gopacket.Layer, v gopacket.Verbosity) string {
+	tcp, ok := l.(*TCP)
+	if !ok || v != gopacket.VerbositySummary {
+		return ""
+	}
+	var flags strings.Builder
+	for _, f := range []struct {
+		set bool
+		c   byte
+	}{
+		{tcp.SYN, 'S'}, {tcp.FIN, 'F'}, {tcp.RST, 'R'}, {tcp.PSH, 'P'},
+		{tcp.URG, 'U'}, {tcp.ECE, 'E'}, {tcp.CWR, 'W'}, {tcp.ACK, '.'},
+	} {
+		if f.set {
+			flags.WriteByte(f.c)
+		}
+	}
+	s := fmt.Sprintf("Flags [%s], seq %d", flags.String(), tcp.Seq)
+	if tcp.ACK {
+		s += fmt.Sprintf(", ack %d", tcp.Ack)
+	}
+	return s + fmt.Sprintf(", win %d", tcp.Window)
+}
+
+func formatICMPv4(l gopacket.Layer, v gopacket.Verbosity) string {
+	icmp, ok := l.(*ICMPv4)
+	if !ok || v != gopacket.VerbositySummary {
+		return ""
+	}
+	switch icmp.TypeCode.Type() {
+	case ICMPv4TypeEchoRequest, ICMPv4TypeEchoReply:
+		return fmt.Sprintf("ICMP %v, id %d, seq %d", icmp.TypeCode, icmp.Id, icmp.Seq)
+	}
+	return fmt.Sprintf("ICMP %v", icmp.TypeCode)
+}
+
+func formatICMPv6(l gopacket.Layer, v gopacket.Verbosity) string {
+	icmp, ok := l.(*ICMPv6)
+	if !ok || v != gopacket.VerbositySummary {
+		return ""
+	}
+	return fmt.Sprintf("ICMP6 %v", icmp.TypeCode)
+}
+
+func formatARP(l gopacket.Layer, v gopacket.Verbosity) string {
+	arp, ok := l.(*ARP)
+	if !ok || v != gopacket.VerbositySummary {
+		return ""
+	}
+	switch arp.Operation {
+	case ARPRequest:
+		return fmt.Sprintf("ARP, Request who-has %v tell %v", net.IP(arp.DstProtAddress), net.IP(arp.SourceProtAddress))
+	case ARPReply:
+		return fmt.Sprintf("ARP, Reply %v is-at %v", net.IP(arp.SourceProtAddress), net.HardwareAddr(arp.SourceHwAddress))
+	}
+	return fmt.Sprintf("ARP, Operation %d", arp.Operation)
+}
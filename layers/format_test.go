@@ -0,0 +1,64 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestFormatPacketSummary(t *testing.T) {
+	ip4 := func() *IPv4 { return &IPv4{SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}} }
+	tests := []struct {
+		builder *Builder
+		want    string
+	}{
+		{Build().Ethernet(nil).IPv4(ip4()).TCP(&TCP{SrcPort: 1234, DstPort: 80, SYN: true, ACK: true, Seq: 1, Ack: 2, Window: 512}).Payload([]byte("hello")),
+			"IPv4 10.0.0.1.1234 > 10.0.0.2.80: Flags [S.], seq 1, ack 2, win 512, length 5"},
+		{Build().Ethernet(nil).IPv4(ip4()).UDP(&UDP{SrcPort: 1000, DstPort: 2000}).Payload([]byte("hi")),
+			"IPv4 10.0.0.1.1000 > 10.0.0.2.2000: UDP, length 2"},
+		{Build().Ethernet(nil).IPv4(ip4()).ICMPv4(&ICMPv4{TypeCode: CreateICMPv4TypeCode(ICMPv4TypeEchoRequest, 0), Id: 7, Seq: 8}),
+			"IPv4 10.0.0.1 > 10.0.0.2: ICMP EchoRequest, id 7, seq 8, length 0"},
+		{Build().Ethernet(nil).Layer(&ARP{AddrType: LinkTypeEthernet, Protocol: EthernetTypeIPv4, HwAddressSize: 6, ProtAddressSize: 4, Operation: ARPRequest,
+			SourceHwAddress: make([]byte, 6), SourceProtAddress: []byte{10, 0, 0, 1}, DstHwAddress: make([]byte, 6), DstProtAddress: []byte{10, 0, 0, 2}}),
+			"ARP, Request who-has 10.0.0.2 tell 10.0.0.1"},
+	}
+	for _, test := range tests {
+		data, err := test.builder.Bytes()
+		if err != nil {
+			t.Errorf("%s: %v", test.want, err)
+			continue
+		}
+		p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default)
+		if got := gopacket.FormatPacket(p, gopacket.VerbositySummary); got != test.want {
+			t.Errorf("got  %q\nwant %q", got, test.want)
+		}
+	}
+
+	data, _ := Build().Ethernet(nil).IPv4(ip4()).TCP(nil).Bytes()
+	p := gopacket.NewPacket(data[:40], LinkTypeEthernet, gopacket.Default)
+	want := "IPv4 10.0.0.1 > 10.0.0.2, length 6 [decode error: Invalid TCP header. Length 6 less than 20]"
+	if got := gopacket.FormatPacket(p, gopacket.VerbositySummary); got != want {
+		t.Errorf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestFormatPacketSummaryDecodeLimit(t *testing.T) {
+	data, err := Build().IPv4(&IPv4{SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}).TCP(&TCP{SrcPort: 1, DstPort: 2}).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The IPv4 header exceeds the limit, so it isn't added to the layers
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.DecodeOptions{MaxTotalLayerBytes: 10})
+	want := " [decode error: " + p.ErrorLayer().Error().Error() + "]"
+	if got := gopacket.FormatPacket(p, gopacket.VerbositySummary); !strings.HasSuffix(got, want) {
+		t.Errorf("got  %q\nwant suffix %q", got, want)
+	}
+}
@@ -50,6 +50,11 @@ func (a {{.Name}}) LayerType() gopacket.LayerType {
 
 type errorDecoderFor{{.Name}} int
 func (a *errorDecoderFor{{.Name}}) Decode(data []byte, p gopacket.PacketBuilder) error {
+{{- if .Unknown}}
+  if ok, err := gopacket.DecodeUnknownProtocol(gopacket.{{.Unknown}}, int(*a), data, p); ok {
+    return err
+  }
+{{- end}}
   return a
 }
 func (a *errorDecoderFor{{.Name}}) Error() string {
@@ -76,18 +81,20 @@ func main() {
 	types := []struct {
 		Name string
 		Num  int
+		// Unknown is the gopacket.UnknownProtocolKind of unknown values
+		Unknown string
 	}{
-		{"LinkType", 256},
-		{"EthernetType", 65536},
-		{"PPPType", 65536},
-		{"IPProtocol", 256},
-		{"SCTPChunkType", 256},
-		{"PPPoECode", 256},
-		{"FDDIFrameControl", 256},
-		{"EAPOLType", 256},
-		{"ProtocolFamily", 256},
-		{"Dot11Type", 256},
-		{"USBTransportType", 256},
+		{"LinkType", 256, ""},
+		{"EthernetType", 65536, "UnknownEthernetType"},
+		{"PPPType", 65536, ""},
+		{"IPProtocol", 256, "UnknownIPProtocol"},
+		{"SCTPChunkType", 256, ""},
+		{"PPPoECode", 256, ""},
+		{"FDDIFrameControl", 256, ""},
+		{"EAPOLType", 256, ""},
+		{"ProtocolFamily", 256, ""},
+		{"Dot11Type", 256, ""},
+		{"USBTransportType", 256, ""},
 	}
 
 	fmt.Println("func init() {")
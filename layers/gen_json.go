@@ -0,0 +1,114 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+//go:build ignore
+// +build ignore
+
+// This binary creates MarshalJSON methods for all layers embedding BaseLayer.
+//
+//	go run gen_json.go | gofmt > json_generated.go
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+const header = `// Copyright 2026 The GoPacket Authors. All rights reserved.
+
+package layers
+
+// Created by gen_json.go, don't edit manually
+
+import (
+	"github.com/google/gopacket"
+)
+`
+
+func main() {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != "json_generated.go"
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// embeds maps each struct type to the names of the types it embeds
+	embeds := map[string][]string{}
+	// layerTypes holds the types with a LayerType method
+	layerTypes := map[string]bool{}
+	for _, f := range pkgs["layers"].Files {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "LayerType" && fn.Recv != nil {
+				recv := fn.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok {
+					layerTypes[ident.Name] = true
+				}
+				continue
+			}
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				embeds[ts.Name.Name] = nil
+				for _, field := range st.Fields.List {
+					if len(field.Names) == 0 {
+						if ident, ok := field.Type.(*ast.Ident); ok {
+							embeds[ts.Name.Name] = append(embeds[ts.Name.Name], ident.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	// types embedding another layer get their own method, as the promoted
+	// one would only marshal the embedded layer
+	var embedsType func(name string, match func(string) bool, depth int) bool
+	embedsType = func(name string, match func(string) bool, depth int) bool {
+		if depth > 8 {
+			return false
+		}
+		for _, e := range embeds[name] {
+			if match(e) || embedsType(e, match, depth+1) {
+				return true
+			}
+		}
+		return false
+	}
+	isBaseLayer := func(name string) bool { return name == "BaseLayer" }
+	hasLayerType := func(name string) bool { return layerTypes[name] }
+	var names []string
+	for name := range embeds {
+		if ast.IsExported(name) && embedsType(name, isBaseLayer, 0) && (hasLayerType(name) || embedsType(name, hasLayerType, 0)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	fmt.Print(header)
+	for _, name := range names {
+		fmt.Printf(`
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *%s) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+`, name)
+	}
+}
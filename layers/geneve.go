@@ -10,6 +10,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/google/gopacket"
 )
@@ -46,13 +48,119 @@ type GeneveOption struct {
 	Flags  uint8  // 3 bits
 	Length uint8  // 5 bits
 	Data   []byte
+	// Value is the typed value of the option, if its class is registered
+	// and its type and data are valid for the class
+	Value GeneveOptionValue
+}
+
+// GeneveOptionValue is the typed value of a Geneve option, decoded from
+// its data by the type registered for its class with
+// RegisterGeneveOptionClass.
+type GeneveOptionValue interface {
+	// DecodeFromBytes decodes the data of an option of type typ.
+	DecodeFromBytes(typ uint8, data []byte) error
+	// AppendData appends the data of the option to b, padded to 4 bytes.
+	AppendData(b []byte) []byte
+}
+
+var geneveOptionClassesMu sync.Mutex
+
+var geneveOptionClasses atomic.Value // map[uint16]func() GeneveOptionValue
+
+// RegisterGeneveOptionClass registers the type of the values of the options
+// of class: the options of decoded Geneve layers get a Value returned by
+// newValue, decoded from their data.  It may be called while packets are
+// decoded by other goroutines.
+func RegisterGeneveOptionClass(class uint16, newValue func() GeneveOptionValue) {
+	geneveOptionClassesMu.Lock()
+	defer geneveOptionClassesMu.Unlock()
+	old, _ := geneveOptionClasses.Load().(map[uint16]func() GeneveOptionValue)
+	m := make(map[uint16]func() GeneveOptionValue, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[class] = newValue
+	geneveOptionClasses.Store(m)
+}
+
+// Geneve option classes with typed values
+const (
+	GeneveOptionClassOVN uint16 = 0x0102
+	GeneveOptionClassAWS uint16 = 0x0108
+)
+
+func init() {
+	RegisterGeneveOptionClass(GeneveOptionClassOVN, func() GeneveOptionValue { return &GeneveOVNOption{} })
+	RegisterGeneveOptionClass(GeneveOptionClassAWS, func() GeneveOptionValue { return &GeneveAWSOption{} })
+}
+
+// GeneveOVNOption is the option of OVN, type 0x80, carrying the logical
+// ports of a packet.
+type GeneveOVNOption struct {
+	IngressPort uint16 // 15 bits
+	EgressPort  uint16
+}
+
+// DecodeFromBytes decodes the data of an OVN option.
+func (o *GeneveOVNOption) DecodeFromBytes(typ uint8, data []byte) error {
+	if typ&0x7f != 0x00 || len(data) != 4 {
+		return fmt.Errorf("invalid OVN geneve option of type %#x and %d bytes", typ, len(data))
+	}
+	o.IngressPort = binary.BigEndian.Uint16(data[0:2]) & 0x7fff
+	o.EgressPort = binary.BigEndian.Uint16(data[2:4])
+	return nil
+}
+
+// AppendData appends the data of an OVN option to b.
+func (o *GeneveOVNOption) AppendData(b []byte) []byte {
+	return append(b, byte(o.IngressPort>>8)&0x7f, byte(o.IngressPort), byte(o.EgressPort>>8), byte(o.EgressPort))
+}
+
+// AWS Gateway Load Balancer geneve option types
+const (
+	GeneveAWSOptionVPCEndpointID uint8 = 1
+	GeneveAWSOptionAttachmentID  uint8 = 2
+	GeneveAWSOptionFlowCookie    uint8 = 3
+)
+
+// GeneveAWSOption is an option of AWS Gateway Load Balancer: the VPC
+// endpoint ID, attachment ID or flow cookie of a packet.
+type GeneveAWSOption struct {
+	Type uint8
+	// Value is the 8 byte ID, or the 4 byte flow cookie
+	Value uint64
+}
+
+// DecodeFromBytes decodes the data of an AWS option.
+func (o *GeneveAWSOption) DecodeFromBytes(typ uint8, data []byte) error {
+	o.Type = typ
+	switch {
+	case typ == GeneveAWSOptionFlowCookie && len(data) == 4:
+		o.Value = uint64(binary.BigEndian.Uint32(data))
+	case (typ == GeneveAWSOptionVPCEndpointID || typ == GeneveAWSOptionAttachmentID) && len(data) == 8:
+		o.Value = binary.BigEndian.Uint64(data)
+	default:
+		return fmt.Errorf("invalid AWS geneve option of type %#x and %d bytes", typ, len(data))
+	}
+	return nil
+}
+
+// AppendData appends the data of an AWS option to b.
+func (o *GeneveAWSOption) AppendData(b []byte) []byte {
+	var buf [8]byte
+	if o.Type == GeneveAWSOptionFlowCookie {
+		binary.BigEndian.PutUint32(buf[:], uint32(o.Value))
+		return append(b, buf[:4]...)
+	}
+	binary.BigEndian.PutUint64(buf[:], o.Value)
+	return append(b, buf[:]...)
 }
 
 // LayerType returns LayerTypeGeneve
 func (gn *Geneve) LayerType() gopacket.LayerType { return LayerTypeGeneve }
 
 func decodeGeneveOption(data []byte, gn *Geneve, df gopacket.DecodeFeedback) (*GeneveOption, uint8, error) {
-	if len(data) < 3 {
+	if len(data) < 4 {
 		df.SetTruncated()
 		return nil, 0, errors.New("geneve option too small")
 	}
@@ -60,8 +168,8 @@ func decodeGeneveOption(data []byte, gn *Geneve, df gopacket.DecodeFeedback) (*G
 
 	opt.Class = binary.BigEndian.Uint16(data[0:2])
 	opt.Type = data[2]
-	opt.Flags = data[3] >> 4
-	opt.Length = (data[3]&0xf)*4 + 4
+	opt.Flags = data[3] >> 5
+	opt.Length = (data[3]&0x1f)*4 + 4
 
 	if len(data) < int(opt.Length) {
 		df.SetTruncated()
@@ -70,16 +178,24 @@ func decodeGeneveOption(data []byte, gn *Geneve, df gopacket.DecodeFeedback) (*G
 	opt.Data = make([]byte, opt.Length-4)
 	copy(opt.Data, data[4:opt.Length])
 
+	classes, _ := geneveOptionClasses.Load().(map[uint16]func() GeneveOptionValue)
+	if newValue := classes[opt.Class]; newValue != nil {
+		// options of unknown types or with invalid data keep only their data
+		if value := newValue(); value.DecodeFromBytes(opt.Type, opt.Data) == nil {
+			opt.Value = value
+		}
+	}
+
 	return opt, opt.Length, nil
 }
 
 func (gn *Geneve) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
-	if len(data) < 7 {
+	if len(data) < 8 {
 		df.SetTruncated()
 		return errors.New("geneve packet too short")
 	}
 
-	gn.Version = data[0] >> 7
+	gn.Version = data[0] >> 6
 	gn.OptionsLength = (data[0] & 0x3f) * 4
 
 	gn.OAMPacket = data[1]&0x80 > 0
@@ -90,21 +206,22 @@ func (gn *Geneve) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error
 	copy(buf[1:], data[4:7])
 	gn.VNI = binary.BigEndian.Uint32(buf[:])
 
-	offset, length := uint8(8), int32(gn.OptionsLength)
-	if len(data) < int(length+7) {
+	offset, length := 8, int(gn.OptionsLength)
+	if len(data) < length+8 {
 		df.SetTruncated()
 		return errors.New("geneve packet too short")
 	}
 
+	gn.Options = gn.Options[:0]
 	for length > 0 {
-		opt, len, err := decodeGeneveOption(data[offset:], gn, df)
+		opt, len, err := decodeGeneveOption(data[offset:8+int(gn.OptionsLength)], gn, df)
 		if err != nil {
 			return err
 		}
 		gn.Options = append(gn.Options, opt)
 
-		length -= int32(len)
-		offset += len
+		length -= int(len)
+		offset += int(len)
 	}
 
 	gn.BaseLayer = BaseLayer{data[:offset], data[offset:]}
@@ -124,8 +241,46 @@ func decodeGeneve(data []byte, p gopacket.PacketBuilder) error {
 // SerializeTo writes the serialized form of this layer into the
 // SerializationBuffer, implementing gopacket.SerializableLayer.
 // See the docs for gopacket.SerializableLayer for more info.
+//
+// The data of options with a Value is serialized from it.  With FixLengths,
+// the Length of the options and the OptionsLength are set, and the data of
+// the options padded to 4 bytes.
 func (gn *Geneve) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
-	plen := int(gn.OptionsLength + 8)
+	if gn.VNI >= 1<<24 {
+		return fmt.Errorf("Virtual Network Identifier = %x exceeds max for 24-bit uint", gn.VNI)
+	}
+	data := make([][]byte, len(gn.Options))
+	length := 0
+	for i, o := range gn.Options {
+		data[i] = o.Data
+		if o.Value != nil {
+			data[i] = o.Value.AppendData(nil)
+		}
+		if opts.FixLengths {
+			if pad := len(data[i]) % 4; pad != 0 {
+				data[i] = append(append([]byte(nil), data[i]...), make([]byte, 4-pad)...)
+			}
+			if len(data[i]) > 0x1f*4 {
+				return fmt.Errorf("geneve option data of %d bytes too long", len(data[i]))
+			}
+			o.Length = uint8(4 + len(data[i]))
+		}
+		if o.Length < 4 || o.Length%4 != 0 || int(o.Length)-4 > 0x1f*4 {
+			return fmt.Errorf("invalid geneve option length %d", o.Length)
+		}
+		length += int(o.Length)
+	}
+	if opts.FixLengths {
+		if length > 0x3f*4 {
+			return fmt.Errorf("geneve options of %d bytes too long", length)
+		}
+		gn.OptionsLength = uint8(length)
+	}
+	if int(gn.OptionsLength) != length || gn.OptionsLength%4 != 0 {
+		return fmt.Errorf("geneve options length %d for options of %d bytes", gn.OptionsLength, length)
+	}
+
+	plen := int(gn.OptionsLength) + 8
 	bytes, err := b.PrependBytes(plen)
 	if err != nil {
 		return err
@@ -149,29 +304,22 @@ func (gn *Geneve) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Serializ
 	}
 
 	binary.BigEndian.PutUint16(bytes[2:4], uint16(gn.Protocol))
-
-	if gn.VNI >= 1<<24 {
-		return fmt.Errorf("Virtual Network Identifier = %x exceeds max for 24-bit uint", gn.VNI)
-	}
 	binary.BigEndian.PutUint32(bytes[4:8], gn.VNI<<8)
 
 	// Construct Options
 
-	offset, _ := uint8(8), int32(gn.OptionsLength)
-	for _, o := range gn.Options {
+	offset := 8
+	for i, o := range gn.Options {
 		binary.BigEndian.PutUint16(bytes[offset:(offset+2)], uint16(o.Class))
+		bytes[offset+2] = o.Type
+		bytes[offset+3] = o.Flags<<5 | ((o.Length-4)>>2)&0x1f
+		offset += 4
 
-		offset += 2
-		bytes[offset] = o.Type
-
-		offset += 1
-		bytes[offset] |= o.Flags << 5
-		bytes[offset] |= ((o.Length - 4) >> 2) & 0x1f
-
-		offset += 1
-		copy(bytes[offset:(offset+o.Length-4)], o.Data)
-
-		offset += o.Length - 4
+		n := copy(bytes[offset:offset+int(o.Length)-4], data[i])
+		for j := offset + n; j < offset+int(o.Length)-4; j++ {
+			bytes[j] = 0
+		}
+		offset += int(o.Length) - 4
 	}
 
 	return nil
@@ -191,3 +191,78 @@ func TestIsomorphicPacketGeneve(t *testing.T) {
 		t.Errorf("VXLAN isomorph mismatch, \nwant %#v\ngot %#v\n", gn, gnTranslated)
 	}
 }
+
+func TestGeneveTypedOptions(t *testing.T) {
+	gn := &Geneve{
+		Protocol: EthernetTypeTransparentEthernetBridging,
+		VNI:      0x123456,
+		Options: []*GeneveOption{
+			{Class: GeneveOptionClassOVN, Type: 0x80, Value: &GeneveOVNOption{IngressPort: 3, EgressPort: 7}},
+			{Class: GeneveOptionClassAWS, Type: GeneveAWSOptionVPCEndpointID, Value: &GeneveAWSOption{Type: GeneveAWSOptionVPCEndpointID, Value: 0x0102030405060708}},
+			{Class: 0xffff, Type: 0x01, Flags: 0x5, Data: []byte{1, 2, 3, 4, 5}},
+		},
+	}
+	b := gopacket.NewSerializeBuffer()
+	if err := gn.SerializeTo(b, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if gn.OptionsLength != 32 || gn.Options[0].Length != 8 || gn.Options[1].Length != 12 || gn.Options[2].Length != 12 {
+		t.Fatalf("unexpected lengths %d %d %d %d", gn.OptionsLength, gn.Options[0].Length, gn.Options[1].Length, gn.Options[2].Length)
+	}
+	if b.Bytes()[0] != 8 || b.Bytes()[8+20+3] != 0xa2 {
+		t.Errorf("unexpected header %x", b.Bytes())
+	}
+
+	p := gopacket.NewPacket(b.Bytes(), LayerTypeGeneve, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	got := p.Layer(LayerTypeGeneve).(*Geneve)
+	if len(got.Options) != 3 || len(got.Payload) != 0 {
+		t.Fatalf("unexpected options %#v", got.Options)
+	}
+	if v, ok := got.Options[0].Value.(*GeneveOVNOption); !ok || v.IngressPort != 3 || v.EgressPort != 7 {
+		t.Errorf("unexpected OVN option %#v", got.Options[0])
+	}
+	if v, ok := got.Options[1].Value.(*GeneveAWSOption); !ok || v.Type != GeneveAWSOptionVPCEndpointID || v.Value != 0x0102030405060708 {
+		t.Errorf("unexpected AWS option %#v", got.Options[1])
+	}
+	if o := got.Options[2]; o.Value != nil || o.Flags != 0x5 || !reflect.DeepEqual(o.Data, []byte{1, 2, 3, 4, 5, 0, 0, 0}) {
+		t.Errorf("unexpected option %#v", o)
+	}
+
+	// options of unknown types of registered classes keep their data
+	gn = &Geneve{
+		Protocol: EthernetTypeTransparentEthernetBridging,
+		Options: []*GeneveOption{
+			{Class: GeneveOptionClassAWS, Type: 9, Data: []byte{1, 2, 3, 4}},
+			{Class: GeneveOptionClassOVN, Type: 0x01, Data: []byte{5, 6, 7, 8}},
+			{Class: GeneveOptionClassAWS, Type: GeneveAWSOptionFlowCookie, Value: &GeneveAWSOption{Type: GeneveAWSOptionFlowCookie, Value: 42}},
+		},
+	}
+	b = gopacket.NewSerializeBuffer()
+	if err := gn.SerializeTo(b, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	p = gopacket.NewPacket(b.Bytes(), LayerTypeGeneve, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	got = p.Layer(LayerTypeGeneve).(*Geneve)
+	if len(got.Options) != 3 {
+		t.Fatalf("unexpected options %#v", got.Options)
+	}
+	for i, data := range [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}} {
+		if o := got.Options[i]; o.Value != nil || !reflect.DeepEqual(o.Data, data) {
+			t.Errorf("unexpected option %#v", o)
+		}
+	}
+	if v, ok := got.Options[2].Value.(*GeneveAWSOption); !ok || v.Value != 42 {
+		t.Errorf("unexpected AWS option %#v", got.Options[2])
+	}
+
+	// an option longer than the options
+	if err := (&Geneve{}).DecodeFromBytes([]byte{0x01, 0, 0x65, 0x58, 0, 0, 0, 0, 0, 0, 0x80, 0x01}, gopacket.NilDecodeFeedback); err == nil {
+		t.Error("no error for truncated option")
+	}
+}
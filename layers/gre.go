@@ -8,11 +8,17 @@ package layers
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 
 	"github.com/google/gopacket"
 )
 
-// GRE is a Generic Routing Encapsulation header.
+// GRE is a Generic Routing Encapsulation header.  Version 1 is the enhanced
+// GRE header of PPTP, RFC 2637, whose key holds the PayloadLength and CallID,
+// and which may carry the Ack of the packets of the other direction without
+// any payload.  NVGRE headers, RFC 7637, are GRE headers of Ethernet
+// frames whose key holds the VSID and FlowID.
 type GRE struct {
 	BaseLayer
 	ChecksumPresent, RoutingPresent, KeyPresent, SeqPresent, StrictSourceRoute, AckPresent bool
@@ -20,6 +26,14 @@ type GRE struct {
 	Protocol                                                                               EthernetType
 	Checksum, Offset                                                                       uint16
 	Key, Seq, Ack                                                                          uint32
+	// PayloadLength and CallID are the halves of the key of version 1
+	PayloadLength, CallID uint16
+	// NVGRE is set for NVGRE headers, with only a key and the
+	// TransparentEthernetBridging protocol; their key is serialized from
+	// the VSID and FlowID
+	NVGRE  bool
+	VSID   uint32 // 24 bits
+	FlowID uint8
 	*GRERouting
 }
 
@@ -35,8 +49,14 @@ type GRERouting struct {
 // LayerType returns gopacket.LayerTypeGRE.
 func (g *GRE) LayerType() gopacket.LayerType { return LayerTypeGRE }
 
+var errGRETooShort = errors.New("GRE header too short")
+
 // DecodeFromBytes decodes the given bytes into this layer.
 func (g *GRE) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 4 {
+		df.SetTruncated()
+		return errGRETooShort
+	}
 	g.ChecksumPresent = data[0]&0x80 != 0
 	g.RoutingPresent = data[0]&0x40 != 0
 	g.KeyPresent = data[0]&0x20 != 0
@@ -47,6 +67,23 @@ func (g *GRE) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
 	g.Flags = data[1] >> 3
 	g.Version = data[1] & 0x7
 	g.Protocol = EthernetType(binary.BigEndian.Uint16(data[2:4]))
+	g.Checksum, g.Offset, g.Key, g.Seq, g.Ack = 0, 0, 0, 0, 0
+	g.PayloadLength, g.CallID = 0, 0
+	g.NVGRE, g.VSID, g.FlowID = false, 0, 0
+	g.GRERouting = nil
+	if g.Version == 1 && !g.KeyPresent {
+		return errors.New("enhanced GRE header without key")
+	}
+	size := 4
+	for _, present := range []bool{g.ChecksumPresent || g.RoutingPresent, g.KeyPresent, g.SeqPresent, g.AckPresent} {
+		if present {
+			size += 4
+		}
+	}
+	if len(data) < size {
+		df.SetTruncated()
+		return errGRETooShort
+	}
 	offset := 4
 	if g.ChecksumPresent || g.RoutingPresent {
 		g.Checksum = binary.BigEndian.Uint16(data[offset : offset+2])
@@ -55,6 +92,14 @@ func (g *GRE) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
 	}
 	if g.KeyPresent {
 		g.Key = binary.BigEndian.Uint32(data[offset : offset+4])
+		if g.Version == 1 {
+			g.PayloadLength = uint16(g.Key >> 16)
+			g.CallID = uint16(g.Key)
+		} else if g.Protocol == EthernetTypeTransparentEthernetBridging && !g.ChecksumPresent && !g.RoutingPresent && !g.SeqPresent {
+			g.NVGRE = true
+			g.VSID = g.Key >> 8
+			g.FlowID = uint8(g.Key)
+		}
 		offset += 4
 	}
 	if g.SeqPresent {
@@ -64,11 +109,19 @@ func (g *GRE) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
 	if g.RoutingPresent {
 		tail := &g.GRERouting
 		for {
+			if len(data) < offset+4 {
+				df.SetTruncated()
+				return errGRETooShort
+			}
 			sre := &GRERouting{
 				AddressFamily: binary.BigEndian.Uint16(data[offset : offset+2]),
 				SREOffset:     data[offset+2],
 				SRELength:     data[offset+3],
 			}
+			if len(data) < offset+4+int(sre.SRELength) {
+				df.SetTruncated()
+				return errGRETooShort
+			}
 			sre.RoutingInformation = data[offset+4 : offset+4+int(sre.SRELength)]
 			offset += 4 + int(sre.SRELength)
 			if sre.AddressFamily == 0 && sre.SRELength == 0 {
@@ -77,18 +130,41 @@ func (g *GRE) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
 			(*tail) = sre
 			tail = &sre.Next
 		}
+		if g.AckPresent && len(data) < offset+4 {
+			df.SetTruncated()
+			return errGRETooShort
+		}
 	}
 	if g.AckPresent {
 		g.Ack = binary.BigEndian.Uint32(data[offset : offset+4])
 		offset += 4
 	}
 	g.BaseLayer = BaseLayer{data[:offset], data[offset:]}
+	if g.Version == 1 && int(g.PayloadLength) < len(g.Payload) {
+		// frames may be padded
+		g.Payload = g.Payload[:g.PayloadLength]
+	}
 	return nil
 }
 
+// IsKeepalive returns true for the headers of GRE keepalive responses: a
+// keepalive is a GRE packet carrying an IP packet addressed back to its
+// sender, itself carrying this empty GRE header.
+func (g *GRE) IsKeepalive() bool {
+	return g.Version == 0 && g.Protocol == 0 && len(g.Payload) == 0
+}
+
 // SerializeTo writes the serialized form of this layer into the SerializationBuffer,
 // implementing gopacket.SerializableLayer. See the docs for gopacket.SerializableLayer for more info.
 func (g *GRE) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if g.NVGRE {
+		if !g.KeyPresent || g.Version != 0 {
+			return errors.New("NVGRE header without key")
+		}
+		if g.VSID >= 1<<24 {
+			return fmt.Errorf("NVGRE VSID %#x exceeds max for 24-bit uint", g.VSID)
+		}
+	}
 	size := 4
 	if g.ChecksumPresent || g.RoutingPresent {
 		size += 4
@@ -150,7 +226,16 @@ func (g *GRE) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOpt
 		offset += 4
 	}
 	if g.KeyPresent {
-		binary.BigEndian.PutUint32(buf[offset:offset+4], g.Key)
+		key := g.Key
+		if g.Version == 1 {
+			if opts.FixLengths {
+				g.PayloadLength = uint16(len(b.Bytes()) - size)
+			}
+			key = uint32(g.PayloadLength)<<16 | uint32(g.CallID)
+		} else if g.NVGRE {
+			key = g.VSID<<8 | uint32(g.FlowID)
+		}
+		binary.BigEndian.PutUint32(buf[offset:offset+4], key)
 		offset += 4
 	}
 	if g.SeqPresent {
@@ -191,6 +276,9 @@ func (g *GRE) CanDecode() gopacket.LayerClass {
 
 // NextLayerType returns the layer type contained by this DecodingLayer.
 func (g *GRE) NextLayerType() gopacket.LayerType {
+	if len(g.Payload) == 0 {
+		return gopacket.LayerTypeZero
+	}
 	return g.Protocol.LayerType()
 }
 
@@ -198,3 +286,81 @@ func decodeGRE(data []byte, p gopacket.PacketBuilder) error {
 	g := &GRE{}
 	return decodingLayerDecoder(g, data, p)
 }
+
+// GRESequenceStatus tells how the sequence number of a GRE packet compares
+// with the ones before it.
+type GRESequenceStatus uint8
+
+// GRE sequence statuses
+const (
+	// GRESequenceAbsent is the status of packets without sequence number
+	GRESequenceAbsent GRESequenceStatus = iota
+	// GRESequenceInOrder is the status of the packet following the last one
+	GRESequenceInOrder
+	// GRESequenceGap is the status of packets following missing ones
+	GRESequenceGap
+	// GRESequenceLate is the status of packets reordered or duplicated,
+	// whose sequence number is before the one expected
+	GRESequenceLate
+)
+
+func (s GRESequenceStatus) String() string {
+	switch s {
+	case GRESequenceAbsent:
+		return "Absent"
+	case GRESequenceInOrder:
+		return "InOrder"
+	case GRESequenceGap:
+		return "Gap"
+	case GRESequenceLate:
+		return "Late"
+	}
+	return fmt.Sprintf("GRESequenceStatus(%d)", uint8(s))
+}
+
+// GRESequenceTracker tracks the sequence numbers of the GRE packets sent in
+// one direction of a tunnel, or of a PPTP call, which the caller tells
+// apart, e.g. by the flow of the IP layer and the Key or CallID.
+type GRESequenceTracker struct {
+	// Packets is the number of packets with a sequence number
+	Packets uint64
+	// Lost is the number of sequence numbers skipped, including the ones
+	// of packets received late
+	Lost uint64
+	// Late is the number of packets reordered or duplicated
+	Late uint64
+	// Acked is the last acknowledgment number of the other direction sent
+	// with the packets, valid if HasAck is set
+	Acked  uint32
+	HasAck bool
+
+	started bool
+	next    uint32
+}
+
+// Track updates the tracker with the sequence and acknowledgment numbers
+// of g, and returns the status of its sequence number.  Sequence numbers
+// are compared with serial number arithmetic, so that they may wrap.
+func (t *GRESequenceTracker) Track(g *GRE) GRESequenceStatus {
+	if g.AckPresent {
+		t.Acked, t.HasAck = g.Ack, true
+	}
+	if !g.SeqPresent {
+		return GRESequenceAbsent
+	}
+	t.Packets++
+	status := GRESequenceInOrder
+	if t.started {
+		switch d := int32(g.Seq - t.next); {
+		case d < 0:
+			t.Late++
+			return GRESequenceLate
+		case d > 0:
+			t.Lost += uint64(d)
+			status = GRESequenceGap
+		}
+	}
+	t.started = true
+	t.next = g.Seq + 1
+	return status
+}
@@ -387,3 +387,129 @@ func setNetworkLayer(layers []gopacket.SerializableLayer) error {
 	}
 	return nil
 }
+
+func TestPPTPGRE(t *testing.T) {
+	inner, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 53, DstPort: 53}).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true}
+	err = gopacket.SerializeLayers(buf, opts,
+		&GRE{KeyPresent: true, SeqPresent: true, AckPresent: true, Version: 1, Protocol: EthernetTypePPP, CallID: 0x1234, Seq: 7, Ack: 3},
+		&PPP{PPPType: PPPTypeIPv4, HasPPTPHeader: true},
+		gopacket.Payload(inner))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(append(buf.Bytes(), 0, 0), LayerTypeGRE, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeGRE, LayerTypePPP, LayerTypeIPv4, LayerTypeUDP}, t)
+	g := p.Layer(LayerTypeGRE).(*GRE)
+	if g.Version != 1 || g.CallID != 0x1234 || int(g.PayloadLength) != 4+len(inner) || len(g.Payload) != int(g.PayloadLength) || g.Seq != 7 || g.Ack != 3 {
+		t.Errorf("unexpected enhanced GRE header %#v", g)
+	}
+
+	// an acknowledgment without payload
+	ack := []byte{0x20, 0x81, 0x88, 0x0b, 0, 0, 0x12, 0x34, 0, 0, 0, 8}
+	p = gopacket.NewPacket(ack, LayerTypeGRE, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeGRE}, t)
+
+	// PPP control protocols
+	lcp := append([]byte{0x30, 0x01, 0x88, 0x0b, 0, 6, 0x12, 0x34, 0, 0, 0, 9}, 0xff, 0x03, 0xc0, 0x21, 1, 1)
+	p = gopacket.NewPacket(lcp, LayerTypeGRE, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeGRE, LayerTypePPP, gopacket.LayerTypePayload}, t)
+
+	for _, data := range [][]byte{
+		{0x20, 0x81, 0x88},
+		ack[:10],
+		{0x00, 0x01, 0x88, 0x0b},
+		{0x40, 0x00, 0x08, 0x00, 0, 0, 0, 0, 0, 1, 0, 4},
+	} {
+		if err := (&GRE{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
+
+func TestNVGRE(t *testing.T) {
+	frame, err := Build().Ethernet(nil).IPv4(nil).UDP(&UDP{SrcPort: 53, DstPort: 53}).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Build().IPv4(&IPv4{Protocol: IPProtocolGRE}).Layer(&GRE{
+		KeyPresent: true, Protocol: EthernetTypeTransparentEthernetBridging, NVGRE: true, VSID: 0x123456, FlowID: 0x78,
+	}).Payload(frame).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeGRE, LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP}, t)
+	g := p.Layer(LayerTypeGRE).(*GRE)
+	if !g.NVGRE || g.Key != 0x12345678 || g.VSID != 0x123456 || g.FlowID != 0x78 {
+		t.Errorf("unexpected NVGRE header %#v", g)
+	}
+
+	// not NVGRE with a sequence number
+	if err := g.DecodeFromBytes([]byte{0x30, 0, 0x65, 0x58, 0x12, 0x34, 0x56, 0x78, 0, 0, 0, 1}, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if g.NVGRE || g.VSID != 0 {
+		t.Errorf("unexpected NVGRE header %#v", g)
+	}
+
+	if err := (&GRE{NVGRE: true}).SerializeTo(gopacket.NewSerializeBuffer(), gopacket.SerializeOptions{}); err == nil {
+		t.Error("no error for NVGRE header without key")
+	}
+}
+
+func TestGREKeepalive(t *testing.T) {
+	keepalive, err := Build().IPv4(&IPv4{Protocol: IPProtocolGRE}).Layer(&GRE{}).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(append([]byte{0, 0, 0x08, 0}, keepalive...), LayerTypeGRE, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeGRE, LayerTypeIPv4, LayerTypeGRE}, t)
+	if g := p.Layers()[2].(*GRE); !g.IsKeepalive() {
+		t.Error("keepalive not recognized")
+	}
+}
+
+func TestGRESequenceTracker(t *testing.T) {
+	var tr GRESequenceTracker
+	for _, c := range []struct {
+		seq  uint32
+		want GRESequenceStatus
+	}{
+		{0xfffffffe, GRESequenceInOrder},
+		{0xffffffff, GRESequenceInOrder},
+		{2, GRESequenceGap},
+		{1, GRESequenceLate},
+		{2, GRESequenceLate},
+		{3, GRESequenceInOrder},
+	} {
+		if got := tr.Track(&GRE{SeqPresent: true, Seq: c.seq}); got != c.want {
+			t.Errorf("%d: got %v, want %v", c.seq, got, c.want)
+		}
+	}
+	if got := tr.Track(&GRE{AckPresent: true, Ack: 5}); got != GRESequenceAbsent || !tr.HasAck || tr.Acked != 5 {
+		t.Errorf("got %v, acked %d", got, tr.Acked)
+	}
+	if tr.Packets != 6 || tr.Lost != 2 || tr.Late != 2 {
+		t.Errorf("got %d packets, %d lost, %d late", tr.Packets, tr.Lost, tr.Late)
+	}
+}
@@ -9,6 +9,7 @@ package layers
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/google/gopacket"
@@ -16,12 +17,121 @@ import (
 
 const gtpMinimumSizeInBytes int = 8
 
+// GTPv1-U extension header types, 3GPP TS 29.281 section 5.2.1
+const (
+	GTPExtensionHeaderNone                uint8 = 0x00
+	GTPExtensionHeaderUDPPort             uint8 = 0x40
+	GTPExtensionHeaderRANContainer        uint8 = 0x81
+	GTPExtensionHeaderLongPDCPPDUNumber   uint8 = 0x82
+	GTPExtensionHeaderXwRANContainer      uint8 = 0x83
+	GTPExtensionHeaderNRRANContainer      uint8 = 0x84
+	GTPExtensionHeaderPDUSessionContainer uint8 = 0x85
+	GTPExtensionHeaderPDCPPDUNumber       uint8 = 0xc0
+)
+
 // GTPExtensionHeader is used to carry extra data and enable future extensions of the GTP  without the need to use another version number.
 type GTPExtensionHeader struct {
 	Type    uint8
 	Content []byte
 }
 
+// GTP PDU session container PDU types
+const (
+	GTPPDUSessionDownlink uint8 = 0
+	GTPPDUSessionUplink   uint8 = 1
+)
+
+// GTPPDUSessionContainer is the content of the PDU session container
+// extension header of 5G user plane packets, 3GPP TS 38.415: the QoS flow of
+// the packet.
+type GTPPDUSessionContainer struct {
+	PDUType uint8
+	// QFI is the QoS flow identifier
+	QFI uint8
+	// RQI is the reflective QoS indicator of downlink packets
+	RQI bool
+	// PPP is set if the paging policy indicator PPI of downlink packets is
+	// present
+	PPP bool
+	PPI uint8
+	// HasSequenceNumber is the SNP flag, set if the QFI sequence number is
+	// present
+	HasSequenceNumber bool
+	SequenceNumber    uint32 // 24 bits
+}
+
+func (c *GTPPDUSessionContainer) decodeFromBytes(data []byte) error {
+	if len(data) < 2 {
+		return errors.New("GTP PDU session container too short")
+	}
+	*c = GTPPDUSessionContainer{PDUType: data[0] >> 4, QFI: data[1] & 0x3f}
+	qmp := data[0]&0x08 != 0
+	offset := 2
+	switch c.PDUType {
+	case GTPPDUSessionDownlink:
+		c.HasSequenceNumber = data[0]&0x04 != 0
+		c.PPP = data[1]&0x80 != 0
+		c.RQI = data[1]&0x40 != 0
+		if c.PPP {
+			if len(data) < 3 {
+				return errors.New("GTP PDU session container too short")
+			}
+			c.PPI = data[2] >> 5
+			offset++
+		}
+		if qmp {
+			// the DL sending time stamp
+			offset += 8
+		}
+	case GTPPDUSessionUplink:
+		c.HasSequenceNumber = data[0]&0x01 != 0
+		if qmp {
+			// the DL sending, DL received and UL sending time stamps
+			offset += 24
+		}
+		for _, delay := range []bool{data[0]&0x04 != 0, data[0]&0x02 != 0} {
+			// the DL and UL delay results
+			if delay {
+				offset += 4
+			}
+		}
+	default:
+		return nil
+	}
+	if c.HasSequenceNumber {
+		if len(data) < offset+3 {
+			return errors.New("GTP PDU session container too short")
+		}
+		c.SequenceNumber = uint32(data[offset])<<16 | uint32(data[offset+1])<<8 | uint32(data[offset+2])
+	}
+	return nil
+}
+
+// content returns the content of the extension header of c.
+func (c *GTPPDUSessionContainer) content() []byte {
+	content := []byte{c.PDUType << 4, c.QFI & 0x3f}
+	if c.PDUType == GTPPDUSessionDownlink {
+		if c.PPP {
+			content[1] |= 0x80
+		}
+		if c.RQI {
+			content[1] |= 0x40
+		}
+		if c.PPP {
+			content = append(content, c.PPI<<5)
+		}
+	}
+	if c.HasSequenceNumber {
+		if c.PDUType == GTPPDUSessionDownlink {
+			content[0] |= 0x04
+		} else {
+			content[0] |= 0x01
+		}
+		content = append(content, byte(c.SequenceNumber>>16), byte(c.SequenceNumber>>8), byte(c.SequenceNumber))
+	}
+	return content
+}
+
 // GTPv1U protocol is used to exchange user data over GTP tunnels across the Sx interfaces.
 // Defined in https://portal.3gpp.org/desktopmodules/Specifications/SpecificationDetails.aspx?specificationId=1595
 type GTPv1U struct {
@@ -38,6 +148,10 @@ type GTPv1U struct {
 	SequenceNumber      uint16
 	NPDU                uint8
 	GTPExtensionHeaders []GTPExtensionHeader
+	// PDUSessionContainer is the content of the PDU session container
+	// extension header, if present.  When serializing, it is written as
+	// the first extension header, unless GTPExtensionHeaders has one.
+	PDUSessionContainer *GTPPDUSessionContainer
 }
 
 // LayerType returns LayerTypeGTPV1U
@@ -58,13 +172,15 @@ func (g *GTPv1U) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error
 	g.ExtensionHeaderFlag = ((data[0] >> 2) & 0x01) == 1
 	g.MessageType = data[1]
 	g.MessageLength = binary.BigEndian.Uint16(data[2:4])
-	pLen := 8 + g.MessageLength
-	if uint16(dLen) < pLen {
+	pLen := 8 + int(g.MessageLength)
+	if dLen < pLen {
 		return fmt.Errorf("GTP packet too small: %d bytes", dLen)
 	}
 	//  Field used to multiplex different connections in the same GTP tunnel.
 	g.TEID = binary.BigEndian.Uint32(data[4:8])
-	cIndex := uint16(hLen)
+	g.SequenceNumber, g.NPDU = 0, 0
+	g.GTPExtensionHeaders, g.PDUSessionContainer = nil, nil
+	cIndex := hLen
 	if g.SequenceNumberFlag || g.NPDUFlag || g.ExtensionHeaderFlag {
 		hLen += 4
 		cIndex += 4
@@ -78,21 +194,30 @@ func (g *GTPv1U) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error
 			g.NPDU = data[10]
 		}
 		if g.ExtensionHeaderFlag {
-			extensionFlag := true
+			extensionFlag := data[cIndex-1] != 0
 			for extensionFlag {
+				if dLen <= cIndex {
+					return fmt.Errorf("GTP packet with small extension header: %d bytes", dLen)
+				}
 				extensionType := uint8(data[cIndex-1])
-				extensionLength := uint(data[cIndex])
+				extensionLength := int(data[cIndex])
 				if extensionLength == 0 {
 					return fmt.Errorf("GTP packet with invalid extension header")
 				}
 				// extensionLength is in 4-octet units
-				lIndex := cIndex + (uint16(extensionLength) * 4)
-				if uint16(dLen) < lIndex {
+				lIndex := cIndex + extensionLength*4
+				if dLen < lIndex {
 					return fmt.Errorf("GTP packet with small extension header: %d bytes", dLen)
 				}
 				content := data[cIndex+1 : lIndex-1]
 				eh := GTPExtensionHeader{Type: extensionType, Content: content}
 				g.GTPExtensionHeaders = append(g.GTPExtensionHeaders, eh)
+				if extensionType == GTPExtensionHeaderPDUSessionContainer && g.PDUSessionContainer == nil {
+					g.PDUSessionContainer = &GTPPDUSessionContainer{}
+					if err := g.PDUSessionContainer.decodeFromBytes(content); err != nil {
+						return err
+					}
+				}
 				cIndex = lIndex
 				// Check if coming bytes are from an extension header
 				extensionFlag = data[cIndex-1] != 0
@@ -108,16 +233,45 @@ func (g *GTPv1U) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error
 // SerializeTo writes the serialized form of this layer into the
 // SerializationBuffer, implementing gopacket.SerializableLayer.
 // See the docs for gopacket.SerializableLayer for more info.
+//
+// The content of extension headers is padded to fill their 4-octet units.
+// With FixLengths, the MessageLength is set.
 func (g *GTPv1U) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
-	data, err := b.PrependBytes(gtpMinimumSizeInBytes)
+	ehs := g.GTPExtensionHeaders
+	if g.PDUSessionContainer != nil {
+		found := false
+		for _, eh := range ehs {
+			found = found || eh.Type == GTPExtensionHeaderPDUSessionContainer
+		}
+		if !found {
+			eh := GTPExtensionHeader{Type: GTPExtensionHeaderPDUSessionContainer, Content: g.PDUSessionContainer.content()}
+			ehs = append([]GTPExtensionHeader{eh}, ehs...)
+		}
+	}
+	if len(ehs) > 0 {
+		g.ExtensionHeaderFlag = true
+	}
+	size := gtpMinimumSizeInBytes
+	if g.ExtensionHeaderFlag || g.SequenceNumberFlag || g.NPDUFlag {
+		size += 4
+	}
+	for _, eh := range ehs {
+		// extensionLength is in 4-octet units, with the length and the
+		// next extension header type
+		extensionLength := (len(eh.Content) + 2 + 3) / 4
+		if extensionLength > 0xff {
+			return fmt.Errorf("GTP extension header content of %d bytes too long", len(eh.Content))
+		}
+		size += extensionLength * 4
+	}
+	data, err := b.PrependBytes(size)
 	if err != nil {
 		return err
 	}
-	data[0] |= (g.Version << 5)
+	data[0] = (g.Version << 5)
 	data[0] |= (1 << 4)
-	if len(g.GTPExtensionHeaders) > 0 {
+	if g.ExtensionHeaderFlag {
 		data[0] |= 0x04
-		g.ExtensionHeaderFlag = true
 	}
 	if g.SequenceNumberFlag {
 		data[0] |= 0x02
@@ -125,28 +279,27 @@ func (g *GTPv1U) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Serialize
 	if g.NPDUFlag {
 		data[0] |= 0x01
 	}
+	if opts.FixLengths {
+		g.MessageLength = uint16(len(b.Bytes()) - gtpMinimumSizeInBytes)
+	}
 	data[1] = g.MessageType
 	binary.BigEndian.PutUint16(data[2:4], g.MessageLength)
 	binary.BigEndian.PutUint32(data[4:8], g.TEID)
-	if g.ExtensionHeaderFlag || g.SequenceNumberFlag || g.NPDUFlag {
-		data, err := b.AppendBytes(4)
-		if err != nil {
-			return err
-		}
-		binary.BigEndian.PutUint16(data[:2], g.SequenceNumber)
-		data[2] = g.NPDU
-		for _, eh := range g.GTPExtensionHeaders {
-			data[len(data)-1] = eh.Type
-			lContent := len(eh.Content)
-			// extensionLength is in 4-octet units
-			extensionLength := (lContent + 2) / 4
-			// Get two extra byte for the next extension header type and length
-			data, err = b.AppendBytes(lContent + 2)
-			if err != nil {
-				return err
+	if size > gtpMinimumSizeInBytes {
+		binary.BigEndian.PutUint16(data[8:10], g.SequenceNumber)
+		data[10] = g.NPDU
+		data[11] = GTPExtensionHeaderNone
+		cIndex := 12
+		for _, eh := range ehs {
+			data[cIndex-1] = eh.Type
+			extensionLength := (len(eh.Content) + 2 + 3) / 4
+			lIndex := cIndex + extensionLength*4
+			data[cIndex] = byte(extensionLength)
+			n := copy(data[cIndex+1:lIndex-1], eh.Content)
+			for i := cIndex + 1 + n; i < lIndex; i++ {
+				data[i] = 0
 			}
-			data[0] = byte(extensionLength)
-			copy(data[1:lContent+1], eh.Content)
+			cIndex = lIndex
 		}
 	}
 	return nil
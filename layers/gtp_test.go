@@ -156,3 +156,58 @@ func TestGTPPacketWithEH(t *testing.T) {
 	}
 
 }
+
+func TestGTPPDUSessionContainer(t *testing.T) {
+	inner, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 53, DstPort: 53}).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &GTPv1U{
+		Version:     1,
+		MessageType: 255,
+		TEID:        0x1234,
+		PDUSessionContainer: &GTPPDUSessionContainer{
+			PDUType: GTPPDUSessionDownlink, QFI: 9, RQI: true, PPP: true, PPI: 5, HasSequenceNumber: true, SequenceNumber: 0x010203,
+		},
+		GTPExtensionHeaders: []GTPExtensionHeader{{Type: GTPExtensionHeaderUDPPort, Content: []byte{0x08, 0x68}}},
+	}
+	data, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 2152, DstPort: 2152}).Layer(g).Payload(inner).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeGTPv1U, LayerTypeIPv4, LayerTypeUDP}, t)
+	got := p.Layer(LayerTypeGTPv1U).(*GTPv1U)
+	if !got.ExtensionHeaderFlag || len(got.GTPExtensionHeaders) != 2 || int(got.MessageLength) != len(got.Contents)-8+len(inner) {
+		t.Fatalf("unexpected GTP header %#v", got)
+	}
+	if got.GTPExtensionHeaders[0].Type != GTPExtensionHeaderPDUSessionContainer || len(got.GTPExtensionHeaders[0].Content) != 6 {
+		t.Errorf("unexpected extension header %#v", got.GTPExtensionHeaders[0])
+	}
+	if got.PDUSessionContainer == nil || *got.PDUSessionContainer != *g.PDUSessionContainer {
+		t.Errorf("unexpected PDU session container %#v", got.PDUSessionContainer)
+	}
+
+	// an uplink container, with time stamps
+	c := &GTPPDUSessionContainer{}
+	content := append([]byte{0x19, 0x05}, make([]byte, 24)...)
+	if err := c.decodeFromBytes(append(content, 0x0a, 0x0b, 0x0c, 0, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if *c != (GTPPDUSessionContainer{PDUType: GTPPDUSessionUplink, QFI: 5, HasSequenceNumber: true, SequenceNumber: 0x0a0b0c}) {
+		t.Errorf("unexpected uplink PDU session container %#v", c)
+	}
+
+	for _, data := range [][]byte{
+		{0x34, 0xff, 0x00, 0x08, 0, 0, 0, 1, 0, 0, 0, 0x85, 1},
+		{0x34, 0xff, 0x00, 0x08, 0, 0, 0, 1, 0, 0, 0, 0x85, 1, 0x00, 0x09, 0x85},
+		{0x34, 0xff, 0x00, 0x08, 0, 0, 0, 1, 0, 0, 0, 0x85, 1, 0x04, 0x09, 0},
+	} {
+		if err := (&GTPv1U{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
@@ -0,0 +1,535 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/gopacket"
+)
+
+// GTPv2MessageType is the type of a GTPv2-C message.
+type GTPv2MessageType uint8
+
+// Some GTPv2-C message types, 3GPP TS 29.274 section 6.1
+const (
+	GTPv2EchoRequest                  GTPv2MessageType = 1
+	GTPv2EchoResponse                 GTPv2MessageType = 2
+	GTPv2VersionNotSupported          GTPv2MessageType = 3
+	GTPv2CreateSessionRequest         GTPv2MessageType = 32
+	GTPv2CreateSessionResponse        GTPv2MessageType = 33
+	GTPv2ModifyBearerRequest          GTPv2MessageType = 34
+	GTPv2ModifyBearerResponse         GTPv2MessageType = 35
+	GTPv2DeleteSessionRequest         GTPv2MessageType = 36
+	GTPv2DeleteSessionResponse        GTPv2MessageType = 37
+	GTPv2ModifyBearerCommand          GTPv2MessageType = 64
+	GTPv2ModifyBearerFailure          GTPv2MessageType = 65
+	GTPv2DeleteBearerCommand          GTPv2MessageType = 66
+	GTPv2DeleteBearerFailure          GTPv2MessageType = 67
+	GTPv2CreateBearerRequest          GTPv2MessageType = 95
+	GTPv2CreateBearerResponse         GTPv2MessageType = 96
+	GTPv2UpdateBearerRequest          GTPv2MessageType = 97
+	GTPv2UpdateBearerResponse         GTPv2MessageType = 98
+	GTPv2DeleteBearerRequest          GTPv2MessageType = 99
+	GTPv2DeleteBearerResponse         GTPv2MessageType = 100
+	GTPv2ReleaseAccessBearersRequest  GTPv2MessageType = 170
+	GTPv2ReleaseAccessBearersResponse GTPv2MessageType = 171
+	GTPv2DownlinkDataNotification     GTPv2MessageType = 176
+	GTPv2DownlinkDataNotificationAck  GTPv2MessageType = 177
+)
+
+var gtpv2MessageTypeNames = map[GTPv2MessageType]string{
+	GTPv2EchoRequest:                  "EchoRequest",
+	GTPv2EchoResponse:                 "EchoResponse",
+	GTPv2VersionNotSupported:          "VersionNotSupported",
+	GTPv2CreateSessionRequest:         "CreateSessionRequest",
+	GTPv2CreateSessionResponse:        "CreateSessionResponse",
+	GTPv2ModifyBearerRequest:          "ModifyBearerRequest",
+	GTPv2ModifyBearerResponse:         "ModifyBearerResponse",
+	GTPv2DeleteSessionRequest:         "DeleteSessionRequest",
+	GTPv2DeleteSessionResponse:        "DeleteSessionResponse",
+	GTPv2ModifyBearerCommand:          "ModifyBearerCommand",
+	GTPv2ModifyBearerFailure:          "ModifyBearerFailureIndication",
+	GTPv2DeleteBearerCommand:          "DeleteBearerCommand",
+	GTPv2DeleteBearerFailure:          "DeleteBearerFailureIndication",
+	GTPv2CreateBearerRequest:          "CreateBearerRequest",
+	GTPv2CreateBearerResponse:         "CreateBearerResponse",
+	GTPv2UpdateBearerRequest:          "UpdateBearerRequest",
+	GTPv2UpdateBearerResponse:         "UpdateBearerResponse",
+	GTPv2DeleteBearerRequest:          "DeleteBearerRequest",
+	GTPv2DeleteBearerResponse:         "DeleteBearerResponse",
+	GTPv2ReleaseAccessBearersRequest:  "ReleaseAccessBearersRequest",
+	GTPv2ReleaseAccessBearersResponse: "ReleaseAccessBearersResponse",
+	GTPv2DownlinkDataNotification:     "DownlinkDataNotification",
+	GTPv2DownlinkDataNotificationAck:  "DownlinkDataNotificationAcknowledge",
+}
+
+func (t GTPv2MessageType) String() string {
+	if name, ok := gtpv2MessageTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("GTPv2MessageType(%d)", uint8(t))
+}
+
+// GTPv2IEType is the type of a GTPv2-C information element.
+type GTPv2IEType uint8
+
+// Some GTPv2-C IE types, 3GPP TS 29.274 section 8.1
+const (
+	GTPv2IEIMSI                GTPv2IEType = 1
+	GTPv2IECause               GTPv2IEType = 2
+	GTPv2IERecovery            GTPv2IEType = 3
+	GTPv2IEAPN                 GTPv2IEType = 71
+	GTPv2IEAMBR                GTPv2IEType = 72
+	GTPv2IEEBI                 GTPv2IEType = 73
+	GTPv2IEMEI                 GTPv2IEType = 75
+	GTPv2IEMSISDN              GTPv2IEType = 76
+	GTPv2IEIndication          GTPv2IEType = 77
+	GTPv2IEPCO                 GTPv2IEType = 78
+	GTPv2IEPAA                 GTPv2IEType = 79
+	GTPv2IEBearerQoS           GTPv2IEType = 80
+	GTPv2IERATType             GTPv2IEType = 82
+	GTPv2IEServingNetwork      GTPv2IEType = 83
+	GTPv2IEULI                 GTPv2IEType = 86
+	GTPv2IEFTEID               GTPv2IEType = 87
+	GTPv2IEBearerContext       GTPv2IEType = 93
+	GTPv2IEChargingID          GTPv2IEType = 94
+	GTPv2IEPDNType             GTPv2IEType = 99
+	GTPv2IEPDNConnection       GTPv2IEType = 109
+	GTPv2IEAPNRestriction      GTPv2IEType = 127
+	GTPv2IESelectionMode       GTPv2IEType = 128
+	GTPv2IEOverloadControlInfo GTPv2IEType = 180
+	GTPv2IELoadControlInfo     GTPv2IEType = 181
+	GTPv2IERemoteUEContext     GTPv2IEType = 191
+	GTPv2IEPrivateExtension    GTPv2IEType = 255
+)
+
+var gtpv2IETypeNames = map[GTPv2IEType]string{
+	GTPv2IEIMSI:                "IMSI",
+	GTPv2IECause:               "Cause",
+	GTPv2IERecovery:            "Recovery",
+	GTPv2IEAPN:                 "APN",
+	GTPv2IEAMBR:                "AMBR",
+	GTPv2IEEBI:                 "EBI",
+	GTPv2IEMEI:                 "MEI",
+	GTPv2IEMSISDN:              "MSISDN",
+	GTPv2IEIndication:          "Indication",
+	GTPv2IEPCO:                 "PCO",
+	GTPv2IEPAA:                 "PAA",
+	GTPv2IEBearerQoS:           "BearerQoS",
+	GTPv2IERATType:             "RATType",
+	GTPv2IEServingNetwork:      "ServingNetwork",
+	GTPv2IEULI:                 "ULI",
+	GTPv2IEFTEID:               "F-TEID",
+	GTPv2IEBearerContext:       "BearerContext",
+	GTPv2IEChargingID:          "ChargingID",
+	GTPv2IEPDNType:             "PDNType",
+	GTPv2IEPDNConnection:       "PDNConnection",
+	GTPv2IEAPNRestriction:      "APNRestriction",
+	GTPv2IESelectionMode:       "SelectionMode",
+	GTPv2IEOverloadControlInfo: "OverloadControlInformation",
+	GTPv2IELoadControlInfo:     "LoadControlInformation",
+	GTPv2IERemoteUEContext:     "RemoteUEContext",
+	GTPv2IEPrivateExtension:    "PrivateExtension",
+}
+
+func (t GTPv2IEType) String() string {
+	if name, ok := gtpv2IETypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("GTPv2IEType(%d)", uint8(t))
+}
+
+// IsGrouped returns true for the types of the grouped IEs, whose value is
+// a list of IEs.
+func (t GTPv2IEType) IsGrouped() bool {
+	switch t {
+	case GTPv2IEBearerContext, GTPv2IEPDNConnection, GTPv2IEOverloadControlInfo, GTPv2IELoadControlInfo, GTPv2IERemoteUEContext:
+		return true
+	}
+	return false
+}
+
+// GTPv2IE is an information element of a GTPv2-C message.  Its value is
+// decoded by the methods of its type, e.g. IMSI or FTEID.
+type GTPv2IE struct {
+	Type     GTPv2IEType
+	Length   uint16
+	Instance uint8
+	Value    []byte
+	// IEs are the IEs of grouped IEs, such as bearer contexts
+	IEs []GTPv2IE
+}
+
+// gtpv2IE returns the first IE of ies of type t and instance, or nil.
+func gtpv2IE(ies []GTPv2IE, t GTPv2IEType, instance uint8) *GTPv2IE {
+	for i := range ies {
+		if ies[i].Type == t && ies[i].Instance == instance {
+			return &ies[i]
+		}
+	}
+	return nil
+}
+
+// IE returns the first IE of the grouped IE of type t and instance, or nil.
+func (ie *GTPv2IE) IE(t GTPv2IEType, instance uint8) *GTPv2IE {
+	return gtpv2IE(ie.IEs, t, instance)
+}
+
+var errGTPv2IEType = errors.New("GTPv2 IE of another type")
+
+// gtpv2TBCD decodes telephony BCD digits, filled with 0xf.
+func gtpv2TBCD(data []byte) string {
+	digits := make([]byte, 0, 2*len(data))
+	for _, b := range data {
+		for _, d := range []byte{b & 0x0f, b >> 4} {
+			if d == 0x0f {
+				return string(digits)
+			}
+			digits = append(digits, "0123456789*#abc"[d])
+		}
+	}
+	return string(digits)
+}
+
+// IMSI returns the IMSI of IMSI IEs.  MSISDN and MEI IEs are encoded the
+// same way.
+func (ie *GTPv2IE) IMSI() (string, error) {
+	switch ie.Type {
+	case GTPv2IEIMSI, GTPv2IEMSISDN, GTPv2IEMEI:
+		return gtpv2TBCD(ie.Value), nil
+	}
+	return "", errGTPv2IEType
+}
+
+// APN returns the access point name of APN IEs, with dots between its
+// labels.
+func (ie *GTPv2IE) APN() (string, error) {
+	if ie.Type != GTPv2IEAPN {
+		return "", errGTPv2IEType
+	}
+	var labels []string
+	for data := ie.Value; len(data) > 0; {
+		n := int(data[0])
+		if len(data) < 1+n {
+			return "", errors.New("GTPv2 APN label too long")
+		}
+		labels = append(labels, string(data[1:1+n]))
+		data = data[1+n:]
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// GTPv2FTEID is the value of a fully qualified TEID IE: the TEID or GRE
+// key of an interface, and its addresses.
+type GTPv2FTEID struct {
+	InterfaceType uint8
+	TEID          uint32
+	IPv4, IPv6    net.IP
+}
+
+// FTEID returns the value of F-TEID IEs.
+func (ie *GTPv2IE) FTEID() (GTPv2FTEID, error) {
+	var f GTPv2FTEID
+	if ie.Type != GTPv2IEFTEID {
+		return f, errGTPv2IEType
+	}
+	data := ie.Value
+	if len(data) < 5 {
+		return f, errors.New("GTPv2 F-TEID too short")
+	}
+	f.InterfaceType = data[0] & 0x3f
+	f.TEID = binary.BigEndian.Uint32(data[1:5])
+	data = data[5:]
+	if ie.Value[0]&0x80 != 0 {
+		if len(data) < 4 {
+			return f, errors.New("GTPv2 F-TEID too short")
+		}
+		f.IPv4 = net.IP(data[:4])
+		data = data[4:]
+	}
+	if ie.Value[0]&0x40 != 0 {
+		if len(data) < 16 {
+			return f, errors.New("GTPv2 F-TEID too short")
+		}
+		f.IPv6 = net.IP(data[:16])
+	}
+	return f, nil
+}
+
+// GTPv2LocationID is an identity of the location of a user, in a ULI IE.
+type GTPv2LocationID struct {
+	MCC, MNC string
+	// Area is the LAC of CGIs, SAIs, RAIs and LAIs, or the TAC of TAIs
+	Area uint16
+	// ID is the CI of CGIs, the SAC of SAIs, the RAC of RAIs, or the ECI of
+	// ECGIs
+	ID uint32
+}
+
+// GTPv2ULI is the value of a user location information IE: the identities
+// present.
+type GTPv2ULI struct {
+	CGI, SAI, RAI, TAI, ECGI, LAI *GTPv2LocationID
+}
+
+// gtpv2PLMN decodes the MCC and MNC of a PLMN identity.
+func gtpv2PLMN(data []byte) (mcc, mnc string) {
+	mcc = gtpv2TBCD([]byte{data[0], data[1]&0x0f | 0xf0})
+	mnc = gtpv2TBCD([]byte{data[2], data[1]>>4 | 0xf0})
+	return
+}
+
+// ULI returns the value of ULI IEs.
+func (ie *GTPv2IE) ULI() (GTPv2ULI, error) {
+	var u GTPv2ULI
+	if ie.Type != GTPv2IEULI {
+		return u, errGTPv2IEType
+	}
+	if len(ie.Value) < 1 {
+		return u, errors.New("GTPv2 ULI too short")
+	}
+	flags, data := ie.Value[0], ie.Value[1:]
+	for _, f := range []struct {
+		flag   byte
+		length int
+		id     **GTPv2LocationID
+	}{
+		{0x01, 7, &u.CGI},
+		{0x02, 7, &u.SAI},
+		{0x04, 7, &u.RAI},
+		{0x08, 5, &u.TAI},
+		{0x10, 7, &u.ECGI},
+		{0x20, 5, &u.LAI},
+	} {
+		if flags&f.flag == 0 {
+			continue
+		}
+		if len(data) < f.length {
+			return u, errors.New("GTPv2 ULI too short")
+		}
+		id := &GTPv2LocationID{}
+		id.MCC, id.MNC = gtpv2PLMN(data)
+		switch f.flag {
+		case 0x10:
+			id.ID = binary.BigEndian.Uint32(data[3:7]) & 0x0fffffff
+		case 0x04:
+			id.Area = binary.BigEndian.Uint16(data[3:5])
+			id.ID = uint32(data[5])
+		default:
+			id.Area = binary.BigEndian.Uint16(data[3:5])
+			if f.length == 7 {
+				id.ID = uint32(binary.BigEndian.Uint16(data[5:7]))
+			}
+		}
+		*f.id = id
+		data = data[f.length:]
+	}
+	return u, nil
+}
+
+// GTPv2C is a GTPv2-C header, 3GPP TS 29.274, carrying the control messages
+// of the EPC, and their IEs.  Piggybacked messages are decoded as the next
+// layer.
+type GTPv2C struct {
+	BaseLayer
+	Version uint8
+	// Piggybacked is the P flag, set if another message follows this one
+	Piggybacked bool
+	// HasTEID is the T flag
+	HasTEID bool
+	// HasPriority is the MP flag
+	HasPriority    bool
+	MessageType    GTPv2MessageType
+	MessageLength  uint16
+	TEID           uint32
+	SequenceNumber uint32 // 24 bits
+	Priority       uint8  // 4 bits
+	IEs            []GTPv2IE
+}
+
+// LayerType returns LayerTypeGTPv2C.
+func (g *GTPv2C) LayerType() gopacket.LayerType { return LayerTypeGTPv2C }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (g *GTPv2C) CanDecode() gopacket.LayerClass { return LayerTypeGTPv2C }
+
+// NextLayerType returns LayerTypeGTPv2C for piggybacked messages.
+func (g *GTPv2C) NextLayerType() gopacket.LayerType {
+	if g.Piggybacked && len(g.Payload) > 0 {
+		return LayerTypeGTPv2C
+	}
+	return gopacket.LayerTypeZero
+}
+
+// IE returns the first IE of type t and instance, or nil.
+func (g *GTPv2C) IE(t GTPv2IEType, instance uint8) *GTPv2IE {
+	return gtpv2IE(g.IEs, t, instance)
+}
+
+func decodeGTPv2IEs(data []byte, ies []GTPv2IE) ([]GTPv2IE, error) {
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return ies, errors.New("GTPv2 IE too short")
+		}
+		ie := GTPv2IE{
+			Type:     GTPv2IEType(data[0]),
+			Length:   binary.BigEndian.Uint16(data[1:3]),
+			Instance: data[3] & 0x0f,
+		}
+		if len(data) < 4+int(ie.Length) {
+			return ies, errors.New("GTPv2 IE too short")
+		}
+		ie.Value = data[4 : 4+int(ie.Length)]
+		if ie.Type.IsGrouped() {
+			var err error
+			if ie.IEs, err = decodeGTPv2IEs(ie.Value, nil); err != nil {
+				return ies, err
+			}
+		}
+		ies = append(ies, ie)
+		data = data[4+int(ie.Length):]
+	}
+	return ies, nil
+}
+
+// DecodeFromBytes decodes the slice into the GTPv2C struct.
+func (g *GTPv2C) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 8 {
+		df.SetTruncated()
+		return errors.New("GTPv2 header too short")
+	}
+	g.Version = data[0] >> 5
+	if g.Version != 2 {
+		return fmt.Errorf("invalid GTPv2 version %d", g.Version)
+	}
+	g.Piggybacked = data[0]&0x10 != 0
+	g.HasTEID = data[0]&0x08 != 0
+	g.HasPriority = data[0]&0x04 != 0
+	g.MessageType = GTPv2MessageType(data[1])
+	g.MessageLength = binary.BigEndian.Uint16(data[2:4])
+	g.TEID, g.Priority = 0, 0
+	hlen := 8
+	if g.HasTEID {
+		hlen = 12
+	}
+	length := 4 + int(g.MessageLength)
+	if length < hlen {
+		return fmt.Errorf("invalid GTPv2 message length %d", g.MessageLength)
+	}
+	if len(data) < length {
+		df.SetTruncated()
+		return errors.New("GTPv2 message too short")
+	}
+	offset := 4
+	if g.HasTEID {
+		g.TEID = binary.BigEndian.Uint32(data[4:8])
+		offset = 8
+	}
+	g.SequenceNumber = binary.BigEndian.Uint32(data[offset:offset+4]) >> 8
+	if g.HasPriority {
+		g.Priority = data[offset+3] >> 4
+	}
+	var err error
+	if g.IEs, err = decodeGTPv2IEs(data[hlen:length], g.IEs[:0]); err != nil {
+		return err
+	}
+	g.Contents = data[:length]
+	g.Payload = data[length:]
+	return nil
+}
+
+// decodeGTPv2C decodes GTPv2-C messages, and leaves the GTPv1-C messages
+// sharing their port as payload.
+func decodeGTPv2C(data []byte, p gopacket.PacketBuilder) error {
+	if len(data) > 0 && data[0]>>5 == 1 {
+		return p.NextDecoder(gopacket.LayerTypePayload)
+	}
+	return decodingLayerDecoder(&GTPv2C{}, data, p)
+}
+
+func serializeGTPv2IEs(ies []GTPv2IE, fix bool) ([]byte, error) {
+	var data []byte
+	for i := range ies {
+		ie := &ies[i]
+		value := ie.Value
+		if len(ie.IEs) > 0 {
+			var err error
+			if value, err = serializeGTPv2IEs(ie.IEs, fix); err != nil {
+				return nil, err
+			}
+		}
+		if fix {
+			if len(value) > 0xffff {
+				return nil, fmt.Errorf("GTPv2 IE of %d bytes too long", len(value))
+			}
+			ie.Length = uint16(len(value))
+		}
+		if int(ie.Length) != len(value) {
+			return nil, fmt.Errorf("GTPv2 IE length %d for %d bytes", ie.Length, len(value))
+		}
+		data = append(data, byte(ie.Type), byte(ie.Length>>8), byte(ie.Length), ie.Instance&0x0f)
+		data = append(data, value...)
+	}
+	return data, nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.  The value
+// of grouped IEs with IEs is serialized from them.  With FixLengths, the
+// lengths of the IEs and of the message are set.
+func (g *GTPv2C) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	ies, err := serializeGTPv2IEs(g.IEs, opts.FixLengths)
+	if err != nil {
+		return err
+	}
+	hlen := 8
+	if g.HasTEID {
+		hlen = 12
+	}
+	if opts.FixLengths {
+		if hlen-4+len(ies) > 0xffff {
+			return errors.New("GTPv2 message too long")
+		}
+		g.MessageLength = uint16(hlen - 4 + len(ies))
+	}
+	if g.SequenceNumber >= 1<<24 {
+		return fmt.Errorf("GTPv2 sequence number %#x exceeds max for 24-bit uint", g.SequenceNumber)
+	}
+	bytes, err := b.PrependBytes(hlen + len(ies))
+	if err != nil {
+		return err
+	}
+	bytes[0] = (g.Version & 0x7) << 5
+	if g.Piggybacked {
+		bytes[0] |= 0x10
+	}
+	if g.HasTEID {
+		bytes[0] |= 0x08
+	}
+	if g.HasPriority {
+		bytes[0] |= 0x04
+	}
+	bytes[1] = uint8(g.MessageType)
+	binary.BigEndian.PutUint16(bytes[2:4], g.MessageLength)
+	offset := 4
+	if g.HasTEID {
+		binary.BigEndian.PutUint32(bytes[4:8], g.TEID)
+		offset = 8
+	}
+	spare := uint32(0)
+	if g.HasPriority {
+		spare = uint32(g.Priority&0x0f) << 4
+	}
+	binary.BigEndian.PutUint32(bytes[offset:offset+4], g.SequenceNumber<<8|spare)
+	copy(bytes[hlen:], ies)
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestGTPv2C(t *testing.T) {
+	// a create session request, with a bearer context
+	ulis := []byte{0x18, 0x12, 0xf4, 0x10, 0x00, 0x01, 0x12, 0xf4, 0x10, 0x01, 0x23, 0x45, 0x67}
+	g := &GTPv2C{
+		Version:        2,
+		HasTEID:        true,
+		MessageType:    GTPv2CreateSessionRequest,
+		SequenceNumber: 0x123456,
+		IEs: []GTPv2IE{
+			{Type: GTPv2IEIMSI, Value: []byte{0x21, 0x43, 0x65, 0x87, 0x09, 0x21, 0x43, 0xf5}},
+			{Type: GTPv2IEULI, Value: ulis},
+			{Type: GTPv2IEFTEID, Value: []byte{0x8a, 0xde, 0xad, 0xbe, 0xef, 10, 0, 0, 1}},
+			{Type: GTPv2IEAPN, Value: []byte("\x08internet\x03mnc\x03mcc\x04gprs")},
+			{Type: GTPv2IEBearerContext, IEs: []GTPv2IE{
+				{Type: GTPv2IEEBI, Value: []byte{5}},
+				{Type: GTPv2IEFTEID, Instance: 2, Value: []byte{0x84, 0, 0, 0, 1, 10, 0, 0, 2}},
+			}},
+		},
+	}
+	data, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 2123, DstPort: 2123}).Layer(g).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeGTPv2C}, t)
+	got := p.Layer(LayerTypeGTPv2C).(*GTPv2C)
+	if got.MessageType != GTPv2CreateSessionRequest || got.MessageType.String() != "CreateSessionRequest" || got.SequenceNumber != 0x123456 || int(got.MessageLength) != len(got.Contents)-4 || len(got.IEs) != 5 {
+		t.Fatalf("unexpected message %#v", got)
+	}
+	if imsi, err := got.IE(GTPv2IEIMSI, 0).IMSI(); err != nil || imsi != "123456789012345" {
+		t.Errorf("unexpected IMSI %q %v", imsi, err)
+	}
+	uli, err := got.IE(GTPv2IEULI, 0).ULI()
+	if err != nil || uli.TAI == nil || uli.ECGI == nil || uli.CGI != nil {
+		t.Fatalf("unexpected ULI %#v %v", uli, err)
+	}
+	if *uli.TAI != (GTPv2LocationID{MCC: "214", MNC: "01", Area: 1}) || *uli.ECGI != (GTPv2LocationID{MCC: "214", MNC: "01", ID: 0x1234567}) {
+		t.Errorf("unexpected ULI %#v %#v", uli.TAI, uli.ECGI)
+	}
+	if f, err := got.IE(GTPv2IEFTEID, 0).FTEID(); err != nil || f.InterfaceType != 10 || f.TEID != 0xdeadbeef || !f.IPv4.Equal(net.IPv4(10, 0, 0, 1)) || f.IPv6 != nil {
+		t.Errorf("unexpected F-TEID %#v %v", f, err)
+	}
+	if apn, err := got.IE(GTPv2IEAPN, 0).APN(); err != nil || apn != "internet.mnc.mcc.gprs" {
+		t.Errorf("unexpected APN %q %v", apn, err)
+	}
+	bearer := got.IE(GTPv2IEBearerContext, 0)
+	if bearer == nil || len(bearer.IEs) != 2 || bearer.Length != 18 {
+		t.Fatalf("unexpected bearer context %#v", bearer)
+	}
+	if f, err := bearer.IE(GTPv2IEFTEID, 2).FTEID(); err != nil || f.TEID != 1 || f.InterfaceType != 4 {
+		t.Errorf("unexpected bearer F-TEID %#v %v", f, err)
+	}
+	if _, err := bearer.IE(GTPv2IEEBI, 0).IMSI(); err == nil {
+		t.Error("no error for IMSI of EBI")
+	}
+
+	// a piggybacked echo request, without TEID
+	echo := []byte{0x58, 0x01, 0x00, 0x08, 0, 0, 0, 1, 0, 0, 2, 0, 0x40, 0x01, 0x00, 0x04, 0, 0, 1, 0}
+	p = gopacket.NewPacket(echo, LayerTypeGTPv2C, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeGTPv2C, LayerTypeGTPv2C}, t)
+	if g := p.Layers()[1].(*GTPv2C); g.HasTEID || g.SequenceNumber != 1 || g.MessageType != GTPv2EchoRequest {
+		t.Errorf("unexpected piggybacked message %#v", g)
+	}
+
+	// GTPv1-C shares the port
+	v1, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 2123, DstPort: 2123}).Payload([]byte{0x32, 0x01, 0, 4, 0, 0, 0, 0, 0, 1, 0, 0}).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLayers(gopacket.NewPacket(v1, LayerTypeIPv4, gopacket.Default), []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, gopacket.LayerTypePayload}, t)
+
+	for _, data := range [][]byte{
+		{0x48, 0x01, 0x00},
+		{0x48, 0x01, 0x00, 0x04, 0, 0, 0, 1},
+		{0x48, 0x01, 0x00, 0x0c, 0, 0, 0, 1, 0, 0, 1, 0},
+		{0x40, 0x01, 0x00, 0x08, 0, 0, 1, 0, 0x01, 0x00, 0x04, 0},
+	} {
+		if err := (&GTPv2C{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
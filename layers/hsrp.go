@@ -0,0 +1,377 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// HSRPOpCode is the type of an HSRP message.
+type HSRPOpCode uint8
+
+// HSRPOpCode known values
+const (
+	HSRPOpCodeHello     HSRPOpCode = 0
+	HSRPOpCodeCoup      HSRPOpCode = 1
+	HSRPOpCodeResign    HSRPOpCode = 2
+	HSRPOpCodeAdvertise HSRPOpCode = 3
+)
+
+func (o HSRPOpCode) String() string {
+	switch o {
+	case HSRPOpCodeHello:
+		return "Hello"
+	case HSRPOpCodeCoup:
+		return "Coup"
+	case HSRPOpCodeResign:
+		return "Resign"
+	case HSRPOpCodeAdvertise:
+		return "Advertise"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(o))
+	}
+}
+
+// HSRPState is the state of the sending router in an HSRP group.
+type HSRPState uint8
+
+// HSRPState known values
+const (
+	HSRPStateInitial HSRPState = 0
+	HSRPStateLearn   HSRPState = 1
+	HSRPStateListen  HSRPState = 2
+	HSRPStateSpeak   HSRPState = 4
+	HSRPStateStandby HSRPState = 8
+	HSRPStateActive  HSRPState = 16
+)
+
+func (s HSRPState) String() string {
+	switch s {
+	case HSRPStateInitial:
+		return "Initial"
+	case HSRPStateLearn:
+		return "Learn"
+	case HSRPStateListen:
+		return "Listen"
+	case HSRPStateSpeak:
+		return "Speak"
+	case HSRPStateStandby:
+		return "Standby"
+	case HSRPStateActive:
+		return "Active"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(s))
+	}
+}
+
+// HSRPTLVType is the type of an HSRPv2 TLV.
+type HSRPTLVType uint8
+
+// HSRPTLVType known values
+const (
+	HSRPTLVGroupState     HSRPTLVType = 1
+	HSRPTLVInterfaceState HSRPTLVType = 2
+	HSRPTLVTextAuth       HSRPTLVType = 3
+	HSRPTLVMD5Auth        HSRPTLVType = 4
+)
+
+func (t HSRPTLVType) String() string {
+	switch t {
+	case HSRPTLVGroupState:
+		return "Group State"
+	case HSRPTLVInterfaceState:
+		return "Interface State"
+	case HSRPTLVTextAuth:
+		return "Text Authentication"
+	case HSRPTLVMD5Auth:
+		return "MD5 Authentication"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(t))
+	}
+}
+
+// HSRPTLV is an HSRP TLV not decoded into the fields of HSRP.
+type HSRPTLV struct {
+	Type  HSRPTLVType
+	Value []byte
+}
+
+// HSRPMD5Auth is the MD5 authentication TLV, appended to HSRPv1 messages or
+// part of HSRPv2 ones.
+type HSRPMD5Auth struct {
+	Algorithm uint8
+	Flags     uint16
+	IPAddress net.IP // Of the sending interface
+	KeyID     uint32
+	Digest    [16]byte
+}
+
+// HSRP lengths
+const (
+	hsrpV1Length         = 20
+	hsrpGroupStateLength = 40
+	hsrpTextAuthLength   = 8
+	hsrpMD5AuthLength    = 28
+)
+
+// HSRP is the Cisco Hot Standby Router Protocol, see RFC 2281 for version 1.
+// Version 2 messages are made of TLVs, whose group state, text and MD5
+// authentication TLVs are decoded into the fields below, and the other ones
+// into TLVs.
+type HSRP struct {
+	BaseLayer
+	Version   uint8 // 0 for HSRPv1, 2 for HSRPv2
+	OpCode    HSRPOpCode
+	State     HSRPState
+	Group     uint16 // 8 bits in HSRPv1
+	Priority  uint32 // 8 bits in HSRPv1
+	HelloTime uint32 // Milliseconds, whole seconds up to 255s in HSRPv1
+	HoldTime  uint32 // Milliseconds, whole seconds up to 255s in HSRPv1
+	VirtualIP net.IP // IPv4 or, for HSRPv2 only, IPv6
+	// Identifier is the MAC address of the sending interface, HSRPv2 only.
+	Identifier net.HardwareAddr
+	// Authentication is the clear text authentication data, up to 8 bytes.
+	// It is always present in HSRPv1 messages, and usually "cisco".
+	Authentication string
+	MD5Auth        *HSRPMD5Auth
+	TLVs           []HSRPTLV
+}
+
+// LayerType returns LayerTypeHSRP.
+func (h *HSRP) LayerType() gopacket.LayerType { return LayerTypeHSRP }
+
+func decodeHSRP(data []byte, p gopacket.PacketBuilder) error {
+	h := &HSRP{}
+	if err := h.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(h)
+	p.SetApplicationLayer(h)
+	return nil
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (h *HSRP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 2 {
+		df.SetTruncated()
+		return errors.New("HSRP packet too short")
+	}
+	*h = HSRP{TLVs: h.TLVs[:0]}
+	tlvs := data
+	if data[0] == 0 {
+		// HSRPv1 starts with its version, while HSRPv2 starts with a TLV type
+		if len(data) < hsrpV1Length {
+			df.SetTruncated()
+			return errors.New("HSRPv1 packet too short")
+		}
+		h.OpCode = HSRPOpCode(data[1])
+		h.State = HSRPState(data[2])
+		h.HelloTime = uint32(data[3]) * 1000
+		h.HoldTime = uint32(data[4]) * 1000
+		h.Priority = uint32(data[5])
+		h.Group = uint16(data[6])
+		h.Authentication = hsrpText(data[8:16])
+		h.VirtualIP = net.IP(data[16:20])
+		tlvs = data[hsrpV1Length:]
+	}
+
+	for len(tlvs) > 0 {
+		if len(tlvs) < 2 || len(tlvs) < 2+int(tlvs[1]) {
+			df.SetTruncated()
+			return errors.New("HSRP TLV truncated")
+		}
+		t, v := HSRPTLVType(tlvs[0]), tlvs[2:2+int(tlvs[1])]
+		tlvs = tlvs[2+len(v):]
+		switch {
+		case t == HSRPTLVGroupState && len(v) >= hsrpGroupStateLength && data[0] != 0:
+			h.Version = v[0]
+			h.OpCode = HSRPOpCode(v[1])
+			h.State = HSRPState(v[2])
+			h.Group = binary.BigEndian.Uint16(v[4:6])
+			h.Identifier = net.HardwareAddr(v[6:12])
+			h.Priority = binary.BigEndian.Uint32(v[12:16])
+			h.HelloTime = binary.BigEndian.Uint32(v[16:20])
+			h.HoldTime = binary.BigEndian.Uint32(v[20:24])
+			switch v[3] {
+			case 4:
+				h.VirtualIP = net.IP(v[24:28])
+			case 6:
+				h.VirtualIP = net.IP(v[24:40])
+			default:
+				return fmt.Errorf("invalid HSRP IP version %d", v[3])
+			}
+		case t == HSRPTLVTextAuth && len(v) == hsrpTextAuthLength && data[0] != 0:
+			h.Authentication = hsrpText(v)
+		case t == HSRPTLVMD5Auth && len(v) >= hsrpMD5AuthLength:
+			h.MD5Auth = &HSRPMD5Auth{
+				Algorithm: v[0],
+				Flags:     binary.BigEndian.Uint16(v[2:4]),
+				IPAddress: net.IP(v[4:8]),
+				KeyID:     binary.BigEndian.Uint32(v[8:12]),
+			}
+			copy(h.MD5Auth.Digest[:], v[12:28])
+		case t == HSRPTLVGroupState || t == HSRPTLVTextAuth || t == HSRPTLVMD5Auth:
+			return fmt.Errorf("invalid HSRP %v TLV length %d", t, len(v))
+		default:
+			h.TLVs = append(h.TLVs, HSRPTLV{Type: t, Value: v})
+		}
+	}
+	h.Contents = data
+	return nil
+}
+
+// hsrpText returns the text of null padded data.
+func hsrpText(data []byte) string {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	return string(data)
+}
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (h *HSRP) CanDecode() gopacket.LayerClass {
+	return LayerTypeHSRP
+}
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (h *HSRP) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypeZero
+}
+
+// Payload returns nil, since HSRP messages do not carry a payload.
+func (h *HSRP) Payload() []byte {
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+func (h *HSRP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if len(h.Authentication) > hsrpTextAuthLength {
+		return fmt.Errorf("HSRP authentication %q too long", h.Authentication)
+	}
+	for _, tlv := range h.TLVs {
+		if len(tlv.Value) > 0xff {
+			return fmt.Errorf("HSRP %v TLV too long", tlv.Type)
+		}
+	}
+	// Prepend the TLVs last first
+	for i := len(h.TLVs) - 1; i >= 0; i-- {
+		tlv := h.TLVs[i]
+		bytes, err := b.PrependBytes(2 + len(tlv.Value))
+		if err != nil {
+			return err
+		}
+		bytes[0], bytes[1] = uint8(tlv.Type), uint8(len(tlv.Value))
+		copy(bytes[2:], tlv.Value)
+	}
+	if a := h.MD5Auth; a != nil {
+		bytes, err := b.PrependBytes(2 + hsrpMD5AuthLength)
+		if err != nil {
+			return err
+		}
+		bytes[0], bytes[1] = uint8(HSRPTLVMD5Auth), hsrpMD5AuthLength
+		bytes[2], bytes[3] = a.Algorithm, 0
+		binary.BigEndian.PutUint16(bytes[4:6], a.Flags)
+		if err := hsrpPutIPv4(bytes[6:10], a.IPAddress); err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint32(bytes[10:14], a.KeyID)
+		copy(bytes[14:30], a.Digest[:])
+	}
+
+	if h.Version < 2 {
+		return h.serializeV1(b)
+	}
+	if h.Authentication != "" {
+		bytes, err := b.PrependBytes(2 + hsrpTextAuthLength)
+		if err != nil {
+			return err
+		}
+		bytes[0], bytes[1] = uint8(HSRPTLVTextAuth), hsrpTextAuthLength
+		hsrpPutText(bytes[2:], h.Authentication)
+	}
+	bytes, err := b.PrependBytes(2 + hsrpGroupStateLength)
+	if err != nil {
+		return err
+	}
+	for i := range bytes {
+		bytes[i] = 0
+	}
+	bytes[0], bytes[1] = uint8(HSRPTLVGroupState), hsrpGroupStateLength
+	v := bytes[2:]
+	v[0], v[1], v[2] = h.Version, uint8(h.OpCode), uint8(h.State)
+	binary.BigEndian.PutUint16(v[4:6], h.Group)
+	if h.Identifier != nil && len(h.Identifier) != 6 {
+		return fmt.Errorf("invalid HSRP identifier %v", h.Identifier)
+	}
+	copy(v[6:12], h.Identifier)
+	binary.BigEndian.PutUint32(v[12:16], h.Priority)
+	binary.BigEndian.PutUint32(v[16:20], h.HelloTime)
+	binary.BigEndian.PutUint32(v[20:24], h.HoldTime)
+	if ip4 := h.VirtualIP.To4(); ip4 != nil {
+		v[3] = 4
+		copy(v[24:28], ip4)
+	} else if ip6 := h.VirtualIP.To16(); ip6 != nil {
+		v[3] = 6
+		copy(v[24:40], ip6)
+	} else {
+		return fmt.Errorf("invalid HSRP virtual IP %v", h.VirtualIP)
+	}
+	return nil
+}
+
+func (h *HSRP) serializeV1(b gopacket.SerializeBuffer) error {
+	if h.Group > 0xff || h.Priority > 0xff {
+		return fmt.Errorf("HSRPv1 group %d or priority %d too large", h.Group, h.Priority)
+	}
+	for _, t := range []uint32{h.HelloTime, h.HoldTime} {
+		if t%1000 != 0 || t/1000 > 0xff {
+			return fmt.Errorf("HSRPv1 time %dms is not a whole number of seconds up to 255s", t)
+		}
+	}
+	bytes, err := b.PrependBytes(hsrpV1Length)
+	if err != nil {
+		return err
+	}
+	bytes[0] = h.Version
+	bytes[1] = uint8(h.OpCode)
+	bytes[2] = uint8(h.State)
+	bytes[3] = uint8(h.HelloTime / 1000)
+	bytes[4] = uint8(h.HoldTime / 1000)
+	bytes[5] = uint8(h.Priority)
+	bytes[6] = uint8(h.Group)
+	bytes[7] = 0
+	hsrpPutText(bytes[8:16], h.Authentication)
+	return hsrpPutIPv4(bytes[16:20], h.VirtualIP)
+}
+
+// hsrpPutText writes s null padded to data.
+func hsrpPutText(data []byte, s string) {
+	n := copy(data, s)
+	for i := n; i < len(data); i++ {
+		data[i] = 0
+	}
+}
+
+func hsrpPutIPv4(data []byte, ip net.IP) error {
+	if ip == nil {
+		copy(data, net.IPv4zero.To4())
+		return nil
+	}
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return fmt.Errorf("invalid HSRP IPv4 address %v", ip)
+	}
+	copy(data, ip4)
+	return nil
+}
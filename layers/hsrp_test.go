@@ -0,0 +1,128 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketHSRPv1 is an HSRPv1 hello of the active router of group 1.
+var testPacketHSRPv1 = []byte{
+	0x01, 0x00, 0x5e, 0x00, 0x00, 0x02, 0x00, 0x00, 0x0c, 0x07, 0xac, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x30, 0x00, 0x00, 0x00, 0x00, 0x01, 0x11, 0x18, 0x51, 0xc0, 0xa8, 0x00, 0x02, 0xe0, 0x00,
+	0x00, 0x02, 0x07, 0xc1, 0x07, 0xc1, 0x00, 0x1c, 0x00, 0x00,
+	0x00, 0x00, 0x10, 0x03, 0x0a, 0x6e, 0x01, 0x00, 0x63, 0x69, 0x73, 0x63, 0x6f, 0x00, 0x00, 0x00,
+	0xc0, 0xa8, 0x00, 0x01,
+}
+
+func TestHSRPv1(t *testing.T) {
+	p := gopacket.NewPacket(testPacketHSRPv1, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeHSRP}, t)
+	hsrp := p.ApplicationLayer().(*HSRP)
+	want := &HSRP{
+		BaseLayer:      BaseLayer{Contents: testPacketHSRPv1[42:]},
+		OpCode:         HSRPOpCodeHello,
+		State:          HSRPStateActive,
+		Group:          1,
+		Priority:       110,
+		HelloTime:      3000,
+		HoldTime:       10000,
+		VirtualIP:      net.IP{192, 168, 0, 1},
+		Authentication: "cisco",
+	}
+	if !reflect.DeepEqual(hsrp, want) {
+		t.Errorf("HSRP mismatch:\ngot  %#v\nwant %#v", hsrp, want)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := hsrp.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testPacketHSRPv1[42:]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), testPacketHSRPv1[42:])
+	}
+
+	hsrp.HelloTime = 1500
+	if err := hsrp.SerializeTo(gopacket.NewSerializeBuffer(), gopacket.SerializeOptions{}); err == nil {
+		t.Error("HSRPv1 serialized a sub-second hello time")
+	}
+}
+
+func TestHSRPv2(t *testing.T) {
+	md5 := &HSRPMD5Auth{
+		Algorithm: 1,
+		IPAddress: net.IP{192, 0, 2, 2},
+		KeyID:     7,
+		Digest:    [16]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xab, 0xcd, 0xef, 0xfe, 0xdc, 0xba, 0x98, 0x76, 0x54, 0x32, 0x10},
+	}
+	for _, test := range []struct {
+		ip   gopacket.NetworkLayer
+		port UDPPort
+		hsrp *HSRP
+	}{
+		{
+			ip:   &IPv4{Version: 4, TTL: 1, Protocol: IPProtocolUDP, SrcIP: net.IP{192, 0, 2, 2}, DstIP: net.IP{224, 0, 0, 102}},
+			port: 1985,
+			hsrp: &HSRP{
+				Version:    2,
+				OpCode:     HSRPOpCodeHello,
+				State:      HSRPStateStandby,
+				Group:      4000,
+				Identifier: net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+				Priority:   100,
+				HelloTime:  250,
+				HoldTime:   750,
+				VirtualIP:  net.IP{192, 0, 2, 1},
+				MD5Auth:    md5,
+				TLVs:       []HSRPTLV{{Type: HSRPTLVInterfaceState, Value: []byte{0x00, 0x01, 0x00, 0x00}}},
+			},
+		},
+		{
+			ip:   &IPv6{Version: 6, HopLimit: 255, NextHeader: IPProtocolUDP, SrcIP: net.ParseIP("fe80::2"), DstIP: net.ParseIP("ff02::66")},
+			port: 2029,
+			hsrp: &HSRP{
+				Version:        2,
+				OpCode:         HSRPOpCodeCoup,
+				State:          HSRPStateSpeak,
+				Group:          1,
+				Identifier:     net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+				Priority:       200,
+				HelloTime:      3000,
+				HoldTime:       10000,
+				VirtualIP:      net.ParseIP("fe80::5:73ff:fea0:1"),
+				Authentication: "secret",
+			},
+		},
+	} {
+		udp := &UDP{SrcPort: test.port, DstPort: test.port}
+		udp.SetNetworkLayerForChecksum(test.ip)
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		if err := gopacket.SerializeLayers(buf, opts, test.ip.(gopacket.SerializableLayer), udp, test.hsrp); err != nil {
+			t.Fatal(err)
+		}
+
+		p := gopacket.NewPacket(buf.Bytes(), test.ip.LayerType(), testDecodeOptions)
+		if p.ErrorLayer() != nil {
+			t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+		}
+		checkLayers(p, []gopacket.LayerType{test.ip.LayerType(), LayerTypeUDP, LayerTypeHSRP}, t)
+		got := p.ApplicationLayer().(*HSRP)
+		test.hsrp.BaseLayer = got.BaseLayer
+		if !reflect.DeepEqual(got, test.hsrp) {
+			t.Errorf("HSRP mismatch:\ngot  %#v\nwant %#v", got, test.hsrp)
+		}
+	}
+}
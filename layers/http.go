@@ -0,0 +1,573 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/gopacket"
+)
+
+// HTTPBodyFraming tells how the end of the body of an HTTP/1.x message is
+// found, RFC 9112 section 6.3.
+type HTTPBodyFraming uint8
+
+// HTTP body framings.
+const (
+	// HTTPBodyNone is used by messages without a body, like most requests
+	// and responses to HEAD requests.
+	HTTPBodyNone HTTPBodyFraming = iota
+	// HTTPBodyContentLength bodies have the length of the Content-Length
+	// header.
+	HTTPBodyContentLength
+	// HTTPBodyChunked bodies use the chunked transfer coding.
+	HTTPBodyChunked
+	// HTTPBodyUntilClose bodies of responses end when the connection is
+	// closed.
+	HTTPBodyUntilClose
+)
+
+func (f HTTPBodyFraming) String() string {
+	switch f {
+	case HTTPBodyNone:
+		return "None"
+	case HTTPBodyContentLength:
+		return "ContentLength"
+	case HTTPBodyChunked:
+		return "Chunked"
+	case HTTPBodyUntilClose:
+		return "UntilClose"
+	}
+	return fmt.Sprintf("HTTPBodyFraming(%d)", f)
+}
+
+// HTTP is an HTTP/1.0 or HTTP/1.1 request or response, RFC 9112.
+//
+// HTTP isn't decoded by port, since TCP segments don't have to hold whole
+// messages; use RegisterTCPPortLayerType to decode the segments of a port
+// as HTTP.  Decoded from a packet, the layer holds the start line and the headers,
+// which have to be complete, and its payload is the part of the body in the
+// packet.  If the packet holds the whole message, Complete is set, and
+// messages pipelined after it are decoded as further HTTP layers.  Messages
+// spread over several packets are decoded by an HTTPStream from the
+// reassembled TCP stream.
+type HTTP struct {
+	BaseLayer
+	IsResponse bool
+	// Method and RequestURI are set for requests.
+	Method     string
+	RequestURI string
+	// Version is the protocol version, e.g. "HTTP/1.1".
+	Version string
+	// StatusCode and Reason are set for responses.
+	StatusCode int
+	Reason     string
+	// Headers are the header values by lower case header name.
+	Headers map[string][]string
+	// BodyFraming tells how the end of the body is found.
+	BodyFraming HTTPBodyFraming
+	// ContentLength is the length of HTTPBodyContentLength bodies.
+	ContentLength int64
+	// Body is the body with the chunked transfer coding removed.  It's only
+	// set if the message is complete.
+	Body []byte
+	// Complete is set if all of the body was decoded.
+	Complete bool
+}
+
+// LayerType returns LayerTypeHTTP.
+func (h *HTTP) LayerType() gopacket.LayerType { return LayerTypeHTTP }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (h *HTTP) CanDecode() gopacket.LayerClass { return LayerTypeHTTP }
+
+// NextLayerType returns gopacket.LayerTypePayload.
+func (h *HTTP) NextLayerType() gopacket.LayerType { return gopacket.LayerTypePayload }
+
+// Payload returns the part of the body in the decoded data, as it was
+// transmitted.
+func (h *HTTP) Payload() []byte { return h.BaseLayer.Payload }
+
+// Header returns the first value of the header with the given name, which
+// isn't case sensitive, or "" if there's no such header.
+func (h *HTTP) Header(name string) string {
+	if values := h.Headers[strings.ToLower(name)]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+var (
+	errHTTPHeaderIncomplete = errors.New("HTTP header incomplete")
+	errHTTPChunkIncomplete  = errors.New("HTTP chunk incomplete")
+)
+
+// isHTTPStart returns true if data starts like an HTTP/1.x request or
+// response.
+func isHTTPStart(data []byte) bool {
+	if bytes.HasPrefix(data, []byte("HTTP/")) {
+		return true
+	}
+	// a method is an upper case token, e.g. GET
+	for i, c := range data {
+		switch {
+		case c == ' ':
+			return i > 0
+		case c < 'A' || c > 'Z', i >= 20:
+			return false
+		}
+	}
+	return false
+}
+
+// DecodeFromBytes decodes the first HTTP message in data.  Responses are
+// decoded as responses to requests other than HEAD or CONNECT.
+func (h *HTTP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	return h.decode(data, df, "")
+}
+
+// decode decodes the first HTTP message in data.  requestMethod is the
+// method of the request a response answers, if known.
+func (h *HTTP) decode(data []byte, df gopacket.DecodeFeedback, requestMethod string) error {
+	end := bytes.Index(data, []byte("\r\n\r\n"))
+	if end < 0 {
+		*h = HTTP{Headers: map[string][]string{}}
+		df.SetTruncated()
+		return errHTTPHeaderIncomplete
+	}
+	if err := h.decodeHeader(data[:end+4], requestMethod); err != nil {
+		return err
+	}
+	return h.decodeBody(data[end+4:], false)
+}
+
+// decodeHeader decodes the start line and the headers in header, which ends
+// with the empty line.
+func (h *HTTP) decodeHeader(header []byte, requestMethod string) error {
+	*h = HTTP{Headers: map[string][]string{}}
+	lines := strings.Split(string(header[:len(header)-4]), "\r\n")
+	if err := h.parseStartLine(lines[0]); err != nil {
+		return err
+	}
+	var last string
+	for _, line := range lines[1:] {
+		if line[0] == ' ' || line[0] == '\t' {
+			// obsolete line folding
+			if last == "" {
+				return fmt.Errorf("invalid HTTP header line %q", line)
+			}
+			values := h.Headers[last]
+			values[len(values)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		colon := strings.IndexByte(line, ':')
+		if colon <= 0 {
+			return fmt.Errorf("invalid HTTP header line %q", line)
+		}
+		last = strings.ToLower(line[:colon])
+		h.Headers[last] = append(h.Headers[last], strings.TrimSpace(line[colon+1:]))
+	}
+	if err := h.setBodyFraming(requestMethod); err != nil {
+		return err
+	}
+	h.Contents = header
+	return nil
+}
+
+// parseStartLine parses the request or status line.
+func (h *HTTP) parseStartLine(line string) error {
+	parts := strings.SplitN(line, " ", 3)
+	if len(parts) < 2 {
+		return fmt.Errorf("invalid HTTP start line %q", line)
+	}
+	if strings.HasPrefix(parts[0], "HTTP/") {
+		h.IsResponse = true
+		h.Version = parts[0]
+		code, err := strconv.Atoi(parts[1])
+		if err != nil || len(parts[1]) != 3 {
+			return fmt.Errorf("invalid HTTP status code %q", parts[1])
+		}
+		h.StatusCode = code
+		if len(parts) == 3 {
+			h.Reason = parts[2]
+		}
+		return nil
+	}
+	if len(parts) != 3 || !strings.HasPrefix(parts[2], "HTTP/") {
+		return fmt.Errorf("invalid HTTP request line %q", line)
+	}
+	h.Method, h.RequestURI, h.Version = parts[0], parts[1], parts[2]
+	return nil
+}
+
+// setBodyFraming sets how the end of the body is found, RFC 9112 section
+// 6.3.
+func (h *HTTP) setBodyFraming(requestMethod string) error {
+	if h.IsResponse {
+		switch {
+		case requestMethod == "HEAD", h.StatusCode < 200, h.StatusCode == 204, h.StatusCode == 304,
+			requestMethod == "CONNECT" && h.StatusCode < 300:
+			h.BodyFraming = HTTPBodyNone
+			return nil
+		}
+	}
+	if codings := h.Headers["transfer-encoding"]; len(codings) > 0 {
+		// chunked has to be the final coding
+		last := strings.Split(codings[len(codings)-1], ",")
+		if strings.EqualFold(strings.TrimSpace(last[len(last)-1]), "chunked") {
+			h.BodyFraming = HTTPBodyChunked
+		} else if h.IsResponse {
+			h.BodyFraming = HTTPBodyUntilClose
+		} else {
+			return errors.New("HTTP request with transfer coding other than chunked")
+		}
+		return nil
+	}
+	if lengths := h.Headers["content-length"]; len(lengths) > 0 {
+		for _, l := range lengths {
+			n, err := strconv.ParseInt(strings.TrimSpace(l), 10, 64)
+			if err != nil || n < 0 || (h.BodyFraming == HTTPBodyContentLength && n != h.ContentLength) {
+				return fmt.Errorf("invalid HTTP Content-Length %q", l)
+			}
+			h.BodyFraming, h.ContentLength = HTTPBodyContentLength, n
+		}
+		return nil
+	}
+	if h.IsResponse {
+		h.BodyFraming = HTTPBodyUntilClose
+	} else {
+		h.BodyFraming = HTTPBodyNone
+	}
+	return nil
+}
+
+// decodeBody sets the payload, body and completeness from data following the
+// header.  closed tells if the connection was closed after data.
+func (h *HTTP) decodeBody(data []byte, closed bool) error {
+	switch h.BodyFraming {
+	case HTTPBodyNone:
+		h.BaseLayer.Payload, h.Complete = data[:0], true
+	case HTTPBodyContentLength:
+		if int64(len(data)) >= h.ContentLength {
+			h.BaseLayer.Payload, h.Complete = data[:h.ContentLength], true
+		} else {
+			h.BaseLayer.Payload = data
+		}
+	case HTTPBodyChunked:
+		n, body, err := decodeHTTPChunks(data)
+		switch err {
+		case nil:
+			h.BaseLayer.Payload, h.Body, h.Complete = data[:n], body, true
+			return nil
+		case errHTTPChunkIncomplete:
+			h.BaseLayer.Payload = data
+		default:
+			return err
+		}
+	case HTTPBodyUntilClose:
+		h.BaseLayer.Payload, h.Complete = data, closed
+	}
+	if h.Complete {
+		h.Body = h.BaseLayer.Payload
+	}
+	return nil
+}
+
+// decodeHTTPChunks decodes a chunked body, RFC 9112 section 7.1, returning
+// its length including the trailer section, and its data.
+func decodeHTTPChunks(data []byte) (int, []byte, error) {
+	var d httpChunkDecoder
+	n, err := d.decode(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return n, d.data(), nil
+}
+
+// httpChunkDecoder decodes a chunked body, RFC 9112 section 7.1, that may be
+// passed in parts, without decoding the chunks already decoded again.
+type httpChunkDecoder struct {
+	body []byte
+	// offset is the length of the chunks and trailer lines decoded
+	offset int
+	// need is the length of data needed to decode the next chunk
+	need int64
+	// trailer is set once the last chunk was decoded
+	trailer bool
+}
+
+// decode continues decoding the chunked body at the start of data, which
+// starts with the data of previous calls.  It returns the length of the body
+// including the trailer section, or errHTTPChunkIncomplete.
+func (d *httpChunkDecoder) decode(data []byte) (int, error) {
+	if int64(len(data)) < d.need {
+		return 0, errHTTPChunkIncomplete
+	}
+	for !d.trailer {
+		eol := bytes.Index(data[d.offset:], []byte("\r\n"))
+		if eol < 0 {
+			return 0, errHTTPChunkIncomplete
+		}
+		line := data[d.offset : d.offset+eol]
+		if ext := bytes.IndexByte(line, ';'); ext >= 0 {
+			line = line[:ext]
+		}
+		size, err := strconv.ParseInt(string(bytes.TrimSpace(line)), 16, 64)
+		if err != nil || size < 0 {
+			return 0, fmt.Errorf("invalid HTTP chunk size %q", line)
+		}
+		start := d.offset + eol + 2
+		if size == 0 {
+			d.offset, d.trailer = start, true
+			break
+		}
+		if int64(len(data)-start) < size+2 {
+			d.need = int64(start) + size + 2
+			return 0, errHTTPChunkIncomplete
+		}
+		end := start + int(size)
+		if !bytes.HasPrefix(data[end:], []byte("\r\n")) {
+			return 0, errors.New("HTTP chunk not followed by CRLF")
+		}
+		d.body = append(d.body, data[start:end]...)
+		d.offset = end + 2
+	}
+	// the trailer section ends with an empty line
+	for {
+		eol := bytes.Index(data[d.offset:], []byte("\r\n"))
+		if eol < 0 {
+			return 0, errHTTPChunkIncomplete
+		}
+		d.offset += eol + 2
+		if eol == 0 {
+			return d.offset, nil
+		}
+	}
+}
+
+// data returns the data of the chunks decoded.
+func (d *httpChunkDecoder) data() []byte {
+	if d.body == nil {
+		return []byte{}
+	}
+	return d.body
+}
+
+// decodeHTTP decodes the HTTP messages in data, which is decoded as payload
+// if it doesn't start with an HTTP message, e.g. because it continues the
+// body of a message of a previous packet.
+func decodeHTTP(data []byte, p gopacket.PacketBuilder) error {
+	if !isHTTPStart(data) {
+		return p.NextDecoder(gopacket.LayerTypePayload)
+	}
+	for len(data) > 0 {
+		h := &HTTP{}
+		if err := h.DecodeFromBytes(data, p); err != nil {
+			return err
+		}
+		p.AddLayer(h)
+		p.SetApplicationLayer(h)
+		if !h.Complete {
+			break
+		}
+		data = data[len(h.Contents)+len(h.BaseLayer.Payload):]
+		if !isHTTPStart(data) {
+			break
+		}
+	}
+	return nil
+}
+
+// HTTPStream decodes the HTTP messages sent in one direction of a TCP
+// connection, whose data is passed to Decode as it's reassembled, e.g. by a
+// reassembly.Stream.  Messages are returned once they're complete.
+//
+// To follow the requests a response answers, set the Peer of each stream to
+// the HTTPStream of the other direction, and decode requests before their
+// responses.  The responses to HEAD and CONNECT requests are decoded
+// correctly then, and the stream of a request asking to upgrade the
+// connection, e.g. to WebSocket, isn't decoded any further until the
+// response is.  If the upgrade is declined, Decode continues with the data
+// buffered, which may be nil.
+//
+// Once a response switches protocols or accepts a CONNECT request, the
+// connection no longer carries HTTP messages, and Upgraded returns true.
+// Decode then only buffers data, which Rest returns for the decoder of the
+// new protocol, e.g. a WebSocketStream.
+type HTTPStream struct {
+	// Peer is the HTTPStream of the other direction of the connection.
+	Peer *HTTPStream
+
+	buf []byte
+	// headerScan is the length of the buffer searched for the end of the
+	// header of the next message
+	headerScan int
+	// pending is the message at the start of the buffer whose header was
+	// decoded, but not all of its body
+	pending *HTTP
+	// pendingMethod is the method of the request pending answers
+	pendingMethod string
+	// chunks decodes the chunked body of pending
+	chunks httpChunkDecoder
+	// methods are the methods of the requests decoded, whose responses
+	// weren't decoded yet
+	methods []string
+	// awaitingUpgrade is set after a request to upgrade the connection
+	awaitingUpgrade bool
+	upgraded        bool
+	failed          bool
+}
+
+// Decode decodes data following the data of previous calls, and returns the
+// messages completed by data.  The returned messages reference the data.
+// After an error, the stream can't be decoded any further.
+func (s *HTTPStream) Decode(data []byte) ([]*HTTP, error) {
+	if s.failed {
+		return nil, nil
+	}
+	s.buf = append(s.buf, data...)
+	var messages []*HTTP
+	for len(s.buf) > 0 && !s.upgraded && !s.awaitingUpgrade {
+		h, err := s.decode(false)
+		if err == errHTTPHeaderIncomplete || (err == nil && !h.Complete) {
+			break
+		}
+		if err != nil {
+			s.failed = true
+			return messages, err
+		}
+		messages = append(messages, h)
+	}
+	if len(s.buf) == 0 {
+		s.buf = nil
+	}
+	return messages, nil
+}
+
+// Close is called when the connection is closed, and returns the last
+// message if its body ends with the connection, or an error if the stream
+// ends with an incomplete message.
+func (s *HTTPStream) Close() (*HTTP, error) {
+	if s.failed || s.upgraded || len(s.buf) == 0 {
+		return nil, nil
+	}
+	h, err := s.decode(true)
+	if err != nil {
+		return nil, err
+	}
+	if !h.Complete {
+		return h, errors.New("HTTP message incomplete")
+	}
+	return h, nil
+}
+
+// Upgraded returns true if the connection switched to another protocol.
+func (s *HTTPStream) Upgraded() bool {
+	return s.upgraded
+}
+
+// Rest returns the data buffered since the connection switched to another
+// protocol, and empties the buffer.
+func (s *HTTPStream) Rest() []byte {
+	if !s.upgraded {
+		return nil
+	}
+	rest := s.buf
+	s.buf = nil
+	return rest
+}
+
+// decode decodes the message at the start of the buffer, consuming it if
+// it's complete.  The header of the message and the chunks of its body are
+// only decoded once, however many calls it takes to complete the message.
+func (s *HTTPStream) decode(closed bool) (*HTTP, error) {
+	h := s.pending
+	if h == nil {
+		// the end of the header may start in the data searched already
+		from := s.headerScan - 3
+		if from < 0 {
+			from = 0
+		}
+		end := bytes.Index(s.buf[from:], []byte("\r\n\r\n"))
+		if end < 0 {
+			s.headerScan = len(s.buf)
+			return nil, errHTTPHeaderIncomplete
+		}
+		var requestMethod string
+		if s.Peer != nil && len(s.Peer.methods) > 0 && bytes.HasPrefix(s.buf, []byte("HTTP/")) {
+			requestMethod = s.Peer.methods[0]
+		}
+		h = &HTTP{}
+		if err := h.decodeHeader(s.buf[:from+end+4], requestMethod); err != nil {
+			return nil, err
+		}
+		s.pending, s.pendingMethod, s.headerScan = h, requestMethod, 0
+	}
+	body := s.buf[len(h.Contents):]
+	if h.BodyFraming == HTTPBodyChunked {
+		n, err := s.chunks.decode(body)
+		switch err {
+		case nil:
+			h.BaseLayer.Payload, h.Body, h.Complete = body[:n], s.chunks.data(), true
+		case errHTTPChunkIncomplete:
+			h.BaseLayer.Payload = body
+		default:
+			return nil, err
+		}
+	} else {
+		h.decodeBody(body, closed)
+	}
+	if !h.Complete {
+		return h, nil
+	}
+	s.buf = s.buf[len(h.Contents)+len(h.BaseLayer.Payload):]
+	s.pending, s.chunks = nil, httpChunkDecoder{}
+	requestMethod := s.pendingMethod
+	if !h.IsResponse {
+		s.methods = append(s.methods, h.Method)
+		s.awaitingUpgrade = s.Peer != nil && (h.Method == "CONNECT" || h.isUpgradeRequest())
+		return h, nil
+	}
+	// informational responses other than 101 precede the final response
+	if h.StatusCode < 200 && h.StatusCode != 101 {
+		return h, nil
+	}
+	if s.Peer != nil && len(s.Peer.methods) > 0 {
+		s.Peer.methods = s.Peer.methods[1:]
+		s.Peer.awaitingUpgrade = false
+	}
+	if h.StatusCode == 101 || (requestMethod == "CONNECT" && h.StatusCode/100 == 2) {
+		s.upgraded = true
+		if s.Peer != nil {
+			s.Peer.upgraded = true
+		}
+	}
+	return h, nil
+}
+
+// isUpgradeRequest returns true if h asks to upgrade the connection to
+// another protocol, RFC 9110 section 7.8.
+func (h *HTTP) isUpgradeRequest() bool {
+	return len(h.Headers["upgrade"]) > 0 && h.hasToken("connection", "upgrade")
+}
+
+// hasToken returns true if the comma separated values of the header with the
+// given name, which has to be lower case, include token, ignoring case.
+func (h *HTTP) hasToken(name, token string) bool {
+	for _, value := range h.Headers[name] {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
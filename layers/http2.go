@@ -0,0 +1,453 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/gopacket"
+	"golang.org/x/net/http2/hpack"
+)
+
+// HTTP2Preface is the connection preface sent by HTTP/2 clients before their
+// first frame, RFC 9113 section 3.4.
+const HTTP2Preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+// HTTP2FrameType is the type of an HTTP/2 frame, RFC 9113 section 6.
+type HTTP2FrameType uint8
+
+// HTTP/2 frame types.
+const (
+	HTTP2FrameTypeData         HTTP2FrameType = 0x0
+	HTTP2FrameTypeHeaders      HTTP2FrameType = 0x1
+	HTTP2FrameTypePriority     HTTP2FrameType = 0x2
+	HTTP2FrameTypeRSTStream    HTTP2FrameType = 0x3
+	HTTP2FrameTypeSettings     HTTP2FrameType = 0x4
+	HTTP2FrameTypePushPromise  HTTP2FrameType = 0x5
+	HTTP2FrameTypePing         HTTP2FrameType = 0x6
+	HTTP2FrameTypeGoAway       HTTP2FrameType = 0x7
+	HTTP2FrameTypeWindowUpdate HTTP2FrameType = 0x8
+	HTTP2FrameTypeContinuation HTTP2FrameType = 0x9
+)
+
+func (t HTTP2FrameType) String() string {
+	switch t {
+	case HTTP2FrameTypeData:
+		return "DATA"
+	case HTTP2FrameTypeHeaders:
+		return "HEADERS"
+	case HTTP2FrameTypePriority:
+		return "PRIORITY"
+	case HTTP2FrameTypeRSTStream:
+		return "RST_STREAM"
+	case HTTP2FrameTypeSettings:
+		return "SETTINGS"
+	case HTTP2FrameTypePushPromise:
+		return "PUSH_PROMISE"
+	case HTTP2FrameTypePing:
+		return "PING"
+	case HTTP2FrameTypeGoAway:
+		return "GOAWAY"
+	case HTTP2FrameTypeWindowUpdate:
+		return "WINDOW_UPDATE"
+	case HTTP2FrameTypeContinuation:
+		return "CONTINUATION"
+	}
+	return fmt.Sprintf("HTTP2FrameType(%#x)", uint8(t))
+}
+
+// HTTP/2 frame flags.  The meaning of a flag depends on the frame type.
+const (
+	HTTP2FlagEndStream  uint8 = 0x01 // DATA, HEADERS
+	HTTP2FlagAck        uint8 = 0x01 // SETTINGS, PING
+	HTTP2FlagEndHeaders uint8 = 0x04 // HEADERS, PUSH_PROMISE, CONTINUATION
+	HTTP2FlagPadded     uint8 = 0x08 // DATA, HEADERS, PUSH_PROMISE
+	HTTP2FlagPriority   uint8 = 0x20 // HEADERS
+)
+
+// HTTP2ErrorCode is an error code of RST_STREAM and GOAWAY frames, RFC 9113
+// section 7.
+type HTTP2ErrorCode uint32
+
+// HTTP/2 error codes.
+const (
+	HTTP2ErrorCodeNoError            HTTP2ErrorCode = 0x0
+	HTTP2ErrorCodeProtocolError      HTTP2ErrorCode = 0x1
+	HTTP2ErrorCodeInternalError      HTTP2ErrorCode = 0x2
+	HTTP2ErrorCodeFlowControlError   HTTP2ErrorCode = 0x3
+	HTTP2ErrorCodeSettingsTimeout    HTTP2ErrorCode = 0x4
+	HTTP2ErrorCodeStreamClosed       HTTP2ErrorCode = 0x5
+	HTTP2ErrorCodeFrameSizeError     HTTP2ErrorCode = 0x6
+	HTTP2ErrorCodeRefusedStream      HTTP2ErrorCode = 0x7
+	HTTP2ErrorCodeCancel             HTTP2ErrorCode = 0x8
+	HTTP2ErrorCodeCompressionError   HTTP2ErrorCode = 0x9
+	HTTP2ErrorCodeConnectError       HTTP2ErrorCode = 0xa
+	HTTP2ErrorCodeEnhanceYourCalm    HTTP2ErrorCode = 0xb
+	HTTP2ErrorCodeInadequateSecurity HTTP2ErrorCode = 0xc
+	HTTP2ErrorCodeHTTP11Required     HTTP2ErrorCode = 0xd
+)
+
+var http2ErrorCodeNames = []string{
+	"NO_ERROR", "PROTOCOL_ERROR", "INTERNAL_ERROR", "FLOW_CONTROL_ERROR",
+	"SETTINGS_TIMEOUT", "STREAM_CLOSED", "FRAME_SIZE_ERROR", "REFUSED_STREAM",
+	"CANCEL", "COMPRESSION_ERROR", "CONNECT_ERROR", "ENHANCE_YOUR_CALM",
+	"INADEQUATE_SECURITY", "HTTP_1_1_REQUIRED",
+}
+
+func (c HTTP2ErrorCode) String() string {
+	if int(c) < len(http2ErrorCodeNames) {
+		return http2ErrorCodeNames[c]
+	}
+	return fmt.Sprintf("HTTP2ErrorCode(%#x)", uint32(c))
+}
+
+// HTTP2SettingID identifies an HTTP/2 setting, RFC 9113 section 6.5.2.
+type HTTP2SettingID uint16
+
+// HTTP/2 settings.
+const (
+	HTTP2SettingHeaderTableSize       HTTP2SettingID = 0x1
+	HTTP2SettingEnablePush            HTTP2SettingID = 0x2
+	HTTP2SettingMaxConcurrentStreams  HTTP2SettingID = 0x3
+	HTTP2SettingInitialWindowSize     HTTP2SettingID = 0x4
+	HTTP2SettingMaxFrameSize          HTTP2SettingID = 0x5
+	HTTP2SettingMaxHeaderListSize     HTTP2SettingID = 0x6
+	HTTP2SettingEnableConnectProtocol HTTP2SettingID = 0x8 // RFC 8441
+	HTTP2SettingNoRFC7540Priorities   HTTP2SettingID = 0x9 // RFC 9218
+)
+
+func (s HTTP2SettingID) String() string {
+	switch s {
+	case HTTP2SettingHeaderTableSize:
+		return "HEADER_TABLE_SIZE"
+	case HTTP2SettingEnablePush:
+		return "ENABLE_PUSH"
+	case HTTP2SettingMaxConcurrentStreams:
+		return "MAX_CONCURRENT_STREAMS"
+	case HTTP2SettingInitialWindowSize:
+		return "INITIAL_WINDOW_SIZE"
+	case HTTP2SettingMaxFrameSize:
+		return "MAX_FRAME_SIZE"
+	case HTTP2SettingMaxHeaderListSize:
+		return "MAX_HEADER_LIST_SIZE"
+	case HTTP2SettingEnableConnectProtocol:
+		return "ENABLE_CONNECT_PROTOCOL"
+	case HTTP2SettingNoRFC7540Priorities:
+		return "NO_RFC7540_PRIORITIES"
+	}
+	return fmt.Sprintf("HTTP2SettingID(%#x)", uint16(s))
+}
+
+// HTTP2Setting is a setting of a SETTINGS frame.
+type HTTP2Setting struct {
+	ID    HTTP2SettingID
+	Value uint32
+}
+
+// HTTP2 is an HTTP/2 frame, RFC 9113.  Each frame of a packet is decoded as
+// an HTTP2 layer; the first frame sent by a client also holds the
+// connection preface.
+//
+// Header blocks are compressed with HPACK, whose state is kept for all of
+// the connection, so Headers are only decoded by an HTTP2Stream, which
+// decodes the frames of a reassembled TCP stream, or of the plaintext of a
+// TLS connection.
+type HTTP2 struct {
+	BaseLayer
+	// Preface is set if the frame is preceded by the connection preface,
+	// which is part of Contents.
+	Preface  bool
+	Length   uint32
+	Type     HTTP2FrameType
+	Flags    uint8
+	StreamID uint32
+	// Data is the data of DATA frames, the header block fragment of HEADERS,
+	// PUSH_PROMISE and CONTINUATION frames, the opaque data of PING frames,
+	// the debug data of GOAWAY frames, and the payload of frames of unknown
+	// types.  Padding isn't included.
+	Data []byte
+	// Exclusive, StreamDependency and Weight are the priority of PRIORITY
+	// frames, and of HEADERS frames with the PRIORITY flag.
+	Exclusive        bool
+	StreamDependency uint32
+	Weight           uint8
+	// ErrorCode is the error code of RST_STREAM and GOAWAY frames.
+	ErrorCode HTTP2ErrorCode
+	// Settings are the settings of SETTINGS frames.
+	Settings []HTTP2Setting
+	// PromisedStreamID is the stream reserved by PUSH_PROMISE frames.
+	PromisedStreamID uint32
+	// LastStreamID is the last stream processed of GOAWAY frames.
+	LastStreamID uint32
+	// WindowSizeIncrement is the increment of WINDOW_UPDATE frames.
+	WindowSizeIncrement uint32
+	// Headers is the decoded header block ended by the frame, set by
+	// HTTP2Stream on the HEADERS, PUSH_PROMISE or CONTINUATION frame with
+	// the END_HEADERS flag.
+	Headers []hpack.HeaderField
+}
+
+// LayerType returns LayerTypeHTTP2.
+func (h *HTTP2) LayerType() gopacket.LayerType { return LayerTypeHTTP2 }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (h *HTTP2) CanDecode() gopacket.LayerClass { return LayerTypeHTTP2 }
+
+// NextLayerType returns LayerTypeHTTP2, since frames are followed by frames,
+// or by nothing.
+func (h *HTTP2) NextLayerType() gopacket.LayerType { return LayerTypeHTTP2 }
+
+// HasFlag returns true if the frame has all bits of flag set.
+func (h *HTTP2) HasFlag(flag uint8) bool {
+	return h.Flags&flag == flag
+}
+
+// http2FrameHeaderLength is the length of the header of a frame.
+const http2FrameHeaderLength = 9
+
+var errHTTP2FrameTooShort = errors.New("HTTP/2 frame too short")
+
+// DecodeFromBytes decodes the first frame of data, and the connection
+// preface preceding it.  The following frames start after Contents.
+func (h *HTTP2) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*h = HTTP2{}
+	offset := 0
+	if bytes.HasPrefix(data, []byte(HTTP2Preface)) {
+		h.Preface = true
+		offset = len(HTTP2Preface)
+	}
+	if len(data)-offset < http2FrameHeaderLength {
+		df.SetTruncated()
+		return errHTTP2FrameTooShort
+	}
+	header := data[offset:]
+	h.Length = uint32(header[0])<<16 | uint32(header[1])<<8 | uint32(header[2])
+	h.Type = HTTP2FrameType(header[3])
+	h.Flags = header[4]
+	h.StreamID = binary.BigEndian.Uint32(header[5:9]) & 0x7fffffff
+	offset += http2FrameHeaderLength
+	if uint64(len(data)-offset) < uint64(h.Length) {
+		df.SetTruncated()
+		return errHTTP2FrameTooShort
+	}
+	h.Contents = data[:offset+int(h.Length)]
+	return h.decodePayload(data[offset : offset+int(h.Length)])
+}
+
+// decodePayload decodes the fields of the frame payload.
+func (h *HTTP2) decodePayload(payload []byte) error {
+	// padding is removed first, RFC 9113 section 6.1
+	if h.HasFlag(HTTP2FlagPadded) {
+		switch h.Type {
+		case HTTP2FrameTypeData, HTTP2FrameTypeHeaders, HTTP2FrameTypePushPromise:
+			if len(payload) == 0 || int(payload[0]) >= len(payload) {
+				return fmt.Errorf("invalid HTTP/2 %v frame padding", h.Type)
+			}
+			payload = payload[1 : len(payload)-int(payload[0])]
+		}
+	}
+	switch h.Type {
+	case HTTP2FrameTypeData, HTTP2FrameTypeContinuation:
+		h.Data = payload
+	case HTTP2FrameTypeHeaders:
+		if h.HasFlag(HTTP2FlagPriority) {
+			if len(payload) < 5 {
+				return errors.New("HTTP/2 HEADERS frame priority too short")
+			}
+			h.decodePriority(payload)
+			payload = payload[5:]
+		}
+		h.Data = payload
+	case HTTP2FrameTypePriority:
+		if len(payload) != 5 {
+			return errors.New("invalid HTTP/2 PRIORITY frame length")
+		}
+		h.decodePriority(payload)
+	case HTTP2FrameTypeRSTStream:
+		if len(payload) != 4 {
+			return errors.New("invalid HTTP/2 RST_STREAM frame length")
+		}
+		h.ErrorCode = HTTP2ErrorCode(binary.BigEndian.Uint32(payload))
+	case HTTP2FrameTypeSettings:
+		if len(payload)%6 != 0 || (h.HasFlag(HTTP2FlagAck) && len(payload) > 0) {
+			return errors.New("invalid HTTP/2 SETTINGS frame length")
+		}
+		for ; len(payload) > 0; payload = payload[6:] {
+			h.Settings = append(h.Settings, HTTP2Setting{
+				ID:    HTTP2SettingID(binary.BigEndian.Uint16(payload)),
+				Value: binary.BigEndian.Uint32(payload[2:6]),
+			})
+		}
+	case HTTP2FrameTypePushPromise:
+		if len(payload) < 4 {
+			return errors.New("HTTP/2 PUSH_PROMISE frame too short")
+		}
+		h.PromisedStreamID = binary.BigEndian.Uint32(payload) & 0x7fffffff
+		h.Data = payload[4:]
+	case HTTP2FrameTypePing:
+		if len(payload) != 8 {
+			return errors.New("invalid HTTP/2 PING frame length")
+		}
+		h.Data = payload
+	case HTTP2FrameTypeGoAway:
+		if len(payload) < 8 {
+			return errors.New("HTTP/2 GOAWAY frame too short")
+		}
+		h.LastStreamID = binary.BigEndian.Uint32(payload) & 0x7fffffff
+		h.ErrorCode = HTTP2ErrorCode(binary.BigEndian.Uint32(payload[4:8]))
+		h.Data = payload[8:]
+	case HTTP2FrameTypeWindowUpdate:
+		if len(payload) != 4 {
+			return errors.New("invalid HTTP/2 WINDOW_UPDATE frame length")
+		}
+		h.WindowSizeIncrement = binary.BigEndian.Uint32(payload) & 0x7fffffff
+	default:
+		// frames of unknown types are ignored by endpoints
+		h.Data = payload
+	}
+	return nil
+}
+
+func (h *HTTP2) decodePriority(data []byte) {
+	dependency := binary.BigEndian.Uint32(data)
+	h.Exclusive = dependency&0x80000000 != 0
+	h.StreamDependency = dependency & 0x7fffffff
+	h.Weight = data[4]
+}
+
+// decodeHTTP2 decodes the frames in data.
+func decodeHTTP2(data []byte, p gopacket.PacketBuilder) error {
+	for len(data) > 0 {
+		h := &HTTP2{}
+		if err := h.DecodeFromBytes(data, p); err != nil {
+			return err
+		}
+		p.AddLayer(h)
+		data = data[len(h.Contents):]
+	}
+	return nil
+}
+
+// DetectHTTP2 recognizes the first segment of HTTP/2 connections over
+// cleartext TCP by the connection preface.  To detect HTTP/2 on unmapped TCP
+// ports, register it as a hook for unknown TCP ports, and decode streams as
+// datagrams:
+//
+//	gopacket.RegisterUnknownProtocolHook(gopacket.UnknownTCPPort, layers.DetectHTTP2)
+func DetectHTTP2(u *gopacket.UnknownProtocol) gopacket.Decoder {
+	if bytes.HasPrefix(u.Data, []byte(HTTP2Preface)) {
+		return LayerTypeHTTP2
+	}
+	return nil
+}
+
+// HTTP2Stream decodes the frames sent in one direction of an HTTP/2
+// connection, whose data is passed to Decode as it's reassembled, e.g. by a
+// reassembly.Stream, or decrypted from TLS records.  The stream may start
+// with the connection preface.
+//
+// HTTP2Stream keeps the HPACK state of the stream to decode the Headers of
+// header blocks.  Set Peer to the HTTP2Stream of the other direction of the
+// connection to have the HEADER_TABLE_SIZE settings of the peer limit the
+// dynamic table.
+type HTTP2Stream struct {
+	// Peer is the HTTP2Stream of the other direction of the connection.
+	Peer *HTTP2Stream
+
+	buf     []byte
+	started bool
+	decoder *hpack.Decoder
+	// block is the header block, while CONTINUATION frames are expected
+	block  []byte
+	failed bool
+}
+
+// hpackDecoder returns the HPACK decoder of the stream, with the default
+// dynamic table size of 4096 bytes.
+func (s *HTTP2Stream) hpackDecoder() *hpack.Decoder {
+	if s.decoder == nil {
+		s.decoder = hpack.NewDecoder(4096, nil)
+	}
+	return s.decoder
+}
+
+// Decode decodes data following the data of previous calls, and returns the
+// frames completed by data.  The returned frames reference the data.  After
+// an error, the stream can't be decoded any further.
+func (s *HTTP2Stream) Decode(data []byte) ([]*HTTP2, error) {
+	if s.failed {
+		return nil, nil
+	}
+	s.buf = append(s.buf, data...)
+	if !s.started {
+		if len(s.buf) < len(HTTP2Preface) && bytes.HasPrefix([]byte(HTTP2Preface), s.buf) {
+			return nil, nil
+		}
+		s.started = true
+	}
+	var frames []*HTTP2
+	for {
+		h := &HTTP2{}
+		err := h.DecodeFromBytes(s.buf, gopacket.NilDecodeFeedback)
+		if err == errHTTP2FrameTooShort {
+			break
+		}
+		if err == nil {
+			err = s.frameDecoded(h)
+		}
+		if err != nil {
+			s.failed = true
+			return frames, err
+		}
+		frames = append(frames, h)
+		s.buf = s.buf[len(h.Contents):]
+	}
+	if len(s.buf) == 0 {
+		s.buf = nil
+	}
+	return frames, nil
+}
+
+// frameDecoded decodes header blocks, and follows the HPACK settings.
+func (s *HTTP2Stream) frameDecoded(h *HTTP2) error {
+	if s.block != nil && h.Type != HTTP2FrameTypeContinuation {
+		return fmt.Errorf("HTTP/2 %v frame instead of CONTINUATION", h.Type)
+	}
+	switch h.Type {
+	case HTTP2FrameTypeHeaders, HTTP2FrameTypePushPromise:
+		s.block = append([]byte{}, h.Data...)
+	case HTTP2FrameTypeContinuation:
+		if s.block == nil {
+			return errors.New("unexpected HTTP/2 CONTINUATION frame")
+		}
+		s.block = append(s.block, h.Data...)
+	case HTTP2FrameTypeSettings:
+		if s.Peer != nil && !h.HasFlag(HTTP2FlagAck) {
+			for _, setting := range h.Settings {
+				if setting.ID == HTTP2SettingHeaderTableSize {
+					s.Peer.hpackDecoder().SetAllowedMaxDynamicTableSize(setting.Value)
+				}
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+	if !h.HasFlag(HTTP2FlagEndHeaders) {
+		return nil
+	}
+	headers, err := s.hpackDecoder().DecodeFull(s.block)
+	s.block = nil
+	if err != nil {
+		return fmt.Errorf("HTTP/2 header block: %v", err)
+	}
+	h.Headers = headers
+	return nil
+}
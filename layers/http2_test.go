@@ -0,0 +1,134 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+	"golang.org/x/net/http2/hpack"
+)
+
+// http2Frame returns an HTTP/2 frame.
+func http2Frame(t HTTP2FrameType, flags uint8, streamID uint32, payload []byte) []byte {
+	frame := []byte{byte(len(payload) >> 16), byte(len(payload) >> 8), byte(len(payload)), byte(t), flags, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(frame[5:], streamID)
+	return append(frame, payload...)
+}
+
+func TestHTTP2Frames(t *testing.T) {
+	var data []byte
+	data = append(data, HTTP2Preface...)
+	data = append(data, http2Frame(HTTP2FrameTypeSettings, 0, 0, mustDecodeHex("000100001000"+"000300000064"))...)
+	data = append(data, http2Frame(HTTP2FrameTypeData, HTTP2FlagPadded|HTTP2FlagEndStream, 1, []byte("\x02hello\x00\x00"))...)
+	data = append(data, http2Frame(HTTP2FrameTypeHeaders, HTTP2FlagPriority, 3, mustDecodeHex("8000000110"+"82"))...)
+	data = append(data, http2Frame(HTTP2FrameTypeGoAway, 0, 0, append(mustDecodeHex("00000003"+"00000002"), "debug"...))...)
+	data = append(data, http2Frame(HTTP2FrameTypeWindowUpdate, 0, 0, mustDecodeHex("80001000"))...)
+	data = append(data, http2Frame(0xfa, 0, 0, []byte("unknown"))...)
+
+	p := gopacket.NewPacket(data, LayerTypeHTTP2, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	want := make([]gopacket.LayerType, 6)
+	for i := range want {
+		want[i] = LayerTypeHTTP2
+	}
+	checkLayers(p, want, t)
+	frame := func(i int) *HTTP2 { return p.Layers()[i].(*HTTP2) }
+
+	if f := frame(0); !f.Preface || f.Type != HTTP2FrameTypeSettings || !reflect.DeepEqual(f.Settings, []HTTP2Setting{
+		{HTTP2SettingHeaderTableSize, 4096}, {HTTP2SettingMaxConcurrentStreams, 100}}) {
+		t.Errorf("unexpected SETTINGS frame %#v", f)
+	}
+	if f := frame(1); f.Preface || f.StreamID != 1 || !f.HasFlag(HTTP2FlagEndStream) || string(f.Data) != "hello" {
+		t.Errorf("unexpected DATA frame %#v", f)
+	}
+	if f := frame(2); !f.Exclusive || f.StreamDependency != 1 || f.Weight != 16 || !bytes.Equal(f.Data, []byte{0x82}) || f.Headers != nil {
+		t.Errorf("unexpected HEADERS frame %#v", f)
+	}
+	if f := frame(3); f.LastStreamID != 3 || f.ErrorCode != HTTP2ErrorCodeInternalError || string(f.Data) != "debug" {
+		t.Errorf("unexpected GOAWAY frame %#v", f)
+	}
+	if f := frame(4); f.WindowSizeIncrement != 0x1000 {
+		t.Errorf("unexpected WINDOW_UPDATE frame %#v", f)
+	}
+	if f := frame(5); f.Type.String() != "HTTP2FrameType(0xfa)" || string(f.Data) != "unknown" {
+		t.Errorf("unexpected frame %#v", f)
+	}
+
+	for _, data := range [][]byte{
+		http2Frame(HTTP2FrameTypeData, 0, 1, []byte("hello"))[:12],
+		http2Frame(HTTP2FrameTypeData, HTTP2FlagPadded, 1, []byte("\x06hello")),
+		http2Frame(HTTP2FrameTypeSettings, HTTP2FlagAck, 0, make([]byte, 6)),
+		http2Frame(HTTP2FrameTypePing, 0, 0, make([]byte, 4)),
+	} {
+		if err := (&HTTP2{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
+
+func TestHTTP2Stream(t *testing.T) {
+	var block bytes.Buffer
+	encoder := hpack.NewEncoder(&block)
+	headerBlock := func(fields ...string) []byte {
+		block.Reset()
+		for i := 0; i < len(fields); i += 2 {
+			encoder.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]})
+		}
+		return append([]byte{}, block.Bytes()...)
+	}
+
+	var data []byte
+	data = append(data, HTTP2Preface...)
+	data = append(data, http2Frame(HTTP2FrameTypeSettings, 0, 0, nil)...)
+	first := headerBlock(":method", "GET", ":path", "/", "x-custom", "value")
+	data = append(data, http2Frame(HTTP2FrameTypeHeaders, HTTP2FlagEndStream, 1, first[:3])...)
+	data = append(data, http2Frame(HTTP2FrameTypeContinuation, HTTP2FlagEndHeaders, 1, first[3:])...)
+	// the second request refers to the dynamic table
+	data = append(data, http2Frame(HTTP2FrameTypeHeaders, HTTP2FlagEndHeaders|HTTP2FlagEndStream, 3, headerBlock(":method", "GET", ":path", "/", "x-custom", "value"))...)
+
+	client := &HTTP2Stream{}
+	var frames []*HTTP2
+	// split the data arbitrarily, even in the preface
+	cuts := []int{0, 5, 30, 50, len(data)}
+	for i := 1; i < len(cuts); i++ {
+		f, err := client.Decode(data[cuts[i-1]:cuts[i]])
+		if err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, f...)
+	}
+	if len(frames) != 4 || !frames[0].Preface {
+		t.Fatalf("unexpected frames %v", frames)
+	}
+	want := []hpack.HeaderField{{Name: ":method", Value: "GET"}, {Name: ":path", Value: "/"}, {Name: "x-custom", Value: "value"}}
+	if frames[1].Headers != nil || !reflect.DeepEqual(frames[2].Headers, want) || !reflect.DeepEqual(frames[3].Headers, want) {
+		t.Errorf("unexpected headers %v, %v, %v", frames[1].Headers, frames[2].Headers, frames[3].Headers)
+	}
+
+	// a table size above the one allowed by the peer is an error
+	server := &HTTP2Stream{Peer: client}
+	if _, err := server.Decode(http2Frame(HTTP2FrameTypeSettings, 0, 0, mustDecodeHex("000100000000"))); err != nil {
+		t.Fatal(err)
+	}
+	encoder.SetMaxDynamicTableSize(200)
+	if _, err := client.Decode(http2Frame(HTTP2FrameTypeHeaders, HTTP2FlagEndHeaders, 5, headerBlock(":path", "/x"))); err == nil {
+		t.Error("no error for dynamic table size update above the limit")
+	}
+
+	// header blocks have to be continued by CONTINUATION frames
+	s := &HTTP2Stream{}
+	data = append(http2Frame(HTTP2FrameTypeHeaders, 0, 1, []byte{0x82}), http2Frame(HTTP2FrameTypeData, 0, 1, nil)...)
+	if _, err := s.Decode(data); err == nil {
+		t.Error("no error for interrupted header block")
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestHTTPDecode(t *testing.T) {
+	for _, test := range []struct {
+		data     string
+		framing  HTTPBodyFraming
+		payload  string
+		body     string
+		complete bool
+	}{
+		{data: "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n", framing: HTTPBodyNone, complete: true},
+		{data: "POST /form HTTP/1.1\r\nContent-Length: 5\r\n\r\nhello", framing: HTTPBodyContentLength, payload: "hello", body: "hello", complete: true},
+		{data: "POST /form HTTP/1.1\r\nContent-Length: 5\r\n\r\nhel", framing: HTTPBodyContentLength, payload: "hel"},
+		{data: "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n5;ext=1\r\nhello\r\n1\r\n!\r\n0\r\nTrailer: x\r\n\r\n",
+			framing: HTTPBodyChunked, payload: "5;ext=1\r\nhello\r\n1\r\n!\r\n0\r\nTrailer: x\r\n\r\n", body: "hello!", complete: true},
+		{data: "HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhel", framing: HTTPBodyChunked, payload: "5\r\nhel"},
+		{data: "HTTP/1.0 200 OK\r\n\r\nuntil close", framing: HTTPBodyUntilClose, payload: "until close"},
+		{data: "HTTP/1.1 304 Not Modified\r\nContent-Length: 10\r\n\r\n", framing: HTTPBodyNone, complete: true},
+	} {
+		h := &HTTP{}
+		if err := h.DecodeFromBytes([]byte(test.data), gopacket.NilDecodeFeedback); err != nil {
+			t.Errorf("%q: %v", test.data, err)
+			continue
+		}
+		if h.BodyFraming != test.framing || string(h.Payload()) != test.payload || string(h.Body) != test.body || h.Complete != test.complete {
+			t.Errorf("%q: got framing %v, payload %q, body %q, complete %v", test.data, h.BodyFraming, h.Payload(), h.Body, h.Complete)
+		}
+	}
+
+	for _, data := range []string{
+		"GET / HTTP/1.1\r\nHost: example.com\r\n",
+		"GET /\r\n\r\n",
+		"HTTP/1.1 2000 OK\r\n\r\n",
+		"GET / HTTP/1.1\r\nHost\r\n\r\n",
+		"POST / HTTP/1.1\r\nContent-Length: 1\r\nContent-Length: 2\r\n\r\n",
+		"HTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\nzz\r\n",
+	} {
+		if err := (&HTTP{}).DecodeFromBytes([]byte(data), gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%q: no error", data)
+		}
+	}
+}
+
+func TestHTTPHeaders(t *testing.T) {
+	h := &HTTP{}
+	data := "GET /index.html?q=1 HTTP/1.1\r\nHost: example.com\r\nAccept: text/html\r\nACCEPT: text/plain\r\nX-Folded: a\r\n  b\r\n\r\n"
+	if err := h.DecodeFromBytes([]byte(data), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if h.IsResponse || h.Method != "GET" || h.RequestURI != "/index.html?q=1" || h.Version != "HTTP/1.1" {
+		t.Errorf("unexpected request line %#v", h)
+	}
+	if h.Header("host") != "example.com" || len(h.Headers["accept"]) != 2 || h.Header("X-Folded") != "a b" || h.Header("missing") != "" {
+		t.Errorf("unexpected headers %v", h.Headers)
+	}
+
+	if err := h.DecodeFromBytes([]byte("HTTP/1.1 404 Not Found\r\n\r\n"), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if !h.IsResponse || h.StatusCode != 404 || h.Reason != "Not Found" || len(h.Headers) != 0 {
+		t.Errorf("unexpected status line %#v", h)
+	}
+}
+
+func TestHTTPPacket(t *testing.T) {
+	RegisterTCPPortLayerType(8081, LayerTypeHTTP)
+	defer RegisterTCPPortLayerType(8081, gopacket.LayerTypePayload)
+
+	decode := func(payload string) gopacket.Packet {
+		t.Helper()
+		data, err := Build().IPv4(nil).TCP(&TCP{SrcPort: 1234, DstPort: 8081, ACK: true}).Payload([]byte(payload)).Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gopacket.NewPacket(data, LayerTypeIPv4, gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true})
+	}
+
+	// pipelined requests are decoded as separate layers
+	p := decode("GET /a HTTP/1.1\r\n\r\nGET /b HTTP/1.1\r\n\r\n")
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeTCP, LayerTypeHTTP, LayerTypeHTTP}, t)
+	if h := p.Layers()[3].(*HTTP); h.RequestURI != "/b" {
+		t.Errorf("unexpected second request %#v", h)
+	}
+	if p.ApplicationLayer().(*HTTP).RequestURI != "/a" {
+		t.Error("application layer isn't the first request")
+	}
+
+	// the body following an incomplete message is its payload
+	p = decode("POST / HTTP/1.1\r\nContent-Length: 10\r\n\r\nhello")
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeTCP, LayerTypeHTTP}, t)
+	if string(p.ApplicationLayer().Payload()) != "hello" {
+		t.Errorf("unexpected payload %q", p.ApplicationLayer().Payload())
+	}
+
+	// segments continuing a body are payload
+	p = decode("world")
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeTCP, gopacket.LayerTypePayload}, t)
+}
+
+func TestHTTPStream(t *testing.T) {
+	requests, responses := &HTTPStream{}, &HTTPStream{}
+	responses.Peer = requests
+
+	decode := func(s *HTTPStream, data string, want ...string) {
+		t.Helper()
+		messages, err := s.Decode([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) != len(want) {
+			t.Fatalf("got %d messages, want %d", len(messages), len(want))
+		}
+		for i, h := range messages {
+			if got := h.Method + h.RequestURI + h.Reason + ":" + string(h.Body); got != want[i] {
+				t.Errorf("got message %q, want %q", got, want[i])
+			}
+		}
+	}
+
+	// messages are split and pipelined arbitrarily
+	decode(requests, "HEAD /a HTTP/1.1\r\nHo")
+	decode(requests, "st: x\r\n\r\nPOST /b HTTP/1.1\r\nContent-Length: 3\r\n\r\nab", "HEAD/a:")
+	decode(requests, "cGET /c HTTP/1.1\r\n\r\n", "POST/b:abc", "GET/c:")
+
+	// the response to HEAD has no body despite its Content-Length
+	decode(responses, "HTTP/1.1 200 A\r\nContent-Length: 5\r\n\r\n", "A:")
+	decode(responses, "HTTP/1.1 100 Continue\r\n\r\nHTTP/1.1 200 B\r\nTransfer-Encoding: chunked\r\n\r\n3\r\nxyz\r\n", "Continue:")
+	decode(responses, "0\r\n\r\nHTTP/1.1 200 C\r\n\r\nuntil ", "B:xyz")
+	decode(responses, "close")
+	h, err := responses.Close()
+	if err != nil || h.Reason != "C" || string(h.Body) != "until close" {
+		t.Errorf("unexpected last response %#v, error %v", h, err)
+	}
+
+	// messages sent a byte at a time are decoded once complete
+	s := &HTTPStream{}
+	message := "POST /d HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n2\r\nab\r\n1;x=y\r\nc\r\n0\r\nT: 1\r\n\r\n"
+	for i := 0; i < len(message)-1; i++ {
+		decode(s, message[i:i+1])
+	}
+	decode(s, message[len(message)-1:], "POST/d:abc")
+	if s.chunks.offset != 0 || s.pending != nil || len(s.buf) != 0 {
+		t.Errorf("stream state not reset after message: %+v", s)
+	}
+
+	// the stream ends with an incomplete message
+	s = &HTTPStream{}
+	decode(s, "POST / HTTP/1.1\r\nContent-Length: 3\r\n\r\na")
+	if _, err := s.Close(); err == nil {
+		t.Error("no error for incomplete message")
+	}
+
+	// the requests following an upgrade request wait for its response
+	requests = &HTTPStream{}
+	responses = &HTTPStream{Peer: requests}
+	requests.Peer = responses
+	decode(requests, "GET /chat HTTP/1.1\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n\r\nGET /next HTTP/1.1\r\n\r\n", "GET/chat:")
+	decode(responses, "HTTP/1.1 200 Declined\r\nContent-Length: 0\r\n\r\n", "Declined:")
+	decode(requests, "", "GET/next:")
+
+	// the streams stop after switching protocols
+	decode(requests, "GET /chat HTTP/1.1\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n\x81\x85", "GET/chat:")
+	decode(responses, "HTTP/1.1 101 Switching Protocols\r\n\r\n\x81\x05hello", "Switching Protocols:")
+	if !responses.Upgraded() || !requests.Upgraded() {
+		t.Error("streams not upgraded")
+	}
+	decode(requests, "mask")
+	if rest := string(requests.Rest()); rest != "\x81\x85mask" {
+		t.Errorf("unexpected rest of requests %q", rest)
+	}
+	if rest := string(responses.Rest()); rest != "\x81\x05hello" {
+		t.Errorf("unexpected rest of responses %q", rest)
+	}
+}
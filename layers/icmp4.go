@@ -251,6 +251,12 @@ func (i *ICMPv4) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Serialize
 	return nil
 }
 
+// VerifyChecksum verifies the checksum of the ICMPv4 header and payload,
+// implementing gopacket.ChecksumVerifier.
+func (i *ICMPv4) VerifyChecksum() (bool, error) {
+	return tcpipChecksumValid(i.Contents, i.Payload, 0), nil
+}
+
 // CanDecode returns the set of layer types that this DecodingLayer can decode.
 func (i *ICMPv4) CanDecode() gopacket.LayerClass {
 	return LayerTypeICMPv4
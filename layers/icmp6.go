@@ -221,6 +221,13 @@ func (i *ICMPv6) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Serialize
 	return nil
 }
 
+// VerifyChecksum verifies the checksum of the ICMPv6 header and payload,
+// implementing gopacket.ChecksumVerifier.  SetNetworkLayerForChecksum must be
+// called before.
+func (i *ICMPv6) VerifyChecksum() (bool, error) {
+	return i.verifyChecksum(i.Contents, i.Payload, IPProtocolICMPv6)
+}
+
 // CanDecode returns the set of layer types that this DecodingLayer can decode.
 func (i *ICMPv6) CanDecode() gopacket.LayerClass {
 	return LayerTypeICMPv6
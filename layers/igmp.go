@@ -10,6 +10,7 @@ package layers
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"net"
 	"time"
 
@@ -161,11 +162,11 @@ func (i *IGMPv1or2) decodeResponse(data []byte) error {
 // IGMPv3GroupRecord stores individual group records for a V3 Membership Report message.
 type IGMPv3GroupRecord struct {
 	Type             IGMPv3GroupRecordType
-	AuxDataLen       uint8 // this should always be 0 as per IGMPv3 spec.
+	AuxDataLen       uint8 // in 32-bit words, this should always be 0 as per IGMPv3 spec.
 	NumberOfSources  uint16
 	MulticastAddress net.IP
 	SourceAddresses  []net.IP
-	AuxData          uint32 // NOT USED
+	AuxData          []byte
 }
 
 func (i *IGMP) decodeIGMPv3MembershipReport(data []byte) error {
@@ -177,6 +178,7 @@ func (i *IGMP) decodeIGMPv3MembershipReport(data []byte) error {
 	i.NumberOfGroupRecords = binary.BigEndian.Uint16(data[6:8])
 
 	recordOffset := 8
+	i.GroupRecords = i.GroupRecords[:0]
 	for j := 0; j < int(i.NumberOfGroupRecords); j++ {
 		if len(data) < recordOffset+8 {
 			return errors.New("IGMPv3 Membership Report too small #2")
@@ -188,7 +190,8 @@ func (i *IGMP) decodeIGMPv3MembershipReport(data []byte) error {
 		gr.NumberOfSources = binary.BigEndian.Uint16(data[recordOffset+2 : recordOffset+4])
 		gr.MulticastAddress = net.IP(data[recordOffset+4 : recordOffset+8])
 
-		if len(data) < recordOffset+8+int(gr.NumberOfSources)*4 {
+		auxOffset := recordOffset + 8 + int(gr.NumberOfSources)*4
+		if len(data) < auxOffset+int(gr.AuxDataLen)*4 {
 			return errors.New("IGMPv3 Membership Report too small #3")
 		}
 
@@ -198,9 +201,15 @@ func (i *IGMP) decodeIGMPv3MembershipReport(data []byte) error {
 			gr.SourceAddresses = append(gr.SourceAddresses, sourceAddr)
 		}
 
+		if gr.AuxDataLen > 0 {
+			gr.AuxData = data[auxOffset : auxOffset+int(gr.AuxDataLen)*4]
+		}
+
 		i.GroupRecords = append(i.GroupRecords, gr)
-		recordOffset += 8 + 4*int(gr.NumberOfSources)
+		recordOffset = auxOffset + int(gr.AuxDataLen)*4
 	}
+	i.Contents = data[:recordOffset]
+	i.Payload = data[recordOffset:]
 	return nil
 }
 
@@ -231,16 +240,21 @@ func (i *IGMP) decodeIGMPv3MembershipQuery(data []byte) error {
 	i.SupressRouterProcessing = data[8]&0x8 != 0
 	i.GroupAddress = net.IP(data[4:8])
 	i.RobustnessValue = data[8] & 0x7
-	i.IntervalTime = igmpTimeDecode(data[9])
+	// QQIC is encoded as the Max Resp Code, but in seconds
+	i.IntervalTime = igmpTimeDecode(data[9]) * 10
 	i.NumberOfSources = binary.BigEndian.Uint16(data[10:12])
 
-	if len(data) < 12+int(i.NumberOfSources)*4 {
+	length := 12 + int(i.NumberOfSources)*4
+	if len(data) < length {
 		return errors.New("IGMPv3 Membership Query too small #2")
 	}
 
+	i.SourceAddresses = i.SourceAddresses[:0]
 	for j := 0; j < int(i.NumberOfSources); j++ {
 		i.SourceAddresses = append(i.SourceAddresses, net.IP(data[12+j*4:16+j*4]))
 	}
+	i.Contents = data[:length]
+	i.Payload = data[length:]
 
 	return nil
 }
@@ -251,9 +265,27 @@ func igmpTimeDecode(t uint8) time.Duration {
 	if t&0x80 == 0 {
 		return time.Millisecond * 100 * time.Duration(t)
 	}
-	mant := (t & 0x70) >> 4
-	exp := t & 0x0F
-	return time.Millisecond * 100 * time.Duration((mant|0x10)<<(exp+3))
+	exp := (t & 0x70) >> 4
+	mant := t & 0x0F
+	return time.Millisecond * 100 * (time.Duration(mant|0x10) << (exp + 3))
+}
+
+// igmpTimeEncode encodes d, rounded down to a representable duration, with
+// the algorithm of igmpTimeDecode.
+func igmpTimeEncode(d time.Duration) (uint8, error) {
+	t := d / (time.Millisecond * 100)
+	if t < 0 || t > 0x1f<<10 {
+		return 0, fmt.Errorf("IGMP time %v out of range", d)
+	}
+	if t < 0x80 {
+		return uint8(t), nil
+	}
+	exp := uint(0)
+	for t>>(exp+3) > 0x1f {
+		exp++
+	}
+	mant := uint8(t>>(exp+3)) & 0x0f
+	return 0x80 | uint8(exp)<<4 | mant, nil
 }
 
 // LayerType returns LayerTypeIGMP for the V1,2,3 message protocol formats.
@@ -269,10 +301,40 @@ func (i *IGMPv1or2) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) err
 	i.MaxResponseTime = igmpTimeDecode(data[1])
 	i.Checksum = binary.BigEndian.Uint16(data[2:4])
 	i.GroupAddress = net.IP(data[4:8])
+	i.Contents = data[:8]
+	i.Payload = data[8:]
 
 	return nil
 }
 
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+func (i *IGMPv1or2) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	bytes, err := b.PrependBytes(8)
+	if err != nil {
+		return err
+	}
+	bytes[0] = uint8(i.Type)
+	if bytes[1], err = igmpTimeEncode(i.MaxResponseTime); err != nil {
+		return err
+	}
+	if err := igmpPutIPv4(bytes[4:8], i.GroupAddress); err != nil {
+		return err
+	}
+	if opts.ComputeChecksums {
+		bytes[2], bytes[3] = 0, 0
+		i.Checksum = tcpipChecksum(bytes, 0)
+	}
+	binary.BigEndian.PutUint16(bytes[2:4], i.Checksum)
+	return nil
+}
+
+// VerifyChecksum verifies the checksum of the IGMP message, implementing
+// gopacket.ChecksumVerifier.
+func (i *IGMPv1or2) VerifyChecksum() (bool, error) {
+	return tcpipChecksum(i.Contents, 0) == 0, nil
+}
+
 func (i *IGMPv1or2) NextLayerType() gopacket.LayerType {
 	return gopacket.LayerTypeZero
 }
@@ -288,17 +350,148 @@ func (i *IGMP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
 	}
 
 	// common IGMP header values between versions 1..3 of IGMP specification..
-	i.Type = IGMPType(data[0])
+	*i = IGMP{
+		Type:            IGMPType(data[0]),
+		Version:         3,
+		SourceAddresses: i.SourceAddresses,
+		GroupRecords:    i.GroupRecords,
+	}
 
 	switch i.Type {
 	case IGMPMembershipQuery:
-		i.decodeIGMPv3MembershipQuery(data)
+		return i.decodeIGMPv3MembershipQuery(data)
 	case IGMPMembershipReportV3:
-		i.decodeIGMPv3MembershipReport(data)
+		return i.decodeIGMPv3MembershipReport(data)
 	default:
 		return errors.New("unsupported IGMP type")
 	}
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+// With FixLengths, the number of sources and group records and the
+// auxiliary data lengths are set from the slices.
+func (i *IGMP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	var bytes []byte
+	var err error
+	switch i.Type {
+	case IGMPMembershipQuery:
+		bytes, err = i.serializeIGMPv3MembershipQuery(b, opts)
+	case IGMPMembershipReportV3:
+		bytes, err = i.serializeIGMPv3MembershipReport(b, opts)
+	default:
+		return fmt.Errorf("unsupported IGMPv3 type %v", i.Type)
+	}
+	if err != nil {
+		return err
+	}
+	bytes[0] = uint8(i.Type)
+	if opts.ComputeChecksums {
+		bytes[2], bytes[3] = 0, 0
+		i.Checksum = tcpipChecksum(bytes, 0)
+	}
+	binary.BigEndian.PutUint16(bytes[2:4], i.Checksum)
+	return nil
+}
 
+func (i *IGMP) serializeIGMPv3MembershipQuery(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) ([]byte, error) {
+	if opts.FixLengths {
+		i.NumberOfSources = uint16(len(i.SourceAddresses))
+	}
+	if int(i.NumberOfSources) != len(i.SourceAddresses) {
+		return nil, fmt.Errorf("IGMPv3 number of sources %d does not match %d addresses", i.NumberOfSources, len(i.SourceAddresses))
+	}
+	if i.RobustnessValue > 7 {
+		return nil, fmt.Errorf("IGMPv3 robustness value %d too large", i.RobustnessValue)
+	}
+	bytes, err := b.PrependBytes(12 + 4*len(i.SourceAddresses))
+	if err != nil {
+		return nil, err
+	}
+	if bytes[1], err = igmpTimeEncode(i.MaxResponseTime); err != nil {
+		return nil, err
+	}
+	if err := igmpPutIPv4(bytes[4:8], i.GroupAddress); err != nil {
+		return nil, err
+	}
+	bytes[8] = i.RobustnessValue
+	if i.SupressRouterProcessing {
+		bytes[8] |= 0x8
+	}
+	if bytes[9], err = igmpTimeEncode(i.IntervalTime / 10); err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint16(bytes[10:12], i.NumberOfSources)
+	for j, src := range i.SourceAddresses {
+		if err := igmpPutIPv4(bytes[12+4*j:], src); err != nil {
+			return nil, err
+		}
+	}
+	return bytes, nil
+}
+
+func (i *IGMP) serializeIGMPv3MembershipReport(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) ([]byte, error) {
+	if opts.FixLengths {
+		i.NumberOfGroupRecords = uint16(len(i.GroupRecords))
+	}
+	if int(i.NumberOfGroupRecords) != len(i.GroupRecords) {
+		return nil, fmt.Errorf("IGMPv3 number of group records %d does not match %d records", i.NumberOfGroupRecords, len(i.GroupRecords))
+	}
+	length := 8
+	for j := range i.GroupRecords {
+		gr := &i.GroupRecords[j]
+		if opts.FixLengths {
+			gr.NumberOfSources = uint16(len(gr.SourceAddresses))
+			gr.AuxDataLen = uint8(len(gr.AuxData) / 4)
+		}
+		if int(gr.NumberOfSources) != len(gr.SourceAddresses) || int(gr.AuxDataLen)*4 != len(gr.AuxData) {
+			return nil, fmt.Errorf("IGMPv3 group record %v lengths do not match its sources and auxiliary data", gr.MulticastAddress)
+		}
+		length += 8 + 4*len(gr.SourceAddresses) + len(gr.AuxData)
+	}
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return nil, err
+	}
+	bytes[1] = 0
+	binary.BigEndian.PutUint16(bytes[4:6], 0)
+	binary.BigEndian.PutUint16(bytes[6:8], i.NumberOfGroupRecords)
+	off := 8
+	for _, gr := range i.GroupRecords {
+		bytes[off] = uint8(gr.Type)
+		bytes[off+1] = gr.AuxDataLen
+		binary.BigEndian.PutUint16(bytes[off+2:off+4], gr.NumberOfSources)
+		if err := igmpPutIPv4(bytes[off+4:off+8], gr.MulticastAddress); err != nil {
+			return nil, err
+		}
+		off += 8
+		for _, src := range gr.SourceAddresses {
+			if err := igmpPutIPv4(bytes[off:off+4], src); err != nil {
+				return nil, err
+			}
+			off += 4
+		}
+		off += copy(bytes[off:], gr.AuxData)
+	}
+	return bytes, nil
+}
+
+// VerifyChecksum verifies the checksum of the IGMP message, implementing
+// gopacket.ChecksumVerifier.
+func (i *IGMP) VerifyChecksum() (bool, error) {
+	return tcpipChecksum(i.Contents, 0) == 0, nil
+}
+
+func igmpPutIPv4(data []byte, ip net.IP) error {
+	if ip == nil {
+		copy(data, net.IPv4zero.To4())
+		return nil
+	}
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return fmt.Errorf("invalid IGMP IPv4 address %v", ip)
+	}
+	copy(data, ip4)
 	return nil
 }
 
@@ -7,7 +7,11 @@
 package layers
 
 import (
+	"bytes"
+	"net"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/gopacket"
 )
@@ -169,3 +173,104 @@ func BenchmarkDecodeigmpv3MembershipReport2Records(b *testing.B) {
 		gopacket.NewPacket(igmpv3MembershipReport2Records, LinkTypeEthernet, gopacket.NoCopy)
 	}
 }
+
+func TestIGMPSerialize(t *testing.T) {
+	for _, data := range [][]byte{
+		igmpv1MembershipReportPacket,
+		igmpv2MembershipQueryPacket,
+		igmpv2MembershipReportPacket,
+		igmp3v3MembershipQueryPacket,
+		igmpv3MembershipReport2Records,
+	} {
+		p := gopacket.NewPacket(data, LinkTypeEthernet, testDecodeOptions)
+		if p.ErrorLayer() != nil {
+			t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+		}
+		l := p.Layer(LayerTypeIGMP)
+		for _, r := range gopacket.VerifyChecksums(p) {
+			if r.Status != gopacket.ChecksumValid {
+				t.Errorf("%v checksum status %v: %v", r.LayerType, r.Status, r.Err)
+			}
+		}
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		if err := l.(gopacket.SerializableLayer).SerializeTo(buf, opts); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), l.LayerContents()) {
+			t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), l.LayerContents())
+		}
+	}
+}
+
+func TestIGMPv3MembershipQueryFields(t *testing.T) {
+	p := gopacket.NewPacket(igmp3v3MembershipQueryPacket, LinkTypeEthernet, testDecodeOptions)
+	igmp := p.Layer(LayerTypeIGMP).(*IGMP)
+	if igmp.MaxResponseTime != 2400*time.Millisecond || igmp.RobustnessValue != 2 || igmp.IntervalTime != 20*time.Second {
+		t.Errorf("IGMPv3 query mismatch: %#v", igmp)
+	}
+}
+
+func TestIGMPv3RoundTrip(t *testing.T) {
+	for _, igmp := range []*IGMP{
+		{
+			Type:                    IGMPMembershipQuery,
+			MaxResponseTime:         25600 * time.Millisecond,
+			GroupAddress:            net.IP{232, 1, 1, 1},
+			SupressRouterProcessing: true,
+			RobustnessValue:         2,
+			IntervalTime:            125 * time.Second,
+			SourceAddresses:         []net.IP{{192, 0, 2, 1}, {192, 0, 2, 2}},
+			NumberOfSources:         2,
+			Version:                 3,
+		},
+		{
+			Type:                 IGMPMembershipReportV3,
+			NumberOfGroupRecords: 2,
+			GroupRecords: []IGMPv3GroupRecord{
+				{
+					Type:             IGMPAllow,
+					NumberOfSources:  1,
+					MulticastAddress: net.IP{232, 1, 1, 1},
+					SourceAddresses:  []net.IP{{192, 0, 2, 1}},
+				},
+				{
+					Type:             IGMPToEx,
+					AuxDataLen:       1,
+					MulticastAddress: net.IP{239, 1, 2, 3},
+					AuxData:          []byte{1, 2, 3, 4},
+				},
+			},
+			Version: 3,
+		},
+	} {
+		buf := gopacket.NewSerializeBuffer()
+		if err := igmp.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}); err != nil {
+			t.Fatal(err)
+		}
+		p := gopacket.NewPacket(buf.Bytes(), LayerTypeIGMP, testDecodeOptions)
+		if p.ErrorLayer() != nil {
+			t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+		}
+		got := p.Layer(LayerTypeIGMP).(*IGMP)
+		igmp.BaseLayer = got.BaseLayer
+		if !reflect.DeepEqual(got, igmp) {
+			t.Errorf("%v mismatch:\ngot  %#v\nwant %#v", igmp.Type, got, igmp)
+		}
+		if valid, err := got.VerifyChecksum(); !valid || err != nil {
+			t.Errorf("%v checksum invalid: %v", igmp.Type, err)
+		}
+	}
+}
+
+func TestIGMPTimeEncode(t *testing.T) {
+	for code := 0; code < 256; code++ {
+		d := igmpTimeDecode(uint8(code))
+		if got, err := igmpTimeEncode(d); err != nil || got != uint8(code) {
+			t.Errorf("code %#x: encoded %v as %#x, %v", code, d, got, err)
+		}
+	}
+	if _, err := igmpTimeEncode(time.Hour); err == nil {
+		t.Error("encoded out of range time")
+	}
+}
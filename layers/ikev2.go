@@ -0,0 +1,563 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// IKEv2ExchangeType is the exchange type of an IKE message.
+type IKEv2ExchangeType uint8
+
+// IKEv2 exchange types, RFC 7296 section 3.1 and RFC 9242.
+const (
+	IKEv2ExchangeIKESAInit       IKEv2ExchangeType = 34
+	IKEv2ExchangeIKEAuth         IKEv2ExchangeType = 35
+	IKEv2ExchangeCreateChildSA   IKEv2ExchangeType = 36
+	IKEv2ExchangeInformational   IKEv2ExchangeType = 37
+	IKEv2ExchangeIKEIntermediate IKEv2ExchangeType = 43
+)
+
+func (e IKEv2ExchangeType) String() string {
+	switch e {
+	case IKEv2ExchangeIKESAInit:
+		return "IKE_SA_INIT"
+	case IKEv2ExchangeIKEAuth:
+		return "IKE_AUTH"
+	case IKEv2ExchangeCreateChildSA:
+		return "CREATE_CHILD_SA"
+	case IKEv2ExchangeInformational:
+		return "INFORMATIONAL"
+	case IKEv2ExchangeIKEIntermediate:
+		return "IKE_INTERMEDIATE"
+	}
+	return fmt.Sprintf("IKEv2ExchangeType(%d)", uint8(e))
+}
+
+// IKEv2 header flags
+const (
+	IKEv2FlagInitiator uint8 = 0x08
+	IKEv2FlagVersion   uint8 = 0x10
+	IKEv2FlagResponse  uint8 = 0x20
+)
+
+// IKEv2PayloadType is the type of an IKE payload.
+type IKEv2PayloadType uint8
+
+// IKEv2 payload types, RFC 7296 section 3.2 and RFC 7383.
+const (
+	IKEv2PayloadNone     IKEv2PayloadType = 0
+	IKEv2PayloadSA       IKEv2PayloadType = 33
+	IKEv2PayloadKE       IKEv2PayloadType = 34
+	IKEv2PayloadIDi      IKEv2PayloadType = 35
+	IKEv2PayloadIDr      IKEv2PayloadType = 36
+	IKEv2PayloadCERT     IKEv2PayloadType = 37
+	IKEv2PayloadCERTREQ  IKEv2PayloadType = 38
+	IKEv2PayloadAUTH     IKEv2PayloadType = 39
+	IKEv2PayloadNonce    IKEv2PayloadType = 40
+	IKEv2PayloadNotify   IKEv2PayloadType = 41
+	IKEv2PayloadDelete   IKEv2PayloadType = 42
+	IKEv2PayloadVendorID IKEv2PayloadType = 43
+	IKEv2PayloadTSi      IKEv2PayloadType = 44
+	IKEv2PayloadTSr      IKEv2PayloadType = 45
+	IKEv2PayloadSK       IKEv2PayloadType = 46
+	IKEv2PayloadCP       IKEv2PayloadType = 47
+	IKEv2PayloadEAP      IKEv2PayloadType = 48
+	IKEv2PayloadSKF      IKEv2PayloadType = 53
+)
+
+// ikev2PayloadTypeNames are the names of the payload types SA to EAP
+var ikev2PayloadTypeNames = [...]string{"SA", "KE", "IDi", "IDr", "CERT", "CERTREQ", "AUTH", "Nonce",
+	"Notify", "Delete", "VendorID", "TSi", "TSr", "SK", "CP", "EAP"}
+
+func (t IKEv2PayloadType) String() string {
+	switch {
+	case t == IKEv2PayloadNone:
+		return "None"
+	case t == IKEv2PayloadSKF:
+		return "SKF"
+	case t >= IKEv2PayloadSA && t <= IKEv2PayloadEAP:
+		return ikev2PayloadTypeNames[t-IKEv2PayloadSA]
+	}
+	return fmt.Sprintf("IKEv2PayloadType(%d)", uint8(t))
+}
+
+// IKEv2ProtocolID is the IPsec protocol of a proposal, notification or
+// deletion.
+type IKEv2ProtocolID uint8
+
+// IKEv2 protocol IDs
+const (
+	IKEv2ProtocolIKE IKEv2ProtocolID = 1
+	IKEv2ProtocolAH  IKEv2ProtocolID = 2
+	IKEv2ProtocolESP IKEv2ProtocolID = 3
+)
+
+func (p IKEv2ProtocolID) String() string {
+	switch p {
+	case IKEv2ProtocolIKE:
+		return "IKE"
+	case IKEv2ProtocolAH:
+		return "AH"
+	case IKEv2ProtocolESP:
+		return "ESP"
+	}
+	return fmt.Sprintf("IKEv2ProtocolID(%d)", uint8(p))
+}
+
+// IKEv2TransformType is the type of a transform of a proposal.
+type IKEv2TransformType uint8
+
+// IKEv2 transform types, RFC 7296 section 3.3.2.  The transform IDs of each
+// type are listed in the IANA IKEv2 registry.
+const (
+	IKEv2TransformEncryption IKEv2TransformType = 1
+	IKEv2TransformPRF        IKEv2TransformType = 2
+	IKEv2TransformIntegrity  IKEv2TransformType = 3
+	IKEv2TransformKE         IKEv2TransformType = 4
+	IKEv2TransformESN        IKEv2TransformType = 5
+)
+
+func (t IKEv2TransformType) String() string {
+	switch t {
+	case IKEv2TransformEncryption:
+		return "ENCR"
+	case IKEv2TransformPRF:
+		return "PRF"
+	case IKEv2TransformIntegrity:
+		return "INTEG"
+	case IKEv2TransformKE:
+		return "KE"
+	case IKEv2TransformESN:
+		return "ESN"
+	}
+	return fmt.Sprintf("IKEv2TransformType(%d)", uint8(t))
+}
+
+// IKEv2Transform is a transform of a proposal.
+type IKEv2Transform struct {
+	Type IKEv2TransformType
+	ID   uint16
+	// KeyLength is the key length attribute of encryption transforms with
+	// variable key lengths, like AES-CBC
+	KeyLength uint16
+	// Attributes are the attributes of the transform
+	Attributes []byte
+}
+
+// IKEv2Proposal is a proposal of an SA payload, RFC 7296 section 3.3.1.
+type IKEv2Proposal struct {
+	Number     uint8
+	ProtocolID IKEv2ProtocolID
+	SPI        []byte
+	Transforms []IKEv2Transform
+}
+
+// IKEv2IDType is the type of an identification payload.
+type IKEv2IDType uint8
+
+// IKEv2 identification types, RFC 7296 section 3.5.
+const (
+	IKEv2IDIPv4Addr   IKEv2IDType = 1
+	IKEv2IDFQDN       IKEv2IDType = 2
+	IKEv2IDRFC822Addr IKEv2IDType = 3
+	IKEv2IDIPv6Addr   IKEv2IDType = 5
+	IKEv2IDDERASN1DN  IKEv2IDType = 9
+	IKEv2IDDERASN1GN  IKEv2IDType = 10
+	IKEv2IDKeyID      IKEv2IDType = 11
+	IKEv2IDFCName     IKEv2IDType = 12
+	IKEv2IDNull       IKEv2IDType = 13
+)
+
+func (t IKEv2IDType) String() string {
+	switch t {
+	case IKEv2IDIPv4Addr:
+		return "ID_IPV4_ADDR"
+	case IKEv2IDFQDN:
+		return "ID_FQDN"
+	case IKEv2IDRFC822Addr:
+		return "ID_RFC822_ADDR"
+	case IKEv2IDIPv6Addr:
+		return "ID_IPV6_ADDR"
+	case IKEv2IDDERASN1DN:
+		return "ID_DER_ASN1_DN"
+	case IKEv2IDDERASN1GN:
+		return "ID_DER_ASN1_GN"
+	case IKEv2IDKeyID:
+		return "ID_KEY_ID"
+	case IKEv2IDFCName:
+		return "ID_FC_NAME"
+	case IKEv2IDNull:
+		return "ID_NULL"
+	}
+	return fmt.Sprintf("IKEv2IDType(%d)", uint8(t))
+}
+
+// IKEv2NotifyType is the type of a Notify payload.  Types below 16384 are
+// errors.
+type IKEv2NotifyType uint16
+
+// Some IKEv2 notify types, RFC 7296 section 3.10.1 and others.
+const (
+	IKEv2NotifyUnsupportedCriticalPayload  IKEv2NotifyType = 1
+	IKEv2NotifyInvalidSyntax               IKEv2NotifyType = 7
+	IKEv2NotifyNoProposalChosen            IKEv2NotifyType = 14
+	IKEv2NotifyInvalidKEPayload            IKEv2NotifyType = 17
+	IKEv2NotifyAuthenticationFailed        IKEv2NotifyType = 24
+	IKEv2NotifyTSUnacceptable              IKEv2NotifyType = 38
+	IKEv2NotifyInitialContact              IKEv2NotifyType = 16384
+	IKEv2NotifyNATDetectionSourceIP        IKEv2NotifyType = 16388
+	IKEv2NotifyNATDetectionDestinationIP   IKEv2NotifyType = 16389
+	IKEv2NotifyCookie                      IKEv2NotifyType = 16390
+	IKEv2NotifyUseTransportMode            IKEv2NotifyType = 16391
+	IKEv2NotifyRekeySA                     IKEv2NotifyType = 16393
+	IKEv2NotifyESPTFCPaddingNotSupported   IKEv2NotifyType = 16394
+	IKEv2NotifyMOBIKESupported             IKEv2NotifyType = 16396
+	IKEv2NotifyIKEv2FragmentationSupported IKEv2NotifyType = 16430
+	IKEv2NotifySignatureHashAlgorithms     IKEv2NotifyType = 16431
+)
+
+var ikev2NotifyTypeNames = map[IKEv2NotifyType]string{
+	IKEv2NotifyUnsupportedCriticalPayload:  "UNSUPPORTED_CRITICAL_PAYLOAD",
+	IKEv2NotifyInvalidSyntax:               "INVALID_SYNTAX",
+	IKEv2NotifyNoProposalChosen:            "NO_PROPOSAL_CHOSEN",
+	IKEv2NotifyInvalidKEPayload:            "INVALID_KE_PAYLOAD",
+	IKEv2NotifyAuthenticationFailed:        "AUTHENTICATION_FAILED",
+	IKEv2NotifyTSUnacceptable:              "TS_UNACCEPTABLE",
+	IKEv2NotifyInitialContact:              "INITIAL_CONTACT",
+	IKEv2NotifyNATDetectionSourceIP:        "NAT_DETECTION_SOURCE_IP",
+	IKEv2NotifyNATDetectionDestinationIP:   "NAT_DETECTION_DESTINATION_IP",
+	IKEv2NotifyCookie:                      "COOKIE",
+	IKEv2NotifyUseTransportMode:            "USE_TRANSPORT_MODE",
+	IKEv2NotifyRekeySA:                     "REKEY_SA",
+	IKEv2NotifyESPTFCPaddingNotSupported:   "ESP_TFC_PADDING_NOT_SUPPORTED",
+	IKEv2NotifyMOBIKESupported:             "MOBIKE_SUPPORTED",
+	IKEv2NotifyIKEv2FragmentationSupported: "IKEV2_FRAGMENTATION_SUPPORTED",
+	IKEv2NotifySignatureHashAlgorithms:     "SIGNATURE_HASH_ALGORITHMS",
+}
+
+func (t IKEv2NotifyType) String() string {
+	if name, ok := ikev2NotifyTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("IKEv2NotifyType(%d)", uint16(t))
+}
+
+// IsError returns true for the notify types reporting errors.
+func (t IKEv2NotifyType) IsError() bool {
+	return t < 16384
+}
+
+// IKEv2TrafficSelector is a traffic selector of a TSi or TSr payload, RFC
+// 7296 section 3.13.1.
+type IKEv2TrafficSelector struct {
+	// Type is 7 for IPv4 address ranges and 8 for IPv6 ones
+	Type                     uint8
+	IPProtocol               IPProtocol
+	StartPort, EndPort       uint16
+	StartAddress, EndAddress net.IP
+}
+
+// IKEv2Payload is a payload of an IKE message.  The fields of the payload
+// types decoded are set according to Type.
+type IKEv2Payload struct {
+	Type     IKEv2PayloadType
+	Critical bool
+	// Data is the payload without its generic header
+	Data []byte
+
+	// Proposals are the proposals of SA payloads
+	Proposals []IKEv2Proposal
+	// DHGroup and KeyExchange are the group and the key exchange data of KE
+	// payloads
+	DHGroup     uint16
+	KeyExchange []byte
+	// IDType and Identification are the identity of IDi and IDr payloads
+	IDType         IKEv2IDType
+	Identification []byte
+	// ProtocolID, SPI, NotifyType and NotifyData are the fields of Notify
+	// payloads; ProtocolID and SPIs are the ones of Delete payloads
+	ProtocolID IKEv2ProtocolID
+	SPI        []byte
+	NotifyType IKEv2NotifyType
+	NotifyData []byte
+	SPIs       [][]byte
+	// TrafficSelectors are the traffic selectors of TSi and TSr payloads
+	TrafficSelectors []IKEv2TrafficSelector
+	// InnerPayload is the type of the first payload encrypted in SK and SKF
+	// payloads, whose Data is the IV, the encrypted payloads and the ICV
+	InnerPayload IKEv2PayloadType
+	// FragmentNumber and TotalFragments number the fragments of SKF
+	// payloads, RFC 7383
+	FragmentNumber, TotalFragments uint16
+}
+
+// IKEv2 is an IKE message, RFC 7296.  The payloads of IKEv2 messages are
+// decoded; messages of other major versions, like those of IKEv1, only have
+// their header decoded, their payloads being the payload of the layer.
+// Encrypted payloads end the payload chain.
+type IKEv2 struct {
+	BaseLayer
+	InitiatorSPI, ResponderSPI uint64
+	NextPayload                IKEv2PayloadType
+	MajorVersion, MinorVersion uint8
+	ExchangeType               IKEv2ExchangeType
+	Flags                      uint8
+	MessageID                  uint32
+	Length                     uint32
+	Payloads                   []IKEv2Payload
+}
+
+// LayerType returns LayerTypeIKEv2.
+func (i *IKEv2) LayerType() gopacket.LayerType { return LayerTypeIKEv2 }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (i *IKEv2) CanDecode() gopacket.LayerClass { return LayerTypeIKEv2 }
+
+// NextLayerType returns gopacket.LayerTypePayload for messages of other
+// versions than 2, and gopacket.LayerTypeZero for IKEv2 messages.
+func (i *IKEv2) NextLayerType() gopacket.LayerType {
+	if len(i.BaseLayer.Payload) > 0 {
+		return gopacket.LayerTypePayload
+	}
+	return gopacket.LayerTypeZero
+}
+
+// Payload returns the payloads of messages of other versions than 2.
+func (i *IKEv2) Payload() []byte { return i.BaseLayer.Payload }
+
+// IsResponse returns true if the Response flag is set.
+func (i *IKEv2) IsResponse() bool { return i.Flags&IKEv2FlagResponse != 0 }
+
+// IsInitiator returns true if the Initiator flag is set, for messages sent
+// by the original initiator of the IKE SA.
+func (i *IKEv2) IsInitiator() bool { return i.Flags&IKEv2FlagInitiator != 0 }
+
+// DecodeFromBytes decodes the slice into the IKEv2 struct.
+func (i *IKEv2) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 28 {
+		df.SetTruncated()
+		return errors.New("IKE message too short")
+	}
+	*i = IKEv2{
+		InitiatorSPI: binary.BigEndian.Uint64(data[0:8]),
+		ResponderSPI: binary.BigEndian.Uint64(data[8:16]),
+		NextPayload:  IKEv2PayloadType(data[16]),
+		MajorVersion: data[17] >> 4,
+		MinorVersion: data[17] & 0x0f,
+		ExchangeType: IKEv2ExchangeType(data[18]),
+		Flags:        data[19],
+		MessageID:    binary.BigEndian.Uint32(data[20:24]),
+		Length:       binary.BigEndian.Uint32(data[24:28]),
+	}
+	if i.Length < 28 {
+		return fmt.Errorf("invalid IKE message length %d", i.Length)
+	}
+	if uint32(len(data)) < i.Length {
+		df.SetTruncated()
+		return errors.New("IKE message truncated")
+	}
+	i.Contents = data[:28]
+	i.BaseLayer.Payload = data[28:i.Length]
+	if i.MajorVersion != 2 {
+		return nil
+	}
+	i.Contents, i.BaseLayer.Payload = data[:i.Length], nil
+	return i.decodePayloads(data[28:i.Length])
+}
+
+// decodePayloads decodes the payload chain of an IKEv2 message.
+func (i *IKEv2) decodePayloads(data []byte) error {
+	next := i.NextPayload
+	for next != IKEv2PayloadNone {
+		if len(data) < 4 {
+			return fmt.Errorf("IKEv2 %v payload missing", next)
+		}
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if length < 4 || length > len(data) {
+			return fmt.Errorf("invalid IKEv2 %v payload length %d", next, length)
+		}
+		p := IKEv2Payload{
+			Type:     next,
+			Critical: data[1]&0x80 != 0,
+			Data:     data[4:length],
+		}
+		following := IKEv2PayloadType(data[0])
+		if err := p.decode(following); err != nil {
+			return err
+		}
+		i.Payloads = append(i.Payloads, p)
+		data = data[length:]
+		if next == IKEv2PayloadSK || next == IKEv2PayloadSKF {
+			break
+		}
+		next = following
+	}
+	return nil
+}
+
+var errIKEv2Malformed = errors.New("IKEv2 payload malformed")
+
+// decode decodes the fields of the payload types known.  next is the next
+// payload field of the generic header.
+func (p *IKEv2Payload) decode(next IKEv2PayloadType) error {
+	data := p.Data
+	switch p.Type {
+	case IKEv2PayloadSA:
+		return p.decodeProposals()
+	case IKEv2PayloadKE:
+		if len(data) < 4 {
+			return errIKEv2Malformed
+		}
+		p.DHGroup = binary.BigEndian.Uint16(data[0:2])
+		p.KeyExchange = data[4:]
+	case IKEv2PayloadIDi, IKEv2PayloadIDr:
+		if len(data) < 4 {
+			return errIKEv2Malformed
+		}
+		p.IDType = IKEv2IDType(data[0])
+		p.Identification = data[4:]
+	case IKEv2PayloadNotify:
+		if len(data) < 4 || len(data) < 4+int(data[1]) {
+			return errIKEv2Malformed
+		}
+		p.ProtocolID = IKEv2ProtocolID(data[0])
+		p.NotifyType = IKEv2NotifyType(binary.BigEndian.Uint16(data[2:4]))
+		p.SPI = data[4 : 4+int(data[1])]
+		p.NotifyData = data[4+int(data[1]):]
+	case IKEv2PayloadDelete:
+		if len(data) < 4 {
+			return errIKEv2Malformed
+		}
+		p.ProtocolID = IKEv2ProtocolID(data[0])
+		size, n := int(data[1]), int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) != 4+size*n {
+			return errIKEv2Malformed
+		}
+		for j := 0; j < n; j++ {
+			p.SPIs = append(p.SPIs, data[4+j*size:4+(j+1)*size])
+		}
+	case IKEv2PayloadTSi, IKEv2PayloadTSr:
+		return p.decodeTrafficSelectors()
+	case IKEv2PayloadSK:
+		p.InnerPayload = next
+	case IKEv2PayloadSKF:
+		if len(data) < 4 {
+			return errIKEv2Malformed
+		}
+		p.InnerPayload = next
+		p.FragmentNumber = binary.BigEndian.Uint16(data[0:2])
+		p.TotalFragments = binary.BigEndian.Uint16(data[2:4])
+	}
+	return nil
+}
+
+// decodeProposals decodes the proposals of an SA payload, RFC 7296 section
+// 3.3.
+func (p *IKEv2Payload) decodeProposals() error {
+	data := p.Data
+	for last := false; !last; {
+		if len(data) < 8 {
+			return errIKEv2Malformed
+		}
+		last = data[0] == 0
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		spiSize := int(data[6])
+		if length < 8+spiSize || length > len(data) {
+			return errIKEv2Malformed
+		}
+		proposal := IKEv2Proposal{
+			Number:     data[4],
+			ProtocolID: IKEv2ProtocolID(data[5]),
+			SPI:        data[8 : 8+spiSize],
+		}
+		transforms := data[8+spiSize : length]
+		for n := int(data[7]); n > 0; n-- {
+			if len(transforms) < 8 {
+				return errIKEv2Malformed
+			}
+			tl := int(binary.BigEndian.Uint16(transforms[2:4]))
+			if tl < 8 || tl > len(transforms) {
+				return errIKEv2Malformed
+			}
+			t := IKEv2Transform{
+				Type:       IKEv2TransformType(transforms[4]),
+				ID:         binary.BigEndian.Uint16(transforms[6:8]),
+				Attributes: transforms[8:tl],
+			}
+			// the key length is the only attribute defined, in TV format
+			attrs := t.Attributes
+			for len(attrs) >= 4 && attrs[0]&0x80 != 0 {
+				if binary.BigEndian.Uint16(attrs)&0x7fff == 14 {
+					t.KeyLength = binary.BigEndian.Uint16(attrs[2:4])
+				}
+				attrs = attrs[4:]
+			}
+			proposal.Transforms = append(proposal.Transforms, t)
+			transforms = transforms[tl:]
+		}
+		if len(transforms) > 0 {
+			return errIKEv2Malformed
+		}
+		p.Proposals = append(p.Proposals, proposal)
+		data = data[length:]
+	}
+	return nil
+}
+
+// decodeTrafficSelectors decodes the traffic selectors of a TSi or TSr
+// payload, RFC 7296 section 3.13.
+func (p *IKEv2Payload) decodeTrafficSelectors() error {
+	if len(p.Data) < 4 {
+		return errIKEv2Malformed
+	}
+	data := p.Data[4:]
+	for n := int(p.Data[0]); n > 0; n-- {
+		if len(data) < 8 {
+			return errIKEv2Malformed
+		}
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if length > len(data) {
+			return errIKEv2Malformed
+		}
+		ts := IKEv2TrafficSelector{
+			Type:       data[0],
+			IPProtocol: IPProtocol(data[1]),
+			StartPort:  binary.BigEndian.Uint16(data[4:6]),
+			EndPort:    binary.BigEndian.Uint16(data[6:8]),
+		}
+		switch {
+		case ts.Type == 7 && length == 16:
+			ts.StartAddress, ts.EndAddress = net.IP(data[8:12]), net.IP(data[12:16])
+		case ts.Type == 8 && length == 40:
+			ts.StartAddress, ts.EndAddress = net.IP(data[8:24]), net.IP(data[24:40])
+		case length < 8:
+			return errIKEv2Malformed
+		}
+		p.TrafficSelectors = append(p.TrafficSelectors, ts)
+		data = data[length:]
+	}
+	return nil
+}
+
+func decodeIKEv2(data []byte, p gopacket.PacketBuilder) error {
+	i := &IKEv2{}
+	if err := i.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(i)
+	p.SetApplicationLayer(i)
+	if next := i.NextLayerType(); next != gopacket.LayerTypeZero {
+		return p.NextDecoder(next)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// ikev2TestMessage returns an IKE message of the given version with the
+// payloads, which start with the next payload type of their generic header.
+func ikev2TestMessage(version uint8, exchange IKEv2ExchangeType, flags uint8, first IKEv2PayloadType, payloads ...[]byte) []byte {
+	m := make([]byte, 28)
+	binary.BigEndian.PutUint64(m, 0x0102030405060708)
+	m[16], m[17], m[18], m[19] = byte(first), version, byte(exchange), flags
+	m = append(m, bytes.Join(payloads, nil)...)
+	binary.BigEndian.PutUint32(m[24:], uint32(len(m)))
+	return m
+}
+
+// ikev2TestPayload returns a payload with a generic header.
+func ikev2TestPayload(next IKEv2PayloadType, data ...[]byte) []byte {
+	d := bytes.Join(data, nil)
+	return append([]byte{byte(next), 0, byte((len(d) + 4) >> 8), byte(len(d) + 4)}, d...)
+}
+
+// ikev2TestSubstructure returns a proposal or transform: a last flag,
+// a reserved byte and a length, followed by data.
+func ikev2TestSubstructure(more byte, data ...[]byte) []byte {
+	d := bytes.Join(data, nil)
+	return append([]byte{more, 0, byte((len(d) + 4) >> 8), byte(len(d) + 4)}, d...)
+}
+
+var testIKEv2SAInit = ikev2TestMessage(0x20, IKEv2ExchangeIKESAInit, IKEv2FlagInitiator, IKEv2PayloadSA,
+	ikev2TestPayload(IKEv2PayloadKE, ikev2TestSubstructure(0, []byte{1, 1, 0, 4},
+		ikev2TestSubstructure(3, []byte{1, 0, 0, 12, 0x80, 0x0e, 0x01, 0x00}),
+		ikev2TestSubstructure(3, []byte{2, 0, 0, 5}),
+		ikev2TestSubstructure(3, []byte{3, 0, 0, 12}),
+		ikev2TestSubstructure(0, []byte{4, 0, 0, 19}))),
+	ikev2TestPayload(IKEv2PayloadNonce, []byte{0, 19, 0, 0}, bytes.Repeat([]byte{0x4b}, 64)),
+	ikev2TestPayload(IKEv2PayloadNotify, bytes.Repeat([]byte{0x4e}, 32)),
+	ikev2TestPayload(IKEv2PayloadNone, []byte{0, 0, 0x40, 0x04}, bytes.Repeat([]byte{0x11}, 20)))
+
+func TestIKEv2SAInit(t *testing.T) {
+	data, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 500, DstPort: 500}).Payload(testIKEv2SAInit).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeIKEv2}, t)
+	ike := p.Layer(LayerTypeIKEv2).(*IKEv2)
+	if ike.InitiatorSPI != 0x0102030405060708 || ike.ResponderSPI != 0 || ike.MajorVersion != 2 || ike.MinorVersion != 0 ||
+		ike.ExchangeType != IKEv2ExchangeIKESAInit || !ike.IsInitiator() || ike.IsResponse() || int(ike.Length) != len(testIKEv2SAInit) {
+		t.Errorf("unexpected header %#v", ike)
+	}
+	var types []IKEv2PayloadType
+	for _, payload := range ike.Payloads {
+		types = append(types, payload.Type)
+	}
+	if !reflect.DeepEqual(types, []IKEv2PayloadType{IKEv2PayloadSA, IKEv2PayloadKE, IKEv2PayloadNonce, IKEv2PayloadNotify}) {
+		t.Fatalf("unexpected payloads %v", types)
+	}
+	want := []IKEv2Proposal{{
+		Number:     1,
+		ProtocolID: IKEv2ProtocolIKE,
+		SPI:        []byte{},
+		Transforms: []IKEv2Transform{
+			{Type: IKEv2TransformEncryption, ID: 12, KeyLength: 256, Attributes: []byte{0x80, 0x0e, 0x01, 0x00}},
+			{Type: IKEv2TransformPRF, ID: 5, Attributes: []byte{}},
+			{Type: IKEv2TransformIntegrity, ID: 12, Attributes: []byte{}},
+			{Type: IKEv2TransformKE, ID: 19, Attributes: []byte{}},
+		},
+	}}
+	if !reflect.DeepEqual(ike.Payloads[0].Proposals, want) {
+		t.Errorf("unexpected proposals %+v", ike.Payloads[0].Proposals)
+	}
+	if ke := ike.Payloads[1]; ke.DHGroup != 19 || len(ke.KeyExchange) != 64 {
+		t.Errorf("unexpected KE payload %+v", ke)
+	}
+	if n := ike.Payloads[3]; n.NotifyType != IKEv2NotifyNATDetectionSourceIP || n.NotifyType.String() != "NAT_DETECTION_SOURCE_IP" ||
+		n.NotifyType.IsError() || len(n.SPI) != 0 || len(n.NotifyData) != 20 {
+		t.Errorf("unexpected Notify payload %+v", n)
+	}
+}
+
+func TestIKEv2Payloads(t *testing.T) {
+	data := ikev2TestMessage(0x20, IKEv2ExchangeInformational, IKEv2FlagResponse, IKEv2PayloadIDi,
+		ikev2TestPayload(IKEv2PayloadTSi, []byte{byte(IKEv2IDFQDN), 0, 0, 0}, []byte("vpn.example.com")),
+		ikev2TestPayload(IKEv2PayloadDelete, []byte{1, 0, 0, 0},
+			[]byte{7, 17, 0, 16, 0, 0, 0xff, 0xff, 10, 0, 0, 0, 10, 0, 0, 255}),
+		ikev2TestPayload(IKEv2PayloadSK, []byte{3, 4, 0, 2}, []byte{1, 2, 3, 4, 5, 6, 7, 8}),
+		ikev2TestPayload(IKEv2PayloadIDr, bytes.Repeat([]byte{0xee}, 32)))
+	ike := &IKEv2{}
+	if err := ike.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if len(ike.Payloads) != 4 || !ike.IsResponse() {
+		t.Fatalf("unexpected message %+v", ike)
+	}
+	if id := ike.Payloads[0]; id.IDType != IKEv2IDFQDN || string(id.Identification) != "vpn.example.com" {
+		t.Errorf("unexpected IDi payload %+v", id)
+	}
+	ts := IKEv2TrafficSelector{Type: 7, IPProtocol: IPProtocolUDP, StartPort: 0, EndPort: 0xffff,
+		StartAddress: net.IP{10, 0, 0, 0}, EndAddress: net.IP{10, 0, 0, 255}}
+	if p := ike.Payloads[1]; !reflect.DeepEqual(p.TrafficSelectors, []IKEv2TrafficSelector{ts}) {
+		t.Errorf("unexpected traffic selectors %+v", p.TrafficSelectors)
+	}
+	if p := ike.Payloads[2]; p.ProtocolID != IKEv2ProtocolESP || !reflect.DeepEqual(p.SPIs, [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}}) {
+		t.Errorf("unexpected Delete payload %+v", p)
+	}
+	if p := ike.Payloads[3]; p.Type != IKEv2PayloadSK || p.InnerPayload != IKEv2PayloadIDr || len(p.Data) != 32 {
+		t.Errorf("unexpected SK payload %+v", p)
+	}
+
+	for _, data := range [][]byte{
+		testIKEv2SAInit[:27],
+		testIKEv2SAInit[:len(testIKEv2SAInit)-1],
+		ikev2TestMessage(0x20, IKEv2ExchangeIKESAInit, 0, IKEv2PayloadNonce),
+		ikev2TestMessage(0x20, IKEv2ExchangeIKESAInit, 0, IKEv2PayloadKE, ikev2TestPayload(IKEv2PayloadNone, []byte{0, 19})),
+		ikev2TestMessage(0x20, IKEv2ExchangeIKESAInit, 0, IKEv2PayloadSA, ikev2TestPayload(IKEv2PayloadNone,
+			ikev2TestSubstructure(0, []byte{1, 1, 0, 2}, ikev2TestSubstructure(0, []byte{1, 0, 0, 12})))),
+	} {
+		if err := (&IKEv2{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
+
+func TestIKEv1(t *testing.T) {
+	// the payloads of IKEv1 messages aren't decoded
+	data := ikev2TestMessage(0x10, 2, 0, 1, ikev2TestPayload(0, []byte{0, 0, 0, 1}))
+	p := gopacket.NewPacket(data, LayerTypeIKEv2, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIKEv2, gopacket.LayerTypePayload}, t)
+	if ike := p.Layer(LayerTypeIKEv2).(*IKEv2); ike.MajorVersion != 1 || len(ike.Payloads) != 0 || len(ike.Payload()) != 8 {
+		t.Errorf("unexpected message %+v", ike)
+	}
+}
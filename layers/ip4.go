@@ -155,6 +155,15 @@ func (ip *IPv4) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeO
 	return nil
 }
 
+// VerifyChecksum verifies the checksum of the IPv4 header, implementing
+// gopacket.ChecksumVerifier.
+func (ip *IPv4) VerifyChecksum() (bool, error) {
+	if len(ip.Contents) < 20 {
+		return false, errors.New("Invalid (too small) IP header length")
+	}
+	return tcpipChecksumValid(ip.Contents, nil, 0), nil
+}
+
 func checksum(bytes []byte) uint16 {
 	// Clear checksum bytes
 	bytes[10] = 0
@@ -293,7 +302,11 @@ func decodeIPv4(data []byte, p gopacket.PacketBuilder) error {
 	if err != nil {
 		return err
 	}
-	return p.NextDecoder(ip.NextLayerType())
+	next := ip.NextLayerType()
+	if next == gopacket.LayerTypeZero {
+		return p.NextDecoder(gopacket.UnknownProtocolDecoder(gopacket.UnknownIPProtocol, int(ip.Protocol), next))
+	}
+	return p.NextDecoder(next)
 }
 
 func checkIPv4Address(addr net.IP) (net.IP, error) {
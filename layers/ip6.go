@@ -301,7 +301,15 @@ func decodeIPv6(data []byte, p gopacket.PacketBuilder) error {
 	if err != nil {
 		return err
 	}
-	return p.NextDecoder(ip6.NextLayerType())
+	next := ip6.NextLayerType()
+	if next == gopacket.LayerTypeZero {
+		proto := ip6.NextHeader
+		if ip6.HopByHop != nil {
+			proto = ip6.HopByHop.NextHeader
+		}
+		return p.NextDecoder(gopacket.UnknownProtocolDecoder(gopacket.UnknownIPProtocol, int(proto), next))
+	}
+	return p.NextDecoder(next)
 }
 
 type ipv6HeaderTLVOption struct {
@@ -547,6 +555,36 @@ func (o *IPv6HopByHopOption) SetJumboLength(len uint32) {
 	o.OptionAlignment = [2]uint8{4, 2}
 }
 
+// IPv6 routing types
+const (
+	IPv6RoutingTypeSource         uint8 = 0
+	IPv6RoutingTypeSegmentRouting uint8 = 4
+)
+
+// IPv6SegmentRoutingTLVType is the type of a TLV of a segment routing header.
+type IPv6SegmentRoutingTLVType uint8
+
+// IPv6 segment routing TLV types, RFC 8754 section 2.1
+const (
+	IPv6SegmentRoutingTLVPad1 IPv6SegmentRoutingTLVType = 0
+	IPv6SegmentRoutingTLVPadN IPv6SegmentRoutingTLVType = 4
+	IPv6SegmentRoutingTLVHMAC IPv6SegmentRoutingTLVType = 5
+)
+
+// IPv6SegmentRoutingTLV is a TLV of a segment routing header.  Pad1 TLVs are
+// the single byte of their type.  The fields of HMAC TLVs are decoded from
+// their value, and serialized into it.
+type IPv6SegmentRoutingTLV struct {
+	Type   IPv6SegmentRoutingTLVType
+	Length uint8
+	Value  []byte
+	// DestinationAddressOnly, HMACKeyID and HMAC are the D flag, key ID
+	// and HMAC of HMAC TLVs
+	DestinationAddressOnly bool
+	HMACKeyID              uint32
+	HMAC                   []byte
+}
+
 // IPv6Routing is the IPv6 routing extension.
 type IPv6Routing struct {
 	ipv6ExtensionBase
@@ -558,33 +596,174 @@ type IPv6Routing struct {
 	// SourceRoutingIPs is the set of IPv6 addresses requested for source routing,
 	// set only if RoutingType == 0.
 	SourceRoutingIPs []net.IP
+
+	// The following fields are set only if RoutingType == 4, for the segment
+	// routing header (SRH) of SRv6, RFC 8754.
+	LastEntry uint8
+	Flags     uint8
+	Tag       uint16
+	// Segments is the segment list, in reverse order: Segments[0] is the
+	// last segment of the path, and Segments[SegmentsLeft] the active one.
+	Segments []net.IP
+	TLVs     []IPv6SegmentRoutingTLV
 }
 
 // LayerType returns LayerTypeIPv6Routing.
 func (i *IPv6Routing) LayerType() gopacket.LayerType { return LayerTypeIPv6Routing }
 
-func decodeIPv6Routing(data []byte, p gopacket.PacketBuilder) error {
-	base, err := decodeIPv6ExtensionBase(data, p)
+// CanDecode implementation according to gopacket.DecodingLayer
+func (i *IPv6Routing) CanDecode() gopacket.LayerClass { return LayerTypeIPv6Routing }
+
+// NextLayerType implementation according to gopacket.DecodingLayer
+func (i *IPv6Routing) NextLayerType() gopacket.LayerType { return i.NextHeader.LayerType() }
+
+// DecodeFromBytes implementation according to gopacket.DecodingLayer
+func (i *IPv6Routing) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	base, err := decodeIPv6ExtensionBase(data, df)
 	if err != nil {
 		return err
 	}
-	i := &IPv6Routing{
+	*i = IPv6Routing{
 		ipv6ExtensionBase: base,
 		RoutingType:       data[2],
 		SegmentsLeft:      data[3],
 		Reserved:          data[4:8],
 	}
 	switch i.RoutingType {
-	case 0: // Source routing
+	case IPv6RoutingTypeSource:
 		if (i.ActualLength-8)%16 != 0 {
 			return fmt.Errorf("Invalid IPv6 source routing, length of type 0 packet %d", i.ActualLength)
 		}
 		for d := i.Contents[8:]; len(d) >= 16; d = d[16:] {
 			i.SourceRoutingIPs = append(i.SourceRoutingIPs, net.IP(d[:16]))
 		}
+	case IPv6RoutingTypeSegmentRouting:
+		return i.decodeSegmentRouting()
+	default:
+		return fmt.Errorf("Unknown IPv6 routing header type %d", i.RoutingType)
+	}
+	return nil
+}
+
+func (i *IPv6Routing) decodeSegmentRouting() error {
+	i.Reserved = nil
+	i.LastEntry = i.Contents[4]
+	i.Flags = i.Contents[5]
+	i.Tag = binary.BigEndian.Uint16(i.Contents[6:8])
+	n := 8 + 16*(int(i.LastEntry)+1)
+	if i.ActualLength < n {
+		return fmt.Errorf("Invalid IPv6 segment routing header, length %d for %d segments", i.ActualLength, int(i.LastEntry)+1)
+	}
+	if i.SegmentsLeft > i.LastEntry {
+		return fmt.Errorf("Invalid IPv6 segment routing header, %d segments left of %d", i.SegmentsLeft, int(i.LastEntry)+1)
+	}
+	for d := i.Contents[8:n]; len(d) > 0; d = d[16:] {
+		i.Segments = append(i.Segments, net.IP(d[:16]))
+	}
+	for d := i.Contents[n:]; len(d) > 0; {
+		tlv := IPv6SegmentRoutingTLV{Type: IPv6SegmentRoutingTLVType(d[0])}
+		if tlv.Type == IPv6SegmentRoutingTLVPad1 {
+			i.TLVs = append(i.TLVs, tlv)
+			d = d[1:]
+			continue
+		}
+		if len(d) < 2 || len(d) < 2+int(d[1]) {
+			return errors.New("Invalid IPv6 segment routing TLV length")
+		}
+		tlv.Length = d[1]
+		tlv.Value = d[2 : 2+int(tlv.Length)]
+		if tlv.Type == IPv6SegmentRoutingTLVHMAC {
+			if len(tlv.Value) < 6 {
+				return errors.New("Invalid IPv6 segment routing HMAC TLV length")
+			}
+			tlv.DestinationAddressOnly = tlv.Value[0]&0x80 != 0
+			tlv.HMACKeyID = binary.BigEndian.Uint32(tlv.Value[2:6])
+			tlv.HMAC = tlv.Value[6:]
+		}
+		i.TLVs = append(i.TLVs, tlv)
+		d = d[2+int(tlv.Length):]
+	}
+	return nil
+}
+
+// SerializeTo implementation according to gopacket.SerializableLayer.  With
+// FixLengths, the HeaderLength, and the LastEntry and the length of the TLVs
+// of segment routing headers, are set, and the TLVs padded.
+func (i *IPv6Routing) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	var body []byte
+	switch i.RoutingType {
+	case IPv6RoutingTypeSource:
+		body = make([]byte, 4, 4+16*len(i.SourceRoutingIPs))
+		copy(body, i.Reserved)
+		for _, ip := range i.SourceRoutingIPs {
+			body = append(body, ip.To16()...)
+		}
+	case IPv6RoutingTypeSegmentRouting:
+		if opts.FixLengths && len(i.Segments) > 0 {
+			i.LastEntry = uint8(len(i.Segments) - 1)
+		}
+		body = []byte{i.LastEntry, i.Flags, byte(i.Tag >> 8), byte(i.Tag)}
+		for _, ip := range i.Segments {
+			body = append(body, ip.To16()...)
+		}
+		for j := range i.TLVs {
+			tlv := &i.TLVs[j]
+			body = append(body, byte(tlv.Type))
+			if tlv.Type == IPv6SegmentRoutingTLVPad1 {
+				continue
+			}
+			value := tlv.Value
+			if tlv.Type == IPv6SegmentRoutingTLVHMAC {
+				value = make([]byte, 6, 6+len(tlv.HMAC))
+				if tlv.DestinationAddressOnly {
+					value[0] = 0x80
+				}
+				binary.BigEndian.PutUint32(value[2:], tlv.HMACKeyID)
+				value = append(value, tlv.HMAC...)
+			}
+			if opts.FixLengths {
+				tlv.Length = uint8(len(value))
+			}
+			body = append(body, tlv.Length)
+			body = append(body, value...)
+		}
+		if opts.FixLengths {
+			switch pad := (8 - (4+len(body))%8) % 8; pad {
+			case 0:
+			case 1:
+				body = append(body, byte(IPv6SegmentRoutingTLVPad1))
+			default:
+				body = append(body, byte(IPv6SegmentRoutingTLVPadN), byte(pad-2))
+				body = append(body, make([]byte, pad-2)...)
+			}
+		}
 	default:
 		return fmt.Errorf("Unknown IPv6 routing header type %d", i.RoutingType)
 	}
+	length := 4 + len(body)
+	if length%8 != 0 {
+		return errors.New("IPv6Routing actual length must be multiple of 8")
+	}
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	if opts.FixLengths {
+		i.HeaderLength = uint8(length/8 - 1)
+	}
+	bytes[0] = uint8(i.NextHeader)
+	bytes[1] = i.HeaderLength
+	bytes[2] = i.RoutingType
+	bytes[3] = i.SegmentsLeft
+	copy(bytes[4:], body)
+	return nil
+}
+
+func decodeIPv6Routing(data []byte, p gopacket.PacketBuilder) error {
+	i := &IPv6Routing{}
+	if err := i.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
 	p.AddLayer(i)
 	return p.NextDecoder(i.NextHeader)
 }
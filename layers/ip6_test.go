@@ -428,3 +428,76 @@ func TestIPv6JumbogramDecode(t *testing.T) {
 		t.Error("No Payload layer type found in packet")
 	}
 }
+
+func TestIPv6SegmentRouting(t *testing.T) {
+	segments := []net.IP{net.ParseIP("2001:db8::3"), net.ParseIP("2001:db8::2"), net.ParseIP("2001:db8::1")}
+	srh := &IPv6Routing{
+		RoutingType:  IPv6RoutingTypeSegmentRouting,
+		SegmentsLeft: 1,
+		Flags:        0x20,
+		Tag:          0x1234,
+		Segments:     segments,
+		TLVs: []IPv6SegmentRoutingTLV{
+			{Type: IPv6SegmentRoutingTLVPad1},
+			{Type: IPv6SegmentRoutingTLVHMAC, DestinationAddressOnly: true, HMACKeyID: 7, HMAC: bytes.Repeat([]byte{0xaa}, 32)},
+		},
+	}
+	srh.NextHeader = IPProtocolIPv6
+	data, err := Build().IPv6(&IPv6{NextHeader: IPProtocolIPv6Routing, DstIP: segments[1]}).Layer(srh).IPv6(nil).UDP(&UDP{SrcPort: 53, DstPort: 53}).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srh.LastEntry != 2 || srh.TLVs[1].Length != 38 || srh.HeaderLength != 12 {
+		t.Fatalf("lengths not fixed: %#v", srh)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv6, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv6, LayerTypeIPv6Routing, LayerTypeIPv6, LayerTypeUDP}, t)
+	got := p.Layer(LayerTypeIPv6Routing).(*IPv6Routing)
+	if got.SegmentsLeft != 1 || got.LastEntry != 2 || got.Flags != 0x20 || got.Tag != 0x1234 || len(got.Segments) != 3 || !got.Segments[got.SegmentsLeft].Equal(segments[1]) {
+		t.Errorf("unexpected SRH %#v", got)
+	}
+	// Pad1, HMAC and the PadN ending the header
+	if len(got.TLVs) != 3 || got.TLVs[0].Type != IPv6SegmentRoutingTLVPad1 || got.TLVs[2].Type != IPv6SegmentRoutingTLVPadN {
+		t.Fatalf("unexpected TLVs %#v", got.TLVs)
+	}
+	if h := got.TLVs[1]; !h.DestinationAddressOnly || h.HMACKeyID != 7 || !bytes.Equal(h.HMAC, srh.TLVs[1].HMAC) {
+		t.Errorf("unexpected HMAC TLV %#v", h)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := got.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), got.Contents) {
+		t.Errorf("serialized SRH\n%x, want\n%x", buf.Bytes(), got.Contents)
+	}
+
+	for _, data := range [][]byte{
+		{59, 2, 4, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{59, 2, 4, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{59, 3, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5, 4, 0, 0, 0, 0, 0, 0},
+	} {
+		if err := (&IPv6Routing{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
+
+func TestIPv6SourceRoutingSerialize(t *testing.T) {
+	r := &IPv6Routing{SourceRoutingIPs: []net.IP{net.ParseIP("2001:db8::1")}}
+	r.NextHeader = IPProtocolNoNextHeader
+	buf := gopacket.NewSerializeBuffer()
+	if err := r.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	got := &IPv6Routing{}
+	if err := got.DecodeFromBytes(buf.Bytes(), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if got.HeaderLength != 2 || len(got.SourceRoutingIPs) != 1 || !got.SourceRoutingIPs[0].Equal(r.SourceRoutingIPs[0]) {
+		t.Errorf("unexpected routing header %#v", got)
+	}
+}
@@ -0,0 +1,457 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/gopacket"
+)
+
+// IPFIX set IDs of template sets, data sets having the ID of their template,
+// from 256.
+const (
+	IPFIXTemplateSetID        = 2
+	IPFIXOptionsTemplateSetID = 3
+	ipfixMinDataSetID         = 256
+)
+
+// IPFIXVariableLength is the length of the field specifiers of variable
+// length fields, whose length is given by each record.
+const IPFIXVariableLength = 0xffff
+
+// IPFIX lengths
+const (
+	ipfixHeaderLength    = 16
+	ipfixSetHeaderLength = 4
+)
+
+// IPFIXField is a field specifier of an IPFIX template.  Enterprise is the
+// private enterprise number of enterprise specific information elements,
+// and zero for the IANA ones.
+type IPFIXField struct {
+	ID         uint16
+	Length     uint16 // IPFIXVariableLength for variable length fields
+	Enterprise uint32
+}
+
+// Element returns the registered information element of the field.
+func (f IPFIXField) Element() (IPFIXInformationElement, bool) {
+	return LookupIPFIXInformationElement(f.Enterprise, f.ID)
+}
+
+// IPFIXTemplate is an IPFIX template or options template, telling the
+// fields of the records of the data sets with its ID.  The first
+// ScopeFieldCount fields of options templates are scope fields.
+//
+// A template without fields withdraws the template with its ID, or all the
+// templates, or options templates, of the observation domain if its ID is
+// IPFIXTemplateSetID, or IPFIXOptionsTemplateSetID.
+type IPFIXTemplate struct {
+	ID              uint16
+	Options         bool
+	ScopeFieldCount uint16
+	Fields          []IPFIXField
+}
+
+// Withdrawal returns whether the template is a template withdrawal.
+func (t *IPFIXTemplate) Withdrawal() bool {
+	return len(t.Fields) == 0
+}
+
+// minRecordLength returns the minimum length of the records of the
+// template, variable length fields having at least their 1 byte length.
+func (t *IPFIXTemplate) minRecordLength() int {
+	n := 0
+	for _, f := range t.Fields {
+		if f.Length == IPFIXVariableLength {
+			n++
+		} else {
+			n += int(f.Length)
+		}
+	}
+	return n
+}
+
+// IPFIXFieldValue is the value of a field of an IPFIX data record.
+type IPFIXFieldValue struct {
+	Field IPFIXField // Length is IPFIXVariableLength for variable length fields
+	Scope bool
+	Value []byte
+}
+
+// Uint returns the value as an unsigned integer, for values of up to 8
+// bytes.
+func (v IPFIXFieldValue) Uint() uint64 {
+	var n uint64
+	for _, b := range v.Value {
+		n = n<<8 | uint64(b)
+	}
+	return n
+}
+
+// IPFIXRecord is a record of an IPFIX data set, holding the values of the
+// fields of its template.
+type IPFIXRecord struct {
+	Values []IPFIXFieldValue
+}
+
+// Value returns the value of the first non scope field with the IANA
+// information element id, or nil if the record has none.
+func (r *IPFIXRecord) Value(id uint16) []byte {
+	return r.EnterpriseValue(0, id)
+}
+
+// EnterpriseValue returns the value of the first non scope field with the
+// information element id of enterprise, or nil if the record has none.
+func (r *IPFIXRecord) EnterpriseValue(enterprise uint32, id uint16) []byte {
+	for _, v := range r.Values {
+		if v.Field.ID == id && v.Field.Enterprise == enterprise && !v.Scope {
+			return v.Value
+		}
+	}
+	return nil
+}
+
+// IPFIXSet is a set of an IPFIX message.  Template sets are decoded into
+// Templates, and data sets into Records if their template is known.  Data
+// holds the set after its header.
+type IPFIXSet struct {
+	ID        uint16
+	Length    uint16
+	Templates []IPFIXTemplate
+	Records   []IPFIXRecord
+	Data      []byte
+}
+
+// IPFIXTemplateCache stores the templates of IPFIX exporters, needed to
+// decode data sets sent after their templates.  Templates are scoped by the
+// exporter, usually its transport session, and the observation domain.
+type IPFIXTemplateCache interface {
+	// Template returns the template of exporter and domain with ID id,
+	// or nil if unknown.
+	Template(exporter string, domain uint32, id uint16) *IPFIXTemplate
+	// SetTemplate stores the template t of exporter and domain,
+	// replacing the one with the same ID.
+	SetTemplate(exporter string, domain uint32, t *IPFIXTemplate)
+	// WithdrawTemplate removes the template of exporter and domain with
+	// ID id.
+	WithdrawTemplate(exporter string, domain uint32, id uint16)
+	// WithdrawAllTemplates removes the templates, or options templates
+	// if options is set, of exporter and domain.
+	WithdrawAllTemplates(exporter string, domain uint32, options bool)
+}
+
+type ipfixTemplateKey struct {
+	exporter string
+	domain   uint32
+	id       uint16
+}
+
+type ipfixTemplateCache struct {
+	mu        sync.RWMutex
+	templates map[ipfixTemplateKey]*IPFIXTemplate
+}
+
+// NewIPFIXTemplateCache returns an empty IPFIXTemplateCache, which may be
+// used by several goroutines.
+func NewIPFIXTemplateCache() IPFIXTemplateCache {
+	return &ipfixTemplateCache{templates: make(map[ipfixTemplateKey]*IPFIXTemplate)}
+}
+
+func (c *ipfixTemplateCache) Template(exporter string, domain uint32, id uint16) *IPFIXTemplate {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.templates[ipfixTemplateKey{exporter, domain, id}]
+}
+
+func (c *ipfixTemplateCache) SetTemplate(exporter string, domain uint32, t *IPFIXTemplate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.templates[ipfixTemplateKey{exporter, domain, t.ID}] = t
+}
+
+func (c *ipfixTemplateCache) WithdrawTemplate(exporter string, domain uint32, id uint16) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.templates, ipfixTemplateKey{exporter, domain, id})
+}
+
+func (c *ipfixTemplateCache) WithdrawAllTemplates(exporter string, domain uint32, options bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, t := range c.templates {
+		if k.exporter == exporter && k.domain == domain && t.Options == options {
+			delete(c.templates, k)
+		}
+	}
+}
+
+// IPFIX is an IPFIX message, see RFC 7011.  Messages following it, as sent
+// over TCP, are decoded as further IPFIX layers.
+//
+// Data sets are decoded with the templates of Templates for Exporter, which
+// are updated by the template sets decoded, so that a DecodingLayer keeping
+// Templates and Exporter across messages decodes the data of templates sent
+// in previous messages.  Exporter identifies the transport session of the
+// exporter, such as its address and port, and is set by the user of the
+// DecodingLayer.  If Templates is nil, only the templates of the message
+// itself are used.
+type IPFIX struct {
+	BaseLayer
+	Version             uint16
+	Length              uint16
+	ExportTime          uint32 // Seconds since the Unix epoch
+	SequenceNumber      uint32 // Of the data records
+	ObservationDomainID uint32
+	Sets                []IPFIXSet
+
+	Exporter  string
+	Templates IPFIXTemplateCache
+}
+
+// LayerType returns LayerTypeIPFIX.
+func (i *IPFIX) LayerType() gopacket.LayerType { return LayerTypeIPFIX }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (i *IPFIX) CanDecode() gopacket.LayerClass { return LayerTypeIPFIX }
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (i *IPFIX) NextLayerType() gopacket.LayerType {
+	if len(i.Payload) > 0 {
+		return LayerTypeIPFIX
+	}
+	return gopacket.LayerTypeZero
+}
+
+func decodeIPFIX(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&IPFIX{}, data, p)
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (i *IPFIX) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < ipfixHeaderLength {
+		df.SetTruncated()
+		return errors.New("IPFIX header too short")
+	}
+	*i = IPFIX{
+		Version:             binary.BigEndian.Uint16(data[0:2]),
+		Length:              binary.BigEndian.Uint16(data[2:4]),
+		ExportTime:          binary.BigEndian.Uint32(data[4:8]),
+		SequenceNumber:      binary.BigEndian.Uint32(data[8:12]),
+		ObservationDomainID: binary.BigEndian.Uint32(data[12:16]),
+		Sets:                i.Sets[:0],
+		Exporter:            i.Exporter,
+		Templates:           i.Templates,
+	}
+	if i.Version != 10 {
+		return fmt.Errorf("unsupported IPFIX version %d", i.Version)
+	}
+	if i.Length < ipfixHeaderLength {
+		return fmt.Errorf("invalid IPFIX message length %d", i.Length)
+	}
+	if int(i.Length) > len(data) {
+		df.SetTruncated()
+		return fmt.Errorf("IPFIX message length %d too long", i.Length)
+	}
+
+	// Templates of this message, used if there is no cache
+	var local map[uint16]*IPFIXTemplate
+	template := func(id uint16) *IPFIXTemplate {
+		if i.Templates != nil {
+			return i.Templates.Template(i.Exporter, i.ObservationDomainID, id)
+		}
+		return local[id]
+	}
+
+	for sets := data[ipfixHeaderLength:i.Length]; len(sets) > 0; {
+		if len(sets) < ipfixSetHeaderLength {
+			df.SetTruncated()
+			return errors.New("IPFIX set header too short")
+		}
+		s := IPFIXSet{
+			ID:     binary.BigEndian.Uint16(sets[0:2]),
+			Length: binary.BigEndian.Uint16(sets[2:4]),
+		}
+		if s.Length < ipfixSetHeaderLength || int(s.Length) > len(sets) {
+			df.SetTruncated()
+			return fmt.Errorf("invalid IPFIX set %d length %d", s.ID, s.Length)
+		}
+		s.Data = sets[ipfixSetHeaderLength:s.Length]
+		sets = sets[s.Length:]
+
+		var err error
+		switch {
+		case s.ID == IPFIXTemplateSetID, s.ID == IPFIXOptionsTemplateSetID:
+			s.Templates, err = decodeIPFIXTemplates(s.Data, s.ID == IPFIXOptionsTemplateSetID)
+		case s.ID >= ipfixMinDataSetID:
+			if t := template(s.ID); t != nil {
+				s.Records, err = decodeIPFIXRecords(s.Data, t)
+			}
+		default:
+			err = fmt.Errorf("invalid IPFIX set ID %d", s.ID)
+		}
+		if err != nil {
+			return err
+		}
+		for j := range s.Templates {
+			i.updateTemplates(&s.Templates[j], &local)
+		}
+		i.Sets = append(i.Sets, s)
+	}
+
+	i.BaseLayer = BaseLayer{Contents: data[:i.Length], Payload: data[i.Length:]}
+	return nil
+}
+
+// updateTemplates applies the template, or template withdrawal, t to the
+// template cache, or to local if there is none.
+func (i *IPFIX) updateTemplates(t *IPFIXTemplate, local *map[uint16]*IPFIXTemplate) {
+	all := t.ID == IPFIXTemplateSetID || t.ID == IPFIXOptionsTemplateSetID
+	if i.Templates != nil {
+		switch {
+		case !t.Withdrawal():
+			i.Templates.SetTemplate(i.Exporter, i.ObservationDomainID, t)
+		case all:
+			i.Templates.WithdrawAllTemplates(i.Exporter, i.ObservationDomainID, t.Options)
+		default:
+			i.Templates.WithdrawTemplate(i.Exporter, i.ObservationDomainID, t.ID)
+		}
+		return
+	}
+	switch {
+	case !t.Withdrawal():
+		if *local == nil {
+			*local = make(map[uint16]*IPFIXTemplate)
+		}
+		(*local)[t.ID] = t
+	case all:
+		for id, lt := range *local {
+			if lt.Options == t.Options {
+				delete(*local, id)
+			}
+		}
+	default:
+		delete(*local, t.ID)
+	}
+}
+
+// decodeIPFIXFields decodes count field specifiers of data, returning them
+// with the remaining data.
+func decodeIPFIXFields(data []byte, count int) ([]IPFIXField, []byte, error) {
+	fields := make([]IPFIXField, 0, count)
+	for n := 0; n < count; n++ {
+		if len(data) < 4 {
+			return nil, nil, errors.New("IPFIX field specifier truncated")
+		}
+		f := IPFIXField{
+			ID:     binary.BigEndian.Uint16(data[0:2]) & 0x7fff,
+			Length: binary.BigEndian.Uint16(data[2:4]),
+		}
+		if data[0]&0x80 == 0 {
+			data = data[4:]
+		} else {
+			if len(data) < 8 {
+				return nil, nil, errors.New("IPFIX enterprise field specifier truncated")
+			}
+			f.Enterprise = binary.BigEndian.Uint32(data[4:8])
+			data = data[8:]
+		}
+		fields = append(fields, f)
+	}
+	return fields, data, nil
+}
+
+// decodeIPFIXTemplates decodes the template records, or options template
+// records, of a template set.
+func decodeIPFIXTemplates(data []byte, options bool) ([]IPFIXTemplate, error) {
+	var templates []IPFIXTemplate
+	setID := uint16(IPFIXTemplateSetID)
+	if options {
+		setID = IPFIXOptionsTemplateSetID
+	}
+	// Anything shorter than a template header is padding
+	for len(data) >= 4 {
+		t := IPFIXTemplate{ID: binary.BigEndian.Uint16(data[0:2]), Options: options}
+		count := int(binary.BigEndian.Uint16(data[2:4]))
+		if count == 0 {
+			// Template withdrawal, of all the templates of the set
+			// type if its ID is the set ID
+			if t.ID == 0 {
+				break
+			}
+			if t.ID < ipfixMinDataSetID && t.ID != setID {
+				return nil, fmt.Errorf("invalid IPFIX template withdrawal ID %d", t.ID)
+			}
+			templates = append(templates, t)
+			data = data[4:]
+			continue
+		}
+		if t.ID < ipfixMinDataSetID {
+			return nil, fmt.Errorf("invalid IPFIX template ID %d", t.ID)
+		}
+		data = data[4:]
+		if options {
+			if len(data) < 2 {
+				return nil, fmt.Errorf("IPFIX options template %d truncated", t.ID)
+			}
+			t.ScopeFieldCount = binary.BigEndian.Uint16(data[0:2])
+			if t.ScopeFieldCount == 0 || int(t.ScopeFieldCount) > count {
+				return nil, fmt.Errorf("invalid IPFIX options template %d scope field count %d", t.ID, t.ScopeFieldCount)
+			}
+			data = data[2:]
+		}
+		var err error
+		if t.Fields, data, err = decodeIPFIXFields(data, count); err != nil {
+			return nil, fmt.Errorf("IPFIX template %d: %v", t.ID, err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// decodeIPFIXRecords decodes the records of a data set with template t,
+// ignoring the padding ending the set.
+func decodeIPFIXRecords(data []byte, t *IPFIXTemplate) ([]IPFIXRecord, error) {
+	length := t.minRecordLength()
+	if length == 0 {
+		return nil, nil
+	}
+	var records []IPFIXRecord
+	for len(data) >= length {
+		r := IPFIXRecord{Values: make([]IPFIXFieldValue, 0, len(t.Fields))}
+		for j, f := range t.Fields {
+			n := int(f.Length)
+			if f.Length == IPFIXVariableLength {
+				if len(data) < 1 {
+					return nil, fmt.Errorf("IPFIX record of template %d truncated", t.ID)
+				}
+				n, data = int(data[0]), data[1:]
+				if n == 255 {
+					if len(data) < 2 {
+						return nil, fmt.Errorf("IPFIX record of template %d truncated", t.ID)
+					}
+					n, data = int(binary.BigEndian.Uint16(data[0:2])), data[2:]
+				}
+			}
+			if len(data) < n {
+				return nil, fmt.Errorf("IPFIX record of template %d truncated", t.ID)
+			}
+			r.Values = append(r.Values, IPFIXFieldValue{
+				Field: f,
+				Scope: j < int(t.ScopeFieldCount),
+				Value: data[:n],
+			})
+			data = data[n:]
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
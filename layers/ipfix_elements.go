@@ -0,0 +1,327 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// IPFIXDataType is the abstract data type of an IPFIX information element,
+// see RFC 7012, section 3.1.
+type IPFIXDataType uint8
+
+// IPFIXDataType known values
+const (
+	IPFIXDataTypeOctetArray           IPFIXDataType = 0
+	IPFIXDataTypeUnsigned8            IPFIXDataType = 1
+	IPFIXDataTypeUnsigned16           IPFIXDataType = 2
+	IPFIXDataTypeUnsigned32           IPFIXDataType = 3
+	IPFIXDataTypeUnsigned64           IPFIXDataType = 4
+	IPFIXDataTypeSigned8              IPFIXDataType = 5
+	IPFIXDataTypeSigned16             IPFIXDataType = 6
+	IPFIXDataTypeSigned32             IPFIXDataType = 7
+	IPFIXDataTypeSigned64             IPFIXDataType = 8
+	IPFIXDataTypeFloat32              IPFIXDataType = 9
+	IPFIXDataTypeFloat64              IPFIXDataType = 10
+	IPFIXDataTypeBoolean              IPFIXDataType = 11
+	IPFIXDataTypeMACAddress           IPFIXDataType = 12
+	IPFIXDataTypeString               IPFIXDataType = 13
+	IPFIXDataTypeDateTimeSeconds      IPFIXDataType = 14
+	IPFIXDataTypeDateTimeMilliseconds IPFIXDataType = 15
+	IPFIXDataTypeDateTimeMicroseconds IPFIXDataType = 16
+	IPFIXDataTypeDateTimeNanoseconds  IPFIXDataType = 17
+	IPFIXDataTypeIPv4Address          IPFIXDataType = 18
+	IPFIXDataTypeIPv6Address          IPFIXDataType = 19
+	IPFIXDataTypeBasicList            IPFIXDataType = 20
+	IPFIXDataTypeSubTemplateList      IPFIXDataType = 21
+	IPFIXDataTypeSubTemplateMultiList IPFIXDataType = 22
+)
+
+func (t IPFIXDataType) String() string {
+	switch t {
+	case IPFIXDataTypeOctetArray:
+		return "octetArray"
+	case IPFIXDataTypeUnsigned8:
+		return "unsigned8"
+	case IPFIXDataTypeUnsigned16:
+		return "unsigned16"
+	case IPFIXDataTypeUnsigned32:
+		return "unsigned32"
+	case IPFIXDataTypeUnsigned64:
+		return "unsigned64"
+	case IPFIXDataTypeSigned8:
+		return "signed8"
+	case IPFIXDataTypeSigned16:
+		return "signed16"
+	case IPFIXDataTypeSigned32:
+		return "signed32"
+	case IPFIXDataTypeSigned64:
+		return "signed64"
+	case IPFIXDataTypeFloat32:
+		return "float32"
+	case IPFIXDataTypeFloat64:
+		return "float64"
+	case IPFIXDataTypeBoolean:
+		return "boolean"
+	case IPFIXDataTypeMACAddress:
+		return "macAddress"
+	case IPFIXDataTypeString:
+		return "string"
+	case IPFIXDataTypeDateTimeSeconds:
+		return "dateTimeSeconds"
+	case IPFIXDataTypeDateTimeMilliseconds:
+		return "dateTimeMilliseconds"
+	case IPFIXDataTypeDateTimeMicroseconds:
+		return "dateTimeMicroseconds"
+	case IPFIXDataTypeDateTimeNanoseconds:
+		return "dateTimeNanoseconds"
+	case IPFIXDataTypeIPv4Address:
+		return "ipv4Address"
+	case IPFIXDataTypeIPv6Address:
+		return "ipv6Address"
+	case IPFIXDataTypeBasicList:
+		return "basicList"
+	case IPFIXDataTypeSubTemplateList:
+		return "subTemplateList"
+	case IPFIXDataTypeSubTemplateMultiList:
+		return "subTemplateMultiList"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(t))
+	}
+}
+
+// IPFIXInformationElement describes an IPFIX information element, the type
+// of a field.  Enterprise is zero for the elements of the IANA registry.
+type IPFIXInformationElement struct {
+	Enterprise uint32
+	ID         uint16
+	Name       string
+	Type       IPFIXDataType
+}
+
+type ipfixElementKey struct {
+	enterprise uint32
+	id         uint16
+}
+
+// ipfixElements is replaced by RegisterIPFIXInformationElement, so that
+// elements can be registered while packets are decoded.
+var (
+	ipfixElementsMu sync.Mutex
+	ipfixElements   atomic.Value // map[ipfixElementKey]IPFIXInformationElement
+)
+
+// RegisterIPFIXInformationElement adds an information element to the
+// registry used by IPFIXFieldValue.Decode, replacing the one with the same
+// enterprise and ID.  It is used for enterprise specific elements, and may
+// be called while packets are decoded by other goroutines.
+func RegisterIPFIXInformationElement(ie IPFIXInformationElement) {
+	ipfixElementsMu.Lock()
+	defer ipfixElementsMu.Unlock()
+	old, _ := ipfixElements.Load().(map[ipfixElementKey]IPFIXInformationElement)
+	m := make(map[ipfixElementKey]IPFIXInformationElement, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[ipfixElementKey{ie.Enterprise, ie.ID}] = ie
+	ipfixElements.Store(m)
+}
+
+// LookupIPFIXInformationElement returns the registered information element
+// of an enterprise with an ID.
+func LookupIPFIXInformationElement(enterprise uint32, id uint16) (IPFIXInformationElement, bool) {
+	m, _ := ipfixElements.Load().(map[ipfixElementKey]IPFIXInformationElement)
+	ie, ok := m[ipfixElementKey{enterprise, id}]
+	return ie, ok
+}
+
+func init() {
+	m := make(map[ipfixElementKey]IPFIXInformationElement, len(ianaIPFIXInformationElements))
+	for _, ie := range ianaIPFIXInformationElements {
+		m[ipfixElementKey{0, ie.ID}] = ie
+	}
+	ipfixElements.Store(m)
+}
+
+// ianaIPFIXInformationElements are the most used elements of the IANA IPFIX
+// information element registry.
+var ianaIPFIXInformationElements = []IPFIXInformationElement{
+	{ID: 1, Name: "octetDeltaCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 2, Name: "packetDeltaCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 3, Name: "deltaFlowCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 4, Name: "protocolIdentifier", Type: IPFIXDataTypeUnsigned8},
+	{ID: 5, Name: "ipClassOfService", Type: IPFIXDataTypeUnsigned8},
+	{ID: 6, Name: "tcpControlBits", Type: IPFIXDataTypeUnsigned16},
+	{ID: 7, Name: "sourceTransportPort", Type: IPFIXDataTypeUnsigned16},
+	{ID: 8, Name: "sourceIPv4Address", Type: IPFIXDataTypeIPv4Address},
+	{ID: 9, Name: "sourceIPv4PrefixLength", Type: IPFIXDataTypeUnsigned8},
+	{ID: 10, Name: "ingressInterface", Type: IPFIXDataTypeUnsigned32},
+	{ID: 11, Name: "destinationTransportPort", Type: IPFIXDataTypeUnsigned16},
+	{ID: 12, Name: "destinationIPv4Address", Type: IPFIXDataTypeIPv4Address},
+	{ID: 13, Name: "destinationIPv4PrefixLength", Type: IPFIXDataTypeUnsigned8},
+	{ID: 14, Name: "egressInterface", Type: IPFIXDataTypeUnsigned32},
+	{ID: 15, Name: "ipNextHopIPv4Address", Type: IPFIXDataTypeIPv4Address},
+	{ID: 16, Name: "bgpSourceAsNumber", Type: IPFIXDataTypeUnsigned32},
+	{ID: 17, Name: "bgpDestinationAsNumber", Type: IPFIXDataTypeUnsigned32},
+	{ID: 18, Name: "bgpNextHopIPv4Address", Type: IPFIXDataTypeIPv4Address},
+	{ID: 21, Name: "flowEndSysUpTime", Type: IPFIXDataTypeUnsigned32},
+	{ID: 22, Name: "flowStartSysUpTime", Type: IPFIXDataTypeUnsigned32},
+	{ID: 23, Name: "postOctetDeltaCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 24, Name: "postPacketDeltaCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 27, Name: "sourceIPv6Address", Type: IPFIXDataTypeIPv6Address},
+	{ID: 28, Name: "destinationIPv6Address", Type: IPFIXDataTypeIPv6Address},
+	{ID: 29, Name: "sourceIPv6PrefixLength", Type: IPFIXDataTypeUnsigned8},
+	{ID: 30, Name: "destinationIPv6PrefixLength", Type: IPFIXDataTypeUnsigned8},
+	{ID: 31, Name: "flowLabelIPv6", Type: IPFIXDataTypeUnsigned32},
+	{ID: 32, Name: "icmpTypeCodeIPv4", Type: IPFIXDataTypeUnsigned16},
+	{ID: 34, Name: "samplingInterval", Type: IPFIXDataTypeUnsigned32},
+	{ID: 35, Name: "samplingAlgorithm", Type: IPFIXDataTypeUnsigned8},
+	{ID: 40, Name: "exportedOctetTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 41, Name: "exportedMessageTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 42, Name: "exportedFlowRecordTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 56, Name: "sourceMacAddress", Type: IPFIXDataTypeMACAddress},
+	{ID: 57, Name: "postDestinationMacAddress", Type: IPFIXDataTypeMACAddress},
+	{ID: 58, Name: "vlanId", Type: IPFIXDataTypeUnsigned16},
+	{ID: 59, Name: "postVlanId", Type: IPFIXDataTypeUnsigned16},
+	{ID: 60, Name: "ipVersion", Type: IPFIXDataTypeUnsigned8},
+	{ID: 61, Name: "flowDirection", Type: IPFIXDataTypeUnsigned8},
+	{ID: 62, Name: "ipNextHopIPv6Address", Type: IPFIXDataTypeIPv6Address},
+	{ID: 80, Name: "destinationMacAddress", Type: IPFIXDataTypeMACAddress},
+	{ID: 81, Name: "postSourceMacAddress", Type: IPFIXDataTypeMACAddress},
+	{ID: 82, Name: "interfaceName", Type: IPFIXDataTypeString},
+	{ID: 83, Name: "interfaceDescription", Type: IPFIXDataTypeString},
+	{ID: 85, Name: "octetTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 86, Name: "packetTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 94, Name: "applicationDescription", Type: IPFIXDataTypeString},
+	{ID: 95, Name: "applicationId", Type: IPFIXDataTypeOctetArray},
+	{ID: 96, Name: "applicationName", Type: IPFIXDataTypeString},
+	{ID: 130, Name: "exporterIPv4Address", Type: IPFIXDataTypeIPv4Address},
+	{ID: 131, Name: "exporterIPv6Address", Type: IPFIXDataTypeIPv6Address},
+	{ID: 136, Name: "flowEndReason", Type: IPFIXDataTypeUnsigned8},
+	{ID: 139, Name: "icmpTypeCodeIPv6", Type: IPFIXDataTypeUnsigned16},
+	{ID: 144, Name: "exportingProcessId", Type: IPFIXDataTypeUnsigned32},
+	{ID: 148, Name: "flowId", Type: IPFIXDataTypeUnsigned64},
+	{ID: 149, Name: "observationDomainId", Type: IPFIXDataTypeUnsigned32},
+	{ID: 150, Name: "flowStartSeconds", Type: IPFIXDataTypeDateTimeSeconds},
+	{ID: 151, Name: "flowEndSeconds", Type: IPFIXDataTypeDateTimeSeconds},
+	{ID: 152, Name: "flowStartMilliseconds", Type: IPFIXDataTypeDateTimeMilliseconds},
+	{ID: 153, Name: "flowEndMilliseconds", Type: IPFIXDataTypeDateTimeMilliseconds},
+	{ID: 154, Name: "flowStartMicroseconds", Type: IPFIXDataTypeDateTimeMicroseconds},
+	{ID: 155, Name: "flowEndMicroseconds", Type: IPFIXDataTypeDateTimeMicroseconds},
+	{ID: 156, Name: "flowStartNanoseconds", Type: IPFIXDataTypeDateTimeNanoseconds},
+	{ID: 157, Name: "flowEndNanoseconds", Type: IPFIXDataTypeDateTimeNanoseconds},
+	{ID: 160, Name: "systemInitTimeMilliseconds", Type: IPFIXDataTypeDateTimeMilliseconds},
+	{ID: 164, Name: "ignoredPacketTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 165, Name: "ignoredOctetTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 166, Name: "notSentFlowTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 167, Name: "notSentPacketTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 168, Name: "notSentOctetTotalCount", Type: IPFIXDataTypeUnsigned64},
+	{ID: 176, Name: "icmpTypeIPv4", Type: IPFIXDataTypeUnsigned8},
+	{ID: 177, Name: "icmpCodeIPv4", Type: IPFIXDataTypeUnsigned8},
+	{ID: 178, Name: "icmpTypeIPv6", Type: IPFIXDataTypeUnsigned8},
+	{ID: 179, Name: "icmpCodeIPv6", Type: IPFIXDataTypeUnsigned8},
+	{ID: 180, Name: "udpSourcePort", Type: IPFIXDataTypeUnsigned16},
+	{ID: 181, Name: "udpDestinationPort", Type: IPFIXDataTypeUnsigned16},
+	{ID: 182, Name: "tcpSourcePort", Type: IPFIXDataTypeUnsigned16},
+	{ID: 183, Name: "tcpDestinationPort", Type: IPFIXDataTypeUnsigned16},
+	{ID: 210, Name: "paddingOctets", Type: IPFIXDataTypeOctetArray},
+	{ID: 225, Name: "postNATSourceIPv4Address", Type: IPFIXDataTypeIPv4Address},
+	{ID: 226, Name: "postNATDestinationIPv4Address", Type: IPFIXDataTypeIPv4Address},
+	{ID: 227, Name: "postNAPTSourceTransportPort", Type: IPFIXDataTypeUnsigned16},
+	{ID: 228, Name: "postNAPTDestinationTransportPort", Type: IPFIXDataTypeUnsigned16},
+	{ID: 234, Name: "ingressVRFID", Type: IPFIXDataTypeUnsigned32},
+	{ID: 235, Name: "egressVRFID", Type: IPFIXDataTypeUnsigned32},
+	{ID: 239, Name: "biflowDirection", Type: IPFIXDataTypeUnsigned8},
+	{ID: 256, Name: "ethernetType", Type: IPFIXDataTypeUnsigned16},
+	{ID: 291, Name: "basicList", Type: IPFIXDataTypeBasicList},
+	{ID: 292, Name: "subTemplateList", Type: IPFIXDataTypeSubTemplateList},
+	{ID: 293, Name: "subTemplateMultiList", Type: IPFIXDataTypeSubTemplateMultiList},
+	{ID: 302, Name: "selectorId", Type: IPFIXDataTypeUnsigned64},
+	{ID: 305, Name: "samplingPacketInterval", Type: IPFIXDataTypeUnsigned32},
+	{ID: 306, Name: "samplingPacketSpace", Type: IPFIXDataTypeUnsigned32},
+}
+
+// ntpEpochOffset is the number of seconds from the NTP epoch, 1900, to the
+// Unix one.
+const ntpEpochOffset = 2208988800
+
+// Decode returns the value converted according to the data type of its
+// information element: a uint64, an int64, a float64, a bool, a
+// net.HardwareAddr, a string, a time.Time or a net.IP.  Values of unknown
+// elements, octet arrays and structured data are returned as a []byte,
+// as are values whose length does not fit their type.  Integers and floats
+// may use reduced size encoding.
+func (v IPFIXFieldValue) Decode() interface{} {
+	ie, ok := v.Field.Element()
+	if !ok {
+		return v.Value
+	}
+	b := v.Value
+	switch ie.Type {
+	case IPFIXDataTypeUnsigned8, IPFIXDataTypeUnsigned16, IPFIXDataTypeUnsigned32, IPFIXDataTypeUnsigned64:
+		if len(b) >= 1 && len(b) <= 8 {
+			return v.Uint()
+		}
+	case IPFIXDataTypeSigned8, IPFIXDataTypeSigned16, IPFIXDataTypeSigned32, IPFIXDataTypeSigned64:
+		if len(b) >= 1 && len(b) <= 8 {
+			shift := uint(64 - 8*len(b))
+			return int64(v.Uint()<<shift) >> shift
+		}
+	case IPFIXDataTypeFloat32, IPFIXDataTypeFloat64:
+		switch len(b) {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(b))
+		}
+	case IPFIXDataTypeBoolean:
+		if len(b) == 1 && (b[0] == 1 || b[0] == 2) {
+			return b[0] == 1
+		}
+	case IPFIXDataTypeMACAddress:
+		if len(b) == 6 {
+			return net.HardwareAddr(b)
+		}
+	case IPFIXDataTypeString:
+		return string(b)
+	case IPFIXDataTypeDateTimeSeconds:
+		if len(b) == 4 {
+			return time.Unix(int64(binary.BigEndian.Uint32(b)), 0).UTC()
+		}
+	case IPFIXDataTypeDateTimeMilliseconds:
+		if len(b) == 8 {
+			ms := int64(binary.BigEndian.Uint64(b))
+			return time.Unix(ms/1000, ms%1000*int64(time.Millisecond)).UTC()
+		}
+	case IPFIXDataTypeDateTimeMicroseconds, IPFIXDataTypeDateTimeNanoseconds:
+		// NTP timestamps, whose 11 least significant bits are unused
+		// for microseconds
+		if len(b) == 8 {
+			frac := uint64(binary.BigEndian.Uint32(b[4:8]))
+			if ie.Type == IPFIXDataTypeDateTimeMicroseconds {
+				frac &^= 0x7ff
+			}
+			secs := int64(binary.BigEndian.Uint32(b[0:4])) - ntpEpochOffset
+			return time.Unix(secs, int64(frac*uint64(time.Second)>>32)).UTC()
+		}
+	case IPFIXDataTypeIPv4Address:
+		if len(b) == net.IPv4len {
+			return net.IP(b)
+		}
+	case IPFIXDataTypeIPv6Address:
+		if len(b) == net.IPv6len {
+			return net.IP(b)
+		}
+	}
+	return b
+}
@@ -0,0 +1,295 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketIPFIX is an IPFIX message with a template, with an enterprise
+// specific and a variable length field, an options template, and their data
+// sets, the data of the first template having two records.
+var testPacketIPFIX = []byte{
+	0x00, 0x0c, 0x29, 0xcc, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xcc, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0xbf, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x35, 0xb9, 0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8,
+	0x00, 0x64, 0xc3, 0x50, 0x12, 0x83, 0x00, 0xab, 0x5d, 0x5f, 0x00, 0x0a, 0x00, 0xa3, 0x65, 0x53,
+	0xf1, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2a, 0x00, 0x02, 0x00, 0x28, 0x01, 0x00,
+	0x00, 0x07, 0x00, 0x08, 0x00, 0x04, 0x00, 0x0c, 0x00, 0x04, 0x00, 0x04, 0x00, 0x01, 0x00, 0x01,
+	0x00, 0x08, 0x00, 0x98, 0x00, 0x08, 0x00, 0x60, 0xff, 0xff, 0x80, 0x05, 0x00, 0x02, 0x00, 0x00,
+	0x7e, 0xd9, 0x00, 0x03, 0x00, 0x12, 0x01, 0x01, 0x00, 0x02, 0x00, 0x01, 0x00, 0x95, 0x00, 0x04,
+	0x00, 0x29, 0x00, 0x08, 0x01, 0x00, 0x00, 0x46, 0x0a, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x01, 0x01,
+	0x06, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0xdc, 0x00, 0x00, 0x01, 0x8b, 0xcf, 0xe5, 0x68,
+	0x7b, 0x05, 0x68, 0x74, 0x74, 0x70, 0x73, 0x00, 0x07, 0x0a, 0x00, 0x00, 0x02, 0x0a, 0x00, 0x01,
+	0x02, 0x11, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x4c, 0x00, 0x00, 0x01, 0x8b, 0xcf, 0xe5,
+	0x6b, 0xe8, 0xff, 0x00, 0x03, 0x64, 0x6e, 0x73, 0x00, 0x08, 0x01, 0x01, 0x00, 0x13, 0x00, 0x00,
+	0x00, 0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00,
+}
+
+// testPacketIPFIXWithdrawal is an IPFIX message following testPacketIPFIX,
+// with data of its template, the withdrawal of the template, and data of the
+// withdrawn template.
+var testPacketIPFIXWithdrawal = []byte{
+	0x00, 0x0c, 0x29, 0xcc, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xcc, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x74, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x36, 0x04, 0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8,
+	0x00, 0x64, 0xc3, 0x50, 0x12, 0x83, 0x00, 0x60, 0xf6, 0xd1, 0x00, 0x0a, 0x00, 0x58, 0x65, 0x53,
+	0xf1, 0x05, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x2a, 0x01, 0x00, 0x00, 0x20, 0x0a, 0x00,
+	0x00, 0x03, 0x0a, 0x00, 0x01, 0x03, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x54, 0x00,
+	0x00, 0x01, 0x8b, 0xcf, 0xe5, 0x6f, 0xd0, 0x00, 0x00, 0x09, 0x00, 0x02, 0x00, 0x08, 0x01, 0x00,
+	0x00, 0x00, 0x01, 0x00, 0x00, 0x20, 0x0a, 0x00, 0x00, 0x03, 0x0a, 0x00, 0x01, 0x03, 0x01, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x54, 0x00, 0x00, 0x01, 0x8b, 0xcf, 0xe5, 0x6f, 0xd0, 0x00,
+	0x00, 0x09,
+}
+
+// testIPFIXWithdrawAllOptions is an IPFIX message withdrawing all the options
+// templates of its observation domain.
+var testIPFIXWithdrawAllOptions = []byte{
+	0x00, 0x0a, 0x00, 0x18, 0x65, 0x53, 0xf1, 0x0a, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x2a,
+	0x00, 0x03, 0x00, 0x08, 0x00, 0x03, 0x00, 0x00,
+}
+
+// testPacketIPFIXTCP is a TCP segment with two IPFIX messages, a template and
+// its data.
+var testPacketIPFIXTCP = []byte{
+	0x00, 0x0c, 0x29, 0xcc, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xcc, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x5a, 0x00, 0x00, 0x00, 0x00, 0x02, 0x06, 0x36, 0x29, 0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8,
+	0x00, 0x64, 0xc3, 0x51, 0x12, 0x83, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x50, 0x18,
+	0xff, 0xff, 0xa2, 0x8e, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x1c, 0x65, 0x53, 0xf1, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x07, 0x00, 0x02, 0x00, 0x0c, 0x01, 0x2c, 0x00, 0x01, 0x00, 0x04,
+	0x00, 0x01, 0x00, 0x0a, 0x00, 0x16, 0x65, 0x53, 0xf1, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x07, 0x01, 0x2c, 0x00, 0x06, 0x06, 0x11,
+}
+
+// testIPFIXEnterprise is the enterprise number reserved for documentation,
+// see RFC 5612.
+const testIPFIXEnterprise = 32473
+
+func TestIPFIX(t *testing.T) {
+	p := gopacket.NewPacket(testPacketIPFIX, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeIPFIX}, t)
+	ipfix := p.Layer(LayerTypeIPFIX).(*IPFIX)
+	if ipfix.Version != 10 || ipfix.Length != 163 || ipfix.ExportTime != 1700000000 || ipfix.SequenceNumber != 0 || ipfix.ObservationDomainID != 42 {
+		t.Errorf("unexpected IPFIX header %+v", ipfix)
+	}
+	if len(ipfix.Sets) != 4 {
+		t.Fatalf("got %d sets, want 4", len(ipfix.Sets))
+	}
+
+	wantTemplates := []IPFIXTemplate{
+		{
+			ID: 256,
+			Fields: []IPFIXField{
+				{ID: 8, Length: 4},
+				{ID: 12, Length: 4},
+				{ID: 4, Length: 1},
+				{ID: 1, Length: 8},
+				{ID: 152, Length: 8},
+				{ID: 96, Length: IPFIXVariableLength},
+				{ID: 5, Length: 2, Enterprise: testIPFIXEnterprise},
+			},
+		},
+	}
+	if s := ipfix.Sets[0]; s.ID != IPFIXTemplateSetID || !reflect.DeepEqual(s.Templates, wantTemplates) {
+		t.Errorf("template set mismatch:\ngot  %#v\nwant %#v", s.Templates, wantTemplates)
+	}
+	wantOptions := []IPFIXTemplate{
+		{
+			ID:              257,
+			Options:         true,
+			ScopeFieldCount: 1,
+			Fields:          []IPFIXField{{ID: 149, Length: 4}, {ID: 41, Length: 8}},
+		},
+	}
+	if s := ipfix.Sets[1]; s.ID != IPFIXOptionsTemplateSetID || !reflect.DeepEqual(s.Templates, wantOptions) {
+		t.Errorf("options template set mismatch:\ngot  %#v\nwant %#v", s.Templates, wantOptions)
+	}
+
+	data := ipfix.Sets[2]
+	if data.ID != 256 || len(data.Records) != 2 {
+		t.Fatalf("got data set %d with %d records, want 256 with 2", data.ID, len(data.Records))
+	}
+	r := data.Records[0]
+	if src := net.IP(r.Value(8)); !src.Equal(net.IP{10, 0, 0, 1}) {
+		t.Errorf("got source address %v, want 10.0.0.1", src)
+	}
+	if name := string(r.Value(96)); name != "https" {
+		t.Errorf("got application name %q, want https", name)
+	}
+	if v := r.EnterpriseValue(testIPFIXEnterprise, 5); !reflect.DeepEqual(v, []byte{0, 7}) {
+		t.Errorf("got enterprise value %v, want [0 7]", v)
+	}
+	if r.Value(5) != nil {
+		t.Error("enterprise specific field returned as an IANA one")
+	}
+	// The second record uses the 3 byte variable length encoding
+	r = data.Records[1]
+	if name := string(r.Value(96)); name != "dns" {
+		t.Errorf("got application name %q, want dns", name)
+	}
+	if octets := r.Values[3].Uint(); octets != 76 {
+		t.Errorf("got %d octets, want 76", octets)
+	}
+
+	options := ipfix.Sets[3]
+	if len(options.Records) != 1 {
+		t.Fatalf("got %d options records, want 1", len(options.Records))
+	}
+	wantValues := []IPFIXFieldValue{
+		{Field: IPFIXField{ID: 149, Length: 4}, Scope: true, Value: []byte{0, 0, 0, 42}},
+		{Field: IPFIXField{ID: 41, Length: 8}, Value: []byte{0, 0, 0, 0, 0, 0, 0, 9}},
+	}
+	if !reflect.DeepEqual(options.Records[0].Values, wantValues) {
+		t.Errorf("options record mismatch:\ngot  %#v\nwant %#v", options.Records[0].Values, wantValues)
+	}
+	if options.Records[0].Value(149) != nil {
+		t.Error("scope field returned as a non scope field")
+	}
+}
+
+func TestIPFIXTCP(t *testing.T) {
+	p := gopacket.NewPacket(testPacketIPFIXTCP, LinkTypeEthernet, gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true})
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeTCP, LayerTypeIPFIX, LayerTypeIPFIX}, t)
+	layers := p.Layers()
+	if first := layers[3].(*IPFIX); len(first.Sets) != 1 || len(first.Sets[0].Templates) != 1 {
+		t.Errorf("unexpected first message %+v", first)
+	}
+	if second := layers[4].(*IPFIX); len(second.Sets) != 1 || second.Sets[0].ID != 300 || len(second.Payload) != 0 {
+		t.Errorf("unexpected second message %+v", second)
+	}
+}
+
+func TestIPFIXTemplateCache(t *testing.T) {
+	cache := NewIPFIXTemplateCache()
+	ipfix := IPFIX{Exporter: "192.168.0.1:50000", Templates: cache}
+	if err := ipfix.DecodeFromBytes(testPacketIPFIX[42:], gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if err := ipfix.DecodeFromBytes(testPacketIPFIXWithdrawal[42:], gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if ipfix.Templates != cache || ipfix.Exporter != "192.168.0.1:50000" {
+		t.Error("template cache not kept across messages")
+	}
+	if len(ipfix.Sets) != 3 {
+		t.Fatalf("got %d sets, want 3", len(ipfix.Sets))
+	}
+	if s := ipfix.Sets[0]; len(s.Records) != 1 || !net.IP(s.Records[0].Value(12)).Equal(net.IP{10, 0, 1, 3}) {
+		t.Errorf("unexpected data set with the cached template %+v", s)
+	}
+	if s := ipfix.Sets[1]; len(s.Templates) != 1 || !s.Templates[0].Withdrawal() {
+		t.Errorf("unexpected template withdrawal set %+v", s)
+	}
+	if s := ipfix.Sets[2]; s.Records != nil || len(s.Data) != 28 {
+		t.Errorf("unexpected data set of a withdrawn template: %d records, %d bytes of data", len(s.Records), len(s.Data))
+	}
+	if cache.Template("192.168.0.1:50000", 42, 256) != nil {
+		t.Error("withdrawn template still cached")
+	}
+	if cache.Template("192.168.0.2:50000", 42, 257) != nil || cache.Template("192.168.0.1:50000", 43, 257) != nil {
+		t.Error("template of another exporter or domain found")
+	}
+	if cache.Template("192.168.0.1:50000", 42, 257) == nil {
+		t.Fatal("options template not cached")
+	}
+
+	if err := ipfix.DecodeFromBytes(testIPFIXWithdrawAllOptions, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Template("192.168.0.1:50000", 42, 257) != nil {
+		t.Error("options template not withdrawn")
+	}
+
+	// Without a cache, only the templates of the message are used
+	ipfix = IPFIX{}
+	if err := ipfix.DecodeFromBytes(testPacketIPFIXWithdrawal[42:], gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if ipfix.Sets[0].Records != nil {
+		t.Error("data set decoded without its template")
+	}
+}
+
+func TestIPFIXFieldValueDecode(t *testing.T) {
+	for _, ie := range []IPFIXInformationElement{
+		{testIPFIXEnterprise, 1, "testSigned", IPFIXDataTypeSigned32},
+		{testIPFIXEnterprise, 2, "testFloat", IPFIXDataTypeFloat64},
+		{testIPFIXEnterprise, 3, "testBoolean", IPFIXDataTypeBoolean},
+	} {
+		RegisterIPFIXInformationElement(ie)
+	}
+	if ie, ok := LookupIPFIXInformationElement(testIPFIXEnterprise, 2); !ok || ie.Name != "testFloat" || ie.Type.String() != "float64" {
+		t.Errorf("unexpected registered element %+v", ie)
+	}
+	if ie, ok := LookupIPFIXInformationElement(0, 152); !ok || ie.Name != "flowStartMilliseconds" {
+		t.Errorf("unexpected IANA element %+v", ie)
+	}
+
+	for _, test := range []struct {
+		field IPFIXField
+		value []byte
+		want  interface{}
+	}{
+		{IPFIXField{ID: 4}, []byte{6}, uint64(6)},
+		{IPFIXField{ID: 1}, []byte{0x05, 0xdc}, uint64(1500)}, // Reduced size
+		{IPFIXField{ID: 8}, []byte{10, 0, 0, 1}, net.IP{10, 0, 0, 1}},
+		{IPFIXField{ID: 56}, []byte{0, 0x0c, 0x29, 0xcc, 0, 1}, net.HardwareAddr{0, 0x0c, 0x29, 0xcc, 0, 1}},
+		{IPFIXField{ID: 96}, []byte("dns"), "dns"},
+		{IPFIXField{ID: 150}, []byte{0x65, 0x53, 0xf1, 0x00}, time.Unix(1700000000, 0).UTC()},
+		{IPFIXField{ID: 152}, []byte{0, 0, 0x01, 0x8b, 0xcf, 0xe5, 0x68, 0x7b}, time.Unix(1700000000, 123000000).UTC()},
+		{IPFIXField{ID: 156}, []byte{0xe8, 0xfe, 0x6f, 0x80, 0x80, 0, 0, 0}, time.Unix(1700000000, 500000000).UTC()},
+		{IPFIXField{ID: 1, Enterprise: testIPFIXEnterprise}, []byte{0xff, 0xfe}, int64(-2)},
+		{IPFIXField{ID: 2, Enterprise: testIPFIXEnterprise}, []byte{0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, 1.5},
+		{IPFIXField{ID: 3, Enterprise: testIPFIXEnterprise}, []byte{2}, false},
+		{IPFIXField{ID: 3, Enterprise: testIPFIXEnterprise}, []byte{3}, []byte{3}},
+		{IPFIXField{ID: 8}, []byte{10, 0, 0}, []byte{10, 0, 0}},
+		{IPFIXField{ID: 5, Enterprise: testIPFIXEnterprise + 1}, []byte{0, 7}, []byte{0, 7}},
+	} {
+		v := IPFIXFieldValue{Field: test.field, Value: test.value}
+		if got := v.Decode(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%+v %v: got %#v, want %#v", test.field, test.value, got, test.want)
+		}
+	}
+}
+
+func TestIPFIXDecodeInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		data   []byte
+		mutate func([]byte)
+	}{
+		{"truncated header", testPacketIPFIX[42:57], nil},
+		{"unsupported version", testPacketIPFIX[42:], func(b []byte) { b[1] = 9 }},
+		{"message length too long", testPacketIPFIX[42:], func(b []byte) { b[3] = 0xff }},
+		{"message length too short", testPacketIPFIX[42:], func(b []byte) { b[2], b[3] = 0, 8 }},
+		{"set length too long", testPacketIPFIX[42:], func(b []byte) { b[19] = 0xff }},
+		{"set length too short", testPacketIPFIX[42:], func(b []byte) { b[19] = 3 }},
+		{"invalid set ID", testPacketIPFIX[42:], func(b []byte) { b[17] = 1 }},
+		{"invalid template ID", testPacketIPFIX[42:], func(b []byte) { b[20] = 0 }},
+		{"template truncated", testPacketIPFIX[42:], func(b []byte) { b[23] = 20 }},
+		{"invalid scope field count", testPacketIPFIX[42:], func(b []byte) { b[65] = 0 }},
+		{"variable length field truncated", testPacketIPFIX[42:], func(b []byte) { b[138] = 0x40 }},
+	} {
+		data := append([]byte{}, test.data...)
+		if test.mutate != nil {
+			test.mutate(data)
+		}
+		var ipfix IPFIX
+		if err := ipfix.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", test.name)
+		}
+	}
+}
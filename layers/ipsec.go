@@ -55,17 +55,34 @@ func decodeIPSecAH(data []byte, p gopacket.PacketBuilder) error {
 
 // IPSecESP is the encapsulating security payload defined in
 // http://tools.ietf.org/html/rfc2406
+//
+// If ESPDecryption has the security association of the SPI, the packet is
+// decrypted, and the data it protects is decoded as the next layers: an IP
+// packet in tunnel mode, or the transport layer in transport mode.
 type IPSecESP struct {
 	BaseLayer
 	SPI, Seq uint32
 	// Encrypted contains the encrypted set of bytes sent in an ESP
 	Encrypted []byte
+
+	// The following fields are set for decrypted packets, whose payload is
+	// the decrypted data.
+	Decrypted  bool
+	IV         []byte
+	Padding    []byte
+	NextHeader IPProtocol
+	// ICV is the integrity check value at the end of the packet
+	ICV []byte
 }
 
 // LayerType returns LayerTypeIPSecESP.
 func (i *IPSecESP) LayerType() gopacket.LayerType { return LayerTypeIPSecESP }
 
 func decodeIPSecESP(data []byte, p gopacket.PacketBuilder) error {
+	if len(data) < 8 {
+		p.SetTruncated()
+		return errors.New("IPSec ESP packet less than 8 bytes")
+	}
 	i := &IPSecESP{
 		BaseLayer: BaseLayer{data, nil},
 		SPI:       binary.BigEndian.Uint32(data[:4]),
@@ -73,5 +90,69 @@ func decodeIPSecESP(data []byte, p gopacket.PacketBuilder) error {
 		Encrypted: data[8:],
 	}
 	p.AddLayer(i)
+	if ESPDecryption == nil {
+		return nil
+	}
+	if err := ESPDecryption.Decrypt(i); err != nil {
+		if err == errESPNoSA {
+			return nil
+		}
+		return err
+	}
+	if len(i.Payload) == 0 || i.NextHeader == IPProtocolNoNextHeader {
+		// dummy packets, RFC 4303 section 2.6
+		return nil
+	}
+	return p.NextDecoder(i.NextHeader)
+}
+
+// IPSecUDPEncap is the UDP encapsulation of IPsec packets for NAT traversal,
+// RFC 3948, used on UDP port 4500.  Its payload is an ESP packet, or an IKE
+// message after a non-ESP marker of 4 zero bytes.  NAT-keepalive packets of a
+// single 0xff byte have no payload.
+type IPSecUDPEncap struct {
+	BaseLayer
+	// NonESPMarker is set if the payload is an IKE message
+	NonESPMarker bool
+	// Keepalive is set for NAT-keepalive packets
+	Keepalive bool
+}
+
+// LayerType returns LayerTypeIPSecUDPEncap.
+func (i *IPSecUDPEncap) LayerType() gopacket.LayerType { return LayerTypeIPSecUDPEncap }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (i *IPSecUDPEncap) CanDecode() gopacket.LayerClass { return LayerTypeIPSecUDPEncap }
+
+// NextLayerType returns the layer type of the payload.
+func (i *IPSecUDPEncap) NextLayerType() gopacket.LayerType {
+	switch {
+	case len(i.Payload) == 0:
+		return gopacket.LayerTypeZero
+	case i.NonESPMarker:
+		return LayerTypeIKEv2
+	}
+	return LayerTypeIPSecESP
+}
+
+// DecodeFromBytes decodes the slice into the IPSecUDPEncap struct.
+func (i *IPSecUDPEncap) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*i = IPSecUDPEncap{}
+	switch {
+	case len(data) == 1 && data[0] == 0xff:
+		i.Keepalive = true
+		i.Contents = data
+	case len(data) >= 4 && binary.BigEndian.Uint32(data) == 0:
+		i.NonESPMarker = true
+		i.Contents = data[:4]
+		i.Payload = data[4:]
+	default:
+		i.Payload = data
+	}
 	return nil
 }
+
+func decodeIPSecUDPEncap(data []byte, p gopacket.PacketBuilder) error {
+	i := &IPSecUDPEncap{}
+	return decodingLayerDecoder(i, data, p)
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ESPDecryption is the ESPSATable used to decrypt ESP packets decoded as
+// LayerTypeIPSecESP.  nil, the default, disables decryption.
+var ESPDecryption *ESPSATable
+
+// ESPCipher is the encryption algorithm of an ESP security association.
+type ESPCipher uint8
+
+// ESP ciphers
+const (
+	// ESPCipherNull is the NULL encryption of RFC 2410, used with
+	// integrity protection only
+	ESPCipherNull ESPCipher = iota
+	// ESPCipherAESCBC is AES-CBC, RFC 3602
+	ESPCipherAESCBC
+	// ESPCipherAESCTR is AES-CTR, RFC 3686
+	ESPCipherAESCTR
+	// ESPCipherAESGCM is AES-GCM, RFC 4106
+	ESPCipherAESGCM
+)
+
+func (c ESPCipher) String() string {
+	switch c {
+	case ESPCipherNull:
+		return "NULL"
+	case ESPCipherAESCBC:
+		return "AES-CBC"
+	case ESPCipherAESCTR:
+		return "AES-CTR"
+	case ESPCipherAESGCM:
+		return "AES-GCM"
+	}
+	return fmt.Sprintf("ESPCipher(%d)", uint8(c))
+}
+
+// ESPSA is the part of an ESP security association needed to decrypt its
+// packets, as found in the output of "ip xfrm state" on Linux.
+type ESPSA struct {
+	Cipher ESPCipher
+	// Key is the encryption key.  For AES-CTR and AES-GCM, it ends with the
+	// 4 byte nonce or salt, RFC 3686 section 5.1 and RFC 4106 section 8.1.
+	Key []byte
+	// ICVLength is the length of the integrity check value ending the
+	// packets: the tag length of AES-GCM, or the truncated length of the
+	// HMAC used with other ciphers, e.g. 12 for HMAC-SHA1-96 and 16 for
+	// HMAC-SHA-256-128.  Only the ICV of AES-GCM is verified.
+	ICVLength int
+}
+
+// espSA is a security association with its cipher initialized.
+type espSA struct {
+	ESPSA
+	block cipher.Block
+	aead  cipher.AEAD
+	// nonce is the nonce or salt of AES-CTR and AES-GCM
+	nonce []byte
+}
+
+// ivLength returns the length of the IV of the packets of sa.
+func (sa *espSA) ivLength() int {
+	switch sa.Cipher {
+	case ESPCipherAESCBC:
+		return aes.BlockSize
+	case ESPCipherAESCTR, ESPCipherAESGCM:
+		return 8
+	}
+	return 0
+}
+
+// ESPSATable holds the security associations used to decrypt ESP packets,
+// by their SPI.  Since SPIs are chosen by the receivers of packets, the SAs
+// of different receivers may have the same SPI, in which case they have to
+// be decrypted with different tables.  Extended sequence numbers aren't
+// supported.
+//
+// An ESPSATable is safe for concurrent use.
+type ESPSATable struct {
+	mu  sync.RWMutex
+	sas map[uint32]*espSA
+}
+
+// NewESPSATable returns an empty ESPSATable.
+func NewESPSATable() *ESPSATable {
+	return &ESPSATable{sas: map[uint32]*espSA{}}
+}
+
+// Add adds the SA of spi, replacing any SA it had.
+func (t *ESPSATable) Add(spi uint32, sa ESPSA) error {
+	s := &espSA{ESPSA: sa}
+	s.Key = append([]byte(nil), sa.Key...)
+	if sa.ICVLength < 0 {
+		return fmt.Errorf("invalid ESP ICV length %d", sa.ICVLength)
+	}
+	key := s.Key
+	switch sa.Cipher {
+	case ESPCipherNull:
+	case ESPCipherAESCBC:
+	case ESPCipherAESCTR, ESPCipherAESGCM:
+		if len(key) < 4 {
+			return fmt.Errorf("%v key too short", sa.Cipher)
+		}
+		key, s.nonce = key[:len(key)-4], key[len(key)-4:]
+	default:
+		return fmt.Errorf("unknown ESP cipher %v", sa.Cipher)
+	}
+	if sa.Cipher != ESPCipherNull {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+		s.block = block
+	}
+	if sa.Cipher == ESPCipherAESGCM {
+		aead, err := cipher.NewGCMWithTagSize(s.block, sa.ICVLength)
+		if err != nil {
+			return err
+		}
+		s.aead = aead
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sas[spi] = s
+	return nil
+}
+
+// Remove removes the SA of spi.
+func (t *ESPSATable) Remove(spi uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sas, spi)
+}
+
+var errESPNoSA = errors.New("no ESP security association")
+
+// Decrypt decrypts the payload of esp with the SA of its SPI.  On success,
+// it sets the Decrypted, IV, Padding, NextHeader and ICV fields of esp, and
+// its payload to the decrypted data.
+func (t *ESPSATable) Decrypt(esp *IPSecESP) error {
+	t.mu.RLock()
+	sa := t.sas[esp.SPI]
+	t.mu.RUnlock()
+	if sa == nil {
+		return errESPNoSA
+	}
+	data := esp.Encrypted
+	ivLen := sa.ivLength()
+	if len(data) < ivLen+sa.ICVLength {
+		return errors.New("ESP packet too short to decrypt")
+	}
+	iv := data[:ivLen]
+	ciphertext := data[ivLen : len(data)-sa.ICVLength]
+	var plaintext []byte
+	switch sa.Cipher {
+	case ESPCipherNull:
+		plaintext = ciphertext
+	case ESPCipherAESCBC:
+		if len(ciphertext)%aes.BlockSize != 0 {
+			return errors.New("ESP AES-CBC payload not a multiple of the block size")
+		}
+		plaintext = make([]byte, len(ciphertext))
+		cipher.NewCBCDecrypter(sa.block, iv).CryptBlocks(plaintext, ciphertext)
+	case ESPCipherAESCTR:
+		counter := make([]byte, aes.BlockSize)
+		copy(counter, sa.nonce)
+		copy(counter[4:], iv)
+		counter[15] = 1
+		plaintext = make([]byte, len(ciphertext))
+		cipher.NewCTR(sa.block, counter).XORKeyStream(plaintext, ciphertext)
+	case ESPCipherAESGCM:
+		nonce := append(append([]byte{}, sa.nonce...), iv...)
+		var err error
+		plaintext, err = sa.aead.Open(nil, nonce, data[ivLen:], esp.Contents[:8])
+		if err != nil {
+			return errors.New("ESP packet authentication failed")
+		}
+	}
+
+	// the trailer, RFC 4303 section 2.4
+	if len(plaintext) < 2 {
+		return errors.New("ESP trailer missing")
+	}
+	padLength := int(plaintext[len(plaintext)-2])
+	if len(plaintext) < 2+padLength {
+		return errors.New("ESP pad length too long")
+	}
+	padding := plaintext[len(plaintext)-2-padLength : len(plaintext)-2]
+	// the default padding also tells if the key is wrong
+	for i, b := range padding {
+		if b != byte(i+1) {
+			return errors.New("ESP padding malformed")
+		}
+	}
+	esp.Decrypted = true
+	esp.IV = iv
+	esp.Padding = padding
+	esp.NextHeader = IPProtocol(plaintext[len(plaintext)-1])
+	esp.ICV = data[len(data)-sa.ICVLength:]
+	esp.Payload = plaintext[:len(plaintext)-2-padLength]
+	return nil
+}
@@ -7,6 +7,10 @@
 package layers
 
 import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
 	"github.com/google/gopacket"
 	"reflect"
 	"testing"
@@ -152,3 +156,137 @@ func BenchmarkDecodePacketIPSecESP(b *testing.B) {
 		gopacket.NewPacket(testPacketIPSecESP, LinkTypeEthernet, gopacket.NoCopy)
 	}
 }
+
+// espTestEncrypt returns an ESP packet protecting inner with sa.
+func espTestEncrypt(sa ESPSA, spi, seq uint32, next IPProtocol, inner []byte) []byte {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header, spi)
+	binary.BigEndian.PutUint32(header[4:], seq)
+	align := 4
+	if sa.Cipher == ESPCipherAESCBC {
+		align = aes.BlockSize
+	}
+	plaintext := append([]byte{}, inner...)
+	for i := 1; (len(plaintext)+2)%align != 0; i++ {
+		plaintext = append(plaintext, byte(i))
+	}
+	plaintext = append(plaintext, byte(len(plaintext)-len(inner)), byte(next))
+
+	key := sa.Key
+	var nonce []byte
+	if sa.Cipher == ESPCipherAESCTR || sa.Cipher == ESPCipherAESGCM {
+		key, nonce = key[:len(key)-4], key[len(key)-4:]
+	}
+	var block cipher.Block
+	if sa.Cipher != ESPCipherNull {
+		block, _ = aes.NewCipher(key)
+	}
+	icv := bytes.Repeat([]byte{0x1c}, sa.ICVLength)
+	switch sa.Cipher {
+	case ESPCipherNull:
+		return bytes.Join([][]byte{header, plaintext, icv}, nil)
+	case ESPCipherAESCBC:
+		iv := bytes.Repeat([]byte{0x1f}, aes.BlockSize)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(plaintext, plaintext)
+		return bytes.Join([][]byte{header, iv, plaintext, icv}, nil)
+	case ESPCipherAESCTR:
+		iv := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+		counter := append(append(append([]byte{}, nonce...), iv...), 0, 0, 0, 1)
+		cipher.NewCTR(block, counter).XORKeyStream(plaintext, plaintext)
+		return bytes.Join([][]byte{header, iv, plaintext, icv}, nil)
+	}
+	iv := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	aead, _ := cipher.NewGCMWithTagSize(block, sa.ICVLength)
+	return bytes.Join([][]byte{header, iv, aead.Seal(nil, append(nonce, iv...), plaintext, header)}, nil)
+}
+
+func TestIPSecESPDecryption(t *testing.T) {
+	defer func() { ESPDecryption = nil }()
+	ESPDecryption = NewESPSATable()
+	key := bytes.Repeat([]byte{0x4b}, 20)
+	sas := []ESPSA{
+		{Cipher: ESPCipherNull, ICVLength: 12},
+		{Cipher: ESPCipherAESCBC, Key: key[:16], ICVLength: 12},
+		{Cipher: ESPCipherAESCTR, Key: key, ICVLength: 16},
+		{Cipher: ESPCipherAESGCM, Key: key, ICVLength: 16},
+	}
+	inner, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 1, DstPort: 2}).Payload([]byte("hello")).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sa := range sas {
+		spi := uint32(0x100 + i)
+		if err := ESPDecryption.Add(spi, sa); err != nil {
+			t.Fatal(err)
+		}
+		// tunnel mode
+		esp := espTestEncrypt(sa, spi, 1, IPProtocolIPv4, inner)
+		data, err := Build().IPv4(&IPv4{Protocol: IPProtocolESP}).Payload(esp).Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+		if p.ErrorLayer() != nil {
+			t.Errorf("%v: %v", sa.Cipher, p.ErrorLayer().Error())
+			continue
+		}
+		checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeIPSecESP, LayerTypeIPv4, LayerTypeUDP, gopacket.LayerTypePayload}, t)
+		e := p.Layer(LayerTypeIPSecESP).(*IPSecESP)
+		if !e.Decrypted || e.NextHeader != IPProtocolIPv4 || !bytes.Equal(e.Payload, inner) || len(e.ICV) != sa.ICVLength {
+			t.Errorf("%v: unexpected ESP layer %#v", sa.Cipher, e)
+		}
+		if string(p.ApplicationLayer().Payload()) != "hello" {
+			t.Errorf("%v: unexpected payload %q", sa.Cipher, p.ApplicationLayer().Payload())
+		}
+	}
+
+	// transport mode over UDP encapsulation
+	esp := espTestEncrypt(sas[3], 0x103, 2, IPProtocolUDP, inner[20:])
+	data, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 4500, DstPort: 4500}).Payload(esp).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeIPSecUDPEncap, LayerTypeIPSecESP, LayerTypeUDP, gopacket.LayerTypePayload}, t)
+
+	// a packet of an unknown SPI isn't decrypted, one of a wrong key fails
+	for spi, wantError := range map[uint32]bool{0x200: false, 0x101: true} {
+		esp := espTestEncrypt(ESPSA{Cipher: ESPCipherAESCBC, Key: make([]byte, 16), ICVLength: 12}, spi, 1, IPProtocolIPv4, inner)
+		data, err := Build().IPv4(&IPv4{Protocol: IPProtocolESP}).Payload(esp).Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+		if (p.ErrorLayer() != nil) != wantError || p.Layer(LayerTypeIPSecESP).(*IPSecESP).Decrypted {
+			t.Errorf("SPI %#x: unexpected error %v", spi, p.ErrorLayer())
+		}
+	}
+
+	if err := ESPDecryption.Add(1, ESPSA{Cipher: ESPCipherAESGCM, Key: key[:4]}); err == nil {
+		t.Error("no error for short key")
+	}
+}
+
+func TestIPSecUDPEncap(t *testing.T) {
+	for _, test := range []struct {
+		data []byte
+		want []gopacket.LayerType
+	}{
+		{[]byte{0xff}, []gopacket.LayerType{LayerTypeIPSecUDPEncap}},
+		{append([]byte{0, 0, 0, 0}, testIKEv2SAInit...), []gopacket.LayerType{LayerTypeIPSecUDPEncap, LayerTypeIKEv2}},
+		{[]byte{0, 0, 1, 0, 0, 0, 0, 1, 2, 3}, []gopacket.LayerType{LayerTypeIPSecUDPEncap, LayerTypeIPSecESP}},
+	} {
+		p := gopacket.NewPacket(test.data, LayerTypeIPSecUDPEncap, gopacket.Default)
+		if p.ErrorLayer() != nil {
+			t.Fatal(p.ErrorLayer().Error())
+		}
+		checkLayers(p, test.want, t)
+	}
+	p := gopacket.NewPacket([]byte{0, 0, 1, 0, 0}, LayerTypeIPSecUDPEncap, gopacket.Default)
+	if p.ErrorLayer() == nil {
+		t.Error("no error for truncated ESP packet")
+	}
+}
@@ -0,0 +1,656 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/gopacket"
+)
+
+// isisNLPID is the network layer protocol identifier of IS-IS, the first
+// byte of every IS-IS PDU.
+const isisNLPID = 0x83
+
+// ISISPDUType is the type of an IS-IS PDU.
+type ISISPDUType uint8
+
+// ISISPDUType known values, see ISO/IEC 10589, section 9
+const (
+	ISISPDUTypeL1LANHello ISISPDUType = 15
+	ISISPDUTypeL2LANHello ISISPDUType = 16
+	ISISPDUTypeP2PHello   ISISPDUType = 17
+	ISISPDUTypeL1LSP      ISISPDUType = 18
+	ISISPDUTypeL2LSP      ISISPDUType = 20
+	ISISPDUTypeL1CSNP     ISISPDUType = 24
+	ISISPDUTypeL2CSNP     ISISPDUType = 25
+	ISISPDUTypeL1PSNP     ISISPDUType = 26
+	ISISPDUTypeL2PSNP     ISISPDUType = 27
+)
+
+func (t ISISPDUType) String() string {
+	switch t {
+	case ISISPDUTypeL1LANHello:
+		return "L1 LAN Hello"
+	case ISISPDUTypeL2LANHello:
+		return "L2 LAN Hello"
+	case ISISPDUTypeP2PHello:
+		return "P2P Hello"
+	case ISISPDUTypeL1LSP:
+		return "L1 LSP"
+	case ISISPDUTypeL2LSP:
+		return "L2 LSP"
+	case ISISPDUTypeL1CSNP:
+		return "L1 CSNP"
+	case ISISPDUTypeL2CSNP:
+		return "L2 CSNP"
+	case ISISPDUTypeL1PSNP:
+		return "L1 PSNP"
+	case ISISPDUTypeL2PSNP:
+		return "L2 PSNP"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(t))
+	}
+}
+
+// ISISTLVType is the type of an IS-IS TLV.
+type ISISTLVType uint8
+
+// ISISTLVType known values, see ISO/IEC 10589, RFC 1195, RFC 5305, RFC 5308
+// and RFC 5301
+const (
+	ISISTLVAreaAddresses          ISISTLVType = 1
+	ISISTLVISReachability         ISISTLVType = 2
+	ISISTLVISNeighbors            ISISTLVType = 6
+	ISISTLVPadding                ISISTLVType = 8
+	ISISTLVLSPEntries             ISISTLVType = 9
+	ISISTLVAuthentication         ISISTLVType = 10
+	ISISTLVExtendedISReachability ISISTLVType = 22
+	ISISTLVIPInternalReachability ISISTLVType = 128
+	ISISTLVProtocolsSupported     ISISTLVType = 129
+	ISISTLVIPExternalReachability ISISTLVType = 130
+	ISISTLVIPInterfaceAddress     ISISTLVType = 132
+	ISISTLVTERouterID             ISISTLVType = 134
+	ISISTLVExtendedIPReachability ISISTLVType = 135
+	ISISTLVHostname               ISISTLVType = 137
+	ISISTLVIPv6InterfaceAddress   ISISTLVType = 232
+	ISISTLVIPv6Reachability       ISISTLVType = 236
+	ISISTLVP2PAdjacencyState      ISISTLVType = 240
+)
+
+func (t ISISTLVType) String() string {
+	switch t {
+	case ISISTLVAreaAddresses:
+		return "Area Addresses"
+	case ISISTLVISReachability:
+		return "IS Reachability"
+	case ISISTLVISNeighbors:
+		return "IS Neighbors"
+	case ISISTLVPadding:
+		return "Padding"
+	case ISISTLVLSPEntries:
+		return "LSP Entries"
+	case ISISTLVAuthentication:
+		return "Authentication"
+	case ISISTLVExtendedISReachability:
+		return "Extended IS Reachability"
+	case ISISTLVIPInternalReachability:
+		return "IP Internal Reachability"
+	case ISISTLVProtocolsSupported:
+		return "Protocols Supported"
+	case ISISTLVIPExternalReachability:
+		return "IP External Reachability"
+	case ISISTLVIPInterfaceAddress:
+		return "IP Interface Address"
+	case ISISTLVTERouterID:
+		return "TE Router ID"
+	case ISISTLVExtendedIPReachability:
+		return "Extended IP Reachability"
+	case ISISTLVHostname:
+		return "Hostname"
+	case ISISTLVIPv6InterfaceAddress:
+		return "IPv6 Interface Address"
+	case ISISTLVIPv6Reachability:
+		return "IPv6 Reachability"
+	case ISISTLVP2PAdjacencyState:
+		return "P2P Adjacency State"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(t))
+	}
+}
+
+// ISISTLV is a TLV of an IS-IS PDU.
+type ISISTLV struct {
+	Type  ISISTLVType
+	Value []byte
+}
+
+// ISISSubTLV is a sub-TLV of an extended reachability entry.  Sub-TLV types
+// are specific to the TLV containing them.
+type ISISSubTLV struct {
+	Type  uint8
+	Value []byte
+}
+
+// ISISSystemID is the system ID of an intermediate system.
+type ISISSystemID []byte
+
+// String returns the system ID in the usual dotted form, like
+// "1921.6800.1001".
+func (id ISISSystemID) String() string {
+	s := hex.EncodeToString(id)
+	var parts []string
+	for len(s) > 4 {
+		parts = append(parts, s[:4])
+		s = s[4:]
+	}
+	return strings.Join(append(parts, s), ".")
+}
+
+// ISISNodeID is a system ID with a pseudonode ID, identifying either a
+// router or, if Pseudonode is not zero, a LAN.
+type ISISNodeID struct {
+	SystemID   ISISSystemID
+	Pseudonode uint8
+}
+
+func (id ISISNodeID) String() string {
+	return fmt.Sprintf("%v.%02x", id.SystemID, id.Pseudonode)
+}
+
+// ISISLSPID identifies a fragment of a link state PDU.
+type ISISLSPID struct {
+	ISISNodeID
+	Fragment uint8
+}
+
+func (id ISISLSPID) String() string {
+	return fmt.Sprintf("%v-%02x", id.ISISNodeID, id.Fragment)
+}
+
+// ISISHello is the header of LAN and point-to-point hello PDUs.
+type ISISHello struct {
+	CircuitType uint8 // 1 for level 1, 2 for level 2, 3 for both
+	SourceID    ISISSystemID
+	HoldingTime uint16 // Seconds
+	PDULength   uint16
+	// Priority and LANID are only used by LAN hellos.
+	Priority uint8
+	LANID    ISISNodeID
+	// LocalCircuitID is only used by point-to-point hellos.
+	LocalCircuitID uint8
+}
+
+// ISISLSP is the header of link state PDUs.
+type ISISLSP struct {
+	PDULength         uint16
+	RemainingLifetime uint16 // Seconds
+	LSPID             ISISLSPID
+	SequenceNumber    uint32
+	Checksum          uint16
+	Partition         bool
+	Attached          uint8 // Default, delay, expense and error metric bits
+	Overload          bool
+	ISType            uint8 // 1 for level 1, 3 for level 2
+}
+
+// ISISSNP is the header of complete and partial sequence number PDUs.
+// StartLSPID and EndLSPID are only used by CSNPs.
+type ISISSNP struct {
+	PDULength  uint16
+	SourceID   ISISNodeID
+	StartLSPID ISISLSPID
+	EndLSPID   ISISLSPID
+}
+
+// ISISISReachability is an entry of an IS reachability TLV.
+type ISISISReachability struct {
+	Virtual    bool
+	Metric     uint8
+	NeighborID ISISNodeID
+}
+
+// ISISExtendedISReachability is an entry of an extended IS reachability TLV.
+type ISISExtendedISReachability struct {
+	NeighborID ISISNodeID
+	Metric     uint32
+	SubTLVs    []ISISSubTLV
+}
+
+// ISISIPReachability is an entry of an IP internal or external reachability
+// TLV.
+type ISISIPReachability struct {
+	External bool
+	Metric   uint8
+	Prefix   net.IPNet
+}
+
+// ISISExtendedIPReachability is an entry of an extended IP reachability TLV.
+type ISISExtendedIPReachability struct {
+	Metric  uint32
+	Down    bool
+	Prefix  net.IPNet
+	SubTLVs []ISISSubTLV
+}
+
+// ISISIPv6Reachability is an entry of an IPv6 reachability TLV.
+type ISISIPv6Reachability struct {
+	Metric   uint32
+	Down     bool
+	External bool
+	Prefix   net.IPNet
+	SubTLVs  []ISISSubTLV
+}
+
+// ISISLSPEntry is an entry of an LSP entries TLV, describing an LSP in a
+// sequence number PDU.
+type ISISLSPEntry struct {
+	RemainingLifetime uint16
+	LSPID             ISISLSPID
+	SequenceNumber    uint32
+	Checksum          uint16
+}
+
+// ISIS is an IS-IS PDU, see ISO/IEC 10589.  Hello, LSP or SNP holds the PDU
+// specific header, depending on Type.  TLVs holds all TLVs of the PDU, the
+// ones understood are also decoded into the remaining fields.
+type ISIS struct {
+	BaseLayer
+	HeaderLength     uint8
+	Version          uint8
+	IDLength         uint8 // As sent, 0 means 6 bytes
+	Type             ISISPDUType
+	MaxAreaAddresses uint8
+
+	Hello *ISISHello
+	LSP   *ISISLSP
+	SNP   *ISISSNP
+
+	TLVs                   []ISISTLV
+	AreaAddresses          [][]byte
+	ProtocolsSupported     []uint8
+	Hostname               string
+	ISNeighbors            []net.HardwareAddr
+	ISReachability         []ISISISReachability
+	ExtendedISReachability []ISISExtendedISReachability
+	IPReachability         []ISISIPReachability
+	ExtendedIPReachability []ISISExtendedIPReachability
+	IPv6Reachability       []ISISIPv6Reachability
+	IPInterfaceAddresses   []net.IP
+	IPv6InterfaceAddresses []net.IP
+	LSPEntries             []ISISLSPEntry
+}
+
+// LayerType returns LayerTypeISIS.
+func (i *ISIS) LayerType() gopacket.LayerType { return LayerTypeISIS }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (i *ISIS) CanDecode() gopacket.LayerClass {
+	return LayerTypeISIS
+}
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (i *ISIS) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypeZero
+}
+
+func decodeISIS(data []byte, p gopacket.PacketBuilder) error {
+	isis := &ISIS{}
+	return decodingLayerDecoder(isis, data, p)
+}
+
+// idLen returns the length of system IDs in the PDU.
+func (i *ISIS) idLen() int {
+	switch i.IDLength {
+	case 0:
+		return 6
+	case 255:
+		return 0
+	}
+	return int(i.IDLength)
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (i *ISIS) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 8 {
+		df.SetTruncated()
+		return errors.New("IS-IS PDU too small")
+	}
+	if data[0] != isisNLPID {
+		return fmt.Errorf("invalid IS-IS protocol identifier %#x", data[0])
+	}
+	*i = ISIS{
+		HeaderLength:     data[1],
+		Version:          data[2],
+		IDLength:         data[3],
+		Type:             ISISPDUType(data[4] & 0x1f),
+		MaxAreaAddresses: data[7],
+	}
+	if i.IDLength > 8 && i.IDLength != 255 {
+		return fmt.Errorf("invalid IS-IS ID length %d", i.IDLength)
+	}
+	idLen := i.idLen()
+
+	var headerLength int
+	var pduLength uint16
+	switch i.Type {
+	case ISISPDUTypeL1LANHello, ISISPDUTypeL2LANHello, ISISPDUTypeP2PHello:
+		headerLength = 8 + 1 + idLen + 4
+		if i.Type == ISISPDUTypeP2PHello {
+			headerLength++
+		} else {
+			headerLength += 1 + idLen + 1
+		}
+		if len(data) < headerLength {
+			df.SetTruncated()
+			return errors.New("IS-IS hello header too small")
+		}
+		h := &ISISHello{
+			CircuitType: data[8] & 0x03,
+			SourceID:    ISISSystemID(data[9 : 9+idLen]),
+			HoldingTime: binary.BigEndian.Uint16(data[9+idLen:]),
+			PDULength:   binary.BigEndian.Uint16(data[11+idLen:]),
+		}
+		if i.Type == ISISPDUTypeP2PHello {
+			h.LocalCircuitID = data[13+idLen]
+		} else {
+			h.Priority = data[13+idLen] & 0x7f
+			h.LANID = decodeISISNodeID(data[14+idLen:], idLen)
+		}
+		pduLength = h.PDULength
+		i.Hello = h
+	case ISISPDUTypeL1LSP, ISISPDUTypeL2LSP:
+		headerLength = 8 + 4 + idLen + 2 + 7
+		if len(data) < headerLength {
+			df.SetTruncated()
+			return errors.New("IS-IS LSP header too small")
+		}
+		flags := data[headerLength-1]
+		l := &ISISLSP{
+			PDULength:         binary.BigEndian.Uint16(data[8:10]),
+			RemainingLifetime: binary.BigEndian.Uint16(data[10:12]),
+			LSPID:             decodeISISLSPID(data[12:], idLen),
+			SequenceNumber:    binary.BigEndian.Uint32(data[14+idLen:]),
+			Checksum:          binary.BigEndian.Uint16(data[18+idLen:]),
+			Partition:         flags&0x80 != 0,
+			Attached:          flags >> 3 & 0x0f,
+			Overload:          flags&0x04 != 0,
+			ISType:            flags & 0x03,
+		}
+		pduLength = l.PDULength
+		i.LSP = l
+	case ISISPDUTypeL1CSNP, ISISPDUTypeL2CSNP, ISISPDUTypeL1PSNP, ISISPDUTypeL2PSNP:
+		headerLength = 8 + 2 + idLen + 1
+		csnp := i.Type == ISISPDUTypeL1CSNP || i.Type == ISISPDUTypeL2CSNP
+		if csnp {
+			headerLength += 2 * (idLen + 2)
+		}
+		if len(data) < headerLength {
+			df.SetTruncated()
+			return errors.New("IS-IS SNP header too small")
+		}
+		s := &ISISSNP{
+			PDULength: binary.BigEndian.Uint16(data[8:10]),
+			SourceID:  decodeISISNodeID(data[10:], idLen),
+		}
+		if csnp {
+			s.StartLSPID = decodeISISLSPID(data[11+idLen:], idLen)
+			s.EndLSPID = decodeISISLSPID(data[13+2*idLen:], idLen)
+		}
+		pduLength = s.PDULength
+		i.SNP = s
+	default:
+		return fmt.Errorf("unknown IS-IS PDU type %d", i.Type)
+	}
+	if int(i.HeaderLength) != headerLength {
+		return fmt.Errorf("invalid IS-IS header length %d for %v, want %d", i.HeaderLength, i.Type, headerLength)
+	}
+	if int(pduLength) < headerLength || int(pduLength) > len(data) {
+		df.SetTruncated()
+		return fmt.Errorf("invalid IS-IS PDU length %d", pduLength)
+	}
+	i.Contents = data[:pduLength]
+	return i.decodeTLVs(data[headerLength:pduLength])
+}
+
+func decodeISISNodeID(data []byte, idLen int) ISISNodeID {
+	return ISISNodeID{
+		SystemID:   ISISSystemID(data[:idLen]),
+		Pseudonode: data[idLen],
+	}
+}
+
+func decodeISISLSPID(data []byte, idLen int) ISISLSPID {
+	return ISISLSPID{
+		ISISNodeID: decodeISISNodeID(data, idLen),
+		Fragment:   data[idLen+1],
+	}
+}
+
+// decodeISISTLVs splits data into TLVs.
+func decodeISISTLVs(data []byte) ([]ISISTLV, error) {
+	var tlvs []ISISTLV
+	for len(data) > 0 {
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			return nil, errors.New("IS-IS TLV truncated")
+		}
+		tlvs = append(tlvs, ISISTLV{Type: ISISTLVType(data[0]), Value: data[2 : 2+int(data[1])]})
+		data = data[2+int(data[1]):]
+	}
+	return tlvs, nil
+}
+
+func (i *ISIS) decodeTLVs(data []byte) error {
+	tlvs, err := decodeISISTLVs(data)
+	if err != nil {
+		return err
+	}
+	i.TLVs = tlvs
+	for _, tlv := range tlvs {
+		if err := i.decodeTLV(tlv); err != nil {
+			return fmt.Errorf("invalid IS-IS %v TLV: %v", tlv.Type, err)
+		}
+	}
+	return nil
+}
+
+func (i *ISIS) decodeTLV(tlv ISISTLV) error {
+	v := tlv.Value
+	idLen := i.idLen()
+	switch tlv.Type {
+	case ISISTLVAreaAddresses:
+		for len(v) > 0 {
+			if len(v) < 1+int(v[0]) {
+				return errors.New("area address truncated")
+			}
+			i.AreaAddresses = append(i.AreaAddresses, v[1:1+int(v[0])])
+			v = v[1+int(v[0]):]
+		}
+	case ISISTLVProtocolsSupported:
+		i.ProtocolsSupported = append(i.ProtocolsSupported, v...)
+	case ISISTLVHostname:
+		i.Hostname = string(v)
+	case ISISTLVISNeighbors:
+		if len(v)%6 != 0 {
+			return fmt.Errorf("length %d not a multiple of 6", len(v))
+		}
+		for ; len(v) > 0; v = v[6:] {
+			i.ISNeighbors = append(i.ISNeighbors, net.HardwareAddr(v[:6]))
+		}
+	case ISISTLVISReachability:
+		size := 4 + idLen + 1
+		if len(v) < 1 || (len(v)-1)%size != 0 {
+			return fmt.Errorf("invalid length %d", len(v))
+		}
+		virtual := v[0] != 0
+		for v = v[1:]; len(v) > 0; v = v[size:] {
+			i.ISReachability = append(i.ISReachability, ISISISReachability{
+				Virtual:    virtual,
+				Metric:     v[0] & 0x3f,
+				NeighborID: decodeISISNodeID(v[4:], idLen),
+			})
+		}
+	case ISISTLVExtendedISReachability:
+		for len(v) > 0 {
+			if len(v) < idLen+5 || len(v) < idLen+5+int(v[idLen+4]) {
+				return errors.New("entry truncated")
+			}
+			end := idLen + 5 + int(v[idLen+4])
+			subTLVs, err := decodeISISSubTLVList(v[idLen+5 : end])
+			if err != nil {
+				return err
+			}
+			i.ExtendedISReachability = append(i.ExtendedISReachability, ISISExtendedISReachability{
+				NeighborID: decodeISISNodeID(v, idLen),
+				Metric:     uint32(v[idLen+1])<<16 | uint32(v[idLen+2])<<8 | uint32(v[idLen+3]),
+				SubTLVs:    subTLVs,
+			})
+			v = v[end:]
+		}
+	case ISISTLVIPInternalReachability, ISISTLVIPExternalReachability:
+		if len(v)%12 != 0 {
+			return fmt.Errorf("length %d not a multiple of 12", len(v))
+		}
+		for ; len(v) > 0; v = v[12:] {
+			i.IPReachability = append(i.IPReachability, ISISIPReachability{
+				External: tlv.Type == ISISTLVIPExternalReachability,
+				Metric:   v[0] & 0x3f,
+				Prefix:   net.IPNet{IP: net.IP(v[4:8]), Mask: net.IPMask(v[8:12])},
+			})
+		}
+	case ISISTLVExtendedIPReachability:
+		for len(v) > 0 {
+			if len(v) < 5 {
+				return errors.New("entry truncated")
+			}
+			control := v[4]
+			prefix, n, err := decodeISISPrefix(v[5:], control&0x3f, net.IPv4len)
+			if err != nil {
+				return err
+			}
+			subTLVs, m, err := decodeISISSubTLVs(v[5+n:], control&0x40 != 0)
+			if err != nil {
+				return err
+			}
+			i.ExtendedIPReachability = append(i.ExtendedIPReachability, ISISExtendedIPReachability{
+				Metric:  binary.BigEndian.Uint32(v[0:4]),
+				Down:    control&0x80 != 0,
+				Prefix:  prefix,
+				SubTLVs: subTLVs,
+			})
+			v = v[5+n+m:]
+		}
+	case ISISTLVIPv6Reachability:
+		for len(v) > 0 {
+			if len(v) < 6 {
+				return errors.New("entry truncated")
+			}
+			control := v[4]
+			prefix, n, err := decodeISISPrefix(v[6:], v[5], net.IPv6len)
+			if err != nil {
+				return err
+			}
+			subTLVs, m, err := decodeISISSubTLVs(v[6+n:], control&0x20 != 0)
+			if err != nil {
+				return err
+			}
+			i.IPv6Reachability = append(i.IPv6Reachability, ISISIPv6Reachability{
+				Metric:   binary.BigEndian.Uint32(v[0:4]),
+				Down:     control&0x80 != 0,
+				External: control&0x40 != 0,
+				Prefix:   prefix,
+				SubTLVs:  subTLVs,
+			})
+			v = v[6+n+m:]
+		}
+	case ISISTLVIPInterfaceAddress:
+		if len(v)%4 != 0 {
+			return fmt.Errorf("length %d not a multiple of 4", len(v))
+		}
+		for ; len(v) > 0; v = v[4:] {
+			i.IPInterfaceAddresses = append(i.IPInterfaceAddresses, net.IP(v[:4]))
+		}
+	case ISISTLVIPv6InterfaceAddress:
+		if len(v)%16 != 0 {
+			return fmt.Errorf("length %d not a multiple of 16", len(v))
+		}
+		for ; len(v) > 0; v = v[16:] {
+			i.IPv6InterfaceAddresses = append(i.IPv6InterfaceAddresses, net.IP(v[:16]))
+		}
+	case ISISTLVLSPEntries:
+		size := 2 + idLen + 2 + 6
+		if len(v)%size != 0 {
+			return fmt.Errorf("length %d not a multiple of %d", len(v), size)
+		}
+		for ; len(v) > 0; v = v[size:] {
+			i.LSPEntries = append(i.LSPEntries, ISISLSPEntry{
+				RemainingLifetime: binary.BigEndian.Uint16(v[0:2]),
+				LSPID:             decodeISISLSPID(v[2:], idLen),
+				SequenceNumber:    binary.BigEndian.Uint32(v[idLen+4:]),
+				Checksum:          binary.BigEndian.Uint16(v[idLen+8:]),
+			})
+		}
+	}
+	return nil
+}
+
+// decodeISISPrefix decodes a prefix of prefixLen bits packed into the
+// fewest bytes possible, returning it and the number of bytes used.
+func decodeISISPrefix(data []byte, prefixLen uint8, addrLen int) (net.IPNet, int, error) {
+	if int(prefixLen) > addrLen*8 {
+		return net.IPNet{}, 0, fmt.Errorf("invalid prefix length %d", prefixLen)
+	}
+	n := (int(prefixLen) + 7) / 8
+	if len(data) < n {
+		return net.IPNet{}, 0, errors.New("prefix truncated")
+	}
+	ip := make(net.IP, addrLen)
+	copy(ip, data[:n])
+	return net.IPNet{IP: ip, Mask: net.CIDRMask(int(prefixLen), addrLen*8)}, n, nil
+}
+
+// decodeISISSubTLVs decodes the sub-TLVs of a reachability entry at the start
+// of data if present is set, returning them and the number of bytes used.
+func decodeISISSubTLVs(data []byte, present bool) ([]ISISSubTLV, int, error) {
+	if !present {
+		return nil, 0, nil
+	}
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return nil, 0, errors.New("sub-TLVs truncated")
+	}
+	tlvs, err := decodeISISSubTLVList(data[1 : 1+int(data[0])])
+	return tlvs, 1 + int(data[0]), err
+}
+
+// decodeISISSubTLVList splits data into sub-TLVs.
+func decodeISISSubTLVList(data []byte) ([]ISISSubTLV, error) {
+	var tlvs []ISISSubTLV
+	for len(data) > 0 {
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			return nil, errors.New("IS-IS sub-TLV truncated")
+		}
+		tlvs = append(tlvs, ISISSubTLV{Type: data[0], Value: data[2 : 2+int(data[1])]})
+		data = data[2+int(data[1]):]
+	}
+	return tlvs, nil
+}
+
+// VerifyChecksum verifies the checksum of an LSP, implementing
+// gopacket.ChecksumVerifier.  Other PDUs carry no checksum and are always
+// reported as valid, as are purged LSPs without checksum.
+func (i *ISIS) VerifyChecksum() (bool, error) {
+	if i.LSP == nil || (i.LSP.Checksum == 0 && i.LSP.RemainingLifetime == 0) {
+		return true, nil
+	}
+	// The checksum covers the LSP from the LSP ID to the end.
+	return isoChecksum(i.Contents[12:], i.idLen()+6) == i.LSP.Checksum, nil
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketISISL1Hello is an L1 LAN hello from 1921.6800.1001 with
+// protocols supported, area address, IP interface address, IS neighbors and
+// padding TLVs.
+var testPacketISISL1Hello = []byte{
+	0x01, 0x80, 0xc2, 0x00, 0x00, 0x14, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x00, 0x42, 0xfe, 0xfe,
+	0x03, 0x83, 0x1b, 0x01, 0x00, 0x0f, 0x01, 0x00, 0x00, 0x01, 0x19, 0x21, 0x68, 0x00, 0x10, 0x01,
+	0x00, 0x1e, 0x00, 0x3f, 0x40, 0x19, 0x21, 0x68, 0x00, 0x10, 0x01, 0x01, 0x81, 0x02, 0xcc, 0x8e,
+	0x01, 0x04, 0x03, 0x49, 0x00, 0x01, 0x84, 0x04, 0x0a, 0x00, 0x00, 0x01, 0x06, 0x06, 0x00, 0x0c,
+	0x29, 0x44, 0x55, 0x66, 0x08, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestISISL1Hello(t *testing.T) {
+	p := gopacket.NewPacket(testPacketISISL1Hello, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeLLC, LayerTypeISIS}, t)
+	isis := p.Layer(LayerTypeISIS).(*ISIS)
+	if isis.Type != ISISPDUTypeL1LANHello || isis.HeaderLength != 27 || isis.idLen() != 6 {
+		t.Errorf("IS-IS header mismatch: %#v", isis)
+	}
+	want := &ISISHello{
+		CircuitType: 1,
+		SourceID:    ISISSystemID{0x19, 0x21, 0x68, 0x00, 0x10, 0x01},
+		HoldingTime: 30,
+		PDULength:   63,
+		Priority:    64,
+		LANID:       ISISNodeID{SystemID: ISISSystemID{0x19, 0x21, 0x68, 0x00, 0x10, 0x01}, Pseudonode: 1},
+	}
+	if !reflect.DeepEqual(isis.Hello, want) {
+		t.Errorf("IS-IS hello mismatch:\ngot  %#v\nwant %#v", isis.Hello, want)
+	}
+	if got := isis.Hello.LANID.String(); got != "1921.6800.1001.01" {
+		t.Errorf("LAN ID %q", got)
+	}
+	if len(isis.TLVs) != 5 || isis.TLVs[4].Type != ISISTLVPadding || len(isis.TLVs[4].Value) != 10 {
+		t.Errorf("IS-IS TLVs mismatch: %v", isis.TLVs)
+	}
+	if !reflect.DeepEqual(isis.ProtocolsSupported, []uint8{0xcc, 0x8e}) {
+		t.Errorf("protocols supported %x", isis.ProtocolsSupported)
+	}
+	if !reflect.DeepEqual(isis.AreaAddresses, [][]byte{{0x49, 0x00, 0x01}}) {
+		t.Errorf("area addresses %x", isis.AreaAddresses)
+	}
+	if len(isis.IPInterfaceAddresses) != 1 || !isis.IPInterfaceAddresses[0].Equal(net.IP{10, 0, 0, 1}) {
+		t.Errorf("IP interface addresses %v", isis.IPInterfaceAddresses)
+	}
+	if len(isis.ISNeighbors) != 1 || isis.ISNeighbors[0].String() != "00:0c:29:44:55:66" {
+		t.Errorf("IS neighbors %v", isis.ISNeighbors)
+	}
+}
+
+// testPacketISISL2LSP is an L2 LSP 1921.6800.1001.00-00 with area address,
+// protocols supported, hostname, IP and IPv6 interface address, extended and
+// old style IS and IP reachability and IPv6 reachability TLVs.
+var testPacketISISL2LSP = []byte{
+	0x01, 0x80, 0xc2, 0x00, 0x00, 0x15, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x00, 0xd2, 0xfe, 0xfe,
+	0x03, 0x83, 0x1b, 0x01, 0x00, 0x14, 0x01, 0x00, 0x00, 0x00, 0xcf, 0x04, 0xb0, 0x19, 0x21, 0x68,
+	0x00, 0x10, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0xa9, 0xf1, 0x0b, 0x01, 0x04, 0x03, 0x49,
+	0x00, 0x01, 0x81, 0x02, 0xcc, 0x8e, 0x89, 0x02, 0x72, 0x31, 0x84, 0x04, 0x0a, 0x00, 0x00, 0x01,
+	0x16, 0x1c, 0x19, 0x21, 0x68, 0x00, 0x10, 0x02, 0x00, 0x00, 0x00, 0x0a, 0x06, 0x06, 0x04, 0x0a,
+	0x00, 0x00, 0x01, 0x19, 0x21, 0x68, 0x00, 0x10, 0x03, 0x01, 0x00, 0x00, 0x14, 0x00, 0x87, 0x1e,
+	0x00, 0x00, 0x00, 0x0a, 0x18, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x60, 0xc0, 0xa8, 0x00,
+	0x01, 0x06, 0x01, 0x04, 0x00, 0x00, 0x00, 0x64, 0x00, 0x00, 0x00, 0x64, 0x88, 0x0b, 0xec, 0x24,
+	0x00, 0x00, 0x00, 0x0a, 0x00, 0x40, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00,
+	0x00, 0x05, 0x40, 0x80, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x01, 0xe8, 0x10, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x02, 0x0c, 0x00, 0x0a, 0x80, 0x80, 0x80, 0x19, 0x21, 0x68,
+	0x00, 0x10, 0x02, 0x00, 0x80, 0x0c, 0x0a, 0x80, 0x80, 0x80, 0x0a, 0x00, 0x00, 0x00, 0xff, 0xff,
+	0xff, 0x00, 0x82, 0x0c, 0x14, 0x80, 0x80, 0x80, 0xc0, 0xa8, 0x01, 0x00, 0xff, 0xff, 0xff, 0x00,
+}
+
+func TestISISL2LSP(t *testing.T) {
+	p := gopacket.NewPacket(testPacketISISL2LSP, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeLLC, LayerTypeISIS}, t)
+	for _, r := range gopacket.VerifyChecksums(p) {
+		if r.Status != gopacket.ChecksumValid {
+			t.Errorf("%v checksum %v", r.LayerType, r.Status)
+		}
+	}
+	isis := p.Layer(LayerTypeISIS).(*ISIS)
+	sysID := func(last byte) ISISSystemID { return ISISSystemID{0x19, 0x21, 0x68, 0x00, 0x10, last} }
+	wantLSP := &ISISLSP{
+		PDULength:         207,
+		RemainingLifetime: 1200,
+		LSPID:             ISISLSPID{ISISNodeID: ISISNodeID{SystemID: sysID(1)}},
+		SequenceNumber:    5,
+		Checksum:          0xa9f1,
+		Attached:          1,
+		ISType:            3,
+	}
+	if !reflect.DeepEqual(isis.LSP, wantLSP) {
+		t.Errorf("IS-IS LSP mismatch:\ngot  %#v\nwant %#v", isis.LSP, wantLSP)
+	}
+	if got := isis.LSP.LSPID.String(); got != "1921.6800.1001.00-00" {
+		t.Errorf("LSP ID %q", got)
+	}
+	if isis.Hostname != "r1" {
+		t.Errorf("hostname %q", isis.Hostname)
+	}
+	wantIS := []ISISExtendedISReachability{
+		{
+			NeighborID: ISISNodeID{SystemID: sysID(2)},
+			Metric:     10,
+			SubTLVs:    []ISISSubTLV{{Type: 6, Value: []byte{10, 0, 0, 1}}},
+		},
+		{
+			NeighborID: ISISNodeID{SystemID: sysID(3), Pseudonode: 1},
+			Metric:     20,
+		},
+	}
+	if !reflect.DeepEqual(isis.ExtendedISReachability, wantIS) {
+		t.Errorf("extended IS reachability mismatch:\ngot  %#v\nwant %#v", isis.ExtendedISReachability, wantIS)
+	}
+	wantOldIS := []ISISISReachability{{Metric: 10, NeighborID: ISISNodeID{SystemID: sysID(2)}}}
+	if !reflect.DeepEqual(isis.ISReachability, wantOldIS) {
+		t.Errorf("IS reachability mismatch:\ngot  %#v\nwant %#v", isis.ISReachability, wantOldIS)
+	}
+	wantIP := []ISISExtendedIPReachability{
+		{Metric: 10, Prefix: mustParseCIDR("10.0.0.0/24")},
+		{Metric: 1, Prefix: mustParseCIDR("192.168.0.1/32"), SubTLVs: []ISISSubTLV{{Type: 1, Value: []byte{0, 0, 0, 100}}}},
+		{Metric: 100, Down: true, Prefix: mustParseCIDR("11.0.0.0/8")},
+	}
+	if !reflect.DeepEqual(isis.ExtendedIPReachability, wantIP) {
+		t.Errorf("extended IP reachability mismatch:\ngot  %#v\nwant %#v", isis.ExtendedIPReachability, wantIP)
+	}
+	wantIPv6 := []ISISIPv6Reachability{
+		{Metric: 10, Prefix: mustParseCIDR("2001:db8:0:1::/64")},
+		{Metric: 5, External: true, Prefix: mustParseCIDR("2001:db8::1/128")},
+	}
+	if !reflect.DeepEqual(isis.IPv6Reachability, wantIPv6) {
+		t.Errorf("IPv6 reachability mismatch:\ngot  %#v\nwant %#v", isis.IPv6Reachability, wantIPv6)
+	}
+	wantOldIP := []ISISIPReachability{
+		{Metric: 10, Prefix: net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPMask{255, 255, 255, 0}}},
+		{External: true, Metric: 20, Prefix: net.IPNet{IP: net.IP{192, 168, 1, 0}, Mask: net.IPMask{255, 255, 255, 0}}},
+	}
+	if !reflect.DeepEqual(isis.IPReachability, wantOldIP) {
+		t.Errorf("IP reachability mismatch:\ngot  %#v\nwant %#v", isis.IPReachability, wantOldIP)
+	}
+	if len(isis.IPv6InterfaceAddresses) != 1 || !isis.IPv6InterfaceAddresses[0].Equal(net.ParseIP("2001:db8::1")) {
+		t.Errorf("IPv6 interface addresses %v", isis.IPv6InterfaceAddresses)
+	}
+
+	// A corrupted LSP fails checksum verification.
+	data := append([]byte(nil), testPacketISISL2LSP...)
+	data[len(data)-1] ^= 0x01
+	p = gopacket.NewPacket(data, LinkTypeEthernet, testDecodeOptions)
+	if ok, err := p.Layer(LayerTypeISIS).(*ISIS).VerifyChecksum(); ok || err != nil {
+		t.Errorf("corrupted LSP checksum verified: %v, %v", ok, err)
+	}
+}
+
+// mustParseCIDR returns the network of s, panicking on error.
+func mustParseCIDR(s string) net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	if ip4 := n.IP.To4(); ip4 != nil {
+		n.IP = ip4
+	}
+	return *n
+}
+
+// testPacketISISL2CSNP is an L2 CSNP covering all LSP IDs with two LSP
+// entries.
+var testPacketISISL2CSNP = []byte{
+	0x01, 0x80, 0xc2, 0x00, 0x00, 0x15, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x00, 0x46, 0xfe, 0xfe,
+	0x03, 0x83, 0x21, 0x01, 0x00, 0x19, 0x01, 0x00, 0x00, 0x00, 0x43, 0x19, 0x21, 0x68, 0x00, 0x10,
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0x09, 0x20, 0x04, 0xb0, 0x19, 0x21, 0x68, 0x00, 0x10, 0x01, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x05, 0xa9, 0xf1, 0x04, 0x4c, 0x19, 0x21, 0x68, 0x00, 0x10, 0x02, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x10, 0x12, 0x34,
+}
+
+func TestISISL2CSNP(t *testing.T) {
+	p := gopacket.NewPacket(testPacketISISL2CSNP, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeLLC, LayerTypeISIS}, t)
+	isis := p.Layer(LayerTypeISIS).(*ISIS)
+	if isis.Type != ISISPDUTypeL2CSNP || isis.SNP == nil {
+		t.Fatalf("IS-IS header mismatch: %#v", isis)
+	}
+	if got := isis.SNP.StartLSPID.String(); got != "0000.0000.0000.00-00" {
+		t.Errorf("start LSP ID %q", got)
+	}
+	if got := isis.SNP.EndLSPID.String(); got != "ffff.ffff.ffff.ff-ff" {
+		t.Errorf("end LSP ID %q", got)
+	}
+	want := []ISISLSPEntry{
+		{
+			RemainingLifetime: 1200,
+			LSPID:             ISISLSPID{ISISNodeID: ISISNodeID{SystemID: ISISSystemID{0x19, 0x21, 0x68, 0x00, 0x10, 0x01}}},
+			SequenceNumber:    5,
+			Checksum:          0xa9f1,
+		},
+		{
+			RemainingLifetime: 1100,
+			LSPID:             ISISLSPID{ISISNodeID: ISISNodeID{SystemID: ISISSystemID{0x19, 0x21, 0x68, 0x00, 0x10, 0x02}}},
+			SequenceNumber:    0x10,
+			Checksum:          0x1234,
+		},
+	}
+	if !reflect.DeepEqual(isis.LSPEntries, want) {
+		t.Errorf("LSP entries mismatch:\ngot  %#v\nwant %#v", isis.LSPEntries, want)
+	}
+}
+
+func TestISISP2PHelloAndPSNP(t *testing.T) {
+	hello := []byte{
+		0x83, 0x14, 0x01, 0x00, 0x11, 0x01, 0x00, 0x00, 0x03, 0x19, 0x21, 0x68, 0x00, 0x10, 0x01, 0x00,
+		0x1e, 0x00, 0x1a, 0x07, 0xf0, 0x04, 0x02, 0x00, 0x00, 0x00, 0x05,
+	}
+	var isis ISIS
+	if err := isis.DecodeFromBytes(hello, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if isis.Hello == nil || isis.Hello.CircuitType != 3 || isis.Hello.LocalCircuitID != 7 || isis.Hello.PDULength != 26 {
+		t.Errorf("P2P hello mismatch: %#v", isis.Hello)
+	}
+	if len(isis.TLVs) != 1 || isis.TLVs[0].Type != ISISTLVP2PAdjacencyState {
+		t.Errorf("P2P hello TLVs mismatch: %v", isis.TLVs)
+	}
+
+	psnp := []byte{
+		0x83, 0x11, 0x01, 0x00, 0x1a, 0x01, 0x00, 0x00, 0x00, 0x23, 0x19, 0x21, 0x68, 0x00, 0x10, 0x02,
+		0x00, 0x09, 0x10, 0x00, 0x00, 0x19, 0x21, 0x68, 0x00, 0x10, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x06, 0x00, 0x00,
+	}
+	if err := isis.DecodeFromBytes(psnp, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if isis.Hello != nil || isis.SNP == nil || isis.SNP.SourceID.String() != "1921.6800.1002.00" {
+		t.Errorf("PSNP mismatch: %#v", isis.SNP)
+	}
+	if len(isis.LSPEntries) != 1 || isis.LSPEntries[0].SequenceNumber != 6 || isis.LSPEntries[0].RemainingLifetime != 0 {
+		t.Errorf("PSNP LSP entries mismatch: %#v", isis.LSPEntries)
+	}
+}
+
+func TestISISDecodeInvalid(t *testing.T) {
+	valid := testPacketISISL2LSP[17:]
+	for _, tc := range []struct {
+		name   string
+		mutate func([]byte) []byte
+	}{
+		{"truncated header", func(b []byte) []byte { return b[:20] }},
+		{"wrong header length", func(b []byte) []byte { b[1] = 26; return b }},
+		{"PDU length too large", func(b []byte) []byte { b[9]++; return b }},
+		{"TLV past PDU", func(b []byte) []byte { b[28] = 0xff; return b }},
+		{"unknown PDU type", func(b []byte) []byte { b[4] = 21; return b }},
+		{"invalid prefix length", func(b []byte) []byte { b[83] = 33; return b }},
+	} {
+		data := tc.mutate(append([]byte(nil), valid...))
+		var isis ISIS
+		if err := isis.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", tc.name)
+		}
+	}
+}
@@ -0,0 +1,884 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+
+package layers
+
+// Created by gen_json.go, don't edit manually
+
+import (
+	"github.com/google/gopacket"
+)
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ARP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ASF) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ASFPresencePong) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *BFD) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *BGP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *CiscoDiscovery) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *CiscoDiscoveryInfo) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *DHCPv4) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *DHCPv6) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *DNS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *DTLS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Diameter) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11Ctrl) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11CtrlAck) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11CtrlBlockAck) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11CtrlBlockAckReq) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11CtrlCFEnd) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11CtrlCFEndAck) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11CtrlCTS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11CtrlPowersavePoll) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11CtrlRTS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11Data) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataCFAck) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataCFAckNoData) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataCFAckPoll) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataCFAckPollNoData) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataCFPoll) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataCFPollNoData) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataNull) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataQOS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataQOSCFAckPollNoData) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataQOSCFPollNoData) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataQOSData) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataQOSDataCFAck) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataQOSDataCFAckPoll) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataQOSDataCFPoll) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11DataQOSNull) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11InformationElement) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtATIM) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtAction) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtActionNoAck) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtArubaWLAN) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtAssociationReq) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtAssociationResp) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtAuthentication) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtBeacon) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtDeauthentication) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtDisassociation) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtMeasurementPilot) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtProbeReq) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtProbeResp) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtReassociationReq) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11MgmtReassociationResp) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot11WEP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Dot1Q) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *EAP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *EAPOL) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *EAPOLKey) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ERSPANII) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ERSPANIII) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *EtherIP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Ethernet) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *EthernetCTP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *EthernetCTPForwardData) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *EthernetCTPReply) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *FDDI) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *GRE) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *GTPv1U) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *GTPv2C) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Geneve) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *HSRP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *HTTP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *HTTP2) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ICMPv4) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ICMPv6) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ICMPv6Echo) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ICMPv6NeighborAdvertisement) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ICMPv6NeighborSolicitation) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ICMPv6Redirect) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ICMPv6RouterAdvertisement) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ICMPv6RouterSolicitation) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IGMP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IGMPv1or2) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IKEv2) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPFIX) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPSecAH) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPSecESP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPSecUDPEncap) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPv4) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPv6) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPv6Destination) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPv6Fragment) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPv6HopByHop) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *IPv6Routing) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ISIS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *L2TP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *LACP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *LDP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *LLC) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *LLMNR) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *LinkLayerDiscovery) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *LinkLayerDiscoveryInfo) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *LinuxSLL) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Loopback) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *MACsec) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *MLDv1MulticastListenerDoneMessage) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *MLDv1MulticastListenerQueryMessage) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *MLDv1MulticastListenerReportMessage) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *MLDv2MulticastListenerQueryMessage) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *MLDv2MulticastListenerReportMessage) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *MPLS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *ModbusTCP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *NBNS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *NBSS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *NSH) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *NTP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *NetFlow) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *NortelDiscovery) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *OSPFv2) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *OSPFv3) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *OpenVPN) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *PFLog) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *PIM) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *PPP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *PPPoE) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *PTP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *PrismHeader) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *QUIC) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *QUICFrame) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *RADIUS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *RIP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *RIPng) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *RMCP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *RSVP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *RTP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *RUDP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *RadioTap) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPASCONF) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPAuth) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPCookieEcho) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPData) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPECN) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPEmptyLayer) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPError) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPForwardTSN) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPHeartbeat) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPInit) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPReConfig) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPSack) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPShutdown) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPShutdownAck) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SCTPUnknownChunkType) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SFlowDatagram) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SIP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SNAP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *SNMP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *STP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *Syslog) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *TCP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *TLS) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *UDP) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *UDPLite) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *USB) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *USBBulk) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *USBControl) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *USBInterrupt) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *USBRequestBlockSetup) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *VRRPv2) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *VRRPv3) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *VXLAN) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *VXLANGPE) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *WebSocket) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
+
+// MarshalJSON implements json.Marshaler, see gopacket.LayerJSON.
+func (l *WireGuard) MarshalJSON() ([]byte, error) {
+	return gopacket.LayerJSON(l)
+}
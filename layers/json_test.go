@@ -0,0 +1,90 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestPacketJSON(t *testing.T) {
+	p := gopacket.NewPacket(testSimpleTCPPacket, LinkTypeEthernet, gopacket.Default)
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal("can't marshal packet:", err)
+	}
+	var decoded struct {
+		Layers []map[string]interface{}
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("invalid JSON:", err)
+	}
+	if len(decoded.Layers) != 4 {
+		t.Fatalf("expected 4 layers, got %d", len(decoded.Layers))
+	}
+	for i, want := range []map[string]interface{}{
+		{"LayerType": "Ethernet", "SrcMAC": "bc:30:5b:e8:d3:49", "EthernetType": float64(EthernetTypeIPv4)},
+		{"LayerType": "IPv4", "SrcIP": "172.17.81.73", "Protocol": float64(IPProtocolTCP)},
+		{"LayerType": "TCP", "SrcPort": float64(50679), "DstPort": float64(80), "PSH": true},
+		{"LayerType": "Payload"},
+	} {
+		for field, value := range want {
+			if decoded.Layers[i][field] != value {
+				t.Errorf("layer %d: expected %s %v, got %v", i, field, value, decoded.Layers[i][field])
+			}
+		}
+	}
+
+	data, err = gopacket.MarshalPacketJSON(p, gopacket.JSONOptions{FiveTuple: true})
+	if err != nil {
+		t.Fatal("can't marshal packet:", err)
+	}
+	want := `{"Metadata":{"Timestamp":"0001-01-01T00:00:00Z","CaptureLength":0,"Length":0,"InterfaceIndex":0,"Truncated":false},"SrcIP":"172.17.81.73","DstIP":"173.222.254.225","Protocol":"TCP","SrcPort":50679,"DstPort":80}`
+	if string(data) != want {
+		t.Errorf("five-tuple JSON mismatch:\n   got: %s\n  want: %s", data, want)
+	}
+}
+
+func TestLayerJSON(t *testing.T) {
+	p := gopacket.NewPacket(testSimpleTCPPacket, LinkTypeEthernet, gopacket.Default)
+	for _, l := range p.Layers() {
+		if _, ok := l.(json.Marshaler); !ok {
+			continue
+		}
+		want, err := gopacket.LayerJSON(l)
+		if err != nil {
+			t.Fatal("can't marshal layer:", err)
+		}
+		got, err := json.Marshal(l)
+		if err != nil {
+			t.Fatal("can't marshal layer:", err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%v JSON mismatch:\n   got: %s\n  want: %s", l.LayerType(), got, want)
+		}
+	}
+
+	// layers in other values are marshaled the same way
+	data, err := json.Marshal(struct{ TCP *TCP }{p.Layer(LayerTypeTCP).(*TCP)})
+	if err != nil {
+		t.Fatal("can't marshal layer:", err)
+	}
+	var decoded struct {
+		TCP map[string]interface{}
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal("invalid JSON:", err)
+	}
+	if decoded.TCP["LayerType"] != "TCP" || decoded.TCP["DstPort"] != float64(80) {
+		t.Errorf("unexpected TCP JSON %s", data)
+	}
+	if _, ok := decoded.TCP["Contents"]; ok {
+		t.Errorf("TCP JSON contains layer contents: %s", data)
+	}
+}
@@ -0,0 +1,449 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/gopacket"
+)
+
+// L2TPMessageType is the type of an L2TP control message, the value of its
+// first AVP.
+type L2TPMessageType uint16
+
+// L2TP control message types, RFC 2661 section 3.2 and RFC 3931 section 6
+const (
+	L2TPMessageSCCRQ   L2TPMessageType = 1
+	L2TPMessageSCCRP   L2TPMessageType = 2
+	L2TPMessageSCCCN   L2TPMessageType = 3
+	L2TPMessageStopCCN L2TPMessageType = 4
+	L2TPMessageHello   L2TPMessageType = 6
+	L2TPMessageOCRQ    L2TPMessageType = 7
+	L2TPMessageOCRP    L2TPMessageType = 8
+	L2TPMessageOCCN    L2TPMessageType = 9
+	L2TPMessageICRQ    L2TPMessageType = 10
+	L2TPMessageICRP    L2TPMessageType = 11
+	L2TPMessageICCN    L2TPMessageType = 12
+	L2TPMessageCDN     L2TPMessageType = 14
+	L2TPMessageWEN     L2TPMessageType = 15
+	L2TPMessageSLI     L2TPMessageType = 16
+	L2TPMessageACK     L2TPMessageType = 20
+)
+
+var l2tpMessageTypeNames = map[L2TPMessageType]string{
+	L2TPMessageSCCRQ:   "SCCRQ",
+	L2TPMessageSCCRP:   "SCCRP",
+	L2TPMessageSCCCN:   "SCCCN",
+	L2TPMessageStopCCN: "StopCCN",
+	L2TPMessageHello:   "HELLO",
+	L2TPMessageOCRQ:    "OCRQ",
+	L2TPMessageOCRP:    "OCRP",
+	L2TPMessageOCCN:    "OCCN",
+	L2TPMessageICRQ:    "ICRQ",
+	L2TPMessageICRP:    "ICRP",
+	L2TPMessageICCN:    "ICCN",
+	L2TPMessageCDN:     "CDN",
+	L2TPMessageWEN:     "WEN",
+	L2TPMessageSLI:     "SLI",
+	L2TPMessageACK:     "ACK",
+}
+
+func (t L2TPMessageType) String() string {
+	if name, ok := l2tpMessageTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("L2TPMessageType(%d)", uint16(t))
+}
+
+// L2TPAVPType is the attribute type of an L2TP AVP.
+type L2TPAVPType uint16
+
+// Some L2TP AVP types, RFC 2661 section 4.4 and RFC 3931 section 5.4
+const (
+	L2TPAVPMessageType          L2TPAVPType = 0
+	L2TPAVPResultCode           L2TPAVPType = 1
+	L2TPAVPProtocolVersion      L2TPAVPType = 2
+	L2TPAVPFramingCapabilities  L2TPAVPType = 3
+	L2TPAVPBearerCapabilities   L2TPAVPType = 4
+	L2TPAVPHostName             L2TPAVPType = 7
+	L2TPAVPVendorName           L2TPAVPType = 8
+	L2TPAVPAssignedTunnelID     L2TPAVPType = 9
+	L2TPAVPReceiveWindowSize    L2TPAVPType = 10
+	L2TPAVPAssignedSessionID    L2TPAVPType = 14
+	L2TPAVPCallSerialNumber     L2TPAVPType = 15
+	L2TPAVPAssignedConnectionID L2TPAVPType = 61
+	L2TPAVPLocalSessionID       L2TPAVPType = 63
+	L2TPAVPRemoteSessionID      L2TPAVPType = 64
+	L2TPAVPAssignedCookie       L2TPAVPType = 65
+	L2TPAVPPseudowireType       L2TPAVPType = 68
+)
+
+// L2TPAVP is an attribute-value pair of an L2TP control message.  The value
+// of hidden AVPs is encrypted.
+type L2TPAVP struct {
+	Mandatory, Hidden bool
+	Length            uint16
+	VendorID          uint16
+	Type              L2TPAVPType
+	Value             []byte
+}
+
+// L2TPPseudowireType is the type of the frames carried by an L2TPv3
+// session, RFC 4446.
+type L2TPPseudowireType uint16
+
+// L2TPv3 pseudowire types
+const (
+	L2TPPseudowireFrameRelay   L2TPPseudowireType = 0x0001
+	L2TPPseudowireATMAAL5      L2TPPseudowireType = 0x0002
+	L2TPPseudowireEthernetVLAN L2TPPseudowireType = 0x0004
+	L2TPPseudowireEthernet     L2TPPseudowireType = 0x0005
+	L2TPPseudowireHDLC         L2TPPseudowireType = 0x0006
+	L2TPPseudowirePPP          L2TPPseudowireType = 0x0007
+)
+
+func (t L2TPPseudowireType) String() string {
+	switch t {
+	case L2TPPseudowireFrameRelay:
+		return "FrameRelay"
+	case L2TPPseudowireATMAAL5:
+		return "ATMAAL5"
+	case L2TPPseudowireEthernetVLAN:
+		return "EthernetVLAN"
+	case L2TPPseudowireEthernet:
+		return "Ethernet"
+	case L2TPPseudowireHDLC:
+		return "HDLC"
+	case L2TPPseudowirePPP:
+		return "PPP"
+	}
+	return fmt.Sprintf("L2TPPseudowireType(%d)", uint16(t))
+}
+
+// LayerType returns the layer type decoding the frames of the pseudowire
+// type, gopacket.LayerTypePayload for the ones without a layer.
+func (t L2TPPseudowireType) LayerType() gopacket.LayerType {
+	switch t {
+	case L2TPPseudowireEthernet, L2TPPseudowireEthernetVLAN:
+		return LayerTypeEthernet
+	case L2TPPseudowirePPP:
+		return LayerTypePPP
+	}
+	return gopacket.LayerTypePayload
+}
+
+// L2TPv3Session describes the data messages of L2TPv3 sessions, which is
+// negotiated by their control connections rather than found in the messages.
+type L2TPv3Session struct {
+	// CookieLength is the length of the cookie, 0, 4 or 8
+	CookieLength int
+	// L2SpecificSublayer is set if the messages have the 4 byte default
+	// L2-Specific Sublayer, RFC 3931 section 4.6
+	L2SpecificSublayer bool
+	Pseudowire         L2TPPseudowireType
+}
+
+// L2TPv3DefaultSession describes the sessions not registered with
+// RegisterL2TPv3Session: Ethernet pseudowires without cookie nor sublayer.
+var L2TPv3DefaultSession = L2TPv3Session{Pseudowire: L2TPPseudowireEthernet}
+
+var l2tpv3SessionsMu sync.Mutex
+
+var l2tpv3Sessions atomic.Value // map[uint32]L2TPv3Session
+
+// RegisterL2TPv3Session sets how the data messages of an L2TPv3 session
+// are decoded.  It may be called while packets are decoded by other
+// goroutines.
+func RegisterL2TPv3Session(sessionID uint32, s L2TPv3Session) {
+	l2tpv3SessionsMu.Lock()
+	defer l2tpv3SessionsMu.Unlock()
+	old, _ := l2tpv3Sessions.Load().(map[uint32]L2TPv3Session)
+	m := make(map[uint32]L2TPv3Session, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[sessionID] = s
+	l2tpv3Sessions.Store(m)
+}
+
+func l2tpv3Session(sessionID uint32) L2TPv3Session {
+	m, _ := l2tpv3Sessions.Load().(map[uint32]L2TPv3Session)
+	if s, ok := m[sessionID]; ok {
+		return s
+	}
+	return L2TPv3DefaultSession
+}
+
+// L2TP is a message of the Layer Two Tunneling Protocol, version 2 (RFC
+// 2661) or 3 (RFC 3931), carried over UDP or, for version 3, directly over
+// IP, decoded from LayerTypeL2TPIP.  The AVPs of control messages are
+// decoded.  The payload of L2TPv2 data messages is decoded as PPP, and the
+// one of L2TPv3 data messages as the pseudowire type of the session, see
+// RegisterL2TPv3Session.
+type L2TP struct {
+	BaseLayer
+	Version uint8
+	// Control is set for control messages
+	Control bool
+	// OverIP is set for L2TPv3 messages carried directly over IP
+	OverIP bool
+	// HasLength, HasSequence, HasOffset and Priority are the L, S, O and P
+	// flags of the header
+	HasLength, HasSequence, HasOffset, Priority bool
+	Length                                      uint16
+	// TunnelID is the tunnel ID of L2TPv2 messages
+	TunnelID uint16
+	// SessionID is the session ID of data messages, and of L2TPv2 control
+	// messages
+	SessionID uint32
+	// ControlConnectionID is the control connection ID of L2TPv3 control
+	// messages
+	ControlConnectionID uint32
+	Ns, Nr              uint16
+	OffsetSize          uint16
+	// Cookie and L2SpecificSublayer are the fields of L2TPv3 data messages
+	Cookie             []byte
+	L2SpecificSublayer []byte
+	Pseudowire         L2TPPseudowireType
+	// MessageType is the type of control messages with AVPs; control
+	// messages without, which acknowledge others, are ZLB messages
+	MessageType L2TPMessageType
+	AVPs        []L2TPAVP
+}
+
+// LayerType returns LayerTypeL2TP.
+func (l *L2TP) LayerType() gopacket.LayerType { return LayerTypeL2TP }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (l *L2TP) CanDecode() gopacket.LayerClass { return LayerTypeL2TP }
+
+// NextLayerType returns the layer type of the tunneled frames.
+func (l *L2TP) NextLayerType() gopacket.LayerType {
+	switch {
+	case l.Control:
+		return gopacket.LayerTypeZero
+	case l.Version == 2:
+		return LayerTypePPP
+	}
+	return l.Pseudowire.LayerType()
+}
+
+// IsZLB returns true for control messages without AVPs, which only
+// acknowledge others.
+func (l *L2TP) IsZLB() bool {
+	return l.Control && len(l.AVPs) == 0
+}
+
+var errL2TPTooShort = errors.New("L2TP message too short")
+
+// DecodeFromBytes decodes an L2TP message carried over UDP.
+func (l *L2TP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*l = L2TP{}
+	return l.decodeUDP(data, df)
+}
+
+func (l *L2TP) decodeUDP(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 2 {
+		df.SetTruncated()
+		return errL2TPTooShort
+	}
+	l.Control = data[0]&0x80 != 0
+	l.HasLength = data[0]&0x40 != 0
+	l.HasSequence = data[0]&0x08 != 0
+	l.HasOffset = data[0]&0x02 != 0
+	l.Priority = data[0]&0x01 != 0
+	l.Version = data[1] & 0x0f
+	switch l.Version {
+	case 2:
+		return l.decodeV2(data, df)
+	case 3:
+		if l.Control {
+			return l.decodeV3Control(data, df)
+		}
+		if len(data) < 8 {
+			df.SetTruncated()
+			return errL2TPTooShort
+		}
+		return l.decodeV3Data(data, 4, df)
+	}
+	return fmt.Errorf("unsupported L2TP version %d", l.Version)
+}
+
+// decodeIP decodes an L2TPv3 message carried over IP.
+func (l *L2TP) decodeIP(data []byte, df gopacket.DecodeFeedback) error {
+	*l = L2TP{Version: 3, OverIP: true}
+	if len(data) < 4 {
+		df.SetTruncated()
+		return errL2TPTooShort
+	}
+	if binary.BigEndian.Uint32(data) != 0 {
+		return l.decodeV3Data(data, 0, df)
+	}
+	// control messages start with a zero session ID
+	if err := l.decodeV3Control(data[4:], df); err != nil {
+		return err
+	}
+	l.Contents = data[:4+len(l.Contents)]
+	return nil
+}
+
+func (l *L2TP) decodeV2(data []byte, df gopacket.DecodeFeedback) error {
+	if l.Control && (!l.HasLength || !l.HasSequence) {
+		return errors.New("L2TP control message without length or sequence")
+	}
+	offset := 2
+	n := 6
+	if l.HasLength {
+		n += 2
+	}
+	if l.HasSequence {
+		n += 4
+	}
+	if l.HasOffset {
+		n += 2
+	}
+	if len(data) < n {
+		df.SetTruncated()
+		return errL2TPTooShort
+	}
+	if l.HasLength {
+		l.Length = binary.BigEndian.Uint16(data[offset:])
+		offset += 2
+	}
+	l.TunnelID = binary.BigEndian.Uint16(data[offset:])
+	l.SessionID = uint32(binary.BigEndian.Uint16(data[offset+2:]))
+	offset += 4
+	if l.HasSequence {
+		l.Ns = binary.BigEndian.Uint16(data[offset:])
+		l.Nr = binary.BigEndian.Uint16(data[offset+2:])
+		offset += 4
+	}
+	if l.HasOffset {
+		l.OffsetSize = binary.BigEndian.Uint16(data[offset:])
+		offset += 2 + int(l.OffsetSize)
+		if len(data) < offset {
+			df.SetTruncated()
+			return errL2TPTooShort
+		}
+	}
+	return l.decodeBody(data, offset, df)
+}
+
+// decodeV3Control decodes the header of an L2TPv3 control message, the
+// same over UDP and, after the zero session ID, over IP.
+func (l *L2TP) decodeV3Control(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 12 {
+		df.SetTruncated()
+		return errL2TPTooShort
+	}
+	l.Control = data[0]&0x80 != 0
+	l.HasLength = data[0]&0x40 != 0
+	l.HasSequence = data[0]&0x08 != 0
+	if !l.Control || !l.HasLength || !l.HasSequence || data[1]&0x0f != 3 {
+		return fmt.Errorf("invalid L2TPv3 control message header %#04x", binary.BigEndian.Uint16(data))
+	}
+	l.Length = binary.BigEndian.Uint16(data[2:])
+	l.ControlConnectionID = binary.BigEndian.Uint32(data[4:])
+	l.Ns = binary.BigEndian.Uint16(data[8:])
+	l.Nr = binary.BigEndian.Uint16(data[10:])
+	return l.decodeBody(data, 12, df)
+}
+
+// decodeV3Data decodes an L2TPv3 data message, whose session ID starts at
+// offset.
+func (l *L2TP) decodeV3Data(data []byte, offset int, df gopacket.DecodeFeedback) error {
+	l.SessionID = binary.BigEndian.Uint32(data[offset:])
+	offset += 4
+	s := l2tpv3Session(l.SessionID)
+	l.Pseudowire = s.Pseudowire
+	n := s.CookieLength
+	if s.L2SpecificSublayer {
+		n += 4
+	}
+	if len(data) < offset+n {
+		df.SetTruncated()
+		return errL2TPTooShort
+	}
+	l.Cookie = data[offset : offset+s.CookieLength]
+	offset += s.CookieLength
+	if s.L2SpecificSublayer {
+		l.L2SpecificSublayer = data[offset : offset+4]
+		offset += 4
+	}
+	l.Contents = data[:offset]
+	l.Payload = data[offset:]
+	return nil
+}
+
+// decodeBody decodes the AVPs of control messages, or sets the payload of
+// data messages, starting at offset.  The length of the message, if any,
+// excludes the padding of the frame.
+func (l *L2TP) decodeBody(data []byte, offset int, df gopacket.DecodeFeedback) error {
+	if l.HasLength {
+		if int(l.Length) < offset {
+			return fmt.Errorf("L2TP length %d too short", l.Length)
+		}
+		if len(data) < int(l.Length) {
+			df.SetTruncated()
+			return errL2TPTooShort
+		}
+		data = data[:l.Length]
+	}
+	l.Contents = data[:offset]
+	l.Payload = data[offset:]
+	if !l.Control {
+		return nil
+	}
+	l.Contents, l.Payload = data, nil
+	for avps := data[offset:]; len(avps) > 0; {
+		if len(avps) < 6 {
+			return errors.New("L2TP AVP too short")
+		}
+		avp := L2TPAVP{
+			Mandatory: avps[0]&0x80 != 0,
+			Hidden:    avps[0]&0x40 != 0,
+			Length:    binary.BigEndian.Uint16(avps) & 0x03ff,
+			VendorID:  binary.BigEndian.Uint16(avps[2:]),
+			Type:      L2TPAVPType(binary.BigEndian.Uint16(avps[4:])),
+		}
+		if avp.Length < 6 || int(avp.Length) > len(avps) {
+			return fmt.Errorf("invalid L2TP AVP length %d", avp.Length)
+		}
+		avp.Value = avps[6:avp.Length]
+		avps = avps[avp.Length:]
+		l.AVPs = append(l.AVPs, avp)
+	}
+	if len(l.AVPs) > 0 {
+		avp := l.AVPs[0]
+		if avp.VendorID != 0 || avp.Type != L2TPAVPMessageType || avp.Hidden || len(avp.Value) != 2 {
+			return errors.New("L2TP control message without Message Type AVP")
+		}
+		l.MessageType = L2TPMessageType(binary.BigEndian.Uint16(avp.Value))
+	}
+	return nil
+}
+
+func decodeL2TP(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&L2TP{}, data, p)
+}
+
+func decodeL2TPIP(data []byte, p gopacket.PacketBuilder) error {
+	l := &L2TP{}
+	if err := l.decodeIP(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(l)
+	if l.Control {
+		return nil
+	}
+	return p.NextDecoder(l.NextLayerType())
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// l2tpTestAVP returns a mandatory AVP.
+func l2tpTestAVP(typ L2TPAVPType, value []byte) []byte {
+	a := make([]byte, 6, 6+len(value))
+	binary.BigEndian.PutUint16(a, 0x8000|uint16(6+len(value)))
+	binary.BigEndian.PutUint16(a[4:], uint16(typ))
+	return append(a, value...)
+}
+
+func TestL2TPv2(t *testing.T) {
+	// an SCCRQ
+	avps := append(l2tpTestAVP(L2TPAVPMessageType, []byte{0, 1}), l2tpTestAVP(L2TPAVPHostName, []byte("lac"))...)
+	control := append([]byte{0xc8, 0x02, 0, byte(12 + len(avps)), 0, 0, 0, 0, 0, 0, 0, 0}, avps...)
+	data, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 1701, DstPort: 1701}).Payload(control).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeL2TP}, t)
+	l := p.Layer(LayerTypeL2TP).(*L2TP)
+	if l.Version != 2 || !l.Control || l.IsZLB() || l.MessageType != L2TPMessageSCCRQ || l.MessageType.String() != "SCCRQ" || len(l.AVPs) != 2 {
+		t.Fatalf("unexpected SCCRQ %#v", l)
+	}
+	if a := l.AVPs[1]; !a.Mandatory || a.Hidden || a.Type != L2TPAVPHostName || string(a.Value) != "lac" {
+		t.Errorf("unexpected AVP %#v", a)
+	}
+
+	// a data message with a length and an offset, carrying IPv4 in PPP
+	inner, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 53, DstPort: 53}).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ppp := append([]byte{0xff, 0x03, 0x00, 0x21}, inner...)
+	msg := append([]byte{0x42, 0x02, 0, byte(12 + len(ppp)), 0x12, 0x34, 0x56, 0x78, 0, 2, 0xaa, 0xbb}, ppp...)
+	p = gopacket.NewPacket(msg, LayerTypeL2TP, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeL2TP, LayerTypePPP, LayerTypeIPv4, LayerTypeUDP}, t)
+	l = p.Layer(LayerTypeL2TP).(*L2TP)
+	if l.Control || l.TunnelID != 0x1234 || l.SessionID != 0x5678 || l.OffsetSize != 2 || len(l.Contents) != 12 {
+		t.Errorf("unexpected data message %#v", l)
+	}
+
+	for _, data := range [][]byte{
+		{0xc8, 0x02},
+		{0x80, 0x02, 0, 0, 0, 0, 0, 0},
+		{0x00, 0x01, 0, 0, 0, 0},
+		append([]byte{0xc8, 0x02, 0, 20, 0, 0, 0, 0, 0, 0, 0, 0}, l2tpTestAVP(L2TPAVPHostName, []byte("la"))...),
+		append([]byte{0xc8, 0x02, 0, 20, 0, 0, 0, 0, 0, 0, 0, 0}, l2tpTestAVP(L2TPAVPMessageType, []byte{0, 1})[:7]...),
+		{0x42, 0x02, 0, 20, 0, 0, 0, 0, 0, 0},
+	} {
+		if err := (&L2TP{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
+
+func TestL2TPv3(t *testing.T) {
+	RegisterL2TPv3Session(0x0a0b0c0d, L2TPv3Session{CookieLength: 4, L2SpecificSublayer: true, Pseudowire: L2TPPseudowireEthernet})
+	frame, err := Build().Ethernet(nil).IPv4(nil).UDP(&UDP{SrcPort: 53, DstPort: 53}).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := append([]byte{0x0a, 0x0b, 0x0c, 0x0d, 1, 2, 3, 4, 0x40, 0, 0, 1}, frame...)
+	data, err := Build().IPv4(&IPv4{Protocol: IPProtocolL2TP}).Payload(msg).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeL2TP, LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP}, t)
+	l := p.Layer(LayerTypeL2TP).(*L2TP)
+	if l.Version != 3 || !l.OverIP || l.SessionID != 0x0a0b0c0d || !bytes.Equal(l.Cookie, []byte{1, 2, 3, 4}) || len(l.L2SpecificSublayer) != 4 || l.Pseudowire != L2TPPseudowireEthernet {
+		t.Errorf("unexpected data message %#v", l)
+	}
+
+	// a ZLB acknowledgment over IP
+	l = &L2TP{}
+	if err := l.decodeIP([]byte{0, 0, 0, 0, 0xc8, 0x03, 0, 12, 0, 0, 0, 9, 0, 1, 0, 2}, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if !l.IsZLB() || l.ControlConnectionID != 9 || l.Ns != 1 || l.Nr != 2 || len(l.Contents) != 16 {
+		t.Errorf("unexpected ZLB %#v", l)
+	}
+
+	// a data message over UDP of an unregistered session
+	p = gopacket.NewPacket(append([]byte{0, 3, 0, 0, 0, 0, 0, 7}, frame...), LayerTypeL2TP, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeL2TP, LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP}, t)
+
+	if err := (&L2TP{}).decodeIP([]byte{0, 0, 0, 0, 0x48, 0x03, 0, 12, 0, 0, 0, 9, 0, 1, 0, 2}, gopacket.NilDecodeFeedback); err == nil {
+		t.Error("no error for control message without T flag")
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// SlowProtocolSubtype is the subtype of IEEE 802.3 Slow Protocols frames,
+// the first byte following the Ethernet header.
+type SlowProtocolSubtype uint8
+
+// SlowProtocolSubtype known values, see IEEE 802.3, annex 57A
+const (
+	SlowProtocolSubtypeLACP   SlowProtocolSubtype = 1
+	SlowProtocolSubtypeMarker SlowProtocolSubtype = 2
+	SlowProtocolSubtypeOAM    SlowProtocolSubtype = 3
+	SlowProtocolSubtypeOSSP   SlowProtocolSubtype = 10
+)
+
+func decodeSlowProtocols(data []byte, p gopacket.PacketBuilder) error {
+	if len(data) > 0 && SlowProtocolSubtype(data[0]) == SlowProtocolSubtypeLACP {
+		return decodeLACP(data, p)
+	}
+	return p.NextDecoder(gopacket.LayerTypePayload)
+}
+
+// LACPState holds the state bits of an LACP actor or partner port.
+type LACPState uint8
+
+// LACPState bits.
+const (
+	LACPStateActivity        LACPState = 0x01 // Active LACP, passive if unset
+	LACPStateTimeout         LACPState = 0x02 // Short timeout, long if unset
+	LACPStateAggregation     LACPState = 0x04 // Aggregatable, individual if unset
+	LACPStateSynchronization LACPState = 0x08
+	LACPStateCollecting      LACPState = 0x10
+	LACPStateDistributing    LACPState = 0x20
+	LACPStateDefaulted       LACPState = 0x40
+	LACPStateExpired         LACPState = 0x80
+)
+
+func (s LACPState) String() string {
+	var out bytes.Buffer
+	for _, f := range []struct {
+		bit  LACPState
+		name string
+	}{
+		{LACPStateActivity, "Activity"},
+		{LACPStateTimeout, "Timeout"},
+		{LACPStateAggregation, "Aggregation"},
+		{LACPStateSynchronization, "Synchronization"},
+		{LACPStateCollecting, "Collecting"},
+		{LACPStateDistributing, "Distributing"},
+		{LACPStateDefaulted, "Defaulted"},
+		{LACPStateExpired, "Expired"},
+	} {
+		if s&f.bit != 0 {
+			out.WriteString(f.name)
+			out.WriteByte(',')
+		}
+	}
+	if length := out.Len(); length > 0 {
+		return string(out.Bytes()[:length-1]) // strip final comma
+	}
+	return ""
+}
+
+// LACPPortInfo is the information about the actor or the partner port of an
+// LACPDU.
+type LACPPortInfo struct {
+	SystemPriority uint16
+	System         net.HardwareAddr
+	Key            uint16
+	PortPriority   uint16
+	Port           uint16
+	State          LACPState
+}
+
+func decodeLACPPortInfo(data []byte) LACPPortInfo {
+	return LACPPortInfo{
+		SystemPriority: binary.BigEndian.Uint16(data[0:2]),
+		System:         net.HardwareAddr(data[2:8]),
+		Key:            binary.BigEndian.Uint16(data[8:10]),
+		PortPriority:   binary.BigEndian.Uint16(data[10:12]),
+		Port:           binary.BigEndian.Uint16(data[12:14]),
+		State:          LACPState(data[14]),
+	}
+}
+
+func (i *LACPPortInfo) encode(data []byte) error {
+	if i.System != nil && len(i.System) != 6 {
+		return fmt.Errorf("invalid LACP system %v", i.System)
+	}
+	binary.BigEndian.PutUint16(data[0:2], i.SystemPriority)
+	copy(data[2:8], i.System)
+	binary.BigEndian.PutUint16(data[8:10], i.Key)
+	binary.BigEndian.PutUint16(data[10:12], i.PortPriority)
+	binary.BigEndian.PutUint16(data[12:14], i.Port)
+	data[14] = byte(i.State)
+	return nil
+}
+
+// LACP TLV types and lengths
+const (
+	lacpTLVActor     = 1
+	lacpTLVPartner   = 2
+	lacpTLVCollector = 3
+
+	lacpPortInfoLength  = 20
+	lacpCollectorLength = 16
+	lacpLength          = 110
+)
+
+// LACP contains a Link Aggregation Control Protocol PDU, sent in Slow
+// Protocols frames, see IEEE 802.1AX-2014, section 6.4.2.
+//
+//	Subtype (1) | Version (1)
+//	Actor Information TLV (20)
+//	Partner Information TLV (20)
+//	Collector Information TLV (16)
+//	Terminator TLV (2) | Reserved (50)
+type LACP struct {
+	BaseLayer
+	Version           uint8
+	Actor             LACPPortInfo
+	Partner           LACPPortInfo
+	CollectorMaxDelay uint16 // Tens of microseconds
+}
+
+// LayerType returns LayerTypeLACP.
+func (l *LACP) LayerType() gopacket.LayerType { return LayerTypeLACP }
+
+func decodeLACP(data []byte, p gopacket.PacketBuilder) error {
+	l := &LACP{}
+	return decodingLayerDecoder(l, data, p)
+}
+
+// DecodeFromBytes decodes the slice into the LACP struct.
+func (l *LACP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	// The reserved bytes following the terminator TLV are often omitted
+	if len(data) < 2+2*lacpPortInfoLength+lacpCollectorLength+2 {
+		df.SetTruncated()
+		return errors.New("LACPDU too short")
+	}
+	if SlowProtocolSubtype(data[0]) != SlowProtocolSubtypeLACP {
+		return fmt.Errorf("invalid LACP subtype %d", data[0])
+	}
+	for _, tlv := range []struct {
+		offset, tlvType, length int
+	}{
+		{2, lacpTLVActor, lacpPortInfoLength},
+		{2 + lacpPortInfoLength, lacpTLVPartner, lacpPortInfoLength},
+		{2 + 2*lacpPortInfoLength, lacpTLVCollector, lacpCollectorLength},
+	} {
+		if int(data[tlv.offset]) != tlv.tlvType || int(data[tlv.offset+1]) != tlv.length {
+			return fmt.Errorf("invalid LACP TLV type %d length %d", data[tlv.offset], data[tlv.offset+1])
+		}
+	}
+	l.Version = data[1]
+	l.Actor = decodeLACPPortInfo(data[4:])
+	l.Partner = decodeLACPPortInfo(data[4+lacpPortInfoLength:])
+	l.CollectorMaxDelay = binary.BigEndian.Uint16(data[4+2*lacpPortInfoLength:])
+	end := len(data)
+	if end > lacpLength {
+		end = lacpLength
+	}
+	l.BaseLayer = BaseLayer{Contents: data[:end]}
+	return nil
+}
+
+// CanDecode implements gopacket.DecodingLayer.
+func (l *LACP) CanDecode() gopacket.LayerClass {
+	return LayerTypeLACP
+}
+
+// NextLayerType implements gopacket.DecodingLayer.
+func (l *LACP) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypeZero
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+func (l *LACP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	data, err := b.PrependBytes(lacpLength)
+	if err != nil {
+		return err
+	}
+	for i := range data {
+		data[i] = 0
+	}
+	data[0] = byte(SlowProtocolSubtypeLACP)
+	data[1] = l.Version
+	actor, partner, collector := data[2:], data[2+lacpPortInfoLength:], data[2+2*lacpPortInfoLength:]
+	actor[0], actor[1] = lacpTLVActor, lacpPortInfoLength
+	if err := l.Actor.encode(actor[2:]); err != nil {
+		return err
+	}
+	partner[0], partner[1] = lacpTLVPartner, lacpPortInfoLength
+	if err := l.Partner.encode(partner[2:]); err != nil {
+		return err
+	}
+	collector[0], collector[1] = lacpTLVCollector, lacpCollectorLength
+	binary.BigEndian.PutUint16(collector[2:4], l.CollectorMaxDelay)
+	// The terminator TLV and reserved bytes are zeros
+	return nil
+}
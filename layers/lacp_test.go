@@ -0,0 +1,92 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketLACP is an LACPDU of an active, short timeout actor in sync
+// with its partner.
+var testPacketLACP = []byte{
+	0x01, 0x80, 0xc2, 0x00, 0x00, 0x02, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x88, 0x09,
+	0x01, 0x01,
+	0x01, 0x14, 0x80, 0x00, 0x00, 0x11, 0x22, 0x33, 0x44, 0x00, 0x00, 0x0d, 0x80, 0x00, 0x00, 0x05, 0x3f, 0x00, 0x00, 0x00,
+	0x02, 0x14, 0xff, 0xff, 0x00, 0xaa, 0xbb, 0xcc, 0xdd, 0x00, 0x00, 0x01, 0x00, 0xff, 0x00, 0x17, 0x3d, 0x00, 0x00, 0x00,
+	0x03, 0x10, 0x00, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestLACP(t *testing.T) {
+	p := gopacket.NewPacket(testPacketLACP, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeLACP}, t)
+
+	lacp := p.Layer(LayerTypeLACP).(*LACP)
+	want := &LACP{
+		BaseLayer: BaseLayer{Contents: testPacketLACP[14:]},
+		Version:   1,
+		Actor: LACPPortInfo{
+			SystemPriority: 0x8000,
+			System:         net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x00},
+			Key:            13,
+			PortPriority:   0x8000,
+			Port:           5,
+			State:          LACPStateActivity | LACPStateTimeout | LACPStateAggregation | LACPStateSynchronization | LACPStateCollecting | LACPStateDistributing,
+		},
+		Partner: LACPPortInfo{
+			SystemPriority: 0xffff,
+			System:         net.HardwareAddr{0x00, 0xaa, 0xbb, 0xcc, 0xdd, 0x00},
+			Key:            1,
+			PortPriority:   0xff,
+			Port:           0x17,
+			State:          LACPStateActivity | LACPStateAggregation | LACPStateSynchronization | LACPStateCollecting | LACPStateDistributing,
+		},
+		CollectorMaxDelay: 5,
+	}
+	if !reflect.DeepEqual(lacp, want) {
+		t.Errorf("LACP mismatch:\ngot  %#v\nwant %#v", lacp, want)
+	}
+	if got := lacp.Partner.State.String(); got != "Activity,Aggregation,Synchronization,Collecting,Distributing" {
+		t.Errorf("partner state %q", got)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	eth := p.Layer(LayerTypeEthernet).(*Ethernet)
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{}, eth, lacp); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testPacketLACP) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), testPacketLACP)
+	}
+
+	// The reserved bytes may be omitted
+	p = gopacket.NewPacket(testPacketLACP[:14+60], LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode short LACPDU:", p.ErrorLayer().Error())
+	}
+	if lacp := p.Layer(LayerTypeLACP).(*LACP); lacp.CollectorMaxDelay != 5 || lacp.Partner.Port != 0x17 {
+		t.Errorf("short LACPDU mismatch: %#v", lacp)
+	}
+}
+
+func TestSlowProtocolsNotLACP(t *testing.T) {
+	marker := append(append([]byte(nil), testPacketLACP...), 0)
+	marker[14] = byte(SlowProtocolSubtypeMarker)
+	p := gopacket.NewPacket(marker, LinkTypeEthernet, testDecodeOptions)
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, gopacket.LayerTypePayload}, t)
+}
@@ -148,6 +148,50 @@ var (
 	LayerTypeASFPresencePong              = gopacket.RegisterLayerType(144, gopacket.LayerTypeMetadata{Name: "ASFPresencePong", Decoder: gopacket.DecodeFunc(decodeASFPresencePong)})
 	LayerTypeERSPANII                     = gopacket.RegisterLayerType(145, gopacket.LayerTypeMetadata{Name: "ERSPAN Type II", Decoder: gopacket.DecodeFunc(decodeERSPANII)})
 	LayerTypeRADIUS                       = gopacket.RegisterLayerType(146, gopacket.LayerTypeMetadata{Name: "RADIUS", Decoder: gopacket.DecodeFunc(decodeRADIUS)})
+	LayerTypeQUIC                         = gopacket.RegisterLayerType(147, gopacket.LayerTypeMetadata{Name: "QUIC", Decoder: gopacket.DecodeFunc(decodeQUIC)})
+	LayerTypeQUICFrame                    = gopacket.RegisterLayerType(148, gopacket.LayerTypeMetadata{Name: "QUICFrame", Decoder: gopacket.DecodeFunc(decodeQUICFrame)})
+	LayerTypeHTTP                         = gopacket.RegisterLayerType(149, gopacket.LayerTypeMetadata{Name: "HTTP", Decoder: gopacket.DecodeFunc(decodeHTTP)})
+	LayerTypeHTTP2                        = gopacket.RegisterLayerType(150, gopacket.LayerTypeMetadata{Name: "HTTP2", Decoder: gopacket.DecodeFunc(decodeHTTP2)})
+	LayerTypeWebSocket                    = gopacket.RegisterLayerType(151, gopacket.LayerTypeMetadata{Name: "WebSocket", Decoder: gopacket.DecodeFunc(decodeWebSocket)})
+	LayerTypeDTLS                         = gopacket.RegisterLayerType(152, gopacket.LayerTypeMetadata{Name: "DTLS", Decoder: gopacket.DecodeFunc(decodeDTLS)})
+	LayerTypeIPSecUDPEncap                = gopacket.RegisterLayerType(153, gopacket.LayerTypeMetadata{Name: "IPSecUDPEncap", Decoder: gopacket.DecodeFunc(decodeIPSecUDPEncap)})
+	LayerTypeIKEv2                        = gopacket.RegisterLayerType(154, gopacket.LayerTypeMetadata{Name: "IKEv2", Decoder: gopacket.DecodeFunc(decodeIKEv2)})
+	LayerTypeWireGuard                    = gopacket.RegisterLayerType(155, gopacket.LayerTypeMetadata{Name: "WireGuard", Decoder: gopacket.DecodeFunc(decodeWireGuard)})
+	LayerTypeOpenVPN                      = gopacket.RegisterLayerType(156, gopacket.LayerTypeMetadata{Name: "OpenVPN", Decoder: gopacket.DecodeFunc(decodeOpenVPN)})
+	LayerTypeOpenVPNTCP                   = gopacket.RegisterLayerType(157, gopacket.LayerTypeMetadata{Name: "OpenVPNTCP", Decoder: gopacket.DecodeFunc(decodeOpenVPNTCP)})
+	LayerTypeL2TP                         = gopacket.RegisterLayerType(158, gopacket.LayerTypeMetadata{Name: "L2TP", Decoder: gopacket.DecodeFunc(decodeL2TP)})
+	LayerTypeL2TPIP                       = gopacket.RegisterLayerType(159, gopacket.LayerTypeMetadata{Name: "L2TPIP", Decoder: gopacket.DecodeFunc(decodeL2TPIP)})
+	LayerTypeMACsec                       = gopacket.RegisterLayerType(160, gopacket.LayerTypeMetadata{Name: "MACsec", Decoder: gopacket.DecodeFunc(decodeMACsec)})
+	LayerTypeVXLANGPE                     = gopacket.RegisterLayerType(161, gopacket.LayerTypeMetadata{Name: "VXLANGPE", Decoder: gopacket.DecodeFunc(decodeVXLANGPE)})
+	LayerTypeNSH                          = gopacket.RegisterLayerType(162, gopacket.LayerTypeMetadata{Name: "NSH", Decoder: gopacket.DecodeFunc(decodeNSH)})
+	LayerTypeERSPANIII                    = gopacket.RegisterLayerType(163, gopacket.LayerTypeMetadata{Name: "ERSPAN Type III", Decoder: gopacket.DecodeFunc(decodeERSPANIII)})
+	LayerTypeGTPv2C                       = gopacket.RegisterLayerType(164, gopacket.LayerTypeMetadata{Name: "GTPv2C", Decoder: gopacket.DecodeFunc(decodeGTPv2C)})
+	LayerTypeSCTPECNE                     = gopacket.RegisterLayerType(165, gopacket.LayerTypeMetadata{Name: "SCTPECNE", Decoder: nil})
+	LayerTypeSCTPCWR                      = gopacket.RegisterLayerType(166, gopacket.LayerTypeMetadata{Name: "SCTPCWR", Decoder: nil})
+	LayerTypeSCTPAuth                     = gopacket.RegisterLayerType(167, gopacket.LayerTypeMetadata{Name: "SCTPAuth", Decoder: nil})
+	LayerTypeSCTPASCONF                   = gopacket.RegisterLayerType(168, gopacket.LayerTypeMetadata{Name: "SCTPASCONF", Decoder: nil})
+	LayerTypeSCTPASCONFAck                = gopacket.RegisterLayerType(169, gopacket.LayerTypeMetadata{Name: "SCTPASCONFAck", Decoder: nil})
+	LayerTypeSCTPReConfig                 = gopacket.RegisterLayerType(170, gopacket.LayerTypeMetadata{Name: "SCTPReConfig", Decoder: nil})
+	LayerTypeSCTPForwardTSN               = gopacket.RegisterLayerType(171, gopacket.LayerTypeMetadata{Name: "SCTPForwardTSN", Decoder: nil})
+	LayerTypeDiameter                     = gopacket.RegisterLayerType(172, gopacket.LayerTypeMetadata{Name: "Diameter", Decoder: gopacket.DecodeFunc(decodeDiameter)})
+	LayerTypeLLMNR                        = gopacket.RegisterLayerType(173, gopacket.LayerTypeMetadata{Name: "LLMNR", Decoder: gopacket.DecodeFunc(decodeLLMNR)})
+	LayerTypeNBNS                         = gopacket.RegisterLayerType(174, gopacket.LayerTypeMetadata{Name: "NBNS", Decoder: gopacket.DecodeFunc(decodeNBNS)})
+	LayerTypeNBSS                         = gopacket.RegisterLayerType(175, gopacket.LayerTypeMetadata{Name: "NBSS", Decoder: gopacket.DecodeFunc(decodeNBSS)})
+	LayerTypePTP                          = gopacket.RegisterLayerType(176, gopacket.LayerTypeMetadata{Name: "PTP", Decoder: gopacket.DecodeFunc(decodePTP)})
+	LayerTypeLACP                         = gopacket.RegisterLayerType(177, gopacket.LayerTypeMetadata{Name: "LACP", Decoder: gopacket.DecodeFunc(decodeLACP)})
+	LayerTypeHSRP                         = gopacket.RegisterLayerType(178, gopacket.LayerTypeMetadata{Name: "HSRP", Decoder: gopacket.DecodeFunc(decodeHSRP)})
+	LayerTypePIM                          = gopacket.RegisterLayerType(179, gopacket.LayerTypeMetadata{Name: "PIM", Decoder: gopacket.DecodeFunc(decodePIM)})
+	LayerTypeBGP                          = gopacket.RegisterLayerType(180, gopacket.LayerTypeMetadata{Name: "BGP", Decoder: gopacket.DecodeFunc(decodeBGP)})
+	LayerTypeISIS                         = gopacket.RegisterLayerType(181, gopacket.LayerTypeMetadata{Name: "ISIS", Decoder: gopacket.DecodeFunc(decodeISIS)})
+	LayerTypeRIP                          = gopacket.RegisterLayerType(182, gopacket.LayerTypeMetadata{Name: "RIP", Decoder: gopacket.DecodeFunc(decodeRIP)})
+	LayerTypeRIPng                        = gopacket.RegisterLayerType(183, gopacket.LayerTypeMetadata{Name: "RIPng", Decoder: gopacket.DecodeFunc(decodeRIPng)})
+	LayerTypeLDP                          = gopacket.RegisterLayerType(184, gopacket.LayerTypeMetadata{Name: "LDP", Decoder: gopacket.DecodeFunc(decodeLDP)})
+	LayerTypeRSVP                         = gopacket.RegisterLayerType(185, gopacket.LayerTypeMetadata{Name: "RSVP", Decoder: gopacket.DecodeFunc(decodeRSVP)})
+	LayerTypeNetFlow                      = gopacket.RegisterLayerType(186, gopacket.LayerTypeMetadata{Name: "NetFlow", Decoder: gopacket.DecodeFunc(decodeNetFlow)})
+	LayerTypeIPFIX                        = gopacket.RegisterLayerType(187, gopacket.LayerTypeMetadata{Name: "IPFIX", Decoder: gopacket.DecodeFunc(decodeIPFIX)})
+	LayerTypeSNMP                         = gopacket.RegisterLayerType(188, gopacket.LayerTypeMetadata{Name: "SNMP", Decoder: gopacket.DecodeFunc(decodeSNMP)})
+	LayerTypeSyslog                       = gopacket.RegisterLayerType(189, gopacket.LayerTypeMetadata{Name: "Syslog", Decoder: gopacket.DecodeFunc(decodeSyslog)})
+	LayerTypeRTP                          = gopacket.RegisterLayerType(190, gopacket.LayerTypeMetadata{Name: "RTP", Decoder: gopacket.DecodeFunc(decodeRTP)})
 )
 
 var (
@@ -185,6 +229,13 @@ var (
 		LayerTypeSCTPAbort,
 		LayerTypeSCTPShutdownComplete,
 		LayerTypeSCTPCookieAck,
+		LayerTypeSCTPECNE,
+		LayerTypeSCTPCWR,
+		LayerTypeSCTPAuth,
+		LayerTypeSCTPASCONF,
+		LayerTypeSCTPASCONFAck,
+		LayerTypeSCTPReConfig,
+		LayerTypeSCTPForwardTSN,
 	})
 	// LayerClassIPv6Extension contains IPv6 extension headers.
 	LayerClassIPv6Extension = gopacket.NewLayerClass([]gopacket.LayerType{
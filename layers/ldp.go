@@ -0,0 +1,484 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// LDPMessageType is the type of an LDP message.
+type LDPMessageType uint16
+
+// LDPMessageType known values, see RFC 5036, section 3.7
+const (
+	LDPMessageTypeNotification      LDPMessageType = 0x0001
+	LDPMessageTypeHello             LDPMessageType = 0x0100
+	LDPMessageTypeInitialization    LDPMessageType = 0x0200
+	LDPMessageTypeKeepAlive         LDPMessageType = 0x0201
+	LDPMessageTypeAddress           LDPMessageType = 0x0300
+	LDPMessageTypeAddressWithdraw   LDPMessageType = 0x0301
+	LDPMessageTypeLabelMapping      LDPMessageType = 0x0400
+	LDPMessageTypeLabelRequest      LDPMessageType = 0x0401
+	LDPMessageTypeLabelWithdraw     LDPMessageType = 0x0402
+	LDPMessageTypeLabelRelease      LDPMessageType = 0x0403
+	LDPMessageTypeLabelAbortRequest LDPMessageType = 0x0404
+)
+
+func (t LDPMessageType) String() string {
+	switch t {
+	case LDPMessageTypeNotification:
+		return "Notification"
+	case LDPMessageTypeHello:
+		return "Hello"
+	case LDPMessageTypeInitialization:
+		return "Initialization"
+	case LDPMessageTypeKeepAlive:
+		return "KeepAlive"
+	case LDPMessageTypeAddress:
+		return "Address"
+	case LDPMessageTypeAddressWithdraw:
+		return "Address Withdraw"
+	case LDPMessageTypeLabelMapping:
+		return "Label Mapping"
+	case LDPMessageTypeLabelRequest:
+		return "Label Request"
+	case LDPMessageTypeLabelWithdraw:
+		return "Label Withdraw"
+	case LDPMessageTypeLabelRelease:
+		return "Label Release"
+	case LDPMessageTypeLabelAbortRequest:
+		return "Label Abort Request"
+	default:
+		return fmt.Sprintf("Unknown(%#04x)", uint16(t))
+	}
+}
+
+// LDPTLVType is the type of an LDP TLV.
+type LDPTLVType uint16
+
+// LDPTLVType known values, see RFC 5036, section 3.4
+const (
+	LDPTLVFEC                     LDPTLVType = 0x0100
+	LDPTLVAddressList             LDPTLVType = 0x0101
+	LDPTLVHopCount                LDPTLVType = 0x0103
+	LDPTLVPathVector              LDPTLVType = 0x0104
+	LDPTLVGenericLabel            LDPTLVType = 0x0200
+	LDPTLVATMLabel                LDPTLVType = 0x0201
+	LDPTLVFrameRelayLabel         LDPTLVType = 0x0202
+	LDPTLVStatus                  LDPTLVType = 0x0300
+	LDPTLVExtendedStatus          LDPTLVType = 0x0301
+	LDPTLVReturnedPDU             LDPTLVType = 0x0302
+	LDPTLVReturnedMessage         LDPTLVType = 0x0303
+	LDPTLVCommonHelloParameters   LDPTLVType = 0x0400
+	LDPTLVIPv4TransportAddress    LDPTLVType = 0x0401
+	LDPTLVConfigurationSequence   LDPTLVType = 0x0402
+	LDPTLVIPv6TransportAddress    LDPTLVType = 0x0403
+	LDPTLVCommonSessionParameters LDPTLVType = 0x0500
+	LDPTLVATMSessionParameters    LDPTLVType = 0x0501
+	LDPTLVFrameRelaySessionParams LDPTLVType = 0x0502
+	LDPTLVLabelRequestMessageID   LDPTLVType = 0x0600
+)
+
+func (t LDPTLVType) String() string {
+	switch t {
+	case LDPTLVFEC:
+		return "FEC"
+	case LDPTLVAddressList:
+		return "Address List"
+	case LDPTLVHopCount:
+		return "Hop Count"
+	case LDPTLVPathVector:
+		return "Path Vector"
+	case LDPTLVGenericLabel:
+		return "Generic Label"
+	case LDPTLVATMLabel:
+		return "ATM Label"
+	case LDPTLVFrameRelayLabel:
+		return "Frame Relay Label"
+	case LDPTLVStatus:
+		return "Status"
+	case LDPTLVExtendedStatus:
+		return "Extended Status"
+	case LDPTLVReturnedPDU:
+		return "Returned PDU"
+	case LDPTLVReturnedMessage:
+		return "Returned Message"
+	case LDPTLVCommonHelloParameters:
+		return "Common Hello Parameters"
+	case LDPTLVIPv4TransportAddress:
+		return "IPv4 Transport Address"
+	case LDPTLVConfigurationSequence:
+		return "Configuration Sequence Number"
+	case LDPTLVIPv6TransportAddress:
+		return "IPv6 Transport Address"
+	case LDPTLVCommonSessionParameters:
+		return "Common Session Parameters"
+	case LDPTLVATMSessionParameters:
+		return "ATM Session Parameters"
+	case LDPTLVFrameRelaySessionParams:
+		return "Frame Relay Session Parameters"
+	case LDPTLVLabelRequestMessageID:
+		return "Label Request Message ID"
+	default:
+		return fmt.Sprintf("Unknown(%#04x)", uint16(t))
+	}
+}
+
+// LDPFECType is the type of an LDP FEC element.
+type LDPFECType uint8
+
+// LDPFECType known values, see RFC 5036, section 3.4.1 and RFC 8077
+const (
+	LDPFECTypeWildcard        LDPFECType = 0x01
+	LDPFECTypePrefix          LDPFECType = 0x02
+	LDPFECTypeHostAddress     LDPFECType = 0x03
+	LDPFECTypePWID            LDPFECType = 0x80
+	LDPFECTypeGeneralizedPWID LDPFECType = 0x81
+)
+
+func (t LDPFECType) String() string {
+	switch t {
+	case LDPFECTypeWildcard:
+		return "Wildcard"
+	case LDPFECTypePrefix:
+		return "Prefix"
+	case LDPFECTypeHostAddress:
+		return "Host Address"
+	case LDPFECTypePWID:
+		return "PWid"
+	case LDPFECTypeGeneralizedPWID:
+		return "Generalized PWid"
+	default:
+		return fmt.Sprintf("Unknown(%#02x)", uint8(t))
+	}
+}
+
+// LDP lengths
+const (
+	ldpPDUHeaderLength     = 10
+	ldpMessageHeaderLength = 8
+	ldpTLVHeaderLength     = 4
+)
+
+// LDPTLV is a TLV of an LDP message.  Unknown and Forward are the U and F
+// bits: a receiver not knowing the TLV ignores it if U is set, forwarding
+// it along with the message if F is set too, and reports it otherwise.
+type LDPTLV struct {
+	Unknown bool
+	Forward bool
+	Type    LDPTLVType
+	Value   []byte
+}
+
+// LDPHelloParameters is the common hello parameters TLV of hello messages.
+type LDPHelloParameters struct {
+	HoldTime        uint16 // Seconds
+	Targeted        bool
+	RequestTargeted bool
+}
+
+// LDPSessionParameters is the common session parameters TLV of
+// initialization messages.
+type LDPSessionParameters struct {
+	ProtocolVersion uint16
+	KeepAliveTime   uint16 // Seconds
+	// DownstreamOnDemand is the A bit, telling the label advertisement
+	// discipline, and LoopDetection the D bit.
+	DownstreamOnDemand bool
+	LoopDetection      bool
+	PathVectorLimit    uint8
+	MaxPDULength       uint16
+	// ReceiverLSRID and ReceiverLabelSpace are the LDP identifier of the
+	// receiver.
+	ReceiverLSRID      net.IP
+	ReceiverLabelSpace uint16
+}
+
+// LDPStatus is the status TLV of notification messages.
+type LDPStatus struct {
+	// Code is the status code, including the fatal error and forward
+	// bits.
+	Code        uint32
+	MessageID   uint32
+	MessageType LDPMessageType
+}
+
+// Fatal returns true if the status is a fatal error, closing the session.
+func (s *LDPStatus) Fatal() bool { return s.Code&0x80000000 != 0 }
+
+// StatusData returns the status code without the fatal error and forward
+// bits.
+func (s *LDPStatus) StatusData() uint32 { return s.Code & 0x3fffffff }
+
+// LDPFECElement is an element of an FEC TLV.  Prefix holds the prefix of
+// prefix elements, and the address of host address elements.  The value of
+// pseudowire and unknown elements is kept in Value, an unknown element
+// ending the TLV since its length is not known.
+type LDPFECElement struct {
+	Type   LDPFECType
+	Prefix net.IPNet
+	Value  []byte
+}
+
+// LDPMessage is a message of an LDP PDU.  TLVs holds all its TLVs, the ones
+// understood being also decoded into the remaining fields.
+type LDPMessage struct {
+	// Unknown is the U bit: a receiver not knowing the message type
+	// silently ignores it if set, and reports it otherwise.
+	Unknown bool
+	Type    LDPMessageType
+	Length  uint16
+	ID      uint32
+	TLVs    []LDPTLV
+
+	Hello            *LDPHelloParameters
+	TransportAddress net.IP
+	Session          *LDPSessionParameters
+	Status           *LDPStatus
+	FEC              []LDPFECElement
+	Label            *uint32 // Generic label
+	Addresses        []net.IP
+}
+
+// LDP is a Label Distribution Protocol PDU, see RFC 5036.  A TCP segment
+// may hold several PDUs, each one decoded into its own layer.
+type LDP struct {
+	BaseLayer
+	Version    uint16
+	PDULength  uint16
+	LSRID      net.IP
+	LabelSpace uint16
+	Messages   []LDPMessage
+}
+
+// LayerType returns LayerTypeLDP.
+func (l *LDP) LayerType() gopacket.LayerType { return LayerTypeLDP }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (l *LDP) CanDecode() gopacket.LayerClass { return LayerTypeLDP }
+
+// NextLayerType returns LayerTypeLDP if another PDU follows.
+func (l *LDP) NextLayerType() gopacket.LayerType {
+	if len(l.Payload) > 0 {
+		return LayerTypeLDP
+	}
+	return gopacket.LayerTypeZero
+}
+
+func decodeLDP(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&LDP{}, data, p)
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (l *LDP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < ldpPDUHeaderLength {
+		df.SetTruncated()
+		return errors.New("LDP PDU header too short")
+	}
+	*l = LDP{
+		Version:    binary.BigEndian.Uint16(data[0:2]),
+		PDULength:  binary.BigEndian.Uint16(data[2:4]),
+		LSRID:      net.IP(data[4:8]),
+		LabelSpace: binary.BigEndian.Uint16(data[8:10]),
+		Messages:   l.Messages[:0],
+	}
+	length := 4 + int(l.PDULength)
+	if length < ldpPDUHeaderLength {
+		return fmt.Errorf("invalid LDP PDU length %d", l.PDULength)
+	}
+	if len(data) < length {
+		df.SetTruncated()
+		return errors.New("LDP PDU too short")
+	}
+	for msgs := data[ldpPDUHeaderLength:length]; len(msgs) > 0; {
+		if len(msgs) < ldpMessageHeaderLength {
+			df.SetTruncated()
+			return errors.New("LDP message header too short")
+		}
+		m := LDPMessage{
+			Unknown: msgs[0]&0x80 != 0,
+			Type:    LDPMessageType(binary.BigEndian.Uint16(msgs[0:2]) & 0x7fff),
+			Length:  binary.BigEndian.Uint16(msgs[2:4]),
+			ID:      binary.BigEndian.Uint32(msgs[4:8]),
+		}
+		end := 4 + int(m.Length)
+		if end < ldpMessageHeaderLength || end > len(msgs) {
+			df.SetTruncated()
+			return fmt.Errorf("invalid LDP %v message length %d", m.Type, m.Length)
+		}
+		if err := m.decodeTLVs(msgs[ldpMessageHeaderLength:end]); err != nil {
+			return err
+		}
+		l.Messages = append(l.Messages, m)
+		msgs = msgs[end:]
+	}
+	l.BaseLayer = BaseLayer{Contents: data[:length], Payload: data[length:]}
+	return nil
+}
+
+func (m *LDPMessage) decodeTLVs(data []byte) error {
+	for len(data) > 0 {
+		if len(data) < ldpTLVHeaderLength {
+			return fmt.Errorf("LDP %v message TLV truncated", m.Type)
+		}
+		tlv := LDPTLV{
+			Unknown: data[0]&0x80 != 0,
+			Forward: data[0]&0x40 != 0,
+			Type:    LDPTLVType(binary.BigEndian.Uint16(data[0:2]) & 0x3fff),
+		}
+		end := ldpTLVHeaderLength + int(binary.BigEndian.Uint16(data[2:4]))
+		if end > len(data) {
+			return fmt.Errorf("LDP %v TLV truncated", tlv.Type)
+		}
+		tlv.Value = data[ldpTLVHeaderLength:end]
+		if err := m.decodeTLV(&tlv); err != nil {
+			return err
+		}
+		m.TLVs = append(m.TLVs, tlv)
+		data = data[end:]
+	}
+	return nil
+}
+
+// ldpTLVLengths are the minimum value lengths of the TLVs decoded by
+// decodeTLV.
+var ldpTLVLengths = map[LDPTLVType]int{
+	LDPTLVGenericLabel:            4,
+	LDPTLVStatus:                  10,
+	LDPTLVCommonHelloParameters:   4,
+	LDPTLVIPv4TransportAddress:    4,
+	LDPTLVIPv6TransportAddress:    16,
+	LDPTLVCommonSessionParameters: 14,
+	LDPTLVAddressList:             2,
+}
+
+func (m *LDPMessage) decodeTLV(tlv *LDPTLV) error {
+	v := tlv.Value
+	if n, ok := ldpTLVLengths[tlv.Type]; ok && len(v) < n {
+		return fmt.Errorf("LDP %v TLV too short", tlv.Type)
+	}
+	switch tlv.Type {
+	case LDPTLVFEC:
+		fec, err := decodeLDPFEC(v)
+		if err != nil {
+			return err
+		}
+		m.FEC = fec
+	case LDPTLVGenericLabel:
+		label := binary.BigEndian.Uint32(v[0:4]) & 0xfffff
+		m.Label = &label
+	case LDPTLVStatus:
+		m.Status = &LDPStatus{
+			Code:        binary.BigEndian.Uint32(v[0:4]),
+			MessageID:   binary.BigEndian.Uint32(v[4:8]),
+			MessageType: LDPMessageType(binary.BigEndian.Uint16(v[8:10])),
+		}
+	case LDPTLVCommonHelloParameters:
+		m.Hello = &LDPHelloParameters{
+			HoldTime:        binary.BigEndian.Uint16(v[0:2]),
+			Targeted:        v[2]&0x80 != 0,
+			RequestTargeted: v[2]&0x40 != 0,
+		}
+	case LDPTLVIPv4TransportAddress:
+		m.TransportAddress = net.IP(v[0:4])
+	case LDPTLVIPv6TransportAddress:
+		m.TransportAddress = net.IP(v[0:16])
+	case LDPTLVCommonSessionParameters:
+		m.Session = &LDPSessionParameters{
+			ProtocolVersion:    binary.BigEndian.Uint16(v[0:2]),
+			KeepAliveTime:      binary.BigEndian.Uint16(v[2:4]),
+			DownstreamOnDemand: v[4]&0x80 != 0,
+			LoopDetection:      v[4]&0x40 != 0,
+			PathVectorLimit:    v[5],
+			MaxPDULength:       binary.BigEndian.Uint16(v[6:8]),
+			ReceiverLSRID:      net.IP(v[8:12]),
+			ReceiverLabelSpace: binary.BigEndian.Uint16(v[12:14]),
+		}
+	case LDPTLVAddressList:
+		n, err := ldpAddressLength(binary.BigEndian.Uint16(v[0:2]))
+		if err != nil {
+			return err
+		}
+		if (len(v)-2)%n != 0 {
+			return fmt.Errorf("invalid LDP address list length %d", len(v))
+		}
+		for a := v[2:]; len(a) > 0; a = a[n:] {
+			m.Addresses = append(m.Addresses, net.IP(a[:n]))
+		}
+	}
+	return nil
+}
+
+// ldpAddressLength returns the address length of an address family, 1 for
+// IPv4 and 2 for IPv6.
+func ldpAddressLength(family uint16) (int, error) {
+	switch family {
+	case 1:
+		return net.IPv4len, nil
+	case 2:
+		return net.IPv6len, nil
+	default:
+		return 0, fmt.Errorf("unsupported LDP address family %d", family)
+	}
+}
+
+func decodeLDPFEC(data []byte) ([]LDPFECElement, error) {
+	var fec []LDPFECElement
+	for len(data) > 0 {
+		e := LDPFECElement{Type: LDPFECType(data[0])}
+		switch e.Type {
+		case LDPFECTypeWildcard:
+			data = data[1:]
+		case LDPFECTypePrefix, LDPFECTypeHostAddress:
+			if len(data) < 4 {
+				return nil, fmt.Errorf("LDP %v FEC element truncated", e.Type)
+			}
+			n, err := ldpAddressLength(binary.BigEndian.Uint16(data[1:3]))
+			if err != nil {
+				return nil, err
+			}
+			bits, size := int(data[3]), int(data[3])
+			if e.Type == LDPFECTypePrefix {
+				size = (bits + 7) / 8
+			} else {
+				bits = size * 8
+			}
+			if bits > n*8 {
+				return nil, fmt.Errorf("invalid LDP %v FEC element length %d", e.Type, data[3])
+			}
+			if len(data) < 4+size {
+				return nil, fmt.Errorf("LDP %v FEC element truncated", e.Type)
+			}
+			ip := make(net.IP, n)
+			copy(ip, data[4:4+size])
+			e.Prefix = net.IPNet{IP: ip, Mask: net.CIDRMask(bits, n*8)}
+			data = data[4+size:]
+		case LDPFECTypePWID, LDPFECTypeGeneralizedPWID:
+			// Both carry a length in their 4th byte, of the value
+			// after a fixed part
+			fixed := 8
+			if e.Type == LDPFECTypeGeneralizedPWID {
+				fixed = 4
+			}
+			if len(data) < 4 || len(data) < fixed+int(data[3]) {
+				return nil, fmt.Errorf("LDP %v FEC element truncated", e.Type)
+			}
+			e.Value = data[1 : fixed+int(data[3])]
+			data = data[fixed+int(data[3]):]
+		default:
+			e.Value = data[1:]
+			data = nil
+		}
+		fec = append(fec, e)
+	}
+	return fec, nil
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketLDPHello is a link hello with a transport address.
+var testPacketLDPHello = []byte{
+	0x01, 0x00, 0x5e, 0x00, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xaa, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x3e, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x0b, 0x44, 0xc0, 0xa8, 0x0c, 0x01, 0xe0, 0x00,
+	0x00, 0x02, 0x02, 0x86, 0x02, 0x86, 0x00, 0x2a, 0x2e, 0x96, 0x00, 0x01, 0x00, 0x1e, 0x0a, 0xff,
+	0x00, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x14, 0x00, 0x00, 0x00, 0x01, 0x04, 0x00, 0x00, 0x04,
+	0x00, 0x0f, 0x00, 0x00, 0x04, 0x01, 0x00, 0x04, 0x0a, 0xff, 0x00, 0x01,
+}
+
+// testPacketLDPSession is a TCP segment holding two LDP PDUs, the first one
+// with initialization and keepalive messages, and the second one with
+// address, label mapping and label withdraw messages.
+var testPacketLDPSession = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xaa, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0xac, 0x00, 0x00, 0x00, 0x00, 0x02, 0x06, 0xa1, 0x8c, 0x0a, 0xff, 0x00, 0x01, 0x0a, 0xff,
+	0x00, 0x02, 0x02, 0x86, 0x9c, 0x40, 0x00, 0x00, 0x03, 0xe8, 0x00, 0x00, 0x07, 0xd0, 0x50, 0x18,
+	0x20, 0x00, 0xb2, 0x14, 0x00, 0x00, 0x00, 0x01, 0x00, 0x28, 0x0a, 0xff, 0x00, 0x01, 0x00, 0x00,
+	0x02, 0x00, 0x00, 0x16, 0x00, 0x00, 0x00, 0x02, 0x05, 0x00, 0x00, 0x0e, 0x00, 0x01, 0x00, 0xb4,
+	0x00, 0x00, 0x10, 0x00, 0x0a, 0xff, 0x00, 0x02, 0x00, 0x00, 0x02, 0x01, 0x00, 0x04, 0x00, 0x00,
+	0x00, 0x03, 0x00, 0x01, 0x00, 0x54, 0x0a, 0xff, 0x00, 0x01, 0x00, 0x00, 0x03, 0x00, 0x00, 0x12,
+	0x00, 0x00, 0x00, 0x04, 0x01, 0x01, 0x00, 0x0a, 0x00, 0x01, 0x0a, 0xff, 0x00, 0x01, 0xc0, 0xa8,
+	0x0c, 0x01, 0x04, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00, 0x05, 0x01, 0x00, 0x00, 0x0f, 0x02, 0x00,
+	0x01, 0x18, 0xac, 0x10, 0x01, 0x02, 0x00, 0x01, 0x20, 0x0a, 0xff, 0x00, 0x01, 0x02, 0x00, 0x00,
+	0x04, 0x00, 0x00, 0x00, 0x10, 0x04, 0x02, 0x00, 0x11, 0x00, 0x00, 0x00, 0x06, 0x01, 0x00, 0x00,
+	0x01, 0x01, 0x02, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x11,
+}
+
+// testPacketLDPNotification is a fatal notification followed by an unknown
+// TLV to forward.
+var testPacketLDPNotification = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xaa, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x4e, 0x00, 0x00, 0x00, 0x00, 0x02, 0x06, 0xa1, 0xea, 0x0a, 0xff, 0x00, 0x01, 0x0a, 0xff,
+	0x00, 0x02, 0x02, 0x86, 0x9c, 0x40, 0x00, 0x00, 0x04, 0x6c, 0x00, 0x00, 0x07, 0xd0, 0x50, 0x18,
+	0x20, 0x00, 0x79, 0x4b, 0x00, 0x00, 0x00, 0x01, 0x00, 0x22, 0x0a, 0xff, 0x00, 0x01, 0x00, 0x00,
+	0x00, 0x01, 0x00, 0x18, 0x00, 0x00, 0x00, 0x07, 0x03, 0x00, 0x00, 0x0a, 0x80, 0x00, 0x00, 0x05,
+	0x00, 0x00, 0x00, 0x02, 0x02, 0x00, 0xc3, 0xff, 0x00, 0x02, 0x01, 0x02,
+}
+
+func TestLDPHello(t *testing.T) {
+	p := gopacket.NewPacket(testPacketLDPHello, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeLDP}, t)
+	ldp := p.Layer(LayerTypeLDP).(*LDP)
+	want := &LDP{
+		BaseLayer: BaseLayer{Contents: testPacketLDPHello[42:], Payload: []byte{}},
+		Version:   1,
+		PDULength: 30,
+		LSRID:     net.IP{10, 255, 0, 1},
+		Messages: []LDPMessage{
+			{
+				Type:   LDPMessageTypeHello,
+				Length: 20,
+				ID:     1,
+				TLVs: []LDPTLV{
+					{Type: LDPTLVCommonHelloParameters, Value: testPacketLDPHello[64:68]},
+					{Type: LDPTLVIPv4TransportAddress, Value: testPacketLDPHello[72:76]},
+				},
+				Hello:            &LDPHelloParameters{HoldTime: 15},
+				TransportAddress: net.IP{10, 255, 0, 1},
+			},
+		},
+	}
+	if !reflect.DeepEqual(ldp, want) {
+		t.Errorf("LDP mismatch:\ngot  %#v\nwant %#v", ldp, want)
+	}
+}
+
+func TestLDPSession(t *testing.T) {
+	p := gopacket.NewPacket(testPacketLDPSession, LinkTypeEthernet, gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true})
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeTCP, LayerTypeLDP, LayerTypeLDP}, t)
+	layers := p.Layers()
+
+	init := layers[3].(*LDP)
+	if len(init.Contents) != 44 || len(init.Messages) != 2 {
+		t.Fatalf("unexpected first PDU of %d bytes with %d messages", len(init.Contents), len(init.Messages))
+	}
+	wantSession := &LDPSessionParameters{
+		ProtocolVersion: 1,
+		KeepAliveTime:   180,
+		MaxPDULength:    4096,
+		ReceiverLSRID:   net.IP{10, 255, 0, 2},
+	}
+	if m := init.Messages[0]; m.Type != LDPMessageTypeInitialization || !reflect.DeepEqual(m.Session, wantSession) {
+		t.Errorf("unexpected initialization message %v, session parameters %#v", m.Type, m.Session)
+	}
+	if m := init.Messages[1]; m.Type != LDPMessageTypeKeepAlive || m.ID != 3 || len(m.TLVs) != 0 {
+		t.Errorf("unexpected keepalive message %#v", m)
+	}
+
+	labels := layers[4].(*LDP)
+	if len(labels.Messages) != 3 {
+		t.Fatalf("got %d messages in second PDU, want 3", len(labels.Messages))
+	}
+	wantAddresses := []net.IP{{10, 255, 0, 1}, {192, 168, 12, 1}}
+	if m := labels.Messages[0]; m.Type != LDPMessageTypeAddress || !reflect.DeepEqual(m.Addresses, wantAddresses) {
+		t.Errorf("unexpected address message %v, addresses %v", m.Type, m.Addresses)
+	}
+	mapping := labels.Messages[1]
+	wantFEC := []LDPFECElement{
+		{Type: LDPFECTypePrefix, Prefix: net.IPNet{IP: net.IP{172, 16, 1, 0}, Mask: net.CIDRMask(24, 32)}},
+		{Type: LDPFECTypePrefix, Prefix: net.IPNet{IP: net.IP{10, 255, 0, 1}, Mask: net.CIDRMask(32, 32)}},
+	}
+	if mapping.Type != LDPMessageTypeLabelMapping || !reflect.DeepEqual(mapping.FEC, wantFEC) {
+		t.Errorf("unexpected label mapping message %v, FEC %v", mapping.Type, mapping.FEC)
+	}
+	if mapping.Label == nil || *mapping.Label != 16 {
+		t.Errorf("unexpected label mapping label %v", mapping.Label)
+	}
+	withdraw := labels.Messages[2]
+	if withdraw.Type != LDPMessageTypeLabelWithdraw || !reflect.DeepEqual(withdraw.FEC, []LDPFECElement{{Type: LDPFECTypeWildcard}}) {
+		t.Errorf("unexpected label withdraw message %v, FEC %v", withdraw.Type, withdraw.FEC)
+	}
+	if withdraw.Label == nil || *withdraw.Label != 17 {
+		t.Errorf("unexpected label withdraw label %v", withdraw.Label)
+	}
+}
+
+func TestLDPNotification(t *testing.T) {
+	p := gopacket.NewPacket(testPacketLDPNotification, LinkTypeEthernet, gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true})
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeTCP, LayerTypeLDP}, t)
+	m := p.Layer(LayerTypeLDP).(*LDP).Messages[0]
+	wantStatus := &LDPStatus{Code: 0x80000005, MessageID: 2, MessageType: LDPMessageTypeInitialization}
+	if m.Type != LDPMessageTypeNotification || !reflect.DeepEqual(m.Status, wantStatus) {
+		t.Errorf("unexpected notification message %v, status %#v", m.Type, m.Status)
+	}
+	if !m.Status.Fatal() || m.Status.StatusData() != 5 {
+		t.Errorf("status fatal %v, data %d, want true, 5", m.Status.Fatal(), m.Status.StatusData())
+	}
+	wantTLV := LDPTLV{Unknown: true, Forward: true, Type: 0x3ff, Value: []byte{1, 2}}
+	if len(m.TLVs) != 2 || !reflect.DeepEqual(m.TLVs[1], wantTLV) {
+		t.Errorf("unexpected TLVs %#v", m.TLVs)
+	}
+}
+
+func TestLDPFECPseudowire(t *testing.T) {
+	// A PWid element of Ethernet PW 100 with a MTU sub-TLV, then a
+	// generalized PWid element with an empty AGI, SAII and TAII
+	data := []byte{
+		0x80, 0x80, 0x05, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x64, 0x01, 0x04, 0x05, 0xdc,
+		0x81, 0x00, 0x05, 0x06, 0x01, 0x00, 0x02, 0x00, 0x03, 0x00,
+	}
+	fec, err := decodeLDPFEC(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LDPFECElement{
+		{Type: LDPFECTypePWID, Value: data[1:16]},
+		{Type: LDPFECTypeGeneralizedPWID, Value: data[17:]},
+	}
+	if !reflect.DeepEqual(fec, want) {
+		t.Errorf("FEC mismatch:\ngot  %#v\nwant %#v", fec, want)
+	}
+}
+
+func TestLDPDecodeInvalid(t *testing.T) {
+	pdu := testPacketLDPSession[54:98]
+	for _, test := range []struct {
+		name   string
+		mutate func([]byte) []byte
+	}{
+		{"truncated header", func(b []byte) []byte { return b[:9] }},
+		{"truncated PDU", func(b []byte) []byte { return b[:len(b)-1] }},
+		{"PDU length too short", func(b []byte) []byte { b[3] = 5; return b }},
+		{"message length too long", func(b []byte) []byte { b[13] = 0xff; return b }},
+		{"TLV length too long", func(b []byte) []byte { b[21] = 0x0f; return b }},
+		{"session parameters too short", func(b []byte) []byte { b[3], b[13], b[21] = 0x27, 0x15, 0x0d; return append(b[:35], b[36:]...) }},
+	} {
+		data := test.mutate(append([]byte{}, pdu...))
+		var ldp LDP
+		if err := ldp.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", test.name)
+		}
+	}
+	fecs := [][]byte{
+		{0x02, 0x00, 0x01, 0x21, 0x0a, 0x00, 0x00, 0x00, 0x00},
+		{0x02, 0x00, 0x03, 0x08, 0x0a},
+		{0x02, 0x00, 0x01, 0x18, 0x0a, 0x00},
+		{0x80, 0x80, 0x05, 0x08, 0x00},
+	}
+	for _, data := range fecs {
+		if _, err := decodeLDPFEC(data); err == nil {
+			t.Errorf("FEC %x decoded successfully", data)
+		}
+	}
+}
@@ -64,6 +64,8 @@ func (l *LLC) NextLayerType() gopacket.LayerType {
 		return LayerTypeSNAP
 	case l.DSAP == 0x42 && l.SSAP == 0x42:
 		return LayerTypeSTP
+	case l.DSAP == 0xFE && l.SSAP == 0xFE && len(l.Payload) > 0 && l.Payload[0] == isisNLPID:
+		return LayerTypeISIS
 	}
 	return gopacket.LayerTypeZero // Not implemented
 }
@@ -767,6 +767,9 @@ func (c *LinkLayerDiscovery) LayerType() gopacket.LayerType {
 
 // SerializeTo serializes LLDP packet to bytes and writes on SerializeBuffer.
 func (c *LinkLayerDiscovery) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if len(c.ChassisID.ID) > 0x1fe || len(c.PortID.ID) > 0x1fe {
+		return errors.New("LLDP chassis or port ID too long")
+	}
 	chassIDLen := c.ChassisID.serializedLen()
 	portIDLen := c.PortID.serializedLen()
 	vb, err := b.AppendBytes(chassIDLen + portIDLen + 4) // +4 for TTL
@@ -779,14 +782,23 @@ func (c *LinkLayerDiscovery) SerializeTo(b gopacket.SerializeBuffer, opts gopack
 	binary.BigEndian.PutUint16(vb[chassIDLen+portIDLen:], ttlIDLen)
 	binary.BigEndian.PutUint16(vb[chassIDLen+portIDLen+2:], c.TTL)
 
-	for _, v := range c.Values {
+	for i := range c.Values {
+		v := &c.Values[i]
+		if opts.FixLengths {
+			v.Length = uint16(len(v.Value))
+		}
+		if v.Length > 0x1ff || len(v.Value) > 0x1ff {
+			return fmt.Errorf("LLDP TLV %v length %d too long", v.Type, len(v.Value))
+		}
 		vb, err := b.AppendBytes(int(v.Length) + 2) // +2 for TLV type and length; 1 byte for subtype is included in v.Value
 		if err != nil {
 			return err
 		}
 		idLen := ((uint16(v.Type) << 9) | v.Length)
 		binary.BigEndian.PutUint16(vb[0:2], idLen)
-		copy(vb[2:], v.Value)
+		for j := copy(vb[2:], v.Value) + 2; j < len(vb); j++ {
+			vb[j] = 0
+		}
 	}
 
 	vb, err = b.AppendBytes(2) // End Tlv, 2 bytes
@@ -881,16 +893,19 @@ func decodeLinkLayerDiscovery(data []byte, p gopacket.PacketBuilder) error {
 			if err := checkLLDPTLVLen(v, 9); err != nil {
 				return err
 			}
-			mlen := v.Value[0]
-			if err := checkLLDPTLVLen(v, int(mlen+7)); err != nil {
+			mlen := int(v.Value[0])
+			if mlen == 0 {
+				return errors.New("Malformed LinkLayerDiscovery MgmtAddress TLV")
+			}
+			if err := checkLLDPTLVLen(v, mlen+7); err != nil {
 				return err
 			}
 			info.MgmtAddress.Subtype = IANAAddressFamily(v.Value[1])
 			info.MgmtAddress.Address = v.Value[2 : mlen+1]
 			info.MgmtAddress.InterfaceSubtype = LLDPInterfaceSubtype(v.Value[mlen+1])
 			info.MgmtAddress.InterfaceNumber = binary.BigEndian.Uint32(v.Value[mlen+2 : mlen+6])
-			olen := v.Value[mlen+6]
-			if err := checkLLDPTLVLen(v, int(mlen+7+olen)); err != nil {
+			olen := int(v.Value[mlen+6])
+			if err := checkLLDPTLVLen(v, mlen+7+olen); err != nil {
 				return err
 			}
 			info.MgmtAddress.OID = string(v.Value[mlen+7 : mlen+7+olen])
@@ -924,16 +939,22 @@ func (l *LinkLayerDiscoveryInfo) Decode8021() (info LLDPInfo8021, err error) {
 			id := binary.BigEndian.Uint16(o.Info[1:3])
 			info.PPVIDs = append(info.PPVIDs, PortProtocolVLANID{sup, en, id})
 		case LLDP8021SubtypeVLANName:
-			if err = checkLLDPOrgSpecificLen(o, 2); err != nil {
+			if err = checkLLDPOrgSpecificLen(o, 3); err != nil {
+				return
+			}
+			if err = checkLLDPOrgSpecificLen(o, 3+int(o.Info[2])); err != nil {
 				return
 			}
 			id := binary.BigEndian.Uint16(o.Info[0:2])
-			info.VLANNames = append(info.VLANNames, VLANName{id, string(o.Info[3:])})
+			info.VLANNames = append(info.VLANNames, VLANName{id, string(o.Info[3 : 3+int(o.Info[2])])})
 		case LLDP8021SubtypeProtocolIdentity:
 			if err = checkLLDPOrgSpecificLen(o, 1); err != nil {
 				return
 			}
 			l := int(o.Info[0])
+			if err = checkLLDPOrgSpecificLen(o, 1+l); err != nil {
+				return
+			}
 			if l > 0 {
 				info.ProtocolIdentities = append(info.ProtocolIdentities, o.Info[1:1+l])
 			}
@@ -984,7 +1005,7 @@ func (l *LinkLayerDiscoveryInfo) Decode8023() (info LLDPInfo8023, err error) {
 			info.PowerViaMDI.PSEPairsAbility = (o.Info[0]&LLDPMDIPowerPairsAbility > 0)
 			info.PowerViaMDI.PSEPowerPair = uint8(o.Info[1])
 			info.PowerViaMDI.PSEClass = uint8(o.Info[2])
-			if len(o.Info) >= 7 {
+			if len(o.Info) >= 8 {
 				info.PowerViaMDI.Type = LLDPPowerType((o.Info[3] & 0xc0) >> 6)
 				info.PowerViaMDI.Source = LLDPPowerSource((o.Info[3] & 0x30) >> 4)
 				if info.PowerViaMDI.Type == 1 || info.PowerViaMDI.Type == 3 {
@@ -1085,7 +1106,7 @@ func (l *LinkLayerDiscoveryInfo) DecodeMedia() (info LLDPInfoMedia, err error) {
 				info.Location.Coordinate.Altitude = b2 & 0x3fffffff
 				info.Location.Coordinate.Datum = uint8(o.Info[15])
 			case LLDPLocationFormatAddress:
-				if err = checkLLDPOrgSpecificLen(o, 3); err != nil {
+				if err = checkLLDPOrgSpecificLen(o, 4); err != nil {
 					return
 				}
 				//ll := uint8(o.Info[0])
@@ -1210,6 +1231,229 @@ func (l *LinkLayerDiscoveryInfo) DecodeProfinet() (info LLDPInfoProfinet, err er
 	return
 }
 
+// Values encodes the TLVs holding the information, as set in the Values of
+// a LinkLayerDiscovery layer to serialize.  Org-specific TLVs can be built
+// with the OrgTLVs() methods of the LLDPInfo types.
+func (l *LinkLayerDiscoveryInfo) Values() []LinkLayerDiscoveryValue {
+	var vals []LinkLayerDiscoveryValue
+	add := func(t LLDPTLVType, v []byte) {
+		vals = append(vals, LinkLayerDiscoveryValue{Type: t, Length: uint16(len(v)), Value: v})
+	}
+	if l.PortDescription != "" {
+		add(LLDPTLVPortDescription, []byte(l.PortDescription))
+	}
+	if l.SysName != "" {
+		add(LLDPTLVSysName, []byte(l.SysName))
+	}
+	if l.SysDescription != "" {
+		add(LLDPTLVSysDescription, []byte(l.SysDescription))
+	}
+	if l.SysCapabilities != (LLDPSysCapabilities{}) {
+		v := make([]byte, 4)
+		binary.BigEndian.PutUint16(v[0:2], putCapabilities(l.SysCapabilities.SystemCap))
+		binary.BigEndian.PutUint16(v[2:4], putCapabilities(l.SysCapabilities.EnabledCap))
+		add(LLDPTLVSysCapabilities, v)
+	}
+	if m := l.MgmtAddress; m.Subtype != IANAAddressFamilyReserved {
+		v := []byte{byte(len(m.Address) + 1), byte(m.Subtype)}
+		v = append(v, m.Address...)
+		v = append(v, byte(m.InterfaceSubtype), 0, 0, 0, 0, byte(len(m.OID)))
+		binary.BigEndian.PutUint32(v[len(v)-5:], m.InterfaceNumber)
+		add(LLDPTLVMgmtAddress, append(v, m.OID...))
+	}
+	for _, o := range l.OrgTLVs {
+		v := []byte{byte(o.OUI >> 16), byte(o.OUI >> 8), byte(o.OUI), o.SubType}
+		add(LLDPTLVOrgSpecific, append(v, o.Info...))
+	}
+	return append(vals, l.Unknown...)
+}
+
+// OrgTLVs encodes the set 802.1 information into Org-specific TLVs.
+func (info *LLDPInfo8021) OrgTLVs() (tlvs []LLDPOrgSpecificTLV) {
+	add := func(subtype uint8, v []byte) {
+		tlvs = append(tlvs, LLDPOrgSpecificTLV{IEEEOUI8021, subtype, v})
+	}
+	if info.PVID != 0 {
+		add(LLDP8021SubtypePortVLANID, []byte{byte(info.PVID >> 8), byte(info.PVID)})
+	}
+	for _, p := range info.PPVIDs {
+		var flags byte
+		if p.Supported {
+			flags |= LLDPProtocolVLANIDCapability
+		}
+		if p.Enabled {
+			flags |= LLDPProtocolVLANIDStatus
+		}
+		add(LLDP8021SubtypeProtocolVLANID, []byte{flags, byte(p.ID >> 8), byte(p.ID)})
+	}
+	for _, n := range info.VLANNames {
+		add(LLDP8021SubtypeVLANName, append([]byte{byte(n.ID >> 8), byte(n.ID), byte(len(n.Name))}, n.Name...))
+	}
+	for _, p := range info.ProtocolIdentities {
+		add(LLDP8021SubtypeProtocolIdentity, append([]byte{byte(len(p))}, p...))
+	}
+	if info.VIDUsageDigest != 0 {
+		v := make([]byte, 4)
+		binary.BigEndian.PutUint32(v, info.VIDUsageDigest)
+		add(LLDP8021SubtypeVDIUsageDigest, v)
+	}
+	if info.ManagementVID != 0 {
+		add(LLDP8021SubtypeManagementVID, []byte{byte(info.ManagementVID >> 8), byte(info.ManagementVID)})
+	}
+	if info.LinkAggregation != (LLDPLinkAggregation{}) {
+		add(LLDP8021SubtypeLinkAggregation, info.LinkAggregation.serialize())
+	}
+	return
+}
+
+func (a LLDPLinkAggregation) serialize() []byte {
+	v := make([]byte, 5)
+	if a.Supported {
+		v[0] |= LLDPAggregationCapability
+	}
+	if a.Enabled {
+		v[0] |= LLDPAggregationStatus
+	}
+	binary.BigEndian.PutUint32(v[1:5], a.PortID)
+	return v
+}
+
+// OrgTLVs encodes the set 802.3 information into Org-specific TLVs.
+func (info *LLDPInfo8023) OrgTLVs() (tlvs []LLDPOrgSpecificTLV) {
+	add := func(subtype uint8, v []byte) {
+		tlvs = append(tlvs, LLDPOrgSpecificTLV{IEEEOUI8023, subtype, v})
+	}
+	if m := info.MACPHYConfigStatus; m != (LLDPMACPHYConfigStatus{}) {
+		v := make([]byte, 5)
+		if m.AutoNegSupported {
+			v[0] |= LLDPMACPHYCapability
+		}
+		if m.AutoNegEnabled {
+			v[0] |= LLDPMACPHYStatus
+		}
+		binary.BigEndian.PutUint16(v[1:3], m.AutoNegCapability)
+		binary.BigEndian.PutUint16(v[3:5], m.MAUType)
+		add(LLDP8023SubtypeMACPHY, v)
+	}
+	if p := info.PowerViaMDI; p != (LLDPPowerViaMDI8023{}) {
+		v := make([]byte, 3, 8)
+		if p.PortClassPSE {
+			v[0] |= LLDPMDIPowerPortClass
+		}
+		if p.PSESupported {
+			v[0] |= LLDPMDIPowerCapability
+		}
+		if p.PSEEnabled {
+			v[0] |= LLDPMDIPowerStatus
+		}
+		if p.PSEPairsAbility {
+			v[0] |= LLDPMDIPowerPairsAbility
+		}
+		v[1], v[2] = p.PSEPowerPair, p.PSEClass
+		if p.Type != 0 || p.Source != 0 || p.Priority != 0 || p.Requested != 0 || p.Allocated != 0 {
+			v = append(v, putPowerTypeSourcePriority(p.Type, p.Source, p.Priority),
+				byte(p.Requested>>8), byte(p.Requested), byte(p.Allocated>>8), byte(p.Allocated))
+		}
+		add(LLDP8023SubtypeMDIPower, v)
+	}
+	if info.LinkAggregation != (LLDPLinkAggregation{}) {
+		add(LLDP8023SubtypeLinkAggregation, info.LinkAggregation.serialize())
+	}
+	if info.MTU != 0 {
+		add(LLDP8023SubtypeMTU, []byte{byte(info.MTU >> 8), byte(info.MTU)})
+	}
+	return
+}
+
+// OrgTLVs encodes the set LLDP-MED information into Org-specific TLVs.
+func (info *LLDPInfoMedia) OrgTLVs() (tlvs []LLDPOrgSpecificTLV) {
+	add := func(subtype LLDPMediaSubtype, v []byte) {
+		tlvs = append(tlvs, LLDPOrgSpecificTLV{IEEEOUIMedia, uint8(subtype), v})
+	}
+	if c := info.MediaCapabilities; c != (LLDPMediaCapabilities{}) {
+		var b uint16
+		if c.Capabilities {
+			b |= LLDPMediaCapsLLDP
+		}
+		if c.NetworkPolicy {
+			b |= LLDPMediaCapsNetwork
+		}
+		if c.Location {
+			b |= LLDPMediaCapsLocation
+		}
+		if c.PowerPSE {
+			b |= LLDPMediaCapsPowerPSE
+		}
+		if c.PowerPD {
+			b |= LLDPMediaCapsPowerPD
+		}
+		if c.Inventory {
+			b |= LLDPMediaCapsInventory
+		}
+		add(LLDPMediaTypeCapabilities, []byte{byte(b >> 8), byte(b), byte(c.Class)})
+	}
+	if n := info.NetworkPolicy; n != (LLDPNetworkPolicy{}) {
+		b := uint32(n.VLANId&0xfff)<<9 | uint32(n.L2Priority&0x7)<<6 | uint32(n.DSCPValue&0x3f)
+		if !n.Defined {
+			b |= 1 << 23
+		}
+		if n.Tagged {
+			b |= 1 << 22
+		}
+		add(LLDPMediaTypeNetwork, []byte{byte(n.ApplicationType), byte(b >> 16), byte(b >> 8), byte(b)})
+	}
+	switch loc := info.Location; loc.Format {
+	case LLDPLocationFormatCoordinate:
+		c := loc.Coordinate
+		v := make([]byte, 17)
+		v[0] = byte(loc.Format)
+		binary.BigEndian.PutUint64(v[1:9], uint64(c.LatitudeResolution&0x3f)<<58|(c.Latitude&0x3ffffffff)<<24|
+			uint64(c.LongitudeResolution&0x3f)<<18|(c.Longitude&0x3ffffffff)>>16)
+		binary.BigEndian.PutUint64(v[9:17], (c.Longitude&0xffff)<<48|uint64(c.AltitudeType&0xf)<<44|
+			uint64(c.AltitudeResolution&0x3f)<<38|uint64(c.Altitude&0x3fffffff)<<8|uint64(c.Datum))
+		add(LLDPMediaTypeLocation, v)
+	case LLDPLocationFormatAddress:
+		a := loc.Address
+		v := []byte{byte(loc.Format), 0, byte(a.What)}
+		v = append(v, (a.CountryCode + "  ")[:2]...)
+		for _, line := range a.AddressLines {
+			v = append(v, byte(line.Type), byte(len(line.Value)))
+			v = append(v, line.Value...)
+		}
+		v[1] = byte(len(v) - 2)
+		add(LLDPMediaTypeLocation, v)
+	case LLDPLocationFormatECS:
+		add(LLDPMediaTypeLocation, append([]byte{byte(loc.Format)}, loc.ECS.ELIN...))
+	}
+	if p := info.PowerViaMDI; p != (LLDPPowerViaMDI{}) {
+		value := p.Value / 100
+		add(LLDPMediaTypePower, []byte{putPowerTypeSourcePriority(p.Type, p.Source, p.Priority), byte(value >> 8), byte(value)})
+	}
+	for _, inv := range []struct {
+		subtype LLDPMediaSubtype
+		value   string
+	}{
+		{LLDPMediaTypeHardware, info.HardwareRevision},
+		{LLDPMediaTypeFirmware, info.FirmwareRevision},
+		{LLDPMediaTypeSoftware, info.SoftwareRevision},
+		{LLDPMediaTypeSerial, info.SerialNumber},
+		{LLDPMediaTypeManufacturer, info.Manufacturer},
+		{LLDPMediaTypeModel, info.Model},
+		{LLDPMediaTypeAssetID, info.AssetID},
+	} {
+		if inv.value != "" {
+			add(inv.subtype, []byte(inv.value))
+		}
+	}
+	return
+}
+
+// putPowerTypeSourcePriority packs power TLV fields, dropping the 128 added
+// to PD power sources when decoding.
+func putPowerTypeSourcePriority(t LLDPPowerType, s LLDPPowerSource, p LLDPPowerPriority) byte {
+	return byte(t&0x3)<<6 | byte(s&0x3)<<4 | byte(p&0xf)
+}
+
 // LayerType returns gopacket.LayerTypeLinkLayerDiscoveryInfo.
 func (c *LinkLayerDiscoveryInfo) LayerType() gopacket.LayerType {
 	return LayerTypeLinkLayerDiscoveryInfo
@@ -1230,6 +1474,30 @@ func getCapabilities(v uint16) (c LLDPCapabilities) {
 	return
 }
 
+func putCapabilities(c LLDPCapabilities) (v uint16) {
+	for _, b := range []struct {
+		set bool
+		bit uint16
+	}{
+		{c.Other, LLDPCapsOther},
+		{c.Repeater, LLDPCapsRepeater},
+		{c.Bridge, LLDPCapsBridge},
+		{c.WLANAP, LLDPCapsWLANAP},
+		{c.Router, LLDPCapsRouter},
+		{c.Phone, LLDPCapsPhone},
+		{c.DocSis, LLDPCapsDocSis},
+		{c.StationOnly, LLDPCapsStationOnly},
+		{c.CVLAN, LLDPCapsCVLAN},
+		{c.SVLAN, LLDPCapsSVLAN},
+		{c.TMPR, LLDPCapsTmpr},
+	} {
+		if b.set {
+			v |= b.bit
+		}
+	}
+	return
+}
+
 func getEVBCapabilities(v uint16) (c LLDPEVBCapabilities) {
 	c.StandardBridging = (v & LLDPEVBCapsSTD) > 0
 	c.StandardBridging = (v & LLDPEVBCapsSTD) > 0
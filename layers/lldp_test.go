@@ -87,3 +87,104 @@ func BenchmarkDecodePacketLLDP(b *testing.B) {
 		gopacket.NewPacket(testPacketLLDP, LinkTypeEthernet, gopacket.NoCopy)
 	}
 }
+
+func TestSerializeLLDP(t *testing.T) {
+	p := gopacket.NewPacket(testPacketLLDP, LinkTypeEthernet, gopacket.Default)
+	lldp := p.Layer(LayerTypeLinkLayerDiscovery).(*LinkLayerDiscovery)
+	buf := gopacket.NewSerializeBuffer()
+	if err := lldp.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := testPacketLLDP[14:]; !reflect.DeepEqual(buf.Bytes(), want) {
+		t.Errorf("LLDP serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), want)
+	}
+
+	info8021 := LLDPInfo8021{
+		PVID:               10,
+		PPVIDs:             []PortProtocolVLANID{{true, true, 20}},
+		VLANNames:          []VLANName{{10, "users"}, {20, "voice"}},
+		ProtocolIdentities: []ProtocolIdentity{{0x88, 0xcc}},
+		ManagementVID:      99,
+		LinkAggregation:    LLDPLinkAggregation{true, true, 7},
+	}
+	info8023 := LLDPInfo8023{
+		MACPHYConfigStatus: LLDPMACPHYConfigStatus{true, true, LLDPMAUPMD1000BaseT_FD, LLDPMAUType1000BaseT_FD},
+		PowerViaMDI:        LLDPPowerViaMDI8023{true, true, true, false, 1, 4, 1, 129, LLDPPowerPriorityHigh, 255, 255},
+		MTU:                9216,
+	}
+	infoMedia := LLDPInfoMedia{
+		MediaCapabilities: LLDPMediaCapabilities{true, true, true, true, false, true, LLDPMediaClassNetwork},
+		NetworkPolicy:     LLDPNetworkPolicy{LLDPAppTypeVoice, true, true, 20, 5, 46},
+		Location: LLDPLocation{Format: LLDPLocationFormatAddress, Address: LLDPLocationAddress{
+			What:         LLDPLocationAddressWhatClient,
+			CountryCode:  "DE",
+			AddressLines: []LLDPLocationAddressLine{{LLDPLocationAddressTypeCity, "Berlin"}, {LLDPLocationAddressTypeRoom, "B12"}},
+		}},
+		PowerViaMDI:  LLDPPowerViaMDI{Priority: LLDPPowerPriorityLow, Value: 6500},
+		Manufacturer: "GoPacket",
+		Model:        "Switch",
+	}
+	info := &LinkLayerDiscoveryInfo{
+		PortDescription: "uplink",
+		SysName:         "switch1",
+		SysCapabilities: LLDPSysCapabilities{
+			SystemCap:  LLDPCapabilities{Bridge: true, Router: true},
+			EnabledCap: LLDPCapabilities{Bridge: true},
+		},
+		MgmtAddress: LLDPMgmtAddress{IANAAddressFamilyIPV4, []byte{192, 0, 2, 1}, LLDPInterfaceSubtypeifIndex, 3, ""},
+	}
+	info.OrgTLVs = append(append(info8021.OrgTLVs(), info8023.OrgTLVs()...), infoMedia.OrgTLVs()...)
+	lldp = &LinkLayerDiscovery{
+		ChassisID: LLDPChassisID{LLDPChassisIDSubTypeMACAddr, []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}},
+		PortID:    LLDPPortID{LLDPPortIDSubtypeIfaceName, []byte("ge-0/0/1")},
+		TTL:       120,
+		Values:    info.Values(),
+	}
+	eth := &Ethernet{
+		SrcMAC:       []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+		DstMAC:       []byte{0x01, 0x80, 0xc2, 0x00, 0x00, 0x0e},
+		EthernetType: EthernetTypeLinkLayerDiscovery,
+	}
+	buf = gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, eth, lldp); err != nil {
+		t.Fatal(err)
+	}
+
+	p = gopacket.NewPacket(buf.Bytes(), LinkTypeEthernet, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeLinkLayerDiscovery, LayerTypeLinkLayerDiscoveryInfo}, t)
+	got := p.Layer(LayerTypeLinkLayerDiscoveryInfo).(*LinkLayerDiscoveryInfo)
+	got.BaseLayer = info.BaseLayer
+	if !reflect.DeepEqual(got, info) {
+		t.Errorf("LLDP info mismatch:\ngot  %#v\nwant %#v", got, info)
+	}
+	if got8021, err := got.Decode8021(); err != nil || !reflect.DeepEqual(got8021, info8021) {
+		t.Errorf("802.1 mismatch (%v):\ngot  %#v\nwant %#v", err, got8021, info8021)
+	}
+	if got8023, err := got.Decode8023(); err != nil || !reflect.DeepEqual(got8023, info8023) {
+		t.Errorf("802.3 mismatch (%v):\ngot  %#v\nwant %#v", err, got8023, info8023)
+	}
+	if gotMedia, err := got.DecodeMedia(); err != nil || !reflect.DeepEqual(gotMedia, infoMedia) {
+		t.Errorf("LLDP-MED mismatch (%v):\ngot  %#v\nwant %#v", err, gotMedia, infoMedia)
+	}
+}
+
+func TestLLDPMediaLocationCoordinate(t *testing.T) {
+	want := LLDPInfoMedia{Location: LLDPLocation{Format: LLDPLocationFormatCoordinate, Coordinate: LLDPLocationCoordinate{
+		LatitudeResolution:  34,
+		Latitude:            0x0a70a3d70,
+		LongitudeResolution: 34,
+		Longitude:           0x3f12b0209,
+		AltitudeType:        1,
+		AltitudeResolution:  30,
+		Altitude:            0x2a00,
+		Datum:               1,
+	}}}
+	info := &LinkLayerDiscoveryInfo{OrgTLVs: want.OrgTLVs()}
+	got, err := info.DecodeMedia()
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("LLDP-MED location mismatch (%v):\ngot  %#v\nwant %#v", err, got, want)
+	}
+}
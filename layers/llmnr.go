@@ -0,0 +1,152 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"github.com/google/gopacket"
+)
+
+//  LLMNR Header, see RFC 4795, section 2.1.1
+//  0  1  2  3  4  5  6  7  8  9  0  1  2  3  4  5
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                      ID                       |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |QR|   Opcode  | C|TC| T| Z| Z| Z| Z|   RCODE   |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                    QDCOUNT                    |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                    ANCOUNT                    |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                    NSCOUNT                    |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                    ARCOUNT                    |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//
+// The rest of the message is encoded as in DNS.
+
+// LLMNR contains data from a single Link-Local Multicast Name Resolution
+// packet.
+type LLMNR struct {
+	BaseLayer
+
+	// Header fields
+	ID     uint16
+	QR     bool
+	OpCode DNSOpCode
+
+	// C (conflict) is set by responders whose name is not unique on the
+	// link, and in queries probing for name conflicts.
+	C  bool
+	TC bool  // Truncated
+	T  bool  // Tentative, the responder has not verified its name is unique
+	Z  uint8 // Reserved for future use
+
+	ResponseCode DNSResponseCode
+	QDCount      uint16 // Number of questions to expect
+	ANCount      uint16 // Number of answers to expect
+	NSCount      uint16 // Number of authorities to expect
+	ARCount      uint16 // Number of additional records to expect
+
+	// Entries
+	Questions   []DNSQuestion
+	Answers     []DNSResourceRecord
+	Authorities []DNSResourceRecord
+	Additionals []DNSResourceRecord
+
+	// dns decodes the message, its buffer being reused across
+	// DecodeFromBytes calls.
+	dns DNS
+}
+
+// LayerType returns LayerTypeLLMNR.
+func (l *LLMNR) LayerType() gopacket.LayerType { return LayerTypeLLMNR }
+
+func decodeLLMNR(data []byte, p gopacket.PacketBuilder) error {
+	l := &LLMNR{}
+	err := l.DecodeFromBytes(data, p)
+	if err != nil {
+		return err
+	}
+	p.AddLayer(l)
+	p.SetApplicationLayer(l)
+	return nil
+}
+
+// DecodeFromBytes decodes the slice into the LLMNR struct.
+func (l *LLMNR) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	err := l.dns.DecodeFromBytes(data, df)
+	if len(data) < 12 {
+		return err
+	}
+	d := &l.dns
+	l.BaseLayer = BaseLayer{Contents: data}
+	l.ID = d.ID
+	l.QR = d.QR
+	l.OpCode = d.OpCode
+	l.C = d.AA
+	l.TC = d.TC
+	l.T = d.RD
+	l.Z = data[3] >> 4
+	l.ResponseCode = DNSResponseCode(data[3] & 0xF)
+	l.QDCount = d.QDCount
+	l.ANCount = d.ANCount
+	l.NSCount = d.NSCount
+	l.ARCount = d.ARCount
+	l.Questions = d.Questions
+	l.Answers = d.Answers
+	l.Authorities = d.Authorities
+	l.Additionals = d.Additionals
+	return err
+}
+
+// CanDecode implements gopacket.DecodingLayer.
+func (l *LLMNR) CanDecode() gopacket.LayerClass {
+	return LayerTypeLLMNR
+}
+
+// NextLayerType implements gopacket.DecodingLayer.
+func (l *LLMNR) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypePayload
+}
+
+// Payload returns nil.
+func (l *LLMNR) Payload() []byte {
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+func (l *LLMNR) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	d := &l.dns
+	d.ID = l.ID
+	d.QR = l.QR
+	d.OpCode = l.OpCode
+	d.AA = l.C
+	d.TC = l.TC
+	d.RD = l.T
+	d.RA = l.Z&0x8 != 0
+	d.Z = l.Z & 0x7
+	d.ResponseCode = l.ResponseCode
+	d.QDCount = l.QDCount
+	d.ANCount = l.ANCount
+	d.NSCount = l.NSCount
+	d.ARCount = l.ARCount
+	d.Questions = l.Questions
+	d.Answers = l.Answers
+	d.Authorities = l.Authorities
+	d.Additionals = l.Additionals
+	if err := d.SerializeTo(b, opts); err != nil {
+		return err
+	}
+	if opts.FixLengths {
+		l.QDCount = d.QDCount
+		l.ANCount = d.ANCount
+		l.NSCount = d.NSCount
+		l.ARCount = d.ARCount
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestLLMNR(t *testing.T) {
+	ip := &IPv4{Version: 4, TTL: 1, Protocol: IPProtocolUDP, SrcIP: net.IP{192, 168, 1, 5}, DstIP: net.IP{224, 0, 0, 252}}
+	udp := &UDP{SrcPort: 50000, DstPort: 5355}
+	udp.SetNetworkLayerForChecksum(ip)
+	response := &LLMNR{ID: 0x1234, QR: true, C: true, T: true, Z: 0x9,
+		Questions: []DNSQuestion{{Name: []byte("fileserver"), Type: DNSTypeA, Class: DNSClassIN}},
+		Answers: []DNSResourceRecord{
+			{Name: []byte("fileserver"), Type: DNSTypeA, Class: DNSClassIN, TTL: 30, IP: net.IP{192, 168, 1, 66}},
+		},
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, ip, udp, response); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.Bytes()[28+2 : 28+4]; got[0] != 0x85 || got[1] != 0x90 {
+		t.Errorf("unexpected LLMNR flags %x", got)
+	}
+
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv4, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeLLMNR}, t)
+	l := p.Layer(LayerTypeLLMNR).(*LLMNR)
+	if l.ID != 0x1234 || !l.QR || !l.C || l.TC || !l.T || l.Z != 0x9 || l.ResponseCode != DNSResponseCodeNoErr {
+		t.Errorf("unexpected LLMNR header %+v", l)
+	}
+	if l.QDCount != 1 || l.ANCount != 1 || string(l.Questions[0].Name) != "fileserver" {
+		t.Errorf("unexpected LLMNR question %+v", l.Questions)
+	}
+	if len(l.Answers) != 1 || !l.Answers[0].IP.Equal(net.IP{192, 168, 1, 66}) {
+		t.Errorf("unexpected LLMNR answers %+v", l.Answers)
+	}
+	if p.ApplicationLayer() != l {
+		t.Error("LLMNR is not the application layer")
+	}
+
+	var dec LLMNR
+	if err := dec.DecodeFromBytes(l.Contents[:11], gopacket.NilDecodeFeedback); err == nil {
+		t.Error("decoded truncated LLMNR header")
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/google/gopacket"
+)
+
+// MACsecDecryption is the MACsecSATable used to decrypt the MACsec frames
+// decoded after an Ethernet header.  nil, the default, disables decryption.
+var MACsecDecryption *MACsecSATable
+
+// macsecICVLength is the length of the ICV of the GCM-AES cipher suites
+const macsecICVLength = 16
+
+// MACsec is the MAC security header, IEEE 802.1AE: the SecTAG following the
+// addresses of an Ethernet frame, and the ICV ending it.  Frames only
+// integrity protected are decoded further, from the EthernetType of the
+// secure data.  The secure data of encrypted frames is decoded further if
+// MACsecDecryption has the key of their secure association.
+type MACsec struct {
+	BaseLayer
+	// EndStation, SCPresent, SingleCopyBroadcast, Encrypted and Changed are
+	// the ES, SC, SCB, E and C bits of the TCI
+	EndStation, SCPresent, SingleCopyBroadcast, Encrypted, Changed bool
+	AssociationNumber                                              uint8
+	// ShortLength is the length of the secure data of short frames, 0 for
+	// the others
+	ShortLength  uint8
+	PacketNumber uint32
+	// SCI is the secure channel identifier: the MAC address and port of the
+	// sender.  If SCPresent isn't set, it is derived from the source address
+	// of the Ethernet header, when decoded after it.
+	SCI uint64
+	// SecureData is the data protected, encrypted if Encrypted is set
+	SecureData []byte
+	ICV        []byte
+	// Decrypted is set if the secure data was decrypted; the payload is
+	// then the decrypted data, after its EthernetType
+	Decrypted    bool
+	EthernetType EthernetType
+}
+
+// LayerType returns LayerTypeMACsec.
+func (m *MACsec) LayerType() gopacket.LayerType { return LayerTypeMACsec }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (m *MACsec) CanDecode() gopacket.LayerClass { return LayerTypeMACsec }
+
+// NextLayerType returns the layer type of the secure data, or
+// gopacket.LayerTypeZero if it is encrypted.
+func (m *MACsec) NextLayerType() gopacket.LayerType {
+	if m.Encrypted && !m.Decrypted {
+		return gopacket.LayerTypeZero
+	}
+	return m.EthernetType.LayerType()
+}
+
+// SCIAddress returns the MAC address of the SCI.
+func (m *MACsec) SCIAddress() net.HardwareAddr {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], m.SCI)
+	return net.HardwareAddr(b[:6])
+}
+
+// SCIPort returns the port identifier of the SCI.
+func (m *MACsec) SCIPort() uint16 {
+	return uint16(m.SCI)
+}
+
+// DecodeFromBytes decodes the slice into the MACsec struct.
+func (m *MACsec) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*m = MACsec{}
+	if len(data) < 6 {
+		df.SetTruncated()
+		return errors.New("MACsec SecTAG too short")
+	}
+	tci := data[0]
+	if tci&0x80 != 0 {
+		return errors.New("unsupported MACsec version")
+	}
+	m.EndStation = tci&0x40 != 0
+	m.SCPresent = tci&0x20 != 0
+	m.SingleCopyBroadcast = tci&0x10 != 0
+	m.Encrypted = tci&0x08 != 0
+	m.Changed = tci&0x04 != 0
+	m.AssociationNumber = tci & 0x03
+	if m.Encrypted != m.Changed {
+		return errors.New("MACsec frame with only one of the E and C bits")
+	}
+	m.ShortLength = data[1] & 0x3f
+	m.PacketNumber = binary.BigEndian.Uint32(data[2:6])
+	n := 6
+	if m.SCPresent {
+		if len(data) < 14 {
+			df.SetTruncated()
+			return errors.New("MACsec SecTAG too short")
+		}
+		m.SCI = binary.BigEndian.Uint64(data[6:14])
+		n = 14
+	}
+	end := len(data)
+	if m.ShortLength != 0 {
+		// the rest is padding of the Ethernet frame
+		end = n + int(m.ShortLength) + macsecICVLength
+	}
+	if len(data) < end || end < n+macsecICVLength {
+		df.SetTruncated()
+		return errors.New("MACsec frame too short")
+	}
+	m.SecureData = data[n : end-macsecICVLength]
+	m.ICV = data[end-macsecICVLength : end]
+	m.Contents = data[:n]
+	if m.Encrypted {
+		m.Payload = m.SecureData
+		return nil
+	}
+	if len(m.SecureData) < 2 {
+		return errors.New("MACsec secure data without EthernetType")
+	}
+	m.EthernetType = EthernetType(binary.BigEndian.Uint16(m.SecureData))
+	m.Contents = data[:n+2]
+	m.Payload = m.SecureData[2:]
+	return nil
+}
+
+func decodeMACsec(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&MACsec{}, data, p)
+}
+
+// macsecDecoder decodes the MACsec frames following an Ethernet header,
+// whose addresses are needed to set the SCI and to decrypt the frames.
+type macsecDecoder struct {
+	eth *Ethernet
+}
+
+func (d macsecDecoder) Decode(data []byte, p gopacket.PacketBuilder) error {
+	m := &MACsec{}
+	if err := m.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	if !m.SCPresent && len(d.eth.SrcMAC) == 6 {
+		m.SCI = macsecImplicitSCI(d.eth.SrcMAC)
+	}
+	p.AddLayer(m)
+	if MACsecDecryption != nil && m.Encrypted {
+		if err := MACsecDecryption.Decrypt(m, d.eth.DstMAC, d.eth.SrcMAC); err != nil {
+			if err == errMACsecNoSA {
+				return nil
+			}
+			return err
+		}
+	}
+	next := m.NextLayerType()
+	if next == gopacket.LayerTypeZero {
+		return nil
+	}
+	return p.NextDecoder(next)
+}
+
+// macsecImplicitSCI returns the SCI of the frames sent from src without
+// SCI: the address and port 1.
+func macsecImplicitSCI(src net.HardwareAddr) uint64 {
+	var b [8]byte
+	copy(b[:], src)
+	b[7] = 1
+	return binary.BigEndian.Uint64(b[:])
+}
+
+type macsecSAKey struct {
+	sci uint64
+	an  uint8
+}
+
+// MACsecSATable holds the keys (SAKs) of the secure associations used to
+// decrypt MACsec frames, by the SCI and association number of the frames.
+// The GCM-AES-128 and GCM-AES-256 cipher suites are supported, not the
+// extended packet numbering ones.
+//
+// A MACsecSATable is safe for concurrent use.
+type MACsecSATable struct {
+	mu  sync.RWMutex
+	sas map[macsecSAKey]cipher.AEAD
+}
+
+// NewMACsecSATable returns an empty MACsecSATable.
+func NewMACsecSATable() *MACsecSATable {
+	return &MACsecSATable{sas: map[macsecSAKey]cipher.AEAD{}}
+}
+
+// Add adds the key of the secure association of sci and an, replacing any
+// key it had.
+func (t *MACsecSATable) Add(sci uint64, an uint8, key []byte) error {
+	if an > 3 {
+		return fmt.Errorf("invalid MACsec association number %d", an)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sas[macsecSAKey{sci, an}] = aead
+	return nil
+}
+
+// Remove removes the key of the secure association of sci and an.
+func (t *MACsecSATable) Remove(sci uint64, an uint8) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sas, macsecSAKey{sci, an})
+}
+
+var errMACsecNoSA = errors.New("no MACsec secure association")
+
+// Decrypt decrypts the secure data of m, sent from src to dst, with the key
+// of its SCI and association number; the SCI of frames without one is set
+// from src.  On success, it sets the Decrypted and EthernetType fields of m,
+// and its payload to the decrypted data.
+func (t *MACsecSATable) Decrypt(m *MACsec, dst, src net.HardwareAddr) error {
+	if !m.Encrypted {
+		return errors.New("MACsec frame not encrypted")
+	}
+	if len(dst) != 6 || len(src) != 6 {
+		return errors.New("MACsec frame without Ethernet addresses")
+	}
+	if !m.SCPresent {
+		m.SCI = macsecImplicitSCI(src)
+	}
+	t.mu.RLock()
+	aead := t.sas[macsecSAKey{m.SCI, m.AssociationNumber}]
+	t.mu.RUnlock()
+	if aead == nil {
+		return errMACsecNoSA
+	}
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, m.SCI)
+	binary.BigEndian.PutUint32(nonce[8:], m.PacketNumber)
+	// the addresses and the SecTAG are authenticated
+	aad := make([]byte, 0, 14+len(m.Contents))
+	aad = append(append(append(aad, dst...), src...), 0x88, 0xe5)
+	aad = append(aad, m.Contents...)
+	ciphertext := make([]byte, 0, len(m.SecureData)+len(m.ICV))
+	ciphertext = append(append(ciphertext, m.SecureData...), m.ICV...)
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return errors.New("MACsec frame authentication failed")
+	}
+	if len(plaintext) < 2 {
+		return errors.New("MACsec secure data without EthernetType")
+	}
+	m.Decrypted = true
+	m.EthernetType = EthernetType(binary.BigEndian.Uint16(plaintext))
+	m.Payload = plaintext[2:]
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+var (
+	testMACsecDst = net.HardwareAddr{0x02, 0, 0, 0, 0, 2}
+	testMACsecSrc = net.HardwareAddr{0x02, 0, 0, 0, 0, 1}
+	testMACsecSCI = uint64(0x0200000000010001)
+	testMACsecKey = bytes.Repeat([]byte{0x11}, 16)
+)
+
+// macsecTestFrame returns an Ethernet frame with an explicit SCI, the
+// secure data encrypted if key is set.
+func macsecTestFrame(t *testing.T, an uint8, pn uint32, key []byte, inner []byte) []byte {
+	frame := append(append(append([]byte{}, testMACsecDst...), testMACsecSrc...), 0x88, 0xe5)
+	tci := 0x20 | an
+	if key != nil {
+		tci |= 0x0c
+	}
+	frame = append(frame, tci, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(frame[16:], pn)
+	binary.BigEndian.PutUint64(frame[20:], testMACsecSCI)
+	plaintext := append([]byte{0x08, 0x00}, inner...)
+	if key == nil {
+		// integrity only; the ICV isn't checked
+		return append(append(frame, plaintext...), make([]byte, 16)...)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, testMACsecSCI)
+	binary.BigEndian.PutUint32(nonce[8:], pn)
+	return aead.Seal(frame, nonce, plaintext, frame)
+}
+
+func TestMACsec(t *testing.T) {
+	inner, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 53, DstPort: 53}).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(macsecTestFrame(t, 1, 7, nil, inner), LinkTypeEthernet, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeMACsec, LayerTypeIPv4, LayerTypeUDP}, t)
+	m := p.Layer(LayerTypeMACsec).(*MACsec)
+	if m.Encrypted || !m.SCPresent || m.AssociationNumber != 1 || m.PacketNumber != 7 || m.SCI != testMACsecSCI || m.SCIPort() != 1 || m.SCIAddress().String() != testMACsecSrc.String() || len(m.ICV) != 16 {
+		t.Errorf("unexpected SecTAG %#v", m)
+	}
+
+	// encrypted frames are decoded further with their key
+	frame := macsecTestFrame(t, 2, 8, testMACsecKey, inner)
+	p = gopacket.NewPacket(frame, LinkTypeEthernet, gopacket.Default)
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeMACsec}, t)
+	MACsecDecryption = NewMACsecSATable()
+	defer func() { MACsecDecryption = nil }()
+	if err := MACsecDecryption.Add(testMACsecSCI, 2, testMACsecKey); err != nil {
+		t.Fatal(err)
+	}
+	p = gopacket.NewPacket(frame, LinkTypeEthernet, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeMACsec, LayerTypeIPv4, LayerTypeUDP}, t)
+	if m := p.Layer(LayerTypeMACsec).(*MACsec); !m.Encrypted || !m.Decrypted || m.EthernetType != EthernetTypeIPv4 {
+		t.Errorf("unexpected decrypted frame %#v", m)
+	}
+
+	// a frame modified
+	frame[len(frame)-20] ^= 1
+	p = gopacket.NewPacket(frame, LinkTypeEthernet, gopacket.Default)
+	if p.ErrorLayer() == nil {
+		t.Error("no error for a modified frame")
+	}
+
+	for _, data := range [][]byte{
+		{0x2c, 0, 0, 0, 0},
+		{0x80, 0, 0, 0, 0, 1},
+		{0x08, 0, 0, 0, 0, 1},
+		append([]byte{0x20, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1}, make([]byte, 17)...),
+		append([]byte{0x00, 10, 0, 0, 0, 1}, make([]byte, 20)...),
+	} {
+		if err := (&MACsec{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"strings"
+)
+
+// Multicast DNS (RFC 6762) reuses the top bit of the class of questions to
+// request unicast responses, and of resource records to flush caches.
+const (
+	mdnsClassTopBit DNSClass = 0x8000
+	mdnsClassMask   DNSClass = 0x7fff
+)
+
+// MDNSServiceEnumeration is the name queried to enumerate the service types
+// of a domain, see RFC 6763, section 9.
+const MDNSServiceEnumeration = "_services._dns-sd._udp"
+
+// UnicastResponse reports whether the QU bit of a mDNS question is set,
+// requesting a unicast response.
+func (q *DNSQuestion) UnicastResponse() bool {
+	return q.Class&mdnsClassTopBit != 0
+}
+
+// MDNSClass returns the class of a mDNS question without the QU bit.
+func (q *DNSQuestion) MDNSClass() DNSClass {
+	return q.Class & mdnsClassMask
+}
+
+// CacheFlush reports whether the cache-flush bit of a mDNS resource record is
+// set, announcing that the record set is unique.
+func (rr *DNSResourceRecord) CacheFlush() bool {
+	return rr.Class&mdnsClassTopBit != 0
+}
+
+// MDNSClass returns the class of a mDNS resource record without the
+// cache-flush bit.
+func (rr *DNSResourceRecord) MDNSClass() DNSClass {
+	return rr.Class & mdnsClassMask
+}
+
+// MDNSService is a service instance discovered from DNS-SD records, see RFC
+// 6763.  Names have no trailing dot, and fields whose records were not in
+// the message are left empty.
+type MDNSService struct {
+	// Instance is the user-visible instance name, e.g. "Office Printer"
+	Instance string
+	// Service is the service type, e.g. "_ipp._tcp"
+	Service string
+	// Domain is e.g. "local"
+	Domain string
+	// Subtypes lists the subtypes the instance was announced with
+	Subtypes []string
+
+	// From the SRV record
+	Host             string
+	Port             uint16
+	Priority, Weight uint16
+
+	// Text holds the attributes of the TXT record.  Keys are lower-cased,
+	// and attributes without a value have an empty value.
+	Text map[string]string
+	// IPs are the addresses of Host found in A and AAAA records
+	IPs []net.IP
+	// TTL is the smallest TTL of the records of the instance, a TTL of 0
+	// announcing that the service is going away
+	TTL uint32
+}
+
+// Name returns the full service instance name.
+func (s *MDNSService) Name() string {
+	return s.Instance + "." + s.Service + "." + s.Domain
+}
+
+// splitMDNSServiceType splits a service type name such as "_ipp._tcp.local"
+// or "_printer._sub._http._tcp.local" into the service, domain and subtype.
+func splitMDNSServiceType(name string) (service, domain, subtype string, ok bool) {
+	if i := strings.Index(name, "._sub."); i >= 0 {
+		subtype, name = name[:i], name[i+len("._sub."):]
+	}
+	labels := strings.SplitN(name, ".", 3)
+	if len(labels) != 3 || !strings.HasPrefix(labels[0], "_") {
+		return "", "", "", false
+	}
+	proto := strings.ToLower(labels[1])
+	if proto != "_tcp" && proto != "_udp" {
+		return "", "", "", false
+	}
+	return labels[0] + "." + labels[1], labels[2], subtype, true
+}
+
+// mdnsRecords returns all the records of the message.
+func (d *DNS) mdnsRecords() []*DNSResourceRecord {
+	var rrs []*DNSResourceRecord
+	for _, section := range [][]DNSResourceRecord{d.Answers, d.Authorities, d.Additionals} {
+		for i := range section {
+			rrs = append(rrs, &section[i])
+		}
+	}
+	return rrs
+}
+
+// MDNSServiceTypes returns the service types, such as "_ipp._tcp.local",
+// answering a service type enumeration.
+func (d *DNS) MDNSServiceTypes() []string {
+	var types []string
+	for _, rr := range d.mdnsRecords() {
+		if rr.Type != DNSTypePTR {
+			continue
+		}
+		name := string(rr.Name)
+		if !strings.HasPrefix(strings.ToLower(name), MDNSServiceEnumeration+".") {
+			continue
+		}
+		types = append(types, string(rr.PTR))
+	}
+	return types
+}
+
+// MDNSServices gathers the PTR, SRV, TXT, A and AAAA records of the message
+// into service instances.  Instances are found from PTR records pointing to
+// them and from SRV records of service instance names.
+func (d *DNS) MDNSServices() []MDNSService {
+	rrs := d.mdnsRecords()
+	var services []MDNSService
+	index := map[string]int{}
+	get := func(instance, service, domain string, rr *DNSResourceRecord) *MDNSService {
+		s := MDNSService{Instance: instance, Service: service, Domain: domain, TTL: rr.TTL}
+		key := strings.ToLower(s.Name())
+		if i, ok := index[key]; ok {
+			if rr.TTL < services[i].TTL {
+				services[i].TTL = rr.TTL
+			}
+			return &services[i]
+		}
+		index[key] = len(services)
+		services = append(services, s)
+		return &services[len(services)-1]
+	}
+	lookup := func(name string) *MDNSService {
+		if i, ok := index[strings.ToLower(name)]; ok {
+			return &services[i]
+		}
+		return nil
+	}
+
+	// Instances pointed to by PTR records
+	for _, rr := range rrs {
+		if rr.Type != DNSTypePTR {
+			continue
+		}
+		service, domain, subtype, ok := splitMDNSServiceType(string(rr.Name))
+		if !ok {
+			continue
+		}
+		suffix := "." + service + "." + domain
+		target := string(rr.PTR)
+		if len(target) <= len(suffix) || !strings.EqualFold(target[len(target)-len(suffix):], suffix) {
+			continue
+		}
+		s := get(target[:len(target)-len(suffix)], service, domain, rr)
+		if subtype != "" {
+			s.Subtypes = append(s.Subtypes, subtype)
+		}
+	}
+
+	// Instances only announced by SRV records
+	for _, rr := range rrs {
+		if rr.Type != DNSTypeSRV || lookup(string(rr.Name)) != nil {
+			continue
+		}
+		name := string(rr.Name)
+		// The instance name is the first label, as its dots can't be told
+		// apart from label separators once decoded.
+		i := strings.IndexByte(name, '.')
+		if i < 0 {
+			continue
+		}
+		if service, domain, subtype, ok := splitMDNSServiceType(name[i+1:]); ok && subtype == "" {
+			get(name[:i], service, domain, rr)
+		}
+	}
+
+	for _, rr := range rrs {
+		s := lookup(string(rr.Name))
+		if s == nil || rr.Type != DNSTypeSRV && rr.Type != DNSTypeTXT {
+			continue
+		}
+		if rr.TTL < s.TTL {
+			s.TTL = rr.TTL
+		}
+		switch rr.Type {
+		case DNSTypeSRV:
+			s.Host = string(rr.SRV.Name)
+			s.Port = rr.SRV.Port
+			s.Priority = rr.SRV.Priority
+			s.Weight = rr.SRV.Weight
+		case DNSTypeTXT:
+			s.Text = decodeMDNSText(rr.TXTs)
+		}
+	}
+
+	for i := range services {
+		s := &services[i]
+		if s.Host == "" {
+			continue
+		}
+		for _, rr := range rrs {
+			if (rr.Type == DNSTypeA || rr.Type == DNSTypeAAAA) && strings.EqualFold(string(rr.Name), s.Host) {
+				s.IPs = append(s.IPs, rr.IP)
+			}
+		}
+	}
+	return services
+}
+
+// decodeMDNSText decodes DNS-SD TXT attributes, keeping the first of
+// duplicated keys, see RFC 6763, section 6.4
+func decodeMDNSText(txts [][]byte) map[string]string {
+	text := map[string]string{}
+	for _, txt := range txts {
+		key, value := txt, []byte(nil)
+		if i := bytes.IndexByte(txt, '='); i >= 0 {
+			key, value = txt[:i], txt[i+1:]
+		}
+		if len(key) == 0 {
+			continue
+		}
+		k := strings.ToLower(string(key))
+		if _, ok := text[k]; !ok {
+			text[k] = string(value)
+		}
+	}
+	return text
+}
+
+// MDNSKnownAnswer reports whether the known answers of a mDNS query, in its
+// answer section, suppress the answer rr, that is whether it is listed with
+// at least half its TTL, see RFC 6762, section 7.1
+func (d *DNS) MDNSKnownAnswer(rr *DNSResourceRecord) bool {
+	if d.QR {
+		return false
+	}
+	for i := range d.Answers {
+		known := &d.Answers[i]
+		if known.TTL >= rr.TTL/2 && sameMDNSRecord(known, rr) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameMDNSRecord reports whether a and b hold the same data, ignoring their
+// TTL and cache-flush bit.
+func sameMDNSRecord(a, b *DNSResourceRecord) bool {
+	if a.Type != b.Type || a.MDNSClass() != b.MDNSClass() || !strings.EqualFold(string(a.Name), string(b.Name)) {
+		return false
+	}
+	switch a.Type {
+	case DNSTypeA, DNSTypeAAAA:
+		return a.IP.Equal(b.IP)
+	case DNSTypePTR:
+		return strings.EqualFold(string(a.PTR), string(b.PTR))
+	case DNSTypeCNAME:
+		return strings.EqualFold(string(a.CNAME), string(b.CNAME))
+	case DNSTypeSRV:
+		return a.SRV.Priority == b.SRV.Priority && a.SRV.Weight == b.SRV.Weight &&
+			a.SRV.Port == b.SRV.Port && strings.EqualFold(string(a.SRV.Name), string(b.SRV.Name))
+	case DNSTypeTXT:
+		if len(a.TXTs) != len(b.TXTs) {
+			return false
+		}
+		for i := range a.TXTs {
+			if !bytes.Equal(a.TXTs[i], b.TXTs[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return bytes.Equal(a.Data, b.Data)
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestMDNSServices(t *testing.T) {
+	flush := DNSClassIN | mdnsClassTopBit
+	response := &DNS{QR: true, AA: true, Answers: []DNSResourceRecord{
+		{Name: []byte("_services._dns-sd._udp.local"), Type: DNSTypePTR, Class: DNSClassIN, TTL: 4500, PTR: []byte("_ipp._tcp.local")},
+		{Name: []byte("_ipp._tcp.local"), Type: DNSTypePTR, Class: DNSClassIN, TTL: 4500, PTR: []byte("Office._ipp._tcp.local")},
+		{Name: []byte("_color._sub._ipp._tcp.local"), Type: DNSTypePTR, Class: DNSClassIN, TTL: 4500, PTR: []byte("Office._ipp._tcp.local")},
+	}, Additionals: []DNSResourceRecord{
+		{Name: []byte("Office._ipp._tcp.local"), Type: DNSTypeSRV, Class: flush, TTL: 120,
+			SRV: DNSSRV{Port: 631, Name: []byte("printer.local")}},
+		{Name: []byte("office._ipp._tcp.local"), Type: DNSTypeTXT, Class: flush, TTL: 4500,
+			TXTs: [][]byte{[]byte("txtvers=1"), []byte("Color"), []byte("ty=Laser"), []byte("TY=Other")}},
+		{Name: []byte("printer.local"), Type: DNSTypeA, Class: flush, TTL: 120, IP: net.IP{192, 168, 1, 20}},
+		{Name: []byte("Scanner._uscan._tcp.local"), Type: DNSTypeSRV, Class: flush, TTL: 0,
+			SRV: DNSSRV{Port: 8080, Name: []byte("printer.local")}},
+	}}
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, response); err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeDNS, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	d := p.Layer(LayerTypeDNS).(*DNS)
+
+	if !d.Additionals[0].CacheFlush() || d.Additionals[0].MDNSClass() != DNSClassIN || d.Answers[0].CacheFlush() {
+		t.Error("unexpected cache-flush bits")
+	}
+	if types := d.MDNSServiceTypes(); len(types) != 1 || types[0] != "_ipp._tcp.local" {
+		t.Errorf("unexpected service types %q", types)
+	}
+
+	services := d.MDNSServices()
+	if len(services) != 2 {
+		t.Fatalf("got %d services, want 2: %+v", len(services), services)
+	}
+	s := services[0]
+	if s.Name() != "Office._ipp._tcp.local" || s.Domain != "local" || len(s.Subtypes) != 1 || s.Subtypes[0] != "_color" {
+		t.Errorf("unexpected service %+v", s)
+	}
+	if s.Host != "printer.local" || s.Port != 631 || s.TTL != 120 || len(s.IPs) != 1 || !s.IPs[0].Equal(net.IP{192, 168, 1, 20}) {
+		t.Errorf("unexpected service location %+v", s)
+	}
+	if v, ok := s.Text["color"]; !ok || v != "" || s.Text["ty"] != "Laser" || s.Text["txtvers"] != "1" {
+		t.Errorf("unexpected TXT attributes %v", s.Text)
+	}
+	if s := services[1]; s.Instance != "Scanner" || s.Service != "_uscan._tcp" || s.Port != 8080 || s.TTL != 0 {
+		t.Errorf("unexpected SRV-only service %+v", s)
+	}
+
+	query := &DNS{
+		Questions: []DNSQuestion{{Name: []byte("_ipp._tcp.local"), Type: DNSTypePTR, Class: DNSClassIN | mdnsClassTopBit}},
+		Answers: []DNSResourceRecord{
+			{Name: []byte("_ipp._tcp.local"), Type: DNSTypePTR, Class: DNSClassIN, TTL: 3000, PTR: []byte("office._ipp._tcp.local")},
+		},
+	}
+	if !query.Questions[0].UnicastResponse() || query.Questions[0].MDNSClass() != DNSClassIN {
+		t.Error("unexpected QU bit")
+	}
+	if !query.MDNSKnownAnswer(&d.Answers[1]) {
+		t.Error("known answer not suppressed")
+	}
+	query.Answers[0].TTL = 2000
+	if query.MDNSKnownAnswer(&d.Answers[1]) {
+		t.Error("known answer with less than half the TTL suppressed")
+	}
+	if query.MDNSKnownAnswer(&d.Answers[2]) {
+		t.Error("unrelated answer suppressed")
+	}
+}
@@ -73,7 +73,8 @@ func (m *MLDv2MulticastListenerQueryMessage) DecodeFromBytes(data []byte, df gop
 
 	m.NumberOfSources = binary.BigEndian.Uint16(data[22:24])
 
-	var end int
+	end := 24
+	m.SourceAddresses = m.SourceAddresses[:0]
 	for i := uint16(0); i < m.NumberOfSources; i++ {
 		begin := 24 + (int(i) * 16)
 		end = begin + 16
@@ -85,6 +86,8 @@ func (m *MLDv2MulticastListenerQueryMessage) DecodeFromBytes(data []byte, df gop
 
 		m.SourceAddresses = append(m.SourceAddresses, data[begin:end])
 	}
+	m.Contents = data[:end]
+	m.Payload = data[end:]
 
 	return nil
 }
@@ -168,7 +171,7 @@ func (m *MLDv2MulticastListenerQueryMessage) String() string {
 	return fmt.Sprintf(
 		"Maximum Response Code: %#x (%dms), Multicast Address: %s, Suppress Routerside Processing: %t, QRV: %#x, QQIC: %#x (%ds), Number of Source Address: %d (actual: %d), Source Addresses: %s",
 		m.MaximumResponseCode,
-		m.MaximumResponseDelay(),
+		m.MaximumResponseDelay()/time.Millisecond,
 		m.MulticastAddress,
 		m.SuppressRoutersideProcessing,
 		m.QueriersRobustnessVariable,
@@ -199,7 +202,7 @@ func (m *MLDv2MulticastListenerQueryMessage) QQI() time.Duration {
 
 	exp := uint16(data) & 0x70 >> 4
 	mant := uint16(data) & 0x0F
-	return time.Second * time.Duration(mant|0x1000<<(exp+3))
+	return time.Second * (time.Duration(mant|0x10) << (exp + 3))
 }
 
 // SetQQI calculates and updates the Querier's Query Interval Code (QQIC)
@@ -218,6 +221,7 @@ func (m *MLDv2MulticastListenerQueryMessage) SetQQI(d time.Duration) error {
 	dms := d / time.Second
 	if dms < 128 {
 		m.QueriersQueryIntervalCode = uint8(dms)
+		return nil
 	}
 
 	if dms > 31744 { // mant=0xF, exp=0x7
@@ -236,7 +240,7 @@ func (m *MLDv2MulticastListenerQueryMessage) SetQQI(d time.Duration) error {
 	}
 
 	mant := uint8(0x000F & (value >> (exp + 3)))
-	sig := uint8(0x10)
+	sig := uint8(0x80)
 	m.QueriersQueryIntervalCode = sig | exp<<4 | mant
 
 	return nil
@@ -247,13 +251,13 @@ func (m *MLDv2MulticastListenerQueryMessage) SetQQI(d time.Duration) error {
 // https://tools.ietf.org/html/rfc3810#section-5.1.3
 func (m *MLDv2MulticastListenerQueryMessage) MaximumResponseDelay() time.Duration {
 	if m.MaximumResponseCode < 0x8000 {
-		return time.Duration(m.MaximumResponseCode)
+		return time.Millisecond * time.Duration(m.MaximumResponseCode)
 	}
 
 	exp := m.MaximumResponseCode & 0x7000 >> 12
 	mant := m.MaximumResponseCode & 0x0FFF
 
-	return time.Millisecond * time.Duration(mant|0x1000<<(exp+3))
+	return time.Millisecond * (time.Duration(mant|0x1000) << (exp + 3))
 }
 
 // SetMLDv2MaximumResponseDelay updates the Maximum Response Code according to
@@ -272,6 +276,7 @@ func (m *MLDv2MulticastListenerQueryMessage) SetMLDv2MaximumResponseDelay(d time
 
 	if dms < 32768 {
 		m.MaximumResponseCode = uint16(dms)
+		return nil
 	}
 
 	if dms > 4193280 { // mant=0xFFF, exp=0x7
@@ -280,7 +285,7 @@ func (m *MLDv2MulticastListenerQueryMessage) SetMLDv2MaximumResponseDelay(d time
 
 	value := uint32(dms) // ok, because 4193280 < math.MaxUint32
 	exp := uint8(7)
-	for mask := uint32(0x40000000); exp > 0; exp-- {
+	for mask := uint32(0x400000); exp > 0; exp-- {
 		if mask&value != 0 {
 			break
 		}
@@ -289,7 +294,7 @@ func (m *MLDv2MulticastListenerQueryMessage) SetMLDv2MaximumResponseDelay(d time
 	}
 
 	mant := uint16(0x00000FFF & (value >> (exp + 3)))
-	sig := uint16(0x1000)
+	sig := uint16(0x8000)
 	m.MaximumResponseCode = sig | uint16(exp)<<12 | mant
 	return nil
 }
@@ -317,6 +322,7 @@ func (m *MLDv2MulticastListenerReportMessage) DecodeFromBytes(data []byte, df go
 	m.NumberOfMulticastAddressRecords = binary.BigEndian.Uint16(data[2:4])
 
 	begin := 4
+	m.MulticastAddressRecords = m.MulticastAddressRecords[:0]
 	for i := uint16(0); i < m.NumberOfMulticastAddressRecords; i++ {
 		mar := MLDv2MulticastAddressRecord{}
 		read, err := mar.decode(data[begin:], df)
@@ -328,6 +334,8 @@ func (m *MLDv2MulticastListenerReportMessage) DecodeFromBytes(data []byte, df go
 
 		begin += read
 	}
+	m.Contents = data[:begin]
+	m.Payload = data[begin:]
 
 	return nil
 }
@@ -503,7 +511,9 @@ func (m *MLDv2MulticastAddressRecord) decode(data []byte, df gopacket.DecodeFeed
 			expectedLengthWithouAuxData)
 	}
 
-	m.AuxiliaryData = data[expectedLengthWithouAuxData:expectedTotalLength]
+	if m.AuxDataLen > 0 {
+		m.AuxiliaryData = data[expectedLengthWithouAuxData:expectedTotalLength]
+	}
 
 	return expectedTotalLength, nil
 }
@@ -553,7 +563,7 @@ func (m *MLDv2MulticastAddressRecord) serializeTo(b gopacket.SerializeBuffer, op
 func (m *MLDv2MulticastAddressRecord) serializeAuxiliaryDataTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
 	if remainder := len(m.AuxiliaryData) % 4; remainder != 0 {
 		zeroWord := []byte{0x0, 0x0, 0x0, 0x0}
-		m.AuxiliaryData = append(m.AuxiliaryData, zeroWord[:remainder]...)
+		m.AuxiliaryData = append(m.AuxiliaryData, zeroWord[remainder:]...)
 	}
 
 	if opts.FixLengths {
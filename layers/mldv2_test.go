@@ -7,7 +7,10 @@
 package layers
 
 import (
+	"net"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/gopacket"
 )
@@ -61,8 +64,12 @@ func TestPacketMulticastListenerQueryMessageV2(t *testing.T) {
 		LayerTypeIPv6HopByHop,
 		LayerTypeICMPv6,
 		LayerTypeMLDv2MulticastListenerQuery}, t)
-	// See https://github.com/google/gopacket/issues/517
-	// checkSerialization(p, t)
+	checkSerialization(p, t)
+
+	query := p.Layer(LayerTypeMLDv2MulticastListenerQuery).(*MLDv2MulticastListenerQueryMessage)
+	if query.MaximumResponseDelay() != 10*time.Second || query.QueriersRobustnessVariable != 2 || query.QQI() != 60*time.Second {
+		t.Errorf("MLDv2 query mismatch: %v", query)
+	}
 }
 
 // Adapted from https://github.com/the-tcpdump-group/tcpdump/blob/master/tests/icmpv6.pcap
@@ -132,6 +139,100 @@ func TestPacketMulticastListenerReportMessageV2(t *testing.T) {
 		LayerTypeIPv6HopByHop,
 		LayerTypeICMPv6,
 		LayerTypeMLDv2MulticastListenerReport}, t)
-	// See https://github.com/google/gopacket/issues/517
-	// checkSerialization(p, t)
+	checkSerialization(p, t)
+}
+
+func TestMLDv2RoundTrip(t *testing.T) {
+	src, dst := net.ParseIP("fe80::1"), net.ParseIP("ff02::16")
+	for _, test := range []struct {
+		typ ICMPv6TypeCode
+		mld interface {
+			gopacket.SerializableLayer
+			gopacket.DecodingLayer
+		}
+	}{
+		{
+			typ: CreateICMPv6TypeCode(ICMPv6TypeMLDv1MulticastListenerQueryMessage, 0),
+			mld: &MLDv2MulticastListenerQueryMessage{
+				MaximumResponseCode:          0x8123,
+				MulticastAddress:             net.ParseIP("ff3e::8000:1"),
+				SuppressRoutersideProcessing: true,
+				QueriersRobustnessVariable:   2,
+				QueriersQueryIntervalCode:    125,
+				NumberOfSources:              2,
+				SourceAddresses:              []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2")},
+			},
+		},
+		{
+			typ: CreateICMPv6TypeCode(ICMPv6TypeMLDv2MulticastListenerReportMessageV2, 0),
+			mld: &MLDv2MulticastListenerReportMessage{
+				NumberOfMulticastAddressRecords: 2,
+				MulticastAddressRecords: []MLDv2MulticastAddressRecord{
+					{
+						RecordType:       MLDv2MulticastAddressRecordTypeAllowNewSources,
+						N:                1,
+						MulticastAddress: net.ParseIP("ff3e::8000:1"),
+						SourceAddresses:  []net.IP{net.ParseIP("2001:db8::1")},
+					},
+					{
+						RecordType:       MLDv2MulticastAddressRecordTypeChangeToExcludeMode,
+						AuxDataLen:       1,
+						MulticastAddress: net.ParseIP("ff02::fb"),
+						AuxiliaryData:    []byte{1, 2, 3, 4},
+					},
+				},
+			},
+		},
+	} {
+		ip6 := &IPv6{Version: 6, HopLimit: 1, NextHeader: IPProtocolICMPv6, SrcIP: src, DstIP: dst}
+		icmp := &ICMPv6{TypeCode: test.typ}
+		icmp.SetNetworkLayerForChecksum(ip6)
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		if err := gopacket.SerializeLayers(buf, opts, ip6, icmp, test.mld); err != nil {
+			t.Fatal(err)
+		}
+
+		p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv6, testDecodeOptions)
+		if p.ErrorLayer() != nil {
+			t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+		}
+		lt := test.mld.CanDecode().(gopacket.LayerType)
+		checkLayers(p, []gopacket.LayerType{LayerTypeIPv6, LayerTypeICMPv6, lt}, t)
+		for _, r := range gopacket.VerifyChecksums(p) {
+			if r.Status != gopacket.ChecksumValid {
+				t.Errorf("%v checksum status %v: %v", r.LayerType, r.Status, r.Err)
+			}
+		}
+		got := p.Layer(lt)
+		switch want := test.mld.(type) {
+		case *MLDv2MulticastListenerQueryMessage:
+			want.BaseLayer = got.(*MLDv2MulticastListenerQueryMessage).BaseLayer
+		case *MLDv2MulticastListenerReportMessage:
+			want.BaseLayer = got.(*MLDv2MulticastListenerReportMessage).BaseLayer
+		}
+		if !reflect.DeepEqual(got, test.mld) {
+			t.Errorf("%v mismatch:\ngot  %#v\nwant %#v", lt, got, test.mld)
+		}
+	}
+}
+
+func TestMLDv2Codes(t *testing.T) {
+	m := &MLDv2MulticastListenerQueryMessage{}
+	for _, d := range []time.Duration{10 * time.Second, 32768 * time.Millisecond, 40960 * time.Millisecond, 4193280 * time.Millisecond} {
+		if err := m.SetMLDv2MaximumResponseDelay(d); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.MaximumResponseDelay(); got != d {
+			t.Errorf("maximum response delay %v encoded as %#x, decoded as %v", d, m.MaximumResponseCode, got)
+		}
+	}
+	for _, d := range []time.Duration{125 * time.Second, 128 * time.Second, 1024 * time.Second, 31744 * time.Second} {
+		if err := m.SetQQI(d); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.QQI(); got != d {
+			t.Errorf("QQI %v encoded as %#x, decoded as %v", d, m.QueriersQueryIntervalCode, got)
+		}
+	}
 }
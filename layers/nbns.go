@@ -0,0 +1,608 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/google/gopacket"
+)
+
+// NetBIOS name suffixes, the 16th byte of names, identifying the service
+// registering them.
+const (
+	NetBIOSSuffixWorkstation         uint8 = 0x00
+	NetBIOSSuffixMessenger           uint8 = 0x03
+	NetBIOSSuffixFileServer          uint8 = 0x20
+	NetBIOSSuffixDomainMasterBrowser uint8 = 0x1b
+	NetBIOSSuffixDomainControllers   uint8 = 0x1c
+	NetBIOSSuffixMasterBrowser       uint8 = 0x1d
+	NetBIOSSuffixBrowserElection     uint8 = 0x1e
+)
+
+// NetBIOSName is a NetBIOS name, see RFC 1001, section 14.
+type NetBIOSName struct {
+	// Name is up to 15 characters, without its space padding.  The wildcard
+	// name of node status queries is "*".
+	Name   string
+	Suffix uint8
+	// Scope is the optional NetBIOS scope, such as "example.com"
+	Scope string
+}
+
+// String returns the name in the usual NAME<xx> form.
+func (n NetBIOSName) String() string {
+	s := fmt.Sprintf("%s<%02x>", n.Name, n.Suffix)
+	if n.Scope != "" {
+		s += "." + n.Scope
+	}
+	return s
+}
+
+// bytes returns the 16 bytes of the name, padded with spaces, or zeros for
+// the wildcard name.
+func (n NetBIOSName) bytes() ([]byte, error) {
+	if len(n.Name) > 15 {
+		return nil, fmt.Errorf("NetBIOS name %q longer than 15 bytes", n.Name)
+	}
+	pad := byte(' ')
+	if n.Name == "*" {
+		pad = 0
+	}
+	b := bytes.Repeat([]byte{pad}, 16)
+	copy(b, n.Name)
+	b[15] = n.Suffix
+	return b, nil
+}
+
+func decodeNetBIOSNameBytes(b []byte) NetBIOSName {
+	name := string(b[:15])
+	if name[0] == '*' {
+		name = strings.TrimRight(name, "\x00")
+	}
+	return NetBIOSName{Name: strings.TrimRight(name, " "), Suffix: b[15]}
+}
+
+// decodeNetBIOSName decodes a first-level encoded name, see RFC 1001,
+// section 14.1, starting at offset with the labels of DNS names.
+func decodeNetBIOSName(data []byte, offset int) (NetBIOSName, int, error) {
+	var buffer []byte
+	encoded, end, err := decodeName(data, offset, &buffer, 1)
+	if err != nil {
+		return NetBIOSName{}, 0, err
+	}
+	scope := ""
+	if i := bytes.IndexByte(encoded, '.'); i >= 0 {
+		encoded, scope = encoded[:i], string(encoded[i+1:])
+	}
+	if len(encoded) != 32 {
+		return NetBIOSName{}, 0, fmt.Errorf("invalid NetBIOS name length %d", len(encoded))
+	}
+	var b [16]byte
+	for i := range b {
+		hi, lo := encoded[2*i]-'A', encoded[2*i+1]-'A'
+		if hi > 15 || lo > 15 {
+			return NetBIOSName{}, 0, errors.New("invalid NetBIOS name encoding")
+		}
+		b[i] = hi<<4 | lo
+	}
+	n := decodeNetBIOSNameBytes(b[:])
+	n.Scope = scope
+	return n, end, nil
+}
+
+// encodedName returns the first-level encoded name, with the scope, as
+// encoded by encodeName.
+func (n NetBIOSName) encodedName() ([]byte, error) {
+	b, err := n.bytes()
+	if err != nil {
+		return nil, err
+	}
+	encoded := make([]byte, 32, 33+len(n.Scope))
+	for i, c := range b {
+		encoded[2*i] = 'A' + c>>4
+		encoded[2*i+1] = 'A' + c&0xf
+	}
+	if n.Scope != "" {
+		encoded = append(encoded, '.')
+		encoded = append(encoded, n.Scope...)
+	}
+	return encoded, nil
+}
+
+// NBNSOpCode is the operation of a NetBIOS Name Service packet.
+type NBNSOpCode uint8
+
+// NBNSOpCode known values, see RFC 1002, section 4.2.1.1
+const (
+	NBNSOpCodeQuery                  NBNSOpCode = 0
+	NBNSOpCodeRegistration           NBNSOpCode = 5
+	NBNSOpCodeRelease                NBNSOpCode = 6
+	NBNSOpCodeWACK                   NBNSOpCode = 7
+	NBNSOpCodeRefresh                NBNSOpCode = 8
+	NBNSOpCodeMultiHomedRegistration NBNSOpCode = 15
+)
+
+func (o NBNSOpCode) String() string {
+	switch o {
+	case NBNSOpCodeQuery:
+		return "Query"
+	case NBNSOpCodeRegistration:
+		return "Registration"
+	case NBNSOpCodeRelease:
+		return "Release"
+	case NBNSOpCodeWACK:
+		return "WACK"
+	case NBNSOpCodeRefresh, 9:
+		return "Refresh"
+	case NBNSOpCodeMultiHomedRegistration:
+		return "MultiHomedRegistration"
+	}
+	return fmt.Sprintf("Unknown(%d)", uint8(o))
+}
+
+// NBNSResponseCode is the result of a NetBIOS Name Service request.
+type NBNSResponseCode uint8
+
+// NBNSResponseCode known values, see RFC 1002, section 4.2.6
+const (
+	NBNSResponseCodeNoError        NBNSResponseCode = 0
+	NBNSResponseCodeFormatError    NBNSResponseCode = 1
+	NBNSResponseCodeServerFailure  NBNSResponseCode = 2
+	NBNSResponseCodeNameError      NBNSResponseCode = 3
+	NBNSResponseCodeNotImplemented NBNSResponseCode = 4
+	NBNSResponseCodeRefused        NBNSResponseCode = 5
+	NBNSResponseCodeActive         NBNSResponseCode = 6
+	NBNSResponseCodeConflict       NBNSResponseCode = 7
+)
+
+func (r NBNSResponseCode) String() string {
+	switch r {
+	case NBNSResponseCodeNoError:
+		return "No Error"
+	case NBNSResponseCodeFormatError:
+		return "Format Error"
+	case NBNSResponseCodeServerFailure:
+		return "Server Failure"
+	case NBNSResponseCodeNameError:
+		return "Name Error"
+	case NBNSResponseCodeNotImplemented:
+		return "Not Implemented"
+	case NBNSResponseCodeRefused:
+		return "Refused"
+	case NBNSResponseCodeActive:
+		return "Active"
+	case NBNSResponseCodeConflict:
+		return "Conflict"
+	}
+	return fmt.Sprintf("Unknown(%d)", uint8(r))
+}
+
+// NBNSType is the type of NetBIOS Name Service questions and resource
+// records.
+type NBNSType uint16
+
+// NBNSType known values.
+const (
+	NBNSTypeA      NBNSType = 0x0001
+	NBNSTypeNS     NBNSType = 0x0002
+	NBNSTypeNULL   NBNSType = 0x000a
+	NBNSTypeNB     NBNSType = 0x0020
+	NBNSTypeNBSTAT NBNSType = 0x0021
+)
+
+func (t NBNSType) String() string {
+	switch t {
+	case NBNSTypeA:
+		return "A"
+	case NBNSTypeNS:
+		return "NS"
+	case NBNSTypeNULL:
+		return "NULL"
+	case NBNSTypeNB:
+		return "NB"
+	case NBNSTypeNBSTAT:
+		return "NBSTAT"
+	}
+	return fmt.Sprintf("Unknown(%d)", uint16(t))
+}
+
+// NBNSNodeType is the type of a NetBIOS node, see RFC 1001, section 10.
+type NBNSNodeType uint8
+
+// NBNSNodeType known values.
+const (
+	NBNSNodeTypeB NBNSNodeType = 0 // Broadcast
+	NBNSNodeTypeP NBNSNodeType = 1 // Point-to-point
+	NBNSNodeTypeM NBNSNodeType = 2 // Mixed
+	NBNSNodeTypeH NBNSNodeType = 3 // Hybrid
+)
+
+func (t NBNSNodeType) String() string {
+	switch t {
+	case NBNSNodeTypeB:
+		return "B"
+	case NBNSNodeTypeP:
+		return "P"
+	case NBNSNodeTypeM:
+		return "M"
+	case NBNSNodeTypeH:
+		return "H"
+	}
+	return fmt.Sprintf("Unknown(%d)", uint8(t))
+}
+
+// NBNSNameFlags are the flags of names in NB records and node status
+// responses, see RFC 1002, sections 4.2.2 and 4.2.18
+type NBNSNameFlags uint16
+
+// NBNSNameFlags known values.  The node type is in NBNSNameFlagsNodeType.
+const (
+	NBNSNameFlagsGroup      NBNSNameFlags = 0x8000
+	NBNSNameFlagsNodeType   NBNSNameFlags = 0x6000
+	NBNSNameFlagsDeregister NBNSNameFlags = 0x1000
+	NBNSNameFlagsConflict   NBNSNameFlags = 0x0800
+	NBNSNameFlagsActive     NBNSNameFlags = 0x0400
+	NBNSNameFlagsPermanent  NBNSNameFlags = 0x0200
+)
+
+const nbnsNameFlagsTypeShift = 13
+
+// Group reports whether the name is a group name.
+func (f NBNSNameFlags) Group() bool {
+	return f&NBNSNameFlagsGroup != 0
+}
+
+// NodeType returns the type of the node owning the name.
+func (f NBNSNameFlags) NodeType() NBNSNodeType {
+	return NBNSNodeType(f & NBNSNameFlagsNodeType >> nbnsNameFlagsTypeShift)
+}
+
+// NBNSAddress is an entry of NB records.
+type NBNSAddress struct {
+	Flags NBNSNameFlags
+	IP    net.IP
+}
+
+// NBNSNodeName is a name of a node status response.
+type NBNSNodeName struct {
+	Name  NetBIOSName
+	Flags NBNSNameFlags
+}
+
+// NBNSNodeStatus is the data of NBSTAT records, see RFC 1002, section
+// 4.2.18
+type NBNSNodeStatus struct {
+	Names []NBNSNodeName
+	// UnitID is usually the MAC address of the node
+	UnitID net.HardwareAddr
+	// Statistics holds the statistics following the unit ID
+	Statistics []byte
+}
+
+// NBNSQuestion is a question of a NetBIOS Name Service packet.
+type NBNSQuestion struct {
+	Name  NetBIOSName
+	Type  NBNSType
+	Class DNSClass
+}
+
+// NBNSResourceRecord is a resource record of a NetBIOS Name Service packet.
+type NBNSResourceRecord struct {
+	Name  NetBIOSName
+	Type  NBNSType
+	Class DNSClass
+	TTL   uint32
+
+	// RDATA Raw Values
+	DataLength uint16
+	Data       []byte
+
+	// RDATA Decoded Values
+	Addresses  []NBNSAddress  // NB records
+	NodeStatus NBNSNodeStatus // NBSTAT records
+	// WACKFlags are the flags of the request acknowledged by WACK responses
+	WACKFlags uint16
+}
+
+//  NBNS Header, see RFC 1002, section 4.2.1.1
+//  0  1  2  3  4  5  6  7  8  9  0  1  2  3  4  5
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                 NAME_TRN_ID                   |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  | R|   OPCODE  |AA|TC|RD|RA| 0| 0| B|   RCODE   |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                    QDCOUNT                    |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                    ANCOUNT                    |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                    NSCOUNT                    |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                    ARCOUNT                    |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+
+// NBNS contains data from a NetBIOS Name Service packet, see RFC 1002,
+// section 4.2
+type NBNS struct {
+	BaseLayer
+
+	ID        uint16
+	Response  bool
+	OpCode    NBNSOpCode
+	AA        bool // Authoritative answer
+	TC        bool // Truncated
+	RD        bool // Recursion desired
+	RA        bool // Recursion available
+	Broadcast bool
+
+	ResponseCode NBNSResponseCode
+	QDCount      uint16
+	ANCount      uint16
+	NSCount      uint16
+	ARCount      uint16
+
+	Questions   []NBNSQuestion
+	Answers     []NBNSResourceRecord
+	Authorities []NBNSResourceRecord
+	Additionals []NBNSResourceRecord
+}
+
+// LayerType returns LayerTypeNBNS.
+func (n *NBNS) LayerType() gopacket.LayerType { return LayerTypeNBNS }
+
+func decodeNBNS(data []byte, p gopacket.PacketBuilder) error {
+	n := &NBNS{}
+	err := n.DecodeFromBytes(data, p)
+	if err != nil {
+		return err
+	}
+	p.AddLayer(n)
+	p.SetApplicationLayer(n)
+	return nil
+}
+
+// DecodeFromBytes decodes the slice into the NBNS struct.
+func (n *NBNS) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 12 {
+		df.SetTruncated()
+		return errors.New("NBNS packet too short")
+	}
+	n.BaseLayer = BaseLayer{Contents: data}
+	n.ID = binary.BigEndian.Uint16(data[0:2])
+	n.Response = data[2]&0x80 != 0
+	n.OpCode = NBNSOpCode(data[2]>>3) & 0xf
+	n.AA = data[2]&0x04 != 0
+	n.TC = data[2]&0x02 != 0
+	n.RD = data[2]&0x01 != 0
+	n.RA = data[3]&0x80 != 0
+	n.Broadcast = data[3]&0x10 != 0
+	n.ResponseCode = NBNSResponseCode(data[3] & 0xf)
+	n.QDCount = binary.BigEndian.Uint16(data[4:6])
+	n.ANCount = binary.BigEndian.Uint16(data[6:8])
+	n.NSCount = binary.BigEndian.Uint16(data[8:10])
+	n.ARCount = binary.BigEndian.Uint16(data[10:12])
+
+	n.Questions = n.Questions[:0]
+	n.Answers = n.Answers[:0]
+	n.Authorities = n.Authorities[:0]
+	n.Additionals = n.Additionals[:0]
+
+	offset := 12
+	var err error
+	for i := 0; i < int(n.QDCount); i++ {
+		var q NBNSQuestion
+		if q.Name, offset, err = decodeNetBIOSName(data, offset); err != nil {
+			return err
+		}
+		if len(data) < offset+4 {
+			return errors.New("NBNS question too small")
+		}
+		q.Type = NBNSType(binary.BigEndian.Uint16(data[offset : offset+2]))
+		q.Class = DNSClass(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+		offset += 4
+		n.Questions = append(n.Questions, q)
+	}
+	for _, section := range []struct {
+		rrs   *[]NBNSResourceRecord
+		count uint16
+	}{{&n.Answers, n.ANCount}, {&n.Authorities, n.NSCount}, {&n.Additionals, n.ARCount}} {
+		for i := 0; i < int(section.count); i++ {
+			*section.rrs = append(*section.rrs, NBNSResourceRecord{})
+			rr := &(*section.rrs)[i]
+			if offset, err = rr.decode(data, offset, n.OpCode); err != nil {
+				*section.rrs = (*section.rrs)[:i]
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (rr *NBNSResourceRecord) decode(data []byte, offset int, op NBNSOpCode) (int, error) {
+	var err error
+	if rr.Name, offset, err = decodeNetBIOSName(data, offset); err != nil {
+		return 0, err
+	}
+	if len(data) < offset+10 {
+		return 0, errors.New("NBNS resource record too small")
+	}
+	rr.Type = NBNSType(binary.BigEndian.Uint16(data[offset : offset+2]))
+	rr.Class = DNSClass(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+	rr.TTL = binary.BigEndian.Uint32(data[offset+4 : offset+8])
+	rr.DataLength = binary.BigEndian.Uint16(data[offset+8 : offset+10])
+	end := offset + 10 + int(rr.DataLength)
+	if end > len(data) {
+		return 0, errors.New("NBNS resource record length exceeds packet")
+	}
+	rr.Data = data[offset+10 : end]
+
+	switch {
+	case op == NBNSOpCodeWACK:
+		if len(rr.Data) != 2 {
+			return 0, errors.New("invalid NBNS WACK length")
+		}
+		rr.WACKFlags = binary.BigEndian.Uint16(rr.Data)
+	case rr.Type == NBNSTypeNB:
+		if len(rr.Data)%6 != 0 {
+			return 0, errors.New("invalid NBNS NB record length")
+		}
+		for i := 0; i < len(rr.Data); i += 6 {
+			rr.Addresses = append(rr.Addresses, NBNSAddress{
+				Flags: NBNSNameFlags(binary.BigEndian.Uint16(rr.Data[i : i+2])),
+				IP:    net.IP(rr.Data[i+2 : i+6]),
+			})
+		}
+	case rr.Type == NBNSTypeNBSTAT:
+		if len(rr.Data) < 1 || len(rr.Data) < 1+18*int(rr.Data[0]) {
+			return 0, errors.New("NBNS node status too small")
+		}
+		i := 1
+		for ; i < 1+18*int(rr.Data[0]); i += 18 {
+			rr.NodeStatus.Names = append(rr.NodeStatus.Names, NBNSNodeName{
+				Name:  decodeNetBIOSNameBytes(rr.Data[i : i+16]),
+				Flags: NBNSNameFlags(binary.BigEndian.Uint16(rr.Data[i+16 : i+18])),
+			})
+		}
+		if len(rr.Data) >= i+6 {
+			rr.NodeStatus.UnitID = net.HardwareAddr(rr.Data[i : i+6])
+			rr.NodeStatus.Statistics = rr.Data[i+6:]
+		}
+	}
+	return end, nil
+}
+
+// CanDecode implements gopacket.DecodingLayer.
+func (n *NBNS) CanDecode() gopacket.LayerClass {
+	return LayerTypeNBNS
+}
+
+// NextLayerType implements gopacket.DecodingLayer.
+func (n *NBNS) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypePayload
+}
+
+// Payload returns nil.
+func (n *NBNS) Payload() []byte {
+	return nil
+}
+
+// data returns the RDATA of the record, encoding its decoded values unless
+// Data is set.
+func (rr *NBNSResourceRecord) data(op NBNSOpCode) ([]byte, error) {
+	if rr.Data != nil {
+		return rr.Data, nil
+	}
+	switch {
+	case op == NBNSOpCodeWACK:
+		b := make([]byte, 2)
+		binary.BigEndian.PutUint16(b, rr.WACKFlags)
+		return b, nil
+	case rr.Type == NBNSTypeNB:
+		b := make([]byte, 0, 6*len(rr.Addresses))
+		for _, a := range rr.Addresses {
+			ip := a.IP.To4()
+			if ip == nil {
+				return nil, fmt.Errorf("invalid NBNS address %v", a.IP)
+			}
+			b = append(b, byte(a.Flags>>8), byte(a.Flags))
+			b = append(b, ip...)
+		}
+		return b, nil
+	case rr.Type == NBNSTypeNBSTAT:
+		s := &rr.NodeStatus
+		if len(s.Names) > 255 {
+			return nil, errors.New("too many NBNS node status names")
+		}
+		b := []byte{byte(len(s.Names))}
+		for _, name := range s.Names {
+			nb, err := name.Name.bytes()
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, nb...)
+			b = append(b, byte(name.Flags>>8), byte(name.Flags))
+		}
+		unitID := make([]byte, 6)
+		copy(unitID, s.UnitID)
+		b = append(b, unitID...)
+		return append(b, s.Statistics...), nil
+	}
+	return nil, nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.  Decoded
+// record values are encoded for records without Data.
+func (n *NBNS) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	var body []byte
+	appendName := func(name NetBIOSName) error {
+		encoded, err := name.encodedName()
+		if err != nil {
+			return err
+		}
+		i := len(body)
+		body = append(body, make([]byte, nameSize(encoded))...)
+		encodeName(encoded, body, i)
+		return nil
+	}
+	for _, q := range n.Questions {
+		if err := appendName(q.Name); err != nil {
+			return err
+		}
+		body = append(body, byte(q.Type>>8), byte(q.Type), byte(q.Class>>8), byte(q.Class))
+	}
+	for _, section := range [][]NBNSResourceRecord{n.Answers, n.Authorities, n.Additionals} {
+		for i := range section {
+			rr := &section[i]
+			if err := appendName(rr.Name); err != nil {
+				return err
+			}
+			data, err := rr.data(n.OpCode)
+			if err != nil {
+				return err
+			}
+			if len(data) > 0xffff {
+				return errors.New("NBNS resource record too long")
+			}
+			if opts.FixLengths {
+				rr.DataLength = uint16(len(data))
+			}
+			var h [10]byte
+			binary.BigEndian.PutUint16(h[0:], uint16(rr.Type))
+			binary.BigEndian.PutUint16(h[2:], uint16(rr.Class))
+			binary.BigEndian.PutUint32(h[4:], rr.TTL)
+			binary.BigEndian.PutUint16(h[8:], uint16(len(data)))
+			body = append(body, h[:]...)
+			body = append(body, data...)
+		}
+	}
+
+	bytes, err := b.PrependBytes(12 + len(body))
+	if err != nil {
+		return err
+	}
+	if opts.FixLengths {
+		n.QDCount = uint16(len(n.Questions))
+		n.ANCount = uint16(len(n.Answers))
+		n.NSCount = uint16(len(n.Authorities))
+		n.ARCount = uint16(len(n.Additionals))
+	}
+	binary.BigEndian.PutUint16(bytes, n.ID)
+	bytes[2] = byte(b2i(n.Response)<<7 | int(n.OpCode&0xf)<<3 | b2i(n.AA)<<2 | b2i(n.TC)<<1 | b2i(n.RD))
+	bytes[3] = byte(b2i(n.RA)<<7 | b2i(n.Broadcast)<<4 | int(n.ResponseCode&0xf))
+	binary.BigEndian.PutUint16(bytes[4:], n.QDCount)
+	binary.BigEndian.PutUint16(bytes[6:], n.ANCount)
+	binary.BigEndian.PutUint16(bytes[8:], n.NSCount)
+	binary.BigEndian.PutUint16(bytes[10:], n.ARCount)
+	copy(bytes[12:], body)
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testNBNSQuery is a broadcast name query for WORKGROUP<1d>.
+var testNBNSQuery = []byte{
+	0x80, 0x03, 0x01, 0x10, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x20, 'F', 'H', 'E', 'P', 'F', 'C', 'E', 'L', 'E', 'H', 'F', 'C', 'E', 'P', 'F', 'F',
+	'F', 'A', 'C', 'A', 'C', 'A', 'C', 'A', 'C', 'A', 'C', 'A', 'C', 'A', 'B', 'N', 0x00,
+	0x00, 0x20, 0x00, 0x01,
+}
+
+func TestNBNSQuery(t *testing.T) {
+	ip := &IPv4{Version: 4, TTL: 128, Protocol: IPProtocolUDP, SrcIP: net.IP{192, 168, 1, 10}, DstIP: net.IP{192, 168, 1, 255}}
+	udp := &UDP{SrcPort: 137, DstPort: 137}
+	udp.SetNetworkLayerForChecksum(ip)
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, ip, udp, gopacket.Payload(testNBNSQuery)); err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv4, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeNBNS}, t)
+	n := p.Layer(LayerTypeNBNS).(*NBNS)
+	if n.ID != 0x8003 || n.Response || n.OpCode != NBNSOpCodeQuery || !n.RD || !n.Broadcast || n.QDCount != 1 {
+		t.Errorf("unexpected NBNS header %+v", n)
+	}
+	want := NetBIOSName{Name: "WORKGROUP", Suffix: NetBIOSSuffixMasterBrowser}
+	if len(n.Questions) != 1 || n.Questions[0].Name != want || n.Questions[0].Type != NBNSTypeNB || n.Questions[0].Class != DNSClassIN {
+		t.Errorf("unexpected NBNS questions %+v", n.Questions)
+	}
+	if got := n.Questions[0].Name.String(); got != "WORKGROUP<1d>" {
+		t.Errorf("unexpected name string %q", got)
+	}
+
+	out := gopacket.NewSerializeBuffer()
+	if err := n.SerializeTo(out, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), testNBNSQuery) {
+		t.Errorf("serialized query\n%x, want\n%x", out.Bytes(), testNBNSQuery)
+	}
+}
+
+func TestNBNSRecords(t *testing.T) {
+	unitID := net.HardwareAddr{0x00, 0x0c, 0x29, 0x01, 0x02, 0x03}
+
+	// Node status response
+	n := &NBNS{}
+	buf := gopacket.NewSerializeBuffer()
+	status := &NBNS{Response: true, Answers: []NBNSResourceRecord{{Name: NetBIOSName{Name: "*"}, Type: NBNSTypeNBSTAT, Class: DNSClassIN,
+		NodeStatus: NBNSNodeStatus{Names: []NBNSNodeName{{Name: NetBIOSName{Name: "FILESRV", Suffix: 0x20}, Flags: NBNSNameFlagsActive | 3<<13}}, UnitID: unitID}}}}
+	if err := status.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.DecodeFromBytes(buf.Bytes(), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	s := n.Answers[0].NodeStatus
+	if n.Answers[0].Name.Name != "*" || len(s.Names) != 1 || s.Names[0].Name.Name != "FILESRV" || s.Names[0].Name.Suffix != 0x20 {
+		t.Errorf("unexpected node status %+v", s)
+	}
+	if f := s.Names[0].Flags; f.Group() || f.NodeType() != NBNSNodeTypeH || f&NBNSNameFlagsActive == 0 {
+		t.Errorf("unexpected name flags %x", f)
+	}
+	if s.UnitID.String() != unitID.String() {
+		t.Errorf("unexpected unit ID %v", s.UnitID)
+	}
+
+	// Registration with a scope
+	reg := &NBNS{OpCode: NBNSOpCodeRegistration, Additionals: []NBNSResourceRecord{{
+		Name: NetBIOSName{Name: "FILESRV", Scope: "corp.example"}, Type: NBNSTypeNB, Class: DNSClassIN,
+		Addresses: []NBNSAddress{{Flags: NBNSNameFlagsGroup | 1<<13, IP: net.IP{10, 0, 0, 7}}},
+	}}}
+	buf.Clear()
+	if err := reg.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.DecodeFromBytes(buf.Bytes(), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	rr := n.Additionals[0]
+	if rr.Name.Scope != "corp.example" || len(rr.Addresses) != 1 || !rr.Addresses[0].IP.Equal(net.IP{10, 0, 0, 7}) ||
+		!rr.Addresses[0].Flags.Group() || rr.Addresses[0].Flags.NodeType() != NBNSNodeTypeP {
+		t.Errorf("unexpected registration record %+v", rr)
+	}
+
+	// WACK response
+	wack := &NBNS{Response: true, OpCode: NBNSOpCodeWACK, AA: true, Answers: []NBNSResourceRecord{{
+		Name: NetBIOSName{Name: "FILESRV"}, Type: NBNSTypeNB, Class: DNSClassIN, TTL: 2, WACKFlags: 0x2910,
+	}}}
+	buf.Clear()
+	if err := wack.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.DecodeFromBytes(buf.Bytes(), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if n.OpCode != NBNSOpCodeWACK || n.Answers[0].WACKFlags != 0x2910 || n.Answers[0].DataLength != 2 {
+		t.Errorf("unexpected WACK response %+v", n.Answers[0])
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// NBSSType is the type of a NetBIOS Session Service packet.
+type NBSSType uint8
+
+// NBSSType known values, see RFC 1002, section 4.3.1
+const (
+	NBSSTypeSessionMessage          NBSSType = 0x00
+	NBSSTypeSessionRequest          NBSSType = 0x81
+	NBSSTypePositiveSessionResponse NBSSType = 0x82
+	NBSSTypeNegativeSessionResponse NBSSType = 0x83
+	NBSSTypeRetargetSessionResponse NBSSType = 0x84
+	NBSSTypeSessionKeepAlive        NBSSType = 0x85
+)
+
+func (t NBSSType) String() string {
+	switch t {
+	case NBSSTypeSessionMessage:
+		return "SessionMessage"
+	case NBSSTypeSessionRequest:
+		return "SessionRequest"
+	case NBSSTypePositiveSessionResponse:
+		return "PositiveSessionResponse"
+	case NBSSTypeNegativeSessionResponse:
+		return "NegativeSessionResponse"
+	case NBSSTypeRetargetSessionResponse:
+		return "RetargetSessionResponse"
+	case NBSSTypeSessionKeepAlive:
+		return "SessionKeepAlive"
+	}
+	return fmt.Sprintf("Unknown(0x%02x)", uint8(t))
+}
+
+// NBSSErrorCode is the error code of negative session responses.
+type NBSSErrorCode uint8
+
+// NBSSErrorCode known values, see RFC 1002, section 4.3.4
+const (
+	NBSSErrorNotListeningOnCalledName   NBSSErrorCode = 0x80
+	NBSSErrorNotListeningForCallingName NBSSErrorCode = 0x81
+	NBSSErrorCalledNameNotPresent       NBSSErrorCode = 0x82
+	NBSSErrorInsufficientResources      NBSSErrorCode = 0x83
+	NBSSErrorUnspecified                NBSSErrorCode = 0x8f
+)
+
+func (e NBSSErrorCode) String() string {
+	switch e {
+	case NBSSErrorNotListeningOnCalledName:
+		return "Not listening on called name"
+	case NBSSErrorNotListeningForCallingName:
+		return "Not listening for calling name"
+	case NBSSErrorCalledNameNotPresent:
+		return "Called name not present"
+	case NBSSErrorInsufficientResources:
+		return "Called name present, but insufficient resources"
+	case NBSSErrorUnspecified:
+		return "Unspecified error"
+	}
+	return fmt.Sprintf("Unknown(0x%02x)", uint8(e))
+}
+
+//  NBSS Header
+//  0                   1                   2                   3
+//  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//  |      TYPE     |     FLAGS   |E|            LENGTH             |
+//  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+//
+// E extends LENGTH to 17 bits.
+
+// NBSS contains a NetBIOS Session Service packet, see RFC 1002, section 4.3.
+// The payload of session messages, typically SMB, is the layer payload.
+type NBSS struct {
+	BaseLayer
+	Type   NBSSType
+	Flags  uint8
+	Length uint32
+
+	// Session requests
+	CalledName, CallingName NetBIOSName
+	// Negative session responses
+	ErrorCode NBSSErrorCode
+	// Retarget session responses
+	RetargetIP   net.IP
+	RetargetPort uint16
+}
+
+// LayerType returns LayerTypeNBSS.
+func (n *NBSS) LayerType() gopacket.LayerType { return LayerTypeNBSS }
+
+func decodeNBSS(data []byte, p gopacket.PacketBuilder) error {
+	n := &NBSS{}
+	return decodingLayerDecoder(n, data, p)
+}
+
+// DecodeFromBytes decodes the slice into the NBSS struct.  Bytes following
+// the packet are ignored.
+func (n *NBSS) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 4 {
+		df.SetTruncated()
+		return errors.New("NBSS packet too short")
+	}
+	n.Type = NBSSType(data[0])
+	n.Flags = data[1]
+	n.Length = uint32(data[1]&1)<<16 | uint32(binary.BigEndian.Uint16(data[2:4]))
+	end := 4 + int(n.Length)
+	if end > len(data) {
+		if n.Type != NBSSTypeSessionMessage {
+			df.SetTruncated()
+			return errors.New("NBSS packet too short")
+		}
+		// Session messages are often split across segments
+		df.SetTruncated()
+		end = len(data)
+	}
+	n.BaseLayer = BaseLayer{Contents: data[:4], Payload: data[4:end]}
+	body := n.Payload
+
+	n.CalledName, n.CallingName = NetBIOSName{}, NetBIOSName{}
+	n.ErrorCode = 0
+	n.RetargetIP, n.RetargetPort = nil, 0
+	switch n.Type {
+	case NBSSTypeSessionRequest:
+		var offset int
+		var err error
+		if n.CalledName, offset, err = decodeNetBIOSName(body, 0); err != nil {
+			return err
+		}
+		if n.CallingName, _, err = decodeNetBIOSName(body, offset); err != nil {
+			return err
+		}
+	case NBSSTypeNegativeSessionResponse:
+		if len(body) != 1 {
+			return errors.New("invalid NBSS negative session response length")
+		}
+		n.ErrorCode = NBSSErrorCode(body[0])
+	case NBSSTypeRetargetSessionResponse:
+		if len(body) != 6 {
+			return errors.New("invalid NBSS retarget session response length")
+		}
+		n.RetargetIP = net.IP(body[0:4])
+		n.RetargetPort = binary.BigEndian.Uint16(body[4:6])
+	}
+	if n.Type != NBSSTypeSessionMessage {
+		n.Contents, n.Payload = data[:end], nil
+	}
+	return nil
+}
+
+// CanDecode implements gopacket.DecodingLayer.
+func (n *NBSS) CanDecode() gopacket.LayerClass {
+	return LayerTypeNBSS
+}
+
+// NextLayerType implements gopacket.DecodingLayer.
+func (n *NBSS) NextLayerType() gopacket.LayerType {
+	if n.Type == NBSSTypeSessionMessage && len(n.Payload) > 0 {
+		return gopacket.LayerTypePayload
+	}
+	return gopacket.LayerTypeZero
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.  The
+// payload of session messages is the already serialized payload.
+func (n *NBSS) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	var body []byte
+	switch n.Type {
+	case NBSSTypeSessionRequest:
+		for _, name := range []NetBIOSName{n.CalledName, n.CallingName} {
+			encoded, err := name.encodedName()
+			if err != nil {
+				return err
+			}
+			i := len(body)
+			body = append(body, make([]byte, nameSize(encoded))...)
+			encodeName(encoded, body, i)
+		}
+	case NBSSTypeNegativeSessionResponse:
+		body = []byte{byte(n.ErrorCode)}
+	case NBSSTypeRetargetSessionResponse:
+		ip := n.RetargetIP.To4()
+		if ip == nil {
+			return fmt.Errorf("invalid NBSS retarget address %v", n.RetargetIP)
+		}
+		body = append(append(body, ip...), byte(n.RetargetPort>>8), byte(n.RetargetPort))
+	}
+	length := len(body)
+	if n.Type == NBSSTypeSessionMessage {
+		length = len(b.Bytes())
+	}
+	if length > 0x1ffff {
+		return fmt.Errorf("NBSS packet length %d too long", length)
+	}
+	if body != nil {
+		bytes, err := b.PrependBytes(len(body))
+		if err != nil {
+			return err
+		}
+		copy(bytes, body)
+	}
+	if opts.FixLengths {
+		n.Length = uint32(length)
+		n.Flags = n.Flags&^1 | uint8(length>>16)
+	}
+	bytes, err := b.PrependBytes(4)
+	if err != nil {
+		return err
+	}
+	bytes[0] = byte(n.Type)
+	bytes[1] = n.Flags
+	binary.BigEndian.PutUint16(bytes[2:], uint16(n.Length))
+	return nil
+}
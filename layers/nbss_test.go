@@ -0,0 +1,77 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestNBSS(t *testing.T) {
+	ip := &IPv4{Version: 4, TTL: 64, Protocol: IPProtocolTCP, SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 7}}
+	tcp := &TCP{SrcPort: 49152, DstPort: 139, Seq: 1, ACK: true, PSH: true, Window: 1024}
+	tcp.SetNetworkLayerForChecksum(ip)
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	decodeOpts := gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true}
+
+	request := &NBSS{Type: NBSSTypeSessionRequest,
+		CalledName:  NetBIOSName{Name: "FILESRV", Suffix: NetBIOSSuffixFileServer},
+		CallingName: NetBIOSName{Name: "LAPTOP"},
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, opts, ip, tcp, request); err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv4, decodeOpts)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeTCP, LayerTypeNBSS}, t)
+	n := p.Layer(LayerTypeNBSS).(*NBSS)
+	if n.Type != NBSSTypeSessionRequest || n.Length != 68 || n.CalledName.String() != "FILESRV<20>" || n.CallingName.String() != "LAPTOP<00>" {
+		t.Errorf("unexpected session request %+v", n)
+	}
+
+	smb := gopacket.Payload([]byte{0xfe, 'S', 'M', 'B', 0x40, 0x00})
+	buf.Clear()
+	if err := gopacket.SerializeLayers(buf, opts, ip, tcp, &NBSS{Type: NBSSTypeSessionMessage}, smb); err != nil {
+		t.Fatal(err)
+	}
+	p = gopacket.NewPacket(buf.Bytes(), LayerTypeIPv4, decodeOpts)
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeTCP, LayerTypeNBSS, gopacket.LayerTypePayload}, t)
+	if n := p.Layer(LayerTypeNBSS).(*NBSS); n.Length != 6 || !bytes.Equal(n.Payload, smb) {
+		t.Errorf("unexpected session message %+v", n)
+	}
+
+	for _, c := range []struct {
+		data []byte
+		want NBSS
+	}{
+		{[]byte{0x83, 0x00, 0x00, 0x01, 0x82}, NBSS{Type: NBSSTypeNegativeSessionResponse, Length: 1, ErrorCode: NBSSErrorCalledNameNotPresent}},
+		{[]byte{0x84, 0x00, 0x00, 0x06, 10, 0, 0, 8, 0x00, 0x8b}, NBSS{Type: NBSSTypeRetargetSessionResponse, Length: 6, RetargetIP: net.IP{10, 0, 0, 8}, RetargetPort: 139}},
+		{[]byte{0x85, 0x00, 0x00, 0x00}, NBSS{Type: NBSSTypeSessionKeepAlive}},
+	} {
+		var n NBSS
+		if err := n.DecodeFromBytes(c.data, gopacket.NilDecodeFeedback); err != nil {
+			t.Fatal(err)
+		}
+		if n.Type != c.want.Type || n.Length != c.want.Length || n.ErrorCode != c.want.ErrorCode ||
+			!n.RetargetIP.Equal(c.want.RetargetIP) || n.RetargetPort != c.want.RetargetPort || len(n.Payload) != 0 {
+			t.Errorf("got %+v, want %+v", n, c.want)
+		}
+		buf.Clear()
+		if err := n.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), c.data) {
+			t.Errorf("serialized %x, want %x", buf.Bytes(), c.data)
+		}
+	}
+}
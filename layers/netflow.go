@@ -0,0 +1,604 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/google/gopacket"
+)
+
+// NetFlowV9FieldType is the type of a field of a NetFlow v9 template.
+type NetFlowV9FieldType uint16
+
+// NetFlowV9FieldType known values, see RFC 3954, section 8
+const (
+	NetFlowV9FieldInBytes           NetFlowV9FieldType = 1
+	NetFlowV9FieldInPackets         NetFlowV9FieldType = 2
+	NetFlowV9FieldFlows             NetFlowV9FieldType = 3
+	NetFlowV9FieldProtocol          NetFlowV9FieldType = 4
+	NetFlowV9FieldSrcTOS            NetFlowV9FieldType = 5
+	NetFlowV9FieldTCPFlags          NetFlowV9FieldType = 6
+	NetFlowV9FieldL4SrcPort         NetFlowV9FieldType = 7
+	NetFlowV9FieldIPv4SrcAddr       NetFlowV9FieldType = 8
+	NetFlowV9FieldSrcMask           NetFlowV9FieldType = 9
+	NetFlowV9FieldInputSNMP         NetFlowV9FieldType = 10
+	NetFlowV9FieldL4DstPort         NetFlowV9FieldType = 11
+	NetFlowV9FieldIPv4DstAddr       NetFlowV9FieldType = 12
+	NetFlowV9FieldDstMask           NetFlowV9FieldType = 13
+	NetFlowV9FieldOutputSNMP        NetFlowV9FieldType = 14
+	NetFlowV9FieldIPv4NextHop       NetFlowV9FieldType = 15
+	NetFlowV9FieldSrcAS             NetFlowV9FieldType = 16
+	NetFlowV9FieldDstAS             NetFlowV9FieldType = 17
+	NetFlowV9FieldBGPIPv4NextHop    NetFlowV9FieldType = 18
+	NetFlowV9FieldLastSwitched      NetFlowV9FieldType = 21
+	NetFlowV9FieldFirstSwitched     NetFlowV9FieldType = 22
+	NetFlowV9FieldOutBytes          NetFlowV9FieldType = 23
+	NetFlowV9FieldOutPackets        NetFlowV9FieldType = 24
+	NetFlowV9FieldIPv6SrcAddr       NetFlowV9FieldType = 27
+	NetFlowV9FieldIPv6DstAddr       NetFlowV9FieldType = 28
+	NetFlowV9FieldIPv6SrcMask       NetFlowV9FieldType = 29
+	NetFlowV9FieldIPv6DstMask       NetFlowV9FieldType = 30
+	NetFlowV9FieldIPv6FlowLabel     NetFlowV9FieldType = 31
+	NetFlowV9FieldICMPType          NetFlowV9FieldType = 32
+	NetFlowV9FieldSamplingInterval  NetFlowV9FieldType = 34
+	NetFlowV9FieldSamplingAlgorithm NetFlowV9FieldType = 35
+	NetFlowV9FieldEngineType        NetFlowV9FieldType = 38
+	NetFlowV9FieldEngineID          NetFlowV9FieldType = 39
+	NetFlowV9FieldInSrcMAC          NetFlowV9FieldType = 56
+	NetFlowV9FieldOutDstMAC         NetFlowV9FieldType = 57
+	NetFlowV9FieldSrcVLAN           NetFlowV9FieldType = 58
+	NetFlowV9FieldDstVLAN           NetFlowV9FieldType = 59
+	NetFlowV9FieldIPProtocolVersion NetFlowV9FieldType = 60
+	NetFlowV9FieldDirection         NetFlowV9FieldType = 61
+	NetFlowV9FieldIPv6NextHop       NetFlowV9FieldType = 62
+)
+
+func (t NetFlowV9FieldType) String() string {
+	switch t {
+	case NetFlowV9FieldInBytes:
+		return "IN_BYTES"
+	case NetFlowV9FieldInPackets:
+		return "IN_PKTS"
+	case NetFlowV9FieldFlows:
+		return "FLOWS"
+	case NetFlowV9FieldProtocol:
+		return "PROTOCOL"
+	case NetFlowV9FieldSrcTOS:
+		return "SRC_TOS"
+	case NetFlowV9FieldTCPFlags:
+		return "TCP_FLAGS"
+	case NetFlowV9FieldL4SrcPort:
+		return "L4_SRC_PORT"
+	case NetFlowV9FieldIPv4SrcAddr:
+		return "IPV4_SRC_ADDR"
+	case NetFlowV9FieldSrcMask:
+		return "SRC_MASK"
+	case NetFlowV9FieldInputSNMP:
+		return "INPUT_SNMP"
+	case NetFlowV9FieldL4DstPort:
+		return "L4_DST_PORT"
+	case NetFlowV9FieldIPv4DstAddr:
+		return "IPV4_DST_ADDR"
+	case NetFlowV9FieldDstMask:
+		return "DST_MASK"
+	case NetFlowV9FieldOutputSNMP:
+		return "OUTPUT_SNMP"
+	case NetFlowV9FieldIPv4NextHop:
+		return "IPV4_NEXT_HOP"
+	case NetFlowV9FieldSrcAS:
+		return "SRC_AS"
+	case NetFlowV9FieldDstAS:
+		return "DST_AS"
+	case NetFlowV9FieldBGPIPv4NextHop:
+		return "BGP_IPV4_NEXT_HOP"
+	case NetFlowV9FieldLastSwitched:
+		return "LAST_SWITCHED"
+	case NetFlowV9FieldFirstSwitched:
+		return "FIRST_SWITCHED"
+	case NetFlowV9FieldOutBytes:
+		return "OUT_BYTES"
+	case NetFlowV9FieldOutPackets:
+		return "OUT_PKTS"
+	case NetFlowV9FieldIPv6SrcAddr:
+		return "IPV6_SRC_ADDR"
+	case NetFlowV9FieldIPv6DstAddr:
+		return "IPV6_DST_ADDR"
+	case NetFlowV9FieldIPv6SrcMask:
+		return "IPV6_SRC_MASK"
+	case NetFlowV9FieldIPv6DstMask:
+		return "IPV6_DST_MASK"
+	case NetFlowV9FieldIPv6FlowLabel:
+		return "IPV6_FLOW_LABEL"
+	case NetFlowV9FieldICMPType:
+		return "ICMP_TYPE"
+	case NetFlowV9FieldSamplingInterval:
+		return "SAMPLING_INTERVAL"
+	case NetFlowV9FieldSamplingAlgorithm:
+		return "SAMPLING_ALGORITHM"
+	case NetFlowV9FieldEngineType:
+		return "ENGINE_TYPE"
+	case NetFlowV9FieldEngineID:
+		return "ENGINE_ID"
+	case NetFlowV9FieldInSrcMAC:
+		return "IN_SRC_MAC"
+	case NetFlowV9FieldOutDstMAC:
+		return "OUT_DST_MAC"
+	case NetFlowV9FieldSrcVLAN:
+		return "SRC_VLAN"
+	case NetFlowV9FieldDstVLAN:
+		return "DST_VLAN"
+	case NetFlowV9FieldIPProtocolVersion:
+		return "IP_PROTOCOL_VERSION"
+	case NetFlowV9FieldDirection:
+		return "DIRECTION"
+	case NetFlowV9FieldIPv6NextHop:
+		return "IPV6_NEXT_HOP"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint16(t))
+	}
+}
+
+// NetFlowV9ScopeType is the type of a scope field of a NetFlow v9 options
+// template.
+type NetFlowV9ScopeType uint16
+
+// NetFlowV9ScopeType known values, see RFC 3954, section 6.1
+const (
+	NetFlowV9ScopeSystem    NetFlowV9ScopeType = 1
+	NetFlowV9ScopeInterface NetFlowV9ScopeType = 2
+	NetFlowV9ScopeLineCard  NetFlowV9ScopeType = 3
+	NetFlowV9ScopeCache     NetFlowV9ScopeType = 4
+	NetFlowV9ScopeTemplate  NetFlowV9ScopeType = 5
+)
+
+func (t NetFlowV9ScopeType) String() string {
+	switch t {
+	case NetFlowV9ScopeSystem:
+		return "System"
+	case NetFlowV9ScopeInterface:
+		return "Interface"
+	case NetFlowV9ScopeLineCard:
+		return "Line Card"
+	case NetFlowV9ScopeCache:
+		return "Cache"
+	case NetFlowV9ScopeTemplate:
+		return "Template"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint16(t))
+	}
+}
+
+// NetFlow v9 flowset IDs of template flowsets, data flowsets having the ID
+// of their template, from 256.
+const (
+	NetFlowV9TemplateFlowSetID        = 0
+	NetFlowV9OptionsTemplateFlowSetID = 1
+	netflowV9MinDataFlowSetID         = 256
+)
+
+// NetFlow lengths
+const (
+	netflowV5HeaderLength = 24
+	netflowV5RecordLength = 48
+	netflowV9HeaderLength = 20
+)
+
+// NetFlowV5Record is a flow record of a NetFlow v5 export packet.
+type NetFlowV5Record struct {
+	SrcAddr         net.IP
+	DstAddr         net.IP
+	NextHop         net.IP
+	InputInterface  uint16 // SNMP index
+	OutputInterface uint16 // SNMP index
+	Packets         uint32
+	Octets          uint32
+	// First and Last are the system uptime in milliseconds at the start
+	// and end of the flow.
+	First    uint32
+	Last     uint32
+	SrcPort  uint16
+	DstPort  uint16
+	TCPFlags uint8 // Cumulative OR of the TCP flags
+	Protocol IPProtocol
+	TOS      uint8
+	SrcAS    uint16
+	DstAS    uint16
+	SrcMask  uint8
+	DstMask  uint8
+}
+
+// NetFlowV9Field is a field specifier of a NetFlow v9 template.  The Type
+// of scope fields is a NetFlowV9ScopeType.
+type NetFlowV9Field struct {
+	Type   NetFlowV9FieldType
+	Length uint16
+}
+
+// NetFlowV9Template is a NetFlow v9 template or options template, telling
+// the fields of the records of the data flowsets with its ID.  The fields
+// of the records of options templates are their ScopeFields followed by
+// their Fields.
+type NetFlowV9Template struct {
+	ID          uint16
+	Options     bool
+	ScopeFields []NetFlowV9Field
+	Fields      []NetFlowV9Field
+}
+
+// recordLength returns the length of the records of the template.
+func (t *NetFlowV9Template) recordLength() int {
+	n := 0
+	for _, f := range t.ScopeFields {
+		n += int(f.Length)
+	}
+	for _, f := range t.Fields {
+		n += int(f.Length)
+	}
+	return n
+}
+
+// NetFlowV9FieldValue is the value of a field of a NetFlow v9 data record.
+type NetFlowV9FieldValue struct {
+	Type  NetFlowV9FieldType // A NetFlowV9ScopeType if Scope is set
+	Scope bool
+	Value []byte
+}
+
+// Uint returns the value as an unsigned integer, for values of up to 8
+// bytes.
+func (v NetFlowV9FieldValue) Uint() uint64 {
+	var n uint64
+	for _, b := range v.Value {
+		n = n<<8 | uint64(b)
+	}
+	return n
+}
+
+// NetFlowV9Record is a record of a NetFlow v9 data flowset, holding the
+// values of the fields of its template.
+type NetFlowV9Record struct {
+	Values []NetFlowV9FieldValue
+}
+
+// Value returns the value of the first non scope field of type t, or nil if
+// the record has none.
+func (r *NetFlowV9Record) Value(t NetFlowV9FieldType) []byte {
+	for _, v := range r.Values {
+		if v.Type == t && !v.Scope {
+			return v.Value
+		}
+	}
+	return nil
+}
+
+// NetFlowV9FlowSet is a flowset of a NetFlow v9 export packet.  Template
+// flowsets are decoded into Templates, and data flowsets into Records if
+// their template is known.  Data holds the flowset after its header.
+type NetFlowV9FlowSet struct {
+	ID        uint16
+	Length    uint16
+	Templates []NetFlowV9Template
+	Records   []NetFlowV9Record
+	Data      []byte
+}
+
+// NetFlowV9TemplateCache stores the templates of NetFlow v9 exporters,
+// needed to decode data flowsets sent after their templates.  Template IDs
+// are scoped by the source ID of the exporter, the exporters themselves
+// being told apart by using a cache per exporter address.
+type NetFlowV9TemplateCache interface {
+	// Template returns the template of source sourceID with ID id, or
+	// nil if unknown.
+	Template(sourceID uint32, id uint16) *NetFlowV9Template
+	// SetTemplate stores the template t of source sourceID, replacing
+	// the one with the same ID.
+	SetTemplate(sourceID uint32, t *NetFlowV9Template)
+}
+
+type netflowV9TemplateKey struct {
+	sourceID uint32
+	id       uint16
+}
+
+type netflowV9TemplateCache struct {
+	mu        sync.RWMutex
+	templates map[netflowV9TemplateKey]*NetFlowV9Template
+}
+
+// NewNetFlowV9TemplateCache returns an empty NetFlowV9TemplateCache, which
+// may be used by several goroutines.
+func NewNetFlowV9TemplateCache() NetFlowV9TemplateCache {
+	return &netflowV9TemplateCache{templates: make(map[netflowV9TemplateKey]*NetFlowV9Template)}
+}
+
+func (c *netflowV9TemplateCache) Template(sourceID uint32, id uint16) *NetFlowV9Template {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.templates[netflowV9TemplateKey{sourceID, id}]
+}
+
+func (c *netflowV9TemplateCache) SetTemplate(sourceID uint32, t *NetFlowV9Template) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.templates[netflowV9TemplateKey{sourceID, t.ID}] = t
+}
+
+// NetFlow is a Cisco NetFlow export packet, either version 5 or version 9,
+// see RFC 3954.
+//
+// Version 9 data flowsets are decoded with the templates of Templates,
+// which are updated by the template flowsets decoded, so that a
+// DecodingLayer keeping Templates across packets decodes the data of
+// templates sent in previous packets.  If Templates is nil, only the
+// templates of the packet itself are used.
+type NetFlow struct {
+	BaseLayer
+	Version        uint16
+	Count          uint16 // Records, including templates for version 9
+	SysUptime      uint32 // Milliseconds
+	UnixSecs       uint32
+	UnixNsecs      uint32 // Version 5 only
+	SequenceNumber uint32 // Of the flows for version 5, of the packets for version 9
+
+	// EngineType, EngineID, SamplingInterval and V5Records are used by
+	// version 5.  SamplingInterval includes the sampling mode, in its 2
+	// most significant bits.
+	EngineType       uint8
+	EngineID         uint8
+	SamplingInterval uint16
+	V5Records        []NetFlowV5Record
+
+	// SourceID and FlowSets are used by version 9.
+	SourceID uint32
+	FlowSets []NetFlowV9FlowSet
+
+	Templates NetFlowV9TemplateCache
+}
+
+// LayerType returns LayerTypeNetFlow.
+func (n *NetFlow) LayerType() gopacket.LayerType { return LayerTypeNetFlow }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (n *NetFlow) CanDecode() gopacket.LayerClass { return LayerTypeNetFlow }
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (n *NetFlow) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+
+// Payload returns nil, since NetFlow packets do not carry a payload.
+func (n *NetFlow) Payload() []byte { return nil }
+
+func decodeNetFlow(data []byte, p gopacket.PacketBuilder) error {
+	n := &NetFlow{}
+	if err := n.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(n)
+	p.SetApplicationLayer(n)
+	return nil
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (n *NetFlow) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 4 {
+		df.SetTruncated()
+		return errors.New("NetFlow header too short")
+	}
+	*n = NetFlow{
+		Version:   binary.BigEndian.Uint16(data[0:2]),
+		Count:     binary.BigEndian.Uint16(data[2:4]),
+		V5Records: n.V5Records[:0],
+		FlowSets:  n.FlowSets[:0],
+		Templates: n.Templates,
+	}
+	var err error
+	switch n.Version {
+	case 5:
+		err = n.decodeV5(data, df)
+	case 9:
+		err = n.decodeV9(data, df)
+	default:
+		err = fmt.Errorf("unsupported NetFlow version %d", n.Version)
+	}
+	if err != nil {
+		return err
+	}
+	n.Contents = data
+	return nil
+}
+
+func (n *NetFlow) decodeV5(data []byte, df gopacket.DecodeFeedback) error {
+	length := netflowV5HeaderLength + int(n.Count)*netflowV5RecordLength
+	if len(data) < length {
+		df.SetTruncated()
+		return fmt.Errorf("NetFlow v5 packet too short for %d records", n.Count)
+	}
+	n.SysUptime = binary.BigEndian.Uint32(data[4:8])
+	n.UnixSecs = binary.BigEndian.Uint32(data[8:12])
+	n.UnixNsecs = binary.BigEndian.Uint32(data[12:16])
+	n.SequenceNumber = binary.BigEndian.Uint32(data[16:20])
+	n.EngineType = data[20]
+	n.EngineID = data[21]
+	n.SamplingInterval = binary.BigEndian.Uint16(data[22:24])
+	for r := data[netflowV5HeaderLength:length]; len(r) > 0; r = r[netflowV5RecordLength:] {
+		n.V5Records = append(n.V5Records, NetFlowV5Record{
+			SrcAddr:         net.IP(r[0:4]),
+			DstAddr:         net.IP(r[4:8]),
+			NextHop:         net.IP(r[8:12]),
+			InputInterface:  binary.BigEndian.Uint16(r[12:14]),
+			OutputInterface: binary.BigEndian.Uint16(r[14:16]),
+			Packets:         binary.BigEndian.Uint32(r[16:20]),
+			Octets:          binary.BigEndian.Uint32(r[20:24]),
+			First:           binary.BigEndian.Uint32(r[24:28]),
+			Last:            binary.BigEndian.Uint32(r[28:32]),
+			SrcPort:         binary.BigEndian.Uint16(r[32:34]),
+			DstPort:         binary.BigEndian.Uint16(r[34:36]),
+			TCPFlags:        r[37],
+			Protocol:        IPProtocol(r[38]),
+			TOS:             r[39],
+			SrcAS:           binary.BigEndian.Uint16(r[40:42]),
+			DstAS:           binary.BigEndian.Uint16(r[42:44]),
+			SrcMask:         r[44],
+			DstMask:         r[45],
+		})
+	}
+	return nil
+}
+
+func (n *NetFlow) decodeV9(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < netflowV9HeaderLength {
+		df.SetTruncated()
+		return errors.New("NetFlow v9 header too short")
+	}
+	n.SysUptime = binary.BigEndian.Uint32(data[4:8])
+	n.UnixSecs = binary.BigEndian.Uint32(data[8:12])
+	n.SequenceNumber = binary.BigEndian.Uint32(data[12:16])
+	n.SourceID = binary.BigEndian.Uint32(data[16:20])
+
+	// Templates of this packet, used if there is no cache
+	var local map[uint16]*NetFlowV9Template
+	template := func(id uint16) *NetFlowV9Template {
+		if n.Templates != nil {
+			return n.Templates.Template(n.SourceID, id)
+		}
+		return local[id]
+	}
+
+	for sets := data[netflowV9HeaderLength:]; len(sets) > 0; {
+		if len(sets) < 4 {
+			df.SetTruncated()
+			return errors.New("NetFlow v9 flowset header too short")
+		}
+		fs := NetFlowV9FlowSet{
+			ID:     binary.BigEndian.Uint16(sets[0:2]),
+			Length: binary.BigEndian.Uint16(sets[2:4]),
+		}
+		if fs.Length < 4 || int(fs.Length) > len(sets) {
+			df.SetTruncated()
+			return fmt.Errorf("invalid NetFlow v9 flowset %d length %d", fs.ID, fs.Length)
+		}
+		fs.Data = sets[4:fs.Length]
+		sets = sets[fs.Length:]
+
+		var err error
+		switch {
+		case fs.ID == NetFlowV9TemplateFlowSetID:
+			fs.Templates, err = decodeNetFlowV9Templates(fs.Data)
+		case fs.ID == NetFlowV9OptionsTemplateFlowSetID:
+			fs.Templates, err = decodeNetFlowV9OptionsTemplates(fs.Data)
+		case fs.ID >= netflowV9MinDataFlowSetID:
+			if t := template(fs.ID); t != nil {
+				fs.Records = decodeNetFlowV9Records(fs.Data, t)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		for i := range fs.Templates {
+			t := &fs.Templates[i]
+			if n.Templates != nil {
+				n.Templates.SetTemplate(n.SourceID, t)
+				continue
+			}
+			if local == nil {
+				local = make(map[uint16]*NetFlowV9Template)
+			}
+			local[t.ID] = t
+		}
+		n.FlowSets = append(n.FlowSets, fs)
+	}
+	return nil
+}
+
+// decodeNetFlowV9Fields decodes count field specifiers of data.
+func decodeNetFlowV9Fields(data []byte, count int) []NetFlowV9Field {
+	fields := make([]NetFlowV9Field, count)
+	for i := range fields {
+		fields[i] = NetFlowV9Field{
+			Type:   NetFlowV9FieldType(binary.BigEndian.Uint16(data[4*i : 4*i+2])),
+			Length: binary.BigEndian.Uint16(data[4*i+2 : 4*i+4]),
+		}
+	}
+	return fields
+}
+
+func decodeNetFlowV9Templates(data []byte) ([]NetFlowV9Template, error) {
+	var templates []NetFlowV9Template
+	// Anything shorter than a template header is padding
+	for len(data) >= 4 {
+		t := NetFlowV9Template{ID: binary.BigEndian.Uint16(data[0:2])}
+		count := int(binary.BigEndian.Uint16(data[2:4]))
+		if t.ID < netflowV9MinDataFlowSetID {
+			if t.ID == 0 && count == 0 {
+				break
+			}
+			return nil, fmt.Errorf("invalid NetFlow v9 template ID %d", t.ID)
+		}
+		if len(data) < 4+4*count {
+			return nil, fmt.Errorf("NetFlow v9 template %d truncated", t.ID)
+		}
+		t.Fields = decodeNetFlowV9Fields(data[4:], count)
+		templates = append(templates, t)
+		data = data[4+4*count:]
+	}
+	return templates, nil
+}
+
+func decodeNetFlowV9OptionsTemplates(data []byte) ([]NetFlowV9Template, error) {
+	var templates []NetFlowV9Template
+	// Anything shorter than an options template header is padding
+	for len(data) >= 6 {
+		t := NetFlowV9Template{ID: binary.BigEndian.Uint16(data[0:2]), Options: true}
+		scopeLength := int(binary.BigEndian.Uint16(data[2:4]))
+		optionLength := int(binary.BigEndian.Uint16(data[4:6]))
+		if t.ID < netflowV9MinDataFlowSetID {
+			if t.ID == 0 && scopeLength == 0 && optionLength == 0 {
+				break
+			}
+			return nil, fmt.Errorf("invalid NetFlow v9 options template ID %d", t.ID)
+		}
+		if scopeLength%4 != 0 || optionLength%4 != 0 {
+			return nil, fmt.Errorf("invalid NetFlow v9 options template %d lengths %d and %d", t.ID, scopeLength, optionLength)
+		}
+		if len(data) < 6+scopeLength+optionLength {
+			return nil, fmt.Errorf("NetFlow v9 options template %d truncated", t.ID)
+		}
+		t.ScopeFields = decodeNetFlowV9Fields(data[6:], scopeLength/4)
+		t.Fields = decodeNetFlowV9Fields(data[6+scopeLength:], optionLength/4)
+		templates = append(templates, t)
+		data = data[6+scopeLength+optionLength:]
+	}
+	return templates, nil
+}
+
+// decodeNetFlowV9Records decodes the records of a data flowset with
+// template t, ignoring the padding ending the flowset.
+func decodeNetFlowV9Records(data []byte, t *NetFlowV9Template) []NetFlowV9Record {
+	length := t.recordLength()
+	if length == 0 {
+		return nil
+	}
+	var records []NetFlowV9Record
+	for ; len(data) >= length; data = data[length:] {
+		r := NetFlowV9Record{Values: make([]NetFlowV9FieldValue, 0, len(t.ScopeFields)+len(t.Fields))}
+		v := data
+		for _, f := range t.ScopeFields {
+			r.Values = append(r.Values, NetFlowV9FieldValue{Type: f.Type, Scope: true, Value: v[:f.Length]})
+			v = v[f.Length:]
+		}
+		for _, f := range t.Fields {
+			r.Values = append(r.Values, NetFlowV9FieldValue{Type: f.Type, Value: v[:f.Length]})
+			v = v[f.Length:]
+		}
+		records = append(records, r)
+	}
+	return records
+}
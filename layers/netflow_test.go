@@ -0,0 +1,262 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketNetFlowV5 is a NetFlow v5 export packet with two flow records.
+var testPacketNetFlowV5 = []byte{
+	0x00, 0x0c, 0x29, 0xcc, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xcc, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x94, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x35, 0xe4, 0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8,
+	0x00, 0x64, 0xc3, 0x50, 0x08, 0x07, 0x00, 0x80, 0x69, 0x63, 0x00, 0x05, 0x00, 0x02, 0x00, 0x01,
+	0xe2, 0x40, 0x65, 0x53, 0xf1, 0x00, 0x00, 0x00, 0x13, 0x88, 0x00, 0x00, 0x00, 0x64, 0x00, 0x01,
+	0x40, 0x64, 0x0a, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x01, 0x01, 0xc0, 0xa8, 0x00, 0xfe, 0x00, 0x01,
+	0x00, 0x02, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x05, 0xdc, 0x00, 0x00, 0x03, 0xe8, 0x00, 0x00,
+	0x07, 0xd0, 0x9c, 0x40, 0x01, 0xbb, 0x00, 0x1b, 0x06, 0x00, 0xfd, 0xe9, 0xfd, 0xea, 0x18, 0x18,
+	0x00, 0x00, 0x0a, 0x00, 0x00, 0x02, 0x08, 0x08, 0x08, 0x08, 0xc0, 0xa8, 0x00, 0xfe, 0x00, 0x01,
+	0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x4c, 0x00, 0x00, 0x05, 0xdc, 0x00, 0x00,
+	0x05, 0xdc, 0xcf, 0x08, 0x00, 0x35, 0x00, 0x00, 0x11, 0x00, 0x00, 0x00, 0x3b, 0x41, 0x18, 0x00,
+	0x00, 0x00,
+}
+
+// testPacketNetFlowV9 is a NetFlow v9 export packet with a template, an
+// options template, and their data flowsets, the data of the first template
+// having two records.
+var testPacketNetFlowV9 = []byte{
+	0x00, 0x0c, 0x29, 0xcc, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xcc, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0xa4, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x35, 0xd4, 0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8,
+	0x00, 0x64, 0xc3, 0x50, 0x08, 0x07, 0x00, 0x90, 0x6b, 0xbd, 0x00, 0x09, 0x00, 0x05, 0x00, 0x01,
+	0xe2, 0x40, 0x65, 0x53, 0xf1, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x2a, 0x00, 0x00,
+	0x00, 0x1c, 0x01, 0x00, 0x00, 0x05, 0x00, 0x08, 0x00, 0x04, 0x00, 0x0c, 0x00, 0x04, 0x00, 0x04,
+	0x00, 0x01, 0x00, 0x02, 0x00, 0x04, 0x00, 0x01, 0x00, 0x08, 0x00, 0x01, 0x00, 0x18, 0x01, 0x01,
+	0x00, 0x04, 0x00, 0x08, 0x00, 0x01, 0x00, 0x04, 0x00, 0x22, 0x00, 0x04, 0x00, 0x23, 0x00, 0x01,
+	0x00, 0x00, 0x01, 0x00, 0x00, 0x30, 0x0a, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x01, 0x01, 0x06, 0x00,
+	0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0xdc, 0x0a, 0x00, 0x00, 0x02, 0x0a,
+	0x00, 0x01, 0x02, 0x11, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x98,
+	0x00, 0x00, 0x01, 0x01, 0x00, 0x10, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x64, 0x02, 0x00,
+	0x00, 0x00,
+}
+
+// testPacketNetFlowV9Data is a NetFlow v9 export packet following
+// testPacketNetFlowV9, with data of its template.
+var testPacketNetFlowV9Data = []byte{
+	0x00, 0x0c, 0x29, 0xcc, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xcc, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x4c, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x36, 0x2c, 0xc0, 0xa8, 0x00, 0x01, 0xc0, 0xa8,
+	0x00, 0x64, 0xc3, 0x50, 0x08, 0x07, 0x00, 0x38, 0x0d, 0x82, 0x00, 0x09, 0x00, 0x01, 0x00, 0x01,
+	0xe2, 0x40, 0x65, 0x53, 0xf1, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x2a, 0x01, 0x00,
+	0x00, 0x1c, 0x0a, 0x00, 0x00, 0x03, 0x0a, 0x00, 0x01, 0x03, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x54, 0x00, 0x00, 0x00,
+}
+
+func TestNetFlowV5(t *testing.T) {
+	p := gopacket.NewPacket(testPacketNetFlowV5, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeNetFlow}, t)
+	nf := p.ApplicationLayer().(*NetFlow)
+	want := &NetFlow{
+		BaseLayer:        BaseLayer{Contents: testPacketNetFlowV5[42:]},
+		Version:          5,
+		Count:            2,
+		SysUptime:        123456,
+		UnixSecs:         1700000000,
+		UnixNsecs:        5000,
+		SequenceNumber:   100,
+		EngineID:         1,
+		SamplingInterval: 0x4000 | 100,
+		V5Records: []NetFlowV5Record{
+			{
+				SrcAddr:         net.IP{10, 0, 0, 1},
+				DstAddr:         net.IP{10, 0, 1, 1},
+				NextHop:         net.IP{192, 168, 0, 254},
+				InputInterface:  1,
+				OutputInterface: 2,
+				Packets:         10,
+				Octets:          1500,
+				First:           1000,
+				Last:            2000,
+				SrcPort:         40000,
+				DstPort:         443,
+				TCPFlags:        0x1b,
+				Protocol:        IPProtocolTCP,
+				SrcAS:           65001,
+				DstAS:           65002,
+				SrcMask:         24,
+				DstMask:         24,
+			},
+			{
+				SrcAddr:         net.IP{10, 0, 0, 2},
+				DstAddr:         net.IP{8, 8, 8, 8},
+				NextHop:         net.IP{192, 168, 0, 254},
+				InputInterface:  1,
+				OutputInterface: 3,
+				Packets:         1,
+				Octets:          76,
+				First:           1500,
+				Last:            1500,
+				SrcPort:         53000,
+				DstPort:         53,
+				Protocol:        IPProtocolUDP,
+				DstAS:           15169,
+				SrcMask:         24,
+			},
+		},
+	}
+	if !reflect.DeepEqual(nf, want) {
+		t.Errorf("NetFlow mismatch:\ngot  %#v\nwant %#v", nf, want)
+	}
+}
+
+func TestNetFlowV9(t *testing.T) {
+	p := gopacket.NewPacket(testPacketNetFlowV9, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeNetFlow}, t)
+	nf := p.ApplicationLayer().(*NetFlow)
+	if nf.Version != 9 || nf.Count != 5 || nf.SequenceNumber != 1 || nf.SourceID != 42 || nf.SysUptime != 123456 {
+		t.Errorf("unexpected NetFlow v9 header %+v", nf)
+	}
+	if len(nf.FlowSets) != 4 {
+		t.Fatalf("got %d flowsets, want 4", len(nf.FlowSets))
+	}
+
+	wantTemplates := []NetFlowV9Template{
+		{
+			ID: 256,
+			Fields: []NetFlowV9Field{
+				{NetFlowV9FieldIPv4SrcAddr, 4},
+				{NetFlowV9FieldIPv4DstAddr, 4},
+				{NetFlowV9FieldProtocol, 1},
+				{NetFlowV9FieldInPackets, 4},
+				{NetFlowV9FieldInBytes, 8},
+			},
+		},
+	}
+	if fs := nf.FlowSets[0]; fs.ID != NetFlowV9TemplateFlowSetID || !reflect.DeepEqual(fs.Templates, wantTemplates) {
+		t.Errorf("template flowset mismatch:\ngot  %#v\nwant %#v", fs.Templates, wantTemplates)
+	}
+	wantOptions := []NetFlowV9Template{
+		{
+			ID:          257,
+			Options:     true,
+			ScopeFields: []NetFlowV9Field{{NetFlowV9FieldType(NetFlowV9ScopeSystem), 4}},
+			Fields: []NetFlowV9Field{
+				{NetFlowV9FieldSamplingInterval, 4},
+				{NetFlowV9FieldSamplingAlgorithm, 1},
+			},
+		},
+	}
+	if fs := nf.FlowSets[1]; fs.ID != NetFlowV9OptionsTemplateFlowSetID || !reflect.DeepEqual(fs.Templates, wantOptions) {
+		t.Errorf("options template flowset mismatch:\ngot  %#v\nwant %#v", fs.Templates, wantOptions)
+	}
+
+	data := nf.FlowSets[2]
+	if data.ID != 256 || len(data.Records) != 2 {
+		t.Fatalf("got data flowset %d with %d records, want 256 with 2", data.ID, len(data.Records))
+	}
+	r := data.Records[1]
+	if src := net.IP(r.Value(NetFlowV9FieldIPv4SrcAddr)); !src.Equal(net.IP{10, 0, 0, 2}) {
+		t.Errorf("got source address %v, want 10.0.0.2", src)
+	}
+	if proto := r.Value(NetFlowV9FieldProtocol); len(proto) != 1 || IPProtocol(proto[0]) != IPProtocolUDP {
+		t.Errorf("got protocol %v, want UDP", proto)
+	}
+	if bytes := r.Values[4].Uint(); r.Values[4].Type != NetFlowV9FieldInBytes || bytes != 152 {
+		t.Errorf("got %v %d, want IN_BYTES 152", r.Values[4].Type, bytes)
+	}
+	if r.Value(NetFlowV9FieldL4SrcPort) != nil {
+		t.Error("found a field missing from the template")
+	}
+
+	options := nf.FlowSets[3]
+	if len(options.Records) != 1 {
+		t.Fatalf("got %d options records, want 1", len(options.Records))
+	}
+	wantValues := []NetFlowV9FieldValue{
+		{Type: NetFlowV9FieldType(NetFlowV9ScopeSystem), Scope: true, Value: []byte{0, 0, 0, 1}},
+		{Type: NetFlowV9FieldSamplingInterval, Value: []byte{0, 0, 0, 100}},
+		{Type: NetFlowV9FieldSamplingAlgorithm, Value: []byte{2}},
+	}
+	if !reflect.DeepEqual(options.Records[0].Values, wantValues) {
+		t.Errorf("options record mismatch:\ngot  %#v\nwant %#v", options.Records[0].Values, wantValues)
+	}
+	// The scope field has type 1 too, which is not IN_BYTES
+	if options.Records[0].Value(NetFlowV9FieldInBytes) != nil {
+		t.Error("scope field returned as a non scope field")
+	}
+}
+
+func TestNetFlowV9TemplateCache(t *testing.T) {
+	// Without templates, data flowsets are left undecoded
+	var nf NetFlow
+	if err := nf.DecodeFromBytes(testPacketNetFlowV9Data[42:], gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if fs := nf.FlowSets[0]; fs.Records != nil || len(fs.Data) != 24 {
+		t.Errorf("unexpected flowset without template: %d records, %d bytes of data", len(fs.Records), len(fs.Data))
+	}
+
+	cache := NewNetFlowV9TemplateCache()
+	nf = NetFlow{Templates: cache}
+	if err := nf.DecodeFromBytes(testPacketNetFlowV9[42:], gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if err := nf.DecodeFromBytes(testPacketNetFlowV9Data[42:], gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if nf.Templates != cache {
+		t.Error("template cache not kept across packets")
+	}
+	fs := nf.FlowSets[0]
+	if len(fs.Records) != 1 {
+		t.Fatalf("got %d records with the cached template, want 1", len(fs.Records))
+	}
+	if dst := net.IP(fs.Records[0].Value(NetFlowV9FieldIPv4DstAddr)); !dst.Equal(net.IP{10, 0, 1, 3}) {
+		t.Errorf("got destination address %v, want 10.0.1.3", dst)
+	}
+	if cache.Template(42, 257) == nil || !cache.Template(42, 257).Options {
+		t.Error("options template not cached")
+	}
+	if cache.Template(43, 256) != nil {
+		t.Error("template of another source ID found")
+	}
+}
+
+func TestNetFlowDecodeInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		data   []byte
+		mutate func([]byte)
+	}{
+		{"truncated header", testPacketNetFlowV5[42:45], nil},
+		{"unsupported version", testPacketNetFlowV5[42:], func(b []byte) { b[1] = 7 }},
+		{"truncated v5 record", testPacketNetFlowV5[42 : len(testPacketNetFlowV5)-1], nil},
+		{"truncated v9 header", testPacketNetFlowV9[42:61], nil},
+		{"flowset length too long", testPacketNetFlowV9[42:], func(b []byte) { b[23] = 0xff }},
+		{"flowset length too short", testPacketNetFlowV9[42:], func(b []byte) { b[23] = 3 }},
+		{"template truncated", testPacketNetFlowV9[42:], func(b []byte) { b[27] = 6 }},
+		{"invalid template ID", testPacketNetFlowV9[42:], func(b []byte) { b[24] = 0 }},
+		{"invalid options lengths", testPacketNetFlowV9[42:], func(b []byte) { b[55] = 5 }},
+	} {
+		data := append([]byte{}, test.data...)
+		if test.mutate != nil {
+			test.mutate(data)
+		}
+		var nf NetFlow
+		if err := nf.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", test.name)
+		}
+	}
+}
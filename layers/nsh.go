@@ -0,0 +1,164 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/gopacket"
+)
+
+// NSH metadata types
+const (
+	NSHMDType1 uint8 = 1
+	NSHMDType2 uint8 = 2
+)
+
+// NSHContextHeader is a variable length context header of MD type 2.
+type NSHContextHeader struct {
+	Class uint16
+	Type  uint8
+	// Length is the length of the value, which is padded to 4 bytes
+	Length uint8
+	Value  []byte
+}
+
+// NSH is a Network Service Header, RFC 8300, carrying the service path of a
+// packet through service functions, and its metadata.
+type NSH struct {
+	BaseLayer
+	Version uint8
+	OAM     bool
+	TTL     uint8
+	// Length is the length of the header in 4 byte words
+	Length       uint8
+	MDType       uint8
+	NextProtocol VXLANGPENextProtocol
+	// ServicePathID and ServiceIndex are the service path header
+	ServicePathID uint32
+	ServiceIndex  uint8
+	// Context is the fixed length context of MD type 1
+	Context []byte
+	// ContextHeaders are the context headers of MD type 2
+	ContextHeaders []NSHContextHeader
+}
+
+// LayerType returns LayerTypeNSH.
+func (n *NSH) LayerType() gopacket.LayerType { return LayerTypeNSH }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (n *NSH) CanDecode() gopacket.LayerClass { return LayerTypeNSH }
+
+// NextLayerType returns the layer type of the payload.
+func (n *NSH) NextLayerType() gopacket.LayerType { return n.NextProtocol.LayerType() }
+
+// DecodeFromBytes decodes the slice into the NSH struct.
+func (n *NSH) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 8 {
+		df.SetTruncated()
+		return errors.New("NSH too short")
+	}
+	n.Version = data[0] >> 6
+	if n.Version != 0 {
+		return fmt.Errorf("unsupported NSH version %d", n.Version)
+	}
+	n.OAM = data[0]&0x20 != 0
+	n.TTL = (data[0]&0x0f)<<2 | data[1]>>6
+	n.Length = data[1] & 0x3f
+	n.MDType = data[2] & 0x0f
+	n.NextProtocol = VXLANGPENextProtocol(data[3])
+	n.ServicePathID = binary.BigEndian.Uint32(data[4:8]) >> 8
+	n.ServiceIndex = data[7]
+	n.Context, n.ContextHeaders = nil, nil
+	length := 4 * int(n.Length)
+	if length < 8 {
+		return fmt.Errorf("invalid NSH length %d", n.Length)
+	}
+	if len(data) < length {
+		df.SetTruncated()
+		return errors.New("NSH too short")
+	}
+	switch n.MDType {
+	case NSHMDType1:
+		if length != 24 {
+			return fmt.Errorf("invalid NSH length %d for MD type 1", n.Length)
+		}
+		n.Context = data[8:24]
+	case NSHMDType2:
+		for d := data[8:length]; len(d) > 0; {
+			if len(d) < 4 {
+				return errors.New("NSH context header too short")
+			}
+			h := NSHContextHeader{
+				Class:  binary.BigEndian.Uint16(d[0:2]),
+				Type:   d[2],
+				Length: d[3] & 0x7f,
+			}
+			padded := (int(h.Length) + 3) &^ 3
+			if len(d) < 4+padded {
+				return errors.New("NSH context header too short")
+			}
+			h.Value = d[4 : 4+int(h.Length)]
+			n.ContextHeaders = append(n.ContextHeaders, h)
+			d = d[4+padded:]
+		}
+	}
+	n.Contents = data[:length]
+	n.Payload = data[length:]
+	return nil
+}
+
+func decodeNSH(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&NSH{}, data, p)
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.  With
+// FixLengths, the Length of the header and of its context headers are set.
+func (n *NSH) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	var context []byte
+	switch n.MDType {
+	case NSHMDType1:
+		context = make([]byte, 16)
+		copy(context, n.Context)
+	case NSHMDType2:
+		for i := range n.ContextHeaders {
+			h := &n.ContextHeaders[i]
+			if opts.FixLengths {
+				h.Length = uint8(len(h.Value))
+			}
+			if h.Length > 0x7f {
+				return fmt.Errorf("NSH context header length %d too long", h.Length)
+			}
+			context = append(context, byte(h.Class>>8), byte(h.Class), h.Type, h.Length)
+			context = append(context, h.Value...)
+			context = append(context, make([]byte, (4-len(h.Value)%4)%4)...)
+		}
+	}
+	if opts.FixLengths {
+		n.Length = uint8((8 + len(context)) / 4)
+	}
+	if n.Length > 0x3f || n.TTL > 0x3f || n.ServicePathID >= 1<<24 {
+		return errors.New("NSH field out of range")
+	}
+	bytes, err := b.PrependBytes(8 + len(context))
+	if err != nil {
+		return err
+	}
+	bytes[0] = n.Version<<6 | n.TTL>>2
+	if n.OAM {
+		bytes[0] |= 0x20
+	}
+	bytes[1] = n.TTL<<6 | n.Length
+	bytes[2] = n.MDType & 0x0f
+	bytes[3] = uint8(n.NextProtocol)
+	binary.BigEndian.PutUint32(bytes[4:8], n.ServicePathID<<8|uint32(n.ServiceIndex))
+	copy(bytes[8:], context)
+	return nil
+}
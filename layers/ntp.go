@@ -247,8 +247,12 @@ type NTP struct {
 	ReceiveTimestamp   NTPTimestamp      // Local time (on server) that request arrived at server host.
 	TransmitTimestamp  NTPTimestamp      // Local time (on server) that request departed server host.
 
-	// FIX: This package should analyse the extension fields and represent the extension fields too.
-	ExtensionBytes []byte // Just put extensions in a byte slice.
+	// ExtensionBytes holds the bytes following the header.  They are
+	// decoded into Extensions and MAC when they are well formed, which are
+	// then serialized instead of ExtensionBytes.
+	ExtensionBytes []byte
+	Extensions     []NTPExtension // Extension fields, see RFC 7822.
+	MAC            *NTPMAC        // Message authentication code, if any.
 }
 
 //******************************************************************************
@@ -338,10 +342,14 @@ func (d *NTP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
 	d.ReceiveTimestamp = NTPTimestamp(binary.BigEndian.Uint64(data[32:40]))
 	d.TransmitTimestamp = NTPTimestamp(binary.BigEndian.Uint64(data[40:48]))
 
-	// This layer does not attempt to analyse the extension bytes.
-	// But if there are any, we'd like the user to know. So we just
-	// place them all in an ExtensionBytes field.
+	// The extension fields and MAC are decoded if they can be told apart,
+	// else they are only available in the ExtensionBytes field.  Control
+	// (6) and private (7) messages have no extension fields.
 	d.ExtensionBytes = data[48:]
+	d.Extensions, d.MAC = nil, nil
+	if d.Mode < 6 {
+		d.Extensions, d.MAC = decodeNTPExtensions(d.ExtensionBytes)
+	}
 
 	// Return no error.
 	return nil
@@ -375,11 +383,19 @@ func (d *NTP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOpt
 	binary.BigEndian.PutUint64(data[32:40], uint64(d.ReceiveTimestamp))
 	binary.BigEndian.PutUint64(data[40:48], uint64(d.TransmitTimestamp))
 
-	ex, err := b.AppendBytes(len(d.ExtensionBytes))
+	// Extensions and MAC take precedence over ExtensionBytes, so that
+	// decoded extension fields can be modified.
+	extensions := d.ExtensionBytes
+	if d.Extensions != nil || d.MAC != nil {
+		if extensions, err = encodeNTPExtensions(d.Extensions, d.MAC); err != nil {
+			return err
+		}
+	}
+	ex, err := b.AppendBytes(len(extensions))
 	if err != nil {
 		return err
 	}
-	copy(ex, d.ExtensionBytes)
+	copy(ex, extensions)
 
 	return nil
 }
@@ -0,0 +1,245 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// NTPExtensionType is the field type of NTP extension fields.
+type NTPExtensionType uint16
+
+// NTPExtensionType known values, see RFC 8915, section 5.7
+const (
+	NTPExtensionUniqueIdentifier     NTPExtensionType = 0x0104
+	NTPExtensionNTSCookie            NTPExtensionType = 0x0204
+	NTPExtensionNTSCookiePlaceholder NTPExtensionType = 0x0304
+	NTPExtensionNTSAuthenticator     NTPExtensionType = 0x0404
+)
+
+const ntpExtensionMinimumLength = 16
+
+func (t NTPExtensionType) String() string {
+	switch t {
+	case NTPExtensionUniqueIdentifier:
+		return "UniqueIdentifier"
+	case NTPExtensionNTSCookie:
+		return "NTSCookie"
+	case NTPExtensionNTSCookiePlaceholder:
+		return "NTSCookiePlaceholder"
+	case NTPExtensionNTSAuthenticator:
+		return "NTSAuthenticator"
+	}
+	return fmt.Sprintf("Unknown(0x%04x)", uint16(t))
+}
+
+// NTPExtension is an extension field of NTPv4 packets, see RFC 7822.
+// Value is padded with zeros to a multiple of 4 bytes, and to at least 12
+// bytes, when serialized.  The last extension field of packets without MAC
+// must be at least 28 bytes long, so that it can't be mistaken for a MAC.
+type NTPExtension struct {
+	Type  NTPExtensionType
+	Value []byte
+}
+
+// NTPMAC is the message authentication code ending NTP packets.  A MAC
+// with only a zero KeyID is a crypto-NAK.
+type NTPMAC struct {
+	KeyID  uint32
+	Digest []byte
+}
+
+// isNTPMACLength reports whether the last n bytes of a packet are a MAC:
+// a crypto-NAK, or a key identifier with a 128-bit (MD5, AES-CMAC) or
+// 160-bit (SHA-1) digest.
+func isNTPMACLength(n int) bool {
+	return n == 4 || n == 20 || n == 24
+}
+
+// decodeNTPExtensions decodes the extension fields and MAC following the
+// NTP header, returning nil for both unless all the bytes are decoded.
+func decodeNTPExtensions(data []byte) ([]NTPExtension, *NTPMAC) {
+	var extensions []NTPExtension
+	for len(data) > 0 {
+		if isNTPMACLength(len(data)) {
+			mac := &NTPMAC{KeyID: binary.BigEndian.Uint32(data[0:4]), Digest: data[4:]}
+			return extensions, mac
+		}
+		if len(data) < ntpExtensionMinimumLength {
+			return nil, nil
+		}
+		l := int(binary.BigEndian.Uint16(data[2:4]))
+		if l < ntpExtensionMinimumLength || l%4 != 0 || l > len(data) {
+			return nil, nil
+		}
+		extensions = append(extensions, NTPExtension{
+			Type:  NTPExtensionType(binary.BigEndian.Uint16(data[0:2])),
+			Value: data[4:l],
+		})
+		data = data[l:]
+	}
+	return extensions, nil
+}
+
+func encodeNTPExtensions(extensions []NTPExtension, mac *NTPMAC) ([]byte, error) {
+	var data []byte
+	for _, e := range extensions {
+		l := (4 + len(e.Value) + 3) &^ 3
+		if l < ntpExtensionMinimumLength {
+			l = ntpExtensionMinimumLength
+		}
+		if l > 0xffff {
+			return nil, fmt.Errorf("NTP extension field %v too long", e.Type)
+		}
+		field := make([]byte, l)
+		binary.BigEndian.PutUint16(field[0:2], uint16(e.Type))
+		binary.BigEndian.PutUint16(field[2:4], uint16(l))
+		copy(field[4:], e.Value)
+		data = append(data, field...)
+	}
+	if mac != nil {
+		if !isNTPMACLength(4 + len(mac.Digest)) {
+			return nil, fmt.Errorf("invalid NTP MAC digest length %d", len(mac.Digest))
+		}
+		data = append(data, byte(mac.KeyID>>24), byte(mac.KeyID>>16), byte(mac.KeyID>>8), byte(mac.KeyID))
+		data = append(data, mac.Digest...)
+	}
+	return data, nil
+}
+
+// Extension returns the first extension field of type t, or nil.
+func (d *NTP) Extension(t NTPExtensionType) *NTPExtension {
+	for i := range d.Extensions {
+		if d.Extensions[i].Type == t {
+			return &d.Extensions[i]
+		}
+	}
+	return nil
+}
+
+// NTSAuthenticator decodes the nonce and ciphertext of NTS Authenticator and
+// Encrypted Extension Fields extension fields, see RFC 8915, section 5.6
+func (e *NTPExtension) NTSAuthenticator() (nonce, ciphertext []byte, err error) {
+	if e.Type != NTPExtensionNTSAuthenticator || len(e.Value) < 4 {
+		return nil, nil, errors.New("invalid NTS authenticator extension field")
+	}
+	nonceLength := int(binary.BigEndian.Uint16(e.Value[0:2]))
+	ciphertextLength := int(binary.BigEndian.Uint16(e.Value[2:4]))
+	paddedNonce := (nonceLength + 3) &^ 3
+	if len(e.Value) < 4+paddedNonce+ciphertextLength {
+		return nil, nil, errors.New("NTS authenticator extension field too short")
+	}
+	nonce = e.Value[4 : 4+nonceLength]
+	ciphertext = e.Value[4+paddedNonce : 4+paddedNonce+ciphertextLength]
+	return nonce, ciphertext, nil
+}
+
+// NewNTSAuthenticator returns an NTS Authenticator and Encrypted Extension
+// Fields extension field holding nonce and ciphertext.
+func NewNTSAuthenticator(nonce, ciphertext []byte) (NTPExtension, error) {
+	if len(nonce) > 0xffff || len(ciphertext) > 0xffff {
+		return NTPExtension{}, errors.New("NTS nonce or ciphertext too long")
+	}
+	paddedNonce := (len(nonce) + 3) &^ 3
+	value := make([]byte, 4+paddedNonce+(len(ciphertext)+3)&^3)
+	binary.BigEndian.PutUint16(value[0:2], uint16(len(nonce)))
+	binary.BigEndian.PutUint16(value[2:4], uint16(len(ciphertext)))
+	copy(value[4:], nonce)
+	copy(value[4+paddedNonce:], ciphertext)
+	return NTPExtension{Type: NTPExtensionNTSAuthenticator, Value: value}, nil
+}
+
+// NTSKERecordType is the type of NTS Key Establishment records.
+type NTSKERecordType uint16
+
+// NTSKERecordType known values, see RFC 8915, section 7.6
+const (
+	NTSKERecordEndOfMessage             NTSKERecordType = 0
+	NTSKERecordNextProtocolNegotiation  NTSKERecordType = 1
+	NTSKERecordError                    NTSKERecordType = 2
+	NTSKERecordWarning                  NTSKERecordType = 3
+	NTSKERecordAEADAlgorithmNegotiation NTSKERecordType = 4
+	NTSKERecordNewCookie                NTSKERecordType = 5
+	NTSKERecordNTPv4ServerNegotiation   NTSKERecordType = 6
+	NTSKERecordNTPv4PortNegotiation     NTSKERecordType = 7
+)
+
+func (t NTSKERecordType) String() string {
+	switch t {
+	case NTSKERecordEndOfMessage:
+		return "EndOfMessage"
+	case NTSKERecordNextProtocolNegotiation:
+		return "NextProtocolNegotiation"
+	case NTSKERecordError:
+		return "Error"
+	case NTSKERecordWarning:
+		return "Warning"
+	case NTSKERecordAEADAlgorithmNegotiation:
+		return "AEADAlgorithmNegotiation"
+	case NTSKERecordNewCookie:
+		return "NewCookie"
+	case NTSKERecordNTPv4ServerNegotiation:
+		return "NTPv4ServerNegotiation"
+	case NTSKERecordNTPv4PortNegotiation:
+		return "NTPv4PortNegotiation"
+	}
+	return fmt.Sprintf("Unknown(%d)", uint16(t))
+}
+
+// NTSKERecord is a record of the NTS Key Establishment protocol, exchanged
+// over TLS before NTS protected NTP packets, see RFC 8915, section 4.
+type NTSKERecord struct {
+	Critical bool
+	Type     NTSKERecordType
+	Body     []byte
+}
+
+// DecodeNTSKERecords decodes the NTS-KE records of a decrypted NTS-KE
+// message, up to and including its End of Message record.
+func DecodeNTSKERecords(data []byte) ([]NTSKERecord, error) {
+	var records []NTSKERecord
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return records, errors.New("NTS-KE record too short")
+		}
+		l := int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+l {
+			return records, errors.New("NTS-KE record body too short")
+		}
+		r := NTSKERecord{
+			Critical: data[0]&0x80 != 0,
+			Type:     NTSKERecordType(binary.BigEndian.Uint16(data[0:2]) & 0x7fff),
+			Body:     data[4 : 4+l],
+		}
+		records = append(records, r)
+		data = data[4+l:]
+		if r.Type == NTSKERecordEndOfMessage {
+			break
+		}
+	}
+	return records, nil
+}
+
+// EncodeNTSKERecords encodes NTS-KE records.  The End of Message record
+// must be the last of records.
+func EncodeNTSKERecords(records []NTSKERecord) ([]byte, error) {
+	var data []byte
+	for _, r := range records {
+		if r.Type > 0x7fff || len(r.Body) > 0xffff {
+			return nil, fmt.Errorf("invalid NTS-KE record %v", r.Type)
+		}
+		t := uint16(r.Type)
+		if r.Critical {
+			t |= 0x8000
+		}
+		data = append(data, byte(t>>8), byte(t), byte(len(r.Body)>>8), byte(len(r.Body)))
+		data = append(data, r.Body...)
+	}
+	return data, nil
+}
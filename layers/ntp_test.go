@@ -256,3 +256,79 @@ func TestNTPIsomorphism(t *testing.T) {
 		t.Errorf("NTP packet is not isomorphic:\ngot  :\n%x\n\nwant :\n%x\n\n", buf.Bytes(), NTPData)
 	}
 }
+
+//******************************************************************************
+
+// TestNTPExtensions tests the decoding and serialization of NTS protected
+// packets and of MAC trailers.
+func TestNTPExtensions(t *testing.T) {
+	auth, err := NewNTSAuthenticator([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}, []byte{0xaa, 0xbb, 0xcc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ntp := &NTP{Version: 4, Mode: 3, TransmitTimestamp: 0xe5f6a7b8c9d0e1f2, Extensions: []NTPExtension{
+		{Type: NTPExtensionUniqueIdentifier, Value: make([]byte, 32)},
+		{Type: NTPExtensionNTSCookie, Value: []byte{0xc0, 0x0c, 0x1e}},
+		auth,
+	}}
+	buf := gopacket.NewSerializeBuffer()
+	if err := ntp.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if want := 48 + 36 + 16 + 4 + 4 + 16 + 4; len(buf.Bytes()) != want {
+		t.Fatalf("serialized %d bytes, want %d", len(buf.Bytes()), want)
+	}
+	got := &NTP{}
+	if err := got.DecodeFromBytes(buf.Bytes(), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Extensions) != 3 || got.MAC != nil {
+		t.Fatalf("unexpected extensions %+v, MAC %+v", got.Extensions, got.MAC)
+	}
+	if cookie := got.Extension(NTPExtensionNTSCookie); cookie == nil || len(cookie.Value) != 12 || cookie.Value[2] != 0x1e {
+		t.Errorf("unexpected cookie %+v", cookie)
+	}
+	nonce, ciphertext, err := got.Extension(NTPExtensionNTSAuthenticator).NTSAuthenticator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nonce) != 16 || nonce[15] != 16 || !reflect.DeepEqual(ciphertext, []byte{0xaa, 0xbb, 0xcc}) {
+		t.Errorf("unexpected NTS authenticator %x %x", nonce, ciphertext)
+	}
+	again := gopacket.NewSerializeBuffer()
+	if err := got.SerializeTo(again, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again.Bytes(), buf.Bytes()) {
+		t.Errorf("NTP extensions are not isomorphic:\ngot  :\n%x\n\nwant :\n%x", again.Bytes(), buf.Bytes())
+	}
+
+	// Symmetric key MAC
+	data := make([]byte, 48+24)
+	data[0] = 0x23 // v4, client
+	data[51] = 7
+	if err := got.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if got.Extensions != nil || got.MAC == nil || got.MAC.KeyID != 7 || len(got.MAC.Digest) != 20 {
+		t.Errorf("unexpected MAC %+v, extensions %+v", got.MAC, got.Extensions)
+	}
+
+	// NTS-KE
+	records := []NTSKERecord{
+		{Critical: true, Type: NTSKERecordNextProtocolNegotiation, Body: []byte{0, 0}},
+		{Type: NTSKERecordNewCookie, Body: []byte{1, 2, 3}},
+		{Critical: true, Type: NTSKERecordEndOfMessage, Body: []byte{}},
+	}
+	ke, err := EncodeNTSKERecords(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeNTSKERecords(append(ke, 0xff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, records) {
+		t.Errorf("unexpected NTS-KE records %+v", decoded)
+	}
+}
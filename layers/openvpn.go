@@ -0,0 +1,273 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/gopacket"
+)
+
+// OpenVPNOpcode is the opcode of an OpenVPN packet.
+type OpenVPNOpcode uint8
+
+// OpenVPN opcodes
+const (
+	OpenVPNControlHardResetClientV1 OpenVPNOpcode = 1
+	OpenVPNControlHardResetServerV1 OpenVPNOpcode = 2
+	OpenVPNControlSoftResetV1       OpenVPNOpcode = 3
+	OpenVPNControlV1                OpenVPNOpcode = 4
+	OpenVPNAckV1                    OpenVPNOpcode = 5
+	OpenVPNDataV1                   OpenVPNOpcode = 6
+	OpenVPNControlHardResetClientV2 OpenVPNOpcode = 7
+	OpenVPNControlHardResetServerV2 OpenVPNOpcode = 8
+	OpenVPNDataV2                   OpenVPNOpcode = 9
+	OpenVPNControlHardResetClientV3 OpenVPNOpcode = 10
+	OpenVPNControlWKCV1             OpenVPNOpcode = 11
+)
+
+var openVPNOpcodeNames = [...]string{
+	OpenVPNControlHardResetClientV1: "P_CONTROL_HARD_RESET_CLIENT_V1",
+	OpenVPNControlHardResetServerV1: "P_CONTROL_HARD_RESET_SERVER_V1",
+	OpenVPNControlSoftResetV1:       "P_CONTROL_SOFT_RESET_V1",
+	OpenVPNControlV1:                "P_CONTROL_V1",
+	OpenVPNAckV1:                    "P_ACK_V1",
+	OpenVPNDataV1:                   "P_DATA_V1",
+	OpenVPNControlHardResetClientV2: "P_CONTROL_HARD_RESET_CLIENT_V2",
+	OpenVPNControlHardResetServerV2: "P_CONTROL_HARD_RESET_SERVER_V2",
+	OpenVPNDataV2:                   "P_DATA_V2",
+	OpenVPNControlHardResetClientV3: "P_CONTROL_HARD_RESET_CLIENT_V3",
+	OpenVPNControlWKCV1:             "P_CONTROL_WKC_V1",
+}
+
+func (o OpenVPNOpcode) String() string {
+	if o > 0 && int(o) < len(openVPNOpcodeNames) {
+		return openVPNOpcodeNames[o]
+	}
+	return fmt.Sprintf("OpenVPNOpcode(%d)", uint8(o))
+}
+
+// IsData returns true for the opcodes of data channel packets.
+func (o OpenVPNOpcode) IsData() bool {
+	return o == OpenVPNDataV1 || o == OpenVPNDataV2
+}
+
+// openVPNHMACLengths are the HMAC lengths tried to detect tls-auth: SHA-1,
+// SHA-256, SHA-512, SHA-384 and MD5.
+var openVPNHMACLengths = []int{20, 32, 64, 48, 16}
+
+// openVPNMaxAcks is the maximum number of acknowledgments of a packet
+const openVPNMaxAcks = 8
+
+// OpenVPN is an OpenVPN packet.  Over UDP, each datagram is a packet; over
+// TCP, packets are prefixed by their length, and a segment may hold several
+// of them, decoded as consecutive OpenVPN layers.
+//
+// The control channel carries a TLS connection, whose data is the payload of
+// control packets; passing the payloads of consecutive P_CONTROL_V1 packets,
+// ordered by MessagePacketID, to a TLSStream decodes it.  Whether control
+// packets are authenticated with tls-auth, or encrypted with tls-crypt, is
+// guessed from the packet ID and time following the session ID or the HMAC.
+// The payload of data channel packets is encrypted.
+type OpenVPN struct {
+	BaseLayer
+	// PacketLength is the length prefix of packets sent over TCP
+	PacketLength uint16
+	Opcode       OpenVPNOpcode
+	KeyID        uint8
+	// PeerID is the peer ID of P_DATA_V2 packets
+	PeerID uint32
+
+	// The following fields are set for control packets.
+	SessionID uint64
+	// TLSAuth is set if the packet has the HMAC, PacketID and NetTime of
+	// tls-auth.  TLSCrypt is set if it has the PacketID, NetTime and
+	// authentication tag (in HMAC) of tls-crypt, the rest of the packet
+	// being encrypted.
+	TLSAuth, TLSCrypt bool
+	HMAC              []byte
+	PacketID          uint32
+	NetTime           uint32
+	// Acks are the message packet IDs of the packets acknowledged, sent by
+	// the peer with RemoteSessionID
+	Acks            []uint32
+	RemoteSessionID uint64
+	// MessagePacketID numbers the packets of the control channel, except
+	// P_ACK_V1 packets
+	MessagePacketID uint32
+}
+
+// LayerType returns LayerTypeOpenVPN.
+func (o *OpenVPN) LayerType() gopacket.LayerType { return LayerTypeOpenVPN }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (o *OpenVPN) CanDecode() gopacket.LayerClass { return LayerTypeOpenVPN }
+
+// NextLayerType returns gopacket.LayerTypeZero, since the payload is
+// encrypted, or part of a TLS stream.
+func (o *OpenVPN) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+
+// Payload returns the TLS data of control packets, or the encrypted data of
+// data packets and of tls-crypt control packets.
+func (o *OpenVPN) Payload() []byte { return o.BaseLayer.Payload }
+
+var errOpenVPNTooShort = errors.New("OpenVPN packet too short")
+
+// DecodeFromBytes decodes an OpenVPN packet sent over UDP.
+func (o *OpenVPN) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*o = OpenVPN{}
+	return o.decodePacket(data, df)
+}
+
+// decodeTCPPacket decodes the first of the length prefixed packets of data.
+func (o *OpenVPN) decodeTCPPacket(data []byte, df gopacket.DecodeFeedback) error {
+	*o = OpenVPN{}
+	if len(data) < 2 {
+		df.SetTruncated()
+		return errOpenVPNTooShort
+	}
+	o.PacketLength = binary.BigEndian.Uint16(data)
+	n := 2 + int(o.PacketLength)
+	if len(data) < n {
+		df.SetTruncated()
+		return errOpenVPNTooShort
+	}
+	if err := o.decodePacket(data[2:n], df); err != nil {
+		return err
+	}
+	o.Contents = data[:n-len(o.BaseLayer.Payload)]
+	return nil
+}
+
+func (o *OpenVPN) decodePacket(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 1 {
+		df.SetTruncated()
+		return errOpenVPNTooShort
+	}
+	o.Opcode = OpenVPNOpcode(data[0] >> 3)
+	o.KeyID = data[0] & 0x07
+	if o.Opcode == 0 || int(o.Opcode) >= len(openVPNOpcodeNames) {
+		return fmt.Errorf("invalid OpenVPN opcode %d", o.Opcode)
+	}
+	hl := 1
+	switch {
+	case o.Opcode == OpenVPNDataV2:
+		if len(data) < 4 {
+			df.SetTruncated()
+			return errOpenVPNTooShort
+		}
+		o.PeerID = uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3])
+		hl = 4
+	case o.Opcode.IsData():
+	default:
+		var err error
+		if hl, err = o.decodeControl(data); err != nil {
+			return err
+		}
+	}
+	o.Contents = data[:hl]
+	o.BaseLayer.Payload = data[hl:]
+	return nil
+}
+
+// openVPNPlausible returns true if id and t look like the packet ID and
+// time of tls-auth or tls-crypt: a small counter and a time since 2010.
+func openVPNPlausible(id, t uint32) bool {
+	return id > 0 && id < 1<<24 && t >= 1262304000 && t < 4102444800
+}
+
+// decodeControl decodes the header of a control packet, and returns its
+// length.
+func (o *OpenVPN) decodeControl(data []byte) (int, error) {
+	if len(data) < 9 {
+		return 0, errOpenVPNTooShort
+	}
+	o.SessionID = binary.BigEndian.Uint64(data[1:9])
+	if len(data) >= 49 && openVPNPlausible(binary.BigEndian.Uint32(data[9:13]), binary.BigEndian.Uint32(data[13:17])) {
+		o.TLSCrypt = true
+		o.PacketID = binary.BigEndian.Uint32(data[9:13])
+		o.NetTime = binary.BigEndian.Uint32(data[13:17])
+		o.HMAC = data[17:49]
+		return 49, nil
+	}
+	for _, h := range openVPNHMACLengths {
+		if len(data) < 17+h || !openVPNPlausible(binary.BigEndian.Uint32(data[9+h:13+h]), binary.BigEndian.Uint32(data[13+h:17+h])) {
+			continue
+		}
+		if n, err := o.decodeReliable(data, 17+h); err == nil {
+			o.TLSAuth = true
+			o.HMAC = data[9 : 9+h]
+			o.PacketID = binary.BigEndian.Uint32(data[9+h : 13+h])
+			o.NetTime = binary.BigEndian.Uint32(data[13+h : 17+h])
+			return n, nil
+		}
+	}
+	return o.decodeReliable(data, 9)
+}
+
+// decodeReliable decodes the acknowledgments and the message packet ID of
+// a control packet, starting at offset, and returns the length of the
+// header.
+func (o *OpenVPN) decodeReliable(data []byte, offset int) (int, error) {
+	if len(data) < offset+1 {
+		return 0, errOpenVPNTooShort
+	}
+	n := int(data[offset])
+	offset++
+	if n > openVPNMaxAcks {
+		return 0, fmt.Errorf("OpenVPN packet with %d acknowledgments", n)
+	}
+	if len(data) < offset+4*n {
+		return 0, errOpenVPNTooShort
+	}
+	o.Acks = nil
+	for i := 0; i < n; i++ {
+		o.Acks = append(o.Acks, binary.BigEndian.Uint32(data[offset:]))
+		offset += 4
+	}
+	if n > 0 {
+		if len(data) < offset+8 {
+			return 0, errOpenVPNTooShort
+		}
+		o.RemoteSessionID = binary.BigEndian.Uint64(data[offset:])
+		offset += 8
+	}
+	if o.Opcode != OpenVPNAckV1 {
+		if len(data) < offset+4 {
+			return 0, errOpenVPNTooShort
+		}
+		o.MessagePacketID = binary.BigEndian.Uint32(data[offset:])
+		offset += 4
+	}
+	return offset, nil
+}
+
+func decodeOpenVPN(data []byte, p gopacket.PacketBuilder) error {
+	o := &OpenVPN{}
+	if err := o.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(o)
+	p.SetApplicationLayer(o)
+	return nil
+}
+
+// decodeOpenVPNTCP decodes the length prefixed packets of a TCP segment.
+func decodeOpenVPNTCP(data []byte, p gopacket.PacketBuilder) error {
+	for len(data) > 0 {
+		o := &OpenVPN{}
+		if err := o.decodeTCPPacket(data, p); err != nil {
+			return err
+		}
+		p.AddLayer(o)
+		p.SetApplicationLayer(o)
+		data = data[2+int(o.PacketLength):]
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// openVPNTestControl returns a control packet without tls-auth, with the
+// given acknowledgments and message packet ID.
+func openVPNTestControl(opcode OpenVPNOpcode, sid uint64, acks []uint32, remote uint64, msgID uint32, payload []byte) []byte {
+	b := make([]byte, 9, 64)
+	b[0] = byte(opcode) << 3
+	binary.BigEndian.PutUint64(b[1:], sid)
+	b = append(b, byte(len(acks)))
+	for _, a := range acks {
+		b = append(b, byte(a>>24), byte(a>>16), byte(a>>8), byte(a))
+	}
+	if len(acks) > 0 {
+		b = append(b, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(b[len(b)-8:], remote)
+	}
+	if opcode != OpenVPNAckV1 {
+		b = append(b, byte(msgID>>24), byte(msgID>>16), byte(msgID>>8), byte(msgID))
+	}
+	return append(b, payload...)
+}
+
+// openVPNTestTCP prefixes packets with their length.
+func openVPNTestTCP(packets ...[]byte) []byte {
+	var b []byte
+	for _, p := range packets {
+		b = append(b, byte(len(p)>>8), byte(len(p)))
+		b = append(b, p...)
+	}
+	return b
+}
+
+func TestOpenVPNTLSAuth(t *testing.T) {
+	// a P_CONTROL_HARD_RESET_CLIENT_V2 with an HMAC-SHA1 tls-auth
+	reset := openVPNTestControl(OpenVPNControlHardResetClientV2, 0x0102030405060708, nil, 0, 0, nil)
+	auth := append(bytes.Repeat([]byte{0xaa}, 20), 0, 0, 0, 1, 0x65, 0x53, 0xf1, 0x00)
+	packet := append(append(append([]byte{}, reset[:9]...), auth...), reset[9:]...)
+	data, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 50000, DstPort: 1194}).Payload(packet).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeOpenVPN}, t)
+	o := p.ApplicationLayer().(*OpenVPN)
+	if o.Opcode != OpenVPNControlHardResetClientV2 || o.Opcode.String() != "P_CONTROL_HARD_RESET_CLIENT_V2" || o.KeyID != 0 || o.SessionID != 0x0102030405060708 {
+		t.Errorf("unexpected header %#v", o)
+	}
+	if !o.TLSAuth || o.TLSCrypt || len(o.HMAC) != 20 || o.PacketID != 1 || o.NetTime != 0x6553f100 || len(o.Acks) != 0 || len(o.Payload()) != 0 {
+		t.Errorf("unexpected tls-auth fields %#v", o)
+	}
+
+	// without tls-auth
+	o = &OpenVPN{}
+	control := openVPNTestControl(OpenVPNControlV1, 1, []uint32{0, 1}, 2, 3, []byte{0x16, 0x03, 0x01})
+	control[0] |= 2
+	if err := o.DecodeFromBytes(control, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if o.TLSAuth || o.TLSCrypt || o.KeyID != 2 || len(o.Acks) != 2 || o.Acks[1] != 1 || o.RemoteSessionID != 2 || o.MessagePacketID != 3 || !bytes.Equal(o.Payload(), []byte{0x16, 0x03, 0x01}) {
+		t.Errorf("unexpected P_CONTROL_V1 %#v", o)
+	}
+
+	// with tls-crypt, the packet ID and time follow the session ID
+	o = &OpenVPN{}
+	crypt := append(append(reset[:9:9], 0, 0, 0, 2, 0x65, 0x53, 0xf1, 0x00), bytes.Repeat([]byte{0xbb}, 40)...)
+	if err := o.DecodeFromBytes(crypt, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if !o.TLSCrypt || o.TLSAuth || o.PacketID != 2 || len(o.HMAC) != 32 || len(o.Payload()) != 8 {
+		t.Errorf("unexpected tls-crypt fields %#v", o)
+	}
+
+	for _, data := range [][]byte{
+		{0},
+		{0x60},
+		reset[:12],
+		openVPNTestControl(OpenVPNAckV1, 1, []uint32{1, 2, 3, 4, 5, 6, 7, 8, 9}, 2, 0, nil),
+	} {
+		if err := (&OpenVPN{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
+
+func TestOpenVPNTCP(t *testing.T) {
+	ack := openVPNTestControl(OpenVPNAckV1, 1, []uint32{7}, 2, 0, nil)
+	dataV2 := append([]byte{byte(OpenVPNDataV2)<<3 | 1, 0, 0, 5}, bytes.Repeat([]byte{0xcc}, 24)...)
+	segment := openVPNTestTCP(ack, dataV2)
+	data, err := Build().IPv4(nil).TCP(&TCP{SrcPort: 1194, DstPort: 50000}).Payload(segment).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true})
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeTCP, LayerTypeOpenVPN, LayerTypeOpenVPN}, t)
+	layers := p.Layers()
+	o := layers[2].(*OpenVPN)
+	if o.PacketLength != uint16(len(ack)) || o.Opcode != OpenVPNAckV1 || len(o.Acks) != 1 || o.Acks[0] != 7 || o.RemoteSessionID != 2 || len(o.Contents) != 2+len(ack) {
+		t.Errorf("unexpected P_ACK_V1 %#v", o)
+	}
+	o = layers[3].(*OpenVPN)
+	if o.Opcode != OpenVPNDataV2 || !o.Opcode.IsData() || o.KeyID != 1 || o.PeerID != 5 || len(o.Payload()) != 24 || len(o.Contents) != 6 {
+		t.Errorf("unexpected P_DATA_V2 %#v", o)
+	}
+
+	// a packet continued in the next segment
+	p = gopacket.NewPacket(segment[:len(segment)-1], LayerTypeOpenVPNTCP, gopacket.Default)
+	if p.ErrorLayer() == nil || !p.Metadata().Truncated {
+		t.Error("no error for a truncated packet")
+	}
+}
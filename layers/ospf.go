@@ -44,6 +44,13 @@ const (
 	IntraAreaPrefixLSAtype  = 0x2009
 )
 
+// Potential values for OSPFv2.AuType, from RFC 2328  D.
+const (
+	OSPFAuthNone           = 0
+	OSPFAuthSimplePassword = 1
+	OSPFAuthCryptographic  = 2
+)
+
 // String conversions for OSPFType
 func (i OSPFType) String() string {
 	switch i {
@@ -125,6 +132,13 @@ type InterAreaPrefixLSA struct {
 	AddressPrefix []byte
 }
 
+// SummaryLSAV2 is the struct from RFC 2328  A.4.4, used by both network and
+// ASBR summary LSAs.
+type SummaryLSAV2 struct {
+	NetworkMask uint32
+	Metric      uint32
+}
+
 // NetworkLSA is the struct from RFC 5340  A.4.4.
 type NetworkLSA struct {
 	Options        uint32
@@ -243,6 +257,26 @@ type OSPFv2 struct {
 	OSPF
 	AuType         uint16
 	Authentication uint64
+	// AuthData is the message digest following the packet when AuType is
+	// OSPFAuthCryptographic.  It is not covered by PacketLength.
+	AuthData []byte
+}
+
+// KeyID returns the key ID of the cryptographic authentication field.
+func (ospf *OSPFv2) KeyID() uint8 {
+	return uint8(ospf.Authentication >> 40)
+}
+
+// AuthDataLength returns the length of the message digest given in the
+// cryptographic authentication field.
+func (ospf *OSPFv2) AuthDataLength() uint8 {
+	return uint8(ospf.Authentication >> 32)
+}
+
+// CryptoSequenceNumber returns the cryptographic sequence number of the
+// cryptographic authentication field.
+func (ospf *OSPFv2) CryptoSequenceNumber() uint32 {
+	return uint32(ospf.Authentication)
 }
 
 // OSPFv3 extend the OSPF head with version 3 specific fields
@@ -251,6 +285,8 @@ type OSPFv3 struct {
 	OSPF
 	Instance uint8
 	Reserved uint8
+
+	tcpipchecksum
 }
 
 // getLSAsv2 parses the LSA information from the packet for OSPFv2
@@ -259,11 +295,14 @@ func getLSAsv2(num uint32, data []byte) ([]LSA, error) {
 	var i uint32 = 0
 	var offset uint32 = 0
 	for ; i < num; i++ {
+		if uint32(len(data)) < offset+20 {
+			return nil, fmt.Errorf("LSA %d header truncated", i)
+		}
 		lstype := uint16(data[offset+3])
 		lsalength := binary.BigEndian.Uint16(data[offset+18 : offset+20])
 		content, err := extractLSAInformation(lstype, lsalength, data[offset:])
 		if err != nil {
-			return nil, fmt.Errorf("Could not extract Link State type.")
+			return nil, fmt.Errorf("Could not extract Link State type: %v", err)
 		}
 		lsa := LSA{
 			LSAheader: LSAheader{
@@ -284,6 +323,41 @@ func getLSAsv2(num uint32, data []byte) ([]LSA, error) {
 	return lsas, nil
 }
 
+// ASExternalLSA.Flags bits from RFC 5340  A.4.7.
+const (
+	ASExternalLSAFlagE = 0x04 // metric is type 2 external
+	ASExternalLSAFlagF = 0x02 // forwarding address present
+	ASExternalLSAFlagT = 0x01 // external route tag present
+)
+
+// ospfPrefixSize returns the number of bytes an OSPFv3 address prefix of
+// prefixLength bits occupies on the wire; prefixes are padded to 32-bit words.
+func ospfPrefixSize(prefixLength uint8) int {
+	return (int(prefixLength) + 31) / 32 * 4
+}
+
+// decodeOSPFPrefix decodes an OSPFv3 address prefix (RFC 5340  A.4.1) at the
+// start of data and returns it along with its encoded size.  The third and
+// fourth byte is the metric in intra-area-prefix LSAs and reserved elsewhere.
+func decodeOSPFPrefix(data []byte, metric bool) (Prefix, int, error) {
+	if len(data) < 4 {
+		return Prefix{}, 0, errors.New("OSPFv3 prefix too small")
+	}
+	size := ospfPrefixSize(data[0])
+	if data[0] > 128 || len(data) < 4+size {
+		return Prefix{}, 0, fmt.Errorf("invalid OSPFv3 prefix of length %d", data[0])
+	}
+	prefix := Prefix{
+		PrefixLength:  data[0],
+		PrefixOptions: data[1],
+		AddressPrefix: data[4 : 4+size],
+	}
+	if metric {
+		prefix.Metric = binary.BigEndian.Uint16(data[2:4])
+	}
+	return prefix, 4 + size, nil
+}
+
 // extractLSAInformation extracts all the LSA information
 func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{}, error) {
 	if lsalength < 20 {
@@ -292,6 +366,10 @@ func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{},
 	if len(data) < int(lsalength) {
 		return nil, fmt.Errorf("Link State header length %v too short, %v required", len(data), lsalength)
 	}
+	data = data[:lsalength]
+	if minLength := lsaMinLength(lstype); len(data) < minLength {
+		return nil, fmt.Errorf("Link State type %#x length %v too short, %v required", lstype, len(data), minLength)
+	}
 	var content interface{}
 	switch lstype {
 	case RouterLSAtypeV2:
@@ -309,9 +387,6 @@ func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{},
 			}
 			routers = append(routers, router)
 		}
-		if len(data) < 24 {
-			return nil, errors.New("LSAtypeV2 too small")
-		}
 		links := binary.BigEndian.Uint16(data[22:24])
 		content = RouterLSAV2{
 			Flags:   data[20],
@@ -328,10 +403,15 @@ func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{},
 			ForwardingAddress: binary.BigEndian.Uint32(data[28:32]),
 			ExternalRouteTag:  binary.BigEndian.Uint32(data[32:36]),
 		}
+	case SummaryLSANetworktypeV2, SummaryLSAASBRtypeV2:
+		content = SummaryLSAV2{
+			NetworkMask: binary.BigEndian.Uint32(data[20:24]),
+			Metric:      binary.BigEndian.Uint32(data[24:28]) & 0x00FFFFFF,
+		}
 	case NetworkLSAtypeV2:
 		var routers []uint32
 		var j uint32
-		for j = 24; j < uint32(lsalength); j += 4 {
+		for j = 24; j+4 <= uint32(lsalength); j += 4 {
 			routers = append(routers, binary.BigEndian.Uint32(data[j:j+4]))
 		}
 		content = NetworkLSAV2{
@@ -342,6 +422,9 @@ func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{},
 		var routers []Router
 		var j uint32
 		for j = 24; j < uint32(lsalength); j += 16 {
+			if len(data) < int(j+16) {
+				return nil, errors.New("Router LSA too small")
+			}
 			router := Router{
 				Type:                uint8(data[j]),
 				Metric:              binary.BigEndian.Uint16(data[j+2 : j+4]),
@@ -359,7 +442,7 @@ func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{},
 	case NetworkLSAtype:
 		var routers []uint32
 		var j uint32
-		for j = 24; j < uint32(lsalength); j += 4 {
+		for j = 24; j+4 <= uint32(lsalength); j += 4 {
 			routers = append(routers, binary.BigEndian.Uint32(data[j:j+4]))
 		}
 		content = NetworkLSA{
@@ -367,11 +450,15 @@ func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{},
 			AttachedRouter: routers,
 		}
 	case InterAreaPrefixLSAtype:
+		prefix, _, err := decodeOSPFPrefix(data[24:], false)
+		if err != nil {
+			return nil, err
+		}
 		content = InterAreaPrefixLSA{
 			Metric:        binary.BigEndian.Uint32(data[20:24]) & 0x00FFFFFF,
-			PrefixLength:  uint8(data[24]),
-			PrefixOptions: uint8(data[25]),
-			AddressPrefix: data[28:uint32(lsalength)],
+			PrefixLength:  prefix.PrefixLength,
+			PrefixOptions: prefix.PrefixOptions,
+			AddressPrefix: prefix.AddressPrefix,
 		}
 	case InterAreaRouterLSAtype:
 		content = InterAreaRouterLSA{
@@ -383,34 +470,52 @@ func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{},
 		fallthrough
 	case NSSALSAtype:
 		flags := uint8(data[20])
-		prefixLen := uint8(data[24]) / 8
-		var forwardingAddress []byte
-		if (flags & 0x02) == 0x02 {
-			forwardingAddress = data[28+uint32(prefixLen) : 28+uint32(prefixLen)+16]
-		}
-		content = ASExternalLSA{
-			Flags:             flags,
-			Metric:            binary.BigEndian.Uint32(data[20:24]) & 0x00FFFFFF,
-			PrefixLength:      prefixLen,
-			PrefixOptions:     uint8(data[25]),
-			RefLSType:         binary.BigEndian.Uint16(data[26:28]),
-			AddressPrefix:     data[28 : 28+uint32(prefixLen)],
-			ForwardingAddress: forwardingAddress,
+		prefix, offset, err := decodeOSPFPrefix(data[24:], false)
+		if err != nil {
+			return nil, err
 		}
+		offset += 24
+		lsa := ASExternalLSA{
+			Flags:         flags,
+			Metric:        binary.BigEndian.Uint32(data[20:24]) & 0x00FFFFFF,
+			PrefixLength:  prefix.PrefixLength,
+			PrefixOptions: prefix.PrefixOptions,
+			RefLSType:     binary.BigEndian.Uint16(data[26:28]),
+			AddressPrefix: prefix.AddressPrefix,
+		}
+		if flags&ASExternalLSAFlagF != 0 {
+			if len(data) < offset+16 {
+				return nil, errors.New("AS-External LSA forwarding address truncated")
+			}
+			lsa.ForwardingAddress = data[offset : offset+16]
+			offset += 16
+		}
+		if flags&ASExternalLSAFlagT != 0 {
+			if len(data) < offset+4 {
+				return nil, errors.New("AS-External LSA route tag truncated")
+			}
+			lsa.ExternalRouteTag = binary.BigEndian.Uint32(data[offset : offset+4])
+			offset += 4
+		}
+		if lsa.RefLSType != 0 {
+			if len(data) < offset+4 {
+				return nil, errors.New("AS-External LSA referenced link state ID truncated")
+			}
+			lsa.RefLinkStateID = binary.BigEndian.Uint32(data[offset : offset+4])
+		}
+		content = lsa
 	case LinkLSAtype:
 		var prefixes []Prefix
-		var prefixOffset uint32 = 44
+		prefixOffset := 44
 		var j uint32
 		numOfPrefixes := binary.BigEndian.Uint32(data[40:44])
 		for j = 0; j < numOfPrefixes; j++ {
-			prefixLen := uint8(data[prefixOffset])
-			prefix := Prefix{
-				PrefixLength:  prefixLen,
-				PrefixOptions: uint8(data[prefixOffset+1]),
-				AddressPrefix: data[prefixOffset+4 : prefixOffset+4+uint32(prefixLen)/8],
+			prefix, n, err := decodeOSPFPrefix(data[prefixOffset:], false)
+			if err != nil {
+				return nil, err
 			}
 			prefixes = append(prefixes, prefix)
-			prefixOffset = prefixOffset + 4 + uint32(prefixLen)/8
+			prefixOffset += n
 		}
 		content = LinkLSA{
 			RtrPriority:      uint8(data[20]),
@@ -421,19 +526,16 @@ func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{},
 		}
 	case IntraAreaPrefixLSAtype:
 		var prefixes []Prefix
-		var prefixOffset uint32 = 32
+		prefixOffset := 32
 		var j uint16
 		numOfPrefixes := binary.BigEndian.Uint16(data[20:22])
 		for j = 0; j < numOfPrefixes; j++ {
-			prefixLen := uint8(data[prefixOffset])
-			prefix := Prefix{
-				PrefixLength:  prefixLen,
-				PrefixOptions: uint8(data[prefixOffset+1]),
-				Metric:        binary.BigEndian.Uint16(data[prefixOffset+2 : prefixOffset+4]),
-				AddressPrefix: data[prefixOffset+4 : prefixOffset+4+uint32(prefixLen)/8],
+			prefix, n, err := decodeOSPFPrefix(data[prefixOffset:], true)
+			if err != nil {
+				return nil, err
 			}
 			prefixes = append(prefixes, prefix)
-			prefixOffset = prefixOffset + 4 + uint32(prefixLen)
+			prefixOffset += n
 		}
 		content = IntraAreaPrefixLSA{
 			NumOfPrefixes:  numOfPrefixes,
@@ -448,19 +550,41 @@ func extractLSAInformation(lstype, lsalength uint16, data []byte) (interface{},
 	return content, nil
 }
 
+// lsaMinLength returns the smallest valid length of an LSA of type lstype,
+// including its 20 byte header.
+func lsaMinLength(lstype uint16) int {
+	switch lstype {
+	case RouterLSAtypeV2, NetworkLSAtypeV2, RouterLSAtype, NetworkLSAtype:
+		return 24
+	case ASExternalLSAtypeV2, NSSALSAtypeV2:
+		return 36
+	case SummaryLSANetworktypeV2, SummaryLSAASBRtypeV2:
+		return 28
+	case InterAreaPrefixLSAtype, ASExternalLSAtype, NSSALSAtype:
+		return 28
+	case InterAreaRouterLSAtype, IntraAreaPrefixLSAtype:
+		return 32
+	case LinkLSAtype:
+		return 44
+	}
+	return 20
+}
+
 // getLSAs parses the LSA information from the packet for OSPFv3
 func getLSAs(num uint32, data []byte) ([]LSA, error) {
 	var lsas []LSA
 	var i uint32 = 0
 	var offset uint32 = 0
 	for ; i < num; i++ {
-		var content interface{}
+		if uint32(len(data)) < offset+20 {
+			return nil, fmt.Errorf("LSA %d header truncated", i)
+		}
 		lstype := binary.BigEndian.Uint16(data[offset+2 : offset+4])
 		lsalength := binary.BigEndian.Uint16(data[offset+18 : offset+20])
 
 		content, err := extractLSAInformation(lstype, lsalength, data[offset:])
 		if err != nil {
-			return nil, fmt.Errorf("Could not extract Link State type.")
+			return nil, fmt.Errorf("Could not extract Link State type: %v", err)
 		}
 		lsa := LSA{
 			LSAheader: LSAheader{
@@ -494,6 +618,25 @@ func (ospf *OSPFv2) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) err
 	ospf.Checksum = binary.BigEndian.Uint16(data[12:14])
 	ospf.AuType = binary.BigEndian.Uint16(data[14:16])
 	ospf.Authentication = binary.BigEndian.Uint64(data[16:24])
+	ospf.Content = nil
+	ospf.AuthData = nil
+
+	if ospf.PacketLength < 24 || int(ospf.PacketLength) > len(data) {
+		return fmt.Errorf("Invalid OSPF Version 2 packet length %d", ospf.PacketLength)
+	}
+	if ospf.AuType == OSPFAuthCryptographic {
+		end := int(ospf.PacketLength) + int(ospf.AuthDataLength())
+		if end > len(data) {
+			return fmt.Errorf("OSPF Version 2 authentication data truncated: %d < %d", len(data), end)
+		}
+		ospf.AuthData = data[ospf.PacketLength:end]
+	}
+	// LSAs are located by their own length fields, so the update body is not
+	// cut to PacketLength.
+	ospf.BaseLayer = BaseLayer{Contents: data[:ospf.PacketLength]}
+	if minLength := ospfv2MinLength(ospf.Type); int(ospf.PacketLength) < minLength {
+		return fmt.Errorf("OSPF Version 2 %v packet too small: %d < %d", ospf.Type, ospf.PacketLength, minLength)
+	}
 
 	switch ospf.Type {
 	case OSPFHello:
@@ -518,7 +661,8 @@ func (ospf *OSPFv2) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) err
 		for i := 32; uint16(i+20) <= ospf.PacketLength; i += 20 {
 			lsa := LSAheader{
 				LSAge:       binary.BigEndian.Uint16(data[i : i+2]),
-				LSType:      binary.BigEndian.Uint16(data[i+2 : i+4]),
+				LSOptions:   data[i+2],
+				LSType:      uint16(data[i+3]),
 				LinkStateID: binary.BigEndian.Uint32(data[i+4 : i+8]),
 				AdvRouter:   binary.BigEndian.Uint32(data[i+8 : i+12]),
 				LSSeqNumber: binary.BigEndian.Uint32(data[i+12 : i+16]),
@@ -591,6 +735,16 @@ func (ospf *OSPFv3) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) err
 	ospf.Checksum = binary.BigEndian.Uint16(data[12:14])
 	ospf.Instance = uint8(data[14])
 	ospf.Reserved = uint8(data[15])
+	ospf.Content = nil
+
+	if ospf.PacketLength < 16 || int(ospf.PacketLength) > len(data) {
+		return fmt.Errorf("Invalid OSPF Version 3 packet length %d", ospf.PacketLength)
+	}
+	data = data[:ospf.PacketLength]
+	ospf.BaseLayer = BaseLayer{Contents: data}
+	if minLength := ospfv3MinLength(ospf.Type); len(data) < minLength {
+		return fmt.Errorf("OSPF Version 3 %v packet too small: %d < %d", ospf.Type, len(data), minLength)
+	}
 
 	switch ospf.Type {
 	case OSPFHello:
@@ -672,6 +826,450 @@ func (ospf *OSPFv3) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) err
 	return nil
 }
 
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+// See the docs for gopacket.SerializableLayer for more info.
+func (ospf *OSPFv2) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	body, err := ospf.encodeContent(2, opts)
+	if err != nil {
+		return err
+	}
+	length := 24 + len(body)
+	if length > 0xffff {
+		return fmt.Errorf("OSPF Version 2 packet too large: %d", length)
+	}
+	var authData []byte
+	if ospf.AuType == OSPFAuthCryptographic {
+		authData = ospf.AuthData
+		if len(authData) > 0xff {
+			return fmt.Errorf("OSPF Version 2 authentication data too large: %d", len(authData))
+		}
+	}
+	bytes, err := b.PrependBytes(length + len(authData))
+	if err != nil {
+		return err
+	}
+	if opts.FixLengths {
+		ospf.PacketLength = uint16(length)
+		if ospf.AuType == OSPFAuthCryptographic {
+			ospf.Authentication = ospf.Authentication&^(0xff<<32) | uint64(len(authData))<<32
+		}
+	}
+	bytes[0] = ospf.Version
+	bytes[1] = uint8(ospf.Type)
+	binary.BigEndian.PutUint16(bytes[2:4], ospf.PacketLength)
+	binary.BigEndian.PutUint32(bytes[4:8], ospf.RouterID)
+	binary.BigEndian.PutUint32(bytes[8:12], ospf.AreaID)
+	bytes[12], bytes[13] = 0, 0
+	binary.BigEndian.PutUint16(bytes[14:16], ospf.AuType)
+	binary.BigEndian.PutUint64(bytes[16:24], ospf.Authentication)
+	copy(bytes[24:], body)
+	copy(bytes[length:], authData)
+	if opts.ComputeChecksums {
+		// RFC 2328  D.4.3: the checksum is not computed with cryptographic
+		// authentication.
+		ospf.Checksum = 0
+		if ospf.AuType != OSPFAuthCryptographic {
+			ospf.Checksum = tcpipChecksum(bytes[24:length], uint32(^tcpipChecksum(bytes[:16], 0)))
+		}
+	}
+	binary.BigEndian.PutUint16(bytes[12:14], ospf.Checksum)
+	return nil
+}
+
+// VerifyChecksum verifies the checksum of the OSPFv2 packet, implementing
+// gopacket.ChecksumVerifier.  The checksum covers the packet except for the
+// authentication field; packets using cryptographic authentication carry no
+// checksum and are always reported as valid.
+func (ospf *OSPFv2) VerifyChecksum() (bool, error) {
+	if ospf.AuType == OSPFAuthCryptographic {
+		return true, nil
+	}
+	if len(ospf.Contents) < 24 {
+		return false, errors.New("OSPF Version 2 packet too small")
+	}
+	return tcpipChecksumValid(ospf.Contents[:16], ospf.Contents[24:], 0), nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+// See the docs for gopacket.SerializableLayer for more info.
+func (ospf *OSPFv3) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	body, err := ospf.encodeContent(3, opts)
+	if err != nil {
+		return err
+	}
+	length := 16 + len(body)
+	if length > 0xffff {
+		return fmt.Errorf("OSPF Version 3 packet too large: %d", length)
+	}
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	if opts.FixLengths {
+		ospf.PacketLength = uint16(length)
+	}
+	bytes[0] = ospf.Version
+	bytes[1] = uint8(ospf.Type)
+	binary.BigEndian.PutUint16(bytes[2:4], ospf.PacketLength)
+	binary.BigEndian.PutUint32(bytes[4:8], ospf.RouterID)
+	binary.BigEndian.PutUint32(bytes[8:12], ospf.AreaID)
+	bytes[12], bytes[13] = 0, 0
+	bytes[14] = ospf.Instance
+	bytes[15] = ospf.Reserved
+	copy(bytes[16:], body)
+	if opts.ComputeChecksums {
+		if ospf.Checksum, err = ospf.computeChecksum(bytes, IPProtocolOSPF); err != nil {
+			return err
+		}
+	}
+	binary.BigEndian.PutUint16(bytes[12:14], ospf.Checksum)
+	return nil
+}
+
+// encodeContent returns the serialized packet body following the common
+// header, using the layout of OSPF version.
+func (ospf *OSPF) encodeContent(version uint8, opts gopacket.SerializeOptions) ([]byte, error) {
+	switch ospf.Type {
+	case OSPFHello:
+		if version == 2 {
+			hello, ok := ospf.Content.(HelloPkgV2)
+			if !ok {
+				return nil, fmt.Errorf("OSPF Version 2 Hello content must be HelloPkgV2, not %T", ospf.Content)
+			}
+			b := make([]byte, 20+4*len(hello.NeighborID))
+			binary.BigEndian.PutUint32(b[0:4], hello.NetworkMask)
+			binary.BigEndian.PutUint16(b[4:6], hello.HelloInterval)
+			b[6] = uint8(hello.Options)
+			b[7] = hello.RtrPriority
+			binary.BigEndian.PutUint32(b[8:12], hello.RouterDeadInterval)
+			binary.BigEndian.PutUint32(b[12:16], hello.DesignatedRouterID)
+			binary.BigEndian.PutUint32(b[16:20], hello.BackupDesignatedRouterID)
+			for i, id := range hello.NeighborID {
+				binary.BigEndian.PutUint32(b[20+4*i:], id)
+			}
+			return b, nil
+		}
+		hello, ok := ospf.Content.(HelloPkg)
+		if !ok {
+			return nil, fmt.Errorf("OSPF Version 3 Hello content must be HelloPkg, not %T", ospf.Content)
+		}
+		if hello.RouterDeadInterval > 0xffff {
+			return nil, fmt.Errorf("OSPF Version 3 router dead interval %d too large", hello.RouterDeadInterval)
+		}
+		b := make([]byte, 20+4*len(hello.NeighborID))
+		binary.BigEndian.PutUint32(b[0:4], hello.InterfaceID)
+		binary.BigEndian.PutUint32(b[4:8], uint32(hello.RtrPriority)<<24|hello.Options&0x00FFFFFF)
+		binary.BigEndian.PutUint16(b[8:10], hello.HelloInterval)
+		binary.BigEndian.PutUint16(b[10:12], uint16(hello.RouterDeadInterval))
+		binary.BigEndian.PutUint32(b[12:16], hello.DesignatedRouterID)
+		binary.BigEndian.PutUint32(b[16:20], hello.BackupDesignatedRouterID)
+		for i, id := range hello.NeighborID {
+			binary.BigEndian.PutUint32(b[20+4*i:], id)
+		}
+		return b, nil
+	case OSPFDatabaseDescription:
+		dbd, ok := ospf.Content.(DbDescPkg)
+		if !ok {
+			return nil, fmt.Errorf("OSPF Version %d Database Description content must be DbDescPkg, not %T", version, ospf.Content)
+		}
+		b := make([]byte, 8, 8+20*len(dbd.LSAinfo))
+		if version == 2 {
+			binary.BigEndian.PutUint16(b[0:2], dbd.InterfaceMTU)
+			b[2] = uint8(dbd.Options)
+			b[3] = uint8(dbd.Flags)
+			binary.BigEndian.PutUint32(b[4:8], dbd.DDSeqNumber)
+		} else {
+			binary.BigEndian.PutUint32(b[0:4], dbd.Options&0x00FFFFFF)
+			b = append(b, 0, 0, 0, 0)
+			binary.BigEndian.PutUint16(b[4:6], dbd.InterfaceMTU)
+			binary.BigEndian.PutUint16(b[6:8], dbd.Flags)
+			binary.BigEndian.PutUint32(b[8:12], dbd.DDSeqNumber)
+		}
+		return appendLSAheaders(b, dbd.LSAinfo, version), nil
+	case OSPFLinkStateRequest:
+		lsrs, ok := ospf.Content.([]LSReq)
+		if !ok && ospf.Content != nil {
+			return nil, fmt.Errorf("OSPF Version %d Link State Request content must be []LSReq, not %T", version, ospf.Content)
+		}
+		b := make([]byte, 12*len(lsrs))
+		for i, lsr := range lsrs {
+			binary.BigEndian.PutUint32(b[12*i:], uint32(lsr.LSType))
+			binary.BigEndian.PutUint32(b[12*i+4:], lsr.LSID)
+			binary.BigEndian.PutUint32(b[12*i+8:], lsr.AdvRouter)
+		}
+		return b, nil
+	case OSPFLinkStateUpdate:
+		update, ok := ospf.Content.(LSUpdate)
+		if !ok {
+			return nil, fmt.Errorf("OSPF Version %d Link State Update content must be LSUpdate, not %T", version, ospf.Content)
+		}
+		if opts.FixLengths {
+			update.NumOfLSAs = uint32(len(update.LSAs))
+			ospf.Content = update
+		}
+		b := make([]byte, 4)
+		binary.BigEndian.PutUint32(b, update.NumOfLSAs)
+		for i := range update.LSAs {
+			lsa, err := update.LSAs[i].encode(version, opts)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, lsa...)
+		}
+		return b, nil
+	case OSPFLinkStateAcknowledgment:
+		headers, ok := ospf.Content.([]LSAheader)
+		if !ok && ospf.Content != nil {
+			return nil, fmt.Errorf("OSPF Version %d Link State Acknowledgment content must be []LSAheader, not %T", version, ospf.Content)
+		}
+		return appendLSAheaders(nil, headers, version), nil
+	}
+	return nil, fmt.Errorf("Unknown OSPF Version %d packet type %d", version, ospf.Type)
+}
+
+// appendLSAheaders appends the 20 byte encoding of each of headers to b.
+func appendLSAheaders(b []byte, headers []LSAheader, version uint8) []byte {
+	for i := range headers {
+		b = append(b, make([]byte, 20)...)
+		headers[i].encode(b[len(b)-20:], version)
+	}
+	return b
+}
+
+// VerifyChecksum verifies the checksum of the OSPFv3 packet, which covers
+// the IPv6 pseudo-header, implementing gopacket.ChecksumVerifier.
+func (ospf *OSPFv3) VerifyChecksum() (bool, error) {
+	return ospf.verifyChecksum(ospf.Contents, nil, IPProtocolOSPF)
+}
+
+// encode writes the 20 byte LSA header into b, using the OSPFv2 layout with
+// its options byte if version is 2 and the OSPFv3 layout otherwise.
+func (h *LSAheader) encode(b []byte, version uint8) {
+	binary.BigEndian.PutUint16(b[0:2], h.LSAge)
+	if version == 2 {
+		b[2] = h.LSOptions
+		b[3] = uint8(h.LSType)
+	} else {
+		binary.BigEndian.PutUint16(b[2:4], h.LSType)
+	}
+	binary.BigEndian.PutUint32(b[4:8], h.LinkStateID)
+	binary.BigEndian.PutUint32(b[8:12], h.AdvRouter)
+	binary.BigEndian.PutUint32(b[12:16], h.LSSeqNumber)
+	binary.BigEndian.PutUint16(b[16:18], h.LSChecksum)
+	binary.BigEndian.PutUint16(b[18:20], h.Length)
+}
+
+// encode returns the serialized LSA.  With opts.FixLengths the header length
+// and the prefix counts of the content are updated, with opts.ComputeChecksums
+// the LS checksum.
+func (lsa *LSA) encode(version uint8, opts gopacket.SerializeOptions) ([]byte, error) {
+	if opts.FixLengths {
+		switch c := lsa.Content.(type) {
+		case RouterLSAV2:
+			c.Links = uint16(len(c.Routers))
+			lsa.Content = c
+		case LinkLSA:
+			c.NumOfPrefixes = uint32(len(c.Prefixes))
+			lsa.Content = c
+		case IntraAreaPrefixLSA:
+			c.NumOfPrefixes = uint16(len(c.Prefixes))
+			lsa.Content = c
+		}
+	}
+	b := make([]byte, 20, 64)
+	b, err := appendLSABody(b, lsa.Content)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 0xffff {
+		return nil, fmt.Errorf("LSA too large: %d", len(b))
+	}
+	if opts.FixLengths {
+		lsa.Length = uint16(len(b))
+	}
+	lsa.LSAheader.encode(b, version)
+	if opts.ComputeChecksums {
+		lsa.LSChecksum = lsaChecksum(b)
+		binary.BigEndian.PutUint16(b[16:18], lsa.LSChecksum)
+	}
+	return b, nil
+}
+
+// appendLSABody appends the body of an LSA, everything after its header, to b.
+func appendLSABody(b []byte, content interface{}) ([]byte, error) {
+	var err error
+	switch c := content.(type) {
+	case RouterLSAV2:
+		b = append(b, c.Flags, 0, uint8(c.Links>>8), uint8(c.Links))
+		for _, r := range c.Routers {
+			b = appendUint32(b, r.LinkID)
+			b = appendUint32(b, r.LinkData)
+			b = append(b, r.Type, 0, uint8(r.Metric>>8), uint8(r.Metric))
+		}
+	case NetworkLSAV2:
+		b = appendUint32(b, c.NetworkMask)
+		for _, r := range c.AttachedRouter {
+			b = appendUint32(b, r)
+		}
+	case SummaryLSAV2:
+		b = appendUint32(b, c.NetworkMask)
+		b = appendUint32(b, c.Metric&0x00FFFFFF)
+	case ASExternalLSAV2:
+		b = appendUint32(b, c.NetworkMask)
+		b = appendUint32(b, uint32(c.ExternalBit&0x80)<<24|c.Metric&0x00FFFFFF)
+		b = appendUint32(b, c.ForwardingAddress)
+		b = appendUint32(b, c.ExternalRouteTag)
+	case RouterLSA:
+		b = appendUint32(b, uint32(c.Flags)<<24|c.Options&0x00FFFFFF)
+		for _, r := range c.Routers {
+			b = append(b, r.Type, 0, uint8(r.Metric>>8), uint8(r.Metric))
+			b = appendUint32(b, r.InterfaceID)
+			b = appendUint32(b, r.NeighborInterfaceID)
+			b = appendUint32(b, r.NeighborRouterID)
+		}
+	case NetworkLSA:
+		b = appendUint32(b, c.Options&0x00FFFFFF)
+		for _, r := range c.AttachedRouter {
+			b = appendUint32(b, r)
+		}
+	case InterAreaPrefixLSA:
+		b = appendUint32(b, c.Metric&0x00FFFFFF)
+		b, err = appendOSPFPrefix(b, Prefix{
+			PrefixLength:  c.PrefixLength,
+			PrefixOptions: c.PrefixOptions,
+			AddressPrefix: c.AddressPrefix,
+		})
+	case InterAreaRouterLSA:
+		b = appendUint32(b, c.Options&0x00FFFFFF)
+		b = appendUint32(b, c.Metric&0x00FFFFFF)
+		b = appendUint32(b, c.DestinationRouterID)
+	case ASExternalLSA:
+		b = appendUint32(b, uint32(c.Flags)<<24|c.Metric&0x00FFFFFF)
+		if b, err = appendOSPFPrefix(b, Prefix{
+			PrefixLength:  c.PrefixLength,
+			PrefixOptions: c.PrefixOptions,
+			Metric:        c.RefLSType,
+			AddressPrefix: c.AddressPrefix,
+		}); err != nil {
+			return nil, err
+		}
+		if c.Flags&ASExternalLSAFlagF != 0 {
+			if len(c.ForwardingAddress) != 16 {
+				return nil, fmt.Errorf("AS-External LSA forwarding address must be 16 bytes, not %d", len(c.ForwardingAddress))
+			}
+			b = append(b, c.ForwardingAddress...)
+		}
+		if c.Flags&ASExternalLSAFlagT != 0 {
+			b = appendUint32(b, c.ExternalRouteTag)
+		}
+		if c.RefLSType != 0 {
+			b = appendUint32(b, c.RefLinkStateID)
+		}
+	case LinkLSA:
+		if len(c.LinkLocalAddress) != 16 {
+			return nil, fmt.Errorf("Link LSA link-local address must be 16 bytes, not %d", len(c.LinkLocalAddress))
+		}
+		b = appendUint32(b, uint32(c.RtrPriority)<<24|c.Options&0x00FFFFFF)
+		b = append(b, c.LinkLocalAddress...)
+		b = appendUint32(b, c.NumOfPrefixes)
+		for _, p := range c.Prefixes {
+			p.Metric = 0
+			if b, err = appendOSPFPrefix(b, p); err != nil {
+				return nil, err
+			}
+		}
+	case IntraAreaPrefixLSA:
+		b = append(b, uint8(c.NumOfPrefixes>>8), uint8(c.NumOfPrefixes), uint8(c.RefLSType>>8), uint8(c.RefLSType))
+		b = appendUint32(b, c.RefLinkStateID)
+		b = appendUint32(b, c.RefAdvRouter)
+		for _, p := range c.Prefixes {
+			if b, err = appendOSPFPrefix(b, p); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported LSA content %T", content)
+	}
+	return b, err
+}
+
+// appendOSPFPrefix appends an OSPFv3 address prefix, padding AddressPrefix
+// to a 32-bit boundary.  Prefix.Metric is written to the third and fourth
+// byte.
+func appendOSPFPrefix(b []byte, p Prefix) ([]byte, error) {
+	size := ospfPrefixSize(p.PrefixLength)
+	if p.PrefixLength > 128 || len(p.AddressPrefix) > size {
+		return nil, fmt.Errorf("invalid OSPFv3 prefix of length %d with %d address bytes", p.PrefixLength, len(p.AddressPrefix))
+	}
+	b = append(b, p.PrefixLength, p.PrefixOptions, uint8(p.Metric>>8), uint8(p.Metric))
+	b = append(b, p.AddressPrefix...)
+	return append(b, make([]byte, size-len(p.AddressPrefix))...), nil
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, uint8(v>>24), uint8(v>>16), uint8(v>>8), uint8(v))
+}
+
+// lsaChecksum computes the Fletcher checksum of a serialized LSA as
+// described in RFC 2328 section 12.1.7.  The checksum covers everything but
+// the LS age, and the current LS checksum field is treated as zero.
+func lsaChecksum(lsa []byte) uint16 {
+	return isoChecksum(lsa[2:], 14)
+}
+
+// isoChecksum computes the Fletcher checksum of ISO 8473 Annex C over data,
+// which is to be stored at checksumOffset.  The two bytes at checksumOffset
+// are treated as zero.
+func isoChecksum(data []byte, checksumOffset int) uint16 {
+	var c0, c1 int
+	for i := range data {
+		if i != checksumOffset && i != checksumOffset+1 {
+			c0 += int(data[i])
+		}
+		c0 %= 255
+		c1 = (c1 + c0) % 255
+	}
+	x := ((len(data)-checksumOffset-1)*c0 - c1) % 255
+	if x <= 0 {
+		x += 255
+	}
+	y := 510 - c0 - x
+	if y > 255 {
+		y -= 255
+	}
+	return uint16(x)<<8 | uint16(y)
+}
+
+// ospfv2MinLength returns the smallest valid length of an OSPFv2 packet of
+// type t, including its 24 byte header.
+func ospfv2MinLength(t OSPFType) int {
+	switch t {
+	case OSPFHello:
+		return 44
+	case OSPFDatabaseDescription:
+		return 32
+	case OSPFLinkStateUpdate:
+		return 28
+	}
+	return 24
+}
+
+// ospfv3MinLength returns the smallest valid length of an OSPFv3 packet of
+// type t, including its 16 byte header.
+func ospfv3MinLength(t OSPFType) int {
+	switch t {
+	case OSPFHello:
+		return 36
+	case OSPFDatabaseDescription:
+		return 28
+	case OSPFLinkStateUpdate:
+		return 20
+	}
+	return 16
+}
+
 // LayerType returns LayerTypeOSPF
 func (ospf *OSPFv2) LayerType() gopacket.LayerType {
 	return LayerTypeOSPF
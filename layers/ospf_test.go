@@ -7,6 +7,8 @@
 package layers
 
 import (
+	"bytes"
+	"net"
 	"reflect"
 	"testing"
 
@@ -41,6 +43,7 @@ func TestPacketOSPF2Hello(t *testing.T) {
 	}
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv2); ok {
 		want := &OSPFv2{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF2Hello[34:]},
 			OSPF: OSPF{
 				Version:      2,
 				Type:         OSPFHello,
@@ -104,6 +107,7 @@ func TestPacketOSPF3Hello(t *testing.T) {
 	}
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv3); ok {
 		want := &OSPFv3{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF3Hello[54:]},
 			OSPF: OSPF{
 				Version:      3,
 				Type:         OSPFHello,
@@ -160,6 +164,7 @@ func TestPacketOSPF2DBDesc(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv2); ok {
 		want := &OSPFv2{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF2DBDesc[34:]},
 			OSPF: OSPF{
 				Version:      2,
 				Type:         OSPFDatabaseDescription,
@@ -213,6 +218,7 @@ func TestPacketOSPF3DBDesc(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv6, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv3); ok {
 		want := &OSPFv3{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF3DBDesc[54:]},
 			OSPF: OSPF{
 				Version:      3,
 				Type:         OSPFDatabaseDescription,
@@ -266,6 +272,7 @@ func TestPacketOSPF2LSRequest(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv2); ok {
 		want := &OSPFv2{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF2LSRequest[34:]},
 			OSPF: OSPF{
 				Version:      2,
 				Type:         OSPFLinkStateRequest,
@@ -328,6 +335,7 @@ func TestPacketOSPF3LSRequest(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv6, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv3); ok {
 		want := &OSPFv3{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF3LSRequest[54:]},
 			OSPF: OSPF{
 				Version:      3,
 				Type:         OSPFLinkStateRequest,
@@ -444,6 +452,7 @@ func TestPacketOSPF2LSUpdate(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv2); ok {
 		want := &OSPFv2{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF2LSUpdate[34:]},
 			OSPF: OSPF{
 				Version:      2,
 				Type:         OSPFLinkStateUpdate,
@@ -646,6 +655,7 @@ func TestPacketOSPF2LSUpdateLSA2(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeDot1Q, LayerTypeIPv4, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv2); ok {
 		want := &OSPFv2{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF2LSUpdateLSA2[38:126]},
 			OSPF: OSPF{
 				Version:      2,
 				Type:         OSPFLinkStateUpdate,
@@ -744,6 +754,7 @@ func TestPacketOSPF2LSUpdateLSA7(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeDot1Q, LayerTypeIPv4, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv2); ok {
 		want := &OSPFv2{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF2LSUpdateLSA7[38:122]},
 			OSPF: OSPF{
 				Version:      2,
 				Type:         OSPFLinkStateUpdate,
@@ -861,6 +872,7 @@ func TestPacketOSPF3LSUpdate(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv6, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv3); ok {
 		want := &OSPFv3{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF3LSUpdate[54:]},
 			OSPF: OSPF{
 				Version:      3,
 				Type:         OSPFLinkStateUpdate,
@@ -1044,6 +1056,7 @@ func TestPacketOSPF2LSAck(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv2); ok {
 		want := &OSPFv2{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF2LSAck[34:]},
 			OSPF: OSPF{
 				Version:      2,
 				Type:         OSPFLinkStateAcknowledgment,
@@ -1115,6 +1128,7 @@ func TestPacketOSPF3LSAck(t *testing.T) {
 	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv6, LayerTypeOSPF}, t)
 	if got, ok := p.Layer(LayerTypeOSPF).(*OSPFv3); ok {
 		want := &OSPFv3{
+			BaseLayer: BaseLayer{Contents: testPacketOSPF3LSAck[54:]},
 			OSPF: OSPF{
 				Version:      3,
 				Type:         OSPFLinkStateAcknowledgment,
@@ -1215,3 +1229,412 @@ func BenchmarkDecodePacketPacket4(b *testing.B) {
 		gopacket.NewPacket(testPacketOSPF3LSAck, LinkTypeEthernet, gopacket.NoCopy)
 	}
 }
+
+func TestOSPF3SerializeRoundTrip(t *testing.T) {
+	for _, data := range [][]byte{
+		testPacketOSPF3Hello,
+		testPacketOSPF3DBDesc,
+		testPacketOSPF3LSRequest,
+		testPacketOSPF3LSUpdate,
+		testPacketOSPF3LSAck,
+	} {
+		p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default)
+		if p.ErrorLayer() != nil {
+			t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+		}
+		for _, r := range gopacket.VerifyChecksums(p) {
+			if r.Status != gopacket.ChecksumValid {
+				t.Errorf("%v checksum %v", r.LayerType, r.Status)
+			}
+		}
+		ospf := p.Layer(LayerTypeOSPF).(*OSPFv3)
+		if err := ospf.SetNetworkLayerForChecksum(p.NetworkLayer()); err != nil {
+			t.Fatal(err)
+		}
+		// Clear all checksums and lengths so they have to be recomputed.
+		ospf.Checksum = 0
+		ospf.PacketLength = 0
+		if update, ok := ospf.Content.(LSUpdate); ok {
+			for i := range update.LSAs {
+				update.LSAs[i].LSChecksum = 0
+				update.LSAs[i].Length = 0
+			}
+		}
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		if err := ospf.SerializeTo(buf, opts); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), data[54:]) {
+			t.Errorf("%v serialization mismatch:\ngot  %x\nwant %x", ospf.Type, buf.Bytes(), data[54:])
+		}
+	}
+}
+
+func TestOSPF3SerializeLSAs(t *testing.T) {
+	want := &OSPFv3{
+		OSPF: OSPF{
+			Version:  3,
+			Type:     OSPFLinkStateUpdate,
+			RouterID: 0x01010101,
+			AreaID:   0,
+			Content: LSUpdate{
+				LSAs: []LSA{
+					{
+						LSAheader: LSAheader{LSAge: 1, LSType: RouterLSAtype, AdvRouter: 0x01010101, LSSeqNumber: 0x80000001},
+						Content: RouterLSA{
+							Flags:   0x01,
+							Options: 0x33,
+							Routers: []Router{
+								{Type: 2, Metric: 10, InterfaceID: 5, NeighborInterfaceID: 5, NeighborRouterID: 0x02020202},
+								{Type: 1, Metric: 20, InterfaceID: 6, NeighborInterfaceID: 7, NeighborRouterID: 0x03030303},
+							},
+						},
+					},
+					{
+						LSAheader: LSAheader{LSAge: 1, LSType: NetworkLSAtype, LinkStateID: 5, AdvRouter: 0x01010101, LSSeqNumber: 0x80000001},
+						Content: NetworkLSA{
+							Options:        0x33,
+							AttachedRouter: []uint32{0x01010101, 0x02020202},
+						},
+					},
+					{
+						LSAheader: LSAheader{LSAge: 1, LSType: InterAreaRouterLSAtype, LinkStateID: 4, AdvRouter: 0x01010101, LSSeqNumber: 0x80000001},
+						Content: InterAreaRouterLSA{
+							Options:             0x33,
+							Metric:              30,
+							DestinationRouterID: 0x04040404,
+						},
+					},
+					{
+						LSAheader: LSAheader{LSAge: 1, LSType: ASExternalLSAtype, LinkStateID: 1, AdvRouter: 0x01010101, LSSeqNumber: 0x80000001},
+						Content: ASExternalLSA{
+							Flags:             ASExternalLSAFlagE | ASExternalLSAFlagF | ASExternalLSAFlagT,
+							Metric:            100,
+							PrefixLength:      48,
+							RefLSType:         0x4005,
+							AddressPrefix:     []byte{0x20, 0x01, 0x0d, 0xb8, 0x00, 0x01, 0x00, 0x00},
+							ForwardingAddress: net.ParseIP("2001:db8::1"),
+							ExternalRouteTag:  0x1234,
+							RefLinkStateID:    9,
+						},
+					},
+					{
+						LSAheader: LSAheader{LSAge: 1, LSType: IntraAreaPrefixLSAtype, AdvRouter: 0x01010101, LSSeqNumber: 0x80000001},
+						Content: IntraAreaPrefixLSA{
+							RefLSType:    RouterLSAtype,
+							RefAdvRouter: 0x01010101,
+							Prefixes: []Prefix{
+								{PrefixLength: 128, PrefixOptions: 0x02, AddressPrefix: net.ParseIP("2001:db8::1")},
+								{PrefixLength: 64, Metric: 10, AddressPrefix: []byte{0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x12}},
+								{PrefixLength: 0, Metric: 1},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	ip6 := &IPv6{
+		Version:    6,
+		NextHeader: IPProtocolOSPF,
+		HopLimit:   1,
+		SrcIP:      net.ParseIP("fe80::1"),
+		DstIP:      net.ParseIP("ff02::5"),
+	}
+	if err := want.SetNetworkLayerForChecksum(ip6); err != nil {
+		t.Fatal(err)
+	}
+	if err := gopacket.SerializeLayers(buf, opts, ip6, want); err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv6, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	for _, r := range gopacket.VerifyChecksums(p) {
+		if r.Status != gopacket.ChecksumValid {
+			t.Errorf("%v checksum %v", r.LayerType, r.Status)
+		}
+	}
+	got := p.Layer(LayerTypeOSPF).(*OSPFv3)
+	if got.PacketLength != 268 {
+		t.Errorf("packet length %d, want 268", got.PacketLength)
+	}
+	update := got.Content.(LSUpdate)
+	for i, lsa := range update.LSAs {
+		if lsa.LSChecksum == 0 || lsa.LSChecksum != lsaChecksum(got.Contents[20+lsaOffset(update.LSAs, i):][:lsa.Length]) {
+			t.Errorf("LSA %d checksum %#x", i, lsa.LSChecksum)
+		}
+	}
+	// A zero length prefix decodes as an empty address prefix.
+	iap := want.Content.(LSUpdate).LSAs[4].Content.(IntraAreaPrefixLSA)
+	iap.Prefixes[2].AddressPrefix = []byte{}
+	want.Content.(LSUpdate).LSAs[4].Content = iap
+	want.BaseLayer = got.BaseLayer
+	want.tcpipchecksum = got.tcpipchecksum
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OSPF round trip failed:\ngot  :\n%#v\n\nwant :\n%#v\n\n", got, want)
+	}
+}
+
+// lsaOffset returns the offset of the i'th LSA in an LS update body.
+func lsaOffset(lsas []LSA, i int) int {
+	offset := 0
+	for _, lsa := range lsas[:i] {
+		offset += int(lsa.Length)
+	}
+	return offset
+}
+
+func TestOSPF3DecodeInvalid(t *testing.T) {
+	valid := testPacketOSPF3LSUpdate[54:]
+	for _, tc := range []struct {
+		name   string
+		mutate func([]byte)
+	}{
+		{"packet length too large", func(b []byte) { b[3]++ }},
+		{"too many LSAs", func(b []byte) { b[19] = 8 }},
+		{"LSA length past packet", func(b []byte) { b[20+18] = 0xff }},
+		{"prefix length too large", func(b []byte) { b[44+24] = 129 }},
+		{"prefix past LSA", func(b []byte) { b[44+24] = 96 }},
+	} {
+		data := append([]byte(nil), valid...)
+		tc.mutate(data)
+		var ospf OSPFv3
+		if err := ospf.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", tc.name)
+		}
+	}
+}
+
+func TestOSPF2SerializeRoundTrip(t *testing.T) {
+	for _, data := range [][]byte{
+		testPacketOSPF2Hello,
+		testPacketOSPF2DBDesc,
+		testPacketOSPF2LSRequest,
+		testPacketOSPF2LSUpdate,
+		testPacketOSPF2LSAck,
+	} {
+		p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default)
+		if p.ErrorLayer() != nil {
+			t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+		}
+		for _, r := range gopacket.VerifyChecksums(p) {
+			if r.Status != gopacket.ChecksumValid {
+				t.Errorf("%v checksum %v", r.LayerType, r.Status)
+			}
+		}
+		ospf := p.Layer(LayerTypeOSPF).(*OSPFv2)
+		want := data[34 : 34+int(ospf.PacketLength)]
+		ospf.Checksum = 0
+		ospf.PacketLength = 0
+		if update, ok := ospf.Content.(LSUpdate); ok {
+			for i := range update.LSAs {
+				update.LSAs[i].LSChecksum = 0
+				update.LSAs[i].Length = 0
+			}
+		}
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		if err := ospf.SerializeTo(buf, opts); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("%v serialization mismatch:\ngot  %x\nwant %x", ospf.Type, buf.Bytes(), want)
+		}
+	}
+}
+
+// serializeOSPF2 serializes ospf in an IPv4 packet and decodes the result.
+func serializeOSPF2(t *testing.T, ospf *OSPFv2) (*OSPFv2, gopacket.Packet) {
+	ip := &IPv4{
+		Version:  4,
+		TTL:      1,
+		Protocol: IPProtocolOSPF,
+		SrcIP:    net.IP{192, 168, 170, 8},
+		DstIP:    net.IP{224, 0, 0, 5},
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, ip, ospf); err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	for _, r := range gopacket.VerifyChecksums(p) {
+		if r.Status != gopacket.ChecksumValid {
+			t.Errorf("%v checksum %v", r.LayerType, r.Status)
+		}
+	}
+	return p.Layer(LayerTypeOSPF).(*OSPFv2), p
+}
+
+func TestOSPF2SerializeSimplePassword(t *testing.T) {
+	hello := &OSPFv2{
+		OSPF: OSPF{
+			Version:  2,
+			Type:     OSPFHello,
+			RouterID: 0xc0a8aa08,
+			AreaID:   1,
+			Content: HelloPkgV2{
+				NetworkMask: 0xffffff00,
+				HelloPkg: HelloPkg{
+					RtrPriority:        1,
+					Options:            0x2,
+					HelloInterval:      10,
+					RouterDeadInterval: 40,
+					DesignatedRouterID: 0xc0a8aa08,
+					NeighborID:         []uint32{0xc0a8aa02, 0xc0a8aa03},
+				},
+			},
+		},
+		AuType:         OSPFAuthSimplePassword,
+		Authentication: 0x7365637265740000, // "secret"
+	}
+	got, _ := serializeOSPF2(t, hello)
+	if got.PacketLength != 52 || got.AuType != OSPFAuthSimplePassword || got.Authentication != 0x7365637265740000 {
+		t.Errorf("got length %d, authentication %d %#x", got.PacketLength, got.AuType, got.Authentication)
+	}
+	if !reflect.DeepEqual(got.Content, hello.Content) {
+		t.Errorf("Hello content mismatch:\ngot  %#v\nwant %#v", got.Content, hello.Content)
+	}
+	// The authentication field is not covered by the checksum.
+	checksum := hello.Checksum
+	hello.Authentication = 0x6f74686572000000
+	if got, _ = serializeOSPF2(t, hello); got.Checksum != checksum {
+		t.Errorf("checksum changed with password: %#x != %#x", got.Checksum, checksum)
+	}
+}
+
+func TestOSPF2SerializeCryptographic(t *testing.T) {
+	digest := []byte{
+		0x8a, 0x2d, 0x4d, 0x10, 0x73, 0x5f, 0x11, 0x2e, 0x4c, 0x7a, 0x61, 0x0d, 0x33, 0x12, 0x9e, 0x05,
+	}
+	dbd := &OSPFv2{
+		OSPF: OSPF{
+			Version:  2,
+			Type:     OSPFDatabaseDescription,
+			RouterID: 0xc0a8aa08,
+			AreaID:   1,
+			Checksum: 0xffff,
+			Content: DbDescPkg{
+				Options:      0x02,
+				InterfaceMTU: 1500,
+				Flags:        0x2,
+				DDSeqNumber:  1098361215,
+				LSAinfo: []LSAheader{
+					{LSAge: 3600, LSOptions: 0x22, LSType: RouterLSAtypeV2, LinkStateID: 0xc0a8aa02, AdvRouter: 0xc0a8aa02, LSSeqNumber: 0x80000001, LSChecksum: 0x4a8e, Length: 48},
+					{LSAge: 1, LSOptions: 0x22, LSType: SummaryLSANetworktypeV2, LinkStateID: 0xc0a80100, AdvRouter: 0xc0a8aa02, LSSeqNumber: 0x80000001, LSChecksum: 0x2c12, Length: 28},
+				},
+			},
+		},
+		AuType:         OSPFAuthCryptographic,
+		Authentication: 0x0000010000000005, // key ID 1, sequence number 5
+		AuthData:       digest,
+	}
+	got, p := serializeOSPF2(t, dbd)
+	if got.Checksum != 0 {
+		t.Errorf("checksum %#x with cryptographic authentication", got.Checksum)
+	}
+	if got.PacketLength != 72 || len(p.NetworkLayer().LayerPayload()) != 88 {
+		t.Errorf("packet length %d, IP payload %d", got.PacketLength, len(p.NetworkLayer().LayerPayload()))
+	}
+	if got.KeyID() != 1 || got.AuthDataLength() != 16 || got.CryptoSequenceNumber() != 5 {
+		t.Errorf("key ID %d, auth data length %d, sequence number %d", got.KeyID(), got.AuthDataLength(), got.CryptoSequenceNumber())
+	}
+	if !bytes.Equal(got.AuthData, digest) {
+		t.Errorf("auth data %x, want %x", got.AuthData, digest)
+	}
+	if !reflect.DeepEqual(got.Content, dbd.Content) {
+		t.Errorf("Database Description content mismatch:\ngot  %#v\nwant %#v", got.Content, dbd.Content)
+	}
+}
+
+func TestOSPF2SerializeLSAs(t *testing.T) {
+	update := &OSPFv2{
+		OSPF: OSPF{
+			Version:  2,
+			Type:     OSPFLinkStateUpdate,
+			RouterID: 0xc0a8aa02,
+			AreaID:   1,
+			Content: LSUpdate{
+				LSAs: []LSA{
+					{
+						LSAheader: LSAheader{LSAge: 1, LSOptions: 0x22, LSType: RouterLSAtypeV2, LinkStateID: 0xc0a8aa02, AdvRouter: 0xc0a8aa02, LSSeqNumber: 0x80000002},
+						Content: RouterLSAV2{
+							Flags: 0x03,
+							Routers: []RouterV2{
+								{Type: 2, LinkID: 0xc0a8aa02, LinkData: 0xc0a8aa02, Metric: 10},
+								{Type: 3, LinkID: 0xc0a80100, LinkData: 0xffffff00, Metric: 1},
+							},
+						},
+					},
+					{
+						LSAheader: LSAheader{LSAge: 1, LSOptions: 0x22, LSType: NetworkLSAtypeV2, LinkStateID: 0xc0a8aa02, AdvRouter: 0xc0a8aa02, LSSeqNumber: 0x80000001},
+						Content: NetworkLSAV2{
+							NetworkMask:    0xffffff00,
+							AttachedRouter: []uint32{0xc0a8aa02, 0xc0a8aa08},
+						},
+					},
+					{
+						LSAheader: LSAheader{LSAge: 1, LSOptions: 0x22, LSType: SummaryLSANetworktypeV2, LinkStateID: 0xc0a80200, AdvRouter: 0xc0a8aa02, LSSeqNumber: 0x80000001},
+						Content:   SummaryLSAV2{NetworkMask: 0xffffff00, Metric: 20},
+					},
+					{
+						LSAheader: LSAheader{LSAge: 1, LSOptions: 0x22, LSType: SummaryLSAASBRtypeV2, LinkStateID: 0xc0a8aa03, AdvRouter: 0xc0a8aa02, LSSeqNumber: 0x80000001},
+						Content:   SummaryLSAV2{Metric: 30},
+					},
+					{
+						LSAheader: LSAheader{LSAge: 1, LSOptions: 0x20, LSType: ASExternalLSAtypeV2, LinkStateID: 0x0a000000, AdvRouter: 0xc0a8aa02, LSSeqNumber: 0x80000001},
+						Content: ASExternalLSAV2{
+							NetworkMask:       0xff000000,
+							ExternalBit:       0x80,
+							Metric:            20,
+							ForwardingAddress: 0xc0a8aa01,
+							ExternalRouteTag:  7,
+						},
+					},
+				},
+			},
+		},
+	}
+	got, _ := serializeOSPF2(t, update)
+	if got.PacketLength != 200 {
+		t.Errorf("packet length %d, want 200", got.PacketLength)
+	}
+	lsas := got.Content.(LSUpdate).LSAs
+	for i, lsa := range lsas {
+		if lsa.LSChecksum == 0 || lsa.LSChecksum != lsaChecksum(got.Contents[28+lsaOffset(lsas, i):][:lsa.Length]) {
+			t.Errorf("LSA %d checksum %#x", i, lsa.LSChecksum)
+		}
+	}
+	if !reflect.DeepEqual(got.Content, update.Content) {
+		t.Errorf("LS Update content mismatch:\ngot  %#v\nwant %#v", got.Content, update.Content)
+	}
+}
+
+func TestOSPF2DecodeInvalid(t *testing.T) {
+	valid := testPacketOSPF2LSUpdate[34:]
+	for _, tc := range []struct {
+		name   string
+		mutate func([]byte)
+	}{
+		{"packet length too large", func(b []byte) { b[3]++ }},
+		{"packet length too small", func(b []byte) { b[2], b[3] = 0, 20 }},
+		{"too many LSAs", func(b []byte) { b[27] = 8 }},
+		{"LSA length past packet", func(b []byte) { b[28+18] = 0xff }},
+		{"cryptographic authentication data truncated", func(b []byte) { b[15], b[19] = OSPFAuthCryptographic, 16 }},
+	} {
+		data := append([]byte(nil), valid...)
+		tc.mutate(data)
+		var ospf OSPFv2
+		if err := ospf.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", tc.name)
+		}
+	}
+}
@@ -0,0 +1,877 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// PIMType is the type of a PIM message.
+type PIMType uint8
+
+// PIMType known values, see RFC 7761, section 4.9 and RFC 3973, section 4.7
+const (
+	PIMTypeHello        PIMType = 0
+	PIMTypeRegister     PIMType = 1
+	PIMTypeRegisterStop PIMType = 2
+	PIMTypeJoinPrune    PIMType = 3
+	PIMTypeBootstrap    PIMType = 4
+	PIMTypeAssert       PIMType = 5
+	PIMTypeGraft        PIMType = 6 // PIM-DM only
+	PIMTypeGraftAck     PIMType = 7 // PIM-DM only
+	PIMTypeCandidateRP  PIMType = 8
+	PIMTypeStateRefresh PIMType = 9 // PIM-DM only
+)
+
+func (t PIMType) String() string {
+	switch t {
+	case PIMTypeHello:
+		return "Hello"
+	case PIMTypeRegister:
+		return "Register"
+	case PIMTypeRegisterStop:
+		return "Register-Stop"
+	case PIMTypeJoinPrune:
+		return "Join/Prune"
+	case PIMTypeBootstrap:
+		return "Bootstrap"
+	case PIMTypeAssert:
+		return "Assert"
+	case PIMTypeGraft:
+		return "Graft"
+	case PIMTypeGraftAck:
+		return "Graft-Ack"
+	case PIMTypeCandidateRP:
+		return "Candidate-RP-Advertisement"
+	case PIMTypeStateRefresh:
+		return "State-Refresh"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(t))
+	}
+}
+
+// PIMHelloOptionType is the type of a PIM hello option.
+type PIMHelloOptionType uint16
+
+// PIMHelloOptionType known values, see RFC 7761, section 4.9.2
+const (
+	PIMHelloOptionHoldtime       PIMHelloOptionType = 1
+	PIMHelloOptionLANPruneDelay  PIMHelloOptionType = 2
+	PIMHelloOptionDRPriority     PIMHelloOptionType = 19
+	PIMHelloOptionGenerationID   PIMHelloOptionType = 20
+	PIMHelloOptionStateRefresh   PIMHelloOptionType = 21
+	PIMHelloOptionBidirCapable   PIMHelloOptionType = 22
+	PIMHelloOptionAddressList    PIMHelloOptionType = 24
+	PIMHelloOptionOldAddressList PIMHelloOptionType = 65001
+)
+
+// PIMHelloOption is a TLV option of a PIM hello message.
+type PIMHelloOption struct {
+	Type  PIMHelloOptionType
+	Value []byte
+}
+
+// PIMGroup is an encoded group address.
+type PIMGroup struct {
+	Address    net.IP
+	MaskLen    uint8
+	Bidir      bool
+	AdminScope bool
+}
+
+// PIMSource is an encoded source address of a join/prune message.
+type PIMSource struct {
+	Address  net.IP
+	MaskLen  uint8
+	Sparse   bool
+	Wildcard bool
+	RPT      bool
+}
+
+// PIMJoinPruneGroup is a group of a join/prune, graft or graft-ack message
+// with its joined and pruned sources.
+type PIMJoinPruneGroup struct {
+	Group  PIMGroup
+	Joined []PIMSource
+	Pruned []PIMSource
+}
+
+// PIMJoinPrune is the body of join/prune, graft and graft-ack messages.
+type PIMJoinPrune struct {
+	UpstreamNeighbor net.IP
+	Holdtime         uint16 // Seconds
+	Groups           []PIMJoinPruneGroup
+}
+
+// PIMRegister is the body of a register message, the encapsulated data
+// packet is the payload of the layer.
+type PIMRegister struct {
+	Border bool
+	Null   bool
+}
+
+// PIMRegisterStop is the body of a register-stop message.
+type PIMRegisterStop struct {
+	Group  PIMGroup
+	Source net.IP
+}
+
+// PIMBootstrapRP is a candidate RP of a bootstrap message group.
+type PIMBootstrapRP struct {
+	Address  net.IP
+	Holdtime uint16 // Seconds
+	Priority uint8
+}
+
+// PIMBootstrapGroup is a group of a bootstrap message.  RPCount is the
+// number of RPs of the group in all the fragments of the bootstrap message.
+type PIMBootstrapGroup struct {
+	Group   PIMGroup
+	RPCount uint8
+	RPs     []PIMBootstrapRP
+}
+
+// PIMBootstrap is the body of a bootstrap message.
+type PIMBootstrap struct {
+	FragmentTag uint16
+	HashMaskLen uint8
+	BSRPriority uint8
+	BSRAddress  net.IP
+	Groups      []PIMBootstrapGroup
+}
+
+// PIMAssert is the body of an assert message.
+type PIMAssert struct {
+	Group            PIMGroup
+	Source           net.IP
+	RPT              bool
+	MetricPreference uint32
+	Metric           uint32
+}
+
+// PIMStateRefresh is the body of a PIM-DM state refresh message.
+type PIMStateRefresh struct {
+	Group            PIMGroup
+	Source           net.IP
+	Originator       net.IP
+	RPT              bool
+	MetricPreference uint32
+	Metric           uint32
+	MaskLen          uint8
+	TTL              uint8
+	PruneIndicator   bool
+	PruneNow         bool
+	AssertOverride   bool
+	Interval         uint8 // Seconds
+}
+
+// PIM is a Protocol Independent Multicast version 2 message, used by both
+// PIM-SM (RFC 7761) and PIM-DM (RFC 3973).
+//
+// Depending on Type, one of the message fields is set.  Graft and
+// graft-ack messages use JoinPrune.  The bodies of candidate-RP
+// advertisements and unknown messages are left as the payload.
+//
+// The checksum covers the whole message, except for register messages
+// where only the first 8 bytes are covered.  Over IPv6 the checksum also
+// covers a pseudo-header, so SetNetworkLayerForChecksum must be called
+// before serializing or verifying the checksum of a PIM over IPv6 message.
+type PIM struct {
+	BaseLayer
+	Version  uint8
+	Type     PIMType
+	Reserved uint8
+	Checksum uint16
+
+	HelloOptions []PIMHelloOption
+	JoinPrune    *PIMJoinPrune
+	Register     *PIMRegister
+	RegisterStop *PIMRegisterStop
+	Bootstrap    *PIMBootstrap
+	Assert       *PIMAssert
+	StateRefresh *PIMStateRefresh
+
+	tcpipchecksum
+}
+
+// PIM address families and encoding type, see RFC 7761, section 4.9.1
+const (
+	pimFamilyIPv4      = 1
+	pimFamilyIPv6      = 2
+	pimEncodingNative  = 0
+	pimHeaderLength    = 4
+	pimRegisterBorder  = 0x80000000
+	pimRegisterNull    = 0x40000000
+	pimGroupBidir      = 0x80
+	pimGroupAdminScope = 0x01
+	pimSourceSparse    = 0x04
+	pimSourceWildcard  = 0x02
+	pimSourceRPT       = 0x01
+	pimRPTBit          = 0x80000000
+)
+
+// LayerType returns LayerTypePIM.
+func (p *PIM) LayerType() gopacket.LayerType { return LayerTypePIM }
+
+// CanDecode implements gopacket.DecodingLayer.
+func (p *PIM) CanDecode() gopacket.LayerClass {
+	return LayerTypePIM
+}
+
+// NextLayerType returns the layer type of the data packet encapsulated in
+// register messages, or gopacket.LayerTypePayload for unparsed message
+// bodies.
+func (p *PIM) NextLayerType() gopacket.LayerType {
+	if len(p.Payload) == 0 {
+		return gopacket.LayerTypeZero
+	}
+	if p.Type == PIMTypeRegister {
+		switch p.Payload[0] >> 4 {
+		case 4:
+			return LayerTypeIPv4
+		case 6:
+			return LayerTypeIPv6
+		}
+	}
+	return gopacket.LayerTypePayload
+}
+
+func decodePIM(data []byte, p gopacket.PacketBuilder) error {
+	pim := &PIM{}
+	return decodingLayerDecoder(pim, data, p)
+}
+
+// pimAddressSize returns the size of the addresses of family, encoded with
+// encoding.
+func pimAddressSize(family, encoding uint8) (int, error) {
+	if encoding != pimEncodingNative {
+		return 0, fmt.Errorf("unsupported PIM address encoding %d", encoding)
+	}
+	switch family {
+	case pimFamilyIPv4:
+		return net.IPv4len, nil
+	case pimFamilyIPv6:
+		return net.IPv6len, nil
+	}
+	return 0, fmt.Errorf("unsupported PIM address family %d", family)
+}
+
+func decodePIMUnicast(data []byte) (net.IP, int, error) {
+	if len(data) < 2 {
+		return nil, 0, errors.New("PIM encoded address too short")
+	}
+	size, err := pimAddressSize(data[0], data[1])
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(data) < 2+size {
+		return nil, 0, errors.New("PIM encoded address too short")
+	}
+	return net.IP(data[2 : 2+size]), 2 + size, nil
+}
+
+// decodePIMPrefix decodes the encoded group and source addresses, which
+// share their layout, returning the flags byte.
+func decodePIMPrefix(data []byte) (net.IP, uint8, uint8, int, error) {
+	if len(data) < 4 {
+		return nil, 0, 0, 0, errors.New("PIM encoded address too short")
+	}
+	size, err := pimAddressSize(data[0], data[1])
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+	if len(data) < 4+size {
+		return nil, 0, 0, 0, errors.New("PIM encoded address too short")
+	}
+	return net.IP(data[4 : 4+size]), data[2], data[3], 4 + size, nil
+}
+
+func decodePIMGroup(data []byte) (PIMGroup, int, error) {
+	ip, flags, maskLen, n, err := decodePIMPrefix(data)
+	if err != nil {
+		return PIMGroup{}, 0, err
+	}
+	return PIMGroup{
+		Address:    ip,
+		MaskLen:    maskLen,
+		Bidir:      flags&pimGroupBidir != 0,
+		AdminScope: flags&pimGroupAdminScope != 0,
+	}, n, nil
+}
+
+func decodePIMSource(data []byte) (PIMSource, int, error) {
+	ip, flags, maskLen, n, err := decodePIMPrefix(data)
+	if err != nil {
+		return PIMSource{}, 0, err
+	}
+	return PIMSource{
+		Address:  ip,
+		MaskLen:  maskLen,
+		Sparse:   flags&pimSourceSparse != 0,
+		Wildcard: flags&pimSourceWildcard != 0,
+		RPT:      flags&pimSourceRPT != 0,
+	}, n, nil
+}
+
+func appendPIMUnicast(b []byte, ip net.IP) ([]byte, error) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return append(append(b, pimFamilyIPv4, pimEncodingNative), ip4...), nil
+	}
+	if len(ip) == net.IPv6len {
+		return append(append(b, pimFamilyIPv6, pimEncodingNative), ip...), nil
+	}
+	return nil, fmt.Errorf("invalid PIM address %v", ip)
+}
+
+func appendPIMPrefix(b []byte, ip net.IP, flags, maskLen uint8) ([]byte, error) {
+	start := len(b)
+	b, err := appendPIMUnicast(b, ip)
+	if err != nil {
+		return nil, err
+	}
+	// Insert the flags and mask length after the family and encoding type
+	b = append(b, 0, 0)
+	copy(b[start+4:], b[start+2:len(b)-2])
+	b[start+2], b[start+3] = flags, maskLen
+	return b, nil
+}
+
+func (g *PIMGroup) append(b []byte) ([]byte, error) {
+	var flags uint8
+	if g.Bidir {
+		flags |= pimGroupBidir
+	}
+	if g.AdminScope {
+		flags |= pimGroupAdminScope
+	}
+	return appendPIMPrefix(b, g.Address, flags, g.MaskLen)
+}
+
+func (s *PIMSource) append(b []byte) ([]byte, error) {
+	var flags uint8
+	if s.Sparse {
+		flags |= pimSourceSparse
+	}
+	if s.Wildcard {
+		flags |= pimSourceWildcard
+	}
+	if s.RPT {
+		flags |= pimSourceRPT
+	}
+	return appendPIMPrefix(b, s.Address, flags, s.MaskLen)
+}
+
+func appendPIMMetric(b []byte, rpt bool, preference, metric uint32) ([]byte, error) {
+	if preference&pimRPTBit != 0 {
+		return nil, fmt.Errorf("PIM metric preference %d too large", preference)
+	}
+	if rpt {
+		preference |= pimRPTBit
+	}
+	b = append(b, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(b[len(b)-8:], preference)
+	binary.BigEndian.PutUint32(b[len(b)-4:], metric)
+	return b, nil
+}
+
+// DecodeFromBytes decodes the slice into the PIM struct.
+func (p *PIM) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < pimHeaderLength {
+		df.SetTruncated()
+		return errors.New("PIM message too short")
+	}
+	*p = PIM{tcpipchecksum: p.tcpipchecksum}
+	p.Version = data[0] >> 4
+	p.Type = PIMType(data[0] & 0x0f)
+	p.Reserved = data[1]
+	p.Checksum = binary.BigEndian.Uint16(data[2:4])
+	if p.Version != 2 {
+		return fmt.Errorf("unsupported PIM version %d", p.Version)
+	}
+	body := data[pimHeaderLength:]
+	var err error
+	switch p.Type {
+	case PIMTypeHello:
+		err = p.decodeHello(body)
+	case PIMTypeRegister:
+		if len(body) < 4 {
+			df.SetTruncated()
+			return errors.New("PIM register too short")
+		}
+		flags := binary.BigEndian.Uint32(body)
+		p.Register = &PIMRegister{
+			Border: flags&pimRegisterBorder != 0,
+			Null:   flags&pimRegisterNull != 0,
+		}
+		p.BaseLayer = BaseLayer{Contents: data[:pimHeaderLength+4], Payload: body[4:]}
+		return nil
+	case PIMTypeRegisterStop:
+		err = p.decodeRegisterStop(body)
+	case PIMTypeJoinPrune, PIMTypeGraft, PIMTypeGraftAck:
+		err = p.decodeJoinPrune(body)
+	case PIMTypeBootstrap:
+		err = p.decodeBootstrap(body)
+	case PIMTypeAssert:
+		err = p.decodeAssert(body)
+	case PIMTypeStateRefresh:
+		err = p.decodeStateRefresh(body)
+	default:
+		p.BaseLayer = BaseLayer{Contents: data[:pimHeaderLength], Payload: body}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	p.BaseLayer = BaseLayer{Contents: data}
+	return nil
+}
+
+func (p *PIM) decodeHello(data []byte) error {
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return errors.New("PIM hello option too short")
+		}
+		length := int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+length {
+			return fmt.Errorf("PIM hello option length %d too large", length)
+		}
+		p.HelloOptions = append(p.HelloOptions, PIMHelloOption{
+			Type:  PIMHelloOptionType(binary.BigEndian.Uint16(data[0:2])),
+			Value: data[4 : 4+length],
+		})
+		data = data[4+length:]
+	}
+	return nil
+}
+
+func (p *PIM) decodeRegisterStop(data []byte) error {
+	group, n, err := decodePIMGroup(data)
+	if err != nil {
+		return err
+	}
+	source, _, err := decodePIMUnicast(data[n:])
+	if err != nil {
+		return err
+	}
+	p.RegisterStop = &PIMRegisterStop{Group: group, Source: source}
+	return nil
+}
+
+func (p *PIM) decodeJoinPrune(data []byte) error {
+	neighbor, n, err := decodePIMUnicast(data)
+	if err != nil {
+		return err
+	}
+	data = data[n:]
+	if len(data) < 4 {
+		return errors.New("PIM join/prune too short")
+	}
+	jp := &PIMJoinPrune{
+		UpstreamNeighbor: neighbor,
+		Holdtime:         binary.BigEndian.Uint16(data[2:4]),
+	}
+	numGroups := int(data[1])
+	data = data[4:]
+	for i := 0; i < numGroups; i++ {
+		var g PIMJoinPruneGroup
+		if g.Group, n, err = decodePIMGroup(data); err != nil {
+			return err
+		}
+		data = data[n:]
+		if len(data) < 4 {
+			return errors.New("PIM join/prune group too short")
+		}
+		numJoined := int(binary.BigEndian.Uint16(data[0:2]))
+		numPruned := int(binary.BigEndian.Uint16(data[2:4]))
+		data = data[4:]
+		for j := 0; j < numJoined+numPruned; j++ {
+			source, n, err := decodePIMSource(data)
+			if err != nil {
+				return err
+			}
+			data = data[n:]
+			if j < numJoined {
+				g.Joined = append(g.Joined, source)
+			} else {
+				g.Pruned = append(g.Pruned, source)
+			}
+		}
+		jp.Groups = append(jp.Groups, g)
+	}
+	p.JoinPrune = jp
+	return nil
+}
+
+func (p *PIM) decodeBootstrap(data []byte) error {
+	if len(data) < 4 {
+		return errors.New("PIM bootstrap too short")
+	}
+	bsr := &PIMBootstrap{
+		FragmentTag: binary.BigEndian.Uint16(data[0:2]),
+		HashMaskLen: data[2],
+		BSRPriority: data[3],
+	}
+	var n int
+	var err error
+	if bsr.BSRAddress, n, err = decodePIMUnicast(data[4:]); err != nil {
+		return err
+	}
+	data = data[4+n:]
+	for len(data) > 0 {
+		var g PIMBootstrapGroup
+		if g.Group, n, err = decodePIMGroup(data); err != nil {
+			return err
+		}
+		data = data[n:]
+		if len(data) < 4 {
+			return errors.New("PIM bootstrap group too short")
+		}
+		g.RPCount = data[0]
+		numRPs := int(data[1])
+		data = data[4:]
+		for i := 0; i < numRPs; i++ {
+			var rp PIMBootstrapRP
+			if rp.Address, n, err = decodePIMUnicast(data); err != nil {
+				return err
+			}
+			data = data[n:]
+			if len(data) < 4 {
+				return errors.New("PIM bootstrap RP too short")
+			}
+			rp.Holdtime = binary.BigEndian.Uint16(data[0:2])
+			rp.Priority = data[2]
+			data = data[4:]
+			g.RPs = append(g.RPs, rp)
+		}
+		bsr.Groups = append(bsr.Groups, g)
+	}
+	p.Bootstrap = bsr
+	return nil
+}
+
+func (p *PIM) decodeAssert(data []byte) error {
+	group, n, err := decodePIMGroup(data)
+	if err != nil {
+		return err
+	}
+	source, m, err := decodePIMUnicast(data[n:])
+	if err != nil {
+		return err
+	}
+	data = data[n+m:]
+	if len(data) < 8 {
+		return errors.New("PIM assert too short")
+	}
+	preference := binary.BigEndian.Uint32(data[0:4])
+	p.Assert = &PIMAssert{
+		Group:            group,
+		Source:           source,
+		RPT:              preference&pimRPTBit != 0,
+		MetricPreference: preference &^ pimRPTBit,
+		Metric:           binary.BigEndian.Uint32(data[4:8]),
+	}
+	return nil
+}
+
+func (p *PIM) decodeStateRefresh(data []byte) error {
+	sr := &PIMStateRefresh{}
+	var n int
+	var err error
+	if sr.Group, n, err = decodePIMGroup(data); err != nil {
+		return err
+	}
+	data = data[n:]
+	if sr.Source, n, err = decodePIMUnicast(data); err != nil {
+		return err
+	}
+	data = data[n:]
+	if sr.Originator, n, err = decodePIMUnicast(data); err != nil {
+		return err
+	}
+	data = data[n:]
+	if len(data) < 12 {
+		return errors.New("PIM state refresh too short")
+	}
+	preference := binary.BigEndian.Uint32(data[0:4])
+	sr.RPT = preference&pimRPTBit != 0
+	sr.MetricPreference = preference &^ pimRPTBit
+	sr.Metric = binary.BigEndian.Uint32(data[4:8])
+	sr.MaskLen = data[8]
+	sr.TTL = data[9]
+	sr.PruneIndicator = data[10]&0x80 != 0
+	sr.PruneNow = data[10]&0x40 != 0
+	sr.AssertOverride = data[10]&0x20 != 0
+	sr.Interval = data[11]
+	p.StateRefresh = sr
+	return nil
+}
+
+// HelloOption returns the value of the first hello option of type t.
+func (p *PIM) HelloOption(t PIMHelloOptionType) ([]byte, bool) {
+	for _, o := range p.HelloOptions {
+		if o.Type == t {
+			return o.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Holdtime returns the holdtime hello option in seconds.
+func (p *PIM) Holdtime() (uint16, bool) {
+	if v, ok := p.HelloOption(PIMHelloOptionHoldtime); ok && len(v) == 2 {
+		return binary.BigEndian.Uint16(v), true
+	}
+	return 0, false
+}
+
+// DRPriority returns the DR priority hello option.
+func (p *PIM) DRPriority() (uint32, bool) {
+	if v, ok := p.HelloOption(PIMHelloOptionDRPriority); ok && len(v) == 4 {
+		return binary.BigEndian.Uint32(v), true
+	}
+	return 0, false
+}
+
+// GenerationID returns the generation ID hello option.
+func (p *PIM) GenerationID() (uint32, bool) {
+	if v, ok := p.HelloOption(PIMHelloOptionGenerationID); ok && len(v) == 4 {
+		return binary.BigEndian.Uint32(v), true
+	}
+	return 0, false
+}
+
+// SecondaryAddresses returns the addresses of the address list hello
+// options.
+func (p *PIM) SecondaryAddresses() ([]net.IP, error) {
+	var addrs []net.IP
+	for _, o := range p.HelloOptions {
+		if o.Type != PIMHelloOptionAddressList {
+			continue
+		}
+		for v := o.Value; len(v) > 0; {
+			ip, n, err := decodePIMUnicast(v)
+			if err != nil {
+				return nil, err
+			}
+			addrs = append(addrs, ip)
+			v = v[n:]
+		}
+	}
+	return addrs, nil
+}
+
+// PIMAddressListOption returns an address list hello option holding addrs.
+func PIMAddressListOption(addrs []net.IP) (PIMHelloOption, error) {
+	var value []byte
+	for _, ip := range addrs {
+		var err error
+		if value, err = appendPIMUnicast(value, ip); err != nil {
+			return PIMHelloOption{}, err
+		}
+	}
+	return PIMHelloOption{Type: PIMHelloOptionAddressList, Value: value}, nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+// See the docs for gopacket.SerializableLayer for more info.
+func (p *PIM) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	body, err := p.encodeBody()
+	if err != nil {
+		return err
+	}
+	bytes, err := b.PrependBytes(pimHeaderLength + len(body))
+	if err != nil {
+		return err
+	}
+	bytes[0] = p.Version<<4 | uint8(p.Type)&0x0f
+	bytes[1] = p.Reserved
+	bytes[2], bytes[3] = 0, 0
+	copy(bytes[pimHeaderLength:], body)
+	if opts.ComputeChecksums {
+		covered := b.Bytes()
+		if p.Type == PIMTypeRegister {
+			covered = covered[:pimHeaderLength+4]
+		}
+		if p.Checksum, err = p.computePIMChecksum(covered); err != nil {
+			return err
+		}
+	}
+	binary.BigEndian.PutUint16(bytes[2:4], p.Checksum)
+	return nil
+}
+
+func (p *PIM) encodeBody() (b []byte, err error) {
+	switch p.Type {
+	case PIMTypeHello:
+		for _, o := range p.HelloOptions {
+			if len(o.Value) > 0xffff {
+				return nil, fmt.Errorf("PIM hello option %d too long", o.Type)
+			}
+			b = append(b, 0, 0, 0, 0)
+			binary.BigEndian.PutUint16(b[len(b)-4:], uint16(o.Type))
+			binary.BigEndian.PutUint16(b[len(b)-2:], uint16(len(o.Value)))
+			b = append(b, o.Value...)
+		}
+	case PIMTypeRegister:
+		if p.Register == nil {
+			return nil, errors.New("PIM register message without register body")
+		}
+		var flags uint32
+		if p.Register.Border {
+			flags |= pimRegisterBorder
+		}
+		if p.Register.Null {
+			flags |= pimRegisterNull
+		}
+		b = make([]byte, 4)
+		binary.BigEndian.PutUint32(b, flags)
+	case PIMTypeRegisterStop:
+		rs := p.RegisterStop
+		if rs == nil {
+			return nil, errors.New("PIM register-stop message without register-stop body")
+		}
+		if b, err = rs.Group.append(b); err != nil {
+			return nil, err
+		}
+		return appendPIMUnicast(b, rs.Source)
+	case PIMTypeJoinPrune, PIMTypeGraft, PIMTypeGraftAck:
+		if p.JoinPrune == nil {
+			return nil, fmt.Errorf("PIM %v message without join/prune body", p.Type)
+		}
+		return p.JoinPrune.encode()
+	case PIMTypeBootstrap:
+		if p.Bootstrap == nil {
+			return nil, errors.New("PIM bootstrap message without bootstrap body")
+		}
+		return p.Bootstrap.encode()
+	case PIMTypeAssert:
+		a := p.Assert
+		if a == nil {
+			return nil, errors.New("PIM assert message without assert body")
+		}
+		if b, err = a.Group.append(b); err != nil {
+			return nil, err
+		}
+		if b, err = appendPIMUnicast(b, a.Source); err != nil {
+			return nil, err
+		}
+		return appendPIMMetric(b, a.RPT, a.MetricPreference, a.Metric)
+	case PIMTypeStateRefresh:
+		if p.StateRefresh == nil {
+			return nil, errors.New("PIM state refresh message without state refresh body")
+		}
+		return p.StateRefresh.encode()
+	}
+	// Other message bodies are serialized by the payload layer
+	return b, nil
+}
+
+func (jp *PIMJoinPrune) encode() (b []byte, err error) {
+	if len(jp.Groups) > 0xff {
+		return nil, fmt.Errorf("too many PIM join/prune groups: %d", len(jp.Groups))
+	}
+	if b, err = appendPIMUnicast(b, jp.UpstreamNeighbor); err != nil {
+		return nil, err
+	}
+	b = append(b, 0, uint8(len(jp.Groups)), uint8(jp.Holdtime>>8), uint8(jp.Holdtime))
+	for _, g := range jp.Groups {
+		if len(g.Joined) > 0xffff || len(g.Pruned) > 0xffff {
+			return nil, errors.New("too many PIM join/prune sources")
+		}
+		if b, err = g.Group.append(b); err != nil {
+			return nil, err
+		}
+		b = append(b, 0, 0, 0, 0)
+		binary.BigEndian.PutUint16(b[len(b)-4:], uint16(len(g.Joined)))
+		binary.BigEndian.PutUint16(b[len(b)-2:], uint16(len(g.Pruned)))
+		for _, sources := range [][]PIMSource{g.Joined, g.Pruned} {
+			for i := range sources {
+				if b, err = sources[i].append(b); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return b, nil
+}
+
+func (bsr *PIMBootstrap) encode() (b []byte, err error) {
+	b = append(b, uint8(bsr.FragmentTag>>8), uint8(bsr.FragmentTag), bsr.HashMaskLen, bsr.BSRPriority)
+	if b, err = appendPIMUnicast(b, bsr.BSRAddress); err != nil {
+		return nil, err
+	}
+	for _, g := range bsr.Groups {
+		if len(g.RPs) > 0xff {
+			return nil, fmt.Errorf("too many PIM bootstrap RPs: %d", len(g.RPs))
+		}
+		if b, err = g.Group.append(b); err != nil {
+			return nil, err
+		}
+		b = append(b, g.RPCount, uint8(len(g.RPs)), 0, 0)
+		for _, rp := range g.RPs {
+			if b, err = appendPIMUnicast(b, rp.Address); err != nil {
+				return nil, err
+			}
+			b = append(b, uint8(rp.Holdtime>>8), uint8(rp.Holdtime), rp.Priority, 0)
+		}
+	}
+	return b, nil
+}
+
+func (sr *PIMStateRefresh) encode() (b []byte, err error) {
+	if b, err = sr.Group.append(b); err != nil {
+		return nil, err
+	}
+	if b, err = appendPIMUnicast(b, sr.Source); err != nil {
+		return nil, err
+	}
+	if b, err = appendPIMUnicast(b, sr.Originator); err != nil {
+		return nil, err
+	}
+	if b, err = appendPIMMetric(b, sr.RPT, sr.MetricPreference, sr.Metric); err != nil {
+		return nil, err
+	}
+	var flags uint8
+	if sr.PruneIndicator {
+		flags |= 0x80
+	}
+	if sr.PruneNow {
+		flags |= 0x40
+	}
+	if sr.AssertOverride {
+		flags |= 0x20
+	}
+	return append(b, sr.MaskLen, sr.TTL, flags, sr.Interval), nil
+}
+
+// computePIMChecksum computes the checksum of covered, including the IPv6
+// pseudo-header if the network layer is IPv6.
+func (p *PIM) computePIMChecksum(covered []byte) (uint16, error) {
+	if _, ok := p.pseudoheader.(*IPv6); ok {
+		return p.computeChecksum(covered, IPProtocolPIM)
+	}
+	return tcpipChecksum(covered, 0), nil
+}
+
+// VerifyChecksum verifies the checksum of the PIM message, implementing
+// gopacket.ChecksumVerifier.
+func (p *PIM) VerifyChecksum() (bool, error) {
+	payload := p.Payload
+	if p.Type == PIMTypeRegister {
+		payload = nil
+	}
+	if _, ok := p.pseudoheader.(*IPv6); ok {
+		return p.verifyChecksum(p.Contents, payload, IPProtocolPIM)
+	}
+	return tcpipChecksumValid(p.Contents, payload, 0), nil
+}
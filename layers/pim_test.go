@@ -0,0 +1,281 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketPIMHello is a PIM-SM hello with holdtime, LAN prune delay, DR
+// priority and generation ID options.
+var testPacketPIMHello = []byte{
+	0x01, 0x00, 0x5e, 0x00, 0x00, 0x0d, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x36, 0x00, 0x00, 0x00, 0x00, 0x01, 0x67, 0x17, 0xea, 0xc0, 0xa8, 0x00, 0x02, 0xe0, 0x00,
+	0x00, 0x0d, 0x20, 0x00, 0x6a, 0xf9, 0x00, 0x01, 0x00, 0x02, 0x00, 0x69, 0x00, 0x02, 0x00, 0x04,
+	0x01, 0xf4, 0x09, 0xc4, 0x00, 0x13, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x14, 0x00, 0x04,
+	0x12, 0x34, 0x56, 0x78,
+}
+
+func TestPIMHello(t *testing.T) {
+	p := gopacket.NewPacket(testPacketPIMHello, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypePIM}, t)
+	pim := p.Layer(LayerTypePIM).(*PIM)
+	if pim.Version != 2 || pim.Type != PIMTypeHello || pim.Checksum != 0x6af9 {
+		t.Errorf("PIM header mismatch: %#v", pim)
+	}
+	want := []PIMHelloOption{
+		{Type: PIMHelloOptionHoldtime, Value: []byte{0x00, 0x69}},
+		{Type: PIMHelloOptionLANPruneDelay, Value: []byte{0x01, 0xf4, 0x09, 0xc4}},
+		{Type: PIMHelloOptionDRPriority, Value: []byte{0x00, 0x00, 0x00, 0x01}},
+		{Type: PIMHelloOptionGenerationID, Value: []byte{0x12, 0x34, 0x56, 0x78}},
+	}
+	if !reflect.DeepEqual(pim.HelloOptions, want) {
+		t.Errorf("PIM hello options mismatch:\ngot  %#v\nwant %#v", pim.HelloOptions, want)
+	}
+	if holdtime, ok := pim.Holdtime(); !ok || holdtime != 105 {
+		t.Errorf("holdtime %d, %v", holdtime, ok)
+	}
+	if priority, ok := pim.DRPriority(); !ok || priority != 1 {
+		t.Errorf("DR priority %d, %v", priority, ok)
+	}
+	if genID, ok := pim.GenerationID(); !ok || genID != 0x12345678 {
+		t.Errorf("generation ID %#x, %v", genID, ok)
+	}
+	for _, r := range gopacket.VerifyChecksums(p) {
+		if r.Status != gopacket.ChecksumValid {
+			t.Errorf("%v checksum %v", r.LayerType, r.Status)
+		}
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := pim.SerializeTo(buf, gopacket.SerializeOptions{ComputeChecksums: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testPacketPIMHello[34:]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), testPacketPIMHello[34:])
+	}
+}
+
+func TestPIMRoundTrip(t *testing.T) {
+	ipv4 := &IPv4{Version: 4, TTL: 1, Protocol: IPProtocolPIM, SrcIP: net.IP{192, 0, 2, 1}, DstIP: net.IP{224, 0, 0, 13}}
+	ipv6 := &IPv6{Version: 6, HopLimit: 1, NextHeader: IPProtocolPIM, SrcIP: net.ParseIP("fe80::1"), DstIP: net.ParseIP("ff02::d")}
+	group := PIMGroup{Address: net.IP{239, 1, 2, 3}, MaskLen: 32}
+	addrList, err := PIMAddressListOption([]net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name string
+		ip   gopacket.NetworkLayer
+		pim  *PIM
+	}{
+		{
+			name: "hello over IPv6",
+			ip:   ipv6,
+			pim: &PIM{
+				Version: 2,
+				Type:    PIMTypeHello,
+				HelloOptions: []PIMHelloOption{
+					{Type: PIMHelloOptionHoldtime, Value: []byte{0x00, 0x69}},
+					addrList,
+				},
+			},
+		},
+		{
+			name: "join/prune",
+			ip:   ipv4,
+			pim: &PIM{
+				Version: 2,
+				Type:    PIMTypeJoinPrune,
+				JoinPrune: &PIMJoinPrune{
+					UpstreamNeighbor: net.IP{192, 0, 2, 254},
+					Holdtime:         210,
+					Groups: []PIMJoinPruneGroup{
+						{
+							Group:  group,
+							Joined: []PIMSource{{Address: net.IP{10, 0, 0, 1}, MaskLen: 32, Sparse: true, Wildcard: true, RPT: true}},
+						},
+						{
+							Group:  PIMGroup{Address: net.IP{239, 1, 2, 4}, MaskLen: 32},
+							Joined: []PIMSource{{Address: net.IP{198, 51, 100, 7}, MaskLen: 32, Sparse: true}},
+							Pruned: []PIMSource{{Address: net.IP{198, 51, 100, 8}, MaskLen: 32, Sparse: true, RPT: true}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "graft",
+			ip:   ipv4,
+			pim: &PIM{
+				Version: 2,
+				Type:    PIMTypeGraft,
+				JoinPrune: &PIMJoinPrune{
+					UpstreamNeighbor: net.IP{192, 0, 2, 254},
+					Groups:           []PIMJoinPruneGroup{{Group: group, Joined: []PIMSource{{Address: net.IP{198, 51, 100, 7}, MaskLen: 32}}}},
+				},
+			},
+		},
+		{
+			name: "register-stop",
+			ip:   ipv6,
+			pim: &PIM{
+				Version: 2,
+				Type:    PIMTypeRegisterStop,
+				RegisterStop: &PIMRegisterStop{
+					Group:  PIMGroup{Address: net.ParseIP("ff3e::8000:1"), MaskLen: 128},
+					Source: net.ParseIP("2001:db8::10"),
+				},
+			},
+		},
+		{
+			name: "bootstrap",
+			ip:   ipv4,
+			pim: &PIM{
+				Version: 2,
+				Type:    PIMTypeBootstrap,
+				Bootstrap: &PIMBootstrap{
+					FragmentTag: 0x1234,
+					HashMaskLen: 30,
+					BSRPriority: 64,
+					BSRAddress:  net.IP{192, 0, 2, 100},
+					Groups: []PIMBootstrapGroup{
+						{
+							Group:   PIMGroup{Address: net.IP{224, 0, 0, 0}, MaskLen: 4},
+							RPCount: 2,
+							RPs: []PIMBootstrapRP{
+								{Address: net.IP{192, 0, 2, 101}, Holdtime: 150, Priority: 1},
+								{Address: net.IP{192, 0, 2, 102}, Holdtime: 150, Priority: 2},
+							},
+						},
+						{
+							Group:   PIMGroup{Address: net.IP{239, 0, 0, 0}, MaskLen: 8, AdminScope: true},
+							RPCount: 1,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "assert",
+			ip:   ipv4,
+			pim: &PIM{
+				Version: 2,
+				Type:    PIMTypeAssert,
+				Assert: &PIMAssert{
+					Group:            group,
+					Source:           net.IP{198, 51, 100, 7},
+					RPT:              true,
+					MetricPreference: 110,
+					Metric:           20,
+				},
+			},
+		},
+		{
+			name: "state refresh",
+			ip:   ipv4,
+			pim: &PIM{
+				Version: 2,
+				Type:    PIMTypeStateRefresh,
+				StateRefresh: &PIMStateRefresh{
+					Group:            group,
+					Source:           net.IP{198, 51, 100, 7},
+					Originator:       net.IP{192, 0, 2, 1},
+					MetricPreference: 1,
+					Metric:           2,
+					MaskLen:          24,
+					TTL:              16,
+					PruneIndicator:   true,
+					Interval:         60,
+				},
+			},
+		},
+	} {
+		test.pim.SetNetworkLayerForChecksum(test.ip)
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		if err := gopacket.SerializeLayers(buf, opts, test.ip.(gopacket.SerializableLayer), test.pim); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		p := gopacket.NewPacket(buf.Bytes(), test.ip.LayerType(), testDecodeOptions)
+		if p.ErrorLayer() != nil {
+			t.Errorf("%s: failed to decode packet: %v", test.name, p.ErrorLayer().Error())
+			continue
+		}
+		checkLayers(p, []gopacket.LayerType{test.ip.LayerType(), LayerTypePIM}, t)
+		got := p.Layer(LayerTypePIM).(*PIM)
+		for _, r := range gopacket.VerifyChecksums(p) {
+			if r.Status != gopacket.ChecksumValid {
+				t.Errorf("%s: %v checksum %v", test.name, r.LayerType, r.Status)
+			}
+		}
+		test.pim.BaseLayer = got.BaseLayer
+		test.pim.tcpipchecksum = got.tcpipchecksum
+		if !reflect.DeepEqual(got, test.pim) {
+			t.Errorf("%s: PIM mismatch:\ngot  %#v\nwant %#v", test.name, got, test.pim)
+		}
+	}
+
+	p := gopacket.NewPacket(func() []byte {
+		buf := gopacket.NewSerializeBuffer()
+		hello := &PIM{Version: 2, Type: PIMTypeHello, HelloOptions: []PIMHelloOption{addrList}}
+		hello.SetNetworkLayerForChecksum(ipv6)
+		if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}, ipv6, hello); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}(), LayerTypeIPv6, testDecodeOptions)
+	addrs, err := p.Layer(LayerTypePIM).(*PIM).SecondaryAddresses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("2001:db8::2")}; !reflect.DeepEqual(addrs, want) {
+		t.Errorf("secondary addresses %v, want %v", addrs, want)
+	}
+}
+
+func TestPIMRegister(t *testing.T) {
+	outer := &IPv4{Version: 4, TTL: 64, Protocol: IPProtocolPIM, SrcIP: net.IP{192, 0, 2, 1}, DstIP: net.IP{192, 0, 2, 100}}
+	pim := &PIM{Version: 2, Type: PIMTypeRegister, Register: &PIMRegister{Border: true}}
+	inner := &IPv4{Version: 4, TTL: 16, Protocol: IPProtocolUDP, SrcIP: net.IP{198, 51, 100, 7}, DstIP: net.IP{239, 1, 2, 3}}
+	udp := &UDP{SrcPort: 5000, DstPort: 5001}
+	udp.SetNetworkLayerForChecksum(inner)
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, outer, pim, inner, udp, gopacket.Payload("data")); err != nil {
+		t.Fatal(err)
+	}
+
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv4, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypePIM, LayerTypeIPv4, LayerTypeUDP, gopacket.LayerTypePayload}, t)
+	got := p.Layer(LayerTypePIM).(*PIM)
+	if !reflect.DeepEqual(got.Register, pim.Register) || len(got.Contents) != 8 {
+		t.Errorf("PIM register mismatch: %#v", got)
+	}
+	// Only the PIM header is covered by the checksum
+	if want := tcpipChecksum(append([]byte{0x21, 0, 0, 0}, 0x80, 0, 0, 0), 0); got.Checksum != want {
+		t.Errorf("checksum %#04x, want %#04x", got.Checksum, want)
+	}
+	for _, r := range gopacket.VerifyChecksums(p) {
+		if r.Status != gopacket.ChecksumValid {
+			t.Errorf("%v checksum %v", r.LayerType, r.Status)
+		}
+	}
+}
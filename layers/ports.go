@@ -9,6 +9,8 @@ package layers
 import (
 	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
 
 	"github.com/google/gopacket"
 )
@@ -53,17 +55,25 @@ func (a TCPPort) String() string {
 // Returns gopacket.LayerTypePayload for unknown/unsupported port numbers.
 func (a TCPPort) LayerType() gopacket.LayerType {
 	if tcpPortLayerTypeOverride.has(uint16(a)) {
-		return tcpPortLayerType[a]
+		return tcpPortLayerType.Load().(map[TCPPort]gopacket.LayerType)[a]
 	}
 	switch a {
 	case 53:
 		return LayerTypeDNS
+	case 139: // netbios-ssn
+		return LayerTypeNBSS
+	case 179: // bgp
+		return LayerTypeBGP
 	case 443: // https
 		return LayerTypeTLS
 	case 502: // modbustcp
 		return LayerTypeModbusTCP
+	case 514: // syslog
+		return LayerTypeSyslog
 	case 636: // ldaps
 		return LayerTypeTLS
+	case 646: // ldp
+		return LayerTypeLDP
 	case 989: // ftps-data
 		return LayerTypeTLS
 	case 990: // ftps
@@ -76,21 +86,43 @@ func (a TCPPort) LayerType() gopacket.LayerType {
 		return LayerTypeTLS
 	case 995: // pop3s
 		return LayerTypeTLS
+	case 1194: // openvpn
+		return LayerTypeOpenVPNTCP
+	case 3868: // diameter
+		return LayerTypeDiameter
+	case 4460: // ntske
+		return LayerTypeTLS
+	case 4739: // ipfix
+		return LayerTypeIPFIX
 	case 5061: // ips
 		return LayerTypeTLS
 	}
 	return gopacket.LayerTypePayload
 }
 
+// Port registrations replace the maps, so that ports can be registered while
+// packets are decoded.  The bit of a port is set after its map entry is
+// stored.
+var portLayerTypeMu sync.Mutex
+
 var tcpPortLayerTypeOverride bitfield
 
-var tcpPortLayerType = map[TCPPort]gopacket.LayerType{}
+var tcpPortLayerType atomic.Value // map[TCPPort]gopacket.LayerType
 
 // RegisterTCPPortLayerType creates a new mapping between a TCPPort
-// and an underlaying LayerType.
+// and an underlaying LayerType.  It may be called while packets are decoded
+// by other goroutines.
 func RegisterTCPPortLayerType(port TCPPort, layerType gopacket.LayerType) {
+	portLayerTypeMu.Lock()
+	defer portLayerTypeMu.Unlock()
+	old, _ := tcpPortLayerType.Load().(map[TCPPort]gopacket.LayerType)
+	m := make(map[TCPPort]gopacket.LayerType, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[port] = layerType
+	tcpPortLayerType.Store(m)
 	tcpPortLayerTypeOverride.set(uint16(port))
-	tcpPortLayerType[port] = layerType
 }
 
 // String returns the port as "number(name)" if there's a well-known port name,
@@ -110,7 +142,7 @@ func (a UDPPort) String() string {
 // Returns gopacket.LayerTypePayload for unknown/unsupported port numbers.
 func (a UDPPort) LayerType() gopacket.LayerType {
 	if udpPortLayerTypeOverride.has(uint16(a)) {
-		return udpPortLayerType[a]
+		return udpPortLayerType.Load().(map[UDPPort]gopacket.LayerType)[a]
 	}
 	switch a {
 	case 53:
@@ -121,39 +153,104 @@ func (a UDPPort) LayerType() gopacket.LayerType {
 		return LayerTypeDHCPv4
 	case 123:
 		return LayerTypeNTP
+	case 137: // netbios-ns
+		return LayerTypeNBNS
+	case 161: // snmp
+		return LayerTypeSNMP
+	case 162: // snmptrap
+		return LayerTypeSNMP
+	case 319: // ptp-event
+		return LayerTypePTP
+	case 320: // ptp-general
+		return LayerTypePTP
+	case 443:
+		return LayerTypeQUIC
+	case 500: // isakmp
+		return LayerTypeIKEv2
+	case 514: // syslog
+		return LayerTypeSyslog
+	case 520: // router
+		return LayerTypeRIP
+	case 521: // ripng
+		return LayerTypeRIPng
 	case 546:
 		return LayerTypeDHCPv6
 	case 547:
 		return LayerTypeDHCPv6
 	case 623:
 		return LayerTypeRMCP
+	case 646: // ldp
+		return LayerTypeLDP
+	case 1194: // openvpn
+		return LayerTypeOpenVPN
+	case 1701: // l2tp
+		return LayerTypeL2TP
 	case 1812:
 		return LayerTypeRADIUS
+	case 1985: // hsrp
+		return LayerTypeHSRP
+	case 2029: // hsrp-v6
+		return LayerTypeHSRP
+	case 2055: // netflow
+		return LayerTypeNetFlow
+	case 2123: // gtp-c
+		return LayerTypeGTPv2C
 	case 2152:
 		return LayerTypeGTPv1U
+	case 3391: // rd gateway
+		return LayerTypeDTLS
 	case 3784:
 		return LayerTypeBFD
+	case 4500: // ipsec-nat-t
+		return LayerTypeIPSecUDPEncap
+	case 4739: // ipfix
+		return LayerTypeIPFIX
+	case 4740: // ipfixs
+		return LayerTypeDTLS
 	case 4789:
 		return LayerTypeVXLAN
+	case 4790: // vxlan-gpe
+		return LayerTypeVXLANGPE
 	case 5060:
 		return LayerTypeSIP
+	case 5349: // turns
+		return LayerTypeDTLS
+	case 5353: // mdns
+		return LayerTypeDNS
+	case 5355:
+		return LayerTypeLLMNR
+	case 5684: // coaps
+		return LayerTypeDTLS
 	case 6081:
 		return LayerTypeGeneve
 	case 6343:
 		return LayerTypeSFlow
+	case 6514: // syslog-tls
+		return LayerTypeDTLS
+	case 51820: // wireguard
+		return LayerTypeWireGuard
 	}
 	return gopacket.LayerTypePayload
 }
 
 var udpPortLayerTypeOverride bitfield
 
-var udpPortLayerType = map[UDPPort]gopacket.LayerType{}
+var udpPortLayerType atomic.Value // map[UDPPort]gopacket.LayerType
 
 // RegisterUDPPortLayerType creates a new mapping between a UDPPort
-// and an underlaying LayerType.
+// and an underlaying LayerType.  It may be called while packets are decoded
+// by other goroutines.
 func RegisterUDPPortLayerType(port UDPPort, layerType gopacket.LayerType) {
+	portLayerTypeMu.Lock()
+	defer portLayerTypeMu.Unlock()
+	old, _ := udpPortLayerType.Load().(map[UDPPort]gopacket.LayerType)
+	m := make(map[UDPPort]gopacket.LayerType, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[port] = layerType
+	udpPortLayerType.Store(m)
 	udpPortLayerTypeOverride.set(uint16(port))
-	udpPortLayerType[port] = layerType
 }
 
 // String returns the port as "number(name)" if there's a well-known port name,
@@ -0,0 +1,80 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// profileTestPacket returns Ethernet/IPv4/UDP packet to dstPort with the given payload layers
+func profileTestPacket(t *testing.T, dstPort UDPPort, payload ...gopacket.SerializableLayer) []byte {
+	ip := &IPv4{Version: 4, TTL: 64, Protocol: IPProtocolUDP, SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}
+	udp := &UDP{SrcPort: 40000, DstPort: dstPort}
+	udp.SetNetworkLayerForChecksum(ip)
+	ls := []gopacket.SerializableLayer{
+		&Ethernet{SrcMAC: net.HardwareAddr{1, 2, 3, 4, 5, 6}, DstMAC: net.HardwareAddr{6, 5, 4, 3, 2, 1}, EthernetType: EthernetTypeIPv4},
+		ip, udp,
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, append(ls, payload...)...); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func profileTestLayerTypes(p gopacket.Packet) []gopacket.LayerType {
+	var types []gopacket.LayerType
+	for _, l := range p.Layers() {
+		types = append(types, l.LayerType())
+	}
+	return types
+}
+
+func TestDecodeProfile(t *testing.T) {
+	dns := &DNS{ID: 1, Questions: []DNSQuestion{{Name: []byte("example.com"), Type: DNSTypeA, Class: DNSClassIN}}}
+	dnsPacket := profileTestPacket(t, 9999, dns)
+	inner := profileTestPacket(t, 53, dns)
+	vxlanPacket := profileTestPacket(t, 4789, &VXLAN{ValidIDFlag: true, VNI: 42}, gopacket.Payload(inner))
+
+	outer := []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP}
+	tunnel := append(append([]gopacket.LayerType(nil), outer...), LayerTypeVXLAN, LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeDNS)
+	tests := []struct {
+		name    string
+		data    []byte
+		profile *gopacket.DecodeProfile
+		want    []gopacket.LayerType
+	}{
+		{"no profile", dnsPacket, nil, append(outer, gopacket.LayerTypePayload)},
+		{"port", dnsPacket, gopacket.NewDecodeProfile("dns").Port(EndpointUDPPort, 9999, LayerTypeDNS), append(outer, LayerTypeDNS)},
+		{"payload port", inner, gopacket.NewDecodeProfile("opaque").Port(EndpointUDPPort, 53, gopacket.LayerTypePayload), append(outer, gopacket.LayerTypePayload)},
+		{"registered ports", vxlanPacket, gopacket.NewDecodeProfile("default"), tunnel},
+		{"exclusive ports", vxlanPacket, gopacket.NewDecodeProfile("dns").Port(EndpointUDPPort, 53, LayerTypeDNS).ExclusivePorts(), append(outer, gopacket.LayerTypePayload)},
+		{"transitions", vxlanPacket, gopacket.NewDecodeProfile("tcp").Allow(LayerTypeIPv4, LayerTypeTCP), []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, gopacket.LayerTypePayload}},
+		{"tunnel depth", vxlanPacket, gopacket.NewDecodeProfile("flat").MaxTunnelDepth(0, LayerTypeVXLAN), append(outer, gopacket.LayerTypePayload)},
+		{"decoder", dnsPacket, gopacket.NewDecodeProfile("fragment").Port(EndpointUDPPort, 9999, LayerTypeDNS).SetDecoder(LayerTypeDNS, gopacket.DecodeFragment), append(outer, gopacket.LayerTypeFragment)},
+	}
+	for _, test := range tests {
+		for _, lazy := range []bool{false, true} {
+			p := gopacket.NewPacket(test.data, LinkTypeEthernet, gopacket.DecodeOptions{Lazy: lazy, Profile: test.profile})
+			if got := profileTestLayerTypes(p); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("%s (lazy %v): expected layers %v, got %v", test.name, lazy, test.want, got)
+			}
+		}
+	}
+
+	// profile decoders take precedence over overrides
+	profile := gopacket.NewDecodeProfile("dns").SetDecoder(LayerTypeDNS, gopacket.DecodeFragment)
+	overrides := gopacket.NewDecoderOverrides(map[gopacket.LayerType]gopacket.Decoder{LayerTypeDNS: gopacket.DecodePayload})
+	p := gopacket.NewPacket(profileTestPacket(t, 53, dns), LinkTypeEthernet, gopacket.DecodeOptions{Profile: profile, DecoderOverrides: overrides})
+	if got := profileTestLayerTypes(p); !reflect.DeepEqual(got, append(outer, gopacket.LayerTypeFragment)) {
+		t.Errorf("expected profile decoder, got %v", got)
+	}
+}
@@ -0,0 +1,528 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// PTPMessageType is the type of a Precision Time Protocol message.
+type PTPMessageType uint8
+
+// PTPMessageType known values, see IEEE 1588-2019, section 13.3.2.3
+const (
+	PTPMessageTypeSync               PTPMessageType = 0x0
+	PTPMessageTypeDelayReq           PTPMessageType = 0x1
+	PTPMessageTypePdelayReq          PTPMessageType = 0x2
+	PTPMessageTypePdelayResp         PTPMessageType = 0x3
+	PTPMessageTypeFollowUp           PTPMessageType = 0x8
+	PTPMessageTypeDelayResp          PTPMessageType = 0x9
+	PTPMessageTypePdelayRespFollowUp PTPMessageType = 0xa
+	PTPMessageTypeAnnounce           PTPMessageType = 0xb
+	PTPMessageTypeSignaling          PTPMessageType = 0xc
+	PTPMessageTypeManagement         PTPMessageType = 0xd
+)
+
+func (t PTPMessageType) String() string {
+	switch t {
+	case PTPMessageTypeSync:
+		return "Sync"
+	case PTPMessageTypeDelayReq:
+		return "Delay_Req"
+	case PTPMessageTypePdelayReq:
+		return "Pdelay_Req"
+	case PTPMessageTypePdelayResp:
+		return "Pdelay_Resp"
+	case PTPMessageTypeFollowUp:
+		return "Follow_Up"
+	case PTPMessageTypeDelayResp:
+		return "Delay_Resp"
+	case PTPMessageTypePdelayRespFollowUp:
+		return "Pdelay_Resp_Follow_Up"
+	case PTPMessageTypeAnnounce:
+		return "Announce"
+	case PTPMessageTypeSignaling:
+		return "Signaling"
+	case PTPMessageTypeManagement:
+		return "Management"
+	}
+	return fmt.Sprintf("Unknown(%d)", uint8(t))
+}
+
+// Event reports whether messages of the type are event messages, which are
+// timestamped when sent and received, and sent to UDP port 319.
+func (t PTPMessageType) Event() bool {
+	return t < 0x8
+}
+
+// PTPFlags are the flags of the PTP header.
+type PTPFlags uint16
+
+// PTPFlags known values, see IEEE 1588-2019, section 13.3.2.8
+const (
+	PTPFlagsAlternateMaster    PTPFlags = 0x0100
+	PTPFlagsTwoStep            PTPFlags = 0x0200
+	PTPFlagsUnicast            PTPFlags = 0x0400
+	PTPFlagsProfileSpecific1   PTPFlags = 0x2000
+	PTPFlagsProfileSpecific2   PTPFlags = 0x4000
+	PTPFlagsLeap61             PTPFlags = 0x0001
+	PTPFlagsLeap59             PTPFlags = 0x0002
+	PTPFlagsCurrentUTCOffset   PTPFlags = 0x0004
+	PTPFlagsTimescale          PTPFlags = 0x0008
+	PTPFlagsTimeTraceable      PTPFlags = 0x0010
+	PTPFlagsFrequencyTraceable PTPFlags = 0x0020
+)
+
+// PTPClockIdentity identifies a PTP clock, usually derived from a MAC
+// address.
+type PTPClockIdentity [8]byte
+
+// String returns the identity in the usual 001122.fffe.334455 form.
+func (c PTPClockIdentity) String() string {
+	return fmt.Sprintf("%02x%02x%02x.%02x%02x.%02x%02x%02x", c[0], c[1], c[2], c[3], c[4], c[5], c[6], c[7])
+}
+
+// PTPPortIdentity identifies a port of a PTP clock.
+type PTPPortIdentity struct {
+	ClockIdentity PTPClockIdentity
+	PortNumber    uint16
+}
+
+func (p PTPPortIdentity) String() string {
+	return fmt.Sprintf("%v-%d", p.ClockIdentity, p.PortNumber)
+}
+
+func decodePTPPortIdentity(data []byte) PTPPortIdentity {
+	var p PTPPortIdentity
+	copy(p.ClockIdentity[:], data[0:8])
+	p.PortNumber = binary.BigEndian.Uint16(data[8:10])
+	return p
+}
+
+func (p PTPPortIdentity) encode(data []byte) {
+	copy(data[0:8], p.ClockIdentity[:])
+	binary.BigEndian.PutUint16(data[8:10], p.PortNumber)
+}
+
+// PTPTimestamp is a PTP timestamp, in the PTP timescale (TAI) unless the
+// Timescale flag is unset.
+type PTPTimestamp struct {
+	Seconds     uint64 // 48 bits
+	Nanoseconds uint32
+}
+
+// Time returns the timestamp as a time.Time.
+func (t PTPTimestamp) Time() time.Time {
+	return time.Unix(int64(t.Seconds), int64(t.Nanoseconds))
+}
+
+func decodePTPTimestamp(data []byte) PTPTimestamp {
+	return PTPTimestamp{
+		Seconds:     uint64(binary.BigEndian.Uint16(data[0:2]))<<32 | uint64(binary.BigEndian.Uint32(data[2:6])),
+		Nanoseconds: binary.BigEndian.Uint32(data[6:10]),
+	}
+}
+
+func (t PTPTimestamp) encode(data []byte) {
+	binary.BigEndian.PutUint16(data[0:2], uint16(t.Seconds>>32))
+	binary.BigEndian.PutUint32(data[2:6], uint32(t.Seconds))
+	binary.BigEndian.PutUint32(data[6:10], t.Nanoseconds)
+}
+
+// PTPClockQuality describes the quality of a grandmaster clock.
+type PTPClockQuality struct {
+	ClockClass              uint8
+	ClockAccuracy           uint8
+	OffsetScaledLogVariance uint16
+}
+
+// PTPAnnounce holds the fields of Announce messages.
+type PTPAnnounce struct {
+	CurrentUTCOffset        int16
+	GrandmasterPriority1    uint8
+	GrandmasterClockQuality PTPClockQuality
+	GrandmasterPriority2    uint8
+	GrandmasterIdentity     PTPClockIdentity
+	StepsRemoved            uint16
+	TimeSource              uint8
+}
+
+// PTPManagementAction is the action of management messages.
+type PTPManagementAction uint8
+
+// PTPManagementAction known values.
+const (
+	PTPManagementActionGet         PTPManagementAction = 0
+	PTPManagementActionSet         PTPManagementAction = 1
+	PTPManagementActionResponse    PTPManagementAction = 2
+	PTPManagementActionCommand     PTPManagementAction = 3
+	PTPManagementActionAcknowledge PTPManagementAction = 4
+)
+
+func (a PTPManagementAction) String() string {
+	switch a {
+	case PTPManagementActionGet:
+		return "GET"
+	case PTPManagementActionSet:
+		return "SET"
+	case PTPManagementActionResponse:
+		return "RESPONSE"
+	case PTPManagementActionCommand:
+		return "COMMAND"
+	case PTPManagementActionAcknowledge:
+		return "ACKNOWLEDGE"
+	}
+	return fmt.Sprintf("Unknown(%d)", uint8(a))
+}
+
+// PTPManagement holds the fields of management messages.
+type PTPManagement struct {
+	StartingBoundaryHops uint8
+	BoundaryHops         uint8
+	Action               PTPManagementAction
+}
+
+// PTPTLVType is the type of PTP TLVs.
+type PTPTLVType uint16
+
+// PTPTLVType known values, see IEEE 1588-2019, section 14.1.1
+const (
+	PTPTLVManagement                   PTPTLVType = 0x0001
+	PTPTLVManagementErrorStatus        PTPTLVType = 0x0002
+	PTPTLVOrganizationExtension        PTPTLVType = 0x0003
+	PTPTLVRequestUnicastTransmission   PTPTLVType = 0x0004
+	PTPTLVGrantUnicastTransmission     PTPTLVType = 0x0005
+	PTPTLVCancelUnicastTransmission    PTPTLVType = 0x0006
+	PTPTLVAckCancelUnicastTransmission PTPTLVType = 0x0007
+	PTPTLVPathTrace                    PTPTLVType = 0x0008
+	PTPTLVAlternateTimeOffsetIndicator PTPTLVType = 0x0009
+)
+
+func (t PTPTLVType) String() string {
+	switch t {
+	case PTPTLVManagement:
+		return "Management"
+	case PTPTLVManagementErrorStatus:
+		return "ManagementErrorStatus"
+	case PTPTLVOrganizationExtension:
+		return "OrganizationExtension"
+	case PTPTLVRequestUnicastTransmission:
+		return "RequestUnicastTransmission"
+	case PTPTLVGrantUnicastTransmission:
+		return "GrantUnicastTransmission"
+	case PTPTLVCancelUnicastTransmission:
+		return "CancelUnicastTransmission"
+	case PTPTLVAckCancelUnicastTransmission:
+		return "AckCancelUnicastTransmission"
+	case PTPTLVPathTrace:
+		return "PathTrace"
+	case PTPTLVAlternateTimeOffsetIndicator:
+		return "AlternateTimeOffsetIndicator"
+	}
+	return fmt.Sprintf("Unknown(0x%04x)", uint16(t))
+}
+
+// PTPTLV is a TLV following the body of PTP messages.
+type PTPTLV struct {
+	Type  PTPTLVType
+	Value []byte
+}
+
+// ManagementID returns the management ID and data of Management TLVs, or
+// the management ID and error status of Management Error Status TLVs.
+func (t PTPTLV) ManagementID() (uint16, []byte, error) {
+	switch t.Type {
+	case PTPTLVManagement:
+		if len(t.Value) < 2 {
+			return 0, nil, errors.New("PTP management TLV too short")
+		}
+		return binary.BigEndian.Uint16(t.Value[0:2]), t.Value[2:], nil
+	case PTPTLVManagementErrorStatus:
+		if len(t.Value) < 8 {
+			return 0, nil, errors.New("PTP management error status TLV too short")
+		}
+		return binary.BigEndian.Uint16(t.Value[2:4]), t.Value[0:2], nil
+	}
+	return 0, nil, fmt.Errorf("PTP TLV %v isn't a management TLV", t.Type)
+}
+
+// PathTrace returns the clock identities of Path Trace TLVs.
+func (t PTPTLV) PathTrace() ([]PTPClockIdentity, error) {
+	if t.Type != PTPTLVPathTrace || len(t.Value)%8 != 0 {
+		return nil, errors.New("invalid PTP path trace TLV")
+	}
+	path := make([]PTPClockIdentity, len(t.Value)/8)
+	for i := range path {
+		copy(path[i][:], t.Value[8*i:])
+	}
+	return path, nil
+}
+
+const ptpHeaderLength = 34
+
+// PTP contains a Precision Time Protocol version 2 message, sent over UDP or
+// directly over Ethernet, see IEEE 1588-2019, section 13.
+type PTP struct {
+	BaseLayer
+
+	// Header
+	MajorSdoID          uint8 // transportSpecific in IEEE 1588-2008
+	MessageType         PTPMessageType
+	MinorVersion        uint8
+	Version             uint8
+	MessageLength       uint16
+	DomainNumber        uint8
+	MinorSdoID          uint8
+	Flags               PTPFlags
+	CorrectionField     int64 // Nanoseconds multiplied by 2^16
+	MessageTypeSpecific uint32
+	SourcePortIdentity  PTPPortIdentity
+	SequenceID          uint16
+	ControlField        uint8
+	LogMessageInterval  int8
+
+	// Timestamp is the originTimestamp of Sync, Delay_Req, Pdelay_Req and
+	// Announce messages, the preciseOriginTimestamp of Follow_Up messages,
+	// the receiveTimestamp of Delay_Resp messages, the
+	// requestReceiptTimestamp of Pdelay_Resp messages and the
+	// responseOriginTimestamp of Pdelay_Resp_Follow_Up messages.
+	Timestamp PTPTimestamp
+	// RequestingPortIdentity is set in Delay_Resp, Pdelay_Resp and
+	// Pdelay_Resp_Follow_Up messages.
+	RequestingPortIdentity PTPPortIdentity
+	Announce               PTPAnnounce
+	// TargetPortIdentity is set in Signaling and Management messages.
+	TargetPortIdentity PTPPortIdentity
+	Management         PTPManagement
+	TLVs               []PTPTLV
+}
+
+// LayerType returns LayerTypePTP.
+func (p *PTP) LayerType() gopacket.LayerType { return LayerTypePTP }
+
+// Correction returns the correction field as a duration, dropping its
+// sub-nanosecond part.
+func (p *PTP) Correction() time.Duration {
+	return time.Duration(p.CorrectionField >> 16)
+}
+
+// TLV returns the first TLV of type t, or nil.
+func (p *PTP) TLV(t PTPTLVType) *PTPTLV {
+	for i := range p.TLVs {
+		if p.TLVs[i].Type == t {
+			return &p.TLVs[i]
+		}
+	}
+	return nil
+}
+
+// ptpBodyLength returns the length of the body of messages of type t,
+// between the header and the TLVs.
+func ptpBodyLength(t PTPMessageType) int {
+	switch t {
+	case PTPMessageTypeSync, PTPMessageTypeDelayReq, PTPMessageTypeFollowUp:
+		return 10
+	case PTPMessageTypePdelayReq, PTPMessageTypePdelayResp, PTPMessageTypeDelayResp, PTPMessageTypePdelayRespFollowUp:
+		return 20
+	case PTPMessageTypeAnnounce:
+		return 30
+	case PTPMessageTypeSignaling:
+		return 10
+	case PTPMessageTypeManagement:
+		return 14
+	}
+	return 0
+}
+
+func decodePTP(data []byte, p gopacket.PacketBuilder) error {
+	ptp := &PTP{}
+	if err := ptp.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(ptp)
+	p.SetApplicationLayer(ptp)
+	return nil
+}
+
+// DecodeFromBytes decodes the slice into the PTP struct.
+func (p *PTP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < ptpHeaderLength {
+		df.SetTruncated()
+		return errors.New("PTP message too short")
+	}
+	p.MajorSdoID = data[0] >> 4
+	p.MessageType = PTPMessageType(data[0] & 0xf)
+	p.MinorVersion = data[1] >> 4
+	p.Version = data[1] & 0xf
+	p.MessageLength = binary.BigEndian.Uint16(data[2:4])
+	p.DomainNumber = data[4]
+	p.MinorSdoID = data[5]
+	p.Flags = PTPFlags(binary.BigEndian.Uint16(data[6:8]))
+	p.CorrectionField = int64(binary.BigEndian.Uint64(data[8:16]))
+	p.MessageTypeSpecific = binary.BigEndian.Uint32(data[16:20])
+	p.SourcePortIdentity = decodePTPPortIdentity(data[20:30])
+	p.SequenceID = binary.BigEndian.Uint16(data[30:32])
+	p.ControlField = data[32]
+	p.LogMessageInterval = int8(data[33])
+	if p.Version != 2 {
+		return fmt.Errorf("unsupported PTP version %d", p.Version)
+	}
+
+	length := int(p.MessageLength)
+	bodyLength := ptpBodyLength(p.MessageType)
+	if length < ptpHeaderLength+bodyLength {
+		return fmt.Errorf("invalid PTP message length %d", length)
+	}
+	if length > len(data) {
+		df.SetTruncated()
+		return errors.New("PTP message length exceeds packet")
+	}
+	// Ethernet padding follows short messages
+	p.BaseLayer = BaseLayer{Contents: data[:length]}
+	body := data[ptpHeaderLength:length]
+
+	p.Timestamp = PTPTimestamp{}
+	p.RequestingPortIdentity = PTPPortIdentity{}
+	p.Announce = PTPAnnounce{}
+	p.TargetPortIdentity = PTPPortIdentity{}
+	p.Management = PTPManagement{}
+	switch p.MessageType {
+	case PTPMessageTypeSync, PTPMessageTypeDelayReq, PTPMessageTypeFollowUp, PTPMessageTypePdelayReq:
+		p.Timestamp = decodePTPTimestamp(body)
+	case PTPMessageTypePdelayResp, PTPMessageTypeDelayResp, PTPMessageTypePdelayRespFollowUp:
+		p.Timestamp = decodePTPTimestamp(body)
+		p.RequestingPortIdentity = decodePTPPortIdentity(body[10:20])
+	case PTPMessageTypeAnnounce:
+		p.Timestamp = decodePTPTimestamp(body)
+		a := &p.Announce
+		a.CurrentUTCOffset = int16(binary.BigEndian.Uint16(body[10:12]))
+		a.GrandmasterPriority1 = body[13]
+		a.GrandmasterClockQuality.ClockClass = body[14]
+		a.GrandmasterClockQuality.ClockAccuracy = body[15]
+		a.GrandmasterClockQuality.OffsetScaledLogVariance = binary.BigEndian.Uint16(body[16:18])
+		a.GrandmasterPriority2 = body[18]
+		copy(a.GrandmasterIdentity[:], body[19:27])
+		a.StepsRemoved = binary.BigEndian.Uint16(body[27:29])
+		a.TimeSource = body[29]
+	case PTPMessageTypeSignaling:
+		p.TargetPortIdentity = decodePTPPortIdentity(body)
+	case PTPMessageTypeManagement:
+		p.TargetPortIdentity = decodePTPPortIdentity(body)
+		p.Management.StartingBoundaryHops = body[10]
+		p.Management.BoundaryHops = body[11]
+		p.Management.Action = PTPManagementAction(body[12] & 0xf)
+	}
+
+	p.TLVs = p.TLVs[:0]
+	for tlvs := body[bodyLength:]; len(tlvs) > 0; {
+		if len(tlvs) < 4 {
+			return errors.New("PTP TLV too short")
+		}
+		l := int(binary.BigEndian.Uint16(tlvs[2:4]))
+		if len(tlvs) < 4+l {
+			return errors.New("PTP TLV length exceeds message")
+		}
+		p.TLVs = append(p.TLVs, PTPTLV{Type: PTPTLVType(binary.BigEndian.Uint16(tlvs[0:2])), Value: tlvs[4 : 4+l]})
+		tlvs = tlvs[4+l:]
+	}
+	return nil
+}
+
+// CanDecode implements gopacket.DecodingLayer.
+func (p *PTP) CanDecode() gopacket.LayerClass {
+	return LayerTypePTP
+}
+
+// NextLayerType implements gopacket.DecodingLayer.
+func (p *PTP) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypeZero
+}
+
+// Payload returns nil.
+func (p *PTP) Payload() []byte {
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+func (p *PTP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	bodyLength := ptpBodyLength(p.MessageType)
+	length := ptpHeaderLength + bodyLength
+	for _, t := range p.TLVs {
+		if len(t.Value) > 0xffff {
+			return fmt.Errorf("PTP TLV %v too long", t.Type)
+		}
+		length += 4 + len(t.Value)
+	}
+	if length > 0xffff {
+		return fmt.Errorf("PTP message length %d too long", length)
+	}
+	data, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	if opts.FixLengths {
+		p.MessageLength = uint16(length)
+	}
+	data[0] = p.MajorSdoID<<4 | uint8(p.MessageType)&0xf
+	data[1] = p.MinorVersion<<4 | p.Version&0xf
+	binary.BigEndian.PutUint16(data[2:4], p.MessageLength)
+	data[4] = p.DomainNumber
+	data[5] = p.MinorSdoID
+	binary.BigEndian.PutUint16(data[6:8], uint16(p.Flags))
+	binary.BigEndian.PutUint64(data[8:16], uint64(p.CorrectionField))
+	binary.BigEndian.PutUint32(data[16:20], p.MessageTypeSpecific)
+	p.SourcePortIdentity.encode(data[20:30])
+	binary.BigEndian.PutUint16(data[30:32], p.SequenceID)
+	data[32] = p.ControlField
+	data[33] = uint8(p.LogMessageInterval)
+
+	body := data[ptpHeaderLength:]
+	for i := range body[:bodyLength] {
+		body[i] = 0
+	}
+	switch p.MessageType {
+	case PTPMessageTypeSync, PTPMessageTypeDelayReq, PTPMessageTypeFollowUp, PTPMessageTypePdelayReq:
+		p.Timestamp.encode(body)
+	case PTPMessageTypePdelayResp, PTPMessageTypeDelayResp, PTPMessageTypePdelayRespFollowUp:
+		p.Timestamp.encode(body)
+		p.RequestingPortIdentity.encode(body[10:20])
+	case PTPMessageTypeAnnounce:
+		p.Timestamp.encode(body)
+		a := &p.Announce
+		binary.BigEndian.PutUint16(body[10:12], uint16(a.CurrentUTCOffset))
+		body[13] = a.GrandmasterPriority1
+		body[14] = a.GrandmasterClockQuality.ClockClass
+		body[15] = a.GrandmasterClockQuality.ClockAccuracy
+		binary.BigEndian.PutUint16(body[16:18], a.GrandmasterClockQuality.OffsetScaledLogVariance)
+		body[18] = a.GrandmasterPriority2
+		copy(body[19:27], a.GrandmasterIdentity[:])
+		binary.BigEndian.PutUint16(body[27:29], a.StepsRemoved)
+		body[29] = a.TimeSource
+	case PTPMessageTypeSignaling:
+		p.TargetPortIdentity.encode(body)
+	case PTPMessageTypeManagement:
+		p.TargetPortIdentity.encode(body)
+		body[10] = p.Management.StartingBoundaryHops
+		body[11] = p.Management.BoundaryHops
+		body[12] = uint8(p.Management.Action) & 0xf
+	}
+
+	tlvs := body[bodyLength:]
+	for _, t := range p.TLVs {
+		binary.BigEndian.PutUint16(tlvs[0:2], uint16(t.Type))
+		binary.BigEndian.PutUint16(tlvs[2:4], uint16(len(t.Value)))
+		copy(tlvs[4:], t.Value)
+		tlvs = tlvs[4+len(t.Value):]
+	}
+	return nil
+}
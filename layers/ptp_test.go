@@ -0,0 +1,155 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketPTPSync is a two-step Sync message sent over Ethernet, padded to
+// the minimum frame length.
+var testPacketPTPSync = []byte{
+	0x01, 0x1b, 0x19, 0x00, 0x00, 0x00, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x88, 0xf7,
+	0x00, 0x02, 0x00, 0x2c, 0x18, 0x00, 0x02, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+	0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x11, 0x22, 0xff, 0xfe, 0x33, 0x44, 0x55,
+	0x00, 0x01, 0x12, 0x34, 0x00, 0xfd, 0x00, 0x00, 0x65, 0x4a, 0x1f, 0x00, 0x0b, 0xeb,
+	0xc2, 0x00, 0x00, 0x00,
+}
+
+func TestPTPSyncEthernet(t *testing.T) {
+	p := gopacket.NewPacket(testPacketPTPSync, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypePTP}, t)
+
+	ptp := p.Layer(LayerTypePTP).(*PTP)
+	want := &PTP{
+		BaseLayer:          BaseLayer{Contents: testPacketPTPSync[14:58]},
+		MessageType:        PTPMessageTypeSync,
+		Version:            2,
+		MessageLength:      44,
+		DomainNumber:       24,
+		Flags:              PTPFlagsTwoStep | PTPFlagsTimescale,
+		CorrectionField:    0x18000,
+		SourcePortIdentity: PTPPortIdentity{ClockIdentity: PTPClockIdentity{0x00, 0x11, 0x22, 0xff, 0xfe, 0x33, 0x44, 0x55}, PortNumber: 1},
+		SequenceID:         0x1234,
+		LogMessageInterval: -3,
+		Timestamp:          PTPTimestamp{Seconds: 0x654a1f00, Nanoseconds: 200000000},
+	}
+	if !reflect.DeepEqual(ptp, want) {
+		t.Errorf("PTP mismatch:\ngot  %#v\nwant %#v", ptp, want)
+	}
+	if got := ptp.SourcePortIdentity.String(); got != "001122.fffe.334455-1" {
+		t.Errorf("port identity %q", got)
+	}
+	if got := ptp.Correction(); got != time.Duration(1) {
+		t.Errorf("correction %v", got)
+	}
+	if got := ptp.Timestamp.Time(); !got.Equal(time.Unix(0x654a1f00, 200000000)) {
+		t.Errorf("timestamp %v", got)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := ptp.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testPacketPTPSync[14:58]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), testPacketPTPSync[14:58])
+	}
+}
+
+func TestPTPUDP(t *testing.T) {
+	clock := PTPClockIdentity{0x00, 0x11, 0x22, 0xff, 0xfe, 0x33, 0x44, 0x55}
+	for _, ptp := range []*PTP{
+		{
+			MessageType:        PTPMessageTypeAnnounce,
+			Version:            2,
+			Flags:              PTPFlagsCurrentUTCOffset | PTPFlagsTimescale | PTPFlagsTimeTraceable,
+			SourcePortIdentity: PTPPortIdentity{ClockIdentity: clock, PortNumber: 1},
+			SequenceID:         7,
+			ControlField:       5,
+			LogMessageInterval: 1,
+			Announce: PTPAnnounce{
+				CurrentUTCOffset:        37,
+				GrandmasterPriority1:    128,
+				GrandmasterClockQuality: PTPClockQuality{ClockClass: 6, ClockAccuracy: 0x21, OffsetScaledLogVariance: 0x4e5d},
+				GrandmasterPriority2:    128,
+				GrandmasterIdentity:     clock,
+				StepsRemoved:            1,
+				TimeSource:              0x20,
+			},
+			TLVs: []PTPTLV{{Type: PTPTLVPathTrace, Value: clock[:]}},
+		},
+		{
+			MessageType:            PTPMessageTypeDelayResp,
+			Version:                2,
+			SourcePortIdentity:     PTPPortIdentity{ClockIdentity: clock, PortNumber: 1},
+			SequenceID:             8,
+			ControlField:           3,
+			Timestamp:              PTPTimestamp{Seconds: 1 << 40, Nanoseconds: 999999999},
+			RequestingPortIdentity: PTPPortIdentity{ClockIdentity: PTPClockIdentity{1, 2, 3, 4, 5, 6, 7, 8}, PortNumber: 2},
+		},
+		{
+			MessageType:        PTPMessageTypeManagement,
+			Version:            2,
+			SourcePortIdentity: PTPPortIdentity{ClockIdentity: clock, PortNumber: 1},
+			ControlField:       4,
+			LogMessageInterval: 0x7f,
+			TargetPortIdentity: PTPPortIdentity{ClockIdentity: PTPClockIdentity{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, PortNumber: 0xffff},
+			Management:         PTPManagement{StartingBoundaryHops: 1, BoundaryHops: 1, Action: PTPManagementActionGet},
+			TLVs:               []PTPTLV{{Type: PTPTLVManagement, Value: []byte{0x20, 0x00}}},
+		},
+	} {
+		ip := &IPv4{Version: 4, TTL: 1, Protocol: IPProtocolUDP, SrcIP: net.IP{192, 0, 2, 1}, DstIP: net.IP{224, 0, 1, 129}}
+		udp := &UDP{SrcPort: 320, DstPort: 320}
+		if ptp.MessageType.Event() {
+			udp.SrcPort, udp.DstPort = 319, 319
+		}
+		udp.SetNetworkLayerForChecksum(ip)
+		buf := gopacket.NewSerializeBuffer()
+		opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+		if err := gopacket.SerializeLayers(buf, opts, ip, udp, ptp); err != nil {
+			t.Fatal(err)
+		}
+
+		p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv4, testDecodeOptions)
+		if p.ErrorLayer() != nil {
+			t.Fatalf("%v: failed to decode packet: %v", ptp.MessageType, p.ErrorLayer().Error())
+		}
+		checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypePTP}, t)
+		got := p.ApplicationLayer().(*PTP)
+		ptp.BaseLayer = got.BaseLayer
+		if !reflect.DeepEqual(got, ptp) {
+			t.Errorf("%v mismatch:\ngot  %#v\nwant %#v", ptp.MessageType, got, ptp)
+		}
+	}
+}
+
+func TestPTPTLVs(t *testing.T) {
+	id, data, err := PTPTLV{Type: PTPTLVManagement, Value: []byte{0x20, 0x00, 0xaa}}.ManagementID()
+	if err != nil || id != 0x2000 || !bytes.Equal(data, []byte{0xaa}) {
+		t.Errorf("management ID %#x %x %v", id, data, err)
+	}
+	id, data, err = PTPTLV{Type: PTPTLVManagementErrorStatus, Value: []byte{0x00, 0x02, 0x20, 0x01, 0, 0, 0, 0}}.ManagementID()
+	if err != nil || id != 0x2001 || !bytes.Equal(data, []byte{0x00, 0x02}) {
+		t.Errorf("management error status %#x %x %v", id, data, err)
+	}
+	if _, _, err := (PTPTLV{Type: PTPTLVPathTrace}).ManagementID(); err == nil {
+		t.Error("path trace TLV decoded as management TLV")
+	}
+	path, err := PTPTLV{Type: PTPTLVPathTrace, Value: make([]byte, 16)}.PathTrace()
+	if err != nil || len(path) != 2 {
+		t.Errorf("path trace %v %v", path, err)
+	}
+}
@@ -0,0 +1,473 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/gopacket"
+)
+
+// QUIC versions, see RFC 9000 and RFC 9369.
+const (
+	QUICVersionNegotiation uint32 = 0
+	QUICVersion1           uint32 = 0x00000001
+	QUICVersion2           uint32 = 0x6b3343cf
+)
+
+// QUICPacketType is the type of a QUIC packet.  Long header packets carry
+// their type in the first byte; version negotiation and short header (1-RTT)
+// packets are told apart by their version and header form.
+type QUICPacketType uint8
+
+// QUIC packet types.
+const (
+	QUICPacketTypeInitial QUICPacketType = iota
+	QUICPacketType0RTT
+	QUICPacketTypeHandshake
+	QUICPacketTypeRetry
+	QUICPacketTypeVersionNegotiation
+	QUICPacketType1RTT
+)
+
+func (t QUICPacketType) String() string {
+	switch t {
+	case QUICPacketTypeInitial:
+		return "Initial"
+	case QUICPacketType0RTT:
+		return "0-RTT"
+	case QUICPacketTypeHandshake:
+		return "Handshake"
+	case QUICPacketTypeRetry:
+		return "Retry"
+	case QUICPacketTypeVersionNegotiation:
+		return "VersionNegotiation"
+	case QUICPacketType1RTT:
+		return "1-RTT"
+	}
+	return fmt.Sprintf("QUICPacketType(%d)", t)
+}
+
+// quicLongPacketType returns the packet type of the long packet type bits of
+// a version, which are rotated in QUIC version 2.
+func quicLongPacketType(version uint32, bits uint8) QUICPacketType {
+	if version == QUICVersion2 {
+		bits = (bits + 3) & 3
+	}
+	return QUICPacketType(bits)
+}
+
+// quicLongPacketTypeBits is the inverse of quicLongPacketType.
+func quicLongPacketTypeBits(version uint32, t QUICPacketType) uint8 {
+	bits := uint8(t) & 3
+	if version == QUICVersion2 {
+		bits = (bits + 1) & 3
+	}
+	return bits
+}
+
+// QUICShortHeaderConnectionIDLength is the length of the destination
+// connection ID of short header packets decoded by the QUIC layer.  Short
+// headers don't carry the length, which endpoints choose themselves, so it
+// has to be known in advance.  If it's 0, the connection IDs known to
+// QUICDecryption are looked for, and the connection ID is left in the payload
+// if none of them is found.
+var QUICShortHeaderConnectionIDLength = 0
+
+// quicMaxConnectionIDLength is the maximum connection ID length of QUIC
+// version 1 and 2
+const quicMaxConnectionIDLength = 20
+
+// QUIC is the header of a QUIC packet, as specified by RFC 9000.  Besides the
+// version independent fields of RFC 8999, it decodes the header fields of
+// QUIC versions 1 and 2 and their drafts.  The packet number and the payload
+// are encrypted, and are the payload of the layer.  If QUICDecryption is set
+// and the packet can be decrypted, the frames of the packet are decoded as
+// the following QUICFrame layers.
+//
+// A UDP datagram may hold several coalesced long header packets, which are
+// decoded as consecutive QUIC layers.  DecodeFromBytes only decodes the
+// first of them; the following ones start after Contents and Payload.
+type QUIC struct {
+	BaseLayer
+	LongHeader bool
+	FixedBit   bool
+	PacketType QUICPacketType
+	// TypeSpecificBits are the bits of the first byte whose meaning depends
+	// on the packet type: the low 4 bits of long header packets, the low 5
+	// bits of short header packets, and the 7 unused bits of version
+	// negotiation packets.  Header protection hides the bits of all packets
+	// except Retry and version negotiation packets.
+	TypeSpecificBits uint8
+	// SpinBit is the latency spin bit of short header packets.
+	SpinBit          bool
+	Version          uint32
+	DestConnectionID []byte
+	SrcConnectionID  []byte
+	// Token is the token of Initial and Retry packets.
+	Token []byte
+	// Length is the length of the packet number and the payload of Initial,
+	// 0-RTT and Handshake packets.
+	Length uint64
+	// SupportedVersions are the versions of version negotiation packets.
+	SupportedVersions []uint32
+	// RetryIntegrityTag is the integrity tag of Retry packets.
+	RetryIntegrityTag []byte
+	// Decrypted is set if the packet was decrypted, which removes the
+	// header protection of TypeSpecificBits.
+	Decrypted bool
+	// PacketNumber is the packet number of decrypted packets.
+	PacketNumber uint64
+}
+
+// LayerType returns LayerTypeQUIC.
+func (q *QUIC) LayerType() gopacket.LayerType { return LayerTypeQUIC }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (q *QUIC) CanDecode() gopacket.LayerClass { return LayerTypeQUIC }
+
+// NextLayerType returns gopacket.LayerTypeZero, since the payload is
+// encrypted.
+func (q *QUIC) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+
+// Payload returns the encrypted packet number and payload.
+func (q *QUIC) Payload() []byte { return q.BaseLayer.Payload }
+
+// PacketNumberLength returns the length of the packet number in bytes, or 0
+// if the packet has no packet number.  It's only meaningful once header
+// protection is removed.
+func (q *QUIC) PacketNumberLength() int {
+	switch q.PacketType {
+	case QUICPacketTypeRetry, QUICPacketTypeVersionNegotiation:
+		return 0
+	}
+	return int(q.TypeSpecificBits&0x03) + 1
+}
+
+// KeyPhase returns the key phase bit of short header packets.  It's only
+// meaningful once header protection is removed.
+func (q *QUIC) KeyPhase() bool {
+	return !q.LongHeader && q.TypeSpecificBits&0x04 != 0
+}
+
+// quicVarint decodes a variable-length integer, RFC 9000 section 16,
+// returning its value and length, or a length of 0 if data is too short.
+func quicVarint(data []byte) (uint64, int) {
+	if len(data) == 0 {
+		return 0, 0
+	}
+	n := 1 << (data[0] >> 6)
+	if len(data) < n {
+		return 0, 0
+	}
+	v := uint64(data[0] & 0x3f)
+	for _, b := range data[1:n] {
+		v = v<<8 | uint64(b)
+	}
+	return v, n
+}
+
+// quicVarintLen returns the length of the shortest encoding of v.
+func quicVarintLen(v uint64) int {
+	switch {
+	case v < 1<<6:
+		return 1
+	case v < 1<<14:
+		return 2
+	case v < 1<<30:
+		return 4
+	}
+	return 8
+}
+
+// putQUICVarint encodes v into data, which has to be quicVarintLen(v) bytes
+// long.
+func putQUICVarint(data []byte, v uint64) {
+	n := len(data)
+	for i := n - 1; i >= 0; i-- {
+		data[i] = byte(v)
+		v >>= 8
+	}
+	switch n {
+	case 2:
+		data[0] |= 0x40
+	case 4:
+		data[0] |= 0x80
+	case 8:
+		data[0] |= 0xc0
+	}
+}
+
+var errQUICTooShort = errors.New("QUIC packet too short")
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (q *QUIC) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 1 {
+		df.SetTruncated()
+		return errQUICTooShort
+	}
+	*q = QUIC{
+		LongHeader: data[0]&0x80 != 0,
+		FixedBit:   data[0]&0x40 != 0,
+	}
+	if !q.LongHeader {
+		q.PacketType = QUICPacketType1RTT
+		q.SpinBit = data[0]&0x20 != 0
+		q.TypeSpecificBits = data[0] & 0x1f
+		n := 1 + QUICShortHeaderConnectionIDLength
+		if n == 1 && QUICDecryption != nil {
+			n += QUICDecryption.shortHeaderConnectionIDLength(data[1:])
+		}
+		if len(data) < n {
+			df.SetTruncated()
+			return errQUICTooShort
+		}
+		q.DestConnectionID = data[1:n]
+		q.BaseLayer = BaseLayer{Contents: data[:n], Payload: data[n:]}
+		return nil
+	}
+
+	if len(data) < 6 {
+		df.SetTruncated()
+		return errQUICTooShort
+	}
+	q.Version = binary.BigEndian.Uint32(data[1:5])
+	offset := 5
+	for _, cid := range []*[]byte{&q.DestConnectionID, &q.SrcConnectionID} {
+		if offset >= len(data) {
+			df.SetTruncated()
+			return errQUICTooShort
+		}
+		n := int(data[offset])
+		offset++
+		// version negotiation packets may carry connection IDs of other
+		// versions, which may be up to 255 bytes long
+		if n > quicMaxConnectionIDLength && q.Version != QUICVersionNegotiation {
+			return fmt.Errorf("QUIC connection ID length %d exceeds %d", n, quicMaxConnectionIDLength)
+		}
+		if offset+n > len(data) {
+			df.SetTruncated()
+			return errQUICTooShort
+		}
+		*cid = data[offset : offset+n]
+		offset += n
+	}
+
+	if q.Version == QUICVersionNegotiation {
+		q.PacketType = QUICPacketTypeVersionNegotiation
+		q.FixedBit = false
+		q.TypeSpecificBits = data[0] & 0x7f
+		rest := data[offset:]
+		if len(rest)%4 != 0 {
+			return fmt.Errorf("QUIC version negotiation versions have invalid length %d", len(rest))
+		}
+		for ; len(rest) > 0; rest = rest[4:] {
+			q.SupportedVersions = append(q.SupportedVersions, binary.BigEndian.Uint32(rest))
+		}
+		q.BaseLayer = BaseLayer{Contents: data}
+		return nil
+	}
+
+	q.PacketType = quicLongPacketType(q.Version, (data[0]>>4)&0x03)
+	q.TypeSpecificBits = data[0] & 0x0f
+	switch q.PacketType {
+	case QUICPacketTypeRetry:
+		if len(data)-offset < 16 {
+			df.SetTruncated()
+			return errQUICTooShort
+		}
+		q.Token = data[offset : len(data)-16]
+		q.RetryIntegrityTag = data[len(data)-16:]
+		q.BaseLayer = BaseLayer{Contents: data}
+		return nil
+	case QUICPacketTypeInitial:
+		tokenLength, n := quicVarint(data[offset:])
+		if n == 0 {
+			df.SetTruncated()
+			return errQUICTooShort
+		}
+		offset += n
+		if tokenLength > uint64(len(data)-offset) {
+			df.SetTruncated()
+			return errQUICTooShort
+		}
+		q.Token = data[offset : offset+int(tokenLength)]
+		offset += int(tokenLength)
+	}
+	length, n := quicVarint(data[offset:])
+	if n == 0 {
+		df.SetTruncated()
+		return errQUICTooShort
+	}
+	offset += n
+	q.Length = length
+	end := len(data)
+	if length > uint64(len(data)-offset) {
+		df.SetTruncated()
+	} else {
+		end = offset + int(length)
+	}
+	q.BaseLayer = BaseLayer{Contents: data[:offset], Payload: data[offset:end]}
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.  The bytes
+// already in the buffer are the packet number and payload.  With FixLengths,
+// Length is set to their length.
+// See the docs for gopacket.SerializableLayer for more info.
+func (q *QUIC) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	payload := len(b.Bytes())
+	if !q.LongHeader {
+		bytes, err := b.PrependBytes(1 + len(q.DestConnectionID))
+		if err != nil {
+			return err
+		}
+		bytes[0] = q.TypeSpecificBits & 0x1f
+		if q.FixedBit {
+			bytes[0] |= 0x40
+		}
+		if q.SpinBit {
+			bytes[0] |= 0x20
+		}
+		copy(bytes[1:], q.DestConnectionID)
+		return nil
+	}
+
+	if len(q.DestConnectionID) > 255 || len(q.SrcConnectionID) > 255 {
+		return errors.New("QUIC connection ID too long")
+	}
+	n := 7 + len(q.DestConnectionID) + len(q.SrcConnectionID)
+	switch q.PacketType {
+	case QUICPacketTypeVersionNegotiation:
+		n += 4 * len(q.SupportedVersions)
+	case QUICPacketTypeRetry:
+		if len(q.RetryIntegrityTag) != 16 {
+			return fmt.Errorf("QUIC retry integrity tag has length %d, must be 16", len(q.RetryIntegrityTag))
+		}
+		n += len(q.Token) + 16
+	case QUICPacketTypeInitial, QUICPacketType0RTT, QUICPacketTypeHandshake:
+		if opts.FixLengths {
+			q.Length = uint64(payload)
+		}
+		if q.PacketType == QUICPacketTypeInitial {
+			n += quicVarintLen(uint64(len(q.Token))) + len(q.Token)
+		}
+		n += quicVarintLen(q.Length)
+	default:
+		return fmt.Errorf("invalid QUIC long header packet type %v", q.PacketType)
+	}
+	bytes, err := b.PrependBytes(n)
+	if err != nil {
+		return err
+	}
+
+	bytes[0] = 0x80
+	if q.PacketType == QUICPacketTypeVersionNegotiation {
+		bytes[0] |= q.TypeSpecificBits & 0x7f
+	} else {
+		bytes[0] |= quicLongPacketTypeBits(q.Version, q.PacketType)<<4 | q.TypeSpecificBits&0x0f
+		if q.FixedBit {
+			bytes[0] |= 0x40
+		}
+	}
+	binary.BigEndian.PutUint32(bytes[1:], q.Version)
+	offset := 5
+	for _, cid := range [][]byte{q.DestConnectionID, q.SrcConnectionID} {
+		bytes[offset] = byte(len(cid))
+		offset += 1 + copy(bytes[offset+1:], cid)
+	}
+	switch q.PacketType {
+	case QUICPacketTypeVersionNegotiation:
+		for _, v := range q.SupportedVersions {
+			binary.BigEndian.PutUint32(bytes[offset:], v)
+			offset += 4
+		}
+	case QUICPacketTypeRetry:
+		offset += copy(bytes[offset:], q.Token)
+		copy(bytes[offset:], q.RetryIntegrityTag)
+	default:
+		if q.PacketType == QUICPacketTypeInitial {
+			l := quicVarintLen(uint64(len(q.Token)))
+			putQUICVarint(bytes[offset:offset+l], uint64(len(q.Token)))
+			offset += l
+			offset += copy(bytes[offset:], q.Token)
+		}
+		putQUICVarint(bytes[offset:], q.Length)
+	}
+	return nil
+}
+
+// decodeQUIC decodes the QUIC packets coalesced in data, adding a QUIC layer
+// for each of them, followed by its frames if it's decrypted.
+func decodeQUIC(data []byte, p gopacket.PacketBuilder) error {
+	for first := true; len(data) > 0; first = false {
+		// padding after the last packet is all zeros, which isn't a valid
+		// long header
+		if !first && data[0]&0x80 == 0 {
+			break
+		}
+		q := &QUIC{}
+		if err := q.DecodeFromBytes(data, p); err != nil {
+			return err
+		}
+		p.AddLayer(q)
+		p.SetApplicationLayer(q)
+		if QUICDecryption != nil {
+			frames, err := QUICDecryption.Decrypt(q)
+			for _, f := range frames {
+				p.AddLayer(f)
+			}
+			if q.Decrypted && err != nil {
+				return err
+			}
+		}
+		data = data[len(q.Contents)+len(q.BaseLayer.Payload):]
+	}
+	return nil
+}
+
+// quicKnownVersion returns true if version is a QUIC version the QUIC layer
+// knows the header fields of.
+func quicKnownVersion(version uint32) bool {
+	switch {
+	case version == QUICVersion1, version == QUICVersion2:
+		return true
+	case version&0xffffff00 == 0xff000000:
+		// IETF drafts
+		return true
+	}
+	return false
+}
+
+// DetectQUIC recognizes long header QUIC packets of the versions known to the
+// QUIC layer and version negotiation packets on any UDP port.  Short header
+// packets can't be recognized, since they're almost free of fixed values.
+// UDP port 443 is decoded as QUIC anyway; to detect QUIC on other ports,
+// register DetectQUIC as a hook for unknown UDP ports:
+//
+//	gopacket.RegisterUnknownProtocolHook(gopacket.UnknownUDPPort, layers.DetectQUIC)
+func DetectQUIC(u *gopacket.UnknownProtocol) gopacket.Decoder {
+	data := u.Data
+	if len(data) < 7 || data[0]&0x80 == 0 {
+		return nil
+	}
+	version := binary.BigEndian.Uint32(data[1:5])
+	if version != QUICVersionNegotiation && (data[0]&0x40 == 0 || !quicKnownVersion(version)) {
+		return nil
+	}
+	var q QUIC
+	if q.DecodeFromBytes(data, gopacket.NilDecodeFeedback) != nil {
+		return nil
+	}
+	if version == QUICVersionNegotiation && len(q.SupportedVersions) == 0 {
+		return nil
+	}
+	return LayerTypeQUIC
+}
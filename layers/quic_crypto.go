@@ -0,0 +1,550 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+
+	"github.com/google/gopacket"
+)
+
+// QUICDecryption is the QUICDecrypter used to decrypt QUIC packets decoded as
+// LayerTypeQUIC.  nil, the default, disables decryption.
+var QUICDecryption *QUICDecrypter
+
+// QUICKeyLog supplies the TLS secrets of QUIC connections, like the key log
+// files written by TLS libraries for the SSLKEYLOGFILE environment variable.
+type QUICKeyLog interface {
+	// QUICSecret returns the secret with the given key log label, e.g.
+	// CLIENT_HANDSHAKE_TRAFFIC_SECRET, of the TLS handshake with the given
+	// client random, or nil if it isn't known.
+	QUICSecret(label string, clientRandom []byte) []byte
+}
+
+// NSSKeyLog is a QUICKeyLog holding the secrets of a key log in the NSS key
+// log format.  It's an io.Writer the key log is written to, so it can be
+// filled from a key log file with io.Copy, or used as the KeyLogWriter of a
+// crypto/tls Config.  Lines are added once their newline is written, and
+// malformed lines are ignored.  It's safe for concurrent use.
+type NSSKeyLog struct {
+	mu      sync.Mutex
+	secrets map[string][]byte
+	partial []byte
+}
+
+// Write adds the secrets of the complete lines written so far.
+func (k *NSSKeyLog) Write(p []byte) (int, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.secrets == nil {
+		k.secrets = map[string][]byte{}
+	}
+	k.partial = append(k.partial, p...)
+	for {
+		i := bytes.IndexByte(k.partial, '\n')
+		if i < 0 {
+			break
+		}
+		k.addLine(k.partial[:i])
+		k.partial = k.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// addLine adds the secret of a "<label> <client random> <secret>" line.
+func (k *NSSKeyLog) addLine(line []byte) {
+	fields := bytes.Fields(line)
+	if len(fields) != 3 || fields[0][0] == '#' {
+		return
+	}
+	random, err := hex.DecodeString(string(fields[1]))
+	if err != nil {
+		return
+	}
+	secret, err := hex.DecodeString(string(fields[2]))
+	if err != nil {
+		return
+	}
+	k.secrets[string(fields[0])+" "+string(random)] = secret
+}
+
+// QUICSecret implements QUICKeyLog.
+func (k *NSSKeyLog) QUICSecret(label string, clientRandom []byte) []byte {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.secrets[label+" "+string(clientRandom)]
+}
+
+// QUICDecrypter decrypts QUIC packets of versions 1 and 2, see RFC 9001 and
+// RFC 9369.  Initial packets are always decrypted, since their keys are
+// derived from the connection ID chosen by the client.  Handshake, 0-RTT and
+// 1-RTT packets are decrypted with the secrets of the key log, which are
+// found with the client random of the ClientHello.  Only the AES-GCM cipher
+// suites are supported.
+//
+// Since the keys of a packet depend on the packets seen before, a
+// QUICDecrypter follows the connections of the packets it decrypts, which
+// have to be passed to it in order, starting with the first Initial packet of
+// the client.  It learns the connection IDs of both endpoints, including
+// those of NEW_CONNECTION_ID frames, which also lets it find the connection
+// IDs of short header packets if QUICShortHeaderConnectionIDLength is 0,
+// reconstructs packet numbers and follows key updates.  Connections are kept
+// until Reset is called.
+//
+// A QUICDecrypter is safe for concurrent use.
+type QUICDecrypter struct {
+	keyLog QUICKeyLog
+
+	mu sync.Mutex
+	// conns are the connections by the connection IDs of either endpoint
+	conns map[string]*quicConn
+	// cidLengths are the lengths of the known connection IDs
+	cidLengths map[int]bool
+}
+
+// NewQUICDecrypter returns a QUICDecrypter using keyLog to decrypt packets
+// other than Initial packets, which may be nil.
+func NewQUICDecrypter(keyLog QUICKeyLog) *QUICDecrypter {
+	d := &QUICDecrypter{keyLog: keyLog}
+	d.Reset()
+	return d
+}
+
+// Reset forgets all connections.
+func (d *QUICDecrypter) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.conns = map[string]*quicConn{}
+	d.cidLengths = map[int]bool{}
+}
+
+// Packet number spaces, RFC 9000 section 12.3
+const (
+	quicSpaceInitial = iota
+	quicSpaceHandshake
+	quicSpaceApplication
+	quicNumSpaces
+)
+
+// Directions of packets
+const (
+	quicFromClient = iota
+	quicFromServer
+)
+
+// quicConn is a connection followed by a QUICDecrypter.
+type quicConn struct {
+	version      uint32
+	origDCID     []byte
+	clientCIDs   map[string]bool
+	clientRandom []byte
+	cipherSuite  uint16
+	initial      [2]*quicKeys
+	handshake    [2]*quicKeys
+	early        *quicKeys
+	application  [2]*quicKeys
+	// largest are the largest packet numbers by space and direction, -1 if
+	// none was seen
+	largest [quicNumSpaces][2]int64
+}
+
+// quicKeys are the packet protection keys derived from a secret.
+type quicKeys struct {
+	hash   func() hash.Hash
+	secret []byte
+	aead   cipher.AEAD
+	iv     []byte
+	hp     cipher.Block
+	// generation is the number of key updates of 1-RTT keys, whose parity
+	// is the key phase
+	generation int
+}
+
+// Initial salts, RFC 9001 section 5.2 and RFC 9369 section 3.3.1
+var (
+	quicSaltV1 = []byte{0x38, 0x76, 0x2c, 0xf7, 0xf5, 0x59, 0x34, 0xb3, 0x4d, 0x17, 0x9a, 0xe6, 0xa4, 0xc8, 0x0c, 0xad, 0xcc, 0xbb, 0x7f, 0x0a}
+	quicSaltV2 = []byte{0x0d, 0xed, 0xe3, 0xde, 0xf7, 0x00, 0xa6, 0xdb, 0x81, 0x93, 0x81, 0xbe, 0x6e, 0x26, 0x9d, 0xcb, 0xf9, 0xbd, 0x2e, 0xd9}
+)
+
+// TLS 1.3 cipher suites
+const (
+	tlsAES128GCMSHA256 uint16 = 0x1301
+	tlsAES256GCMSHA384 uint16 = 0x1302
+)
+
+func hkdfExtract(h func() hash.Hash, salt, secret []byte) []byte {
+	mac := hmac.New(h, salt)
+	mac.Write(secret)
+	return mac.Sum(nil)
+}
+
+// hkdfExpandLabel is HKDF-Expand-Label of TLS 1.3 with an empty context,
+// RFC 8446 section 7.1.
+func hkdfExpandLabel(h func() hash.Hash, secret []byte, label string, length int) []byte {
+	info := make([]byte, 0, 4+6+len(label))
+	info = append(info, byte(length>>8), byte(length), byte(6+len(label)))
+	info = append(info, "tls13 "...)
+	info = append(info, label...)
+	info = append(info, 0)
+	var out, t []byte
+	mac := hmac.New(h, secret)
+	for i := byte(1); len(out) < length; i++ {
+		mac.Reset()
+		mac.Write(t)
+		mac.Write(info)
+		mac.Write([]byte{i})
+		t = mac.Sum(nil)
+		out = append(out, t...)
+	}
+	return out[:length]
+}
+
+// quicLabelPrefix returns the prefix of the packet protection labels of a
+// version.
+func quicLabelPrefix(version uint32) string {
+	if version == QUICVersion2 {
+		return "quicv2 "
+	}
+	return "quic "
+}
+
+// quicKeyMaterial derives the key, IV and header protection key of a
+// secret, RFC 9001 section 5.1.
+func quicKeyMaterial(version uint32, h func() hash.Hash, keyLen int, secret []byte) (key, iv, hp []byte) {
+	prefix := quicLabelPrefix(version)
+	return hkdfExpandLabel(h, secret, prefix+"key", keyLen),
+		hkdfExpandLabel(h, secret, prefix+"iv", 12),
+		hkdfExpandLabel(h, secret, prefix+"hp", keyLen)
+}
+
+// quicInitialSecrets returns the client and server Initial secrets of a
+// connection, or nil if the version isn't supported.
+func quicInitialSecrets(version uint32, dcid []byte) (client, server []byte) {
+	var salt []byte
+	switch version {
+	case QUICVersion1:
+		salt = quicSaltV1
+	case QUICVersion2:
+		salt = quicSaltV2
+	default:
+		return nil, nil
+	}
+	initial := hkdfExtract(sha256.New, salt, dcid)
+	return hkdfExpandLabel(sha256.New, initial, "client in", 32),
+		hkdfExpandLabel(sha256.New, initial, "server in", 32)
+}
+
+// newQUICKeys returns the keys of a secret used with a cipher suite.
+func newQUICKeys(version uint32, cipherSuite uint16, secret []byte) (*quicKeys, error) {
+	h, keyLen := sha256.New, 16
+	switch cipherSuite {
+	case tlsAES128GCMSHA256:
+	case tlsAES256GCMSHA384:
+		h, keyLen = sha512.New384, 32
+	default:
+		return nil, fmt.Errorf("unsupported QUIC cipher suite %#04x", cipherSuite)
+	}
+	key, iv, hpKey := quicKeyMaterial(version, h, keyLen, secret)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	hp, err := aes.NewCipher(hpKey)
+	if err != nil {
+		return nil, err
+	}
+	return &quicKeys{hash: h, secret: secret, aead: aead, iv: iv, hp: hp}, nil
+}
+
+// next returns the keys following a key update, RFC 9001 section 6.  The
+// header protection key isn't updated.
+func (k *quicKeys) next(version uint32) (*quicKeys, error) {
+	secret := hkdfExpandLabel(k.hash, k.secret, quicLabelPrefix(version)+"ku", len(k.secret))
+	keyLen := 16
+	if len(k.secret) == sha512.Size384 {
+		keyLen = 32
+	}
+	key, iv, _ := quicKeyMaterial(version, k.hash, keyLen, secret)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &quicKeys{hash: k.hash, secret: secret, aead: aead, iv: iv, hp: k.hp, generation: k.generation + 1}, nil
+}
+
+var errQUICDecrypt = errors.New("QUIC packet can't be decrypted")
+
+// unprotect removes the header protection of q, RFC 9001 section 5.4,
+// returning the unprotected first byte and header, and the truncated packet
+// number and its length.
+func (k *quicKeys) unprotect(q *QUIC) (header []byte, pn uint64, pnLen int, err error) {
+	payload := q.Payload()
+	if len(payload) < 4+aes.BlockSize {
+		return nil, 0, 0, errQUICDecrypt
+	}
+	var mask [aes.BlockSize]byte
+	k.hp.Encrypt(mask[:], payload[4:4+aes.BlockSize])
+	first := q.Contents[0]
+	if q.LongHeader {
+		first ^= mask[0] & 0x0f
+	} else {
+		first ^= mask[0] & 0x1f
+	}
+	pnLen = int(first&0x03) + 1
+	header = make([]byte, len(q.Contents)+pnLen)
+	copy(header, q.Contents)
+	header[0] = first
+	for i := 0; i < pnLen; i++ {
+		b := payload[i] ^ mask[1+i]
+		header[len(q.Contents)+i] = b
+		pn = pn<<8 | uint64(b)
+	}
+	return header, pn, pnLen, nil
+}
+
+// open decrypts the payload of q with the unprotected header and the full
+// packet number.
+func (k *quicKeys) open(q *QUIC, header []byte, pn uint64, pnLen int) ([]byte, error) {
+	nonce := make([]byte, len(k.iv))
+	copy(nonce, k.iv)
+	var pnBytes [8]byte
+	binary.BigEndian.PutUint64(pnBytes[:], pn)
+	for i, b := range pnBytes {
+		nonce[len(nonce)-8+i] ^= b
+	}
+	return k.aead.Open(nil, nonce, q.Payload()[pnLen:], header)
+}
+
+// quicPacketNumber reconstructs a packet number from its truncated form and
+// the largest packet number seen, RFC 9000 appendix A.3.
+func quicPacketNumber(largest int64, truncated uint64, pnLen int) uint64 {
+	expected := uint64(largest + 1)
+	win := uint64(1) << (8 * uint(pnLen))
+	hwin := win / 2
+	candidate := expected&^(win-1) | truncated
+	switch {
+	case candidate+hwin <= expected && candidate < 1<<62-win:
+		return candidate + win
+	case candidate > expected+hwin && candidate >= win:
+		return candidate - win
+	}
+	return candidate
+}
+
+// shortHeaderConnectionIDLength returns the length of the known connection
+// ID data of a short header packet starts with, or 0.
+func (d *QUICDecrypter) shortHeaderConnectionIDLength(data []byte) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for l := range d.cidLengths {
+		if l > n && l <= len(data) && d.conns[string(data[:l])] != nil {
+			n = l
+		}
+	}
+	return n
+}
+
+// addConnectionID adds the connection ID of an endpoint of c.
+func (d *QUICDecrypter) addConnectionID(c *quicConn, cid []byte, client bool) {
+	d.conns[string(cid)] = c
+	d.cidLengths[len(cid)] = true
+	if client {
+		c.clientCIDs[string(cid)] = true
+	}
+}
+
+// connection returns the connection and direction of q, adding a new
+// connection for the first Initial packet of a client.
+func (d *QUICDecrypter) connection(q *QUIC) (*quicConn, int) {
+	c := d.conns[string(q.DestConnectionID)]
+	if c == nil {
+		if q.PacketType != QUICPacketTypeInitial {
+			return nil, 0
+		}
+		c = &quicConn{
+			version:    q.Version,
+			origDCID:   q.DestConnectionID,
+			clientCIDs: map[string]bool{},
+		}
+		for i := range c.largest {
+			c.largest[i] = [2]int64{-1, -1}
+		}
+		d.addConnectionID(c, q.DestConnectionID, false)
+	}
+	if c.clientCIDs[string(q.DestConnectionID)] {
+		return c, quicFromServer
+	}
+	return c, quicFromClient
+}
+
+// keys returns the keys for packets of the given type and direction of c,
+// or nil if they aren't known.
+func (d *QUICDecrypter) keys(c *quicConn, t QUICPacketType, dir int) *quicKeys {
+	var keys **quicKeys
+	var label string
+	switch t {
+	case QUICPacketTypeInitial:
+		keys = &c.initial[dir]
+	case QUICPacketTypeHandshake:
+		keys = &c.handshake[dir]
+		label = [2]string{"CLIENT_HANDSHAKE_TRAFFIC_SECRET", "SERVER_HANDSHAKE_TRAFFIC_SECRET"}[dir]
+	case QUICPacketType0RTT:
+		if dir != quicFromClient {
+			return nil
+		}
+		keys = &c.early
+		label = "CLIENT_EARLY_TRAFFIC_SECRET"
+	case QUICPacketType1RTT:
+		keys = &c.application[dir]
+		label = [2]string{"CLIENT_TRAFFIC_SECRET_0", "SERVER_TRAFFIC_SECRET_0"}[dir]
+	default:
+		return nil
+	}
+	if *keys != nil {
+		return *keys
+	}
+
+	var secret []byte
+	cipherSuite := c.cipherSuite
+	if t == QUICPacketTypeInitial {
+		client, server := quicInitialSecrets(c.version, c.origDCID)
+		secret = [2][]byte{client, server}[dir]
+		cipherSuite = tlsAES128GCMSHA256
+	} else if d.keyLog != nil && c.clientRandom != nil {
+		secret = d.keyLog.QUICSecret(label, c.clientRandom)
+	}
+	if secret == nil {
+		return nil
+	}
+	if cipherSuite == 0 {
+		// the ServerHello wasn't seen, guess from the hash length
+		cipherSuite = tlsAES128GCMSHA256
+		if len(secret) == sha512.Size384 {
+			cipherSuite = tlsAES256GCMSHA384
+		}
+	}
+	k, err := newQUICKeys(c.version, cipherSuite, secret)
+	if err != nil {
+		return nil
+	}
+	*keys = k
+	return k
+}
+
+// Decrypt removes the header protection of q, decrypts its payload and
+// decodes its frames.  On success, it sets the PacketNumber,
+// TypeSpecificBits and Decrypted fields of q.  Frames decoded before an
+// error are returned with the error, which is only the case for packets
+// that were decrypted.
+func (d *QUICDecrypter) Decrypt(q *QUIC) ([]*QUICFrame, error) {
+	if q.PacketType == QUICPacketTypeRetry || q.PacketType == QUICPacketTypeVersionNegotiation {
+		return nil, errQUICDecrypt
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c, dir := d.connection(q)
+	if c == nil {
+		return nil, errQUICDecrypt
+	}
+	keys := d.keys(c, q.PacketType, dir)
+	if keys == nil {
+		return nil, errQUICDecrypt
+	}
+	header, truncated, pnLen, err := keys.unprotect(q)
+	if err != nil {
+		return nil, err
+	}
+	space := quicSpaceApplication
+	switch q.PacketType {
+	case QUICPacketTypeInitial:
+		space = quicSpaceInitial
+	case QUICPacketTypeHandshake:
+		space = quicSpaceHandshake
+	}
+	pn := quicPacketNumber(c.largest[space][dir], truncated, pnLen)
+	updated := !q.LongHeader && int(header[0]>>2&1) != keys.generation&1
+	if updated {
+		// the key phase changed
+		if keys, err = keys.next(c.version); err != nil {
+			return nil, err
+		}
+	}
+	plaintext, err := keys.open(q, header, pn, pnLen)
+	if err != nil {
+		return nil, errQUICDecrypt
+	}
+	if updated {
+		c.application[dir] = keys
+	}
+	if int64(pn) > c.largest[space][dir] {
+		c.largest[space][dir] = int64(pn)
+	}
+	q.PacketNumber = pn
+	q.Decrypted = true
+	if q.LongHeader {
+		q.TypeSpecificBits = header[0] & 0x0f
+	} else {
+		q.TypeSpecificBits = header[0] & 0x1f
+	}
+
+	frames, err := decodeQUICFrames(plaintext, gopacket.NilDecodeFeedback)
+	d.learn(c, dir, q, frames)
+	return frames, err
+}
+
+// learn updates c with what a decrypted packet tells about the connection:
+// the connection IDs, the client random and the cipher suite.
+func (d *QUICDecrypter) learn(c *quicConn, dir int, q *QUIC, frames []*QUICFrame) {
+	if q.LongHeader {
+		d.addConnectionID(c, q.SrcConnectionID, dir == quicFromClient)
+	}
+	for _, f := range frames {
+		switch f.Type {
+		case QUICFrameTypeNewConnectionID:
+			d.addConnectionID(c, f.ConnectionID, dir == quicFromClient)
+		case QUICFrameTypeCrypto:
+			if q.PacketType != QUICPacketTypeInitial || f.Offset != 0 {
+				continue
+			}
+			// the start of the ClientHello or ServerHello: type, length,
+			// legacy version and random
+			hello := f.Data
+			if len(hello) < 38 {
+				continue
+			}
+			switch {
+			case dir == quicFromClient && hello[0] == 1:
+				c.clientRandom = append([]byte(nil), hello[6:38]...)
+			case dir == quicFromServer && hello[0] == 2:
+				// session ID and cipher suite
+				if len(hello) > 38 && len(hello) >= 39+int(hello[38])+2 {
+					c.cipherSuite = binary.BigEndian.Uint16(hello[39+int(hello[38]):])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestQUICInitialKeys(t *testing.T) {
+	dcid := mustDecodeHex("8394c8f03e515708")
+	for _, test := range []struct {
+		version        uint32
+		client, server bool
+		key, iv, hp    string
+		sample, mask   string
+	}{
+		// RFC 9001 appendix A.1 and A.2
+		{version: QUICVersion1, client: true, key: "1f369613dd76d5467730efcbe3b1a22d", iv: "fa044b2f42a3fd3b46fb255c", hp: "9f50449e04a0e810283a1e9933adedd2",
+			sample: "d1b1c98dd7689fb8ec11d242b123dc9b", mask: "437b9aec36"},
+		{version: QUICVersion1, server: true, key: "cf3a5331653c364c88f0f379b6067e37", iv: "0ac1493ca1905853b0bba03e", hp: "c206b8d9b9f0f37644430b490eeaa314"},
+		// RFC 9369 appendix A.1
+		{version: QUICVersion2, client: true, key: "8b1a0bc121284290a29e0971b5cd045d", iv: "91f73e2351d8fa91660e909f", hp: "45b95e15235d6f45a6b19cbcb0294ba9"},
+	} {
+		client, server := quicInitialSecrets(test.version, dcid)
+		secret := server
+		if test.client {
+			secret = client
+		}
+		key, iv, hp := quicKeyMaterial(test.version, sha256.New, 16, secret)
+		if fmt.Sprintf("%x %x %x", key, iv, hp) != test.key+" "+test.iv+" "+test.hp {
+			t.Errorf("version %#x, client %v: got key %x, iv %x, hp %x", test.version, test.client, key, iv, hp)
+		}
+		if test.sample == "" {
+			continue
+		}
+		keys, err := newQUICKeys(test.version, tlsAES128GCMSHA256, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mask := make([]byte, 16)
+		keys.hp.Encrypt(mask, mustDecodeHex(test.sample))
+		if got := fmt.Sprintf("%x", mask[:5]); got != test.mask {
+			t.Errorf("header protection mask %s, want %s", got, test.mask)
+		}
+	}
+}
+
+func TestQUICPacketNumber(t *testing.T) {
+	// RFC 9000 appendix A.3
+	if pn := quicPacketNumber(0xa82f30ea, 0x9b32, 2); pn != 0xa82f9b32 {
+		t.Errorf("got packet number %#x", pn)
+	}
+	if pn := quicPacketNumber(-1, 2, 4); pn != 2 {
+		t.Errorf("got packet number %#x", pn)
+	}
+}
+
+// The server Initial packet of RFC 9001 appendix A.3.
+var testQUICServerInitial = mustDecodeHex("cf000000010008f067a5502a4262b5004075c0d95a482cd0991cd25b0aac406a5816b6394100f37a1c69797554780bb38cc5a99f5ede4cf73c3ec2493a1839b3dbcba3f6ea46c5b7684df3548e7ddeb9c3bf9c73cc3f3bded74b562bfb19fb84022f8ef4cdd93795d77d06edbb7aaf2f58891850abbdca3d20398c276456cbc42158407dd074ee")
+
+// sealQUIC protects a packet whose unprotected header ends with a packet
+// number of pnLen bytes.
+func sealQUIC(t *testing.T, keys *quicKeys, header []byte, pn uint64, pnLen int, plaintext []byte) []byte {
+	nonce := append([]byte(nil), keys.iv...)
+	var pnBytes [8]byte
+	binary.BigEndian.PutUint64(pnBytes[:], pn)
+	for i, b := range pnBytes {
+		nonce[len(nonce)-8+i] ^= b
+	}
+	packet := keys.aead.Seal(append([]byte(nil), header...), nonce, plaintext, header)
+	pnOffset := len(header) - pnLen
+	mask := make([]byte, 16)
+	keys.hp.Encrypt(mask, packet[pnOffset+4:pnOffset+20])
+	if packet[0]&0x80 != 0 {
+		packet[0] ^= mask[0] & 0x0f
+	} else {
+		packet[0] ^= mask[0] & 0x1f
+	}
+	for i := 0; i < pnLen; i++ {
+		packet[pnOffset+i] ^= mask[1+i]
+	}
+	return packet
+}
+
+// quicLongHeader returns the unprotected header of a long header packet
+// with a 4 byte packet number and a payload of n bytes.
+func quicLongHeader(t *testing.T, q *QUIC, pn uint32, n int) []byte {
+	q.LongHeader, q.FixedBit, q.Version, q.TypeSpecificBits = true, true, QUICVersion1, 3
+	buf := gopacket.NewSerializeBuffer()
+	pnBytes, _ := buf.AppendBytes(4 + n + 16)
+	binary.BigEndian.PutUint32(pnBytes, pn)
+	if err := q.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()[:len(buf.Bytes())-n-16]
+}
+
+func TestQUICDecrypt(t *testing.T) {
+	keyLog := &NSSKeyLog{}
+	QUICDecryption = NewQUICDecrypter(keyLog)
+	defer func() { QUICDecryption = nil }()
+
+	decode := func(data []byte, want ...gopacket.LayerType) gopacket.Packet {
+		t.Helper()
+		p := gopacket.NewPacket(data, LayerTypeQUIC, gopacket.Default)
+		if p.ErrorLayer() != nil {
+			t.Fatal(p.ErrorLayer().Error())
+		}
+		checkLayers(p, want, t)
+		return p
+	}
+	frame := func(p gopacket.Packet, i int) *QUICFrame {
+		t.Helper()
+		if f, ok := p.Layers()[i].(*QUICFrame); ok {
+			return f
+		}
+		t.Fatalf("layer %d isn't a frame", i)
+		return nil
+	}
+
+	// the client Initial of RFC 9001 appendix A.2 with a shorter ClientHello
+	// and a different client random
+	random := bytes.Repeat([]byte{0xaa}, 32)
+	hello := append(append(mustDecodeHex("010000ed0303"), random...), make([]byte, 0xed-34)...)
+	crypto := append(mustDecodeHex("060040f1"), hello...)
+	plaintext := append(crypto, make([]byte, 1162-len(crypto))...)
+	clientSecret, _ := quicInitialSecrets(QUICVersion1, mustDecodeHex("8394c8f03e515708"))
+	clientKeys, _ := newQUICKeys(QUICVersion1, tlsAES128GCMSHA256, clientSecret)
+	header := quicLongHeader(t, &QUIC{PacketType: QUICPacketTypeInitial, DestConnectionID: mustDecodeHex("8394c8f03e515708")}, 2, len(plaintext))
+	if want := "c300000001088394c8f03e5157080000449e00000002"; fmt.Sprintf("%x", header) != want {
+		t.Fatalf("client Initial header %x, want %s", header, want)
+	}
+	p := decode(sealQUIC(t, clientKeys, header, 2, 4, plaintext), LayerTypeQUIC, LayerTypeQUICFrame, LayerTypeQUICFrame)
+	q := p.Layer(LayerTypeQUIC).(*QUIC)
+	if !q.Decrypted || q.PacketNumber != 2 || q.PacketNumberLength() != 4 {
+		t.Errorf("unexpected client Initial %#v", q)
+	}
+	if f := frame(p, 1); f.Type != QUICFrameTypeCrypto || f.Offset != 0 || !bytes.Equal(f.Data, hello) {
+		t.Errorf("unexpected CRYPTO frame %#v", f)
+	}
+	if f := frame(p, 2); f.Type != QUICFrameTypePadding || len(f.Contents) != 1162-len(crypto) {
+		t.Errorf("unexpected PADDING frame %#v", f)
+	}
+
+	// the server Initial is protected with keys derived from the connection
+	// ID of the client Initial
+	p = decode(testQUICServerInitial, LayerTypeQUIC, LayerTypeQUICFrame, LayerTypeQUICFrame)
+	q = p.Layer(LayerTypeQUIC).(*QUIC)
+	if !q.Decrypted || q.PacketNumber != 1 || q.TypeSpecificBits != 1 {
+		t.Errorf("unexpected server Initial %#v", q)
+	}
+	if f := frame(p, 1); f.Type != QUICFrameTypeAck || f.LargestAcknowledged != 0 {
+		t.Errorf("unexpected ACK frame %#v", f)
+	}
+	if f := frame(p, 2); f.Type != QUICFrameTypeCrypto || len(f.Data) != 0x5a || f.Data[0] != 2 {
+		t.Errorf("unexpected CRYPTO frame %#v", f)
+	}
+
+	// Handshake packets are protected with a secret of the key log
+	handshakeSecret := bytes.Repeat([]byte{1}, 32)
+	fmt.Fprintf(keyLog, "# comment\nSERVER_HANDSHAKE_TRAFFIC_SECRET %x %x\n", random, handshakeSecret)
+	keys, _ := newQUICKeys(QUICVersion1, tlsAES128GCMSHA256, handshakeSecret)
+	plaintext = mustDecodeHex("0600050102030405" + "1e")
+	header = quicLongHeader(t, &QUIC{PacketType: QUICPacketTypeHandshake, SrcConnectionID: mustDecodeHex("f067a5502a4262b5")}, 0, len(plaintext))
+	p = decode(sealQUIC(t, keys, header, 0, 4, plaintext), LayerTypeQUIC, LayerTypeQUICFrame, LayerTypeQUICFrame)
+	if f := frame(p, 2); f.Type != QUICFrameTypeHandshakeDone {
+		t.Errorf("unexpected frame %#v", f)
+	}
+
+	// 1-RTT packets are found by the connection ID of the server
+	appSecret := bytes.Repeat([]byte{2}, 32)
+	fmt.Fprintf(keyLog, "CLIENT_TRAFFIC_SECRET_0 %x %x\n", random, appSecret)
+	keys, _ = newQUICKeys(QUICVersion1, tlsAES128GCMSHA256, appSecret)
+	plaintext = append(mustDecodeHex("0904"), []byte("GET / HTTP/0.9\r\n")...)
+	header = append(mustDecodeHex("41f067a5502a4262b5"), 0x00, 0x05)
+	p = decode(sealQUIC(t, keys, header, 5, 2, plaintext), LayerTypeQUIC, LayerTypeQUICFrame)
+	q = p.Layer(LayerTypeQUIC).(*QUIC)
+	if !bytes.Equal(q.DestConnectionID, mustDecodeHex("f067a5502a4262b5")) || q.PacketNumber != 5 || q.KeyPhase() {
+		t.Errorf("unexpected 1-RTT packet %#v", q)
+	}
+	if f := frame(p, 1); f.Type != QUICFrameTypeStream || f.StreamID != 4 || !f.Fin || string(f.Data) != "GET / HTTP/0.9\r\n" {
+		t.Errorf("unexpected STREAM frame %#v", f)
+	}
+
+	// after a key update, the key phase is set
+	keys, _ = keys.next(QUICVersion1)
+	header = append(mustDecodeHex("44f067a5502a4262b5"), 0x06)
+	p = decode(sealQUIC(t, keys, header, 6, 1, mustDecodeHex("01000000")), LayerTypeQUIC, LayerTypeQUICFrame, LayerTypeQUICFrame)
+	q = p.Layer(LayerTypeQUIC).(*QUIC)
+	if !q.Decrypted || q.PacketNumber != 6 || !q.KeyPhase() {
+		t.Errorf("unexpected 1-RTT packet after key update %#v", q)
+	}
+
+	// packets of unknown connections stay encrypted
+	p = decode(mustDecodeHex("4100112233445566778899aabbccddeeff00112233"), LayerTypeQUIC)
+	if p.Layer(LayerTypeQUIC).(*QUIC).Decrypted {
+		t.Error("unknown packet decrypted")
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/gopacket"
+)
+
+// QUICFrameType is the type of a QUIC frame, RFC 9000 section 12.4.
+type QUICFrameType uint64
+
+// QUIC frame types.  STREAM frame types have the OFF, LEN and FIN bits in
+// their low bits, all of them are decoded as QUICFrameTypeStream.
+const (
+	QUICFrameTypePadding            QUICFrameType = 0x00
+	QUICFrameTypePing               QUICFrameType = 0x01
+	QUICFrameTypeAck                QUICFrameType = 0x02
+	QUICFrameTypeAckECN             QUICFrameType = 0x03
+	QUICFrameTypeResetStream        QUICFrameType = 0x04
+	QUICFrameTypeStopSending        QUICFrameType = 0x05
+	QUICFrameTypeCrypto             QUICFrameType = 0x06
+	QUICFrameTypeNewToken           QUICFrameType = 0x07
+	QUICFrameTypeStream             QUICFrameType = 0x08
+	QUICFrameTypeMaxData            QUICFrameType = 0x10
+	QUICFrameTypeMaxStreamData      QUICFrameType = 0x11
+	QUICFrameTypeMaxStreamsBidi     QUICFrameType = 0x12
+	QUICFrameTypeMaxStreamsUni      QUICFrameType = 0x13
+	QUICFrameTypeDataBlocked        QUICFrameType = 0x14
+	QUICFrameTypeStreamDataBlocked  QUICFrameType = 0x15
+	QUICFrameTypeStreamsBlockedBidi QUICFrameType = 0x16
+	QUICFrameTypeStreamsBlockedUni  QUICFrameType = 0x17
+	QUICFrameTypeNewConnectionID    QUICFrameType = 0x18
+	QUICFrameTypeRetireConnectionID QUICFrameType = 0x19
+	QUICFrameTypePathChallenge      QUICFrameType = 0x1a
+	QUICFrameTypePathResponse       QUICFrameType = 0x1b
+	QUICFrameTypeConnectionClose    QUICFrameType = 0x1c
+	QUICFrameTypeApplicationClose   QUICFrameType = 0x1d
+	QUICFrameTypeHandshakeDone      QUICFrameType = 0x1e
+	// DATAGRAM frames are specified by RFC 9221.
+	QUICFrameTypeDatagram       QUICFrameType = 0x30
+	QUICFrameTypeDatagramLength QUICFrameType = 0x31
+)
+
+func (t QUICFrameType) String() string {
+	switch t {
+	case QUICFrameTypePadding:
+		return "PADDING"
+	case QUICFrameTypePing:
+		return "PING"
+	case QUICFrameTypeAck, QUICFrameTypeAckECN:
+		return "ACK"
+	case QUICFrameTypeResetStream:
+		return "RESET_STREAM"
+	case QUICFrameTypeStopSending:
+		return "STOP_SENDING"
+	case QUICFrameTypeCrypto:
+		return "CRYPTO"
+	case QUICFrameTypeNewToken:
+		return "NEW_TOKEN"
+	case QUICFrameTypeStream:
+		return "STREAM"
+	case QUICFrameTypeMaxData:
+		return "MAX_DATA"
+	case QUICFrameTypeMaxStreamData:
+		return "MAX_STREAM_DATA"
+	case QUICFrameTypeMaxStreamsBidi, QUICFrameTypeMaxStreamsUni:
+		return "MAX_STREAMS"
+	case QUICFrameTypeDataBlocked:
+		return "DATA_BLOCKED"
+	case QUICFrameTypeStreamDataBlocked:
+		return "STREAM_DATA_BLOCKED"
+	case QUICFrameTypeStreamsBlockedBidi, QUICFrameTypeStreamsBlockedUni:
+		return "STREAMS_BLOCKED"
+	case QUICFrameTypeNewConnectionID:
+		return "NEW_CONNECTION_ID"
+	case QUICFrameTypeRetireConnectionID:
+		return "RETIRE_CONNECTION_ID"
+	case QUICFrameTypePathChallenge:
+		return "PATH_CHALLENGE"
+	case QUICFrameTypePathResponse:
+		return "PATH_RESPONSE"
+	case QUICFrameTypeConnectionClose, QUICFrameTypeApplicationClose:
+		return "CONNECTION_CLOSE"
+	case QUICFrameTypeHandshakeDone:
+		return "HANDSHAKE_DONE"
+	case QUICFrameTypeDatagram, QUICFrameTypeDatagramLength:
+		return "DATAGRAM"
+	}
+	return fmt.Sprintf("QUICFrameType(%#x)", uint64(t))
+}
+
+// QUICFrame is a frame of a decrypted QUIC packet.  The fields of CRYPTO and
+// STREAM frames, and the fields needed to follow connections, are decoded;
+// all frames can be found in Contents.  Consecutive PADDING frames are
+// decoded as a single frame.
+type QUICFrame struct {
+	BaseLayer
+	Type QUICFrameType
+	// StreamID is the stream ID of STREAM, RESET_STREAM, STOP_SENDING,
+	// MAX_STREAM_DATA and STREAM_DATA_BLOCKED frames.
+	StreamID uint64
+	// Offset is the offset of the data of CRYPTO and STREAM frames.
+	Offset uint64
+	// Fin is the FIN bit of STREAM frames.
+	Fin bool
+	// Data is the data of CRYPTO, STREAM and DATAGRAM frames, the token of
+	// NEW_TOKEN frames, the data of PATH_CHALLENGE and PATH_RESPONSE frames,
+	// and the reason phrase of CONNECTION_CLOSE frames.
+	Data []byte
+	// ErrorCode is the error code of RESET_STREAM, STOP_SENDING and
+	// CONNECTION_CLOSE frames.
+	ErrorCode uint64
+	// LargestAcknowledged is the largest packet number acknowledged by ACK
+	// frames.
+	LargestAcknowledged uint64
+	// SequenceNumber is the sequence number of the connection ID of
+	// NEW_CONNECTION_ID and RETIRE_CONNECTION_ID frames.
+	SequenceNumber uint64
+	// ConnectionID is the connection ID of NEW_CONNECTION_ID frames.
+	ConnectionID []byte
+}
+
+// LayerType returns LayerTypeQUICFrame.
+func (f *QUICFrame) LayerType() gopacket.LayerType { return LayerTypeQUICFrame }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (f *QUICFrame) CanDecode() gopacket.LayerClass { return LayerTypeQUICFrame }
+
+// NextLayerType returns LayerTypeQUICFrame, since frames are followed by
+// frames, or by nothing.
+func (f *QUICFrame) NextLayerType() gopacket.LayerType { return LayerTypeQUICFrame }
+
+// quicReader reads the fields of QUIC frames.  Once a field is missing, err
+// is set and all following reads return zero values.
+type quicReader struct {
+	data []byte
+	off  int
+	err  bool
+}
+
+func (r *quicReader) varint() uint64 {
+	if r.err {
+		return 0
+	}
+	v, n := quicVarint(r.data[r.off:])
+	if n == 0 {
+		r.err = true
+	}
+	r.off += n
+	return v
+}
+
+func (r *quicReader) bytes(n uint64) []byte {
+	if r.err || n > uint64(len(r.data)-r.off) {
+		r.err = true
+		return nil
+	}
+	b := r.data[r.off : r.off+int(n)]
+	r.off += int(n)
+	return b
+}
+
+var errQUICFrameTooShort = errors.New("QUIC frame too short")
+
+// DecodeFromBytes decodes the first frame of data.  The following frames
+// start after Contents.
+func (f *QUICFrame) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*f = QUICFrame{}
+	r := &quicReader{data: data}
+	f.Type = QUICFrameType(r.varint())
+	switch t := f.Type; {
+	case t == QUICFrameTypePadding:
+		for r.off < len(data) && data[r.off] == 0 {
+			r.off++
+		}
+	case t == QUICFrameTypePing, t == QUICFrameTypeHandshakeDone:
+	case t == QUICFrameTypeAck, t == QUICFrameTypeAckECN:
+		f.LargestAcknowledged = r.varint()
+		r.varint() // ACK delay
+		ranges := r.varint()
+		r.varint() // first ACK range
+		for i := uint64(0); i < ranges && !r.err; i++ {
+			r.varint() // gap
+			r.varint() // ACK range length
+		}
+		if t == QUICFrameTypeAckECN {
+			r.varint()
+			r.varint()
+			r.varint()
+		}
+	case t == QUICFrameTypeResetStream:
+		f.StreamID = r.varint()
+		f.ErrorCode = r.varint()
+		r.varint() // final size
+	case t == QUICFrameTypeStopSending:
+		f.StreamID = r.varint()
+		f.ErrorCode = r.varint()
+	case t == QUICFrameTypeCrypto:
+		f.Offset = r.varint()
+		f.Data = r.bytes(r.varint())
+	case t == QUICFrameTypeNewToken:
+		f.Data = r.bytes(r.varint())
+	case t >= QUICFrameTypeStream && t <= QUICFrameTypeStream|0x07:
+		f.Type = QUICFrameTypeStream
+		f.StreamID = r.varint()
+		if t&0x04 != 0 {
+			f.Offset = r.varint()
+		}
+		if t&0x02 != 0 {
+			f.Data = r.bytes(r.varint())
+		} else {
+			f.Data = r.bytes(uint64(len(data) - r.off))
+		}
+		f.Fin = t&0x01 != 0
+	case t == QUICFrameTypeMaxData, t == QUICFrameTypeMaxStreamsBidi, t == QUICFrameTypeMaxStreamsUni,
+		t == QUICFrameTypeDataBlocked, t == QUICFrameTypeStreamsBlockedBidi, t == QUICFrameTypeStreamsBlockedUni:
+		r.varint()
+	case t == QUICFrameTypeMaxStreamData, t == QUICFrameTypeStreamDataBlocked:
+		f.StreamID = r.varint()
+		r.varint()
+	case t == QUICFrameTypeNewConnectionID:
+		f.SequenceNumber = r.varint()
+		r.varint() // retire prior to
+		if n := r.bytes(1); n != nil {
+			f.ConnectionID = r.bytes(uint64(n[0]))
+		}
+		r.bytes(16) // stateless reset token
+	case t == QUICFrameTypeRetireConnectionID:
+		f.SequenceNumber = r.varint()
+	case t == QUICFrameTypePathChallenge, t == QUICFrameTypePathResponse:
+		f.Data = r.bytes(8)
+	case t == QUICFrameTypeConnectionClose, t == QUICFrameTypeApplicationClose:
+		f.ErrorCode = r.varint()
+		if t == QUICFrameTypeConnectionClose {
+			r.varint() // frame type
+		}
+		f.Data = r.bytes(r.varint())
+	case t == QUICFrameTypeDatagram:
+		f.Data = r.bytes(uint64(len(data) - r.off))
+	case t == QUICFrameTypeDatagramLength:
+		f.Data = r.bytes(r.varint())
+	default:
+		if r.err {
+			break
+		}
+		return fmt.Errorf("unknown QUIC frame type %v", t)
+	}
+	if r.err {
+		df.SetTruncated()
+		return errQUICFrameTooShort
+	}
+	f.BaseLayer = BaseLayer{Contents: data[:r.off]}
+	return nil
+}
+
+// decodeQUICFrames decodes the frames of a decrypted packet, returning the
+// frames decoded before an error.
+func decodeQUICFrames(data []byte, df gopacket.DecodeFeedback) ([]*QUICFrame, error) {
+	var frames []*QUICFrame
+	for len(data) > 0 {
+		f := &QUICFrame{}
+		if err := f.DecodeFromBytes(data, df); err != nil {
+			return frames, err
+		}
+		frames = append(frames, f)
+		data = data[len(f.Contents):]
+	}
+	return frames, nil
+}
+
+// decodeQUICFrame decodes the frames in data.
+func decodeQUICFrame(data []byte, p gopacket.PacketBuilder) error {
+	frames, err := decodeQUICFrames(data, p)
+	for _, f := range frames {
+		p.AddLayer(f)
+	}
+	return err
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// The header of the client Initial packet of RFC 9001 appendix A.2, with
+// header protection, followed by a zero filled packet number and payload.
+var testQUICInitial = append(mustDecodeHex("c000000001088394c8f03e5157080000449e"), make([]byte, 0x49e)...)
+
+// The Retry packet of RFC 9001 appendix A.4.
+var testQUICRetry = mustDecodeHex("ff000000010008f067a5502a4262b5746f6b656e04a265ba2eff4d829058fb3f0f2496ba")
+
+func TestQUICInitial(t *testing.T) {
+	p := gopacket.NewPacket(testQUICInitial, LayerTypeQUIC, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeQUIC}, t)
+	q := p.Layer(LayerTypeQUIC).(*QUIC)
+	want := &QUIC{
+		BaseLayer:        BaseLayer{Contents: testQUICInitial[:18], Payload: testQUICInitial[18:]},
+		LongHeader:       true,
+		FixedBit:         true,
+		PacketType:       QUICPacketTypeInitial,
+		Version:          QUICVersion1,
+		DestConnectionID: mustDecodeHex("8394c8f03e515708"),
+		SrcConnectionID:  []byte{},
+		Token:            []byte{},
+		Length:           0x49e,
+	}
+	if !reflect.DeepEqual(q, want) {
+		t.Errorf("got\n%#v\nwant\n%#v", q, want)
+	}
+	if p.ApplicationLayer() != q {
+		t.Error("QUIC isn't the application layer")
+	}
+	if n := q.PacketNumberLength(); n != 1 {
+		t.Errorf("packet number length %d, want 1", n)
+	}
+
+	// round trip
+	buf := gopacket.NewSerializeBuffer()
+	q.Length = 0
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, q, gopacket.Payload(q.Payload())); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testQUICInitial) {
+		t.Errorf("serialized\n%x\nwant\n%x", buf.Bytes(), testQUICInitial)
+	}
+}
+
+func TestQUICCoalesced(t *testing.T) {
+	handshake := &QUIC{LongHeader: true, FixedBit: true, PacketType: QUICPacketTypeHandshake, Version: QUICVersion2,
+		DestConnectionID: []byte{1, 2}, SrcConnectionID: []byte{3}, TypeSpecificBits: 2}
+	data, err := Build().Layer(handshake).Payload([]byte{4, 5, 6}).Serialize(gopacket.SerializeOptions{FixLengths: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// version 2 rotates the packet types
+	if data[0] != 0xf2 {
+		t.Errorf("first byte %#x, want 0xf2", data[0])
+	}
+	// Initial, Handshake and padding
+	data = append(append(append([]byte{}, testQUICInitial...), data...), 0, 0, 0)
+
+	p := gopacket.NewPacket(data, LayerTypeQUIC, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeQUIC, LayerTypeQUIC}, t)
+	q := p.Layers()[1].(*QUIC)
+	if q.PacketType != QUICPacketTypeHandshake || q.Version != QUICVersion2 || q.Length != 3 ||
+		!bytes.Equal(q.Payload(), []byte{4, 5, 6}) || q.PacketNumberLength() != 3 {
+		t.Errorf("unexpected handshake packet %#v", q)
+	}
+}
+
+func TestQUICRetry(t *testing.T) {
+	var q QUIC
+	if err := q.DecodeFromBytes(testQUICRetry, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if q.PacketType != QUICPacketTypeRetry || len(q.DestConnectionID) != 0 ||
+		!bytes.Equal(q.SrcConnectionID, mustDecodeHex("f067a5502a4262b5")) ||
+		string(q.Token) != "token" || !bytes.Equal(q.RetryIntegrityTag, testQUICRetry[len(testQUICRetry)-16:]) ||
+		q.PacketNumberLength() != 0 {
+		t.Errorf("unexpected retry packet %#v", q)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := q.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testQUICRetry) {
+		t.Errorf("serialized\n%x\nwant\n%x", buf.Bytes(), testQUICRetry)
+	}
+}
+
+func TestQUICVersionNegotiation(t *testing.T) {
+	data := mustDecodeHex("b500000000" + "0101" + "020203" + "00000001" + "6b3343cf")
+	var q QUIC
+	if err := q.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if q.PacketType != QUICPacketTypeVersionNegotiation || q.TypeSpecificBits != 0x35 ||
+		!reflect.DeepEqual(q.SupportedVersions, []uint32{QUICVersion1, QUICVersion2}) {
+		t.Errorf("unexpected version negotiation packet %#v", q)
+	}
+	if DetectQUIC(&gopacket.UnknownProtocol{Data: data}) != LayerTypeQUIC {
+		t.Error("version negotiation packet not detected")
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := q.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("serialized\n%x\nwant\n%x", buf.Bytes(), data)
+	}
+}
+
+func TestQUICShortHeader(t *testing.T) {
+	defer func(n int) { QUICShortHeaderConnectionIDLength = n }(QUICShortHeaderConnectionIDLength)
+	QUICShortHeaderConnectionIDLength = 4
+
+	data, err := Build().
+		IPv4(&IPv4{SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}).
+		UDP(&UDP{SrcPort: 50000, DstPort: 443}).
+		Payload(mustDecodeHex("7401020304aabbcc")).
+		Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeQUIC}, t)
+	q, ok := p.Layer(LayerTypeQUIC).(*QUIC)
+	if !ok {
+		t.Fatal("no QUIC layer")
+	}
+	if q.PacketType != QUICPacketType1RTT || !q.SpinBit || !q.KeyPhase() || q.PacketNumberLength() != 1 ||
+		!bytes.Equal(q.DestConnectionID, []byte{1, 2, 3, 4}) || !bytes.Equal(q.Payload(), []byte{0xaa, 0xbb, 0xcc}) {
+		t.Errorf("unexpected short header packet %#v", q)
+	}
+}
+
+func TestQUICTruncated(t *testing.T) {
+	for i := 0; i < 18; i++ {
+		var q QUIC
+		if err := q.DecodeFromBytes(testQUICInitial[:i], gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("no error for %d bytes", i)
+		}
+	}
+	p := gopacket.NewPacket(testQUICInitial[:100], LayerTypeQUIC, gopacket.Default)
+	if p.ErrorLayer() != nil || !p.Metadata().Truncated {
+		t.Errorf("expected truncated packet without error, got %v", p)
+	}
+}
+
+func TestDetectQUIC(t *testing.T) {
+	for _, test := range []struct {
+		data []byte
+		want bool
+	}{
+		{testQUICInitial, true},
+		{testQUICRetry, true},
+		// unknown version
+		{mustDecodeHex("c0abcdef01088394c8f03e5157080000449e"), false},
+		// fixed bit not set
+		{mustDecodeHex("8000000001088394c8f03e5157080000449e"), false},
+		// short header
+		{mustDecodeHex("4000000001088394c8f03e5157080000449e"), false},
+		// connection ID too long
+		{mustDecodeHex("c000000001158394c8f03e5157080000449e"), false},
+	} {
+		if got := DetectQUIC(&gopacket.UnknownProtocol{Data: test.data}) != nil; got != test.want {
+			t.Errorf("DetectQUIC(%x) = %v, want %v", test.data[:8], got, test.want)
+		}
+	}
+}
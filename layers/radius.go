@@ -182,6 +182,9 @@ const (
 	RADIUSAttributeTypeFramedPool             RADIUSAttributeType = 88 // RFC2869 5.18.  Framed-Pool
 	RADIUSAttributeTypeTunnelClientAuthID     RADIUSAttributeType = 90 // RFC2868  3.9.  Tunnel-Client-Auth-ID
 	RADIUSAttributeTypeTunnelServerAuthID     RADIUSAttributeType = 91 // RFC2868 3.10.  Tunnel-Server-Auth-ID
+	RADIUSAttributeTypeNASIPv6Address         RADIUSAttributeType = 95 // RFC3162  2.1.  NAS-IPv6-Address
+	RADIUSAttributeTypeFramedInterfaceId      RADIUSAttributeType = 96 // RFC3162  2.2.  Framed-Interface-Id
+	RADIUSAttributeTypeFramedIPv6Prefix       RADIUSAttributeType = 97 // RFC3162  2.3.  Framed-IPv6-Prefix
 )
 
 // RADIUSAttributeType represents attribute length.
@@ -359,6 +362,12 @@ func (t RADIUSAttributeType) String() (s string) {
 		s = "Tunnel-Client-Auth-ID"
 	case RADIUSAttributeTypeTunnelServerAuthID:
 		s = "Tunnel-Server-Auth-ID"
+	case RADIUSAttributeTypeNASIPv6Address:
+		s = "NAS-IPv6-Address"
+	case RADIUSAttributeTypeFramedInterfaceId:
+		s = "Framed-Interface-Id"
+	case RADIUSAttributeTypeFramedIPv6Prefix:
+		s = "Framed-IPv6-Prefix"
 	default:
 		s = fmt.Sprintf("Unknown(%d)", t)
 	}
@@ -396,6 +405,7 @@ func (radius *RADIUS) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) e
 	}
 
 	radius.BaseLayer = BaseLayer{Contents: data}
+	radius.Attributes = radius.Attributes[:0]
 
 	radius.Code = RADIUSCode(data[0])
 	radius.Identifier = RADIUSIdentifier(data[1])
@@ -456,11 +466,9 @@ func (radius *RADIUS) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) e
 			return fmt.Errorf("RADIUS attributes length %d too short", attr.Length)
 		}
 
-		if int(attr.Length) > radiusAttributesMinimumRecordSizeInBytes {
-			attr.Value = make([]byte, attr.Length-2)
-			copy(attr.Value[:], data[pos+2:pos+int(attr.Length)])
-			radius.Attributes = append(radius.Attributes, attr)
-		}
+		attr.Value = make([]byte, attr.Length-2)
+		copy(attr.Value[:], data[pos+2:pos+int(attr.Length)])
+		radius.Attributes = append(radius.Attributes, attr)
 
 		pos += int(attr.Length)
 	}
@@ -484,6 +492,9 @@ func (radius *RADIUS) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Seri
 	}
 
 	if opts.FixLengths {
+		if plen > radiusMaximumRecordSizeInBytes {
+			return fmt.Errorf("RADIUS length %d too big", plen)
+		}
 		radius.Length = RADIUSLength(plen)
 	}
 
@@ -498,12 +509,14 @@ func (radius *RADIUS) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Seri
 	copy(data[4:20], radius.Authenticator[:])
 
 	pos := radiusMinimumRecordSizeInBytes
-	for _, v := range radius.Attributes {
+	for i := range radius.Attributes {
+		v := &radius.Attributes[i]
 		if opts.FixLengths {
 			v.Length, err = attributeValueLength(v.Value)
 			if err != nil {
 				return err
 			}
+			v.Length += 2 // Added Type and Length
 		}
 
 		data[pos] = byte(v.Type)
@@ -552,7 +565,7 @@ func decodeRADIUS(data []byte, p gopacket.PacketBuilder) error {
 
 func attributeValueLength(v []byte) (RADIUSAttributeLength, error) {
 	n := len(v)
-	if n > 255 {
+	if n > 255-radiusAttributesMinimumRecordSizeInBytes {
 		return 0, fmt.Errorf("RADIUS attribute value length %d too long", n)
 	} else {
 		return RADIUSAttributeLength(n), nil
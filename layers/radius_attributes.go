@@ -0,0 +1,456 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/gopacket"
+)
+
+const (
+	// RFC 2865 5.  Attributes
+	// `The Length field is one octet`
+	radiusMaximumAttributeValueSizeInBytes int = 255 - radiusAttributesMinimumRecordSizeInBytes
+
+	// RFC 3579 3.2.  Message-Authenticator
+	radiusMessageAuthenticatorSizeInBytes int = 16
+)
+
+// Attribute returns the first attribute of type t, or nil.
+func (radius *RADIUS) Attribute(t RADIUSAttributeType) *RADIUSAttribute {
+	for i := range radius.Attributes {
+		if radius.Attributes[i].Type == t {
+			return &radius.Attributes[i]
+		}
+	}
+	return nil
+}
+
+// UserName returns the User-Name attribute, or "".
+func (radius *RADIUS) UserName() string {
+	return radius.Attribute(RADIUSAttributeTypeUserName).Text()
+}
+
+// NASIdentifier returns the NAS-Identifier attribute, or "".
+func (radius *RADIUS) NASIdentifier() string {
+	return radius.Attribute(RADIUSAttributeTypeNASIdentifier).Text()
+}
+
+// NASIPAddress returns the NAS-IP-Address attribute, or the NAS-IPv6-Address
+// attribute, or nil.
+func (radius *RADIUS) NASIPAddress() net.IP {
+	if ip, err := radius.Attribute(RADIUSAttributeTypeNASIPAddress).IP(); err == nil {
+		return ip
+	}
+	ip, _ := radius.Attribute(RADIUSAttributeTypeNASIPv6Address).IP()
+	return ip
+}
+
+// FramedIPAddress returns the Framed-IP-Address attribute, or nil.
+func (radius *RADIUS) FramedIPAddress() net.IP {
+	ip, _ := radius.Attribute(RADIUSAttributeTypeFramedIPAddress).IP()
+	return ip
+}
+
+// FramedIPv6Prefix returns the Framed-IPv6-Prefix attribute, or nil.
+func (radius *RADIUS) FramedIPv6Prefix() *net.IPNet {
+	prefix, _ := radius.Attribute(RADIUSAttributeTypeFramedIPv6Prefix).IPv6Prefix()
+	return prefix
+}
+
+// EAPMessage returns the concatenated values of the EAP-Message attributes,
+// which is also the payload of the layer.
+func (radius *RADIUS) EAPMessage() []byte {
+	var msg []byte
+	for _, v := range radius.Attributes {
+		if v.Type == RADIUSAttributeTypeEAPMessage {
+			msg = append(msg, v.Value...)
+		}
+	}
+	return msg
+}
+
+// SetEAPMessage replaces the EAP-Message attributes by ones carrying msg,
+// splitting it into attributes of at most 253 bytes.
+func (radius *RADIUS) SetEAPMessage(msg []byte) {
+	var attrs []RADIUSAttribute
+	added := false
+	for _, v := range radius.Attributes {
+		if v.Type != RADIUSAttributeTypeEAPMessage {
+			attrs = append(attrs, v)
+			continue
+		}
+		if !added {
+			attrs = appendRADIUSEAPMessage(attrs, msg)
+			added = true
+		}
+	}
+	if !added {
+		attrs = appendRADIUSEAPMessage(attrs, msg)
+	}
+	radius.Attributes = attrs
+}
+
+func appendRADIUSEAPMessage(attrs []RADIUSAttribute, msg []byte) []RADIUSAttribute {
+	for len(msg) > 0 {
+		n := len(msg)
+		if n > radiusMaximumAttributeValueSizeInBytes {
+			n = radiusMaximumAttributeValueSizeInBytes
+		}
+		attrs = append(attrs, RADIUSAttribute{
+			Type:   RADIUSAttributeTypeEAPMessage,
+			Length: RADIUSAttributeLength(n + 2),
+			Value:  RADIUSAttributeValue(msg[:n]),
+		})
+		msg = msg[n:]
+	}
+	return attrs
+}
+
+// Text returns the value of text and string attributes, e.g. User-Name, or ""
+// for a nil attribute.
+func (a *RADIUSAttribute) Text() string {
+	if a == nil {
+		return ""
+	}
+	return string(a.Value)
+}
+
+// Uint32 returns the value of integer attributes, e.g. NAS-Port.
+func (a *RADIUSAttribute) Uint32() (uint32, error) {
+	if a == nil || len(a.Value) != 4 {
+		return 0, errors.New("RADIUS attribute isn't an integer")
+	}
+	return binary.BigEndian.Uint32(a.Value), nil
+}
+
+// IP returns the value of IPv4 and IPv6 address attributes, e.g.
+// Framed-IP-Address.
+func (a *RADIUSAttribute) IP() (net.IP, error) {
+	if a == nil || (len(a.Value) != net.IPv4len && len(a.Value) != net.IPv6len) {
+		return nil, errors.New("RADIUS attribute isn't an IP address")
+	}
+	return net.IP(a.Value), nil
+}
+
+// IPv6Prefix returns the value of IPv6 prefix attributes, e.g.
+// Framed-IPv6-Prefix.
+func (a *RADIUSAttribute) IPv6Prefix() (*net.IPNet, error) {
+	if a == nil || len(a.Value) < 2 || a.Value[1] > 128 || len(a.Value)-2 > net.IPv6len {
+		return nil, errors.New("RADIUS attribute isn't an IPv6 prefix")
+	}
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, a.Value[2:])
+	mask := net.CIDRMask(int(a.Value[1]), 128)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}, nil
+}
+
+// RADIUSVendor describes the Vendor-Specific attributes of a vendor.
+type RADIUSVendor struct {
+	Name string
+	// TypeLength is the length of the type of its attributes, 1, 2 or 4, and
+	// LengthLength is the length of their length, 0, 1 or 2.  Both are 1 for
+	// the format suggested by RFC 2865, which is used for vendors that
+	// aren't registered.
+	TypeLength, LengthLength int
+	// Attributes are the names of its attributes
+	Attributes map[uint32]string
+}
+
+// Some RADIUS vendor IDs
+const (
+	RADIUSVendorCisco     uint32 = 9
+	RADIUSVendorMicrosoft uint32 = 311
+	RADIUSVendorJuniper   uint32 = 2636
+	RADIUSVendorWISPr     uint32 = 14122
+)
+
+var radiusVendorsMu sync.Mutex
+
+var radiusVendors atomic.Value // map[uint32]RADIUSVendor
+
+// RegisterRADIUSVendor registers the dictionary of the Vendor-Specific
+// attributes of vendorID, replacing any previous one.  It may be called while
+// packets are decoded by other goroutines.
+func RegisterRADIUSVendor(vendorID uint32, vendor RADIUSVendor) {
+	if (vendor.TypeLength != 1 && vendor.TypeLength != 2 && vendor.TypeLength != 4) || vendor.LengthLength < 0 || vendor.LengthLength > 2 {
+		panic(fmt.Sprintf("invalid RADIUS vendor format %d,%d", vendor.TypeLength, vendor.LengthLength))
+	}
+	radiusVendorsMu.Lock()
+	defer radiusVendorsMu.Unlock()
+	old, _ := radiusVendors.Load().(map[uint32]RADIUSVendor)
+	m := make(map[uint32]RADIUSVendor, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[vendorID] = vendor
+	radiusVendors.Store(m)
+}
+
+func radiusVendor(vendorID uint32) RADIUSVendor {
+	vendor, ok := radiusVendors.Load().(map[uint32]RADIUSVendor)[vendorID]
+	if !ok {
+		vendor = RADIUSVendor{TypeLength: 1, LengthLength: 1}
+	}
+	return vendor
+}
+
+func init() {
+	RegisterRADIUSVendor(RADIUSVendorCisco, RADIUSVendor{Name: "Cisco", TypeLength: 1, LengthLength: 1, Attributes: map[uint32]string{
+		1:  "Cisco-AVPair",
+		2:  "Cisco-NAS-Port",
+		23: "Cisco-Disconnect-Cause",
+	}})
+	RegisterRADIUSVendor(RADIUSVendorMicrosoft, RADIUSVendor{Name: "Microsoft", TypeLength: 1, LengthLength: 1, Attributes: map[uint32]string{
+		1:  "MS-CHAP-Response",
+		2:  "MS-CHAP-Error",
+		7:  "MS-MPPE-Encryption-Policy",
+		8:  "MS-MPPE-Encryption-Types",
+		11: "MS-CHAP-Challenge",
+		16: "MS-MPPE-Send-Key",
+		17: "MS-MPPE-Recv-Key",
+		25: "MS-CHAP2-Response",
+		26: "MS-CHAP2-Success",
+	}})
+	RegisterRADIUSVendor(RADIUSVendorJuniper, RADIUSVendor{Name: "Juniper", TypeLength: 1, LengthLength: 1, Attributes: map[uint32]string{
+		1: "Juniper-Local-User-Name",
+		2: "Juniper-Allow-Commands",
+		3: "Juniper-Deny-Commands",
+	}})
+	RegisterRADIUSVendor(RADIUSVendorWISPr, RADIUSVendor{Name: "WISPr", TypeLength: 1, LengthLength: 1, Attributes: map[uint32]string{
+		1: "WISPr-Location-ID",
+		2: "WISPr-Location-Name",
+		3: "WISPr-Logoff-URL",
+		4: "WISPr-Redirection-URL",
+		7: "WISPr-Bandwidth-Max-Up",
+		8: "WISPr-Bandwidth-Max-Down",
+	}})
+}
+
+// RADIUSVendorAttribute is an attribute of a Vendor-Specific attribute.
+type RADIUSVendorAttribute struct {
+	Type uint32
+	// Name is the name of the attribute in the dictionary of the vendor
+	Name  string
+	Value []byte
+}
+
+// RADIUSVendorSpecific is the value of a Vendor-Specific attribute.
+type RADIUSVendorSpecific struct {
+	VendorID   uint32
+	Attributes []RADIUSVendorAttribute
+}
+
+// VendorSpecific decodes the value of a Vendor-Specific attribute in the
+// format of its vendor, registered with RegisterRADIUSVendor.
+func (a *RADIUSAttribute) VendorSpecific() (*RADIUSVendorSpecific, error) {
+	if a == nil || a.Type != RADIUSAttributeTypeVendorSpecific {
+		return nil, errors.New("RADIUS attribute isn't Vendor-Specific")
+	}
+	if len(a.Value) < 4 {
+		return nil, errors.New("RADIUS Vendor-Specific attribute too short")
+	}
+	v := &RADIUSVendorSpecific{VendorID: binary.BigEndian.Uint32(a.Value[0:4])}
+	vendor := radiusVendor(v.VendorID)
+	hlen := vendor.TypeLength + vendor.LengthLength
+	for data := a.Value[4:]; len(data) > 0; {
+		if len(data) < hlen {
+			return nil, errors.New("RADIUS vendor attribute too short")
+		}
+		attr := RADIUSVendorAttribute{Type: radiusVendorField(data[:vendor.TypeLength])}
+		length := len(data)
+		if vendor.LengthLength > 0 {
+			length = int(radiusVendorField(data[vendor.TypeLength:hlen]))
+		}
+		if length < hlen || length > len(data) {
+			return nil, fmt.Errorf("invalid RADIUS vendor attribute length %d", length)
+		}
+		attr.Name = vendor.Attributes[attr.Type]
+		attr.Value = data[hlen:length]
+		v.Attributes = append(v.Attributes, attr)
+		data = data[length:]
+	}
+	return v, nil
+}
+
+func radiusVendorField(b []byte) uint32 {
+	var v uint32
+	for _, c := range b {
+		v = v<<8 | uint32(c)
+	}
+	return v
+}
+
+// Attribute encodes the Vendor-Specific attribute in the format of its
+// vendor.
+func (v *RADIUSVendorSpecific) Attribute() (RADIUSAttribute, error) {
+	vendor := radiusVendor(v.VendorID)
+	hlen := vendor.TypeLength + vendor.LengthLength
+	value := make([]byte, 4, radiusMaximumAttributeValueSizeInBytes)
+	binary.BigEndian.PutUint32(value, v.VendorID)
+	for _, attr := range v.Attributes {
+		length := hlen + len(attr.Value)
+		if vendor.LengthLength == 0 && len(v.Attributes) > 1 {
+			return RADIUSAttribute{}, errors.New("RADIUS vendor attributes without length must be alone")
+		}
+		if vendor.LengthLength > 0 && length >= 1<<(8*uint(vendor.LengthLength)) {
+			return RADIUSAttribute{}, fmt.Errorf("RADIUS vendor attribute of %d bytes too long", len(attr.Value))
+		}
+		for i := vendor.TypeLength - 1; i >= 0; i-- {
+			value = append(value, byte(attr.Type>>(8*uint(i))))
+		}
+		for i := vendor.LengthLength - 1; i >= 0; i-- {
+			value = append(value, byte(length>>(8*uint(i))))
+		}
+		value = append(value, attr.Value...)
+	}
+	n, err := attributeValueLength(value)
+	if err != nil {
+		return RADIUSAttribute{}, err
+	}
+	return RADIUSAttribute{Type: RADIUSAttributeTypeVendorSpecific, Length: n + 2, Value: value}, nil
+}
+
+// VendorAttributes returns the attributes of vendorID in all Vendor-Specific
+// attributes which can be decoded.
+func (radius *RADIUS) VendorAttributes(vendorID uint32) []RADIUSVendorAttribute {
+	var attrs []RADIUSVendorAttribute
+	for i := range radius.Attributes {
+		a := &radius.Attributes[i]
+		if a.Type != RADIUSAttributeTypeVendorSpecific || len(a.Value) < 4 || binary.BigEndian.Uint32(a.Value) != vendorID {
+			continue
+		}
+		if v, err := a.VendorSpecific(); err == nil {
+			attrs = append(attrs, v.Attributes...)
+		}
+	}
+	return attrs
+}
+
+// radiusSignedAuthenticator returns the authenticator that the
+// authenticators of the packet are computed with, RFC 2866 and RFC 3579.
+func (radius *RADIUS) radiusSignedAuthenticator(request *RADIUSAuthenticator) (RADIUSAuthenticator, error) {
+	switch radius.Code {
+	case RADIUSCodeAccessAccept, RADIUSCodeAccessReject, RADIUSCodeAccessChallenge, RADIUSCodeAccountingResponse:
+		if request == nil {
+			return RADIUSAuthenticator{}, fmt.Errorf("RADIUS %v is authenticated with the authenticator of its request", radius.Code)
+		}
+		return *request, nil
+	case RADIUSCodeAccountingRequest:
+		return RADIUSAuthenticator{}, nil
+	}
+	return radius.Authenticator, nil
+}
+
+// serialize returns the packet with the authenticator replaced, and with the
+// value of the Message-Authenticator attribute zeroed if zeroMA.
+func (radius *RADIUS) serialize(opts gopacket.SerializeOptions, authenticator RADIUSAuthenticator, zeroMA bool) ([]byte, error) {
+	buf := gopacket.NewSerializeBuffer()
+	if err := radius.SerializeTo(buf, opts); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	copy(data[4:20], authenticator[:])
+	pos := radiusMinimumRecordSizeInBytes
+	for _, v := range radius.Attributes {
+		if v.Type == RADIUSAttributeTypeMessageAuthenticator && zeroMA {
+			for i := range v.Value {
+				data[pos+2+i] = 0
+			}
+		}
+		pos += len(v.Value) + 2
+	}
+	return data, nil
+}
+
+// ValidateMessageAuthenticator checks the Message-Authenticator attribute of
+// the packet with the shared secret, RFC 3579.  Responses are authenticated
+// with the authenticator of their request.
+func (radius *RADIUS) ValidateMessageAuthenticator(secret []byte, request *RADIUSAuthenticator) error {
+	ma := radius.Attribute(RADIUSAttributeTypeMessageAuthenticator)
+	if ma == nil {
+		return errors.New("RADIUS packet has no Message-Authenticator")
+	}
+	if len(ma.Value) != radiusMessageAuthenticatorSizeInBytes {
+		return fmt.Errorf("RADIUS Message-Authenticator length %d invalid", len(ma.Value))
+	}
+	authenticator, err := radius.radiusSignedAuthenticator(request)
+	if err != nil {
+		return err
+	}
+	data, err := radius.serialize(gopacket.SerializeOptions{}, authenticator, true)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(md5.New, secret)
+	mac.Write(data)
+	if !hmac.Equal(mac.Sum(nil), ma.Value) {
+		return errors.New("RADIUS Message-Authenticator invalid")
+	}
+	return nil
+}
+
+// SetMessageAuthenticator computes the Message-Authenticator attribute of the
+// packet with the shared secret, adding the attribute if it is missing.  The
+// lengths of the packet and of its attributes are set.
+// Responses are authenticated with the authenticator of their request, and
+// their authenticator must be computed after this.
+func (radius *RADIUS) SetMessageAuthenticator(secret []byte, request *RADIUSAuthenticator) error {
+	ma := radius.Attribute(RADIUSAttributeTypeMessageAuthenticator)
+	if ma == nil {
+		radius.Attributes = append(radius.Attributes, RADIUSAttribute{Type: RADIUSAttributeTypeMessageAuthenticator})
+		ma = &radius.Attributes[len(radius.Attributes)-1]
+	}
+	ma.Length = RADIUSAttributeLength(radiusMessageAuthenticatorSizeInBytes + 2)
+	ma.Value = make([]byte, radiusMessageAuthenticatorSizeInBytes)
+	authenticator, err := radius.radiusSignedAuthenticator(request)
+	if err != nil {
+		return err
+	}
+	data, err := radius.serialize(gopacket.SerializeOptions{FixLengths: true}, authenticator, true)
+	if err != nil {
+		return err
+	}
+	mac := hmac.New(md5.New, secret)
+	mac.Write(data)
+	copy(ma.Value, mac.Sum(nil))
+	return nil
+}
+
+// ValidateAuthenticator checks the authenticator of responses, with the
+// authenticator of their request, and of Accounting-Request packets with the
+// shared secret, RFC 2865 and RFC 2866.  The authenticator of other requests
+// is random.
+func (radius *RADIUS) ValidateAuthenticator(secret []byte, request *RADIUSAuthenticator) error {
+	switch radius.Code {
+	case RADIUSCodeAccessAccept, RADIUSCodeAccessReject, RADIUSCodeAccessChallenge, RADIUSCodeAccountingResponse, RADIUSCodeAccountingRequest:
+	default:
+		return fmt.Errorf("RADIUS %v has no authenticator computed with the secret", radius.Code)
+	}
+	authenticator, err := radius.radiusSignedAuthenticator(request)
+	if err != nil {
+		return err
+	}
+	data, err := radius.serialize(gopacket.SerializeOptions{}, authenticator, false)
+	if err != nil {
+		return err
+	}
+	sum := md5.Sum(append(data, secret...))
+	if !bytes.Equal(sum[:], radius.Authenticator[:]) {
+		return errors.New("RADIUS authenticator invalid")
+	}
+	return nil
+}
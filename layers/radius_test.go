@@ -6,6 +6,9 @@
 package layers
 
 import (
+	"bytes"
+	"crypto/md5"
+	"net"
 	"reflect"
 	"testing"
 
@@ -183,6 +186,9 @@ func TestRADIUSAttributeType(t *testing.T) {
 		{name: "Framed-Pool", code: RADIUSAttributeTypeFramedPool},
 		{name: "Tunnel-Client-Auth-ID", code: RADIUSAttributeTypeTunnelClientAuthID},
 		{name: "Tunnel-Server-Auth-ID", code: RADIUSAttributeTypeTunnelServerAuthID},
+		{name: "NAS-IPv6-Address", code: RADIUSAttributeTypeNASIPv6Address},
+		{name: "Framed-Interface-Id", code: RADIUSAttributeTypeFramedInterfaceId},
+		{name: "Framed-IPv6-Prefix", code: RADIUSAttributeTypeFramedIPv6Prefix},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -403,3 +409,101 @@ func TestRADIUSAccessAccept(t *testing.T) {
 
 	checkRADIUS("AccessAccept", t, testPacketRADIUS, pExpectedRADIUS)
 }
+
+func TestRADIUSAttributes(t *testing.T) {
+	secret := []byte("testing123")
+	vsa, err := (&RADIUSVendorSpecific{VendorID: RADIUSVendorCisco, Attributes: []RADIUSVendorAttribute{
+		{Type: 1, Value: []byte("shell:priv-lvl=15")},
+	}}).Attribute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := &RADIUS{
+		Code:          RADIUSCodeAccessRequest,
+		Identifier:    7,
+		Authenticator: RADIUSAuthenticator{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		Attributes: []RADIUSAttribute{
+			{Type: RADIUSAttributeTypeUserName, Value: []byte("alice")},
+			{Type: RADIUSAttributeTypeNASIdentifier, Value: []byte("ap-1")},
+			{Type: RADIUSAttributeTypeNASIPAddress, Value: []byte{192, 0, 2, 1}},
+			{Type: RADIUSAttributeTypeFramedIPv6Prefix, Value: []byte{0, 64, 0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 1}},
+			vsa,
+		},
+	}
+	eap := make([]byte, 300)
+	eap[0], eap[1], eap[2], eap[3], eap[4] = 2, 7, 0x01, 0x2c, 1
+	request.SetEAPMessage(eap)
+	if err := request.SetMessageAuthenticator(secret, nil); err != nil {
+		t.Fatal(err)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := request.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if request.Attributes[0].Length != 7 {
+		t.Errorf("attribute length %d, want 7", request.Attributes[0].Length)
+	}
+
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeRADIUS, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeRADIUS, LayerTypeEAP}, t)
+	r := p.Layer(LayerTypeRADIUS).(*RADIUS)
+	if r.UserName() != "alice" || r.NASIdentifier() != "ap-1" || !r.NASIPAddress().Equal(net.IP{192, 0, 2, 1}) || r.FramedIPAddress() != nil {
+		t.Errorf("wrong attributes %q %q %v %v", r.UserName(), r.NASIdentifier(), r.NASIPAddress(), r.FramedIPAddress())
+	}
+	if prefix := r.FramedIPv6Prefix(); prefix == nil || prefix.String() != "2001:db8:0:1::/64" {
+		t.Errorf("wrong Framed-IPv6-Prefix %v", prefix)
+	}
+	if attrs := r.VendorAttributes(RADIUSVendorCisco); len(attrs) != 1 || attrs[0].Name != "Cisco-AVPair" || string(attrs[0].Value) != "shell:priv-lvl=15" {
+		t.Errorf("wrong vendor attributes %+v", attrs)
+	}
+	if n := len(r.Attributes); n != 8 {
+		t.Errorf("%d attributes, want 8", n)
+	}
+	if !bytes.Equal(r.EAPMessage(), eap) || !bytes.Equal(r.Payload(), eap) {
+		t.Error("wrong EAP-Message")
+	}
+	if err := r.ValidateMessageAuthenticator(secret, nil); err != nil {
+		t.Error(err)
+	}
+	if err := r.ValidateMessageAuthenticator([]byte("wrong"), nil); err == nil {
+		t.Error("Message-Authenticator valid with wrong secret")
+	}
+	if err := r.ValidateAuthenticator(secret, nil); err == nil {
+		t.Error("Access-Request authenticator validated")
+	}
+
+	// A response is authenticated with the authenticator of its request.
+	accept := &RADIUS{Code: RADIUSCodeAccessAccept, Identifier: 7}
+	if err := accept.SetMessageAuthenticator(secret, nil); err == nil {
+		t.Error("response authenticated without request authenticator")
+	}
+	if err := accept.SetMessageAuthenticator(secret, &r.Authenticator); err != nil {
+		t.Fatal(err)
+	}
+	buf = gopacket.NewSerializeBuffer()
+	if err := accept.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	copy(data[4:20], r.Authenticator[:])
+	sum := md5.Sum(append(append([]byte(nil), data...), secret...))
+	copy(data[4:20], sum[:])
+	p = gopacket.NewPacket(data, LayerTypeRADIUS, gopacket.Default)
+	a := p.Layer(LayerTypeRADIUS).(*RADIUS)
+	if err := a.ValidateMessageAuthenticator(secret, &r.Authenticator); err != nil {
+		t.Error(err)
+	}
+	if err := a.ValidateAuthenticator(secret, &r.Authenticator); err != nil {
+		t.Error(err)
+	}
+	if err := a.ValidateAuthenticator(secret, &RADIUSAuthenticator{}); err == nil {
+		t.Error("authenticator valid with wrong request authenticator")
+	}
+
+	if _, err := (&RADIUSAttribute{Type: RADIUSAttributeTypeVendorSpecific, Value: []byte{0, 0, 0, 9, 1, 9, 0}}).VendorSpecific(); err == nil {
+		t.Error("vendor attribute longer than Vendor-Specific decoded")
+	}
+}
@@ -0,0 +1,403 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// RIPCommand is the command of a RIP or RIPng message.
+type RIPCommand uint8
+
+// RIPCommand known values
+const (
+	RIPCommandRequest  RIPCommand = 1
+	RIPCommandResponse RIPCommand = 2
+)
+
+func (c RIPCommand) String() string {
+	switch c {
+	case RIPCommandRequest:
+		return "Request"
+	case RIPCommandResponse:
+		return "Response"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(c))
+	}
+}
+
+// RIPAuthType is the authentication type of a RIPv2 message.
+type RIPAuthType uint16
+
+// RIPAuthType known values, see RFC 2453 and RFC 4822
+const (
+	RIPAuthTypeSimplePassword RIPAuthType = 2
+	RIPAuthTypeCryptographic  RIPAuthType = 3
+)
+
+func (t RIPAuthType) String() string {
+	switch t {
+	case RIPAuthTypeSimplePassword:
+		return "Simple Password"
+	case RIPAuthTypeCryptographic:
+		return "Cryptographic"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint16(t))
+	}
+}
+
+// RIP lengths and markers
+const (
+	ripHeaderLength    = 4
+	ripEntryLength     = 20
+	ripAuthFamily      = 0xffff
+	ripAuthTrailerType = 1
+)
+
+// RIPEntry is a route entry of a RIP message.  RouteTag, Mask and NextHop
+// are only used by RIPv2, and are zero in RIPv1 messages.
+type RIPEntry struct {
+	AddressFamily uint16 // 2 for IPv4, 0 in a request for the whole table
+	RouteTag      uint16
+	IP            net.IP
+	Mask          net.IPMask
+	NextHop       net.IP
+	Metric        uint32 // 1 to 15, 16 meaning unreachable
+}
+
+// RIPAuthentication is the authentication entry of a RIPv2 message, always
+// its first entry.
+type RIPAuthentication struct {
+	Type RIPAuthType
+	// Password is the null padded password of simple password
+	// authentication.
+	Password string
+	// PacketLength, KeyID, AuthDataLength and SequenceNumber are used by
+	// cryptographic authentication, whose digest, found after PacketLength
+	// bytes of the message, is in AuthData.  AuthDataLength includes the 4
+	// bytes preceding the digest in the trailer.
+	PacketLength   uint16
+	KeyID          uint8
+	AuthDataLength uint8
+	SequenceNumber uint32
+	AuthData       []byte
+	// Value is the authentication data of other types.
+	Value []byte
+}
+
+// RIP is a RIP version 1 or 2 message, see RFC 1058 and RFC 2453.
+type RIP struct {
+	BaseLayer
+	Command        RIPCommand
+	Version        uint8
+	Authentication *RIPAuthentication
+	Entries        []RIPEntry
+}
+
+// LayerType returns LayerTypeRIP.
+func (r *RIP) LayerType() gopacket.LayerType { return LayerTypeRIP }
+
+func decodeRIP(data []byte, p gopacket.PacketBuilder) error {
+	r := &RIP{}
+	if err := r.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(r)
+	p.SetApplicationLayer(r)
+	return nil
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (r *RIP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < ripHeaderLength {
+		df.SetTruncated()
+		return errors.New("RIP packet too short")
+	}
+	*r = RIP{
+		Command: RIPCommand(data[0]),
+		Version: data[1],
+		Entries: r.Entries[:0],
+	}
+	entries := data[ripHeaderLength:]
+	if r.Version >= 2 && len(entries) >= ripEntryLength && binary.BigEndian.Uint16(entries[0:2]) == ripAuthFamily {
+		auth, n, err := decodeRIPAuthentication(data)
+		if err != nil {
+			return err
+		}
+		r.Authentication = auth
+		entries = data[ripHeaderLength+ripEntryLength : n]
+	}
+	if len(entries)%ripEntryLength != 0 {
+		df.SetTruncated()
+		return fmt.Errorf("RIP entries length %d not a multiple of %d", len(entries), ripEntryLength)
+	}
+	for ; len(entries) > 0; entries = entries[ripEntryLength:] {
+		r.Entries = append(r.Entries, RIPEntry{
+			AddressFamily: binary.BigEndian.Uint16(entries[0:2]),
+			RouteTag:      binary.BigEndian.Uint16(entries[2:4]),
+			IP:            net.IP(entries[4:8]),
+			Mask:          net.IPMask(entries[8:12]),
+			NextHop:       net.IP(entries[12:16]),
+			Metric:        binary.BigEndian.Uint32(entries[16:20]),
+		})
+	}
+	r.Contents = data
+	return nil
+}
+
+// decodeRIPAuthentication decodes the authentication entry of message data,
+// returning it with the length of the message without the authentication
+// trailer.
+func decodeRIPAuthentication(data []byte) (*RIPAuthentication, int, error) {
+	e := data[ripHeaderLength : ripHeaderLength+ripEntryLength]
+	auth := &RIPAuthentication{Type: RIPAuthType(binary.BigEndian.Uint16(e[2:4]))}
+	switch auth.Type {
+	case RIPAuthTypeSimplePassword:
+		auth.Password = hsrpText(e[4:20])
+		return auth, len(data), nil
+	case RIPAuthTypeCryptographic:
+		auth.PacketLength = binary.BigEndian.Uint16(e[4:6])
+		auth.KeyID = e[6]
+		auth.AuthDataLength = e[7]
+		auth.SequenceNumber = binary.BigEndian.Uint32(e[8:12])
+		n := int(auth.PacketLength)
+		if n < ripHeaderLength+ripEntryLength || n+4 > len(data) {
+			return nil, 0, fmt.Errorf("invalid RIP authenticated packet length %d", n)
+		}
+		trailer := data[n:]
+		if binary.BigEndian.Uint16(trailer[0:2]) != ripAuthFamily || binary.BigEndian.Uint16(trailer[2:4]) != ripAuthTrailerType {
+			return nil, 0, errors.New("RIP authentication trailer not found")
+		}
+		auth.AuthData = trailer[4:]
+		return auth, n, nil
+	default:
+		auth.Value = e[4:20]
+		return auth, len(data), nil
+	}
+}
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (r *RIP) CanDecode() gopacket.LayerClass {
+	return LayerTypeRIP
+}
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (r *RIP) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypeZero
+}
+
+// Payload returns nil, since RIP messages do not carry a payload.
+func (r *RIP) Payload() []byte {
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.  With
+// FixLengths, the packet and authentication data lengths of cryptographic
+// authentication are set.  The digest itself is not computed, since it
+// depends on a key.
+func (r *RIP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	auth := r.Authentication
+	length := ripHeaderLength + len(r.Entries)*ripEntryLength
+	if auth != nil {
+		length += ripEntryLength
+		if auth.Type == RIPAuthTypeCryptographic {
+			if opts.FixLengths {
+				auth.PacketLength = uint16(length)
+				auth.AuthDataLength = uint8(4 + len(auth.AuthData))
+			}
+			length += 4 + len(auth.AuthData)
+		}
+	}
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	for i := range bytes {
+		bytes[i] = 0
+	}
+	bytes[0], bytes[1] = uint8(r.Command), r.Version
+	e := bytes[ripHeaderLength:]
+	if auth != nil {
+		if err := auth.encode(e[:ripEntryLength], bytes[ripHeaderLength+ripEntryLength*(1+len(r.Entries)):]); err != nil {
+			return err
+		}
+		e = e[ripEntryLength:]
+	}
+	for _, entry := range r.Entries {
+		binary.BigEndian.PutUint16(e[0:2], entry.AddressFamily)
+		binary.BigEndian.PutUint16(e[2:4], entry.RouteTag)
+		if err := ripPutIPv4(e[4:8], entry.IP); err != nil {
+			return err
+		}
+		switch len(entry.Mask) {
+		case 0:
+		case net.IPv4len:
+			copy(e[8:12], entry.Mask)
+		default:
+			return fmt.Errorf("invalid RIP mask %v", entry.Mask)
+		}
+		if err := ripPutIPv4(e[12:16], entry.NextHop); err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint32(e[16:20], entry.Metric)
+		e = e[ripEntryLength:]
+	}
+	return nil
+}
+
+// encode writes the authentication entry to e and, for cryptographic
+// authentication, the trailer to trailer.
+func (a *RIPAuthentication) encode(e, trailer []byte) error {
+	binary.BigEndian.PutUint16(e[0:2], ripAuthFamily)
+	binary.BigEndian.PutUint16(e[2:4], uint16(a.Type))
+	switch a.Type {
+	case RIPAuthTypeSimplePassword:
+		if len(a.Password) > 16 {
+			return fmt.Errorf("RIP password %q too long", a.Password)
+		}
+		hsrpPutText(e[4:20], a.Password)
+	case RIPAuthTypeCryptographic:
+		binary.BigEndian.PutUint16(e[4:6], a.PacketLength)
+		e[6], e[7] = a.KeyID, a.AuthDataLength
+		binary.BigEndian.PutUint32(e[8:12], a.SequenceNumber)
+		binary.BigEndian.PutUint16(trailer[0:2], ripAuthFamily)
+		binary.BigEndian.PutUint16(trailer[2:4], ripAuthTrailerType)
+		copy(trailer[4:], a.AuthData)
+	default:
+		if len(a.Value) > 16 {
+			return fmt.Errorf("RIP %v authentication too long", a.Type)
+		}
+		copy(e[4:20], a.Value)
+	}
+	return nil
+}
+
+func ripPutIPv4(data []byte, ip net.IP) error {
+	if ip == nil {
+		return nil
+	}
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return fmt.Errorf("invalid RIP IPv4 address %v", ip)
+	}
+	copy(data, ip4)
+	return nil
+}
+
+// RIPngMetricNextHop is the metric of RIPng next hop entries, whose prefix is
+// the next hop of the following entries.
+const RIPngMetricNextHop = 0xff
+
+// ripngEntryLength is the length of a RIPng route entry.
+const ripngEntryLength = 20
+
+// RIPngEntry is a route entry of a RIPng message.
+type RIPngEntry struct {
+	Prefix       net.IP
+	RouteTag     uint16
+	PrefixLength uint8
+	Metric       uint8 // 1 to 15, 16 meaning unreachable
+}
+
+// IsNextHop returns true if the entry gives the next hop of the following
+// entries rather than a route.
+func (e *RIPngEntry) IsNextHop() bool {
+	return e.Metric == RIPngMetricNextHop
+}
+
+// RIPng is a RIPng message, the IPv6 version of RIP, see RFC 2080.
+type RIPng struct {
+	BaseLayer
+	Command RIPCommand
+	Version uint8
+	Entries []RIPngEntry
+}
+
+// LayerType returns LayerTypeRIPng.
+func (r *RIPng) LayerType() gopacket.LayerType { return LayerTypeRIPng }
+
+func decodeRIPng(data []byte, p gopacket.PacketBuilder) error {
+	r := &RIPng{}
+	if err := r.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(r)
+	p.SetApplicationLayer(r)
+	return nil
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (r *RIPng) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < ripHeaderLength {
+		df.SetTruncated()
+		return errors.New("RIPng packet too short")
+	}
+	if (len(data)-ripHeaderLength)%ripngEntryLength != 0 {
+		df.SetTruncated()
+		return fmt.Errorf("RIPng entries length %d not a multiple of %d", len(data)-ripHeaderLength, ripngEntryLength)
+	}
+	*r = RIPng{
+		Command: RIPCommand(data[0]),
+		Version: data[1],
+		Entries: r.Entries[:0],
+	}
+	for e := data[ripHeaderLength:]; len(e) > 0; e = e[ripngEntryLength:] {
+		r.Entries = append(r.Entries, RIPngEntry{
+			Prefix:       net.IP(e[0:16]),
+			RouteTag:     binary.BigEndian.Uint16(e[16:18]),
+			PrefixLength: e[18],
+			Metric:       e[19],
+		})
+	}
+	r.Contents = data
+	return nil
+}
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (r *RIPng) CanDecode() gopacket.LayerClass {
+	return LayerTypeRIPng
+}
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (r *RIPng) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypeZero
+}
+
+// Payload returns nil, since RIPng messages do not carry a payload.
+func (r *RIPng) Payload() []byte {
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+func (r *RIPng) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	bytes, err := b.PrependBytes(ripHeaderLength + len(r.Entries)*ripngEntryLength)
+	if err != nil {
+		return err
+	}
+	bytes[0], bytes[1], bytes[2], bytes[3] = uint8(r.Command), r.Version, 0, 0
+	e := bytes[ripHeaderLength:]
+	for _, entry := range r.Entries {
+		if entry.Prefix == nil {
+			copy(e[0:16], net.IPv6zero)
+		} else if ip6 := entry.Prefix.To16(); ip6 != nil && entry.Prefix.To4() == nil {
+			copy(e[0:16], ip6)
+		} else {
+			return fmt.Errorf("invalid RIPng prefix %v", entry.Prefix)
+		}
+		binary.BigEndian.PutUint16(e[16:18], entry.RouteTag)
+		e[18], e[19] = entry.PrefixLength, entry.Metric
+		e = e[ripngEntryLength:]
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketRIPv2Response is a RIPv2 response with two routes, the second one
+// tagged and with a next hop.
+var testPacketRIPv2Response = []byte{
+	0x01, 0x00, 0x5e, 0x00, 0x00, 0x09, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x48, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x16, 0x33, 0xc0, 0xa8, 0x01, 0x01, 0xe0, 0x00,
+	0x00, 0x09, 0x02, 0x08, 0x02, 0x08, 0x00, 0x34, 0xdf, 0x9c, 0x02, 0x02, 0x00, 0x00, 0x00, 0x02,
+	0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x01, 0x00, 0x02, 0x00, 0x64, 0xac, 0x10, 0x00, 0x00, 0xff, 0xff, 0x00, 0x00, 0xc0, 0xa8,
+	0x01, 0xfe, 0x00, 0x00, 0x00, 0x03,
+}
+
+// testPacketRIPv1Request is a broadcast RIPv1 request for the whole routing
+// table.
+var testPacketRIPv1Request = []byte{
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x34, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x33, 0xa9, 0xc0, 0xa8, 0x01, 0x01, 0xc0, 0xa8,
+	0x01, 0xff, 0x02, 0x08, 0x02, 0x08, 0x00, 0x20, 0x76, 0x3c, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x10,
+}
+
+// testPacketRIPv2MD5 is a RIPv2 response with keyed MD5 authentication.
+var testPacketRIPv2MD5 = []byte{
+	0x01, 0x00, 0x5e, 0x00, 0x00, 0x09, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x5c, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x16, 0x1f, 0xc0, 0xa8, 0x01, 0x01, 0xe0, 0x00,
+	0x00, 0x09, 0x02, 0x08, 0x02, 0x08, 0x00, 0x48, 0x93, 0x65, 0x02, 0x02, 0x00, 0x00, 0xff, 0xff,
+	0x00, 0x03, 0x00, 0x2c, 0x01, 0x14, 0x00, 0x00, 0x00, 0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x0a, 0x01, 0x00, 0x00, 0xff, 0xff, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xff, 0x00, 0x01, 0x10, 0x11, 0x12, 0x13, 0x14, 0x15,
+	0x16, 0x17, 0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+}
+
+// testPacketRIPngResponse is a RIPng response with a next hop entry followed
+// by two routes.
+var testPacketRIPngResponse = []byte{
+	0x33, 0x33, 0x00, 0x00, 0x00, 0x09, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x86, 0xdd, 0x60, 0x00,
+	0x00, 0x00, 0x00, 0x48, 0x11, 0xff, 0xfe, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x0c,
+	0x29, 0xff, 0xfe, 0x11, 0x22, 0x33, 0xff, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x09, 0x02, 0x09, 0x02, 0x09, 0x00, 0x48, 0x97, 0x53, 0x02, 0x01,
+	0x00, 0x00, 0xfe, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x0c, 0x29, 0xff, 0xfe, 0x11,
+	0x22, 0xfe, 0x00, 0x00, 0x00, 0xff, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x01, 0x20, 0x01, 0x0d, 0xb8, 0x00, 0x02,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x07, 0x30, 0x02,
+}
+
+func TestRIPv2Response(t *testing.T) {
+	p := gopacket.NewPacket(testPacketRIPv2Response, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeRIP}, t)
+	rip := p.ApplicationLayer().(*RIP)
+	want := &RIP{
+		BaseLayer: BaseLayer{Contents: testPacketRIPv2Response[42:]},
+		Command:   RIPCommandResponse,
+		Version:   2,
+		Entries: []RIPEntry{
+			{
+				AddressFamily: 2,
+				IP:            net.IP{10, 0, 0, 0},
+				Mask:          net.IPMask{255, 0, 0, 0},
+				NextHop:       net.IP{0, 0, 0, 0},
+				Metric:        1,
+			},
+			{
+				AddressFamily: 2,
+				RouteTag:      100,
+				IP:            net.IP{172, 16, 0, 0},
+				Mask:          net.IPMask{255, 255, 0, 0},
+				NextHop:       net.IP{192, 168, 1, 254},
+				Metric:        3,
+			},
+		},
+	}
+	if !reflect.DeepEqual(rip, want) {
+		t.Errorf("RIP mismatch:\ngot  %#v\nwant %#v", rip, want)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := rip.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testPacketRIPv2Response[42:]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), testPacketRIPv2Response[42:])
+	}
+}
+
+func TestRIPv1Request(t *testing.T) {
+	p := gopacket.NewPacket(testPacketRIPv1Request, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeRIP}, t)
+	rip := p.ApplicationLayer().(*RIP)
+	if rip.Command != RIPCommandRequest || rip.Version != 1 || rip.Authentication != nil {
+		t.Errorf("unexpected RIP header %v, version %d, authentication %v", rip.Command, rip.Version, rip.Authentication)
+	}
+	if len(rip.Entries) != 1 || rip.Entries[0].AddressFamily != 0 || rip.Entries[0].Metric != 16 {
+		t.Errorf("unexpected whole table request entries %+v", rip.Entries)
+	}
+
+	// Entries built by hand have no mask nor next hop
+	rip = &RIP{
+		Command: RIPCommandRequest,
+		Version: 1,
+		Entries: []RIPEntry{{Metric: 16}},
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := rip.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testPacketRIPv1Request[42:]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), testPacketRIPv1Request[42:])
+	}
+}
+
+func TestRIPv2MD5(t *testing.T) {
+	p := gopacket.NewPacket(testPacketRIPv2MD5, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeRIP}, t)
+	rip := p.ApplicationLayer().(*RIP)
+	want := &RIP{
+		BaseLayer: BaseLayer{Contents: testPacketRIPv2MD5[42:]},
+		Command:   RIPCommandResponse,
+		Version:   2,
+		Authentication: &RIPAuthentication{
+			Type:           RIPAuthTypeCryptographic,
+			PacketLength:   44,
+			KeyID:          1,
+			AuthDataLength: 20,
+			SequenceNumber: 42,
+			AuthData:       testPacketRIPv2MD5[90:106],
+		},
+		Entries: []RIPEntry{
+			{
+				AddressFamily: 2,
+				IP:            net.IP{10, 1, 0, 0},
+				Mask:          net.IPMask{255, 255, 0, 0},
+				NextHop:       net.IP{0, 0, 0, 0},
+				Metric:        2,
+			},
+		},
+	}
+	if !reflect.DeepEqual(rip, want) {
+		t.Errorf("RIP mismatch:\ngot  %#v\nwant %#v", rip, want)
+	}
+
+	// FixLengths restores the lengths of the authentication entry
+	rip.Authentication.PacketLength = 0
+	rip.Authentication.AuthDataLength = 0
+	buf := gopacket.NewSerializeBuffer()
+	if err := rip.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testPacketRIPv2MD5[42:]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), testPacketRIPv2MD5[42:])
+	}
+}
+
+func TestRIPv2SimplePassword(t *testing.T) {
+	rip := &RIP{
+		Command:        RIPCommandResponse,
+		Version:        2,
+		Authentication: &RIPAuthentication{Type: RIPAuthTypeSimplePassword, Password: "secret"},
+		Entries: []RIPEntry{
+			{
+				AddressFamily: 2,
+				IP:            net.IP{10, 2, 0, 0},
+				Mask:          net.IPMask{255, 255, 255, 0},
+				NextHop:       net.IP{0, 0, 0, 0},
+				Metric:        4,
+			},
+		},
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := rip.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(buf.Bytes()) != 44 {
+		t.Fatalf("serialized %d bytes, want 44", len(buf.Bytes()))
+	}
+	var got RIP
+	if err := got.DecodeFromBytes(buf.Bytes(), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	rip.Contents = buf.Bytes()
+	if !reflect.DeepEqual(&got, rip) {
+		t.Errorf("RIP mismatch:\ngot  %#v\nwant %#v", &got, rip)
+	}
+
+	rip.Authentication.Password = "a password too long"
+	if err := rip.SerializeTo(gopacket.NewSerializeBuffer(), gopacket.SerializeOptions{}); err == nil {
+		t.Error("RIP serialized a password longer than 16 bytes")
+	}
+}
+
+func TestRIPDecodeInvalid(t *testing.T) {
+	longPacket := append([]byte{}, testPacketRIPv2MD5[42:]...)
+	longPacket[9] = 90 // Authenticated packet length
+	for _, test := range []struct {
+		name string
+		data []byte
+	}{
+		{"truncated header", testPacketRIPv2Response[42:45]},
+		{"truncated entry", testPacketRIPv2Response[42 : len(testPacketRIPv2Response)-1]},
+		{"packet length too large", longPacket},
+		{"missing trailer", testPacketRIPv2MD5[42:86]},
+	} {
+		var rip RIP
+		if err := rip.DecodeFromBytes(test.data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", test.name)
+		}
+	}
+}
+
+func TestRIPngResponse(t *testing.T) {
+	p := gopacket.NewPacket(testPacketRIPngResponse, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv6, LayerTypeUDP, LayerTypeRIPng}, t)
+	ripng := p.ApplicationLayer().(*RIPng)
+	want := &RIPng{
+		BaseLayer: BaseLayer{Contents: testPacketRIPngResponse[62:]},
+		Command:   RIPCommandResponse,
+		Version:   1,
+		Entries: []RIPngEntry{
+			{Prefix: net.ParseIP("fe80::20c:29ff:fe11:22fe"), Metric: RIPngMetricNextHop},
+			{Prefix: net.ParseIP("2001:db8:1::"), PrefixLength: 64, Metric: 1},
+			{Prefix: net.ParseIP("2001:db8:2::"), RouteTag: 7, PrefixLength: 48, Metric: 2},
+		},
+	}
+	if !reflect.DeepEqual(ripng, want) {
+		t.Errorf("RIPng mismatch:\ngot  %#v\nwant %#v", ripng, want)
+	}
+	if !ripng.Entries[0].IsNextHop() || ripng.Entries[1].IsNextHop() {
+		t.Error("next hop entry not recognized")
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := ripng.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testPacketRIPngResponse[62:]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), testPacketRIPngResponse[62:])
+	}
+
+	ripng.Entries[1].Prefix = net.IP{10, 0, 0, 0}
+	if err := ripng.SerializeTo(gopacket.NewSerializeBuffer(), gopacket.SerializeOptions{}); err == nil {
+		t.Error("RIPng serialized an IPv4 prefix")
+	}
+	if err := ripng.DecodeFromBytes(testPacketRIPngResponse[62:len(testPacketRIPngResponse)-1], gopacket.NilDecodeFeedback); err == nil {
+		t.Error("RIPng decoded a truncated entry")
+	}
+}
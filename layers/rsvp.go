@@ -0,0 +1,468 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/google/gopacket"
+)
+
+// RSVPMessageType is the type of an RSVP message.
+type RSVPMessageType uint8
+
+// RSVPMessageType known values, see RFC 2205 and RFC 3209
+const (
+	RSVPMessageTypePath     RSVPMessageType = 1
+	RSVPMessageTypeResv     RSVPMessageType = 2
+	RSVPMessageTypePathErr  RSVPMessageType = 3
+	RSVPMessageTypeResvErr  RSVPMessageType = 4
+	RSVPMessageTypePathTear RSVPMessageType = 5
+	RSVPMessageTypeResvTear RSVPMessageType = 6
+	RSVPMessageTypeResvConf RSVPMessageType = 7
+	RSVPMessageTypeHello    RSVPMessageType = 20
+)
+
+func (t RSVPMessageType) String() string {
+	switch t {
+	case RSVPMessageTypePath:
+		return "Path"
+	case RSVPMessageTypeResv:
+		return "Resv"
+	case RSVPMessageTypePathErr:
+		return "PathErr"
+	case RSVPMessageTypeResvErr:
+		return "ResvErr"
+	case RSVPMessageTypePathTear:
+		return "PathTear"
+	case RSVPMessageTypeResvTear:
+		return "ResvTear"
+	case RSVPMessageTypeResvConf:
+		return "ResvConf"
+	case RSVPMessageTypeHello:
+		return "Hello"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(t))
+	}
+}
+
+// RSVPClass is the class number of an RSVP object.
+type RSVPClass uint8
+
+// RSVPClass known values, see RFC 2205 and RFC 3209
+const (
+	RSVPClassSession          RSVPClass = 1
+	RSVPClassHop              RSVPClass = 3
+	RSVPClassIntegrity        RSVPClass = 4
+	RSVPClassTimeValues       RSVPClass = 5
+	RSVPClassErrorSpec        RSVPClass = 6
+	RSVPClassScope            RSVPClass = 7
+	RSVPClassStyle            RSVPClass = 8
+	RSVPClassFlowSpec         RSVPClass = 9
+	RSVPClassFilterSpec       RSVPClass = 10
+	RSVPClassSenderTemplate   RSVPClass = 11
+	RSVPClassSenderTSpec      RSVPClass = 12
+	RSVPClassAdSpec           RSVPClass = 13
+	RSVPClassPolicyData       RSVPClass = 14
+	RSVPClassResvConfirm      RSVPClass = 15
+	RSVPClassLabel            RSVPClass = 16
+	RSVPClassLabelRequest     RSVPClass = 19
+	RSVPClassExplicitRoute    RSVPClass = 20
+	RSVPClassRecordRoute      RSVPClass = 21
+	RSVPClassHello            RSVPClass = 22
+	RSVPClassSessionAttribute RSVPClass = 207
+)
+
+func (c RSVPClass) String() string {
+	switch c {
+	case RSVPClassSession:
+		return "Session"
+	case RSVPClassHop:
+		return "Hop"
+	case RSVPClassIntegrity:
+		return "Integrity"
+	case RSVPClassTimeValues:
+		return "Time Values"
+	case RSVPClassErrorSpec:
+		return "Error Spec"
+	case RSVPClassScope:
+		return "Scope"
+	case RSVPClassStyle:
+		return "Style"
+	case RSVPClassFlowSpec:
+		return "Flow Spec"
+	case RSVPClassFilterSpec:
+		return "Filter Spec"
+	case RSVPClassSenderTemplate:
+		return "Sender Template"
+	case RSVPClassSenderTSpec:
+		return "Sender TSpec"
+	case RSVPClassAdSpec:
+		return "AdSpec"
+	case RSVPClassPolicyData:
+		return "Policy Data"
+	case RSVPClassResvConfirm:
+		return "Resv Confirm"
+	case RSVPClassLabel:
+		return "Label"
+	case RSVPClassLabelRequest:
+		return "Label Request"
+	case RSVPClassExplicitRoute:
+		return "Explicit Route"
+	case RSVPClassRecordRoute:
+		return "Record Route"
+	case RSVPClassHello:
+		return "Hello"
+	case RSVPClassSessionAttribute:
+		return "Session Attribute"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(c))
+	}
+}
+
+// RSVPSubobjectType is the type of an explicit or record route subobject.
+type RSVPSubobjectType uint8
+
+// RSVPSubobjectType known values, see RFC 3209 and RFC 3477
+const (
+	RSVPSubobjectIPv4Prefix RSVPSubobjectType = 1
+	RSVPSubobjectIPv6Prefix RSVPSubobjectType = 2
+	RSVPSubobjectLabel      RSVPSubobjectType = 3 // Record route only
+	RSVPSubobjectUnnumbered RSVPSubobjectType = 4
+	RSVPSubobjectASNumber   RSVPSubobjectType = 32 // Explicit route only
+)
+
+func (t RSVPSubobjectType) String() string {
+	switch t {
+	case RSVPSubobjectIPv4Prefix:
+		return "IPv4 Prefix"
+	case RSVPSubobjectIPv6Prefix:
+		return "IPv6 Prefix"
+	case RSVPSubobjectLabel:
+		return "Label"
+	case RSVPSubobjectUnnumbered:
+		return "Unnumbered Interface"
+	case RSVPSubobjectASNumber:
+		return "AS Number"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(t))
+	}
+}
+
+// RSVP lengths
+const (
+	rsvpHeaderLength       = 8
+	rsvpObjectHeaderLength = 4
+)
+
+// RSVPSession is a session object, either an IPv4 or IPv6 UDP session, or an
+// LSP tunnel session of RSVP-TE.
+type RSVPSession struct {
+	Destination net.IP // The tunnel end point of LSP tunnels
+	// Protocol, Flags and DestinationPort are used by UDP sessions.
+	Protocol        IPProtocol
+	Flags           uint8
+	DestinationPort uint16
+	// TunnelID and ExtendedTunnelID are used by LSP tunnels.
+	TunnelID         uint16
+	ExtendedTunnelID net.IP
+}
+
+// RSVPHop is the previous or next hop object, the address of the
+// interface sending the message.
+type RSVPHop struct {
+	Address                net.IP
+	LogicalInterfaceHandle uint32
+}
+
+// RSVPSenderTemplate is a sender template or filter spec object.
+type RSVPSenderTemplate struct {
+	Source     net.IP
+	SourcePort uint16 // UDP sessions only
+	LSPID      uint16 // LSP tunnels only
+}
+
+// RSVPLabelRequest is a label request object.  The label ranges of ATM and
+// frame relay label requests are left in the object contents.
+type RSVPLabelRequest struct {
+	L3PID EthernetType // Of the traffic carried by the LSP
+}
+
+// RSVPRouteSubobject is a subobject of an explicit or record route object.
+// Prefix holds the address and prefix length of IPv4 and IPv6 prefix
+// subobjects.  Contents holds the subobject after its type and length.
+type RSVPRouteSubobject struct {
+	Loose bool // Explicit route only
+	Type  RSVPSubobjectType
+	// Flags are the flags of record route subobjects, such as whether
+	// local protection is available or in use.
+	Flags       uint8
+	Prefix      net.IPNet
+	Label       uint32 // Record route label subobjects, with a C-Type of 1
+	RouterID    net.IP // Unnumbered interface subobjects
+	InterfaceID uint32 // Unnumbered interface subobjects
+	ASN         uint16
+	Contents    []byte
+}
+
+// RSVPObject is an object of an RSVP message.  The objects of known class
+// and C-Type are also decoded into the corresponding field.
+type RSVPObject struct {
+	Class    RSVPClass
+	CType    uint8
+	Contents []byte
+
+	Session        *RSVPSession
+	Hop            *RSVPHop
+	SenderTemplate *RSVPSenderTemplate // Also filter specs
+	LabelRequest   *RSVPLabelRequest
+	Label          *uint32
+	Route          []RSVPRouteSubobject // Explicit and record routes
+}
+
+// RSVP is a Resource Reservation Protocol message, see RFC 2205, including
+// the objects of RSVP-TE, see RFC 3209.
+type RSVP struct {
+	BaseLayer
+	Version     uint8
+	Flags       uint8
+	MessageType RSVPMessageType
+	Checksum    uint16
+	SendTTL     uint8
+	Length      uint16
+	Objects     []RSVPObject
+}
+
+// LayerType returns LayerTypeRSVP.
+func (r *RSVP) LayerType() gopacket.LayerType { return LayerTypeRSVP }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (r *RSVP) CanDecode() gopacket.LayerClass { return LayerTypeRSVP }
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (r *RSVP) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+
+func decodeRSVP(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&RSVP{}, data, p)
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (r *RSVP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < rsvpHeaderLength {
+		df.SetTruncated()
+		return errors.New("RSVP header too short")
+	}
+	*r = RSVP{
+		Version:     data[0] >> 4,
+		Flags:       data[0] & 0x0f,
+		MessageType: RSVPMessageType(data[1]),
+		Checksum:    binary.BigEndian.Uint16(data[2:4]),
+		SendTTL:     data[4],
+		Length:      binary.BigEndian.Uint16(data[6:8]),
+		Objects:     r.Objects[:0],
+	}
+	if r.Length < rsvpHeaderLength {
+		return fmt.Errorf("invalid RSVP length %d", r.Length)
+	}
+	if len(data) < int(r.Length) {
+		df.SetTruncated()
+		return errors.New("RSVP message too short")
+	}
+	for objs := data[rsvpHeaderLength:r.Length]; len(objs) > 0; {
+		if len(objs) < rsvpObjectHeaderLength {
+			df.SetTruncated()
+			return errors.New("RSVP object header too short")
+		}
+		length := int(binary.BigEndian.Uint16(objs[0:2]))
+		o := RSVPObject{Class: RSVPClass(objs[2]), CType: objs[3]}
+		if length < rsvpObjectHeaderLength || length%4 != 0 || length > len(objs) {
+			return fmt.Errorf("invalid RSVP %v object length %d", o.Class, length)
+		}
+		o.Contents = objs[rsvpObjectHeaderLength:length]
+		if err := o.decode(); err != nil {
+			return err
+		}
+		r.Objects = append(r.Objects, o)
+		objs = objs[length:]
+	}
+	r.BaseLayer = BaseLayer{Contents: data[:r.Length], Payload: data[r.Length:]}
+	return nil
+}
+
+// Object returns the first object of the message of class c, or nil if
+// there is none.
+func (r *RSVP) Object(c RSVPClass) *RSVPObject {
+	for i := range r.Objects {
+		if r.Objects[i].Class == c {
+			return &r.Objects[i]
+		}
+	}
+	return nil
+}
+
+// rsvpAddressLength returns the address length of the usual C-Types of
+// session, hop, sender template and filter spec objects: 1 and 7 for IPv4,
+// 2 and 8 for IPv6.
+func rsvpAddressLength(ctype uint8) int {
+	switch ctype {
+	case 1, 7:
+		return net.IPv4len
+	case 2, 8:
+		return net.IPv6len
+	}
+	return 0
+}
+
+func (o *RSVPObject) decode() error {
+	v := o.Contents
+	n := rsvpAddressLength(o.CType)
+	tooShort := func(length int) error {
+		if len(v) < length {
+			return fmt.Errorf("RSVP %v object of C-Type %d too short", o.Class, o.CType)
+		}
+		return nil
+	}
+	switch {
+	case o.Class == RSVPClassSession && n != 0:
+		if o.CType == 7 || o.CType == 8 {
+			if err := tooShort(2*n + 4); err != nil {
+				return err
+			}
+			o.Session = &RSVPSession{
+				Destination:      net.IP(v[:n]),
+				TunnelID:         binary.BigEndian.Uint16(v[n+2 : n+4]),
+				ExtendedTunnelID: net.IP(v[n+4 : 2*n+4]),
+			}
+			break
+		}
+		if err := tooShort(n + 4); err != nil {
+			return err
+		}
+		o.Session = &RSVPSession{
+			Destination:     net.IP(v[:n]),
+			Protocol:        IPProtocol(v[n]),
+			Flags:           v[n+1],
+			DestinationPort: binary.BigEndian.Uint16(v[n+2 : n+4]),
+		}
+	case o.Class == RSVPClassHop && (o.CType == 1 || o.CType == 2):
+		if err := tooShort(n + 4); err != nil {
+			return err
+		}
+		o.Hop = &RSVPHop{
+			Address:                net.IP(v[:n]),
+			LogicalInterfaceHandle: binary.BigEndian.Uint32(v[n : n+4]),
+		}
+	case (o.Class == RSVPClassSenderTemplate || o.Class == RSVPClassFilterSpec) && n != 0:
+		if err := tooShort(n + 4); err != nil {
+			return err
+		}
+		o.SenderTemplate = &RSVPSenderTemplate{Source: net.IP(v[:n])}
+		if o.CType == 7 || o.CType == 8 {
+			o.SenderTemplate.LSPID = binary.BigEndian.Uint16(v[n+2 : n+4])
+		} else {
+			o.SenderTemplate.SourcePort = binary.BigEndian.Uint16(v[n+2 : n+4])
+		}
+	case o.Class == RSVPClassLabelRequest && o.CType >= 1 && o.CType <= 3:
+		if err := tooShort(4); err != nil {
+			return err
+		}
+		o.LabelRequest = &RSVPLabelRequest{L3PID: EthernetType(binary.BigEndian.Uint16(v[2:4]))}
+	case o.Class == RSVPClassLabel && o.CType == 1:
+		if err := tooShort(4); err != nil {
+			return err
+		}
+		label := binary.BigEndian.Uint32(v[0:4])
+		o.Label = &label
+	case (o.Class == RSVPClassExplicitRoute || o.Class == RSVPClassRecordRoute) && o.CType == 1:
+		route, err := decodeRSVPRoute(v, o.Class == RSVPClassExplicitRoute)
+		if err != nil {
+			return err
+		}
+		o.Route = route
+	}
+	return nil
+}
+
+// rsvpSubobjectLengths are the minimum lengths of the subobjects decoded by
+// decodeRSVPRoute.
+var rsvpSubobjectLengths = map[RSVPSubobjectType]int{
+	RSVPSubobjectIPv4Prefix: 8,
+	RSVPSubobjectIPv6Prefix: 20,
+	RSVPSubobjectLabel:      8,
+	RSVPSubobjectUnnumbered: 12,
+	RSVPSubobjectASNumber:   4,
+}
+
+// decodeRSVPRoute decodes the subobjects of explicit route, if explicit is
+// true, or record route objects.
+func decodeRSVPRoute(data []byte, explicit bool) ([]RSVPRouteSubobject, error) {
+	var route []RSVPRouteSubobject
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, errors.New("RSVP route subobject truncated")
+		}
+		s := RSVPRouteSubobject{Type: RSVPSubobjectType(data[0])}
+		if explicit {
+			s.Loose = data[0]&0x80 != 0
+			s.Type &= 0x7f
+		}
+		length := int(data[1])
+		if length < 2 || length > len(data) {
+			return nil, fmt.Errorf("invalid RSVP %v subobject length %d", s.Type, length)
+		}
+		if n, ok := rsvpSubobjectLengths[s.Type]; ok && length < n {
+			return nil, fmt.Errorf("RSVP %v subobject too short", s.Type)
+		}
+		v := data[2:length]
+		s.Contents = v
+		switch s.Type {
+		case RSVPSubobjectIPv4Prefix, RSVPSubobjectIPv6Prefix:
+			n := net.IPv4len
+			if s.Type == RSVPSubobjectIPv6Prefix {
+				n = net.IPv6len
+			}
+			// Always a full length prefix in record routes
+			bits := int(v[n])
+			if bits > 8*n {
+				return nil, fmt.Errorf("invalid RSVP %v subobject prefix length %d", s.Type, bits)
+			}
+			if !explicit {
+				s.Flags = v[n+1]
+			}
+			s.Prefix = net.IPNet{IP: net.IP(v[:n]), Mask: net.CIDRMask(bits, 8*n)}
+		case RSVPSubobjectLabel:
+			s.Flags = v[0]
+			if v[1] == 1 {
+				s.Label = binary.BigEndian.Uint32(v[2:6])
+			}
+		case RSVPSubobjectUnnumbered:
+			if !explicit {
+				s.Flags = v[0]
+			}
+			s.RouterID = net.IP(v[2:6])
+			s.InterfaceID = binary.BigEndian.Uint32(v[6:10])
+		case RSVPSubobjectASNumber:
+			s.ASN = binary.BigEndian.Uint16(v[0:2])
+		}
+		route = append(route, s)
+		data = data[length:]
+	}
+	return route, nil
+}
+
+// VerifyChecksum verifies the checksum of the RSVP message, implementing
+// gopacket.ChecksumVerifier.  A zero checksum means that none was
+// transmitted.
+func (r *RSVP) VerifyChecksum() (bool, error) {
+	if r.Checksum == 0 {
+		return true, nil
+	}
+	return tcpipChecksumValid(r.Contents, nil, 0), nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketRSVPPath is an RSVP-TE path message of an LSP tunnel, with an
+// explicit route and a record route.
+var testPacketRSVPPath = []byte{
+	0x00, 0x0c, 0x29, 0xbb, 0x00, 0x02, 0x00, 0x0c, 0x29, 0xbb, 0x00, 0x01, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0xbc, 0x00, 0x00, 0x00, 0x00, 0x02, 0x2e, 0xa3, 0x4b, 0x0a, 0x00, 0x00, 0x01, 0x0a, 0x00,
+	0x00, 0x09, 0x10, 0x01, 0x56, 0x41, 0x40, 0x00, 0x00, 0xa8, 0x00, 0x10, 0x01, 0x07, 0x0a, 0x00,
+	0x00, 0x09, 0x00, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x0c, 0x03, 0x01, 0xc0, 0xa8,
+	0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0x05, 0x01, 0x00, 0x00, 0x75, 0x30, 0x00, 0x18,
+	0x14, 0x01, 0x01, 0x08, 0xc0, 0xa8, 0x01, 0x02, 0x20, 0x00, 0x81, 0x08, 0x0a, 0x00, 0x00, 0x09,
+	0x20, 0x00, 0x20, 0x04, 0xfd, 0xe8, 0x00, 0x08, 0x13, 0x01, 0x00, 0x00, 0x08, 0x00, 0x00, 0x0c,
+	0xcf, 0x07, 0x07, 0x07, 0x00, 0x04, 0x6c, 0x73, 0x70, 0x31, 0x00, 0x0c, 0x0b, 0x07, 0x0a, 0x00,
+	0x00, 0x01, 0x00, 0x00, 0x00, 0x03, 0x00, 0x24, 0x0c, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20, 0x15, 0x01, 0x01, 0x08,
+	0x0a, 0x00, 0x00, 0x01, 0x20, 0x01, 0x03, 0x08, 0x01, 0x01, 0x00, 0x00, 0x03, 0xe8, 0x04, 0x0c,
+	0x00, 0x00, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x05,
+}
+
+// testPacketRSVPResv is a fixed filter reservation of an IPv4 UDP session,
+// with a label.
+var testPacketRSVPResv = []byte{
+	0x00, 0x0c, 0x29, 0xbb, 0x00, 0x01, 0x00, 0x0c, 0x29, 0xbb, 0x00, 0x02, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x7c, 0x00, 0x00, 0x00, 0x00, 0x02, 0x2e, 0x34, 0x41, 0xc0, 0xa8, 0x01, 0x02, 0xc0, 0xa8,
+	0x01, 0x01, 0x10, 0x02, 0x61, 0x3a, 0x40, 0x00, 0x00, 0x68, 0x00, 0x0c, 0x01, 0x01, 0x0a, 0x00,
+	0x00, 0x09, 0x11, 0x00, 0x13, 0x88, 0x00, 0x0c, 0x03, 0x01, 0xc0, 0xa8, 0x01, 0x02, 0x00, 0x00,
+	0x00, 0x07, 0x00, 0x08, 0x05, 0x01, 0x00, 0x00, 0x75, 0x30, 0x00, 0x08, 0x08, 0x01, 0x00, 0x00,
+	0x00, 0x0a, 0x00, 0x24, 0x09, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0a, 0x01, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x00,
+	0x17, 0x70, 0x00, 0x08, 0x10, 0x01, 0x00, 0x04, 0x93, 0x00,
+}
+
+func TestRSVPPath(t *testing.T) {
+	p := gopacket.NewPacket(testPacketRSVPPath, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeRSVP}, t)
+	rsvp := p.Layer(LayerTypeRSVP).(*RSVP)
+	if rsvp.Version != 1 || rsvp.MessageType != RSVPMessageTypePath || rsvp.SendTTL != 64 || rsvp.Length != 168 {
+		t.Errorf("unexpected RSVP header: version %d, type %v, send TTL %d, length %d", rsvp.Version, rsvp.MessageType, rsvp.SendTTL, rsvp.Length)
+	}
+	if len(rsvp.Objects) != 9 {
+		t.Fatalf("got %d objects, want 9", len(rsvp.Objects))
+	}
+
+	wantSession := &RSVPSession{
+		Destination:      net.IP{10, 0, 0, 9},
+		TunnelID:         1,
+		ExtendedTunnelID: net.IP{10, 0, 0, 1},
+	}
+	if got := rsvp.Object(RSVPClassSession).Session; !reflect.DeepEqual(got, wantSession) {
+		t.Errorf("session mismatch:\ngot  %#v\nwant %#v", got, wantSession)
+	}
+	wantHop := &RSVPHop{Address: net.IP{192, 168, 1, 1}}
+	if got := rsvp.Object(RSVPClassHop).Hop; !reflect.DeepEqual(got, wantHop) {
+		t.Errorf("hop mismatch:\ngot  %#v\nwant %#v", got, wantHop)
+	}
+	ero := testPacketRSVPPath[34+44:]
+	wantERO := []RSVPRouteSubobject{
+		{
+			Type:     RSVPSubobjectIPv4Prefix,
+			Prefix:   net.IPNet{IP: net.IP{192, 168, 1, 2}, Mask: net.CIDRMask(32, 32)},
+			Contents: ero[6:12],
+		},
+		{
+			Loose:    true,
+			Type:     RSVPSubobjectIPv4Prefix,
+			Prefix:   net.IPNet{IP: net.IP{10, 0, 0, 9}, Mask: net.CIDRMask(32, 32)},
+			Contents: ero[14:20],
+		},
+		{
+			Type:     RSVPSubobjectASNumber,
+			ASN:      65000,
+			Contents: ero[22:24],
+		},
+	}
+	if got := rsvp.Object(RSVPClassExplicitRoute).Route; !reflect.DeepEqual(got, wantERO) {
+		t.Errorf("explicit route mismatch:\ngot  %#v\nwant %#v", got, wantERO)
+	}
+	wantLabelRequest := &RSVPLabelRequest{L3PID: EthernetTypeIPv4}
+	if got := rsvp.Object(RSVPClassLabelRequest).LabelRequest; !reflect.DeepEqual(got, wantLabelRequest) {
+		t.Errorf("label request mismatch:\ngot  %#v\nwant %#v", got, wantLabelRequest)
+	}
+	wantSender := &RSVPSenderTemplate{Source: net.IP{10, 0, 0, 1}, LSPID: 3}
+	if got := rsvp.Object(RSVPClassSenderTemplate).SenderTemplate; !reflect.DeepEqual(got, wantSender) {
+		t.Errorf("sender template mismatch:\ngot  %#v\nwant %#v", got, wantSender)
+	}
+	if o := rsvp.Object(RSVPClassSessionAttribute); o == nil || string(o.Contents[4:]) != "lsp1" {
+		t.Errorf("unexpected session attribute %#v", o)
+	}
+	rro := testPacketRSVPPath[34+136:]
+	wantRRO := []RSVPRouteSubobject{
+		{
+			Type:     RSVPSubobjectIPv4Prefix,
+			Flags:    1,
+			Prefix:   net.IPNet{IP: net.IP{10, 0, 0, 1}, Mask: net.CIDRMask(32, 32)},
+			Contents: rro[6:12],
+		},
+		{
+			Type:     RSVPSubobjectLabel,
+			Flags:    1,
+			Label:    1000,
+			Contents: rro[14:20],
+		},
+		{
+			Type:        RSVPSubobjectUnnumbered,
+			RouterID:    net.IP{10, 0, 0, 1},
+			InterfaceID: 5,
+			Contents:    rro[22:32],
+		},
+	}
+	if got := rsvp.Object(RSVPClassRecordRoute).Route; !reflect.DeepEqual(got, wantRRO) {
+		t.Errorf("record route mismatch:\ngot  %#v\nwant %#v", got, wantRRO)
+	}
+	if rsvp.Object(RSVPClassLabel) != nil {
+		t.Error("found a label object in a path message")
+	}
+
+	if ok, err := rsvp.VerifyChecksum(); !ok || err != nil {
+		t.Errorf("checksum verification failed: %v, %v", ok, err)
+	}
+	data := append([]byte{}, testPacketRSVPPath[34:]...)
+	data[len(data)-1] ^= 0x01
+	var corrupted RSVP
+	if err := corrupted.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := corrupted.VerifyChecksum(); ok {
+		t.Error("corrupted RSVP message checksum verified")
+	}
+}
+
+func TestRSVPResv(t *testing.T) {
+	p := gopacket.NewPacket(testPacketRSVPResv, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeRSVP}, t)
+	rsvp := p.Layer(LayerTypeRSVP).(*RSVP)
+	if rsvp.MessageType != RSVPMessageTypeResv {
+		t.Errorf("got message type %v, want Resv", rsvp.MessageType)
+	}
+	wantSession := &RSVPSession{
+		Destination:     net.IP{10, 0, 0, 9},
+		Protocol:        IPProtocolUDP,
+		DestinationPort: 5000,
+	}
+	if got := rsvp.Object(RSVPClassSession).Session; !reflect.DeepEqual(got, wantSession) {
+		t.Errorf("session mismatch:\ngot  %#v\nwant %#v", got, wantSession)
+	}
+	wantFilter := &RSVPSenderTemplate{Source: net.IP{10, 0, 0, 1}, SourcePort: 6000}
+	if got := rsvp.Object(RSVPClassFilterSpec).SenderTemplate; !reflect.DeepEqual(got, wantFilter) {
+		t.Errorf("filter spec mismatch:\ngot  %#v\nwant %#v", got, wantFilter)
+	}
+	if label := rsvp.Object(RSVPClassLabel).Label; label == nil || *label != 299776 {
+		t.Errorf("unexpected label %v", label)
+	}
+	if hop := rsvp.Object(RSVPClassHop).Hop; hop.LogicalInterfaceHandle != 7 {
+		t.Errorf("got logical interface handle %d, want 7", hop.LogicalInterfaceHandle)
+	}
+}
+
+func TestRSVPDecodeInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		mutate func([]byte) []byte
+	}{
+		{"truncated header", func(b []byte) []byte { return b[:7] }},
+		{"truncated message", func(b []byte) []byte { return b[:len(b)-1] }},
+		{"length too short", func(b []byte) []byte { b[6], b[7] = 0, 4; return b }},
+		{"object length not a multiple of 4", func(b []byte) []byte { b[9] = 15; return b }},
+		{"object too long", func(b []byte) []byte { b[8], b[9] = 1, 0; return b }},
+		{"session too short", func(b []byte) []byte { b[9] = 12; b[20], b[21], b[22], b[23] = 0, 4, 0, 0; return b }},
+		{"subobject length too long", func(b []byte) []byte { b[49] = 30; return b }},
+		{"subobject too short", func(b []byte) []byte { b[49] = 4; b[52], b[53] = 0, 4; return b }},
+		{"invalid prefix length", func(b []byte) []byte { b[54] = 33; return b }},
+	} {
+		data := test.mutate(append([]byte{}, testPacketRSVPPath[34:]...))
+		var rsvp RSVP
+		if err := rsvp.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", test.name)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/gopacket"
+)
+
+// RTPPayloadType is the payload type of an RTP packet, which identifies the
+// format of its payload.
+type RTPPayloadType uint8
+
+// RTPPayloadType static values, see RFC 3551, section 6.  Payload types 96
+// to 127 are dynamic, and bound to formats out of band, e.g. by SDP.
+const (
+	RTPPCMU       RTPPayloadType = 0
+	RTPGSM        RTPPayloadType = 3
+	RTPG723       RTPPayloadType = 4
+	RTPDVI4_8000  RTPPayloadType = 5
+	RTPDVI4_16000 RTPPayloadType = 6
+	RTPLPC        RTPPayloadType = 7
+	RTPPCMA       RTPPayloadType = 8
+	RTPG722       RTPPayloadType = 9
+	RTPL16Stereo  RTPPayloadType = 10
+	RTPL16Mono    RTPPayloadType = 11
+	RTPQCELP      RTPPayloadType = 12
+	RTPCN         RTPPayloadType = 13
+	RTPMPA        RTPPayloadType = 14
+	RTPG728       RTPPayloadType = 15
+	RTPDVI4_11025 RTPPayloadType = 16
+	RTPDVI4_22050 RTPPayloadType = 17
+	RTPG729       RTPPayloadType = 18
+	RTPCelB       RTPPayloadType = 25
+	RTPJPEG       RTPPayloadType = 26
+	RTPNV         RTPPayloadType = 28
+	RTPH261       RTPPayloadType = 31
+	RTPMPV        RTPPayloadType = 32
+	RTPMP2T       RTPPayloadType = 33
+	RTPH263       RTPPayloadType = 34
+
+	RTPDynamicMin RTPPayloadType = 96
+	RTPDynamicMax RTPPayloadType = 127
+)
+
+// IsDynamic returns whether t is in the dynamic range, 96 to 127.
+func (t RTPPayloadType) IsDynamic() bool {
+	return t >= RTPDynamicMin && t <= RTPDynamicMax
+}
+
+func (t RTPPayloadType) String() string {
+	if f, ok := rtpStaticPayloadFormats[t]; ok {
+		return f.EncodingName
+	}
+	if t.IsDynamic() {
+		return fmt.Sprintf("Dynamic(%d)", uint8(t))
+	}
+	return fmt.Sprintf("Unknown(%d)", uint8(t))
+}
+
+// RTPPayloadFormat is the format of the payload of RTP packets, as described
+// by an SDP rtpmap attribute.
+type RTPPayloadFormat struct {
+	EncodingName string
+	ClockRate    uint32 // Hz
+	// EncodingParameters is the number of channels of audio formats, and
+	// empty if there is only one.
+	EncodingParameters string
+}
+
+func (f RTPPayloadFormat) String() string {
+	s := f.EncodingName + "/" + strconv.FormatUint(uint64(f.ClockRate), 10)
+	if f.EncodingParameters != "" {
+		s += "/" + f.EncodingParameters
+	}
+	return s
+}
+
+var rtpStaticPayloadFormats = map[RTPPayloadType]RTPPayloadFormat{
+	RTPPCMU:       {"PCMU", 8000, ""},
+	RTPGSM:        {"GSM", 8000, ""},
+	RTPG723:       {"G723", 8000, ""},
+	RTPDVI4_8000:  {"DVI4", 8000, ""},
+	RTPDVI4_16000: {"DVI4", 16000, ""},
+	RTPLPC:        {"LPC", 8000, ""},
+	RTPPCMA:       {"PCMA", 8000, ""},
+	RTPG722:       {"G722", 8000, ""},
+	RTPL16Stereo:  {"L16", 44100, "2"},
+	RTPL16Mono:    {"L16", 44100, ""},
+	RTPQCELP:      {"QCELP", 8000, ""},
+	RTPCN:         {"CN", 8000, ""},
+	RTPMPA:        {"MPA", 90000, ""},
+	RTPG728:       {"G728", 8000, ""},
+	RTPDVI4_11025: {"DVI4", 11025, ""},
+	RTPDVI4_22050: {"DVI4", 22050, ""},
+	RTPG729:       {"G729", 8000, ""},
+	RTPCelB:       {"CelB", 90000, ""},
+	RTPJPEG:       {"JPEG", 90000, ""},
+	RTPNV:         {"nv", 90000, ""},
+	RTPH261:       {"H261", 90000, ""},
+	RTPMPV:        {"MPV", 90000, ""},
+	RTPMP2T:       {"MP2T", 90000, ""},
+	RTPH263:       {"H263", 90000, ""},
+}
+
+// RTPPayloadTypeMap binds dynamic payload types to their formats.  A nil
+// RTPPayloadTypeMap only knows the static payload types.
+type RTPPayloadTypeMap map[RTPPayloadType]RTPPayloadFormat
+
+// BindSDP binds the payload types of the rtpmap attributes of the SDP
+// session description sdp, as found in the bodies of SIP messages (see RFC
+// 8866, section 6.6).  The formats of different media descriptions are bound
+// in the same map, so a map should be used for the RTP sessions of one
+// media description if their payload types conflict.
+func (m RTPPayloadTypeMap) BindSDP(sdp []byte) error {
+	for _, line := range bytes.Split(sdp, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if !bytes.HasPrefix(line, []byte("a=rtpmap:")) {
+			continue
+		}
+		attr := string(line[len("a=rtpmap:"):])
+		sp := strings.IndexByte(attr, ' ')
+		if sp < 0 {
+			return fmt.Errorf("invalid SDP rtpmap attribute %q", attr)
+		}
+		pt, err := strconv.ParseUint(attr[:sp], 10, 7)
+		if err != nil {
+			return fmt.Errorf("invalid SDP rtpmap payload type %q", attr[:sp])
+		}
+		parts := strings.SplitN(strings.TrimSpace(attr[sp+1:]), "/", 3)
+		if len(parts) < 2 || parts[0] == "" {
+			return fmt.Errorf("invalid SDP rtpmap encoding %q", attr[sp+1:])
+		}
+		rate, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid SDP rtpmap clock rate %q", parts[1])
+		}
+		f := RTPPayloadFormat{EncodingName: parts[0], ClockRate: uint32(rate)}
+		if len(parts) == 3 {
+			f.EncodingParameters = parts[2]
+		}
+		m[RTPPayloadType(pt)] = f
+	}
+	return nil
+}
+
+// Format returns the format of payload type t, either bound in m or static.
+func (m RTPPayloadTypeMap) Format(t RTPPayloadType) (RTPPayloadFormat, bool) {
+	if f, ok := m[t]; ok {
+		return f, true
+	}
+	f, ok := rtpStaticPayloadFormats[t]
+	return f, ok
+}
+
+// RTPHeaderExtension is an element of the header extension of an RTP
+// packet, in the one-byte or two-byte format of RFC 8285.
+type RTPHeaderExtension struct {
+	ID   uint8
+	Data []byte
+}
+
+// RTP header extension profiles of RFC 8285.  The low 4 bits of the
+// two-byte profile are application specific.
+const (
+	RTPExtensionOneByte uint16 = 0xbede
+	RTPExtensionTwoByte uint16 = 0x1000
+)
+
+const rtpHeaderLength = 12
+
+// RTP is an RTP packet, see RFC 3550.
+//
+// RTP has no well-known ports; the ports of RTP sessions are usually
+// negotiated by SIP and SDP.  The layer is decoded for the ports mapped to
+// LayerTypeRTP, by DetectRTP if registered, or by a DecodingLayerParser.
+type RTP struct {
+	BaseLayer
+	Version        uint8
+	Padding        bool
+	Extension      bool
+	Marker         bool
+	PayloadType    RTPPayloadType
+	SequenceNumber uint16
+	Timestamp      uint32
+	SSRC           uint32
+	CSRCs          []uint32
+
+	// ExtensionProfile and ExtensionData are the header extension, if
+	// Extension is set.  The elements of RFC 8285 header extensions are
+	// decoded in Extensions.
+	ExtensionProfile uint16
+	ExtensionData    []byte
+	Extensions       []RTPHeaderExtension
+
+	// PaddingLength is the length of the padding following the payload,
+	// including its last byte, if Padding is set.
+	PaddingLength uint8
+}
+
+// LayerType returns LayerTypeRTP.
+func (r *RTP) LayerType() gopacket.LayerType { return LayerTypeRTP }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (r *RTP) CanDecode() gopacket.LayerClass { return LayerTypeRTP }
+
+// NextLayerType returns gopacket.LayerTypeZero, since the format of the
+// payload depends on PayloadType.
+func (r *RTP) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+
+// Payload returns the media of the packet, without padding.
+func (r *RTP) Payload() []byte { return r.BaseLayer.Payload }
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (r *RTP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < rtpHeaderLength {
+		df.SetTruncated()
+		return errors.New("RTP header too short")
+	}
+	*r = RTP{
+		Version:        data[0] >> 6,
+		Padding:        data[0]&0x20 != 0,
+		Extension:      data[0]&0x10 != 0,
+		Marker:         data[1]&0x80 != 0,
+		PayloadType:    RTPPayloadType(data[1] & 0x7f),
+		SequenceNumber: binary.BigEndian.Uint16(data[2:4]),
+		Timestamp:      binary.BigEndian.Uint32(data[4:8]),
+		SSRC:           binary.BigEndian.Uint32(data[8:12]),
+		CSRCs:          r.CSRCs[:0],
+		Extensions:     r.Extensions[:0],
+	}
+	if r.Version != 2 {
+		return fmt.Errorf("unsupported RTP version %d", r.Version)
+	}
+	// RTCP packet types 200 to 204 multiplexed with RTP, see RFC 5761
+	if data[1] >= 200 && data[1] <= 204 {
+		return fmt.Errorf("RTCP packet type %d", data[1])
+	}
+
+	offset := rtpHeaderLength
+	count := int(data[0] & 0x0f)
+	if len(data) < offset+4*count {
+		df.SetTruncated()
+		return errors.New("RTP CSRC list truncated")
+	}
+	for i := 0; i < count; i++ {
+		r.CSRCs = append(r.CSRCs, binary.BigEndian.Uint32(data[offset:]))
+		offset += 4
+	}
+
+	if r.Extension {
+		if len(data) < offset+4 {
+			df.SetTruncated()
+			return errors.New("RTP header extension truncated")
+		}
+		r.ExtensionProfile = binary.BigEndian.Uint16(data[offset:])
+		length := 4 * int(binary.BigEndian.Uint16(data[offset+2:]))
+		offset += 4
+		if len(data) < offset+length {
+			df.SetTruncated()
+			return fmt.Errorf("RTP header extension length %d too long", length)
+		}
+		r.ExtensionData = data[offset : offset+length]
+		offset += length
+		if err := r.decodeExtensions(); err != nil {
+			return err
+		}
+	}
+
+	end := len(data)
+	if r.Padding {
+		r.PaddingLength = data[end-1]
+		if r.PaddingLength == 0 || int(r.PaddingLength) > end-offset {
+			return fmt.Errorf("invalid RTP padding length %d", r.PaddingLength)
+		}
+		end -= int(r.PaddingLength)
+	}
+	r.Contents, r.BaseLayer.Payload = data[:offset], data[offset:end]
+	return nil
+}
+
+// decodeExtensions decodes the elements of RFC 8285 header extensions.
+func (r *RTP) decodeExtensions() error {
+	var twoByte bool
+	switch {
+	case r.ExtensionProfile == RTPExtensionOneByte:
+	case r.ExtensionProfile&0xfff0 == RTPExtensionTwoByte:
+		twoByte = true
+	default:
+		return nil
+	}
+	data := r.ExtensionData
+	for len(data) > 0 {
+		var e RTPHeaderExtension
+		var length int
+		if twoByte {
+			if data[0] == 0 {
+				data = data[1:]
+				continue
+			}
+			if len(data) < 2 {
+				return errors.New("RTP header extension element truncated")
+			}
+			e.ID, length, data = data[0], int(data[1]), data[2:]
+		} else {
+			e.ID, length = data[0]>>4, int(data[0]&0x0f)+1
+			if e.ID == 0 {
+				data = data[1:]
+				continue
+			}
+			if e.ID == 15 {
+				// Reserved, the rest of the extension is ignored
+				break
+			}
+			data = data[1:]
+		}
+		if len(data) < length {
+			return fmt.Errorf("RTP header extension element %d length %d too long", e.ID, length)
+		}
+		e.Data, data = data[:length], data[length:]
+		r.Extensions = append(r.Extensions, e)
+	}
+	return nil
+}
+
+func decodeRTP(data []byte, p gopacket.PacketBuilder) error {
+	r := &RTP{}
+	if err := r.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(r)
+	p.SetApplicationLayer(r)
+	return nil
+}
+
+// DetectRTP recognizes RTP packets on any UDP port by their version and
+// payload type.  RTP headers have few fixed bits, so other protocols are
+// recognized too; it should be registered after the hooks of protocols with
+// stronger signatures:
+//
+//	gopacket.RegisterUnknownProtocolHook(gopacket.UnknownUDPPort, layers.DetectRTP)
+func DetectRTP(u *gopacket.UnknownProtocol) gopacket.Decoder {
+	var r RTP
+	if r.DecodeFromBytes(u.Data, gopacket.NilDecodeFeedback) != nil {
+		return nil
+	}
+	if _, ok := rtpStaticPayloadFormats[r.PayloadType]; !ok && !r.PayloadType.IsDynamic() {
+		return nil
+	}
+	return LayerTypeRTP
+}
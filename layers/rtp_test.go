@@ -0,0 +1,185 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketRTPOpus is an RTP packet of dynamic payload type 111 with two
+// CSRCs, one-byte header extension elements and padding.
+var testPacketRTPOpus = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x58, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0xa0, 0xd0, 0x0a, 0x01, 0x01, 0x01, 0x0a, 0x02,
+	0x02, 0x02, 0x40, 0x00, 0x40, 0x02, 0x00, 0x44, 0x2e, 0xf0, 0xb2, 0xef, 0x03, 0xe8, 0x00, 0x02,
+	0x71, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xbe, 0xde,
+	0x00, 0x03, 0x10, 0x85, 0x00, 0x32, 0xaa, 0xbb, 0xcc, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x02,
+	0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12,
+	0x13, 0x14, 0x00, 0x00, 0x00, 0x04,
+}
+
+// testPacketRTPPCMU is an RTP packet of PCMU audio.
+var testPacketRTPPCMU = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x3c, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0xa0, 0xec, 0x0a, 0x01, 0x01, 0x01, 0x0a, 0x02,
+	0x02, 0x02, 0x4e, 0x20, 0x4e, 0x22, 0x00, 0x28, 0x0f, 0x71, 0x80, 0x00, 0x00, 0x07, 0x00, 0x00,
+	0x1f, 0x40, 0xde, 0xad, 0xbe, 0xef, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+}
+
+func TestRTP(t *testing.T) {
+	profile := gopacket.NewDecodeProfile("rtp").Port(EndpointUDPPort, 16386, LayerTypeRTP)
+	p := gopacket.NewPacket(testPacketRTPOpus, LinkTypeEthernet, gopacket.DecodeOptions{Profile: profile})
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeRTP}, t)
+	r := p.ApplicationLayer().(*RTP)
+	want := &RTP{
+		BaseLayer:        BaseLayer{Contents: testPacketRTPOpus[42:78], Payload: testPacketRTPOpus[78:98]},
+		Version:          2,
+		Padding:          true,
+		Extension:        true,
+		Marker:           true,
+		PayloadType:      111,
+		SequenceNumber:   1000,
+		Timestamp:        160000,
+		SSRC:             0x11223344,
+		CSRCs:            []uint32{0x55667788, 0x99aabbcc},
+		ExtensionProfile: RTPExtensionOneByte,
+		ExtensionData:    testPacketRTPOpus[66:78],
+		Extensions: []RTPHeaderExtension{
+			{ID: 1, Data: []byte{0x85}},
+			{ID: 3, Data: []byte{0xaa, 0xbb, 0xcc}},
+		},
+		PaddingLength: 4,
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("RTP mismatch:\ngot  %#v\nwant %#v", r, want)
+	}
+
+	p = gopacket.NewPacket(testPacketRTPPCMU, LinkTypeEthernet, testDecodeOptions)
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, gopacket.LayerTypePayload}, t)
+	var pcmu RTP
+	if err := pcmu.DecodeFromBytes(testPacketRTPPCMU[42:], gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if pcmu.PayloadType != RTPPCMU || pcmu.SSRC != 0xdeadbeef || pcmu.Marker || len(pcmu.CSRCs) != 0 || len(pcmu.Payload()) != 20 {
+		t.Errorf("got PCMU packet %#v", pcmu)
+	}
+}
+
+func TestRTPTwoByteExtension(t *testing.T) {
+	data := []byte{
+		0x90, 0x60, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03,
+		0x10, 0x00, 0x00, 0x02, 0x05, 0x02, 0xab, 0xcd, 0x00, 0x07, 0x00, 0x00,
+		0x01,
+	}
+	var r RTP
+	if err := r.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	want := []RTPHeaderExtension{{ID: 5, Data: []byte{0xab, 0xcd}}, {ID: 7, Data: []byte{}}}
+	if !reflect.DeepEqual(r.Extensions, want) {
+		t.Errorf("got extensions %#v, want %#v", r.Extensions, want)
+	}
+	if r.PayloadType.String() != "Dynamic(96)" || len(r.Payload()) != 1 {
+		t.Errorf("got payload type %v, payload %x", r.PayloadType, r.Payload())
+	}
+}
+
+func TestRTPDecodeInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		data   []byte
+		mutate func([]byte)
+	}{
+		{"truncated header", testPacketRTPPCMU[42:53], nil},
+		{"version 1", testPacketRTPPCMU[42:], func(b []byte) { b[0] = 0x40 }},
+		{"rtcp", testPacketRTPPCMU[42:], func(b []byte) { b[1] = 200 }},
+		{"truncated CSRCs", testPacketRTPPCMU[42:], func(b []byte) { b[0] = 0x8f }},
+		{"truncated extension", testPacketRTPOpus[42:70], nil},
+		{"extension length too long", testPacketRTPOpus[42:], func(b []byte) { b[23] = 0x40 }},
+		{"extension element too long", testPacketRTPOpus[42:], func(b []byte) { b[27] = 0x3f }},
+		{"zero padding length", testPacketRTPOpus[42:], func(b []byte) { b[len(b)-1] = 0 }},
+		{"padding too long", testPacketRTPOpus[42:], func(b []byte) { b[len(b)-1] = 0xff }},
+	} {
+		data := append([]byte{}, test.data...)
+		if test.mutate != nil {
+			test.mutate(data)
+		}
+		var r RTP
+		if err := r.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", test.name)
+		}
+		if DetectRTP(&gopacket.UnknownProtocol{Data: data}) != nil {
+			t.Errorf("%s: detected as RTP", test.name)
+		}
+	}
+	for _, data := range [][]byte{testPacketRTPOpus[42:], testPacketRTPPCMU[42:]} {
+		if DetectRTP(&gopacket.UnknownProtocol{Data: data}) != LayerTypeRTP {
+			t.Errorf("%x: RTP not detected", data)
+		}
+	}
+	reserved := append([]byte{}, testPacketRTPPCMU[42:]...)
+	reserved[1] = 50
+	if DetectRTP(&gopacket.UnknownProtocol{Data: reserved}) != nil {
+		t.Error("reserved payload type detected as RTP")
+	}
+}
+
+func TestRTPPayloadTypeMap(t *testing.T) {
+	sdp := []byte("v=0\r\n" +
+		"o=- 20518 0 IN IP4 10.1.1.1\r\n" +
+		"s=-\r\n" +
+		"c=IN IP4 10.1.1.1\r\n" +
+		"t=0 0\r\n" +
+		"m=audio 16384 RTP/AVP 111 0 101\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n" +
+		"a=fmtp:111 minptime=10;useinbandfec=1\r\n" +
+		"a=rtpmap:0 PCMU/8000\r\n" +
+		"a=rtpmap:101 telephone-event/8000\r\n")
+	m := RTPPayloadTypeMap{}
+	if err := m.BindSDP(sdp); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		pt   RTPPayloadType
+		want string
+	}{
+		{111, "opus/48000/2"},
+		{101, "telephone-event/8000"},
+		{RTPPCMU, "PCMU/8000"},
+		{RTPG722, "G722/8000"},
+		{RTPL16Stereo, "L16/44100/2"},
+	} {
+		if f, ok := m.Format(test.pt); !ok || f.String() != test.want {
+			t.Errorf("payload type %d: got %v, %v, want %s", test.pt, f, ok, test.want)
+		}
+	}
+	if f, ok := m.Format(100); ok {
+		t.Errorf("unbound payload type 100 has format %v", f)
+	}
+	if f, ok := RTPPayloadTypeMap(nil).Format(RTPPCMA); !ok || f.EncodingName != "PCMA" {
+		t.Errorf("got PCMA format %v, %v", f, ok)
+	}
+
+	for _, sdp := range []string{
+		"a=rtpmap:111\r\n",
+		"a=rtpmap:128 opus/48000\r\n",
+		"a=rtpmap:x opus/48000\r\n",
+		"a=rtpmap:111 opus\r\n",
+		"a=rtpmap:111 opus/fast\r\n",
+	} {
+		if err := (RTPPayloadTypeMap{}).BindSDP([]byte(sdp)); err == nil {
+			t.Errorf("%q: bound successfully", sdp)
+		}
+	}
+}
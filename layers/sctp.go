@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"hash/crc32"
+	"net"
 
 	"github.com/google/gopacket"
 )
@@ -60,14 +61,32 @@ func (s SCTP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOpt
 	binary.BigEndian.PutUint16(bytes[2:4], uint16(s.DstPort))
 	binary.BigEndian.PutUint32(bytes[4:8], s.VerificationTag)
 	if opts.ComputeChecksums {
+		// The checksum is computed with the checksum field set to zero.
+		binary.LittleEndian.PutUint32(bytes[8:12], 0)
 		// Note:  MakeTable(Castagnoli) actually only creates the table once, then
 		// passes back a singleton on every other call, so this shouldn't cause
 		// excessive memory allocation.
 		binary.LittleEndian.PutUint32(bytes[8:12], crc32.Checksum(b.Bytes(), crc32.MakeTable(crc32.Castagnoli)))
+	} else {
+		binary.BigEndian.PutUint32(bytes[8:12], s.Checksum)
 	}
 	return nil
 }
 
+// VerifyChecksum verifies the CRC32c checksum of the SCTP packet,
+// implementing gopacket.ChecksumVerifier.
+func (s *SCTP) VerifyChecksum() (bool, error) {
+	if len(s.Contents) < 12 {
+		return false, errors.New("Invalid SCTP common header length")
+	}
+	table := crc32.MakeTable(crc32.Castagnoli)
+	crc := crc32.Update(0, table, s.Contents[:8])
+	crc = crc32.Update(crc, table, []byte{0, 0, 0, 0})
+	crc = crc32.Update(crc, table, s.Contents[12:])
+	crc = crc32.Update(crc, table, s.Payload)
+	return binary.LittleEndian.Uint32(s.Contents[8:12]) == crc, nil
+}
+
 func (sctp *SCTP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
 	if len(data) < 12 {
 		return errors.New("Invalid SCTP common header length")
@@ -112,13 +131,38 @@ func roundUpToNearest4(i int) int {
 	return i + 4 - (i % 4)
 }
 
+// sctpChunkMinLength returns the length of the fixed fields of chunks of
+// type ct.
+func sctpChunkMinLength(ct SCTPChunkType) int {
+	switch ct {
+	case SCTPChunkTypeData, SCTPChunkTypeSack:
+		return 16
+	case SCTPChunkTypeInit, SCTPChunkTypeInitAck:
+		return 20
+	case SCTPChunkTypeShutdown, SCTPChunkTypeECNE, SCTPChunkTypeCWR, SCTPChunkTypeAuth,
+		SCTPChunkTypeForwardTSN, SCTPChunkTypeASCONF, SCTPChunkTypeASCONFAck:
+		return 8
+	}
+	return 4
+}
+
 func decodeSCTPChunk(data []byte) (SCTPChunk, error) {
+	if len(data) < 4 {
+		return SCTPChunk{}, errors.New("SCTP chunk too short")
+	}
 	length := binary.BigEndian.Uint16(data[2:4])
-	if length < 4 {
+	ct := SCTPChunkType(data[0])
+	if int(length) < sctpChunkMinLength(ct) {
 		return SCTPChunk{}, errors.New("invalid SCTP chunk length")
 	}
+	if int(length) > len(data) {
+		return SCTPChunk{}, errors.New("SCTP chunk length exceeds packet length")
+	}
 	actual := roundUpToNearest4(int(length))
-	ct := SCTPChunkType(data[0])
+	if actual > len(data) {
+		// the padding of the last chunk may be missing
+		actual = len(data)
+	}
 
 	// For SCTP Data, use a separate layer for the payload
 	delta := 0
@@ -144,14 +188,79 @@ type SCTPParameter struct {
 	Value        []byte
 }
 
-func decodeSCTPParameter(data []byte) SCTPParameter {
+// SCTP parameter types, used by SCTPParameter.Type, from
+// http://www.iana.org/assignments/sctp-parameters/sctp-parameters.xhtml
+const (
+	SCTPParameterHeartbeatInfo             uint16 = 1
+	SCTPParameterIPv4Address               uint16 = 5
+	SCTPParameterIPv6Address               uint16 = 6
+	SCTPParameterStateCookie               uint16 = 7
+	SCTPParameterUnrecognizedParameter     uint16 = 8
+	SCTPParameterCookiePreservative        uint16 = 9
+	SCTPParameterHostName                  uint16 = 11
+	SCTPParameterSupportedAddressTypes     uint16 = 12
+	SCTPParameterOutgoingSSNResetRequest   uint16 = 13
+	SCTPParameterIncomingSSNResetRequest   uint16 = 14
+	SCTPParameterSSNTSNResetRequest        uint16 = 15
+	SCTPParameterReconfigurationResponse   uint16 = 16
+	SCTPParameterAddOutgoingStreamsRequest uint16 = 17
+	SCTPParameterAddIncomingStreamsRequest uint16 = 18
+	SCTPParameterECNCapable                uint16 = 0x8000
+	SCTPParameterRandom                    uint16 = 0x8002
+	SCTPParameterChunkList                 uint16 = 0x8003
+	SCTPParameterRequestedHMACAlgorithm    uint16 = 0x8004
+	SCTPParameterPadding                   uint16 = 0x8005
+	SCTPParameterSupportedExtensions       uint16 = 0x8008
+	SCTPParameterForwardTSNSupported       uint16 = 0xc000
+	SCTPParameterAddIPAddress              uint16 = 0xc001
+	SCTPParameterDeleteIPAddress           uint16 = 0xc002
+	SCTPParameterErrorCauseIndication      uint16 = 0xc003
+	SCTPParameterSetPrimaryAddress         uint16 = 0xc004
+	SCTPParameterSuccessIndication         uint16 = 0xc005
+	SCTPParameterAdaptationLayerIndication uint16 = 0xc006
+)
+
+func decodeSCTPParameter(data []byte) (SCTPParameter, error) {
+	if len(data) < 4 {
+		return SCTPParameter{}, errors.New("SCTP parameter too short")
+	}
 	length := binary.BigEndian.Uint16(data[2:4])
+	if length < 4 || int(length) > len(data) {
+		return SCTPParameter{}, errors.New("invalid SCTP parameter length")
+	}
+	actual := roundUpToNearest4(int(length))
+	if actual > len(data) {
+		// the padding of the last parameter may be missing
+		actual = len(data)
+	}
 	return SCTPParameter{
 		Type:         binary.BigEndian.Uint16(data[0:2]),
 		Length:       length,
 		Value:        data[4:length],
-		ActualLength: roundUpToNearest4(int(length)),
+		ActualLength: actual,
+	}, nil
+}
+
+// decodeSCTPParameters decodes a list of parameters.
+func decodeSCTPParameters(data []byte) ([]SCTPParameter, error) {
+	var params []SCTPParameter
+	for len(data) > 0 {
+		p, err := decodeSCTPParameter(data)
+		if err != nil {
+			return nil, err
+		}
+		data = data[p.ActualLength:]
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// IP returns the address of IPv4 and IPv6 address parameters, or nil.
+func (p SCTPParameter) IP() net.IP {
+	if (p.Type == SCTPParameterIPv4Address && len(p.Value) == 4) || (p.Type == SCTPParameterIPv6Address && len(p.Value) == 16) {
+		return net.IP(p.Value)
 	}
+	return nil
 }
 
 func (p SCTPParameter) Bytes() []byte {
@@ -163,6 +272,14 @@ func (p SCTPParameter) Bytes() []byte {
 	return data
 }
 
+// zeroSCTPPadding zeroes the padding of a chunk serialized into bytes, which
+// may be dirty.
+func zeroSCTPPadding(bytes []byte, length int) {
+	for i := length; i < len(bytes); i++ {
+		bytes[i] = 0
+	}
+}
+
 // SCTPUnknownChunkType is the layer type returned when we don't recognize the
 // chunk type.  Since there's a length in a known location, we can skip over
 // it even if we don't know what it is, and continue parsing the rest of the
@@ -243,6 +360,7 @@ const (
 	SCTPPayloadDDPSegment                     = 16
 	SCTPPayloadDDPStream                      = 17
 	SCTPPayloadS1AP                           = 18
+	SCTPPayloadDiameter                       = 46
 )
 
 func (p SCTPPayloadProtocol) String() string {
@@ -285,6 +403,8 @@ func (p SCTPPayloadProtocol) String() string {
 		return "DDPStream"
 	case SCTPPayloadS1AP:
 		return "S1AP"
+	case SCTPPayloadDiameter:
+		return "Diameter"
 	}
 	return fmt.Sprintf("Unknown(%d)", p)
 }
@@ -306,6 +426,9 @@ func decodeSCTPData(data []byte, p gopacket.PacketBuilder) error {
 	}
 	// Length is the length in bytes of the data, INCLUDING the 16-byte header.
 	p.AddLayer(sc)
+	if sc.PayloadProtocol == SCTPPayloadDiameter && sc.BeginFragment && sc.EndFragment {
+		return p.NextDecoder(LayerTypeDiameter)
+	}
 	return p.NextDecoder(gopacket.LayerTypePayload)
 }
 
@@ -377,11 +500,12 @@ func decodeSCTPInit(data []byte, p gopacket.PacketBuilder) error {
 		InboundStreams:                 binary.BigEndian.Uint16(data[14:16]),
 		InitialTSN:                     binary.BigEndian.Uint32(data[16:20]),
 	}
-	paramData := data[20:sc.ActualLength]
-	for len(paramData) > 0 {
-		p := SCTPInitParameter(decodeSCTPParameter(paramData))
-		paramData = paramData[p.ActualLength:]
-		sc.Parameters = append(sc.Parameters, p)
+	params, err := decodeSCTPParameters(data[20:sc.ActualLength])
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		sc.Parameters = append(sc.Parameters, SCTPInitParameter(param))
 	}
 	p.AddLayer(sc)
 	return p.NextDecoder(gopacket.DecodeFunc(decodeWithSCTPChunkTypePrefix))
@@ -407,17 +531,41 @@ func (sc SCTPInit) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Seriali
 	binary.BigEndian.PutUint16(bytes[14:16], sc.InboundStreams)
 	binary.BigEndian.PutUint32(bytes[16:20], sc.InitialTSN)
 	copy(bytes[20:], payload)
+	zeroSCTPPadding(bytes, length)
 	return nil
 }
 
+// IP returns the address of IPv4 and IPv6 address parameters, or nil.
+func (p SCTPInitParameter) IP() net.IP {
+	return SCTPParameter(p).IP()
+}
+
 // SCTPSack is the SCTP Selective ACK chunk layer.
 type SCTPSack struct {
 	SCTPChunk
 	CumulativeTSNAck               uint32
 	AdvertisedReceiverWindowCredit uint32
-	NumGapACKs, NumDuplicateTSNs   uint16
-	GapACKs                        []uint16
-	DuplicateTSNs                  []uint32
+	// NumGapACKs is the number of gap ack blocks
+	NumGapACKs, NumDuplicateTSNs uint16
+	// GapACKs holds the start and end offsets from the CumulativeTSNAck of
+	// each gap ack block
+	GapACKs       []uint16
+	DuplicateTSNs []uint32
+}
+
+// SCTPGapACKBlock is a gap ack block of an SCTPSack: the TSNs from
+// CumulativeTSNAck+Start to CumulativeTSNAck+End were received.
+type SCTPGapACKBlock struct {
+	Start, End uint16
+}
+
+// GapACKBlocks returns the gap ack blocks of GapACKs.
+func (sc *SCTPSack) GapACKBlocks() []SCTPGapACKBlock {
+	blocks := make([]SCTPGapACKBlock, 0, len(sc.GapACKs)/2)
+	for i := 0; i+1 < len(sc.GapACKs); i += 2 {
+		blocks = append(blocks, SCTPGapACKBlock{sc.GapACKs[i], sc.GapACKs[i+1]})
+	}
+	return blocks
 }
 
 // LayerType return LayerTypeSCTPSack
@@ -443,10 +591,13 @@ func decodeSCTPSack(data []byte, p gopacket.PacketBuilder) error {
 	// fail if the user-supplied values are too high (in the for loops below), but
 	// the amount of memory we'll have allocated because of that should be small
 	// (< sc.ActualLength)
+	if 16+4*int(sc.NumGapACKs)+4*int(sc.NumDuplicateTSNs) > int(sc.Length) {
+		return errors.New("SCTP SACK chunk too short")
+	}
 	gapAcks := sc.SCTPChunk.ActualLength / 2
 	dupTSNs := (sc.SCTPChunk.ActualLength - gapAcks*2) / 4
-	if gapAcks > int(sc.NumGapACKs) {
-		gapAcks = int(sc.NumGapACKs)
+	if gapAcks > 2*int(sc.NumGapACKs) {
+		gapAcks = 2 * int(sc.NumGapACKs)
 	}
 	if dupTSNs > int(sc.NumDuplicateTSNs) {
 		dupTSNs = int(sc.NumDuplicateTSNs)
@@ -454,7 +605,7 @@ func decodeSCTPSack(data []byte, p gopacket.PacketBuilder) error {
 	sc.GapACKs = make([]uint16, 0, gapAcks)
 	sc.DuplicateTSNs = make([]uint32, 0, dupTSNs)
 	bytesRemaining := data[16:]
-	for i := 0; i < int(sc.NumGapACKs); i++ {
+	for i := 0; i < 2*int(sc.NumGapACKs); i++ {
 		sc.GapACKs = append(sc.GapACKs, binary.BigEndian.Uint16(bytesRemaining[:2]))
 		bytesRemaining = bytesRemaining[2:]
 	}
@@ -468,6 +619,9 @@ func decodeSCTPSack(data []byte, p gopacket.PacketBuilder) error {
 
 // SerializeTo is for gopacket.SerializableLayer.
 func (sc SCTPSack) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if len(sc.GapACKs)%2 != 0 {
+		return errors.New("SCTP SACK gap ack block without end")
+	}
 	length := 16 + 2*len(sc.GapACKs) + 4*len(sc.DuplicateTSNs)
 	bytes, err := b.PrependBytes(roundUpToNearest4(length))
 	if err != nil {
@@ -478,7 +632,7 @@ func (sc SCTPSack) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Seriali
 	binary.BigEndian.PutUint16(bytes[2:4], uint16(length))
 	binary.BigEndian.PutUint32(bytes[4:8], sc.CumulativeTSNAck)
 	binary.BigEndian.PutUint32(bytes[8:12], sc.AdvertisedReceiverWindowCredit)
-	binary.BigEndian.PutUint16(bytes[12:14], uint16(len(sc.GapACKs)))
+	binary.BigEndian.PutUint16(bytes[12:14], uint16(len(sc.GapACKs)/2))
 	binary.BigEndian.PutUint16(bytes[14:16], uint16(len(sc.DuplicateTSNs)))
 	for i, v := range sc.GapACKs {
 		binary.BigEndian.PutUint16(bytes[16+i*2:], v)
@@ -487,6 +641,7 @@ func (sc SCTPSack) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.Seriali
 	for i, v := range sc.DuplicateTSNs {
 		binary.BigEndian.PutUint32(bytes[offset+i*4:], v)
 	}
+	zeroSCTPPadding(bytes, length)
 	return nil
 }
 
@@ -517,11 +672,12 @@ func decodeSCTPHeartbeat(data []byte, p gopacket.PacketBuilder) error {
 	sc := &SCTPHeartbeat{
 		SCTPChunk: chunk,
 	}
-	paramData := data[4:sc.Length]
-	for len(paramData) > 0 {
-		p := SCTPHeartbeatParameter(decodeSCTPParameter(paramData))
-		paramData = paramData[p.ActualLength:]
-		sc.Parameters = append(sc.Parameters, p)
+	params, err := decodeSCTPParameters(data[4:sc.Length])
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		sc.Parameters = append(sc.Parameters, SCTPHeartbeatParameter(param))
 	}
 	p.AddLayer(sc)
 	return p.NextDecoder(gopacket.DecodeFunc(decodeWithSCTPChunkTypePrefix))
@@ -573,11 +729,12 @@ func decodeSCTPError(data []byte, p gopacket.PacketBuilder) error {
 	sc := &SCTPError{
 		SCTPChunk: chunk,
 	}
-	paramData := data[4:sc.Length]
-	for len(paramData) > 0 {
-		p := SCTPErrorParameter(decodeSCTPParameter(paramData))
-		paramData = paramData[p.ActualLength:]
-		sc.Parameters = append(sc.Parameters, p)
+	params, err := decodeSCTPParameters(data[4:sc.Length])
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		sc.Parameters = append(sc.Parameters, SCTPErrorParameter(param))
 	}
 	p.AddLayer(sc)
 	return p.NextDecoder(gopacket.DecodeFunc(decodeWithSCTPChunkTypePrefix))
@@ -702,6 +859,7 @@ func (sc SCTPCookieEcho) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.S
 	bytes[1] = sc.Flags
 	binary.BigEndian.PutUint16(bytes[2:4], uint16(length))
 	copy(bytes[4:], sc.Cookie)
+	zeroSCTPPadding(bytes, length)
 	return nil
 }
 
@@ -744,3 +902,408 @@ func (sc SCTPEmptyLayer) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.S
 	binary.BigEndian.PutUint16(bytes[2:4], 4)
 	return nil
 }
+
+// SCTPECN is the SCTP ECN Echo layer, also used for Congestion Window
+// Reduced chunks.
+type SCTPECN struct {
+	SCTPChunk
+	// TSN is the lowest TSN of ECNE chunks, or the TSN of CWR chunks
+	TSN uint32
+}
+
+// LayerType returns LayerTypeSCTPECNE or LayerTypeSCTPCWR.
+func (sc *SCTPECN) LayerType() gopacket.LayerType {
+	if sc.Type == SCTPChunkTypeCWR {
+		return LayerTypeSCTPCWR
+	}
+	// sc.Type == SCTPChunkTypeECNE
+	return LayerTypeSCTPECNE
+}
+
+func decodeSCTPECN(data []byte, p gopacket.PacketBuilder) error {
+	chunk, err := decodeSCTPChunk(data)
+	if err != nil {
+		return err
+	}
+	sc := &SCTPECN{
+		SCTPChunk: chunk,
+		TSN:       binary.BigEndian.Uint32(data[4:8]),
+	}
+	p.AddLayer(sc)
+	return p.NextDecoder(gopacket.DecodeFunc(decodeWithSCTPChunkTypePrefix))
+}
+
+// SerializeTo is for gopacket.SerializableLayer.
+func (sc SCTPECN) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	bytes, err := b.PrependBytes(8)
+	if err != nil {
+		return err
+	}
+	bytes[0] = uint8(sc.Type)
+	bytes[1] = sc.Flags
+	binary.BigEndian.PutUint16(bytes[2:4], 8)
+	binary.BigEndian.PutUint32(bytes[4:8], sc.TSN)
+	return nil
+}
+
+// SCTPAuth is the SCTP authentication chunk layer, RFC 4895.
+type SCTPAuth struct {
+	SCTPChunk
+	SharedKeyID, HMACID uint16
+	HMAC                []byte
+}
+
+// LayerType returns LayerTypeSCTPAuth.
+func (sc *SCTPAuth) LayerType() gopacket.LayerType { return LayerTypeSCTPAuth }
+
+func decodeSCTPAuth(data []byte, p gopacket.PacketBuilder) error {
+	chunk, err := decodeSCTPChunk(data)
+	if err != nil {
+		return err
+	}
+	sc := &SCTPAuth{
+		SCTPChunk:   chunk,
+		SharedKeyID: binary.BigEndian.Uint16(data[4:6]),
+		HMACID:      binary.BigEndian.Uint16(data[6:8]),
+	}
+	sc.HMAC = data[8:sc.Length]
+	p.AddLayer(sc)
+	return p.NextDecoder(gopacket.DecodeFunc(decodeWithSCTPChunkTypePrefix))
+}
+
+// SerializeTo is for gopacket.SerializableLayer.
+func (sc SCTPAuth) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	length := 8 + len(sc.HMAC)
+	bytes, err := b.PrependBytes(roundUpToNearest4(length))
+	if err != nil {
+		return err
+	}
+	bytes[0] = uint8(sc.Type)
+	bytes[1] = sc.Flags
+	binary.BigEndian.PutUint16(bytes[2:4], uint16(length))
+	binary.BigEndian.PutUint16(bytes[4:6], sc.SharedKeyID)
+	binary.BigEndian.PutUint16(bytes[6:8], sc.HMACID)
+	copy(bytes[8:], sc.HMAC)
+	zeroSCTPPadding(bytes, length)
+	return nil
+}
+
+// SCTPForwardTSNStream is a stream of an SCTPForwardTSN, with the largest
+// stream sequence number skipped.
+type SCTPForwardTSNStream struct {
+	StreamId       uint16
+	StreamSequence uint16
+}
+
+// SCTPForwardTSN is the SCTP Forward Cumulative TSN chunk layer of the
+// partial reliability extension, RFC 3758, used by WebRTC data channels.
+type SCTPForwardTSN struct {
+	SCTPChunk
+	NewCumulativeTSN uint32
+	Streams          []SCTPForwardTSNStream
+}
+
+// LayerType returns LayerTypeSCTPForwardTSN.
+func (sc *SCTPForwardTSN) LayerType() gopacket.LayerType { return LayerTypeSCTPForwardTSN }
+
+func decodeSCTPForwardTSN(data []byte, p gopacket.PacketBuilder) error {
+	chunk, err := decodeSCTPChunk(data)
+	if err != nil {
+		return err
+	}
+	sc := &SCTPForwardTSN{
+		SCTPChunk:        chunk,
+		NewCumulativeTSN: binary.BigEndian.Uint32(data[4:8]),
+	}
+	for streams := data[8:sc.Length]; len(streams) >= 4; streams = streams[4:] {
+		sc.Streams = append(sc.Streams, SCTPForwardTSNStream{
+			StreamId:       binary.BigEndian.Uint16(streams[0:2]),
+			StreamSequence: binary.BigEndian.Uint16(streams[2:4]),
+		})
+	}
+	p.AddLayer(sc)
+	return p.NextDecoder(gopacket.DecodeFunc(decodeWithSCTPChunkTypePrefix))
+}
+
+// SerializeTo is for gopacket.SerializableLayer.
+func (sc SCTPForwardTSN) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	length := 8 + 4*len(sc.Streams)
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	bytes[0] = uint8(sc.Type)
+	bytes[1] = sc.Flags
+	binary.BigEndian.PutUint16(bytes[2:4], uint16(length))
+	binary.BigEndian.PutUint32(bytes[4:8], sc.NewCumulativeTSN)
+	for i, s := range sc.Streams {
+		binary.BigEndian.PutUint16(bytes[8+i*4:], s.StreamId)
+		binary.BigEndian.PutUint16(bytes[10+i*4:], s.StreamSequence)
+	}
+	return nil
+}
+
+// SCTPReConfigParameter is a parameter of an SCTP Re-configuration chunk,
+// RFC 6525: a request to reset streams or to add streams, or the response
+// to a request.  The fields used depend on its Type.
+type SCTPReConfigParameter struct {
+	Type uint16
+	// RequestSequence is the sequence number of requests
+	RequestSequence uint32
+	// ResponseSequence is the sequence number of the request answered by
+	// responses and outgoing SSN reset requests
+	ResponseSequence uint32
+	// SenderLastTSN is the last TSN of outgoing SSN reset requests
+	SenderLastTSN uint32
+	// Streams are the streams to reset, all if empty
+	Streams []uint16
+	// Result is the result of responses
+	Result uint32
+	// HasTSNs is set if responses have the next TSNs of the sender and
+	// receiver
+	HasTSNs                        bool
+	SenderNextTSN, ReceiverNextTSN uint32
+	// NumberOfNewStreams is the number of streams of add streams requests
+	NumberOfNewStreams uint16
+}
+
+func decodeSCTPReConfigParameter(param SCTPParameter) (SCTPReConfigParameter, error) {
+	r := SCTPReConfigParameter{Type: param.Type}
+	v := param.Value
+	var min int
+	switch param.Type {
+	case SCTPParameterOutgoingSSNResetRequest:
+		min = 12
+	case SCTPParameterIncomingSSNResetRequest, SCTPParameterSSNTSNResetRequest:
+		min = 4
+	case SCTPParameterReconfigurationResponse, SCTPParameterAddOutgoingStreamsRequest, SCTPParameterAddIncomingStreamsRequest:
+		min = 8
+	default:
+		return r, fmt.Errorf("invalid SCTP re-configuration parameter type %d", param.Type)
+	}
+	if len(v) < min {
+		return r, errors.New("SCTP re-configuration parameter too short")
+	}
+	var streams []byte
+	switch param.Type {
+	case SCTPParameterOutgoingSSNResetRequest:
+		r.RequestSequence = binary.BigEndian.Uint32(v[0:4])
+		r.ResponseSequence = binary.BigEndian.Uint32(v[4:8])
+		r.SenderLastTSN = binary.BigEndian.Uint32(v[8:12])
+		streams = v[12:]
+	case SCTPParameterIncomingSSNResetRequest:
+		r.RequestSequence = binary.BigEndian.Uint32(v[0:4])
+		streams = v[4:]
+	case SCTPParameterSSNTSNResetRequest:
+		r.RequestSequence = binary.BigEndian.Uint32(v[0:4])
+	case SCTPParameterReconfigurationResponse:
+		r.ResponseSequence = binary.BigEndian.Uint32(v[0:4])
+		r.Result = binary.BigEndian.Uint32(v[4:8])
+		if len(v) >= 16 {
+			r.HasTSNs = true
+			r.SenderNextTSN = binary.BigEndian.Uint32(v[8:12])
+			r.ReceiverNextTSN = binary.BigEndian.Uint32(v[12:16])
+		}
+	default:
+		r.RequestSequence = binary.BigEndian.Uint32(v[0:4])
+		r.NumberOfNewStreams = binary.BigEndian.Uint16(v[4:6])
+	}
+	for ; len(streams) >= 2; streams = streams[2:] {
+		r.Streams = append(r.Streams, binary.BigEndian.Uint16(streams))
+	}
+	return r, nil
+}
+
+// parameter returns r as an SCTPParameter.
+func (r SCTPReConfigParameter) parameter() SCTPParameter {
+	var v []byte
+	put32 := func(i uint32) {
+		v = append(v, byte(i>>24), byte(i>>16), byte(i>>8), byte(i))
+	}
+	switch r.Type {
+	case SCTPParameterOutgoingSSNResetRequest:
+		put32(r.RequestSequence)
+		put32(r.ResponseSequence)
+		put32(r.SenderLastTSN)
+	case SCTPParameterReconfigurationResponse:
+		put32(r.ResponseSequence)
+		put32(r.Result)
+		if r.HasTSNs {
+			put32(r.SenderNextTSN)
+			put32(r.ReceiverNextTSN)
+		}
+	case SCTPParameterAddOutgoingStreamsRequest, SCTPParameterAddIncomingStreamsRequest:
+		put32(r.RequestSequence)
+		v = append(v, byte(r.NumberOfNewStreams>>8), byte(r.NumberOfNewStreams), 0, 0)
+	default:
+		put32(r.RequestSequence)
+	}
+	if r.Type == SCTPParameterOutgoingSSNResetRequest || r.Type == SCTPParameterIncomingSSNResetRequest {
+		for _, s := range r.Streams {
+			v = append(v, byte(s>>8), byte(s))
+		}
+	}
+	return SCTPParameter{Type: r.Type, Value: v}
+}
+
+// SCTPReConfig is the SCTP Re-configuration chunk layer, RFC 6525, used to
+// reset the streams of WebRTC data channels.
+type SCTPReConfig struct {
+	SCTPChunk
+	Parameters []SCTPReConfigParameter
+}
+
+// LayerType returns LayerTypeSCTPReConfig.
+func (sc *SCTPReConfig) LayerType() gopacket.LayerType { return LayerTypeSCTPReConfig }
+
+func decodeSCTPReConfig(data []byte, p gopacket.PacketBuilder) error {
+	chunk, err := decodeSCTPChunk(data)
+	if err != nil {
+		return err
+	}
+	sc := &SCTPReConfig{
+		SCTPChunk: chunk,
+	}
+	params, err := decodeSCTPParameters(data[4:sc.Length])
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		r, err := decodeSCTPReConfigParameter(param)
+		if err != nil {
+			return err
+		}
+		sc.Parameters = append(sc.Parameters, r)
+	}
+	p.AddLayer(sc)
+	return p.NextDecoder(gopacket.DecodeFunc(decodeWithSCTPChunkTypePrefix))
+}
+
+// SerializeTo is for gopacket.SerializableLayer.
+func (sc SCTPReConfig) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	var payload []byte
+	for _, param := range sc.Parameters {
+		payload = append(payload, param.parameter().Bytes()...)
+	}
+	length := 4 + len(payload)
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	bytes[0] = uint8(sc.Type)
+	bytes[1] = sc.Flags
+	binary.BigEndian.PutUint16(bytes[2:4], uint16(length))
+	copy(bytes[4:], payload)
+	return nil
+}
+
+// SCTPASCONFParameter is a parameter of an SCTP ASCONF or ASCONF-ACK chunk,
+// RFC 5061: a request to add, delete or set as primary an address, or the
+// response to a request, identified by its correlation ID.
+type SCTPASCONFParameter struct {
+	Type          uint16
+	CorrelationID uint32
+	// Address is the address parameter of the requests
+	Address SCTPParameter
+	// Causes are the error causes of error cause indications
+	Causes []SCTPErrorParameter
+}
+
+// SCTPASCONF is the SCTP Address Configuration Change chunk layer, also used
+// for ASCONF-ACK chunks.
+type SCTPASCONF struct {
+	SCTPChunk
+	SerialNumber uint32
+	// Address is the address parameter of ASCONF chunks, an address of the
+	// sender
+	Address    SCTPParameter
+	Parameters []SCTPASCONFParameter
+}
+
+// LayerType returns LayerTypeSCTPASCONF or LayerTypeSCTPASCONFAck.
+func (sc *SCTPASCONF) LayerType() gopacket.LayerType {
+	if sc.Type == SCTPChunkTypeASCONFAck {
+		return LayerTypeSCTPASCONFAck
+	}
+	// sc.Type == SCTPChunkTypeASCONF
+	return LayerTypeSCTPASCONF
+}
+
+func decodeSCTPASCONF(data []byte, p gopacket.PacketBuilder) error {
+	chunk, err := decodeSCTPChunk(data)
+	if err != nil {
+		return err
+	}
+	sc := &SCTPASCONF{
+		SCTPChunk:    chunk,
+		SerialNumber: binary.BigEndian.Uint32(data[4:8]),
+	}
+	params, err := decodeSCTPParameters(data[8:sc.Length])
+	if err != nil {
+		return err
+	}
+	if sc.Type == SCTPChunkTypeASCONF {
+		if len(params) == 0 {
+			return errors.New("SCTP ASCONF chunk without address")
+		}
+		sc.Address, params = params[0], params[1:]
+	}
+	for _, param := range params {
+		if len(param.Value) < 4 {
+			return errors.New("SCTP ASCONF parameter too short")
+		}
+		a := SCTPASCONFParameter{
+			Type:          param.Type,
+			CorrelationID: binary.BigEndian.Uint32(param.Value[0:4]),
+		}
+		nested, err := decodeSCTPParameters(param.Value[4:])
+		if err != nil {
+			return err
+		}
+		switch param.Type {
+		case SCTPParameterAddIPAddress, SCTPParameterDeleteIPAddress, SCTPParameterSetPrimaryAddress:
+			if len(nested) != 1 {
+				return errors.New("SCTP ASCONF request without address")
+			}
+			a.Address = nested[0]
+		default:
+			for _, n := range nested {
+				a.Causes = append(a.Causes, SCTPErrorParameter(n))
+			}
+		}
+		sc.Parameters = append(sc.Parameters, a)
+	}
+	p.AddLayer(sc)
+	return p.NextDecoder(gopacket.DecodeFunc(decodeWithSCTPChunkTypePrefix))
+}
+
+// SerializeTo is for gopacket.SerializableLayer.
+func (sc SCTPASCONF) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	var payload []byte
+	if sc.Type == SCTPChunkTypeASCONF {
+		payload = sc.Address.Bytes()
+	}
+	for _, param := range sc.Parameters {
+		value := []byte{byte(param.CorrelationID >> 24), byte(param.CorrelationID >> 16), byte(param.CorrelationID >> 8), byte(param.CorrelationID)}
+		switch param.Type {
+		case SCTPParameterAddIPAddress, SCTPParameterDeleteIPAddress, SCTPParameterSetPrimaryAddress:
+			value = append(value, param.Address.Bytes()...)
+		default:
+			for _, c := range param.Causes {
+				value = append(value, SCTPParameter(c).Bytes()...)
+			}
+		}
+		payload = append(payload, SCTPParameter{Type: param.Type, Value: value}.Bytes()...)
+	}
+	length := 8 + len(payload)
+	bytes, err := b.PrependBytes(length)
+	if err != nil {
+		return err
+	}
+	bytes[0] = uint8(sc.Type)
+	bytes[1] = sc.Flags
+	binary.BigEndian.PutUint16(bytes[2:4], uint16(length))
+	binary.BigEndian.PutUint32(bytes[4:8], sc.SerialNumber)
+	copy(bytes[8:], payload)
+	return nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestSCTPChunks(t *testing.T) {
+	v4 := SCTPParameter{Type: SCTPParameterIPv4Address, Value: []byte{10, 0, 0, 1}}
+	data, err := Build().IPv4(&IPv4{Protocol: IPProtocolSCTP}).Layer(&SCTP{SrcPort: 5000, DstPort: 5000, VerificationTag: 7}).
+		Layer(&SCTPInit{SCTPChunk: SCTPChunk{Type: SCTPChunkTypeInit}, InitiateTag: 1, OutboundStreams: 1, InboundStreams: 1, Parameters: []SCTPInitParameter{
+			SCTPInitParameter(v4),
+			{Type: SCTPParameterSupportedExtensions, Value: []byte{byte(SCTPChunkTypeReConfig), byte(SCTPChunkTypeForwardTSN)}},
+		}}).
+		Layer(&SCTPSack{SCTPChunk: SCTPChunk{Type: SCTPChunkTypeSack}, CumulativeTSNAck: 100, GapACKs: []uint16{2, 3, 5, 5}, DuplicateTSNs: []uint32{99}}).
+		Layer(&SCTPECN{SCTPChunk: SCTPChunk{Type: SCTPChunkTypeCWR}, TSN: 42}).
+		Layer(&SCTPAuth{SCTPChunk: SCTPChunk{Type: SCTPChunkTypeAuth}, SharedKeyID: 1, HMACID: 3, HMAC: []byte{1, 2, 3, 4, 5, 6}}).
+		Layer(&SCTPForwardTSN{SCTPChunk: SCTPChunk{Type: SCTPChunkTypeForwardTSN}, NewCumulativeTSN: 120, Streams: []SCTPForwardTSNStream{{1, 2}}}).
+		Layer(&SCTPReConfig{SCTPChunk: SCTPChunk{Type: SCTPChunkTypeReConfig}, Parameters: []SCTPReConfigParameter{
+			{Type: SCTPParameterOutgoingSSNResetRequest, RequestSequence: 9, ResponseSequence: 8, SenderLastTSN: 119, Streams: []uint16{1}},
+			{Type: SCTPParameterReconfigurationResponse, ResponseSequence: 5, Result: 1, HasTSNs: true, SenderNextTSN: 3, ReceiverNextTSN: 4},
+		}}).
+		Layer(&SCTPASCONF{SCTPChunk: SCTPChunk{Type: SCTPChunkTypeASCONF}, SerialNumber: 11, Address: v4, Parameters: []SCTPASCONFParameter{
+			{Type: SCTPParameterAddIPAddress, CorrelationID: 1, Address: SCTPParameter{Type: SCTPParameterIPv6Address, Value: net.IPv6loopback}},
+		}}).
+		Layer(&SCTPData{SCTPChunk: SCTPChunk{Type: SCTPChunkTypeData}, TSN: 121, BeginFragment: true, EndFragment: true, PayloadProtocol: 51}).
+		Payload([]byte("hello")).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeSCTP, LayerTypeSCTPInit, LayerTypeSCTPSack, LayerTypeSCTPCWR, LayerTypeSCTPAuth,
+		LayerTypeSCTPForwardTSN, LayerTypeSCTPReConfig, LayerTypeSCTPASCONF, LayerTypeSCTPData, gopacket.LayerTypePayload}, t)
+	if ok, err := p.Layer(LayerTypeSCTP).(*SCTP).VerifyChecksum(); err != nil || !ok {
+		t.Errorf("bad checksum %v", err)
+	}
+
+	init := p.Layer(LayerTypeSCTPInit).(*SCTPInit)
+	if len(init.Parameters) != 2 || !init.Parameters[0].IP().Equal(net.IPv4(10, 0, 0, 1)) || init.Parameters[1].Length != 6 {
+		t.Errorf("unexpected init %#v", init)
+	}
+	sack := p.Layer(LayerTypeSCTPSack).(*SCTPSack)
+	if sack.NumGapACKs != 2 || !reflect.DeepEqual(sack.GapACKBlocks(), []SCTPGapACKBlock{{2, 3}, {5, 5}}) || !reflect.DeepEqual(sack.DuplicateTSNs, []uint32{99}) {
+		t.Errorf("unexpected sack %#v", sack)
+	}
+	if cwr := p.Layer(LayerTypeSCTPCWR).(*SCTPECN); cwr.TSN != 42 {
+		t.Errorf("unexpected CWR %#v", cwr)
+	}
+	if auth := p.Layer(LayerTypeSCTPAuth).(*SCTPAuth); auth.HMACID != 3 || len(auth.HMAC) != 6 || auth.ActualLength != 16 {
+		t.Errorf("unexpected auth %#v", auth)
+	}
+	if fwd := p.Layer(LayerTypeSCTPForwardTSN).(*SCTPForwardTSN); fwd.NewCumulativeTSN != 120 || !reflect.DeepEqual(fwd.Streams, []SCTPForwardTSNStream{{1, 2}}) {
+		t.Errorf("unexpected forward TSN %#v", fwd)
+	}
+	reconfig := p.Layer(LayerTypeSCTPReConfig).(*SCTPReConfig)
+	if len(reconfig.Parameters) != 2 || reconfig.Parameters[0].SenderLastTSN != 119 || !reflect.DeepEqual(reconfig.Parameters[0].Streams, []uint16{1}) ||
+		!reconfig.Parameters[1].HasTSNs || reconfig.Parameters[1].ReceiverNextTSN != 4 {
+		t.Errorf("unexpected re-config %#v", reconfig)
+	}
+	asconf := p.Layer(LayerTypeSCTPASCONF).(*SCTPASCONF)
+	if asconf.SerialNumber != 11 || asconf.Address.IP() == nil || len(asconf.Parameters) != 1 || asconf.Parameters[0].CorrelationID != 1 ||
+		!asconf.Parameters[0].Address.IP().Equal(net.IPv6loopback) {
+		t.Errorf("unexpected ASCONF %#v", asconf)
+	}
+	if d := p.Layer(LayerTypeSCTPData).(*SCTPData); d.TSN != 121 || string(d.Payload) != "hello" {
+		t.Errorf("unexpected data %#v", d)
+	}
+
+	// chunks and parameters overrunning the packet
+	for _, data := range [][]byte{
+		{0x03, 0, 0, 8, 0, 0, 0, 0},
+		{0x01, 0, 0, 30, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 5, 0, 8, 10, 0},
+		{0x01, 0, 0, 24, 0, 0, 0, 1, 0, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 5, 0, 0},
+		{0x03, 0, 0, 16, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0},
+		{0xc1, 0, 0, 8, 0, 0, 0, 0},
+	} {
+		p := gopacket.NewPacket(data, sctpChunkTypePrefixDecoder, gopacket.Default)
+		if p.ErrorLayer() == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
@@ -52,6 +52,13 @@ The following sample record types are supported:
 	Extended URL Data
 	opaque = flow_data; enterprise = 0; format = 1005
 
+	Extended MPLS, NAT, MPLS Tunnel, MPLS VC, MPLS FTN, MPLS LDP FEC
+	and VLAN Tunnel Data
+	opaque = flow_data; enterprise = 0; format = 1006 to 1012
+
+	Extended 802.11 Payload, RX and TX Data - see sflow_80211.txt
+	opaque = flow_data; enterprise = 0; format = 1013 to 1015
+
 The following types of counter records are supported:
 
 	Generic Interface Counters - see RFC 2233
@@ -60,6 +67,19 @@ The following types of counter records are supported:
 	Ethernet Interface Counters - see RFC 2358
 	opaque = counter_data; enterprise = 0; format = 2
 
+	802.11 Counters and Radio Utilization - see sflow_80211.txt
+	opaque = counter_data; enterprise = 0; format = 6 and 1002
+
+	LAG Port Statistics - see sflow_lag.txt
+	opaque = counter_data; enterprise = 0; format = 7
+
+	Host and Virtual Domain Counters - see sflow_host.txt
+	opaque = counter_data; enterprise = 0; format = 2000 to 2006
+	and 2100 to 2104
+
+Other records, including enterprise specific ones, are kept undecoded as
+SFlowUnknownFlowRecord and SFlowUnknownCounterRecord.
+
 SFlow is encoded using XDR (RFC4506). There are a few places
 where the standard 4-byte fields are partitioned into two
 bitfields of different lengths. I'm not sure why the designers
@@ -75,6 +95,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"net"
 
 	"github.com/google/gopacket"
@@ -448,11 +469,6 @@ func (fs SFlowFlowSample) GetType() SFlowSampleType {
 	return SFlowTypeFlowSample
 }
 
-func skipRecord(data *[]byte) {
-	recordLength := int(binary.BigEndian.Uint32((*data)[4:]))
-	*data = (*data)[(recordLength+((4-recordLength)%4))+8:]
-}
-
 func decodeFlowSample(data *[]byte, expanded bool) (SFlowFlowSample, error) {
 	s := SFlowFlowSample{}
 	var sdf SFlowDataFormat
@@ -593,33 +609,65 @@ func decodeFlowSample(data *[]byte, expanded bool) (SFlowFlowSample, error) {
 					return s, err
 				}
 			case SFlowTypeExtendedMlpsFlow:
-				// TODO
-				skipRecord(data)
-				return s, errors.New("skipping TypeExtendedMlpsFlow")
+				if record, err := decodeExtendedMPLSRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
 			case SFlowTypeExtendedNatFlow:
-				// TODO
-				skipRecord(data)
-				return s, errors.New("skipping TypeExtendedNatFlow")
+				if record, err := decodeExtendedNATRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
 			case SFlowTypeExtendedMlpsTunnelFlow:
-				// TODO
-				skipRecord(data)
-				return s, errors.New("skipping TypeExtendedMlpsTunnelFlow")
+				if record, err := decodeExtendedMPLSTunnelRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
 			case SFlowTypeExtendedMlpsVcFlow:
-				// TODO
-				skipRecord(data)
-				return s, errors.New("skipping TypeExtendedMlpsVcFlow")
+				if record, err := decodeExtendedMPLSVCRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
 			case SFlowTypeExtendedMlpsFecFlow:
-				// TODO
-				skipRecord(data)
-				return s, errors.New("skipping TypeExtendedMlpsFecFlow")
+				if record, err := decodeExtendedMPLSFTNRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
 			case SFlowTypeExtendedMlpsLvpFecFlow:
-				// TODO
-				skipRecord(data)
-				return s, errors.New("skipping TypeExtendedMlpsLvpFecFlow")
+				if record, err := decodeExtendedMPLSLDPFECRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
 			case SFlowTypeExtendedVlanFlow:
-				// TODO
-				skipRecord(data)
-				return s, errors.New("skipping TypeExtendedVlanFlow")
+				if record, err := decodeExtendedVLANTunnelRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
+			case SFlowTypeExtended80211PayloadFlow:
+				if record, err := decodeExtended80211PayloadRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
+			case SFlowTypeExtended80211RxFlow:
+				if record, err := decodeExtended80211RxRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
+			case SFlowTypeExtended80211TxFlow:
+				if record, err := decodeExtended80211TxRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
 			case SFlowTypeExtendedIpv4TunnelEgressFlow:
 				if record, err := decodeExtendedIpv4TunnelEgress(data); err == nil {
 					s.Records = append(s.Records, record)
@@ -669,10 +717,19 @@ func decodeFlowSample(data *[]byte, expanded bool) (SFlowFlowSample, error) {
 					return s, err
 				}
 			default:
-				return s, fmt.Errorf("Unsupported flow record type: %d", flowRecordType)
+				if record, err := decodeUnknownFlowRecord(data); err == nil {
+					s.Records = append(s.Records, record)
+				} else {
+					return s, err
+				}
 			}
 		} else {
-			skipRecord(data)
+			// Enterprise specific records are left undecoded
+			if record, err := decodeUnknownFlowRecord(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
 		}
 	}
 	return s, nil
@@ -738,10 +795,24 @@ const (
 	SFlowTypeTokenRingInterfaceCounters SFlowCounterRecordType = 3
 	SFlowType100BaseVGInterfaceCounters SFlowCounterRecordType = 4
 	SFlowTypeVLANCounters               SFlowCounterRecordType = 5
+	SFlowType80211Counters              SFlowCounterRecordType = 6
 	SFlowTypeLACPCounters               SFlowCounterRecordType = 7
 	SFlowTypeProcessorCounters          SFlowCounterRecordType = 1001
+	SFlowTypeRadioUtilizationCounters   SFlowCounterRecordType = 1002
 	SFlowTypeOpenflowPortCounters       SFlowCounterRecordType = 1004
 	SFlowTypePORTNAMECounters           SFlowCounterRecordType = 1005
+	SFlowTypeHostDescrCounters          SFlowCounterRecordType = 2000
+	SFlowTypeHostAdaptersCounters       SFlowCounterRecordType = 2001
+	SFlowTypeHostParentCounters         SFlowCounterRecordType = 2002
+	SFlowTypeHostCPUCounters            SFlowCounterRecordType = 2003
+	SFlowTypeHostMemoryCounters         SFlowCounterRecordType = 2004
+	SFlowTypeHostDiskIOCounters         SFlowCounterRecordType = 2005
+	SFlowTypeHostNetIOCounters          SFlowCounterRecordType = 2006
+	SFlowTypeVirtNodeCounters           SFlowCounterRecordType = 2100
+	SFlowTypeVirtCPUCounters            SFlowCounterRecordType = 2101
+	SFlowTypeVirtMemoryCounters         SFlowCounterRecordType = 2102
+	SFlowTypeVirtDiskIOCounters         SFlowCounterRecordType = 2103
+	SFlowTypeVirtNetIOCounters          SFlowCounterRecordType = 2104
 	SFLowTypeAPPRESOURCESCounters       SFlowCounterRecordType = 2203
 	SFlowTypeOVSDPCounters              SFlowCounterRecordType = 2207
 )
@@ -758,14 +829,42 @@ func (cr SFlowCounterRecordType) String() string {
 		return "100BaseVG Interface Counters"
 	case SFlowTypeVLANCounters:
 		return "VLAN Counters"
+	case SFlowType80211Counters:
+		return "802.11 Counters"
 	case SFlowTypeLACPCounters:
 		return "LACP Counters"
 	case SFlowTypeProcessorCounters:
 		return "Processor Counters"
+	case SFlowTypeRadioUtilizationCounters:
+		return "Radio Utilization Counters"
 	case SFlowTypeOpenflowPortCounters:
 		return "Openflow Port Counters"
 	case SFlowTypePORTNAMECounters:
 		return "PORT NAME Counters"
+	case SFlowTypeHostDescrCounters:
+		return "Host Description"
+	case SFlowTypeHostAdaptersCounters:
+		return "Host Adapters"
+	case SFlowTypeHostParentCounters:
+		return "Host Parent"
+	case SFlowTypeHostCPUCounters:
+		return "Host CPU Counters"
+	case SFlowTypeHostMemoryCounters:
+		return "Host Memory Counters"
+	case SFlowTypeHostDiskIOCounters:
+		return "Host Disk IO Counters"
+	case SFlowTypeHostNetIOCounters:
+		return "Host Network IO Counters"
+	case SFlowTypeVirtNodeCounters:
+		return "Virtual Node Counters"
+	case SFlowTypeVirtCPUCounters:
+		return "Virtual Domain CPU Counters"
+	case SFlowTypeVirtMemoryCounters:
+		return "Virtual Domain Memory Counters"
+	case SFlowTypeVirtDiskIOCounters:
+		return "Virtual Domain Disk IO Counters"
+	case SFlowTypeVirtNetIOCounters:
+		return "Virtual Domain Network IO Counters"
 	case SFLowTypeAPPRESOURCESCounters:
 		return "App Resources Counters"
 	case SFlowTypeOVSDPCounters:
@@ -797,7 +896,16 @@ func decodeCounterSample(data *[]byte, expanded bool) (SFlowCounterSample, error
 
 	for i := uint32(0); i < s.RecordCount; i++ {
 		cdf := SFlowCounterDataFormat(binary.BigEndian.Uint32((*data)[:4]))
-		_, counterRecordType := cdf.decode()
+		enterpriseID, counterRecordType := cdf.decode()
+		if enterpriseID != 0 {
+			// Enterprise specific records are left undecoded
+			if record, err := decodeUnknownCounterRecord(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+			continue
+		}
 		switch counterRecordType {
 		case SFlowTypeGenericInterfaceCounters:
 			if record, err := decodeGenericInterfaceCounters(data); err == nil {
@@ -811,18 +919,18 @@ func decodeCounterSample(data *[]byte, expanded bool) (SFlowCounterSample, error
 			} else {
 				return s, err
 			}
-		case SFlowTypeTokenRingInterfaceCounters:
-			skipRecord(data)
-			return s, errors.New("skipping TypeTokenRingInterfaceCounters")
-		case SFlowType100BaseVGInterfaceCounters:
-			skipRecord(data)
-			return s, errors.New("skipping Type100BaseVGInterfaceCounters")
 		case SFlowTypeVLANCounters:
 			if record, err := decodeVLANCounters(data); err == nil {
 				s.Records = append(s.Records, record)
 			} else {
 				return s, err
 			}
+		case SFlowType80211Counters:
+			if record, err := decode80211Counters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
 		case SFlowTypeLACPCounters:
 			if record, err := decodeLACPCounters(data); err == nil {
 				s.Records = append(s.Records, record)
@@ -835,6 +943,12 @@ func decodeCounterSample(data *[]byte, expanded bool) (SFlowCounterSample, error
 			} else {
 				return s, err
 			}
+		case SFlowTypeRadioUtilizationCounters:
+			if record, err := decodeRadioUtilizationCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
 		case SFlowTypeOpenflowPortCounters:
 			if record, err := decodeOpenflowportCounters(data); err == nil {
 				s.Records = append(s.Records, record)
@@ -847,6 +961,78 @@ func decodeCounterSample(data *[]byte, expanded bool) (SFlowCounterSample, error
 			} else {
 				return s, err
 			}
+		case SFlowTypeHostDescrCounters:
+			if record, err := decodeHostDescrCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeHostAdaptersCounters:
+			if record, err := decodeHostAdaptersCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeHostParentCounters:
+			if record, err := decodeHostParentCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeHostCPUCounters:
+			if record, err := decodeHostCPUCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeHostMemoryCounters:
+			if record, err := decodeHostMemoryCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeHostDiskIOCounters:
+			if record, err := decodeHostDiskIOCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeHostNetIOCounters:
+			if record, err := decodeHostNetIOCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeVirtNodeCounters:
+			if record, err := decodeVirtNodeCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeVirtCPUCounters:
+			if record, err := decodeVirtCPUCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeVirtMemoryCounters:
+			if record, err := decodeVirtMemoryCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeVirtDiskIOCounters:
+			if record, err := decodeVirtDiskIOCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
+		case SFlowTypeVirtNetIOCounters:
+			if record, err := decodeVirtNetIOCounters(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
 		case SFLowTypeAPPRESOURCESCounters:
 			if record, err := decodeAppresourcesCounters(data); err == nil {
 				s.Records = append(s.Records, record)
@@ -860,7 +1046,11 @@ func decodeCounterSample(data *[]byte, expanded bool) (SFlowCounterSample, error
 				return s, err
 			}
 		default:
-			return s, fmt.Errorf("Invalid counter record type: %d", counterRecordType)
+			if record, err := decodeUnknownCounterRecord(data); err == nil {
+				s.Records = append(s.Records, record)
+			} else {
+				return s, err
+			}
 		}
 	}
 	return s, nil
@@ -899,6 +1089,10 @@ const (
 	SFlowTypeExtendedMlpsFecFlow            SFlowFlowRecordType = 1010
 	SFlowTypeExtendedMlpsLvpFecFlow         SFlowFlowRecordType = 1011
 	SFlowTypeExtendedVlanFlow               SFlowFlowRecordType = 1012
+	SFlowTypeExtended80211PayloadFlow       SFlowFlowRecordType = 1013
+	SFlowTypeExtended80211RxFlow            SFlowFlowRecordType = 1014
+	SFlowTypeExtended80211TxFlow            SFlowFlowRecordType = 1015
+	SFlowTypeExtended80211AggregationFlow   SFlowFlowRecordType = 1016
 	SFlowTypeExtendedIpv4TunnelEgressFlow   SFlowFlowRecordType = 1023
 	SFlowTypeExtendedIpv4TunnelIngressFlow  SFlowFlowRecordType = 1024
 	SFlowTypeExtendedIpv6TunnelEgressFlow   SFlowFlowRecordType = 1025
@@ -943,6 +1137,14 @@ func (rt SFlowFlowRecordType) String() string {
 		return "Extended MPLS LVP FEC Flow Record"
 	case SFlowTypeExtendedVlanFlow:
 		return "Extended VLAN Flow Record"
+	case SFlowTypeExtended80211PayloadFlow:
+		return "Extended 802.11 Payload Flow Record"
+	case SFlowTypeExtended80211RxFlow:
+		return "Extended 802.11 RX Flow Record"
+	case SFlowTypeExtended80211TxFlow:
+		return "Extended 802.11 TX Flow Record"
+	case SFlowTypeExtended80211AggregationFlow:
+		return "Extended 802.11 Aggregation Flow Record"
 	case SFlowTypeExtendedIpv4TunnelEgressFlow:
 		return "Extended IPv4 Tunnel Egress Record"
 	case SFlowTypeExtendedIpv4TunnelIngressFlow:
@@ -2061,32 +2263,7 @@ type SFlowBaseCounterRecord struct {
 }
 
 func (bcr SFlowBaseCounterRecord) GetType() SFlowCounterRecordType {
-	switch bcr.Format {
-	case SFlowTypeGenericInterfaceCounters:
-		return SFlowTypeGenericInterfaceCounters
-	case SFlowTypeEthernetInterfaceCounters:
-		return SFlowTypeEthernetInterfaceCounters
-	case SFlowTypeTokenRingInterfaceCounters:
-		return SFlowTypeTokenRingInterfaceCounters
-	case SFlowType100BaseVGInterfaceCounters:
-		return SFlowType100BaseVGInterfaceCounters
-	case SFlowTypeVLANCounters:
-		return SFlowTypeVLANCounters
-	case SFlowTypeLACPCounters:
-		return SFlowTypeLACPCounters
-	case SFlowTypeProcessorCounters:
-		return SFlowTypeProcessorCounters
-	case SFlowTypeOpenflowPortCounters:
-		return SFlowTypeOpenflowPortCounters
-	case SFlowTypePORTNAMECounters:
-		return SFlowTypePORTNAMECounters
-	case SFLowTypeAPPRESOURCESCounters:
-		return SFLowTypeAPPRESOURCESCounters
-	case SFlowTypeOVSDPCounters:
-		return SFlowTypeOVSDPCounters
-	}
-	unrecognized := fmt.Sprint("Unrecognized counter record type:", bcr.Format)
-	panic(unrecognized)
+	return bcr.Format
 }
 
 // **************************************************
@@ -2565,3 +2742,963 @@ func decodePortnameCounters(data *[]byte) (SFlowPORTNAME, error) {
 
 	return pn, nil
 }
+
+// ActorAdmin returns the administrative state of the actor.
+func (ps SFLLACPPortState) ActorAdmin() uint8 { return uint8(ps.PortStateAll >> 24) }
+
+// ActorOper returns the operational state of the actor.
+func (ps SFLLACPPortState) ActorOper() uint8 { return uint8(ps.PortStateAll >> 16) }
+
+// PartnerAdmin returns the administrative state of the partner.
+func (ps SFLLACPPortState) PartnerAdmin() uint8 { return uint8(ps.PortStateAll >> 8) }
+
+// PartnerOper returns the operational state of the partner.
+func (ps SFLLACPPortState) PartnerOper() uint8 { return uint8(ps.PortStateAll) }
+
+// decodeSFlowRecordData removes a flow or counter record from data,
+// returning its format, its length and its data, without its padding.
+func decodeSFlowRecordData(data *[]byte) (uint32, uint32, []byte, error) {
+	if len(*data) < 8 {
+		return 0, 0, nil, errors.New("sflow record header too small")
+	}
+	format := binary.BigEndian.Uint32((*data)[:4])
+	length := binary.BigEndian.Uint32((*data)[4:8])
+	padded := uint64(length) + uint64((4-length%4)%4)
+	if uint64(len(*data)-8) < padded {
+		return 0, 0, nil, fmt.Errorf("sflow record length %d too large", length)
+	}
+	record := (*data)[8 : 8+length]
+	*data = (*data)[8+padded:]
+	return format, length, record, nil
+}
+
+// decodeSFlowOpaque removes a variable length XDR opaque, or string, of at
+// most max bytes from data.
+func decodeSFlowOpaque(data *[]byte, max uint32) ([]byte, error) {
+	if len(*data) < 4 {
+		return nil, errors.New("sflow opaque too small")
+	}
+	length := binary.BigEndian.Uint32((*data)[:4])
+	padded := uint64(length) + uint64((4-length%4)%4)
+	if length > max || uint64(len(*data)-4) < padded {
+		return nil, fmt.Errorf("invalid sflow opaque length %d", length)
+	}
+	opaque := (*data)[4 : 4+length]
+	*data = (*data)[4+padded:]
+	return opaque, nil
+}
+
+// decodeSFlowUint32Array removes a variable length array of unsigned
+// integers from data.
+func decodeSFlowUint32Array(data *[]byte) ([]uint32, error) {
+	if len(*data) < 4 {
+		return nil, errors.New("sflow array too small")
+	}
+	count := binary.BigEndian.Uint32((*data)[:4])
+	if uint64(len(*data)-4) < 4*uint64(count) {
+		return nil, fmt.Errorf("sflow array of %d integers too small", count)
+	}
+	*data = (*data)[4:]
+	values := make([]uint32, count)
+	for i := range values {
+		*data, values[i] = (*data)[4:], binary.BigEndian.Uint32((*data)[:4])
+	}
+	return values, nil
+}
+
+// decodeSFlowAddress removes an IPv4 or IPv6 address, prefixed by its
+// SFlowIPType, from data.
+func decodeSFlowAddress(data *[]byte) (net.IP, error) {
+	if len(*data) < 4 {
+		return nil, errors.New("sflow address too small")
+	}
+	ipType := SFlowIPType(binary.BigEndian.Uint32((*data)[:4]))
+	length := ipType.Length()
+	if length == 0 {
+		return nil, fmt.Errorf("invalid sflow address type %d", ipType)
+	}
+	if len(*data) < 4+length {
+		return nil, errors.New("sflow address too small")
+	}
+	ip := net.IP((*data)[4 : 4+length])
+	*data = (*data)[4+length:]
+	return ip, nil
+}
+
+// SFlowUnknownFlowRecord is a flow record this decoder does not
+// understand, such as an enterprise specific one, holding its data.
+type SFlowUnknownFlowRecord struct {
+	SFlowBaseFlowRecord
+	Data []byte
+}
+
+func decodeUnknownFlowRecord(data *[]byte) (SFlowUnknownFlowRecord, error) {
+	ufr := SFlowUnknownFlowRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return ufr, err
+	}
+	ufr.EnterpriseID, ufr.Format = SFlowFlowDataFormat(fdf).decode()
+	ufr.FlowDataLength = length
+	ufr.Data = record
+	return ufr, nil
+}
+
+// **************************************************
+//  Extended MPLS Flow Record
+// **************************************************
+
+//  0                      15                      31
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |      20 bit Interprise (0)     |12 bit format |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                  record length                |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |   IP version of next hop router (1=v4|2=v6)   |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  /     Next Hop address (v4=4byte|v6=16byte)     /
+//  /                                               /
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |               In label stack count            |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  /                  In label stack               /
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |              Out label stack count            |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  /                 Out label stack               /
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+
+// SFlowExtendedMPLSRecord gives the MPLS next hop and label stacks of the
+// sampled packet.  Labels are the whole 32 bit label stack entries.
+type SFlowExtendedMPLSRecord struct {
+	SFlowBaseFlowRecord
+	NextHop   net.IP
+	InLabels  []uint32
+	OutLabels []uint32
+}
+
+func decodeExtendedMPLSRecord(data *[]byte) (SFlowExtendedMPLSRecord, error) {
+	mpls := SFlowExtendedMPLSRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return mpls, err
+	}
+	mpls.EnterpriseID, mpls.Format = SFlowFlowDataFormat(fdf).decode()
+	mpls.FlowDataLength = length
+	if mpls.NextHop, err = decodeSFlowAddress(&record); err != nil {
+		return mpls, err
+	}
+	if mpls.InLabels, err = decodeSFlowUint32Array(&record); err != nil {
+		return mpls, err
+	}
+	if mpls.OutLabels, err = decodeSFlowUint32Array(&record); err != nil {
+		return mpls, err
+	}
+	return mpls, nil
+}
+
+// **************************************************
+//  Extended NAT Flow Record
+// **************************************************
+
+//  0                      15                      31
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |      20 bit Interprise (0)     |12 bit format |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |                  record length                |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  |   IP version of source address (1=v4|2=v6)    |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  /    Source address (v4=4byte|v6=16byte)        /
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  | IP version of destination address (1=v4|2=v6) |
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+//  /  Destination address (v4=4byte|v6=16byte)     /
+//  +--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+--+
+
+// SFlowExtendedNATRecord gives the addresses of the sampled packet after
+// network address translation.
+type SFlowExtendedNATRecord struct {
+	SFlowBaseFlowRecord
+	SourceAddress      net.IP
+	DestinationAddress net.IP
+}
+
+func decodeExtendedNATRecord(data *[]byte) (SFlowExtendedNATRecord, error) {
+	nat := SFlowExtendedNATRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return nat, err
+	}
+	nat.EnterpriseID, nat.Format = SFlowFlowDataFormat(fdf).decode()
+	nat.FlowDataLength = length
+	if nat.SourceAddress, err = decodeSFlowAddress(&record); err != nil {
+		return nat, err
+	}
+	if nat.DestinationAddress, err = decodeSFlowAddress(&record); err != nil {
+		return nat, err
+	}
+	return nat, nil
+}
+
+// SFlowExtendedMPLSTunnelRecord gives the MPLS tunnel of the sampled
+// packet.
+type SFlowExtendedMPLSTunnelRecord struct {
+	SFlowBaseFlowRecord
+	TunnelLSPName string
+	TunnelID      uint32
+	TunnelCOS     uint32
+}
+
+func decodeExtendedMPLSTunnelRecord(data *[]byte) (SFlowExtendedMPLSTunnelRecord, error) {
+	mt := SFlowExtendedMPLSTunnelRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return mt, err
+	}
+	mt.EnterpriseID, mt.Format = SFlowFlowDataFormat(fdf).decode()
+	mt.FlowDataLength = length
+	name, err := decodeSFlowOpaque(&record, length)
+	if err != nil {
+		return mt, err
+	}
+	mt.TunnelLSPName = string(name)
+	if len(record) < 8 {
+		return mt, errors.New("extended MPLS tunnel record too small")
+	}
+	record, mt.TunnelID = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, mt.TunnelCOS = record[4:], binary.BigEndian.Uint32(record[:4])
+	return mt, nil
+}
+
+// SFlowExtendedMPLSVCRecord gives the MPLS virtual circuit of the sampled
+// packet.
+type SFlowExtendedMPLSVCRecord struct {
+	SFlowBaseFlowRecord
+	VCInstanceName string
+	VLLVCID        uint32
+	VCLabelCOS     uint32
+}
+
+func decodeExtendedMPLSVCRecord(data *[]byte) (SFlowExtendedMPLSVCRecord, error) {
+	vc := SFlowExtendedMPLSVCRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return vc, err
+	}
+	vc.EnterpriseID, vc.Format = SFlowFlowDataFormat(fdf).decode()
+	vc.FlowDataLength = length
+	name, err := decodeSFlowOpaque(&record, length)
+	if err != nil {
+		return vc, err
+	}
+	vc.VCInstanceName = string(name)
+	if len(record) < 8 {
+		return vc, errors.New("extended MPLS VC record too small")
+	}
+	record, vc.VLLVCID = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vc.VCLabelCOS = record[4:], binary.BigEndian.Uint32(record[:4])
+	return vc, nil
+}
+
+// SFlowExtendedMPLSFTNRecord gives the MPLS FEC to next hop label
+// forwarding entry of the sampled packet.
+type SFlowExtendedMPLSFTNRecord struct {
+	SFlowBaseFlowRecord
+	Description string
+	Mask        uint32
+}
+
+func decodeExtendedMPLSFTNRecord(data *[]byte) (SFlowExtendedMPLSFTNRecord, error) {
+	ftn := SFlowExtendedMPLSFTNRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return ftn, err
+	}
+	ftn.EnterpriseID, ftn.Format = SFlowFlowDataFormat(fdf).decode()
+	ftn.FlowDataLength = length
+	descr, err := decodeSFlowOpaque(&record, length)
+	if err != nil {
+		return ftn, err
+	}
+	ftn.Description = string(descr)
+	if len(record) < 4 {
+		return ftn, errors.New("extended MPLS FTN record too small")
+	}
+	ftn.Mask = binary.BigEndian.Uint32(record[:4])
+	return ftn, nil
+}
+
+// SFlowExtendedMPLSLDPFECRecord gives the prefix length of the LDP FEC of
+// the sampled packet.
+type SFlowExtendedMPLSLDPFECRecord struct {
+	SFlowBaseFlowRecord
+	PrefixLength uint32
+}
+
+func decodeExtendedMPLSLDPFECRecord(data *[]byte) (SFlowExtendedMPLSLDPFECRecord, error) {
+	fec := SFlowExtendedMPLSLDPFECRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return fec, err
+	}
+	fec.EnterpriseID, fec.Format = SFlowFlowDataFormat(fdf).decode()
+	fec.FlowDataLength = length
+	if len(record) < 4 {
+		return fec, errors.New("extended MPLS LDP FEC record too small")
+	}
+	fec.PrefixLength = binary.BigEndian.Uint32(record[:4])
+	return fec, nil
+}
+
+// SFlowExtendedVLANTunnelRecord gives the 802.1Q tags, TPID and TCI,
+// stripped from the sampled packet, outermost first.
+type SFlowExtendedVLANTunnelRecord struct {
+	SFlowBaseFlowRecord
+	Stack []uint32
+}
+
+func decodeExtendedVLANTunnelRecord(data *[]byte) (SFlowExtendedVLANTunnelRecord, error) {
+	vt := SFlowExtendedVLANTunnelRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return vt, err
+	}
+	vt.EnterpriseID, vt.Format = SFlowFlowDataFormat(fdf).decode()
+	vt.FlowDataLength = length
+	vt.Stack, err = decodeSFlowUint32Array(&record)
+	return vt, err
+}
+
+// **************************************************
+//  Extended 802.11 Flow Records
+// **************************************************
+
+// See http://sflow.org/sflow_80211.txt
+
+// SFlow80211Version is the 802.11 protocol version of a sampled frame.
+type SFlow80211Version uint32
+
+const (
+	SFlow80211a SFlow80211Version = 1
+	SFlow80211b SFlow80211Version = 2
+	SFlow80211d SFlow80211Version = 3
+	SFlow80211e SFlow80211Version = 4
+	SFlow80211g SFlow80211Version = 5
+	SFlow80211h SFlow80211Version = 6
+	SFlow80211i SFlow80211Version = 7
+	SFlow80211j SFlow80211Version = 8
+)
+
+// SFlowExtended80211PayloadRecord holds the unencrypted payload of a
+// sampled 802.11 frame.
+type SFlowExtended80211PayloadRecord struct {
+	SFlowBaseFlowRecord
+	CipherSuite uint32 // OUI in the 24 most significant bits, then the suite type
+	Data        []byte
+}
+
+func decodeExtended80211PayloadRecord(data *[]byte) (SFlowExtended80211PayloadRecord, error) {
+	pl := SFlowExtended80211PayloadRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return pl, err
+	}
+	pl.EnterpriseID, pl.Format = SFlowFlowDataFormat(fdf).decode()
+	pl.FlowDataLength = length
+	if len(record) < 4 {
+		return pl, errors.New("extended 802.11 payload record too small")
+	}
+	record, pl.CipherSuite = record[4:], binary.BigEndian.Uint32(record[:4])
+	pl.Data, err = decodeSFlowOpaque(&record, length)
+	return pl, err
+}
+
+// decodeSFlow80211Station removes the SSID and BSSID common to 802.11 RX and
+// TX records from data.
+func decodeSFlow80211Station(data *[]byte) (string, net.HardwareAddr, error) {
+	ssid, err := decodeSFlowOpaque(data, 32)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(*data) < 8 {
+		return "", nil, errors.New("sflow 802.11 BSSID too small")
+	}
+	bssid := net.HardwareAddr((*data)[:6])
+	*data = (*data)[8:]
+	return string(ssid), bssid, nil
+}
+
+// SFlowExtended80211RxRecord gives the reception details of a sampled
+// 802.11 frame.
+type SFlowExtended80211RxRecord struct {
+	SFlowBaseFlowRecord
+	SSID           string
+	BSSID          net.HardwareAddr
+	Version        SFlow80211Version
+	Channel        uint32
+	Speed          uint64 // Bits per second
+	RSNI           uint32 // Received signal to noise ratio, see 802.11-2007
+	RCPI           uint32 // Received channel power, see 802.11-2007
+	PacketDuration uint32 // Microseconds
+}
+
+func decodeExtended80211RxRecord(data *[]byte) (SFlowExtended80211RxRecord, error) {
+	rx := SFlowExtended80211RxRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return rx, err
+	}
+	rx.EnterpriseID, rx.Format = SFlowFlowDataFormat(fdf).decode()
+	rx.FlowDataLength = length
+	if rx.SSID, rx.BSSID, err = decodeSFlow80211Station(&record); err != nil {
+		return rx, err
+	}
+	if len(record) < 28 {
+		return rx, errors.New("extended 802.11 RX record too small")
+	}
+	record, rx.Version = record[4:], SFlow80211Version(binary.BigEndian.Uint32(record[:4]))
+	record, rx.Channel = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, rx.Speed = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, rx.RSNI = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, rx.RCPI = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, rx.PacketDuration = record[4:], binary.BigEndian.Uint32(record[:4])
+	return rx, nil
+}
+
+// SFlowExtended80211TxRecord gives the transmission details of a sampled
+// 802.11 frame.
+type SFlowExtended80211TxRecord struct {
+	SFlowBaseFlowRecord
+	SSID            string
+	BSSID           net.HardwareAddr
+	Version         SFlow80211Version
+	Transmissions   uint32 // 0 if unknown, 1 if sent at the first attempt
+	PacketDuration  uint32 // Microseconds
+	RetransDuration uint32 // Microseconds
+	Channel         uint32
+	Speed           uint64 // Bits per second
+	Power           uint32 // Milliwatts
+}
+
+func decodeExtended80211TxRecord(data *[]byte) (SFlowExtended80211TxRecord, error) {
+	tx := SFlowExtended80211TxRecord{}
+	fdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return tx, err
+	}
+	tx.EnterpriseID, tx.Format = SFlowFlowDataFormat(fdf).decode()
+	tx.FlowDataLength = length
+	if tx.SSID, tx.BSSID, err = decodeSFlow80211Station(&record); err != nil {
+		return tx, err
+	}
+	if len(record) < 32 {
+		return tx, errors.New("extended 802.11 TX record too small")
+	}
+	record, tx.Version = record[4:], SFlow80211Version(binary.BigEndian.Uint32(record[:4]))
+	record, tx.Transmissions = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, tx.PacketDuration = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, tx.RetransDuration = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, tx.Channel = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, tx.Speed = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, tx.Power = record[4:], binary.BigEndian.Uint32(record[:4])
+	return tx, nil
+}
+
+// SFlowUnknownCounterRecord is a counter record this decoder does not
+// understand, such as an enterprise specific one, holding its data.
+type SFlowUnknownCounterRecord struct {
+	SFlowBaseCounterRecord
+	Data []byte
+}
+
+func decodeUnknownCounterRecord(data *[]byte) (SFlowUnknownCounterRecord, error) {
+	ucr := SFlowUnknownCounterRecord{}
+	cdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return ucr, err
+	}
+	ucr.EnterpriseID, ucr.Format = SFlowCounterDataFormat(cdf).decode()
+	ucr.FlowDataLength = length
+	ucr.Data = record
+	return ucr, nil
+}
+
+// decodeSFlowCounterRecordData removes a counter record of at least
+// minLength bytes from data, returning its header and its data.
+func decodeSFlowCounterRecordData(data *[]byte, minLength int) (SFlowBaseCounterRecord, []byte, error) {
+	cdf, length, record, err := decodeSFlowRecordData(data)
+	if err != nil {
+		return SFlowBaseCounterRecord{}, nil, err
+	}
+	bcr := SFlowBaseCounterRecord{FlowDataLength: length}
+	bcr.EnterpriseID, bcr.Format = SFlowCounterDataFormat(cdf).decode()
+	if len(record) < minLength {
+		return bcr, nil, fmt.Errorf("%v too small", bcr.Format)
+	}
+	return bcr, record, nil
+}
+
+// **************************************************
+//  802.11 Counter Record
+// **************************************************
+
+// SFlow80211Counters are the 802.11 counters of an interface, see the
+// dot11CountersTable of IEEE 802.11.
+type SFlow80211Counters struct {
+	SFlowBaseCounterRecord
+	TransmittedFragmentCount       uint32
+	MulticastTransmittedFrameCount uint32
+	FailedCount                    uint32
+	RetryCount                     uint32
+	MultipleRetryCount             uint32
+	FrameDuplicateCount            uint32
+	RTSSuccessCount                uint32
+	RTSFailureCount                uint32
+	ACKFailureCount                uint32
+	ReceivedFragmentCount          uint32
+	MulticastReceivedFrameCount    uint32
+	FCSErrorCount                  uint32
+	TransmittedFrameCount          uint32
+	WEPUndecryptableCount          uint32
+	QoSDiscardedFragmentCount      uint32
+	AssociatedStationCount         uint32
+	QoSCFPollsReceivedCount        uint32
+	QoSCFPollsUnusedCount          uint32
+	QoSCFPollsUnusableCount        uint32
+	QoSCFPollsLostCount            uint32
+}
+
+func decode80211Counters(data *[]byte) (SFlow80211Counters, error) {
+	wc := SFlow80211Counters{}
+	var record []byte
+	var err error
+	if wc.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 80); err != nil {
+		return wc, err
+	}
+	for _, c := range []*uint32{
+		&wc.TransmittedFragmentCount, &wc.MulticastTransmittedFrameCount, &wc.FailedCount,
+		&wc.RetryCount, &wc.MultipleRetryCount, &wc.FrameDuplicateCount, &wc.RTSSuccessCount,
+		&wc.RTSFailureCount, &wc.ACKFailureCount, &wc.ReceivedFragmentCount,
+		&wc.MulticastReceivedFrameCount, &wc.FCSErrorCount, &wc.TransmittedFrameCount,
+		&wc.WEPUndecryptableCount, &wc.QoSDiscardedFragmentCount, &wc.AssociatedStationCount,
+		&wc.QoSCFPollsReceivedCount, &wc.QoSCFPollsUnusedCount, &wc.QoSCFPollsUnusableCount,
+		&wc.QoSCFPollsLostCount,
+	} {
+		record, *c = record[4:], binary.BigEndian.Uint32(record[:4])
+	}
+	return wc, nil
+}
+
+// SFlowRadioUtilizationCounters give the use of the channel of an 802.11
+// radio, in milliseconds.
+type SFlowRadioUtilizationCounters struct {
+	SFlowBaseCounterRecord
+	ElapsedTime       uint32
+	OnChannelTime     uint32
+	OnChannelBusyTime uint32
+}
+
+func decodeRadioUtilizationCounters(data *[]byte) (SFlowRadioUtilizationCounters, error) {
+	ru := SFlowRadioUtilizationCounters{}
+	var record []byte
+	var err error
+	if ru.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 12); err != nil {
+		return ru, err
+	}
+	record, ru.ElapsedTime = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, ru.OnChannelTime = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, ru.OnChannelBusyTime = record[4:], binary.BigEndian.Uint32(record[:4])
+	return ru, nil
+}
+
+// **************************************************
+//  Host and Virtual Domain Counter Records
+// **************************************************
+
+// See http://sflow.org/sflow_host.txt.  Physical hosts, hypervisors,
+// virtual machines and containers are described by the host description,
+// adapters and parent records, and their resources by the host counters,
+// or the virtual domain counters for virtual machines and containers.
+
+// SFlowHostDescrCounters describes a host.
+type SFlowHostDescrCounters struct {
+	SFlowBaseCounterRecord
+	Hostname    string
+	UUID        []byte // 16 bytes
+	MachineType uint32 // Processor family, 2 for x86, 3 for x86_64...
+	OSName      uint32 // 2 for Linux, 3 for Windows...
+	OSRelease   string
+}
+
+func decodeHostDescrCounters(data *[]byte) (SFlowHostDescrCounters, error) {
+	hd := SFlowHostDescrCounters{}
+	var record []byte
+	var err error
+	if hd.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 0); err != nil {
+		return hd, err
+	}
+	hostname, err := decodeSFlowOpaque(&record, 64)
+	if err != nil {
+		return hd, err
+	}
+	hd.Hostname = string(hostname)
+	if len(record) < 24 {
+		return hd, errors.New("host description too small")
+	}
+	record, hd.UUID = record[16:], record[:16]
+	record, hd.MachineType = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hd.OSName = record[4:], binary.BigEndian.Uint32(record[:4])
+	release, err := decodeSFlowOpaque(&record, 32)
+	if err != nil {
+		return hd, err
+	}
+	hd.OSRelease = string(release)
+	return hd, nil
+}
+
+// SFlowHostAdapter is a network adapter of a host.
+type SFlowHostAdapter struct {
+	IfIndex      uint32
+	MACAddresses []net.HardwareAddr
+}
+
+// SFlowHostAdaptersCounters lists the network adapters of a host.
+type SFlowHostAdaptersCounters struct {
+	SFlowBaseCounterRecord
+	Adapters []SFlowHostAdapter
+}
+
+func decodeHostAdaptersCounters(data *[]byte) (SFlowHostAdaptersCounters, error) {
+	ha := SFlowHostAdaptersCounters{}
+	var record []byte
+	var err error
+	if ha.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 4); err != nil {
+		return ha, err
+	}
+	var count uint32
+	record, count = record[4:], binary.BigEndian.Uint32(record[:4])
+	for i := uint32(0); i < count; i++ {
+		if len(record) < 8 {
+			return ha, errors.New("host adapter too small")
+		}
+		var a SFlowHostAdapter
+		var macs uint32
+		record, a.IfIndex = record[4:], binary.BigEndian.Uint32(record[:4])
+		record, macs = record[4:], binary.BigEndian.Uint32(record[:4])
+		if uint64(len(record)) < 8*uint64(macs) {
+			return ha, errors.New("host adapter MAC addresses too small")
+		}
+		for j := uint32(0); j < macs; j++ {
+			a.MACAddresses = append(a.MACAddresses, net.HardwareAddr(record[:6]))
+			record = record[8:]
+		}
+		ha.Adapters = append(ha.Adapters, a)
+	}
+	return ha, nil
+}
+
+// SFlowHostParentCounters gives the container, usually a hypervisor, of a
+// virtual host.
+type SFlowHostParentCounters struct {
+	SFlowBaseCounterRecord
+	ContainerType  uint32 // sFlow data source type of the container
+	ContainerIndex uint32 // sFlow data source index of the container
+}
+
+func decodeHostParentCounters(data *[]byte) (SFlowHostParentCounters, error) {
+	hp := SFlowHostParentCounters{}
+	var record []byte
+	var err error
+	if hp.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 8); err != nil {
+		return hp, err
+	}
+	record, hp.ContainerType = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hp.ContainerIndex = record[4:], binary.BigEndian.Uint32(record[:4])
+	return hp, nil
+}
+
+// SFlowHostCPUCounters are the processor counters of a host.  Times are in
+// milliseconds.  CPUSteal, CPUGuest and CPUGuestNice are only sent by recent
+// agents.
+type SFlowHostCPUCounters struct {
+	SFlowBaseCounterRecord
+	LoadOne      float32 // 1 minute load average
+	LoadFive     float32 // 5 minute load average
+	LoadFifteen  float32 // 15 minute load average
+	ProcRun      uint32  // Running processes
+	ProcTotal    uint32
+	CPUNum       uint32
+	CPUSpeed     uint32 // MHz
+	Uptime       uint32 // Seconds
+	CPUUser      uint32
+	CPUNice      uint32
+	CPUSystem    uint32
+	CPUIdle      uint32
+	CPUWio       uint32
+	CPUIntr      uint32
+	CPUSintr     uint32
+	Interrupts   uint32
+	Contexts     uint32 // Context switches
+	CPUSteal     uint32
+	CPUGuest     uint32
+	CPUGuestNice uint32
+}
+
+func decodeHostCPUCounters(data *[]byte) (SFlowHostCPUCounters, error) {
+	hc := SFlowHostCPUCounters{}
+	var record []byte
+	var err error
+	if hc.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 68); err != nil {
+		return hc, err
+	}
+	record, hc.LoadOne = record[4:], math.Float32frombits(binary.BigEndian.Uint32(record[:4]))
+	record, hc.LoadFive = record[4:], math.Float32frombits(binary.BigEndian.Uint32(record[:4]))
+	record, hc.LoadFifteen = record[4:], math.Float32frombits(binary.BigEndian.Uint32(record[:4]))
+	for _, c := range []*uint32{
+		&hc.ProcRun, &hc.ProcTotal, &hc.CPUNum, &hc.CPUSpeed, &hc.Uptime, &hc.CPUUser,
+		&hc.CPUNice, &hc.CPUSystem, &hc.CPUIdle, &hc.CPUWio, &hc.CPUIntr, &hc.CPUSintr,
+		&hc.Interrupts, &hc.Contexts, &hc.CPUSteal, &hc.CPUGuest, &hc.CPUGuestNice,
+	} {
+		if len(record) < 4 {
+			break
+		}
+		record, *c = record[4:], binary.BigEndian.Uint32(record[:4])
+	}
+	return hc, nil
+}
+
+// SFlowHostMemoryCounters are the memory counters of a host, in bytes,
+// and its paging and swapping counts.
+type SFlowHostMemoryCounters struct {
+	SFlowBaseCounterRecord
+	MemTotal   uint64
+	MemFree    uint64
+	MemShared  uint64
+	MemBuffers uint64
+	MemCached  uint64
+	SwapTotal  uint64
+	SwapFree   uint64
+	PageIn     uint32
+	PageOut    uint32
+	SwapIn     uint32
+	SwapOut    uint32
+}
+
+func decodeHostMemoryCounters(data *[]byte) (SFlowHostMemoryCounters, error) {
+	hm := SFlowHostMemoryCounters{}
+	var record []byte
+	var err error
+	if hm.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 72); err != nil {
+		return hm, err
+	}
+	record, hm.MemTotal = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hm.MemFree = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hm.MemShared = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hm.MemBuffers = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hm.MemCached = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hm.SwapTotal = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hm.SwapFree = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hm.PageIn = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hm.PageOut = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hm.SwapIn = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hm.SwapOut = record[4:], binary.BigEndian.Uint32(record[:4])
+	return hm, nil
+}
+
+// SFlowHostDiskIOCounters are the disk counters of a host.  Times are in
+// milliseconds.
+type SFlowHostDiskIOCounters struct {
+	SFlowBaseCounterRecord
+	DiskTotal    uint64 // Bytes
+	DiskFree     uint64 // Bytes
+	PartMaxUsed  uint32 // Utilization of the fullest partition, in hundredths of a percent
+	Reads        uint32
+	BytesRead    uint64
+	ReadTime     uint32
+	Writes       uint32
+	BytesWritten uint64
+	WriteTime    uint32
+}
+
+func decodeHostDiskIOCounters(data *[]byte) (SFlowHostDiskIOCounters, error) {
+	hd := SFlowHostDiskIOCounters{}
+	var record []byte
+	var err error
+	if hd.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 52); err != nil {
+		return hd, err
+	}
+	record, hd.DiskTotal = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hd.DiskFree = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hd.PartMaxUsed = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hd.Reads = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hd.BytesRead = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hd.ReadTime = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hd.Writes = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hd.BytesWritten = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hd.WriteTime = record[4:], binary.BigEndian.Uint32(record[:4])
+	return hd, nil
+}
+
+// SFlowHostNetIOCounters are the network counters of a host, summed over
+// its adapters.
+type SFlowHostNetIOCounters struct {
+	SFlowBaseCounterRecord
+	BytesIn  uint64
+	PktsIn   uint32
+	ErrsIn   uint32
+	DropsIn  uint32
+	BytesOut uint64
+	PktsOut  uint32
+	ErrsOut  uint32
+	DropsOut uint32
+}
+
+func decodeHostNetIOCounters(data *[]byte) (SFlowHostNetIOCounters, error) {
+	hn := SFlowHostNetIOCounters{}
+	var record []byte
+	var err error
+	if hn.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 40); err != nil {
+		return hn, err
+	}
+	record, hn.BytesIn = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hn.PktsIn = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hn.ErrsIn = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hn.DropsIn = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hn.BytesOut = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, hn.PktsOut = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hn.ErrsOut = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, hn.DropsOut = record[4:], binary.BigEndian.Uint32(record[:4])
+	return hn, nil
+}
+
+// SFlowVirtNodeCounters are the counters of a hypervisor.
+type SFlowVirtNodeCounters struct {
+	SFlowBaseCounterRecord
+	MHz        uint32 // Expected CPU frequency
+	CPUs       uint32 // Active CPUs
+	Memory     uint64 // Bytes
+	MemoryFree uint64 // Bytes
+	NumDomains uint32 // Active domains
+}
+
+func decodeVirtNodeCounters(data *[]byte) (SFlowVirtNodeCounters, error) {
+	vn := SFlowVirtNodeCounters{}
+	var record []byte
+	var err error
+	if vn.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 28); err != nil {
+		return vn, err
+	}
+	record, vn.MHz = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vn.CPUs = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vn.Memory = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vn.MemoryFree = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vn.NumDomains = record[4:], binary.BigEndian.Uint32(record[:4])
+	return vn, nil
+}
+
+// SFlowVirtCPUCounters are the processor counters of a virtual machine or
+// container.
+type SFlowVirtCPUCounters struct {
+	SFlowBaseCounterRecord
+	State     uint32 // virDomainState, 1 for running
+	CPUTime   uint32 // Milliseconds
+	NrVirtCPU uint32 // Virtual CPUs
+}
+
+func decodeVirtCPUCounters(data *[]byte) (SFlowVirtCPUCounters, error) {
+	vc := SFlowVirtCPUCounters{}
+	var record []byte
+	var err error
+	if vc.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 12); err != nil {
+		return vc, err
+	}
+	record, vc.State = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vc.CPUTime = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vc.NrVirtCPU = record[4:], binary.BigEndian.Uint32(record[:4])
+	return vc, nil
+}
+
+// SFlowVirtMemoryCounters are the memory counters of a virtual machine or
+// container, in bytes.
+type SFlowVirtMemoryCounters struct {
+	SFlowBaseCounterRecord
+	Memory    uint64
+	MaxMemory uint64
+}
+
+func decodeVirtMemoryCounters(data *[]byte) (SFlowVirtMemoryCounters, error) {
+	vm := SFlowVirtMemoryCounters{}
+	var record []byte
+	var err error
+	if vm.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 16); err != nil {
+		return vm, err
+	}
+	record, vm.Memory = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vm.MaxMemory = record[8:], binary.BigEndian.Uint64(record[:8])
+	return vm, nil
+}
+
+// SFlowVirtDiskIOCounters are the disk counters of a virtual machine or
+// container.
+type SFlowVirtDiskIOCounters struct {
+	SFlowBaseCounterRecord
+	Capacity   uint64 // Bytes
+	Allocation uint64 // Bytes
+	Available  uint64 // Bytes
+	RdReq      uint32
+	RdBytes    uint64
+	WrReq      uint32
+	WrBytes    uint64
+	Errs       uint32
+}
+
+func decodeVirtDiskIOCounters(data *[]byte) (SFlowVirtDiskIOCounters, error) {
+	vd := SFlowVirtDiskIOCounters{}
+	var record []byte
+	var err error
+	if vd.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 52); err != nil {
+		return vd, err
+	}
+	record, vd.Capacity = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vd.Allocation = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vd.Available = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vd.RdReq = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vd.RdBytes = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vd.WrReq = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vd.WrBytes = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vd.Errs = record[4:], binary.BigEndian.Uint32(record[:4])
+	return vd, nil
+}
+
+// SFlowVirtNetIOCounters are the network counters of a virtual machine or
+// container.
+type SFlowVirtNetIOCounters struct {
+	SFlowBaseCounterRecord
+	RxBytes   uint64
+	RxPackets uint32
+	RxErrs    uint32
+	RxDrop    uint32
+	TxBytes   uint64
+	TxPackets uint32
+	TxErrs    uint32
+	TxDrop    uint32
+}
+
+func decodeVirtNetIOCounters(data *[]byte) (SFlowVirtNetIOCounters, error) {
+	vn := SFlowVirtNetIOCounters{}
+	var record []byte
+	var err error
+	if vn.SFlowBaseCounterRecord, record, err = decodeSFlowCounterRecordData(data, 40); err != nil {
+		return vn, err
+	}
+	record, vn.RxBytes = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vn.RxPackets = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vn.RxErrs = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vn.RxDrop = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vn.TxBytes = record[8:], binary.BigEndian.Uint64(record[:8])
+	record, vn.TxPackets = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vn.TxErrs = record[4:], binary.BigEndian.Uint32(record[:4])
+	record, vn.TxDrop = record[4:], binary.BigEndian.Uint32(record[:4])
+	return vn, nil
+}
@@ -404,6 +404,85 @@ var SFlowTestPacket13 = []byte{
 	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a, 0x3b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 }
 
+// SFlowTestPacketExtendedFlows is an sFlow datagram with a flow sample
+// holding extended MPLS, NAT, MPLS tunnel, VLAN tunnel and 802.11 records,
+// an enterprise specific record and an 802.11 aggregation record.
+var SFlowTestPacketExtendedFlows = []byte{
+	0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x64, 0x00, 0x01, 0xe2, 0x40, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01,
+	0x00, 0x00, 0x01, 0x28, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x0c, 0x00, 0x00, 0x03, 0xe8,
+	0x00, 0x03, 0x0d, 0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x3f, 0xff, 0xff, 0xff,
+	0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x03, 0xee, 0x00, 0x00, 0x00, 0x1c, 0x00, 0x00, 0x00, 0x01,
+	0x0a, 0x01, 0x01, 0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x01, 0x01, 0x40, 0x00, 0x0c, 0x81, 0x41,
+	0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0xf1, 0xff, 0x00, 0x00, 0x03, 0xef, 0x00, 0x00, 0x00, 0x1c,
+	0x00, 0x00, 0x00, 0x01, 0xc0, 0x00, 0x02, 0x0a, 0x00, 0x00, 0x00, 0x02, 0x20, 0x01, 0x0d, 0xb8,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x03, 0xf0,
+	0x00, 0x00, 0x00, 0x14, 0x00, 0x00, 0x00, 0x08, 0x6c, 0x73, 0x70, 0x2d, 0x65, 0x61, 0x73, 0x74,
+	0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x03, 0xf4, 0x00, 0x00, 0x00, 0x0c,
+	0x00, 0x00, 0x00, 0x02, 0x88, 0xa8, 0x00, 0x64, 0x81, 0x00, 0x00, 0xc8, 0x00, 0x00, 0x03, 0xf6,
+	0x00, 0x00, 0x00, 0x30, 0x00, 0x00, 0x00, 0x06, 0x6f, 0x66, 0x66, 0x69, 0x63, 0x65, 0x00, 0x00,
+	0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x06,
+	0x00, 0x00, 0x00, 0x00, 0x03, 0x37, 0xf9, 0x80, 0x00, 0x00, 0x00, 0x28, 0x00, 0x00, 0x00, 0xb4,
+	0x00, 0x00, 0x00, 0x70, 0x00, 0x00, 0x03, 0xf7, 0x00, 0x00, 0x00, 0x34, 0x00, 0x00, 0x00, 0x06,
+	0x6f, 0x66, 0x66, 0x69, 0x63, 0x65, 0x00, 0x00, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x70, 0x00, 0x00, 0x00, 0xdc,
+	0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00, 0x00, 0x03, 0x37, 0xf9, 0x80, 0x00, 0x00, 0x00, 0x64,
+	0x01, 0x13, 0xd0, 0x05, 0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02,
+	0x00, 0x00, 0x03, 0xf8, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00,
+}
+
+// SFlowTestPacketExtendedCounters is an sFlow datagram with a counter
+// sample holding 802.11, radio utilization, host and virtual domain counters,
+// and an enterprise specific record.
+var SFlowTestPacketExtendedCounters = []byte{
+	0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x01, 0x0a, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x65, 0x00, 0x01, 0xe2, 0x40, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02,
+	0x00, 0x00, 0x02, 0xb4, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x0f,
+	0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00, 0x50, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02,
+	0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x06,
+	0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 0x0a,
+	0x00, 0x00, 0x00, 0x0b, 0x00, 0x00, 0x00, 0x0c, 0x00, 0x00, 0x00, 0x0d, 0x00, 0x00, 0x00, 0x0e,
+	0x00, 0x00, 0x00, 0x0f, 0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x11, 0x00, 0x00, 0x00, 0x12,
+	0x00, 0x00, 0x00, 0x13, 0x00, 0x00, 0x00, 0x14, 0x00, 0x00, 0x03, 0xea, 0x00, 0x00, 0x00, 0x0c,
+	0x00, 0x00, 0x03, 0xe8, 0x00, 0x00, 0x03, 0x84, 0x00, 0x00, 0x01, 0x2c, 0x00, 0x00, 0x07, 0xd0,
+	0x00, 0x00, 0x00, 0x30, 0x00, 0x00, 0x00, 0x06, 0x77, 0x65, 0x62, 0x2d, 0x30, 0x31, 0x00, 0x00,
+	0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+	0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x06, 0x35, 0x2e, 0x31, 0x35,
+	0x2e, 0x30, 0x00, 0x00, 0x00, 0x00, 0x07, 0xd1, 0x00, 0x00, 0x00, 0x1c, 0x00, 0x00, 0x00, 0x02,
+	0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x0c, 0x29, 0x00, 0x00, 0x01, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x07, 0xd2, 0x00, 0x00, 0x00, 0x08,
+	0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x07, 0xd3, 0x00, 0x00, 0x00, 0x44,
+	0x3f, 0x00, 0x00, 0x00, 0x3e, 0x80, 0x00, 0x00, 0x3e, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+	0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x05,
+	0x00, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x09,
+	0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x0b, 0x00, 0x00, 0x00, 0x0c, 0x00, 0x00, 0x00, 0x0d,
+	0x00, 0x00, 0x00, 0x0e, 0x00, 0x00, 0x07, 0xd4, 0x00, 0x00, 0x00, 0x48, 0x00, 0x00, 0x00, 0x02,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x30, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x20, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x14, 0x00, 0x00, 0x00, 0x1e,
+	0x00, 0x00, 0x00, 0x28, 0x00, 0x00, 0x07, 0xd5, 0x00, 0x00, 0x00, 0x34, 0x00, 0x00, 0x00, 0x19,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1d, 0x7e,
+	0x00, 0x00, 0x00, 0x0b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x0c,
+	0x00, 0x00, 0x00, 0x0d, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00, 0x0e,
+	0x00, 0x00, 0x07, 0xd6, 0x00, 0x00, 0x00, 0x28, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0xe8,
+	0x00, 0x00, 0x00, 0x0a, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x07, 0xd0, 0x00, 0x00, 0x00, 0x14, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x04,
+	0x00, 0x00, 0x08, 0x34, 0x00, 0x00, 0x00, 0x1c, 0x00, 0x00, 0x09, 0x60, 0x00, 0x00, 0x00, 0x08,
+	0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x08, 0x35, 0x00, 0x00, 0x00, 0x0c, 0x00, 0x00, 0x00, 0x01,
+	0x00, 0x00, 0x13, 0x88, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x08, 0x36, 0x00, 0x00, 0x00, 0x10,
+	0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x08, 0x37, 0x00, 0x00, 0x00, 0x34, 0x00, 0x00, 0x00, 0x02, 0x80, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x01, 0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x02,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x08, 0x38,
+	0x00, 0x00, 0x00, 0x28, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0b, 0xb8, 0x00, 0x00, 0x00, 0x1e,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0f, 0xa0,
+	0x00, 0x00, 0x00, 0x28, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x01, 0x13, 0xd0, 0x01,
+	0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x2a,
+}
+
 func TestDecodeUDPSFlow(t *testing.T) {
 	p := gopacket.NewPacket(SFlowTestPacket1, LayerTypeEthernet, gopacket.Default)
 	if p.ErrorLayer() != nil {
@@ -1875,6 +1954,272 @@ func TestDecodeAppResourcesOvsdbCounter(t *testing.T) {
 	}
 }
 
+func TestDecodeExtendedFlowRecords(t *testing.T) {
+	p := gopacket.NewPacket(SFlowTestPacketExtendedFlows, LayerTypeSFlow, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeSFlow}, t)
+	got := p.ApplicationLayer().(*SFlowDatagram)
+	if len(got.FlowSamples) != 1 {
+		t.Fatalf("got %d flow samples, want 1", len(got.FlowSamples))
+	}
+
+	bssid := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+	want := []SFlowRecord{
+		SFlowExtendedMPLSRecord{
+			SFlowBaseFlowRecord: SFlowBaseFlowRecord{Format: SFlowTypeExtendedMlpsFlow, FlowDataLength: 28},
+			NextHop:             net.IP{10, 1, 1, 1},
+			InLabels:            []uint32{0x00010140, 0x000c8141},
+			OutLabels:           []uint32{0x0001f1ff},
+		},
+		SFlowExtendedNATRecord{
+			SFlowBaseFlowRecord: SFlowBaseFlowRecord{Format: SFlowTypeExtendedNatFlow, FlowDataLength: 28},
+			SourceAddress:       net.IP{192, 0, 2, 10},
+			DestinationAddress:  net.ParseIP("2001:db8::1"),
+		},
+		SFlowExtendedMPLSTunnelRecord{
+			SFlowBaseFlowRecord: SFlowBaseFlowRecord{Format: SFlowTypeExtendedMlpsTunnelFlow, FlowDataLength: 20},
+			TunnelLSPName:       "lsp-east",
+			TunnelID:            7,
+			TunnelCOS:           3,
+		},
+		SFlowExtendedVLANTunnelRecord{
+			SFlowBaseFlowRecord: SFlowBaseFlowRecord{Format: SFlowTypeExtendedVlanFlow, FlowDataLength: 12},
+			Stack:               []uint32{0x88a80064, 0x810000c8},
+		},
+		SFlowExtended80211RxRecord{
+			SFlowBaseFlowRecord: SFlowBaseFlowRecord{Format: SFlowTypeExtended80211RxFlow, FlowDataLength: 48},
+			SSID:                "office",
+			BSSID:               bssid,
+			Version:             SFlow80211g,
+			Channel:             6,
+			Speed:               54000000,
+			RSNI:                40,
+			RCPI:                180,
+			PacketDuration:      112,
+		},
+		SFlowExtended80211TxRecord{
+			SFlowBaseFlowRecord: SFlowBaseFlowRecord{Format: SFlowTypeExtended80211TxFlow, FlowDataLength: 52},
+			SSID:                "office",
+			BSSID:               bssid,
+			Version:             SFlow80211g,
+			Transmissions:       2,
+			PacketDuration:      112,
+			RetransDuration:     220,
+			Channel:             6,
+			Speed:               54000000,
+			Power:               100,
+		},
+		SFlowUnknownFlowRecord{
+			SFlowBaseFlowRecord: SFlowBaseFlowRecord{EnterpriseID: 4413, Format: 5, FlowDataLength: 8},
+			Data:                []byte{0, 0, 0, 1, 0, 0, 0, 2},
+		},
+		SFlowUnknownFlowRecord{
+			SFlowBaseFlowRecord: SFlowBaseFlowRecord{Format: SFlowTypeExtended80211AggregationFlow, FlowDataLength: 4},
+			Data:                []byte{0, 0, 0, 0},
+		},
+	}
+	if !reflect.DeepEqual(want, got.FlowSamples[0].Records) {
+		t.Errorf("SFlow records mismatch, \nwant:\n\n%#v\ngot:\n\n\n%#v\n\n", want, got.FlowSamples[0].Records)
+	}
+}
+
+func TestDecodeExtendedCounterRecords(t *testing.T) {
+	p := gopacket.NewPacket(SFlowTestPacketExtendedCounters, LayerTypeSFlow, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeSFlow}, t)
+	got := p.ApplicationLayer().(*SFlowDatagram)
+	if len(got.CounterSamples) != 1 {
+		t.Fatalf("got %d counter samples, want 1", len(got.CounterSamples))
+	}
+
+	want := []SFlowRecord{
+		SFlow80211Counters{
+			SFlowBaseCounterRecord:         SFlowBaseCounterRecord{Format: SFlowType80211Counters, FlowDataLength: 80},
+			TransmittedFragmentCount:       1,
+			MulticastTransmittedFrameCount: 2,
+			FailedCount:                    3,
+			RetryCount:                     4,
+			MultipleRetryCount:             5,
+			FrameDuplicateCount:            6,
+			RTSSuccessCount:                7,
+			RTSFailureCount:                8,
+			ACKFailureCount:                9,
+			ReceivedFragmentCount:          10,
+			MulticastReceivedFrameCount:    11,
+			FCSErrorCount:                  12,
+			TransmittedFrameCount:          13,
+			WEPUndecryptableCount:          14,
+			QoSDiscardedFragmentCount:      15,
+			AssociatedStationCount:         16,
+			QoSCFPollsReceivedCount:        17,
+			QoSCFPollsUnusedCount:          18,
+			QoSCFPollsUnusableCount:        19,
+			QoSCFPollsLostCount:            20,
+		},
+		SFlowRadioUtilizationCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeRadioUtilizationCounters, FlowDataLength: 12},
+			ElapsedTime:            1000,
+			OnChannelTime:          900,
+			OnChannelBusyTime:      300,
+		},
+		SFlowHostDescrCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeHostDescrCounters, FlowDataLength: 48},
+			Hostname:               "web-01",
+			UUID:                   []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+			MachineType:            3,
+			OSName:                 2,
+			OSRelease:              "5.15.0",
+		},
+		SFlowHostAdaptersCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeHostAdaptersCounters, FlowDataLength: 28},
+			Adapters: []SFlowHostAdapter{
+				{IfIndex: 1, MACAddresses: []net.HardwareAddr{{0x00, 0x0c, 0x29, 0x00, 0x00, 0x01}}},
+				{IfIndex: 2},
+			},
+		},
+		SFlowHostParentCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeHostParentCounters, FlowDataLength: 8},
+			ContainerType:          2,
+			ContainerIndex:         7,
+		},
+		SFlowHostCPUCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeHostCPUCounters, FlowDataLength: 68},
+			LoadOne:                0.5,
+			LoadFive:               0.25,
+			LoadFifteen:            0.125,
+			ProcRun:                1,
+			ProcTotal:              2,
+			CPUNum:                 3,
+			CPUSpeed:               4,
+			Uptime:                 5,
+			CPUUser:                6,
+			CPUNice:                7,
+			CPUSystem:              8,
+			CPUIdle:                9,
+			CPUWio:                 10,
+			CPUIntr:                11,
+			CPUSintr:               12,
+			Interrupts:             13,
+			Contexts:               14,
+		},
+		SFlowHostMemoryCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeHostMemoryCounters, FlowDataLength: 72},
+			MemTotal:               8 << 30,
+			MemFree:                4 << 30,
+			MemShared:              1 << 20,
+			MemBuffers:             2 << 20,
+			MemCached:              3 << 20,
+			SwapTotal:              1 << 30,
+			SwapFree:               1 << 29,
+			PageIn:                 10,
+			PageOut:                20,
+			SwapIn:                 30,
+			SwapOut:                40,
+		},
+		SFlowHostDiskIOCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeHostDiskIOCounters, FlowDataLength: 52},
+			DiskTotal:              100 << 30,
+			DiskFree:               50 << 30,
+			PartMaxUsed:            7550,
+			Reads:                  11,
+			BytesRead:              4096,
+			ReadTime:               12,
+			Writes:                 13,
+			BytesWritten:           8192,
+			WriteTime:              14,
+		},
+		SFlowHostNetIOCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeHostNetIOCounters, FlowDataLength: 40},
+			BytesIn:                1000,
+			PktsIn:                 10,
+			ErrsIn:                 1,
+			DropsIn:                2,
+			BytesOut:               2000,
+			PktsOut:                20,
+			ErrsOut:                3,
+			DropsOut:               4,
+		},
+		SFlowVirtNodeCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeVirtNodeCounters, FlowDataLength: 28},
+			MHz:                    2400,
+			CPUs:                   8,
+			Memory:                 16 << 30,
+			MemoryFree:             8 << 30,
+			NumDomains:             3,
+		},
+		SFlowVirtCPUCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeVirtCPUCounters, FlowDataLength: 12},
+			State:                  1,
+			CPUTime:                5000,
+			NrVirtCPU:              2,
+		},
+		SFlowVirtMemoryCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeVirtMemoryCounters, FlowDataLength: 16},
+			Memory:                 1 << 30,
+			MaxMemory:              2 << 30,
+		},
+		SFlowVirtDiskIOCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeVirtDiskIOCounters, FlowDataLength: 52},
+			Capacity:               10 << 30,
+			Allocation:             5 << 30,
+			Available:              4 << 30,
+			RdReq:                  1,
+			RdBytes:                512,
+			WrReq:                  2,
+			WrBytes:                1024,
+		},
+		SFlowVirtNetIOCounters{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{Format: SFlowTypeVirtNetIOCounters, FlowDataLength: 40},
+			RxBytes:                3000,
+			RxPackets:              30,
+			RxDrop:                 1,
+			TxBytes:                4000,
+			TxPackets:              40,
+			TxDrop:                 2,
+		},
+		SFlowUnknownCounterRecord{
+			SFlowBaseCounterRecord: SFlowBaseCounterRecord{EnterpriseID: 4413, Format: 1, FlowDataLength: 4},
+			Data:                   []byte{0, 0, 0, 42},
+		},
+	}
+	if !reflect.DeepEqual(want, got.CounterSamples[0].Records) {
+		t.Errorf("SFlow records mismatch, \nwant:\n\n%#v\ngot:\n\n\n%#v\n\n", want, got.CounterSamples[0].Records)
+	}
+}
+
+func TestDecodeExtendedRecordsInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		data   []byte
+		offset int
+		value  byte
+	}{
+		{"record length too large", SFlowTestPacketExtendedFlows, 74, 1},
+		{"invalid next hop type", SFlowTestPacketExtendedFlows, 79, 3},
+		{"label stack too large", SFlowTestPacketExtendedFlows, 87, 9},
+		{"SSID too long", SFlowTestPacketExtendedFlows, 199, 33},
+		{"counters too small", SFlowTestPacketExtendedCounters, 55, 76},
+	} {
+		data := append([]byte{}, test.data...)
+		data[test.offset] = test.value
+		var s SFlowDatagram
+		if err := s.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", test.name)
+		}
+	}
+}
+
+func TestLACPPortState(t *testing.T) {
+	ps := SFLLACPPortState{PortStateAll: 0x053d0f3f}
+	if ps.ActorAdmin() != 0x05 || ps.ActorOper() != 0x3d || ps.PartnerAdmin() != 0x0f || ps.PartnerOper() != 0x3f {
+		t.Errorf("unexpected port states %#x %#x %#x %#x", ps.ActorAdmin(), ps.ActorOper(), ps.PartnerAdmin(), ps.PartnerOper())
+	}
+}
+
 func BenchmarkDecodeSFlowPacket1(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		gopacket.NewPacket(SFlowTestPacket1, LinkTypeEthernet, gopacket.NoCopy)
@@ -0,0 +1,749 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/google/gopacket"
+)
+
+// SNMPVersion is the version of an SNMP message, as encoded in it.
+type SNMPVersion int
+
+// SNMPVersion known values
+const (
+	SNMPv1  SNMPVersion = 0
+	SNMPv2c SNMPVersion = 1
+	SNMPv3  SNMPVersion = 3
+)
+
+func (v SNMPVersion) String() string {
+	switch v {
+	case SNMPv1:
+		return "SNMPv1"
+	case SNMPv2c:
+		return "SNMPv2c"
+	case SNMPv3:
+		return "SNMPv3"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int(v))
+	}
+}
+
+// SNMPPDUType is the type of an SNMP PDU, its BER tag.
+type SNMPPDUType uint8
+
+// SNMPPDUType known values
+const (
+	SNMPGetRequest     SNMPPDUType = 0xa0
+	SNMPGetNextRequest SNMPPDUType = 0xa1
+	SNMPResponse       SNMPPDUType = 0xa2 // GetResponse in SNMPv1
+	SNMPSetRequest     SNMPPDUType = 0xa3
+	SNMPTrapV1         SNMPPDUType = 0xa4
+	SNMPGetBulkRequest SNMPPDUType = 0xa5
+	SNMPInformRequest  SNMPPDUType = 0xa6
+	SNMPTrapV2         SNMPPDUType = 0xa7
+	SNMPReport         SNMPPDUType = 0xa8
+)
+
+func (t SNMPPDUType) String() string {
+	switch t {
+	case SNMPGetRequest:
+		return "GetRequest"
+	case SNMPGetNextRequest:
+		return "GetNextRequest"
+	case SNMPResponse:
+		return "Response"
+	case SNMPSetRequest:
+		return "SetRequest"
+	case SNMPTrapV1:
+		return "Trap"
+	case SNMPGetBulkRequest:
+		return "GetBulkRequest"
+	case SNMPInformRequest:
+		return "InformRequest"
+	case SNMPTrapV2:
+		return "SNMPv2-Trap"
+	case SNMPReport:
+		return "Report"
+	default:
+		return fmt.Sprintf("Unknown(%#x)", uint8(t))
+	}
+}
+
+// SNMPErrorStatus is the error status of an SNMP response.
+type SNMPErrorStatus int32
+
+// SNMPErrorStatus known values, see RFC 3416, section 3
+const (
+	SNMPNoError             SNMPErrorStatus = 0
+	SNMPTooBig              SNMPErrorStatus = 1
+	SNMPNoSuchName          SNMPErrorStatus = 2
+	SNMPBadValue            SNMPErrorStatus = 3
+	SNMPReadOnly            SNMPErrorStatus = 4
+	SNMPGenErr              SNMPErrorStatus = 5
+	SNMPNoAccess            SNMPErrorStatus = 6
+	SNMPWrongType           SNMPErrorStatus = 7
+	SNMPWrongLength         SNMPErrorStatus = 8
+	SNMPWrongEncoding       SNMPErrorStatus = 9
+	SNMPWrongValue          SNMPErrorStatus = 10
+	SNMPNoCreation          SNMPErrorStatus = 11
+	SNMPInconsistentValue   SNMPErrorStatus = 12
+	SNMPResourceUnavailable SNMPErrorStatus = 13
+	SNMPCommitFailed        SNMPErrorStatus = 14
+	SNMPUndoFailed          SNMPErrorStatus = 15
+	SNMPAuthorizationError  SNMPErrorStatus = 16
+	SNMPNotWritable         SNMPErrorStatus = 17
+	SNMPInconsistentName    SNMPErrorStatus = 18
+)
+
+func (s SNMPErrorStatus) String() string {
+	switch s {
+	case SNMPNoError:
+		return "noError"
+	case SNMPTooBig:
+		return "tooBig"
+	case SNMPNoSuchName:
+		return "noSuchName"
+	case SNMPBadValue:
+		return "badValue"
+	case SNMPReadOnly:
+		return "readOnly"
+	case SNMPGenErr:
+		return "genErr"
+	case SNMPNoAccess:
+		return "noAccess"
+	case SNMPWrongType:
+		return "wrongType"
+	case SNMPWrongLength:
+		return "wrongLength"
+	case SNMPWrongEncoding:
+		return "wrongEncoding"
+	case SNMPWrongValue:
+		return "wrongValue"
+	case SNMPNoCreation:
+		return "noCreation"
+	case SNMPInconsistentValue:
+		return "inconsistentValue"
+	case SNMPResourceUnavailable:
+		return "resourceUnavailable"
+	case SNMPCommitFailed:
+		return "commitFailed"
+	case SNMPUndoFailed:
+		return "undoFailed"
+	case SNMPAuthorizationError:
+		return "authorizationError"
+	case SNMPNotWritable:
+		return "notWritable"
+	case SNMPInconsistentName:
+		return "inconsistentName"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int32(s))
+	}
+}
+
+// SNMPGenericTrap is the generic trap type of an SNMPv1 trap.
+type SNMPGenericTrap int32
+
+// SNMPGenericTrap known values
+const (
+	SNMPColdStart             SNMPGenericTrap = 0
+	SNMPWarmStart             SNMPGenericTrap = 1
+	SNMPLinkDown              SNMPGenericTrap = 2
+	SNMPLinkUp                SNMPGenericTrap = 3
+	SNMPAuthenticationFailure SNMPGenericTrap = 4
+	SNMPEGPNeighborLoss       SNMPGenericTrap = 5
+	SNMPEnterpriseSpecific    SNMPGenericTrap = 6
+)
+
+func (t SNMPGenericTrap) String() string {
+	switch t {
+	case SNMPColdStart:
+		return "coldStart"
+	case SNMPWarmStart:
+		return "warmStart"
+	case SNMPLinkDown:
+		return "linkDown"
+	case SNMPLinkUp:
+		return "linkUp"
+	case SNMPAuthenticationFailure:
+		return "authenticationFailure"
+	case SNMPEGPNeighborLoss:
+		return "egpNeighborLoss"
+	case SNMPEnterpriseSpecific:
+		return "enterpriseSpecific"
+	default:
+		return fmt.Sprintf("Unknown(%d)", int32(t))
+	}
+}
+
+// SNMPValueType is the type of the value of a variable binding, its BER
+// tag.
+type SNMPValueType uint8
+
+// SNMPValueType known values, see RFC 2578 and RFC 3416
+const (
+	SNMPInteger        SNMPValueType = 0x02
+	SNMPOctetString    SNMPValueType = 0x04
+	SNMPNull           SNMPValueType = 0x05
+	SNMPObjectID       SNMPValueType = 0x06
+	SNMPIPAddress      SNMPValueType = 0x40
+	SNMPCounter32      SNMPValueType = 0x41
+	SNMPGauge32        SNMPValueType = 0x42
+	SNMPTimeTicks      SNMPValueType = 0x43
+	SNMPOpaque         SNMPValueType = 0x44
+	SNMPCounter64      SNMPValueType = 0x46
+	SNMPNoSuchObject   SNMPValueType = 0x80
+	SNMPNoSuchInstance SNMPValueType = 0x81
+	SNMPEndOfMIBView   SNMPValueType = 0x82
+)
+
+func (t SNMPValueType) String() string {
+	switch t {
+	case SNMPInteger:
+		return "INTEGER"
+	case SNMPOctetString:
+		return "OCTET STRING"
+	case SNMPNull:
+		return "NULL"
+	case SNMPObjectID:
+		return "OBJECT IDENTIFIER"
+	case SNMPIPAddress:
+		return "IpAddress"
+	case SNMPCounter32:
+		return "Counter32"
+	case SNMPGauge32:
+		return "Gauge32"
+	case SNMPTimeTicks:
+		return "TimeTicks"
+	case SNMPOpaque:
+		return "Opaque"
+	case SNMPCounter64:
+		return "Counter64"
+	case SNMPNoSuchObject:
+		return "noSuchObject"
+	case SNMPNoSuchInstance:
+		return "noSuchInstance"
+	case SNMPEndOfMIBView:
+		return "endOfMibView"
+	default:
+		return fmt.Sprintf("Unknown(%#x)", uint8(t))
+	}
+}
+
+// SNMPSecurityModel is the security model of an SNMPv3 message.
+type SNMPSecurityModel int32
+
+// SNMPSecurityModel known values
+const (
+	SNMPSecurityModelSNMPv1 SNMPSecurityModel = 1
+	SNMPSecurityModelSNMPv2 SNMPSecurityModel = 2
+	SNMPSecurityModelUSM    SNMPSecurityModel = 3
+	SNMPSecurityModelTSM    SNMPSecurityModel = 4
+)
+
+// SNMPv3Flags are the flags of an SNMPv3 message.
+type SNMPv3Flags uint8
+
+// SNMPv3Flags known values
+const (
+	SNMPv3FlagAuth       SNMPv3Flags = 0x01
+	SNMPv3FlagPriv       SNMPv3Flags = 0x02
+	SNMPv3FlagReportable SNMPv3Flags = 0x04
+)
+
+// BER tags of the universal types used by SNMP messages.
+const (
+	snmpTagSequence = 0x30
+)
+
+// SNMPObjectIdentifier is an ASN.1 object identifier, such as the name of
+// an SNMP variable.
+type SNMPObjectIdentifier []uint32
+
+// String returns the object identifier in dotted notation.
+func (o SNMPObjectIdentifier) String() string {
+	s := make([]string, len(o))
+	for i, n := range o {
+		s[i] = strconv.FormatUint(uint64(n), 10)
+	}
+	return strings.Join(s, ".")
+}
+
+// HasPrefix returns whether o is in the subtree of prefix.
+func (o SNMPObjectIdentifier) HasPrefix(prefix SNMPObjectIdentifier) bool {
+	if len(o) < len(prefix) {
+		return false
+	}
+	for i, n := range prefix {
+		if o[i] != n {
+			return false
+		}
+	}
+	return true
+}
+
+// SNMPVarBind is a variable binding of an SNMP PDU.  Value holds the
+// contents of the BER encoding of the value.
+type SNMPVarBind struct {
+	Name  SNMPObjectIdentifier
+	Type  SNMPValueType
+	Value []byte
+}
+
+// Decode returns the value of the variable binding: an int64 for integers,
+// a uint64 for counters, gauges and time ticks, a []byte for octet strings
+// and opaque values, an SNMPObjectIdentifier, a net.IP, or nil for null
+// values and exceptions.
+func (v *SNMPVarBind) Decode() (interface{}, error) {
+	switch v.Type {
+	case SNMPInteger:
+		return snmpBERInt(v.Value)
+	case SNMPCounter32, SNMPGauge32, SNMPTimeTicks, SNMPCounter64:
+		n, err := snmpBERUint(v.Value)
+		if err == nil && v.Type != SNMPCounter64 && n > 0xffffffff {
+			err = fmt.Errorf("SNMP %v value %d too large", v.Type, n)
+		}
+		return n, err
+	case SNMPOctetString, SNMPOpaque:
+		return v.Value, nil
+	case SNMPObjectID:
+		return decodeSNMPObjectIdentifier(v.Value)
+	case SNMPIPAddress:
+		if len(v.Value) != net.IPv4len {
+			return nil, fmt.Errorf("invalid SNMP IpAddress length %d", len(v.Value))
+		}
+		return net.IP(v.Value), nil
+	case SNMPNull, SNMPNoSuchObject, SNMPNoSuchInstance, SNMPEndOfMIBView:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown SNMP value type %v", v.Type)
+	}
+}
+
+// SNMPPDU is the PDU of an SNMP message.
+type SNMPPDU struct {
+	Type        SNMPPDUType
+	RequestID   int32
+	ErrorStatus SNMPErrorStatus
+	ErrorIndex  int32
+	// NonRepeaters and MaxRepetitions replace ErrorStatus and ErrorIndex
+	// in GetBulkRequest PDUs.
+	NonRepeaters   int32
+	MaxRepetitions int32
+	// Enterprise, AgentAddress, GenericTrap, SpecificTrap and Timestamp
+	// are used by SNMPv1 traps, which have no request ID.
+	Enterprise   SNMPObjectIdentifier
+	AgentAddress net.IP
+	GenericTrap  SNMPGenericTrap
+	SpecificTrap int32
+	Timestamp    uint32 // Hundredths of seconds since the agent started
+	VarBinds     []SNMPVarBind
+}
+
+// SNMPUSMSecurityParameters are the security parameters of SNMPv3
+// messages using the user-based security model, see RFC 3414.
+type SNMPUSMSecurityParameters struct {
+	AuthoritativeEngineID    []byte
+	AuthoritativeEngineBoots int32
+	AuthoritativeEngineTime  int32
+	UserName                 string
+	AuthenticationParameters []byte // The HMAC of the message, if authenticated
+	PrivacyParameters        []byte // The salt of the encryption, if encrypted
+}
+
+// SNMP is an SNMP message, of version 1 or 2c, with a community, or of
+// version 3, see RFC 3412.
+//
+// The PDU of SNMPv3 messages is in the scoped PDU, with ContextEngineID and
+// ContextName, unless it is encrypted.  Encrypted scoped PDUs are left in
+// EncryptedPDU, and PDU is nil.
+type SNMP struct {
+	BaseLayer
+	Version   SNMPVersion
+	Community string // SNMPv1 and SNMPv2c only
+
+	// MessageID, MaxSize, Flags, SecurityModel, SecurityParameters,
+	// USM, ContextEngineID, ContextName and EncryptedPDU are used by
+	// SNMPv3.  USM holds the decoded SecurityParameters of messages
+	// using the user-based security model.
+	MessageID          int32
+	MaxSize            int32
+	Flags              SNMPv3Flags
+	SecurityModel      SNMPSecurityModel
+	SecurityParameters []byte
+	USM                *SNMPUSMSecurityParameters
+	ContextEngineID    []byte
+	ContextName        string
+	EncryptedPDU       []byte
+
+	PDU *SNMPPDU
+}
+
+// LayerType returns LayerTypeSNMP.
+func (s *SNMP) LayerType() gopacket.LayerType { return LayerTypeSNMP }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (s *SNMP) CanDecode() gopacket.LayerClass { return LayerTypeSNMP }
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (s *SNMP) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+
+// Payload returns nil, since SNMP messages do not carry a payload.
+func (s *SNMP) Payload() []byte { return nil }
+
+func decodeSNMP(data []byte, p gopacket.PacketBuilder) error {
+	s := &SNMP{}
+	if err := s.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(s)
+	p.SetApplicationLayer(s)
+	return nil
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (s *SNMP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*s = SNMP{}
+	msg, rest, err := snmpBERExpect(data, snmpTagSequence, "message")
+	if err != nil {
+		df.SetTruncated()
+		return err
+	}
+	version, msg, err := snmpBERInteger(msg, "version")
+	if err != nil {
+		return err
+	}
+	s.Version = SNMPVersion(version)
+	switch s.Version {
+	case SNMPv1, SNMPv2c:
+		var community []byte
+		if community, msg, err = snmpBERExpect(msg, byte(SNMPOctetString), "community"); err != nil {
+			return err
+		}
+		s.Community = string(community)
+		s.PDU, err = decodeSNMPPDU(msg)
+	case SNMPv3:
+		err = s.decodeV3(msg)
+	default:
+		err = fmt.Errorf("unsupported SNMP version %d", version)
+	}
+	if err != nil {
+		return err
+	}
+	s.Contents = data[:len(data)-len(rest)]
+	return nil
+}
+
+func (s *SNMP) decodeV3(msg []byte) error {
+	header, msg, err := snmpBERExpect(msg, snmpTagSequence, "SNMPv3 header")
+	if err != nil {
+		return err
+	}
+	if s.MessageID, header, err = snmpBERInteger(header, "message ID"); err != nil {
+		return err
+	}
+	if s.MaxSize, header, err = snmpBERInteger(header, "maximum size"); err != nil {
+		return err
+	}
+	flags, header, err := snmpBERExpect(header, byte(SNMPOctetString), "flags")
+	if err != nil {
+		return err
+	}
+	if len(flags) != 1 {
+		return fmt.Errorf("invalid SNMPv3 flags length %d", len(flags))
+	}
+	s.Flags = SNMPv3Flags(flags[0])
+	model, _, err := snmpBERInteger(header, "security model")
+	if err != nil {
+		return err
+	}
+	s.SecurityModel = SNMPSecurityModel(model)
+
+	if s.SecurityParameters, msg, err = snmpBERExpect(msg, byte(SNMPOctetString), "security parameters"); err != nil {
+		return err
+	}
+	if s.SecurityModel == SNMPSecurityModelUSM {
+		if s.USM, err = decodeSNMPUSMSecurityParameters(s.SecurityParameters); err != nil {
+			return err
+		}
+	}
+
+	tag, scoped, _, err := snmpBERElement(msg)
+	if err != nil {
+		return err
+	}
+	switch tag {
+	case byte(SNMPOctetString):
+		s.EncryptedPDU = scoped
+		return nil
+	case snmpTagSequence:
+	default:
+		return fmt.Errorf("invalid SNMPv3 scoped PDU tag %#x", tag)
+	}
+	var contextName []byte
+	if s.ContextEngineID, scoped, err = snmpBERExpect(scoped, byte(SNMPOctetString), "context engine ID"); err != nil {
+		return err
+	}
+	if contextName, scoped, err = snmpBERExpect(scoped, byte(SNMPOctetString), "context name"); err != nil {
+		return err
+	}
+	s.ContextName = string(contextName)
+	s.PDU, err = decodeSNMPPDU(scoped)
+	return err
+}
+
+func decodeSNMPUSMSecurityParameters(data []byte) (*SNMPUSMSecurityParameters, error) {
+	params, _, err := snmpBERExpect(data, snmpTagSequence, "USM security parameters")
+	if err != nil {
+		return nil, err
+	}
+	usm := &SNMPUSMSecurityParameters{}
+	if usm.AuthoritativeEngineID, params, err = snmpBERExpect(params, byte(SNMPOctetString), "authoritative engine ID"); err != nil {
+		return nil, err
+	}
+	if usm.AuthoritativeEngineBoots, params, err = snmpBERInteger(params, "authoritative engine boots"); err != nil {
+		return nil, err
+	}
+	if usm.AuthoritativeEngineTime, params, err = snmpBERInteger(params, "authoritative engine time"); err != nil {
+		return nil, err
+	}
+	var userName []byte
+	if userName, params, err = snmpBERExpect(params, byte(SNMPOctetString), "user name"); err != nil {
+		return nil, err
+	}
+	usm.UserName = string(userName)
+	if usm.AuthenticationParameters, params, err = snmpBERExpect(params, byte(SNMPOctetString), "authentication parameters"); err != nil {
+		return nil, err
+	}
+	if usm.PrivacyParameters, _, err = snmpBERExpect(params, byte(SNMPOctetString), "privacy parameters"); err != nil {
+		return nil, err
+	}
+	return usm, nil
+}
+
+func decodeSNMPPDU(data []byte) (*SNMPPDU, error) {
+	tag, content, _, err := snmpBERElement(data)
+	if err != nil {
+		return nil, err
+	}
+	pdu := &SNMPPDU{Type: SNMPPDUType(tag)}
+	if pdu.Type < SNMPGetRequest || pdu.Type > SNMPReport {
+		return nil, fmt.Errorf("unknown SNMP PDU type %#x", tag)
+	}
+
+	if pdu.Type == SNMPTrapV1 {
+		var enterprise, address, timestamp []byte
+		if enterprise, content, err = snmpBERExpect(content, byte(SNMPObjectID), "enterprise"); err != nil {
+			return nil, err
+		}
+		if pdu.Enterprise, err = decodeSNMPObjectIdentifier(enterprise); err != nil {
+			return nil, err
+		}
+		if address, content, err = snmpBERExpect(content, byte(SNMPIPAddress), "agent address"); err != nil {
+			return nil, err
+		}
+		if len(address) != net.IPv4len {
+			return nil, fmt.Errorf("invalid SNMP agent address length %d", len(address))
+		}
+		pdu.AgentAddress = net.IP(address)
+		var generic int32
+		if generic, content, err = snmpBERInteger(content, "generic trap"); err != nil {
+			return nil, err
+		}
+		pdu.GenericTrap = SNMPGenericTrap(generic)
+		if pdu.SpecificTrap, content, err = snmpBERInteger(content, "specific trap"); err != nil {
+			return nil, err
+		}
+		if timestamp, content, err = snmpBERExpect(content, byte(SNMPTimeTicks), "timestamp"); err != nil {
+			return nil, err
+		}
+		ticks, err := snmpBERUint(timestamp)
+		if err != nil || ticks > 0xffffffff {
+			return nil, errors.New("invalid SNMP trap timestamp")
+		}
+		pdu.Timestamp = uint32(ticks)
+	} else {
+		var status, index int32
+		if pdu.RequestID, content, err = snmpBERInteger(content, "request ID"); err != nil {
+			return nil, err
+		}
+		if status, content, err = snmpBERInteger(content, "error status"); err != nil {
+			return nil, err
+		}
+		if index, content, err = snmpBERInteger(content, "error index"); err != nil {
+			return nil, err
+		}
+		if pdu.Type == SNMPGetBulkRequest {
+			pdu.NonRepeaters, pdu.MaxRepetitions = status, index
+		} else {
+			pdu.ErrorStatus, pdu.ErrorIndex = SNMPErrorStatus(status), index
+		}
+	}
+
+	varBinds, _, err := snmpBERExpect(content, snmpTagSequence, "variable bindings")
+	if err != nil {
+		return nil, err
+	}
+	for len(varBinds) > 0 {
+		var vb, name []byte
+		if vb, varBinds, err = snmpBERExpect(varBinds, snmpTagSequence, "variable binding"); err != nil {
+			return nil, err
+		}
+		if name, vb, err = snmpBERExpect(vb, byte(SNMPObjectID), "variable name"); err != nil {
+			return nil, err
+		}
+		v := SNMPVarBind{}
+		if v.Name, err = decodeSNMPObjectIdentifier(name); err != nil {
+			return nil, err
+		}
+		var tag byte
+		if tag, v.Value, _, err = snmpBERElement(vb); err != nil {
+			return nil, err
+		}
+		v.Type = SNMPValueType(tag)
+		pdu.VarBinds = append(pdu.VarBinds, v)
+	}
+	return pdu, nil
+}
+
+// snmpBERElement decodes the BER element starting data, returning its tag,
+// its contents and the data following it.  SNMP only uses single byte tags
+// and definite lengths.
+func snmpBERElement(data []byte) (byte, []byte, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errors.New("SNMP BER element too short")
+	}
+	tag := data[0]
+	if tag&0x1f == 0x1f {
+		return 0, nil, nil, fmt.Errorf("unsupported SNMP BER tag %#x", tag)
+	}
+	length, offset := int(data[1]), 2
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 {
+			return 0, nil, nil, fmt.Errorf("unsupported SNMP BER length of %d bytes", n)
+		}
+		if len(data) < 2+n {
+			return 0, nil, nil, errors.New("SNMP BER length too short")
+		}
+		length = 0
+		for _, b := range data[2 : 2+n] {
+			if length > 0x7fffff {
+				return 0, nil, nil, errors.New("SNMP BER length too large")
+			}
+			length = length<<8 | int(b)
+		}
+		offset += n
+	}
+	if len(data)-offset < length {
+		return 0, nil, nil, fmt.Errorf("SNMP BER element length %d too long", length)
+	}
+	return tag, data[offset : offset+length], data[offset+length:], nil
+}
+
+// snmpBERExpect decodes the BER element starting data, which must have tag
+// tag.  what names the element in errors.
+func snmpBERExpect(data []byte, tag byte, what string) ([]byte, []byte, error) {
+	t, content, rest, err := snmpBERElement(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("SNMP %s: %v", what, err)
+	}
+	if t != tag {
+		return nil, nil, fmt.Errorf("SNMP %s has tag %#x, want %#x", what, t, tag)
+	}
+	return content, rest, nil
+}
+
+// snmpBERInteger decodes the 32 bit INTEGER starting data.
+func snmpBERInteger(data []byte, what string) (int32, []byte, error) {
+	content, rest, err := snmpBERExpect(data, byte(SNMPInteger), what)
+	if err != nil {
+		return 0, nil, err
+	}
+	n, err := snmpBERInt(content)
+	if err != nil || n != int64(int32(n)) {
+		return 0, nil, fmt.Errorf("invalid SNMP %s", what)
+	}
+	return int32(n), rest, nil
+}
+
+// snmpBERInt decodes the contents of a BER encoded signed integer of up to
+// 64 bits.
+func snmpBERInt(content []byte) (int64, error) {
+	if len(content) == 0 || len(content) > 8 {
+		return 0, fmt.Errorf("invalid SNMP integer length %d", len(content))
+	}
+	n := int64(int8(content[0]))
+	for _, b := range content[1:] {
+		n = n<<8 | int64(b)
+	}
+	return n, nil
+}
+
+// snmpBERUint decodes the contents of a BER encoded unsigned integer of up
+// to 64 bits, which may have a leading zero byte.
+func snmpBERUint(content []byte) (uint64, error) {
+	if len(content) == 9 && content[0] == 0 {
+		content = content[1:]
+	}
+	if len(content) == 0 || len(content) > 8 {
+		return 0, fmt.Errorf("invalid SNMP unsigned integer length %d", len(content))
+	}
+	var n uint64
+	for _, b := range content {
+		n = n<<8 | uint64(b)
+	}
+	return n, nil
+}
+
+// decodeSNMPObjectIdentifier decodes the contents of a BER encoded object
+// identifier.
+func decodeSNMPObjectIdentifier(content []byte) (SNMPObjectIdentifier, error) {
+	if len(content) == 0 {
+		return nil, errors.New("empty SNMP object identifier")
+	}
+	oid := make(SNMPObjectIdentifier, 1, len(content)+1)
+	for i := 0; i < len(content); {
+		var n uint64
+		for {
+			if i == len(content) {
+				return nil, errors.New("SNMP object identifier truncated")
+			}
+			b := content[i]
+			i++
+			n = n<<7 | uint64(b&0x7f)
+			if n > 0xffffffff+80 {
+				return nil, errors.New("SNMP object identifier too large")
+			}
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		if len(oid) == 1 {
+			// The first subidentifier encodes the first two arcs
+			switch {
+			case n < 40:
+				oid[0] = 0
+			case n < 80:
+				oid[0], n = 1, n-40
+			default:
+				oid[0], n = 2, n-80
+			}
+		}
+		if n > 0xffffffff {
+			return nil, errors.New("SNMP object identifier too large")
+		}
+		oid = append(oid, uint32(n))
+	}
+	return oid, nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketSNMPv2cResponse is an SNMPv2c response with variables of most types.
+var testPacketSNMPv2cResponse = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0xdc, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0xa3, 0x4f, 0x0a, 0x00, 0x00, 0x02, 0x0a, 0x00,
+	0x00, 0x01, 0x00, 0xa1, 0x9c, 0x40, 0x00, 0xc8, 0x4b, 0x9c, 0x30, 0x81, 0xbd, 0x02, 0x01, 0x01,
+	0x04, 0x06, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0xa2, 0x81, 0xaf, 0x02, 0x02, 0x12, 0x34, 0x02,
+	0x01, 0x00, 0x02, 0x01, 0x00, 0x30, 0x81, 0xa2, 0x30, 0x18, 0x06, 0x08, 0x2b, 0x06, 0x01, 0x02,
+	0x01, 0x01, 0x01, 0x00, 0x04, 0x0c, 0x4c, 0x69, 0x6e, 0x75, 0x78, 0x20, 0x72, 0x6f, 0x75, 0x74,
+	0x65, 0x72, 0x30, 0x0f, 0x06, 0x08, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x03, 0x00, 0x43, 0x03,
+	0x01, 0xe2, 0x40, 0x30, 0x13, 0x06, 0x0a, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x02, 0x02, 0x01, 0x0a,
+	0x01, 0x41, 0x05, 0x00, 0xee, 0x6b, 0x28, 0x00, 0x30, 0x14, 0x06, 0x0b, 0x2b, 0x06, 0x01, 0x02,
+	0x01, 0x1f, 0x01, 0x01, 0x01, 0x06, 0x01, 0x46, 0x05, 0x12, 0x34, 0x56, 0x78, 0x90, 0x30, 0x15,
+	0x06, 0x0d, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x04, 0x14, 0x01, 0x01, 0x0a, 0x00, 0x00, 0x02, 0x40,
+	0x04, 0x0a, 0x00, 0x00, 0x02, 0x30, 0x16, 0x06, 0x08, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x02,
+	0x00, 0x06, 0x0a, 0x2b, 0x06, 0x01, 0x04, 0x01, 0xbf, 0x08, 0x03, 0x02, 0x0a, 0x30, 0x0d, 0x06,
+	0x08, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x07, 0x00, 0x02, 0x01, 0xb8, 0x30, 0x0c, 0x06, 0x08,
+	0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x09, 0x00, 0x81, 0x00,
+}
+
+// testPacketSNMPv1Trap is an SNMPv1 linkUp trap.
+var testPacketSNMPv1Trap = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x56, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0xa3, 0xd5, 0x0a, 0x00, 0x00, 0x02, 0x0a, 0x00,
+	0x00, 0x01, 0x04, 0x01, 0x00, 0xa2, 0x00, 0x42, 0x14, 0x40, 0x30, 0x38, 0x02, 0x01, 0x00, 0x04,
+	0x05, 0x74, 0x72, 0x61, 0x70, 0x73, 0xa4, 0x2c, 0x06, 0x06, 0x2b, 0x06, 0x01, 0x04, 0x01, 0x09,
+	0x40, 0x04, 0x0a, 0x00, 0x00, 0x02, 0x02, 0x01, 0x03, 0x02, 0x01, 0x00, 0x43, 0x03, 0x0f, 0x12,
+	0x06, 0x30, 0x11, 0x30, 0x0f, 0x06, 0x0a, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x02, 0x02, 0x01, 0x01,
+	0x02, 0x02, 0x01, 0x02,
+}
+
+// testPacketSNMPv3GetBulk is an authenticated SNMPv3 get-bulk request.
+var testPacketSNMPv3GetBulk = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0xa2, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0xa3, 0x89, 0x0a, 0x00, 0x00, 0x01, 0x0a, 0x00,
+	0x00, 0x02, 0x9c, 0x41, 0x00, 0xa1, 0x00, 0x8e, 0x5c, 0x7a, 0x30, 0x81, 0x83, 0x02, 0x01, 0x03,
+	0x30, 0x0e, 0x02, 0x01, 0x2a, 0x02, 0x03, 0x00, 0xff, 0xe3, 0x04, 0x01, 0x05, 0x02, 0x01, 0x03,
+	0x04, 0x2f, 0x30, 0x2d, 0x04, 0x0d, 0x80, 0x00, 0x1f, 0x88, 0x80, 0xe9, 0x63, 0x00, 0x00, 0xd6,
+	0x1f, 0xf4, 0x49, 0x02, 0x01, 0x07, 0x02, 0x02, 0x0e, 0x10, 0x04, 0x05, 0x61, 0x64, 0x6d, 0x69,
+	0x6e, 0x04, 0x0c, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x04,
+	0x00, 0x30, 0x3d, 0x04, 0x0d, 0x80, 0x00, 0x1f, 0x88, 0x80, 0xe9, 0x63, 0x00, 0x00, 0xd6, 0x1f,
+	0xf4, 0x49, 0x04, 0x03, 0x63, 0x74, 0x78, 0xa5, 0x27, 0x02, 0x01, 0x63, 0x02, 0x01, 0x01, 0x02,
+	0x01, 0x0a, 0x30, 0x1c, 0x30, 0x0b, 0x06, 0x07, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x03, 0x05,
+	0x00, 0x30, 0x0d, 0x06, 0x09, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x02, 0x02, 0x01, 0x02, 0x05, 0x00,
+}
+
+// testPacketSNMPv3Encrypted is an encrypted SNMPv3 message.
+var testPacketSNMPv3Encrypted = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x8c, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0xa3, 0x9f, 0x0a, 0x00, 0x00, 0x01, 0x0a, 0x00,
+	0x00, 0x02, 0x9c, 0x41, 0x00, 0xa1, 0x00, 0x78, 0x46, 0x53, 0x30, 0x6e, 0x02, 0x01, 0x03, 0x30,
+	0x0e, 0x02, 0x01, 0x2b, 0x02, 0x03, 0x00, 0xff, 0xe3, 0x04, 0x01, 0x07, 0x02, 0x01, 0x03, 0x04,
+	0x37, 0x30, 0x35, 0x04, 0x0d, 0x80, 0x00, 0x1f, 0x88, 0x80, 0xe9, 0x63, 0x00, 0x00, 0xd6, 0x1f,
+	0xf4, 0x49, 0x02, 0x01, 0x07, 0x02, 0x02, 0x0e, 0x11, 0x04, 0x05, 0x61, 0x64, 0x6d, 0x69, 0x6e,
+	0x04, 0x0c, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x04, 0x08,
+	0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x04, 0x20, 0x40, 0x41, 0x42, 0x43, 0x44, 0x45,
+	0x46, 0x47, 0x48, 0x49, 0x4a, 0x4b, 0x4c, 0x4d, 0x4e, 0x4f, 0x50, 0x51, 0x52, 0x53, 0x54, 0x55,
+	0x56, 0x57, 0x58, 0x59, 0x5a, 0x5b, 0x5c, 0x5d, 0x5e, 0x5f,
+}
+
+func TestSNMPv2cResponse(t *testing.T) {
+	p := gopacket.NewPacket(testPacketSNMPv2cResponse, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeSNMP}, t)
+	s := p.ApplicationLayer().(*SNMP)
+	if s.Version != SNMPv2c || s.Community != "public" {
+		t.Errorf("got version %v community %q", s.Version, s.Community)
+	}
+	if !reflect.DeepEqual(s.Contents, testPacketSNMPv2cResponse[42:]) {
+		t.Error("contents mismatch")
+	}
+	pdu := s.PDU
+	if pdu == nil {
+		t.Fatal("no PDU")
+	}
+	if pdu.Type != SNMPResponse || pdu.RequestID != 0x1234 || pdu.ErrorStatus != SNMPNoError || pdu.ErrorIndex != 0 {
+		t.Errorf("got PDU header %v %d %v %d", pdu.Type, pdu.RequestID, pdu.ErrorStatus, pdu.ErrorIndex)
+	}
+	for i, want := range []struct {
+		name  string
+		typ   SNMPValueType
+		value interface{}
+	}{
+		{"1.3.6.1.2.1.1.1.0", SNMPOctetString, []byte("Linux router")},
+		{"1.3.6.1.2.1.1.3.0", SNMPTimeTicks, uint64(123456)},
+		{"1.3.6.1.2.1.2.2.1.10.1", SNMPCounter32, uint64(4000000000)},
+		{"1.3.6.1.2.1.31.1.1.1.6.1", SNMPCounter64, uint64(0x1234567890)},
+		{"1.3.6.1.2.1.4.20.1.1.10.0.0.2", SNMPIPAddress, net.IP{10, 0, 0, 2}},
+		{"1.3.6.1.2.1.1.2.0", SNMPObjectID, SNMPObjectIdentifier{1, 3, 6, 1, 4, 1, 8072, 3, 2, 10}},
+		{"1.3.6.1.2.1.1.7.0", SNMPInteger, int64(-72)},
+		{"1.3.6.1.2.1.1.9.0", SNMPNoSuchInstance, nil},
+	} {
+		if i >= len(pdu.VarBinds) {
+			t.Fatalf("got %d variable bindings", len(pdu.VarBinds))
+		}
+		vb := pdu.VarBinds[i]
+		if vb.Name.String() != want.name || vb.Type != want.typ {
+			t.Errorf("variable %d: got %v %v, want %s %v", i, vb.Name, vb.Type, want.name, want.typ)
+		}
+		v, err := vb.Decode()
+		if err != nil {
+			t.Errorf("variable %d: %v", i, err)
+		} else if !reflect.DeepEqual(v, want.value) {
+			t.Errorf("variable %d: got value %#v, want %#v", i, v, want.value)
+		}
+	}
+	if !pdu.VarBinds[2].Name.HasPrefix(SNMPObjectIdentifier{1, 3, 6, 1, 2, 1, 2}) {
+		t.Error("ifInOctets not in the interfaces subtree")
+	}
+	if pdu.VarBinds[0].Name.HasPrefix(SNMPObjectIdentifier{1, 3, 6, 1, 2, 1, 2}) {
+		t.Error("sysDescr in the interfaces subtree")
+	}
+}
+
+func TestSNMPv1Trap(t *testing.T) {
+	p := gopacket.NewPacket(testPacketSNMPv1Trap, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeSNMP}, t)
+	s := p.ApplicationLayer().(*SNMP)
+	want := &SNMP{
+		BaseLayer: BaseLayer{Contents: testPacketSNMPv1Trap[42:]},
+		Version:   SNMPv1,
+		Community: "traps",
+		PDU: &SNMPPDU{
+			Type:         SNMPTrapV1,
+			Enterprise:   SNMPObjectIdentifier{1, 3, 6, 1, 4, 1, 9},
+			AgentAddress: net.IP{10, 0, 0, 2},
+			GenericTrap:  SNMPLinkUp,
+			Timestamp:    987654,
+			VarBinds: []SNMPVarBind{
+				{
+					Name:  SNMPObjectIdentifier{1, 3, 6, 1, 2, 1, 2, 2, 1, 1, 2},
+					Type:  SNMPInteger,
+					Value: []byte{2},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("SNMP trap mismatch:\ngot  %#v\nwant %#v", s, want)
+	}
+}
+
+func TestSNMPv3(t *testing.T) {
+	engineID := []byte{0x80, 0x00, 0x1f, 0x88, 0x80, 0xe9, 0x63, 0x00, 0x00, 0xd6, 0x1f, 0xf4, 0x49}
+
+	p := gopacket.NewPacket(testPacketSNMPv3GetBulk, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeSNMP}, t)
+	s := p.ApplicationLayer().(*SNMP)
+	want := &SNMP{
+		BaseLayer:          BaseLayer{Contents: testPacketSNMPv3GetBulk[42:]},
+		Version:            SNMPv3,
+		MessageID:          0x2a,
+		MaxSize:            65507,
+		Flags:              SNMPv3FlagAuth | SNMPv3FlagReportable,
+		SecurityModel:      SNMPSecurityModelUSM,
+		SecurityParameters: testPacketSNMPv3GetBulk[42+24 : 42+71],
+		USM: &SNMPUSMSecurityParameters{
+			AuthoritativeEngineID:    engineID,
+			AuthoritativeEngineBoots: 7,
+			AuthoritativeEngineTime:  3600,
+			UserName:                 "admin",
+			AuthenticationParameters: make([]byte, 12),
+			PrivacyParameters:        []byte{},
+		},
+		ContextEngineID: engineID,
+		ContextName:     "ctx",
+		PDU: &SNMPPDU{
+			Type:           SNMPGetBulkRequest,
+			RequestID:      99,
+			NonRepeaters:   1,
+			MaxRepetitions: 10,
+			VarBinds: []SNMPVarBind{
+				{Name: SNMPObjectIdentifier{1, 3, 6, 1, 2, 1, 1, 3}, Type: SNMPNull, Value: []byte{}},
+				{Name: SNMPObjectIdentifier{1, 3, 6, 1, 2, 1, 2, 2, 1, 2}, Type: SNMPNull, Value: []byte{}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("SNMPv3 get-bulk mismatch:\ngot  %#v\nwant %#v", s, want)
+	}
+
+	p = gopacket.NewPacket(testPacketSNMPv3Encrypted, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	s = p.ApplicationLayer().(*SNMP)
+	if s.Flags != SNMPv3FlagAuth|SNMPv3FlagPriv|SNMPv3FlagReportable || s.PDU != nil {
+		t.Errorf("got flags %#x, PDU %v", s.Flags, s.PDU)
+	}
+	if s.USM == nil || s.USM.AuthoritativeEngineTime != 3601 || len(s.USM.AuthenticationParameters) != 12 || len(s.USM.PrivacyParameters) != 8 {
+		t.Errorf("got USM parameters %#v", s.USM)
+	}
+	if !reflect.DeepEqual(s.EncryptedPDU, testPacketSNMPv3Encrypted[42+80:]) {
+		t.Errorf("got encrypted PDU %x", s.EncryptedPDU)
+	}
+}
+
+func TestSNMPDecodeInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		data   []byte
+		mutate func([]byte)
+	}{
+		{"truncated message", testPacketSNMPv2cResponse[42:60], nil},
+		{"indefinite length", testPacketSNMPv2cResponse[42:], func(b []byte) { b[1] = 0x80 }},
+		{"high tag number", testPacketSNMPv2cResponse[42:], func(b []byte) { b[0] = 0x3f }},
+		{"unsupported version", testPacketSNMPv2cResponse[42:], func(b []byte) { b[5] = 2 }},
+		{"invalid community tag", testPacketSNMPv2cResponse[42:], func(b []byte) { b[6] = 5 }},
+		{"unknown PDU type", testPacketSNMPv2cResponse[42:], func(b []byte) { b[14] = 0xaf }},
+		{"truncated object identifier", testPacketSNMPv2cResponse[42:], func(b []byte) { b[41] = 0x80 }},
+		{"invalid generic trap tag", testPacketSNMPv1Trap[42:], func(b []byte) { b[28] = 4 }},
+		{"invalid security parameters tag", testPacketSNMPv3GetBulk[42:], func(b []byte) { b[22] = 5 }},
+		{"invalid USM parameters tag", testPacketSNMPv3GetBulk[42:], func(b []byte) { b[24] = 0x31 }},
+		{"invalid scoped PDU tag", testPacketSNMPv3GetBulk[42:], func(b []byte) { b[71] = 0x31 }},
+		{"truncated encrypted PDU", testPacketSNMPv3Encrypted[42 : len(testPacketSNMPv3Encrypted)-1], nil},
+	} {
+		data := append([]byte{}, test.data...)
+		if test.mutate != nil {
+			test.mutate(data)
+		}
+		var s SNMP
+		if err := s.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", test.name)
+		}
+	}
+}
@@ -7,6 +7,7 @@
 package layers
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -21,7 +22,140 @@ type STPSwitchID struct {
 	HwAddr   net.HardwareAddr
 }
 
+func decodeSTPSwitchID(data []byte) STPSwitchID {
+	return STPSwitchID{
+		Priority: binary.BigEndian.Uint16(data[0:2]) & 0xf000,
+		SysID:    binary.BigEndian.Uint16(data[0:2]) & 0x0fff,
+		HwAddr:   net.HardwareAddr(data[2:8]),
+	}
+}
+
+func (id STPSwitchID) encode(data []byte) error {
+	prio, err := checkPriority(id.Priority)
+	if err != nil {
+		return err
+	}
+	if id.SysID >= 4096 {
+		return fmt.Errorf("Invalid VlanID value %d", id.SysID)
+	}
+	binary.BigEndian.PutUint16(data[0:2], prio|id.SysID)
+	copy(data[2:8], id.HwAddr)
+	return nil
+}
+
+// STP protocol versions
+const (
+	STPVersionSTP  uint8 = 0
+	STPVersionRSTP uint8 = 2
+	STPVersionMSTP uint8 = 3
+)
+
+// BPDU types
+const (
+	STPTypeConfig uint8 = 0x00
+	STPTypeRST    uint8 = 0x02 // Used by both RSTP and MSTP
+	STPTypeTCN    uint8 = 0x80
+)
+
+// STPPortRole is the port role carried in the flags of RST and MST BPDUs.
+type STPPortRole uint8
+
+// STPPortRole known values
+const (
+	STPPortRoleUnknown    STPPortRole = 0
+	STPPortRoleAlternate  STPPortRole = 1 // Alternate or backup
+	STPPortRoleRoot       STPPortRole = 2
+	STPPortRoleDesignated STPPortRole = 3
+)
+
+func (r STPPortRole) String() string {
+	switch r {
+	case STPPortRoleAlternate:
+		return "Alternate/Backup"
+	case STPPortRoleRoot:
+		return "Root"
+	case STPPortRoleDesignated:
+		return "Designated"
+	default:
+		return "Unknown"
+	}
+}
+
+// STPFlags holds the flags shared by RST BPDUs and MSTI configuration
+// messages, bits 0 to 6 of their flags byte.
+type STPFlags struct {
+	TC         bool // Topology change
+	Proposal   bool
+	PortRole   STPPortRole
+	Learning   bool
+	Forwarding bool
+	Agreement  bool
+}
+
+func decodeSTPFlags(b uint8) STPFlags {
+	return STPFlags{
+		TC:         b&0x01 != 0,
+		Proposal:   b&0x02 != 0,
+		PortRole:   STPPortRole(b>>2) & 0x3,
+		Learning:   b&0x10 != 0,
+		Forwarding: b&0x20 != 0,
+		Agreement:  b&0x40 != 0,
+	}
+}
+
+func (f STPFlags) encode() (b uint8) {
+	if f.TC {
+		b |= 0x01
+	}
+	if f.Proposal {
+		b |= 0x02
+	}
+	b |= uint8(f.PortRole&0x3) << 2
+	if f.Learning {
+		b |= 0x10
+	}
+	if f.Forwarding {
+		b |= 0x20
+	}
+	if f.Agreement {
+		b |= 0x40
+	}
+	return
+}
+
+// STPMSTConfigID identifies an MST region, see IEEE 802.1Q-2018 section
+// 13.8.
+type STPMSTConfigID struct {
+	FormatSelector uint8
+	Name           string
+	Revision       uint16
+	Digest         [16]byte
+}
+
+// STPMSTIRecord is an MSTI configuration message, carrying the spanning tree
+// information of one MST instance.
+type STPMSTIRecord struct {
+	Flags                STPFlags
+	Master               bool
+	RegionalRootID       STPSwitchID // SysID holds the MSTID
+	InternalRootPathCost uint32
+	BridgePriority       uint16 // Multiple of 4096
+	PortPriority         uint8  // Multiple of 16
+	RemainingHops        uint8
+}
+
+// MSTP lengths
+const (
+	stpMSTConfigIDLength = 51
+	stpMSTIRecordLength  = 16
+	stpMSTLength         = stpMSTConfigIDLength + 13 // Version 3 length without MSTIs
+)
+
 // STP decode spanning tree protocol packets to transport BPDU (bridge protocol data unit) message.
+//
+// Configuration BPDUs only use the fields up to FDelay, and topology change
+// notification BPDUs only the ProtocolID, Version and Type. RST BPDUs also
+// use the RSTP flags, and MST BPDUs (Version 3) the remaining fields.
 type STP struct {
 	BaseLayer
 	ProtocolID        uint16
@@ -35,6 +169,20 @@ type STP struct {
 	MaxAge            uint16
 	HelloTime         uint16
 	FDelay            uint16
+
+	// RSTP flags
+	Proposal   bool
+	PortRole   STPPortRole
+	Learning   bool
+	Forwarding bool
+	Agreement  bool
+
+	// MSTP
+	MSTConfigID              STPMSTConfigID
+	CISTInternalRootPathCost uint32
+	CISTBridgeID             STPSwitchID
+	CISTRemainingHops        uint8
+	MSTIs                    []STPMSTIRecord
 }
 
 // LayerType returns gopacket.LayerTypeSTP.
@@ -47,35 +195,105 @@ func (s *STP) CanDecode() gopacket.LayerClass {
 
 // DecodeFromBytes decodes the given bytes into this layer.
 func (stp *STP) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
-	stpLength := 35
-	if len(data) < stpLength {
+	if len(data) < 4 {
 		df.SetTruncated()
 		return fmt.Errorf("STP length %d too short", len(data))
 	}
-
+	*stp = STP{MSTIs: stp.MSTIs[:0]}
 	stp.ProtocolID = binary.BigEndian.Uint16(data[:2])
 	stp.Version = uint8(data[2])
 	stp.Type = uint8(data[3])
+	if stp.Type == STPTypeTCN {
+		stp.Contents = data[:4]
+		stp.Payload = data[4:]
+		return nil
+	}
+
+	stpLength := 35
+	if stp.Type == STPTypeRST {
+		stpLength++ // Version 1 length
+	}
+	if len(data) < stpLength {
+		df.SetTruncated()
+		return fmt.Errorf("STP length %d too short", len(data))
+	}
+
 	stp.TC = data[4]&0x01 != 0
 	stp.TCA = data[4]&0x80 != 0
-	stp.RouteID.Priority = binary.BigEndian.Uint16(data[5:7]) & 0xf000
-	stp.RouteID.SysID = binary.BigEndian.Uint16(data[5:7]) & 0x0fff
-	stp.RouteID.HwAddr = net.HardwareAddr(data[7:13])
+	if stp.Type == STPTypeRST {
+		flags := decodeSTPFlags(data[4])
+		stp.Proposal = flags.Proposal
+		stp.PortRole = flags.PortRole
+		stp.Learning = flags.Learning
+		stp.Forwarding = flags.Forwarding
+		stp.Agreement = flags.Agreement
+	}
+	stp.RouteID = decodeSTPSwitchID(data[5:13])
 	stp.Cost = binary.BigEndian.Uint32(data[13:17])
-	stp.BridgeID.Priority = binary.BigEndian.Uint16(data[17:19]) & 0xf000
-	stp.BridgeID.SysID = binary.BigEndian.Uint16(data[17:19]) & 0x0fff
-	stp.BridgeID.HwAddr = net.HardwareAddr(data[19:25])
+	stp.BridgeID = decodeSTPSwitchID(data[17:25])
 	stp.PortID = binary.BigEndian.Uint16(data[25:27])
 	stp.MessageAge = binary.BigEndian.Uint16(data[27:29])
 	stp.MaxAge = binary.BigEndian.Uint16(data[29:31])
 	stp.HelloTime = binary.BigEndian.Uint16(data[31:33])
 	stp.FDelay = binary.BigEndian.Uint16(data[33:35])
+
+	if stp.Type == STPTypeRST && stp.Version >= STPVersionMSTP {
+		if err := stp.decodeMST(data[stpLength:], df); err != nil {
+			return err
+		}
+		stpLength += 2 + stpMSTLength + len(stp.MSTIs)*stpMSTIRecordLength
+	}
 	stp.Contents = data[:stpLength]
 	stp.Payload = data[stpLength:]
 
 	return nil
 }
 
+// decodeMST decodes the MSTP specific part of an MST BPDU, starting at the
+// Version 3 length.
+func (stp *STP) decodeMST(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 2 {
+		df.SetTruncated()
+		return errors.New("MST BPDU too short")
+	}
+	length := int(binary.BigEndian.Uint16(data[:2]))
+	if length < stpMSTLength || (length-stpMSTLength)%stpMSTIRecordLength != 0 {
+		return fmt.Errorf("invalid MST BPDU version 3 length %d", length)
+	}
+	data = data[2:]
+	if len(data) < length {
+		df.SetTruncated()
+		return fmt.Errorf("MST BPDU length %d too short, expected %d", len(data), length)
+	}
+
+	id := &stp.MSTConfigID
+	id.FormatSelector = data[0]
+	name := data[1:33]
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+	id.Name = string(name)
+	id.Revision = binary.BigEndian.Uint16(data[33:35])
+	copy(id.Digest[:], data[35:51])
+	stp.CISTInternalRootPathCost = binary.BigEndian.Uint32(data[51:55])
+	stp.CISTBridgeID = decodeSTPSwitchID(data[55:63])
+	stp.CISTRemainingHops = data[63]
+
+	for off := stpMSTLength; off < length; off += stpMSTIRecordLength {
+		r := data[off : off+stpMSTIRecordLength]
+		stp.MSTIs = append(stp.MSTIs, STPMSTIRecord{
+			Flags:                decodeSTPFlags(r[0]),
+			Master:               r[0]&0x80 != 0,
+			RegionalRootID:       decodeSTPSwitchID(r[1:9]),
+			InternalRootPathCost: binary.BigEndian.Uint32(r[9:13]),
+			BridgePriority:       uint16(r[13]&0xf0) << 8,
+			PortPriority:         r[14] & 0xf0,
+			RemainingHops:        r[15],
+		})
+	}
+	return nil
+}
+
 // NextLayerType returns the layer type contained by this DecodingLayer.
 func (stp *STP) NextLayerType() gopacket.LayerType {
 	return gopacket.LayerTypePayload
@@ -83,28 +301,46 @@ func (stp *STP) NextLayerType() gopacket.LayerType {
 
 // Check if the priority value is correct.
 func checkPriority(prio uint16) (uint16, error) {
-	if prio == 0 {
-		return prio, errors.New("Invalid Priority value must be in the rage <4096-61440> with an increment of 4096")
-	}
 	if prio%4096 == 0 {
 		return prio, nil
-	} else {
-		return prio, errors.New("Invalid Priority value must be in the rage <4096-61440> with an increment of 4096")
 	}
+	return prio, errors.New("Invalid Priority value must be in the rage <0-61440> with an increment of 4096")
 }
 
 // SerializeTo writes the serialized form of this layer into the
 // SerializationBuffer, implementing gopacket.SerializableLayer.
 // See the docs for gopacket.SerializableLayer for more info.
 func (s *STP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
-	var flags uint8 = 0x00
-	bytes, err := b.PrependBytes(35)
+	length := 35
+	switch {
+	case s.Type == STPTypeTCN:
+		length = 4
+	case s.Type == STPTypeRST && s.Version >= STPVersionMSTP:
+		length = 36 + 2 + stpMSTLength + len(s.MSTIs)*stpMSTIRecordLength
+	case s.Type == STPTypeRST:
+		length = 36
+	}
+	bytes, err := b.PrependBytes(length)
 	if err != nil {
 		return err
 	}
 	binary.BigEndian.PutUint16(bytes, s.ProtocolID)
 	bytes[2] = s.Version
 	bytes[3] = s.Type
+	if s.Type == STPTypeTCN {
+		return nil
+	}
+
+	var flags uint8 = 0x00
+	if s.Type == STPTypeRST {
+		flags = STPFlags{
+			Proposal:   s.Proposal,
+			PortRole:   s.PortRole,
+			Learning:   s.Learning,
+			Forwarding: s.Forwarding,
+			Agreement:  s.Agreement,
+		}.encode()
+	}
 	if s.TC {
 		flags |= 0x01
 	}
@@ -113,34 +349,70 @@ func (s *STP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOpt
 	}
 	bytes[4] = flags
 
-	prioRoot, err := checkPriority(s.RouteID.Priority)
-	if err != nil {
-		panic(err)
-	}
-	if s.RouteID.SysID >= 4096 {
-		panic("Invalid VlanID value ..!")
+	if err := s.RouteID.encode(bytes[5:13]); err != nil {
+		return err
 	}
-	binary.BigEndian.PutUint16(bytes[5:7], prioRoot|s.RouteID.SysID)
-	copy(bytes[7:13], s.RouteID.HwAddr)
-
 	binary.BigEndian.PutUint32(bytes[13:17], s.Cost)
-
-	prioBridge, err := checkPriority(s.BridgeID.Priority)
-	if err != nil {
-		panic(err)
-	}
-	if s.BridgeID.SysID >= 4096 {
-		panic("Invalid VlanID value ..!")
+	if err := s.BridgeID.encode(bytes[17:25]); err != nil {
+		return err
 	}
-	binary.BigEndian.PutUint16(bytes[17:19], prioBridge|s.BridgeID.SysID)
-	copy(bytes[19:25], s.BridgeID.HwAddr)
-
 	binary.BigEndian.PutUint16(bytes[25:27], s.PortID)
 	binary.BigEndian.PutUint16(bytes[27:29], s.MessageAge)
 	binary.BigEndian.PutUint16(bytes[29:31], s.MaxAge)
 	binary.BigEndian.PutUint16(bytes[31:33], s.HelloTime)
 	binary.BigEndian.PutUint16(bytes[33:35], s.FDelay)
+	if length == 35 {
+		return nil
+	}
+	bytes[35] = 0 // Version 1 length
+	if length == 36 {
+		return nil
+	}
+	return s.encodeMST(bytes[36:])
+}
 
+// encodeMST writes the MSTP specific part of an MST BPDU, starting at the
+// Version 3 length.
+func (s *STP) encodeMST(data []byte) error {
+	id := &s.MSTConfigID
+	if len(id.Name) > 32 {
+		return fmt.Errorf("MST configuration name %q too long", id.Name)
+	}
+	binary.BigEndian.PutUint16(data[:2], uint16(len(data)-2))
+	data = data[2:]
+	data[0] = id.FormatSelector
+	copy(data[1:33], id.Name)
+	for i := 1 + len(id.Name); i < 33; i++ {
+		data[i] = 0
+	}
+	binary.BigEndian.PutUint16(data[33:35], id.Revision)
+	copy(data[35:51], id.Digest[:])
+	binary.BigEndian.PutUint32(data[51:55], s.CISTInternalRootPathCost)
+	if err := s.CISTBridgeID.encode(data[55:63]); err != nil {
+		return err
+	}
+	data[63] = s.CISTRemainingHops
+
+	for i, m := range s.MSTIs {
+		r := data[stpMSTLength+i*stpMSTIRecordLength:]
+		r[0] = m.Flags.encode()
+		if m.Master {
+			r[0] |= 0x80
+		}
+		if err := m.RegionalRootID.encode(r[1:9]); err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint32(r[9:13], m.InternalRootPathCost)
+		if _, err := checkPriority(m.BridgePriority); err != nil {
+			return err
+		}
+		r[13] = uint8(m.BridgePriority >> 8)
+		if m.PortPriority&0x0f != 0 {
+			return fmt.Errorf("Invalid MSTI port priority %d, must be a multiple of 16", m.PortPriority)
+		}
+		r[14] = m.PortPriority
+		r[15] = m.RemainingHops
+	}
 	return nil
 }
 
@@ -182,3 +182,133 @@ func TestEncodeDecodeSTP(t *testing.T) {
 		}
 	}
 }
+
+// testPacketRSTP is an RST BPDU from a designated, forwarding port, sent over
+// 802.3 with an LLC header.
+var testPacketRSTP = []byte{
+	0x01, 0x80, 0xc2, 0x00, 0x00, 0x00, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x00, 0x27, 0x42, 0x42,
+	0x03, 0x00, 0x00, 0x02, 0x02, 0x3c, 0x10, 0x00, 0x00, 0x11, 0x22, 0x33, 0x44, 0x00, 0x00, 0x00,
+	0x4e, 0x20, 0x80, 0x00, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x80, 0x02, 0x01, 0x00, 0x14, 0x00,
+	0x02, 0x00, 0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestDecodeRSTP(t *testing.T) {
+	p := gopacket.NewPacket(testPacketRSTP, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeLLC, LayerTypeSTP}, t)
+	stp := p.Layer(LayerTypeSTP).(*STP)
+	want := &STP{
+		BaseLayer:  BaseLayer{Contents: testPacketRSTP[17:53], Payload: []byte{}},
+		Version:    STPVersionRSTP,
+		Type:       STPTypeRST,
+		PortRole:   STPPortRoleDesignated,
+		Learning:   true,
+		Forwarding: true,
+		RouteID: STPSwitchID{
+			Priority: 4096,
+			HwAddr:   net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x00},
+		},
+		Cost: 20000,
+		BridgeID: STPSwitchID{
+			Priority: 32768,
+			HwAddr:   net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+		},
+		PortID:     0x8002,
+		MessageAge: 1 * 256,
+		MaxAge:     20 * 256,
+		HelloTime:  2 * 256,
+		FDelay:     15 * 256,
+	}
+	if !reflect.DeepEqual(stp, want) {
+		t.Errorf("RSTP mismatch:\ngot  %#v\nwant %#v", stp, want)
+	}
+
+	buf := gopacket.NewSerializeBuffer()
+	if err := stp.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(buf.Bytes(), testPacketRSTP[17:53]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), testPacketRSTP[17:53])
+	}
+}
+
+func TestDecodeSTPTCN(t *testing.T) {
+	p := gopacket.NewPacket([]byte{0x00, 0x00, 0x00, 0x80, 0x00, 0x00}, LayerTypeSTP, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	stp := p.Layer(LayerTypeSTP).(*STP)
+	if stp.Type != STPTypeTCN || len(stp.Contents) != 4 {
+		t.Errorf("TCN BPDU mismatch: %#v", stp)
+	}
+	if err := testEncodeDecodeSTP(&STP{Type: STPTypeTCN}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEncodeDecodeMSTP(t *testing.T) {
+	bridge := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+	mstp := &STP{
+		Version:    STPVersionMSTP,
+		Type:       STPTypeRST,
+		TC:         true,
+		Proposal:   true,
+		PortRole:   STPPortRoleRoot,
+		Agreement:  true,
+		RouteID:    STPSwitchID{Priority: 0, HwAddr: bridge},
+		BridgeID:   STPSwitchID{Priority: 0, HwAddr: bridge},
+		PortID:     0x8001,
+		MessageAge: 1 * 256,
+		MaxAge:     20 * 256,
+		HelloTime:  2 * 256,
+		FDelay:     15 * 256,
+		MSTConfigID: STPMSTConfigID{
+			Name:     "region1",
+			Revision: 3,
+			Digest:   [16]byte{0xac, 0x36, 0x17, 0x7f, 0x50, 0x28, 0x3c, 0xd4, 0xb8, 0x38, 0x21, 0xd8, 0xab, 0x26, 0xde, 0x62},
+		},
+		CISTInternalRootPathCost: 20000,
+		CISTBridgeID:             STPSwitchID{Priority: 32768, HwAddr: bridge},
+		CISTRemainingHops:        19,
+		MSTIs: []STPMSTIRecord{
+			{
+				Flags:          STPFlags{PortRole: STPPortRoleDesignated, Learning: true, Forwarding: true},
+				Master:         true,
+				RegionalRootID: STPSwitchID{Priority: 4096, SysID: 1, HwAddr: bridge},
+				BridgePriority: 4096,
+				PortPriority:   128,
+				RemainingHops:  20,
+			},
+			{
+				Flags:                STPFlags{PortRole: STPPortRoleAlternate},
+				RegionalRootID:       STPSwitchID{Priority: 8192, SysID: 2, HwAddr: bridge},
+				InternalRootPathCost: 2000,
+				BridgePriority:       61440,
+				PortPriority:         240,
+				RemainingHops:        19,
+			},
+		},
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := mstp.SerializeTo(buf, gopacket.SerializeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(buf.Bytes()), 102+2*16; got != want {
+		t.Errorf("MST BPDU length %d, want %d", got, want)
+	}
+	if err := testEncodeDecodeSTP(mstp); err != nil {
+		t.Error(err)
+	}
+
+	// A version 3 length not covering whole MSTI records is rejected
+	data := append([]byte(nil), buf.Bytes()...)
+	data[37]--
+	if err := (&STP{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+		t.Error("invalid version 3 length accepted")
+	}
+	if err := (&STP{}).DecodeFromBytes(buf.Bytes()[:len(data)-1], gopacket.NilDecodeFeedback); err == nil {
+		t.Error("truncated MST BPDU accepted")
+	}
+}
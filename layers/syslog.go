@@ -0,0 +1,411 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// SyslogFacility is the facility of a syslog message.
+type SyslogFacility uint8
+
+// SyslogFacility known values, see RFC 5424, section 6.2.1
+const (
+	SyslogKern     SyslogFacility = 0
+	SyslogUser     SyslogFacility = 1
+	SyslogMail     SyslogFacility = 2
+	SyslogDaemon   SyslogFacility = 3
+	SyslogAuth     SyslogFacility = 4
+	SyslogSyslog   SyslogFacility = 5
+	SyslogLPR      SyslogFacility = 6
+	SyslogNews     SyslogFacility = 7
+	SyslogUUCP     SyslogFacility = 8
+	SyslogCron     SyslogFacility = 9
+	SyslogAuthPriv SyslogFacility = 10
+	SyslogFTP      SyslogFacility = 11
+	SyslogNTP      SyslogFacility = 12
+	SyslogAudit    SyslogFacility = 13
+	SyslogAlert    SyslogFacility = 14
+	SyslogClock    SyslogFacility = 15
+	SyslogLocal0   SyslogFacility = 16
+	SyslogLocal1   SyslogFacility = 17
+	SyslogLocal2   SyslogFacility = 18
+	SyslogLocal3   SyslogFacility = 19
+	SyslogLocal4   SyslogFacility = 20
+	SyslogLocal5   SyslogFacility = 21
+	SyslogLocal6   SyslogFacility = 22
+	SyslogLocal7   SyslogFacility = 23
+)
+
+func (f SyslogFacility) String() string {
+	switch f {
+	case SyslogKern:
+		return "kern"
+	case SyslogUser:
+		return "user"
+	case SyslogMail:
+		return "mail"
+	case SyslogDaemon:
+		return "daemon"
+	case SyslogAuth:
+		return "auth"
+	case SyslogSyslog:
+		return "syslog"
+	case SyslogLPR:
+		return "lpr"
+	case SyslogNews:
+		return "news"
+	case SyslogUUCP:
+		return "uucp"
+	case SyslogCron:
+		return "cron"
+	case SyslogAuthPriv:
+		return "authpriv"
+	case SyslogFTP:
+		return "ftp"
+	case SyslogNTP:
+		return "ntp"
+	case SyslogAudit:
+		return "audit"
+	case SyslogAlert:
+		return "alert"
+	case SyslogClock:
+		return "clock"
+	}
+	if f >= SyslogLocal0 && f <= SyslogLocal7 {
+		return fmt.Sprintf("local%d", f-SyslogLocal0)
+	}
+	return fmt.Sprintf("Unknown(%d)", uint8(f))
+}
+
+// SyslogSeverity is the severity of a syslog message.
+type SyslogSeverity uint8
+
+// SyslogSeverity known values
+const (
+	SyslogEmergency     SyslogSeverity = 0
+	SyslogAlertSeverity SyslogSeverity = 1
+	SyslogCritical      SyslogSeverity = 2
+	SyslogError         SyslogSeverity = 3
+	SyslogWarning       SyslogSeverity = 4
+	SyslogNotice        SyslogSeverity = 5
+	SyslogInformational SyslogSeverity = 6
+	SyslogDebug         SyslogSeverity = 7
+)
+
+func (s SyslogSeverity) String() string {
+	switch s {
+	case SyslogEmergency:
+		return "emerg"
+	case SyslogAlertSeverity:
+		return "alert"
+	case SyslogCritical:
+		return "crit"
+	case SyslogError:
+		return "err"
+	case SyslogWarning:
+		return "warning"
+	case SyslogNotice:
+		return "notice"
+	case SyslogInformational:
+		return "info"
+	case SyslogDebug:
+		return "debug"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(s))
+	}
+}
+
+// SyslogFormat is the format of a syslog message.
+type SyslogFormat uint8
+
+// SyslogFormat known values
+const (
+	// SyslogRFC3164 is the BSD syslog format, with a timestamp without
+	// year, a hostname and a message usually starting with a tag.
+	SyslogRFC3164 SyslogFormat = 0
+	// SyslogRFC5424 is the syslog protocol format, with a version, an
+	// RFC 3339 timestamp, a hostname, an application name, a process ID, a
+	// message ID and structured data.
+	SyslogRFC5424 SyslogFormat = 1
+)
+
+func (f SyslogFormat) String() string {
+	switch f {
+	case SyslogRFC3164:
+		return "RFC3164"
+	case SyslogRFC5424:
+		return "RFC5424"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(f))
+	}
+}
+
+// SyslogSDParam is a parameter of a structured data element, with its value
+// unescaped.
+type SyslogSDParam struct {
+	Name  string
+	Value string
+}
+
+// SyslogSDElement is a structured data element of an RFC 5424 message.
+type SyslogSDElement struct {
+	ID     string
+	Params []SyslogSDParam
+}
+
+// Param returns the value of the first parameter of e named name.
+func (e *SyslogSDElement) Param(name string) (string, bool) {
+	for _, p := range e.Params {
+		if p.Name == name {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// Syslog is a syslog message, in the format of RFC 3164 or RFC 5424.
+// Messages following it, as sent over TCP with octet counting (RFC 6587,
+// section 3.4.1) or with newline terminated frames, are decoded as further
+// Syslog layers.
+//
+// RFC 3164 timestamps have no year nor time zone, so the year of their
+// Timestamp is 0 and its location is UTC.  AppName and ProcID of RFC 3164
+// messages are taken from the tag at the beginning of the message, such as
+// "sshd[42]:", which is removed from Message.  Fields with the RFC 5424
+// nil value "-" are left empty.
+type Syslog struct {
+	BaseLayer
+	OctetCounted   bool
+	Facility       SyslogFacility
+	Severity       SyslogSeverity
+	Format         SyslogFormat
+	Version        uint16 // RFC 5424 only
+	Timestamp      time.Time
+	Hostname       string
+	AppName        string
+	ProcID         string
+	MsgID          string // RFC 5424 only
+	StructuredData []SyslogSDElement
+	Message        []byte
+}
+
+// LayerType returns LayerTypeSyslog.
+func (s *Syslog) LayerType() gopacket.LayerType { return LayerTypeSyslog }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (s *Syslog) CanDecode() gopacket.LayerClass { return LayerTypeSyslog }
+
+// NextLayerType returns the layer type contained by this DecodingLayer.
+func (s *Syslog) NextLayerType() gopacket.LayerType {
+	if len(s.Payload) > 0 {
+		return LayerTypeSyslog
+	}
+	return gopacket.LayerTypeZero
+}
+
+func decodeSyslog(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&Syslog{}, data, p)
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.
+func (s *Syslog) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*s = Syslog{}
+	if len(data) == 0 {
+		df.SetTruncated()
+		return errors.New("empty syslog message")
+	}
+
+	var msg, rest []byte
+	if data[0] >= '1' && data[0] <= '9' {
+		// Octet counting: MSG-LEN SP SYSLOG-MSG
+		sp := bytes.IndexByte(data, ' ')
+		if sp < 0 || sp > 9 {
+			df.SetTruncated()
+			return errors.New("invalid syslog message length")
+		}
+		length, err := strconv.Atoi(string(data[:sp]))
+		if err != nil {
+			return fmt.Errorf("invalid syslog message length %q", data[:sp])
+		}
+		if len(data)-sp-1 < length {
+			df.SetTruncated()
+			return fmt.Errorf("syslog message length %d too long", length)
+		}
+		s.OctetCounted = true
+		msg, rest = data[sp+1:sp+1+length], data[sp+1+length:]
+	} else {
+		// The message ends at the end of the data, or at a newline
+		// followed by another message.
+		msg = data
+		if i := bytes.Index(data, []byte("\n<")); i >= 0 {
+			msg, rest = data[:i+1], data[i+1:]
+		}
+	}
+	s.Contents, s.Payload = data[:len(data)-len(rest)], rest
+
+	msg = bytes.TrimRight(msg, "\r\n\x00")
+	if len(msg) < 3 || msg[0] != '<' {
+		return errors.New("syslog message has no priority")
+	}
+	end := bytes.IndexByte(msg, '>')
+	if end < 2 || end > 4 {
+		return errors.New("invalid syslog priority")
+	}
+	pri, err := strconv.Atoi(string(msg[1:end]))
+	if err != nil || pri > 191 {
+		return fmt.Errorf("invalid syslog priority %q", msg[1:end])
+	}
+	s.Facility, s.Severity = SyslogFacility(pri>>3), SyslogSeverity(pri&7)
+	msg = msg[end+1:]
+
+	if sp := bytes.IndexByte(msg, ' '); sp > 0 && sp <= 3 && msg[0] != '0' && isSyslogDigits(msg[:sp]) {
+		version, _ := strconv.Atoi(string(msg[:sp]))
+		s.Format, s.Version = SyslogRFC5424, uint16(version)
+		return s.decodeRFC5424(msg[sp+1:])
+	}
+	s.decodeRFC3164(msg)
+	return nil
+}
+
+func (s *Syslog) decodeRFC5424(msg []byte) error {
+	var fields [5]string
+	for i := range fields {
+		sp := bytes.IndexByte(msg, ' ')
+		if sp <= 0 {
+			return errors.New("syslog header truncated")
+		}
+		if f := string(msg[:sp]); f != "-" {
+			fields[i] = f
+		}
+		msg = msg[sp+1:]
+	}
+	if fields[0] != "" {
+		t, err := time.Parse(time.RFC3339Nano, fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid syslog timestamp %q", fields[0])
+		}
+		s.Timestamp = t
+	}
+	s.Hostname, s.AppName, s.ProcID, s.MsgID = fields[1], fields[2], fields[3], fields[4]
+
+	if len(msg) > 0 && msg[0] == '-' {
+		msg = msg[1:]
+	} else {
+		var err error
+		if s.StructuredData, msg, err = decodeSyslogStructuredData(msg); err != nil {
+			return err
+		}
+	}
+	if len(msg) > 0 {
+		if msg[0] != ' ' {
+			return errors.New("invalid syslog structured data")
+		}
+		s.Message = msg[1:]
+	}
+	return nil
+}
+
+// decodeSyslogStructuredData decodes the structured data elements starting
+// msg, returning the data following them.
+func decodeSyslogStructuredData(msg []byte) ([]SyslogSDElement, []byte, error) {
+	var elements []SyslogSDElement
+	for len(msg) > 0 && msg[0] == '[' {
+		msg = msg[1:]
+		i := bytes.IndexAny(msg, " ]")
+		if i <= 0 {
+			return nil, nil, errors.New("invalid syslog structured data ID")
+		}
+		e := SyslogSDElement{ID: string(msg[:i])}
+		msg = msg[i:]
+		for len(msg) > 0 && msg[0] == ' ' {
+			eq := bytes.IndexByte(msg, '=')
+			if eq <= 1 || eq+1 == len(msg) || msg[eq+1] != '"' {
+				return nil, nil, errors.New("invalid syslog structured data parameter")
+			}
+			p := SyslogSDParam{Name: string(msg[1:eq])}
+			msg = msg[eq+2:]
+			var value []byte
+			for {
+				if len(msg) == 0 {
+					return nil, nil, errors.New("syslog structured data parameter truncated")
+				}
+				c := msg[0]
+				msg = msg[1:]
+				if c == '"' {
+					break
+				}
+				if c == '\\' && len(msg) > 0 && (msg[0] == '"' || msg[0] == '\\' || msg[0] == ']') {
+					c = msg[0]
+					msg = msg[1:]
+				}
+				value = append(value, c)
+			}
+			p.Value = string(value)
+			e.Params = append(e.Params, p)
+		}
+		if len(msg) == 0 || msg[0] != ']' {
+			return nil, nil, errors.New("syslog structured data element truncated")
+		}
+		msg = msg[1:]
+		elements = append(elements, e)
+	}
+	if elements == nil {
+		return nil, nil, errors.New("invalid syslog structured data")
+	}
+	return elements, msg, nil
+}
+
+func (s *Syslog) decodeRFC3164(msg []byte) {
+	// RFC 3164 messages without a valid timestamp are entirely MSG
+	if len(msg) > len(time.Stamp) && msg[len(time.Stamp)] == ' ' {
+		if t, err := time.Parse(time.Stamp, string(msg[:len(time.Stamp)])); err == nil {
+			s.Timestamp = t
+			msg = msg[len(time.Stamp)+1:]
+			if sp := bytes.IndexByte(msg, ' '); sp > 0 {
+				s.Hostname = string(msg[:sp])
+				msg = msg[sp+1:]
+			}
+		}
+	}
+	s.Message = msg
+
+	// TAG[PROCID]: CONTENT, with a tag of at most 32 characters
+	i := bytes.IndexAny(msg, ":[ ")
+	if i <= 0 || i > 32 {
+		return
+	}
+	tag, procID := msg[:i], []byte(nil)
+	if msg[i] == '[' {
+		end := bytes.IndexByte(msg[i:], ']')
+		if end < 0 {
+			return
+		}
+		procID = msg[i+1 : i+end]
+		i += end + 1
+	}
+	if i == len(msg) || msg[i] != ':' {
+		return
+	}
+	s.AppName, s.ProcID = string(tag), string(procID)
+	s.Message = bytes.TrimPrefix(msg[i+1:], []byte(" "))
+}
+
+func isSyslogDigits(b []byte) bool {
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
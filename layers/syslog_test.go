@@ -0,0 +1,209 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// testPacketSyslogRFC3164 is an RFC 3164 syslog message.
+var testPacketSyslogRFC3164 = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x6d, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x34, 0x65, 0xc0, 0xa8, 0x01, 0x0a, 0xc0, 0xa8,
+	0x01, 0x01, 0x02, 0x02, 0x02, 0x02, 0x00, 0x59, 0xbc, 0x14, 0x3c, 0x33, 0x34, 0x3e, 0x4f, 0x63,
+	0x74, 0x20, 0x31, 0x31, 0x20, 0x32, 0x32, 0x3a, 0x31, 0x34, 0x3a, 0x31, 0x35, 0x20, 0x6d, 0x79,
+	0x6d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x20, 0x73, 0x75, 0x5b, 0x32, 0x33, 0x30, 0x5d, 0x3a,
+	0x20, 0x27, 0x73, 0x75, 0x20, 0x72, 0x6f, 0x6f, 0x74, 0x27, 0x20, 0x66, 0x61, 0x69, 0x6c, 0x65,
+	0x64, 0x20, 0x66, 0x6f, 0x72, 0x20, 0x6c, 0x6f, 0x6e, 0x76, 0x69, 0x63, 0x6b, 0x20, 0x6f, 0x6e,
+	0x20, 0x2f, 0x64, 0x65, 0x76, 0x2f, 0x70, 0x74, 0x73, 0x2f, 0x38,
+}
+
+// testPacketSyslogRFC5424 is an RFC 5424 syslog message with structured data.
+var testPacketSyslogRFC5424 = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0xf5, 0x00, 0x00, 0x00, 0x00, 0x02, 0x11, 0x33, 0xdd, 0xc0, 0xa8, 0x01, 0x0a, 0xc0, 0xa8,
+	0x01, 0x01, 0x02, 0x02, 0x02, 0x02, 0x00, 0xe1, 0x77, 0xb6, 0x3c, 0x31, 0x36, 0x35, 0x3e, 0x31,
+	0x20, 0x32, 0x30, 0x30, 0x33, 0x2d, 0x31, 0x30, 0x2d, 0x31, 0x31, 0x54, 0x32, 0x32, 0x3a, 0x31,
+	0x34, 0x3a, 0x31, 0x35, 0x2e, 0x30, 0x30, 0x33, 0x5a, 0x20, 0x6d, 0x79, 0x6d, 0x61, 0x63, 0x68,
+	0x69, 0x6e, 0x65, 0x2e, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x2e, 0x63, 0x6f, 0x6d, 0x20,
+	0x65, 0x76, 0x6e, 0x74, 0x73, 0x6c, 0x6f, 0x67, 0x20, 0x2d, 0x20, 0x49, 0x44, 0x34, 0x37, 0x20,
+	0x5b, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x44, 0x49, 0x44, 0x40, 0x33, 0x32, 0x34,
+	0x37, 0x33, 0x20, 0x69, 0x75, 0x74, 0x3d, 0x22, 0x33, 0x22, 0x20, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x3d, 0x22, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x22, 0x20, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x3d, 0x22, 0x31, 0x30,
+	0x31, 0x31, 0x22, 0x5d, 0x5b, 0x65, 0x78, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x50, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x40, 0x33, 0x32, 0x34, 0x37, 0x33, 0x20, 0x63, 0x6c, 0x61, 0x73, 0x73,
+	0x3d, 0x22, 0x68, 0x69, 0x67, 0x68, 0x20, 0x5c, 0x22, 0x78, 0x5c, 0x22, 0x20, 0x5c, 0x5d, 0x22,
+	0x5d, 0x20, 0x41, 0x6e, 0x20, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x20, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x20, 0x6c, 0x6f, 0x67, 0x20, 0x65, 0x6e, 0x74, 0x72, 0x79,
+	0x2e, 0x2e, 0x2e,
+}
+
+// testPacketSyslogOctetCounted is a TCP segment with two octet counted RFC 5424
+// syslog messages.
+var testPacketSyslogOctetCounted = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x95, 0x00, 0x00, 0x00, 0x00, 0x02, 0x06, 0x34, 0x48, 0xc0, 0xa8, 0x01, 0x0a, 0xc0, 0xa8,
+	0x01, 0x01, 0x9c, 0x40, 0x02, 0x02, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x50, 0x18,
+	0xff, 0xff, 0xb7, 0x20, 0x00, 0x00, 0x38, 0x35, 0x20, 0x3c, 0x38, 0x36, 0x3e, 0x31, 0x20, 0x32,
+	0x30, 0x32, 0x36, 0x2d, 0x30, 0x31, 0x2d, 0x30, 0x32, 0x54, 0x30, 0x33, 0x3a, 0x30, 0x34, 0x3a,
+	0x30, 0x35, 0x2e, 0x31, 0x32, 0x33, 0x34, 0x35, 0x36, 0x2b, 0x30, 0x32, 0x3a, 0x30, 0x30, 0x20,
+	0x68, 0x6f, 0x73, 0x74, 0x20, 0x73, 0x73, 0x68, 0x64, 0x20, 0x34, 0x32, 0x34, 0x32, 0x20, 0x2d,
+	0x20, 0x2d, 0x20, 0x41, 0x63, 0x63, 0x65, 0x70, 0x74, 0x65, 0x64, 0x20, 0x70, 0x75, 0x62, 0x6c,
+	0x69, 0x63, 0x6b, 0x65, 0x79, 0x20, 0x66, 0x6f, 0x72, 0x20, 0x72, 0x6f, 0x6f, 0x74, 0x31, 0x38,
+	0x20, 0x3c, 0x33, 0x38, 0x3e, 0x31, 0x20, 0x2d, 0x20, 0x2d, 0x20, 0x2d, 0x20, 0x2d, 0x20, 0x2d,
+	0x20, 0x2d, 0x20,
+}
+
+// testPacketSyslogNewlineFramed is a TCP segment with two newline terminated
+// RFC 3164 syslog messages, the second one without header.
+var testPacketSyslogNewlineFramed = []byte{
+	0x00, 0x0c, 0x29, 0xaa, 0xbb, 0xcc, 0x00, 0x0c, 0x29, 0x11, 0x22, 0x33, 0x08, 0x00, 0x45, 0xc0,
+	0x00, 0x5c, 0x00, 0x00, 0x00, 0x00, 0x02, 0x06, 0x34, 0x81, 0xc0, 0xa8, 0x01, 0x0a, 0xc0, 0xa8,
+	0x01, 0x01, 0x9c, 0x41, 0x02, 0x02, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x50, 0x18,
+	0xff, 0xff, 0xba, 0x9a, 0x00, 0x00, 0x3c, 0x31, 0x33, 0x3e, 0x4a, 0x61, 0x6e, 0x20, 0x20, 0x32,
+	0x20, 0x30, 0x33, 0x3a, 0x30, 0x34, 0x3a, 0x30, 0x35, 0x20, 0x68, 0x6f, 0x73, 0x74, 0x20, 0x61,
+	0x70, 0x70, 0x3a, 0x20, 0x66, 0x69, 0x72, 0x73, 0x74, 0x0a, 0x3c, 0x31, 0x34, 0x3e, 0x73, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x20, 0x6c, 0x69, 0x6e, 0x65, 0x0a,
+}
+
+func TestSyslogRFC3164(t *testing.T) {
+	p := gopacket.NewPacket(testPacketSyslogRFC3164, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeSyslog}, t)
+	s := p.Layer(LayerTypeSyslog).(*Syslog)
+	want := &Syslog{
+		BaseLayer: BaseLayer{Contents: testPacketSyslogRFC3164[42:]},
+		Facility:  SyslogAuth,
+		Severity:  SyslogCritical,
+		Format:    SyslogRFC3164,
+		Timestamp: time.Date(0, time.October, 11, 22, 14, 15, 0, time.UTC),
+		Hostname:  "mymachine",
+		AppName:   "su",
+		ProcID:    "230",
+		Message:   []byte("'su root' failed for lonvick on /dev/pts/8"),
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Syslog mismatch:\ngot  %#v\nwant %#v", s, want)
+	}
+}
+
+func TestSyslogRFC5424(t *testing.T) {
+	p := gopacket.NewPacket(testPacketSyslogRFC5424, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeSyslog}, t)
+	s := p.Layer(LayerTypeSyslog).(*Syslog)
+	want := &Syslog{
+		BaseLayer: BaseLayer{Contents: testPacketSyslogRFC5424[42:]},
+		Facility:  SyslogLocal4,
+		Severity:  SyslogNotice,
+		Format:    SyslogRFC5424,
+		Version:   1,
+		Timestamp: time.Date(2003, time.October, 11, 22, 14, 15, 3000000, time.UTC),
+		Hostname:  "mymachine.example.com",
+		AppName:   "evntslog",
+		MsgID:     "ID47",
+		StructuredData: []SyslogSDElement{
+			{
+				ID: "exampleSDID@32473",
+				Params: []SyslogSDParam{
+					{"iut", "3"},
+					{"eventSource", "Application"},
+					{"eventID", "1011"},
+				},
+			},
+			{
+				ID:     "examplePriority@32473",
+				Params: []SyslogSDParam{{"class", `high "x" ]`}},
+			},
+		},
+		Message: []byte("An application event log entry..."),
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Syslog mismatch:\ngot  %#v\nwant %#v", s, want)
+	}
+	if v, ok := s.StructuredData[0].Param("eventID"); !ok || v != "1011" {
+		t.Errorf("got eventID %q, %v", v, ok)
+	}
+	if _, ok := s.StructuredData[0].Param("class"); ok {
+		t.Error("found class in exampleSDID")
+	}
+}
+
+func TestSyslogTCP(t *testing.T) {
+	p := gopacket.NewPacket(testPacketSyslogOctetCounted, LinkTypeEthernet, gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true})
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeTCP, LayerTypeSyslog, LayerTypeSyslog}, t)
+	layers := p.Layers()
+	first, second := layers[3].(*Syslog), layers[4].(*Syslog)
+	if !first.OctetCounted || first.Severity != SyslogInformational || first.Facility != SyslogAuthPriv ||
+		first.Hostname != "host" || first.AppName != "sshd" || first.ProcID != "4242" || first.MsgID != "" ||
+		string(first.Message) != "Accepted publickey for root" {
+		t.Errorf("first message mismatch: %#v", first)
+	}
+	if want := time.Date(2026, time.January, 2, 1, 4, 5, 123456000, time.UTC); !first.Timestamp.Equal(want) {
+		t.Errorf("got timestamp %v, want %v", first.Timestamp, want)
+	}
+	if !second.OctetCounted || second.Facility != SyslogAuth || second.Severity != SyslogInformational ||
+		!second.Timestamp.IsZero() || second.Hostname != "" || second.StructuredData != nil || len(second.Message) != 0 ||
+		len(second.Payload) != 0 {
+		t.Errorf("second message mismatch: %#v", second)
+	}
+
+	p = gopacket.NewPacket(testPacketSyslogNewlineFramed, LinkTypeEthernet, gopacket.DecodeOptions{DecodeStreamsAsDatagrams: true})
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeTCP, LayerTypeSyslog, LayerTypeSyslog}, t)
+	layers = p.Layers()
+	first, second = layers[3].(*Syslog), layers[4].(*Syslog)
+	if first.OctetCounted || first.Hostname != "host" || first.AppName != "app" || first.ProcID != "" ||
+		string(first.Message) != "first" || first.Timestamp != time.Date(0, time.January, 2, 3, 4, 5, 0, time.UTC) {
+		t.Errorf("first message mismatch: %#v", first)
+	}
+	if second.Severity != SyslogInformational || second.Facility != SyslogUser || !second.Timestamp.IsZero() ||
+		second.Hostname != "" || second.AppName != "" || string(second.Message) != "second line" {
+		t.Errorf("second message mismatch: %#v", second)
+	}
+}
+
+func TestSyslogDecodeInvalid(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"no priority", "Oct 11 22:14:15 host app: message"},
+		{"unterminated priority", "<134 message"},
+		{"priority too large", "<192>message"},
+		{"invalid priority", "<1a>message"},
+		{"octet count too long", "20 <13>message"},
+		{"octet count without space", "12345678901"},
+		{"truncated header", "<13>1 2003-10-11T22:14:15Z host app"},
+		{"invalid timestamp", "<13>1 yesterday host app - - -"},
+		{"invalid structured data", "<13>1 - host app - - x"},
+		{"unterminated structured data", `<13>1 - host app - - [id a="b"`},
+		{"unterminated parameter value", `<13>1 - host app - - [id a="b]`},
+		{"parameter without value", `<13>1 - host app - - [id a]`},
+		{"no space before message", `<13>1 - host app - - [id]message`},
+	} {
+		var s Syslog
+		if err := s.DecodeFromBytes([]byte(test.data), gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: decoded successfully", test.name)
+		}
+	}
+}
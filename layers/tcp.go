@@ -194,6 +194,13 @@ func (t *TCP) ComputeChecksum() (uint16, error) {
 	return t.computeChecksum(append(t.Contents, t.Payload...), IPProtocolTCP)
 }
 
+// VerifyChecksum verifies the checksum of the TCP header and payload,
+// implementing gopacket.ChecksumVerifier.  SetNetworkLayerForChecksum must be
+// called before.
+func (t *TCP) VerifyChecksum() (bool, error) {
+	return t.verifyChecksum(t.Contents, t.Payload, IPProtocolTCP)
+}
+
 func (t *TCP) flagsAndOffset() uint16 {
 	f := uint16(t.DataOffset) << 12
 	if t.FIN {
@@ -306,6 +313,17 @@ func (t *TCP) CanDecode() gopacket.LayerClass {
 }
 
 func (t *TCP) NextLayerType() gopacket.LayerType {
+	return t.nextLayerType(nil)
+}
+
+// nextLayerType is NextLayerType with the port mappings of profile, if not nil
+func (t *TCP) nextLayerType(profile *gopacket.DecodeProfile) gopacket.LayerType {
+	if lt, ok := profile.PortLayerType(EndpointTCPPort, uint16(t.DstPort)); ok {
+		return lt
+	}
+	if lt, ok := profile.PortLayerType(EndpointTCPPort, uint16(t.SrcPort)); ok {
+		return lt
+	}
 	lt := t.DstPort.LayerType()
 	if lt == gopacket.LayerTypePayload {
 		lt = t.SrcPort.LayerType()
@@ -322,7 +340,11 @@ func decodeTCP(data []byte, p gopacket.PacketBuilder) error {
 		return err
 	}
 	if p.DecodeOptions().DecodeStreamsAsDatagrams {
-		return p.NextDecoder(tcp.NextLayerType())
+		next := tcp.nextLayerType(p.DecodeOptions().Profile)
+		if next == gopacket.LayerTypePayload {
+			return p.NextDecoder(gopacket.UnknownProtocolDecoder(gopacket.UnknownTCPPort, int(tcp.DstPort), next))
+		}
+		return p.NextDecoder(next)
 	} else {
 		return p.NextDecoder(gopacket.LayerTypePayload)
 	}
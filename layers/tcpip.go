@@ -87,6 +87,31 @@ func (c *tcpipchecksum) computeChecksum(headerAndPayload []byte, headerProtocol
 	return tcpipChecksum(headerAndPayload, csum), nil
 }
 
+// verifyChecksum verifies a TCP or UDP checksum.  header is the TCP or UDP
+// header including its checksum, and must have an even length.
+func (c *tcpipchecksum) verifyChecksum(header, payload []byte, headerProtocol IPProtocol) (bool, error) {
+	if c.pseudoheader == nil {
+		return false, errors.New("TCP/IP layer 4 checksum cannot be verified without network layer... call SetNetworkLayerForChecksum to set which layer to use")
+	}
+	length := uint32(len(header) + len(payload))
+	csum, err := c.pseudoheader.pseudoheaderChecksum()
+	if err != nil {
+		return false, err
+	}
+	csum += uint32(headerProtocol)
+	csum += length & 0xffff
+	csum += length >> 16
+	return tcpipChecksumValid(header, payload, csum), nil
+}
+
+// tcpipChecksumValid returns true if the rfc1071 checksum of header and
+// payload, including their checksum field, is valid.  header must have an
+// even length.
+func tcpipChecksumValid(header, payload []byte, csum uint32) bool {
+	csum = uint32(^tcpipChecksum(header, csum))
+	return tcpipChecksum(payload, csum) == 0
+}
+
 // SetNetworkLayerForChecksum tells this layer which network layer is wrapping it.
 // This is needed for computing the checksum when serializing, since TCP/IP transport
 // layer checksums depends on fields in the IPv4 or IPv6 layer that contains it.
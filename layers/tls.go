@@ -61,6 +61,12 @@ func (tv TLSVersion) String() string {
 		return "TLS 1.2"
 	case 0x0304:
 		return "TLS 1.3"
+	case 0xfeff:
+		return "DTLS 1.0"
+	case 0xfefd:
+		return "DTLS 1.2"
+	case 0xfefc:
+		return "DTLS 1.3"
 	}
 }
 
@@ -124,10 +130,10 @@ func (t *TLS) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
 	t.AppData = t.AppData[:0]
 	t.Alert = t.Alert[:0]
 
-	return t.decodeTLSRecords(data, df)
+	return t.decodeTLSRecords(data, df, &tlsHandshakeState{})
 }
 
-func (t *TLS) decodeTLSRecords(data []byte, df gopacket.DecodeFeedback) error {
+func (t *TLS) decodeTLSRecords(data []byte, df gopacket.DecodeFeedback, s *tlsHandshakeState) error {
 	if len(data) < 5 {
 		df.SetTruncated()
 		return errors.New("TLS record too short")
@@ -164,6 +170,7 @@ func (t *TLS) decodeTLSRecords(data []byte, df gopacket.DecodeFeedback) error {
 			return e
 		}
 		t.ChangeCipherSpec = append(t.ChangeCipherSpec, r)
+		s.changedCipher = true
 	case TLSAlert:
 		var r TLSAlertRecord
 		e := r.decodeFromBytes(h, data[hl:tl], df)
@@ -173,7 +180,7 @@ func (t *TLS) decodeTLSRecords(data []byte, df gopacket.DecodeFeedback) error {
 		t.Alert = append(t.Alert, r)
 	case TLSHandshake:
 		var r TLSHandshakeRecord
-		e := r.decodeFromBytes(h, data[hl:tl], df)
+		e := r.decodeFromBytes(h, data[hl:tl], s, df)
 		if e != nil {
 			return e
 		}
@@ -190,7 +197,7 @@ func (t *TLS) decodeTLSRecords(data []byte, df gopacket.DecodeFeedback) error {
 	if len(data) == tl {
 		return nil
 	}
-	return t.decodeTLSRecords(data[tl:len(data)], df)
+	return t.decodeTLSRecords(data[tl:len(data)], df, s)
 }
 
 // CanDecode implements gopacket.DecodingLayer.
@@ -281,3 +288,46 @@ func encodeHeader(header TLSRecordHeader, data []byte, offset int) int {
 
 	return offset + 5
 }
+
+// TLSStream decodes the records sent in one direction of a TLS connection,
+// whose data is passed to Decode as it's reassembled, e.g. by a
+// reassembly.Stream.  Handshake messages fragmented over several records are
+// coalesced across calls.
+type TLSStream struct {
+	buf    []byte
+	state  tlsHandshakeState
+	failed bool
+}
+
+// Decode decodes data following the data of previous calls, and returns a
+// TLS layer with the records completed by data, or nil if there's none.  The
+// returned layer references the data.  After an error, the stream can't be
+// decoded any further.
+func (s *TLSStream) Decode(data []byte) (*TLS, error) {
+	if s.failed {
+		return nil, nil
+	}
+	s.buf = append(s.buf, data...)
+	n := 0
+	for len(s.buf)-n >= 5 {
+		l := 5 + int(binary.BigEndian.Uint16(s.buf[n+3:]))
+		if len(s.buf)-n < l {
+			break
+		}
+		n += l
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	t := &TLS{}
+	if err := t.decodeTLSRecords(s.buf[:n], gopacket.NilDecodeFeedback, &s.state); err != nil {
+		s.failed = true
+		return nil, err
+	}
+	t.Contents = s.buf[:n]
+	s.buf = s.buf[n:]
+	if len(s.buf) == 0 {
+		s.buf = nil
+	}
+	return t, nil
+}
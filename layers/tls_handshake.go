@@ -7,22 +7,499 @@
 package layers
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
 	"github.com/google/gopacket"
 )
 
+// TLSHandshakeType defines the type of a handshake message
+type TLSHandshakeType uint8
+
+// TLSHandshakeType known values.
+const (
+	TLSHandshakeHelloRequest        TLSHandshakeType = 0
+	TLSHandshakeClientHello         TLSHandshakeType = 1
+	TLSHandshakeServerHello         TLSHandshakeType = 2
+	TLSHandshakeHelloVerifyRequest  TLSHandshakeType = 3
+	TLSHandshakeNewSessionTicket    TLSHandshakeType = 4
+	TLSHandshakeEndOfEarlyData      TLSHandshakeType = 5
+	TLSHandshakeEncryptedExtensions TLSHandshakeType = 8
+	TLSHandshakeCertificate         TLSHandshakeType = 11
+	TLSHandshakeServerKeyExchange   TLSHandshakeType = 12
+	TLSHandshakeCertificateRequest  TLSHandshakeType = 13
+	TLSHandshakeServerHelloDone     TLSHandshakeType = 14
+	TLSHandshakeCertificateVerify   TLSHandshakeType = 15
+	TLSHandshakeClientKeyExchange   TLSHandshakeType = 16
+	TLSHandshakeFinished            TLSHandshakeType = 20
+	TLSHandshakeCertificateStatus   TLSHandshakeType = 22
+	TLSHandshakeKeyUpdate           TLSHandshakeType = 24
+)
+
+// String shows the handshake type nicely formatted
+func (ht TLSHandshakeType) String() string {
+	switch ht {
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(ht))
+	case TLSHandshakeHelloRequest:
+		return "HelloRequest"
+	case TLSHandshakeClientHello:
+		return "ClientHello"
+	case TLSHandshakeServerHello:
+		return "ServerHello"
+	case TLSHandshakeHelloVerifyRequest:
+		return "HelloVerifyRequest"
+	case TLSHandshakeNewSessionTicket:
+		return "NewSessionTicket"
+	case TLSHandshakeEndOfEarlyData:
+		return "EndOfEarlyData"
+	case TLSHandshakeEncryptedExtensions:
+		return "EncryptedExtensions"
+	case TLSHandshakeCertificate:
+		return "Certificate"
+	case TLSHandshakeServerKeyExchange:
+		return "ServerKeyExchange"
+	case TLSHandshakeCertificateRequest:
+		return "CertificateRequest"
+	case TLSHandshakeServerHelloDone:
+		return "ServerHelloDone"
+	case TLSHandshakeCertificateVerify:
+		return "CertificateVerify"
+	case TLSHandshakeClientKeyExchange:
+		return "ClientKeyExchange"
+	case TLSHandshakeFinished:
+		return "Finished"
+	case TLSHandshakeCertificateStatus:
+		return "CertificateStatus"
+	case TLSHandshakeKeyUpdate:
+		return "KeyUpdate"
+	}
+}
+
+// known returns true for the handshake types above, which are the ones
+// expected at the start of a plaintext TLS handshake record.
+func (ht TLSHandshakeType) known() bool {
+	switch ht {
+	case TLSHandshakeHelloRequest, TLSHandshakeClientHello, TLSHandshakeServerHello,
+		TLSHandshakeNewSessionTicket, TLSHandshakeEndOfEarlyData, TLSHandshakeEncryptedExtensions,
+		TLSHandshakeCertificate, TLSHandshakeServerKeyExchange, TLSHandshakeCertificateRequest,
+		TLSHandshakeServerHelloDone, TLSHandshakeCertificateVerify, TLSHandshakeClientKeyExchange,
+		TLSHandshakeFinished, TLSHandshakeCertificateStatus, TLSHandshakeKeyUpdate:
+		return true
+	}
+	return false
+}
+
+// TLSExtensionType defines the type of a hello extension
+type TLSExtensionType uint16
+
+// TLSExtensionType values decoded by TLSHandshakeMessage, and some other
+// common ones.
+const (
+	TLSExtensionServerName           TLSExtensionType = 0
+	TLSExtensionStatusRequest        TLSExtensionType = 5
+	TLSExtensionSupportedGroups      TLSExtensionType = 10
+	TLSExtensionECPointFormats       TLSExtensionType = 11
+	TLSExtensionSignatureAlgorithms  TLSExtensionType = 13
+	TLSExtensionALPN                 TLSExtensionType = 16
+	TLSExtensionExtendedMasterSecret TLSExtensionType = 23
+	TLSExtensionSessionTicket        TLSExtensionType = 35
+	TLSExtensionPreSharedKey         TLSExtensionType = 41
+	TLSExtensionEarlyData            TLSExtensionType = 42
+	TLSExtensionSupportedVersions    TLSExtensionType = 43
+	TLSExtensionCookie               TLSExtensionType = 44
+	TLSExtensionPSKKeyExchangeModes  TLSExtensionType = 45
+	TLSExtensionKeyShare             TLSExtensionType = 51
+	TLSExtensionEncryptedClientHello TLSExtensionType = 0xfe0d
+	TLSExtensionRenegotiationInfo    TLSExtensionType = 0xff01
+)
+
+// String shows the extension type nicely formatted
+func (et TLSExtensionType) String() string {
+	switch et {
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint16(et))
+	case TLSExtensionServerName:
+		return "server_name"
+	case TLSExtensionStatusRequest:
+		return "status_request"
+	case TLSExtensionSupportedGroups:
+		return "supported_groups"
+	case TLSExtensionECPointFormats:
+		return "ec_point_formats"
+	case TLSExtensionSignatureAlgorithms:
+		return "signature_algorithms"
+	case TLSExtensionALPN:
+		return "application_layer_protocol_negotiation"
+	case TLSExtensionExtendedMasterSecret:
+		return "extended_master_secret"
+	case TLSExtensionSessionTicket:
+		return "session_ticket"
+	case TLSExtensionPreSharedKey:
+		return "pre_shared_key"
+	case TLSExtensionEarlyData:
+		return "early_data"
+	case TLSExtensionSupportedVersions:
+		return "supported_versions"
+	case TLSExtensionCookie:
+		return "cookie"
+	case TLSExtensionPSKKeyExchangeModes:
+		return "psk_key_exchange_modes"
+	case TLSExtensionKeyShare:
+		return "key_share"
+	case TLSExtensionEncryptedClientHello:
+		return "encrypted_client_hello"
+	case TLSExtensionRenegotiationInfo:
+		return "renegotiation_info"
+	}
+}
+
+// TLSExtension is an extension of a hello message
+type TLSExtension struct {
+	Type TLSExtensionType
+	Data []byte
+}
+
+// TLSKeyShare is a key share of the key_share extension
+type TLSKeyShare struct {
+	Group       uint16
+	KeyExchange []byte
+}
+
+// TLSHandshakeMessage is a handshake message.  The fields of ClientHello and
+// ServerHello messages, and the extensions of EncryptedExtensions messages,
+// are decoded.
+type TLSHandshakeMessage struct {
+	Type TLSHandshakeType
+	// Body is the message without its type and length
+	Body []byte
+
+	// Version, Random and SessionID are the legacy version, the random and
+	// the legacy session ID of hellos
+	Version   TLSVersion
+	Random    []byte
+	SessionID []byte
+	// Cookie is the cookie of DTLS ClientHello and HelloVerifyRequest
+	// messages
+	Cookie []byte
+	// CipherSuites and CompressionMethods are the values offered by a
+	// ClientHello, or the single value selected by a ServerHello
+	CipherSuites       []uint16
+	CompressionMethods []uint8
+	Extensions         []TLSExtension
+
+	// The following fields are decoded from the extensions.
+
+	// ServerName is the host name of the server_name extension
+	ServerName string
+	// ALPN are the protocols of the application_layer_protocol_negotiation
+	// extension
+	ALPN []string
+	// SupportedVersions are the versions offered by a ClientHello, or the
+	// version selected by a ServerHello
+	SupportedVersions   []TLSVersion
+	SupportedGroups     []uint16
+	SignatureAlgorithms []uint16
+	// KeyShares are the key shares offered by a ClientHello, or the one
+	// selected by a ServerHello.  The key share of a HelloRetryRequest only
+	// has a group.
+	KeyShares           []TLSKeyShare
+	PSKKeyExchangeModes []uint8
+	// PreSharedKey, EarlyData and EncryptedClientHello tell if the
+	// pre_shared_key, early_data and encrypted_client_hello extensions are
+	// present
+	PreSharedKey         bool
+	EarlyData            bool
+	EncryptedClientHello bool
+}
+
+// tlsHelloRetryRequestRandom is the random of HelloRetryRequest messages,
+// RFC 8446 section 4.1.3.
+var tlsHelloRetryRequestRandom = []byte{
+	0xcf, 0x21, 0xad, 0x74, 0xe5, 0x9a, 0x61, 0x11, 0xbe, 0x1d, 0x8c, 0x02, 0x1e, 0x65, 0xb8, 0x91,
+	0xc2, 0xa2, 0x11, 0x16, 0x7a, 0xbb, 0x8c, 0x5e, 0x07, 0x9e, 0x09, 0xe2, 0xc8, 0xa8, 0x33, 0x9c,
+}
+
+// IsHelloRetryRequest returns true for the ServerHello messages used as
+// HelloRetryRequest by TLS 1.3 servers.
+func (m *TLSHandshakeMessage) IsHelloRetryRequest() bool {
+	return m.Type == TLSHandshakeServerHello && bytes.Equal(m.Random, tlsHelloRetryRequestRandom)
+}
+
+// SelectedVersion returns the version selected by a ServerHello: the one of
+// the supported_versions extension, or the legacy version.  If it's TLS 1.3,
+// the rest of the handshake is encrypted, and sent in application data
+// records.
+func (m *TLSHandshakeMessage) SelectedVersion() TLSVersion {
+	if len(m.SupportedVersions) == 1 {
+		return m.SupportedVersions[0]
+	}
+	return m.Version
+}
+
 // TLSHandshakeRecord defines the structure of a Handshare Record
 type TLSHandshakeRecord struct {
 	TLSRecordHeader
+	// Messages are the handshake messages ending in the record.  Messages
+	// fragmented over several records are decoded with the last record.
+	Messages []TLSHandshakeMessage
+	// Encrypted is set for records following a Change Cipher Spec record,
+	// like the Finished messages of TLS 1.2, and for other records not
+	// starting with a known handshake message type.
+	Encrypted bool
+}
+
+// tlsHandshakeState is the state of the handshake kept across records
+type tlsHandshakeState struct {
+	// fragment is the start of a handshake message continued by the next
+	// handshake record
+	fragment []byte
+	// changedCipher is set after a Change Cipher Spec record
+	changedCipher bool
 }
 
 // DecodeFromBytes decodes the slice into the TLS struct.
-func (t *TLSHandshakeRecord) decodeFromBytes(h TLSRecordHeader, data []byte, df gopacket.DecodeFeedback) error {
+func (t *TLSHandshakeRecord) decodeFromBytes(h TLSRecordHeader, data []byte, s *tlsHandshakeState, df gopacket.DecodeFeedback) error {
 	// TLS Record Header
 	t.ContentType = h.ContentType
 	t.Version = h.Version
 	t.Length = h.Length
 
-	// TODO
+	if s.changedCipher || (len(s.fragment) == 0 && len(data) > 0 && !TLSHandshakeType(data[0]).known()) {
+		t.Encrypted = true
+		return nil
+	}
+	if len(s.fragment) > 0 {
+		data = append(s.fragment, data...)
+		s.fragment = nil
+	}
+	for len(data) > 0 {
+		if len(data) < 4 {
+			s.fragment = append([]byte(nil), data...)
+			break
+		}
+		n := 4 + (int(data[1])<<16 | int(data[2])<<8 | int(data[3]))
+		if len(data) < n {
+			s.fragment = append([]byte(nil), data...)
+			break
+		}
+		var m TLSHandshakeMessage
+		if err := m.decodeFromBytes(data[:n]); err != nil {
+			return err
+		}
+		t.Messages = append(t.Messages, m)
+		data = data[n:]
+	}
+	return nil
+}
+
+// tlsReader reads the fields of handshake messages.  Once a field is
+// missing, err is set and all following reads return zero values.
+type tlsReader struct {
+	data []byte
+	err  bool
+}
+
+func (r *tlsReader) bytes(n int) []byte {
+	if r.err || len(r.data) < n {
+		r.err = true
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *tlsReader) uint8() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *tlsReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+// vector8 and vector16 read vectors with a length of 1 and 2 bytes
+func (r *tlsReader) vector8() []byte {
+	return r.bytes(int(r.uint8()))
+}
+
+func (r *tlsReader) vector16() []byte {
+	return r.bytes(int(r.uint16()))
+}
+
+// uint16s reads a vector of uint16 values
+func (r *tlsReader) uint16s(vector []byte) []uint16 {
+	if len(vector)%2 != 0 {
+		r.err = true
+		return nil
+	}
+	values := make([]uint16, len(vector)/2)
+	for i := range values {
+		values[i] = binary.BigEndian.Uint16(vector[2*i:])
+	}
+	return values
+}
+
+// DecodeFromBytes decodes the handshake message at the start of data, which
+// isn't in TLS records, like the messages in the CRYPTO frames of QUIC.  The
+// message ends after its Body.
+func (m *TLSHandshakeMessage) DecodeFromBytes(data []byte) error {
+	if len(data) < 4 {
+		return errors.New("TLS handshake message too short")
+	}
+	n := 4 + (int(data[1])<<16 | int(data[2])<<8 | int(data[3]))
+	if len(data) < n {
+		return errors.New("TLS handshake message too short")
+	}
+	*m = TLSHandshakeMessage{}
+	return m.decodeFromBytes(data[:n])
+}
+
+// decodeFromBytes decodes a message including its header.
+func (m *TLSHandshakeMessage) decodeFromBytes(data []byte) error {
+	m.Type = TLSHandshakeType(data[0])
+	return m.decodeBody(data[4:], false)
+}
+
+// decodeBody decodes the body of a message of type m.Type.  The hellos of
+// DTLS have cookies.
+func (m *TLSHandshakeMessage) decodeBody(body []byte, dtls bool) error {
+	m.Body = body
+	r := &tlsReader{data: m.Body}
+	var extensions []byte
+	switch m.Type {
+	default:
+		return nil
+	case TLSHandshakeClientHello:
+		m.Version = TLSVersion(r.uint16())
+		m.Random = r.bytes(32)
+		m.SessionID = r.vector8()
+		if dtls {
+			m.Cookie = r.vector8()
+		}
+		m.CipherSuites = r.uint16s(r.vector16())
+		m.CompressionMethods = r.vector8()
+	case TLSHandshakeServerHello:
+		m.Version = TLSVersion(r.uint16())
+		m.Random = r.bytes(32)
+		m.SessionID = r.vector8()
+		m.CipherSuites = []uint16{r.uint16()}
+		m.CompressionMethods = r.bytes(1)
+	case TLSHandshakeHelloVerifyRequest:
+		if !dtls {
+			return nil
+		}
+		m.Version = TLSVersion(r.uint16())
+		m.Cookie = r.vector8()
+		if r.err || len(r.data) > 0 {
+			return fmt.Errorf("TLS %v malformed", m.Type)
+		}
+		return nil
+	case TLSHandshakeEncryptedExtensions:
+	}
+	// extensions are optional in hellos before TLS 1.2
+	if !r.err && (len(r.data) > 0 || m.Type == TLSHandshakeEncryptedExtensions) {
+		extensions = r.vector16()
+	}
+	if r.err || len(r.data) > 0 {
+		return fmt.Errorf("TLS %v malformed", m.Type)
+	}
+	return m.decodeExtensions(extensions)
+}
+
+// decodeExtensions decodes the extensions of a message.
+func (m *TLSHandshakeMessage) decodeExtensions(data []byte) error {
+	r := &tlsReader{data: data}
+	for len(r.data) > 0 && !r.err {
+		e := TLSExtension{Type: TLSExtensionType(r.uint16()), Data: r.vector16()}
+		if r.err {
+			break
+		}
+		m.Extensions = append(m.Extensions, e)
+		if err := m.decodeExtension(e); err != nil {
+			return err
+		}
+	}
+	if r.err {
+		return fmt.Errorf("TLS %v extensions malformed", m.Type)
+	}
+	return nil
+}
 
+// decodeExtension decodes the fields of an extension.  The extensions of
+// ServerHello and HelloRetryRequest messages hold single values.
+func (m *TLSHandshakeMessage) decodeExtension(e TLSExtension) error {
+	r := &tlsReader{data: e.Data}
+	client := m.Type == TLSHandshakeClientHello
+	switch e.Type {
+	default:
+		return nil
+	case TLSExtensionServerName:
+		// servers acknowledge the extension with empty data
+		if len(e.Data) == 0 {
+			return nil
+		}
+		list := &tlsReader{data: r.vector16()}
+		for len(list.data) > 0 && !list.err {
+			nameType, name := list.uint8(), list.vector16()
+			if nameType == 0 {
+				m.ServerName = string(name)
+			}
+		}
+		r.err = r.err || list.err
+	case TLSExtensionALPN:
+		list := &tlsReader{data: r.vector16()}
+		for len(list.data) > 0 && !list.err {
+			m.ALPN = append(m.ALPN, string(list.vector8()))
+		}
+		r.err = r.err || list.err
+	case TLSExtensionSupportedVersions:
+		if client {
+			for _, v := range r.uint16s(r.vector8()) {
+				m.SupportedVersions = append(m.SupportedVersions, TLSVersion(v))
+			}
+		} else {
+			m.SupportedVersions = []TLSVersion{TLSVersion(r.uint16())}
+		}
+	case TLSExtensionSupportedGroups:
+		m.SupportedGroups = r.uint16s(r.vector16())
+	case TLSExtensionSignatureAlgorithms:
+		m.SignatureAlgorithms = r.uint16s(r.vector16())
+	case TLSExtensionKeyShare:
+		switch {
+		case client:
+			list := &tlsReader{data: r.vector16()}
+			for len(list.data) > 0 && !list.err {
+				m.KeyShares = append(m.KeyShares, TLSKeyShare{Group: list.uint16(), KeyExchange: list.vector16()})
+			}
+			r.err = r.err || list.err
+		case m.IsHelloRetryRequest():
+			m.KeyShares = []TLSKeyShare{{Group: r.uint16()}}
+		default:
+			m.KeyShares = []TLSKeyShare{{Group: r.uint16(), KeyExchange: r.vector16()}}
+		}
+	case TLSExtensionPSKKeyExchangeModes:
+		m.PSKKeyExchangeModes = r.vector8()
+	case TLSExtensionPreSharedKey:
+		m.PreSharedKey = true
+		return nil
+	case TLSExtensionEarlyData:
+		m.EarlyData = true
+		return nil
+	case TLSExtensionEncryptedClientHello:
+		m.EncryptedClientHello = true
+		return nil
+	}
+	if r.err || len(r.data) > 0 {
+		return fmt.Errorf("TLS %v extension malformed", e.Type)
+	}
 	return nil
 }
@@ -5,6 +5,8 @@
 package layers
 
 import (
+	"bytes"
+	"encoding/binary"
 	"reflect"
 	"testing"
 
@@ -42,11 +44,38 @@ var testClientHelloDecoded = &TLS{
 	ChangeCipherSpec: nil,
 	Handshake: []TLSHandshakeRecord{
 		{
-			TLSRecordHeader{
+			TLSRecordHeader: TLSRecordHeader{
 				ContentType: 22,
 				Version:     0x0301,
 				Length:      209,
 			},
+			Messages: []TLSHandshakeMessage{
+				{
+					Type:      TLSHandshakeClientHello,
+					Body:      testClientHello[63:],
+					Version:   0x0301,
+					Random:    testClientHello[65:97],
+					SessionID: testClientHello[98:98],
+					CipherSuites: []uint16{
+						0xc014, 0xc00a, 0x0039, 0x0038, 0x0088, 0x0087, 0xc00f, 0xc005, 0x0035, 0x0084, 0xc013, 0xc009,
+						0x0033, 0x0032, 0x009a, 0x0099, 0x0045, 0x0044, 0xc00e, 0xc004, 0x002f, 0x0096, 0x0041, 0xc011,
+						0xc007, 0xc00c, 0xc002, 0x0005, 0x0004, 0xc012, 0xc008, 0x0016, 0x0013, 0xc00d, 0xc003, 0x000a,
+						0x0015, 0x0012, 0x0009, 0x0014, 0x0011, 0x0008, 0x0006, 0x0003, 0x00ff,
+					},
+					CompressionMethods: testClientHello[191:193],
+					Extensions: []TLSExtension{
+						{TLSExtensionECPointFormats, testClientHello[199:203]},
+						{TLSExtensionSupportedGroups, testClientHello[207:259]},
+						{TLSExtensionSessionTicket, testClientHello[263:263]},
+						{15, testClientHello[267:268]}, // heartbeat
+					},
+					SupportedGroups: []uint16{
+						0x000e, 0x000d, 0x0019, 0x000b, 0x000c, 0x0018, 0x0009, 0x000a, 0x0016, 0x0017, 0x0008, 0x0006,
+						0x0007, 0x0014, 0x0015, 0x0004, 0x0005, 0x0012, 0x0013, 0x0001, 0x0002, 0x0003, 0x000f, 0x0010,
+						0x0011,
+					},
+				},
+			},
 		},
 	},
 	AppData: nil,
@@ -116,18 +145,22 @@ var testClientKeyExchangeDecoded = &TLS{
 	},
 	Handshake: []TLSHandshakeRecord{
 		{
-			TLSRecordHeader{
+			TLSRecordHeader: TLSRecordHeader{
 				ContentType: 22,
 				Version:     0x0301,
 				Length:      70,
 			},
+			Messages: []TLSHandshakeMessage{
+				{Type: TLSHandshakeClientKeyExchange, Body: testClientKeyExchange[9:75]},
+			},
 		},
 		{
-			TLSRecordHeader{
+			TLSRecordHeader: TLSRecordHeader{
 				ContentType: 22,
 				Version:     0x0301,
 				Length:      48,
 			},
+			Encrypted: true,
 		},
 	},
 	AppData: nil,
@@ -389,3 +422,180 @@ func TestSerializeTLSAlertEncrypted(t *testing.T) {
 		t.Error("No TLS layer type found in reconstructed packet")
 	}
 }
+
+func TestParseTLSServerHello(t *testing.T) {
+	p := gopacket.NewPacket(testServerHello, LayerTypeTLS, testTLSDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	tls := p.Layer(LayerTypeTLS).(*TLS)
+	var types []TLSHandshakeType
+	for _, r := range tls.Handshake {
+		for _, m := range r.Messages {
+			types = append(types, m.Type)
+		}
+	}
+	if !reflect.DeepEqual(types, []TLSHandshakeType{TLSHandshakeServerHello, TLSHandshakeCertificate, TLSHandshakeServerHelloDone}) {
+		t.Fatalf("unexpected handshake messages %v", types)
+	}
+	hello := tls.Handshake[0].Messages[0]
+	if hello.Version != 0x0301 || hello.SelectedVersion() != 0x0301 || !reflect.DeepEqual(hello.CipherSuites, []uint16{0x002f}) ||
+		len(hello.Extensions) != 3 || hello.Extensions[0].Type != TLSExtensionRenegotiationInfo || hello.IsHelloRetryRequest() {
+		t.Errorf("unexpected ServerHello %#v", hello)
+	}
+}
+
+// tlsTestVector returns data prefixed with its length of n bytes.
+func tlsTestVector(n int, data ...[]byte) []byte {
+	v := bytes.Join(data, nil)
+	l := make([]byte, 4)
+	binary.BigEndian.PutUint32(l, uint32(len(v)))
+	return append(l[4-n:], v...)
+}
+
+// tlsTestExtension returns an extension.
+func tlsTestExtension(typ TLSExtensionType, data ...[]byte) []byte {
+	return append([]byte{byte(typ >> 8), byte(typ)}, tlsTestVector(2, data...)...)
+}
+
+// tlsTestMessage returns a handshake message.
+func tlsTestMessage(typ TLSHandshakeType, body ...[]byte) []byte {
+	return append([]byte{byte(typ)}, tlsTestVector(3, body...)...)
+}
+
+// tlsTestRecord returns a handshake record.
+func tlsTestRecord(fragment []byte) []byte {
+	return append([]byte{22, 3, 1}, tlsTestVector(2, fragment)...)
+}
+
+var (
+	testTLS13Random = bytes.Repeat([]byte{0xab}, 32)
+	testTLS13Key    = bytes.Repeat([]byte{0xcd}, 32)
+
+	testTLS13ClientHello = tlsTestMessage(TLSHandshakeClientHello,
+		[]byte{3, 3}, testTLS13Random, tlsTestVector(1, bytes.Repeat([]byte{1}, 32)),
+		tlsTestVector(2, []byte{0x13, 0x01, 0x13, 0x02}), tlsTestVector(1, []byte{0}),
+		tlsTestVector(2,
+			tlsTestExtension(TLSExtensionServerName, tlsTestVector(2, []byte{0}, tlsTestVector(2, []byte("example.com")))),
+			tlsTestExtension(TLSExtensionALPN, tlsTestVector(2, tlsTestVector(1, []byte("h2")), tlsTestVector(1, []byte("http/1.1")))),
+			tlsTestExtension(TLSExtensionSupportedVersions, tlsTestVector(1, []byte{3, 4, 3, 3})),
+			tlsTestExtension(TLSExtensionSupportedGroups, tlsTestVector(2, []byte{0, 0x1d, 0, 0x17})),
+			tlsTestExtension(TLSExtensionSignatureAlgorithms, tlsTestVector(2, []byte{4, 3, 8, 4})),
+			tlsTestExtension(TLSExtensionKeyShare, tlsTestVector(2, []byte{0, 0x1d}, tlsTestVector(2, testTLS13Key))),
+			tlsTestExtension(TLSExtensionPSKKeyExchangeModes, tlsTestVector(1, []byte{1})),
+			tlsTestExtension(TLSExtensionEarlyData),
+			tlsTestExtension(TLSExtensionEncryptedClientHello, []byte{0, 1, 2}),
+		))
+)
+
+func TestTLS13ClientHello(t *testing.T) {
+	var tls TLS
+	if err := tls.DecodeFromBytes(tlsTestRecord(testTLS13ClientHello), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if len(tls.Handshake) != 1 || len(tls.Handshake[0].Messages) != 1 {
+		t.Fatalf("unexpected handshake records %#v", tls.Handshake)
+	}
+	m := tls.Handshake[0].Messages[0]
+	if m.Type != TLSHandshakeClientHello || m.Version != 0x0303 || !bytes.Equal(m.Random, testTLS13Random) || len(m.SessionID) != 32 ||
+		!reflect.DeepEqual(m.CipherSuites, []uint16{0x1301, 0x1302}) || len(m.Extensions) != 9 {
+		t.Errorf("unexpected ClientHello %#v", m)
+	}
+	if m.ServerName != "example.com" || !reflect.DeepEqual(m.ALPN, []string{"h2", "http/1.1"}) ||
+		!reflect.DeepEqual(m.SupportedVersions, []TLSVersion{0x0304, 0x0303}) ||
+		!reflect.DeepEqual(m.SupportedGroups, []uint16{0x1d, 0x17}) || !reflect.DeepEqual(m.SignatureAlgorithms, []uint16{0x0403, 0x0804}) ||
+		!reflect.DeepEqual(m.KeyShares, []TLSKeyShare{{0x1d, testTLS13Key}}) || !bytes.Equal(m.PSKKeyExchangeModes, []byte{1}) ||
+		m.PreSharedKey || !m.EarlyData || !m.EncryptedClientHello {
+		t.Errorf("unexpected ClientHello extensions %#v", m)
+	}
+
+	// malformed hellos and extensions
+	for _, data := range [][]byte{
+		tlsTestMessage(TLSHandshakeClientHello, []byte{3, 3}, testTLS13Random),
+		tlsTestMessage(TLSHandshakeClientHello, testTLS13ClientHello[4:], []byte{0}),
+		tlsTestMessage(TLSHandshakeServerHello, []byte{3, 3}, testTLS13Random, []byte{0, 0x13, 0x01, 0},
+			tlsTestVector(2, tlsTestExtension(TLSExtensionSupportedVersions, []byte{3}))),
+		tlsTestMessage(TLSHandshakeEncryptedExtensions, tlsTestVector(2, tlsTestExtension(TLSExtensionALPN, []byte{0, 3, 2, 'h'}))),
+	} {
+		if err := tls.DecodeFromBytes(tlsTestRecord(data), gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
+
+func TestTLS13ServerHello(t *testing.T) {
+	serverHello := func(random []byte, keyShare []byte) []byte {
+		return tlsTestMessage(TLSHandshakeServerHello, []byte{3, 3}, random, []byte{0, 0x13, 0x01, 0},
+			tlsTestVector(2,
+				tlsTestExtension(TLSExtensionSupportedVersions, []byte{3, 4}),
+				tlsTestExtension(TLSExtensionKeyShare, keyShare),
+			))
+	}
+	encryptedExtensions := tlsTestMessage(TLSHandshakeEncryptedExtensions, tlsTestVector(2,
+		tlsTestExtension(TLSExtensionServerName),
+		tlsTestExtension(TLSExtensionALPN, tlsTestVector(2, tlsTestVector(1, []byte("h2")))),
+	))
+	// a HelloRetryRequest, and a ServerHello coalesced with the plaintext
+	// EncryptedExtensions, e.g. decrypted from QUIC
+	data := append(tlsTestRecord(serverHello(tlsHelloRetryRequestRandom, []byte{0, 0x17})),
+		tlsTestRecord(append(serverHello(testTLS13Random, append([]byte{0, 0x1d}, tlsTestVector(2, testTLS13Key)...)), encryptedExtensions...))...)
+	var tls TLS
+	if err := tls.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if len(tls.Handshake) != 2 || len(tls.Handshake[0].Messages) != 1 || len(tls.Handshake[1].Messages) != 2 {
+		t.Fatalf("unexpected handshake records %#v", tls.Handshake)
+	}
+	hrr := tls.Handshake[0].Messages[0]
+	if !hrr.IsHelloRetryRequest() || !reflect.DeepEqual(hrr.KeyShares, []TLSKeyShare{{Group: 0x17}}) {
+		t.Errorf("unexpected HelloRetryRequest %#v", hrr)
+	}
+	hello := tls.Handshake[1].Messages[0]
+	if hello.IsHelloRetryRequest() || hello.SelectedVersion() != 0x0304 || !reflect.DeepEqual(hello.CipherSuites, []uint16{0x1301}) ||
+		!reflect.DeepEqual(hello.KeyShares, []TLSKeyShare{{0x1d, testTLS13Key}}) {
+		t.Errorf("unexpected ServerHello %#v", hello)
+	}
+	if ee := tls.Handshake[1].Messages[1]; ee.Type != TLSHandshakeEncryptedExtensions || len(ee.Extensions) != 2 ||
+		!reflect.DeepEqual(ee.ALPN, []string{"h2"}) || ee.ServerName != "" {
+		t.Errorf("unexpected EncryptedExtensions %#v", ee)
+	}
+}
+
+func TestTLSHandshakeFragmented(t *testing.T) {
+	// the ClientHello is fragmented over three records
+	hello := testTLS13ClientHello
+	records := append(append(tlsTestRecord(hello[:2]), tlsTestRecord(hello[2:100])...), tlsTestRecord(hello[100:])...)
+	var tls TLS
+	if err := tls.DecodeFromBytes(records, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if len(tls.Handshake) != 3 || len(tls.Handshake[0].Messages)+len(tls.Handshake[1].Messages) != 0 ||
+		len(tls.Handshake[2].Messages) != 1 || tls.Handshake[2].Messages[0].ServerName != "example.com" {
+		t.Errorf("unexpected handshake records %#v", tls.Handshake)
+	}
+
+	// the records are split over segments of a stream
+	s := &TLSStream{}
+	var messages []TLSHandshakeMessage
+	for _, segment := range [][]byte{records[:3], records[3:50], records[50:120], records[120:]} {
+		tls, err := s.Decode(segment)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tls == nil {
+			continue
+		}
+		for _, r := range tls.Handshake {
+			messages = append(messages, r.Messages...)
+		}
+	}
+	if len(messages) != 1 || !bytes.Equal(messages[0].Body, hello[4:]) {
+		t.Errorf("unexpected messages %#v", messages)
+	}
+
+	// handshake records following a Change Cipher Spec record are encrypted
+	tls2, err := s.Decode(append([]byte{20, 3, 3, 0, 1, 1}, tlsTestRecord([]byte{1, 0, 0, 0})...))
+	if err != nil || len(tls2.ChangeCipherSpec) != 1 || len(tls2.Handshake) != 1 || !tls2.Handshake[0].Encrypted {
+		t.Errorf("unexpected records %#v, error %v", tls2, err)
+	}
+}
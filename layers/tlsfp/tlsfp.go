@@ -0,0 +1,275 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+// Package tlsfp computes the JA3, JA3S and JA4 fingerprints of TLS clients
+// and servers from the hello messages decoded by the layers package:
+//
+//	if tls, ok := packet.Layer(layers.LayerTypeTLS).(*layers.TLS); ok {
+//	  if hello := tlsfp.ClientHello(tls); hello != nil {
+//	    fmt.Println(tlsfp.JA3Hash(hello), tlsfp.JA4(hello, tlsfp.TCP))
+//	  }
+//	}
+//
+// The ClientHello of QUIC connections is found in the CRYPTO frames of
+// Initial packets decrypted by a layers.QUICDecrypter, see QUICClientHello.
+//
+// GREASE values (RFC 8701) are left out of all fingerprints.
+package tlsfp
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/gopacket/layers"
+)
+
+// ClientHello returns the first ClientHello of the handshake records of t, or
+// nil if there's none.
+func ClientHello(t *layers.TLS) *layers.TLSHandshakeMessage {
+	return findHello(t, layers.TLSHandshakeClientHello)
+}
+
+// ServerHello returns the first ServerHello of the handshake records of t, or
+// nil if there's none.
+func ServerHello(t *layers.TLS) *layers.TLSHandshakeMessage {
+	return findHello(t, layers.TLSHandshakeServerHello)
+}
+
+func findHello(t *layers.TLS, typ layers.TLSHandshakeType) *layers.TLSHandshakeMessage {
+	for i := range t.Handshake {
+		for j := range t.Handshake[i].Messages {
+			if m := &t.Handshake[i].Messages[j]; m.Type == typ {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// QUICClientHello returns the ClientHello sent in the CRYPTO frames of the
+// Initial packets of a QUIC client.  The frames may come from several
+// packets, in any order.
+func QUICClientHello(frames []*layers.QUICFrame) (*layers.TLSHandshakeMessage, error) {
+	var crypto []*layers.QUICFrame
+	for _, f := range frames {
+		if f.Type == layers.QUICFrameTypeCrypto {
+			crypto = append(crypto, f)
+		}
+	}
+	sort.SliceStable(crypto, func(i, j int) bool { return crypto[i].Offset < crypto[j].Offset })
+	var data []byte
+	for _, f := range crypto {
+		if f.Offset > uint64(len(data)) {
+			break
+		}
+		if end := f.Offset + uint64(len(f.Data)); end > uint64(len(data)) {
+			data = append(data, f.Data[uint64(len(data))-f.Offset:]...)
+		}
+	}
+	hello := &layers.TLSHandshakeMessage{}
+	if err := hello.DecodeFromBytes(data); err != nil {
+		return nil, err
+	}
+	if hello.Type != layers.TLSHandshakeClientHello {
+		return nil, fmt.Errorf("QUIC CRYPTO frames start with %v instead of ClientHello", hello.Type)
+	}
+	return hello, nil
+}
+
+// isGREASE returns true for the GREASE values reserved by RFC 8701, which
+// are all of the form 0x?a?a.
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+// decimals returns the non-GREASE values joined by '-'.
+func decimals(values []uint16) string {
+	var s []string
+	for _, v := range values {
+		if !isGREASE(v) {
+			s = append(s, strconv.Itoa(int(v)))
+		}
+	}
+	return strings.Join(s, "-")
+}
+
+// extensionTypes returns the types of the extensions of hello.
+func extensionTypes(hello *layers.TLSHandshakeMessage) []uint16 {
+	types := make([]uint16, len(hello.Extensions))
+	for i, e := range hello.Extensions {
+		types[i] = uint16(e.Type)
+	}
+	return types
+}
+
+// JA3 returns the JA3 string of a ClientHello: the decimal version, cipher
+// suites, extensions, supported groups and EC point formats.
+func JA3(hello *layers.TLSHandshakeMessage) string {
+	var pointFormats []uint16
+	for _, e := range hello.Extensions {
+		if e.Type == layers.TLSExtensionECPointFormats && len(e.Data) > 0 && int(e.Data[0]) == len(e.Data)-1 {
+			for _, f := range e.Data[1:] {
+				pointFormats = append(pointFormats, uint16(f))
+			}
+		}
+	}
+	return strings.Join([]string{
+		strconv.Itoa(int(hello.Version)),
+		decimals(hello.CipherSuites),
+		decimals(extensionTypes(hello)),
+		decimals(hello.SupportedGroups),
+		decimals(pointFormats),
+	}, ",")
+}
+
+// JA3Hash returns the MD5 hash of the JA3 string of a ClientHello.
+func JA3Hash(hello *layers.TLSHandshakeMessage) string {
+	sum := md5.Sum([]byte(JA3(hello)))
+	return hex.EncodeToString(sum[:])
+}
+
+// JA3S returns the JA3S string of a ServerHello: the decimal version,
+// cipher suite and extensions.
+func JA3S(hello *layers.TLSHandshakeMessage) string {
+	return strings.Join([]string{
+		strconv.Itoa(int(hello.Version)),
+		decimals(hello.CipherSuites),
+		decimals(extensionTypes(hello)),
+	}, ",")
+}
+
+// JA3SHash returns the MD5 hash of the JA3S string of a ServerHello.
+func JA3SHash(hello *layers.TLSHandshakeMessage) string {
+	sum := md5.Sum([]byte(JA3S(hello)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Transport is the transport a ClientHello was sent over, the first
+// character of JA4 fingerprints.
+type Transport byte
+
+// Transports of JA4 fingerprints.
+const (
+	TCP  Transport = 't'
+	QUIC Transport = 'q'
+	DTLS Transport = 'd'
+)
+
+// ja4Version returns the JA4 code of a version.
+func ja4Version(v layers.TLSVersion) string {
+	switch v {
+	case 0x0304:
+		return "13"
+	case 0x0303:
+		return "12"
+	case 0x0302:
+		return "11"
+	case 0x0301:
+		return "10"
+	case 0x0300:
+		return "s3"
+	case 0x0200:
+		return "s2"
+	case 0xfeff:
+		return "d1"
+	case 0xfefd:
+		return "d2"
+	case 0xfefc:
+		return "d3"
+	}
+	return "00"
+}
+
+// ja4Hash returns the truncated SHA-256 hash of the sorted values, hex
+// encoded and joined by ',', followed by suffix.
+func ja4Hash(values []uint16, suffix string) string {
+	var s []string
+	for _, v := range values {
+		if !isGREASE(v) {
+			s = append(s, fmt.Sprintf("%04x", v))
+		}
+	}
+	if len(s) == 0 {
+		return "000000000000"
+	}
+	sort.Strings(s)
+	sum := sha256.Sum256([]byte(strings.Join(s, ",") + suffix))
+	return hex.EncodeToString(sum[:6])
+}
+
+// JA4 returns the JA4 fingerprint of a ClientHello sent over transport.
+func JA4(hello *layers.TLSHandshakeMessage, transport Transport) string {
+	// the highest supported version, or the legacy version
+	version := hello.Version
+	if len(hello.SupportedVersions) > 0 {
+		version = 0
+		for _, v := range hello.SupportedVersions {
+			if !isGREASE(uint16(v)) && v > version {
+				version = v
+			}
+		}
+	}
+	sni := 'i'
+	if hello.ServerName != "" {
+		sni = 'd'
+	}
+	alpn := "00"
+	if len(hello.ALPN) > 0 && hello.ALPN[0] != "" {
+		first, last := hello.ALPN[0][0], hello.ALPN[0][len(hello.ALPN[0])-1]
+		if isAlphanumeric(first) && isAlphanumeric(last) {
+			alpn = string([]byte{first, last})
+		} else {
+			h := hex.EncodeToString([]byte(hello.ALPN[0]))
+			alpn = h[:1] + h[len(h)-1:]
+		}
+	}
+	var ciphers, extensions []uint16
+	for _, c := range hello.CipherSuites {
+		if !isGREASE(c) {
+			ciphers = append(ciphers, c)
+		}
+	}
+	// SNI and ALPN are counted, but not hashed
+	var hashed []uint16
+	for _, e := range extensionTypes(hello) {
+		if isGREASE(e) {
+			continue
+		}
+		extensions = append(extensions, e)
+		if e != uint16(layers.TLSExtensionServerName) && e != uint16(layers.TLSExtensionALPN) {
+			hashed = append(hashed, e)
+		}
+	}
+	var signatures string
+	if len(hello.SignatureAlgorithms) > 0 {
+		var s []string
+		for _, a := range hello.SignatureAlgorithms {
+			if !isGREASE(a) {
+				s = append(s, fmt.Sprintf("%04x", a))
+			}
+		}
+		signatures = "_" + strings.Join(s, ",")
+	}
+	return fmt.Sprintf("%c%s%c%02d%02d%s_%s_%s", transport, ja4Version(version), sni,
+		min99(len(ciphers)), min99(len(extensions)), alpn,
+		ja4Hash(ciphers, ""), ja4Hash(hashed, signatures))
+}
+
+func isAlphanumeric(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func min99(n int) int {
+	if n > 99 {
+		return 99
+	}
+	return n
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package tlsfp
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// vector returns data prefixed by its n byte length.
+func vector(n int, data ...byte) []byte {
+	v := make([]byte, n, n+len(data))
+	for i := 0; i < n; i++ {
+		v[i] = byte(len(data) >> uint(8*(n-1-i)))
+	}
+	return append(v, data...)
+}
+
+func uint16s(values ...uint16) []byte {
+	b := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint16(b[2*i:], v)
+	}
+	return b
+}
+
+func extension(typ uint16, data ...byte) []byte {
+	return append(uint16s(typ), vector(2, data...)...)
+}
+
+// message returns a handshake message.
+func message(typ layers.TLSHandshakeType, body ...byte) []byte {
+	return append([]byte{byte(typ)}, vector(3, body...)...)
+}
+
+// chromeHello returns a ClientHello sent by Chrome, with GREASE values.
+func chromeHello() []byte {
+	var body []byte
+	body = append(body, 0x03, 0x03)
+	body = append(body, make([]byte, 32)...)
+	body = append(body, vector(1)...)
+	body = append(body, vector(2, uint16s(0x0a0a, 0x1301, 0x1302, 0x1303, 0xc02b, 0xc02f, 0xc02c, 0xc030,
+		0xcca9, 0xcca8, 0xc013, 0xc014, 0x009c, 0x009d, 0x002f, 0x0035)...)...)
+	body = append(body, vector(1, 0)...)
+	var extensions []byte
+	for _, e := range [][]byte{
+		extension(0x1a1a),
+		extension(0x0000, vector(2, append([]byte{0}, vector(2, []byte("example.com")...)...)...)...),
+		extension(0x0017),
+		extension(0xff01, 0),
+		extension(0x000a, vector(2, uint16s(0x2a2a, 0x001d, 0x0017, 0x0018)...)...),
+		extension(0x000b, vector(1, 0)...),
+		extension(0x0023),
+		extension(0x0010, vector(2, vector(1, []byte("h2")...)...)...),
+		extension(0x0005, 1, 0, 0, 0, 0),
+		extension(0x000d, vector(2, uint16s(0x0403, 0x0804, 0x0401, 0x0503, 0x0805, 0x0501, 0x0806, 0x0601)...)...),
+		extension(0x0012),
+		extension(0x0033, vector(2, append(uint16s(0x001d), vector(2, make([]byte, 32)...)...)...)...),
+		extension(0x002d, vector(1, 1)...),
+		extension(0x002b, vector(1, uint16s(0x3a3a, 0x0304, 0x0303)...)...),
+		extension(0x001b, 0x02, 0x00, 0x02),
+		extension(0x4469, vector(2, vector(1, []byte("h2")...)...)...),
+		extension(0x0015, make([]byte, 8)...),
+	} {
+		extensions = append(extensions, e...)
+	}
+	body = append(body, vector(2, extensions...)...)
+	return message(layers.TLSHandshakeClientHello, body...)
+}
+
+func TestClientHello(t *testing.T) {
+	record := append([]byte{0x16, 0x03, 0x01}, vector(2, chromeHello()...)...)
+	p := gopacket.NewPacket(record, layers.LayerTypeTLS, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	tls := p.Layer(layers.LayerTypeTLS).(*layers.TLS)
+	if ServerHello(tls) != nil {
+		t.Error("unexpected ServerHello")
+	}
+	hello := ClientHello(tls)
+	if hello == nil {
+		t.Fatal("no ClientHello")
+	}
+
+	want := "771,4865-4866-4867-49195-49199-49196-49200-52393-52392-49171-49172-156-157-47-53," +
+		"0-23-65281-10-11-35-16-5-13-18-51-45-43-27-17513-21,29-23-24,0"
+	if got := JA3(hello); got != want {
+		t.Errorf("got JA3 %s, want %s", got, want)
+	}
+	if got := JA3Hash(hello); got != "cd08e31494f9531f560d64c695473da9" {
+		t.Errorf("got JA3 hash %s", got)
+	}
+	for _, test := range []struct {
+		transport Transport
+		want      string
+	}{
+		{TCP, "t13d1516h2_8daaf6152771_e5627efa2ab1"},
+		{QUIC, "q13d1516h2_8daaf6152771_e5627efa2ab1"},
+	} {
+		if got := JA4(hello, test.transport); got != test.want {
+			t.Errorf("got JA4 %s, want %s", got, test.want)
+		}
+	}
+}
+
+func TestJA4(t *testing.T) {
+	for _, test := range []struct {
+		hello layers.TLSHandshakeMessage
+		want  string
+	}{
+		{layers.TLSHandshakeMessage{Version: 0x0301}, "t10i000000_000000000000_000000000000"},
+		{layers.TLSHandshakeMessage{Version: 0x0303, ALPN: []string{"http/1.1"}}, "t12i0000h1_000000000000_000000000000"},
+		// ALPN values that don't start and end with alphanumerics are hex encoded
+		{layers.TLSHandshakeMessage{Version: 0xfefd, ALPN: []string{"\xabc\xcd"}}, "td2i0000ad_000000000000_000000000000"},
+		// unknown versions are 00
+		{layers.TLSHandshakeMessage{Version: 0x0303, SupportedVersions: []layers.TLSVersion{0x7f1c}}, "t00i000000_000000000000_000000000000"},
+	} {
+		if got := JA4(&test.hello, TCP); got != test.want {
+			t.Errorf("got JA4 %s, want %s", got, test.want)
+		}
+	}
+}
+
+func TestServerHello(t *testing.T) {
+	var body []byte
+	body = append(body, 0x03, 0x03)
+	body = append(body, make([]byte, 32)...)
+	body = append(body, vector(1)...)
+	body = append(body, 0x13, 0x01, 0)
+	body = append(body, vector(2, append(extension(0x002b, 0x03, 0x04),
+		extension(0x0033, append(uint16s(0x001d), vector(2, make([]byte, 32)...)...)...)...)...)...)
+	hello := &layers.TLSHandshakeMessage{}
+	if err := hello.DecodeFromBytes(message(layers.TLSHandshakeServerHello, body...)); err != nil {
+		t.Fatal(err)
+	}
+	if got := JA3S(hello); got != "771,4865,43-51" {
+		t.Errorf("got JA3S %s", got)
+	}
+	if got := JA3SHash(hello); got != "f4febc55ea12b31ae17cfb7e614afda8" {
+		t.Errorf("got JA3S hash %s", got)
+	}
+}
+
+func TestQUICClientHello(t *testing.T) {
+	data := chromeHello()
+	frames := []*layers.QUICFrame{
+		{Type: layers.QUICFrameTypeCrypto, Offset: 100, Data: data[100:]},
+		{Type: layers.QUICFrameTypePing},
+		{Type: layers.QUICFrameTypeCrypto, Offset: 0, Data: data[:60]},
+		{Type: layers.QUICFrameTypeCrypto, Offset: 50, Data: data[50:120]},
+	}
+	hello, err := QUICClientHello(frames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := JA4(hello, QUIC); got != "q13d1516h2_8daaf6152771_e5627efa2ab1" {
+		t.Errorf("got JA4 %s", got)
+	}
+	if _, err := QUICClientHello(frames[1:]); err == nil {
+		t.Error("no error for missing CRYPTO frame")
+	}
+}
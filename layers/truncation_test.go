@@ -0,0 +1,103 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestTruncationPolicy(t *testing.T) {
+	data, err := Build().Ethernet(nil).IPv4(nil).TCP(nil).Payload(make([]byte, 100)).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// cut in the middle of the TCP header, like a small snaplen does
+	data = data[:14+20+10]
+
+	tests := []struct {
+		policy    gopacket.TruncationPolicy
+		want      []gopacket.LayerType
+		truncated gopacket.LayerType
+		failure   bool
+	}{
+		{gopacket.TruncationError, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeTCP, gopacket.LayerTypeDecodeFailure}, LayerTypeTCP, true},
+		{gopacket.TruncationPartial, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeTCP}, LayerTypeTCP, false},
+		{gopacket.TruncationPayload, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, gopacket.LayerTypePayload}, gopacket.LayerTypePayload, false},
+	}
+	for _, test := range tests {
+		for _, lazy := range []bool{false, true} {
+			p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.DecodeOptions{Truncation: test.policy, Lazy: lazy})
+			var types []gopacket.LayerType
+			for _, l := range p.Layers() {
+				types = append(types, l.LayerType())
+			}
+			if !reflect.DeepEqual(types, test.want) {
+				t.Errorf("policy %d, lazy %v: expected layers %v, got %v", test.policy, lazy, test.want, types)
+			}
+			if (p.ErrorLayer() != nil) != test.failure {
+				t.Errorf("policy %d, lazy %v: unexpected error layer %v", test.policy, lazy, p.ErrorLayer())
+			}
+			md := p.Metadata()
+			if !md.Truncated || md.TruncatedLayer == nil || md.TruncatedLayer.LayerType() != test.truncated {
+				t.Errorf("policy %d, lazy %v: expected truncated layer %v, got %v", test.policy, lazy, test.truncated, md.TruncatedLayer)
+			}
+			if p.NetworkLayer() == nil {
+				t.Errorf("policy %d, lazy %v: no network layer", test.policy, lazy)
+			}
+			if test.policy == gopacket.TruncationPayload {
+				if p.TransportLayer() != nil {
+					t.Errorf("policy %d, lazy %v: unexpected transport layer", test.policy, lazy)
+				}
+				if app := p.ApplicationLayer(); app == nil || !bytes.Equal(app.Payload(), data[34:]) {
+					t.Errorf("policy %d, lazy %v: unexpected application layer %v", test.policy, lazy, app)
+				}
+			}
+		}
+	}
+}
+
+func TestTruncatedLayer(t *testing.T) {
+	data, err := Build().Ethernet(nil).IPv4(nil).TCP(nil).Payload(make([]byte, 100)).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data[:len(data)-10], LinkTypeEthernet, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	if md := p.Metadata(); !md.Truncated || md.TruncatedLayer != p.NetworkLayer() {
+		t.Errorf("expected truncated IPv4 layer, got %v", md.TruncatedLayer)
+	}
+
+	p = gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default)
+	if md := p.Metadata(); md.Truncated || md.TruncatedLayer != nil {
+		t.Errorf("unexpected truncated layer %v", md.TruncatedLayer)
+	}
+}
+
+func TestTruncationPolicyMalformedLayer(t *testing.T) {
+	data, err := Build().Ethernet(nil).IPv4(nil).TCP(nil).Payload(make([]byte, 100)).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the IPv4 layer is truncated, but the TCP layer is malformed
+	data = data[:len(data)-10]
+	data[14+20+12] = 0x10 // data offset 1
+	for _, policy := range []gopacket.TruncationPolicy{gopacket.TruncationPartial, gopacket.TruncationPayload} {
+		p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.DecodeOptions{Truncation: policy})
+		if !p.Metadata().Truncated {
+			t.Errorf("policy %d: expected truncated packet", policy)
+		}
+		if p.ErrorLayer() == nil {
+			t.Errorf("policy %d: expected error layer for the malformed TCP layer", policy)
+		}
+	}
+}
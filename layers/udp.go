@@ -9,6 +9,7 @@ package layers
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/google/gopacket"
@@ -95,6 +96,17 @@ func (u *UDP) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOpt
 	return nil
 }
 
+// VerifyChecksum verifies the checksum of the UDP header and payload,
+// implementing gopacket.ChecksumVerifier.  SetNetworkLayerForChecksum must be
+// called before.  UDP over IPv4 may omit the checksum, in which case an error
+// is returned.
+func (u *UDP) VerifyChecksum() (bool, error) {
+	if _, ok := u.pseudoheader.(*IPv4); ok && u.Checksum == 0 {
+		return false, errors.New("UDP checksum not present")
+	}
+	return u.verifyChecksum(u.Contents, u.Payload, IPProtocolUDP)
+}
+
 func (u *UDP) CanDecode() gopacket.LayerClass {
 	return LayerTypeUDP
 }
@@ -103,6 +115,17 @@ func (u *UDP) CanDecode() gopacket.LayerClass {
 // right next decoder. It tries first to decode via the
 // destination port, then the source port.
 func (u *UDP) NextLayerType() gopacket.LayerType {
+	return u.nextLayerType(nil)
+}
+
+// nextLayerType is NextLayerType with the port mappings of profile, if not nil
+func (u *UDP) nextLayerType(profile *gopacket.DecodeProfile) gopacket.LayerType {
+	if lt, ok := profile.PortLayerType(EndpointUDPPort, uint16(u.DstPort)); ok {
+		return lt
+	}
+	if lt, ok := profile.PortLayerType(EndpointUDPPort, uint16(u.SrcPort)); ok {
+		return lt
+	}
 	if lt := u.DstPort.LayerType(); lt != gopacket.LayerTypePayload {
 		return lt
 	}
@@ -117,7 +140,11 @@ func decodeUDP(data []byte, p gopacket.PacketBuilder) error {
 	if err != nil {
 		return err
 	}
-	return p.NextDecoder(udp.NextLayerType())
+	next := udp.nextLayerType(p.DecodeOptions().Profile)
+	if next == gopacket.LayerTypePayload {
+		return p.NextDecoder(gopacket.UnknownProtocolDecoder(gopacket.UnknownUDPPort, int(udp.DstPort), next))
+	}
+	return p.NextDecoder(next)
 }
 
 func (u *UDP) TransportFlow() gopacket.Flow {
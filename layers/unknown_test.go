@@ -0,0 +1,114 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// The hooks only handle protocol numbers reserved for experiments, so they
+// don't affect other tests.
+const (
+	testUnknownEthernetType = EthernetType(0x88b5)
+	testUnknownIPProtocol   = IPProtocol(253)
+	testUnknownUDPPort      = 40000
+)
+
+var testUnknownUDPLayer gopacket.Layer
+
+func init() {
+	gopacket.RegisterUnknownProtocolHook(gopacket.UnknownEthernetType, func(u *gopacket.UnknownProtocol) gopacket.Decoder {
+		if u.Number == int(testUnknownEthernetType) && len(u.Data) > 0 && u.Data[0]>>4 == 4 {
+			return LayerTypeIPv4
+		}
+		return nil
+	})
+	gopacket.RegisterUnknownProtocolHook(gopacket.UnknownIPProtocol, func(u *gopacket.UnknownProtocol) gopacket.Decoder {
+		if u.Number == int(testUnknownIPProtocol) {
+			return LayerTypeUDP
+		}
+		return nil
+	})
+	gopacket.RegisterUnknownProtocolHook(gopacket.UnknownUDPPort, func(u *gopacket.UnknownProtocol) gopacket.Decoder {
+		if u.Number == testUnknownUDPPort {
+			testUnknownUDPLayer = u.Layer
+			return LayerTypeDNS
+		}
+		return nil
+	})
+}
+
+func TestUnknownProtocolHooks(t *testing.T) {
+	dns := &DNS{ID: 1, QDCount: 1, Questions: []DNSQuestion{{Name: []byte("example.com"), Type: DNSTypeA, Class: DNSClassIN}}}
+	tests := []struct {
+		name    string
+		builder *Builder
+		want    []gopacket.LayerType
+	}{
+		{"ethernet type", Build().Ethernet(&Ethernet{EthernetType: testUnknownEthernetType}).IPv4(nil).ICMPv4(nil),
+			[]gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeICMPv4}},
+		{"ip protocol", Build().Ethernet(nil).IPv4(&IPv4{Protocol: testUnknownIPProtocol}).UDP(&UDP{SrcPort: 1, DstPort: 2}).Payload([]byte{1}),
+			[]gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, gopacket.LayerTypePayload}},
+		{"ipv6 next header", Build().Ethernet(nil).IPv6(&IPv6{NextHeader: testUnknownIPProtocol}).UDP(&UDP{SrcPort: 1, DstPort: 2}).Payload([]byte{1}),
+			[]gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv6, LayerTypeUDP, gopacket.LayerTypePayload}},
+		{"udp port", Build().Ethernet(nil).IPv4(nil).UDP(&UDP{SrcPort: 1, DstPort: testUnknownUDPPort}).Layer(dns),
+			[]gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, LayerTypeDNS}},
+		{"unhandled udp port", Build().Ethernet(nil).IPv4(nil).UDP(&UDP{SrcPort: 1, DstPort: testUnknownUDPPort + 1}).Layer(dns),
+			[]gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv4, LayerTypeUDP, gopacket.LayerTypePayload}},
+	}
+	for _, test := range tests {
+		data, err := test.builder.Serialize(gopacket.SerializeOptions{FixLengths: true})
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		for _, lazy := range []bool{false, true} {
+			p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.DecodeOptions{Lazy: lazy})
+			var types []gopacket.LayerType
+			for _, l := range p.Layers() {
+				types = append(types, l.LayerType())
+			}
+			if !reflect.DeepEqual(types, test.want) {
+				t.Errorf("%s, lazy %v: expected layers %v, got %v", test.name, lazy, test.want, types)
+			}
+			if test.name == "udp port" && testUnknownUDPLayer != p.TransportLayer() {
+				t.Errorf("%s, lazy %v: hook got layer %v", test.name, lazy, testUnknownUDPLayer)
+			}
+		}
+	}
+
+	// unknown EtherTypes without a hook still fail
+	data, _ := Build().Ethernet(&Ethernet{EthernetType: testUnknownEthernetType + 1}).Payload([]byte{1, 2, 3}).Bytes()
+	if p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default); p.ErrorLayer() == nil {
+		t.Error("expected decode failure for unknown EtherType")
+	}
+}
+
+func TestRegisterUnknownProtocolHookConcurrent(t *testing.T) {
+	data, err := Build().Ethernet(nil).IPv4(&IPv4{Protocol: testUnknownIPProtocol}).UDP(&UDP{SrcPort: 1, DstPort: 2}).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			gopacket.RegisterUnknownProtocolHook(gopacket.UnknownIPProtocol, func(u *gopacket.UnknownProtocol) gopacket.Decoder {
+				return nil
+			})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if p := gopacket.NewPacket(data, LinkTypeEthernet, gopacket.Default); p.Layer(LayerTypeUDP) == nil {
+			t.Fatalf("expected UDP layer, got %v", p.Layers())
+		}
+	}
+	<-done
+}
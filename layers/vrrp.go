@@ -9,7 +9,9 @@ package layers
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"net"
+	"time"
 
 	"github.com/google/gopacket"
 )
@@ -90,7 +92,12 @@ type VRRPv2 struct {
 // LayerType returns LayerTypeVRRP for VRRP v2 message.
 func (v *VRRPv2) LayerType() gopacket.LayerType { return LayerTypeVRRP }
 
+// DecodeFromBytes decodes the given bytes into this layer.
 func (v *VRRPv2) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 8 {
+		df.SetTruncated()
+		return errors.New("Not a valid VRRP packet. Packet length is too small.")
+	}
 
 	v.BaseLayer = BaseLayer{Contents: data[:len(data)]}
 	v.Version = data[0] >> 4 // high nibble == VRRP version. We're expecting v2
@@ -116,6 +123,11 @@ func (v *VRRPv2) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error
 	// populate the IPAddress field. The number of addresses is specified in the v.CountIPAddr field
 	// offset references the starting byte containing the list of ip addresses
 	offset := 8
+	if len(data) < offset+4*int(v.CountIPAddr) {
+		df.SetTruncated()
+		return errors.New("VRRPv2 packet too small for its IP addresses.")
+	}
+	v.IPAddress = v.IPAddress[:0]
 	for i := uint8(0); i < v.CountIPAddr; i++ {
 		v.IPAddress = append(v.IPAddress, data[offset:offset+4])
 		offset += 4
@@ -131,6 +143,51 @@ func (v *VRRPv2) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error
 	return nil
 }
 
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+// The 8 bytes of authentication data are written as zeros.
+func (v *VRRPv2) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if opts.FixLengths {
+		v.CountIPAddr = uint8(len(v.IPAddress))
+	}
+	if int(v.CountIPAddr) != len(v.IPAddress) {
+		return fmt.Errorf("VRRPv2 count of IP addresses %d does not match %d addresses", v.CountIPAddr, len(v.IPAddress))
+	}
+	bytes, err := b.PrependBytes(8 + 4*len(v.IPAddress) + 8)
+	if err != nil {
+		return err
+	}
+	bytes[0] = v.Version<<4 | uint8(v.Type)&0x0f
+	bytes[1] = v.VirtualRtrID
+	bytes[2] = v.Priority
+	bytes[3] = v.CountIPAddr
+	bytes[4] = uint8(v.AuthType)
+	bytes[5] = v.AdverInt
+	bytes[6], bytes[7] = 0, 0
+	for i, ip := range v.IPAddress {
+		ip4 := ip.To4()
+		if ip4 == nil {
+			return fmt.Errorf("invalid VRRPv2 IPv4 address %v", ip)
+		}
+		copy(bytes[8+4*i:], ip4)
+	}
+	auth := bytes[8+4*len(v.IPAddress):]
+	for i := range auth {
+		auth[i] = 0
+	}
+	if opts.ComputeChecksums {
+		v.Checksum = tcpipChecksum(bytes, 0)
+	}
+	binary.BigEndian.PutUint16(bytes[6:8], v.Checksum)
+	return nil
+}
+
+// VerifyChecksum verifies the checksum of the VRRP message, implementing
+// gopacket.ChecksumVerifier.
+func (v *VRRPv2) VerifyChecksum() (bool, error) {
+	return tcpipChecksum(v.Contents, 0) == 0, nil
+}
+
 // CanDecode specifies the layer type in which we are attempting to unwrap.
 func (v *VRRPv2) CanDecode() gopacket.LayerClass {
 	return LayerTypeVRRP
@@ -146,11 +203,155 @@ func (v *VRRPv2) Payload() []byte {
 	return nil
 }
 
-// decodeVRRP will parse VRRP v2
+/*
+	VRRPv3 has the same layout, except that the authentication fields are
+	replaced by the maximum advertisement interval, in centiseconds, and that
+	the addresses are either all IPv4 or all IPv6 ones.
+	https://tools.ietf.org/html/rfc5798#section-5.1
+    0                   1                   2                   3
+    0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+   |Version| Type  | Virtual Rtr ID|   Priority    |Count IPvX Addr|
+   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+   |(rsvd) |     Max Adver Int     |          Checksum             |
+   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+   |                     IPvX Address(es)                          |
+   +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+*/
+
+// VRRPv3 represents a VRRP v3 message, sent over IPv4 or IPv6.
+type VRRPv3 struct {
+	BaseLayer
+	Version      uint8      // 3
+	Type         VRRPv2Type // The only type defined in v3 is still ADVERTISEMENT
+	VirtualRtrID uint8
+	Priority     uint8
+	CountIPAddr  uint8
+	MaxAdverInt  uint16 // 12 bits advertisement interval, in centiseconds
+	Checksum     uint16 // Computed over an IPv4 or IPv6 pseudo-header too
+	IPAddress    []net.IP
+	tcpipchecksum
+}
+
+// LayerType returns LayerTypeVRRP for VRRP v3 message.
+func (v *VRRPv3) LayerType() gopacket.LayerType { return LayerTypeVRRP }
+
+// AdvertisementInterval returns MaxAdverInt as a time.Duration.
+func (v *VRRPv3) AdvertisementInterval() time.Duration {
+	return time.Duration(v.MaxAdverInt) * 10 * time.Millisecond
+}
+
+// DecodeFromBytes decodes the given bytes into this layer.  The family of the
+// addresses is guessed from the length of the message: they are IPv6
+// addresses if there is room for CountIPAddr of them.
+func (v *VRRPv3) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 8 {
+		df.SetTruncated()
+		return errors.New("Not a valid VRRP packet. Packet length is too small.")
+	}
+	v.Version = data[0] >> 4
+	v.Type = VRRPv2Type(data[0] & 0x0F)
+	if v.Type != VRRPv2Advertisement {
+		return errors.New("Unrecognized VRRPv3 type field.")
+	}
+	v.VirtualRtrID = data[1]
+	v.Priority = data[2]
+	v.CountIPAddr = data[3]
+	v.MaxAdverInt = binary.BigEndian.Uint16(data[4:6]) & 0x0fff
+	v.Checksum = binary.BigEndian.Uint16(data[6:8])
+
+	size := net.IPv6len
+	if len(data) < 8+size*int(v.CountIPAddr) {
+		size = net.IPv4len
+	}
+	length := 8 + size*int(v.CountIPAddr)
+	if len(data) < length {
+		df.SetTruncated()
+		return errors.New("VRRPv3 packet too small for its IP addresses.")
+	}
+	v.IPAddress = v.IPAddress[:0]
+	for offset := 8; offset < length; offset += size {
+		v.IPAddress = append(v.IPAddress, data[offset:offset+size])
+	}
+	v.BaseLayer = BaseLayer{Contents: data[:length], Payload: data[length:]}
+	return nil
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+// The addresses are written as IPv4 addresses if they all are IPv4 ones.
+func (v *VRRPv3) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if opts.FixLengths {
+		v.CountIPAddr = uint8(len(v.IPAddress))
+	}
+	if int(v.CountIPAddr) != len(v.IPAddress) {
+		return fmt.Errorf("VRRPv3 count of IP addresses %d does not match %d addresses", v.CountIPAddr, len(v.IPAddress))
+	}
+	if v.MaxAdverInt > 0x0fff {
+		return fmt.Errorf("VRRPv3 advertisement interval %d too large", v.MaxAdverInt)
+	}
+	size := net.IPv4len
+	for _, ip := range v.IPAddress {
+		if ip.To4() == nil {
+			size = net.IPv6len
+		}
+	}
+	bytes, err := b.PrependBytes(8 + size*len(v.IPAddress))
+	if err != nil {
+		return err
+	}
+	bytes[0] = v.Version<<4 | uint8(v.Type)&0x0f
+	bytes[1] = v.VirtualRtrID
+	bytes[2] = v.Priority
+	bytes[3] = v.CountIPAddr
+	binary.BigEndian.PutUint16(bytes[4:6], v.MaxAdverInt)
+	bytes[6], bytes[7] = 0, 0
+	for i, ip := range v.IPAddress {
+		addr := ip.To16()
+		if size == net.IPv4len {
+			addr = ip.To4()
+		}
+		if addr == nil {
+			return fmt.Errorf("invalid VRRPv3 address %v", ip)
+		}
+		copy(bytes[8+size*i:], addr)
+	}
+	if opts.ComputeChecksums {
+		csum, err := v.computeChecksum(bytes, IPProtocolVRRP)
+		if err != nil {
+			return err
+		}
+		v.Checksum = csum
+	}
+	binary.BigEndian.PutUint16(bytes[6:8], v.Checksum)
+	return nil
+}
+
+// VerifyChecksum verifies the checksum of the VRRP message, implementing
+// gopacket.ChecksumVerifier.  SetNetworkLayerForChecksum must be called
+// before.
+func (v *VRRPv3) VerifyChecksum() (bool, error) {
+	return v.verifyChecksum(v.Contents, nil, IPProtocolVRRP)
+}
+
+// CanDecode specifies the layer type in which we are attempting to unwrap.
+func (v *VRRPv3) CanDecode() gopacket.LayerClass {
+	return LayerTypeVRRP
+}
+
+// NextLayerType specifies the next layer that should be decoded. VRRP does not contain any further payload, so we set to 0
+func (v *VRRPv3) NextLayerType() gopacket.LayerType {
+	return gopacket.LayerTypeZero
+}
+
+// decodeVRRP will parse VRRP v2 or v3
 func decodeVRRP(data []byte, p gopacket.PacketBuilder) error {
 	if len(data) < 8 {
 		return errors.New("Not a valid VRRP packet. Packet length is too small.")
 	}
+	if data[0]>>4 == 3 {
+		return decodingLayerDecoder(&VRRPv3{}, data, p)
+	}
 	v := &VRRPv2{}
 	return decodingLayerDecoder(v, data, p)
 }
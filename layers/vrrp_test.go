@@ -6,8 +6,13 @@
 package layers
 
 import (
-	"github.com/google/gopacket"
+	"bytes"
+	"net"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/google/gopacket"
 )
 
 // vrrpPacketPriority100 is the packet:
@@ -53,3 +58,104 @@ func BenchmarkDecodeVRRPPacket0(b *testing.B) {
 		gopacket.NewPacket(vrrpPacketPriority100, LayerTypeEthernet, gopacket.NoCopy)
 	}
 }
+
+func TestVRRPv2Serialize(t *testing.T) {
+	p := gopacket.NewPacket(vrrpPacketPriority100, LinkTypeEthernet, testDecodeOptions)
+	vrrp := p.Layer(LayerTypeVRRP).(*VRRPv2)
+	if valid, err := vrrp.VerifyChecksum(); !valid || err != nil {
+		t.Errorf("VRRPv2 checksum invalid: %v", err)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := vrrp.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), vrrpPacketPriority100[34:54]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), vrrpPacketPriority100[34:54])
+	}
+}
+
+// vrrpv3PacketIPv6 is a VRRPv3 advertisement over IPv6 with a 500ms interval.
+var vrrpv3PacketIPv6 = []byte{
+	0x33, 0x33, 0x00, 0x00, 0x00, 0x12, 0x00, 0x00, 0x5e, 0x00, 0x02, 0x0a, 0x86, 0xdd, 0x60, 0x00,
+	0x00, 0x00, 0x00, 0x18, 0x70, 0xff, 0xfe, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0xff, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x12, 0x31, 0x0a, 0x64, 0x01, 0x00, 0x32, 0x6e, 0x12, 0xfe, 0x80,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+}
+
+func TestVRRPv3IPv6(t *testing.T) {
+	p := gopacket.NewPacket(vrrpv3PacketIPv6, LinkTypeEthernet, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeEthernet, LayerTypeIPv6, LayerTypeVRRP}, t)
+	vrrp := p.Layer(LayerTypeVRRP).(*VRRPv3)
+	want := &VRRPv3{
+		BaseLayer:    BaseLayer{Contents: vrrpv3PacketIPv6[54:], Payload: []byte{}},
+		Version:      3,
+		Type:         VRRPv2Advertisement,
+		VirtualRtrID: 10,
+		Priority:     100,
+		CountIPAddr:  1,
+		MaxAdverInt:  50,
+		Checksum:     0x6e12,
+		IPAddress:    []net.IP{net.ParseIP("fe80::10")},
+	}
+	if !reflect.DeepEqual(vrrp, want) {
+		t.Errorf("VRRPv3 mismatch:\ngot  %#v\nwant %#v", vrrp, want)
+	}
+	if got := vrrp.AdvertisementInterval(); got != 500*time.Millisecond {
+		t.Errorf("advertisement interval %v", got)
+	}
+	for _, r := range gopacket.VerifyChecksums(p) {
+		if r.Status != gopacket.ChecksumValid {
+			t.Errorf("%v checksum status %v: %v", r.LayerType, r.Status, r.Err)
+		}
+	}
+
+	ip6 := p.Layer(LayerTypeIPv6).(*IPv6)
+	vrrp.SetNetworkLayerForChecksum(ip6)
+	vrrp.Checksum = 0
+	buf := gopacket.NewSerializeBuffer()
+	if err := vrrp.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), vrrpv3PacketIPv6[54:]) {
+		t.Errorf("serialization mismatch:\ngot  %x\nwant %x", buf.Bytes(), vrrpv3PacketIPv6[54:])
+	}
+}
+
+func TestVRRPv3IPv4(t *testing.T) {
+	ip := &IPv4{Version: 4, TTL: 255, Protocol: IPProtocolVRRP, SrcIP: net.IP{192, 168, 0, 30}, DstIP: net.IP{224, 0, 0, 18}}
+	vrrp := &VRRPv3{
+		Version:      3,
+		Type:         VRRPv2Advertisement,
+		VirtualRtrID: 1,
+		Priority:     100,
+		MaxAdverInt:  100,
+		IPAddress:    []net.IP{net.IPv4(192, 168, 0, 1), net.IPv4(192, 168, 0, 2)},
+	}
+	vrrp.SetNetworkLayerForChecksum(ip)
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := gopacket.SerializeLayers(buf, opts, ip, vrrp); err != nil {
+		t.Fatal(err)
+	}
+	if vrrp.Checksum != 0x47ea {
+		t.Errorf("VRRPv3 checksum %#x, want 0x47ea", vrrp.Checksum)
+	}
+
+	p := gopacket.NewPacket(buf.Bytes(), LayerTypeIPv4, testDecodeOptions)
+	if p.ErrorLayer() != nil {
+		t.Fatal("Failed to decode packet:", p.ErrorLayer().Error())
+	}
+	got := p.Layer(LayerTypeVRRP).(*VRRPv3)
+	if got.CountIPAddr != 2 || !got.IPAddress[0].Equal(vrrp.IPAddress[0]) || !got.IPAddress[1].Equal(vrrp.IPAddress[1]) || len(got.IPAddress[1]) != net.IPv4len {
+		t.Errorf("VRRPv3 addresses mismatch: %v", got.IPAddress)
+	}
+	for _, r := range gopacket.VerifyChecksums(p) {
+		if r.Status != gopacket.ChecksumValid {
+			t.Errorf("%v checksum status %v: %v", r.LayerType, r.Status, r.Err)
+		}
+	}
+}
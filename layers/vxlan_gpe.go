@@ -0,0 +1,154 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/gopacket"
+)
+
+// VXLANGPENextProtocol is the protocol of the payload of a VXLAN-GPE header,
+// and of an NSH header, which shares its values.
+type VXLANGPENextProtocol uint8
+
+// VXLAN-GPE next protocols
+const (
+	VXLANGPENextProtocolIPv4     VXLANGPENextProtocol = 0x01
+	VXLANGPENextProtocolIPv6     VXLANGPENextProtocol = 0x02
+	VXLANGPENextProtocolEthernet VXLANGPENextProtocol = 0x03
+	VXLANGPENextProtocolNSH      VXLANGPENextProtocol = 0x04
+	VXLANGPENextProtocolMPLS     VXLANGPENextProtocol = 0x05
+)
+
+func (p VXLANGPENextProtocol) String() string {
+	switch p {
+	case VXLANGPENextProtocolIPv4:
+		return "IPv4"
+	case VXLANGPENextProtocolIPv6:
+		return "IPv6"
+	case VXLANGPENextProtocolEthernet:
+		return "Ethernet"
+	case VXLANGPENextProtocolNSH:
+		return "NSH"
+	case VXLANGPENextProtocolMPLS:
+		return "MPLS"
+	}
+	return fmt.Sprintf("VXLANGPENextProtocol(%d)", uint8(p))
+}
+
+// LayerType returns the layer type of the protocol, or
+// gopacket.LayerTypePayload for unknown protocols.
+func (p VXLANGPENextProtocol) LayerType() gopacket.LayerType {
+	switch p {
+	case VXLANGPENextProtocolIPv4:
+		return LayerTypeIPv4
+	case VXLANGPENextProtocolIPv6:
+		return LayerTypeIPv6
+	case VXLANGPENextProtocolEthernet:
+		return LayerTypeEthernet
+	case VXLANGPENextProtocolNSH:
+		return LayerTypeNSH
+	case VXLANGPENextProtocolMPLS:
+		return LayerTypeMPLS
+	}
+	return gopacket.LayerTypePayload
+}
+
+//  VXLAN-GPE is specified in draft-ietf-nvo3-vxlan-gpe
+//  0                   1                   2                   3
+//  0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |R|R|Ver|I|P|B|O|       Reserved                |Next Protocol  |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+// |                VXLAN Network Identifier (VNI) |   Reserved    |
+// +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+// VXLANGPE is a VXLAN Generic Protocol Extension header, used on UDP port
+// 4790.  Unlike VXLAN, its payload isn't always an Ethernet frame, but the
+// protocol of its NextProtocol field; without the P bit, it is Ethernet.
+type VXLANGPE struct {
+	BaseLayer
+	Version          uint8
+	ValidIDFlag      bool // 'I' bit
+	NextProtocolFlag bool // 'P' bit
+	BUMFlag          bool // 'B' bit, for broadcast, unknown unicast and multicast
+	OAMFlag          bool // 'O' bit
+	NextProtocol     VXLANGPENextProtocol
+	VNI              uint32
+}
+
+// LayerType returns LayerTypeVXLANGPE
+func (v *VXLANGPE) LayerType() gopacket.LayerType { return LayerTypeVXLANGPE }
+
+// CanDecode returns the layer type this DecodingLayer can decode
+func (v *VXLANGPE) CanDecode() gopacket.LayerClass { return LayerTypeVXLANGPE }
+
+// NextLayerType returns the layer type of the payload.
+func (v *VXLANGPE) NextLayerType() gopacket.LayerType {
+	if !v.NextProtocolFlag {
+		return LayerTypeEthernet
+	}
+	return v.NextProtocol.LayerType()
+}
+
+// DecodeFromBytes takes a byte buffer and decodes
+func (v *VXLANGPE) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	if len(data) < 8 {
+		df.SetTruncated()
+		return errors.New("VXLAN-GPE packet too small")
+	}
+	v.Version = data[0] >> 4 & 0x03
+	if v.Version != 0 {
+		return fmt.Errorf("unsupported VXLAN-GPE version %d", v.Version)
+	}
+	v.ValidIDFlag = data[0]&0x08 != 0
+	v.NextProtocolFlag = data[0]&0x04 != 0
+	v.BUMFlag = data[0]&0x02 != 0
+	v.OAMFlag = data[0]&0x01 != 0
+	v.NextProtocol = VXLANGPENextProtocol(data[3])
+	v.VNI = binary.BigEndian.Uint32(data[4:8]) >> 8
+	v.Contents = data[:8]
+	v.Payload = data[8:]
+	return nil
+}
+
+func decodeVXLANGPE(data []byte, p gopacket.PacketBuilder) error {
+	return decodingLayerDecoder(&VXLANGPE{}, data, p)
+}
+
+// SerializeTo writes the serialized form of this layer into the
+// SerializationBuffer, implementing gopacket.SerializableLayer.
+// See the docs for gopacket.SerializableLayer for more info.
+func (v *VXLANGPE) SerializeTo(b gopacket.SerializeBuffer, opts gopacket.SerializeOptions) error {
+	if v.VNI >= 1<<24 {
+		return fmt.Errorf("Virtual Network Identifier = %x exceeds max for 24-bit uint", v.VNI)
+	}
+	bytes, err := b.PrependBytes(8)
+	if err != nil {
+		return err
+	}
+	bytes[0] = (v.Version & 0x03) << 4
+	if v.ValidIDFlag {
+		bytes[0] |= 0x08
+	}
+	if v.NextProtocolFlag {
+		bytes[0] |= 0x04
+	}
+	if v.BUMFlag {
+		bytes[0] |= 0x02
+	}
+	if v.OAMFlag {
+		bytes[0] |= 0x01
+	}
+	bytes[1], bytes[2] = 0, 0
+	bytes[3] = uint8(v.NextProtocol)
+	binary.BigEndian.PutUint32(bytes[4:8], v.VNI<<8)
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestVXLANGPE(t *testing.T) {
+	nsh := &NSH{
+		TTL:           63,
+		MDType:        NSHMDType2,
+		NextProtocol:  VXLANGPENextProtocolIPv4,
+		ServicePathID: 0x123456,
+		ServiceIndex:  255,
+		ContextHeaders: []NSHContextHeader{
+			{Class: 0x0101, Type: 1, Value: []byte{1, 2, 3, 4, 5}},
+		},
+	}
+	data, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 50000, DstPort: 4790}).
+		Layer(&VXLANGPE{ValidIDFlag: true, NextProtocolFlag: true, NextProtocol: VXLANGPENextProtocolNSH, VNI: 0xabcdef}).
+		Layer(nsh).IPv4(nil).UDP(&UDP{SrcPort: 53, DstPort: 53}).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nsh.Length != 5 || nsh.ContextHeaders[0].Length != 5 {
+		t.Fatalf("lengths not fixed: %#v", nsh)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeVXLANGPE, LayerTypeNSH, LayerTypeIPv4, LayerTypeUDP}, t)
+	v := p.Layer(LayerTypeVXLANGPE).(*VXLANGPE)
+	if !v.ValidIDFlag || !v.NextProtocolFlag || v.BUMFlag || v.OAMFlag || v.NextProtocol != VXLANGPENextProtocolNSH || v.VNI != 0xabcdef {
+		t.Errorf("unexpected VXLAN-GPE header %#v", v)
+	}
+	n := p.Layer(LayerTypeNSH).(*NSH)
+	if n.TTL != 63 || n.ServicePathID != 0x123456 || n.ServiceIndex != 255 || len(n.ContextHeaders) != 1 || !bytes.Equal(n.ContextHeaders[0].Value, []byte{1, 2, 3, 4, 5}) {
+		t.Errorf("unexpected NSH %#v", n)
+	}
+
+	// without the P bit, the payload is Ethernet
+	frame, err := Build().Ethernet(nil).IPv4(nil).Payload(nil).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = gopacket.NewPacket(append([]byte{0x08, 0, 0, 0, 0, 0, 1, 0}, frame...), LayerTypeVXLANGPE, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeVXLANGPE, LayerTypeEthernet, LayerTypeIPv4}, t)
+
+	for _, data := range [][]byte{
+		{0x0c, 0, 0, 1, 0, 0, 1},
+		{0x2c, 0, 0, 1, 0, 0, 1, 0},
+	} {
+		if err := (&VXLANGPE{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
+
+func TestNSHMDType1(t *testing.T) {
+	data := append([]byte{0x0f, 0xc6, 0x01, 0x03, 0, 0, 1, 254}, make([]byte, 16)...)
+	n := &NSH{}
+	if err := n.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if n.TTL != 63 || n.Length != 6 || n.MDType != NSHMDType1 || n.NextProtocol != VXLANGPENextProtocolEthernet || n.ServicePathID != 1 || n.ServiceIndex != 254 || len(n.Context) != 16 {
+		t.Errorf("unexpected NSH %#v", n)
+	}
+	buf := gopacket.NewSerializeBuffer()
+	if err := n.SerializeTo(buf, gopacket.SerializeOptions{FixLengths: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("serialized NSH %x, want %x", buf.Bytes(), data)
+	}
+	for _, data := range [][]byte{
+		data[:20],
+		{0x0f, 0xc5, 0x01, 0x03, 0, 0, 1, 254, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0x0f, 0xc3, 0x02, 0x03, 0, 0, 1, 254, 0, 1, 1, 8},
+	} {
+		if err := (&NSH{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/gopacket"
+)
+
+// WebSocketOpcode is the opcode of a WebSocket frame, RFC 6455 section 5.2.
+type WebSocketOpcode uint8
+
+// WebSocket opcodes.
+const (
+	WebSocketOpcodeContinuation WebSocketOpcode = 0x0
+	WebSocketOpcodeText         WebSocketOpcode = 0x1
+	WebSocketOpcodeBinary       WebSocketOpcode = 0x2
+	WebSocketOpcodeClose        WebSocketOpcode = 0x8
+	WebSocketOpcodePing         WebSocketOpcode = 0x9
+	WebSocketOpcodePong         WebSocketOpcode = 0xa
+)
+
+func (o WebSocketOpcode) String() string {
+	switch o {
+	case WebSocketOpcodeContinuation:
+		return "Continuation"
+	case WebSocketOpcodeText:
+		return "Text"
+	case WebSocketOpcodeBinary:
+		return "Binary"
+	case WebSocketOpcodeClose:
+		return "Close"
+	case WebSocketOpcodePing:
+		return "Ping"
+	case WebSocketOpcodePong:
+		return "Pong"
+	}
+	return fmt.Sprintf("WebSocketOpcode(%#x)", uint8(o))
+}
+
+// IsControl returns true for the opcodes of control frames, which can't be
+// fragmented.
+func (o WebSocketOpcode) IsControl() bool {
+	return o&0x8 != 0
+}
+
+// WebSocket is a WebSocket frame, RFC 6455.  Each frame of a packet is
+// decoded as a WebSocket layer.  Messages may be fragmented into a frame with
+// a Text or Binary opcode followed by Continuation frames, the last of which
+// has Fin set; a WebSocketStream reassembles them.
+type WebSocket struct {
+	BaseLayer
+	Fin bool
+	// RSV1, RSV2 and RSV3 are used by extensions, e.g. RSV1 marks messages
+	// compressed by the permessage-deflate extension of RFC 7692.
+	RSV1, RSV2, RSV3 bool
+	Opcode           WebSocketOpcode
+	// Masked is set for frames sent by clients, whose payload is masked with
+	// MaskingKey.
+	Masked        bool
+	MaskingKey    [4]byte
+	PayloadLength uint64
+	// Data is the payload data, unmasked.
+	Data []byte
+	// CloseCode and CloseReason are the status code and reason of Close
+	// frames, if the frame has them.
+	CloseCode   uint16
+	CloseReason string
+	// Message is the data of the message ended by the frame, set by
+	// WebSocketStream on the data frame with Fin set.
+	Message []byte
+}
+
+// LayerType returns LayerTypeWebSocket.
+func (w *WebSocket) LayerType() gopacket.LayerType { return LayerTypeWebSocket }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (w *WebSocket) CanDecode() gopacket.LayerClass { return LayerTypeWebSocket }
+
+// NextLayerType returns LayerTypeWebSocket, since frames are followed by
+// frames, or by nothing.
+func (w *WebSocket) NextLayerType() gopacket.LayerType { return LayerTypeWebSocket }
+
+var errWebSocketTooShort = errors.New("WebSocket frame too short")
+
+// DecodeFromBytes decodes the first frame of data.  The following frames
+// start after Contents.
+func (w *WebSocket) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*w = WebSocket{}
+	if len(data) < 2 {
+		df.SetTruncated()
+		return errWebSocketTooShort
+	}
+	w.Fin = data[0]&0x80 != 0
+	w.RSV1 = data[0]&0x40 != 0
+	w.RSV2 = data[0]&0x20 != 0
+	w.RSV3 = data[0]&0x10 != 0
+	w.Opcode = WebSocketOpcode(data[0] & 0x0f)
+	w.Masked = data[1]&0x80 != 0
+	w.PayloadLength = uint64(data[1] & 0x7f)
+	offset := 2
+	switch w.PayloadLength {
+	case 126:
+		if len(data) < 4 {
+			df.SetTruncated()
+			return errWebSocketTooShort
+		}
+		w.PayloadLength = uint64(binary.BigEndian.Uint16(data[2:4]))
+		offset = 4
+	case 127:
+		if len(data) < 10 {
+			df.SetTruncated()
+			return errWebSocketTooShort
+		}
+		w.PayloadLength = binary.BigEndian.Uint64(data[2:10])
+		if w.PayloadLength>>63 != 0 {
+			return errors.New("invalid WebSocket payload length")
+		}
+		offset = 10
+	}
+	if w.Opcode.IsControl() && (!w.Fin || w.PayloadLength > 125) {
+		return fmt.Errorf("invalid WebSocket %v frame", w.Opcode)
+	}
+	if w.Masked {
+		if len(data) < offset+4 {
+			df.SetTruncated()
+			return errWebSocketTooShort
+		}
+		copy(w.MaskingKey[:], data[offset:offset+4])
+		offset += 4
+	}
+	if uint64(len(data)-offset) < w.PayloadLength {
+		df.SetTruncated()
+		return errWebSocketTooShort
+	}
+	end := offset + int(w.PayloadLength)
+	w.Contents = data[:end]
+	w.Data = data[offset:end]
+	if w.Masked {
+		w.Data = make([]byte, w.PayloadLength)
+		for i, b := range data[offset:end] {
+			w.Data[i] = b ^ w.MaskingKey[i%4]
+		}
+	}
+	if w.Opcode == WebSocketOpcodeClose && len(w.Data) > 0 {
+		if len(w.Data) < 2 {
+			return errors.New("invalid WebSocket Close frame")
+		}
+		w.CloseCode = binary.BigEndian.Uint16(w.Data)
+		w.CloseReason = string(w.Data[2:])
+	}
+	return nil
+}
+
+// decodeWebSocket decodes the frames in data.
+func decodeWebSocket(data []byte, p gopacket.PacketBuilder) error {
+	for len(data) > 0 {
+		w := &WebSocket{}
+		if err := w.DecodeFromBytes(data, p); err != nil {
+			return err
+		}
+		p.AddLayer(w)
+		data = data[len(w.Contents):]
+	}
+	return nil
+}
+
+// webSocketGUID is appended to Sec-WebSocket-Key values to compute
+// Sec-WebSocket-Accept values.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocketAccept returns the Sec-WebSocket-Accept value of a server
+// accepting a handshake with the Sec-WebSocket-Key value key, RFC 6455
+// section 4.2.2.
+func WebSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(key) + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// IsWebSocketUpgrade returns true if h is part of the opening handshake of a
+// WebSocket connection, RFC 6455 section 4: a request to upgrade the
+// connection to WebSocket, or a response switching to it.  After the
+// response, the connection carries WebSocket frames; an HTTPStream notices
+// the switch, see HTTPStream.Rest.
+func (h *HTTP) IsWebSocketUpgrade() bool {
+	if !h.hasToken("upgrade", "websocket") || !h.hasToken("connection", "upgrade") {
+		return false
+	}
+	if h.IsResponse {
+		return h.StatusCode == 101
+	}
+	return h.Method == "GET" && h.Header("Sec-WebSocket-Key") != ""
+}
+
+// WebSocketStream decodes the frames sent in one direction of a WebSocket
+// connection, whose data is passed to Decode as it's reassembled, e.g. the
+// data following the opening handshake returned by HTTPStream.Rest.  It sets
+// the Message of the frames ending data messages.
+type WebSocketStream struct {
+	buf []byte
+	// message is the data of a fragmented message, while Continuation
+	// frames are expected
+	message    []byte
+	fragmented bool
+	failed     bool
+}
+
+// Decode decodes data following the data of previous calls, and returns the
+// frames completed by data.  The returned frames reference the data.  After
+// an error, the stream can't be decoded any further.
+func (s *WebSocketStream) Decode(data []byte) ([]*WebSocket, error) {
+	if s.failed {
+		return nil, nil
+	}
+	s.buf = append(s.buf, data...)
+	var frames []*WebSocket
+	for {
+		w := &WebSocket{}
+		err := w.DecodeFromBytes(s.buf, gopacket.NilDecodeFeedback)
+		if err == errWebSocketTooShort {
+			break
+		}
+		if err == nil {
+			err = s.frameDecoded(w)
+		}
+		if err != nil {
+			s.failed = true
+			return frames, err
+		}
+		frames = append(frames, w)
+		s.buf = s.buf[len(w.Contents):]
+	}
+	if len(s.buf) == 0 {
+		s.buf = nil
+	}
+	return frames, nil
+}
+
+// frameDecoded reassembles fragmented messages, RFC 6455 section 5.4.
+func (s *WebSocketStream) frameDecoded(w *WebSocket) error {
+	switch {
+	case w.Opcode.IsControl():
+		// control frames may be injected between fragments
+		return nil
+	case w.Opcode == WebSocketOpcodeContinuation:
+		if !s.fragmented {
+			return errors.New("unexpected WebSocket Continuation frame")
+		}
+		s.message = append(s.message, w.Data...)
+	default:
+		if s.fragmented {
+			return fmt.Errorf("WebSocket %v frame instead of Continuation", w.Opcode)
+		}
+		if w.Fin {
+			w.Message = w.Data
+			return nil
+		}
+		s.message = append([]byte{}, w.Data...)
+	}
+	s.fragmented = !w.Fin
+	if w.Fin {
+		w.Message, s.message = s.message, nil
+	}
+	return nil
+}
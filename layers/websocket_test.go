@@ -0,0 +1,144 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+func TestWebSocketFrames(t *testing.T) {
+	// the examples of RFC 6455 section 5.7
+	for _, test := range []struct {
+		data   string
+		fin    bool
+		opcode WebSocketOpcode
+		masked bool
+		length uint64
+		want   string
+	}{
+		{data: "810548656c6c6f", fin: true, opcode: WebSocketOpcodeText, length: 5, want: "Hello"},
+		{data: "818537fa213d7f9f4d5158", fin: true, opcode: WebSocketOpcodeText, masked: true, length: 5, want: "Hello"},
+		{data: "010348656c", opcode: WebSocketOpcodeText, length: 3, want: "Hel"},
+		{data: "80026c6f", fin: true, opcode: WebSocketOpcodeContinuation, length: 2, want: "lo"},
+		{data: "890548656c6c6f", fin: true, opcode: WebSocketOpcodePing, length: 5, want: "Hello"},
+		{data: "8a8537fa213d7f9f4d5158", fin: true, opcode: WebSocketOpcodePong, masked: true, length: 5, want: "Hello"},
+	} {
+		w := &WebSocket{}
+		if err := w.DecodeFromBytes(mustDecodeHex(test.data), gopacket.NilDecodeFeedback); err != nil {
+			t.Errorf("%s: %v", test.data, err)
+			continue
+		}
+		if w.Fin != test.fin || w.Opcode != test.opcode || w.Masked != test.masked || w.PayloadLength != test.length || string(w.Data) != test.want {
+			t.Errorf("%s: unexpected frame %#v", test.data, w)
+		}
+	}
+
+	// extended payload lengths
+	for _, length := range []int{256, 65536} {
+		data := []byte{0x82, 0x7e, byte(length >> 8), byte(length)}
+		if length > 0xffff {
+			data = []byte{0x82, 0x7f, 0, 0, 0, 0, 0, byte(length >> 16), byte(length >> 8), byte(length)}
+		}
+		data = append(data, make([]byte, length)...)
+		w := &WebSocket{}
+		if err := w.DecodeFromBytes(data, gopacket.NilDecodeFeedback); err != nil {
+			t.Fatal(err)
+		}
+		if w.Opcode != WebSocketOpcodeBinary || w.PayloadLength != uint64(length) || len(w.Data) != length || len(w.Contents) != len(data) {
+			t.Errorf("unexpected frame of %d bytes: length %d", length, w.PayloadLength)
+		}
+	}
+
+	w := &WebSocket{}
+	if err := w.DecodeFromBytes(mustDecodeHex("880503e8627965"), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if w.CloseCode != 1000 || w.CloseReason != "bye" {
+		t.Errorf("unexpected Close frame %#v", w)
+	}
+
+	for _, data := range []string{"81", "817e00", "81854444", "810548656c", "097e0100", "8801"} {
+		if err := (&WebSocket{}).DecodeFromBytes(mustDecodeHex(data), gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%s: no error", data)
+		}
+	}
+
+	p := gopacket.NewPacket(mustDecodeHex("010348656c"+"890548656c6c6f"+"80026c6f"), LayerTypeWebSocket, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeWebSocket, LayerTypeWebSocket, LayerTypeWebSocket}, t)
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	// the handshake of RFC 6455 section 1.2
+	requests, responses := &HTTPStream{}, &HTTPStream{}
+	requests.Peer, responses.Peer = responses, requests
+	r, err := requests.Decode([]byte("GET /chat HTTP/1.1\r\nHost: server.example.com\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n\x81\x85\x37\xfa\x21\x3d\x7f\x9f"))
+	if err != nil || len(r) != 1 || !r[0].IsWebSocketUpgrade() {
+		t.Fatalf("unexpected request %v, error %v", r, err)
+	}
+	if accept := WebSocketAccept(r[0].Header("Sec-WebSocket-Key")); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("got accept value %s", accept)
+	}
+	r, err = responses.Decode([]byte("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: s3pPLMBiTxaQ9kYGzzhZRbK+xOo=\r\n\r\n"))
+	if err != nil || len(r) != 1 || !r[0].IsWebSocketUpgrade() || !requests.Upgraded() {
+		t.Fatalf("unexpected response %v, error %v", r, err)
+	}
+
+	// the client data following the handshake is decoded as WebSocket
+	client := &WebSocketStream{}
+	frames, err := client.Decode(requests.Rest())
+	if err != nil || len(frames) != 0 {
+		t.Fatalf("unexpected frames %v, error %v", frames, err)
+	}
+	frames, err = client.Decode(mustDecodeHex("4d5158"))
+	if err != nil || len(frames) != 1 || string(frames[0].Message) != "Hello" {
+		t.Fatalf("unexpected frames %v, error %v", frames, err)
+	}
+
+	for _, data := range []string{
+		"GET / HTTP/1.1\r\nUpgrade: h2c\r\nConnection: Upgrade\r\n\r\n",
+		"GET / HTTP/1.1\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n",
+		"HTTP/1.1 200 OK\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n",
+	} {
+		h := &HTTP{}
+		if err := h.DecodeFromBytes([]byte(data), gopacket.NilDecodeFeedback); err != nil {
+			t.Fatal(err)
+		}
+		if h.IsWebSocketUpgrade() {
+			t.Errorf("%q is a WebSocket upgrade", data)
+		}
+	}
+}
+
+func TestWebSocketStream(t *testing.T) {
+	s := &WebSocketStream{}
+	// a fragmented message with a Ping between its fragments
+	frames, err := s.Decode(mustDecodeHex("010348656c" + "890548656c6c6f" + "0001"))
+	if err != nil || len(frames) != 2 || frames[0].Message != nil || frames[1].Message != nil {
+		t.Fatalf("unexpected frames %v, error %v", frames, err)
+	}
+	frames, err = s.Decode(mustDecodeHex("6c" + "80016f" + "82026869"))
+	if err != nil || len(frames) != 3 {
+		t.Fatalf("unexpected frames %v, error %v", frames, err)
+	}
+	if string(frames[1].Message) != "Hello" || !bytes.Equal(frames[2].Message, []byte("hi")) {
+		t.Errorf("unexpected messages %q, %q", frames[1].Message, frames[2].Message)
+	}
+
+	for _, data := range []string{"80026c6f", "010348656c" + "810548656c6c6f"} {
+		if _, err := (&WebSocketStream{}).Decode(mustDecodeHex(data)); err == nil {
+			t.Errorf("%s: no error", data)
+		}
+	}
+}
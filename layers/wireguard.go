@@ -0,0 +1,174 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/google/gopacket"
+)
+
+// WireGuardMessageType is the type of a WireGuard message.
+type WireGuardMessageType uint8
+
+// WireGuard message types
+const (
+	WireGuardHandshakeInitiation WireGuardMessageType = 1
+	WireGuardHandshakeResponse   WireGuardMessageType = 2
+	WireGuardCookieReply         WireGuardMessageType = 3
+	WireGuardTransportData       WireGuardMessageType = 4
+)
+
+func (t WireGuardMessageType) String() string {
+	switch t {
+	case WireGuardHandshakeInitiation:
+		return "HandshakeInitiation"
+	case WireGuardHandshakeResponse:
+		return "HandshakeResponse"
+	case WireGuardCookieReply:
+		return "CookieReply"
+	case WireGuardTransportData:
+		return "TransportData"
+	}
+	return fmt.Sprintf("WireGuardMessageType(%d)", uint8(t))
+}
+
+// wireGuardMessageLengths are the lengths of the handshake messages, and the
+// minimum length of transport data messages, which carry an AEAD tag.
+var wireGuardMessageLengths = [...]int{
+	WireGuardHandshakeInitiation: 148,
+	WireGuardHandshakeResponse:   92,
+	WireGuardCookieReply:         64,
+	WireGuardTransportData:       32,
+}
+
+// WireGuard is a message of the WireGuard protocol, as described in the
+// WireGuard paper.  The encrypted fields of handshake messages are decoded
+// as they are.  The encrypted packet of transport data messages is the
+// payload of the layer.
+//
+// WireGuard has no well-known port; UDP port 51820, the one used in its
+// documentation, is decoded as WireGuard.  To recognize WireGuard on other
+// ports, register DetectWireGuard as a hook for unknown UDP ports.
+type WireGuard struct {
+	BaseLayer
+	Type WireGuardMessageType
+	// SenderIndex is set for handshake initiation and response messages
+	SenderIndex uint32
+	// ReceiverIndex is set for the other messages
+	ReceiverIndex uint32
+	// Ephemeral is the ephemeral public key of handshake messages
+	Ephemeral []byte
+	// EncryptedStatic and EncryptedTimestamp are the encrypted static key
+	// and timestamp of handshake initiations
+	EncryptedStatic    []byte
+	EncryptedTimestamp []byte
+	// EncryptedNothing is the encrypted empty data of handshake responses
+	EncryptedNothing []byte
+	// MAC1 and MAC2 are the MACs of handshake messages; MAC2 is all zeros
+	// unless the sender has a cookie
+	MAC1, MAC2 []byte
+	// Nonce and EncryptedCookie are the fields of cookie replies
+	Nonce           []byte
+	EncryptedCookie []byte
+	// Counter is the nonce counter of transport data messages
+	Counter uint64
+}
+
+// LayerType returns LayerTypeWireGuard.
+func (w *WireGuard) LayerType() gopacket.LayerType { return LayerTypeWireGuard }
+
+// CanDecode returns the set of layer types that this DecodingLayer can decode.
+func (w *WireGuard) CanDecode() gopacket.LayerClass { return LayerTypeWireGuard }
+
+// NextLayerType returns gopacket.LayerTypeZero, since the payload is
+// encrypted.
+func (w *WireGuard) NextLayerType() gopacket.LayerType { return gopacket.LayerTypeZero }
+
+// Payload returns the encrypted packet of transport data messages.
+func (w *WireGuard) Payload() []byte { return w.BaseLayer.Payload }
+
+// IsKeepalive returns true for the transport data messages without data,
+// sent as keepalives.
+func (w *WireGuard) IsKeepalive() bool {
+	return w.Type == WireGuardTransportData && len(w.BaseLayer.Payload) == 16
+}
+
+// DecodeFromBytes decodes the slice into the WireGuard struct.
+func (w *WireGuard) DecodeFromBytes(data []byte, df gopacket.DecodeFeedback) error {
+	*w = WireGuard{}
+	if len(data) < 4 {
+		df.SetTruncated()
+		return errors.New("WireGuard message too short")
+	}
+	w.Type = WireGuardMessageType(data[0])
+	if w.Type < WireGuardHandshakeInitiation || w.Type > WireGuardTransportData || data[1]|data[2]|data[3] != 0 {
+		return fmt.Errorf("invalid WireGuard message type %#x", binary.LittleEndian.Uint32(data))
+	}
+	n := wireGuardMessageLengths[w.Type]
+	if len(data) < n {
+		df.SetTruncated()
+		return fmt.Errorf("WireGuard %v message too short", w.Type)
+	}
+	if w.Type != WireGuardTransportData && len(data) != n {
+		return fmt.Errorf("WireGuard %v message of %d bytes", w.Type, len(data))
+	}
+	w.Contents = data
+	switch w.Type {
+	case WireGuardHandshakeInitiation:
+		w.SenderIndex = binary.LittleEndian.Uint32(data[4:8])
+		w.Ephemeral = data[8:40]
+		w.EncryptedStatic = data[40:88]
+		w.EncryptedTimestamp = data[88:116]
+		w.MAC1, w.MAC2 = data[116:132], data[132:148]
+	case WireGuardHandshakeResponse:
+		w.SenderIndex = binary.LittleEndian.Uint32(data[4:8])
+		w.ReceiverIndex = binary.LittleEndian.Uint32(data[8:12])
+		w.Ephemeral = data[12:44]
+		w.EncryptedNothing = data[44:60]
+		w.MAC1, w.MAC2 = data[60:76], data[76:92]
+	case WireGuardCookieReply:
+		w.ReceiverIndex = binary.LittleEndian.Uint32(data[4:8])
+		w.Nonce = data[8:32]
+		w.EncryptedCookie = data[32:64]
+	case WireGuardTransportData:
+		// packets are padded to multiples of 16 bytes before encryption
+		if (len(data)-16)%16 != 0 {
+			return fmt.Errorf("WireGuard %v message of %d bytes", w.Type, len(data))
+		}
+		w.ReceiverIndex = binary.LittleEndian.Uint32(data[4:8])
+		w.Counter = binary.LittleEndian.Uint64(data[8:16])
+		w.Contents, w.BaseLayer.Payload = data[:16], data[16:]
+	}
+	return nil
+}
+
+func decodeWireGuard(data []byte, p gopacket.PacketBuilder) error {
+	w := &WireGuard{}
+	if err := w.DecodeFromBytes(data, p); err != nil {
+		return err
+	}
+	p.AddLayer(w)
+	p.SetApplicationLayer(w)
+	return nil
+}
+
+// DetectWireGuard recognizes WireGuard messages on any UDP port by their
+// type and length.  Transport data messages have few fixed values, so
+// random data of the right length is recognized too; the handshake messages
+// of both ports should be looked at before trusting it:
+//
+//	gopacket.RegisterUnknownProtocolHook(gopacket.UnknownUDPPort, layers.DetectWireGuard)
+func DetectWireGuard(u *gopacket.UnknownProtocol) gopacket.Decoder {
+	var w WireGuard
+	if w.DecodeFromBytes(u.Data, gopacket.NilDecodeFeedback) != nil {
+		return nil
+	}
+	return LayerTypeWireGuard
+}
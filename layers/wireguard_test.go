@@ -0,0 +1,85 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package layers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+// wireGuardTestMessage returns a message of the given type and length, with
+// the bytes following the type and index set to b.
+func wireGuardTestMessage(t WireGuardMessageType, length int, b byte) []byte {
+	m := bytes.Repeat([]byte{b}, length)
+	copy(m, []byte{byte(t), 0, 0, 0, 0x01, 0x02, 0x03, 0x04})
+	return m
+}
+
+func TestWireGuard(t *testing.T) {
+	initiation := wireGuardTestMessage(WireGuardHandshakeInitiation, 148, 0xaa)
+	data, err := Build().IPv4(nil).UDP(&UDP{SrcPort: 40000, DstPort: 51820}).Payload(initiation).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := gopacket.NewPacket(data, LayerTypeIPv4, gopacket.Default)
+	if p.ErrorLayer() != nil {
+		t.Fatal(p.ErrorLayer().Error())
+	}
+	checkLayers(p, []gopacket.LayerType{LayerTypeIPv4, LayerTypeUDP, LayerTypeWireGuard}, t)
+	w := p.Layer(LayerTypeWireGuard).(*WireGuard)
+	if w.Type != WireGuardHandshakeInitiation || w.SenderIndex != 0x04030201 || len(w.Ephemeral) != 32 ||
+		len(w.EncryptedStatic) != 48 || len(w.EncryptedTimestamp) != 28 || len(w.MAC1) != 16 || len(w.MAC2) != 16 {
+		t.Errorf("unexpected handshake initiation %#v", w)
+	}
+
+	response := wireGuardTestMessage(WireGuardHandshakeResponse, 92, 0xbb)
+	copy(response[8:], []byte{5, 6, 7, 8})
+	w = &WireGuard{}
+	if err := w.DecodeFromBytes(response, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if w.SenderIndex != 0x04030201 || w.ReceiverIndex != 0x08070605 || len(w.EncryptedNothing) != 16 {
+		t.Errorf("unexpected handshake response %#v", w)
+	}
+
+	if err := w.DecodeFromBytes(wireGuardTestMessage(WireGuardCookieReply, 64, 0xcc), gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if w.ReceiverIndex != 0x04030201 || len(w.Nonce) != 24 || len(w.EncryptedCookie) != 32 {
+		t.Errorf("unexpected cookie reply %#v", w)
+	}
+
+	transport := wireGuardTestMessage(WireGuardTransportData, 16, 0)
+	transport[8] = 7
+	transport = append(transport, bytes.Repeat([]byte{0xdd}, 16)...)
+	if err := w.DecodeFromBytes(transport, gopacket.NilDecodeFeedback); err != nil {
+		t.Fatal(err)
+	}
+	if w.Counter != 7 || !w.IsKeepalive() || len(w.Contents) != 16 || len(w.Payload()) != 16 {
+		t.Errorf("unexpected transport data %#v", w)
+	}
+
+	for _, data := range [][]byte{
+		initiation[:147],
+		append(initiation, 0),
+		wireGuardTestMessage(5, 148, 0),
+		wireGuardTestMessage(WireGuardTransportData, 40, 0),
+		{1, 1, 0, 0},
+	} {
+		if err := (&WireGuard{}).DecodeFromBytes(data, gopacket.NilDecodeFeedback); err == nil {
+			t.Errorf("%x: no error", data)
+		}
+		if DetectWireGuard(&gopacket.UnknownProtocol{Data: data}) != nil {
+			t.Errorf("%x detected as WireGuard", data)
+		}
+	}
+	if DetectWireGuard(&gopacket.UnknownProtocol{Data: transport}) != LayerTypeWireGuard {
+		t.Error("WireGuard not detected")
+	}
+}
@@ -9,6 +9,8 @@ package gopacket
 import (
 	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
 )
 
 // LayerType is a unique identifier for each type of layer.  This enumeration
@@ -28,20 +30,41 @@ type LayerTypeMetadata struct {
 	Decoder Decoder
 }
 
-type layerTypeMetadata struct {
-	inUse bool
-	LayerTypeMetadata
-}
-
 // DecodersByLayerName maps layer names to decoders for those layers.
 // This allows users to specify decoders by name to a program and have that
 // program pick the correct decoder accordingly.
+//
+// DecodersByLayerName is updated by RegisterLayerType and OverrideLayerType,
+// so it must not be accessed concurrently with them.  Use DecoderByLayerName
+// if layer types are registered while packets are decoded.
 var DecodersByLayerName = map[string]Decoder{}
 
 const maxLayerType = 2000
 
-var ltMeta [maxLayerType]layerTypeMetadata
-var ltMetaMap = map[LayerType]layerTypeMetadata{}
+// Layer type metadata is replaced, never modified, so that layer types can be
+// registered while packets are decoded.  Registrations are serialized by
+// ltMu; lookups don't lock.
+var (
+	ltMu sync.Mutex
+	// ltMeta holds a *LayerTypeMetadata for every registered layer type below
+	// maxLayerType
+	ltMeta [maxLayerType]atomic.Value
+	// ltMetaMap holds a map[LayerType]*LayerTypeMetadata of all other layer
+	// types
+	ltMetaMap atomic.Value
+	// ltDecoders holds a map[string]Decoder copy of DecodersByLayerName
+	ltDecoders atomic.Value
+)
+
+// layerTypeMeta returns the metadata of t, or nil if t isn't registered
+func layerTypeMeta(t LayerType) *LayerTypeMetadata {
+	if 0 <= t && t < maxLayerType {
+		meta, _ := ltMeta[t].Load().(*LayerTypeMetadata)
+		return meta
+	}
+	metas, _ := ltMetaMap.Load().(map[LayerType]*LayerTypeMetadata)
+	return metas[t]
+}
 
 // RegisterLayerType creates a new layer type and registers it globally.
 // The number passed in must be unique, or a runtime panic will occur.  Numbers
@@ -50,59 +73,99 @@ var ltMetaMap = map[LayerType]layerTypeMetadata{}
 // number (negative or >= 2000) may be used for uncommon application-specific
 // types, and are somewhat slower (they require a map lookup over an array
 // index).
+//
+// Layer types may be registered at any time, even while packets are decoded
+// by other goroutines.
 func RegisterLayerType(num int, meta LayerTypeMetadata) LayerType {
-	if 0 <= num && num < maxLayerType {
-		if ltMeta[num].inUse {
-			panic("Layer type already exists")
-		}
-	} else {
-		if ltMetaMap[LayerType(num)].inUse {
-			panic("Layer type already exists")
-		}
+	ltMu.Lock()
+	defer ltMu.Unlock()
+	if layerTypeMeta(LayerType(num)) != nil {
+		panic("Layer type already exists")
 	}
-	return OverrideLayerType(num, meta)
+	return setLayerType(LayerType(num), meta)
 }
 
 // OverrideLayerType acts like RegisterLayerType, except that if the layer type
 // has already been registered, it overrides the metadata with the passed-in
 // metadata intead of panicing.
 func OverrideLayerType(num int, meta LayerTypeMetadata) LayerType {
-	if 0 <= num && num < maxLayerType {
-		ltMeta[num] = layerTypeMetadata{
-			inUse:             true,
-			LayerTypeMetadata: meta,
-		}
+	ltMu.Lock()
+	defer ltMu.Unlock()
+	return setLayerType(LayerType(num), meta)
+}
+
+// OverrideLayerDecoder replaces the decoder of the layer type t, keeping its
+// name, and returns the previous decoder.  All packets decoded afterwards
+// use the new decoder wherever t is decoded, e.g. to replace the decoder of a
+// layer type registered for a TCP port with a custom one:
+//
+//	gopacket.OverrideLayerDecoder(myHTTPLayerType, gopacket.DecodeFunc(decodeMyHTTP))
+//
+// Since this affects all packets of the program, prefer
+// DecodeOptions.DecoderOverrides to replace decoders for some packets only.
+// OverrideLayerDecoder may be called while packets are decoded by other
+// goroutines.
+func OverrideLayerDecoder(t LayerType, d Decoder) Decoder {
+	ltMu.Lock()
+	defer ltMu.Unlock()
+	var meta LayerTypeMetadata
+	if old := layerTypeMeta(t); old != nil {
+		meta = *old
+	}
+	previous := meta.Decoder
+	meta.Decoder = d
+	setLayerType(t, meta)
+	return previous
+}
+
+// setLayerType stores the metadata of t.  ltMu must be held.
+func setLayerType(t LayerType, meta LayerTypeMetadata) LayerType {
+	if 0 <= t && t < maxLayerType {
+		ltMeta[t].Store(&meta)
 	} else {
-		ltMetaMap[LayerType(num)] = layerTypeMetadata{
-			inUse:             true,
-			LayerTypeMetadata: meta,
+		old, _ := ltMetaMap.Load().(map[LayerType]*LayerTypeMetadata)
+		metas := make(map[LayerType]*LayerTypeMetadata, len(old)+1)
+		for k, v := range old {
+			metas[k] = v
 		}
+		metas[t] = &meta
+		ltMetaMap.Store(metas)
 	}
-	DecodersByLayerName[meta.Name] = meta.Decoder
-	return LayerType(num)
+	if meta.Name != "" {
+		DecodersByLayerName[meta.Name] = meta.Decoder
+		old, _ := ltDecoders.Load().(map[string]Decoder)
+		decoders := make(map[string]Decoder, len(old)+1)
+		for k, v := range old {
+			decoders[k] = v
+		}
+		decoders[meta.Name] = meta.Decoder
+		ltDecoders.Store(decoders)
+	}
+	return t
+}
+
+// DecoderByLayerName returns the decoder of the registered layer type with
+// the given name.  Unlike DecodersByLayerName, it may be used while layer
+// types are registered by other goroutines.
+func DecoderByLayerName(name string) (Decoder, bool) {
+	decoders, _ := ltDecoders.Load().(map[string]Decoder)
+	d, ok := decoders[name]
+	return d, ok
 }
 
 // Decode decodes the given data using the decoder registered with the layer
 // type.
 func (t LayerType) Decode(data []byte, c PacketBuilder) error {
-	var d Decoder
-	if 0 <= int(t) && int(t) < maxLayerType {
-		d = ltMeta[int(t)].Decoder
-	} else {
-		d = ltMetaMap[t].Decoder
-	}
-	if d != nil {
-		return d.Decode(data, c)
+	if meta := layerTypeMeta(t); meta != nil && meta.Decoder != nil {
+		return meta.Decoder.Decode(data, c)
 	}
 	return fmt.Errorf("Layer type %v has no associated decoder", t)
 }
 
 // String returns the string associated with this layer type.
 func (t LayerType) String() (s string) {
-	if 0 <= int(t) && int(t) < maxLayerType {
-		s = ltMeta[int(t)].Name
-	} else {
-		s = ltMetaMap[t].Name
+	if meta := layerTypeMeta(t); meta != nil {
+		s = meta.Name
 	}
 	if s == "" {
 		s = strconv.Itoa(int(t))
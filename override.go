@@ -0,0 +1,64 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+// DecoderOverrides replaces the decoders of layer types for the packets
+// decoded with it set in DecodeOptions.DecoderOverrides, without changing the
+// globally registered decoders.  This allows one part of a program to
+// customize decoding, e.g. of a layer type registered for TCP port 80,
+// while other parts still decode with the registered decoders:
+//
+//	overrides := gopacket.NewDecoderOverrides(map[gopacket.LayerType]gopacket.Decoder{
+//	  myHTTPLayerType: gopacket.DecodeFunc(decodeMyHTTP),
+//	})
+//	packet := gopacket.NewPacket(data, layers.LinkTypeEthernet,
+//	  gopacket.DecodeOptions{DecoderOverrides: overrides})
+//
+// An override applies wherever the layer type is decoded, including
+// decoders like layers.EthernetType that decode a layer type.  A
+// DecoderOverrides can't be modified after creation, so it's safe for
+// concurrent use.  It doesn't affect DecodingLayerParser.
+type DecoderOverrides struct {
+	decoders map[LayerType]Decoder
+}
+
+// NewDecoderOverrides returns a new DecoderOverrides replacing the decoder of
+// every layer type in decoders.
+func NewDecoderOverrides(decoders map[LayerType]Decoder) *DecoderOverrides {
+	o := &DecoderOverrides{decoders: make(map[LayerType]Decoder, len(decoders))}
+	for t, d := range decoders {
+		o.decoders[t] = d
+	}
+	return o
+}
+
+// layerTyper is implemented by decoders decoding a layer type, like
+// layers.EthernetType.
+type layerTyper interface {
+	LayerType() LayerType
+}
+
+// Decoder returns the decoder replacing d, or d if there is no override for
+// the layer type decoded by d.
+func (o *DecoderOverrides) Decoder(d Decoder) Decoder {
+	if o == nil {
+		return d
+	}
+	var t LayerType
+	switch d := d.(type) {
+	case LayerType:
+		t = d
+	case layerTyper:
+		t = d.LayerType()
+	default:
+		return d
+	}
+	if override, ok := o.decoders[t]; ok {
+		return override
+	}
+	return d
+}
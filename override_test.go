@@ -0,0 +1,103 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"sync"
+	"testing"
+)
+
+var layerTypeOverrideTest = RegisterLayerType(1990, LayerTypeMetadata{Name: "OverrideTest", Decoder: DecodeFunc(decodePayload)})
+
+// decodeOverrideTestHeader decodes a one byte header followed by a layerTypeOverrideTest
+func decodeOverrideTestHeader(data []byte, p PacketBuilder) error {
+	p.AddLayer(&limitTestLayer{contents: data[:1], payload: data[1:]})
+	return p.NextDecoder(layerTypeOverrideTest)
+}
+
+func decodeOverrideTestFragment(data []byte, p PacketBuilder) error {
+	p.AddLayer(&Fragment{})
+	return nil
+}
+
+func overrideTestLayerTypes(p Packet) []LayerType {
+	var types []LayerType
+	for _, l := range p.Layers() {
+		types = append(types, l.LayerType())
+	}
+	return types
+}
+
+func TestDecoderOverrides(t *testing.T) {
+	data := []byte{1, 2, 3}
+	overrides := NewDecoderOverrides(map[LayerType]Decoder{
+		layerTypeOverrideTest: DecodeFunc(decodeOverrideTestFragment),
+	})
+	for _, lazy := range []bool{false, true} {
+		p := NewPacket(data, DecodeFunc(decodeOverrideTestHeader), DecodeOptions{Lazy: lazy})
+		if types := overrideTestLayerTypes(p); len(types) != 2 || types[1] != LayerTypePayload {
+			t.Errorf("lazy %v: expected registered decoder, got %v", lazy, types)
+		}
+		p = NewPacket(data, DecodeFunc(decodeOverrideTestHeader), DecodeOptions{Lazy: lazy, DecoderOverrides: overrides})
+		if types := overrideTestLayerTypes(p); len(types) != 2 || types[1] != LayerTypeFragment {
+			t.Errorf("lazy %v: expected override, got %v", lazy, types)
+		}
+	}
+
+	// first layer
+	p := NewPacket(data, layerTypeOverrideTest, DecodeOptions{DecoderOverrides: overrides})
+	if types := overrideTestLayerTypes(p); len(types) != 1 || types[0] != LayerTypeFragment {
+		t.Errorf("expected override of first layer, got %v", types)
+	}
+}
+
+func TestOverrideLayerDecoder(t *testing.T) {
+	lt := RegisterLayerType(1991, LayerTypeMetadata{Name: "OverrideDecoderTest", Decoder: DecodeFunc(decodePayload)})
+	previous := OverrideLayerDecoder(lt, DecodeFunc(decodeOverrideTestFragment))
+	if previous == nil {
+		t.Error("expected previous decoder")
+	}
+	if lt.String() != "OverrideDecoderTest" {
+		t.Errorf("name changed to %q", lt.String())
+	}
+	if d, ok := DecoderByLayerName("OverrideDecoderTest"); !ok || d == nil {
+		t.Error("decoder not found by name")
+	}
+	p := NewPacket([]byte{1}, lt, Default)
+	if types := overrideTestLayerTypes(p); len(types) != 1 || types[0] != LayerTypeFragment {
+		t.Errorf("expected overridden decoder, got %v", types)
+	}
+}
+
+func TestConcurrentLayerTypeRegistration(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				RegisterLayerType(100000+i*100+j, LayerTypeMetadata{Name: "ConcurrentTest", Decoder: DecodeFunc(decodePayload)})
+				OverrideLayerDecoder(layerTypeOverrideTest, DecodeFunc(decodePayload))
+			}
+		}(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				p := NewPacket([]byte{1, 2}, DecodeFunc(decodeOverrideTestHeader), Default)
+				if len(p.Layers()) != 2 {
+					t.Errorf("unexpected layers %v", p.Layers())
+				}
+				_ = LayerType(100000 + j).String()
+			}
+		}()
+	}
+	wg.Wait()
+	if LayerType(100349).String() != "ConcurrentTest" {
+		t.Errorf("layer type not registered")
+	}
+}
@@ -8,6 +8,7 @@ package gopacket
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -17,6 +18,8 @@ import (
 	"reflect"
 	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -33,12 +36,47 @@ type CaptureInfo struct {
 	Length int
 	// InterfaceIndex
 	InterfaceIndex int
+	// InterfaceName is the name of the interface the packet was captured on,
+	// if that is known.  Sources that only know the device they capture on,
+	// like pcap handles, report its name, which is "any" for captures on all
+	// interfaces of Linux.
+	InterfaceName string
+	// Direction is the direction of the packet, if that is known.
+	Direction PacketDirection
+	// RxQueue is the receive queue of the network card the packet was
+	// received on.  It is only valid if HasRxQueue is true.
+	RxQueue    int
+	HasRxQueue bool
 	// The packet source can place ancillary data of various types here.
 	// For example, the afpacket source can report the VLAN of captured
 	// packets this way.
 	AncillaryData []interface{}
 }
 
+// PacketDirection is the direction of a captured packet as seen from the
+// capturing host.
+type PacketDirection uint8
+
+const (
+	// PacketDirectionUnknown is used if the packet source doesn't know the
+	// direction.
+	PacketDirectionUnknown PacketDirection = iota
+	// PacketDirectionInbound is used for received packets.
+	PacketDirectionInbound
+	// PacketDirectionOutbound is used for sent packets.
+	PacketDirectionOutbound
+)
+
+func (d PacketDirection) String() string {
+	switch d {
+	case PacketDirectionInbound:
+		return "Inbound"
+	case PacketDirectionOutbound:
+		return "Outbound"
+	}
+	return "Unknown"
+}
+
 // PacketMetadata contains metadata for a packet.
 type PacketMetadata struct {
 	CaptureInfo
@@ -48,6 +86,13 @@ type PacketMetadata struct {
 	// This is also set automatically for packets captured off the wire if
 	// CaptureInfo.CaptureLength < CaptureInfo.Length.
 	Truncated bool
+	// TruncatedLayer is the last layer whose decoder detected that the
+	// packet is truncated, if any.  If decoding stopped because of the
+	// truncation, this is the partially decoded layer.
+	TruncatedLayer Layer
+	// Tags holds user-defined tags attached to the packet by the
+	// application.
+	Tags Tags
 }
 
 // Packet is the primary object used by gopacket.  Packets are created by a
@@ -99,6 +144,16 @@ type Packet interface {
 	Metadata() *PacketMetadata
 }
 
+// Recyclable is implemented by packets that can be returned to a
+// PacketPool.  The packets returned by NewPacket and PacketPool.NewPacket
+// implement it.
+type Recyclable interface {
+	// Recycle returns a packet created by PacketPool.NewPacket to its pool.
+	// Neither the packet nor its layers or data may be used afterwards.  For
+	// packets created by NewPacket, Recycle does nothing.
+	Recycle()
+}
+
 // packet contains all the information we need to fulfill the Packet interface,
 // and its two "subclasses" (yes, no such thing in Go, bear with me),
 // eagerPacket and lazyPacket, provide eager and lazy decoding logic around the
@@ -124,57 +179,175 @@ type packet struct {
 	transport   TransportLayer
 	application ApplicationLayer
 	failure     ErrorLayer
+
+	// buf holds the copy of the packet data of a pooled packet for reuse
+	buf []byte
+	// pool is the PacketPool this packet was created by, or nil
+	pool *PacketPool
+	// released is set by Recycle in debug builds
+	released bool
+
+	// depth is the number of decoders run so far
+	depth int
+	// layerBytes is the sum of the contents lengths of all layers
+	layerBytes int
+	// limitErr is set if a decode limit was exceeded
+	limitErr *ErrDecodeLimit
+	// start is the state of the packet before the running decoder was called
+	start decodeState
+	// truncating is set if the running decoder called SetTruncated
+	truncating bool
+}
+
+// decodeState holds the layers of a packet when a decoder is called, which
+// are restored if the data of the decoder is decoded as payload instead
+type decodeState struct {
+	layers      int
+	link        LinkLayer
+	network     NetworkLayer
+	transport   TransportLayer
+	application ApplicationLayer
 }
 
 func (p *packet) SetTruncated() {
 	p.metadata.Truncated = true
+	p.truncating = true
 }
 
+// startDecoder is called before a decoder is called for the next layer.
+func (p *packet) startDecoder() {
+	p.finishDecoder()
+	p.start = decodeState{
+		layers:      len(p.layers),
+		link:        p.link,
+		network:     p.network,
+		transport:   p.transport,
+		application: p.application,
+	}
+}
+
+// finishDecoder sets the truncated layer if the running decoder called
+// SetTruncated.
+func (p *packet) finishDecoder() {
+	if p.truncating {
+		p.truncating = false
+		if p.start.layers < len(p.layers) {
+			p.metadata.TruncatedLayer = p.layers[p.start.layers]
+		}
+	}
+}
+
+// lastLayer returns the last layer added to the packet, which is used as the
+// context of unknown protocol numbers
+func (p *packet) lastLayer() Layer {
+	return p.last
+}
+
+// The layer setters ignore layers once a decode limit is exceeded, since
+// AddLayer doesn't add them to the layers anymore.
+
 func (p *packet) SetLinkLayer(l LinkLayer) {
-	if p.link == nil {
+	if p.link == nil && p.limitErr == nil {
 		p.link = l
 	}
 }
 
 func (p *packet) SetNetworkLayer(l NetworkLayer) {
-	if p.network == nil {
+	if p.network == nil && p.limitErr == nil {
 		p.network = l
 	}
 }
 
 func (p *packet) SetTransportLayer(l TransportLayer) {
-	if p.transport == nil {
+	if p.transport == nil && p.limitErr == nil {
 		p.transport = l
 	}
 }
 
 func (p *packet) SetApplicationLayer(l ApplicationLayer) {
-	if p.application == nil {
+	if p.application == nil && p.limitErr == nil {
 		p.application = l
 	}
 }
 
 func (p *packet) SetErrorLayer(l ErrorLayer) {
-	if p.failure == nil {
+	if p.failure == nil && p.limitErr == nil {
 		p.failure = l
 	}
 }
 
 func (p *packet) AddLayer(l Layer) {
+	if p.limitErr != nil {
+		return
+	}
+	if max := p.decodeOptions.MaxLayers; max > 0 && len(p.layers) >= max {
+		p.exceedLimit("MaxLayers", max)
+		return
+	}
+	if max := p.decodeOptions.MaxTotalLayerBytes; max > 0 {
+		p.layerBytes += len(l.LayerContents())
+		if p.layerBytes > max {
+			p.exceedLimit("MaxTotalLayerBytes", max)
+			return
+		}
+	}
 	p.layers = append(p.layers, l)
 	p.last = l
 }
 
+// nextDepth counts a decoder about to be run, and returns false if it must not
+// run since a decode limit is exceeded.
+func (p *packet) nextDepth() bool {
+	if p.limitErr != nil {
+		return false
+	}
+	if max := p.decodeOptions.MaxDecodeDepth; max > 0 && p.depth >= max {
+		p.exceedLimit("MaxDecodeDepth", max)
+		return false
+	}
+	p.depth++
+	return true
+}
+
+// exceedLimit stops decoding and adds a DecodeFailure layer with an
+// ErrDecodeLimit error.
+func (p *packet) exceedLimit(limit string, value int) {
+	p.limitErr = &ErrDecodeLimit{Limit: limit, Value: value}
+	fail := &DecodeFailure{err: p.limitErr}
+	if p.last == nil {
+		fail.data = p.data
+	} else {
+		fail.data = p.last.LayerPayload()
+	}
+	p.layers = append(p.layers, fail)
+	p.last = fail
+	if p.failure == nil {
+		p.failure = fail
+	}
+}
+
+// decoder returns the decoder to use for next according to the decode options
+func (p *packet) decoder(next Decoder) Decoder {
+	if p.decodeOptions.Profile != nil {
+		if d, ok := p.decodeOptions.Profile.decoder(p.layers, next); ok {
+			return d
+		}
+	}
+	return p.decodeOptions.DecoderOverrides.Decoder(next)
+}
+
 func (p *packet) DumpPacketData() {
 	fmt.Fprint(os.Stderr, p.packetDump())
 	os.Stderr.Sync()
 }
 
 func (p *packet) Metadata() *PacketMetadata {
+	p.checkReleased()
 	return &p.metadata
 }
 
 func (p *packet) Data() []byte {
+	p.checkReleased()
 	return p.data
 }
 
@@ -183,6 +356,23 @@ func (p *packet) DecodeOptions() *DecodeOptions {
 }
 
 func (p *packet) addFinalDecodeError(err error, stack []byte) {
+	if p.limitErr != nil {
+		// decoders fail after their next decoder was refused
+		return
+	}
+	// only the error of a decoder which found its data truncated is
+	// handled by the truncation policy
+	truncated := p.truncating
+	p.finishDecoder()
+	if truncated && stack == nil {
+		switch p.decodeOptions.Truncation {
+		case TruncationPartial:
+			return
+		case TruncationPayload:
+			p.decodeAsPayload()
+			return
+		}
+	}
 	fail := &DecodeFailure{err: err, stack: stack}
 	if p.last == nil {
 		fail.data = p.data
@@ -193,6 +383,28 @@ func (p *packet) addFinalDecodeError(err error, stack []byte) {
 	p.SetErrorLayer(fail)
 }
 
+// decodeAsPayload removes the layers added by the failed decoder and adds its
+// data as a Payload layer, which is the truncated layer afterwards.
+func (p *packet) decodeAsPayload() {
+	p.layers = p.layers[:p.start.layers]
+	p.link = p.start.link
+	p.network = p.start.network
+	p.transport = p.start.transport
+	p.application = p.start.application
+	p.last = nil
+	data := p.data
+	if len(p.layers) > 0 {
+		p.last = p.layers[len(p.layers)-1]
+		data = p.last.LayerPayload()
+	}
+	if len(data) > 0 {
+		payload := Payload(data)
+		p.AddLayer(&payload)
+		p.SetApplicationLayer(&payload)
+	}
+	p.metadata.TruncatedLayer = p.last
+}
+
 func (p *packet) recoverDecodeError() {
 	if !p.decodeOptions.SkipDecodeRecovery {
 		if r := recover(); r != nil {
@@ -445,35 +657,50 @@ func (p *eagerPacket) NextDecoder(next Decoder) error {
 	if len(d) == 0 {
 		return nil
 	}
+	if !p.nextDepth() {
+		return nil
+	}
+	p.startDecoder()
 	// Since we're eager, immediately call the next decoder.
-	return next.Decode(d, p)
+	return p.decoder(next).Decode(d, p)
 }
 func (p *eagerPacket) initialDecode(dec Decoder) {
 	defer p.recoverDecodeError()
-	err := dec.Decode(p.data, p)
+	p.depth = 1
+	p.startDecoder()
+	err := p.decoder(dec).Decode(p.data, p)
 	if err != nil {
 		p.addFinalDecodeError(err, nil)
+	} else {
+		p.finishDecoder()
 	}
 }
 func (p *eagerPacket) LinkLayer() LinkLayer {
+	p.checkReleased()
 	return p.link
 }
 func (p *eagerPacket) NetworkLayer() NetworkLayer {
+	p.checkReleased()
 	return p.network
 }
 func (p *eagerPacket) TransportLayer() TransportLayer {
+	p.checkReleased()
 	return p.transport
 }
 func (p *eagerPacket) ApplicationLayer() ApplicationLayer {
+	p.checkReleased()
 	return p.application
 }
 func (p *eagerPacket) ErrorLayer() ErrorLayer {
+	p.checkReleased()
 	return p.failure
 }
 func (p *eagerPacket) Layers() []Layer {
+	p.checkReleased()
 	return p.layers
 }
 func (p *eagerPacket) Layer(t LayerType) Layer {
+	p.checkReleased()
 	for _, l := range p.layers {
 		if l.LayerType() == t {
 			return l
@@ -482,6 +709,7 @@ func (p *eagerPacket) Layer(t LayerType) Layer {
 	return nil
 }
 func (p *eagerPacket) LayerClass(lc LayerClass) Layer {
+	p.checkReleased()
 	for _, l := range p.layers {
 		if lc.Contains(l.LayerType()) {
 			return l
@@ -489,16 +717,46 @@ func (p *eagerPacket) LayerClass(lc LayerClass) Layer {
 	}
 	return nil
 }
-func (p *eagerPacket) String() string { return p.packetString() }
-func (p *eagerPacket) Dump() string   { return p.packetDump() }
+func (p *eagerPacket) String() string { p.checkReleased(); return p.packetString() }
+func (p *eagerPacket) Dump() string   { p.checkReleased(); return p.packetDump() }
+func (p *eagerPacket) Recycle() {
+	if p.release() {
+		p.pool.eager.Put(p)
+	}
+}
 
 // lazyPacket does lazy decoding on its packet data.  On construction it does
 // no initial decoding.  For each function call, it decodes only as many layers
 // as are necessary to compute the return value for that function.
 // lazyPacket implements Packet and PacketBuilder.
+//
+// With DecodeOptions.ConcurrentLazy, the Packet methods decode while holding
+// mu.  Once all layers are decoded, done is set and the layers are no longer
+// modified, so they're read without locking.
 type lazyPacket struct {
 	packet
 	next Decoder
+	mu   sync.Mutex
+	done uint32
+}
+
+// lock locks the packet for decoding if it's concurrency-safe and not fully
+// decoded yet, returning true if it has to be unlocked.
+func (p *lazyPacket) lock() bool {
+	if !p.decodeOptions.ConcurrentLazy || atomic.LoadUint32(&p.done) == 1 {
+		return false
+	}
+	p.mu.Lock()
+	return true
+}
+
+// unlock unlocks a packet locked by lock, marking it done if all of its
+// layers are decoded.
+func (p *lazyPacket) unlock() {
+	if p.next == nil {
+		atomic.StoreUint32(&p.done, 1)
+	}
+	p.mu.Unlock()
 }
 
 func (p *lazyPacket) NextDecoder(next Decoder) error {
@@ -523,49 +781,83 @@ func (p *lazyPacket) decodeNextLayer() {
 	if len(d) == 0 {
 		return
 	}
+	if !p.nextDepth() {
+		return
+	}
 	defer p.recoverDecodeError()
-	err := next.Decode(d, p)
+	p.startDecoder()
+	err := p.decoder(next).Decode(d, p)
 	if err != nil {
 		p.addFinalDecodeError(err, nil)
+	} else {
+		p.finishDecoder()
 	}
 }
 func (p *lazyPacket) LinkLayer() LinkLayer {
+	p.checkReleased()
+	if p.lock() {
+		defer p.unlock()
+	}
 	for p.link == nil && p.next != nil {
 		p.decodeNextLayer()
 	}
 	return p.link
 }
 func (p *lazyPacket) NetworkLayer() NetworkLayer {
+	p.checkReleased()
+	if p.lock() {
+		defer p.unlock()
+	}
 	for p.network == nil && p.next != nil {
 		p.decodeNextLayer()
 	}
 	return p.network
 }
 func (p *lazyPacket) TransportLayer() TransportLayer {
+	p.checkReleased()
+	if p.lock() {
+		defer p.unlock()
+	}
 	for p.transport == nil && p.next != nil {
 		p.decodeNextLayer()
 	}
 	return p.transport
 }
 func (p *lazyPacket) ApplicationLayer() ApplicationLayer {
+	p.checkReleased()
+	if p.lock() {
+		defer p.unlock()
+	}
 	for p.application == nil && p.next != nil {
 		p.decodeNextLayer()
 	}
 	return p.application
 }
 func (p *lazyPacket) ErrorLayer() ErrorLayer {
+	p.checkReleased()
+	if p.lock() {
+		defer p.unlock()
+	}
 	for p.failure == nil && p.next != nil {
 		p.decodeNextLayer()
 	}
 	return p.failure
 }
 func (p *lazyPacket) Layers() []Layer {
+	p.checkReleased()
+	if p.lock() {
+		defer p.unlock()
+	}
 	for p.next != nil {
 		p.decodeNextLayer()
 	}
 	return p.layers
 }
 func (p *lazyPacket) Layer(t LayerType) Layer {
+	p.checkReleased()
+	if p.lock() {
+		defer p.unlock()
+	}
 	for _, l := range p.layers {
 		if l.LayerType() == t {
 			return l
@@ -584,6 +876,10 @@ func (p *lazyPacket) Layer(t LayerType) Layer {
 	return nil
 }
 func (p *lazyPacket) LayerClass(lc LayerClass) Layer {
+	p.checkReleased()
+	if p.lock() {
+		defer p.unlock()
+	}
 	for _, l := range p.layers {
 		if lc.Contains(l.LayerType()) {
 			return l
@@ -603,14 +899,51 @@ func (p *lazyPacket) LayerClass(lc LayerClass) Layer {
 }
 func (p *lazyPacket) String() string { p.Layers(); return p.packetString() }
 func (p *lazyPacket) Dump() string   { p.Layers(); return p.packetDump() }
+func (p *lazyPacket) Recycle() {
+	p.next = nil
+	if p.release() {
+		p.pool.lazy.Put(p)
+	}
+}
+
+// TruncationPolicy controls what happens when a packet can't be fully
+// decoded because its headers declare more data than was captured, which is
+// common for captures with a small snaplen.  The policy only applies to
+// decoding errors of packets found to be truncated, see
+// PacketMetadata.Truncated.
+type TruncationPolicy uint8
+
+const (
+	// TruncationError adds a DecodeFailure layer with the error of the layer
+	// that couldn't be decoded, like for any other decoding error.  This is
+	// the default.
+	TruncationError TruncationPolicy = iota
+	// TruncationPartial stops decoding without an error layer, keeping the
+	// layers decoded so far, including the partially decoded layer added by
+	// the failed decoder, if any.  PacketMetadata.TruncatedLayer tells which
+	// layer is incomplete.
+	TruncationPartial
+	// TruncationPayload removes the layers added by the failed decoder and
+	// adds its data as a Payload layer instead, which becomes
+	// PacketMetadata.TruncatedLayer.
+	TruncationPayload
+)
 
 // DecodeOptions tells gopacket how to decode a packet.
 type DecodeOptions struct {
 	// Lazy decoding decodes the minimum number of layers needed to return data
 	// for a packet at each function call.  Be careful using this with concurrent
 	// packet processors, as each call to packet.* could mutate the packet, and
-	// two concurrent function calls could interact poorly.
+	// two concurrent function calls could interact poorly, unless
+	// ConcurrentLazy is also set.
 	Lazy bool
+	// ConcurrentLazy makes lazily decoded packets safe for concurrent use by
+	// multiple goroutines, e.g. when fanning packets out to several
+	// processors.  Decoding is serialized with a lock, which is no longer
+	// taken once all layers have been decoded.  PacketMetadata fields set by
+	// decoding, like Truncated, are only safe to read once all layers have
+	// been decoded, e.g. by calling Layers.  It has no effect without Lazy.
+	ConcurrentLazy bool
 	// NoCopy decoding doesn't copy its input buffer into storage that's owned by
 	// the packet.  If you can guarantee that the bytes underlying the slice
 	// passed into NewPacket aren't going to be modified, this can be faster.  If
@@ -628,6 +961,31 @@ type DecodeOptions struct {
 	// This is disabled by default because the reassembly package drives the decoding
 	// of TCP payload data after reassembly.
 	DecodeStreamsAsDatagrams bool
+	// The following limits protect against hostile packets (e.g. deeply nested
+	// tunnels or looping encapsulations) consuming unbounded CPU and memory.  If
+	// a limit is exceeded, decoding stops and a DecodeFailure layer with an
+	// *ErrDecodeLimit error is added to the packet.
+	//
+	// MaxLayers limits the number of layers of a packet.  0 means unlimited.
+	MaxLayers int
+	// MaxDecodeDepth limits the number of decoders run for a packet, which is
+	// the nesting depth of encapsulations.  0 means unlimited.
+	MaxDecodeDepth int
+	// MaxTotalLayerBytes limits the sum of the contents lengths of all layers of
+	// a packet.  Data decoded repeatedly, e.g. by looping encapsulations, is
+	// counted every time.  0 means unlimited.
+	MaxTotalLayerBytes int
+	// Truncation controls how layers which can't be decoded because the
+	// packet is truncated are handled, see TruncationPolicy.
+	Truncation TruncationPolicy
+	// DecoderOverrides replaces the decoders of some layer types for the
+	// decoded packets.  nil decodes all layer types with their registered
+	// decoders.
+	DecoderOverrides *DecoderOverrides
+	// Profile decodes packets with an explicit decode graph instead of the
+	// global one, see DecodeProfile.  Its decoders take precedence over
+	// DecoderOverrides.
+	Profile *DecodeProfile
 }
 
 // Default decoding provides the safest (but slowest) method for decoding
@@ -642,6 +1000,9 @@ var Default = DecodeOptions{}
 // Lazy is a DecodeOptions with just Lazy set.
 var Lazy = DecodeOptions{Lazy: true}
 
+// ConcurrentLazy is a DecodeOptions with just Lazy and ConcurrentLazy set.
+var ConcurrentLazy = DecodeOptions{Lazy: true, ConcurrentLazy: true}
+
 // NoCopy is a DecodeOptions with just NoCopy set.
 var NoCopy = DecodeOptions{NoCopy: true}
 
@@ -778,13 +1139,22 @@ type ZeroCopyPacketDataSource interface {
 //    handlePacket(packet)  // Do something with each packet.
 //  }
 type PacketSource struct {
-	source  PacketDataSource
-	decoder Decoder
+	// droppedByApplication is accessed atomically, so it must be the first
+	// entry to ensure alignment
+	droppedByApplication uint64
+	source               PacketDataSource
+	decoder              Decoder
 	// DecodeOptions is the set of options to use for decoding each piece
 	// of packet data.  This can/should be changed by the user to reflect the
 	// way packets should be decoded.
 	DecodeOptions
-	c chan Packet
+	// DropWhenFull makes Packets, PacketsCtx and Batches drop packets when
+	// the consumer falls behind and the channel is full, instead of blocking
+	// the reading goroutine.  A blocked reader causes drops in the kernel or
+	// capture library, which are much harder to attribute to the consumer.
+	// Packets dropped this way are counted by DroppedByApplication.
+	DropWhenFull bool
+	c            chan Packet
 }
 
 // NewPacketSource creates a packet data source.
@@ -814,33 +1184,90 @@ func (p *PacketSource) NextPacket() (Packet, error) {
 // is returned by NextPacket().
 func (p *PacketSource) packetsToChannel() {
 	defer close(p.c)
-	for {
-		packet, err := p.NextPacket()
-		if err == nil {
-			p.c <- packet
-			continue
+	p.readPackets(context.Background(), func(packet Packet) bool {
+		return p.send(p.c, packet, nil)
+	})
+}
+
+// send sends packet to c, or drops it if c is full and DropWhenFull is set.
+// It returns false if done is closed before packet could be sent.
+func (p *PacketSource) send(c chan Packet, packet Packet, done <-chan struct{}) bool {
+	if p.DropWhenFull {
+		select {
+		case c <- packet:
+		default:
+			atomic.AddUint64(&p.droppedByApplication, 1)
+			RecyclePacket(packet)
 		}
+		return true
+	}
+	select {
+	case c <- packet:
+		return true
+	case <-done:
+		return false
+	}
+}
 
-		// Immediately retry for temporary network errors
-		if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
+// DroppedByApplication returns the number of packets dropped because the
+// consumer didn't keep up with the PacketSource, see DropWhenFull.
+func (p *PacketSource) DroppedByApplication() uint64 {
+	return atomic.LoadUint64(&p.droppedByApplication)
+}
+
+// classifyReadError returns whether reading packets should be retried
+// immediately or stopped after err was returned by a PacketDataSource.  If
+// neither is true, reading should be retried after a short sleep.
+func classifyReadError(err error) (retry, stop bool) {
+	// Immediately retry for temporary network errors
+	if nerr, ok := err.(net.Error); ok && nerr.Temporary() {
+		return true, false
+	}
+
+	// Immediately retry for EAGAIN
+	if err == syscall.EAGAIN {
+		return true, false
+	}
+
+	// Immediately break for known unrecoverable errors
+	if err == io.EOF || err == io.ErrUnexpectedEOF ||
+		err == io.ErrNoProgress || err == io.ErrClosedPipe || err == io.ErrShortBuffer ||
+		err == syscall.EBADF ||
+		strings.Contains(err.Error(), "use of closed file") {
+		return false, true
+	}
+	return false, false
+}
+
+// readRetryDelay is the time to wait before reading packets again after an
+// error which is neither temporary nor unrecoverable, like a read timeout.
+const readRetryDelay = 5 * time.Millisecond
+
+// readPackets reads in packets from the packet source and passes them to
+// handle.  This routine terminates when a non-temporary error is returned by
+// NextPacket(), when ctx is done, or when handle returns false.
+func (p *PacketSource) readPackets(ctx context.Context, handle func(Packet) bool) {
+	for ctx.Err() == nil {
+		packet, err := p.NextPacket()
+		if err == nil {
+			if !handle(packet) {
+				return
+			}
 			continue
 		}
-
-		// Immediately retry for EAGAIN
-		if err == syscall.EAGAIN {
+		retry, stop := classifyReadError(err)
+		if retry {
 			continue
 		}
-
-		// Immediately break for known unrecoverable errors
-		if err == io.EOF || err == io.ErrUnexpectedEOF ||
-			err == io.ErrNoProgress || err == io.ErrClosedPipe || err == io.ErrShortBuffer ||
-			err == syscall.EBADF ||
-			strings.Contains(err.Error(), "use of closed file") {
-			break
+		if stop {
+			return
 		}
 
 		// Sleep briefly and try again
-		time.Sleep(time.Millisecond * time.Duration(5))
+		select {
+		case <-ctx.Done():
+		case <-time.After(readRetryDelay):
+		}
 	}
 }
 
@@ -862,3 +1289,91 @@ func (p *PacketSource) Packets() chan Packet {
 	}
 	return p.c
 }
+
+// PacketsCtx is like Packets, but stops reading packets and closes the
+// returned channel once ctx is done, so consumers can shut down cleanly:
+//
+//  ctx, cancel := context.WithCancel(context.Background())
+//  defer cancel()
+//  for packet := range packetSource.PacketsCtx(ctx) {
+//    handlePacket(packet)  // Do something with each packet.
+//  }
+//
+// A read of the underlying PacketDataSource blocking while ctx becomes done
+// isn't interrupted, so the channel is closed once it returns.  Every call
+// starts reading into a new channel; PacketsCtx, Batches and Packets must not
+// be used on the same PacketSource at the same time.
+func (p *PacketSource) PacketsCtx(ctx context.Context) chan Packet {
+	c := make(chan Packet, 1000)
+	go func() {
+		defer close(c)
+		p.readPackets(ctx, func(packet Packet) bool {
+			return p.send(c, packet, ctx.Done())
+		})
+	}()
+	return c
+}
+
+// Batches is like PacketsCtx, but delivers the packets in slices of up to n
+// packets, which amortizes the channel operations of consumers handling
+// many packets.  A batch is delivered once it holds n packets, or once
+// timeout has passed since its first packet was read, if timeout is
+// positive.  The last batch is delivered when the underlying
+// PacketDataSource returns an unrecoverable error; batches not yet delivered
+// when ctx becomes done are dropped.
+func (p *PacketSource) Batches(ctx context.Context, n int, timeout time.Duration) chan []Packet {
+	if n < 1 {
+		n = 1
+	}
+	packets := p.PacketsCtx(ctx)
+	c := make(chan []Packet, 1000/n+1)
+	go func() {
+		defer close(c)
+		timer := time.NewTimer(timeout)
+		timer.Stop()
+		var timeoutC <-chan time.Time
+		batch := make([]Packet, 0, n)
+		send := func() bool {
+			timeoutC = nil
+			if len(batch) == 0 {
+				return true
+			}
+			select {
+			case c <- batch:
+			case <-ctx.Done():
+				return false
+			}
+			batch = make([]Packet, 0, n)
+			return true
+		}
+		for {
+			select {
+			case packet, ok := <-packets:
+				if !ok {
+					send()
+					return
+				}
+				batch = append(batch, packet)
+				if len(batch) == n {
+					if !send() {
+						return
+					}
+				} else if len(batch) == 1 && timeout > 0 {
+					if !timer.Stop() {
+						select {
+						case <-timer.C:
+						default:
+						}
+					}
+					timer.Reset(timeout)
+					timeoutC = timer.C
+				}
+			case <-timeoutC:
+				if !send() {
+					return
+				}
+			}
+		}
+	}()
+	return c
+}
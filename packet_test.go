@@ -7,9 +7,12 @@
 package gopacket
 
 import (
+	"context"
 	"io"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 )
 
 type embedded struct {
@@ -60,3 +63,153 @@ func TestConcatPacketSources(t *testing.T) {
 		t.Errorf("expected io.EOF, got %v", err)
 	}
 }
+
+// chanPacketSource returns the packet data sent to it, and io.EOF once it's
+// closed
+type chanPacketSource chan []byte
+
+func (s chanPacketSource) ReadPacketData() ([]byte, CaptureInfo, error) {
+	data, ok := <-s
+	if !ok {
+		return nil, CaptureInfo{}, io.EOF
+	}
+	return data, CaptureInfo{CaptureLength: len(data), Length: len(data)}, nil
+}
+
+func TestPacketSourcePacketsCtx(t *testing.T) {
+	source := make(chanPacketSource, 2)
+	source <- []byte{1}
+	source <- []byte{2}
+	ctx, cancel := context.WithCancel(context.Background())
+	packets := NewPacketSource(source, DecodePayload).PacketsCtx(ctx)
+	for i := byte(1); i <= 2; i++ {
+		if p := <-packets; p.Data()[0] != i {
+			t.Errorf("expected packet %d, got %v", i, p.Data())
+		}
+	}
+	cancel()
+	// unblock the pending read
+	source <- []byte{3}
+	for range packets {
+	}
+}
+
+func TestPacketSourceBatches(t *testing.T) {
+	source := make(chanPacketSource, 7)
+	for i := byte(0); i < 7; i++ {
+		source <- []byte{i}
+	}
+	close(source)
+	var sizes []int
+	var next byte
+	for batch := range NewPacketSource(source, DecodePayload).Batches(context.Background(), 3, 0) {
+		sizes = append(sizes, len(batch))
+		for _, p := range batch {
+			if p.Data()[0] != next {
+				t.Errorf("expected packet %d, got %v", next, p.Data())
+			}
+			next++
+		}
+	}
+	if !reflect.DeepEqual(sizes, []int{3, 3, 1}) {
+		t.Errorf("expected batches of 3, 3 and 1 packets, got %v", sizes)
+	}
+}
+
+func TestPacketSourceBatchesTimeout(t *testing.T) {
+	source := make(chanPacketSource, 2)
+	source <- []byte{1}
+	source <- []byte{2}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	batches := NewPacketSource(source, DecodePayload).Batches(ctx, 10, 10*time.Millisecond)
+	select {
+	case batch := <-batches:
+		if len(batch) != 2 {
+			t.Errorf("expected a batch of 2 packets, got %d", len(batch))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for batch")
+	}
+	source <- []byte{3}
+	close(source)
+	if batch := <-batches; len(batch) != 1 {
+		t.Errorf("expected a batch of 1 packet, got %d", len(batch))
+	}
+	if _, ok := <-batches; ok {
+		t.Error("expected closed channel")
+	}
+}
+
+// eofPacketSource returns n packets, then closes eof and returns io.EOF
+type eofPacketSource struct {
+	n   int
+	eof chan struct{}
+}
+
+func (s *eofPacketSource) ReadPacketData() ([]byte, CaptureInfo, error) {
+	if s.n == 0 {
+		close(s.eof)
+		return nil, CaptureInfo{}, io.EOF
+	}
+	s.n--
+	return []byte{1}, CaptureInfo{CaptureLength: 1, Length: 1}, nil
+}
+
+func TestPacketSourceDropWhenFull(t *testing.T) {
+	source := &eofPacketSource{n: 1500, eof: make(chan struct{})}
+	ps := NewPacketSource(source, DecodePayload)
+	ps.DropWhenFull = true
+	packets := ps.Packets()
+	// the reader doesn't block although nothing is consumed
+	select {
+	case <-source.eof:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reader blocked")
+	}
+	received := 0
+	for range packets {
+		received++
+	}
+	if dropped := ps.DroppedByApplication(); received != cap(packets) || dropped != uint64(1500-received) {
+		t.Errorf("expected %d received and %d dropped packets, got %d and %d", cap(packets), 1500-cap(packets), received, dropped)
+	}
+}
+
+func TestConcurrentLazy(t *testing.T) {
+	data := make([]byte, 50)
+	pool := NewPacketPool()
+	for _, p := range []Packet{
+		NewPacket(data, DecodeFunc(decodeNested), ConcurrentLazy),
+		pool.NewPacket(data, DecodeFunc(decodeNested), ConcurrentLazy),
+	} {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				switch i % 4 {
+				case 0:
+					if n := len(p.Layers()); n != len(data) {
+						t.Errorf("expected %d layers, got %d", len(data), n)
+					}
+				case 1:
+					if p.Layer(LayerTypeFragment) == nil {
+						t.Error("expected a fragment layer")
+					}
+				case 2:
+					if p.ErrorLayer() != nil {
+						t.Errorf("unexpected error %v", p.ErrorLayer().Error())
+					}
+				default:
+					_ = p.String()
+				}
+			}(i)
+		}
+		wg.Wait()
+		if n := len(p.Layers()); n != len(data) {
+			t.Errorf("expected %d layers, got %d", len(data), n)
+		}
+		RecyclePacket(p)
+	}
+}
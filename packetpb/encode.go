@@ -0,0 +1,306 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+// Package packetpb encodes decoded packets as protocol buffers, for shipping
+// them from capture agents to backends over gRPC, Kafka or similar.
+//
+// The messages are defined in packet.proto in this directory.  A Packet
+// message holds the CaptureInfo of a packet and the most important fields
+// of its Ethernet, IPv4/IPv6, TCP, UDP, DNS and TLS layers.  Backends
+// generate their decoders from packet.proto with protoc; the encoder itself
+// is hand-written and has no dependencies besides gopacket:
+//
+//	var enc packetpb.Encoder
+//	for packet := range packetSource.Packets() {
+//	  producer.Send(enc.Encode(packet))
+//	}
+//
+// Encoding doesn't allocate once the internal buffer of the Encoder has
+// grown to the size of the largest message.
+package packetpb
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// Field numbers of the Packet message
+const (
+	packetCaptureInfo   = 1
+	packetEthernet      = 2
+	packetIP            = 3
+	packetTCP           = 4
+	packetUDP           = 5
+	packetDNS           = 6
+	packetTLS           = 7
+	packetPayloadLength = 8
+	packetDecodeError   = 9
+)
+
+// Encoder encodes packets as Packet messages, reusing its buffer between
+// calls. An Encoder must not be used from multiple goroutines.
+type Encoder struct {
+	buf []byte
+}
+
+// Encode returns the Packet message of p. The returned slice is only valid
+// until the next call of the Encoder.
+func (e *Encoder) Encode(p gopacket.Packet) []byte {
+	e.buf = AppendPacket(e.buf[:0], p)
+	return e.buf
+}
+
+// WriteDelimited writes the Packet message of p to w, prefixed with its
+// length as varint. This is the format of writeDelimitedTo and
+// parseDelimitedFrom of the protobuf libraries for message streams.
+func (e *Encoder) WriteDelimited(w io.Writer, p gopacket.Packet) error {
+	var length [binary.MaxVarintLen64]byte
+	e.buf = append(e.buf[:0], length[:]...)
+	e.buf = AppendPacket(e.buf, p)
+	n := binary.PutUvarint(length[:], uint64(len(e.buf)-len(length)))
+	start := len(length) - n
+	copy(e.buf[start:], length[:n])
+	_, err := w.Write(e.buf[start:])
+	return err
+}
+
+// AppendPacket appends the Packet message of p to b and returns the extended
+// buffer.
+func AppendPacket(b []byte, p gopacket.Packet) []byte {
+	b = appendCaptureInfo(b, p.Metadata())
+
+	var (
+		eth       *layers.Ethernet
+		vlans     []*layers.Dot1Q
+		ip4       *layers.IPv4
+		ip6       *layers.IPv6
+		tcp       *layers.TCP
+		udp       *layers.UDP
+		dns       *layers.DNS
+		tls       *layers.TLS
+		vlanArray [4]*layers.Dot1Q
+	)
+	vlans = vlanArray[:0]
+	for _, l := range p.Layers() {
+		switch l := l.(type) {
+		case *layers.Ethernet:
+			if eth == nil {
+				eth = l
+			}
+		case *layers.Dot1Q:
+			if ip4 == nil && ip6 == nil {
+				vlans = append(vlans, l)
+			}
+		case *layers.IPv4:
+			if ip4 == nil && ip6 == nil {
+				ip4 = l
+			}
+		case *layers.IPv6:
+			if ip4 == nil && ip6 == nil {
+				ip6 = l
+			}
+		case *layers.TCP:
+			if tcp == nil && udp == nil {
+				tcp = l
+			}
+		case *layers.UDP:
+			if tcp == nil && udp == nil {
+				udp = l
+			}
+		case *layers.DNS:
+			if dns == nil {
+				dns = l
+			}
+		case *layers.TLS:
+			if tls == nil {
+				tls = l
+			}
+		}
+	}
+
+	if eth != nil {
+		b = appendEthernet(b, eth, vlans)
+	}
+	if ip4 != nil {
+		b = appendIPv4(b, ip4)
+	} else if ip6 != nil {
+		b = appendIPv6(b, ip6)
+	}
+	if tcp != nil {
+		b = appendTCP(b, tcp)
+	} else if udp != nil {
+		b = appendUDP(b, udp)
+	}
+	if dns != nil {
+		b = appendDNS(b, dns)
+	}
+	if tls != nil {
+		b = appendTLS(b, tls)
+	}
+	if app := p.ApplicationLayer(); app != nil {
+		b = appendUint(b, packetPayloadLength, uint64(len(app.Payload())))
+	}
+	if failure := p.ErrorLayer(); failure != nil && failure.Error() != nil {
+		b = appendString(b, packetDecodeError, failure.Error().Error())
+	}
+	return b
+}
+
+func appendCaptureInfo(b []byte, md *gopacket.PacketMetadata) []byte {
+	ci := md.CaptureInfo
+	b, start := beginMessage(b, packetCaptureInfo)
+	if !ci.Timestamp.IsZero() {
+		b = appendUint(b, 1, uint64(ci.Timestamp.UnixNano()))
+	}
+	b = appendUint(b, 2, uint64(ci.CaptureLength))
+	b = appendUint(b, 3, uint64(ci.Length))
+	// int32 fields are sign extended to 64 bits
+	b = appendUint(b, 4, uint64(int64(int32(ci.InterfaceIndex))))
+	b = appendBool(b, 5, md.Truncated)
+	return endMessage(b, start)
+}
+
+func appendEthernet(b []byte, eth *layers.Ethernet, vlans []*layers.Dot1Q) []byte {
+	b, start := beginMessage(b, packetEthernet)
+	b = appendBytes(b, 1, eth.SrcMAC)
+	b = appendBytes(b, 2, eth.DstMAC)
+	b = appendUint(b, 3, uint64(eth.EthernetType))
+	if len(vlans) > 0 {
+		// packed repeated field
+		var ids [4 * 2]byte
+		packed := ids[:0]
+		for _, vlan := range vlans {
+			packed = appendVarint(packed, uint64(vlan.VLANIdentifier))
+		}
+		b = appendBytes(b, 4, packed)
+	}
+	return endMessage(b, start)
+}
+
+func appendIPv4(b []byte, ip *layers.IPv4) []byte {
+	b, start := beginMessage(b, packetIP)
+	b = appendUint(b, 1, 4)
+	b = appendBytes(b, 2, ip.SrcIP.To4())
+	b = appendBytes(b, 3, ip.DstIP.To4())
+	b = appendUint(b, 4, uint64(ip.Protocol))
+	b = appendUint(b, 5, uint64(ip.TTL))
+	b = appendUint(b, 6, uint64(ip.Length))
+	b = appendUint(b, 7, uint64(ip.TOS))
+	b = appendUint(b, 8, uint64(ip.Id))
+	b = appendUint(b, 9, uint64(ip.Flags))
+	b = appendUint(b, 10, uint64(ip.FragOffset))
+	return endMessage(b, start)
+}
+
+func appendIPv6(b []byte, ip *layers.IPv6) []byte {
+	b, start := beginMessage(b, packetIP)
+	b = appendUint(b, 1, 6)
+	b = appendBytes(b, 2, ip.SrcIP.To16())
+	b = appendBytes(b, 3, ip.DstIP.To16())
+	b = appendUint(b, 4, uint64(ip.NextHeader))
+	b = appendUint(b, 5, uint64(ip.HopLimit))
+	b = appendUint(b, 6, uint64(ip.Length))
+	b = appendUint(b, 7, uint64(ip.TrafficClass))
+	b = appendUint(b, 11, uint64(ip.FlowLabel))
+	return endMessage(b, start)
+}
+
+func appendTCP(b []byte, tcp *layers.TCP) []byte {
+	var flags uint64
+	for i, set := range [...]bool{tcp.FIN, tcp.SYN, tcp.RST, tcp.PSH, tcp.ACK, tcp.URG, tcp.ECE, tcp.CWR, tcp.NS} {
+		if set {
+			flags |= 1 << uint(i)
+		}
+	}
+	b, start := beginMessage(b, packetTCP)
+	b = appendUint(b, 1, uint64(tcp.SrcPort))
+	b = appendUint(b, 2, uint64(tcp.DstPort))
+	b = appendUint(b, 3, uint64(tcp.Seq))
+	b = appendUint(b, 4, uint64(tcp.Ack))
+	b = appendUint(b, 5, flags)
+	b = appendUint(b, 6, uint64(tcp.Window))
+	b = appendUint(b, 7, uint64(len(tcp.Payload)))
+	return endMessage(b, start)
+}
+
+func appendUDP(b []byte, udp *layers.UDP) []byte {
+	b, start := beginMessage(b, packetUDP)
+	b = appendUint(b, 1, uint64(udp.SrcPort))
+	b = appendUint(b, 2, uint64(udp.DstPort))
+	b = appendUint(b, 3, uint64(udp.Length))
+	b = appendUint(b, 4, uint64(len(udp.Payload)))
+	return endMessage(b, start)
+}
+
+func appendDNS(b []byte, dns *layers.DNS) []byte {
+	b, start := beginMessage(b, packetDNS)
+	b = appendUint(b, 1, uint64(dns.ID))
+	b = appendBool(b, 2, dns.QR)
+	b = appendUint(b, 3, uint64(dns.OpCode))
+	b = appendUint(b, 4, uint64(dns.ResponseCode))
+	for i := range dns.Questions {
+		q := &dns.Questions[i]
+		var qstart int
+		b, qstart = beginMessage(b, 5)
+		b = appendBytes(b, 1, q.Name)
+		b = appendUint(b, 2, uint64(q.Type))
+		b = appendUint(b, 3, uint64(q.Class))
+		b = endMessage(b, qstart)
+	}
+	for i := range dns.Answers {
+		rr := &dns.Answers[i]
+		var rstart int
+		b, rstart = beginMessage(b, 6)
+		b = appendBytes(b, 1, rr.Name)
+		b = appendUint(b, 2, uint64(rr.Type))
+		b = appendUint(b, 3, uint64(rr.Class))
+		b = appendUint(b, 4, uint64(rr.TTL))
+		switch rr.Type {
+		case layers.DNSTypeA:
+			b = appendBytes(b, 5, rr.IP.To4())
+		case layers.DNSTypeAAAA:
+			b = appendBytes(b, 5, rr.IP.To16())
+		case layers.DNSTypeNS:
+			b = appendBytes(b, 6, rr.NS)
+		case layers.DNSTypeCNAME:
+			b = appendBytes(b, 6, rr.CNAME)
+		case layers.DNSTypePTR:
+			b = appendBytes(b, 6, rr.PTR)
+		case layers.DNSTypeMX:
+			b = appendBytes(b, 6, rr.MX.Name)
+		}
+		b = endMessage(b, rstart)
+	}
+	return endMessage(b, start)
+}
+
+func appendTLS(b []byte, tls *layers.TLS) []byte {
+	b, start := beginMessage(b, packetTLS)
+	for i := range tls.ChangeCipherSpec {
+		b = appendTLSRecord(b, &tls.ChangeCipherSpec[i].TLSRecordHeader)
+	}
+	for i := range tls.Handshake {
+		b = appendTLSRecord(b, &tls.Handshake[i].TLSRecordHeader)
+	}
+	for i := range tls.AppData {
+		b = appendTLSRecord(b, &tls.AppData[i].TLSRecordHeader)
+	}
+	for i := range tls.Alert {
+		b = appendTLSRecord(b, &tls.Alert[i].TLSRecordHeader)
+	}
+	return endMessage(b, start)
+}
+
+func appendTLSRecord(b []byte, h *layers.TLSRecordHeader) []byte {
+	b, start := beginMessage(b, 1)
+	b = appendUint(b, 1, uint64(h.ContentType))
+	b = appendUint(b, 2, uint64(h.Version))
+	b = appendUint(b, 3, uint64(h.Length))
+	return endMessage(b, start)
+}
@@ -0,0 +1,256 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package packetpb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// message is a parsed protobuf message: the varint and bytes values by field number
+type message map[int][]interface{}
+
+func parse(t *testing.T, data []byte) message {
+	t.Helper()
+	m := message{}
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			t.Fatalf("invalid tag")
+		}
+		data = data[n:]
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			t.Fatalf("invalid varint")
+		}
+		data = data[n:]
+		switch key & 7 {
+		case wireVarint:
+			m[int(key>>3)] = append(m[int(key>>3)], v)
+		case wireBytes:
+			if uint64(len(data)) < v {
+				t.Fatalf("length %d exceeds message", v)
+			}
+			m[int(key>>3)] = append(m[int(key>>3)], data[:v])
+			data = data[v:]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+	return m
+}
+
+func (m message) uint(field int) uint64 {
+	if len(m[field]) == 0 {
+		return 0
+	}
+	return m[field][0].(uint64)
+}
+
+func (m message) bytes(field int) []byte {
+	if len(m[field]) == 0 {
+		return nil
+	}
+	return m[field][0].([]byte)
+}
+
+func (m message) message(t *testing.T, field int) message {
+	return parse(t, m.bytes(field))
+}
+
+func serialize(t *testing.T, ls ...gopacket.SerializableLayer) []byte {
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}, ls...); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncodeTCP(t *testing.T) {
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{1, 2, 3, 4, 5, 6},
+		DstMAC:       net.HardwareAddr{6, 5, 4, 3, 2, 1},
+		EthernetType: layers.EthernetTypeDot1Q,
+	}
+	vlan := &layers.Dot1Q{VLANIdentifier: 300, Type: layers.EthernetTypeIPv4}
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Id:       7,
+		Flags:    layers.IPv4DontFragment,
+		Protocol: layers.IPProtocolTCP,
+		SrcIP:    net.IP{10, 0, 0, 1},
+		DstIP:    net.IP{10, 0, 0, 2},
+	}
+	tcp := &layers.TCP{SrcPort: 12345, DstPort: 80, Seq: 1000, Ack: 2000, SYN: true, ACK: true, Window: 1024}
+	tcp.SetNetworkLayerForChecksum(ip)
+	data := serialize(t, eth, vlan, ip, tcp, gopacket.Payload("hello"))
+
+	p := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+	ts := time.Unix(1500000000, 123)
+	p.Metadata().CaptureInfo = gopacket.CaptureInfo{Timestamp: ts, CaptureLength: len(data), Length: len(data), InterfaceIndex: 2}
+
+	var enc Encoder
+	m := parse(t, enc.Encode(p))
+
+	ci := m.message(t, packetCaptureInfo)
+	if ci.uint(1) != uint64(ts.UnixNano()) || ci.uint(2) != uint64(len(data)) || ci.uint(3) != uint64(len(data)) || ci.uint(4) != 2 {
+		t.Errorf("wrong capture info %v", ci)
+	}
+
+	e := m.message(t, packetEthernet)
+	if !bytes.Equal(e.bytes(1), eth.SrcMAC) || !bytes.Equal(e.bytes(2), eth.DstMAC) || e.uint(3) != uint64(layers.EthernetTypeDot1Q) {
+		t.Errorf("wrong ethernet %v", e)
+	}
+	if id, _ := binary.Uvarint(e.bytes(4)); id != 300 {
+		t.Errorf("wrong vlan ids %v", e.bytes(4))
+	}
+
+	i := m.message(t, packetIP)
+	if i.uint(1) != 4 || !bytes.Equal(i.bytes(2), ip.SrcIP) || !bytes.Equal(i.bytes(3), ip.DstIP) ||
+		i.uint(4) != uint64(layers.IPProtocolTCP) || i.uint(5) != 64 || i.uint(8) != 7 || i.uint(9) != uint64(layers.IPv4DontFragment) {
+		t.Errorf("wrong ip %v", i)
+	}
+
+	tc := m.message(t, packetTCP)
+	if tc.uint(1) != 12345 || tc.uint(2) != 80 || tc.uint(3) != 1000 || tc.uint(4) != 2000 || tc.uint(5) != 0x12 || tc.uint(6) != 1024 || tc.uint(7) != 5 {
+		t.Errorf("wrong tcp %v", tc)
+	}
+	if m.uint(packetPayloadLength) != 5 {
+		t.Errorf("wrong payload length %d", m.uint(packetPayloadLength))
+	}
+	for _, field := range []int{packetUDP, packetDNS, packetTLS, packetDecodeError} {
+		if _, ok := m[field]; ok {
+			t.Errorf("unexpected field %d", field)
+		}
+	}
+}
+
+func TestEncodeDNS(t *testing.T) {
+	dns := &layers.DNS{
+		ID:        0x1234,
+		QR:        true,
+		Questions: []layers.DNSQuestion{{Name: []byte("example.com"), Type: layers.DNSTypeA, Class: layers.DNSClassIN}},
+	}
+	// enough answers to need multi-byte message lengths
+	for i := 0; i < 20; i++ {
+		dns.Answers = append(dns.Answers, layers.DNSResourceRecord{
+			Name: []byte("example.com"), Type: layers.DNSTypeA, Class: layers.DNSClassIN, TTL: 300, IP: net.IP{192, 0, 2, byte(i)},
+		})
+	}
+	// names aren't necessarily valid UTF-8 and are encoded as bytes
+	dns.Answers = append(dns.Answers, layers.DNSResourceRecord{
+		Name: []byte("www.example.com"), Type: layers.DNSTypeCNAME, Class: layers.DNSClassIN, TTL: 60, CNAME: []byte("ex\xffample.com"),
+	})
+	ip := &layers.IPv6{Version: 6, HopLimit: 32, NextHeader: layers.IPProtocolUDP, SrcIP: net.ParseIP("2001:db8::1"), DstIP: net.ParseIP("2001:db8::2")}
+	udp := &layers.UDP{SrcPort: 53, DstPort: 5353}
+	udp.SetNetworkLayerForChecksum(ip)
+	data := serialize(t, ip, udp, dns)
+
+	p := gopacket.NewPacket(data, layers.LayerTypeIPv6, gopacket.Default)
+	var buf bytes.Buffer
+	var enc Encoder
+	if err := enc.WriteDelimited(&buf, p); err != nil {
+		t.Fatal(err)
+	}
+	length, n := binary.Uvarint(buf.Bytes())
+	if n <= 0 || int(length) != buf.Len()-n {
+		t.Fatalf("wrong length prefix %d for %d bytes", length, buf.Len()-n)
+	}
+	m := parse(t, buf.Bytes()[n:])
+
+	if i := m.message(t, packetIP); i.uint(1) != 6 || !bytes.Equal(i.bytes(2), ip.SrcIP) || i.uint(5) != 32 {
+		t.Errorf("wrong ip %v", i)
+	}
+	if u := m.message(t, packetUDP); u.uint(1) != 53 || u.uint(2) != 5353 || u.uint(3) != uint64(len(data)-40) {
+		t.Errorf("wrong udp %v", u)
+	}
+
+	d := m.message(t, packetDNS)
+	if d.uint(1) != 0x1234 || d.uint(2) != 1 {
+		t.Errorf("wrong dns header %v", d)
+	}
+	if len(d[5]) != 1 || string(parse(t, d[5][0].([]byte)).bytes(1)) != "example.com" {
+		t.Errorf("wrong questions %v", d[5])
+	}
+	if len(d[6]) != 21 {
+		t.Fatalf("got %d answers, want 21", len(d[6]))
+	}
+	if a := parse(t, d[6][3].([]byte)); !bytes.Equal(a.bytes(5), []byte{192, 0, 2, 3}) || a.uint(4) != 300 {
+		t.Errorf("wrong answer %v", a)
+	}
+	if a := parse(t, d[6][20].([]byte)); string(a.bytes(6)) != "ex\xffample.com" || a.uint(2) != uint64(layers.DNSTypeCNAME) {
+		t.Errorf("wrong cname answer %v", a)
+	}
+}
+
+func TestEncodeTLS(t *testing.T) {
+	// handshake and application data records
+	payload := []byte{
+		0x16, 0x03, 0x03, 0x00, 0x02, 0x01, 0x02,
+		0x17, 0x03, 0x03, 0x00, 0x03, 0x01, 0x02, 0x03,
+	}
+	p := gopacket.NewPacket(payload, layers.LayerTypeTLS, gopacket.Default)
+
+	var enc Encoder
+	m := parse(t, enc.Encode(p))
+	records := m.message(t, packetTLS)[1]
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	if r := parse(t, records[0].([]byte)); r.uint(1) != 22 || r.uint(2) != 0x0303 || r.uint(3) != 2 {
+		t.Errorf("wrong handshake record %v", r)
+	}
+	if r := parse(t, records[1].([]byte)); r.uint(1) != 23 || r.uint(3) != 3 {
+		t.Errorf("wrong application data record %v", r)
+	}
+}
+
+func TestEncodeDecodeError(t *testing.T) {
+	p := gopacket.NewPacket([]byte{0x45, 0x00}, layers.LayerTypeIPv4, gopacket.Default)
+	var enc Encoder
+	m := parse(t, enc.Encode(p))
+	if len(m.bytes(packetDecodeError)) == 0 {
+		t.Error("missing decode error")
+	}
+}
+
+func TestEndMessage(t *testing.T) {
+	for _, n := range []int{0, 1, 127, 128, 300, 20000} {
+		b, start := beginMessage([]byte{0xff}, 3)
+		b = append(b, bytes.Repeat([]byte{0xaa}, n)...)
+		b = endMessage(b, start)
+		m := parse(t, b[1:])
+		if got := m.bytes(3); len(got) != n {
+			t.Errorf("got %d bytes, want %d", len(got), n)
+		}
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	ip := &layers.IPv4{Version: 4, TTL: 64, Protocol: layers.IPProtocolTCP, SrcIP: net.IP{10, 0, 0, 1}, DstIP: net.IP{10, 0, 0, 2}}
+	tcp := &layers.TCP{SrcPort: 12345, DstPort: 80, ACK: true}
+	tcp.SetNetworkLayerForChecksum(ip)
+	eth := &layers.Ethernet{SrcMAC: net.HardwareAddr{1, 2, 3, 4, 5, 6}, DstMAC: net.HardwareAddr{6, 5, 4, 3, 2, 1}, EthernetType: layers.EthernetTypeIPv4}
+	buf := gopacket.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, eth, ip, tcp); err != nil {
+		b.Fatal(err)
+	}
+	p := gopacket.NewPacket(buf.Bytes(), layers.LayerTypeEthernet, gopacket.Default)
+	var enc Encoder
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc.Encode(p)
+	}
+}
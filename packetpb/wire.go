@@ -0,0 +1,80 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package packetpb
+
+// Wire types of the protobuf encoding.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendTag(b []byte, field, wire int) []byte {
+	return appendVarint(b, uint64(field)<<3|uint64(wire))
+}
+
+// appendUint appends a varint field. Zero values are omitted as in proto3.
+func appendUint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return appendVarint(appendTag(b, field, wireVarint), v)
+}
+
+func appendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return append(appendTag(b, field, wireVarint), 1)
+}
+
+func appendBytes(b []byte, field int, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = appendVarint(appendTag(b, field, wireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendString(b []byte, field int, v string) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = appendVarint(appendTag(b, field, wireBytes), uint64(len(v)))
+	return append(b, v...)
+}
+
+// beginMessage appends the tag of an embedded message and reserves a single
+// byte for its length. The returned offset must be passed to endMessage
+// after appending the message fields.
+func beginMessage(b []byte, field int) ([]byte, int) {
+	b = append(appendTag(b, field, wireBytes), 0)
+	return b, len(b)
+}
+
+// endMessage fills in the length of the message started at start, moving the
+// fields if the length doesn't fit into the reserved byte.
+func endMessage(b []byte, start int) []byte {
+	n := len(b) - start
+	if n < 0x80 {
+		b[start-1] = byte(n)
+		return b
+	}
+	var length [10]byte
+	l := appendVarint(length[:0], uint64(n))
+	b = append(b, l[1:]...)
+	copy(b[start-1+len(l):], b[start:start+n])
+	copy(b[start-1:], l)
+	return b
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"sync"
+)
+
+// PacketPool recycles Packet objects together with their layer slices and
+// their copies of the packet data.  At high packet rates, allocating a new
+// packet for every NewPacket call puts considerable pressure on the garbage
+// collector; a PacketPool avoids most of these allocations.
+//
+// Packets are created with PacketPool.NewPacket and returned to the pool with
+// RecyclePacket once they're no longer needed:
+//
+//	pool := gopacket.NewPacketPool()
+//	for data := range myByteSliceChannel {
+//	  p := pool.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+//	  doSomethingWithPacket(p)
+//	  gopacket.RecyclePacket(p)
+//	}
+//
+// After Recycle, the packet, its layers, and its data will be reused by a
+// later NewPacket call, so no references to any of them may be kept.  Build
+// with the gopacket_debug tag to catch violations: in debug builds recycled
+// packets are never reused, their data is overwritten, and any method call on
+// a recycled packet panics.
+//
+// A PacketPool is safe for concurrent use by multiple goroutines.  The zero
+// value is ready to use.
+type PacketPool struct {
+	eager, lazy sync.Pool
+}
+
+// NewPacketPool returns a new, empty PacketPool.
+func NewPacketPool() *PacketPool {
+	return &PacketPool{}
+}
+
+// NewPacket is like NewPacket, but reuses a packet recycled to this pool if
+// one is available.
+func (pp *PacketPool) NewPacket(data []byte, firstLayerDecoder Decoder, options DecodeOptions) Packet {
+	if options.Lazy {
+		p, _ := pp.lazy.Get().(*lazyPacket)
+		if p == nil {
+			p = &lazyPacket{}
+		}
+		p.reset(pp, data, options)
+		p.next = firstLayerDecoder
+		p.done = 0
+		return p
+	}
+	p, _ := pp.eager.Get().(*eagerPacket)
+	if p == nil {
+		p = &eagerPacket{}
+	}
+	p.reset(pp, data, options)
+	p.initialDecode(firstLayerDecoder)
+	return p
+}
+
+// RecyclePacket returns p to the pool it was created by if it implements
+// Recyclable.  Other packets, like those of other Packet implementations, are
+// left alone.
+func RecyclePacket(p Packet) {
+	if r, ok := p.(Recyclable); ok {
+		r.Recycle()
+	}
+}
+
+// reset prepares a packet of the given pool for decoding data, reusing its
+// buffers.
+func (p *packet) reset(pool *PacketPool, data []byte, options DecodeOptions) {
+	buf := p.buf
+	if !options.NoCopy {
+		buf = append(buf[:0], data...)
+		data = buf
+	}
+	layers := p.layers[:0]
+	if layers == nil {
+		layers = p.initialLayers[:0]
+	}
+	*p = packet{
+		data:          data,
+		layers:        layers,
+		decodeOptions: options,
+		buf:           buf,
+		pool:          pool,
+	}
+}
+
+// release clears the packet and returns true if it can be put back into its
+// pool.
+func (p *packet) release() bool {
+	if p.pool == nil {
+		return false
+	}
+	if debugPacketPool {
+		if p.released {
+			panic("gopacket: packet recycled twice")
+		}
+		p.released = true
+		// make use of the data through old layers obvious
+		for i := range p.buf {
+			p.buf[i] = 0xff
+		}
+		return false
+	}
+	// don't keep the old layers alive
+	for i := range p.layers {
+		p.layers[i] = nil
+	}
+	*p = packet{
+		layers: p.layers[:0],
+		buf:    p.buf[:0],
+		pool:   p.pool,
+	}
+	return true
+}
+
+// checkReleased panics in debug builds if the packet was already recycled.
+func (p *packet) checkReleased() {
+	if debugPacketPool && p.released {
+		panic("gopacket: use of recycled packet")
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+//go:build gopacket_debug
+// +build gopacket_debug
+
+package gopacket
+
+import (
+	"testing"
+)
+
+func TestPacketPoolUseAfterRecycle(t *testing.T) {
+	pool := NewPacketPool()
+	p := pool.NewPacket([]byte{1, 2, 3}, DecodePayload, Default)
+	payload := p.ApplicationLayer()
+	RecyclePacket(p)
+	if contents := payload.LayerContents(); contents[0] != 0xff {
+		t.Errorf("expected overwritten data, got %v", contents)
+	}
+	for name, f := range map[string]func(){
+		"Data":    func() { p.Data() },
+		"Layers":  func() { p.Layers() },
+		"String":  func() { _ = p.String() },
+		"Recycle": func() { RecyclePacket(p) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %s after Recycle", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
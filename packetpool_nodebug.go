@@ -0,0 +1,13 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+//go:build !gopacket_debug
+// +build !gopacket_debug
+
+package gopacket
+
+// debugPacketPool enables the use-after-recycle checks of PacketPool.
+const debugPacketPool = false
@@ -0,0 +1,67 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPacketPool(t *testing.T) {
+	pool := NewPacketPool()
+	for _, options := range []DecodeOptions{Default, Lazy, NoCopy} {
+		for i := 0; i < 10; i++ {
+			data := bytes.Repeat([]byte{byte(i)}, i+1)
+			p := pool.NewPacket(data, DecodePayload, options)
+			if !bytes.Equal(p.Data(), data) {
+				t.Errorf("%+v: expected data %v, got %v", options, data, p.Data())
+			}
+			layers := p.Layers()
+			if len(layers) != 1 || !bytes.Equal(layers[0].LayerContents(), data) {
+				t.Errorf("%+v: expected single payload layer %v, got %v", options, data, layers)
+			}
+			if p.ErrorLayer() != nil || p.ApplicationLayer() != layers[0] {
+				t.Errorf("%+v: wrong layer pointers", options)
+			}
+			if options.NoCopy != (&p.Data()[0] == &data[0]) {
+				t.Errorf("%+v: data copied wrongly", options)
+			}
+			RecyclePacket(p)
+		}
+	}
+
+	// packets not created by a pool ignore Recycle
+	p := NewPacket([]byte{1, 2, 3}, DecodePayload, Default)
+	RecyclePacket(p)
+	if !bytes.Equal(p.Data(), []byte{1, 2, 3}) {
+		t.Errorf("expected data to be unchanged, got %v", p.Data())
+	}
+}
+
+func BenchmarkPacketPool(b *testing.B) {
+	data := bytes.Repeat([]byte{1}, 100)
+	pool := NewPacketPool()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		RecyclePacket(pool.NewPacket(data, DecodePayload, Default))
+	}
+}
+
+func TestRecyclePacketOtherImplementation(t *testing.T) {
+	pool := NewPacketPool()
+	p := pool.NewPacket([]byte{1, 2, 3}, DecodePayload, Default)
+	if _, ok := p.(Recyclable); !ok {
+		t.Fatal("expected pool packet to be recyclable")
+	}
+	// a Packet implementation wrapping a pool packet isn't Recyclable
+	wrapped := struct{ Packet }{p}
+	RecyclePacket(wrapped)
+	if !bytes.Equal(wrapped.Data(), []byte{1, 2, 3}) {
+		t.Errorf("expected data to be unchanged, got %v", wrapped.Data())
+	}
+	RecyclePacket(p)
+}
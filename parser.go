@@ -188,6 +188,7 @@ type DecodingLayerParser struct {
 	df    DecodeFeedback
 
 	decodeFunc DecodingLayerFunc
+	callbacks  map[LayerType]DecodingLayerCallback
 
 	// Truncated is set when a decode layer detects that the packet has been
 	// truncated.
@@ -237,7 +238,78 @@ func NewDecodingLayerParser(first LayerType, decoders ...DecodingLayer) *Decodin
 // DecodingLayerParser.
 func (l *DecodingLayerParser) SetDecodingLayerContainer(dlc DecodingLayerContainer) {
 	l.dlc = dlc
-	l.decodeFunc = l.dlc.LayersDecoder(l.first, l.df)
+	l.setDecodeFunc()
+}
+
+// DecodingLayerCallback is called by DecodingLayerParser as soon as a layer
+// has been decoded.  layer is the DecodingLayer holding the decoded layer, and
+// decoded holds the types of all layers decoded so far, ending with the type
+// of this layer.  The DecodingLayers of the lower layers still hold their
+// decoded data.  The decoded slice must not be retained or modified.
+//
+// If the callback returns an error, decoding stops and DecodeLayers returns
+// the error.
+type DecodingLayerCallback func(layer DecodingLayer, decoded []LayerType) error
+
+// OnLayer registers a callback which is called whenever a layer of the given
+// type has been decoded by DecodeLayers, before the following layers are
+// decoded.  This allows dispatching layers to their processing while
+// decoding, instead of iterating the decoded slice after DecodeLayers
+// returned:
+//
+//  parser.OnLayer(layers.LayerTypeDNS, func(gopacket.DecodingLayer, []gopacket.LayerType) error {
+//    handleDNS(&ip4, &udp, &dns)
+//    return nil
+//  })
+//
+// A callback replaces the callback previously registered for typ, and nil
+// removes it.  Without callbacks, DecodeLayers uses the faster decoding
+// function of the DecodingLayerContainer.
+func (l *DecodingLayerParser) OnLayer(typ LayerType, callback DecodingLayerCallback) {
+	if callback == nil {
+		delete(l.callbacks, typ)
+	} else {
+		if l.callbacks == nil {
+			l.callbacks = make(map[LayerType]DecodingLayerCallback)
+		}
+		l.callbacks[typ] = callback
+	}
+	l.setDecodeFunc()
+}
+
+func (l *DecodingLayerParser) setDecodeFunc() {
+	if len(l.callbacks) > 0 {
+		l.decodeFunc = l.decodeWithCallbacks
+	} else {
+		l.decodeFunc = l.dlc.LayersDecoder(l.first, l.df)
+	}
+}
+
+// decodeWithCallbacks is a DecodingLayerFunc like the one returned by
+// LayersDecoder, which additionally calls the registered callbacks
+func (l *DecodingLayerParser) decodeWithCallbacks(data []byte, decoded *[]LayerType) (LayerType, error) {
+	*decoded = (*decoded)[:0] // Truncated decoded layers.
+	typ := l.first
+	for {
+		decoder, ok := l.dlc.Decoder(typ)
+		if !ok {
+			return typ, nil
+		}
+		if err := decoder.DecodeFromBytes(data, l.df); err != nil {
+			return LayerTypeZero, err
+		}
+		*decoded = append(*decoded, typ)
+		if callback := l.callbacks[typ]; callback != nil {
+			if err := callback(decoder, *decoded); err != nil {
+				return LayerTypeZero, err
+			}
+		}
+		typ = decoder.NextLayerType()
+		if data = decoder.LayerPayload(); len(data) == 0 {
+			break
+		}
+	}
+	return LayerTypeZero, nil
 }
 
 // DecodeLayers decodes as many layers as possible from the given data.  It
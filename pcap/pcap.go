@@ -321,6 +321,9 @@ func (p *Handle) getNextBufPtrLocked(ci *gopacket.CaptureInfo) error {
 			ci.CaptureLength = p.pkthdr.getCaplen()
 			ci.Length = p.pkthdr.getLen()
 			ci.InterfaceIndex = p.deviceIndex
+			// libpcap doesn't tell the interface of each packet, so this
+			// is the capture device, e.g. "any"
+			ci.InterfaceName = p.device
 
 			return nil
 		case NextErrorNoMorePackets:
@@ -37,11 +37,20 @@ type EthernetHandle struct {
 	ancil  []interface{}
 	mu     sync.Mutex
 	intf   int
+	name   string
 	addr   net.HardwareAddr
 }
 
-// readOne reads a packet from the handle and returns a capture info + vlan info
-func (h *EthernetHandle) readOne() (ci gopacket.CaptureInfo, vlan int, haveVlan bool, err error) {
+// packetDirection returns the direction of a packet with the given sll_pkttype
+func packetDirection(pkttype uint8) gopacket.PacketDirection {
+	if pkttype == unix.PACKET_OUTGOING {
+		return gopacket.PacketDirectionOutbound
+	}
+	return gopacket.PacketDirectionInbound
+}
+
+// readOne reads a packet from the handle and returns a capture info + vlan info + checksum offload info
+func (h *EthernetHandle) readOne() (ci gopacket.CaptureInfo, vlan int, haveVlan bool, offload gopacket.ChecksumOffload, err error) {
 	// we could use unix.Recvmsg, but that does a memory allocation (for the returned sockaddr) :(
 	var msg unix.Msghdr
 	var sa unix.RawSockaddrLinklayer
@@ -66,14 +75,16 @@ func (h *EthernetHandle) readOne() (ci gopacket.CaptureInfo, vlan int, haveVlan
 	n, _, e := syscall.Syscall(unix.SYS_RECVMSG, uintptr(h.fd), uintptr(unsafe.Pointer(&msg)), uintptr(unix.MSG_TRUNC))
 
 	if e != 0 {
-		return gopacket.CaptureInfo{}, 0, false, fmt.Errorf("couldn't read packet: %s", e)
+		return gopacket.CaptureInfo{}, 0, false, offload, fmt.Errorf("couldn't read packet: %s", e)
 	}
 
 	if sa.Family == unix.AF_PACKET {
 		ci.InterfaceIndex = int(sa.Ifindex)
+		ci.Direction = packetDirection(sa.Pkttype)
 	} else {
 		ci.InterfaceIndex = h.intf
 	}
+	ci.InterfaceName = h.name
 
 	// custom aux parsing so we don't allocate stuff (unix.ParseSocketControlMessage allocates a slice)
 	// we're getting at most 2 cmsgs anyway and know which ones they are (auxdata + timestamp(ns))
@@ -89,6 +100,8 @@ func (h *EthernetHandle) readOne() (ci gopacket.CaptureInfo, vlan int, haveVlan
 			ci.Length = int(aux.Len)
 			vlan = int(aux.Vlan_tci)
 			haveVlan = (aux.Status & unix.TP_STATUS_VLAN_VALID) != 0
+			offload.NotReady = (aux.Status & unix.TP_STATUS_CSUMNOTREADY) != 0
+			offload.Verified = (aux.Status & unix.TP_STATUS_CSUM_VALID) != 0
 			gotAux = true
 		case hdr.Level == unix.SOL_SOCKET && hdr.Type == unix.SO_TIMESTAMPNS && len(oob) >= timensLen:
 			tstamp := (*unix.Timespec)(unsafe.Pointer(&oob[hdrLen]))
@@ -117,13 +130,14 @@ func (h *EthernetHandle) readOne() (ci gopacket.CaptureInfo, vlan int, haveVlan
 		ci.Timestamp = time.Now()
 	}
 
-	return ci, vlan, haveVlan, nil
+	return ci, vlan, haveVlan, offload, nil
 }
 
-// ReadPacketData implements gopacket.PacketDataSource. If this was captured on a vlan, the vlan id will be in the AncillaryData[0]
+// ReadPacketData implements gopacket.PacketDataSource. If this was captured on a vlan, the vlan id will be in the AncillaryData[0].
+// If the kernel reported the state of checksum offloading, a gopacket.ChecksumOffload is added to the AncillaryData.
 func (h *EthernetHandle) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
 	h.mu.Lock()
-	ci, vlan, haveVlan, err := h.readOne()
+	ci, vlan, haveVlan, offload, err := h.readOne()
 	if err != nil {
 		h.mu.Unlock()
 		return nil, gopacket.CaptureInfo{}, fmt.Errorf("couldn't read packet data: %s", err)
@@ -137,21 +151,31 @@ func (h *EthernetHandle) ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
 		ci.AncillaryData = []interface{}{vlan}
 
 	}
+	if offload != (gopacket.ChecksumOffload{}) {
+		ci.AncillaryData = append(ci.AncillaryData, offload)
+	}
 
 	return b, ci, nil
 }
 
 // ZeroCopyReadPacketData implements gopacket.ZeroCopyPacketDataSource. If this was captured on a vlan, the vlan id will be in the AncillaryData[0].
+// If the kernel reported the state of checksum offloading, a gopacket.ChecksumOffload is added to the AncillaryData.
 // This function does not allocate memory. Beware that the next call to ZeroCopyReadPacketData will overwrite existing slices (returned data AND AncillaryData)!
 // Due to shared buffers this must not be called concurrently
 func (h *EthernetHandle) ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
-	ci, vlan, haveVlan, err := h.readOne()
+	ci, vlan, haveVlan, offload, err := h.readOne()
 	if err != nil {
 		return nil, gopacket.CaptureInfo{}, fmt.Errorf("couldn't read packet data: %s", err)
 	}
 
+	h.ancil = h.ancil[:0]
 	if haveVlan {
-		h.ancil[0] = vlan
+		h.ancil = append(h.ancil, vlan)
+	}
+	if offload != (gopacket.ChecksumOffload{}) {
+		h.ancil = append(h.ancil, offload)
+	}
+	if len(h.ancil) > 0 {
 		ci.AncillaryData = h.ancil
 	}
 
@@ -277,8 +301,9 @@ func NewEthernetHandle(ifname string) (*EthernetHandle, error) {
 		fd:     fd,
 		buffer: make([]byte, intf.MTU),
 		oob:    make([]byte, ooblen),
-		ancil:  make([]interface{}, 1),
+		ancil:  make([]interface{}, 0, 2),
 		intf:   intf.Index,
+		name:   intf.Name,
 		addr:   intf.HardwareAddr,
 	}
 	runtime.SetFinalizer(handle, (*EthernetHandle).Close)
@@ -0,0 +1,198 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Decompressor wraps a compressed stream and returns a reader producing the uncompressed data.
+type Decompressor func(io.Reader) (io.Reader, error)
+
+// compressionFormat describes a compression format detected by its magic bytes
+type compressionFormat struct {
+	name         string
+	magic        []byte
+	decompressor Decompressor
+}
+
+var compressionFormatsMu sync.RWMutex
+
+// compressionFormats holds the known compression formats. Formats without a decompressor are
+// detected, but reading them fails until a decompressor is registered with RegisterDecompressor.
+var compressionFormats = []compressionFormat{
+	{"gzip", []byte{magicGzip1, magicGzip2}, func(r io.Reader) (io.Reader, error) {
+		return gzip.NewReader(r)
+	}},
+	{"bzip2", []byte{'B', 'Z', 'h'}, func(r io.Reader) (io.Reader, error) {
+		return bzip2.NewReader(r), nil
+	}},
+	{"zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}, nil},
+	{"lz4", []byte{0x04, 0x22, 0x4d, 0x18}, nil},
+	{"xz", []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, nil},
+}
+
+// RegisterDecompressor registers a decompressor for input starting with the given magic bytes. Reader and
+// NgReader detect compressed input by its magic bytes and transparently decompress it with the registered
+// decompressor. gzip and bzip2 are supported out of the box; zstd, lz4, and xz are recognized but need
+// a decompressor, e.g.:
+//
+//	pcapgo.RegisterDecompressor("zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}, func(r io.Reader) (io.Reader, error) {
+//		return zstd.NewReader(r)
+//	})
+//
+// Registering a name that is already known replaces the existing format.
+func RegisterDecompressor(name string, magic []byte, decompressor Decompressor) {
+	compressionFormatsMu.Lock()
+	defer compressionFormatsMu.Unlock()
+	format := compressionFormat{
+		name:         name,
+		magic:        append([]byte(nil), magic...),
+		decompressor: decompressor,
+	}
+	for i := range compressionFormats {
+		if compressionFormats[i].name == name {
+			compressionFormats[i] = format
+			return
+		}
+	}
+	compressionFormats = append(compressionFormats, format)
+}
+
+// detectCompression returns the compression format of the data available from br, or nil if the data doesn't start with a known magic.
+func detectCompression(br *bufio.Reader) (*compressionFormat, error) {
+	compressionFormatsMu.RLock()
+	defer compressionFormatsMu.RUnlock()
+	maxMagic := 0
+	for _, format := range compressionFormats {
+		if len(format.magic) > maxMagic {
+			maxMagic = len(format.magic)
+		}
+	}
+	magic, err := br.Peek(maxMagic)
+	if len(magic) == 0 {
+		return nil, err
+	}
+	for _, format := range compressionFormats {
+		if len(format.magic) > 0 && bytes.HasPrefix(magic, format.magic) {
+			ret := format
+			return &ret, nil
+		}
+	}
+	return nil, nil
+}
+
+// decompress returns a reader with the decompressed contents of br if br holds compressed data, or br otherwise.
+func decompress(br *bufio.Reader) (io.Reader, error) {
+	format, err := detectCompression(br)
+	if err != nil {
+		return nil, err
+	}
+	if format == nil {
+		return br, nil
+	}
+	if format.decompressor == nil {
+		return nil, fmt.Errorf("%s compressed input needs a decompressor; see RegisterDecompressor", format.name)
+	}
+	return format.decompressor(br)
+}
+
+// Compressor starts a new compressed frame writing to w. Closing the returned writer must finish the frame.
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+// GzipCompressor returns a Compressor writing every frame as a separate gzip member with the given compression level.
+// Concatenated gzip members are read as a single stream by gzip readers, including Reader and NgReader.
+func GzipCompressor(level int) Compressor {
+	return func(w io.Writer) (io.WriteCloser, error) {
+		return gzip.NewWriterLevel(w, level)
+	}
+}
+
+// DefaultCompressedFrameSize is the amount of uncompressed data after which CompressedWriter starts a new frame if no frame size is given.
+const DefaultCompressedFrameSize = 1 << 20
+
+// CompressedWriter compresses the data written to it as a sequence of independent frames. If a Writer or NgWriter
+// writes to a CompressedWriter, frames are only finished at block boundaries, which means every finished frame
+// contains complete packets, and a crashed process still leaves a readable file up to the last finished frame.
+// Frames with a size of at least frameSize bytes of uncompressed data are finished after the next block. Flush finishes
+// the current frame immediately, and Close must be called before closing the underlying writer.
+//
+//	f, _ := os.Create("/tmp/file.pcapng.gz")
+//	cw := pcapgo.NewCompressedWriter(f, pcapgo.GzipCompressor(gzip.DefaultCompression), 0)
+//	w, _ := pcapgo.NewNgWriter(cw, layers.LinkTypeEthernet)
+//	w.WritePacket(gopacket.CaptureInfo{...}, data1)
+//	w.Flush()
+//	cw.Close()
+//	f.Close()
+type CompressedWriter struct {
+	w            io.Writer
+	compressor   Compressor
+	frameSize    int
+	frame        io.WriteCloser
+	frameWritten int
+}
+
+// NewCompressedWriter returns a new CompressedWriter writing frames created with compressor to w. If frameSize is 0, DefaultCompressedFrameSize is used.
+func NewCompressedWriter(w io.Writer, compressor Compressor, frameSize int) *CompressedWriter {
+	if frameSize <= 0 {
+		frameSize = DefaultCompressedFrameSize
+	}
+	return &CompressedWriter{
+		w:          w,
+		compressor: compressor,
+		frameSize:  frameSize,
+	}
+}
+
+// Write compresses p into the current frame, starting a new frame if necessary.
+func (c *CompressedWriter) Write(p []byte) (int, error) {
+	if c.frame == nil {
+		frame, err := c.compressor(c.w)
+		if err != nil {
+			return 0, err
+		}
+		c.frame = frame
+	}
+	n, err := c.frame.Write(p)
+	c.frameWritten += n
+	return n, err
+}
+
+// frameFull returns true if the current frame should be finished at the next block boundary, if additional bytes are still pending.
+func (c *CompressedWriter) frameFull(pending int) bool {
+	return c.frameWritten+pending >= c.frameSize
+}
+
+// endBlock must be called at block boundaries and finishes the current frame if it is full.
+func (c *CompressedWriter) endBlock() error {
+	if !c.frameFull(0) {
+		return nil
+	}
+	return c.Flush()
+}
+
+// Flush finishes the current frame, which makes everything written so far readable.
+func (c *CompressedWriter) Flush() error {
+	if c.frame == nil {
+		return nil
+	}
+	err := c.frame.Close()
+	c.frame = nil
+	c.frameWritten = 0
+	return err
+}
+
+// Close finishes the current frame. The underlying writer is not closed.
+func (c *CompressedWriter) Close() error {
+	return c.Flush()
+}
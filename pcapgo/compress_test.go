@@ -0,0 +1,186 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestNgReaderGzip(t *testing.T) {
+	contents, err := ioutil.ReadFile(filepath.Join("tests", "le", "test010.pcapng"))
+	if err != nil {
+		t.Fatal("Couldn't open file:", err)
+	}
+	compressed := &bytes.Buffer{}
+	zw := gzip.NewWriter(compressed)
+	zw.Write(contents)
+	zw.Close()
+
+	for _, test := range tests {
+		if test.testName == "test010" {
+			test.testContents = bytes.NewReader(compressed.Bytes())
+			ngRunFileReadTest(test, "", false, t)
+		}
+	}
+}
+
+// xorDecompressor is a toy codec for testing the decompressor registry
+type xorDecompressor struct {
+	r io.Reader
+}
+
+func (x xorDecompressor) Read(p []byte) (int, error) {
+	n, err := x.r.Read(p)
+	for i := range p[:n] {
+		p[i] ^= 0xff
+	}
+	return n, err
+}
+
+func TestRegisterDecompressor(t *testing.T) {
+	contents, err := ioutil.ReadFile(filepath.Join("tests", "le", "test010.pcapng"))
+	if err != nil {
+		t.Fatal("Couldn't open file:", err)
+	}
+
+	magic := []byte("XOR!")
+	compressed := append([]byte(nil), magic...)
+	for _, b := range contents {
+		compressed = append(compressed, b^0xff)
+	}
+
+	RegisterDecompressor("xor", magic, nil)
+	if _, err := NewNgReader(bytes.NewReader(compressed), DefaultNgReaderOptions); err == nil || !strings.Contains(err.Error(), "xor") {
+		t.Fatal("Expected error about missing xor decompressor, but got", err)
+	}
+
+	RegisterDecompressor("xor", magic, func(r io.Reader) (io.Reader, error) {
+		if _, err := io.ReadFull(r, make([]byte, len(magic))); err != nil {
+			return nil, err
+		}
+		return xorDecompressor{r}, nil
+	})
+	defer RegisterDecompressor("xor", nil, nil)
+
+	for _, test := range tests {
+		if test.testName == "test010" {
+			test.testContents = bytes.NewReader(compressed)
+			ngRunFileReadTest(test, "", false, t)
+		}
+	}
+}
+
+func TestUnsupportedCompression(t *testing.T) {
+	zstd := []byte{0x28, 0xb5, 0x2f, 0xfd, 0, 0, 0, 0}
+	if _, err := NewReader(bytes.NewReader(zstd)); err == nil || !strings.Contains(err.Error(), "zstd") {
+		t.Error("Expected error about missing zstd decompressor, but got", err)
+	}
+	if _, err := NewNgReader(bytes.NewReader(zstd), DefaultNgReaderOptions); err == nil || !strings.Contains(err.Error(), "zstd") {
+		t.Error("Expected error about missing zstd decompressor, but got", err)
+	}
+}
+
+func TestCompressedWriter(t *testing.T) {
+	for _, ng := range []bool{false, true} {
+		buffer := &bytes.Buffer{}
+		// small frames to get a frame for every few packets
+		cw := NewCompressedWriter(buffer, GzipCompressor(gzip.BestSpeed), 1000)
+
+		var writePacket func(gopacket.CaptureInfo, []byte) error
+		var flush func() error
+		if ng {
+			w, err := NewNgWriter(cw, layers.LinkTypeEthernet)
+			if err != nil {
+				t.Fatal("Opening file failed with: ", err)
+			}
+			writePacket = w.WritePacket
+			flush = w.Flush
+		} else {
+			w := NewWriter(cw)
+			if err := w.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+				t.Fatal("Couldn't write file header:", err)
+			}
+			writePacket = w.WritePacket
+			flush = func() error { return nil }
+		}
+
+		// sizes of the compressed stream after every packet
+		var sizes []int
+		for i := 0; i < 20; i++ {
+			data := ngPacketSource[i%len(ngPacketSource)]
+			ci := gopacket.CaptureInfo{
+				Timestamp:     time.Unix(int64(i), 0).UTC(),
+				CaptureLength: len(data),
+				Length:        len(data),
+			}
+			if err := writePacket(ci, data); err != nil {
+				t.Fatal("Couldn't write packet:", err)
+			}
+			sizes = append(sizes, buffer.Len())
+		}
+		if err := flush(); err != nil {
+			t.Fatal("Couldn't flush:", err)
+		}
+		if err := cw.Close(); err != nil {
+			t.Fatal("Couldn't close compressed writer:", err)
+		}
+
+		readAll := func(contents []byte) (n int, err error) {
+			var r interface {
+				ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+			}
+			if ng {
+				r, err = NewNgReader(bytes.NewReader(contents), DefaultNgReaderOptions)
+			} else {
+				r, err = NewReader(bytes.NewReader(contents))
+			}
+			if err != nil {
+				return 0, err
+			}
+			for {
+				if _, _, err = r.ReadPacketData(); err != nil {
+					if err == io.EOF {
+						err = nil
+					}
+					return
+				}
+				n++
+			}
+		}
+
+		n, err := readAll(buffer.Bytes())
+		if err != nil || n != 20 {
+			t.Fatalf("[ng=%v] Expected 20 packets without error, but got %d and %v", ng, n, err)
+		}
+
+		// Simulate a crash by cutting off the last frame. Every packet before the last finished frame must still be readable.
+		last := sizes[len(sizes)-1]
+		finished := 0
+		for i, size := range sizes {
+			if size != last {
+				finished = i + 1
+			}
+		}
+		if finished == 0 || last == sizes[0] {
+			t.Fatalf("[ng=%v] Expected multiple frames", ng)
+		}
+		n, _ = readAll(buffer.Bytes()[:last+(buffer.Len()-last)/2])
+		if n < finished {
+			t.Fatalf("[ng=%v] Expected at least %d packets from truncated file, but got %d", ng, finished, n)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// ConvertFormat is the output format of Convert.
+type ConvertFormat int
+
+// Output formats of Convert
+const (
+	// ConvertAuto converts pcap to pcapng and pcapng to pcap.
+	ConvertAuto ConvertFormat = iota
+	// ConvertPcapng converts to pcapng.
+	ConvertPcapng
+	// ConvertPcap converts to pcap.
+	ConvertPcap
+)
+
+// FlattenPolicy selects how Convert handles pcapng input with multiple interfaces when converting to pcap, which
+// supports only a single link type.
+type FlattenPolicy int
+
+// Flatten policies
+const (
+	// FlattenErrorOnMixedLinkType writes the packets of all interfaces and fails on a packet with a link type different from the first packet.
+	FlattenErrorOnMixedLinkType FlattenPolicy = iota
+	// FlattenDropMixedLinkType writes the packets of all interfaces with the link type of the first packet and drops the others.
+	FlattenDropMixedLinkType
+	// FlattenFirstInterface writes only the packets of the interface of the first packet.
+	FlattenFirstInterface
+)
+
+// ConvertOptions holds options for Convert.
+type ConvertOptions struct {
+	// Format is the output format.
+	Format ConvertFormat
+	// Flatten selects the handling of multiple interfaces when converting pcapng to pcap.
+	Flatten FlattenPolicy
+	// Snaplen overrides the snap length of pcap output. By default the snap length of the first interface is used,
+	// or 262144 if it is unlimited. Longer packets are truncated.
+	Snaplen uint32
+	// Nanosecond enables nanosecond timestamp resolution for pcap output. By default, nanosecond resolution is used if
+	// the input has a finer resolution than microseconds.
+	Nanosecond bool
+	// SectionInfo is written to the section header of pcapng output. The section information of pcapng input is used if empty.
+	SectionInfo NgSectionInfo
+}
+
+// convertDefaultSnaplen is the snap length of pcap output for unlimited input
+const convertDefaultSnaplen = 262144
+
+// Convert reads a pcap or pcapng file from r and writes it to w in the format given in options. Conversion is
+// streaming, and compressed input is uncompressed transparently. Timestamps, link types, snap length, and FCS length
+// are preserved. Converting to pcapng preserves packet comments and flags of pcapng input. Converting to pcap loses
+// all pcapng specific information like interface names and packet options.
+func Convert(w io.Writer, r io.Reader, options ConvertOptions) error {
+	in, err := openMergeInput(r, 0)
+	if err != nil {
+		return err
+	}
+	format := options.Format
+	if format == ConvertAuto {
+		format = ConvertPcapng
+		if in.ng != nil {
+			format = ConvertPcap
+		}
+	}
+	switch format {
+	case ConvertPcapng:
+		return mergeInputs(w, MergeOptions{SectionInfo: options.SectionInfo, KeepDuplicateInterfaces: true}, []*mergeInput{in})
+	case ConvertPcap:
+		return convertToPcap(w, in, options)
+	}
+	return fmt.Errorf("Unknown output format %d", format)
+}
+
+// NewConvertReader returns a reader producing the output of Convert for the input r. Conversion happens in a separate
+// goroutine while the returned reader is read. Closing the returned reader stops the conversion.
+func NewConvertReader(r io.Reader, options ConvertOptions) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(Convert(pw, r, options))
+	}()
+	return pr
+}
+
+// convertToPcap writes the packets of in as pcap
+func convertToPcap(w io.Writer, in *mergeInput, options ConvertOptions) error {
+	err := in.next()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	eof := err == io.EOF
+	intf := in.intf
+	if eof && in.ng != nil {
+		if len(in.ng.ifaces) == 0 {
+			return errors.New("Input contains neither packets nor interfaces")
+		}
+		intf = in.ng.ifaces[0]
+	}
+	firstInterface := mergeInterfaceKey{in.index, in.section, in.ci.InterfaceIndex}
+
+	snaplen := options.Snaplen
+	if snaplen == 0 {
+		snaplen = intf.SnapLength
+	}
+	if snaplen == 0 {
+		snaplen = convertDefaultSnaplen
+	}
+	resolution := intf.Resolution()
+	if in.pcap != nil {
+		resolution = in.pcap.Resolution()
+	}
+	nanosecond := options.Nanosecond || (resolution != gopacket.TimestampResolutionInvalid && resolution.ToDuration() < time.Microsecond)
+
+	var out *Writer
+	if nanosecond {
+		out = NewWriterNanos(w)
+	} else {
+		out = NewWriter(w)
+	}
+	if err := out.WriteFileHeaderFCS(snaplen, intf.LinkType, intf.FCSLength); err != nil {
+		return err
+	}
+
+	for !eof {
+		write := true
+		if options.Flatten == FlattenFirstInterface {
+			write = mergeInterfaceKey{in.index, in.section, in.ci.InterfaceIndex} == firstInterface
+		} else if in.intf.LinkType != intf.LinkType {
+			if options.Flatten == FlattenErrorOnMixedLinkType {
+				return fmt.Errorf("Packet with link type %s can't be written to pcap with link type %s", in.intf.LinkType, intf.LinkType)
+			}
+			write = false
+		}
+		if write {
+			ci := in.ci
+			data := in.data
+			if ci.CaptureLength > int(snaplen) {
+				ci.CaptureLength = int(snaplen)
+				data = data[:snaplen]
+			}
+			if err := out.WritePacket(ci, data); err != nil {
+				return err
+			}
+		}
+		if err := in.next(); err != nil {
+			if err != io.EOF {
+				return err
+			}
+			eof = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestConvertRoundTrip(t *testing.T) {
+	pcap := &bytes.Buffer{}
+	w := NewWriterNanos(pcap)
+	if err := w.WriteFileHeaderFCS(1000, layers.LinkTypeEthernet, 4); err != nil {
+		t.Fatal("Couldn't write header:", err)
+	}
+	for i, data := range ngPacketSource {
+		ci := gopacket.CaptureInfo{
+			Timestamp:     time.Unix(int64(i), int64(i)+1).UTC(),
+			CaptureLength: len(data),
+			Length:        len(data) + 10,
+		}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+	}
+	original := append([]byte(nil), pcap.Bytes()...)
+
+	ng := &bytes.Buffer{}
+	if err := Convert(ng, pcap, ConvertOptions{}); err != nil {
+		t.Fatal("Couldn't convert to pcapng:", err)
+	}
+	r, err := NewNgReader(bytes.NewReader(ng.Bytes()), DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal("Couldn't read pcapng:", err)
+	}
+	if _, _, err := r.ReadPacketData(); err != nil {
+		t.Fatal("Couldn't read packet:", err)
+	}
+	intf, _ := r.Interface(0)
+	if intf.LinkType != layers.LinkTypeEthernet || intf.SnapLength != 1000 || intf.FCSLength != 4 {
+		t.Errorf("Wrong interface %+v", intf)
+	}
+
+	converted, err := ioutil.ReadAll(NewConvertReader(ng, ConvertOptions{}))
+	if err != nil {
+		t.Fatal("Couldn't convert to pcap:", err)
+	}
+	if !bytes.Equal(converted, original) {
+		t.Errorf("Round trip changed the file:\nexpected: %x\nactual  : %x", original, converted)
+	}
+}
+
+func TestConvertFlatten(t *testing.T) {
+	ng := &bytes.Buffer{}
+	w, err := NewNgWriter(ng, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal("Couldn't create writer:", err)
+	}
+	ethernet2 := DefaultNgInterface
+	ethernet2.LinkType = layers.LinkTypeEthernet
+	ethernet2.Name = "eth1"
+	if _, err := w.AddInterface(ethernet2); err != nil {
+		t.Fatal("Couldn't add interface:", err)
+	}
+	raw := DefaultNgInterface
+	raw.LinkType = layers.LinkTypeRaw
+	if _, err := w.AddInterface(raw); err != nil {
+		t.Fatal("Couldn't add interface:", err)
+	}
+	for i := 0; i < 6; i++ {
+		data := ngPacketSource[0]
+		ci := gopacket.CaptureInfo{
+			Timestamp:      time.Unix(int64(i), 0).UTC(),
+			CaptureLength:  len(data),
+			Length:         len(data),
+			InterfaceIndex: i % 3,
+		}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("Couldn't flush:", err)
+	}
+
+	count := func(policy FlattenPolicy) (int, error) {
+		pcap := &bytes.Buffer{}
+		if err := Convert(pcap, bytes.NewReader(ng.Bytes()), ConvertOptions{Format: ConvertPcap, Flatten: policy}); err != nil {
+			return 0, err
+		}
+		r, err := NewReader(pcap)
+		if err != nil {
+			return 0, err
+		}
+		n := 0
+		for {
+			if _, _, err := r.ReadPacketData(); err != nil {
+				if err == io.EOF {
+					return n, nil
+				}
+				return n, err
+			}
+			n++
+		}
+	}
+
+	if _, err := count(FlattenErrorOnMixedLinkType); err == nil {
+		t.Error("Expected error for mixed link types")
+	}
+	if n, err := count(FlattenDropMixedLinkType); err != nil || n != 4 {
+		t.Errorf("Expected 4 packets, but got %d and %v", n, err)
+	}
+	if n, err := count(FlattenFirstInterface); err != nil || n != 2 {
+		t.Errorf("Expected 2 packets, but got %d and %v", n, err)
+	}
+}
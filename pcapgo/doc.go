@@ -13,15 +13,31 @@ This package contains implementations for native PCAP support. Currently support
 
  * pcap-files read/write: Reader, Writer
  * pcapng-files read/write: NgReader, NgWriter
+ * writing pcapng-files from concurrent goroutines: ConcurrentNgWriter
+ * random access to pcap- and pcapng-files: IndexedReader
+ * writing rotating pcap- and pcapng-files: RotatingWriter
+ * merging pcap- and pcapng-files in timestamp order: MergeReaders
+ * converting between pcap and pcapng: Convert, NewConvertReader
+ * filtering packets by time range and BPF program while reading: ReadFilter
+ * adding TLS key logs (SSLKEYLOGFILE) to pcapng-files: KeyLogWatcher
+ * writing pcap- and pcapng-files from parallel goroutines with WriteAt: ParallelWriter
+ * ERF-files (Endace DAG) read/write: ErfReader, ErfWriter
+ * Microsoft Network Monitor files (.cap) read: NetmonReader
  * raw socket capture (linux only): EthernetHandle
 
+Compressed files are transparently uncompressed by Reader and NgReader. gzip and bzip2 are supported
+out of the box, other formats can be added with RegisterDecompressor. For writing compressed files,
+Writer and NgWriter can write to a CompressedWriter, which finishes compressed frames only at block
+boundaries.
+
 Basic Usage pcapng
 
 Pcapng files can be read and written. Reading supports both big and little endian files, packet blocks,
 simple packet blocks, enhanced packets blocks, interface blocks, and interface statistics blocks. All
 the options also by Wireshark are supported. The default reader options match libpcap behaviour. Have
 a look at NgReaderOptions for more advanced usage. Both ReadPacketData and ZeroCopyReadPacketData is
-supported (which means PacketDataSource and ZeroCopyPacketDataSource is supported).
+supported (which means PacketDataSource and ZeroCopyPacketDataSource is supported). Packet block options,
+like packet comments, can be read with ReadPacketDataWithOptions.
 
 		f, err := os.Open("somefile.pcapng")
 		if err != nil {
@@ -42,7 +58,8 @@ blocks. The same options as with writing are supported. Interface timestamp reso
 10^-9s to match time.Time. Any other values are ignored. Upon creating a writer, a section, and an
 interface block is automatically written. Additional interfaces can be added at any time. Since
 the writer uses a bufio.Writer internally, Flush must be called before closing the file! Have a look
-at NewNgWriterInterface for more advanced usage.
+at NewNgWriterInterface for more advanced usage. Packet options can be written with
+WritePacketWithOptions.
 
 		f, err := os.Create("somefile.pcapng")
 		if err != nil {
@@ -0,0 +1,419 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// ErfType is the type of an ERF record.
+type ErfType uint8
+
+// ERF record types
+const (
+	ErfTypeLegacy          ErfType = 0
+	ErfTypeHDLCPOS         ErfType = 1
+	ErfTypeEthernet        ErfType = 2
+	ErfTypeATM             ErfType = 3
+	ErfTypeAAL5            ErfType = 4
+	ErfTypeMCHDLC          ErfType = 5
+	ErfTypeMCRaw           ErfType = 6
+	ErfTypeMCATM           ErfType = 7
+	ErfTypeMCRawChannel    ErfType = 8
+	ErfTypeMCAAL5          ErfType = 9
+	ErfTypeColorHDLCPOS    ErfType = 10
+	ErfTypeColorEthernet   ErfType = 11
+	ErfTypeMCAAL2          ErfType = 12
+	ErfTypeIPCounter       ErfType = 13
+	ErfTypeTCPFlowCounter  ErfType = 14
+	ErfTypeDSMColorHDLCPOS ErfType = 15
+	ErfTypeDSMColorEth     ErfType = 16
+	ErfTypeColorMCHDLCPOS  ErfType = 17
+	ErfTypeAAL2            ErfType = 18
+	ErfTypeColorHashPOS    ErfType = 19
+	ErfTypeColorHashEth    ErfType = 20
+	ErfTypeInfiniband      ErfType = 21
+	ErfTypeIPv4            ErfType = 22
+	ErfTypeIPv6            ErfType = 23
+	ErfTypeRawLink         ErfType = 24
+	ErfTypeInfinibandLink  ErfType = 25
+	ErfTypeMeta            ErfType = 27
+	ErfTypePad             ErfType = 48
+)
+
+const (
+	erfHeaderLength    = 16
+	erfExtensionLength = 8
+	erfEthernetPad     = 2
+
+	erfTypeExtension      = 0x80 // extension headers follow the header
+	erfExtensionMore      = 0x80 // another extension header follows
+	erfFlagsVarLen        = 0x04
+	erfFlagsInterfaceMask = 0x03
+)
+
+// ethernet returns true if records of this type carry ethernet frames preceded by two padding bytes
+func (t ErfType) ethernet() bool {
+	switch t {
+	case ErfTypeEthernet, ErfTypeColorEthernet, ErfTypeDSMColorEth, ErfTypeColorHashEth:
+		return true
+	}
+	return false
+}
+
+// LinkType returns the link type of the packets in records of this type. ok is false if the type carries no supported packet data.
+func (t ErfType) LinkType() (linkType layers.LinkType, ok bool) {
+	switch t {
+	case ErfTypeEthernet, ErfTypeColorEthernet, ErfTypeDSMColorEth, ErfTypeColorHashEth:
+		return layers.LinkTypeEthernet, true
+	case ErfTypeHDLCPOS, ErfTypeColorHDLCPOS, ErfTypeDSMColorHDLCPOS, ErfTypeColorHashPOS:
+		return layers.LinkTypeC_HDLC, true
+	case ErfTypeIPv4:
+		return layers.LinkTypeIPv4, true
+	case ErfTypeIPv6:
+		return layers.LinkTypeIPv6, true
+	}
+	return 0, false
+}
+
+// erfTypeForLinkType returns the ERF record type used for writing packets of the given link type
+func erfTypeForLinkType(linkType layers.LinkType) (ErfType, error) {
+	switch linkType {
+	case layers.LinkTypeEthernet:
+		return ErfTypeEthernet, nil
+	case layers.LinkTypeC_HDLC:
+		return ErfTypeHDLCPOS, nil
+	case layers.LinkTypeIPv4:
+		return ErfTypeIPv4, nil
+	case layers.LinkTypeIPv6:
+		return ErfTypeIPv6, nil
+	}
+	return 0, fmt.Errorf("Link type %s can't be written to ERF", linkType)
+}
+
+// ErfFlags holds the flags of an ERF record.
+type ErfFlags uint8
+
+// Interface returns the capture interface (port) of the record.
+func (f ErfFlags) Interface() int {
+	return int(f & erfFlagsInterfaceMask)
+}
+
+// VarLen returns true if the record has a variable length. Otherwise it is padded to the snap length.
+func (f ErfFlags) VarLen() bool {
+	return f&erfFlagsVarLen != 0
+}
+
+// Truncated returns true if the record was truncated due to insufficient buffer space.
+func (f ErfFlags) Truncated() bool {
+	return f&0x08 != 0
+}
+
+// RxError returns true if a link layer error (like a bad FCS) was detected.
+func (f ErfFlags) RxError() bool {
+	return f&0x10 != 0
+}
+
+// DSError returns true if an internal error was detected.
+func (f ErfFlags) DSError() bool {
+	return f&0x20 != 0
+}
+
+// ErfRecordInfo holds the ERF specific information of a record. ErfReader places it in ci.AncillaryData[0]; ErfWriter uses it
+// if it is found in ci.AncillaryData.
+type ErfRecordInfo struct {
+	// Type is the record type (without the extension header bit).
+	Type ErfType
+	// Flags holds the record flags. The interface is additionally reported as ci.InterfaceIndex.
+	Flags ErfFlags
+	// LossCounter is the number of records lost between this and the previous record (or the color field for colored types).
+	LossCounter uint16
+	// Extensions holds the raw extension headers. The most significant byte contains the extension type; the "more extensions" bit is handled automatically.
+	Extensions []uint64
+}
+
+// ErrErfLinkTypeMismatch is returned by ErfReader for a packet record whose type has a different link type than the first
+// packet record. The record is skipped, so reading can continue with the next one.
+var ErrErfLinkTypeMismatch = errors.New("Link type of ERF record is different from first one")
+
+// ErfReader reads packet records in the Extensible Record Format (ERF) used by Endace DAG cards.
+// Padding and meta data records are skipped.
+type ErfReader struct {
+	r         *bufio.Reader
+	linkType  layers.LinkType
+	buf       [erfHeaderLength]byte
+	packetBuf []byte
+	info      ErfRecordInfo
+	ancil     [1]interface{}
+}
+
+// NewErfReader returns a new ErfReader reading from r. Since ERF files don't have a file header, the first packet record
+// is inspected to determine the link type. An error is returned if there is no packet record within the first 4096 bytes.
+func NewErfReader(r io.Reader) (*ErfReader, error) {
+	ret := &ErfReader{r: bufio.NewReader(r)}
+	offset := 0
+	for {
+		header, err := ret.r.Peek(offset + erfHeaderLength)
+		if err != nil {
+			if offset > 0 && (err == io.EOF || err == bufio.ErrBufferFull) {
+				// only padding or meta data records within the buffer
+				return nil, errors.New("No ERF packet record found")
+			}
+			return nil, err
+		}
+		header = header[offset:]
+		typ := ErfType(header[8] &^ erfTypeExtension)
+		rlen := int(binary.BigEndian.Uint16(header[10:12]))
+		if rlen < erfHeaderLength {
+			return nil, fmt.Errorf("Invalid ERF record length %d", rlen)
+		}
+		if typ == ErfTypePad || typ == ErfTypeMeta {
+			offset += rlen
+			continue
+		}
+		linkType, ok := typ.LinkType()
+		if !ok {
+			return nil, fmt.Errorf("Unsupported ERF record type %d", typ)
+		}
+		ret.linkType = linkType
+		return ret, nil
+	}
+}
+
+// readRecordHeader reads the next packet record header including extension headers and returns the remaining length of the record.
+func (r *ErfReader) readRecordHeader() (ci gopacket.CaptureInfo, remaining int, err error) {
+	for {
+		if _, err = io.ReadFull(r.r, r.buf[:]); err != nil {
+			return
+		}
+		ts := binary.LittleEndian.Uint64(r.buf[0:8])
+		r.info.Type = ErfType(r.buf[8] &^ erfTypeExtension)
+		r.info.Flags = ErfFlags(r.buf[9])
+		rlen := int(binary.BigEndian.Uint16(r.buf[10:12]))
+		r.info.LossCounter = binary.BigEndian.Uint16(r.buf[12:14])
+		wlen := int(binary.BigEndian.Uint16(r.buf[14:16]))
+		if rlen < erfHeaderLength {
+			err = fmt.Errorf("Invalid ERF record length %d", rlen)
+			return
+		}
+		remaining = rlen - erfHeaderLength
+
+		r.info.Extensions = r.info.Extensions[:0]
+		more := r.buf[8]&erfTypeExtension != 0
+		for more {
+			if remaining < erfExtensionLength {
+				err = errors.New("ERF extension header exceeds record length")
+				return
+			}
+			if _, err = io.ReadFull(r.r, r.buf[:erfExtensionLength]); err != nil {
+				return
+			}
+			remaining -= erfExtensionLength
+			more = r.buf[0]&erfExtensionMore != 0
+			r.buf[0] &^= erfExtensionMore
+			r.info.Extensions = append(r.info.Extensions, binary.BigEndian.Uint64(r.buf[:erfExtensionLength]))
+		}
+
+		if r.info.Type == ErfTypePad || r.info.Type == ErfTypeMeta {
+			if _, err = r.r.Discard(remaining); err != nil {
+				return
+			}
+			continue
+		}
+		if linkType, ok := r.info.Type.LinkType(); !ok || linkType != r.linkType {
+			if _, err = r.r.Discard(remaining); err == nil {
+				err = ErrErfLinkTypeMismatch
+			}
+			return
+		}
+
+		if r.info.Type.ethernet() {
+			if remaining < erfEthernetPad {
+				err = errors.New("ERF ethernet record too short")
+				return
+			}
+			if _, err = r.r.Discard(erfEthernetPad); err != nil {
+				return
+			}
+			remaining -= erfEthernetPad
+		}
+
+		secs := ts >> 32
+		nanos := ((ts&0xffffffff)*1e9 + 1<<31) >> 32
+		if nanos >= 1e9 {
+			secs++
+			nanos -= 1e9
+		}
+		ci.Timestamp = time.Unix(int64(secs), int64(nanos)).UTC()
+		ci.Length = wlen
+		ci.CaptureLength = remaining
+		if ci.CaptureLength > ci.Length {
+			// records padded to the snap length (no varlen flag) or including the FCS
+			if !r.info.Flags.VarLen() {
+				ci.CaptureLength = ci.Length
+			} else {
+				ci.Length = ci.CaptureLength
+			}
+		}
+		ci.InterfaceIndex = r.info.Flags.Interface()
+		return
+	}
+}
+
+// recordInfo returns a copy of the current record info
+func (r *ErfReader) recordInfo() ErfRecordInfo {
+	info := r.info
+	if len(info.Extensions) > 0 {
+		info.Extensions = append([]uint64(nil), info.Extensions...)
+	} else {
+		info.Extensions = nil
+	}
+	return info
+}
+
+// ReadPacketData reads the next packet record. ci.AncillaryData[0] contains an ErfRecordInfo.
+func (r *ErfReader) ReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
+	var remaining int
+	if ci, remaining, err = r.readRecordHeader(); err != nil {
+		return
+	}
+	data = make([]byte, remaining)
+	if _, err = io.ReadFull(r.r, data); err != nil {
+		return
+	}
+	data = data[:ci.CaptureLength]
+	ci.AncillaryData = []interface{}{r.recordInfo()}
+	return
+}
+
+// ZeroCopyReadPacketData reads the next packet record. The data buffer and ci.AncillaryData are owned by the ErfReader,
+// and each call to ZeroCopyReadPacketData invalidates data returned by the previous one. ci.AncillaryData[0] contains an ErfRecordInfo.
+func (r *ErfReader) ZeroCopyReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
+	var remaining int
+	if ci, remaining, err = r.readRecordHeader(); err != nil {
+		return
+	}
+	if cap(r.packetBuf) < remaining {
+		r.packetBuf = make([]byte, remaining)
+	}
+	data = r.packetBuf[:remaining]
+	if _, err = io.ReadFull(r.r, data); err != nil {
+		return
+	}
+	data = data[:ci.CaptureLength]
+	r.ancil[0] = r.info
+	ci.AncillaryData = r.ancil[:]
+	return
+}
+
+// LinkType returns the link type of the first record.
+func (r *ErfReader) LinkType() layers.LinkType {
+	return r.linkType
+}
+
+// Resolution returns the timestamp resolution of acquired timestamps before scaling to NanosecondTimestampResolution.
+func (r *ErfReader) Resolution() gopacket.TimestampResolution {
+	return gopacket.TimestampResolution{Base: 2, Exponent: -32}
+}
+
+// ErfWriter writes packets as ERF records with variable length. Loss counters, flags, and extension headers are taken
+// from an ErfRecordInfo in ci.AncillaryData if present.
+type ErfWriter struct {
+	w   io.Writer
+	typ ErfType
+	buf [erfHeaderLength + erfEthernetPad]byte
+}
+
+// NewErfWriter returns a new ErfWriter writing records for packets of the given link type to w. Supported link types are
+// Ethernet, C_HDLC, IPv4, and IPv6.
+func NewErfWriter(w io.Writer, linkType layers.LinkType) (*ErfWriter, error) {
+	typ, err := erfTypeForLinkType(linkType)
+	if err != nil {
+		return nil, err
+	}
+	return &ErfWriter{w: w, typ: typ}, nil
+}
+
+// WritePacket writes the given packet as an ERF record. ci.InterfaceIndex must be in the range 0-3.
+func (w *ErfWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	if ci.CaptureLength != len(data) {
+		return fmt.Errorf("capture length %d does not match data length %d", ci.CaptureLength, len(data))
+	}
+	if ci.CaptureLength > ci.Length {
+		return fmt.Errorf("invalid capture info %+v:  capture length > length", ci)
+	}
+	if ci.InterfaceIndex < 0 || ci.InterfaceIndex > erfFlagsInterfaceMask {
+		return fmt.Errorf("ERF supports only interfaces 0-3, but got %d", ci.InterfaceIndex)
+	}
+	var info ErfRecordInfo
+	for _, ancil := range ci.AncillaryData {
+		if i, ok := ancil.(ErfRecordInfo); ok {
+			info = i
+			break
+		}
+	}
+
+	rlen := erfHeaderLength + erfExtensionLength*len(info.Extensions) + len(data)
+	if w.typ.ethernet() {
+		rlen += erfEthernetPad
+	}
+	if rlen > 0xffff {
+		return fmt.Errorf("ERF record length %d exceeds maximum", rlen)
+	}
+	wlen := ci.Length
+	if wlen > 0xffff {
+		wlen = 0xffff
+	}
+
+	t := ci.Timestamp
+	if t.IsZero() {
+		t = time.Now()
+	}
+	ts := uint64(t.Unix())<<32 | (uint64(t.Nanosecond())<<32)/1e9
+	typ := w.typ
+	if len(info.Extensions) > 0 {
+		typ |= erfTypeExtension
+	}
+	flags := info.Flags&^(erfFlagsInterfaceMask) | ErfFlags(ci.InterfaceIndex) | erfFlagsVarLen
+
+	binary.LittleEndian.PutUint64(w.buf[0:8], ts)
+	w.buf[8] = uint8(typ)
+	w.buf[9] = uint8(flags)
+	binary.BigEndian.PutUint16(w.buf[10:12], uint16(rlen))
+	binary.BigEndian.PutUint16(w.buf[12:14], info.LossCounter)
+	binary.BigEndian.PutUint16(w.buf[14:16], uint16(wlen))
+	if _, err := w.w.Write(w.buf[:erfHeaderLength]); err != nil {
+		return err
+	}
+	for i, ext := range info.Extensions {
+		binary.BigEndian.PutUint64(w.buf[0:8], ext)
+		if i < len(info.Extensions)-1 {
+			w.buf[0] |= erfExtensionMore
+		} else {
+			w.buf[0] &^= erfExtensionMore
+		}
+		if _, err := w.w.Write(w.buf[:erfExtensionLength]); err != nil {
+			return err
+		}
+	}
+	if w.typ.ethernet() {
+		w.buf[0], w.buf[1] = 0, 0
+		if _, err := w.w.Write(w.buf[:erfEthernetPad]); err != nil {
+			return err
+		}
+	}
+	_, err := w.w.Write(data)
+	return err
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// erfEthernetRecord is an ethernet record with one extension header on interface 1 with a loss counter of 3
+var erfEthernetRecord = []byte{
+	0x00, 0x00, 0x00, 0x80, 0x2a, 0x00, 0x00, 0x00, // timestamp 42.5
+	0x82,       // type: ethernet with extension header
+	0x05,       // flags: varlen, interface 1
+	0x00, 0x26, // rlen: 16 + 8 + 2 + 12
+	0x00, 0x03, // lctr
+	0x00, 0x3c, // wlen: 60
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x12, 0x34, // extension header
+	0x00, 0x00, // padding
+	0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb,
+}
+
+// erfPadRecord is a padding record, which must be skipped
+var erfPadRecord = []byte{
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x30, 0x04, 0x00, 0x18, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+}
+
+func TestErfRead(t *testing.T) {
+	contents := append(append([]byte(nil), erfPadRecord...), erfEthernetRecord...)
+	r, err := NewErfReader(bytes.NewReader(contents))
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+	if r.LinkType() != layers.LinkTypeEthernet {
+		t.Error("Expected link type ethernet, but got", r.LinkType())
+	}
+	data, ci, err := r.ReadPacketData()
+	if err != nil {
+		t.Fatal("Couldn't read packet:", err)
+	}
+	if !bytes.Equal(data, erfEthernetRecord[26:]) {
+		t.Errorf("Wrong packet data %x", data)
+	}
+	expected := gopacket.CaptureInfo{
+		Timestamp:      time.Unix(42, 5e8).UTC(),
+		CaptureLength:  12,
+		Length:         60,
+		InterfaceIndex: 1,
+		AncillaryData: []interface{}{ErfRecordInfo{
+			Type:        ErfTypeEthernet,
+			Flags:       0x05,
+			LossCounter: 3,
+			Extensions:  []uint64{0x0100000000001234},
+		}},
+	}
+	if !reflect.DeepEqual(ci, expected) {
+		t.Errorf("Wrong capture info:\nexpected: %+v\nactual  : %+v", expected, ci)
+	}
+	if _, _, err := r.ReadPacketData(); err != io.EOF {
+		t.Error("Expected EOF, but got", err)
+	}
+}
+
+func TestErfWriteRead(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	w, err := NewErfWriter(buffer, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal("Couldn't create writer:", err)
+	}
+	ci := gopacket.CaptureInfo{
+		Timestamp:      time.Unix(42, 5e8).UTC(),
+		CaptureLength:  12,
+		Length:         60,
+		InterfaceIndex: 1,
+		AncillaryData: []interface{}{ErfRecordInfo{
+			LossCounter: 3,
+			Extensions:  []uint64{0x0100000000001234},
+		}},
+	}
+	if err := w.WritePacket(ci, erfEthernetRecord[26:]); err != nil {
+		t.Fatal("Couldn't write packet:", err)
+	}
+	if !bytes.Equal(buffer.Bytes(), erfEthernetRecord) {
+		t.Errorf("Wrong record:\nexpected: %x\nactual  : %x", erfEthernetRecord, buffer.Bytes())
+	}
+
+	buffer.Reset()
+	for i := 0; i < 3; i++ {
+		data := ngPacketSource[i]
+		ci := gopacket.CaptureInfo{
+			Timestamp:      time.Unix(int64(1000+i), int64(i)*1000).UTC(),
+			CaptureLength:  len(data),
+			Length:         len(data),
+			InterfaceIndex: i,
+		}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+	}
+	r, err := NewErfReader(buffer)
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+	for i := 0; i < 3; i++ {
+		data, ci, err := r.ZeroCopyReadPacketData()
+		if err != nil {
+			t.Fatal("Couldn't read packet:", err)
+		}
+		if !bytes.Equal(data, ngPacketSource[i]) {
+			t.Errorf("Wrong packet data of packet %d", i)
+		}
+		if ci.InterfaceIndex != i {
+			t.Errorf("Expected interface %d, but got %d", i, ci.InterfaceIndex)
+		}
+		if ts := time.Unix(int64(1000+i), int64(i)*1000); !ci.Timestamp.Equal(ts) {
+			t.Errorf("Expected timestamp %s, but got %s", ts, ci.Timestamp)
+		}
+	}
+
+	if _, err := NewErfWriter(buffer, layers.LinkTypeTokenRing); err == nil {
+		t.Error("Expected error for unsupported link type")
+	}
+	if err := w.WritePacket(gopacket.CaptureInfo{InterfaceIndex: 4}, nil); err == nil {
+		t.Error("Expected error for interface out of range")
+	}
+}
+
+func TestErfMismatchingRecord(t *testing.T) {
+	ipv4Record := append([]byte(nil), erfEthernetRecord...)
+	ipv4Record[8] = 0x80 | byte(ErfTypeIPv4)
+	var contents []byte
+	for _, record := range [][]byte{erfEthernetRecord, ipv4Record, erfEthernetRecord} {
+		contents = append(contents, record...)
+	}
+	r, err := NewErfReader(bytes.NewReader(contents))
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+	for i, want := range []error{nil, ErrErfLinkTypeMismatch, nil, io.EOF} {
+		if _, _, err := r.ReadPacketData(); err != want {
+			t.Errorf("[record %d] Expected error %v, but got %v", i, want, err)
+		}
+	}
+}
+
+func TestErfNoPacketRecord(t *testing.T) {
+	if _, err := NewErfReader(bytes.NewReader(erfPadRecord)); err == nil {
+		t.Error("Expected error for a file without packet records")
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+
+	"github.com/google/gopacket"
+)
+
+// EthernetFCSLength is the length of the Ethernet frame check sequence in bytes.
+const EthernetFCSLength = 4
+
+// EthernetFCS returns the Ethernet frame check sequence (CRC-32) of the given frame without FCS.
+func EthernetFCS(frame []byte) uint32 {
+	return crc32.ChecksumIEEE(frame)
+}
+
+// ValidEthernetFCS returns true if data ends with the valid Ethernet frame check sequence of the preceding bytes.
+func ValidEthernetFCS(data []byte) bool {
+	if len(data) < EthernetFCSLength {
+		return false
+	}
+	frame := data[:len(data)-EthernetFCSLength]
+	return binary.LittleEndian.Uint32(data[len(frame):]) == EthernetFCS(frame)
+}
+
+// StripFCS removes a frame check sequence of fcsLength bytes from the end of the packet and adjusts ci accordingly.
+// If the packet was truncated, only the captured part of the FCS is removed from data.
+func StripFCS(ci *gopacket.CaptureInfo, data []byte, fcsLength int) []byte {
+	captured := fcsLength - (ci.Length - ci.CaptureLength)
+	if captured > len(data) {
+		captured = len(data)
+	}
+	if captured > 0 {
+		data = data[:len(data)-captured]
+		ci.CaptureLength -= captured
+	}
+	ci.Length -= fcsLength
+	if ci.Length < ci.CaptureLength {
+		ci.Length = ci.CaptureLength
+	}
+	return data
+}
+
+// AppendEthernetFCS appends the Ethernet frame check sequence to a packet without FCS and adjusts ci accordingly.
+// The FCS can only be computed for completely captured packets; for truncated packets only ci.Length is adjusted.
+// A new slice is returned if data has no spare capacity.
+func AppendEthernetFCS(ci *gopacket.CaptureInfo, data []byte) []byte {
+	ci.Length += EthernetFCSLength
+	if ci.CaptureLength != ci.Length-EthernetFCSLength || len(data) != ci.CaptureLength {
+		return data
+	}
+	var fcs [EthernetFCSLength]byte
+	binary.LittleEndian.PutUint32(fcs[:], EthernetFCS(data))
+	ci.CaptureLength += EthernetFCSLength
+	return append(data, fcs[:]...)
+}
+
+// FCSAction selects how FCSDataSource changes the frame check sequence of packets.
+type FCSAction int
+
+// FCS actions
+const (
+	// FCSKeep passes packets through unchanged, which is useful together with FCSDataSource.DropInvalid.
+	FCSKeep FCSAction = iota
+	// FCSStrip removes the FCS from packets including one.
+	FCSStrip
+	// FCSAppend adds an Ethernet FCS to packets without one.
+	FCSAppend
+)
+
+// FCSDataSource wraps a packet data source and strips, appends, or validates Ethernet frame check sequences on the fly.
+// This is useful to normalize captures (e.g., from span ports), where some or all frames include an FCS, before decoding them.
+type FCSDataSource struct {
+	source gopacket.PacketDataSource
+	// FCSLength is the length in bytes of the FCS included in the packets of the source, e.g. from Reader.FCSLength or
+	// NgInterface.FCSLength. If negative, every completely captured packet is checked for a valid Ethernet FCS, which
+	// handles captures with mixed packets.
+	FCSLength int
+	// Action selects what to do with the FCS.
+	Action FCSAction
+	// DropInvalid drops packets with an invalid FCS instead of returning them. This needs an FCSLength of 4.
+	DropInvalid bool
+	// Invalid is the number of dropped packets.
+	Invalid int
+}
+
+// NewFCSDataSource returns a new FCSDataSource reading from source, where every packet includes an FCS of fcsLength bytes.
+func NewFCSDataSource(source gopacket.PacketDataSource, fcsLength int, action FCSAction) *FCSDataSource {
+	return &FCSDataSource{
+		source:    source,
+		FCSLength: fcsLength,
+		Action:    action,
+	}
+}
+
+// ReadPacketData returns the next packet of the source with the FCS handled according to Action.
+func (s *FCSDataSource) ReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
+	for {
+		if data, ci, err = s.source.ReadPacketData(); err != nil {
+			return
+		}
+		fcsLength := s.FCSLength
+		if fcsLength < 0 {
+			fcsLength = 0
+			if ci.CaptureLength == ci.Length && ValidEthernetFCS(data) {
+				fcsLength = EthernetFCSLength
+			}
+		}
+		if s.DropInvalid && fcsLength == EthernetFCSLength && ci.CaptureLength == ci.Length && !ValidEthernetFCS(data) {
+			s.Invalid++
+			continue
+		}
+		switch s.Action {
+		case FCSStrip:
+			if fcsLength > 0 {
+				data = StripFCS(&ci, data, fcsLength)
+			}
+		case FCSAppend:
+			if fcsLength == 0 {
+				data = AppendEthernetFCS(&ci, data)
+			}
+		}
+		return
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// fcsTestSource returns the given packets
+type fcsTestSource [][]byte
+
+func (s *fcsTestSource) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	if len(*s) == 0 {
+		return nil, gopacket.CaptureInfo{}, io.EOF
+	}
+	data := (*s)[0]
+	*s = (*s)[1:]
+	return data, gopacket.CaptureInfo{CaptureLength: len(data), Length: len(data)}, nil
+}
+
+func TestEthernetFCS(t *testing.T) {
+	frame := ngPacketSource[0]
+	ci := gopacket.CaptureInfo{CaptureLength: len(frame), Length: len(frame)}
+	withFCS := AppendEthernetFCS(&ci, append([]byte(nil), frame...))
+	if ci.CaptureLength != len(frame)+4 || ci.Length != len(frame)+4 || len(withFCS) != len(frame)+4 {
+		t.Fatalf("Wrong lengths after appending FCS: %+v", ci)
+	}
+	if !ValidEthernetFCS(withFCS) {
+		t.Error("Appended FCS is invalid")
+	}
+	// the CRC-32 check value
+	if fcs := EthernetFCS([]byte("123456789")); fcs != 0xcbf43926 {
+		t.Errorf("Wrong FCS %x", fcs)
+	}
+
+	stripped := StripFCS(&ci, withFCS, EthernetFCSLength)
+	if !bytes.Equal(stripped, frame) || ci.CaptureLength != len(frame) || ci.Length != len(frame) {
+		t.Errorf("Wrong packet after stripping FCS: %+v", ci)
+	}
+
+	// truncated packet, where the FCS was only partially captured
+	ci = gopacket.CaptureInfo{CaptureLength: 62, Length: 64}
+	stripped = StripFCS(&ci, make([]byte, 62), EthernetFCSLength)
+	if len(stripped) != 60 || ci.CaptureLength != 60 || ci.Length != 60 {
+		t.Errorf("Wrong packet after stripping partial FCS: %d %+v", len(stripped), ci)
+	}
+}
+
+func TestFCSDataSource(t *testing.T) {
+	bad := fcsPacketForTest(ngPacketSource[2])
+	bad[len(bad)-1] ^= 0xff
+
+	// mixed packets with and without FCS
+	source := fcsTestSource{fcsPacketForTest(ngPacketSource[0]), ngPacketSource[1], fcsPacketForTest(ngPacketSource[2])}
+	s := NewFCSDataSource(&source, -1, FCSStrip)
+	for i := 0; i < 3; i++ {
+		data, _, err := s.ReadPacketData()
+		if err != nil {
+			t.Fatal("Couldn't read packet:", err)
+		}
+		if !bytes.Equal(data, ngPacketSource[i]) {
+			t.Errorf("Packet %d still has an FCS", i)
+		}
+	}
+
+	source = fcsTestSource{fcsPacketForTest(ngPacketSource[0]), bad, fcsPacketForTest(ngPacketSource[1])}
+	s = NewFCSDataSource(&source, EthernetFCSLength, FCSKeep)
+	s.DropInvalid = true
+	n := 0
+	for {
+		_, _, err := s.ReadPacketData()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("Couldn't read packet:", err)
+		}
+		n++
+	}
+	if n != 2 || s.Invalid != 1 {
+		t.Errorf("Expected 2 valid and 1 invalid packets, but got %d and %d", n, s.Invalid)
+	}
+
+	source = fcsTestSource{ngPacketSource[0]}
+	s = NewFCSDataSource(&source, 0, FCSAppend)
+	data, _, err := s.ReadPacketData()
+	if err != nil {
+		t.Fatal("Couldn't read packet:", err)
+	}
+	if !ValidEthernetFCS(data) || len(data) != len(ngPacketSource[0])+4 {
+		t.Error("Expected packet with valid FCS")
+	}
+}
+
+func TestFileFCSLength(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	w := NewWriter(buffer)
+	if err := w.WriteFileHeaderFCS(65536, layers.LinkTypeEthernet, 4); err != nil {
+		t.Fatal("Couldn't write header:", err)
+	}
+	r, err := NewReader(buffer)
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+	if r.FCSLength() != 4 || r.LinkType() != layers.LinkTypeEthernet {
+		t.Errorf("Expected FCS length 4 and link type ethernet, but got %d and %s", r.FCSLength(), r.LinkType())
+	}
+	if err := NewWriter(buffer).WriteFileHeaderFCS(65536, layers.LinkTypeEthernet, 3); err == nil {
+		t.Error("Expected error for odd FCS length")
+	}
+
+	buffer.Reset()
+	intf := DefaultNgInterface
+	intf.LinkType = layers.LinkTypeEthernet
+	intf.FCSLength = 4
+	ngw, err := NewNgWriterInterface(buffer, intf, DefaultNgWriterOptions)
+	if err != nil {
+		t.Fatal("Couldn't create writer:", err)
+	}
+	data := fcsPacketForTest(ngPacketSource[0])
+	if err := ngw.WritePacket(gopacket.CaptureInfo{Timestamp: time.Unix(0, 0), CaptureLength: len(data), Length: len(data)}, data); err != nil {
+		t.Fatal("Couldn't write packet:", err)
+	}
+	if err := ngw.Flush(); err != nil {
+		t.Fatal("Couldn't flush:", err)
+	}
+	ngr, err := NewNgReader(buffer, DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+	if _, _, err := ngr.ReadPacketData(); err != nil {
+		t.Fatal("Couldn't read packet:", err)
+	}
+	if read, _ := ngr.Interface(0); read.FCSLength != 4 {
+		t.Errorf("Expected FCS length 4, but got %d", read.FCSLength)
+	}
+}
+
+func fcsPacketForTest(frame []byte) []byte {
+	ci := gopacket.CaptureInfo{CaptureLength: len(frame), Length: len(frame)}
+	return AppendEthernetFCS(&ci, append([]byte(nil), frame...))
+}
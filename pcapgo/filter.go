@@ -0,0 +1,83 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/net/bpf"
+)
+
+// ReadFilter selects the packets returned by Reader and NgReader. Packets not matching the filter are skipped while
+// reading and never returned. Packets outside the time window are skipped without reading their data, which makes
+// skimming large files for a short time range fast.
+type ReadFilter struct {
+	// Start skips packets with a timestamp before Start. No lower bound if zero.
+	Start time.Time
+	// End skips packets with a timestamp after End. No upper bound if zero.
+	End time.Time
+	// Sorted declares the input to be in timestamp order. Reading stops with io.EOF at the first packet after End.
+	Sorted bool
+	// BPF is a compiled BPF program, e.g., the output of tcpdump -dd. Packets for which the
+	// program returns 0 are skipped. The program is run on the captured data by a pure Go virtual machine and must
+	// match the link type of the packets.
+	BPF []bpf.RawInstruction
+}
+
+var errInvalidBPF = errors.New("Invalid BPF program")
+
+// readFilter is a ReadFilter prepared for matching
+type readFilter struct {
+	start, end time.Time
+	sorted     bool
+	vm         *bpf.VM
+}
+
+// newReadFilter prepares filter for matching. Returns nil for an empty filter.
+func newReadFilter(filter ReadFilter) (*readFilter, error) {
+	if filter.Start.IsZero() && filter.End.IsZero() && len(filter.BPF) == 0 {
+		return nil, nil
+	}
+	ret := &readFilter{
+		start:  filter.Start,
+		end:    filter.End,
+		sorted: filter.Sorted,
+	}
+	if len(filter.BPF) > 0 {
+		program, ok := bpf.Disassemble(filter.BPF)
+		if !ok {
+			return nil, errInvalidBPF
+		}
+		vm, err := bpf.NewVM(program)
+		if err != nil {
+			return nil, err
+		}
+		ret.vm = vm
+	}
+	return ret, nil
+}
+
+// matchTime returns true if t is inside the time window, and whether reading can stop since all following packets are after the window
+func (f *readFilter) matchTime(t time.Time) (match, done bool) {
+	if !f.start.IsZero() && t.Before(f.start) {
+		return false, false
+	}
+	if !f.end.IsZero() && t.After(f.end) {
+		return false, f.sorted
+	}
+	return true, false
+}
+
+// matchData returns true if the BPF program accepts data
+func (f *readFilter) matchData(data []byte) (bool, error) {
+	if f.vm == nil {
+		return true, nil
+	}
+	n, err := f.vm.Run(data)
+	return n > 0, err
+}
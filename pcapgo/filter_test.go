@@ -0,0 +1,132 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/bpf"
+)
+
+// filterTestPackets is the number of packets in the test files; packet i has timestamp i and the data of ngPacketSource[i%3]
+const filterTestPackets = 12
+
+func filterTestFiles(t *testing.T) (pcap, ng []byte) {
+	pcapBuf := &bytes.Buffer{}
+	w := NewWriter(pcapBuf)
+	if err := w.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+		t.Fatal("Couldn't write header:", err)
+	}
+	ngBuf := &bytes.Buffer{}
+	ngw, err := NewNgWriter(ngBuf, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal("Couldn't create writer:", err)
+	}
+	for i := 0; i < filterTestPackets; i++ {
+		data := ngPacketSource[i%3]
+		ci := gopacket.CaptureInfo{
+			Timestamp:     time.Unix(int64(i), 0).UTC(),
+			CaptureLength: len(data),
+			Length:        len(data),
+		}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+		if err := ngw.WritePacket(ci, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+	}
+	if err := ngw.Flush(); err != nil {
+		t.Fatal("Couldn't flush:", err)
+	}
+	return pcapBuf.Bytes(), ngBuf.Bytes()
+}
+
+// filterTestRead returns the seconds of the timestamps of all packets read from r
+func filterTestRead(t *testing.T, read func() ([]byte, gopacket.CaptureInfo, error)) []int {
+	var ret []int
+	for {
+		_, ci, err := read()
+		if err == io.EOF {
+			return ret
+		}
+		if err != nil {
+			t.Fatal("Couldn't read packet:", err)
+		}
+		ret = append(ret, int(ci.Timestamp.Unix()))
+	}
+}
+
+func TestReadFilter(t *testing.T) {
+	// accept only broadcast packets
+	broadcast, err := bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: 0, Size: 4},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0xffffffff, SkipFalse: 1},
+		bpf.RetConstant{Val: 65535},
+		bpf.RetConstant{Val: 0},
+	})
+	if err != nil {
+		t.Fatal("Couldn't assemble BPF program:", err)
+	}
+	pcap, ng := filterTestFiles(t)
+
+	tests := []struct {
+		name     string
+		filter   ReadFilter
+		expected []int
+	}{
+		{"none", ReadFilter{}, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}},
+		{"time", ReadFilter{Start: time.Unix(3, 0), End: time.Unix(6, 0)}, []int{3, 4, 5, 6}},
+		{"sorted", ReadFilter{End: time.Unix(2, 0), Sorted: true}, []int{0, 1, 2}},
+		{"bpf", ReadFilter{BPF: broadcast}, []int{0, 2, 3, 5, 6, 8, 9, 11}},
+		{"both", ReadFilter{Start: time.Unix(4, 0), End: time.Unix(8, 0), BPF: broadcast}, []int{5, 6, 8}},
+	}
+	for _, test := range tests {
+		r, err := NewReader(bytes.NewReader(pcap))
+		if err != nil {
+			t.Fatal("Couldn't create reader:", err)
+		}
+		if err := r.SetFilter(test.filter); err != nil {
+			t.Fatal("Couldn't set filter:", err)
+		}
+		if got := filterTestRead(t, r.ReadPacketData); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("[%s] pcap: expected packets %v, but got %v", test.name, test.expected, got)
+		}
+
+		options := DefaultNgReaderOptions
+		options.Filter = test.filter
+		ngr, err := NewNgReader(bytes.NewReader(ng), options)
+		if err != nil {
+			t.Fatal("Couldn't create reader:", err)
+		}
+		if got := filterTestRead(t, ngr.ZeroCopyReadPacketData); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("[%s] pcapng: expected packets %v, but got %v", test.name, test.expected, got)
+		}
+
+		ngr, err = NewNgReader(bytes.NewReader(ng), options)
+		if err != nil {
+			t.Fatal("Couldn't create reader:", err)
+		}
+		got := filterTestRead(t, func() ([]byte, gopacket.CaptureInfo, error) {
+			data, ci, _, err := ngr.ReadPacketDataWithOptions()
+			return data, ci, err
+		})
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("[%s] pcapng with options: expected packets %v, but got %v", test.name, test.expected, got)
+		}
+	}
+
+	if _, err := NewNgReader(bytes.NewReader(ng), NgReaderOptions{Filter: ReadFilter{BPF: []bpf.RawInstruction{{Op: 0xffff}}}}); err == nil {
+		t.Error("Expected error for invalid BPF program")
+	}
+}
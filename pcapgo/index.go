@@ -0,0 +1,528 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// ErrIndexOutOfRange is returned by IndexedReader.SeekPacket for packet numbers not contained in the index.
+var ErrIndexOutOfRange = errors.New("Packet number out of index range")
+
+const (
+	indexMagic   = 0x47504958 // "GPIX"
+	indexVersion = 1
+
+	indexFormatPcap   = 1
+	indexFormatPcapng = 2
+
+	indexNoTimestamp = math.MinInt64
+)
+
+// indexEntry is the position of a single packet
+type indexEntry struct {
+	offset    int64 // offset of the packet record/block
+	timestamp int64 // nanoseconds since epoch
+	section   uint32
+}
+
+// indexSection is the position of a pcapng section and its interfaces
+type indexSection struct {
+	offset     int64
+	interfaces []int64
+}
+
+// Index holds the file offset and timestamp of every packet in a pcap or pcapng file, which allows
+// IndexedReader to seek to a packet number or timestamp without reading the file from the start.
+// An index can be built with BuildIndex, stored with WriteTo, and loaded again with ReadIndex.
+type Index struct {
+	format   uint8
+	sorted   bool
+	entries  []indexEntry
+	sections []indexSection
+}
+
+// Len returns the number of packets in the index.
+func (idx *Index) Len() int {
+	return len(idx.entries)
+}
+
+// Timestamp returns the timestamp of the packet with the given number.
+// Packets without a timestamp (simple packet blocks) return the zero time.
+func (idx *Index) Timestamp(n int) time.Time {
+	if idx.entries[n].timestamp == indexNoTimestamp {
+		return time.Time{}
+	}
+	return time.Unix(0, idx.entries[n].timestamp).UTC()
+}
+
+// search returns the number of the first packet with a timestamp not before t.
+func (idx *Index) search(t time.Time) int {
+	ts := t.UnixNano()
+	if idx.sorted {
+		return sort.Search(len(idx.entries), func(i int) bool {
+			return idx.entries[i].timestamp >= ts
+		})
+	}
+	for i := range idx.entries {
+		if idx.entries[i].timestamp >= ts {
+			return i
+		}
+	}
+	return len(idx.entries)
+}
+
+func (idx *Index) add(e indexEntry) {
+	if n := len(idx.entries); n > 0 && idx.entries[n-1].timestamp > e.timestamp {
+		idx.sorted = false
+	}
+	idx.entries = append(idx.entries, e)
+}
+
+// countingReader counts the bytes read from the underlying reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// BuildIndex reads the whole pcap or pcapng file from rs and returns an index of all packets contained in it.
+// Compressed files can't be indexed.
+func BuildIndex(rs io.ReadSeeker) (*Index, error) {
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	cr := &countingReader{r: rs}
+	br := bufio.NewReader(cr)
+	offset := func() int64 {
+		return cr.n - int64(br.Buffered())
+	}
+
+	format, err := detectCompression(br)
+	if err != nil {
+		return nil, err
+	}
+	if format != nil {
+		return nil, fmt.Errorf("%s compressed files can't be indexed", format.name)
+	}
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{sorted: true}
+	if ngBlockType(binary.LittleEndian.Uint32(magic)) == ngBlockTypeSectionHeader {
+		idx.format = indexFormatPcapng
+		err = idx.buildNg(br, offset)
+	} else {
+		idx.format = indexFormatPcap
+		err = idx.buildPcap(br, offset)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+func (idx *Index) buildPcap(br *bufio.Reader, offset func() int64) error {
+	r, err := NewReader(br)
+	if err != nil {
+		return err
+	}
+	for {
+		pos := offset()
+		ci, err := r.readPacketHeader()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		idx.add(indexEntry{
+			offset:    pos,
+			timestamp: ci.Timestamp.UnixNano(),
+		})
+		if _, err := br.Discard(ci.CaptureLength); err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+}
+
+func (idx *Index) buildNg(br *bufio.Reader, offset func() int64) error {
+	r := &NgReader{
+		r: br,
+		currentOption: ngOption{
+			value: make([]byte, 1024),
+		},
+		options: NgReaderOptions{WantMixedLinkType: true},
+	}
+	for {
+		pos := offset()
+		if err := r.readBlock(); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch r.currentBlock.typ {
+		case ngBlockTypeSectionHeader:
+			if err := r.readSectionHeader(); err != nil {
+				return err
+			}
+			idx.sections = append(idx.sections, indexSection{offset: pos})
+			continue
+		case ngBlockTypeInterfaceDescriptor:
+			if len(idx.sections) == 0 {
+				return errors.New("Interface block outside of a section")
+			}
+			if err := r.readInterfaceDescriptor(); err != nil {
+				return err
+			}
+			section := &idx.sections[len(idx.sections)-1]
+			section.interfaces = append(section.interfaces, pos)
+			continue
+		case ngBlockTypeEnhancedPacket, ngBlockTypePacket:
+			if err := r.readBytes(r.buf[:12]); err != nil {
+				return err
+			}
+			r.currentBlock.length -= 12
+			var iface int
+			if r.currentBlock.typ == ngBlockTypePacket {
+				iface = int(r.getUint16(r.buf[0:2]))
+			} else {
+				iface = int(r.getUint32(r.buf[0:4]))
+			}
+			if iface >= len(r.ifaces) {
+				return fmt.Errorf("Interface id %d not present in section (have only %d interfaces)", iface, len(r.ifaces))
+			}
+			idx.add(indexEntry{
+				offset:    pos,
+				timestamp: time.Unix(r.convertTime(iface, uint64(r.getUint32(r.buf[4:8]))<<32|uint64(r.getUint32(r.buf[8:12])))).UnixNano(),
+				section:   uint32(len(idx.sections) - 1),
+			})
+		case ngBlockTypeSimplePacket:
+			idx.add(indexEntry{
+				offset:    pos,
+				timestamp: indexNoTimestamp,
+				section:   uint32(len(idx.sections) - 1),
+			})
+		}
+		if _, err := br.Discard(int(r.currentBlock.length)); err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+}
+
+// WriteTo writes the index in a binary format to w, which can be loaded again with ReadIndex.
+func (idx *Index) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+	var buf [20]byte
+	write := func(b []byte) error {
+		nn, err := bw.Write(b)
+		n += int64(nn)
+		return err
+	}
+
+	binary.LittleEndian.PutUint32(buf[0:4], indexMagic)
+	buf[4] = indexVersion
+	buf[5] = idx.format
+	if idx.sorted {
+		buf[6] = 1
+	} else {
+		buf[6] = 0
+	}
+	buf[7] = 0
+	binary.LittleEndian.PutUint32(buf[8:12], uint32(len(idx.sections)))
+	binary.LittleEndian.PutUint64(buf[12:20], uint64(len(idx.entries)))
+	if err := write(buf[:20]); err != nil {
+		return n, err
+	}
+	for _, section := range idx.sections {
+		binary.LittleEndian.PutUint64(buf[0:8], uint64(section.offset))
+		binary.LittleEndian.PutUint32(buf[8:12], uint32(len(section.interfaces)))
+		if err := write(buf[:12]); err != nil {
+			return n, err
+		}
+		for _, intf := range section.interfaces {
+			binary.LittleEndian.PutUint64(buf[0:8], uint64(intf))
+			if err := write(buf[:8]); err != nil {
+				return n, err
+			}
+		}
+	}
+	for _, e := range idx.entries {
+		binary.LittleEndian.PutUint64(buf[0:8], uint64(e.offset))
+		binary.LittleEndian.PutUint64(buf[8:16], uint64(e.timestamp))
+		binary.LittleEndian.PutUint32(buf[16:20], e.section)
+		if err := write(buf[:20]); err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
+
+// ReadIndex loads an index previously stored with Index.WriteTo.
+func ReadIndex(r io.Reader) (*Index, error) {
+	br := bufio.NewReader(r)
+	var buf [20]byte
+	if _, err := io.ReadFull(br, buf[:20]); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(buf[0:4]) != indexMagic {
+		return nil, errors.New("Not a packet index")
+	}
+	if buf[4] != indexVersion {
+		return nil, fmt.Errorf("Unknown index version %d", buf[4])
+	}
+	if buf[5] != indexFormatPcap && buf[5] != indexFormatPcapng {
+		return nil, fmt.Errorf("Unknown index format %d", buf[5])
+	}
+	idx := &Index{
+		format: buf[5],
+		sorted: buf[6] == 1,
+	}
+	nSections := binary.LittleEndian.Uint32(buf[8:12])
+	nEntries := binary.LittleEndian.Uint64(buf[12:20])
+	for i := uint32(0); i < nSections; i++ {
+		if _, err := io.ReadFull(br, buf[:12]); err != nil {
+			return nil, err
+		}
+		section := indexSection{offset: int64(binary.LittleEndian.Uint64(buf[0:8]))}
+		nInterfaces := binary.LittleEndian.Uint32(buf[8:12])
+		for j := uint32(0); j < nInterfaces; j++ {
+			if _, err := io.ReadFull(br, buf[:8]); err != nil {
+				return nil, err
+			}
+			section.interfaces = append(section.interfaces, int64(binary.LittleEndian.Uint64(buf[0:8])))
+		}
+		idx.sections = append(idx.sections, section)
+	}
+	for i := uint64(0); i < nEntries; i++ {
+		if _, err := io.ReadFull(br, buf[:20]); err != nil {
+			return nil, err
+		}
+		e := indexEntry{
+			offset:    int64(binary.LittleEndian.Uint64(buf[0:8])),
+			timestamp: int64(binary.LittleEndian.Uint64(buf[8:16])),
+			section:   binary.LittleEndian.Uint32(buf[16:20]),
+		}
+		if idx.format == indexFormatPcapng && int(e.section) >= len(idx.sections) {
+			return nil, fmt.Errorf("Packet %d references non existent section %d", i, e.section)
+		}
+		idx.entries = append(idx.entries, e)
+	}
+	return idx, nil
+}
+
+// IndexedReader reads packets from a pcap or pcapng file and additionally allows seeking to a packet number or timestamp with the help of an Index.
+type IndexedReader struct {
+	rs    io.ReadSeeker
+	index *Index
+	br    *bufio.Reader
+	pcap  *Reader
+	ng    *NgReader
+	next  int
+}
+
+// NewIndexedReader returns a new IndexedReader reading from rs. If index is nil, the index is built with BuildIndex first.
+// The given options are only used for pcapng files.
+func NewIndexedReader(rs io.ReadSeeker, index *Index, options NgReaderOptions) (*IndexedReader, error) {
+	if index == nil {
+		var err error
+		if index, err = BuildIndex(rs); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	ret := &IndexedReader{
+		rs:    rs,
+		index: index,
+		br:    bufio.NewReader(rs),
+	}
+	var err error
+	switch index.format {
+	case indexFormatPcap:
+		// NewReader and NewNgReader reuse br, since it is already a bufio.Reader
+		ret.pcap, err = NewReader(ret.br)
+	case indexFormatPcapng:
+		ret.ng, err = NewNgReader(ret.br, options)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Index returns the index used by this reader.
+func (r *IndexedReader) Index() *Index {
+	return r.index
+}
+
+// Len returns the number of packets in the file.
+func (r *IndexedReader) Len() int {
+	return len(r.index.entries)
+}
+
+// Position returns the number of the packet returned by the next call to ReadPacketData.
+func (r *IndexedReader) Position() int {
+	return r.next
+}
+
+// seek positions the underlying reader at the given offset
+func (r *IndexedReader) seek(offset int64) error {
+	if _, err := r.rs.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	r.reset(offset)
+	return nil
+}
+
+// reset discards the data buffered before the underlying reader was positioned at offset
+func (r *IndexedReader) reset(offset int64) {
+	r.br.Reset(r.rs)
+	if r.ng != nil && r.ng.counter != nil {
+		// in Recover mode, the NgReader buffers and counts the input itself
+		r.ng.counter.n = offset
+		r.ng.r.Reset(r.ng.counter)
+	}
+}
+
+// SeekPacket positions the reader so that the next call to ReadPacketData returns the packet with the given number (starting at 0).
+// Seeking to Len() positions the reader at the end of the file.
+func (r *IndexedReader) SeekPacket(n int) error {
+	if n < 0 || n > len(r.index.entries) {
+		return ErrIndexOutOfRange
+	}
+	if n == len(r.index.entries) {
+		end, err := r.rs.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		r.reset(end)
+		r.next = n
+		return nil
+	}
+	e := r.index.entries[n]
+	if r.ng != nil {
+		if err := r.restoreSection(e); err != nil {
+			return err
+		}
+	}
+	if err := r.seek(e.offset); err != nil {
+		return err
+	}
+	r.next = n
+	return nil
+}
+
+// SeekTime positions the reader at the first packet with a timestamp not before t.
+// If the packets in the file are sorted by time, a binary search is used.
+func (r *IndexedReader) SeekTime(t time.Time) error {
+	return r.SeekPacket(r.index.search(t))
+}
+
+// restoreSection restores section and interface state of the pcapng reader as it would be while reading the given packet sequentially
+func (r *IndexedReader) restoreSection(e indexEntry) error {
+	section := r.index.sections[e.section]
+	if err := r.seek(section.offset); err != nil {
+		return err
+	}
+	if err := r.ng.readBlock(); err != nil {
+		return err
+	}
+	// Jumping into a section doesn't end the current one, and the interfaces are restored below.
+	r.ng.activeSection = false
+	mixed := r.ng.options.WantMixedLinkType
+	r.ng.options.WantMixedLinkType = true
+	err := r.ng.readSectionHeader()
+	r.ng.options.WantMixedLinkType = mixed
+	if err != nil {
+		return err
+	}
+	for _, offset := range section.interfaces {
+		if offset > e.offset {
+			break
+		}
+		if err := r.seek(offset); err != nil {
+			return err
+		}
+		if err := r.ng.readBlock(); err != nil {
+			return err
+		}
+		if err := r.ng.readInterfaceDescriptor(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadPacketData returns the next packet available from this data source.
+func (r *IndexedReader) ReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
+	if r.ng != nil {
+		data, ci, err = r.ng.ReadPacketData()
+	} else {
+		data, ci, err = r.pcap.ReadPacketData()
+	}
+	if err == nil {
+		r.next++
+	}
+	return
+}
+
+// ZeroCopyReadPacketData returns the next packet available from this data source.
+// The data buffer is owned by the reader, and each call invalidates data returned by the previous one.
+func (r *IndexedReader) ZeroCopyReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
+	if r.ng != nil {
+		data, ci, err = r.ng.ZeroCopyReadPacketData()
+	} else {
+		data, ci, err = r.pcap.ZeroCopyReadPacketData()
+	}
+	if err == nil {
+		r.next++
+	}
+	return
+}
+
+// LinkType returns the link type of the file. For pcapng files see NgReader.LinkType.
+func (r *IndexedReader) LinkType() layers.LinkType {
+	if r.ng != nil {
+		return r.ng.LinkType()
+	}
+	return r.pcap.LinkType()
+}
+
+// Resolution returns the timestamp resolution of acquired timestamps before scaling to NanosecondTimestampResolution.
+func (r *IndexedReader) Resolution() gopacket.TimestampResolution {
+	if r.ng != nil {
+		return r.ng.Resolution()
+	}
+	return r.pcap.Resolution()
+}
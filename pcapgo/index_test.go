@@ -0,0 +1,192 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+type indexTestPacket struct {
+	data []byte
+	ci   gopacket.CaptureInfo
+}
+
+// readAllIndexTest reads all packets sequentially
+func readAllIndexTest(t *testing.T, r *IndexedReader) []indexTestPacket {
+	var ret []indexTestPacket
+	for {
+		data, ci, err := r.ReadPacketData()
+		if err == io.EOF {
+			return ret
+		}
+		if err != nil {
+			t.Fatal("Couldn't read packet:", err)
+		}
+		ret = append(ret, indexTestPacket{data, ci})
+	}
+}
+
+// checkIndexedReader compares random access reads with sequential reads
+func checkIndexedReader(t *testing.T, name string, r *IndexedReader) {
+	packets := readAllIndexTest(t, r)
+	if len(packets) != r.Len() {
+		t.Fatalf("[%s] Expected %d packets, but index has %d", name, len(packets), r.Len())
+	}
+	for _, n := range []int{len(packets) - 1, 0, len(packets) / 2, len(packets) - 1} {
+		if err := r.SeekPacket(n); err != nil {
+			t.Fatalf("[%s] Couldn't seek to packet %d: %s", name, n, err)
+		}
+		data, ci, err := r.ReadPacketData()
+		if err != nil {
+			t.Fatalf("[%s] Couldn't read packet %d: %s", name, n, err)
+		}
+		if !bytes.Equal(data, packets[n].data) || !reflect.DeepEqual(ci, packets[n].ci) {
+			t.Fatalf("[%s] Packet %d mismatch:\ngot:\n%#v\nwant:\n%#v\n\n", name, n, ci, packets[n].ci)
+		}
+		if r.Position() != n+1 {
+			t.Fatalf("[%s] Expected position %d, but got %d", name, n+1, r.Position())
+		}
+	}
+	if err := r.SeekPacket(r.Len()); err != nil {
+		t.Fatalf("[%s] Couldn't seek to end: %s", name, err)
+	}
+	if _, _, err := r.ReadPacketData(); err != io.EOF {
+		t.Fatalf("[%s] Expected EOF after seeking to the end, but got %v", name, err)
+	}
+	if err := r.SeekPacket(r.Len() + 1); err != ErrIndexOutOfRange {
+		t.Fatalf("[%s] Expected ErrIndexOutOfRange, but got %v", name, err)
+	}
+}
+
+func TestIndexedReaderPcap(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	w := NewWriterNanos(buffer)
+	if err := w.WriteFileHeader(65536, layers.LinkTypeEthernet); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1519128000, 0)
+	for i := 0; i < 100; i++ {
+		data := ngPacketSource[i%len(ngPacketSource)]
+		ci := gopacket.CaptureInfo{
+			Timestamp:     start.Add(time.Duration(i) * time.Second),
+			CaptureLength: len(data),
+			Length:        len(data),
+		}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rs := bytes.NewReader(buffer.Bytes())
+	r, err := NewIndexedReader(rs, nil, DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal("Couldn't create indexed reader:", err)
+	}
+	checkIndexedReader(t, "pcap", r)
+
+	if err := r.SeekTime(start.Add(41500 * time.Millisecond)); err != nil {
+		t.Fatal("Couldn't seek to timestamp:", err)
+	}
+	_, ci, err := r.ReadPacketData()
+	if err != nil {
+		t.Fatal("Couldn't read packet:", err)
+	}
+	if want := start.Add(42 * time.Second); !ci.Timestamp.Equal(want) {
+		t.Fatalf("Expected packet at %s, but got %s", want, ci.Timestamp)
+	}
+
+	// store and load the index
+	stored := &bytes.Buffer{}
+	if _, err := r.Index().WriteTo(stored); err != nil {
+		t.Fatal("Couldn't store index:", err)
+	}
+	index, err := ReadIndex(stored)
+	if err != nil {
+		t.Fatal("Couldn't load index:", err)
+	}
+	if !reflect.DeepEqual(index, r.Index()) {
+		t.Fatal("Loaded index differs from stored one")
+	}
+}
+
+func TestIndexedReaderPcapng(t *testing.T) {
+	for _, name := range []string{"test005", "test009", "test010", "test101", "test202"} {
+		for _, be := range []string{"be", "le"} {
+			contents, err := ioutil.ReadFile(filepath.Join("tests", be, name+".pcapng"))
+			if err != nil {
+				t.Fatal("Couldn't open file:", err)
+			}
+			rs := bytes.NewReader(contents)
+			index, err := BuildIndex(rs)
+			if err != nil {
+				t.Fatalf("[%s/%s] Couldn't build index: %s", be, name, err)
+			}
+
+			stored := &bytes.Buffer{}
+			if _, err := index.WriteTo(stored); err != nil {
+				t.Fatal("Couldn't store index:", err)
+			}
+			if index, err = ReadIndex(stored); err != nil {
+				t.Fatal("Couldn't load index:", err)
+			}
+
+			r, err := NewIndexedReader(rs, index, NgReaderOptions{WantMixedLinkType: true})
+			if err != nil {
+				t.Fatalf("[%s/%s] Couldn't create indexed reader: %s", be, name, err)
+			}
+			checkIndexedReader(t, be+"/"+name, r)
+		}
+	}
+}
+
+func TestIndexedReaderPcapngRecover(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	w, err := NewNgWriter(buffer, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		data := ngPacketSource[i%len(ngPacketSource)]
+		ci := gopacket.CaptureInfo{
+			Timestamp:     time.Unix(int64(i), 0).UTC(),
+			CaptureLength: len(data),
+			Length:        len(data),
+		}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewIndexedReader(bytes.NewReader(buffer.Bytes()), nil, NgReaderOptions{
+		Recover: true,
+		RecoverCallback: func(offset, length int64) {
+			t.Errorf("Unexpected skipped range %d+%d", offset, length)
+		},
+	})
+	if err != nil {
+		t.Fatal("Couldn't create indexed reader:", err)
+	}
+	checkIndexedReader(t, "recover", r)
+	if err := r.SeekPacket(30); err != nil {
+		t.Fatal("Couldn't seek to packet 30:", err)
+	}
+	if _, ci, err := r.ReadPacketData(); err != nil || ci.Timestamp.Unix() != 30 {
+		t.Fatalf("Expected packet 30, but got %s, error %v", ci.Timestamp, err)
+	}
+}
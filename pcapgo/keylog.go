@@ -0,0 +1,197 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// KeyLogWatcher tails a TLS key log file, as written by browsers and TLS libraries to the file named by the
+// SSLKEYLOGFILE environment variable, and writes the collected secrets as decryption secrets blocks to an NgWriter.
+// This makes live captures decryptable in Wireshark without extra steps.
+//
+// Packets must be written with the methods of the KeyLogWatcher. If Interval is not zero, new secrets are written
+// before the next packet at most every Interval. All remaining secrets are written by Close. Comments, empty lines,
+// and lines already written are skipped. The key log file doesn't need to exist when the KeyLogWatcher is created.
+//
+// Like NgWriter, a KeyLogWatcher must not be used from multiple goroutines.
+type KeyLogWatcher struct {
+	w    *NgWriter
+	path string
+	// Interval is the minimum time between decryption secrets blocks written by WritePacket. 0 writes the secrets only on Close.
+	Interval time.Duration
+
+	file    *os.File
+	offset  int64
+	partial []byte
+	pending bytes.Buffer
+	seen    map[string]struct{}
+	last    time.Time
+	buf     []byte
+}
+
+// NewKeyLogWatcher returns a new KeyLogWatcher tailing the key log file at path and writing the secrets to w at most
+// every interval.
+func NewKeyLogWatcher(w *NgWriter, path string, interval time.Duration) *KeyLogWatcher {
+	return &KeyLogWatcher{
+		w:        w,
+		path:     path,
+		Interval: interval,
+		seen:     make(map[string]struct{}),
+		last:     time.Now(),
+	}
+}
+
+// Poll reads the lines appended to the key log file since the last call. A truncated or replaced file is read from
+// the start again. The collected secrets are written by the next WriteSecrets.
+func (k *KeyLogWatcher) Poll() error {
+	if k.file == nil {
+		f, err := os.Open(k.path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		k.file = f
+		k.offset = 0
+	}
+
+	info, err := k.file.Stat()
+	if err != nil {
+		return err
+	}
+	if current, err := os.Stat(k.path); err == nil && !os.SameFile(info, current) {
+		// replaced; finish the old file and continue with the new one
+		err = k.read()
+		k.file.Close()
+		k.file = nil
+		k.flushPartial()
+		if err != nil {
+			return err
+		}
+		return k.Poll()
+	}
+	if info.Size() < k.offset {
+		// truncated
+		k.offset = 0
+		k.partial = k.partial[:0]
+	}
+	return k.read()
+}
+
+// read reads the file from the current offset up to the end
+func (k *KeyLogWatcher) read() error {
+	if k.buf == nil {
+		k.buf = make([]byte, 32*1024)
+	}
+	for {
+		n, err := k.file.ReadAt(k.buf, k.offset)
+		k.offset += int64(n)
+		k.addLines(k.buf[:n])
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// addLines collects the complete new lines in data
+func (k *KeyLogWatcher) addLines(data []byte) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			k.partial = append(k.partial, data...)
+			return
+		}
+		line := data[:i]
+		if len(k.partial) > 0 {
+			k.partial = append(k.partial, line...)
+			line = k.partial
+		}
+		data = data[i+1:]
+		k.addLine(line)
+		k.partial = k.partial[:0]
+	}
+}
+
+// flushPartial collects the last line of a finished file, which may lack its newline
+func (k *KeyLogWatcher) flushPartial() {
+	k.addLine(k.partial)
+	k.partial = k.partial[:0]
+}
+
+// addLine collects the secret in line, if it wasn't seen yet
+func (k *KeyLogWatcher) addLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == '#' {
+		return
+	}
+	if _, ok := k.seen[string(line)]; !ok {
+		k.seen[string(line)] = struct{}{}
+		k.pending.Write(line)
+		k.pending.WriteByte('\n')
+	}
+}
+
+// WriteSecrets polls the key log file and writes all collected secrets not yet written as a decryption secrets block.
+// Nothing is written if there are no new secrets.
+func (k *KeyLogWatcher) WriteSecrets() error {
+	k.last = time.Now()
+	if err := k.Poll(); err != nil {
+		return err
+	}
+	if k.pending.Len() == 0 {
+		return nil
+	}
+	if err := k.w.WriteDecryptionSecrets(NgSecretsTypeTLSKeyLog, k.pending.Bytes()); err != nil {
+		return err
+	}
+	k.pending.Reset()
+	return nil
+}
+
+// WritePacket writes the given packet to the underlying NgWriter. If Interval has elapsed since secrets were
+// written the last time, new secrets are written before the packet.
+func (k *KeyLogWatcher) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	return k.WritePacketWithOptions(ci, data, NgPacketOptions{})
+}
+
+// WritePacketWithOptions is like WritePacket, but additionally writes the given packet options.
+func (k *KeyLogWatcher) WritePacketWithOptions(ci gopacket.CaptureInfo, data []byte, options NgPacketOptions) error {
+	if k.Interval > 0 && time.Since(k.last) >= k.Interval {
+		if err := k.WriteSecrets(); err != nil {
+			return err
+		}
+	}
+	return k.w.WritePacketWithOptions(ci, data, options)
+}
+
+// Close writes all remaining secrets, including a last line without a newline, flushes the underlying NgWriter, and
+// closes the key log file.
+func (k *KeyLogWatcher) Close() error {
+	err := k.Poll()
+	if err == nil {
+		k.flushPartial()
+		err = k.WriteSecrets()
+	}
+	if k.file != nil {
+		k.file.Close()
+		k.file = nil
+	}
+	if err != nil {
+		return err
+	}
+	return k.w.Flush()
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestKeyLogWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keylog")
+	if err != nil {
+		t.Fatal("Couldn't create directory:", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sslkeylog.txt")
+
+	buffer := &bytes.Buffer{}
+	w, err := NewNgWriter(buffer, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal("Couldn't create writer:", err)
+	}
+	k := NewKeyLogWatcher(w, path, time.Nanosecond)
+	writePacket := func() {
+		data := ngPacketSource[0]
+		if err := k.WritePacket(gopacket.CaptureInfo{Timestamp: time.Unix(0, 0), CaptureLength: len(data), Length: len(data)}, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+	}
+	appendLog := func(s string) {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			t.Fatal("Couldn't open key log:", err)
+		}
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal("Couldn't write key log:", err)
+		}
+		f.Close()
+	}
+
+	// key log doesn't exist yet
+	writePacket()
+	appendLog("# comment\nCLIENT_RANDOM aa 11\nCLIENT_RANDOM bb")
+	time.Sleep(time.Millisecond)
+	writePacket()
+	appendLog(" 22\nCLIENT_RANDOM aa 11\n")
+	time.Sleep(time.Millisecond)
+	writePacket()
+	// truncated and rewritten
+	if err := ioutil.WriteFile(path, []byte("CLIENT_RANDOM cc 33\n"), 0600); err != nil {
+		t.Fatal("Couldn't write key log:", err)
+	}
+	if err := k.Close(); err != nil {
+		t.Fatal("Couldn't close watcher:", err)
+	}
+
+	var secrets []string
+	r, err := NewNgReader(buffer, NgReaderOptions{
+		DecryptionSecretsCallback: func(typ NgSecretsType, data []byte) {
+			if typ != NgSecretsTypeTLSKeyLog {
+				t.Errorf("Expected TLS key log, but got type %x", typ)
+			}
+			secrets = append(secrets, string(data))
+		},
+	})
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+	packets := 0
+	for {
+		if _, _, err := r.ReadPacketData(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal("Couldn't read packet:", err)
+		}
+		packets++
+	}
+	expected := []string{"CLIENT_RANDOM aa 11\n", "CLIENT_RANDOM bb 22\n", "CLIENT_RANDOM cc 33\n"}
+	if packets != 3 || len(secrets) != len(expected) {
+		t.Fatalf("Expected 3 packets and secrets %q, but got %d and %q", expected, packets, secrets)
+	}
+	for i := range expected {
+		if secrets[i] != expected[i] {
+			t.Errorf("Expected secrets %q, but got %q", expected[i], secrets[i])
+		}
+	}
+}
+
+func TestKeyLogWatcherNoFinalNewline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keylog")
+	if err != nil {
+		t.Fatal("Couldn't create directory:", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sslkeylog.txt")
+	if err := ioutil.WriteFile(path, []byte("CLIENT_RANDOM aa 11\nCLIENT_RANDOM bb 22"), 0600); err != nil {
+		t.Fatal("Couldn't write key log:", err)
+	}
+
+	buffer := &bytes.Buffer{}
+	w, err := NewNgWriter(buffer, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal("Couldn't create writer:", err)
+	}
+	if err := NewKeyLogWatcher(w, path, 0).Close(); err != nil {
+		t.Fatal("Couldn't close watcher:", err)
+	}
+
+	var secrets []string
+	r, err := NewNgReader(buffer, NgReaderOptions{
+		DecryptionSecretsCallback: func(typ NgSecretsType, data []byte) {
+			secrets = append(secrets, string(data))
+		},
+	})
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+	if _, _, err := r.ReadPacketData(); err != io.EOF {
+		t.Fatal("Expected EOF, but got", err)
+	}
+	expected := "CLIENT_RANDOM aa 11\nCLIENT_RANDOM bb 22\n"
+	if len(secrets) != 1 || secrets[0] != expected {
+		t.Errorf("Expected secrets %q, but got %q", expected, secrets)
+	}
+}
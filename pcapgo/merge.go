@@ -0,0 +1,247 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/google/gopacket"
+)
+
+// MergeOptions holds options for MergeReaders.
+type MergeOptions struct {
+	// SectionInfo is written to the section header of the output. If empty, the section information of the first
+	// pcapng input is used, or DefaultNgWriterOptions.SectionInfo if there is none.
+	SectionInfo NgSectionInfo
+	// KeepDuplicateInterfaces disables coalescing identical interfaces of different inputs into a single output interface.
+	KeepDuplicateInterfaces bool
+}
+
+// mergeInterfaceKey identifies an interface of an input
+type mergeInterfaceKey struct {
+	input   int
+	section int
+	id      int
+}
+
+// mergeInput is a single input of MergeReaders with its next packet
+type mergeInput struct {
+	index   int
+	ng      *NgReader
+	pcap    *Reader
+	section int
+
+	data    []byte
+	ci      gopacket.CaptureInfo
+	options NgPacketOptions
+	intf    NgInterface
+}
+
+// next reads the next packet of the input
+func (in *mergeInput) next() error {
+	var err error
+	if in.ng != nil {
+		if in.data, in.ci, in.options, err = in.ng.ReadPacketDataWithOptions(); err != nil {
+			return err
+		}
+		in.intf = in.ng.ifaces[in.ci.InterfaceIndex]
+		return nil
+	}
+	in.data, in.ci, err = in.pcap.ReadPacketData()
+	return err
+}
+
+// mergeHeap orders inputs by the timestamp of their next packet, and the input order for equal timestamps
+type mergeHeap []*mergeInput
+
+func (h mergeHeap) Len() int { return len(h) }
+func (h mergeHeap) Less(i, j int) bool {
+	if h[i].ci.Timestamp.Equal(h[j].ci.Timestamp) {
+		return h[i].index < h[j].index
+	}
+	return h[i].ci.Timestamp.Before(h[j].ci.Timestamp)
+}
+func (h mergeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(*mergeInput)) }
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// mergeComparableInterface clears the values of an interface which don't identify it. The timestamp offset is
+// cleared as well, since it is already applied to the packet timestamps by NgReader.
+func mergeComparableInterface(intf NgInterface) NgInterface {
+	intf.Statistics = NgInterfaceStatistics{}
+	intf.TimestampResolution = 0
+	intf.TimestampOffset = 0
+	intf.secondMask = 0
+	intf.scaleUp = 0
+	intf.scaleDown = 0
+	return intf
+}
+
+// openMergeInput detects the format of r and opens a reader for it
+func openMergeInput(r io.Reader, index int) (*mergeInput, error) {
+	br := bufio.NewReader(r)
+	in := &mergeInput{index: index}
+	uncompressed, err := decompress(br)
+	if err != nil {
+		return nil, err
+	}
+	if uncompressed != io.Reader(br) {
+		br = bufio.NewReader(uncompressed)
+	}
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, err
+	}
+	if ngBlockType(binary.LittleEndian.Uint32(magic)) == ngBlockTypeSectionHeader {
+		in.ng, err = NewNgReader(br, NgReaderOptions{
+			WantMixedLinkType: true,
+			SectionEndCallback: func([]NgInterface, NgSectionInfo) {
+				in.section++
+			},
+		})
+	} else {
+		in.pcap, err = NewReader(br)
+		if err == nil {
+			in.intf = NgInterface{
+				LinkType:   in.pcap.LinkType(),
+				SnapLength: in.pcap.Snaplen(),
+				FCSLength:  in.pcap.FCSLength(),
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// MergeReaders merges the packets of the given pcap and pcapng inputs in timestamp order and writes them to w
+// as a pcapng file. Every interface of the inputs becomes an interface of the output, where identical interfaces
+// of different inputs are coalesced into one (see MergeOptions.KeepDuplicateInterfaces). Packets with equal
+// timestamps are written in the order of the inputs. Packet comments and flags of pcapng inputs are preserved,
+// interface statistics are not.
+//
+// Each input must be sorted by timestamp for the output to be sorted.
+func MergeReaders(w io.Writer, options MergeOptions, inputs ...io.Reader) error {
+	if len(inputs) == 0 {
+		return errors.New("Nothing to merge")
+	}
+
+	opened := make([]*mergeInput, len(inputs))
+	for i, r := range inputs {
+		in, err := openMergeInput(r, i)
+		if err != nil {
+			return err
+		}
+		opened[i] = in
+	}
+	return mergeInputs(w, options, opened)
+}
+
+// mergeInputs merges the packets of the given opened inputs; see MergeReaders
+func mergeInputs(w io.Writer, options MergeOptions, inputs []*mergeInput) error {
+	sectionInfo := options.SectionInfo
+	first := inputs[0]
+	h := make(mergeHeap, 0, len(inputs))
+	for _, in := range inputs {
+		if in.ng != nil && sectionInfo == (NgSectionInfo{}) {
+			sectionInfo = in.ng.SectionInfo()
+		}
+		if err := in.next(); err != nil {
+			if err == io.EOF {
+				continue
+			}
+			return err
+		}
+		h = append(h, in)
+	}
+	if sectionInfo == (NgSectionInfo{}) {
+		sectionInfo = DefaultNgWriterOptions.SectionInfo
+	}
+	heap.Init(&h)
+
+	var out *NgWriter
+	var outInterfaces []NgInterface
+	mapping := make(map[mergeInterfaceKey]int)
+
+	outputInterface := func(in *mergeInput) (int, error) {
+		key := mergeInterfaceKey{in.index, in.section, in.ci.InterfaceIndex}
+		if id, ok := mapping[key]; ok {
+			return id, nil
+		}
+		intf := mergeComparableInterface(in.intf)
+		if !options.KeepDuplicateInterfaces {
+			for id := range outInterfaces {
+				if outInterfaces[id] == intf {
+					mapping[key] = id
+					return id, nil
+				}
+			}
+		}
+		var id int
+		var err error
+		if out == nil {
+			out, err = NewNgWriterInterface(w, intf, NgWriterOptions{SectionInfo: sectionInfo})
+		} else {
+			id, err = out.AddInterface(intf)
+		}
+		if err != nil {
+			return 0, err
+		}
+		outInterfaces = append(outInterfaces, intf)
+		mapping[key] = id
+		return id, nil
+	}
+
+	for h.Len() > 0 {
+		in := h[0]
+		id, err := outputInterface(in)
+		if err != nil {
+			return err
+		}
+		ci := in.ci
+		ci.InterfaceIndex = id
+		ci.AncillaryData = nil
+		if err := out.WritePacketWithOptions(ci, in.data, in.options); err != nil {
+			return err
+		}
+		if err := in.next(); err != nil {
+			if err != io.EOF {
+				return err
+			}
+			heap.Pop(&h)
+			continue
+		}
+		heap.Fix(&h, 0)
+	}
+
+	if out == nil {
+		// no packets at all; write at least the first interface
+		intf := first.intf
+		if first.ng != nil {
+			if len(first.ng.ifaces) == 0 {
+				return errors.New("Input contains neither packets nor interfaces")
+			}
+			intf = first.ng.ifaces[0]
+		}
+		var err error
+		if out, err = NewNgWriterInterface(w, mergeComparableInterface(intf), NgWriterOptions{SectionInfo: sectionInfo}); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestMergeReaders(t *testing.T) {
+	start := time.Unix(1519128000, 0).UTC()
+	packet := func(second int, intf int, data []byte) gopacket.CaptureInfo {
+		return gopacket.CaptureInfo{
+			Timestamp:      start.Add(time.Duration(second) * time.Second),
+			CaptureLength:  len(data),
+			Length:         len(data),
+			InterfaceIndex: intf,
+		}
+	}
+
+	// pcapng input with two interfaces
+	ng1 := &bytes.Buffer{}
+	w1, err := NewNgWriterInterface(ng1, NgInterface{Name: "eth0", LinkType: layers.LinkTypeEthernet}, NgWriterOptions{SectionInfo: NgSectionInfo{Comment: "sensor 1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w1.AddInterface(NgInterface{Name: "lo", LinkType: layers.LinkTypeNull}); err != nil {
+		t.Fatal(err)
+	}
+	w1.WritePacket(packet(0, 0, ngPacketSource[0]), ngPacketSource[0])
+	w1.WritePacketWithOptions(packet(3, 1, ngPacketSource[4]), ngPacketSource[4], NgPacketOptions{Comments: []string{"loopback"}})
+	w1.WritePacket(packet(4, 0, ngPacketSource[1]), ngPacketSource[1])
+	w1.Flush()
+
+	// pcapng input sharing eth0 with the first one
+	ng2 := &bytes.Buffer{}
+	w2, err := NewNgWriterInterface(ng2, NgInterface{Name: "eth0", LinkType: layers.LinkTypeEthernet}, DefaultNgWriterOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2.WritePacket(packet(1, 0, ngPacketSource[2]), ngPacketSource[2])
+	w2.WritePacket(packet(4, 0, ngPacketSource[3]), ngPacketSource[3])
+	w2.Flush()
+
+	// pcap input
+	pcap := &bytes.Buffer{}
+	w3 := NewWriterNanos(pcap)
+	w3.WriteFileHeader(65536, layers.LinkTypeEthernet)
+	w3.WritePacket(packet(2, 0, ngPacketSource[0]), ngPacketSource[0])
+
+	out := &bytes.Buffer{}
+	if err := MergeReaders(out, MergeOptions{}, ng1, ng2, pcap); err != nil {
+		t.Fatal("Couldn't merge:", err)
+	}
+
+	r, err := NewNgReader(out, NgReaderOptions{WantMixedLinkType: true})
+	if err != nil {
+		t.Fatal("Couldn't read merged file:", err)
+	}
+	if r.SectionInfo().Comment != "sensor 1" {
+		t.Errorf("Expected section info of first input, but got %+v", r.SectionInfo())
+	}
+	want := []struct {
+		second  int
+		data    []byte
+		intf    string
+		comment string
+	}{
+		{0, ngPacketSource[0], "eth0", ""},
+		{1, ngPacketSource[2], "eth0", ""},
+		{2, ngPacketSource[0], "", ""},
+		{3, ngPacketSource[4], "lo", "loopback"},
+		{4, ngPacketSource[1], "eth0", ""},
+		{4, ngPacketSource[3], "eth0", ""},
+	}
+	for i, p := range want {
+		data, ci, options, err := r.ReadPacketDataWithOptions()
+		if err != nil {
+			t.Fatalf("[packet %d] Couldn't read packet: %s", i, err)
+		}
+		if !bytes.Equal(data, p.data) {
+			t.Errorf("[packet %d] data mismatch", i)
+		}
+		if !ci.Timestamp.Equal(start.Add(time.Duration(p.second) * time.Second)) {
+			t.Errorf("[packet %d] Expected second %d, but got %s", i, p.second, ci.Timestamp)
+		}
+		intf, err := r.Interface(ci.InterfaceIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if intf.Name != p.intf {
+			t.Errorf("[packet %d] Expected interface %q, but got %q", i, p.intf, intf.Name)
+		}
+		if p.comment != "" && (len(options.Comments) != 1 || options.Comments[0] != p.comment) {
+			t.Errorf("[packet %d] Expected comment %q, but got %q", i, p.comment, options.Comments)
+		}
+	}
+	if _, _, err := r.ReadPacketData(); err != io.EOF {
+		t.Fatal("Expected EOF, but got", err)
+	}
+	// eth0 coalesced, lo, and the pcap interface
+	if r.NInterfaces() != 3 {
+		t.Errorf("Expected 3 interfaces, but got %d", r.NInterfaces())
+	}
+}
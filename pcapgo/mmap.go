@@ -0,0 +1,114 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// MappedFile is a read-only memory mapping of a capture file. If a Reader or NgReader reads from a MappedFile,
+// ZeroCopyReadPacketData returns packet data pointing directly into the mapping instead of copying it into a
+// buffer. The returned data stays valid until the MappedFile is closed, but must not be modified.
+//
+// Compressed files are read like any other file, but without the zero-copy benefit.
+//
+//	f, _ := pcapgo.OpenMappedFile("/tmp/file.pcapng")
+//	defer f.Close()
+//	r, _ := pcapgo.NewNgReader(f, pcapgo.DefaultNgReaderOptions)
+//	data, ci, err := r.ZeroCopyReadPacketData()
+//
+// On platforms without mmap support, the file is read into memory.
+type MappedFile struct {
+	data  []byte
+	pos   int
+	unmap func() error
+}
+
+// OpenMappedFile maps the file with the given name into memory.
+func OpenMappedFile(name string) (*MappedFile, error) {
+	data, unmap, err := mapFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return &MappedFile{data: data, unmap: unmap}, nil
+}
+
+// Read implements io.Reader.
+func (m *MappedFile) Read(p []byte) (int, error) {
+	if m.pos >= len(m.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[m.pos:])
+	m.pos += n
+	return n, nil
+}
+
+// Seek implements io.Seeker.
+func (m *MappedFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += int64(m.pos)
+	case io.SeekEnd:
+		offset += int64(len(m.data))
+	}
+	if offset < 0 {
+		return 0, errors.New("Seek to negative position")
+	}
+	if offset > int64(len(m.data)) {
+		offset = int64(len(m.data))
+	}
+	m.pos = int(offset)
+	return offset, nil
+}
+
+// Len returns the size of the mapped file.
+func (m *MappedFile) Len() int {
+	return len(m.data)
+}
+
+// Close unmaps the file. Data returned by zero-copy reads must not be accessed afterwards.
+func (m *MappedFile) Close() error {
+	if m.unmap == nil {
+		return nil
+	}
+	err := m.unmap()
+	m.unmap = nil
+	m.data = nil
+	return err
+}
+
+// compressed returns true if the remaining data starts with a known compression magic
+func (m *MappedFile) compressed() bool {
+	format, _ := detectCompression(bufio.NewReaderSize(bytes.NewReader(m.data[m.pos:]), 16))
+	return format != nil
+}
+
+// next returns the next n bytes of the mapping and advances the position. If skipped is not nil, the bytes
+// buffered by skipped are consumed first.
+func (m *MappedFile) next(n int, skipped *bufio.Reader) ([]byte, error) {
+	pos := m.pos
+	buffered := 0
+	if skipped != nil {
+		buffered = skipped.Buffered()
+		pos -= buffered
+	}
+	if n > len(m.data)-pos {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if n <= buffered {
+		skipped.Discard(n)
+	} else {
+		if buffered > 0 {
+			skipped.Discard(buffered)
+		}
+		m.pos += n - buffered
+	}
+	return m.data[pos : pos+n : pos+n], nil
+}
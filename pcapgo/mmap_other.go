@@ -0,0 +1,17 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package pcapgo
+
+import "io/ioutil"
+
+func mapFile(name string) ([]byte, func() error, error) {
+	data, err := ioutil.ReadFile(name)
+	return data, nil, err
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/gopacket"
+)
+
+type zeroCopyReader interface {
+	ZeroCopyReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+}
+
+// compareMapped reads all packets of name with a regular reader and with a reader on a MappedFile and
+// checks that the packets are equal and the mapped data points into the mapping.
+func compareMapped(t *testing.T, name string, open func(io.Reader) (zeroCopyReader, error)) {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal("Couldn't open file:", err)
+	}
+	defer f.Close()
+	expected, err := open(f)
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+
+	mf, err := OpenMappedFile(name)
+	if err != nil {
+		t.Fatal("Couldn't map file:", err)
+	}
+	defer mf.Close()
+	mapped, err := open(mf)
+	if err != nil {
+		t.Fatal("Couldn't create mapped reader:", err)
+	}
+
+	var previous []byte
+	n := 0
+	for ; ; n++ {
+		expectedData, expectedCI, expectedErr := expected.ZeroCopyReadPacketData()
+		data, ci, err := mapped.ZeroCopyReadPacketData()
+		if err != expectedErr {
+			t.Fatalf("%s packet %d: expected error %v, but got %v", name, n, expectedErr, err)
+		}
+		if err != nil {
+			break
+		}
+		if !bytes.Equal(data, expectedData) || ci.Timestamp != expectedCI.Timestamp || ci.CaptureLength != expectedCI.CaptureLength {
+			t.Fatalf("%s packet %d differs", name, n)
+		}
+		if len(data) > 0 {
+			if !pointsInto(mf.data, data) || cap(data) != len(data) {
+				t.Fatalf("%s packet %d doesn't point into the mapping", name, n)
+			}
+			if previous != nil && &previous[0] == &data[0] {
+				t.Fatalf("%s packet %d reuses the buffer of the previous packet", name, n)
+			}
+			previous = data
+		}
+	}
+	if n == 0 {
+		t.Fatalf("%s: no packets read", name)
+	}
+}
+
+// pointsInto returns true if data is a subslice of buf
+func pointsInto(buf, data []byte) bool {
+	for i := range buf[:len(buf)-len(data)+1] {
+		if &buf[i] == &data[0] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMappedFile(t *testing.T) {
+	compareMapped(t, filepath.Join("..", "pcap", "test_ethernet.pcap"), func(r io.Reader) (zeroCopyReader, error) {
+		return NewReader(r)
+	})
+	for _, name := range []string{"test008.pcapng", "test010.pcapng", "test100.pcapng"} {
+		compareMapped(t, filepath.Join("tests", "le", name), func(r io.Reader) (zeroCopyReader, error) {
+			return NewNgReader(r, DefaultNgReaderOptions)
+		})
+	}
+}
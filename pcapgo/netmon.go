@@ -0,0 +1,286 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+const (
+	netmonMagic1 = "RTSS" // NetMon 1.x
+	netmonMagic2 = "GMBU" // NetMon 2.x
+
+	// netmonHeaderLength is the length of the file header up to and including the frame table length
+	netmonHeaderLength = 4 + 2 + 2 + 8*2 + 4 + 4
+
+	// network types with the top bits set to netmonNetPcapBase carry the pcap link type in the lower bits
+	netmonNetPcapBase = 0xe000
+	// network types from netmonNetEvent on are used for event and meta data frames
+	netmonNetEvent = 0xffe0
+
+	netmonMaxFrameLength = 0x1000000
+	// netmonMaxFrameTableLength limits the frame table to 16M frames
+	netmonMaxFrameTableLength = 4 * 0x1000000
+)
+
+// netmon media types mapped to gopacket link types
+var netmonLinkTypes = map[uint16]layers.LinkType{
+	1: layers.LinkTypeEthernet,  // NDIS Ethernet
+	2: layers.LinkTypeTokenRing, // NDIS Token Ring
+	3: layers.LinkTypeFDDI,      // NDIS FDDI
+	/*
+		not supported:
+		0 - unknown
+		4 - NDIS WAN
+		5 - NDIS LocalTalk
+		6 - IEEE 802.11 with NetMon radio header
+	*/
+}
+
+// netmonLinkType maps a netmon network type to a gopacket link type
+func netmonLinkType(network uint16) (layers.LinkType, bool) {
+	if network&0xf000 == netmonNetPcapBase {
+		return layers.LinkType(network & 0x0fff), true
+	}
+	linkType, ok := netmonLinkTypes[network]
+	return linkType, ok
+}
+
+// NetmonFrameInfo holds the per frame information of a NetMon file. NetmonReader places it in ci.AncillaryData[0].
+type NetmonFrameInfo struct {
+	// Network is the raw network (media) type of the frame. Files of version 2.1 and later store a network type per frame,
+	// older files use the network type of the file header.
+	Network uint16
+	// LinkType is the link type matching Network, or 0 if there is no matching link type.
+	LinkType layers.LinkType
+	// ProcessInfoIndex is the index into the process info table of files of version 2.2 and later.
+	ProcessInfoIndex uint32
+}
+
+// NetmonReader reads packets from capture files written by Microsoft Network Monitor 1.x and 2.x (.cap files).
+// Frames are read in the order of the frame table, which NetMon uses to locate frames within the file. Event and
+// meta data frames of NetMon 3 are skipped.
+//
+// NetMon stores the capture start time without time zone; it is interpreted as UTC. Files of version 2.3 and later
+// carry an additional UTC timestamp per frame, which is used instead.
+type NetmonReader struct {
+	r            io.ReadSeeker
+	versionMajor uint8
+	versionMinor uint8
+	network      uint16
+	start        time.Time
+	frameTable   []uint32
+	next         int
+	trailer      int
+
+	packetBuf []byte
+	buf       [16]byte
+	ancil     [1]interface{}
+}
+
+// NewNetmonReader returns a new NetmonReader reading from r. The frame table is read immediately.
+func NewNetmonReader(r io.ReadSeeker) (*NetmonReader, error) {
+	var header [netmonHeaderLength]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	ret := &NetmonReader{r: r}
+	switch string(header[:4]) {
+	case netmonMagic1, netmonMagic2:
+	default:
+		return nil, errors.New("Unknown NetMon magic")
+	}
+	ret.versionMinor = header[4]
+	ret.versionMajor = header[5]
+	switch ret.versionMajor {
+	case 1:
+	case 2:
+		switch {
+		case ret.versionMinor == 0:
+		case ret.versionMinor == 1:
+			ret.trailer = 2
+		case ret.versionMinor == 2:
+			ret.trailer = 6
+		default:
+			ret.trailer = 15
+		}
+	default:
+		return nil, fmt.Errorf("Unknown NetMon version %d.%d", ret.versionMajor, ret.versionMinor)
+	}
+	ret.network = binary.LittleEndian.Uint16(header[6:8])
+
+	year := int(binary.LittleEndian.Uint16(header[8:10]))
+	month := time.Month(binary.LittleEndian.Uint16(header[10:12]))
+	day := int(binary.LittleEndian.Uint16(header[14:16]))
+	hour := int(binary.LittleEndian.Uint16(header[16:18]))
+	min := int(binary.LittleEndian.Uint16(header[18:20]))
+	sec := int(binary.LittleEndian.Uint16(header[20:22]))
+	msec := int(binary.LittleEndian.Uint16(header[22:24]))
+	ret.start = time.Date(year, month, day, hour, min, sec, msec*int(time.Millisecond), time.UTC)
+
+	tableOffset := binary.LittleEndian.Uint32(header[24:28])
+	tableLength := binary.LittleEndian.Uint32(header[28:32])
+	if tableLength%4 != 0 || tableLength > netmonMaxFrameTableLength {
+		return nil, fmt.Errorf("Invalid NetMon frame table length %d", tableLength)
+	}
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if int64(tableOffset)+int64(tableLength) > size {
+		return nil, fmt.Errorf("NetMon frame table at %d with length %d exceeds file size %d", tableOffset, tableLength, size)
+	}
+	if _, err := r.Seek(int64(tableOffset), io.SeekStart); err != nil {
+		return nil, err
+	}
+	table := make([]byte, tableLength)
+	if _, err := io.ReadFull(r, table); err != nil {
+		return nil, fmt.Errorf("Couldn't read NetMon frame table: %v", err)
+	}
+	ret.frameTable = make([]uint32, tableLength/4)
+	for i := range ret.frameTable {
+		ret.frameTable[i] = binary.LittleEndian.Uint32(table[i*4:])
+	}
+	return ret, nil
+}
+
+// readFrameHeader reads the header of the next frame and returns the capture info and the frame info. The reader is positioned at the frame data.
+func (r *NetmonReader) readFrameHeader() (ci gopacket.CaptureInfo, info NetmonFrameInfo, err error) {
+	for {
+		if r.next >= len(r.frameTable) {
+			err = io.EOF
+			return
+		}
+		offset := int64(r.frameTable[r.next])
+		r.next++
+		if _, err = r.r.Seek(offset, io.SeekStart); err != nil {
+			return
+		}
+
+		var delta time.Duration
+		var headerLength int
+		if r.versionMajor == 1 {
+			headerLength = 8
+			if _, err = io.ReadFull(r.r, r.buf[:headerLength]); err != nil {
+				return
+			}
+			delta = time.Duration(binary.LittleEndian.Uint32(r.buf[0:4])) * time.Millisecond
+			ci.Length = int(binary.LittleEndian.Uint16(r.buf[4:6]))
+			ci.CaptureLength = int(binary.LittleEndian.Uint16(r.buf[6:8]))
+		} else {
+			headerLength = 16
+			if _, err = io.ReadFull(r.r, r.buf[:headerLength]); err != nil {
+				return
+			}
+			delta = time.Duration(binary.LittleEndian.Uint64(r.buf[0:8])) * time.Microsecond
+			ci.Length = int(binary.LittleEndian.Uint32(r.buf[8:12]))
+			ci.CaptureLength = int(binary.LittleEndian.Uint32(r.buf[12:16]))
+		}
+		if ci.CaptureLength > netmonMaxFrameLength {
+			err = fmt.Errorf("NetMon frame length %d exceeds maximum", ci.CaptureLength)
+			return
+		}
+		ci.Timestamp = r.start.Add(delta)
+		info.Network = r.network
+
+		if r.trailer > 0 {
+			if _, err = r.r.Seek(offset+int64(headerLength+ci.CaptureLength), io.SeekStart); err != nil {
+				return
+			}
+			if _, err = io.ReadFull(r.r, r.buf[:r.trailer]); err != nil {
+				return
+			}
+			info.Network = binary.LittleEndian.Uint16(r.buf[0:2])
+			if r.trailer >= 6 {
+				info.ProcessInfoIndex = binary.LittleEndian.Uint32(r.buf[2:6])
+			}
+			if r.trailer >= 14 {
+				// 100ns units since January 1, 1601
+				if utc := binary.LittleEndian.Uint64(r.buf[6:14]); utc != 0 {
+					const epochDiff = 11644473600 // seconds between 1601 and 1970
+					ci.Timestamp = time.Unix(int64(utc/1e7)-epochDiff, int64(utc%1e7)*100).UTC()
+				}
+			}
+			if _, err = r.r.Seek(offset+int64(headerLength), io.SeekStart); err != nil {
+				return
+			}
+		}
+
+		if info.Network >= netmonNetEvent {
+			continue
+		}
+		info.LinkType, _ = netmonLinkType(info.Network)
+		return
+	}
+}
+
+// ReadPacketData reads the next frame. ci.AncillaryData[0] contains a NetmonFrameInfo.
+func (r *NetmonReader) ReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
+	var info NetmonFrameInfo
+	if ci, info, err = r.readFrameHeader(); err != nil {
+		return
+	}
+	data = make([]byte, ci.CaptureLength)
+	if _, err = io.ReadFull(r.r, data); err != nil {
+		return
+	}
+	ci.AncillaryData = []interface{}{info}
+	return
+}
+
+// ZeroCopyReadPacketData reads the next frame. The data buffer and ci.AncillaryData are owned by the NetmonReader,
+// and each call to ZeroCopyReadPacketData invalidates data returned by the previous one. ci.AncillaryData[0] contains a NetmonFrameInfo.
+func (r *NetmonReader) ZeroCopyReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
+	var info NetmonFrameInfo
+	if ci, info, err = r.readFrameHeader(); err != nil {
+		return
+	}
+	if cap(r.packetBuf) < ci.CaptureLength {
+		r.packetBuf = make([]byte, ci.CaptureLength)
+	}
+	data = r.packetBuf[:ci.CaptureLength]
+	if _, err = io.ReadFull(r.r, data); err != nil {
+		return
+	}
+	r.ancil[0] = info
+	ci.AncillaryData = r.ancil[:]
+	return
+}
+
+// LinkType returns the link type of the network type in the file header. 0 is returned for unsupported network types.
+func (r *NetmonReader) LinkType() layers.LinkType {
+	linkType, _ := netmonLinkType(r.network)
+	return linkType
+}
+
+// Version returns the major and minor version of the file format.
+func (r *NetmonReader) Version() (major, minor int) {
+	return int(r.versionMajor), int(r.versionMinor)
+}
+
+// Len returns the number of frames in the frame table, including event and meta data frames.
+func (r *NetmonReader) Len() int {
+	return len(r.frameTable)
+}
+
+// Resolution returns the timestamp resolution of acquired timestamps before scaling to NanosecondTimestampResolution.
+func (r *NetmonReader) Resolution() gopacket.TimestampResolution {
+	if r.versionMajor == 1 {
+		return gopacket.TimestampResolution{Base: 10, Exponent: -3}
+	}
+	if r.trailer >= 14 {
+		return gopacket.TimestampResolution{Base: 10, Exponent: -7}
+	}
+	return gopacket.TimestampResolution{Base: 10, Exponent: -6}
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+type netmonTestFrame struct {
+	delta   uint64
+	network uint16
+	utc     uint64
+	data    []byte
+}
+
+// buildNetmonFile builds a NetMon file with the given version and frames. The frame table is written in reverse
+// order of the frames in the file to check that the frame table order is used.
+func buildNetmonFile(major, minor uint8, frames []netmonTestFrame) []byte {
+	le := binary.LittleEndian
+	header := make([]byte, 128)
+	if major == 1 {
+		copy(header, netmonMagic1)
+	} else {
+		copy(header, netmonMagic2)
+	}
+	header[4] = minor
+	header[5] = major
+	le.PutUint16(header[6:], 1)
+	for i, v := range []uint16{2020, 2, 0, 3, 4, 5, 6, 7} {
+		le.PutUint16(header[8+2*i:], v)
+	}
+
+	buf := bytes.NewBuffer(header)
+	offsets := make([]uint32, len(frames))
+	for i := len(frames) - 1; i >= 0; i-- {
+		f := frames[i]
+		offsets[i] = uint32(buf.Len())
+		if major == 1 {
+			binary.Write(buf, le, uint32(f.delta))
+			binary.Write(buf, le, uint16(len(f.data)+4))
+			binary.Write(buf, le, uint16(len(f.data)))
+		} else {
+			binary.Write(buf, le, f.delta)
+			binary.Write(buf, le, uint32(len(f.data)+4))
+			binary.Write(buf, le, uint32(len(f.data)))
+		}
+		buf.Write(f.data)
+		if major == 2 && minor >= 3 {
+			binary.Write(buf, le, f.network)
+			binary.Write(buf, le, uint32(7))
+			binary.Write(buf, le, f.utc)
+			buf.WriteByte(0)
+		}
+	}
+	contents := buf.Bytes()
+	le.PutUint32(contents[24:], uint32(len(contents)))
+	le.PutUint32(contents[28:], uint32(4*len(frames)))
+	for _, offset := range offsets {
+		contents = append(contents, 0, 0, 0, 0)
+		le.PutUint32(contents[len(contents)-4:], offset)
+	}
+	return contents
+}
+
+func TestNetmonRead(t *testing.T) {
+	start := time.Date(2020, 2, 3, 4, 5, 6, 7e6, time.UTC)
+	tests := []struct {
+		major, minor uint8
+		delta        time.Duration
+	}{
+		{1, 1, time.Millisecond},
+		{2, 0, time.Microsecond},
+		{2, 3, time.Microsecond},
+	}
+	for _, test := range tests {
+		frames := []netmonTestFrame{
+			{delta: 10, network: 1, data: ngPacketSource[0]},
+			// 100ns units since 1601 for 2021-01-01
+			{delta: 30, network: netmonNetPcapBase | uint16(layers.LinkTypeRaw), utc: (1609459200 + 11644473600) * 1e7, data: ngPacketSource[1]},
+		}
+		if test.minor >= 3 {
+			// event frames can only be identified by the per frame network type
+			frames = append(frames, netmonTestFrame{delta: 40, network: netmonNetEvent, data: []byte{1, 2, 3}})
+		}
+		contents := buildNetmonFile(test.major, test.minor, frames)
+		r, err := NewNetmonReader(bytes.NewReader(contents))
+		if err != nil {
+			t.Fatalf("[%d.%d] Couldn't create reader: %v", test.major, test.minor, err)
+		}
+		if r.LinkType() != layers.LinkTypeEthernet {
+			t.Errorf("[%d.%d] Expected link type ethernet, but got %s", test.major, test.minor, r.LinkType())
+		}
+		if r.Len() != len(frames) {
+			t.Errorf("[%d.%d] Expected %d frames, but got %d", test.major, test.minor, len(frames), r.Len())
+		}
+
+		var packets []gopacket.CaptureInfo
+		for {
+			data, ci, err := r.ReadPacketData()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("[%d.%d] Couldn't read packet: %v", test.major, test.minor, err)
+			}
+			if ci.CaptureLength != len(data) || ci.Length != len(data)+4 {
+				t.Errorf("[%d.%d] Wrong lengths %+v", test.major, test.minor, ci)
+			}
+			packets = append(packets, ci)
+		}
+		if len(packets) != 2 {
+			t.Fatalf("[%d.%d] Expected 2 packets, but got %d", test.major, test.minor, len(packets))
+		}
+
+		if ts := start.Add(10 * test.delta); !packets[0].Timestamp.Equal(ts) {
+			t.Errorf("[%d.%d] Expected timestamp %s, but got %s", test.major, test.minor, ts, packets[0].Timestamp)
+		}
+		info := packets[1].AncillaryData[0].(NetmonFrameInfo)
+		if test.minor >= 3 {
+			if ts := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC); !packets[1].Timestamp.Equal(ts) {
+				t.Errorf("[%d.%d] Expected timestamp %s, but got %s", test.major, test.minor, ts, packets[1].Timestamp)
+			}
+			if info.LinkType != layers.LinkTypeRaw || info.ProcessInfoIndex != 7 {
+				t.Errorf("[%d.%d] Wrong frame info %+v", test.major, test.minor, info)
+			}
+		} else {
+			if ts := start.Add(30 * test.delta); !packets[1].Timestamp.Equal(ts) {
+				t.Errorf("[%d.%d] Expected timestamp %s, but got %s", test.major, test.minor, ts, packets[1].Timestamp)
+			}
+			if info.LinkType != layers.LinkTypeEthernet {
+				t.Errorf("[%d.%d] Wrong frame info %+v", test.major, test.minor, info)
+			}
+		}
+	}
+}
+
+func TestNetmonBadMagic(t *testing.T) {
+	if _, err := NewNetmonReader(bytes.NewReader(make([]byte, 64))); err == nil {
+		t.Error("Expected error for bad magic")
+	}
+}
+
+func TestNetmonBadFrameTable(t *testing.T) {
+	for _, length := range []uint32{0xfffffffc, 4 * 16} {
+		data := buildNetmonFile(2, 1, []netmonTestFrame{{data: []byte{1, 2, 3, 4}}})
+		binary.LittleEndian.PutUint32(data[28:], length)
+		if _, err := NewNetmonReader(bytes.NewReader(data)); err == nil {
+			t.Errorf("Expected error for frame table length %d", length)
+		}
+	}
+}
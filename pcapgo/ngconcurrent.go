@@ -0,0 +1,251 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"container/heap"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+)
+
+// DefaultConcurrentNgWriterMaxPending is the number of packets ConcurrentNgWriter holds back for reordering if no limit is given.
+const DefaultConcurrentNgWriterMaxPending = 4096
+
+// ConcurrentNgWriter writes packets of multiple concurrently capturing goroutines to a single pcapng file. Every goroutine
+// registers its interface with Interface and writes to the returned NgInterfaceWriter, which can be used without further locking.
+//
+// Packets are written in timestamp order, assuming every NgInterfaceWriter receives packets in timestamp order. A packet
+// is held back until every open NgInterfaceWriter has written a packet with an equal or later timestamp, or until more
+// than maxPending packets are held back. Closing an NgInterfaceWriter of an interface without further traffic releases the
+// packets held back for it. Flush or Close must be called to write out all held back packets.
+//
+//	w := pcapgo.NewConcurrentNgWriter(f, pcapgo.DefaultNgWriterOptions, 0)
+//	for _, name := range []string{"eth0", "eth1"} {
+//		iw, _ := w.Interface(pcapgo.NgInterface{Name: name, LinkType: layers.LinkTypeEthernet})
+//		go func() {
+//			defer iw.Close()
+//			for ... {
+//				iw.WritePacket(ci, data)
+//			}
+//		}()
+//	}
+//	... wait for the goroutines
+//	w.Close()
+type ConcurrentNgWriter struct {
+	mu         sync.Mutex
+	w          io.Writer
+	options    NgWriterOptions
+	ng         *NgWriter
+	maxPending int
+	pending    ngPendingHeap
+	seq        uint64
+	open       map[*NgInterfaceWriter]struct{}
+	err        error
+}
+
+// NgInterfaceWriter writes the packets of a single interface to a ConcurrentNgWriter. It is safe for concurrent use.
+type NgInterfaceWriter struct {
+	c    *ConcurrentNgWriter
+	id   int
+	last time.Time
+}
+
+// ngPendingPacket is a packet held back for reordering
+type ngPendingPacket struct {
+	ci      gopacket.CaptureInfo
+	data    []byte
+	options NgPacketOptions
+	seq     uint64
+}
+
+// ngPendingHeap orders pending packets by timestamp, and the order of writing for equal timestamps
+type ngPendingHeap []ngPendingPacket
+
+func (h ngPendingHeap) Len() int { return len(h) }
+func (h ngPendingHeap) Less(i, j int) bool {
+	if h[i].ci.Timestamp.Equal(h[j].ci.Timestamp) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].ci.Timestamp.Before(h[j].ci.Timestamp)
+}
+func (h ngPendingHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *ngPendingHeap) Push(x interface{}) { *h = append(*h, x.(ngPendingPacket)) }
+func (h *ngPendingHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = ngPendingPacket{}
+	*h = old[:n-1]
+	return x
+}
+
+// NewConcurrentNgWriter returns a new ConcurrentNgWriter writing to w. The section header is written with the first interface.
+// If maxPending is 0, DefaultConcurrentNgWriterMaxPending is used.
+func NewConcurrentNgWriter(w io.Writer, options NgWriterOptions, maxPending int) *ConcurrentNgWriter {
+	if maxPending <= 0 {
+		maxPending = DefaultConcurrentNgWriterMaxPending
+	}
+	return &ConcurrentNgWriter{
+		w:          w,
+		options:    options,
+		maxPending: maxPending,
+		open:       make(map[*NgInterfaceWriter]struct{}),
+	}
+}
+
+// Interface adds the given interface to the file and returns a writer for its packets.
+func (c *ConcurrentNgWriter) Interface(intf NgInterface) (*NgInterfaceWriter, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return nil, c.err
+	}
+	var id int
+	var err error
+	if c.ng == nil {
+		c.ng, err = NewNgWriterInterface(c.w, intf, c.options)
+	} else {
+		id, err = c.ng.AddInterface(intf)
+	}
+	if err != nil {
+		c.err = err
+		return nil, err
+	}
+	iw := &NgInterfaceWriter{c: c, id: id}
+	c.open[iw] = struct{}{}
+	return iw, nil
+}
+
+// watermark returns the timestamp up to which all packets have been handed to the writer
+func (c *ConcurrentNgWriter) watermark() (t time.Time, ok bool) {
+	first := true
+	for iw := range c.open {
+		if first || iw.last.Before(t) {
+			t = iw.last
+			first = false
+		}
+	}
+	return t, !first
+}
+
+// release writes the pending packets which can't be preceded by later packets anymore. If all is true, every pending packet is written.
+func (c *ConcurrentNgWriter) release(all bool) error {
+	watermark, limited := c.watermark()
+	for len(c.pending) > 0 {
+		if !all && len(c.pending) <= c.maxPending && limited && c.pending[0].ci.Timestamp.After(watermark) {
+			break
+		}
+		p := heap.Pop(&c.pending).(ngPendingPacket)
+		if err := c.ng.WritePacketWithOptions(p.ci, p.data, p.options); err != nil {
+			c.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes all held back packets and flushes the underlying NgWriter.
+func (c *ConcurrentNgWriter) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	if c.ng == nil {
+		return nil
+	}
+	if err := c.release(true); err != nil {
+		return err
+	}
+	return c.ng.Flush()
+}
+
+// Close writes all held back packets and flushes the underlying NgWriter. The underlying writer is not closed.
+func (c *ConcurrentNgWriter) Close() error {
+	return c.Flush()
+}
+
+// ID returns the interface id in the file.
+func (iw *NgInterfaceWriter) ID() int {
+	return iw.id
+}
+
+// WritePacket writes the given packet with ci.InterfaceIndex set to the id of the interface. The data is copied.
+func (iw *NgInterfaceWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	return iw.WritePacketWithOptions(ci, data, NgPacketOptions{})
+}
+
+// WritePacketWithOptions is like WritePacket, but additionally writes the given packet options.
+func (iw *NgInterfaceWriter) WritePacketWithOptions(ci gopacket.CaptureInfo, data []byte, options NgPacketOptions) error {
+	if ci.CaptureLength != len(data) {
+		return errors.New("capture length must match data length")
+	}
+	ci.InterfaceIndex = iw.id
+	ci.AncillaryData = nil
+	if ci.Timestamp.IsZero() {
+		ci.Timestamp = time.Now()
+	}
+	p := ngPendingPacket{
+		ci:      ci,
+		data:    append([]byte(nil), data...),
+		options: options,
+	}
+	if len(options.Comments) > 0 {
+		p.options.Comments = append([]string(nil), options.Comments...)
+	}
+	if len(options.Hash) > 0 {
+		p.options.Hash = append([]byte(nil), options.Hash...)
+	}
+
+	c := iw.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	if _, ok := c.open[iw]; !ok {
+		return errors.New("Interface writer is closed")
+	}
+	if ci.Timestamp.After(iw.last) {
+		iw.last = ci.Timestamp
+	}
+	p.seq = c.seq
+	c.seq++
+	heap.Push(&c.pending, p)
+	return c.release(false)
+}
+
+// WriteInterfaceStats writes the given statistics for the interface. Statistics are written immediately and not reordered.
+func (iw *NgInterfaceWriter) WriteInterfaceStats(stats NgInterfaceStatistics) error {
+	c := iw.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	if err := c.ng.WriteInterfaceStats(iw.id, stats); err != nil {
+		c.err = err
+		return err
+	}
+	return nil
+}
+
+// Close marks the end of the packets of this interface, which releases packets held back for it. Close doesn't flush the ConcurrentNgWriter.
+func (iw *NgInterfaceWriter) Close() error {
+	c := iw.c
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.open, iw)
+	if c.err != nil {
+		return c.err
+	}
+	return c.release(false)
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestConcurrentNgWriter(t *testing.T) {
+	const goroutines = 4
+	const packets = 200
+
+	buffer := &bytes.Buffer{}
+	w := NewConcurrentNgWriter(buffer, DefaultNgWriterOptions, 0)
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		iw, err := w.Interface(NgInterface{
+			Name:     fmt.Sprintf("eth%d", g),
+			LinkType: layers.LinkTypeEthernet,
+		})
+		if err != nil {
+			t.Fatal("Couldn't add interface:", err)
+		}
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			defer iw.Close()
+			data := make([]byte, 60)
+			for i := 0; i < packets; i++ {
+				// the data buffer is reused on purpose
+				data[0] = byte(g)
+				ci := gopacket.CaptureInfo{
+					Timestamp:     time.Unix(int64(i*goroutines+g), 0).UTC(),
+					CaptureLength: len(data),
+					Length:        len(data),
+				}
+				if err := iw.WritePacket(ci, data); err != nil {
+					t.Error("Couldn't write packet:", err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal("Couldn't close writer:", err)
+	}
+
+	r, err := NewNgReader(buffer, NgReaderOptions{WantMixedLinkType: true})
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+	n := 0
+	for ; ; n++ {
+		data, ci, err := r.ReadPacketData()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("Couldn't read packet:", err)
+		}
+		if ci.Timestamp.Unix() != int64(n) {
+			t.Fatalf("Packet %d has timestamp %d", n, ci.Timestamp.Unix())
+		}
+		intf, err := r.Interface(ci.InterfaceIndex)
+		if err != nil {
+			t.Fatal("Couldn't get interface:", err)
+		}
+		if expected := fmt.Sprintf("eth%d", n%goroutines); intf.Name != expected || int(data[0]) != n%goroutines {
+			t.Fatalf("Packet %d is from interface %s, but should be from %s", n, intf.Name, expected)
+		}
+	}
+	if n != goroutines*packets {
+		t.Fatalf("Expected %d packets, but got %d", goroutines*packets, n)
+	}
+}
+
+func TestConcurrentNgWriterMaxPending(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	w := NewConcurrentNgWriter(buffer, DefaultNgWriterOptions, 2)
+	active, err := w.Interface(NgInterface{Name: "active", LinkType: layers.LinkTypeEthernet})
+	if err != nil {
+		t.Fatal("Couldn't add interface:", err)
+	}
+	// idle never writes, which holds back all packets up to the limit
+	if _, err := w.Interface(NgInterface{Name: "idle", LinkType: layers.LinkTypeEthernet}); err != nil {
+		t.Fatal("Couldn't add interface:", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("Couldn't flush:", err)
+	}
+	headerLength := buffer.Len()
+
+	data := make([]byte, 60)
+	for i := 0; i < 3; i++ {
+		ci := gopacket.CaptureInfo{
+			Timestamp:     time.Unix(int64(i), 0),
+			CaptureLength: len(data),
+			Length:        len(data),
+		}
+		if err := active.WritePacket(ci, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+	}
+	if err := w.ng.w.Flush(); err != nil {
+		t.Fatal("Couldn't flush:", err)
+	}
+	// 3 packets written, 2 held back
+	if written := buffer.Len() - headerLength; written == 0 || written >= 2*(len(data)+32) {
+		t.Errorf("Expected exactly one packet to be written, but got %d bytes", written)
+	}
+	if err := active.Close(); err != nil {
+		t.Fatal("Couldn't close interface writer:", err)
+	}
+	if err := active.WritePacket(gopacket.CaptureInfo{CaptureLength: len(data), Length: len(data)}, data); err == nil {
+		t.Error("Expected error writing to closed interface writer")
+	}
+}
@@ -33,6 +33,18 @@ type NgReaderOptions struct {
 	SectionEndCallback func([]NgInterface, NgSectionInfo)
 	// StatisticsCallback is called when a interface statistics block is read. The interface id and the read statistics are provided.
 	StatisticsCallback func(int, NgInterfaceStatistics)
+	// DecryptionSecretsCallback is called when a decryption secrets block is read. The type and the secrets are provided.
+	DecryptionSecretsCallback func(NgSecretsType, []byte)
+	// Recover enables reading damaged files. Before every block, the block length and the trailing block length are checked.
+	// If they don't match, or a section header has a wrong byte order magic, the input is scanned forward for the next
+	// plausible block and reading continues there. A truncated last block is skipped and reported as EOF.
+	// Blocks larger than 1 MiB are considered damaged in this mode.
+	Recover bool
+	// RecoverCallback is called with the offset and the length of every byte range skipped in Recover mode. The offset is
+	// relative to the start of the (uncompressed) input.
+	RecoverCallback func(offset, length int64)
+	// Filter selects the returned packets. Packets not matching the filter are skipped.
+	Filter ReadFilter
 }
 
 // DefaultNgReaderOptions provides sane defaults for a pcapng reader.
@@ -55,16 +67,42 @@ type NgReader struct {
 	firstSectionFound bool
 	activeSection     bool
 	bigEndian         bool
+	// mapped is set if reading from an uncompressed MappedFile
+	mapped *MappedFile
+	// counter counts the input bytes in Recover mode
+	counter *countingReader
+	// filter is set if packets are filtered
+	filter *readFilter
 }
 
 // NewNgReader initializes a new writer, reads the first section header, and if necessary according to the options the first interface.
+// Compressed input is transparently uncompressed (see RegisterDecompressor).
 func NewNgReader(r io.Reader, options NgReaderOptions) (*NgReader, error) {
+	br := bufio.NewReader(r)
+	uncompressed, err := decompress(br)
+	if err != nil {
+		return nil, err
+	}
+	var counter *countingReader
+	if options.Recover {
+		counter = &countingReader{r: uncompressed}
+		br = bufio.NewReaderSize(counter, ngRecoverMaxBlockLength)
+	} else if uncompressed != io.Reader(br) {
+		br = bufio.NewReader(uncompressed)
+	}
 	ret := &NgReader{
-		r: bufio.NewReader(r),
+		r: br,
 		currentOption: ngOption{
 			value: make([]byte, 1024),
 		},
 		options: options,
+		counter: counter,
+	}
+	if mf, ok := r.(*MappedFile); ok && uncompressed == io.Reader(br) {
+		ret.mapped = mf
+	}
+	if ret.filter, err = newReadFilter(options.Filter); err != nil {
+		return nil, err
 	}
 
 	//pcapng _must_ start with a section header
@@ -125,6 +163,11 @@ func (r *NgReader) getUint64(buffer []byte) uint64 {
 
 // readBlock reads a the blocktype and length from the file. If the type is a section header, endianess is also read.
 func (r *NgReader) readBlock() error {
+	if r.options.Recover {
+		if err := r.resync(); err != nil {
+			return err
+		}
+	}
 	if err := r.readBytes(r.buf[0:8]); err != nil {
 		return err
 	}
@@ -151,6 +194,91 @@ func (r *NgReader) readBlock() error {
 	return nil
 }
 
+// ngRecoverMaxBlockLength is the maximum block length in Recover mode and the size of the read buffer, since blocks are checked in the buffer
+const ngRecoverMaxBlockLength = 1 << 20
+
+// plausibleBlock returns true if the input continues with a complete block with matching block lengths. If known is
+// true, only known block types are accepted.
+func (r *NgReader) plausibleBlock(known bool) bool {
+	header, err := r.r.Peek(12)
+	if err != nil {
+		return false
+	}
+	typ := ngBlockType(r.getUint32(header[0:4]))
+	var byteOrder binary.ByteOrder = binary.LittleEndian
+	if r.bigEndian {
+		byteOrder = binary.BigEndian
+	}
+	switch typ {
+	case ngBlockTypeSectionHeader:
+		if binary.BigEndian.Uint32(header[8:12]) == ngByteOrderMagic {
+			byteOrder = binary.BigEndian
+		} else if binary.LittleEndian.Uint32(header[8:12]) == ngByteOrderMagic {
+			byteOrder = binary.LittleEndian
+		} else {
+			return false
+		}
+	case ngBlockTypeInterfaceDescriptor, ngBlockTypePacket, ngBlockTypeSimplePacket, ngBlockTypeInterfaceStatistics, ngBlockTypeEnhancedPacket, ngBlockTypeDecryptionSecrets:
+	default:
+		if known {
+			return false
+		}
+	}
+	length := byteOrder.Uint32(header[4:8])
+	if length < 12 || length%4 != 0 || length > ngRecoverMaxBlockLength {
+		return false
+	}
+	block, err := r.r.Peek(int(length))
+	if err != nil {
+		return false
+	}
+	return byteOrder.Uint32(block[length-4:]) == length
+}
+
+// resync skips damaged input up to the next plausible block in Recover mode and reports the skipped range.
+func (r *NgReader) resync() error {
+	if _, err := r.r.Peek(1); err != nil {
+		return err
+	}
+	if r.plausibleBlock(false) {
+		return nil
+	}
+	offset := r.counter.n - int64(r.r.Buffered())
+	var skipped int64
+	var err error
+	for {
+		if _, err = r.r.Discard(1); err != nil {
+			break
+		}
+		skipped++
+		if _, err = r.r.Peek(1); err != nil {
+			break
+		}
+		if r.plausibleBlock(true) {
+			break
+		}
+	}
+	if r.options.RecoverCallback != nil {
+		r.options.RecoverCallback(offset, skipped)
+	}
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return err
+}
+
+// skipDamagedBlock skips the rest of the current block and reports the whole block as skipped. consumed is the number of bytes of the block already read.
+func (r *NgReader) skipDamagedBlock(consumed int) error {
+	offset := r.counter.n - int64(r.r.Buffered()) - int64(consumed)
+	if _, err := r.r.Discard(int(r.currentBlock.length)); err != nil {
+		return err
+	}
+	if r.options.RecoverCallback != nil {
+		r.options.RecoverCallback(offset, int64(consumed)+int64(r.currentBlock.length))
+	}
+	return nil
+}
+
 // readOption reads a single arbitrary option (type and value). If there is no space left for options and end of options is missing, it is faked.
 func (r *NgReader) readOption() error {
 	if r.currentBlock.length == 4 {
@@ -314,6 +442,11 @@ func (r *NgReader) firstInterface() error {
 			return nil
 		case ngBlockTypePacket, ngBlockTypeEnhancedPacket, ngBlockTypeSimplePacket, ngBlockTypeInterfaceStatistics:
 			return errors.New("A section must have an interface before a packet block")
+		case ngBlockTypeDecryptionSecrets:
+			if err := r.readDecryptionSecrets(); err != nil {
+				return err
+			}
+			continue
 		}
 		if _, err := r.r.Discard(int(r.currentBlock.length)); err != nil {
 			return err
@@ -350,6 +483,11 @@ OPTIONS:
 			intf.Filter = string(r.currentOption.value[1:])
 		case ngOptionCodeInterfaceOS:
 			intf.OS = string(r.currentOption.value)
+		case ngOptionCodeInterfaceFCSLength:
+			if len(r.currentOption.value) >= 1 {
+				// stored in bits
+				intf.FCSLength = int(r.currentOption.value[0]) / 8
+			}
 		case ngOptionCodeInterfaceTimestampOffset:
 			intf.TimestampOffset = r.getUint64(r.currentOption.value[:8])
 		case ngOptionCodeInterfaceTimestampResolution:
@@ -437,6 +575,32 @@ OPTIONS:
 	return nil
 }
 
+// readDecryptionSecrets reads a decryption secrets block and passes the secrets to the DecryptionSecretsCallback
+func (r *NgReader) readDecryptionSecrets() error {
+	if r.options.DecryptionSecretsCallback == nil {
+		_, err := r.r.Discard(int(r.currentBlock.length))
+		return err
+	}
+	if err := r.readBytes(r.buf[:8]); err != nil {
+		return err
+	}
+	r.currentBlock.length -= 8
+	typ := NgSecretsType(r.getUint32(r.buf[:4]))
+	length := r.getUint32(r.buf[4:8])
+	if length > r.currentBlock.length {
+		return fmt.Errorf("Decryption secrets length %d exceeds block length %d", length, r.currentBlock.length)
+	}
+	secrets := make([]byte, length)
+	if err := r.readBytes(secrets); err != nil {
+		return err
+	}
+	if _, err := r.r.Discard(int(r.currentBlock.length - length)); err != nil {
+		return err
+	}
+	r.options.DecryptionSecretsCallback(typ, secrets)
+	return nil
+}
+
 // readPacketHeader looks for a packet (enhanced, simple, or packet) and parses the header.
 // If an interface descriptor, an interface statistics block, or a section header is encountered, those are handled accordingly.
 // All other block types are skipped. New block types must be added here.
@@ -448,20 +612,44 @@ FIND_PACKET:
 			return err
 		}
 		switch r.currentBlock.typ {
-		case ngBlockTypeEnhancedPacket:
+		case ngBlockTypeEnhancedPacket, ngBlockTypePacket:
+			if r.options.Recover && r.currentBlock.length < 20+4 {
+				if err := r.skipDamagedBlock(8); err != nil {
+					return err
+				}
+				continue
+			}
 			if err := r.readBytes(r.buf[:20]); err != nil {
 				return err
 			}
 			r.currentBlock.length -= 20
-			r.ci.InterfaceIndex = int(r.getUint32(r.buf[:4]))
+			if r.currentBlock.typ == ngBlockTypeEnhancedPacket {
+				r.ci.InterfaceIndex = int(r.getUint32(r.buf[:4]))
+			} else {
+				// the obsolete packet block has a 16 bit interface id followed by the drops count
+				r.ci.InterfaceIndex = int(r.getUint16(r.buf[0:2]))
+			}
+			r.ci.CaptureLength = int(r.getUint32(r.buf[12:16]))
+			if r.options.Recover && (r.ci.InterfaceIndex >= len(r.ifaces) || uint32(r.ci.CaptureLength) > r.currentBlock.length-4) {
+				// the packet data has to fit into the block before the trailing block length
+				if err := r.skipDamagedBlock(8 + 20); err != nil {
+					return err
+				}
+				continue
+			}
 			if r.ci.InterfaceIndex >= len(r.ifaces) {
 				return fmt.Errorf("Interface id %d not present in section (have only %d interfaces)", r.ci.InterfaceIndex, len(r.ifaces))
 			}
 			r.ci.Timestamp = time.Unix(r.convertTime(r.ci.InterfaceIndex, uint64(r.getUint32(r.buf[4:8]))<<32|uint64(r.getUint32(r.buf[8:12])))).UTC()
-			r.ci.CaptureLength = int(r.getUint32(r.buf[12:16]))
 			r.ci.Length = int(r.getUint32(r.buf[16:20]))
 			break FIND_PACKET
 		case ngBlockTypeSimplePacket:
+			if r.options.Recover && r.currentBlock.length < 4+4 {
+				if err := r.skipDamagedBlock(8); err != nil {
+					return err
+				}
+				continue
+			}
 			if err := r.readBytes(r.buf[:4]); err != nil {
 				return err
 			}
@@ -476,6 +664,10 @@ FIND_PACKET:
 			if r.ifaces[0].SnapLength != 0 && uint32(r.ci.CaptureLength) > r.ifaces[0].SnapLength {
 				r.ci.CaptureLength = int(r.ifaces[0].SnapLength)
 			}
+			if r.options.Recover && uint32(r.ci.CaptureLength) > r.currentBlock.length-4 {
+				// the packet data is the block body before the trailing block length
+				r.ci.CaptureLength = int(r.currentBlock.length - 4)
+			}
 			break FIND_PACKET
 		case ngBlockTypeInterfaceDescriptor:
 			if err := r.readInterfaceDescriptor(); err != nil {
@@ -485,29 +677,23 @@ FIND_PACKET:
 			if err := r.readInterfaceStatistics(); err != nil {
 				return err
 			}
-		case ngBlockTypeSectionHeader:
-			if err := r.readSectionHeader(); err != nil {
+		case ngBlockTypeDecryptionSecrets:
+			if err := r.readDecryptionSecrets(); err != nil {
 				return err
 			}
-		case ngBlockTypePacket:
-			if err := r.readBytes(r.buf[:20]); err != nil {
+		case ngBlockTypeSectionHeader:
+			if err := r.readSectionHeader(); err != nil {
 				return err
 			}
-			r.currentBlock.length -= 20
-			r.ci.InterfaceIndex = int(r.getUint16(r.buf[0:2]))
-			if r.ci.InterfaceIndex >= len(r.ifaces) {
-				return fmt.Errorf("Interface id %d not present in section (have only %d interfaces)", r.ci.InterfaceIndex, len(r.ifaces))
-			}
-			r.ci.Timestamp = time.Unix(r.convertTime(r.ci.InterfaceIndex, uint64(r.getUint32(r.buf[4:8]))<<32|uint64(r.getUint32(r.buf[8:12])))).UTC()
-			r.ci.CaptureLength = int(r.getUint32(r.buf[12:16]))
-			r.ci.Length = int(r.getUint32(r.buf[16:20]))
-			break FIND_PACKET
 		default:
 			if _, err := r.r.Discard(int(r.currentBlock.length)); err != nil {
 				return err
 			}
 		}
 	}
+	r.ci.InterfaceName = r.ifaces[r.ci.InterfaceIndex].Name
+	r.ci.Direction = gopacket.PacketDirectionUnknown
+	r.ci.RxQueue, r.ci.HasRxQueue = 0, false
 	if !r.options.WantMixedLinkType {
 		if r.ifaces[r.ci.InterfaceIndex].LinkType != r.linkType {
 			if _, err := r.r.Discard(int(r.currentBlock.length)); err != nil {
@@ -518,59 +704,191 @@ FIND_PACKET:
 			}
 			goto RESTART
 		}
-		return nil
+	} else {
+		r.ancil[0] = r.ifaces[r.ci.InterfaceIndex].LinkType
+	}
+	if r.filter != nil {
+		match, done := r.filter.matchTime(r.ci.Timestamp)
+		if done {
+			return io.EOF
+		}
+		if !match {
+			if _, err := r.r.Discard(int(r.currentBlock.length)); err != nil {
+				return err
+			}
+			goto RESTART
+		}
 	}
-	r.ancil[0] = r.ifaces[r.ci.InterfaceIndex].LinkType
 	return nil
 }
 
+// matchData returns true if the packet data of the current block matches the BPF program of the filter
+func (r *NgReader) matchData(data []byte) (bool, error) {
+	if r.filter == nil {
+		return true, nil
+	}
+	return r.filter.matchData(data)
+}
+
 // ReadPacketData returns the next packet available from this data source.
 // If WantMixedLinkType is true, ci.AncillaryData[0] contains the link type.
+// ci.Direction is set from the epb_flags option and ci.RxQueue from the epb_queue option.
 func (r *NgReader) ReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
-	if err = r.readPacketHeader(); err != nil {
-		return
+	for {
+		if err = r.readPacketHeader(); err != nil {
+			return
+		}
+		data = make([]byte, r.ci.CaptureLength)
+		if err = r.readBytes(data); err != nil {
+			return
+		}
+		if err = r.readPacketOptions(nil); err != nil {
+			return
+		}
+		var match bool
+		if match, err = r.matchData(data); err != nil || match {
+			break
+		}
 	}
 	ci = r.ci
 	if r.options.WantMixedLinkType {
 		ci.AncillaryData = make([]interface{}, 1)
 		ci.AncillaryData[0] = r.ancil[0]
 	}
-	data = make([]byte, r.ci.CaptureLength)
-	if err = r.readBytes(data); err != nil {
+	return
+}
+
+// ReadPacketDataWithOptions returns the next packet available from this data source together with the options of the packet block.
+// If WantMixedLinkType is true, ci.AncillaryData[0] contains the link type.
+// The options are parsed by every read method, since the direction and queue of the capture info come from them; this method
+// additionally returns the comments, flags, drop count and hash.
+func (r *NgReader) ReadPacketDataWithOptions() (data []byte, ci gopacket.CaptureInfo, options NgPacketOptions, err error) {
+	for {
+		if err = r.readPacketHeader(); err != nil {
+			return
+		}
+		data = make([]byte, r.ci.CaptureLength)
+		if err = r.readBytes(data); err != nil {
+			return
+		}
+		var match bool
+		if match, err = r.matchData(data); err != nil {
+			return
+		} else if match {
+			break
+		}
+		if _, err = r.r.Discard(int(r.currentBlock.length) - r.ci.CaptureLength); err != nil {
+			return
+		}
+	}
+	if err = r.readPacketOptions(&options); err != nil {
 		return
 	}
-	// handle options somehow - this would be expensive
-	_, err = r.r.Discard(int(r.currentBlock.length) - r.ci.CaptureLength)
+	ci = r.ci
+	if r.options.WantMixedLinkType {
+		ci.AncillaryData = make([]interface{}, 1)
+		ci.AncillaryData[0] = r.ancil[0]
+	}
 	return
 }
 
+// readPacketOptions parses the options of the current packet block into options and skips the rest of the block. The packet data must already be consumed.
+// The direction of the epb_flags option and the epb_queue option are stored in the capture info of the reader, even if options is nil.
+func (r *NgReader) readPacketOptions(options *NgPacketOptions) error {
+	length := uint32(r.ci.CaptureLength)
+	length += (4 - length&3) & 3 // padding
+	if r.currentBlock.typ == ngBlockTypeSimplePacket || r.currentBlock.length < length+4 {
+		// simple packet blocks don't have options
+		_, err := r.r.Discard(int(r.currentBlock.length) - r.ci.CaptureLength)
+		return err
+	}
+	if _, err := r.r.Discard(int(length) - r.ci.CaptureLength); err != nil {
+		return err
+	}
+	r.currentBlock.length -= length
+
+OPTIONS:
+	for {
+		if err := r.readOption(); err != nil {
+			return err
+		}
+		switch r.currentOption.code {
+		case ngOptionCodeEndOfOptions:
+			break OPTIONS
+		case ngOptionCodeComment:
+			if options != nil {
+				options.Comments = append(options.Comments, string(r.currentOption.value))
+			}
+		case ngOptionCodeEnhancedPacketFlags:
+			if len(r.currentOption.value) >= 4 {
+				flags := NgPacketFlags(r.getUint32(r.currentOption.value[:4]))
+				r.ci.Direction = flags.Direction().PacketDirection()
+				if options != nil {
+					options.Flags = flags
+				}
+			}
+		case ngOptionCodeEnhancedPacketHash:
+			if options != nil {
+				options.Hash = append([]byte(nil), r.currentOption.value...)
+			}
+		case ngOptionCodeEnhancedPacketDropCount:
+			if len(r.currentOption.value) >= 8 && options != nil {
+				options.DropCount = r.getUint64(r.currentOption.value[:8])
+			}
+		case ngOptionCodeEnhancedPacketQueue:
+			if len(r.currentOption.value) >= 4 {
+				r.ci.RxQueue = int(r.getUint32(r.currentOption.value[:4]))
+				r.ci.HasRxQueue = true
+			}
+		}
+	}
+	_, err := r.r.Discard(int(r.currentBlock.length))
+	return err
+}
+
 // ZeroCopyReadPacketData returns the next packet available from this data source.
 // If WantMixedLinkType is true, ci.AncillaryData[0] contains the link type.
+// ci.Direction is set from the epb_flags option and ci.RxQueue from the epb_queue option.
 // Warning: Like data, ci.AncillaryData is also reused and overwritten on the next call to ZeroCopyReadPacketData.
 //
 // It is not true zero copy, as data is still copied from the underlying reader. However,
-// this method avoids allocating heap memory for every packet.
+// this method avoids allocating heap memory for every packet. If the underlying reader is a
+// MappedFile, data points into the mapping and stays valid until the MappedFile is closed.
 func (r *NgReader) ZeroCopyReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
-	if err = r.readPacketHeader(); err != nil {
-		return
+	for {
+		if err = r.readPacketHeader(); err != nil {
+			return
+		}
+		ci = r.ci
+		if r.mapped != nil {
+			if data, err = r.mapped.next(ci.CaptureLength, r.r); err != nil {
+				return
+			}
+		} else {
+			if cap(r.packetBuf) < ci.CaptureLength {
+				snaplen := int(r.ifaces[ci.InterfaceIndex].SnapLength)
+				if snaplen < ci.CaptureLength {
+					snaplen = ci.CaptureLength
+				}
+				r.packetBuf = make([]byte, snaplen)
+			}
+			data = r.packetBuf[:ci.CaptureLength]
+			if err = r.readBytes(data); err != nil {
+				return
+			}
+		}
+		if err = r.readPacketOptions(nil); err != nil {
+			return
+		}
+		ci = r.ci
+		var match bool
+		if match, err = r.matchData(data); err != nil || match {
+			break
+		}
 	}
-	ci = r.ci
 	if r.options.WantMixedLinkType {
 		ci.AncillaryData = r.ancil[:]
 	}
-	if cap(r.packetBuf) < ci.CaptureLength {
-		snaplen := int(r.ifaces[ci.InterfaceIndex].SnapLength)
-		if snaplen < ci.CaptureLength {
-			snaplen = ci.CaptureLength
-		}
-		r.packetBuf = make([]byte, snaplen)
-	}
-	data = r.packetBuf[:ci.CaptureLength]
-	if err = r.readBytes(data); err != nil {
-		return
-	}
-	// handle options somehow - this would be expensive
-	_, err = r.r.Discard(int(r.currentBlock.length) - ci.CaptureLength)
 	return
 }
 
@@ -9,6 +9,7 @@ package pcapgo
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"io"
 	"log"
@@ -177,6 +178,8 @@ func ngRunFileReadTest(test ngFileReadTest, be string, zerocopy bool, t *testing
 			t.Fatalf("[packet %d] data mismatch", i)
 		}
 
+		// the interface names are checked by TestNgCaptureInfoFields
+		ci.InterfaceName = ""
 		if !reflect.DeepEqual(ci, packet.ci) {
 			t.Fatalf("[packet %d] ci mismatch:\ngot:\n%#v\nwant:\n%#v\n\n", i, ci, packet.ci)
 		}
@@ -1842,6 +1845,49 @@ func TestNgFileReadLEZero(t *testing.T) {
 	}
 }
 
+func TestNgReadPacketOptions(t *testing.T) {
+	for _, be := range []string{"be", "le"} {
+		f, err := os.Open(filepath.Join("tests", be, "test009.pcapng"))
+		if err != nil {
+			t.Fatal("Couldn't open file:", err)
+		}
+		defer f.Close()
+		r, err := NewNgReader(f, DefaultNgReaderOptions)
+		if err != nil {
+			t.Fatal("Couldn't read start of file:", err)
+		}
+		want := []NgPacketOptions{
+			{Comments: []string{"test009-1"}},
+			{Comments: []string{"test009-2"}, Flags: 0x48000000, DropCount: 12345},
+		}
+		for i := range want {
+			_, _, options, err := r.ReadPacketDataWithOptions()
+			if err != nil {
+				t.Fatalf("[%s packet %d] Couldn't read packet: %s", be, i, err)
+			}
+			if !reflect.DeepEqual(options, want[i]) {
+				t.Fatalf("[%s packet %d] options mismatch:\ngot:\n%#v\nwant:\n%#v\n\n", be, i, options, want[i])
+			}
+		}
+	}
+}
+
+func TestNgPacketFlags(t *testing.T) {
+	flags := NewNgPacketFlags(NgPacketDirectionInbound, NgReceptionTypePromiscuous, 4)
+	if flags.Direction() != NgPacketDirectionInbound {
+		t.Errorf("Expected direction %s, but got %s", NgPacketDirectionInbound, flags.Direction())
+	}
+	if flags.ReceptionType() != NgReceptionTypePromiscuous {
+		t.Errorf("Expected reception type %d, but got %d", NgReceptionTypePromiscuous, flags.ReceptionType())
+	}
+	if flags.FCSLength() != 4 {
+		t.Errorf("Expected FCS length 4, but got %d", flags.FCSLength())
+	}
+	if flags.LinkLayerErrors() != 0 {
+		t.Errorf("Expected no link-layer errors, but got %x", flags.LinkLayerErrors())
+	}
+}
+
 type endlessNgPacketReader struct {
 	packet []byte
 }
@@ -1905,6 +1951,116 @@ func setupNgReadBenchmark(b *testing.B) *NgReader {
 	return r
 }
 
+// ngRecoverFile writes 10 packets and returns the file and the offsets of the packet blocks
+func ngRecoverFile(t *testing.T) ([]byte, []int) {
+	buffer := &bytes.Buffer{}
+	w, err := NewNgWriter(buffer, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal("Couldn't create writer:", err)
+	}
+	var offsets []int
+	for i := 0; i < 10; i++ {
+		if err := w.Flush(); err != nil {
+			t.Fatal("Couldn't flush:", err)
+		}
+		offsets = append(offsets, buffer.Len())
+		data := ngPacketSource[i%len(ngPacketSource)]
+		ci := gopacket.CaptureInfo{
+			Timestamp:     time.Unix(int64(i), 0).UTC(),
+			CaptureLength: len(data),
+			Length:        len(data),
+		}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("Couldn't flush:", err)
+	}
+	return buffer.Bytes(), offsets
+}
+
+func TestNgReadRecover(t *testing.T) {
+	contents, offsets := ngRecoverFile(t)
+	tests := []struct {
+		name     string
+		damage   func([]byte) []byte
+		expected []int64 // expected timestamps (seconds)
+		skipped  int
+	}{
+		{"undamaged", func(b []byte) []byte { return b }, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 0},
+		{"block length", func(b []byte) []byte {
+			b[offsets[3]+4] ^= 0x55
+			return b
+		}, []int64{0, 1, 2, 4, 5, 6, 7, 8, 9}, 1},
+		{"captured length", func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[offsets[4]+20:], 0x7ffffff0)
+			return b
+		}, []int64{0, 1, 2, 3, 5, 6, 7, 8, 9}, 1},
+		{"garbage", func(b []byte) []byte {
+			garbage := []byte{1, 2, 3, 4, 5, 6, 7}
+			return append(append(append([]byte(nil), b[:offsets[5]]...), garbage...), b[offsets[5]:]...)
+		}, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, 1},
+		{"truncated", func(b []byte) []byte {
+			return b[:offsets[9]+10]
+		}, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8}, 1},
+		{"multiple", func(b []byte) []byte {
+			b[offsets[1]+5] ^= 0x55
+			b[offsets[7]+8] = 0xff  // interface id of block 7
+			b[offsets[7]-1] ^= 0x55 // trailing length of block 6
+			return b
+		}, []int64{0, 2, 3, 4, 5, 8, 9}, 3},
+	}
+	for _, test := range tests {
+		damaged := test.damage(append([]byte(nil), contents...))
+		var skipped []int64
+		r, err := NewNgReader(bytes.NewReader(damaged), NgReaderOptions{
+			Recover: true,
+			RecoverCallback: func(offset, length int64) {
+				if length <= 0 {
+					t.Errorf("%s: invalid skipped range %d+%d", test.name, offset, length)
+				}
+				skipped = append(skipped, offset)
+			},
+		})
+		if err != nil {
+			t.Fatalf("%s: Couldn't create reader: %v", test.name, err)
+		}
+		var timestamps []int64
+		for {
+			_, ci, err := r.ReadPacketData()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: Couldn't read packet: %v", test.name, err)
+			}
+			timestamps = append(timestamps, ci.Timestamp.Unix())
+		}
+		if !reflect.DeepEqual(timestamps, test.expected) {
+			t.Errorf("%s: expected packets %v, but got %v", test.name, test.expected, timestamps)
+		}
+		if len(skipped) != test.skipped {
+			t.Errorf("%s: expected %d skipped ranges, but got %v", test.name, test.skipped, skipped)
+		}
+	}
+
+	// without Recover, the damaged file is not readable past the damage
+	contents[offsets[3]+4] ^= 0x55
+	r, err := NewNgReader(bytes.NewReader(contents), DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal("Couldn't create reader:", err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, _, err = r.ReadPacketData(); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		t.Error("Expected error reading damaged file")
+	}
+}
+
 func BenchmarkNgReadPacketData(b *testing.B) {
 	r := setupNgReadBenchmark(b)
 	b.ResetTimer()
@@ -11,6 +11,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"runtime"
 	"time"
 
@@ -47,6 +48,10 @@ type NgWriter struct {
 	options NgWriterOptions
 	intf    uint32
 	buf     [28]byte
+	// packetOptions is reused by WritePacketWithOptions to avoid allocating for every packet
+	packetOptions []ngOption
+	// frames is set if the underlying writer is a CompressedWriter
+	frames *CompressedWriter
 }
 
 // NewNgWriter initializes and returns a new writer. Additionally, one section and one interface (without statistics) is written to the file. Interface and section options are used from DefaultNgInterface and DefaultNgWriterOptions.
@@ -68,6 +73,7 @@ func NewNgWriterInterface(w io.Writer, intf NgInterface, options NgWriterOptions
 		w:       bufio.NewWriter(w),
 		options: options,
 	}
+	ret.frames, _ = w.(*CompressedWriter)
 	if err := ret.writeSectionHeader(); err != nil {
 		return nil, err
 	}
@@ -99,10 +105,14 @@ func ngOptionLength(option ngOption) int {
 }
 
 // prepareNgOptions fills out the length value of the given options and returns the number of octets needed for all the given options including padding.
-func prepareNgOptions(options []ngOption) uint32 {
+// An error is returned if a value is too long for the 16 bit length of an option.
+func prepareNgOptions(options []ngOption) (uint32, error) {
 	var ret uint32
 	for i, option := range options {
 		length := ngOptionLength(option)
+		if length > math.MaxUint16 {
+			return 0, fmt.Errorf("pcapng option %d value of %d bytes is longer than %d bytes", option.code, length, math.MaxUint16)
+		}
 		options[i].length = uint16(length)
 		length += (4-length&3)&3 + // padding
 			4 //header
@@ -111,7 +121,7 @@ func prepareNgOptions(options []ngOption) uint32 {
 	if ret > 0 {
 		ret += 4 // end of options
 	}
-	return ret
+	return ret, nil
 }
 
 // writeOptions writes the given options to the file. prepareOptions must be called beforehand.
@@ -210,8 +220,11 @@ func (w *NgWriter) writeSectionHeader() error {
 	}
 	options := scratch[:i]
 
-	length := prepareNgOptions(options) +
-		24 + // header
+	length, err := prepareNgOptions(options)
+	if err != nil {
+		return err
+	}
+	length += 24 + // header
 		4 // trailer
 
 	binary.LittleEndian.PutUint32(w.buf[:4], uint32(ngBlockTypeSectionHeader))
@@ -229,16 +242,28 @@ func (w *NgWriter) writeSectionHeader() error {
 	}
 
 	binary.LittleEndian.PutUint32(w.buf[0:4], length)
-	_, err := w.w.Write(w.buf[:4])
-	return err
+	if _, err := w.w.Write(w.buf[:4]); err != nil {
+		return err
+	}
+	return w.endBlock()
+}
+
+// endBlock finishes the current compressed frame if necessary. Must be called after every block.
+func (w *NgWriter) endBlock() error {
+	if w.frames == nil || !w.frames.frameFull(w.w.Buffered()) {
+		return nil
+	}
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	return w.frames.Flush()
 }
 
 // AddInterface adds the specified interface to the file, excluding statistics. Interface timestamp resolution is fixed to 9 (to match time.Time). Empty values are not written.
 func (w *NgWriter) AddInterface(intf NgInterface) (id int, err error) {
 	id = int(w.intf)
-	w.intf++
 
-	var scratch [7]ngOption
+	var scratch [8]ngOption
 	i := 0
 	if intf.Name != "" {
 		scratch[i].code = ngOptionCodeInterfaceName
@@ -265,6 +290,11 @@ func (w *NgWriter) AddInterface(intf NgInterface) (id int, err error) {
 		scratch[i].raw = intf.OS
 		i++
 	}
+	if intf.FCSLength > 0 {
+		scratch[i].code = ngOptionCodeInterfaceFCSLength
+		scratch[i].raw = uint8(intf.FCSLength * 8) // in bits
+		i++
+	}
 	if intf.TimestampOffset != 0 {
 		scratch[i].code = ngOptionCodeInterfaceTimestampOffset
 		scratch[i].raw = intf.TimestampOffset
@@ -275,8 +305,12 @@ func (w *NgWriter) AddInterface(intf NgInterface) (id int, err error) {
 	i++
 	options := scratch[:i]
 
-	length := prepareNgOptions(options) +
-		16 + // header
+	length, err := prepareNgOptions(options)
+	if err != nil {
+		return 0, err
+	}
+	w.intf++
+	length += 16 + // header
 		4 // trailer
 
 	binary.LittleEndian.PutUint32(w.buf[:4], uint32(ngBlockTypeInterfaceDescriptor))
@@ -293,8 +327,10 @@ func (w *NgWriter) AddInterface(intf NgInterface) (id int, err error) {
 	}
 
 	binary.LittleEndian.PutUint32(w.buf[0:4], length)
-	_, err = w.w.Write(w.buf[:4])
-	return id, err
+	if _, err = w.w.Write(w.buf[:4]); err != nil {
+		return id, err
+	}
+	return id, w.endBlock()
 }
 
 // WriteInterfaceStats writes the given interface statistics for the given interface id to the file. Empty values are not written.
@@ -327,7 +363,11 @@ func (w *NgWriter) WriteInterfaceStats(intf int, stats NgInterfaceStatistics) er
 	}
 	options := scratch[:i]
 
-	length := prepareNgOptions(options) + 24
+	length, err := prepareNgOptions(options)
+	if err != nil {
+		return err
+	}
+	length += 24
 
 	ts := stats.LastUpdate.UnixNano()
 	if stats.LastUpdate.IsZero() {
@@ -348,12 +388,48 @@ func (w *NgWriter) WriteInterfaceStats(intf int, stats NgInterfaceStatistics) er
 	}
 
 	binary.LittleEndian.PutUint32(w.buf[0:4], length)
-	_, err := w.w.Write(w.buf[:4])
-	return err
+	if _, err := w.w.Write(w.buf[:4]); err != nil {
+		return err
+	}
+	return w.endBlock()
+}
+
+// WriteDecryptionSecrets writes a decryption secrets block with the given secrets to the file. Readers like Wireshark
+// use the secrets to decrypt the packets of the section, e.g. TLS sessions with secrets of type NgSecretsTypeTLSKeyLog.
+func (w *NgWriter) WriteDecryptionSecrets(typ NgSecretsType, secrets []byte) error {
+	padding := (4 - len(secrets)&3) & 3
+	length := uint32(len(secrets)+padding) +
+		16 + // header
+		4 // trailer
+
+	binary.LittleEndian.PutUint32(w.buf[:4], uint32(ngBlockTypeDecryptionSecrets))
+	binary.LittleEndian.PutUint32(w.buf[4:8], length)
+	binary.LittleEndian.PutUint32(w.buf[8:12], uint32(typ))
+	binary.LittleEndian.PutUint32(w.buf[12:16], uint32(len(secrets)))
+	if _, err := w.w.Write(w.buf[:16]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(secrets); err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint32(w.buf[0:4], 0)
+	binary.LittleEndian.PutUint32(w.buf[padding:padding+4], length)
+	if _, err := w.w.Write(w.buf[:padding+4]); err != nil {
+		return err
+	}
+	return w.endBlock()
 }
 
 // WritePacket writes out packet with the given data and capture info. The given InterfaceIndex must already be added to the file. InterfaceIndex 0 is automatically added by the NewWriter* methods.
 func (w *NgWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	return w.WritePacketWithOptions(ci, data, NgPacketOptions{})
+}
+
+// WritePacketWithOptions writes out packet with the given data, capture info, and packet options. The given InterfaceIndex must already be added to the file. InterfaceIndex 0 is automatically added by the NewWriter* methods.
+// Empty values in options are not written.
+// If options.Flags is 0, ci.Direction is written as the direction of the packet flags. ci.RxQueue is written if ci.HasRxQueue is true.
+func (w *NgWriter) WritePacketWithOptions(ci gopacket.CaptureInfo, data []byte, options NgPacketOptions) error {
 	if ci.InterfaceIndex >= int(w.intf) || ci.InterfaceIndex < 0 {
 		return fmt.Errorf("Can't send statistics for non existent interface %d; have only %d interfaces", ci.InterfaceIndex, w.intf)
 	}
@@ -364,9 +440,49 @@ func (w *NgWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
 		return fmt.Errorf("invalid capture info %+v:  capture length > length", ci)
 	}
 
+	w.packetOptions = w.packetOptions[:0]
+	for _, comment := range options.Comments {
+		w.packetOptions = append(w.packetOptions, ngOption{
+			code: ngOptionCodeComment,
+			raw:  comment,
+		})
+	}
+	flags := options.Flags
+	if flags == 0 {
+		flags = NewNgPacketFlags(ngPacketDirection(ci.Direction), NgReceptionTypeUnspecified, 0)
+	}
+	if flags != 0 {
+		w.packetOptions = append(w.packetOptions, ngOption{
+			code: ngOptionCodeEnhancedPacketFlags,
+			raw:  uint32(flags),
+		})
+	}
+	if len(options.Hash) > 0 {
+		w.packetOptions = append(w.packetOptions, ngOption{
+			code: ngOptionCodeEnhancedPacketHash,
+			raw:  options.Hash,
+		})
+	}
+	if options.DropCount != 0 {
+		w.packetOptions = append(w.packetOptions, ngOption{
+			code: ngOptionCodeEnhancedPacketDropCount,
+			raw:  options.DropCount,
+		})
+	}
+	if ci.HasRxQueue {
+		w.packetOptions = append(w.packetOptions, ngOption{
+			code: ngOptionCodeEnhancedPacketQueue,
+			raw:  uint32(ci.RxQueue),
+		})
+	}
+
+	optionsLength, err := prepareNgOptions(w.packetOptions)
+	if err != nil {
+		return err
+	}
 	length := uint32(len(data)) + 32
 	padding := (4 - length&3) & 3
-	length += padding
+	length += padding + optionsLength
 
 	ts := ci.Timestamp.UnixNano()
 
@@ -386,12 +502,38 @@ func (w *NgWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
 		return err
 	}
 
+	if len(w.packetOptions) == 0 {
+		binary.LittleEndian.PutUint32(w.buf[:4], 0)
+		if _, err := w.w.Write(w.buf[4-padding : 8]); err != nil { // padding + length
+			return err
+		}
+		return w.endBlock()
+	}
+
 	binary.LittleEndian.PutUint32(w.buf[:4], 0)
-	_, err := w.w.Write(w.buf[4-padding : 8]) // padding + length
-	return err
+	if _, err := w.w.Write(w.buf[:padding]); err != nil {
+		return err
+	}
+
+	if err := w.writeOptions(w.packetOptions); err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint32(w.buf[:4], length)
+	if _, err := w.w.Write(w.buf[:4]); err != nil {
+		return err
+	}
+	return w.endBlock()
 }
 
 // Flush writes out buffered data to the storage media. Must be called before closing the underlying file.
+// If the underlying writer is a CompressedWriter, the current frame is finished.
 func (w *NgWriter) Flush() error {
-	return w.w.Flush()
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	if w.frames != nil {
+		return w.frames.Flush()
+	}
+	return nil
 }
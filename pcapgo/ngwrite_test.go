@@ -8,6 +8,9 @@ package pcapgo
 
 import (
 	"bytes"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -214,6 +217,171 @@ func TestNgWriteComplex(t *testing.T) {
 	ngRunFileReadTest(test, "", false, t)
 }
 
+func TestNgWritePacketOptions(t *testing.T) {
+	buffer := &bytes.Buffer{}
+
+	w, err := NewNgWriter(buffer, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal("Opening file failed with: ", err)
+	}
+	packets := []struct {
+		data    []byte
+		options NgPacketOptions
+	}{
+		{ngPacketSource[0], NgPacketOptions{Comments: []string{"alert: rule 1"}}},
+		{ngPacketSource[4], NgPacketOptions{}},
+		{ngPacketSource[1][:97], NgPacketOptions{Comments: []string{"a", "second comment"}}},
+		{ngPacketSource[2], NgPacketOptions{
+			Flags:     NewNgPacketFlags(NgPacketDirectionOutbound, NgReceptionTypeUnicast, 4),
+			DropCount: 42,
+			Hash:      []byte{2, 0xde, 0xad, 0xbe, 0xef},
+		}},
+	}
+	for i, packet := range packets {
+		ci := gopacket.CaptureInfo{
+			Timestamp:     time.Unix(int64(i), 0).UTC(),
+			Length:        len(packet.data),
+			CaptureLength: len(packet.data),
+		}
+		if err := w.WritePacketWithOptions(ci, packet.data, packet.options); err != nil {
+			t.Fatal("Couldn't write packet", err)
+		}
+	}
+	// a comment too long for the option length isn't written
+	ci := gopacket.CaptureInfo{Length: len(ngPacketSource[0]), CaptureLength: len(ngPacketSource[0])}
+	long := NgPacketOptions{Comments: []string{strings.Repeat("x", 0x10000)}}
+	if err := w.WritePacketWithOptions(ci, ngPacketSource[0], long); err == nil {
+		t.Fatal("No error for comment longer than 0xffff bytes")
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("Couldn't flush buffer", err)
+	}
+
+	r, err := NewNgReader(bytes.NewReader(buffer.Bytes()), DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal("Couldn't read start of file:", err)
+	}
+	for i, packet := range packets {
+		data, ci, options, err := r.ReadPacketDataWithOptions()
+		if err != nil {
+			t.Fatalf("[packet %d] Couldn't read packet: %s", i, err)
+		}
+		if !bytes.Equal(data, packet.data) {
+			t.Fatalf("[packet %d] data mismatch", i)
+		}
+		if ci.Timestamp != time.Unix(int64(i), 0).UTC() {
+			t.Fatalf("[packet %d] timestamp mismatch: %s", i, ci.Timestamp)
+		}
+		if !reflect.DeepEqual(options, packet.options) {
+			t.Fatalf("[packet %d] options mismatch:\ngot:\n%#v\nwant:\n%#v\n\n", i, options, packet.options)
+		}
+		if ci.AncillaryData != nil {
+			t.Fatalf("[packet %d] unexpected ancillary data: %v", i, ci.AncillaryData)
+		}
+	}
+	if _, _, _, err := r.ReadPacketDataWithOptions(); err != io.EOF {
+		t.Fatal("Expected EOF, but got", err)
+	}
+}
+
+func TestNgCaptureInfoFields(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	intf := DefaultNgInterface
+	intf.Name = "eth1"
+	w, err := NewNgWriterInterface(buffer, intf, DefaultNgWriterOptions)
+	if err != nil {
+		t.Fatal("Opening file failed with: ", err)
+	}
+	data := []byte{1, 2, 3, 4}
+	cis := []gopacket.CaptureInfo{
+		{Direction: gopacket.PacketDirectionInbound, RxQueue: 3, HasRxQueue: true},
+		{Direction: gopacket.PacketDirectionOutbound},
+		{RxQueue: 0, HasRxQueue: true},
+		{},
+	}
+	for i := range cis {
+		cis[i].Timestamp = time.Unix(int64(i), 0).UTC()
+		cis[i].CaptureLength = len(data)
+		cis[i].Length = len(data)
+		cis[i].InterfaceName = "eth1"
+		if err := w.WritePacket(cis[i], data); err != nil {
+			t.Fatal("Couldn't write packet", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("Couldn't flush buffer", err)
+	}
+
+	// every read path returns the fields stored in packet options
+	for name, read := range map[string]func(r *NgReader) (gopacket.CaptureInfo, error){
+		"ReadPacketDataWithOptions": func(r *NgReader) (gopacket.CaptureInfo, error) {
+			_, ci, _, err := r.ReadPacketDataWithOptions()
+			return ci, err
+		},
+		"ReadPacketData": func(r *NgReader) (gopacket.CaptureInfo, error) {
+			_, ci, err := r.ReadPacketData()
+			return ci, err
+		},
+		"ZeroCopyReadPacketData": func(r *NgReader) (gopacket.CaptureInfo, error) {
+			_, ci, err := r.ZeroCopyReadPacketData()
+			return ci, err
+		},
+	} {
+		r, err := NewNgReader(bytes.NewReader(buffer.Bytes()), DefaultNgReaderOptions)
+		if err != nil {
+			t.Fatal("Couldn't read start of file:", err)
+		}
+		for i, want := range cis {
+			ci, err := read(r)
+			if err != nil {
+				t.Fatalf("%s [packet %d] Couldn't read packet: %s", name, i, err)
+			}
+			ci.AncillaryData = nil
+			if !reflect.DeepEqual(ci, want) {
+				t.Errorf("%s [packet %d] ci mismatch:\ngot:\n%#v\nwant:\n%#v", name, i, ci, want)
+			}
+		}
+	}
+}
+
+func TestNgPacketOptionsTags(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	w, err := NewNgWriter(buffer, layers.LinkTypeEthernet)
+	if err != nil {
+		t.Fatal("Opening file failed with: ", err)
+	}
+	var tags gopacket.Tags
+	tags.Set("tenant", 42)
+	tags.Set("verdict", "drop")
+	options := NgPacketOptions{Comments: []string{"not a tag", "x=1 observed"}}
+	options.AddTags(tags)
+	data := []byte{1, 2, 3, 4}
+	ci := gopacket.CaptureInfo{CaptureLength: len(data), Length: len(data)}
+	if err := w.WritePacketWithOptions(ci, data, options); err != nil {
+		t.Fatal("Couldn't write packet", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal("Couldn't flush buffer", err)
+	}
+
+	r, err := NewNgReader(bytes.NewReader(buffer.Bytes()), DefaultNgReaderOptions)
+	if err != nil {
+		t.Fatal("Couldn't read start of file:", err)
+	}
+	_, _, options, err = r.ReadPacketDataWithOptions()
+	if err != nil {
+		t.Fatal("Couldn't read packet:", err)
+	}
+	wantComments := []string{"not a tag", "x=1 observed", "tag:tenant=42", "tag:verdict=drop"}
+	if !reflect.DeepEqual(options.Comments, wantComments) {
+		t.Errorf("got comments %q, want %q", options.Comments, wantComments)
+	}
+	want := gopacket.Tags{{Key: "tenant", Value: "42"}, {Key: "verdict", Value: "drop"}}
+	if got := options.Tags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got tags %v, want %v", got, want)
+	}
+}
+
 type ngDevNull struct{}
 
 func (w *ngDevNull) Write(p []byte) (n int, err error) {
@@ -0,0 +1,207 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// ParallelWriterOptions holds options for creating a ParallelWriter.
+type ParallelWriterOptions struct {
+	// Pcapng selects pcapng as the output format. Otherwise pcap is written.
+	Pcapng bool
+	// LinkType is the link type of the written packets. For pcapng files, this is only used if Interface is empty.
+	LinkType layers.LinkType
+	// Snaplen is the snap length written to pcap files.
+	Snaplen uint32
+	// Nanosecond enables nanosecond timestamp resolution for pcap files.
+	Nanosecond bool
+	// Interface is the interface written to pcapng files. DefaultNgInterface with LinkType is used if this is empty.
+	Interface NgInterface
+	// NgOptions holds the options for pcapng files. DefaultNgWriterOptions is used if this is empty.
+	NgOptions NgWriterOptions
+
+	// Offset is the position in the output at which the file starts.
+	Offset int64
+	// Preallocate is the size of the chunks the output is grown by, if it has a Truncate(int64) error method like *os.File.
+	// Growing the file in large chunks reduces file system metadata updates. Close truncates the file to its final size.
+	Preallocate int64
+}
+
+// ParallelWriter writes packets to a pcap or pcapng file from multiple goroutines in parallel. Every packet is
+// serialized by the calling goroutine into a region of the output reserved for it, and written with WriteAt, so
+// goroutines only synchronize to reserve space. This avoids the bottleneck of a single writing goroutine at very high
+// packet rates.
+//
+// Packets are stored in the order in which space was reserved for them, which is not necessarily timestamp order.
+// Until all WritePacket calls have returned, the file might contain regions not yet written. Such regions stay zero
+// if the process crashes; NgReaderOptions.Recover skips them when reading pcapng files.
+type ParallelWriter struct {
+	// accessed atomically; first in the struct for 64-bit alignment
+	size      int64 // end of the reserved regions
+	allocated int64 // size of the preallocated file
+
+	w       io.WriterAt
+	options ParallelWriterOptions
+
+	truncater interface {
+		Truncate(int64) error
+	}
+	allocMu sync.Mutex
+
+	encoders sync.Pool
+}
+
+// parallelEncoder serializes packets of a single goroutine
+type parallelEncoder struct {
+	buf  bytes.Buffer
+	pcap *Writer
+	ng   *NgWriter
+}
+
+// NewParallelWriter returns a new ParallelWriter writing to w and writes the file header.
+func NewParallelWriter(w io.WriterAt, options ParallelWriterOptions) (*ParallelWriter, error) {
+	ret := &ParallelWriter{
+		w:       w,
+		options: options,
+		size:    options.Offset,
+	}
+	if options.Preallocate > 0 {
+		ret.truncater, _ = w.(interface {
+			Truncate(int64) error
+		})
+	}
+	if options.Pcapng {
+		if ret.options.Interface == (NgInterface{}) {
+			ret.options.Interface = DefaultNgInterface
+			ret.options.Interface.LinkType = options.LinkType
+		}
+		if ret.options.NgOptions == (NgWriterOptions{}) {
+			ret.options.NgOptions = DefaultNgWriterOptions
+		}
+	}
+	ret.encoders.New = ret.newEncoder
+
+	var header bytes.Buffer
+	if options.Pcapng {
+		ng, err := NewNgWriterInterface(&header, ret.options.Interface, ret.options.NgOptions)
+		if err != nil {
+			return nil, err
+		}
+		if err := ng.Flush(); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if options.Nanosecond {
+			err = NewWriterNanos(&header).WriteFileHeader(options.Snaplen, options.LinkType)
+		} else {
+			err = NewWriter(&header).WriteFileHeader(options.Snaplen, options.LinkType)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := ret.writeReserved(header.Bytes()); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (w *ParallelWriter) newEncoder() interface{} {
+	enc := &parallelEncoder{}
+	if w.options.Pcapng {
+		// the section header and the interface are written by NewParallelWriter
+		enc.ng = &NgWriter{
+			w:       bufio.NewWriter(&enc.buf),
+			options: w.options.NgOptions,
+			intf:    1,
+		}
+	} else if w.options.Nanosecond {
+		enc.pcap = NewWriterNanos(&enc.buf)
+	} else {
+		enc.pcap = NewWriter(&enc.buf)
+	}
+	return enc
+}
+
+// reserve reserves length bytes of the output and returns the offset of the region
+func (w *ParallelWriter) reserve(length int) (int64, error) {
+	end := atomic.AddInt64(&w.size, int64(length))
+	if w.truncater != nil && end > atomic.LoadInt64(&w.allocated) {
+		w.allocMu.Lock()
+		defer w.allocMu.Unlock()
+		if allocated := atomic.LoadInt64(&w.allocated); end > allocated {
+			for allocated < end {
+				allocated += w.options.Preallocate
+			}
+			if err := w.truncater.Truncate(allocated); err != nil {
+				return 0, err
+			}
+			atomic.StoreInt64(&w.allocated, allocated)
+		}
+	}
+	return end - int64(length), nil
+}
+
+// writeReserved writes data to a newly reserved region
+func (w *ParallelWriter) writeReserved(data []byte) error {
+	offset, err := w.reserve(len(data))
+	if err != nil {
+		return err
+	}
+	_, err = w.w.WriteAt(data, offset)
+	return err
+}
+
+// WritePacket writes the given packet. It is safe to call WritePacket from multiple goroutines.
+func (w *ParallelWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	return w.WritePacketWithOptions(ci, data, NgPacketOptions{})
+}
+
+// WritePacketWithOptions is like WritePacket, but additionally writes the given packet options to pcapng files.
+// The options are ignored for pcap files.
+func (w *ParallelWriter) WritePacketWithOptions(ci gopacket.CaptureInfo, data []byte, options NgPacketOptions) error {
+	enc := w.encoders.Get().(*parallelEncoder)
+	defer w.encoders.Put(enc)
+	enc.buf.Reset()
+	if enc.ng != nil {
+		ci.InterfaceIndex = 0
+		if err := enc.ng.WritePacketWithOptions(ci, data, options); err != nil {
+			// drop partially serialized data
+			enc.ng.w.Reset(&enc.buf)
+			return err
+		}
+		if err := enc.ng.w.Flush(); err != nil {
+			return err
+		}
+	} else if err := enc.pcap.WritePacket(ci, data); err != nil {
+		return err
+	}
+	return w.writeReserved(enc.buf.Bytes())
+}
+
+// Size returns the size of the file written so far including the header (without Offset).
+func (w *ParallelWriter) Size() int64 {
+	return atomic.LoadInt64(&w.size) - w.options.Offset
+}
+
+// Close truncates a preallocated file to its final size. Close must only be called after all calls to WritePacket
+// returned. The underlying writer is not closed.
+func (w *ParallelWriter) Close() error {
+	if w.truncater == nil {
+		return nil
+	}
+	return w.truncater.Truncate(atomic.LoadInt64(&w.size))
+}
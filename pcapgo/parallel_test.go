@@ -0,0 +1,105 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestParallelWriter(t *testing.T) {
+	const goroutines = 8
+	const packets = 100
+
+	for _, ng := range []bool{false, true} {
+		f, err := ioutil.TempFile("", "parallel")
+		if err != nil {
+			t.Fatal("Couldn't create file:", err)
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+
+		w, err := NewParallelWriter(f, ParallelWriterOptions{
+			Pcapng:      ng,
+			LinkType:    layers.LinkTypeEthernet,
+			Snaplen:     65536,
+			Preallocate: 4096,
+		})
+		if err != nil {
+			t.Fatal("Couldn't create writer:", err)
+		}
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < packets; i++ {
+					data := ngPacketSource[(g+i)%len(ngPacketSource)]
+					ci := gopacket.CaptureInfo{
+						Timestamp:     time.Unix(int64(g), int64(i)*1000).UTC(),
+						CaptureLength: len(data),
+						Length:        len(data),
+					}
+					if err := w.WritePacket(ci, data); err != nil {
+						t.Error("Couldn't write packet:", err)
+						return
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+		if err := w.Close(); err != nil {
+			t.Fatal("Couldn't close writer:", err)
+		}
+		if fi, err := f.Stat(); err != nil || fi.Size() != w.Size() {
+			t.Fatalf("[ng=%v] Expected file size %d, but got %v and %v", ng, w.Size(), fi.Size(), err)
+		}
+
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			t.Fatal("Couldn't seek:", err)
+		}
+		var r interface {
+			ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+		}
+		if ng {
+			r, err = NewNgReader(f, DefaultNgReaderOptions)
+		} else {
+			r, err = NewReader(f)
+		}
+		if err != nil {
+			t.Fatal("Couldn't create reader:", err)
+		}
+		perGoroutine := make([]int, goroutines)
+		for {
+			data, ci, err := r.ReadPacketData()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("[ng=%v] Couldn't read packet: %v", ng, err)
+			}
+			g := int(ci.Timestamp.Unix())
+			i := ci.Timestamp.Nanosecond() / 1000
+			if len(data) != len(ngPacketSource[(g+i)%len(ngPacketSource)]) {
+				t.Fatalf("[ng=%v] Packet %d of goroutine %d has wrong length", ng, i, g)
+			}
+			perGoroutine[g]++
+		}
+		for g, n := range perGoroutine {
+			if n != packets {
+				t.Errorf("[ng=%v] Expected %d packets of goroutine %d, but got %d", ng, packets, g, n)
+			}
+		}
+	}
+}
@@ -9,6 +9,7 @@ package pcapgo
 import (
 	"errors"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/google/gopacket"
@@ -37,6 +38,7 @@ const (
 	ngBlockTypeSimplePacket        ngBlockType = 3          // Simple packet block
 	ngBlockTypeInterfaceStatistics ngBlockType = 5          // Interface statistics block
 	ngBlockTypeEnhancedPacket      ngBlockType = 6          // Enhanced packet block
+	ngBlockTypeDecryptionSecrets   ngBlockType = 0x0A       // Decryption secrets block
 	ngBlockTypeSectionHeader       ngBlockType = 0x0A0D0D0A // Section header block (same in both endians)
 )
 
@@ -76,6 +78,29 @@ const (
 	ngOptionCodeInterfaceStatisticsDelivered                                 // Packets delivered to user
 )
 
+const (
+	ngOptionCodeEnhancedPacketFlags     ngOptionCode = iota + 2 // link-layer information (direction, reception type, FCS length, errors)
+	ngOptionCodeEnhancedPacketHash                              // hash of the packet
+	ngOptionCodeEnhancedPacketDropCount                         // packets lost between this and the preceding packet
+	ngOptionCodeEnhancedPacketID                                // unique identifier of the packet
+	ngOptionCodeEnhancedPacketQueue                             // queue of the interface the packet was received on
+)
+
+// NgSecretsType is the type of the secrets in a pcapng decryption secrets block
+type NgSecretsType uint32
+
+// Secrets types of decryption secrets blocks
+const (
+	// NgSecretsTypeTLSKeyLog is a TLS key log in the NSS format (SSLKEYLOGFILE).
+	NgSecretsTypeTLSKeyLog NgSecretsType = 0x544c534b
+	// NgSecretsTypeWireGuardKeyLog is a WireGuard key log.
+	NgSecretsTypeWireGuardKeyLog NgSecretsType = 0x57474b4c
+	// NgSecretsTypeZigBeeNWKKey is a ZigBee NWK key.
+	NgSecretsTypeZigBeeNWKKey NgSecretsType = 0x5a4e574b
+	// NgSecretsTypeZigBeeAPSKey is a ZigBee APS key.
+	NgSecretsTypeZigBeeAPSKey NgSecretsType = 0x5a415053
+)
+
 // ngOption is a pcapng option
 type ngOption struct {
 	code   ngOptionCode
@@ -161,6 +186,9 @@ type NgInterface struct {
 	TimestampOffset uint64
 	// SnapLength is the maximum packet length captured by this interface. 0 for unlimited
 	SnapLength uint32
+	// FCSLength is the length in bytes of the frame check sequence at the end of every packet of this interface.
+	// 0 if the packets don't include an FCS or this option is missing.
+	FCSLength int
 	// Statistics holds the interface statistics
 	Statistics NgInterfaceStatistics
 
@@ -185,3 +213,129 @@ type NgSectionInfo struct {
 	// Comment can be an arbitrary comment. This value might be empty if this option is missing.
 	Comment string
 }
+
+// NgPacketDirection is the direction of a packet as stored in the packet flags.
+type NgPacketDirection uint8
+
+const (
+	// NgPacketDirectionUnknown is used if the direction is not available.
+	NgPacketDirectionUnknown NgPacketDirection = iota
+	// NgPacketDirectionInbound is used for received packets.
+	NgPacketDirectionInbound
+	// NgPacketDirectionOutbound is used for transmitted packets.
+	NgPacketDirectionOutbound
+)
+
+func (d NgPacketDirection) String() string {
+	switch d {
+	case NgPacketDirectionInbound:
+		return "Inbound"
+	case NgPacketDirectionOutbound:
+		return "Outbound"
+	}
+	return "Unknown"
+}
+
+// PacketDirection returns d as a gopacket.PacketDirection.
+func (d NgPacketDirection) PacketDirection() gopacket.PacketDirection {
+	switch d {
+	case NgPacketDirectionInbound:
+		return gopacket.PacketDirectionInbound
+	case NgPacketDirectionOutbound:
+		return gopacket.PacketDirectionOutbound
+	}
+	return gopacket.PacketDirectionUnknown
+}
+
+// ngPacketDirection returns d as an NgPacketDirection.
+func ngPacketDirection(d gopacket.PacketDirection) NgPacketDirection {
+	switch d {
+	case gopacket.PacketDirectionInbound:
+		return NgPacketDirectionInbound
+	case gopacket.PacketDirectionOutbound:
+		return NgPacketDirectionOutbound
+	}
+	return NgPacketDirectionUnknown
+}
+
+// NgReceptionType is the reception type of a packet as stored in the packet flags.
+type NgReceptionType uint8
+
+const (
+	// NgReceptionTypeUnspecified is used if the reception type is not available.
+	NgReceptionTypeUnspecified NgReceptionType = iota
+	// NgReceptionTypeUnicast is used for unicast packets.
+	NgReceptionTypeUnicast
+	// NgReceptionTypeMulticast is used for multicast packets.
+	NgReceptionTypeMulticast
+	// NgReceptionTypeBroadcast is used for broadcast packets.
+	NgReceptionTypeBroadcast
+	// NgReceptionTypePromiscuous is used for packets only received because of promiscuous mode.
+	NgReceptionTypePromiscuous
+)
+
+// NgPacketFlags holds the link-layer information of a packet (epb_flags option).
+// Bits 0-1 hold the direction, bits 2-4 the reception type, bits 5-8 the FCS length in octets, and bits 16-31 link-layer dependent errors.
+type NgPacketFlags uint32
+
+// NewNgPacketFlags returns packet flags with the given direction, reception type, and FCS length in octets.
+func NewNgPacketFlags(direction NgPacketDirection, reception NgReceptionType, fcsLength uint8) NgPacketFlags {
+	return NgPacketFlags(direction&0x3) | NgPacketFlags(reception&0x7)<<2 | NgPacketFlags(fcsLength&0xf)<<5
+}
+
+// Direction returns the direction of the packet.
+func (f NgPacketFlags) Direction() NgPacketDirection {
+	return NgPacketDirection(f & 0x3)
+}
+
+// ReceptionType returns the reception type of the packet.
+func (f NgPacketFlags) ReceptionType() NgReceptionType {
+	return NgReceptionType(f >> 2 & 0x7)
+}
+
+// FCSLength returns the length of the frame check sequence in octets. 0 means that this information is not available.
+func (f NgPacketFlags) FCSLength() uint8 {
+	return uint8(f >> 5 & 0xf)
+}
+
+// LinkLayerErrors returns the link-layer dependent error bits (CRC error, packet too long, ...).
+func (f NgPacketFlags) LinkLayerErrors() uint16 {
+	return uint16(f >> 16)
+}
+
+// NgPacketOptions holds the options of a single (enhanced) packet block. Empty values are not written.
+type NgPacketOptions struct {
+	// Comments holds the comments attached to this packet. Every entry is written as a separate comment option.
+	Comments []string
+	// Flags holds link-layer information like the direction of the packet. This value might be 0 if this option is missing.
+	Flags NgPacketFlags
+	// DropCount is the number of packets lost between this and the preceding packet on the same interface. This value might be 0 if this option is missing.
+	DropCount uint64
+	// Hash is the raw value of the hash option: the first octet is the hash algorithm followed by the hash itself. This value might be empty if this option is missing.
+	Hash []byte
+}
+
+// ngTagCommentPrefix starts the comments holding tags, which tells them apart from other comments.
+const ngTagCommentPrefix = "tag:"
+
+// AddTags adds the given tags as "tag:key=value" comments to the options.
+func (o *NgPacketOptions) AddTags(tags gopacket.Tags) {
+	for _, tag := range tags {
+		o.Comments = append(o.Comments, ngTagCommentPrefix+tag.String())
+	}
+}
+
+// Tags returns the comments of the options in "tag:key=value" format, like the ones added by AddTags, as tags with
+// string values. Other comments are ignored, even if they contain "=".
+func (o NgPacketOptions) Tags() gopacket.Tags {
+	var tags gopacket.Tags
+	for _, comment := range o.Comments {
+		if !strings.HasPrefix(comment, ngTagCommentPrefix) {
+			continue
+		}
+		if tag, ok := gopacket.ParseTag(comment[len(ngTagCommentPrefix):]); ok {
+			tags.Set(tag.Key, tag.Value)
+		}
+	}
+	return tags
+}
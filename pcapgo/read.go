@@ -11,10 +11,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"time"
 
 	"bufio"
-	"compress/gzip"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
@@ -27,8 +27,8 @@ import (
 // We currenty read v2.4 file format with nanosecond and microsecdond
 // timestamp resolution in little-endian and big-endian encoding.
 //
-// If the PCAP data is gzip compressed it is transparently uncompressed
-// by wrapping the given io.Reader with a gzip.Reader.
+// If the PCAP data is compressed it is transparently uncompressed
+// by wrapping the given io.Reader with a decompressor (see RegisterDecompressor).
 type Reader struct {
 	r              io.Reader
 	byteOrder      binary.ByteOrder
@@ -37,12 +37,17 @@ type Reader struct {
 	versionMinor   uint16
 	// timezone
 	// sigfigs
-	snaplen  uint32
-	linkType layers.LinkType
+	snaplen   uint32
+	linkType  layers.LinkType
+	fcsLength int
 	// reusable buffer
 	buf [16]byte
 	// buffer for ZeroCopyReadPacketData
 	packetBuf []byte
+	// mapped is set if reading from an uncompressed MappedFile
+	mapped *MappedFile
+	// filter is set if packets are filtered
+	filter *readFilter
 }
 
 const magicNanoseconds = 0xA1B23C4D
@@ -71,20 +76,13 @@ func NewReader(r io.Reader) (*Reader, error) {
 }
 
 func (r *Reader) readHeader() error {
-	br := bufio.NewReader(r.r)
-	gzipMagic, err := br.Peek(2)
-	if err != nil {
+	var err error
+	if mf, ok := r.r.(*MappedFile); ok && !mf.compressed() {
+		r.mapped = mf
+	} else if r.r, err = decompress(bufio.NewReader(r.r)); err != nil {
 		return err
 	}
 
-	if gzipMagic[0] == magicGzip1 && gzipMagic[1] == magicGzip2 {
-		if r.r, err = gzip.NewReader(br); err != nil {
-			return err
-		}
-	} else {
-		r.r = br
-	}
-
 	buf := make([]byte, 24)
 	if n, err := io.ReadFull(r.r, buf); err != nil {
 		return err
@@ -114,56 +112,130 @@ func (r *Reader) readHeader() error {
 	}
 	// ignore timezone 8:12 and sigfigs 12:16
 	r.snaplen = r.byteOrder.Uint32(buf[16:20])
-	r.linkType = layers.LinkType(r.byteOrder.Uint32(buf[20:24]))
+	linkType := r.byteOrder.Uint32(buf[20:24])
+	r.linkType = layers.LinkType(linkType & pcapLinkTypeMask)
+	if linkType&pcapFCSPresent != 0 {
+		// FCS length in 16-bit words
+		r.fcsLength = int(linkType>>pcapFCSLengthShift) * 2
+	}
 	return nil
 }
 
 // ReadPacketData reads next packet from file.
 func (r *Reader) ReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
-	if ci, err = r.readPacketHeader(); err != nil {
-		return
-	}
-	if ci.CaptureLength > int(r.snaplen) {
-		err = fmt.Errorf("capture length exceeds snap length: %d > %d", ci.CaptureLength, r.snaplen)
-		return
-	}
-	if ci.CaptureLength > ci.Length {
-		err = fmt.Errorf("capture length exceeds original packet length: %d > %d", ci.CaptureLength, ci.Length)
+	for {
+		if ci, err = r.readPacketHeader(); err != nil {
+			return
+		}
+		if ci.CaptureLength > int(r.snaplen) {
+			err = fmt.Errorf("capture length exceeds snap length: %d > %d", ci.CaptureLength, r.snaplen)
+			return
+		}
+		if ci.CaptureLength > ci.Length {
+			err = fmt.Errorf("capture length exceeds original packet length: %d > %d", ci.CaptureLength, ci.Length)
+			return
+		}
+		if r.filter != nil {
+			var skip bool
+			if skip, err = r.skipByTime(ci); err != nil {
+				return
+			} else if skip {
+				continue
+			}
+		}
+		data = make([]byte, ci.CaptureLength)
+		if _, err = io.ReadFull(r.r, data); err != nil {
+			return
+		}
+		if r.filter != nil {
+			var match bool
+			if match, err = r.filter.matchData(data); err != nil {
+				return
+			} else if !match {
+				continue
+			}
+		}
 		return
 	}
-	data = make([]byte, ci.CaptureLength)
-	_, err = io.ReadFull(r.r, data)
-	return data, ci, err
 }
 
 // ZeroCopyReadPacketData reads next packet from file. The data buffer is owned by the Reader,
 // and each call to ZeroCopyReadPacketData invalidates data returned by the previous one.
 //
 // It is not true zero copy, as data is still copied from the underlying reader. However,
-// this method avoids allocating heap memory for every packet.
+// this method avoids allocating heap memory for every packet. If the underlying reader is a
+// MappedFile, data points into the mapping and stays valid until the MappedFile is closed.
 func (r *Reader) ZeroCopyReadPacketData() (data []byte, ci gopacket.CaptureInfo, err error) {
-	if ci, err = r.readPacketHeader(); err != nil {
+	for {
+		if ci, err = r.readPacketHeader(); err != nil {
+			return
+		}
+		if ci.CaptureLength > int(r.snaplen) {
+			err = fmt.Errorf("capture length exceeds snap length: %d > %d", ci.CaptureLength, r.snaplen)
+			return
+		}
+		if ci.CaptureLength > ci.Length {
+			err = fmt.Errorf("capture length exceeds original packet length: %d > %d", ci.CaptureLength, ci.Length)
+			return
+		}
+		if r.filter != nil {
+			var skip bool
+			if skip, err = r.skipByTime(ci); err != nil {
+				return
+			} else if skip {
+				continue
+			}
+		}
+
+		if r.mapped != nil {
+			if data, err = r.mapped.next(ci.CaptureLength, nil); err != nil {
+				return
+			}
+		} else {
+			if cap(r.packetBuf) < ci.CaptureLength {
+				snaplen := int(r.snaplen)
+				if snaplen < ci.CaptureLength {
+					snaplen = ci.CaptureLength
+				}
+				r.packetBuf = make([]byte, snaplen)
+			}
+			data = r.packetBuf[:ci.CaptureLength]
+			if _, err = io.ReadFull(r.r, data); err != nil {
+				return
+			}
+		}
+		if r.filter != nil {
+			var match bool
+			if match, err = r.filter.matchData(data); err != nil {
+				return
+			} else if !match {
+				continue
+			}
+		}
 		return
 	}
-	if ci.CaptureLength > int(r.snaplen) {
-		err = fmt.Errorf("capture length exceeds snap length: %d > %d", ci.CaptureLength, r.snaplen)
-		return
+}
+
+// skipByTime discards the data of the packet and returns true if the packet is outside the time window of the filter.
+// Returns io.EOF if all following packets are outside the time window.
+func (r *Reader) skipByTime(ci gopacket.CaptureInfo) (bool, error) {
+	match, done := r.filter.matchTime(ci.Timestamp)
+	if done {
+		return true, io.EOF
 	}
-	if ci.CaptureLength > ci.Length {
-		err = fmt.Errorf("capture length exceeds original packet length: %d > %d", ci.CaptureLength, ci.Length)
-		return
+	if match {
+		return false, nil
 	}
-
-	if cap(r.packetBuf) < ci.CaptureLength {
-		snaplen := int(r.snaplen)
-		if snaplen < ci.CaptureLength {
-			snaplen = ci.CaptureLength
-		}
-		r.packetBuf = make([]byte, snaplen)
+	if r.mapped != nil {
+		_, err := r.mapped.next(ci.CaptureLength, nil)
+		return true, err
 	}
-	data = r.packetBuf[:ci.CaptureLength]
-	_, err = io.ReadFull(r.r, data)
-	return data, ci, err
+	if br, ok := r.r.(*bufio.Reader); ok {
+		_, err := br.Discard(ci.CaptureLength)
+		return true, err
+	}
+	_, err := io.CopyN(ioutil.Discard, r.r, int64(ci.CaptureLength))
+	return true, err
 }
 
 func (r *Reader) readPacketHeader() (ci gopacket.CaptureInfo, err error) {
@@ -181,6 +253,23 @@ func (r *Reader) LinkType() layers.LinkType {
 	return r.linkType
 }
 
+// FCSLength returns the length in bytes of the frame check sequence at the end of every packet as stored in the file header.
+// 0 if the packets don't include an FCS or the length is unknown.
+func (r *Reader) FCSLength() int {
+	return r.fcsLength
+}
+
+// SetFilter sets a filter for the packets returned by ReadPacketData and ZeroCopyReadPacketData. An empty filter
+// disables filtering. An error is returned if the BPF program is invalid.
+func (r *Reader) SetFilter(filter ReadFilter) error {
+	f, err := newReadFilter(filter)
+	if err != nil {
+		return err
+	}
+	r.filter = f
+	return nil
+}
+
 // Snaplen returns the snapshot length of the capture file.
 func (r *Reader) Snaplen() uint32 {
 	return r.snaplen
@@ -0,0 +1,390 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// RotatingWriterOptions holds options for creating a RotatingWriter.
+type RotatingWriterOptions struct {
+	// Filename is the template for the names of the written files. It supports the strftime conversions
+	// %Y, %y, %m, %d, %H, %M, %S, %j, %s, and %% which are expanded with the timestamp of the first packet of
+	// the file (in the location of the timestamp), and additionally %n which is expanded to the number of the file starting at 0.
+	// If the template expands to the same name for consecutive files, the number of the file is appended.
+	Filename string
+	// Pcapng selects pcapng as the output format. Otherwise pcap is written.
+	Pcapng bool
+	// LinkType is the link type of the written packets. For pcapng files, this is only used if Interface is empty.
+	LinkType layers.LinkType
+	// Snaplen is the snap length written to pcap files.
+	Snaplen uint32
+	// Nanosecond enables nanosecond timestamp resolution for pcap files.
+	Nanosecond bool
+	// Interface is the interface written to pcapng files. DefaultNgInterface with LinkType is used if this is empty.
+	Interface NgInterface
+	// NgOptions holds the options for pcapng files. DefaultNgWriterOptions is used if this is empty.
+	NgOptions NgWriterOptions
+
+	// MaxSize is the size in bytes after which a new file is started (like tcpdump -C). 0 disables this limit.
+	MaxSize int64
+	// MaxDuration is the time span of packet timestamps after which a new file is started (like tcpdump -G). 0 disables this limit.
+	MaxDuration time.Duration
+	// MaxPackets is the number of packets after which a new file is started. 0 disables this limit.
+	MaxPackets int
+
+	// Compressor, if set, is used to compress every file after it has been closed. The compressed file gets the
+	// name of the file plus CompressedSuffix, and the uncompressed file is removed. Compression is done in the background.
+	Compressor Compressor
+	// CompressedSuffix is appended to the names of compressed files. Defaults to ".gz".
+	CompressedSuffix string
+	// MaxFiles is the number of files to keep (like tcpdump -W). If more files are written, the oldest ones are removed. 0 keeps all files.
+	MaxFiles int
+}
+
+// RotatingWriter writes packets to a sequence of pcap or pcapng files and starts a new file whenever one of the limits in
+// RotatingWriterOptions is reached. Close must be called to finish the last file and wait for background compression.
+type RotatingWriter struct {
+	options RotatingWriterOptions
+
+	file    *os.File
+	counter *countingWriter
+	pcap    *Writer
+	ng      *NgWriter
+
+	name     string
+	expanded string
+	number   int
+	start    time.Time
+	packets  int
+
+	closed   chan closedFile
+	done     sync.WaitGroup
+	errMu    sync.Mutex
+	err      error
+	written  []string
+	isClosed bool
+}
+
+// closedFile is a file handed to the background worker. last is set for the file finished by Close, as no new
+// file is opened after it.
+type closedFile struct {
+	name string
+	last bool
+}
+
+var errRotatingWriterClosed = errors.New("RotatingWriter is closed")
+
+// countingWriter counts the bytes written to the underlying writer
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// NewRotatingWriter returns a new RotatingWriter. The first file is created when the first packet is written.
+func NewRotatingWriter(options RotatingWriterOptions) (*RotatingWriter, error) {
+	if options.Filename == "" {
+		return nil, errors.New("Filename template must not be empty")
+	}
+	if options.CompressedSuffix == "" {
+		options.CompressedSuffix = ".gz"
+	}
+	ret := &RotatingWriter{
+		options: options,
+		closed:  make(chan closedFile, 16),
+	}
+	ret.done.Add(1)
+	go ret.finishFiles()
+	return ret, nil
+}
+
+// finishFiles compresses closed files and enforces the retention policy
+func (w *RotatingWriter) finishFiles() {
+	defer w.done.Done()
+	for file := range w.closed {
+		name := file.name
+		if w.options.Compressor != nil {
+			compressed, err := w.compressFile(name)
+			if err != nil {
+				w.setError(err)
+			} else {
+				name = compressed
+			}
+		}
+		w.written = append(w.written, name)
+		if w.options.MaxFiles > 0 {
+			// while rotating, the newly opened file counts towards the limit as well
+			keep := w.options.MaxFiles
+			if !file.last {
+				keep--
+			}
+			for len(w.written) > keep {
+				if err := os.Remove(w.written[0]); err != nil && !os.IsNotExist(err) {
+					w.setError(err)
+				}
+				w.written = w.written[1:]
+			}
+		}
+	}
+}
+
+// compressFile compresses the given file and removes the uncompressed one
+func (w *RotatingWriter) compressFile(name string) (string, error) {
+	in, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	compressedName := name + w.options.CompressedSuffix
+	out, err := os.Create(compressedName)
+	if err != nil {
+		return "", err
+	}
+	cw := NewCompressedWriter(out, w.options.Compressor, 0)
+	if _, err := io.Copy(cw, in); err != nil {
+		out.Close()
+		return "", err
+	}
+	if err := cw.Close(); err != nil {
+		out.Close()
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+	return compressedName, os.Remove(name)
+}
+
+func (w *RotatingWriter) setError(err error) {
+	w.errMu.Lock()
+	if w.err == nil {
+		w.err = err
+	}
+	w.errMu.Unlock()
+}
+
+func (w *RotatingWriter) getError() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	return w.err
+}
+
+// size returns the number of bytes in the current file including buffered data
+func (w *RotatingWriter) size() int64 {
+	if w.ng != nil {
+		return w.counter.n + int64(w.ng.w.Buffered())
+	}
+	return w.counter.n
+}
+
+// needsRotation returns true if a packet with the given timestamp must be written to a new file
+func (w *RotatingWriter) needsRotation(ts time.Time) bool {
+	if w.file == nil {
+		return true
+	}
+	if w.options.MaxPackets > 0 && w.packets >= w.options.MaxPackets {
+		return true
+	}
+	if w.options.MaxSize > 0 && w.size() >= w.options.MaxSize {
+		return true
+	}
+	if w.options.MaxDuration > 0 && ts.Sub(w.start) >= w.options.MaxDuration {
+		return true
+	}
+	return false
+}
+
+// closeFile finishes the current file and hands it to the background worker
+func (w *RotatingWriter) closeFile(last bool) error {
+	if w.file == nil {
+		return nil
+	}
+	var err error
+	if w.ng != nil {
+		err = w.ng.Flush()
+	}
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	w.closed <- closedFile{name: w.name, last: last}
+	w.file = nil
+	w.pcap = nil
+	w.ng = nil
+	return err
+}
+
+// openFile starts a new file for a packet with the given timestamp
+func (w *RotatingWriter) openFile(ts time.Time) error {
+	name := expandFilename(w.options.Filename, ts, w.number)
+	expanded := name
+	if w.number > 0 && name == w.expanded {
+		name += strconv.Itoa(w.number)
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	counter := &countingWriter{w: f}
+
+	// the writer is only set up after the file header was written, so a failure leaves no half-opened file behind
+	var pcap *Writer
+	var ng *NgWriter
+	if w.options.Pcapng {
+		intf := w.options.Interface
+		if intf == (NgInterface{}) {
+			intf = DefaultNgInterface
+			intf.LinkType = w.options.LinkType
+		}
+		options := w.options.NgOptions
+		if options == (NgWriterOptions{}) {
+			options = DefaultNgWriterOptions
+		}
+		if ng, err = NewNgWriterInterface(counter, intf, options); err == nil {
+			// NgWriter buffers, so write out the header to notice failures now
+			err = ng.Flush()
+		}
+	} else {
+		if w.options.Nanosecond {
+			pcap = NewWriterNanos(counter)
+		} else {
+			pcap = NewWriter(counter)
+		}
+		err = pcap.WriteFileHeader(w.options.Snaplen, w.options.LinkType)
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.expanded = expanded
+	w.file = f
+	w.counter = counter
+	w.pcap = pcap
+	w.ng = ng
+	w.name = name
+	w.number++
+	w.start = ts
+	w.packets = 0
+	return nil
+}
+
+// WritePacket writes the given packet to the current file, starting a new file if necessary.
+// Errors of the background compression and retention are also returned here.
+func (w *RotatingWriter) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	if w.isClosed {
+		return errRotatingWriterClosed
+	}
+	if err := w.getError(); err != nil {
+		return err
+	}
+	ts := ci.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	if w.needsRotation(ts) {
+		if err := w.closeFile(false); err != nil {
+			return err
+		}
+		if err := w.openFile(ts); err != nil {
+			return err
+		}
+	}
+	w.packets++
+	if w.ng != nil {
+		return w.ng.WritePacket(ci, data)
+	}
+	return w.pcap.WritePacket(ci, data)
+}
+
+// Filename returns the name of the file currently written to, or an empty string if no file is open.
+func (w *RotatingWriter) Filename() string {
+	if w.file == nil {
+		return ""
+	}
+	return w.name
+}
+
+// Flush writes out buffered data of the current file.
+func (w *RotatingWriter) Flush() error {
+	if w.ng != nil {
+		return w.ng.Flush()
+	}
+	return nil
+}
+
+// Close finishes the current file and waits until all files are compressed and the retention policy is applied.
+// Calling Close again does nothing and returns nil.
+func (w *RotatingWriter) Close() error {
+	if w.isClosed {
+		return nil
+	}
+	w.isClosed = true
+	err := w.closeFile(true)
+	close(w.closed)
+	w.done.Wait()
+	if err != nil {
+		return err
+	}
+	return w.getError()
+}
+
+// expandFilename expands the strftime conversions in template with t and %n with number
+func expandFilename(template string, t time.Time, number int) string {
+	var b strings.Builder
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		if c != '%' || i == len(template)-1 {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch template[i] {
+		case 'Y':
+			fmt.Fprintf(&b, "%04d", t.Year())
+		case 'y':
+			fmt.Fprintf(&b, "%02d", t.Year()%100)
+		case 'm':
+			fmt.Fprintf(&b, "%02d", int(t.Month()))
+		case 'd':
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case 'H':
+			fmt.Fprintf(&b, "%02d", t.Hour())
+		case 'M':
+			fmt.Fprintf(&b, "%02d", t.Minute())
+		case 'S':
+			fmt.Fprintf(&b, "%02d", t.Second())
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 's':
+			fmt.Fprintf(&b, "%d", t.Unix())
+		case 'n':
+			fmt.Fprintf(&b, "%d", number)
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(template[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,262 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package pcapgo
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+func TestExpandFilename(t *testing.T) {
+	ts := time.Date(2018, 2, 20, 12, 3, 4, 0, time.UTC)
+	got := expandFilename("cap-%Y%m%d-%H%M%S-%j-%y-%s-%n-%%-%x.pcap", ts, 7)
+	want := "cap-20180220-120304-051-18-1519128184-7-%-%x.pcap"
+	if got != want {
+		t.Errorf("Expected %q, but got %q", want, got)
+	}
+}
+
+// writeRotatingTest writes count packets one second apart and returns the sorted names of the remaining files
+func writeRotatingTest(t *testing.T, options RotatingWriterOptions, count int) []string {
+	w, err := NewRotatingWriter(options)
+	if err != nil {
+		t.Fatal("Couldn't create writer:", err)
+	}
+	start := time.Date(2018, 2, 20, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < count; i++ {
+		data := ngPacketSource[4]
+		ci := gopacket.CaptureInfo{
+			Timestamp:     start.Add(time.Duration(i) * time.Second),
+			CaptureLength: len(data),
+			Length:        len(data),
+		}
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("Couldn't close writer:", err)
+	}
+	files, err := filepath.Glob(filepath.Join(filepath.Dir(options.Filename), "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	for i := range files {
+		files[i] = filepath.Base(files[i])
+	}
+	return files
+}
+
+// countRotatingTestPackets returns the number of packets in the given file
+func countRotatingTestPackets(t *testing.T, name string) int {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var r interface {
+		ReadPacketData() ([]byte, gopacket.CaptureInfo, error)
+	}
+	if r, err = NewNgReader(f, DefaultNgReaderOptions); err != nil {
+		f.Seek(0, io.SeekStart)
+		if r, err = NewReader(f); err != nil {
+			t.Fatalf("Couldn't read %s: %s", name, err)
+		}
+	}
+	n := 0
+	for {
+		if _, _, err := r.ReadPacketData(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("Couldn't read %s: %s", name, err)
+			}
+			return n
+		}
+		n++
+	}
+}
+
+func TestRotatingWriterPackets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := writeRotatingTest(t, RotatingWriterOptions{
+		Filename:   filepath.Join(dir, "capture.pcap"),
+		LinkType:   layers.LinkTypeEthernet,
+		Snaplen:    65536,
+		MaxPackets: 4,
+	}, 10)
+	want := []string{"capture.pcap", "capture.pcap1", "capture.pcap2"}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("Expected files %v, but got %v", want, files)
+	}
+	for i, count := range []int{4, 4, 2} {
+		if n := countRotatingTestPackets(t, filepath.Join(dir, files[i])); n != count {
+			t.Errorf("Expected %d packets in %s, but got %d", count, files[i], n)
+		}
+	}
+}
+
+func TestRotatingWriterDuration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := writeRotatingTest(t, RotatingWriterOptions{
+		Filename:    filepath.Join(dir, "capture-%H%M%S.pcapng"),
+		Pcapng:      true,
+		LinkType:    layers.LinkTypeEthernet,
+		MaxDuration: 30 * time.Second,
+		MaxFiles:    2,
+		Compressor:  GzipCompressor(gzip.DefaultCompression),
+	}, 100)
+	want := []string{"capture-120100.pcapng.gz", "capture-120130.pcapng.gz"}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("Expected files %v, but got %v", want, files)
+	}
+	for i, count := range []int{30, 10} {
+		if n := countRotatingTestPackets(t, filepath.Join(dir, files[i])); n != count {
+			t.Errorf("Expected %d packets in %s, but got %d", count, files[i], n)
+		}
+	}
+}
+
+func TestRotatingWriterSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := writeRotatingTest(t, RotatingWriterOptions{
+		Filename: filepath.Join(dir, "capture-%n.pcapng"),
+		Pcapng:   true,
+		LinkType: layers.LinkTypeEthernet,
+		MaxSize:  1000,
+	}, 20)
+	total := 0
+	for _, name := range files {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the limit is checked before writing a packet, so a file can exceed it by one packet
+		if info.Size() > 1000+int64(len(ngPacketSource[4]))+32 {
+			t.Errorf("File %s is too large: %d bytes", name, info.Size())
+		}
+		total += countRotatingTestPackets(t, filepath.Join(dir, name))
+	}
+	if len(files) < 2 || total != 20 {
+		t.Fatalf("Expected 20 packets in multiple files, but got %d in %v", total, files)
+	}
+}
+
+func TestRotatingWriterMaxFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w, err := NewRotatingWriter(RotatingWriterOptions{
+		Filename:   filepath.Join(dir, "capture-%n.pcap"),
+		LinkType:   layers.LinkTypeEthernet,
+		Snaplen:    65536,
+		MaxPackets: 2,
+		MaxFiles:   2,
+	})
+	if err != nil {
+		t.Fatal("Couldn't create writer:", err)
+	}
+	data := ngPacketSource[4]
+	ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+	for i := 0; i < 9; i++ {
+		if err := w.WritePacket(ci, data); err != nil {
+			t.Fatal("Couldn't write packet:", err)
+		}
+	}
+	// old files are removed in the background, so wait for the open file to be counted against the limit
+	var files []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if files, err = filepath.Glob(filepath.Join(dir, "*")); err != nil {
+			t.Fatal(err)
+		}
+		if len(files) <= 2 {
+			break
+		}
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected 2 files while writing, but got %v", files)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal("Couldn't close writer:", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Error("Second Close returned", err)
+	}
+	if err := w.WritePacket(ci, data); err == nil {
+		t.Error("WritePacket after Close didn't return an error")
+	}
+	files, err = filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	for i := range files {
+		files[i] = filepath.Base(files[i])
+	}
+	want := []string{"capture-3.pcap", "capture-4.pcap"}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("Expected files %v, but got %v", want, files)
+	}
+}
+
+func TestRotatingWriterHeaderError(t *testing.T) {
+	// writes to /dev/full fail with ENOSPC
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("/dev/full not available")
+	}
+	for _, pcapng := range []bool{false, true} {
+		w, err := NewRotatingWriter(RotatingWriterOptions{
+			Filename: "/dev/full",
+			Pcapng:   pcapng,
+			LinkType: layers.LinkTypeEthernet,
+			Snaplen:  65536,
+		})
+		if err != nil {
+			t.Fatal("Couldn't create writer:", err)
+		}
+		data := ngPacketSource[4]
+		ci := gopacket.CaptureInfo{Timestamp: time.Now(), CaptureLength: len(data), Length: len(data)}
+		for i := 0; i < 2; i++ {
+			if err := w.WritePacket(ci, data); err == nil {
+				t.Errorf("pcapng %v: expected error for packet %d", pcapng, i)
+			}
+			if name := w.Filename(); name != "" {
+				t.Errorf("pcapng %v: expected no open file, got %s", pcapng, name)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Errorf("pcapng %v: Close returned %s", pcapng, err)
+		}
+	}
+}
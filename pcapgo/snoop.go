@@ -27,6 +27,7 @@ const unknownVersion = "Unknown Snoop Format Version"
 const unkownLinkType = "Unknown Link Type"
 const originalLenExceeded = "Capture length exceeds original packet length"
 const captureLenExceeded = "Capture length exceeds max capture length"
+const recordLenTooShort = "Packet record length too short for capture length"
 
 type snoopHeader struct {
 	Version  uint32
@@ -54,14 +55,20 @@ var (
 		4: layers.LinkTypeEthernet,  // Ethernet
 		5: layers.LinkTypeC_HDLC,    // HDLC
 		8: layers.LinkTypeFDDI,      // FDDI
+		// Solaris/illumos DLPI media types (dlpi.h) beyond RFC 1761
+		0x18:       layers.LinkTypeEthernet, // DL_ETH_CSMA: ISO 8802/3 and Ethernet
+		0x19:       layers.LinkTypeEthernet, // DL_100BT: 100BaseT
+		0x80000001: layers.LinkTypeIPv4,     // DL_IPV4: IPv4 tunnel
+		0x80000002: layers.LinkTypeIPv6,     // DL_IPV6: IPv6 tunnel
 		/*
-			10 - 4294967295 Unassigned
+			10 - 4294967295 Unassigned in RFC 1761
 			not supported:
 			1 - IEEE 802.4 Token Bus
 			3 - IEEE 802.6 Metro Net
 			6 - Character Synchronous
 			7 - IBM Channel-to-Channel
 			9 - Other
+			0x0a-0x17, 0x1a - DLPI frame relay, X.25, loopback, fibre channel, ATM, ISDN, HIPPI, 100VG, Infiniband
 		*/
 	}
 )
@@ -106,7 +113,8 @@ func (r *SnoopReader) readHeader() error {
 		return fmt.Errorf("%s: %d", unknownVersion, r.header.Version)
 	}
 
-	if r.header.linkType = binary.BigEndian.Uint32(buf[12:16]); r.header.linkType > 10 {
+	r.header.linkType = binary.BigEndian.Uint32(buf[12:16])
+	if _, ok := layerTypes[r.header.linkType]; !ok && r.header.linkType > 10 {
 		return fmt.Errorf("%s, Code:%d", unkownLinkType, r.header.linkType)
 	}
 	return nil
@@ -127,7 +135,8 @@ func (r *SnoopReader) readPacketHeader() (ci gopacket.CaptureInfo, err error) {
 	ci.Timestamp = time.Unix(int64(binary.BigEndian.Uint32(r.buf[16:20])), int64(binary.BigEndian.Uint32(r.buf[20:24])*1000)).UTC()
 	ci.Length = int(binary.BigEndian.Uint32(r.buf[0:4]))
 	ci.CaptureLength = int(binary.BigEndian.Uint32(r.buf[4:8]))
-	r.pad = int(binary.BigEndian.Uint32(r.buf[8:12])) - (24 + ci.Length)
+	// the record is padded after the included (not the original) packet data
+	r.pad = int(binary.BigEndian.Uint32(r.buf[8:12])) - (24 + ci.CaptureLength)
 
 	if ci.CaptureLength > ci.Length {
 		err = errors.New(originalLenExceeded)
@@ -136,6 +145,11 @@ func (r *SnoopReader) readPacketHeader() (ci gopacket.CaptureInfo, err error) {
 
 	if ci.CaptureLength > maxCaptureLen {
 		err = errors.New(captureLenExceeded)
+		return
+	}
+
+	if r.pad < 0 {
+		err = errors.New(recordLenTooShort)
 	}
 
 	return
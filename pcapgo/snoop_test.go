@@ -11,6 +11,8 @@ import (
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/google/gopacket/layers"
 )
 
 var (
@@ -147,6 +149,43 @@ func TestLinkType(t *testing.T) {
 	equalNil(t, err)
 }
 
+func TestSolarisLinkType(t *testing.T) {
+	buf := make([]byte, len(spHeader))
+	copy(buf, spHeader)
+	buf[12] = 0x80
+	buf[15] = 0x02 // DL_IPV6
+	handle, err := NewSnoopReader(bytes.NewReader(buf))
+	equalNil(t, err)
+	lt, err := handle.LinkType()
+	equalNil(t, err)
+	equal(t, layers.LinkTypeIPv6, *lt)
+}
+
+func TestTruncatedPacket(t *testing.T) {
+	truncated := make([]byte, len(pack))
+	copy(truncated, pack)
+	// original length 60, included length 42, record length 24 + 42 + 2 bytes padding
+	truncated[3] = 0x3c
+	buf := append(append(append([]byte(nil), spHeader...), truncated...), pack...)
+	handle, err := NewSnoopReader(bytes.NewReader(buf))
+	equalNil(t, err)
+	data, ci, err := handle.ReadPacketData()
+	equalNil(t, err)
+	equal(t, 60, ci.Length)
+	equal(t, 42, ci.CaptureLength)
+	equal(t, pack[24:66], data)
+	data, _, err = handle.ReadPacketData()
+	equalNil(t, err)
+	equal(t, pack[24:66], data)
+
+	// record length shorter than the included data
+	truncated[11] = 0x40
+	handle, err = NewSnoopReader(bytes.NewReader(append(append([]byte(nil), spHeader...), truncated...)))
+	equalNil(t, err)
+	_, _, err = handle.ReadPacketData()
+	equalError(t, err, fmt.Errorf(recordLenTooShort))
+}
+
 func TestNotOverlapBuf(t *testing.T) {
 	buf := make([]byte, len(spHeader)+len(pack)*2)
 	packs := append(spHeader, pack...)
@@ -25,6 +25,8 @@ import (
 type Writer struct {
 	w        io.Writer
 	tsScaler int
+	// frames is set if w is a CompressedWriter
+	frames *CompressedWriter
 	// Moving this into the struct seems to save an allocation for each call to writePacketHeader
 	buf [16]byte
 }
@@ -33,6 +35,11 @@ const magicMicroseconds = 0xA1B2C3D4
 const versionMajor = 2
 const versionMinor = 4
 
+// The upper bits of the link type field in the file header declare the FCS length
+const pcapLinkTypeMask = 0x0fffffff
+const pcapFCSPresent = 0x10000000
+const pcapFCSLengthShift = 29
+
 // NewWriterNanos returns a new writer object, for writing packet data out
 // to the given writer.  If this is a new empty writer (as opposed to
 // an append), you must call WriteFileHeader before WritePacket.  Packet
@@ -51,7 +58,9 @@ const versionMinor = 4
 //  w2.WritePacket(gopacket.CaptureInfo{...}, data2)
 //  f2.Close()
 func NewWriterNanos(w io.Writer) *Writer {
-	return &Writer{w: w, tsScaler: nanosPerNano}
+	ret := &Writer{w: w, tsScaler: nanosPerNano}
+	ret.frames, _ = w.(*CompressedWriter)
+	return ret
 }
 
 // NewWriter returns a new writer object, for writing packet data out
@@ -72,12 +81,28 @@ func NewWriterNanos(w io.Writer) *Writer {
 //  w2.WritePacket(gopacket.CaptureInfo{...}, data2)
 //  f2.Close()
 func NewWriter(w io.Writer) *Writer {
-	return &Writer{w: w, tsScaler: nanosPerMicro}
+	ret := &Writer{w: w, tsScaler: nanosPerMicro}
+	ret.frames, _ = w.(*CompressedWriter)
+	return ret
 }
 
 // WriteFileHeader writes a file header out to the writer.
 // This must be called exactly once per output.
 func (w *Writer) WriteFileHeader(snaplen uint32, linktype layers.LinkType) error {
+	return w.WriteFileHeaderFCS(snaplen, linktype, 0)
+}
+
+// WriteFileHeaderFCS writes a file header out to the writer, which declares that every packet ends with a frame check
+// sequence of fcsLength bytes. fcsLength must be an even number up to 14; 0 declares packets without FCS.
+// This must be called exactly once per output instead of WriteFileHeader.
+func (w *Writer) WriteFileHeaderFCS(snaplen uint32, linktype layers.LinkType, fcsLength int) error {
+	if fcsLength < 0 || fcsLength > 14 || fcsLength%2 != 0 {
+		return fmt.Errorf("invalid FCS length %d", fcsLength)
+	}
+	linkTypeField := uint32(linktype)
+	if fcsLength > 0 {
+		linkTypeField |= pcapFCSPresent | uint32(fcsLength/2)<<pcapFCSLengthShift
+	}
 	var buf [24]byte
 	if w.tsScaler == nanosPerMicro {
 		binary.LittleEndian.PutUint32(buf[0:4], magicMicroseconds)
@@ -90,9 +115,19 @@ func (w *Writer) WriteFileHeader(snaplen uint32, linktype layers.LinkType) error
 	// bytes 12:16 stay 0 (sigfigs is always set to zero, according to
 	//   http://wiki.wireshark.org/Development/LibpcapFileFormat
 	binary.LittleEndian.PutUint32(buf[16:20], snaplen)
-	binary.LittleEndian.PutUint32(buf[20:24], uint32(linktype))
-	_, err := w.w.Write(buf[:])
-	return err
+	binary.LittleEndian.PutUint32(buf[20:24], linkTypeField)
+	if _, err := w.w.Write(buf[:]); err != nil {
+		return err
+	}
+	return w.endBlock()
+}
+
+// endBlock finishes the current compressed frame if necessary
+func (w *Writer) endBlock() error {
+	if w.frames == nil {
+		return nil
+	}
+	return w.frames.endBlock()
 }
 
 const nanosPerMicro = 1000
@@ -124,6 +159,8 @@ func (w *Writer) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
 	if err := w.writePacketHeader(ci); err != nil {
 		return fmt.Errorf("error writing packet header: %v", err)
 	}
-	_, err := w.w.Write(data)
-	return err
+	if _, err := w.w.Write(data); err != nil {
+		return err
+	}
+	return w.endBlock()
 }
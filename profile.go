@@ -0,0 +1,175 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+// DecodeProfile is an explicit decode graph used for the packets decoded with
+// it set in DecodeOptions.Profile, instead of the single global graph built
+// by the registered decoders.  Different probes need very different views of
+// their traffic, e.g. an ISP probe decodes GTP tunnels while a datacenter
+// probe decodes VXLAN, and neither wants the other's interpretation of
+// arbitrary ports.  A profile can
+//   - restrict the layer types decoded after a layer type (Allow),
+//   - replace the decoders of layer types (SetDecoder),
+//   - map transport ports to the layer types of their payload (Port,
+//     ExclusivePorts), and
+//   - limit the number of nested tunnels (MaxTunnelDepth).
+//
+// Data the profile doesn't allow to be decoded is decoded as Payload.  A
+// profile is built by chaining its methods:
+//
+//	gtp := gopacket.NewDecodeProfile("gtp").
+//	  Port(layers.EndpointUDPPort, 2152, layers.LayerTypeGTPv1U).
+//	  ExclusivePorts().
+//	  Allow(layers.LayerTypeGTPv1U, layers.LayerTypeIPv4, layers.LayerTypeIPv6).
+//	  MaxTunnelDepth(1, layers.LayerTypeGTPv1U)
+//	packet := gopacket.NewPacket(data, layers.LinkTypeEthernet, gopacket.DecodeOptions{Profile: gtp})
+//
+// A profile must not be modified once packets are decoded with it; it's safe
+// for concurrent use afterwards.  Port mappings are applied by the decoders
+// of transport layers in the layers package (TCP only with
+// DecodeStreamsAsDatagrams), and profiles don't affect DecodingLayerParser.
+type DecodeProfile struct {
+	name           string
+	transitions    map[LayerType]map[LayerType]bool
+	decoders       map[LayerType]Decoder
+	ports          map[profilePort]LayerType
+	exclusivePorts bool
+	tunnels        map[LayerType]bool
+	maxTunnelDepth int
+}
+
+type profilePort struct {
+	typ  EndpointType
+	port uint16
+}
+
+// NewDecodeProfile returns a new DecodeProfile which decodes like the global
+// decode graph until it's configured otherwise.
+func NewDecodeProfile(name string) *DecodeProfile {
+	return &DecodeProfile{name: name}
+}
+
+// String returns the name of the profile.
+func (p *DecodeProfile) String() string {
+	return p.name
+}
+
+// Allow allows the layer types to follow a layer of type from.  Once Allow
+// has been called for from, all other layer types following it are decoded
+// as Payload.  Layer types Allow hasn't been called for may be followed by
+// any layer type.
+func (p *DecodeProfile) Allow(from LayerType, to ...LayerType) *DecodeProfile {
+	if p.transitions == nil {
+		p.transitions = make(map[LayerType]map[LayerType]bool)
+	}
+	allowed := p.transitions[from]
+	if allowed == nil {
+		allowed = make(map[LayerType]bool)
+		p.transitions[from] = allowed
+	}
+	for _, t := range to {
+		allowed[t] = true
+	}
+	return p
+}
+
+// SetDecoder replaces the decoder of layer type t, like DecoderOverrides.
+func (p *DecodeProfile) SetDecoder(t LayerType, d Decoder) *DecodeProfile {
+	if p.decoders == nil {
+		p.decoders = make(map[LayerType]Decoder)
+	}
+	p.decoders[t] = d
+	return p
+}
+
+// Port decodes the payload of transport layers with the given source or
+// destination port as layer type t, taking precedence over the globally
+// registered port mappings.  The transport layer is identified by the
+// endpoint type of its ports, e.g. layers.EndpointUDPPort.  Destination ports
+// are looked up first.
+func (p *DecodeProfile) Port(typ EndpointType, port uint16, t LayerType) *DecodeProfile {
+	if p.ports == nil {
+		p.ports = make(map[profilePort]LayerType)
+	}
+	p.ports[profilePort{typ, port}] = t
+	return p
+}
+
+// ExclusivePorts decodes the payload of transport layers with ports not
+// mapped by Port as Payload, ignoring the globally registered port mappings.
+func (p *DecodeProfile) ExclusivePorts() *DecodeProfile {
+	p.exclusivePorts = true
+	return p
+}
+
+// MaxTunnelDepth limits the number of layers of the given tunnel layer types
+// in a packet to depth.  Further tunnel layers are decoded as Payload.
+func (p *DecodeProfile) MaxTunnelDepth(depth int, tunnels ...LayerType) *DecodeProfile {
+	if p.tunnels == nil {
+		p.tunnels = make(map[LayerType]bool)
+	}
+	for _, t := range tunnels {
+		p.tunnels[t] = true
+	}
+	p.maxTunnelDepth = depth
+	return p
+}
+
+// PortLayerType returns the layer type of the payload of a transport layer
+// with the given port according to the profile.  ok is false if the profile
+// doesn't map the port, in which case the globally registered port mappings
+// apply.  It's used by the decoders of transport layers, and may be called on
+// a nil profile.
+func (p *DecodeProfile) PortLayerType(typ EndpointType, port uint16) (t LayerType, ok bool) {
+	if p == nil {
+		return LayerTypeZero, false
+	}
+	if t, ok := p.ports[profilePort{typ, port}]; ok {
+		return t, true
+	}
+	if p.exclusivePorts {
+		return LayerTypePayload, true
+	}
+	return LayerTypeZero, false
+}
+
+// decoder returns the decoder to use for next after the given layers, and
+// false if the profile doesn't change the decoder
+func (p *DecodeProfile) decoder(layers []Layer, next Decoder) (Decoder, bool) {
+	var t LayerType
+	switch d := next.(type) {
+	case LayerType:
+		t = d
+	case layerTyper:
+		t = d.LayerType()
+	default:
+		return next, false
+	}
+	if t == LayerTypePayload {
+		return next, false
+	}
+	if len(layers) > 0 {
+		if allowed, ok := p.transitions[layers[len(layers)-1].LayerType()]; ok && !allowed[t] {
+			return LayerTypePayload, true
+		}
+	}
+	if p.tunnels[t] {
+		depth := 0
+		for _, l := range layers {
+			if p.tunnels[l.LayerType()] {
+				depth++
+			}
+		}
+		if depth >= p.maxTunnelDepth {
+			return LayerTypePayload, true
+		}
+	}
+	if d, ok := p.decoders[t]; ok {
+		return d, true
+	}
+	return next, false
+}
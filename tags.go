@@ -0,0 +1,90 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tag is a user-defined key/value pair attached to a packet.
+type Tag struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns the tag as "key=value".
+func (t Tag) String() string {
+	return fmt.Sprintf("%s=%v", t.Key, t.Value)
+}
+
+// ParseTag parses a tag in the format returned by Tag.String.  The value of
+// the returned tag is a string.
+func ParseTag(s string) (Tag, bool) {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return Tag{}, false
+	}
+	return Tag{Key: s[:i], Value: s[i+1:]}, true
+}
+
+// Tags holds the user-defined tags of a packet, which let the stages of a
+// processing pipeline attach information to the packet, like a tenant ID or
+// the rules it matched.  Since tags are part of PacketMetadata, they travel
+// with the packet through channels:
+//
+//	packet.Metadata().Tags.Set("verdict", "drop")
+//	...
+//	if v, ok := packet.Metadata().Tags.Get("verdict"); ok && v == "drop" {
+//	  ...
+//	}
+//
+// Writers can persist tags, e.g. as pcapng packet comments with
+// pcapgo.NgPacketOptions.AddTags.  Tags are kept in the order they were
+// first set; the zero value is an empty set of tags.
+type Tags []Tag
+
+// Get returns the value of the tag with the given key.
+func (t Tags) Get(key string) (interface{}, bool) {
+	for _, tag := range t {
+		if tag.Key == key {
+			return tag.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Set sets the value of the tag with the given key, adding the tag if
+// necessary.
+func (t *Tags) Set(key string, value interface{}) {
+	for i := range *t {
+		if (*t)[i].Key == key {
+			(*t)[i].Value = value
+			return
+		}
+	}
+	*t = append(*t, Tag{Key: key, Value: value})
+}
+
+// Delete removes the tag with the given key.
+func (t *Tags) Delete(key string) {
+	for i := range *t {
+		if (*t)[i].Key == key {
+			*t = append((*t)[:i], (*t)[i+1:]...)
+			return
+		}
+	}
+}
+
+// String returns the tags as space separated "key=value" pairs.
+func (t Tags) String() string {
+	s := make([]string, len(t))
+	for i, tag := range t {
+		s[i] = tag.String()
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	p := NewPacket([]byte{1, 2, 3}, DecodePayload, Default)
+	tags := &p.Metadata().Tags
+	tags.Set("tenant", 42)
+	tags.Set("rule", "r1")
+	tags.Set("tenant", 43)
+	if v, ok := p.Metadata().Tags.Get("tenant"); !ok || v != 43 {
+		t.Errorf("tenant: got %v (%v), want 43", v, ok)
+	}
+	if got, want := p.Metadata().Tags.String(), "tenant=43 rule=r1"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	tags.Delete("tenant")
+	tags.Delete("missing")
+	if _, ok := tags.Get("tenant"); ok {
+		t.Error("deleted tag still present")
+	}
+	if want := (Tags{{Key: "rule", Value: "r1"}}); !reflect.DeepEqual(*tags, want) {
+		t.Errorf("got %v, want %v", *tags, want)
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	for _, test := range []struct {
+		s    string
+		want Tag
+		ok   bool
+	}{
+		{"a=b", Tag{Key: "a", Value: "b"}, true},
+		{"a=b=c", Tag{Key: "a", Value: "b=c"}, true},
+		{"a=", Tag{Key: "a", Value: ""}, true},
+		{"=b", Tag{}, false},
+		{"comment", Tag{}, false},
+	} {
+		if got, ok := ParseTag(test.s); got != test.want || ok != test.ok {
+			t.Errorf("%q: got %v (%v), want %v (%v)", test.s, got, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 The GoPacket Authors. All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license
+// that can be found in the LICENSE file in the root of the source
+// tree.
+
+package gopacket
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// UnknownProtocolKind is the kind of protocol number an UnknownProtocolHook
+// is called for.
+type UnknownProtocolKind uint8
+
+const (
+	// UnknownEthernetType is used for EtherTypes without a registered
+	// decoder.
+	UnknownEthernetType UnknownProtocolKind = iota
+	// UnknownIPProtocol is used for IP protocol numbers without a registered
+	// decoder.
+	UnknownIPProtocol
+	// UnknownTCPPort is used for the payload of TCP segments whose ports
+	// aren't mapped to a layer type, if the payload is decoded at all (see
+	// DecodeOptions.DecodeStreamsAsDatagrams).
+	UnknownTCPPort
+	// UnknownUDPPort is used for the payload of UDP datagrams whose ports
+	// aren't mapped to a layer type.
+	UnknownUDPPort
+	numUnknownProtocolKinds
+)
+
+// UnknownProtocol describes data following a protocol number no decoder is
+// registered for.
+type UnknownProtocol struct {
+	Kind UnknownProtocolKind
+	// Number is the EtherType, the IP protocol number, or the destination
+	// port.  The source port can be taken from Layer.
+	Number int
+	// Layer is the layer the protocol number was found in, e.g. the
+	// layers.UDP layer for UnknownUDPPort, or nil if it isn't known.
+	Layer Layer
+	// Data is the data following the protocol number.
+	Data []byte
+}
+
+// UnknownProtocolHook is called for data with an unknown protocol number.  It
+// returns the decoder to decode the data with, or nil to leave the data to
+// the next hook, or to the default handling of unknown protocol numbers.
+// Hooks can implement heuristics, like recognizing QUIC on arbitrary UDP
+// ports.
+type UnknownProtocolHook func(u *UnknownProtocol) Decoder
+
+// Hook registrations replace the slices, so that hooks can be registered
+// while packets are decoded.
+var unknownProtocolHooksMu sync.Mutex
+
+var unknownProtocolHooks [numUnknownProtocolKinds]atomic.Value // []UnknownProtocolHook
+
+// RegisterUnknownProtocolHook registers hook to be called for unknown
+// protocol numbers of the given kind.  Hooks are called in the order they
+// were registered until one of them returns a decoder.  It may be called
+// while packets are decoded by other goroutines.
+func RegisterUnknownProtocolHook(kind UnknownProtocolKind, hook UnknownProtocolHook) {
+	unknownProtocolHooksMu.Lock()
+	defer unknownProtocolHooksMu.Unlock()
+	old := unknownProtocolHooksOf(kind)
+	hooks := make([]UnknownProtocolHook, len(old), len(old)+1)
+	copy(hooks, old)
+	unknownProtocolHooks[kind].Store(append(hooks, hook))
+}
+
+func unknownProtocolHooksOf(kind UnknownProtocolKind) []UnknownProtocolHook {
+	hooks, _ := unknownProtocolHooks[kind].Load().([]UnknownProtocolHook)
+	return hooks
+}
+
+// DecodeUnknownProtocol calls the hooks registered for kind with data
+// following the unknown protocol number, and decodes data with the decoder
+// returned by the first hook returning one.  ok is false if no hook returned
+// a decoder, in which case the caller handles data as it did without hooks.
+// It's used by the decoders of layers with protocol numbers.
+func DecodeUnknownProtocol(kind UnknownProtocolKind, number int, data []byte, p PacketBuilder) (ok bool, err error) {
+	hooks := unknownProtocolHooksOf(kind)
+	if len(hooks) == 0 {
+		return false, nil
+	}
+	u := &UnknownProtocol{Kind: kind, Number: number, Data: data}
+	if l, ok := p.(interface{ lastLayer() Layer }); ok {
+		u.Layer = l.lastLayer()
+	}
+	for _, hook := range hooks {
+		if d := hook(u); d != nil {
+			return true, d.Decode(data, p)
+		}
+	}
+	return false, nil
+}
+
+// UnknownProtocolDecoder returns a decoder for data following the unknown
+// protocol number, which is decoded like by DecodeUnknownProtocol, or with
+// fallback if no hook returns a decoder.  It returns fallback itself if no
+// hooks are registered for kind.
+func UnknownProtocolDecoder(kind UnknownProtocolKind, number int, fallback Decoder) Decoder {
+	if len(unknownProtocolHooksOf(kind)) == 0 {
+		return fallback
+	}
+	return &unknownProtocolDecoder{kind: kind, number: number, fallback: fallback}
+}
+
+type unknownProtocolDecoder struct {
+	kind     UnknownProtocolKind
+	number   int
+	fallback Decoder
+}
+
+func (d *unknownProtocolDecoder) Decode(data []byte, p PacketBuilder) error {
+	if ok, err := DecodeUnknownProtocol(d.kind, d.number, data, p); ok {
+		return err
+	}
+	return d.fallback.Decode(data, p)
+}
@@ -8,6 +8,7 @@ package gopacket
 
 import (
 	"fmt"
+	"sync"
 )
 
 // SerializableLayer allows its implementations to be written out as a set of bytes,
@@ -112,6 +113,8 @@ type serializeBuffer struct {
 	start               int
 	prepended, appended int
 	layers              []LayerType
+	// initialLayers backs layers for the common case of a few layers
+	initialLayers [8]LayerType
 }
 
 // NewSerializeBuffer creates a new instance of the default implementation of
@@ -123,6 +126,12 @@ func NewSerializeBuffer() SerializeBuffer {
 // NewSerializeBufferExpectedSize creates a new buffer for serialization, optimized for an
 // expected number of bytes prepended/appended.  This tends to decrease the
 // number of memory allocations made by the buffer during writes.
+//
+// PrependBytes and AppendBytes are guaranteed not to allocate as long as the
+// bytes prepended and appended since the last Clear don't exceed the expected
+// lengths, and the buffer never grows once it has been used for the largest
+// workload: a buffer grown by previous calls keeps its size on Clear.  Up to
+// 8 layers are recorded by PushLayer without allocating.
 func NewSerializeBufferExpectedSize(expectedPrependLength, expectedAppendLength int) SerializeBuffer {
 	return &serializeBuffer{
 		data:      make([]byte, expectedPrependLength, expectedPrependLength+expectedAppendLength),
@@ -189,9 +198,64 @@ func (w *serializeBuffer) Layers() []LayerType {
 }
 
 func (w *serializeBuffer) PushLayer(l LayerType) {
+	if w.layers == nil {
+		w.layers = w.initialLayers[:0]
+	}
 	w.layers = append(w.layers, l)
 }
 
+// PooledSerializeBuffer is a SerializeBuffer taken from a pool, which must be
+// returned to the pool with Release.
+type PooledSerializeBuffer interface {
+	SerializeBuffer
+	// Release clears the buffer and returns it to the pool.  Neither the
+	// buffer nor any byte slice returned by it may be used afterwards.
+	Release()
+}
+
+// maxPooledSerializeBuffer is the maximum capacity of buffers kept in the
+// pool; larger buffers are left to the garbage collector.
+const maxPooledSerializeBuffer = 64 * 1024
+
+var serializeBufferPool sync.Pool
+
+type pooledSerializeBuffer struct {
+	serializeBuffer
+	released bool
+}
+
+// NewSerializeBufferFromPool returns a SerializeBuffer from a pool shared by
+// the whole program, allocating a new one if the pool is empty.  Buffers
+// keep their size when they are released, so a program serializing packets
+// of similar sizes, like a packet crafting service, stops allocating once
+// the pool has warmed up:
+//
+//  buf := gopacket.NewSerializeBufferFromPool()
+//  err := gopacket.SerializeLayers(buf, opts, layers...)
+//  send(buf.Bytes())
+//  buf.Release()
+//
+// NewSerializeBufferFromPool is safe for concurrent use; the returned buffer
+// isn't.
+func NewSerializeBufferFromPool() PooledSerializeBuffer {
+	if b, ok := serializeBufferPool.Get().(*pooledSerializeBuffer); ok {
+		b.released = false
+		return b
+	}
+	return &pooledSerializeBuffer{}
+}
+
+func (w *pooledSerializeBuffer) Release() {
+	if w.released {
+		panic("SerializeBuffer released twice")
+	}
+	w.released = true
+	w.Clear()
+	if cap(w.data) <= maxPooledSerializeBuffer {
+		serializeBufferPool.Put(w)
+	}
+}
+
 // SerializeLayers clears the given write buffer, then writes all layers into it so
 // they correctly wrap each other.  Note that by clearing the buffer, it
 // invalidates all slices previously returned by w.Bytes()
@@ -7,6 +7,7 @@
 package gopacket
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 )
@@ -63,6 +64,79 @@ func TestExponentialSizeIncreaseAppend(t *testing.T) {
 	}
 }
 
+func TestSerializeBufferFromPool(t *testing.T) {
+	b := NewSerializeBufferFromPool()
+	var payload SerializableLayer = Payload([]byte{1, 2, 3})
+	if err := SerializeLayers(b, SerializeOptions{}, payload, payload); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), []byte{1, 2, 3, 1, 2, 3}) || len(b.Layers()) != 2 {
+		t.Errorf("unexpected contents %v, layers %v", b.Bytes(), b.Layers())
+	}
+	b.Release()
+
+	b = NewSerializeBufferFromPool()
+	if len(b.Bytes()) != 0 || len(b.Layers()) != 0 {
+		t.Errorf("buffer from pool not cleared: %v, layers %v", b.Bytes(), b.Layers())
+	}
+	b.Release()
+	defer func() {
+		if recover() == nil {
+			t.Error("double release didn't panic")
+		}
+	}()
+	b.Release()
+}
+
+// testSerializeAllocs serializes payload with f repeatedly and fails if that allocates
+func testSerializeAllocs(t *testing.T, name string, f func()) {
+	f() // warm up
+	if allocs := testing.AllocsPerRun(100, f); allocs != 0 {
+		t.Errorf("%s: %v allocations per run, want 0", name, allocs)
+	}
+}
+
+func TestSerializeBufferZeroAllocs(t *testing.T) {
+	var payload SerializableLayer = Payload(make([]byte, 100))
+	opts := SerializeOptions{}
+
+	b := NewSerializeBufferExpectedSize(64, 128)
+	testSerializeAllocs(t, "expected size", func() {
+		b.Clear()
+		b.PrependBytes(64)
+		b.AppendBytes(128)
+	})
+	testSerializeAllocs(t, "SerializeLayers", func() {
+		SerializeLayers(b, opts, payload, payload, payload)
+	})
+	testSerializeAllocs(t, "pool", func() {
+		b := NewSerializeBufferFromPool()
+		SerializeLayers(b, opts, payload, payload)
+		b.Release()
+	})
+}
+
+func BenchmarkSerializeBufferFromPool(b *testing.B) {
+	var payload SerializableLayer = Payload(make([]byte, 100))
+	opts := SerializeOptions{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf := NewSerializeBufferFromPool()
+		SerializeLayers(buf, opts, payload, payload)
+		buf.Release()
+	}
+}
+
+func BenchmarkSerializeBufferExpectedSize(b *testing.B) {
+	var payload SerializableLayer = Payload(make([]byte, 100))
+	opts := SerializeOptions{}
+	buf := NewSerializeBufferExpectedSize(128, 0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		SerializeLayers(buf, opts, payload)
+	}
+}
+
 func ExampleSerializeBuffer() {
 	b := NewSerializeBuffer()
 	fmt.Println("1:", b.Bytes())